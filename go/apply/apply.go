@@ -0,0 +1,333 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apply implements the $apply operation of R4 ActivityDefinitions and
+// PlanDefinitions, which instantiates a definition as requests for a subject.
+//
+// Applying an ActivityDefinition produces a resource of its kind, i.e. a
+// ServiceRequest or MedicationRequest, with the elements of the definition
+// that have a counterpart in that resource. Applying a PlanDefinition
+// produces a CarePlan containing a RequestGroup with an action for each
+// applicable action of the plan, and the resources the actions define.
+//
+// Applicability conditions and dynamic values are expressions in FHIRPath,
+// evaluated against the subject, or CQL, naming an expression of the
+// definition's library which is evaluated with the cql package.
+package apply
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/cql"
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	adpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/activity_definition_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/care_plan_go_proto"
+	pdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/plan_definition_go_proto"
+)
+
+// Request holds the parameters of an $apply invocation. References are
+// relative, i.e. "Patient/123".
+type Request struct {
+	// Subject is the patient or other subject the definition is applied to.
+	Subject string
+	// Encounter, Practitioner and Organization optionally give the context
+	// of the application; the practitioner becomes the requester or author
+	// of the results.
+	Encounter    string
+	Practitioner string
+	Organization string
+}
+
+// Options configures the application of definitions.
+type Options struct {
+	// Definitions returns the ActivityDefinition or PlanDefinition with the
+	// given canonical URL, for actions that refer to other definitions.
+	Definitions func(canonical string) (proto.Message, error)
+	// Library returns the ELM library with the given canonical URL, for CQL
+	// expressions.
+	Library func(canonical string) (*cql.Library, error)
+	// Engine configures the execution of CQL libraries.
+	Engine cql.Options
+	// Resolver resolves references for FHIRPath expressions. It is also used
+	// to fetch the subject, which is the input of FHIRPath expressions and
+	// available to them as %subject.
+	Resolver fhirpath.Resolver
+}
+
+// ActivityDefinition applies ad to the subject of req and returns the
+// resulting request resource.
+func ActivityDefinition(ctx context.Context, ad *adpb.ActivityDefinition, req *Request, opts Options) (proto.Message, error) {
+	a, err := newApplier(ctx, req, opts)
+	if err != nil {
+		return nil, err
+	}
+	return a.activity(ad)
+}
+
+// PlanDefinition applies pd to the subject of req and returns a CarePlan
+// holding the resulting RequestGroup and requests as contained resources.
+func PlanDefinition(ctx context.Context, pd *pdpb.PlanDefinition, req *Request, opts Options) (*cppb.CarePlan, error) {
+	a, err := newApplier(ctx, req, opts)
+	if err != nil {
+		return nil, err
+	}
+	rg, err := a.plan(pd)
+	if err != nil {
+		return nil, err
+	}
+	cp := &cppb.CarePlan{
+		Title:     pd.GetTitle(),
+		Subject:   a.subject,
+		Encounter: a.encounter,
+		Author:    a.practitioner,
+		Created:   a.created(),
+	}
+	if d := pd.GetDescription().GetValue(); d != "" {
+		cp.Description = &d4pb.String{Value: d}
+	}
+	if err := setCodes(cp.ProtoReflect(), map[string]string{"status": "draft", "intent": "proposal"}); err != nil {
+		return nil, err
+	}
+	if c := canonical(pd.GetUrl().GetValue(), pd.GetVersion().GetValue()); c != "" {
+		cp.InstantiatesCanonical = []*d4pb.Canonical{{Value: c}}
+	}
+	ref, err := a.contain(rg)
+	if err != nil {
+		return nil, err
+	}
+	cp.Activity = []*cppb.CarePlan_Activity{{Reference: ref}}
+	cp.Contained = a.contained
+	return cp, nil
+}
+
+// applier holds the state of a single application.
+type applier struct {
+	ctx  context.Context
+	req  *Request
+	opts Options
+	now  time.Time
+
+	subject, encounter, practitioner, organization *d4pb.Reference
+	// subjectID is the id of the subject, the context of CQL evaluation.
+	subjectID string
+	// subjectResource is the subject fetched with the resolver, if any.
+	subjectResource proto.Message
+
+	engines   map[string]*cql.Engine
+	contained []*anypb.Any
+}
+
+func newApplier(ctx context.Context, req *Request, opts Options) (*applier, error) {
+	if req.Subject == "" {
+		return nil, fmt.Errorf("$apply requires a subject")
+	}
+	a := &applier{ctx: ctx, req: req, opts: opts, now: opts.Engine.Now, engines: map[string]*cql.Engine{}}
+	if a.now.IsZero() {
+		a.now = time.Now()
+	}
+	var err error
+	if a.subject, err = fhirtypes.ResourceReference(req.Subject); err != nil {
+		return nil, err
+	}
+	a.subjectID = req.Subject[strings.LastIndex(req.Subject, "/")+1:]
+	for _, r := range []struct {
+		ref string
+		out **d4pb.Reference
+	}{
+		{req.Encounter, &a.encounter},
+		{req.Practitioner, &a.practitioner},
+		{req.Organization, &a.organization},
+	} {
+		if r.ref == "" {
+			continue
+		}
+		if *r.out, err = fhirtypes.ResourceReference(r.ref); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Resolver != nil {
+		if a.subjectResource, err = opts.Resolver(req.Subject); err != nil {
+			return nil, fmt.Errorf("resolving subject %s: %w", req.Subject, err)
+		}
+	}
+	return a, nil
+}
+
+func (a *applier) created() *d4pb.DateTime {
+	dt, _ := fhirpath.ProtoValue(fhirpath.Temporal{Kind: fhirpath.DateTime, Time: a.now, Precision: fhirpath.PrecisionSecond})
+	return dt.(*d4pb.DateTime)
+}
+
+// requestSubjectFields are the elements of the request resources that refer
+// to the subject, in order of preference.
+var requestSubjectFields = []string{"subject", "patient", "for", "beneficiary"}
+
+// activity instantiates ad as a resource of its kind. Elements of ad are
+// copied to the elements of the resource that correspond to them by
+// convention, i.e. timing to occurrence[x], where the resource has such an
+// element of a compatible type.
+func (a *applier) activity(ad *adpb.ActivityDefinition) (proto.Message, error) {
+	kind := ad.GetKind().GetValue()
+	if kind == 0 {
+		return nil, fmt.Errorf("ActivityDefinition %s has no kind", ad.GetUrl().GetValue())
+	}
+	typ := fhirpath.CodeString(kind.Descriptor().Values().ByNumber(kind.Number()))
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName("google.fhir.r4.core." + typ))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported ActivityDefinition kind %s: %w", typ, err)
+	}
+	res := mt.New()
+	codes := map[string]string{"status": "draft", "intent": "proposal"}
+	if ad.GetIntent() != nil {
+		intent := ad.GetIntent().GetValue()
+		codes["intent"] = fhirpath.CodeString(intent.Descriptor().Values().ByNumber(intent.Number()))
+	}
+	if err := setCodes(res, codes); err != nil {
+		return nil, err
+	}
+	if c := canonical(ad.GetUrl().GetValue(), ad.GetVersion().GetValue()); c != "" {
+		setFirst(res, []string{"instantiatesCanonical"}, &d4pb.Canonical{Value: c})
+	}
+	setFirst(res, requestSubjectFields, a.subject)
+	if a.encounter != nil {
+		setFirst(res, []string{"encounter", "context"}, a.encounter)
+	}
+	if requester := a.practitioner; requester != nil || a.organization != nil {
+		if requester == nil {
+			requester = a.organization
+		}
+		setFirst(res, []string{"requester", "orderer", "author"}, requester)
+	}
+	setFirst(res, []string{"authoredOn", "dateTime", "created"}, a.created())
+	src := ad.ProtoReflect()
+	for _, m := range []struct {
+		from string
+		to   []string
+	}{
+		{"priority", []string{"priority"}},
+		{"doNotPerform", []string{"doNotPerform"}},
+		{"code", []string{"code"}},
+		{"timing", []string{"occurrence", "timing"}},
+		{"location", []string{"locationReference"}},
+		{"product", []string{"medication", "item", "product"}},
+		{"quantity", []string{"quantity"}},
+		{"dosage", []string{"dosageInstruction", "dosage"}},
+		{"bodySite", []string{"bodySite"}},
+	} {
+		for _, v := range values(src, m.from) {
+			setFirst(res, m.to, v)
+		}
+	}
+	for _, dv := range ad.GetDynamicValue() {
+		if err := a.dynamicValue(res, dv.GetPath().GetValue(), dv.GetExpression(), ad.GetLibrary()); err != nil {
+			return nil, err
+		}
+	}
+	return res.Interface(), nil
+}
+
+// values returns the values of the element name of m, with choice types
+// resolved to their value.
+func values(m protoreflect.Message, name string) []proto.Message {
+	fd, _, err := elementpath.LookupField(m.Descriptor(), name)
+	if err != nil || !m.Has(fd) {
+		return nil
+	}
+	var out []proto.Message
+	add := func(v protoreflect.Message) {
+		if elementpath.IsChoice(v.Descriptor()) {
+			set := v.WhichOneof(v.Descriptor().Oneofs().Get(0))
+			if set == nil {
+				return
+			}
+			v = v.Get(set).Message()
+		}
+		out = append(out, v.Interface())
+	}
+	if fd.IsList() {
+		l := m.Get(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			add(l.Get(i).Message())
+		}
+	} else {
+		add(m.Get(fd).Message())
+	}
+	return out
+}
+
+// setFirst assigns v to the first element of names that m has and that can
+// hold v. It reports whether v was assigned.
+func setFirst(m protoreflect.Message, names []string, v proto.Message) bool {
+	for _, name := range names {
+		if _, _, err := elementpath.LookupField(m.Descriptor(), name); err != nil {
+			continue
+		}
+		if err := elementpath.Set(m, name, v); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// setCodes assigns codes to the code elements of m by name.
+func setCodes(m protoreflect.Message, codes map[string]string) error {
+	for name, code := range codes {
+		if _, _, err := elementpath.LookupField(m.Descriptor(), name); err != nil {
+			continue
+		}
+		if err := elementpath.Set(m, name, &d4pb.Code{Value: code}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func canonical(url, version string) string {
+	if url != "" && version != "" {
+		return url + "|" + version
+	}
+	return url
+}
+
+// contain adds res to the contained resources of the result with a new id
+// and returns a reference to it.
+func (a *applier) contain(res proto.Message) (*d4pb.Reference, error) {
+	id := elementpath.ResourceType(res) + "-" + strconv.Itoa(len(a.contained)+1)
+	if err := elementpath.Set(res.ProtoReflect(), "id", &d4pb.Id{Value: id}); err != nil {
+		return nil, err
+	}
+	cr, err := elementpath.Convert(res, (&r4pb.ContainedResource{}).ProtoReflect().Descriptor())
+	if err != nil {
+		return nil, err
+	}
+	any, err := anypb.New(cr)
+	if err != nil {
+		return nil, err
+	}
+	a.contained = append(a.contained, any)
+	return &d4pb.Reference{Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: id}}}, nil
+}
@@ -0,0 +1,371 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/cql"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	adpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/activity_definition_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/care_plan_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	pdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/plan_definition_go_proto"
+	rgpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/request_group_go_proto"
+	srpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/service_request_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+const (
+	libraryURL  = "http://example.com/Library/referrals"
+	referralURL = "http://example.com/ActivityDefinition/referral"
+)
+
+// elm is the translation of:
+//
+//	library Referrals version '1'
+//	using FHIR version '4.0.1'
+//	include FHIRHelpers version '4.0.1'
+//	context Patient
+//	define "Is Female": FHIRHelpers.ToString(Patient.gender) = 'female'
+//	define "Priority": 'urgent'
+const elm = `{"library":{
+	"identifier":{"id":"Referrals","version":"1"},
+	"includes":{"def":[{"localIdentifier":"FHIRHelpers","path":"FHIRHelpers","version":"4.0.1"}]},
+	"statements":{"def":[
+		{"name":"Patient","context":"Patient","expression":{"type":"SingletonFrom",
+			"operand":{"type":"Retrieve","dataType":"{http://hl7.org/fhir}Patient"}}},
+		{"name":"Is Female","context":"Patient","expression":{"type":"Equal","operand":[
+			{"type":"FunctionRef","libraryName":"FHIRHelpers","name":"ToString",
+				"operand":[{"type":"Property","path":"gender","source":{"type":"ExpressionRef","name":"Patient"}}]},
+			{"type":"Literal","valueType":"{urn:hl7-org:elm-types:r1}String","value":"female"}]}},
+		{"name":"Priority","context":"Patient","expression":{"type":"Literal",
+			"valueType":"{urn:hl7-org:elm-types:r1}String","value":"urgent"}}
+	]}}}`
+
+var now = time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+
+func patients() []proto.Message {
+	patient := func(id, given string, gender c4pb.AdministrativeGenderCode_Value) proto.Message {
+		return &ppb.Patient{
+			Id:     &d4pb.Id{Value: id},
+			Name:   []*d4pb.HumanName{{Given: []*d4pb.String{{Value: given}}}},
+			Gender: &ppb.Patient_GenderCode{Value: gender},
+		}
+	}
+	return []proto.Message{
+		patient("p1", "Alice", c4pb.AdministrativeGenderCode_FEMALE),
+		patient("p2", "Bob", c4pb.AdministrativeGenderCode_MALE),
+	}
+}
+
+func testOptions(defs ...*adpb.ActivityDefinition) Options {
+	resources := patients()
+	return Options{
+		Definitions: func(canonical string) (proto.Message, error) {
+			for _, d := range defs {
+				if d.GetUrl().GetValue() == canonical {
+					return d, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown definition %s", canonical)
+		},
+		Library: func(canonical string) (*cql.Library, error) {
+			if canonical != libraryURL {
+				return nil, fmt.Errorf("unknown library %s", canonical)
+			}
+			return cql.Parse([]byte(elm))
+		},
+		Engine: cql.Options{Retriever: cql.NewMemoryRetriever(nil, resources...), Now: now},
+		Resolver: func(ref string) (proto.Message, error) {
+			for _, r := range resources {
+				if "Patient/"+r.(*ppb.Patient).GetId().GetValue() == ref {
+					return r, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown resource %s", ref)
+		},
+	}
+}
+
+func fhirpathExpr(s string) *d4pb.Expression {
+	return &d4pb.Expression{Language: &d4pb.Code{Value: "text/fhirpath"}, Expression: &d4pb.String{Value: s}}
+}
+
+func cqlExpr(name string) *d4pb.Expression {
+	return &d4pb.Expression{Language: &d4pb.Code{Value: "text/cql.identifier"}, Expression: &d4pb.String{Value: name}}
+}
+
+func concept(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: system}, Code: &d4pb.Code{Value: code}}}}
+}
+
+func patientRef(id string) *d4pb.Reference {
+	return &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: id}}}
+}
+
+func fragment(id string) *d4pb.Reference {
+	return &d4pb.Reference{Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: id}}}
+}
+
+var created = &d4pb.DateTime{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND}
+
+func referral() *adpb.ActivityDefinition {
+	return &adpb.ActivityDefinition{
+		Url:      &d4pb.Uri{Value: referralURL},
+		Version:  &d4pb.String{Value: "1"},
+		Library:  []*d4pb.Canonical{{Value: libraryURL}},
+		Kind:     &adpb.ActivityDefinition_KindCode{Value: c4pb.RequestResourceTypeCode_SERVICE_REQUEST},
+		Code:     concept("http://snomed.info/sct", "306206005"),
+		Priority: &adpb.ActivityDefinition_PriorityCode{Value: c4pb.RequestPriorityCode_ROUTINE},
+		DynamicValue: []*adpb.ActivityDefinition_DynamicValue{
+			{Path: &d4pb.String{Value: "priority"}, Expression: cqlExpr("Priority")},
+			{Path: &d4pb.String{Value: "note.text"}, Expression: fhirpathExpr("'Referral for ' + %subject.name.given.first()")},
+		},
+	}
+}
+
+func wantReferral(patient, given string) *srpb.ServiceRequest {
+	return &srpb.ServiceRequest{
+		Status:                &srpb.ServiceRequest_StatusCode{Value: c4pb.RequestStatusCode_DRAFT},
+		Intent:                &srpb.ServiceRequest_IntentCode{Value: c4pb.RequestIntentCode_PROPOSAL},
+		InstantiatesCanonical: []*d4pb.Canonical{{Value: referralURL + "|1"}},
+		Subject:               patientRef(patient),
+		AuthoredOn:            created,
+		Priority:              &srpb.ServiceRequest_PriorityCode{Value: c4pb.RequestPriorityCode_URGENT},
+		Code:                  concept("http://snomed.info/sct", "306206005"),
+		Note:                  []*d4pb.Annotation{{Text: &d4pb.Markdown{Value: "Referral for " + given}}},
+	}
+}
+
+func TestActivityDefinition(t *testing.T) {
+	amoxicillin := concept("http://www.nlm.nih.gov/research/umls/rxnorm", "723")
+	dosage := &d4pb.Dosage{Text: &d4pb.String{Value: "500mg three times daily"}}
+	tests := []struct {
+		name string
+		ad   *adpb.ActivityDefinition
+		want proto.Message
+	}{
+		{
+			name: "service request",
+			ad:   referral(),
+			want: wantReferral("p1", "Alice"),
+		},
+		{
+			name: "medication request",
+			ad: &adpb.ActivityDefinition{
+				Kind:    &adpb.ActivityDefinition_KindCode{Value: c4pb.RequestResourceTypeCode_MEDICATION_REQUEST},
+				Intent:  &adpb.ActivityDefinition_IntentCode{Value: c4pb.RequestIntentCode_ORDER},
+				Product: &adpb.ActivityDefinition_ProductX{Choice: &adpb.ActivityDefinition_ProductX_CodeableConcept{CodeableConcept: amoxicillin}},
+				Dosage:  []*d4pb.Dosage{dosage},
+			},
+			want: &mrpb.MedicationRequest{
+				Status:            &mrpb.MedicationRequest_StatusCode{Value: c4pb.MedicationrequestStatusCode_DRAFT},
+				Intent:            &mrpb.MedicationRequest_IntentCode{Value: c4pb.MedicationRequestIntentCode_ORDER},
+				Subject:           patientRef("p1"),
+				AuthoredOn:        created,
+				Medication:        &mrpb.MedicationRequest_MedicationX{Choice: &mrpb.MedicationRequest_MedicationX_CodeableConcept{CodeableConcept: amoxicillin}},
+				DosageInstruction: []*d4pb.Dosage{dosage},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ActivityDefinition(context.Background(), test.ad, &Request{Subject: "Patient/p1"}, testOptions())
+			if err != nil {
+				t.Fatalf("ActivityDefinition() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ActivityDefinition() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func contained(t *testing.T, msgs ...proto.Message) []*anypb.Any {
+	t.Helper()
+	var out []*anypb.Any
+	for _, m := range msgs {
+		var cr *r4pb.ContainedResource
+		switch m := m.(type) {
+		case *srpb.ServiceRequest:
+			cr = &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_ServiceRequest{ServiceRequest: m}}
+		case *rgpb.RequestGroup:
+			cr = &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_RequestGroup{RequestGroup: m}}
+		}
+		any, err := anypb.New(cr)
+		if err != nil {
+			t.Fatalf("anypb.New() returned unexpected error: %v", err)
+		}
+		out = append(out, any)
+	}
+	return out
+}
+
+func TestPlanDefinition(t *testing.T) {
+	pd := &pdpb.PlanDefinition{
+		Url:     &d4pb.Uri{Value: "http://example.com/PlanDefinition/screening"},
+		Title:   &d4pb.String{Value: "Screening"},
+		Library: []*d4pb.Canonical{{Value: libraryURL}},
+		Action: []*pdpb.PlanDefinition_Action{
+			{
+				Title: &d4pb.String{Value: "Refer"},
+				Condition: []*pdpb.PlanDefinition_Action_Condition{{
+					Kind:       &pdpb.PlanDefinition_Action_Condition_KindCode{Value: c4pb.ActionConditionKindCode_APPLICABILITY},
+					Expression: cqlExpr("Is Female"),
+				}},
+				Definition: &pdpb.PlanDefinition_Action_DefinitionX{Choice: &pdpb.PlanDefinition_Action_DefinitionX_Canonical{
+					Canonical: &d4pb.Canonical{Value: referralURL},
+				}},
+				DynamicValue: []*pdpb.PlanDefinition_Action_DynamicValue{{
+					Path:       &d4pb.String{Value: "action.description"},
+					Expression: fhirpathExpr("'Refer ' + %subject.name.given.first()"),
+				}},
+			},
+			{
+				Title:          &d4pb.String{Value: "Counsel"},
+				TextEquivalent: &d4pb.String{Value: "Offer counselling"},
+				Condition: []*pdpb.PlanDefinition_Action_Condition{{
+					Kind:       &pdpb.PlanDefinition_Action_Condition_KindCode{Value: c4pb.ActionConditionKindCode_APPLICABILITY},
+					Expression: fhirpathExpr("gender = 'male'"),
+				}},
+			},
+		},
+	}
+	carePlan := func(patient string, rg string, resources ...proto.Message) *cppb.CarePlan {
+		return &cppb.CarePlan{
+			Status:                &cppb.CarePlan_StatusCode{Value: c4pb.RequestStatusCode_DRAFT},
+			Intent:                &cppb.CarePlan_IntentCode{Value: vspb.CarePlanIntentValueSet_PROPOSAL},
+			InstantiatesCanonical: []*d4pb.Canonical{{Value: "http://example.com/PlanDefinition/screening"}},
+			Title:                 &d4pb.String{Value: "Screening"},
+			Subject:               patientRef(patient),
+			Created:               created,
+			Activity:              []*cppb.CarePlan_Activity{{Reference: fragment(rg)}},
+			Contained:             contained(t, resources...),
+		}
+	}
+	requestGroup := func(id, patient string, actions ...*rgpb.RequestGroup_Action) *rgpb.RequestGroup {
+		return &rgpb.RequestGroup{
+			Id:                    &d4pb.Id{Value: id},
+			Status:                &rgpb.RequestGroup_StatusCode{Value: c4pb.RequestStatusCode_DRAFT},
+			Intent:                &rgpb.RequestGroup_IntentCode{Value: c4pb.RequestIntentCode_PROPOSAL},
+			InstantiatesCanonical: []*d4pb.Canonical{{Value: "http://example.com/PlanDefinition/screening"}},
+			Subject:               patientRef(patient),
+			AuthoredOn:            created,
+			Action:                actions,
+		}
+	}
+	sr := wantReferral("p1", "Alice")
+	sr.Id = &d4pb.Id{Value: "ServiceRequest-1"}
+
+	tests := []struct {
+		name    string
+		subject string
+		want    *cppb.CarePlan
+	}{
+		{
+			name:    "referral",
+			subject: "Patient/p1",
+			want: carePlan("p1", "RequestGroup-2", sr, requestGroup("RequestGroup-2", "p1", &rgpb.RequestGroup_Action{
+				Title:       &d4pb.String{Value: "Refer"},
+				Description: &d4pb.String{Value: "Refer Alice"},
+				Resource:    fragment("ServiceRequest-1"),
+			})),
+		},
+		{
+			name:    "counselling",
+			subject: "Patient/p2",
+			want: carePlan("p2", "RequestGroup-1", requestGroup("RequestGroup-1", "p2", &rgpb.RequestGroup_Action{
+				Title:          &d4pb.String{Value: "Counsel"},
+				TextEquivalent: &d4pb.String{Value: "Offer counselling"},
+			})),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := PlanDefinition(context.Background(), pd, &Request{Subject: test.subject}, testOptions(referral()))
+			if err != nil {
+				t.Fatalf("PlanDefinition() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("PlanDefinition() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	action := func(a *pdpb.PlanDefinition_Action) *pdpb.PlanDefinition {
+		return &pdpb.PlanDefinition{Library: []*d4pb.Canonical{{Value: libraryURL}}, Action: []*pdpb.PlanDefinition_Action{a}}
+	}
+	tests := []struct {
+		name    string
+		pd      *pdpb.PlanDefinition
+		subject string
+	}{
+		{
+			name: "no subject",
+			pd:   &pdpb.PlanDefinition{},
+		},
+		{
+			name:    "unknown definition",
+			subject: "Patient/p1",
+			pd: action(&pdpb.PlanDefinition_Action{Definition: &pdpb.PlanDefinition_Action_DefinitionX{
+				Choice: &pdpb.PlanDefinition_Action_DefinitionX_Canonical{Canonical: &d4pb.Canonical{Value: "http://example.com/unknown"}},
+			}}),
+		},
+		{
+			name:    "unsupported language",
+			subject: "Patient/p1",
+			pd: action(&pdpb.PlanDefinition_Action{Condition: []*pdpb.PlanDefinition_Action_Condition{{
+				Kind:       &pdpb.PlanDefinition_Action_Condition_KindCode{Value: c4pb.ActionConditionKindCode_APPLICABILITY},
+				Expression: &d4pb.Expression{Language: &d4pb.Code{Value: "text/x-unknown"}, Expression: &d4pb.String{Value: "true"}},
+			}}}),
+		},
+		{
+			name:    "non-Boolean condition",
+			subject: "Patient/p1",
+			pd: action(&pdpb.PlanDefinition_Action{Condition: []*pdpb.PlanDefinition_Action_Condition{{
+				Kind:       &pdpb.PlanDefinition_Action_Condition_KindCode{Value: c4pb.ActionConditionKindCode_APPLICABILITY},
+				Expression: cqlExpr("Priority"),
+			}}}),
+		},
+		{
+			name:    "unknown dynamic value path",
+			subject: "Patient/p1",
+			pd: action(&pdpb.PlanDefinition_Action{DynamicValue: []*pdpb.PlanDefinition_Action_DynamicValue{{
+				Path: &d4pb.String{Value: "unknown"}, Expression: fhirpathExpr("'x'"),
+			}}}),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := PlanDefinition(context.Background(), test.pd, &Request{Subject: test.subject}, testOptions()); err == nil {
+				t.Errorf("PlanDefinition() succeeded, want error")
+			}
+		})
+	}
+}
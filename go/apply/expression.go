@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/cql"
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// condition evaluates the applicability condition e, which is met if it
+// evaluates to true.
+func (a *applier) condition(e *d4pb.Expression, libs []*d4pb.Canonical) (bool, error) {
+	vs, err := a.evaluate(e, libs)
+	if err != nil {
+		return false, err
+	}
+	if len(vs) != 1 {
+		return false, nil
+	}
+	v := vs[0]
+	if m, ok := v.(proto.Message); ok {
+		v, _ = fhirpath.SystemValue(m)
+	}
+	b, ok := v.(bool)
+	if !ok && v != nil {
+		return false, fmt.Errorf("condition evaluated to %v, not a Boolean", v)
+	}
+	return b, nil
+}
+
+// dynamicValue sets the element of target at the dotted path to the value of
+// e. Intermediate elements are created as needed and the last one receives
+// every value e evaluates to.
+func (a *applier) dynamicValue(target protoreflect.Message, path string, e *d4pb.Expression, libs []*d4pb.Canonical) error {
+	if path == "" {
+		return fmt.Errorf("dynamic value has no path")
+	}
+	vs, err := a.evaluate(e, libs)
+	if err != nil {
+		return fmt.Errorf("dynamic value %s: %w", path, err)
+	}
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		if target, err = elementpath.Child(target, part, false); err != nil {
+			return fmt.Errorf("dynamic value %s: %w", path, err)
+		}
+	}
+	for _, v := range vs {
+		m, ok := v.(proto.Message)
+		if !ok {
+			if m, ok = cql.ProtoValue(v); !ok {
+				return fmt.Errorf("dynamic value %s: %v has no FHIR representation", path, v)
+			}
+		}
+		if err := elementpath.Set(target, parts[len(parts)-1], m); err != nil {
+			return fmt.Errorf("dynamic value %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// evaluate evaluates e and returns its results as a list. FHIRPath
+// expressions take the subject as input; CQL expressions name a definition of
+// the library e refers to, or else of the first library in libs.
+func (a *applier) evaluate(e *d4pb.Expression, libs []*d4pb.Canonical) ([]interface{}, error) {
+	text := e.GetExpression().GetValue()
+	if text == "" {
+		return nil, fmt.Errorf("expression has no text")
+	}
+	switch lang := e.GetLanguage().GetValue(); lang {
+	case "text/fhirpath":
+		expr, err := fhirpath.Compile(text)
+		if err != nil {
+			return nil, err
+		}
+		var input fhirpath.Collection
+		if a.subjectResource != nil {
+			input = fhirpath.Collection{a.subjectResource}
+		}
+		opts := []fhirpath.EvaluateOption{fhirpath.WithVariable("subject", input), fhirpath.WithNow(a.now)}
+		if a.opts.Resolver != nil {
+			opts = append(opts, fhirpath.WithResolver(a.opts.Resolver))
+		}
+		c, err := expr.Evaluate(input, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}(c), nil
+	case "text/cql", "text/cql.identifier", "text/cql-identifier":
+		canonical := e.GetReference().GetValue()
+		if canonical == "" && len(libs) > 0 {
+			canonical = libs[0].GetValue()
+		}
+		if canonical == "" {
+			return nil, fmt.Errorf("no library for CQL expression %q", text)
+		}
+		engine, err := a.engine(canonical)
+		if err != nil {
+			return nil, err
+		}
+		results, err := engine.Evaluate(a.ctx, a.subjectID, text)
+		if err != nil {
+			return nil, err
+		}
+		switch v := results[text].(type) {
+		case nil:
+			return nil, nil
+		case []interface{}:
+			return v, nil
+		default:
+			return []interface{}{v}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported expression language %q", lang)
+	}
+}
+
+// engine returns the CQL engine for the library with the given canonical URL.
+func (a *applier) engine(canonical string) (*cql.Engine, error) {
+	if e, ok := a.engines[canonical]; ok {
+		return e, nil
+	}
+	if a.opts.Library == nil {
+		return nil, fmt.Errorf("no library resolver for %s", canonical)
+	}
+	lib, err := a.opts.Library(canonical)
+	if err != nil {
+		return nil, fmt.Errorf("loading library %s: %w", canonical, err)
+	}
+	e, err := cql.New(lib, a.opts.Engine)
+	if err != nil {
+		return nil, err
+	}
+	a.engines[canonical] = e
+	return e, nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	adpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/activity_definition_go_proto"
+	pdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/plan_definition_go_proto"
+	rgpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/request_group_go_proto"
+)
+
+// actionFields are the elements of PlanDefinition.action copied unchanged to
+// RequestGroup.action.
+var actionFields = []string{
+	"prefix", "title", "description", "textEquivalent", "priority", "code", "documentation", "timing", "type",
+	"groupingBehavior", "selectionBehavior", "requiredBehavior", "precheckBehavior", "cardinalityBehavior",
+}
+
+// plan instantiates pd as a RequestGroup. The resources defined by its
+// actions are added to the contained resources of the result.
+func (a *applier) plan(pd *pdpb.PlanDefinition) (*rgpb.RequestGroup, error) {
+	rg := &rgpb.RequestGroup{
+		Subject:    a.subject,
+		Encounter:  a.encounter,
+		Author:     a.practitioner,
+		AuthoredOn: a.created(),
+	}
+	if err := setCodes(rg.ProtoReflect(), map[string]string{"status": "draft", "intent": "proposal"}); err != nil {
+		return nil, err
+	}
+	if c := canonical(pd.GetUrl().GetValue(), pd.GetVersion().GetValue()); c != "" {
+		rg.InstantiatesCanonical = []*d4pb.Canonical{{Value: c}}
+	}
+	actions, err := a.actions(pd, pd.GetAction())
+	if err != nil {
+		return nil, err
+	}
+	rg.Action = actions
+	return rg, nil
+}
+
+func (a *applier) actions(pd *pdpb.PlanDefinition, defs []*pdpb.PlanDefinition_Action) ([]*rgpb.RequestGroup_Action, error) {
+	var out []*rgpb.RequestGroup_Action
+	for i, def := range defs {
+		act, err := a.action(pd, def)
+		if err != nil {
+			name := def.GetTitle().GetValue()
+			if name == "" {
+				name = fmt.Sprint(i)
+			}
+			return nil, fmt.Errorf("action %s: %w", name, err)
+		}
+		if act != nil {
+			out = append(out, act)
+		}
+	}
+	return out, nil
+}
+
+// action instantiates def, returning nil if one of its applicability
+// conditions is not met.
+func (a *applier) action(pd *pdpb.PlanDefinition, def *pdpb.PlanDefinition_Action) (*rgpb.RequestGroup_Action, error) {
+	for _, c := range def.GetCondition() {
+		if c.GetKind().GetValue() != c4pb.ActionConditionKindCode_APPLICABILITY {
+			continue
+		}
+		ok, err := a.condition(c.GetExpression(), pd.GetLibrary())
+		if err != nil {
+			return nil, fmt.Errorf("applicability condition: %w", err)
+		}
+		if !ok {
+			return nil, nil
+		}
+	}
+	out := &rgpb.RequestGroup_Action{}
+	rm, src := out.ProtoReflect(), def.ProtoReflect()
+	for _, name := range actionFields {
+		for _, v := range values(src, name) {
+			if err := elementpath.Set(rm, name, v); err != nil {
+				return nil, fmt.Errorf("copying %s: %w", name, err)
+			}
+		}
+	}
+	for _, ra := range def.GetRelatedAction() {
+		related, err := elementpath.Child(rm, "relatedAction", true)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range []string{"actionId", "relationship", "offset"} {
+			for _, v := range values(ra.ProtoReflect(), name) {
+				if err := elementpath.Set(related, name, v); err != nil {
+					return nil, fmt.Errorf("copying relatedAction.%s: %w", name, err)
+				}
+			}
+		}
+	}
+
+	var res proto.Message
+	if url := definitionURL(def); url != "" {
+		d, err := a.definition(pd, url)
+		if err != nil {
+			return nil, err
+		}
+		switch d := d.(type) {
+		case *adpb.ActivityDefinition:
+			if res, err = a.activity(d); err != nil {
+				return nil, fmt.Errorf("applying %s: %w", url, err)
+			}
+		case *pdpb.PlanDefinition:
+			if res, err = a.plan(d); err != nil {
+				return nil, fmt.Errorf("applying %s: %w", url, err)
+			}
+		default:
+			return nil, fmt.Errorf("definition %s is a %T, not an ActivityDefinition or PlanDefinition", url, d)
+		}
+	}
+
+	// Dynamic values apply to the resource of the action, or to the action
+	// itself when their path starts with "action." or it has no resource.
+	for _, dv := range def.GetDynamicValue() {
+		path, target := dv.GetPath().GetValue(), protoreflect.Message(rm)
+		if strings.HasPrefix(path, "action.") || res == nil {
+			path = strings.TrimPrefix(path, "action.")
+		} else {
+			target = res.ProtoReflect()
+		}
+		if err := a.dynamicValue(target, path, dv.GetExpression(), pd.GetLibrary()); err != nil {
+			return nil, err
+		}
+	}
+	if res != nil {
+		ref, err := a.contain(res)
+		if err != nil {
+			return nil, err
+		}
+		out.Resource = ref
+	}
+
+	children, err := a.actions(pd, def.GetAction())
+	if err != nil {
+		return nil, err
+	}
+	out.Action = children
+	return out, nil
+}
+
+// definitionURL returns the canonical URL of the definition of def, or an empty
+// string if it has none.
+func definitionURL(def *pdpb.PlanDefinition_Action) string {
+	if c := def.GetDefinition().GetCanonical().GetValue(); c != "" {
+		return c
+	}
+	return def.GetDefinition().GetUri().GetValue()
+}
+
+// definition returns the definition with the canonical URL url, which is
+// either a reference to a resource contained in pd or resolved with the
+// Definitions option.
+func (a *applier) definition(pd *pdpb.PlanDefinition, url string) (proto.Message, error) {
+	if strings.HasPrefix(url, "#") {
+		for _, any := range pd.GetContained() {
+			m, err := any.UnmarshalNew()
+			if err != nil {
+				return nil, err
+			}
+			if elementpath.ID(m) == url[1:] {
+				return elementpath.Unwrap(m), nil
+			}
+		}
+		return nil, fmt.Errorf("no contained definition %s", url)
+	}
+	if a.opts.Definitions == nil {
+		return nil, fmt.Errorf("no definition resolver for %s", url)
+	}
+	d, err := a.opts.Definitions(url)
+	if err != nil {
+		return nil, fmt.Errorf("resolving definition %s: %w", url, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,431 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package appointment checks proposed R4 Appointments against the Schedules
+// and Slots of a scheduling service and the other Appointments of their
+// participants, and works out the Slot status updates booking them takes.
+//
+// Times are half-open, so an Appointment ending at 10:00 does not overlap
+// one starting at 10:00. Pending, booked, arrived and checked-in
+// Appointments and fulfilled ones hold the time of their participants;
+// proposed and waitlisted Appointments do not, nor do cancelled ones, no
+// shows and those entered in error.
+package appointment
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/appointment_go_proto"
+	schpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/schedule_go_proto"
+	slpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/slot_go_proto"
+)
+
+// Kind is the kind of a Conflict.
+type Kind int
+
+const (
+	// UnknownSlot is a Slot the Appointment refers to that the Calendar
+	// does not have.
+	UnknownSlot Kind = iota + 1
+	// SlotUnavailable is a Slot of the Appointment that is not free.
+	SlotUnavailable
+	// OutsideSlots is an Appointment whose time its Slots do not cover.
+	OutsideSlots
+	// ScheduleInactive is an inactive Schedule of a Slot of the
+	// Appointment.
+	ScheduleInactive
+	// OutsideHorizon is a Schedule of a Slot of the Appointment whose
+	// planning horizon does not contain it.
+	OutsideHorizon
+	// ParticipantBusy is a busy Slot of the Schedule of a participant that
+	// overlaps the Appointment.
+	ParticipantBusy
+	// DoubleBooked is another Appointment of a participant that overlaps
+	// the Appointment.
+	DoubleBooked
+)
+
+var kindNames = map[Kind]string{
+	UnknownSlot:      "unknown slot",
+	SlotUnavailable:  "slot unavailable",
+	OutsideSlots:     "outside slots",
+	ScheduleInactive: "schedule inactive",
+	OutsideHorizon:   "outside planning horizon",
+	ParticipantBusy:  "participant busy",
+	DoubleBooked:     "double booked",
+}
+
+func (k Kind) String() string {
+	if s, ok := kindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Conflict is a reason an Appointment cannot be booked as proposed.
+type Conflict struct {
+	Kind Kind
+	// Resource is the reference of the Slot, Schedule or other Appointment
+	// in conflict, i.e. "Slot/1". It is "" for OutsideSlots.
+	Resource string
+	// Actor is the reference of the participant of ParticipantBusy and
+	// DoubleBooked conflicts, i.e. "Practitioner/1".
+	Actor string
+}
+
+func (c Conflict) String() string {
+	s := c.Kind.String()
+	if c.Resource != "" {
+		s += ": " + c.Resource
+	}
+	if c.Actor != "" {
+		s += " of " + c.Actor
+	}
+	return s
+}
+
+// Calendar holds the Schedules, Slots and Appointments of a scheduling
+// service that Appointments are checked against.
+type Calendar struct {
+	Schedules    []*schpb.Schedule
+	Slots        []*slpb.Slot
+	Appointments []*apb.Appointment
+}
+
+// holdsTime reports whether Appointments of status s hold the time of their
+// participants.
+func holdsTime(s c4pb.AppointmentStatusCode_Value) bool {
+	switch s {
+	case c4pb.AppointmentStatusCode_PENDING, c4pb.AppointmentStatusCode_BOOKED, c4pb.AppointmentStatusCode_ARRIVED,
+		c4pb.AppointmentStatusCode_CHECKED_IN, c4pb.AppointmentStatusCode_FULFILLED:
+		return true
+	}
+	return false
+}
+
+// interval is the half-open time [start, end).
+type interval struct {
+	start, end time.Time
+}
+
+func (i interval) overlaps(j interval) bool {
+	return i.start.Before(j.end) && j.start.Before(i.end)
+}
+
+// instants returns the interval from start to end, which must both be set.
+func instants(start, end *d4pb.Instant) (interval, bool, error) {
+	if start == nil || end == nil {
+		return interval{}, false, nil
+	}
+	s, err := fhirtypes.InstantToTime(start)
+	if err != nil {
+		return interval{}, false, fmt.Errorf("start: %w", err)
+	}
+	e, err := fhirtypes.InstantToTime(end)
+	if err != nil {
+		return interval{}, false, fmt.Errorf("end: %w", err)
+	}
+	if e.Before(s) {
+		return interval{}, false, fmt.Errorf("ends before it starts")
+	}
+	return interval{s, e}, true, nil
+}
+
+func slotInterval(s *slpb.Slot) (interval, error) {
+	i, ok, err := instants(s.GetStart(), s.GetEnd())
+	if err == nil && !ok {
+		err = fmt.Errorf("no start or end")
+	}
+	if err != nil {
+		return interval{}, fmt.Errorf("Slot/%s: %w", s.GetId().GetValue(), err)
+	}
+	return i, nil
+}
+
+// refersTo reports whether any of refs refers to the resource of
+// resourceType with the id.
+func refersTo(refs []*d4pb.Reference, resourceType, id string) bool {
+	target := fhirtypes.Reference(resourceType, id)
+	for _, r := range refs {
+		if fhirtypes.SameReference(r, target, "") {
+			return true
+		}
+	}
+	return false
+}
+
+// actors returns the references of the participants of a whose time it
+// holds: those that have not declined and are not there for information
+// only.
+func actors(a *apb.Appointment) []*d4pb.Reference {
+	var out []*d4pb.Reference
+	for _, p := range a.GetParticipant() {
+		if p.GetActor() == nil || p.GetStatus().GetValue() == c4pb.ParticipationStatusCode_DECLINED ||
+			p.GetRequired().GetValue() == c4pb.ParticipantRequiredCode_INFORMATION_ONLY {
+			continue
+		}
+		out = append(out, p.GetActor())
+	}
+	return out
+}
+
+// Check returns the conflicts of the proposed Appointment appt with cal:
+// the Slots it refers to must be in cal and free, unless appt itself holds
+// them, their Schedules active and planning for its time, and its time
+// covered by them; and its participants must have neither busy Slots nor
+// other Appointments that overlap it. Appointments in cal with the id of
+// appt are earlier versions of it and are ignored. Proposed, cancelled and
+// waitlisted Appointments may have no time, and have no conflicts then.
+func Check(appt *apb.Appointment, cal Calendar) ([]Conflict, error) {
+	at, ok, err := instants(appt.GetStart(), appt.GetEnd())
+	if err != nil {
+		return nil, fmt.Errorf("appointment: %w", err)
+	}
+	if !ok {
+		switch appt.GetStatus().GetValue() {
+		case c4pb.AppointmentStatusCode_PROPOSED, c4pb.AppointmentStatusCode_CANCELLED, c4pb.AppointmentStatusCode_WAITLIST:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("appointment has no start or end")
+	}
+	id := appt.GetId().GetValue()
+	others := otherAppointments(id, cal.Appointments)
+	heldBySelf := func(s *slpb.Slot) bool {
+		for _, a := range cal.Appointments {
+			if _, holds := slotStatus(a.GetStatus().GetValue()); holds && id != "" && a.GetId().GetValue() == id &&
+				refersTo(a.GetSlot(), "Slot", s.GetId().GetValue()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var conflicts []Conflict
+	var covered []interval
+	for _, ref := range appt.GetSlot() {
+		s := findSlot(cal.Slots, ref)
+		if s == nil {
+			conflicts = append(conflicts, Conflict{Kind: UnknownSlot, Resource: fhirtypes.ReferenceURI(ref)})
+			continue
+		}
+		si, err := slotInterval(s)
+		if err != nil {
+			return nil, err
+		}
+		covered = append(covered, si)
+		name := "Slot/" + s.GetId().GetValue()
+		if st := s.GetStatus().GetValue(); st != c4pb.SlotStatusCode_FREE && !heldBySelf(s) {
+			conflicts = append(conflicts, Conflict{Kind: SlotUnavailable, Resource: name})
+		}
+		sch := findSchedule(cal.Schedules, s.GetSchedule())
+		if sch == nil {
+			continue
+		}
+		schName := "Schedule/" + sch.GetId().GetValue()
+		if sch.GetActive() != nil && !sch.GetActive().GetValue() {
+			conflicts = append(conflicts, Conflict{Kind: ScheduleInactive, Resource: schName})
+		}
+		if h := sch.GetPlanningHorizon(); h != nil {
+			starts, err := fhirtypes.PeriodContains(h, at.start)
+			if err != nil {
+				return nil, fmt.Errorf("%s: planningHorizon: %w", schName, err)
+			}
+			ends, err := fhirtypes.PeriodContains(h, at.end.Add(-time.Nanosecond))
+			if err != nil {
+				return nil, fmt.Errorf("%s: planningHorizon: %w", schName, err)
+			}
+			if !starts || !ends {
+				conflicts = append(conflicts, Conflict{Kind: OutsideHorizon, Resource: schName})
+			}
+		}
+	}
+	if len(appt.GetSlot()) > 0 && !covers(covered, at) {
+		conflicts = append(conflicts, Conflict{Kind: OutsideSlots})
+	}
+
+	for _, actor := range actors(appt) {
+		actorName := fhirtypes.ReferenceURI(actor)
+		for _, s := range cal.Slots {
+			switch s.GetStatus().GetValue() {
+			case c4pb.SlotStatusCode_BUSY, c4pb.SlotStatusCode_BUSY_UNAVAILABLE, c4pb.SlotStatusCode_BUSY_TENTATIVE:
+			default:
+				continue
+			}
+			if refersTo(appt.GetSlot(), "Slot", s.GetId().GetValue()) || heldByAny(s, cal.Appointments) {
+				// Slots held by Appointments are checked as Appointments.
+				continue
+			}
+			sch := findSchedule(cal.Schedules, s.GetSchedule())
+			if sch == nil || !hasActor(sch.GetActor(), actor) {
+				continue
+			}
+			si, err := slotInterval(s)
+			if err != nil {
+				return nil, err
+			}
+			if si.overlaps(at) {
+				conflicts = append(conflicts, Conflict{Kind: ParticipantBusy, Resource: "Slot/" + s.GetId().GetValue(), Actor: actorName})
+			}
+		}
+		for _, o := range others {
+			if !holdsTime(o.GetStatus().GetValue()) || !hasActor(actors(o), actor) {
+				continue
+			}
+			oi, ok, err := instants(o.GetStart(), o.GetEnd())
+			if err != nil {
+				return nil, fmt.Errorf("Appointment/%s: %w", o.GetId().GetValue(), err)
+			}
+			if ok && oi.overlaps(at) {
+				conflicts = append(conflicts, Conflict{Kind: DoubleBooked, Resource: "Appointment/" + o.GetId().GetValue(), Actor: actorName})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// otherAppointments returns the appointments without the id, or all of
+// them if id is "".
+func otherAppointments(id string, appointments []*apb.Appointment) []*apb.Appointment {
+	var out []*apb.Appointment
+	for _, a := range appointments {
+		if id == "" || a.GetId().GetValue() != id {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// heldByAny reports whether any of appointments holds the Slot s.
+func heldByAny(s *slpb.Slot, appointments []*apb.Appointment) bool {
+	for _, a := range appointments {
+		if holdsTime(a.GetStatus().GetValue()) && refersTo(a.GetSlot(), "Slot", s.GetId().GetValue()) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasActor(refs []*d4pb.Reference, actor *d4pb.Reference) bool {
+	for _, r := range refs {
+		if fhirtypes.SameReference(r, actor, "") {
+			return true
+		}
+	}
+	return false
+}
+
+func findSlot(slots []*slpb.Slot, ref *d4pb.Reference) *slpb.Slot {
+	for _, s := range slots {
+		if fhirtypes.SameReference(ref, fhirtypes.Reference("Slot", s.GetId().GetValue()), "") {
+			return s
+		}
+	}
+	return nil
+}
+
+func findSchedule(schedules []*schpb.Schedule, ref *d4pb.Reference) *schpb.Schedule {
+	if ref == nil {
+		return nil
+	}
+	for _, s := range schedules {
+		if fhirtypes.SameReference(ref, fhirtypes.Reference("Schedule", s.GetId().GetValue()), "") {
+			return s
+		}
+	}
+	return nil
+}
+
+// covers reports whether the union of intervals covers i.
+func covers(intervals []interval, i interval) bool {
+	sort.Slice(intervals, func(a, b int) bool { return intervals[a].start.Before(intervals[b].start) })
+	t := i.start
+	for _, j := range intervals {
+		if !t.Before(i.end) {
+			break
+		}
+		if j.start.After(t) {
+			return false
+		}
+		if j.end.After(t) {
+			t = j.end
+		}
+	}
+	return !t.Before(i.end)
+}
+
+// slotStatus returns the status Appointments of status s give the Slots
+// they refer to, and whether they hold them at all. Fulfilled Appointments
+// and no shows keep their Slots busy, as their time has passed.
+func slotStatus(s c4pb.AppointmentStatusCode_Value) (c4pb.SlotStatusCode_Value, bool) {
+	switch s {
+	case c4pb.AppointmentStatusCode_BOOKED, c4pb.AppointmentStatusCode_ARRIVED, c4pb.AppointmentStatusCode_CHECKED_IN,
+		c4pb.AppointmentStatusCode_FULFILLED, c4pb.AppointmentStatusCode_NOSHOW:
+		return c4pb.SlotStatusCode_BUSY, true
+	case c4pb.AppointmentStatusCode_PROPOSED, c4pb.AppointmentStatusCode_PENDING:
+		return c4pb.SlotStatusCode_BUSY_TENTATIVE, true
+	}
+	return 0, false
+}
+
+// UpdateSlots returns updated copies of the Slots of cal whose status
+// changes when appt is written over its earlier version in cal, if any:
+// the Slots appt or its earlier version refer to become busy while a
+// booked, arrived, checked-in, fulfilled or no show Appointment refers to
+// them, busy-tentative while only proposed or pending ones do, and free
+// otherwise. Slots that are busy-unavailable or entered in error are left
+// alone.
+func UpdateSlots(appt *apb.Appointment, cal Calendar) []*slpb.Slot {
+	current := append(otherAppointments(appt.GetId().GetValue(), cal.Appointments), appt)
+	touched := append([]*d4pb.Reference(nil), appt.GetSlot()...)
+	if id := appt.GetId().GetValue(); id != "" {
+		for _, a := range cal.Appointments {
+			if a.GetId().GetValue() == id {
+				touched = append(touched, a.GetSlot()...)
+			}
+		}
+	}
+	var out []*slpb.Slot
+	for _, s := range cal.Slots {
+		if !refersTo(touched, "Slot", s.GetId().GetValue()) {
+			continue
+		}
+		old := s.GetStatus().GetValue()
+		if old == c4pb.SlotStatusCode_BUSY_UNAVAILABLE || old == c4pb.SlotStatusCode_ENTERED_IN_ERROR {
+			continue
+		}
+		status := c4pb.SlotStatusCode_FREE
+		for _, a := range current {
+			st, ok := slotStatus(a.GetStatus().GetValue())
+			if !ok || !refersTo(a.GetSlot(), "Slot", s.GetId().GetValue()) {
+				continue
+			}
+			if st == c4pb.SlotStatusCode_BUSY || status == c4pb.SlotStatusCode_FREE {
+				status = st
+			}
+		}
+		if status != old {
+			u := proto.Clone(s).(*slpb.Slot)
+			u.Status = &slpb.Slot_StatusCode{Value: status}
+			out = append(out, u)
+		}
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appointment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/appointment_go_proto"
+	schpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/schedule_go_proto"
+	slpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/slot_go_proto"
+)
+
+// at returns the instant of the hour and minute on 2024-05-06.
+func at(hour, min int) *d4pb.Instant {
+	return fhirtypes.InstantFromTime(time.Date(2024, 5, 6, hour, min, 0, 0, time.UTC), d4pb.Instant_SECOND)
+}
+
+func slot(id string, hour int, status c4pb.SlotStatusCode_Value) *slpb.Slot {
+	return &slpb.Slot{
+		Id:       fhirtypes.ID(id),
+		Schedule: fhirtypes.Reference("Schedule", "dr-smith"),
+		Status:   &slpb.Slot_StatusCode{Value: status},
+		Start:    at(hour, 0),
+		End:      at(hour, 30),
+	}
+}
+
+func appointment(id string, status c4pb.AppointmentStatusCode_Value, start, end *d4pb.Instant, slots ...string) *apb.Appointment {
+	a := &apb.Appointment{
+		Id:     fhirtypes.ID(id),
+		Status: &apb.Appointment_StatusCode{Value: status},
+		Start:  start,
+		End:    end,
+		Participant: []*apb.Appointment_Participant{
+			{Actor: fhirtypes.Reference("Practitioner", "smith"), Status: &apb.Appointment_Participant_StatusCode{Value: c4pb.ParticipationStatusCode_ACCEPTED}},
+			{Actor: fhirtypes.Reference("Patient", "p"+id), Status: &apb.Appointment_Participant_StatusCode{Value: c4pb.ParticipationStatusCode_ACCEPTED}},
+		},
+	}
+	for _, s := range slots {
+		a.Slot = append(a.Slot, fhirtypes.Reference("Slot", s))
+	}
+	return a
+}
+
+func calendar() Calendar {
+	return Calendar{
+		Schedules: []*schpb.Schedule{{
+			Id:     fhirtypes.ID("dr-smith"),
+			Active: &d4pb.Boolean{Value: true},
+			Actor:  []*d4pb.Reference{fhirtypes.Reference("Practitioner", "smith")},
+			PlanningHorizon: &d4pb.Period{
+				Start: fhirtypes.DateTimeFromTime(time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC), d4pb.DateTime_SECOND),
+				End:   fhirtypes.DateTimeFromTime(time.Date(2024, 5, 6, 17, 0, 0, 0, time.UTC), d4pb.DateTime_SECOND),
+			},
+		}},
+		Slots: []*slpb.Slot{
+			slot("s9", 9, c4pb.SlotStatusCode_FREE),
+			{Id: fhirtypes.ID("s930"), Schedule: fhirtypes.Reference("Schedule", "dr-smith"), Status: &slpb.Slot_StatusCode{Value: c4pb.SlotStatusCode_FREE}, Start: at(9, 30), End: at(10, 0)},
+			slot("s10", 10, c4pb.SlotStatusCode_BUSY),
+			slot("s11", 11, c4pb.SlotStatusCode_BUSY_UNAVAILABLE),
+			slot("s16", 16, c4pb.SlotStatusCode_FREE),
+		},
+		Appointments: []*apb.Appointment{
+			appointment("a10", c4pb.AppointmentStatusCode_BOOKED, at(10, 0), at(10, 30), "s10"),
+			appointment("a12", c4pb.AppointmentStatusCode_CANCELLED, at(12, 0), at(12, 30)),
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	booked := c4pb.AppointmentStatusCode_BOOKED
+	tests := []struct {
+		name string
+		appt *apb.Appointment
+		want []Conflict
+	}{
+		{"free slots", appointment("new", booked, at(9, 0), at(10, 0), "s9", "s930"), nil},
+		{"adjacent to other appointment", appointment("new", booked, at(9, 30), at(10, 0), "s930"), nil},
+		{"over a cancelled appointment", appointment("new", c4pb.AppointmentStatusCode_PROPOSED, at(12, 0), at(12, 30)), nil},
+		{"proposed without time", appointment("new", c4pb.AppointmentStatusCode_PROPOSED, nil, nil), nil},
+		{"rebooking itself", appointment("a10", c4pb.AppointmentStatusCode_ARRIVED, at(10, 0), at(10, 30), "s10"), nil},
+		{
+			"busy slot",
+			appointment("new", booked, at(10, 0), at(10, 30), "s10"),
+			[]Conflict{
+				{Kind: SlotUnavailable, Resource: "Slot/s10"},
+				{Kind: DoubleBooked, Resource: "Appointment/a10", Actor: "Practitioner/smith"},
+			},
+		},
+		{
+			"not covered by slots",
+			appointment("new", booked, at(9, 0), at(9, 45), "s9"),
+			[]Conflict{{Kind: OutsideSlots}},
+		},
+		{
+			"unavailable participant",
+			appointment("new", booked, at(11, 15), at(11, 45)),
+			[]Conflict{{Kind: ParticipantBusy, Resource: "Slot/s11", Actor: "Practitioner/smith"}},
+		},
+		{
+			"unknown slot and beyond horizon",
+			appointment("new", booked, at(16, 0), at(17, 30), "s16", "s99"),
+			[]Conflict{
+				{Kind: OutsideHorizon, Resource: "Schedule/dr-smith"},
+				{Kind: UnknownSlot, Resource: "Slot/s99"},
+				{Kind: OutsideSlots},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Check(tc.appt, calendar())
+			if err != nil {
+				t.Fatalf("Check() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Check() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheck_DeclinedParticipant(t *testing.T) {
+	appt := appointment("new", c4pb.AppointmentStatusCode_BOOKED, at(10, 0), at(10, 30))
+	appt.Participant[0].Status = &apb.Appointment_Participant_StatusCode{Value: c4pb.ParticipationStatusCode_DECLINED}
+	got, err := Check(appt, calendar())
+	if err != nil {
+		t.Fatalf("Check() returned unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Check() = %v, want no conflicts for a declined participant", got)
+	}
+}
+
+func TestCheck_Errors(t *testing.T) {
+	for _, appt := range []*apb.Appointment{
+		appointment("new", c4pb.AppointmentStatusCode_BOOKED, nil, nil),
+		appointment("new", c4pb.AppointmentStatusCode_BOOKED, at(10, 0), at(9, 0)),
+	} {
+		if _, err := Check(appt, calendar()); err == nil {
+			t.Errorf("Check(%v) succeeded, want error", appt)
+		}
+	}
+}
+
+func TestUpdateSlots(t *testing.T) {
+	status := func(s c4pb.SlotStatusCode_Value) *slpb.Slot_StatusCode { return &slpb.Slot_StatusCode{Value: s} }
+	tests := []struct {
+		name string
+		appt *apb.Appointment
+		want map[string]c4pb.SlotStatusCode_Value
+	}{
+		{
+			"booking",
+			appointment("new", c4pb.AppointmentStatusCode_BOOKED, at(9, 0), at(10, 0), "s9", "s930"),
+			map[string]c4pb.SlotStatusCode_Value{"s9": c4pb.SlotStatusCode_BUSY, "s930": c4pb.SlotStatusCode_BUSY},
+		},
+		{
+			"pending",
+			appointment("new", c4pb.AppointmentStatusCode_PENDING, at(9, 0), at(9, 30), "s9"),
+			map[string]c4pb.SlotStatusCode_Value{"s9": c4pb.SlotStatusCode_BUSY_TENTATIVE},
+		},
+		{
+			"cancelling",
+			appointment("a10", c4pb.AppointmentStatusCode_CANCELLED, at(10, 0), at(10, 30), "s10"),
+			map[string]c4pb.SlotStatusCode_Value{"s10": c4pb.SlotStatusCode_FREE},
+		},
+		{
+			"moving",
+			appointment("a10", c4pb.AppointmentStatusCode_BOOKED, at(9, 0), at(9, 30), "s9"),
+			map[string]c4pb.SlotStatusCode_Value{"s9": c4pb.SlotStatusCode_BUSY, "s10": c4pb.SlotStatusCode_FREE},
+		},
+		{"unchanged", appointment("a10", c4pb.AppointmentStatusCode_ARRIVED, at(10, 0), at(10, 30), "s10"), nil},
+		{"unavailable slot", appointment("new", c4pb.AppointmentStatusCode_BOOKED, at(11, 0), at(11, 30), "s11"), nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cal := calendar()
+			var want []*slpb.Slot
+			for _, s := range cal.Slots {
+				if st, ok := tc.want[s.GetId().GetValue()]; ok {
+					u := proto.Clone(s).(*slpb.Slot)
+					u.Status = status(st)
+					want = append(want, u)
+				}
+			}
+			got := UpdateSlots(tc.appt, cal)
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("UpdateSlots() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package attachment moves the data of the Attachments of R4 resources
+// between the resources and Binary resources: Externalize moves large
+// inline data out to Binaries, and Inline brings the data of referenced
+// Binaries back in. NewDocument and CreateDocument make DocumentReferences
+// to raw files, Fetch and Verify read their content back, checking it
+// against its size and hash, and Supersede replaces a prior document.
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// Store creates and reads Binary resources.
+type Store interface {
+	// Create creates a Binary of the given content type with the data read
+	// from r and returns its URL, i.e. "Binary/123".
+	Create(ctx context.Context, contentType string, r io.Reader) (string, error)
+	// Open returns the data of the Binary at url, a URL returned by Create
+	// or found in an Attachment. contentType is the content type the
+	// Attachment declares, if any.
+	Open(ctx context.Context, url, contentType string) (io.ReadCloser, error)
+}
+
+// Externalize moves the inline data of the Attachments of res that is
+// larger than threshold bytes to Binaries created in store. The Attachments
+// then refer to the Binaries by url, with the size and SHA-1 hash of the
+// data.
+func Externalize(ctx context.Context, res proto.Message, store Store, threshold int) error {
+	return walk(res.ProtoReflect(), func(a *d4pb.Attachment) error {
+		data := a.GetData().GetValue()
+		if len(data) <= threshold {
+			return nil
+		}
+		if len(data) > math.MaxUint32 {
+			return fmt.Errorf("attachment of %d bytes is too large for its size", len(data))
+		}
+		sum := sha1.Sum(data)
+		if a.GetHash() != nil && !bytes.Equal(a.GetHash().GetValue(), sum[:]) {
+			return fmt.Errorf("attachment data does not match its hash")
+		}
+		url, err := store.Create(ctx, a.GetContentType().GetValue(), bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("creating Binary: %w", err)
+		}
+		a.Data = nil
+		a.Url = &d4pb.Url{Value: url}
+		a.Size = &d4pb.UnsignedInt{Value: uint32(len(data))}
+		a.Hash = &d4pb.Base64Binary{Value: sum[:]}
+		return nil
+	})
+}
+
+// Inline copies the data of the Binaries the Attachments of res refer to
+// into the Attachments, for Binaries of at most max bytes. Attachments
+// declaring a larger size are left alone without reading their Binary. The
+// data is checked against the size and hash of the Attachments, which are
+// set if they are missing.
+func Inline(ctx context.Context, res proto.Message, store Store, max int64) error {
+	return walk(res.ProtoReflect(), func(a *d4pb.Attachment) error {
+		url := a.GetUrl().GetValue()
+		if a.GetData() != nil || !isBinary(url) {
+			return nil
+		}
+		if a.GetSize() != nil && int64(a.GetSize().GetValue()) > max {
+			return nil
+		}
+		rc, err := store.Open(ctx, url, a.GetContentType().GetValue())
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", url, err)
+		}
+		defer rc.Close()
+		h := sha1.New()
+		data, err := io.ReadAll(io.TeeReader(io.LimitReader(rc, max+1), h))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", url, err)
+		}
+		if int64(len(data)) > max {
+			return nil
+		}
+		sum := h.Sum(nil)
+		if a.GetHash() != nil && !bytes.Equal(a.GetHash().GetValue(), sum) {
+			return fmt.Errorf("data of %s does not match the attachment hash", url)
+		}
+		if a.GetSize() != nil && int64(a.GetSize().GetValue()) != int64(len(data)) {
+			return fmt.Errorf("data of %s is %d bytes, the attachment declares %d", url, len(data), a.GetSize().GetValue())
+		}
+		a.Data = &d4pb.Base64Binary{Value: data}
+		a.Size = &d4pb.UnsignedInt{Value: uint32(len(data))}
+		a.Hash = &d4pb.Base64Binary{Value: sum}
+		return nil
+	})
+}
+
+// isBinary returns whether url refers to a Binary resource, relatively or
+// absolutely.
+func isBinary(url string) bool {
+	url = strings.TrimSuffix(url, "/")
+	if i := strings.Index(url, "/_history/"); i >= 0 {
+		url = url[:i]
+	}
+	segs := strings.Split(url, "/")
+	return len(segs) >= 2 && segs[len(segs)-2] == "Binary" && segs[len(segs)-1] != ""
+}
+
+var attachmentName = (&d4pb.Attachment{}).ProtoReflect().Descriptor().FullName()
+
+// walk calls f with the Attachments of m, including those of the resources
+// m contains, which are repacked after f.
+func walk(m protoreflect.Message, f func(*d4pb.Attachment) error) error {
+	if m.Descriptor().FullName() == attachmentName {
+		return f(m.Interface().(*d4pb.Attachment))
+	}
+	if a, ok := m.Interface().(*anypb.Any); ok {
+		res, err := a.UnmarshalNew()
+		if err != nil {
+			return fmt.Errorf("unpacking contained resource: %w", err)
+		}
+		if err := walk(res.ProtoReflect(), f); err != nil {
+			return err
+		}
+		return a.MarshalFrom(res)
+	}
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil || fd.IsMap() {
+			return true
+		}
+		if fd.IsList() {
+			for i := 0; i < v.List().Len() && err == nil; i++ {
+				err = walk(v.List().Get(i).Message(), f)
+			}
+		} else {
+			err = walk(v.Message(), f)
+		}
+		return err == nil
+	})
+	return err
+}
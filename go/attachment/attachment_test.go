@@ -0,0 +1,227 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+type memoryStore struct {
+	binaries map[string][]byte
+	types    map[string]string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{binaries: map[string][]byte{}, types: map[string]string{}}
+}
+
+func (s *memoryStore) Create(_ context.Context, contentType string, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("Binary/%d", len(s.binaries)+1)
+	s.binaries[url] = data
+	s.types[url] = contentType
+	return url, nil
+}
+
+func (s *memoryStore) Open(_ context.Context, url, _ string) (io.ReadCloser, error) {
+	data, ok := s.binaries[url]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", url)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func photo(data []byte) *d4pb.Attachment {
+	return &d4pb.Attachment{
+		ContentType: &d4pb.Attachment_ContentTypeCode{Value: "image/png"},
+		Data:        &d4pb.Base64Binary{Value: data},
+	}
+}
+
+func hash(data []byte) *d4pb.Base64Binary {
+	sum := sha1.Sum(data)
+	return &d4pb.Base64Binary{Value: sum[:]}
+}
+
+func TestExternalize(t *testing.T) {
+	small, large := []byte("tiny"), bytes.Repeat([]byte{7}, 100)
+	p := &ppb.Patient{Photo: []*d4pb.Attachment{photo(small), photo(large)}}
+	store := newMemoryStore()
+	if err := Externalize(context.Background(), p, store, 10); err != nil {
+		t.Fatalf("Externalize() returned unexpected error: %v", err)
+	}
+	want := &ppb.Patient{Photo: []*d4pb.Attachment{
+		photo(small),
+		{
+			ContentType: &d4pb.Attachment_ContentTypeCode{Value: "image/png"},
+			Url:         &d4pb.Url{Value: "Binary/1"},
+			Size:        &d4pb.UnsignedInt{Value: 100},
+			Hash:        hash(large),
+		},
+	}}
+	if diff := cmp.Diff(want, p, protocmp.Transform()); diff != "" {
+		t.Errorf("Externalize() diff (-want +got):\n%s", diff)
+	}
+	if !bytes.Equal(store.binaries["Binary/1"], large) || store.types["Binary/1"] != "image/png" {
+		t.Errorf("Externalize() created Binary %q of type %q, want the photo data", store.binaries["Binary/1"], store.types["Binary/1"])
+	}
+
+	// Inlining restores the data, keeping the url, size and hash.
+	if err := Inline(context.Background(), p, store, 1000); err != nil {
+		t.Fatalf("Inline() returned unexpected error: %v", err)
+	}
+	want.Photo[1].Data = &d4pb.Base64Binary{Value: large}
+	if diff := cmp.Diff(want, p, protocmp.Transform()); diff != "" {
+		t.Errorf("Inline() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestExternalize_Contained(t *testing.T) {
+	data := bytes.Repeat([]byte{1}, 20)
+	contained, err := anypb.New(&r4pb.ContainedResource{
+		OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{Photo: []*d4pb.Attachment{photo(data)}}},
+	})
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+	obs := &obspb.Observation{Contained: []*anypb.Any{contained}}
+	if err := Externalize(context.Background(), obs, newMemoryStore(), 10); err != nil {
+		t.Fatalf("Externalize() returned unexpected error: %v", err)
+	}
+	cr := &r4pb.ContainedResource{}
+	if err := obs.GetContained()[0].UnmarshalTo(cr); err != nil {
+		t.Fatalf("UnmarshalTo() returned unexpected error: %v", err)
+	}
+	if got := cr.GetPatient().GetPhoto()[0].GetUrl().GetValue(); got != "Binary/1" {
+		t.Errorf("Externalize() set contained attachment url %q, want Binary/1", got)
+	}
+}
+
+func TestInline(t *testing.T) {
+	store := newMemoryStore()
+	data := []byte("0123456789")
+	url, err := store.Create(context.Background(), "text/plain", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Create() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		name    string
+		a       *d4pb.Attachment
+		max     int64
+		want    *d4pb.Attachment
+		wantErr bool
+	}{
+		{
+			name: "inlined",
+			a:    &d4pb.Attachment{Url: &d4pb.Url{Value: url}},
+			max:  10,
+			want: &d4pb.Attachment{
+				Url:  &d4pb.Url{Value: url},
+				Data: &d4pb.Base64Binary{Value: data},
+				Size: &d4pb.UnsignedInt{Value: 10},
+				Hash: hash(data),
+			},
+		},
+		{
+			name: "too large",
+			a:    &d4pb.Attachment{Url: &d4pb.Url{Value: url}},
+			max:  9,
+			want: &d4pb.Attachment{Url: &d4pb.Url{Value: url}},
+		},
+		{
+			name: "declared too large",
+			a:    &d4pb.Attachment{Url: &d4pb.Url{Value: "Binary/missing"}, Size: &d4pb.UnsignedInt{Value: 11}},
+			max:  10,
+			want: &d4pb.Attachment{Url: &d4pb.Url{Value: "Binary/missing"}, Size: &d4pb.UnsignedInt{Value: 11}},
+		},
+		{
+			name: "not a Binary",
+			a:    &d4pb.Attachment{Url: &d4pb.Url{Value: "https://example.com/scan.pdf"}},
+			max:  10,
+			want: &d4pb.Attachment{Url: &d4pb.Url{Value: "https://example.com/scan.pdf"}},
+		},
+		{
+			name:    "hash mismatch",
+			a:       &d4pb.Attachment{Url: &d4pb.Url{Value: url}, Hash: hash([]byte("other"))},
+			max:     10,
+			wantErr: true,
+		},
+		{
+			name:    "size mismatch",
+			a:       &d4pb.Attachment{Url: &d4pb.Url{Value: url}, Size: &d4pb.UnsignedInt{Value: 4}},
+			max:     10,
+			wantErr: true,
+		},
+		{
+			name:    "missing",
+			a:       &d4pb.Attachment{Url: &d4pb.Url{Value: "Binary/missing"}},
+			max:     10,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &ppb.Patient{Photo: []*d4pb.Attachment{test.a}}
+			err := Inline(context.Background(), p, store, test.max)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Inline() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Inline() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, p.GetPhoto()[0], protocmp.Transform()); diff != "" {
+				t.Errorf("Inline() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"Binary/1", true},
+		{"https://example.com/fhir/Binary/1/_history/2", true},
+		{"Binary/", false},
+		{"Patient/1", false},
+		{"https://example.com/scan.pdf", false},
+	}
+	for _, test := range tests {
+		if got := isBinary(test.url); got != test.want {
+			t.Errorf("isBinary(%q) = %v, want %v", test.url, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/codes"
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/binary_go_proto"
+	drpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/document_reference_go_proto"
+)
+
+// ErrContentMismatch is returned for content that does not match the size
+// or hash its Attachment declares.
+var ErrContentMismatch = errors.New("content does not match attachment")
+
+// DocumentOptions are the optional elements of the DocumentReferences
+// NewDocument and CreateDocument make.
+type DocumentOptions struct {
+	// ContentType overrides the content type otherwise detected from the
+	// file name extension, or else from the data.
+	ContentType string
+	// Title is the title of the Attachment; the base of the file name is
+	// used if it is empty.
+	Title string
+	// Type, Category and Subject are copied to the DocumentReference.
+	Type     *d4pb.CodeableConcept
+	Category []*d4pb.CodeableConcept
+	Subject  *d4pb.Reference
+	// Date is the time the DocumentReference is made, and Created the time
+	// the file was authored; they are left unset if zero.
+	Date, Created time.Time
+}
+
+// DetectContentType returns the content type of the file name with data:
+// the type registered for its extension, or else the type sniffed from the
+// data as by http.DetectContentType.
+func DetectContentType(name string, data []byte) string {
+	if ext := filepath.Ext(name); ext != "" {
+		if t := mime.TypeByExtension(strings.ToLower(ext)); t != "" {
+			return t
+		}
+	}
+	return http.DetectContentType(data)
+}
+
+// NewDocument returns a current DocumentReference to the file name with
+// data and the Binary holding the data, for services that write both
+// themselves, as in a transaction Bundle. The Attachment refers to the
+// Binary by url, i.e. "Binary/123" or the urn:uuid: full URL of its Bundle
+// entry, and carries the size and SHA-1 hash of the data. The Binary has
+// the id of url if it is relative.
+func NewDocument(name string, data []byte, url string, opts DocumentOptions) (*drpb.DocumentReference, *bpb.Binary, error) {
+	if url == "" {
+		return nil, nil, fmt.Errorf("no url for the Binary of %s", name)
+	}
+	doc, err := newDocument(name, data, url, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	bin := &bpb.Binary{
+		ContentType: &bpb.Binary_ContentTypeCode{Value: doc.GetContent()[0].GetAttachment().GetContentType().GetValue()},
+		Data:        &d4pb.Base64Binary{Value: data},
+	}
+	if p, err := fhirtypes.ParseReference(url); err == nil && p.Type == "Binary" && p.Base == "" {
+		bin.Id = fhirtypes.ID(p.ID)
+	}
+	return doc, bin, nil
+}
+
+// CreateDocument creates a Binary with the data of the file name in store
+// and returns a current DocumentReference to it, as NewDocument.
+func CreateDocument(ctx context.Context, store Store, name string, data []byte, opts DocumentOptions) (*drpb.DocumentReference, error) {
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = DetectContentType(name, data)
+	}
+	url, err := store.Create(ctx, contentType, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("creating Binary: %w", err)
+	}
+	opts.ContentType = contentType
+	return newDocument(name, data, url, opts)
+}
+
+func newDocument(name string, data []byte, url string, opts DocumentOptions) (*drpb.DocumentReference, error) {
+	if len(data) > math.MaxUint32 {
+		return nil, fmt.Errorf("%s of %d bytes is too large for its size", name, len(data))
+	}
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = DetectContentType(name, data)
+	}
+	title := opts.Title
+	if title == "" {
+		title = filepath.Base(name)
+	}
+	sum := sha1.Sum(data)
+	a := &d4pb.Attachment{
+		ContentType: &d4pb.Attachment_ContentTypeCode{Value: contentType},
+		Url:         &d4pb.Url{Value: url},
+		Size:        &d4pb.UnsignedInt{Value: uint32(len(data))},
+		Hash:        &d4pb.Base64Binary{Value: sum[:]},
+		Title:       fhirtypes.String(title),
+	}
+	if !opts.Created.IsZero() {
+		a.Creation = fhirtypes.DateTimeFromTime(opts.Created, d4pb.DateTime_SECOND)
+	}
+	doc := &drpb.DocumentReference{
+		Status:   &drpb.DocumentReference_StatusCode{Value: c4pb.DocumentReferenceStatusCode_CURRENT},
+		Type:     opts.Type,
+		Category: opts.Category,
+		Subject:  opts.Subject,
+		Content:  []*drpb.DocumentReference_Content{{Attachment: a}},
+	}
+	if !opts.Date.IsZero() {
+		doc.Date = fhirtypes.InstantFromTime(opts.Date, d4pb.Instant_SECOND)
+	}
+	return doc, nil
+}
+
+// Fetch returns the data of the Attachment a, inline or read from the
+// Binary or other URL it refers to, of at most max bytes. The data is
+// checked against the size and hash of a, failing with ErrContentMismatch
+// if they differ.
+func Fetch(ctx context.Context, a *d4pb.Attachment, store Store, max int64) ([]byte, error) {
+	data := a.GetData().GetValue()
+	if a.GetData() == nil {
+		url := a.GetUrl().GetValue()
+		if url == "" {
+			return nil, fmt.Errorf("attachment has neither data nor url")
+		}
+		if a.GetSize() != nil && int64(a.GetSize().GetValue()) > max {
+			return nil, fmt.Errorf("%s is %d bytes, more than %d", url, a.GetSize().GetValue(), max)
+		}
+		rc, err := store.Open(ctx, url, a.GetContentType().GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", url, err)
+		}
+		defer rc.Close()
+		if data, err = io.ReadAll(io.LimitReader(rc, max+1)); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", url, err)
+		}
+		if int64(len(data)) > max {
+			return nil, fmt.Errorf("%s is more than %d bytes", url, max)
+		}
+	}
+	if a.GetSize() != nil && int64(a.GetSize().GetValue()) != int64(len(data)) {
+		return nil, fmt.Errorf("%w: %d bytes, the attachment declares %d", ErrContentMismatch, len(data), a.GetSize().GetValue())
+	}
+	if sum := sha1.Sum(data); a.GetHash() != nil && !bytes.Equal(a.GetHash().GetValue(), sum[:]) {
+		return nil, fmt.Errorf("%w: hash differs", ErrContentMismatch)
+	}
+	return data, nil
+}
+
+// Verify fetches the content of doc, as by Fetch, and checks it against the
+// size and hash of its Attachments.
+func Verify(ctx context.Context, doc *drpb.DocumentReference, store Store, max int64) error {
+	if len(doc.GetContent()) == 0 {
+		return fmt.Errorf("DocumentReference has no content")
+	}
+	for i, c := range doc.GetContent() {
+		if _, err := Fetch(ctx, c.GetAttachment(), store, max); err != nil {
+			return fmt.Errorf("content[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Supersede marks doc as replacing old: doc gains a relatesTo of code
+// replaces targeting old, which must have an id, and old becomes
+// superseded. Documents that are already superseded or entered in error
+// cannot be replaced.
+func Supersede(doc, old *drpb.DocumentReference) error {
+	id := old.GetId().GetValue()
+	if id == "" {
+		return fmt.Errorf("superseded DocumentReference has no id")
+	}
+	if id == doc.GetId().GetValue() {
+		return fmt.Errorf("DocumentReference/%s cannot supersede itself", id)
+	}
+	switch s := old.GetStatus().GetValue(); s {
+	case c4pb.DocumentReferenceStatusCode_SUPERSEDED, c4pb.DocumentReferenceStatusCode_ENTERED_IN_ERROR:
+		return fmt.Errorf("DocumentReference/%s is %s", id, codes.Code(s))
+	}
+	target := fhirtypes.Reference("DocumentReference", id)
+	if !replaces(doc, target) {
+		doc.RelatesTo = append(doc.RelatesTo, &drpb.DocumentReference_RelatesTo{
+			Code:   &drpb.DocumentReference_RelatesTo_CodeType{Value: c4pb.DocumentRelationshipTypeCode_REPLACES},
+			Target: target,
+		})
+	}
+	old.Status = &drpb.DocumentReference_StatusCode{Value: c4pb.DocumentReferenceStatusCode_SUPERSEDED}
+	return nil
+}
+
+// replaces reports whether doc already has a relatesTo of code replaces
+// targeting target.
+func replaces(doc *drpb.DocumentReference, target *d4pb.Reference) bool {
+	for _, r := range doc.GetRelatesTo() {
+		if r.GetCode().GetValue() == c4pb.DocumentRelationshipTypeCode_REPLACES && fhirtypes.SameReference(r.GetTarget(), target, "") {
+			return true
+		}
+	}
+	return false
+}
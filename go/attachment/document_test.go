@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attachment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/binary_go_proto"
+	drpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/document_reference_go_proto"
+)
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"report.PDF", nil, "application/pdf"},
+		{"scan", []byte("\x89PNG\r\n\x1a\n"), "image/png"},
+		{"notes", []byte("plain text"), "text/plain; charset=utf-8"},
+	}
+	for _, tc := range tests {
+		if got := DetectContentType(tc.name, tc.data); got != tc.want {
+			t.Errorf("DetectContentType(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	data := []byte("\x89PNG\r\n\x1a\nimage")
+	date := time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)
+	doc, bin, err := NewDocument("scans/xray", data, "Binary/b1", DocumentOptions{
+		Subject: fhirtypes.Reference("Patient", "p1"),
+		Date:    date,
+	})
+	if err != nil {
+		t.Fatalf("NewDocument() returned unexpected error: %v", err)
+	}
+	wantDoc := &drpb.DocumentReference{
+		Status:  &drpb.DocumentReference_StatusCode{Value: c4pb.DocumentReferenceStatusCode_CURRENT},
+		Subject: fhirtypes.Reference("Patient", "p1"),
+		Date:    fhirtypes.InstantFromTime(date, d4pb.Instant_SECOND),
+		Content: []*drpb.DocumentReference_Content{{Attachment: &d4pb.Attachment{
+			ContentType: &d4pb.Attachment_ContentTypeCode{Value: "image/png"},
+			Url:         &d4pb.Url{Value: "Binary/b1"},
+			Size:        &d4pb.UnsignedInt{Value: uint32(len(data))},
+			Hash:        hash(data),
+			Title:       fhirtypes.String("xray"),
+		}}},
+	}
+	if diff := cmp.Diff(wantDoc, doc, protocmp.Transform()); diff != "" {
+		t.Errorf("NewDocument() DocumentReference diff (-want +got):\n%s", diff)
+	}
+	wantBin := &bpb.Binary{
+		Id:          fhirtypes.ID("b1"),
+		ContentType: &bpb.Binary_ContentTypeCode{Value: "image/png"},
+		Data:        &d4pb.Base64Binary{Value: data},
+	}
+	if diff := cmp.Diff(wantBin, bin, protocmp.Transform()); diff != "" {
+		t.Errorf("NewDocument() Binary diff (-want +got):\n%s", diff)
+	}
+
+	_, bin, err = NewDocument("a.txt", data, "urn:uuid:0b5ebd4e-3a4f-4b8e-9a4e-6c1d3b1f7a10", DocumentOptions{})
+	if err != nil {
+		t.Fatalf("NewDocument() returned unexpected error: %v", err)
+	}
+	if bin.GetId() != nil {
+		t.Errorf("NewDocument() Binary id = %v, want none for a urn:uuid: url", bin.GetId())
+	}
+	if _, _, err := NewDocument("a.txt", data, "", DocumentOptions{}); err == nil {
+		t.Errorf("NewDocument() without url succeeded, want error")
+	}
+}
+
+func TestCreateAndVerify(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	data := []byte("discharge summary")
+	doc, err := CreateDocument(ctx, store, "summary.txt", data, DocumentOptions{})
+	if err != nil {
+		t.Fatalf("CreateDocument() returned unexpected error: %v", err)
+	}
+	a := doc.GetContent()[0].GetAttachment()
+	if got, want := a.GetUrl().GetValue(), "Binary/1"; got != want {
+		t.Errorf("CreateDocument() url = %q, want %q", got, want)
+	}
+	if got, want := store.types["Binary/1"], "text/plain; charset=utf-8"; got != want {
+		t.Errorf("CreateDocument() created Binary of type %q, want %q", got, want)
+	}
+	got, err := Fetch(ctx, a, store, 1024)
+	if err != nil {
+		t.Fatalf("Fetch() returned unexpected error: %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("Fetch() = %q, want %q", got, data)
+	}
+	if err := Verify(ctx, doc, store, 1024); err != nil {
+		t.Errorf("Verify() returned unexpected error: %v", err)
+	}
+	if _, err := Fetch(ctx, a, store, 4); err == nil {
+		t.Errorf("Fetch() of more than max bytes succeeded, want error")
+	}
+
+	store.binaries["Binary/1"] = []byte("discharge summarY")
+	if err := Verify(ctx, doc, store, 1024); !errors.Is(err, ErrContentMismatch) {
+		t.Errorf("Verify() of altered content returned error %v, want %v", err, ErrContentMismatch)
+	}
+	inline := &d4pb.Attachment{Data: &d4pb.Base64Binary{Value: data}, Size: &d4pb.UnsignedInt{Value: 3}}
+	if _, err := Fetch(ctx, inline, store, 1024); !errors.Is(err, ErrContentMismatch) {
+		t.Errorf("Fetch() of inline data of the wrong size returned error %v, want %v", err, ErrContentMismatch)
+	}
+}
+
+func TestSupersede(t *testing.T) {
+	current := &drpb.DocumentReference_StatusCode{Value: c4pb.DocumentReferenceStatusCode_CURRENT}
+	old := &drpb.DocumentReference{Id: fhirtypes.ID("d1"), Status: current}
+	doc := &drpb.DocumentReference{Id: fhirtypes.ID("d2"), Status: current}
+	for i := 0; i < 2; i++ {
+		old.Status = current
+		if err := Supersede(doc, old); err != nil {
+			t.Fatalf("Supersede() returned unexpected error: %v", err)
+		}
+	}
+	want := []*drpb.DocumentReference_RelatesTo{{
+		Code:   &drpb.DocumentReference_RelatesTo_CodeType{Value: c4pb.DocumentRelationshipTypeCode_REPLACES},
+		Target: fhirtypes.Reference("DocumentReference", "d1"),
+	}}
+	if diff := cmp.Diff(want, doc.GetRelatesTo(), protocmp.Transform()); diff != "" {
+		t.Errorf("Supersede() relatesTo diff (-want +got):\n%s", diff)
+	}
+	if got := old.GetStatus().GetValue(); got != c4pb.DocumentReferenceStatusCode_SUPERSEDED {
+		t.Errorf("Supersede() old status = %v, want %v", got, c4pb.DocumentReferenceStatusCode_SUPERSEDED)
+	}
+	if err := Supersede(&drpb.DocumentReference{}, old); err == nil {
+		t.Errorf("Supersede() of a superseded document succeeded, want error")
+	}
+	if err := Supersede(doc, &drpb.DocumentReference{Status: current}); err == nil {
+		t.Errorf("Supersede() of a document without id succeeded, want error")
+	}
+}
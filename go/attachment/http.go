@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+
+	bpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/binary_go_proto"
+)
+
+// maxResource bounds the size of the Binary resources HTTPStore reads as
+// FHIR JSON rather than as raw data.
+const maxResource = 64 << 20
+
+var unmarshaller *jsonformat.Unmarshaller
+
+func init() {
+	var err error
+	if unmarshaller, err = jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("attachment: creating unmarshaller: %v", err))
+	}
+}
+
+// HTTPStore is a Store of the Binaries of a FHIR server, which it creates
+// and reads as raw data.
+type HTTPStore struct {
+	// Client sends the requests; http.DefaultClient is used if it is nil.
+	Client *http.Client
+	// Base is the FHIR base URL of the server, i.e.
+	// "https://example.com/fhir".
+	Base string
+}
+
+// Create creates a Binary with a POST of the raw data, and returns the
+// relative URL of the Binary the server responds with.
+func (s *HTTPStore) Create(ctx context.Context, contentType string, r io.Reader) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.base()+"/Binary", r)
+	if err != nil {
+		return "", err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("creating Binary: %s", resp.Status)
+	}
+	loc := resp.Header.Get("Location")
+	if i := strings.Index(loc, "/_history/"); i >= 0 {
+		loc = loc[:i]
+	}
+	loc = strings.TrimPrefix(loc, s.base()+"/")
+	if !isBinary(loc) {
+		return "", fmt.Errorf("creating Binary: unexpected location %q", resp.Header.Get("Location"))
+	}
+	return loc, nil
+}
+
+// Open reads the Binary at url, relative to Base unless it is absolute,
+// asking for its raw data. Absolute URLs must be under Base: the URLs of
+// attachments come with the data, and following them elsewhere would send
+// requests to any server they name. Servers responding with the Binary
+// resource in FHIR JSON are supported too.
+func (s *HTTPStore) Open(ctx context.Context, url, contentType string) (io.ReadCloser, error) {
+	switch {
+	case !strings.Contains(url, "://"):
+		url = s.base() + "/" + url
+	case !strings.HasPrefix(url, s.base()+"/"):
+		return nil, fmt.Errorf("reading Binary: %s is not on the server %s", url, s.base())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Accept", contentType)
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("reading Binary: %s", resp.Status)
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "application/fhir+json" || contentType == mt {
+		return resp.Body, nil
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResource))
+	if err != nil {
+		return nil, err
+	}
+	res, err := unmarshaller.Unmarshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("reading Binary: %w", err)
+	}
+	b, ok := elementpath.Unwrap(res).(*bpb.Binary)
+	if !ok {
+		return nil, fmt.Errorf("reading Binary: got a %s", elementpath.ResourceType(res))
+	}
+	return io.NopCloser(bytes.NewReader(b.GetData().GetValue())), nil
+}
+
+func (s *HTTPStore) base() string {
+	return strings.TrimSuffix(s.Base, "/")
+}
+
+func (s *HTTPStore) client() *http.Client {
+	if s.Client == nil {
+		return http.DefaultClient
+	}
+	return s.Client
+}
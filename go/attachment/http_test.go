@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attachment
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPStore(t *testing.T) {
+	var stored []byte
+	var storedType string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fhir/Binary", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "", http.StatusMethodNotAllowed)
+			return
+		}
+		stored, _ = io.ReadAll(r.Body)
+		storedType = r.Header.Get("Content-Type")
+		w.Header().Set("Location", "http://"+r.Host+"/fhir/Binary/b1/_history/1")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/fhir/Binary/b1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", storedType)
+		w.Write(stored)
+	})
+	mux.HandleFunc("/fhir/Binary/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/fhir+json")
+		fmt.Fprintf(w, `{"resourceType":"Binary","contentType":"text/plain","data":%q}`, base64.StdEncoding.EncodeToString([]byte("from json")))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+	store := &HTTPStore{Base: s.URL + "/fhir/"}
+	ctx := context.Background()
+
+	url, err := store.Create(ctx, "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Create() returned unexpected error: %v", err)
+	}
+	if url != "Binary/b1" || string(stored) != "hello" || storedType != "text/plain" {
+		t.Errorf("Create() = %q, stored %q of type %q, want Binary/b1 storing hello of type text/plain", url, stored, storedType)
+	}
+
+	for _, test := range []struct {
+		url  string
+		want string
+	}{
+		{"Binary/b1", "hello"},
+		{s.URL + "/fhir/Binary/b1", "hello"},
+		{"Binary/json", "from json"},
+	} {
+		rc, err := store.Open(ctx, test.url, "text/plain")
+		if err != nil {
+			t.Fatalf("Open(%q) returned unexpected error: %v", test.url, err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("reading %q returned unexpected error: %v", test.url, err)
+		}
+		if string(got) != test.want {
+			t.Errorf("Open(%q) read %q, want %q", test.url, got, test.want)
+		}
+	}
+
+	if _, err := store.Open(ctx, "Binary/missing", ""); err == nil {
+		t.Errorf("Open(Binary/missing) succeeded, want error")
+	}
+
+	requested := false
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer other.Close()
+	for _, url := range []string{other.URL + "/fhir/Binary/b1", s.URL + "/fhirx/Binary/b1", s.URL + "/fhir"} {
+		if _, err := store.Open(ctx, url, ""); err == nil {
+			t.Errorf("Open(%q) succeeded, want error", url)
+		}
+	}
+	if requested {
+		t.Errorf("Open() sent a request to a server other than %s", store.Base)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit builds R4 AuditEvents from a compact description of what
+// happened, and records the reads of sensitive compartments that go
+// through net/http servers and clients.
+package audit
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	aepb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/audit_event_go_proto"
+)
+
+const (
+	auditEventTypeSystem     = "http://terminology.hl7.org/CodeSystem/audit-event-type"
+	restfulInteractionSystem = "http://hl7.org/fhir/restful-interaction"
+	entityTypeSystem         = "http://terminology.hl7.org/CodeSystem/audit-entity-type"
+	objectRoleSystem         = "http://terminology.hl7.org/CodeSystem/object-role"
+)
+
+// Action is what was done, as the AuditEvent action codes it.
+type Action string
+
+// Actions.
+const (
+	Create  Action = "C"
+	Read    Action = "R"
+	Update  Action = "U"
+	Delete  Action = "D"
+	Execute Action = "E"
+)
+
+var actionCodes = map[Action]c4pb.AuditEventActionCode_Value{
+	Create:  c4pb.AuditEventActionCode_C,
+	Read:    c4pb.AuditEventActionCode_R,
+	Update:  c4pb.AuditEventActionCode_U,
+	Delete:  c4pb.AuditEventActionCode_D,
+	Execute: c4pb.AuditEventActionCode_E,
+}
+
+// interactionActions are the actions of the FHIR RESTful interactions.
+var interactionActions = map[string]Action{
+	"read":           Read,
+	"vread":          Read,
+	"history":        Read,
+	"search":         Execute,
+	"search-type":    Execute,
+	"search-system":  Execute,
+	"create":         Create,
+	"update":         Update,
+	"patch":          Update,
+	"delete":         Delete,
+	"operation":      Execute,
+	"transaction":    Execute,
+	"batch":          Execute,
+	"capabilities":   Read,
+	"history-type":   Read,
+	"history-system": Read,
+}
+
+// Outcome is whether the action succeeded.
+type Outcome int
+
+// Outcomes.
+const (
+	Success Outcome = iota
+	MinorFailure
+	SeriousFailure
+	MajorFailure
+)
+
+var outcomeCodes = map[Outcome]c4pb.AuditEventOutcomeCode_Value{
+	Success:        c4pb.AuditEventOutcomeCode_SUCCESS,
+	MinorFailure:   c4pb.AuditEventOutcomeCode_MINOR_FAILURE,
+	SeriousFailure: c4pb.AuditEventOutcomeCode_SERIOUS_FAILURE,
+	MajorFailure:   c4pb.AuditEventOutcomeCode_MAJOR_FAILURE,
+}
+
+// Actor is the party that did the action.
+type Actor struct {
+	// Who is the relative reference of the actor, i.e. "Practitioner/123".
+	Who  string
+	Name string
+	// Roles are the roles the actor acted in.
+	Roles []*d4pb.Coding
+	// Address is the network address of the actor, an IP address or a
+	// machine name.
+	Address      string
+	PurposeOfUse []*d4pb.Coding
+}
+
+// Source is the system reporting the event.
+type Source struct {
+	// Observer is the relative reference of the reporting system, i.e.
+	// "Device/fhir-server". It is required.
+	Observer string
+	Site     string
+	Type     []*d4pb.Coding
+}
+
+// Event describes an event to audit.
+type Event struct {
+	// Interaction is the FHIR RESTful interaction, i.e. "read" or
+	// "search-type", which becomes the subtype of a "rest" AuditEvent. Type
+	// and Subtype may be set instead.
+	Interaction string
+	Type        *d4pb.Coding
+	Subtype     []*d4pb.Coding
+	// Action defaults to the action of Interaction.
+	Action      Action
+	Outcome     Outcome
+	OutcomeDesc string
+	// Recorded defaults to the current time.
+	Recorded time.Time
+	Actor    Actor
+	Source   Source
+	// Patient is the relative reference of the patient the event is about,
+	// if any.
+	Patient string
+	// Entities are the relative references of the resources the event is
+	// about.
+	Entities []string
+	// Query is the query of searches.
+	Query string
+}
+
+// Build returns the AuditEvent of e.
+func (e Event) Build() (*aepb.AuditEvent, error) {
+	if e.Source.Observer == "" {
+		return nil, fmt.Errorf("audit event has no source observer")
+	}
+	typ, subtype := e.Type, e.Subtype
+	action := e.Action
+	if e.Interaction != "" {
+		if typ == nil {
+			typ = &d4pb.Coding{System: &d4pb.Uri{Value: auditEventTypeSystem}, Code: &d4pb.Code{Value: "rest"}}
+		}
+		subtype = append([]*d4pb.Coding{{
+			System: &d4pb.Uri{Value: restfulInteractionSystem},
+			Code:   &d4pb.Code{Value: e.Interaction},
+		}}, subtype...)
+		if action == "" {
+			action = interactionActions[e.Interaction]
+		}
+	}
+	if typ == nil {
+		return nil, fmt.Errorf("audit event has no type or interaction")
+	}
+	ae := &aepb.AuditEvent{
+		Type:    typ,
+		Subtype: subtype,
+		Outcome: &aepb.AuditEvent_OutcomeCode{Value: outcomeCodes[e.Outcome]},
+	}
+	if action != "" {
+		code, ok := actionCodes[action]
+		if !ok {
+			return nil, fmt.Errorf("unknown action %q", action)
+		}
+		ae.Action = &aepb.AuditEvent_ActionCode{Value: code}
+	}
+	if ae.GetOutcome().GetValue() == c4pb.AuditEventOutcomeCode_INVALID_UNINITIALIZED {
+		return nil, fmt.Errorf("unknown outcome %d", e.Outcome)
+	}
+	if e.OutcomeDesc != "" {
+		ae.OutcomeDesc = &d4pb.String{Value: e.OutcomeDesc}
+	}
+	recorded := e.Recorded
+	if recorded.IsZero() {
+		recorded = time.Now()
+	}
+	ae.Recorded = &d4pb.Instant{ValueUs: recorded.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_MICROSECOND}
+
+	agent, err := e.Actor.agent()
+	if err != nil {
+		return nil, err
+	}
+	ae.Agent = []*aepb.AuditEvent_Agent{agent}
+	observer, err := fhirtypes.ReferenceFromURI(e.Source.Observer)
+	if err != nil {
+		return nil, fmt.Errorf("source observer: %w", err)
+	}
+	ae.Source = &aepb.AuditEvent_Source{Observer: observer, Type: e.Source.Type}
+	if e.Source.Site != "" {
+		ae.Source.Site = &d4pb.String{Value: e.Source.Site}
+	}
+
+	if e.Patient != "" {
+		entity, err := entity(e.Patient, "1", "1")
+		if err != nil {
+			return nil, fmt.Errorf("patient: %w", err)
+		}
+		ae.Entity = append(ae.Entity, entity)
+	}
+	for _, ref := range e.Entities {
+		if ref == e.Patient {
+			continue
+		}
+		entity, err := entity(ref, "2", "4")
+		if err != nil {
+			return nil, fmt.Errorf("entity: %w", err)
+		}
+		ae.Entity = append(ae.Entity, entity)
+	}
+	if e.Query != "" {
+		ae.Entity = append(ae.Entity, &aepb.AuditEvent_Entity{
+			Type:  fhirtypes.Coding(entityTypeSystem, "2"),
+			Role:  fhirtypes.Coding(objectRoleSystem, "24"),
+			Query: &d4pb.Base64Binary{Value: []byte(e.Query)},
+		})
+	}
+	return ae, nil
+}
+
+func (a Actor) agent() (*aepb.AuditEvent_Agent, error) {
+	agent := &aepb.AuditEvent_Agent{Requestor: &d4pb.Boolean{Value: true}}
+	if a.Who != "" {
+		who, err := fhirtypes.ReferenceFromURI(a.Who)
+		if err != nil {
+			return nil, fmt.Errorf("actor: %w", err)
+		}
+		agent.Who = who
+	}
+	if a.Name != "" {
+		agent.Name = &d4pb.String{Value: a.Name}
+	}
+	if len(a.Roles) > 0 {
+		agent.Role = []*d4pb.CodeableConcept{{Coding: a.Roles}}
+	}
+	for _, p := range a.PurposeOfUse {
+		agent.PurposeOfUse = append(agent.PurposeOfUse, &d4pb.CodeableConcept{Coding: []*d4pb.Coding{p}})
+	}
+	if a.Address != "" {
+		typ := c4pb.AuditEventAgentNetworkTypeCode_MACHINE_NAME
+		if net.ParseIP(a.Address) != nil {
+			typ = c4pb.AuditEventAgentNetworkTypeCode_IP_ADDRESS
+		}
+		agent.Network = &aepb.AuditEvent_Agent_Network{
+			Address: &d4pb.String{Value: a.Address},
+			Type:    &aepb.AuditEvent_Agent_Network_TypeCode{Value: typ},
+		}
+	}
+	return agent, nil
+}
+
+// entity returns the entity of the resource ref, with the given
+// audit-entity-type and object-role codes.
+func entity(ref, typ, role string) (*aepb.AuditEvent_Entity, error) {
+	what, err := fhirtypes.ReferenceFromURI(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &aepb.AuditEvent_Entity{
+		What: what,
+		Type: fhirtypes.Coding(entityTypeSystem, typ),
+		Role: fhirtypes.Coding(objectRoleSystem, role),
+	}, nil
+}
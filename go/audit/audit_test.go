@@ -0,0 +1,205 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	aepb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/audit_event_go_proto"
+)
+
+var now = time.Date(2026, 4, 2, 8, 30, 0, 0, time.UTC)
+
+var source = Source{Observer: "Device/fhir-server", Site: "main"}
+
+func TestBuild(t *testing.T) {
+	got, err := Event{
+		Interaction: "read",
+		Recorded:    now,
+		Actor:       Actor{Who: "Practitioner/dr", Address: "10.0.0.7"},
+		Source:      source,
+		Patient:     "Patient/p1",
+		Entities:    []string{"Observation/o1"},
+	}.Build()
+	if err != nil {
+		t.Fatalf("Build() returned unexpected error: %v", err)
+	}
+	want := &aepb.AuditEvent{
+		Type:     fhirtypes.Coding(auditEventTypeSystem, "rest"),
+		Subtype:  []*d4pb.Coding{fhirtypes.Coding(restfulInteractionSystem, "read")},
+		Action:   &aepb.AuditEvent_ActionCode{Value: c4pb.AuditEventActionCode_R},
+		Recorded: &d4pb.Instant{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_MICROSECOND},
+		Outcome:  &aepb.AuditEvent_OutcomeCode{Value: c4pb.AuditEventOutcomeCode_SUCCESS},
+		Agent: []*aepb.AuditEvent_Agent{{
+			Who:       &d4pb.Reference{Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: "dr"}}},
+			Requestor: &d4pb.Boolean{Value: true},
+			Network: &aepb.AuditEvent_Agent_Network{
+				Address: &d4pb.String{Value: "10.0.0.7"},
+				Type:    &aepb.AuditEvent_Agent_Network_TypeCode{Value: c4pb.AuditEventAgentNetworkTypeCode_IP_ADDRESS},
+			},
+		}},
+		Source: &aepb.AuditEvent_Source{
+			Site:     &d4pb.String{Value: "main"},
+			Observer: &d4pb.Reference{Reference: &d4pb.Reference_DeviceId{DeviceId: &d4pb.ReferenceId{Value: "fhir-server"}}},
+		},
+		Entity: []*aepb.AuditEvent_Entity{
+			{
+				What: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+				Type: fhirtypes.Coding(entityTypeSystem, "1"),
+				Role: fhirtypes.Coding(objectRoleSystem, "1"),
+			},
+			{
+				What: &d4pb.Reference{Reference: &d4pb.Reference_ObservationId{ObservationId: &d4pb.ReferenceId{Value: "o1"}}},
+				Type: fhirtypes.Coding(entityTypeSystem, "2"),
+				Role: fhirtypes.Coding(objectRoleSystem, "4"),
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Build() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Event
+	}{
+		{"no observer", Event{Interaction: "read"}},
+		{"no type", Event{Source: source}},
+		{"unknown action", Event{Interaction: "read", Action: "X", Source: source}},
+		{"unknown outcome", Event{Interaction: "read", Outcome: 7, Source: source}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.e.Build(); err == nil {
+				t.Errorf("Build() succeeded, want error")
+			}
+		})
+	}
+}
+
+type emitted struct {
+	mu     sync.Mutex
+	events []*aepb.AuditEvent
+}
+
+func (e *emitted) emit(_ context.Context, ae *aepb.AuditEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, ae)
+}
+
+func (e *emitted) take() []*aepb.AuditEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	events := e.events
+	e.events = nil
+	return events
+}
+
+func TestMiddleware(t *testing.T) {
+	var got emitted
+	rec := &Recorder{Emit: got.emit, Source: source, BasePath: "/fhir", Now: func() time.Time { return now }}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fhir/Patient/missing" {
+			http.NotFound(w, r)
+		}
+	})
+	s := httptest.NewServer(rec.Middleware(func(*http.Request) Actor { return Actor{Who: "Practitioner/dr"} })(next))
+	defer s.Close()
+
+	tests := []struct {
+		path        string
+		interaction string
+		patient     string
+		outcome     c4pb.AuditEventOutcomeCode_Value
+	}{
+		{"/fhir/Patient/p1", "read", "p1", c4pb.AuditEventOutcomeCode_SUCCESS},
+		{"/fhir/Patient/missing", "read", "missing", c4pb.AuditEventOutcomeCode_MINOR_FAILURE},
+		{"/fhir/Patient/p1/Observation", "search-type", "p1", c4pb.AuditEventOutcomeCode_SUCCESS},
+		{"/fhir/Observation?subject=Patient/p2&code=1234-5", "search-type", "p2", c4pb.AuditEventOutcomeCode_SUCCESS},
+		{"/fhir/Observation?patient=p3", "search-type", "p3", c4pb.AuditEventOutcomeCode_SUCCESS},
+		{"/fhir/Organization/org", "", "", 0},
+		{"/fhir/Observation?code=1234-5", "", "", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			resp, err := http.Get(s.URL + test.path)
+			if err != nil {
+				t.Fatalf("GET %s returned unexpected error: %v", test.path, err)
+			}
+			resp.Body.Close()
+			events := got.take()
+			if test.interaction == "" {
+				if len(events) != 0 {
+					t.Errorf("GET %s recorded %d events, want none", test.path, len(events))
+				}
+				return
+			}
+			if len(events) != 1 {
+				t.Fatalf("GET %s recorded %d events, want 1", test.path, len(events))
+			}
+			ae := events[0]
+			if got := ae.GetSubtype()[0].GetCode().GetValue(); got != test.interaction {
+				t.Errorf("GET %s recorded interaction %q, want %q", test.path, got, test.interaction)
+			}
+			if got := ae.GetEntity()[0].GetWhat().GetPatientId().GetValue(); got != test.patient {
+				t.Errorf("GET %s recorded patient %q, want %q", test.path, got, test.patient)
+			}
+			if got := ae.GetOutcome().GetValue(); got != test.outcome {
+				t.Errorf("GET %s recorded outcome %v, want %v", test.path, got, test.outcome)
+			}
+			if got := ae.GetAgent()[0].GetNetwork().GetAddress().GetValue(); got != "127.0.0.1" {
+				t.Errorf("GET %s recorded address %q, want 127.0.0.1", test.path, got)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+	var got emitted
+	rec := &Recorder{Emit: got.emit, Source: Source{Observer: "Device/client"}}
+	client := &http.Client{Transport: rec.Transport(nil, Actor{Name: "batch job"})}
+
+	resp, err := client.Get(s.URL + "/Patient/p1/_history/2")
+	if err != nil {
+		t.Fatalf("GET returned unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	events := got.take()
+	if len(events) != 1 {
+		t.Fatalf("GET recorded %d events, want 1", len(events))
+	}
+	if got := events[0].GetSubtype()[0].GetCode().GetValue(); got != "vread" {
+		t.Errorf("GET recorded interaction %q, want vread", got)
+	}
+	if got := events[0].GetAgent()[0].GetName().GetValue(); got != "batch job" {
+		t.Errorf("GET recorded agent %q, want batch job", got)
+	}
+}
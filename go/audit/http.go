@@ -0,0 +1,234 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	aepb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/audit_event_go_proto"
+)
+
+// Emitter records an AuditEvent, i.e. by creating it on an audit record
+// repository. Emitters handle their own errors.
+type Emitter func(ctx context.Context, ae *aepb.AuditEvent)
+
+// Recorder records the reads of sensitive compartments, the read and
+// search interactions that concern a resource of a sensitive compartment
+// type, through the Middleware of a server or the Transport of a client.
+type Recorder struct {
+	// Emit records the AuditEvents. It is required.
+	Emit Emitter
+	// Source is the source of the AuditEvents. Its observer is required:
+	// no events are recorded without it.
+	Source Source
+	// Compartments are the types of the sensitive compartments; "Patient"
+	// if it is empty.
+	Compartments []string
+	// BasePath is the path of the FHIR base, which is removed from the paths
+	// of requests.
+	BasePath string
+	// Now returns the time events are recorded at. time.Now is used if it
+	// is nil.
+	Now func() time.Time
+}
+
+// Middleware returns middleware recording the reads of sensitive
+// compartments the wrapped handler serves. actor returns the actor of a
+// request; its network address defaults to the remote address of the
+// request.
+func (rec *Recorder) Middleware(actor func(*http.Request) Actor) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r)
+			a := actor(r)
+			if a.Address == "" {
+				if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+					a.Address = host
+				}
+			}
+			rec.record(r, sw.status, a)
+		})
+	}
+}
+
+// Transport returns an http.RoundTripper recording the reads of sensitive
+// compartments made through base, or http.DefaultTransport if it is nil, by
+// actor.
+func (rec *Recorder) Transport(base http.RoundTripper, actor Actor) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper(func(r *http.Request) (*http.Response, error) {
+		resp, err := base.RoundTrip(r)
+		status := http.StatusBadGateway
+		if err == nil {
+			status = resp.StatusCode
+		}
+		rec.record(r, status, actor)
+		return resp, err
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// record emits the AuditEvent of r if it reads a sensitive compartment.
+func (rec *Recorder) record(r *http.Request, status int, actor Actor) {
+	e, ok := rec.event(r)
+	if !ok {
+		return
+	}
+	e.Actor = actor
+	e.Source = rec.Source
+	switch {
+	case status >= 500:
+		e.Outcome = SeriousFailure
+	case status >= 400:
+		e.Outcome = MinorFailure
+	}
+	if e.Outcome != Success {
+		e.OutcomeDesc = http.StatusText(status)
+	}
+	if rec.Now != nil {
+		e.Recorded = rec.Now()
+	}
+	ae, err := e.Build()
+	if err != nil {
+		// Only an invalid Source or actor reference makes Build fail.
+		return
+	}
+	rec.Emit(r.Context(), ae)
+}
+
+// event returns the event of r, a FHIR RESTful request, and whether r reads
+// a sensitive compartment.
+func (rec *Recorder) event(r *http.Request) (Event, bool) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return Event{}, false
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, rec.BasePath), "/")
+	var segs []string
+	if path != "" {
+		segs = strings.Split(path, "/")
+	}
+	e := Event{}
+	var compartment string
+	switch {
+	case len(segs) > 0 && strings.HasPrefix(segs[len(segs)-1], "$"):
+		e.Interaction = "operation"
+	case len(segs) == 0:
+		e.Interaction = "search-system"
+	case len(segs) == 1:
+		e.Interaction = "search-type"
+	case len(segs) == 2:
+		e.Interaction = "read"
+		e.Entities = []string{segs[0] + "/" + segs[1]}
+	case len(segs) == 3 && segs[2] == "_history":
+		e.Interaction = "history"
+		e.Entities = []string{segs[0] + "/" + segs[1]}
+	case len(segs) == 4 && segs[2] == "_history":
+		e.Interaction = "vread"
+		e.Entities = []string{segs[0] + "/" + segs[1] + "/_history/" + segs[3]}
+	case len(segs) == 3:
+		// A compartment search, [type]/[id]/[type].
+		e.Interaction = "search-type"
+	default:
+		return Event{}, false
+	}
+	if len(segs) >= 2 && rec.sensitive(segs[0]) {
+		compartment = segs[0] + "/" + segs[1]
+	} else {
+		compartment = rec.queryCompartment(r)
+	}
+	if compartment == "" {
+		return Event{}, false
+	}
+	if strings.HasPrefix(compartment, "Patient/") {
+		e.Patient = compartment
+	} else {
+		e.Entities = append(e.Entities, compartment)
+	}
+	if strings.HasPrefix(e.Interaction, "search") || e.Interaction == "operation" {
+		e.Query = r.URL.RawQuery
+	}
+	return e, true
+}
+
+func (rec *Recorder) sensitive(typ string) bool {
+	if len(rec.Compartments) == 0 {
+		return typ == "Patient"
+	}
+	for _, c := range rec.Compartments {
+		if c == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// queryCompartment returns the sensitive compartment the search parameters
+// of r restrict the search to: a parameter named after the compartment
+// type, i.e. patient=123, or a subject of the compartment type, i.e.
+// subject=Patient/123.
+func (rec *Recorder) queryCompartment(r *http.Request) string {
+	for name, values := range r.URL.Query() {
+		name, _, _ = strings.Cut(name, ":")
+		if name == "" {
+			continue
+		}
+		for _, v := range values {
+			typ, id, ok := strings.Cut(v, "/")
+			if !ok {
+				typ, id = strings.ToUpper(name[:1])+name[1:], v
+			}
+			if id == "" || strings.Contains(id, "/") || !rec.sensitive(typ) {
+				continue
+			}
+			if name == "subject" || strings.EqualFold(name, typ) {
+				return typ + "/" + id
+			}
+		}
+	}
+	return ""
+}
+
+// statusWriter is an http.ResponseWriter remembering the status of the
+// response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package billing aggregates R4 ChargeItems into the balances of their
+// Accounts and the line items of Invoices, for revenue cycle analytics.
+//
+// The amount of a ChargeItem is its price override, which is the total
+// price of the item, or else its unit price, as given by Options, times its
+// quantity and its factor override. Amounts are added exactly by the Money
+// arithmetic of package fhirtypes, and only in the same currency; products
+// are rounded to the minor unit of their currency.
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/charge_item_go_proto"
+)
+
+// ErrNoPrice is returned for ChargeItems without a price override when
+// Options has no UnitPrice.
+var ErrNoPrice = errors.New("charge item has no price")
+
+// Options configures the aggregation of ChargeItems.
+type Options struct {
+	// Period, if set, restricts aggregation to the ChargeItems that occurred
+	// within it: at the start of their occurrence or, for those without
+	// one, on their entered date. ChargeItems with neither are skipped.
+	Period *d4pb.Period
+	// UnitPrice returns the unit price of ChargeItems without a price
+	// override, as from the ChargeItemDefinitions they refer to.
+	UnitPrice func(*cipb.ChargeItem) (*d4pb.Money, error)
+	// Rounding is the rounding of the products of unit prices and
+	// quantities.
+	Rounding fhirtypes.RoundingMode
+}
+
+// Billable reports whether ci is a charge to be billed or already billed,
+// rather than planned, aborted, not billable or entered in error.
+func Billable(ci *cipb.ChargeItem) bool {
+	switch ci.GetStatus().GetValue() {
+	case c4pb.ChargeItemStatusCode_BILLABLE, c4pb.ChargeItemStatusCode_BILLED:
+		return true
+	}
+	return false
+}
+
+// Amount returns the amount of ci.
+func Amount(ci *cipb.ChargeItem, opts Options) (*d4pb.Money, error) {
+	if p := ci.GetPriceOverride(); p != nil {
+		return p, nil
+	}
+	if opts.UnitPrice == nil {
+		return nil, fmt.Errorf("ChargeItem %s: %w", ci.GetId().GetValue(), ErrNoPrice)
+	}
+	price, err := opts.UnitPrice(ci)
+	if err != nil {
+		return nil, fmt.Errorf("ChargeItem %s: %w", ci.GetId().GetValue(), err)
+	}
+	if price == nil {
+		return nil, fmt.Errorf("ChargeItem %s: %w", ci.GetId().GetValue(), ErrNoPrice)
+	}
+	factor := big.NewRat(1, 1)
+	for _, d := range []*d4pb.Decimal{ci.GetQuantity().GetValue(), ci.GetFactorOverride()} {
+		if d == nil {
+			continue
+		}
+		f, ok := new(big.Rat).SetString(d.GetValue())
+		if !ok {
+			return nil, fmt.Errorf("ChargeItem %s: invalid decimal %q", ci.GetId().GetValue(), d.GetValue())
+		}
+		factor.Mul(factor, f)
+	}
+	amount, err := fhirtypes.MultiplyMoney(price, factor.FloatString(18), opts.Rounding)
+	if err != nil {
+		return nil, fmt.Errorf("ChargeItem %s: %w", ci.GetId().GetValue(), err)
+	}
+	return amount, nil
+}
+
+// Occurred returns the time ci occurred: the start of its occurrence, or
+// its entered date if it has none. It returns false if ci has neither.
+func Occurred(ci *cipb.ChargeItem) (time.Time, bool, error) {
+	var m *d4pb.DateTime
+	switch o := ci.GetOccurrence(); {
+	case o.GetDateTime() != nil:
+		m = o.GetDateTime()
+	case o.GetPeriod().GetStart() != nil:
+		m = o.GetPeriod().GetStart()
+	case len(o.GetTiming().GetEvent()) > 0:
+		m = o.GetTiming().GetEvent()[0]
+	default:
+		m = ci.GetEnteredDate()
+	}
+	if m == nil {
+		return time.Time{}, false, nil
+	}
+	t, _, err := fhirtypes.Span(m)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("ChargeItem %s: occurrence %w", ci.GetId().GetValue(), err)
+	}
+	return t, true, nil
+}
+
+// Select returns the billable ChargeItems among items that occurred within
+// the period of opts, in their order.
+func Select(items []*cipb.ChargeItem, opts Options) ([]*cipb.ChargeItem, error) {
+	var out []*cipb.ChargeItem
+	for _, ci := range items {
+		if !Billable(ci) {
+			continue
+		}
+		if opts.Period != nil {
+			t, ok, err := Occurred(ci)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			in, err := fhirtypes.PeriodContains(opts.Period, t)
+			if err != nil {
+				return nil, fmt.Errorf("period %w", err)
+			}
+			if !in {
+				continue
+			}
+		}
+		out = append(out, ci)
+	}
+	return out, nil
+}
+
+// Total returns the sum of the amounts of the billable items that occurred
+// within the period of opts, or nil if there are none.
+func Total(items []*cipb.ChargeItem, opts Options) (*d4pb.Money, error) {
+	selected, err := Select(items, opts)
+	if err != nil {
+		return nil, err
+	}
+	var total *d4pb.Money
+	for _, ci := range selected {
+		a, err := Amount(ci, opts)
+		if err != nil {
+			return nil, err
+		}
+		if total, err = fhirtypes.SumMoney(total, a); err != nil {
+			return nil, fmt.Errorf("ChargeItem %s: %w", ci.GetId().GetValue(), err)
+		}
+	}
+	return total, nil
+}
+
+// Balance is the sum of the charges to an Account.
+type Balance struct {
+	// Account is the literal reference to the Account.
+	Account string
+	// Billable is the sum of the charges yet to be billed, Billed that of
+	// those already billed and Total their sum. Each is nil if there are no
+	// such charges.
+	Billable, Billed, Total *d4pb.Money
+	// Items are the charges to the Account, in the order they were given.
+	Items []*cipb.ChargeItem
+}
+
+// Balances returns the balances of the Accounts that items are charged to,
+// in the order the Accounts are first referred to, for the billable items
+// that occurred within the period of opts. Items charged to several
+// Accounts count towards the balance of each; items without an Account
+// towards none.
+func Balances(items []*cipb.ChargeItem, opts Options) ([]*Balance, error) {
+	selected, err := Select(items, opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Balance
+	byAccount := map[string]*Balance{}
+	for _, ci := range selected {
+		if len(ci.GetAccount()) == 0 {
+			continue
+		}
+		a, err := Amount(ci, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range ci.GetAccount() {
+			key := fhirtypes.ReferenceURI(ref)
+			if key == "" {
+				return nil, fmt.Errorf("ChargeItem %s: account is not a literal reference", ci.GetId().GetValue())
+			}
+			b := byAccount[key]
+			if b == nil {
+				b = &Balance{Account: key}
+				byAccount[key] = b
+				out = append(out, b)
+			}
+			part := &b.Billable
+			if ci.GetStatus().GetValue() == c4pb.ChargeItemStatusCode_BILLED {
+				part = &b.Billed
+			}
+			if *part, err = fhirtypes.AddMoney(*part, a); err != nil {
+				return nil, fmt.Errorf("Account %s: ChargeItem %s: %w", key, ci.GetId().GetValue(), err)
+			}
+			if b.Total, err = fhirtypes.AddMoney(b.Total, a); err != nil {
+				return nil, fmt.Errorf("Account %s: ChargeItem %s: %w", key, ci.GetId().GetValue(), err)
+			}
+			b.Items = append(b.Items, ci)
+		}
+	}
+	return out, nil
+}
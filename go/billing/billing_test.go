@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package billing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/charge_item_go_proto"
+)
+
+func day(s string) *d4pb.DateTime {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return fhirtypes.DateTimeFromTime(t, d4pb.DateTime_DAY)
+}
+
+type item struct {
+	id       string
+	status   c4pb.ChargeItemStatusCode_Value
+	on       string
+	price    *d4pb.Money
+	quantity string
+	accounts []string
+}
+
+func (i item) proto() *cipb.ChargeItem {
+	ci := &cipb.ChargeItem{
+		Id:            fhirtypes.ID(i.id),
+		Status:        &cipb.ChargeItem_StatusCode{Value: i.status},
+		Code:          &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding("http://www.ama-assn.org/go/cpt", "99213")}},
+		PriceOverride: i.price,
+	}
+	if i.on != "" {
+		ci.Occurrence = &cipb.ChargeItem_OccurrenceX{Choice: &cipb.ChargeItem_OccurrenceX_DateTime{DateTime: day(i.on)}}
+	}
+	if i.quantity != "" {
+		ci.Quantity = &d4pb.Quantity{Value: fhirtypes.Decimal(i.quantity)}
+	}
+	for _, a := range i.accounts {
+		ci.Account = append(ci.Account, fhirtypes.Reference("Account", a))
+	}
+	return ci
+}
+
+func items(is ...item) []*cipb.ChargeItem {
+	var out []*cipb.ChargeItem
+	for _, i := range is {
+		out = append(out, i.proto())
+	}
+	return out
+}
+
+const (
+	billable = c4pb.ChargeItemStatusCode_BILLABLE
+	billed   = c4pb.ChargeItemStatusCode_BILLED
+)
+
+func TestAmount(t *testing.T) {
+	unitPrice := func(*cipb.ChargeItem) (*d4pb.Money, error) { return fhirtypes.Money("33.33", "USD"), nil }
+	ci := item{id: "c1", status: billable, quantity: "3"}.proto()
+	ci.FactorOverride = fhirtypes.Decimal("0.5")
+	got, err := Amount(ci, Options{UnitPrice: unitPrice})
+	if err != nil {
+		t.Fatalf("Amount() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(fhirtypes.Money("50.00", "USD"), got, protocmp.Transform()); diff != "" {
+		t.Errorf("Amount() diff (-want +got):\n%s", diff)
+	}
+	got, err = Amount(item{id: "c2", status: billable, quantity: "3", price: fhirtypes.Money("75", "USD")}.proto(), Options{UnitPrice: unitPrice})
+	if err != nil {
+		t.Fatalf("Amount() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(fhirtypes.Money("75", "USD"), got, protocmp.Transform()); diff != "" {
+		t.Errorf("Amount() with price override diff (-want +got):\n%s", diff)
+	}
+	if _, err := Amount(item{id: "c3", status: billable}.proto(), Options{}); !errors.Is(err, ErrNoPrice) {
+		t.Errorf("Amount() returned error %v, want %v", err, ErrNoPrice)
+	}
+}
+
+func TestBalances(t *testing.T) {
+	in := items(
+		item{id: "c1", status: billable, on: "2024-01-05", price: fhirtypes.Money("100.00", "USD"), accounts: []string{"a1"}},
+		item{id: "c2", status: billed, on: "2024-01-10", price: fhirtypes.Money("50.50", "USD"), accounts: []string{"a1", "a2"}},
+		item{id: "c3", status: c4pb.ChargeItemStatusCode_ENTERED_IN_ERROR, on: "2024-01-10", price: fhirtypes.Money("999", "USD"), accounts: []string{"a1"}},
+		item{id: "c4", status: billable, on: "2024-02-01", price: fhirtypes.Money("20", "USD"), accounts: []string{"a2"}},
+		item{id: "c5", status: billable, price: fhirtypes.Money("10", "USD"), accounts: []string{"a2"}},
+		item{id: "c6", status: billable, on: "2024-01-20", price: fhirtypes.Money("5", "USD")},
+	)
+	tests := []struct {
+		name string
+		opts Options
+		want []*Balance
+	}{{
+		name: "all",
+		want: []*Balance{
+			{Account: "Account/a1", Billable: fhirtypes.Money("100.00", "USD"), Billed: fhirtypes.Money("50.50", "USD"), Total: fhirtypes.Money("150.50", "USD"), Items: []*cipb.ChargeItem{in[0], in[1]}},
+			{Account: "Account/a2", Billable: fhirtypes.Money("30", "USD"), Billed: fhirtypes.Money("50.50", "USD"), Total: fhirtypes.Money("80.50", "USD"), Items: []*cipb.ChargeItem{in[1], in[3], in[4]}},
+		},
+	}, {
+		name: "january",
+		opts: Options{Period: &d4pb.Period{Start: day("2024-01-01"), End: day("2024-01-31")}},
+		want: []*Balance{
+			{Account: "Account/a1", Billable: fhirtypes.Money("100.00", "USD"), Billed: fhirtypes.Money("50.50", "USD"), Total: fhirtypes.Money("150.50", "USD"), Items: []*cipb.ChargeItem{in[0], in[1]}},
+			{Account: "Account/a2", Billed: fhirtypes.Money("50.50", "USD"), Total: fhirtypes.Money("50.50", "USD"), Items: []*cipb.ChargeItem{in[1]}},
+		},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Balances(in, tc.opts)
+			if err != nil {
+				t.Fatalf("Balances() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Balances() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	total, err := Total(in, Options{Period: &d4pb.Period{Start: day("2024-01-01"), End: day("2024-01-31")}})
+	if err != nil {
+		t.Fatalf("Total() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(fhirtypes.Money("155.50", "USD"), total, protocmp.Transform()); diff != "" {
+		t.Errorf("Total() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestBalancesCurrencyMismatch(t *testing.T) {
+	in := items(
+		item{id: "c1", status: billable, price: fhirtypes.Money("100", "USD"), accounts: []string{"a1"}},
+		item{id: "c2", status: billable, price: fhirtypes.Money("100", "EUR"), accounts: []string{"a1"}},
+	)
+	if _, err := Balances(in, Options{}); !errors.Is(err, fhirtypes.ErrCurrencyMismatch) {
+		t.Errorf("Balances() returned error %v, want %v", err, fhirtypes.ErrCurrencyMismatch)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package billing
+
+import (
+	"fmt"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/charge_item_go_proto"
+	invpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/invoice_go_proto"
+)
+
+// LineItems returns the Invoice line items of the billable items that
+// occurred within the period of opts, numbered from 1 in their order. Each
+// refers to its ChargeItem, or has its code if it has no id, and has the
+// amount of the item as its base price component.
+func LineItems(items []*cipb.ChargeItem, opts Options) ([]*invpb.Invoice_LineItem, error) {
+	selected, err := Select(items, opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []*invpb.Invoice_LineItem
+	for i, ci := range selected {
+		a, err := Amount(ci, opts)
+		if err != nil {
+			return nil, err
+		}
+		li := &invpb.Invoice_LineItem{
+			Sequence:   fhirtypes.PositiveInt(uint32(i + 1)),
+			ChargeItem: &invpb.Invoice_LineItem_ChargeItemX{},
+			PriceComponent: []*invpb.Invoice_LineItem_PriceComponent{{
+				Type:   &invpb.Invoice_LineItem_PriceComponent_TypeCode{Value: c4pb.InvoicePriceComponentTypeCode_BASE},
+				Amount: a,
+			}},
+		}
+		if id := ci.GetId().GetValue(); id != "" {
+			li.ChargeItem.Choice = &invpb.Invoice_LineItem_ChargeItemX_Reference{Reference: fhirtypes.Reference("ChargeItem", id)}
+		} else {
+			li.ChargeItem.Choice = &invpb.Invoice_LineItem_ChargeItemX_CodeableConcept{CodeableConcept: ci.GetCode()}
+		}
+		out = append(out, li)
+	}
+	return out, nil
+}
+
+// Totals returns the net and gross totals of the line items of inv: the
+// sum of their base prices and surcharges less their deductions and
+// discounts, and that plus their taxes. Deductions and discounts reduce the
+// totals whether their amounts are positive or negative; informational
+// components do not count. Both are nil if inv has no priced line items.
+func Totals(inv *invpb.Invoice) (net, gross *d4pb.Money, err error) {
+	var taxes *d4pb.Money
+	for _, li := range inv.GetLineItem() {
+		for _, pc := range li.GetPriceComponent() {
+			a := pc.GetAmount()
+			if a == nil {
+				continue
+			}
+			switch pc.GetType().GetValue() {
+			case c4pb.InvoicePriceComponentTypeCode_BASE, c4pb.InvoicePriceComponentTypeCode_SURCHARGE:
+				net, err = fhirtypes.AddMoney(net, a)
+			case c4pb.InvoicePriceComponentTypeCode_DEDUCTION, c4pb.InvoicePriceComponentTypeCode_DISCOUNT:
+				if a, err = abs(a); err == nil {
+					if net == nil {
+						net = fhirtypes.Money("0", a.GetCurrency().GetValue())
+					}
+					net, err = fhirtypes.SubtractMoney(net, a)
+				}
+			case c4pb.InvoicePriceComponentTypeCode_TAX:
+				taxes, err = fhirtypes.AddMoney(taxes, a)
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("line item %d: %w", li.GetSequence().GetValue(), err)
+			}
+		}
+	}
+	if net == nil && taxes == nil {
+		return nil, nil, nil
+	}
+	if gross, err = fhirtypes.SumMoney(net, taxes); err != nil {
+		return nil, nil, err
+	}
+	if net == nil {
+		net = fhirtypes.Money("0", gross.GetCurrency().GetValue())
+	}
+	return net, gross, nil
+}
+
+// SetTotals sets the total net and gross of inv to its Totals.
+func SetTotals(inv *invpb.Invoice) error {
+	net, gross, err := Totals(inv)
+	if err != nil {
+		return err
+	}
+	inv.TotalNet, inv.TotalGross = net, gross
+	return nil
+}
+
+// abs returns m without its sign.
+func abs(m *d4pb.Money) (*d4pb.Money, error) {
+	zero := fhirtypes.Money("0", m.GetCurrency().GetValue())
+	o, err := fhirtypes.CompareMoney(m, zero)
+	if err != nil || o != fhirtypes.Less {
+		return m, err
+	}
+	return fhirtypes.SubtractMoney(zero, m)
+}
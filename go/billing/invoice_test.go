@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package billing
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	invpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/invoice_go_proto"
+)
+
+func component(t c4pb.InvoicePriceComponentTypeCode_Value, amount *d4pb.Money) *invpb.Invoice_LineItem_PriceComponent {
+	return &invpb.Invoice_LineItem_PriceComponent{Type: &invpb.Invoice_LineItem_PriceComponent_TypeCode{Value: t}, Amount: amount}
+}
+
+func TestLineItems(t *testing.T) {
+	in := items(
+		item{id: "c1", status: billable, price: fhirtypes.Money("100.00", "USD")},
+		item{id: "c2", status: c4pb.ChargeItemStatusCode_PLANNED, price: fhirtypes.Money("10", "USD")},
+		item{status: billed, price: fhirtypes.Money("25", "USD")},
+	)
+	got, err := LineItems(in, Options{})
+	if err != nil {
+		t.Fatalf("LineItems() returned unexpected error: %v", err)
+	}
+	want := []*invpb.Invoice_LineItem{{
+		Sequence:       fhirtypes.PositiveInt(1),
+		ChargeItem:     &invpb.Invoice_LineItem_ChargeItemX{Choice: &invpb.Invoice_LineItem_ChargeItemX_Reference{Reference: fhirtypes.Reference("ChargeItem", "c1")}},
+		PriceComponent: []*invpb.Invoice_LineItem_PriceComponent{component(c4pb.InvoicePriceComponentTypeCode_BASE, fhirtypes.Money("100.00", "USD"))},
+	}, {
+		Sequence:       fhirtypes.PositiveInt(2),
+		ChargeItem:     &invpb.Invoice_LineItem_ChargeItemX{Choice: &invpb.Invoice_LineItem_ChargeItemX_CodeableConcept{CodeableConcept: in[2].GetCode()}},
+		PriceComponent: []*invpb.Invoice_LineItem_PriceComponent{component(c4pb.InvoicePriceComponentTypeCode_BASE, fhirtypes.Money("25", "USD"))},
+	}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("LineItems() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetTotals(t *testing.T) {
+	inv := &invpb.Invoice{LineItem: []*invpb.Invoice_LineItem{{
+		Sequence: fhirtypes.PositiveInt(1),
+		PriceComponent: []*invpb.Invoice_LineItem_PriceComponent{
+			component(c4pb.InvoicePriceComponentTypeCode_BASE, fhirtypes.Money("100.00", "EUR")),
+			component(c4pb.InvoicePriceComponentTypeCode_DISCOUNT, fhirtypes.Money("-10.00", "EUR")),
+			component(c4pb.InvoicePriceComponentTypeCode_TAX, fhirtypes.Money("17.10", "EUR")),
+			component(c4pb.InvoicePriceComponentTypeCode_INFORMATIONAL, fhirtypes.Money("1000", "EUR")),
+		},
+	}, {
+		Sequence: fhirtypes.PositiveInt(2),
+		PriceComponent: []*invpb.Invoice_LineItem_PriceComponent{
+			component(c4pb.InvoicePriceComponentTypeCode_BASE, fhirtypes.Money("20", "EUR")),
+			component(c4pb.InvoicePriceComponentTypeCode_SURCHARGE, fhirtypes.Money("2.50", "EUR")),
+			component(c4pb.InvoicePriceComponentTypeCode_DEDUCTION, fhirtypes.Money("5", "EUR")),
+		},
+	}}}
+	if err := SetTotals(inv); err != nil {
+		t.Fatalf("SetTotals() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(fhirtypes.Money("107.50", "EUR"), inv.GetTotalNet(), protocmp.Transform()); diff != "" {
+		t.Errorf("SetTotals() net diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(fhirtypes.Money("124.60", "EUR"), inv.GetTotalGross(), protocmp.Transform()); diff != "" {
+		t.Errorf("SetTotals() gross diff (-want +got):\n%s", diff)
+	}
+
+	mixed := &invpb.Invoice{LineItem: []*invpb.Invoice_LineItem{{
+		PriceComponent: []*invpb.Invoice_LineItem_PriceComponent{
+			component(c4pb.InvoicePriceComponentTypeCode_BASE, fhirtypes.Money("1", "EUR")),
+			component(c4pb.InvoicePriceComponentTypeCode_BASE, fhirtypes.Money("1", "USD")),
+		},
+	}}}
+	if err := SetTotals(mixed); err == nil {
+		t.Errorf("SetTotals() succeeded for mixed currencies, want error")
+	}
+}
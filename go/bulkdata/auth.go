@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulkdata
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/fhir/go/jose"
+)
+
+// TokenSource provides the access tokens of requests.
+type TokenSource interface {
+	// Token returns a valid access token.
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource of a fixed access token.
+type StaticToken string
+
+// Token returns t.
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// assertionLifetime is the lifetime of the client assertions of
+// BackendServices, the longest the specification allows.
+const assertionLifetime = 5 * time.Minute
+
+// BackendServices is a TokenSource of the SMART Backend Services
+// authorization: it obtains access tokens with the client credentials grant,
+// authenticating with a JWT signed by the private key of the client. Tokens
+// are reused until shortly before they expire. A BackendServices may be used
+// concurrently.
+type BackendServices struct {
+	// TokenURL is the token endpoint of the authorization server.
+	TokenURL string
+	// ClientID is the client ID registered with the server.
+	ClientID string
+	// Key is the private key of the client, whose public key is registered
+	// with the server. See jose.Sign for the supported keys; ES384 keys are
+	// the most widely accepted.
+	Key crypto.Signer
+	// KeyID is the kid of Key in the JWK set of the client.
+	KeyID string
+	// Scope is the requested scope, i.e. "system/*.read".
+	Scope string
+	// HTTPClient is used for the token requests; http.DefaultClient is used
+	// if it is nil.
+	HTTPClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// tokenResponse is the response of a token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Token returns the current access token, requesting a new one if it expires
+// within a minute.
+func (b *BackendServices) Token(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if b.token != "" && now.Add(time.Minute).Before(b.expiry) {
+		return b.token, nil
+	}
+	assertion, err := b.assertion(now)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"scope":                 {b.Scope},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient(b.HTTPClient).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("requesting access token: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	var tr tokenResponse
+	if err := json.Unmarshal(data, &tr); err != nil {
+		return "", fmt.Errorf("requesting access token: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return "", fmt.Errorf("requesting access token: no access_token in response")
+	}
+	b.token, b.expiry = tr.AccessToken, now.Add(time.Duration(tr.ExpiresIn)*time.Second)
+	return b.token, nil
+}
+
+// assertion returns the client assertion of a token request made at now.
+func (b *BackendServices) assertion(now time.Time) (string, error) {
+	var jti [16]byte
+	if _, err := rand.Read(jti[:]); err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss": b.ClientID,
+		"sub": b.ClientID,
+		"aud": b.TokenURL,
+		"exp": now.Add(assertionLifetime).Unix(),
+		"jti": hex.EncodeToString(jti[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+	return jose.Sign(jose.Header{Kid: b.KeyID, Typ: "JWT"}, claims, b.Key)
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
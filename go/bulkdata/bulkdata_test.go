@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulkdata
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/jose"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testNDJSON = `{"resourceType":"Patient","id":"p1"}
+{"resourceType":"Patient","id":"p2"}
+`
+
+// testServer is an export server requiring Backend Services authorization.
+type testServer struct {
+	*httptest.Server
+	key *ecdsa.PrivateKey
+
+	mu sync.Mutex
+	// polls is the number of status requests answered with 202.
+	polls, tokens int
+	kickoffs      []string
+	ranges        []string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() returned unexpected error: %v", err)
+	}
+	s := &testServer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/fhir/", s.fhir)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+	_, payload, err := jose.Verify(r.Form.Get("client_assertion"), nil, func(h jose.Header) (crypto.PublicKey, error) {
+		if h.Kid != "k1" {
+			return nil, fmt.Errorf("unknown key %q", h.Kid)
+		}
+		return &s.key.PublicKey, nil
+	})
+	var claims struct{ Iss, Aud string }
+	if err == nil {
+		err = json.Unmarshal(payload, &claims)
+	}
+	if err != nil || claims.Iss != "client" || claims.Aud != s.URL+"/token" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	s.mu.Lock()
+	s.tokens++
+	s.mu.Unlock()
+	fmt.Fprint(w, `{"access_token":"secret","token_type":"bearer","expires_in":300}`)
+}
+
+func (s *testServer) fhir(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/$export"):
+		if r.Header.Get("Prefer") != "respond-async" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.kickoffs = append(s.kickoffs, r.URL.RequestURI())
+		w.Header().Set("Content-Location", "/fhir/status/1")
+		w.WriteHeader(http.StatusAccepted)
+	case r.URL.Path == "/fhir/status/1":
+		if s.polls < 2 {
+			s.polls++
+			w.Header().Set("X-Progress", fmt.Sprintf("%d%%", s.polls*50))
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		fmt.Fprintf(w, `{"transactionTime":"2026-01-02T03:04:05Z","request":"%s/fhir/$export","requiresAccessToken":true,`+
+			`"output":[{"type":"Patient","url":"%s/fhir/files/Patient.ndjson","count":2}],"error":[]}`, s.URL, s.URL)
+	case r.URL.Path == "/fhir/files/Patient.ndjson":
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "Patient.ndjson", time.Time{}, strings.NewReader(testNDJSON))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *testServer) client() *Client {
+	return &Client{
+		BaseURL: s.URL + "/fhir",
+		Auth: &BackendServices{
+			TokenURL: s.URL + "/token",
+			ClientID: "client",
+			Key:      s.key,
+			KeyID:    "k1",
+			Scope:    "system/*.read",
+		},
+		PollInterval: time.Millisecond,
+	}
+}
+
+func TestExport(t *testing.T) {
+	s := newTestServer(t)
+	c := s.client()
+	var progress []string
+	c.Progress = func(st Status) { progress = append(progress, st.Progress) }
+	ctx := context.Background()
+
+	status, err := c.Kickoff(ctx, ExportRequest{Level: Group, GroupID: "g1", Types: []string{"Patient", "Observation"}})
+	if err != nil {
+		t.Fatalf("Kickoff() returned unexpected error: %v", err)
+	}
+	if want := s.URL + "/fhir/status/1"; status != want {
+		t.Errorf("Kickoff() = %q, want %q", status, want)
+	}
+	m, err := c.Wait(ctx, status)
+	if err != nil {
+		t.Fatalf("Wait() returned unexpected error: %v", err)
+	}
+	want := &Manifest{
+		TransactionTime:     "2026-01-02T03:04:05Z",
+		Request:             s.URL + "/fhir/$export",
+		RequiresAccessToken: true,
+		Output:              []File{{Type: "Patient", URL: s.URL + "/fhir/files/Patient.ndjson", Count: 2}},
+		Error:               []File{},
+	}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Errorf("Wait() diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"50%", "100%"}, progress); diff != "" {
+		t.Errorf("Wait() progress diff (-want +got):\n%s", diff)
+	}
+
+	path := filepath.Join(t.TempDir(), "Patient.ndjson")
+	n, err := c.Download(ctx, m.Output[0], m.RequiresAccessToken, path)
+	if err != nil {
+		t.Fatalf("Download() returned unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+	if string(got) != testNDJSON || n != int64(len(testNDJSON)) {
+		t.Errorf("Download() wrote %d bytes %q, want %q", n, got, testNDJSON)
+	}
+	if diff := cmp.Diff([]string{"/fhir/Group/g1/$export?_type=Patient%2CObservation"}, s.kickoffs); diff != "" {
+		t.Errorf("kick-off requests diff (-want +got):\n%s", diff)
+	}
+	if s.tokens != 1 {
+		t.Errorf("server issued %d tokens, want 1", s.tokens)
+	}
+}
+
+func TestDownload_Resume(t *testing.T) {
+	tests := []struct {
+		name      string
+		part      string
+		wantRange string
+	}{
+		{"partial", testNDJSON[:10], "bytes=10-"},
+		{"complete", testNDJSON, fmt.Sprintf("bytes=%d-", len(testNDJSON))},
+		{"longer than the file", testNDJSON + "garbage", fmt.Sprintf("bytes=%d-", len(testNDJSON)+7)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestServer(t)
+			path := filepath.Join(t.TempDir(), "Patient.ndjson")
+			if err := os.WriteFile(path+PartSuffix, []byte(test.part), 0644); err != nil {
+				t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+			}
+			f := File{Type: "Patient", URL: s.URL + "/fhir/files/Patient.ndjson"}
+			if _, err := s.client().Download(context.Background(), f, true, path); err != nil {
+				t.Fatalf("Download() returned unexpected error: %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, []byte(testNDJSON)) {
+				t.Errorf("Download() wrote %q, want %q", got, testNDJSON)
+			}
+			if _, err := os.Stat(path + PartSuffix); !os.IsNotExist(err) {
+				t.Errorf("Download() left the part file, stat error: %v", err)
+			}
+			if len(s.ranges) == 0 || s.ranges[0] != test.wantRange {
+				t.Errorf("Download() sent ranges %q, want first %q", s.ranges, test.wantRange)
+			}
+		})
+	}
+}
+
+func TestPoll_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/throttled":
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"resourceType":"OperationOutcome"}`)
+		}
+	}))
+	defer srv.Close()
+	c := &Client{BaseURL: srv.URL}
+	ctx := context.Background()
+
+	m, st, err := c.Poll(ctx, srv.URL+"/throttled")
+	if err != nil || m != nil || st.RetryAfter != 7*time.Second {
+		t.Errorf("Poll(throttled) = %v, %+v, %v, want retry after 7s", m, st, err)
+	}
+	if _, _, err := c.Poll(ctx, srv.URL+"/unavailable"); err == nil {
+		t.Errorf("Poll(unavailable) succeeded, want error")
+	}
+	if _, _, err := c.Poll(ctx, srv.URL+"/failed"); err == nil || !strings.Contains(err.Error(), "OperationOutcome") {
+		t.Errorf("Poll(failed) returned error %v, want the OperationOutcome", err)
+	}
+	if _, err := c.Kickoff(ctx, ExportRequest{Level: Group}); err == nil {
+		t.Errorf("Kickoff() of a group export without group succeeded, want error")
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bulkdata is a client of the FHIR Bulk Data Access export
+// operation.
+//
+// A Client starts a system, group or patient level $export, polls its status
+// until the server publishes the manifest of the output files, and downloads
+// them. Downloads resume from the partial file left by an interrupted
+// download when the server supports range requests. BackendServices
+// authorizes the requests with the SMART Backend Services profile. The
+// downloaded files can be decoded with package ndjson.
+package bulkdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Level is the level of an export.
+type Level int
+
+// Levels of exports.
+const (
+	// System exports the data of all patients and more of the server.
+	System Level = iota
+	// Group exports the data of the patients of a Group.
+	Group
+	// Patient exports the data of all patients.
+	Patient
+)
+
+// ExportRequest describes an export.
+type ExportRequest struct {
+	Level Level
+	// GroupID is the ID of the Group of a Group export.
+	GroupID string
+	// Types are the resource types to export; all types if empty.
+	Types []string
+	// Since limits the export to resources updated after it, if it is set.
+	Since time.Time
+	// OutputFormat is the format of the output files; the server default,
+	// NDJSON, if empty.
+	OutputFormat string
+}
+
+// path returns the path of the kick-off request of r, relative to the base
+// URL of the server.
+func (r ExportRequest) path() (string, error) {
+	var p string
+	switch r.Level {
+	case System:
+		p = "/$export"
+	case Group:
+		if r.GroupID == "" {
+			return "", fmt.Errorf("group export without group ID")
+		}
+		p = "/Group/" + url.PathEscape(r.GroupID) + "/$export"
+	case Patient:
+		p = "/Patient/$export"
+	default:
+		return "", fmt.Errorf("unknown export level %d", r.Level)
+	}
+	q := url.Values{}
+	if len(r.Types) > 0 {
+		q.Set("_type", strings.Join(r.Types, ","))
+	}
+	if !r.Since.IsZero() {
+		q.Set("_since", r.Since.Format(time.RFC3339))
+	}
+	if r.OutputFormat != "" {
+		q.Set("_outputFormat", r.OutputFormat)
+	}
+	if len(q) > 0 {
+		p += "?" + q.Encode()
+	}
+	return p, nil
+}
+
+// Manifest is the manifest of a completed export.
+type Manifest struct {
+	TransactionTime     string `json:"transactionTime"`
+	Request             string `json:"request"`
+	RequiresAccessToken bool   `json:"requiresAccessToken"`
+	Output              []File `json:"output"`
+	// Error are the files of the OperationOutcomes of the errors of the
+	// export.
+	Error []File `json:"error"`
+}
+
+// File is an output file of an export.
+type File struct {
+	// Type is the resource type of the resources of the file.
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Count int    `json:"count,omitempty"`
+}
+
+// Status is the status of an export in progress.
+type Status struct {
+	// Progress is the X-Progress header of the server, if any.
+	Progress string
+	// RetryAfter is when the server asks to be polled again; zero if it does
+	// not say.
+	RetryAfter time.Duration
+}
+
+// DefaultPollInterval is the interval between the status requests of Wait
+// when the server does not ask for one.
+const DefaultPollInterval = 10 * time.Second
+
+// Client is a client of the export operation of a FHIR server.
+type Client struct {
+	// BaseURL is the FHIR base URL of the server, i.e.
+	// "https://example.com/fhir".
+	BaseURL string
+	// HTTPClient is used for the requests; http.DefaultClient is used if it
+	// is nil.
+	HTTPClient *http.Client
+	// Auth provides the access tokens of the requests, which are sent without
+	// one if it is nil.
+	Auth TokenSource
+	// PollInterval is the interval between status requests when the server
+	// does not ask for one; DefaultPollInterval if 0.
+	PollInterval time.Duration
+	// Progress, if set, is called with the status of the export on each
+	// status request of Wait that finds it in progress.
+	Progress func(Status)
+}
+
+// Kickoff starts the export r and returns the URL of its status.
+func (c *Client) Kickoff(ctx context.Context, r ExportRequest) (string, error) {
+	p, err := r.path()
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(ctx, http.MethodGet, strings.TrimSuffix(c.BaseURL, "/")+p, true, func(h http.Header) {
+		h.Set("Accept", "application/fhir+json")
+		h.Set("Prefer", "respond-async")
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return "", responseError("starting export", resp)
+	}
+	loc := resp.Header.Get("Content-Location")
+	if loc == "" {
+		return "", fmt.Errorf("starting export: no Content-Location in response")
+	}
+	return resolve(resp.Request.URL, loc)
+}
+
+// Poll requests the status of the export at statusURL once. It returns the
+// manifest of the export if it is complete, and its status otherwise.
+func (c *Client) Poll(ctx context.Context, statusURL string) (*Manifest, Status, error) {
+	resp, err := c.do(ctx, http.MethodGet, statusURL, true, func(h http.Header) {
+		h.Set("Accept", "application/json")
+	})
+	if err != nil {
+		return nil, Status{}, err
+	}
+	defer resp.Body.Close()
+	st := Status{Progress: resp.Header.Get("X-Progress"), RetryAfter: RetryAfter(resp.Header)}
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// Servers throttling clients answer 429 or 503 with a Retry-After
+		// while the export goes on.
+		if resp.StatusCode != http.StatusAccepted && st.RetryAfter == 0 {
+			return nil, st, responseError("polling export", resp)
+		}
+		return nil, st, nil
+	case http.StatusOK:
+		m := &Manifest{}
+		if err := json.NewDecoder(resp.Body).Decode(m); err != nil {
+			return nil, st, fmt.Errorf("polling export: reading manifest: %w", err)
+		}
+		return m, st, nil
+	default:
+		return nil, st, responseError("polling export", resp)
+	}
+}
+
+// Wait polls the status of the export at statusURL until it completes, and
+// returns its manifest.
+func (c *Client) Wait(ctx context.Context, statusURL string) (*Manifest, error) {
+	for {
+		m, st, err := c.Poll(ctx, statusURL)
+		if err != nil || m != nil {
+			return m, err
+		}
+		if c.Progress != nil {
+			c.Progress(st)
+		}
+		wait := st.RetryAfter
+		if wait == 0 {
+			wait = c.PollInterval
+		}
+		if wait == 0 {
+			wait = DefaultPollInterval
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Cancel cancels the export at statusURL, or deletes its output files if it
+// is complete.
+func (c *Client) Cancel(ctx context.Context, statusURL string) error {
+	resp, err := c.do(ctx, http.MethodDelete, statusURL, true, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return responseError("cancelling export", resp)
+	}
+	return nil
+}
+
+// do sends a request with the access token of c if auth is set.
+func (c *Client) do(ctx context.Context, method, u string, auth bool, header func(http.Header)) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		header(req.Header)
+	}
+	if auth && c.Auth != nil {
+		token, err := c.Auth.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return httpClient(c.HTTPClient).Do(req)
+}
+
+// responseError returns the error of the unexpected response resp, with its
+// body, usually an OperationOutcome.
+func responseError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if len(body) == 0 {
+		return fmt.Errorf("%s: %s", op, resp.Status)
+	}
+	return fmt.Errorf("%s: %s: %s", op, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// RetryAfter returns the delay of the Retry-After header of h, in seconds or
+// as an HTTP date.
+func RetryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func resolve(base *url.URL, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(u).String(), nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulkdata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PartSuffix is the suffix of the files downloads are written to until they
+// complete.
+const PartSuffix = ".part"
+
+// Download downloads the output file f of an export to path, and returns its
+// size. requiresToken is the RequiresAccessToken of the manifest of the
+// export. The data is written to path+PartSuffix, which is renamed to path
+// once complete. A partial file left by an interrupted download is resumed
+// with a range request, or downloaded again if the server does not support
+// them.
+func (c *Client) Download(ctx context.Context, f File, requiresToken bool, path string) (int64, error) {
+	part := path + PartSuffix
+	offset := int64(0)
+	if fi, err := os.Stat(part); err == nil {
+		offset = fi.Size()
+	}
+	resp, err := c.do(ctx, http.MethodGet, f.URL, requiresToken, func(h http.Header) {
+		h.Set("Accept", "application/fhir+ndjson")
+		if offset > 0 {
+			h.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+	case http.StatusPartialContent:
+		if start, ok := rangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return 0, fmt.Errorf("downloading %s: unexpected Content-Range %q", f.URL, resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The part file holds the whole file if its size is the one the
+		// server reports.
+		if size, ok := rangeSize(resp.Header.Get("Content-Range")); ok && size == offset {
+			return offset, os.Rename(part, path)
+		}
+		if err := os.Remove(part); err != nil {
+			return 0, err
+		}
+		return c.Download(ctx, f, requiresToken, path)
+	default:
+		return 0, responseError("downloading "+f.URL, resp)
+	}
+	out, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, resp.Body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("downloading %s: %w", f.URL, err)
+	}
+	return offset + n, os.Rename(part, path)
+}
+
+// rangeStart returns the first byte of a Content-Range "bytes a-b/n".
+func rangeStart(cr string) (int64, bool) {
+	if !strings.HasPrefix(cr, "bytes ") {
+		return 0, false
+	}
+	a, _, ok := strings.Cut(strings.TrimPrefix(cr, "bytes "), "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(a, 10, 64)
+	return start, err == nil
+}
+
+// rangeSize returns the complete length of a Content-Range "bytes */n".
+func rangeSize(cr string) (int64, bool) {
+	_, n, ok := strings.Cut(cr, "/")
+	if !ok || n == "*" {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(n, 10, 64)
+	return size, err == nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ndjson reads and writes the files of FHIR bulk data, such as the
+// output of $export, which hold one JSON resource per line:
+//
+//	r := ndjson.NewReader(f, u)
+//	for {
+//		res, err := r.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		var lerr *ndjson.LineError
+//		if errors.As(err, &lerr) {
+//			// Report the line and go on with the next.
+//			continue
+//		}
+//		...
+//	}
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/lines"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultMaxLineSize is the default limit on the size of a line.
+const DefaultMaxLineSize = 64 << 20
+
+// ErrLineTooLong is the error of lines longer than the limit of a Reader.
+var ErrLineTooLong = errors.New("line too long")
+
+// LineError is the failure to decode a line.
+type LineError struct {
+	// Line is the 1-based number of the line.
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Reader decodes the resources of NDJSON one line at a time.
+type Reader struct {
+	// MaxLineSize bounds the size of lines, which are skipped and reported
+	// with ErrLineTooLong beyond it. DefaultMaxLineSize is used if it is 0.
+	MaxLineSize int
+
+	r     io.Reader
+	lines lines.Source
+	u     *jsonformat.Unmarshaller
+	line  int
+	err   error
+}
+
+// NewReader returns a Reader of the NDJSON in r, whose resources are decoded
+// and validated by u.
+func NewReader(r io.Reader, u *jsonformat.Unmarshaller) *Reader {
+	return &Reader{r: r, u: u}
+}
+
+// Next returns the resource of the next line that is not blank, as its typed
+// proto, such as a *ppb.Patient, and io.EOF once all lines have been read.
+// A line that fails to decode or validate is reported as a *LineError, and
+// the next call goes on with the following line; other errors, from reading
+// the input, end the reading.
+func (r *Reader) Next() (proto.Message, error) {
+	if r.lines == nil {
+		max := r.MaxLineSize
+		if max <= 0 {
+			max = DefaultMaxLineSize
+		}
+		r.lines = lines.Buffered(r.r, max)
+	}
+	for r.err == nil {
+		data, tooLong, err := r.lines()
+		if err != nil && err != io.EOF {
+			r.err = err
+			break
+		}
+		if err == io.EOF {
+			r.err = io.EOF
+		}
+		if len(data) == 0 && !tooLong && err == io.EOF {
+			break
+		}
+		r.line++
+		if tooLong {
+			return nil, &LineError{Line: r.line, Err: ErrLineTooLong}
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		cr, err := r.u.Unmarshal(data)
+		if err != nil {
+			return nil, &LineError{Line: r.line, Err: err}
+		}
+		res := elementpath.Unwrap(cr)
+		if res == nil {
+			return nil, &LineError{Line: r.line, Err: errors.New("no resource")}
+		}
+		return res, nil
+	}
+	return nil, r.err
+}
+
+// Line returns the number of the line Next last read.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Writer writes resources as NDJSON.
+type Writer struct {
+	bw *bufio.Writer
+	m  *jsonformat.Marshaller
+	n  int
+}
+
+// NewWriter returns a Writer of resources of version ver to w. The output
+// is buffered until Flush.
+func NewWriter(w io.Writer, ver fhirversion.Version) (*Writer, error) {
+	m, err := jsonformat.NewMarshaller(false, "", "", ver)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{bw: bufio.NewWriter(w), m: m}, nil
+}
+
+// Write writes the resource res, or the resource held by the
+// ContainedResource res, as a line.
+func (w *Writer) Write(res proto.Message) error {
+	res = elementpath.Unwrap(res)
+	if res == nil {
+		return errors.New("no resource")
+	}
+	data, err := w.m.MarshalResource(res)
+	if err != nil {
+		return err
+	}
+	if _, err := w.bw.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	w.n++
+	return nil
+}
+
+// Count returns the number of resources written.
+func (w *Writer) Count() int {
+	return w.n
+}
+
+// Flush writes the buffered output to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.bw.Flush()
+}
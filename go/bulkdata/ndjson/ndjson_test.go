@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ndjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func patient(id string) *ppb.Patient {
+	return &ppb.Patient{Id: &d4pb.Id{Value: id}, Active: &d4pb.Boolean{Value: true}}
+}
+
+func newUnmarshaller(t *testing.T) *jsonformat.Unmarshaller {
+	t.Helper()
+	u, err := jsonformat.NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	return u
+}
+
+func TestReader(t *testing.T) {
+	in := `{"resourceType":"Patient","id":"p1","active":true}` + "\r\n" +
+		"\n" +
+		`{"resourceType":"Patient","id":"p2","active":"yes"}` + "\n" +
+		`not json` + "\n" +
+		`{"resourceType":"Patient","id":"` + strings.Repeat("x", 100) + `"}` + "\n" +
+		`{"resourceType":"Patient","id":"p3","active":true}`
+	r := NewReader(strings.NewReader(in), newUnmarshaller(t))
+	r.MaxLineSize = 80
+	var got []proto.Message
+	var errLines []int
+	for {
+		res, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		var lerr *LineError
+		if errors.As(err, &lerr) {
+			if lerr.Line != r.Line() {
+				t.Errorf("Next() returned error on line %d, Line() = %d", lerr.Line, r.Line())
+			}
+			errLines = append(errLines, lerr.Line)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Next() returned unexpected error: %v", err)
+		}
+		got = append(got, res)
+	}
+	if diff := cmp.Diff([]proto.Message{patient("p1"), patient("p3")}, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Next() diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{3, 4, 5}, errLines); diff != "" {
+		t.Errorf("Next() error lines diff (-want +got):\n%s", diff)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() after the end returned %v, want %v", err, io.EOF)
+	}
+}
+
+func TestReader_LineTooLong(t *testing.T) {
+	r := NewReader(strings.NewReader(strings.Repeat("x", 100)+"\n"), newUnmarshaller(t))
+	r.MaxLineSize = 10
+	if _, err := r.Next(); !errors.Is(err, ErrLineTooLong) {
+		t.Errorf("Next() returned error %v, want %v", err, ErrLineTooLong)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewWriter() returned unexpected error: %v", err)
+	}
+	want := []proto.Message{patient("p1"), patient("p2")}
+	if err := w.Write(want[0]); err != nil {
+		t.Fatalf("Write() returned unexpected error: %v", err)
+	}
+	cr := &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: patient("p2")}}
+	if err := w.Write(cr); err != nil {
+		t.Fatalf("Write() returned unexpected error: %v", err)
+	}
+	if err := w.Write(&r4pb.ContainedResource{}); err == nil {
+		t.Errorf("Write() of an empty ContainedResource succeeded, want error")
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() returned unexpected error: %v", err)
+	}
+	if got := w.Count(); got != 2 {
+		t.Errorf("Count() = %d, want 2", got)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("Write() wrote %d lines, want 2:\n%s", got, buf.String())
+	}
+
+	r := NewReader(&buf, newUnmarshaller(t))
+	var got []proto.Message
+	for {
+		res, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() returned unexpected error: %v", err)
+		}
+		got = append(got, res)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Next() diff (-want +got):\n%s", diff)
+	}
+}
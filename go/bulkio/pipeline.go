@@ -0,0 +1,443 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bulkio processes FHIR bulk data files, which hold one JSON resource
+// per line (NDJSON), across a pool of workers.
+package bulkio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/lines"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultMaxLineSize is the default limit on the size of a line.
+const DefaultMaxLineSize = 64 << 20
+
+// errMmapUnsupported is returned by mapFile on platforms without memory
+// mappings.
+var errMmapUnsupported = errors.New("memory mappings are not supported")
+
+// Stage is a stage of a Pipeline.
+type Stage string
+
+// Stages, in the order lines go through them.
+const (
+	ReadStage      Stage = "read"
+	UnmarshalStage Stage = "unmarshal"
+	TransformStage Stage = "transform"
+	MarshalStage   Stage = "marshal"
+	WriteStage     Stage = "write"
+)
+
+// LineError is the failure of a stage to process a line.
+type LineError struct {
+	// Line is the 1-based line of the resource in the input.
+	Line  int
+	Stage Stage
+	Err   error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Stage, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Transform transforms a resource. Returning a nil resource drops it from
+// the output.
+type Transform func(ctx context.Context, res proto.Message) (proto.Message, error)
+
+// StageMetrics are the metrics of a stage.
+type StageMetrics struct {
+	// Processed is the number of lines or resources the stage processed,
+	// including failed ones.
+	Processed int64
+	// Failed is the number of lines or resources the stage failed on.
+	Failed int64
+	// Time is the time spent in the stage, summed over workers.
+	Time time.Duration
+}
+
+func (m *StageMetrics) add(o StageMetrics) {
+	m.Processed += o.Processed
+	m.Failed += o.Failed
+	m.Time += o.Time
+}
+
+// record records that the stage processed one line since start, failing if
+// err is not nil.
+func (m *StageMetrics) record(start time.Time, err error) {
+	m.Processed++
+	if err != nil {
+		m.Failed++
+	}
+	m.Time += time.Since(start)
+}
+
+// Metrics are the metrics of a run of a Pipeline.
+type Metrics struct {
+	Read      StageMetrics
+	Unmarshal StageMetrics
+	Transform StageMetrics
+	Marshal   StageMetrics
+	Write     StageMetrics
+	// Dropped is the number of resources the Transform dropped.
+	Dropped int64
+}
+
+func (m *Metrics) add(o Metrics) {
+	m.Read.add(o.Read)
+	m.Unmarshal.add(o.Unmarshal)
+	m.Transform.add(o.Transform)
+	m.Marshal.add(o.Marshal)
+	m.Write.add(o.Write)
+	m.Dropped += o.Dropped
+}
+
+// Pipeline reads resources from NDJSON, unmarshals, validates and transforms
+// them across a pool of workers, and writes them back as NDJSON.
+type Pipeline struct {
+	// Version is the FHIR version of the resources; R4 if empty.
+	Version fhirversion.Version
+	// TimeZone is the IANA time zone of dates and times without an offset;
+	// UTC if empty.
+	TimeZone string
+	// Validate enables the extended validation of resources, such as their
+	// required fields and references, on top of the validation of their
+	// primitives.
+	Validate bool
+	// Transform, if set, is applied to every resource. It is called
+	// concurrently by the workers.
+	Transform Transform
+	// Workers is the number of workers; runtime.GOMAXPROCS(0) if 0 or less.
+	Workers int
+	// Ordered writes resources in the order of their lines. Otherwise they are
+	// written as soon as they are processed.
+	Ordered bool
+	// MaxLineSize is the limit on the size of a line, in bytes;
+	// DefaultMaxLineSize if 0 or less.
+	MaxLineSize int
+	// Mmap makes RunFile map the file in memory instead of reading it, on the
+	// platforms that support it, which saves copying the lines of very large
+	// files. The file must not be modified while it is processed.
+	Mmap bool
+	// Errors, if set, receives the errors of lines, which are then skipped.
+	// Run blocks on sending to it and never closes it. If Errors is nil, Run
+	// stops at the first error.
+	Errors chan<- *LineError
+}
+
+type job struct {
+	seq  int
+	line int
+	data []byte
+	// err is set for lines that could not be read.
+	err *LineError
+}
+
+type result struct {
+	seq  int
+	line int
+	// out is nil for dropped resources.
+	out []byte
+	err *LineError
+}
+
+// Run processes the NDJSON in r and writes the output to w. Blank lines are
+// skipped. It returns once the input is exhausted and processed, or on the
+// first error that stops it, along with the metrics of the run.
+func (p *Pipeline) Run(ctx context.Context, r io.Reader, w io.Writer) (Metrics, error) {
+	return p.run(ctx, lines.Buffered(r, p.maxLineSize()), w)
+}
+
+// RunFile processes the NDJSON file at path like Run. If p.Mmap is set and
+// memory mappings are supported, the file is mapped rather than read, and
+// its lines are unmarshalled in place.
+func (p *Pipeline) RunFile(ctx context.Context, path string, w io.Writer) (Metrics, error) {
+	if p.Mmap {
+		data, unmap, err := mapFile(path)
+		if err == nil {
+			// run returns once the workers are done with the mapped lines.
+			defer unmap()
+			return p.run(ctx, lines.Mapped(data, p.maxLineSize()), w)
+		}
+		if err != errMmapUnsupported {
+			return Metrics{}, fmt.Errorf("mapping %s: %w", path, err)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Metrics{}, err
+	}
+	defer f.Close()
+	return p.Run(ctx, f, w)
+}
+
+func (p *Pipeline) maxLineSize() int {
+	if p.MaxLineSize <= 0 {
+		return DefaultMaxLineSize
+	}
+	return p.MaxLineSize
+}
+
+func (p *Pipeline) run(ctx context.Context, next lines.Source, w io.Writer) (Metrics, error) {
+	ver := p.Version
+	if ver == "" {
+		ver = fhirversion.R4
+	}
+	tz := p.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	// Unmarshallers and Marshallers are per worker.
+	type codec struct {
+		um *jsonformat.Unmarshaller
+		m  *jsonformat.Marshaller
+	}
+	codecs := make([]codec, workers)
+	for i := range codecs {
+		var err error
+		if p.Validate {
+			codecs[i].um, err = jsonformat.NewUnmarshaller(tz, ver)
+		} else {
+			codecs[i].um, err = jsonformat.NewUnmarshallerWithoutValidation(tz, ver)
+		}
+		if err != nil {
+			return Metrics{}, fmt.Errorf("creating unmarshaller: %w", err)
+		}
+		if codecs[i].m, err = jsonformat.NewMarshaller(false, "", "", ver); err != nil {
+			return Metrics{}, fmt.Errorf("creating marshaller: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Tokens bound the lines in flight, and so the results waiting to be
+	// written in order.
+	tokens := make(chan struct{}, 2*workers)
+	jobs := make(chan job)
+	results := make(chan result)
+	workerMetrics := make([]Metrics, workers+1)
+
+	var readErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		readErr = p.read(ctx, next, tokens, jobs, &workerMetrics[workers].Read)
+	}()
+	var workersWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workersWG.Add(1)
+		go func(c codec, m *Metrics) {
+			defer workersWG.Done()
+			for j := range jobs {
+				res := p.process(ctx, c.um, c.m, j, m)
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(codecs[i], &workerMetrics[i])
+	}
+	go func() {
+		workersWG.Wait()
+		close(results)
+	}()
+
+	var metrics Metrics
+	err := p.write(ctx, w, tokens, results, &metrics.Write)
+	cancel()
+	// Drain the workers, which may be blocked on sending results.
+	for range results {
+	}
+	wg.Wait()
+	for _, m := range workerMetrics {
+		metrics.add(m)
+	}
+	if err == nil {
+		err = readErr
+	}
+	return metrics, err
+}
+
+// read sends the lines of next to jobs, taking a token for each.
+func (p *Pipeline) read(ctx context.Context, next lines.Source, tokens chan<- struct{}, jobs chan<- job, m *StageMetrics) error {
+	seq := 0
+	for line := 1; ; line++ {
+		start := time.Now()
+		data, tooLong, err := next()
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("reading line %d: %w", line, err)
+		}
+		if len(bytes.TrimSpace(data)) > 0 || tooLong {
+			j := job{seq: seq, line: line, data: data}
+			var lineErr error
+			if tooLong {
+				lineErr = fmt.Errorf("line exceeds %d bytes", p.maxLineSize())
+				j.data, j.err = nil, &LineError{Line: line, Stage: ReadStage, Err: lineErr}
+			}
+			m.record(start, lineErr)
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return nil
+			}
+			seq++
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// process unmarshals, transforms and marshals the resource of j.
+func (p *Pipeline) process(ctx context.Context, um *jsonformat.Unmarshaller, ma *jsonformat.Marshaller, j job, m *Metrics) result {
+	r := result{seq: j.seq, line: j.line}
+	if j.err != nil {
+		r.err = j.err
+		return r
+	}
+	fail := func(s Stage, err error) result {
+		r.err = &LineError{Line: j.line, Stage: s, Err: err}
+		return r
+	}
+
+	start := time.Now()
+	cr, err := um.Unmarshal(j.data)
+	m.Unmarshal.record(start, err)
+	if err != nil {
+		return fail(UnmarshalStage, err)
+	}
+	res := elementpath.Unwrap(cr)
+	if res == nil {
+		return fail(UnmarshalStage, errors.New("no resource"))
+	}
+
+	if p.Transform != nil {
+		start = time.Now()
+		res, err = p.Transform(ctx, res)
+		m.Transform.record(start, err)
+		if err != nil {
+			return fail(TransformStage, err)
+		}
+		if res == nil {
+			m.Dropped++
+			return r
+		}
+	}
+
+	start = time.Now()
+	r.out, err = ma.MarshalResource(res)
+	m.Marshal.record(start, err)
+	if err != nil {
+		return fail(MarshalStage, err)
+	}
+	return r
+}
+
+// write writes results to w, in the order of their lines if p.Ordered,
+// returning a token for each.
+func (p *Pipeline) write(ctx context.Context, w io.Writer, tokens <-chan struct{}, results <-chan result, m *StageMetrics) error {
+	bw := bufio.NewWriter(w)
+	handle := func(r result) error {
+		<-tokens
+		if r.err != nil {
+			return p.report(ctx, r.err)
+		}
+		if r.out == nil {
+			return nil
+		}
+		start := time.Now()
+		_, err := bw.Write(r.out)
+		if err == nil {
+			err = bw.WriteByte('\n')
+		}
+		m.record(start, err)
+		if err != nil {
+			return &LineError{Line: r.line, Stage: WriteStage, Err: err}
+		}
+		return nil
+	}
+
+	pending := map[int]result{}
+	next := 0
+	for r := range results {
+		if !p.Ordered {
+			if err := handle(r); err != nil {
+				return err
+			}
+			continue
+		}
+		pending[r.seq] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if err := handle(r); err != nil {
+				return err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
+
+// report sends err to p.Errors, or returns it if there is none.
+func (p *Pipeline) report(ctx context.Context, err *LineError) error {
+	if p.Errors == nil {
+		return err
+	}
+	select {
+	case p.Errors <- err:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
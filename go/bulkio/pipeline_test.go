@@ -0,0 +1,266 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulkio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func patients(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, `{"resourceType":"Patient","id":"p%d"}`+"\n", i)
+	}
+	return sb.String()
+}
+
+// ids returns the ids of the Patients of NDJSON output.
+func ids(t *testing.T, out string) []string {
+	t.Helper()
+	var got []string
+	for _, l := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if l == "" {
+			continue
+		}
+		i := strings.Index(l, `"id":"`)
+		if i < 0 {
+			t.Fatalf("output line %q has no id", l)
+		}
+		id := l[i+len(`"id":"`):]
+		got = append(got, id[:strings.IndexByte(id, '"')])
+	}
+	return got
+}
+
+// stall delays the Patients with low ids most, so that workers finish them
+// last.
+func stall(ctx context.Context, res proto.Message) (proto.Message, error) {
+	var n int
+	fmt.Sscanf(res.(*ppb.Patient).GetId().GetValue(), "p%d", &n)
+	if n < 4 {
+		time.Sleep(time.Duration(4-n) * 10 * time.Millisecond)
+	}
+	return res, nil
+}
+
+func TestRun_Ordered(t *testing.T) {
+	p := &Pipeline{Workers: 4, Ordered: true, Transform: stall}
+	var out bytes.Buffer
+	metrics, err := p.Run(context.Background(), strings.NewReader(patients(20)), &out)
+	if err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+	want := ids(t, patients(20))
+	if diff := cmp.Diff(want, ids(t, out.String())); diff != "" {
+		t.Errorf("Run() output diff (-want +got):\n%s", diff)
+	}
+	for _, s := range []struct {
+		name string
+		m    StageMetrics
+	}{
+		{"read", metrics.Read},
+		{"unmarshal", metrics.Unmarshal},
+		{"transform", metrics.Transform},
+		{"marshal", metrics.Marshal},
+		{"write", metrics.Write},
+	} {
+		if s.m.Processed != 20 || s.m.Failed != 0 {
+			t.Errorf("Run() %s metrics = %+v, want 20 processed", s.name, s.m)
+		}
+	}
+}
+
+func TestRun_Unordered(t *testing.T) {
+	p := &Pipeline{Workers: 4, Transform: stall}
+	var out bytes.Buffer
+	if _, err := p.Run(context.Background(), strings.NewReader(patients(20)), &out); err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+	got := ids(t, out.String())
+	sort.Strings(got)
+	want := ids(t, patients(20))
+	sort.Strings(want)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Run() output diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	in := strings.Join([]string{
+		`{"resourceType":"Patient","id":"p0"}`,
+		`{"resourceType":"Patient","id":`,
+		``,
+		`{"resourceType":"Patient","id":"fail"}`,
+		`{"resourceType":"Patient","id":"drop"}`,
+		`{"resourceType":"Patient","id":"` + strings.Repeat("x", 100) + `"}`,
+		`{"resourceType":"Patient","id":"p1"}`,
+	}, "\r\n")
+	transform := func(_ context.Context, res proto.Message) (proto.Message, error) {
+		switch res.(*ppb.Patient).GetId().GetValue() {
+		case "fail":
+			return nil, errors.New("failed")
+		case "drop":
+			return nil, nil
+		}
+		res.(*ppb.Patient).Active = &d4pb.Boolean{Value: true}
+		return res, nil
+	}
+	errs := make(chan *LineError)
+	var lineErrs []string
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for e := range errs {
+			lineErrs = append(lineErrs, fmt.Sprintf("%d %s", e.Line, e.Stage))
+		}
+	}()
+	p := &Pipeline{Workers: 3, Ordered: true, Transform: transform, MaxLineSize: 80, Errors: errs}
+	var out bytes.Buffer
+	metrics, err := p.Run(context.Background(), strings.NewReader(in), &out)
+	close(errs)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+	want := `{"active":true,"id":"p0","resourceType":"Patient"}` + "\n" + `{"active":true,"id":"p1","resourceType":"Patient"}` + "\n"
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Errorf("Run() output diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"2 unmarshal", "4 transform", "6 read"}, lineErrs); diff != "" {
+		t.Errorf("Run() errors diff (-want +got):\n%s", diff)
+	}
+	if metrics.Dropped != 1 || metrics.Unmarshal.Failed != 1 || metrics.Transform.Failed != 1 || metrics.Read.Failed != 1 {
+		t.Errorf("Run() metrics = %+v, want 1 dropped, 1 failed read, unmarshal and transform", metrics)
+	}
+}
+
+func TestRun_StopsAtFirstError(t *testing.T) {
+	in := patients(10) + `{"resourceType":"Patient","id":` + "\n" + patients(10)
+	p := &Pipeline{Workers: 2, Ordered: true}
+	var out bytes.Buffer
+	_, err := p.Run(context.Background(), strings.NewReader(in), &out)
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 11 || lineErr.Stage != UnmarshalStage {
+		t.Errorf("Run() returned %v, want an unmarshal error on line 11", err)
+	}
+}
+
+func TestRun_Validate(t *testing.T) {
+	in := `{"resourceType":"Observation","code":{"text":"x"}}` + "\n"
+	for _, validate := range []bool{false, true} {
+		p := &Pipeline{Validate: validate}
+		var out bytes.Buffer
+		_, err := p.Run(context.Background(), strings.NewReader(in), &out)
+		if gotErr := err != nil; gotErr != validate {
+			t.Errorf("Run() with Validate %v returned %v, want error %v", validate, err, validate)
+		}
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	transform := func(ctx context.Context, res proto.Message) (proto.Message, error) {
+		cancel()
+		return res, nil
+	}
+	p := &Pipeline{Workers: 2, Transform: transform}
+	var out bytes.Buffer
+	if _, err := p.Run(ctx, strings.NewReader(patients(100)), &out); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() returned %v, want context.Canceled", err)
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		want  []string
+		lines []int
+	}{
+		{
+			name: "trailing newline",
+			in:   patients(3),
+			want: []string{"p0", "p1", "p2"},
+		},
+		{
+			name:  "CRLF, long and last line without newline",
+			in:    `{"resourceType":"Patient","id":"p0"}` + "\r\n\r\n" + `{"resourceType":"Patient","id":"` + strings.Repeat("x", 100) + `"}` + "\r\n" + `{"resourceType":"Patient","id":"p1"}`,
+			want:  []string{"p0", "p1"},
+			lines: []int{3},
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "in.ndjson")
+		if err := os.WriteFile(path, []byte(test.in), 0644); err != nil {
+			t.Fatalf("WriteFile() returned unexpected error: %v", err)
+		}
+		for _, mmap := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, mmap %v", test.name, mmap), func(t *testing.T) {
+				errs := make(chan *LineError)
+				var lines []int
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					for e := range errs {
+						lines = append(lines, e.Line)
+					}
+				}()
+				p := &Pipeline{Workers: 2, Ordered: true, Mmap: mmap, MaxLineSize: 80, Errors: errs}
+				var out bytes.Buffer
+				_, err := p.RunFile(context.Background(), path, &out)
+				close(errs)
+				<-done
+				if err != nil {
+					t.Fatalf("RunFile() returned unexpected error: %v", err)
+				}
+				if diff := cmp.Diff(test.want, ids(t, out.String())); diff != "" {
+					t.Errorf("RunFile() output diff (-want +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(test.lines, lines); diff != "" {
+					t.Errorf("RunFile() error lines diff (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestRunFile_Missing(t *testing.T) {
+	for _, mmap := range []bool{false, true} {
+		p := &Pipeline{Mmap: mmap}
+		if _, err := p.RunFile(context.Background(), filepath.Join(t.TempDir(), "missing"), &bytes.Buffer{}); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("RunFile() with mmap %v returned %v, want os.ErrNotExist", mmap, err)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdshooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+var patientView = Service{
+	Hook:        PatientView,
+	ID:          "greeting",
+	Description: "Greets the patient",
+	Prefetch:    map[string]string{"patient": "Patient/{{context.patientId}}"},
+}
+
+// greet returns a card with the name of the prefetched patient and a
+// suggestion to create a MedicationRequest.
+func greet(_ context.Context, req *Request) (*Response, error) {
+	p, ok := req.Prefetch["patient"].(*ppb.Patient)
+	if !ok {
+		return nil, fmt.Errorf("no patient prefetched")
+	}
+	return &Response{Cards: []*Card{{
+		Summary:   "Hello " + p.GetName()[0].GetGiven()[0].GetValue(),
+		Indicator: Info,
+		Source:    Source{Label: "test"},
+		Suggestions: []*Suggestion{{
+			Label: "Order",
+			Actions: []*Action{{
+				Type:        Create,
+				Description: "Order something",
+				Resource: &mrpb.MedicationRequest{
+					Status: &mrpb.MedicationRequest_StatusCode{Value: c4pb.MedicationrequestStatusCode_DRAFT},
+					Intent: &mrpb.MedicationRequest_IntentCode{Value: c4pb.MedicationRequestIntentCode_PROPOSAL},
+				},
+			}},
+		}},
+	}}}, nil
+}
+
+func patient() *ppb.Patient {
+	return &ppb.Patient{
+		Id:   &d4pb.Id{Value: "p1"},
+		Name: []*d4pb.HumanName{{Given: []*d4pb.String{{Value: "Alex"}}}},
+	}
+}
+
+// fhirServer serves Patient/p1 to requests with the bearer token "secret".
+func fhirServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	data, err := marshalResource(patient())
+	if err != nil {
+		t.Fatalf("marshalResource() returned unexpected error: %v", err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/fhir/Patient/p1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+}
+
+func cdsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	s := &Server{}
+	if err := s.Register(patientView, greet); err != nil {
+		t.Fatalf("Register() returned unexpected error: %v", err)
+	}
+	return httptest.NewServer(s)
+}
+
+func TestDiscover(t *testing.T) {
+	srv := cdsServer(t)
+	defer srv.Close()
+	got, err := (&Client{BaseURL: srv.URL}).Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&Discovery{Services: []Service{patientView}}, got); diff != "" {
+		t.Errorf("Discover() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCall(t *testing.T) {
+	fhir := fhirServer(t)
+	defer fhir.Close()
+	srv := cdsServer(t)
+	defer srv.Close()
+	client := &Client{BaseURL: srv.URL}
+
+	tests := []struct {
+		name string
+		req  func() *Request
+	}{
+		{
+			name: "prefetched",
+			req: func() *Request {
+				return &Request{Hook: PatientView, Prefetch: map[string]proto.Message{"patient": patient()}}
+			},
+		},
+		{
+			name: "fetched by the service",
+			req: func() *Request {
+				return &Request{
+					Hook:              PatientView,
+					FHIRServer:        fhir.URL + "/fhir",
+					FHIRAuthorization: &Authorization{AccessToken: "secret", TokenType: "Bearer"},
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := test.req()
+			if err := req.SetContext("patientId", "p1"); err != nil {
+				t.Fatalf("SetContext() returned unexpected error: %v", err)
+			}
+			got, err := client.Call(context.Background(), "greeting", req)
+			if err != nil {
+				t.Fatalf("Call() returned unexpected error: %v", err)
+			}
+			if req.HookInstance == "" {
+				t.Errorf("Call() did not set a hookInstance")
+			}
+			want, _ := greet(context.Background(), &Request{Prefetch: map[string]proto.Message{"patient": patient()}})
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Call() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCall_Errors(t *testing.T) {
+	fhir := fhirServer(t)
+	defer fhir.Close()
+	srv := cdsServer(t)
+	defer srv.Close()
+	client := &Client{BaseURL: srv.URL}
+	tests := []struct {
+		name string
+		id   string
+		req  *Request
+		want string
+	}{
+		{"unknown service", "other", &Request{Hook: PatientView}, "404"},
+		{"wrong hook", "greeting", &Request{Hook: OrderSign}, "400"},
+		{"no FHIR server", "greeting", &Request{Hook: PatientView}, "412"},
+		{"unauthorized", "greeting", &Request{Hook: PatientView, FHIRServer: fhir.URL + "/fhir"}, "412"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.req.SetContext("patientId", "p1")
+			if _, err := client.Call(context.Background(), test.id, test.req); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Call() returned error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestRequestJSON(t *testing.T) {
+	req := &Request{
+		Hook:         OrderSelect,
+		HookInstance: "d1577c69-dfbe-44ad-ba6d-3e05e953b2ea",
+		Prefetch:     map[string]proto.Message{"patient": patient(), "missing": nil},
+	}
+	if err := req.SetContext("userId", "Practitioner/dr1"); err != nil {
+		t.Fatalf("SetContext() returned unexpected error: %v", err)
+	}
+	if err := req.SetContext("draftOrders", patient()); err != nil {
+		t.Fatalf("SetContext() returned unexpected error: %v", err)
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"resourceType":"Patient"`) {
+		t.Errorf("json.Marshal() = %s, want FHIR JSON resources", data)
+	}
+	got := &Request{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(req, got, protocmp.Transform()); diff != "" {
+		t.Errorf("json round trip diff (-want +got):\n%s", diff)
+	}
+	res, err := got.ContextResource("draftOrders")
+	if err != nil {
+		t.Fatalf("ContextResource() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(patient(), res, protocmp.Transform()); diff != "" {
+		t.Errorf("ContextResource() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	req := &Request{}
+	req.SetContext("patientId", "p1")
+	req.SetContext("userId", "Practitioner/dr1")
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"Patient/{{context.patientId}}", "Patient/p1"},
+		{"MedicationRequest?patient={{ context.patientId }}&status=active", "MedicationRequest?patient=p1&status=active"},
+		{"Practitioner/{{userPractitionerId}}", "Practitioner/dr1"},
+	}
+	for _, test := range tests {
+		got, err := Expand(test.template, req)
+		if err != nil {
+			t.Errorf("Expand(%q) returned unexpected error: %v", test.template, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Expand(%q) = %q, want %q", test.template, got, test.want)
+		}
+	}
+	for _, template := range []string{"Encounter/{{context.encounterId}}", "Patient/{{userPatientId}}"} {
+		if _, err := Expand(template, req); err == nil {
+			t.Errorf("Expand(%q) succeeded, want error", template)
+		}
+	}
+}
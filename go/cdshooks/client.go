@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdshooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/fhir/go/internal/uuid"
+)
+
+// Client calls the services of a CDS server.
+type Client struct {
+	// BaseURL is the URL of the server, without the /cds-services path.
+	BaseURL string
+	// HTTPClient is used for the calls; http.DefaultClient is used if it is
+	// nil.
+	HTTPClient *http.Client
+}
+
+// Discover returns the services of the server.
+func (c *Client) Discover(ctx context.Context) (*Discovery, error) {
+	d := &Discovery{}
+	if err := c.do(ctx, http.MethodGet, DiscoveryPath, nil, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Call calls service id with req and returns its response. A random
+// hookInstance is set on req if it has none.
+func (c *Client) Call(ctx context.Context, id string, req *Request) (*Response, error) {
+	if req.HookInstance == "" {
+		req.HookInstance = uuid.New()
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	resp := &Response{}
+	if err := c.do(ctx, http.MethodPost, DiscoveryPath+"/"+id, body, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var in io.Reader
+	if body != nil {
+		in = bytes.NewReader(body)
+	}
+	hr, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, in)
+	if err != nil {
+		return err
+	}
+	hr.Header.Set("Accept", "application/json")
+	if body != nil {
+		hr.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(hr)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	return nil
+}
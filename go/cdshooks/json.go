@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdshooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	marshaller   *jsonformat.Marshaller
+	unmarshaller *jsonformat.Unmarshaller
+)
+
+func init() {
+	var err error
+	if marshaller, err = jsonformat.NewMarshaller(false, "", "", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("cdshooks: creating marshaller: %v", err))
+	}
+	if unmarshaller, err = jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("cdshooks: creating unmarshaller: %v", err))
+	}
+}
+
+// marshalResource returns the FHIR JSON of res, which may be wrapped in a
+// ContainedResource.
+func marshalResource(res proto.Message) ([]byte, error) {
+	return marshaller.MarshalResource(elementpath.Unwrap(res))
+}
+
+// unmarshalResource returns the resource of FHIR JSON data, unwrapped from
+// its ContainedResource.
+func unmarshalResource(data []byte) (proto.Message, error) {
+	cr, err := unmarshaller.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return elementpath.Unwrap(cr), nil
+}
+
+// The aliases below have the fields, but not the methods, of the types they
+// alias, so that the methods can defer to encoding/json for the fields
+// without FHIR content.
+type (
+	plainRequest Request
+	plainAction  Action
+)
+
+type requestJSON struct {
+	*plainRequest
+	Prefetch map[string]json.RawMessage `json:"prefetch,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r *Request) MarshalJSON() ([]byte, error) {
+	plain := plainRequest(*r)
+	if plain.Context == nil {
+		// The specification requires a context object, even if empty.
+		plain.Context = map[string]json.RawMessage{}
+	}
+	out := requestJSON{plainRequest: &plain}
+	if len(r.Prefetch) > 0 {
+		out.Prefetch = map[string]json.RawMessage{}
+		for k, res := range r.Prefetch {
+			if res == nil {
+				out.Prefetch[k] = json.RawMessage("null")
+				continue
+			}
+			data, err := marshalResource(res)
+			if err != nil {
+				return nil, fmt.Errorf("prefetch %s: %w", k, err)
+			}
+			out.Prefetch[k] = data
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Request) UnmarshalJSON(data []byte) error {
+	in := requestJSON{plainRequest: (*plainRequest)(r)}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	r.Prefetch = nil
+	for k, raw := range in.Prefetch {
+		if r.Prefetch == nil {
+			r.Prefetch = map[string]proto.Message{}
+		}
+		if string(raw) == "null" {
+			r.Prefetch[k] = nil
+			continue
+		}
+		res, err := unmarshalResource(raw)
+		if err != nil {
+			return fmt.Errorf("prefetch %s: %w", k, err)
+		}
+		r.Prefetch[k] = res
+	}
+	return nil
+}
+
+type actionJSON struct {
+	*plainAction
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a *Action) MarshalJSON() ([]byte, error) {
+	out := actionJSON{plainAction: (*plainAction)(a)}
+	if a.Resource != nil {
+		data, err := marshalResource(a.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("action resource: %w", err)
+		}
+		out.Resource = data
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Action) UnmarshalJSON(data []byte) error {
+	in := actionJSON{plainAction: (*plainAction)(a)}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	a.Resource = nil
+	if len(in.Resource) > 0 && string(in.Resource) != "null" {
+		res, err := unmarshalResource(in.Resource)
+		if err != nil {
+			return fmt.Errorf("action resource: %w", err)
+		}
+		a.Resource = res
+	}
+	return nil
+}
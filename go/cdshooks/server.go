@@ -0,0 +1,242 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdshooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DiscoveryPath is the path of the discovery endpoint; the endpoint of a
+// service is DiscoveryPath/id.
+const DiscoveryPath = "/cds-services"
+
+// Handler handles the calls to a CDS service. The prefetch of req is
+// complete: templates the client did not prefetch have been fetched from
+// the FHIR server.
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Server is an http.Handler serving the discovery endpoint and the
+// endpoints of registered services. It is safe for concurrent use.
+type Server struct {
+	// HTTPClient is used to fetch prefetch templates the client did not
+	// prefetch. http.DefaultClient is used if it is nil.
+	HTTPClient *http.Client
+
+	mu       sync.RWMutex
+	services []Service
+	handlers map[string]Handler
+}
+
+// Register adds a service to s.
+func (s *Server) Register(svc Service, h Handler) error {
+	if svc.ID == "" || strings.Contains(svc.ID, "/") {
+		return fmt.Errorf("invalid service id %q", svc.ID)
+	}
+	if svc.Hook == "" {
+		return fmt.Errorf("service %s has no hook", svc.ID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handlers == nil {
+		s.handlers = map[string]Handler{}
+	}
+	if _, ok := s.handlers[svc.ID]; ok {
+		return fmt.Errorf("service %s already registered", svc.ID)
+	}
+	s.services = append(s.services, svc)
+	s.handlers[svc.ID] = h
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == DiscoveryPath:
+		if r.Method != http.MethodGet {
+			http.Error(w, "discovery requires GET", http.StatusMethodNotAllowed)
+			return
+		}
+		s.mu.RLock()
+		d := Discovery{Services: append([]Service{}, s.services...)}
+		s.mu.RUnlock()
+		writeJSON(w, d)
+	case strings.HasPrefix(path, DiscoveryPath+"/"):
+		if r.Method != http.MethodPost {
+			http.Error(w, "service calls require POST", http.StatusMethodNotAllowed)
+			return
+		}
+		s.call(w, r, strings.TrimPrefix(path, DiscoveryPath+"/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) call(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.RLock()
+	h, ok := s.handlers[id]
+	var svc Service
+	for _, candidate := range s.services {
+		if candidate.ID == id {
+			svc = candidate
+		}
+	}
+	s.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &Request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Hook != svc.Hook {
+		http.Error(w, fmt.Sprintf("service %s handles %s, not %s", id, svc.Hook, req.Hook), http.StatusBadRequest)
+		return
+	}
+	if err := s.prefetch(r.Context(), svc, req); err != nil {
+		// The CDS Hooks specification reserves 412 for services unable to
+		// obtain the data they need.
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	resp, err := h(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if resp == nil {
+		resp = &Response{}
+	}
+	if resp.Cards == nil {
+		resp.Cards = []*Card{}
+	}
+	writeJSON(w, resp)
+}
+
+// prefetch fetches the templates of svc missing from the prefetch of req.
+func (s *Server) prefetch(ctx context.Context, svc Service, req *Request) error {
+	for key, template := range svc.Prefetch {
+		if _, ok := req.Prefetch[key]; ok {
+			continue
+		}
+		if req.FHIRServer == "" {
+			return fmt.Errorf("prefetch %s is missing and the request has no FHIR server", key)
+		}
+		query, err := Expand(template, req)
+		if err != nil {
+			return fmt.Errorf("prefetch %s: %w", key, err)
+		}
+		res, err := fetch(ctx, s.HTTPClient, req, query)
+		if err != nil {
+			return fmt.Errorf("prefetch %s: %w", key, err)
+		}
+		if req.Prefetch == nil {
+			req.Prefetch = map[string]proto.Message{}
+		}
+		req.Prefetch[key] = res
+	}
+	return nil
+}
+
+var token = regexp.MustCompile(`\{\{\s*([A-Za-z.]+)\s*\}\}`)
+
+// Expand replaces the tokens of a prefetch template with values from req.
+// {{context.name}} stands for the string context field name, and
+// {{userPractitionerId}}, {{userPractitionerRoleId}}, {{userPatientId}} and
+// {{userRelatedPersonId}} for the id of context.userId if it refers to a
+// resource of that type.
+func Expand(template string, req *Request) (string, error) {
+	var err error
+	out := token.ReplaceAllStringFunc(template, func(t string) string {
+		name := token.FindStringSubmatch(t)[1]
+		if field := strings.TrimPrefix(name, "context."); field != name {
+			v, e := req.ContextString(field)
+			if e != nil || v == "" {
+				err = fmt.Errorf("no value for %s", t)
+			}
+			return v
+		}
+		if typ := strings.TrimSuffix(strings.TrimPrefix(name, "user"), "Id"); typ != name {
+			user, _ := req.ContextString("userId")
+			if id := strings.TrimPrefix(user, typ+"/"); id != user && id != "" {
+				return id
+			}
+		}
+		err = fmt.Errorf("no value for %s", t)
+		return ""
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// fetch GETs query from the FHIR server of req, with its authorization. Not
+// found responses yield a nil resource.
+func fetch(ctx context.Context, client *http.Client, req *Request, query string) (proto.Message, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	hr, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(req.FHIRServer, "/")+"/"+query, nil)
+	if err != nil {
+		return nil, err
+	}
+	hr.Header.Set("Accept", "application/fhir+json")
+	if a := req.FHIRAuthorization; a != nil && a.AccessToken != "" {
+		hr.Header.Set("Authorization", "Bearer "+a.AccessToken)
+	}
+	resp, err := client.Do(hr)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", query, resp.Status)
+	}
+	return unmarshalResource(body)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cdshooks implements CDS Hooks 1.0 (https://cds-hooks.hl7.org/1.0/)
+// services and clients over the R4 protos.
+//
+// The JSON payloads of CDS Hooks embed FHIR resources, in the prefetch and
+// context of requests and in the actions of suggestions. The types of this
+// package hold those resources as protos and convert them to and from FHIR
+// JSON with jsonformat when the payloads are marshalled.
+package cdshooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Hooks defined by the CDS Hooks specification.
+const (
+	PatientView        = "patient-view"
+	OrderSelect        = "order-select"
+	OrderSign          = "order-sign"
+	EncounterStart     = "encounter-start"
+	EncounterDischarge = "encounter-discharge"
+	AppointmentBook    = "appointment-book"
+)
+
+// Card indicators, in increasing order of urgency.
+const (
+	Info     = "info"
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+// Service describes a CDS service in the discovery response.
+type Service struct {
+	Hook        string `json:"hook"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description"`
+	ID          string `json:"id"`
+	// Prefetch holds the prefetch templates of the service by key. The
+	// templates are FHIR queries relative to the FHIR server, in which
+	// tokens like {{context.patientId}} stand for values of the request.
+	Prefetch map[string]string `json:"prefetch,omitempty"`
+}
+
+// Discovery is the response of the discovery endpoint.
+type Discovery struct {
+	Services []Service `json:"services"`
+}
+
+// Authorization is the OAuth 2.0 access token granting the CDS service
+// access to the FHIR server.
+type Authorization struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+	Subject     string `json:"subject"`
+}
+
+// Request is the body of a call to a CDS service.
+type Request struct {
+	Hook              string         `json:"hook"`
+	HookInstance      string         `json:"hookInstance"`
+	FHIRServer        string         `json:"fhirServer,omitempty"`
+	FHIRAuthorization *Authorization `json:"fhirAuthorization,omitempty"`
+	// Context holds the hook-specific context by field, as raw JSON. Use
+	// ContextString and ContextResource to read it and SetContext to write
+	// it.
+	Context map[string]json.RawMessage `json:"context"`
+	// Prefetch holds the prefetched resources by key. A nil resource stands
+	// for a prefetch query that found nothing.
+	Prefetch map[string]proto.Message `json:"-"`
+}
+
+// ContextString returns the string context field key, such as patientId.
+func (r *Request) ContextString(key string) (string, error) {
+	raw, ok := r.Context[key]
+	if !ok {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", fmt.Errorf("context %s: %w", key, err)
+	}
+	return s, nil
+}
+
+// ContextResource returns the FHIR resource in context field key, such as
+// the draftOrders Bundle of order-select, or nil if the field is absent.
+func (r *Request) ContextResource(key string) (proto.Message, error) {
+	raw, ok := r.Context[key]
+	if !ok || string(raw) == "null" {
+		return nil, nil
+	}
+	res, err := unmarshalResource(raw)
+	if err != nil {
+		return nil, fmt.Errorf("context %s: %w", key, err)
+	}
+	return res, nil
+}
+
+// SetContext sets context field key to value, which is marshalled as a FHIR
+// resource if it is a proto and with encoding/json otherwise.
+func (r *Request) SetContext(key string, value interface{}) error {
+	var raw []byte
+	var err error
+	if m, ok := value.(proto.Message); ok {
+		raw, err = marshalResource(m)
+	} else {
+		raw, err = json.Marshal(value)
+	}
+	if err != nil {
+		return fmt.Errorf("context %s: %w", key, err)
+	}
+	if r.Context == nil {
+		r.Context = map[string]json.RawMessage{}
+	}
+	r.Context[key] = raw
+	return nil
+}
+
+// Response is the response of a CDS service.
+type Response struct {
+	Cards         []*Card   `json:"cards"`
+	SystemActions []*Action `json:"systemActions,omitempty"`
+}
+
+// Card is decision support for the user.
+type Card struct {
+	UUID              string        `json:"uuid,omitempty"`
+	Summary           string        `json:"summary"`
+	Detail            string        `json:"detail,omitempty"`
+	Indicator         string        `json:"indicator"`
+	Source            Source        `json:"source"`
+	Suggestions       []*Suggestion `json:"suggestions,omitempty"`
+	SelectionBehavior string        `json:"selectionBehavior,omitempty"`
+	OverrideReasons   []*Coding     `json:"overrideReasons,omitempty"`
+	Links             []*Link       `json:"links,omitempty"`
+}
+
+// Source is the source of the information of a card.
+type Source struct {
+	Label string  `json:"label"`
+	URL   string  `json:"url,omitempty"`
+	Icon  string  `json:"icon,omitempty"`
+	Topic *Coding `json:"topic,omitempty"`
+}
+
+// Coding is the CDS Hooks representation of a code.
+type Coding struct {
+	Code    string `json:"code"`
+	System  string `json:"system,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+// Suggestion is a set of actions the user may accept.
+type Suggestion struct {
+	Label         string    `json:"label"`
+	UUID          string    `json:"uuid,omitempty"`
+	IsRecommended bool      `json:"isRecommended,omitempty"`
+	Actions       []*Action `json:"actions,omitempty"`
+}
+
+// Action types.
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+// Action is a change to FHIR data proposed by a suggestion.
+type Action struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	// Resource is the resource to create or update.
+	Resource proto.Message `json:"-"`
+	// ResourceID is the relative reference of the resource to delete.
+	ResourceID string `json:"resourceId,omitempty"`
+}
+
+// Link is a link to a reference or a SMART app.
+type Link struct {
+	Label      string `json:"label"`
+	URL        string `json:"url"`
+	Type       string `json:"type"`
+	AppContext string `json:"appContext,omitempty"`
+}
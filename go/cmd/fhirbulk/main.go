@@ -0,0 +1,416 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirbulk exports data from a FHIR server with the Bulk Data Access
+// $export operation.
+//
+// Usage:
+//
+//	fhirbulk -base https://example.com/fhir -level group -group g1 \
+//	    -token_url https://example.com/auth/token -client_id my-client \
+//	    -key private.pem -kid key-1 -out export/
+//
+// fhirbulk starts the export, authenticating with SMART Backend Services if
+// -token_url is set or with the fixed -token otherwise, waits for it to
+// complete and downloads its output files to the -out directory as
+// <type>-<n>.ndjson. Error files are downloaded as error-<n>.ndjson. Once all
+// files are downloaded it writes manifest.json, the manifest of the server
+// with the local file and size of each output.
+//
+// An interrupted run is resumed by running fhirbulk again with the same -out:
+// the status URL of the export is kept in fhirbulk-state.json, files already
+// downloaded are skipped and partial downloads are resumed.
+//
+// With -decompress, gzip-compressed output files are decompressed. With
+// -convert json, the resources of the NDJSON files are also written one per
+// file as <type>/<id>.json.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/bulkdata"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+)
+
+var (
+	base       = flag.String("base", "", "FHIR base URL of the server")
+	level      = flag.String("level", "system", "export level: system, group or patient")
+	group      = flag.String("group", "", "ID of the Group of a group export")
+	types      = flag.String("types", "", "comma separated resource types to export; all if empty")
+	since      = flag.String("since", "", "only export resources updated after this RFC 3339 time")
+	out        = flag.String("out", "", "output directory")
+	tokenURL   = flag.String("token_url", "", "token endpoint of the SMART Backend Services authorization")
+	clientID   = flag.String("client_id", "", "client ID of the Backend Services authorization")
+	keyFile    = flag.String("key", "", "PEM file of the private key of the Backend Services authorization")
+	kid        = flag.String("kid", "", "key ID of -key")
+	scope      = flag.String("scope", "system/*.read", "scope of the Backend Services authorization")
+	token      = flag.String("token", "", "fixed access token, if -token_url is not set")
+	decompress = flag.Bool("decompress", false, "decompress gzip-compressed output files")
+	convert    = flag.String("convert", "", "json to also write each resource to its own JSON file")
+	poll       = flag.Duration("poll", bulkdata.DefaultPollInterval, "interval between status requests when the server does not ask for one")
+)
+
+// resourceTypePattern matches FHIR resource type names, which are used as
+// file names of the downloaded files.
+var resourceTypePattern = regexp.MustCompile(`^[A-Z][A-Za-z]+$`)
+
+const (
+	stateFile    = "fhirbulk-state.json"
+	manifestFile = "manifest.json"
+)
+
+// state is the state of an export, kept between runs.
+type state struct {
+	StatusURL string             `json:"statusUrl"`
+	Manifest  *bulkdata.Manifest `json:"manifest,omitempty"`
+}
+
+// manifest is the manifest written once all files are downloaded.
+type manifest struct {
+	TransactionTime string       `json:"transactionTime"`
+	Request         string       `json:"request"`
+	Output          []outputFile `json:"output"`
+	Error           []outputFile `json:"error"`
+}
+
+type outputFile struct {
+	bulkdata.File
+	// Path is the path of the file, relative to the output directory.
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+func main() {
+	flag.Parse()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "fhirbulk: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context) error {
+	if *base == "" || *out == "" {
+		flag.Usage()
+		return fmt.Errorf("-base and -out are required")
+	}
+	if *convert != "" && *convert != "json" {
+		return fmt.Errorf("unknown -convert format %q", *convert)
+	}
+	if _, err := os.Stat(filepath.Join(*out, manifestFile)); err == nil {
+		return fmt.Errorf("%s already holds a complete export", *out)
+	}
+	req, err := exportRequest()
+	if err != nil {
+		return err
+	}
+	c := &bulkdata.Client{
+		BaseURL:      *base,
+		PollInterval: *poll,
+		Progress: func(st bulkdata.Status) {
+			if st.Progress != "" {
+				fmt.Fprintf(os.Stderr, "fhirbulk: export in progress: %s\n", st.Progress)
+			}
+		},
+	}
+	if c.Auth, err = auth(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+
+	st, err := readState()
+	if err != nil {
+		return err
+	}
+	if st.StatusURL == "" {
+		if st.StatusURL, err = c.Kickoff(ctx, req); err != nil {
+			return err
+		}
+		if err := writeJSON(stateFile, st); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "fhirbulk: started export %s\n", st.StatusURL)
+	} else {
+		fmt.Fprintf(os.Stderr, "fhirbulk: resuming export %s\n", st.StatusURL)
+	}
+	if st.Manifest == nil {
+		if st.Manifest, err = c.Wait(ctx, st.StatusURL); err != nil {
+			return err
+		}
+		if err := writeJSON(stateFile, st); err != nil {
+			return err
+		}
+	}
+
+	m := manifest{TransactionTime: st.Manifest.TransactionTime, Request: st.Manifest.Request}
+	if m.Output, err = download(ctx, c, st.Manifest, st.Manifest.Output, ""); err != nil {
+		return err
+	}
+	if m.Error, err = download(ctx, c, st.Manifest, st.Manifest.Error, "error"); err != nil {
+		return err
+	}
+	if err := writeJSON(manifestFile, m); err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(*out, stateFile))
+}
+
+func exportRequest() (bulkdata.ExportRequest, error) {
+	req := bulkdata.ExportRequest{GroupID: *group}
+	switch *level {
+	case "system":
+		req.Level = bulkdata.System
+	case "group":
+		req.Level = bulkdata.Group
+	case "patient":
+		req.Level = bulkdata.Patient
+	default:
+		return req, fmt.Errorf("unknown export level %q", *level)
+	}
+	for _, t := range strings.Split(*types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			req.Types = append(req.Types, t)
+		}
+	}
+	if *since != "" {
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return req, fmt.Errorf("invalid -since: %w", err)
+		}
+		req.Since = t
+	}
+	return req, nil
+}
+
+func auth() (bulkdata.TokenSource, error) {
+	if *tokenURL == "" {
+		if *token == "" {
+			return nil, nil
+		}
+		return bulkdata.StaticToken(*token), nil
+	}
+	if *clientID == "" || *keyFile == "" {
+		return nil, fmt.Errorf("-client_id and -key are required with -token_url")
+	}
+	key, err := readKey(*keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &bulkdata.BackendServices{
+		TokenURL: *tokenURL,
+		ClientID: *clientID,
+		Key:      key,
+		KeyID:    *kid,
+		Scope:    *scope,
+	}, nil
+}
+
+// readKey reads a PEM private key in the PKCS #8, SEC 1 or PKCS #1 format.
+func readKey(name string) (crypto.Signer, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", name)
+	}
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported key type %T", name, key)
+	}
+	return signer, nil
+}
+
+func readState() (state, error) {
+	var st state
+	data, err := os.ReadFile(filepath.Join(*out, stateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("%s: %w", stateFile, err)
+	}
+	return st, nil
+}
+
+func writeJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(*out, name), append(data, '\n'), 0644)
+}
+
+// download downloads the files of m, named after their type or prefix if
+// set, skipping those downloaded by a previous run.
+func download(ctx context.Context, c *bulkdata.Client, m *bulkdata.Manifest, files []bulkdata.File, prefix string) ([]outputFile, error) {
+	outs := []outputFile{}
+	seen := map[string]int{}
+	for _, f := range files {
+		name := prefix
+		if name == "" {
+			if !resourceTypePattern.MatchString(f.Type) {
+				return nil, fmt.Errorf("manifest file %s has invalid type %q", f.URL, f.Type)
+			}
+			name = f.Type
+		}
+		seen[name]++
+		rel := fmt.Sprintf("%s-%d.ndjson", name, seen[name])
+		path := filepath.Join(*out, rel)
+		fi, err := os.Stat(path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "fhirbulk: downloading %s\n", rel)
+			if _, err := c.Download(ctx, f, m.RequiresAccessToken, path); err != nil {
+				return nil, err
+			}
+			if *decompress {
+				if err := gunzip(path); err != nil {
+					return nil, err
+				}
+			}
+			if *convert == "json" && prefix == "" {
+				if err := split(path); err != nil {
+					return nil, err
+				}
+			}
+			if fi, err = os.Stat(path); err != nil {
+				return nil, err
+			}
+		}
+		outs = append(outs, outputFile{File: f, Path: rel, Size: fi.Size()})
+	}
+	return outs, nil
+}
+
+// gunzip decompresses the file path in place if it is gzip-compressed.
+func gunzip(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if magic, err := br.Peek(2); err != nil || !bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	tmp := path + ".gunzip"
+	w, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, zr)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// split writes the resources of the NDJSON file path to <type>/<id>.json
+// files in the output directory.
+func split(path string) error {
+	um, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4)
+	if err != nil {
+		return err
+	}
+	m, err := jsonformat.NewPrettyMarshaller(fhirversion.R4)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 64<<20)
+	for line := 1; s.Scan(); line++ {
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		cr, err := um.UnmarshalR4(s.Bytes())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		typ := elementpath.ResourceType(cr)
+		if typ == "" {
+			continue
+		}
+		id := elementpath.ID(cr)
+		if id == "" {
+			id = fmt.Sprintf("line-%d", line)
+		}
+		data, err := m.Marshal(cr)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		dir := filepath.Join(*out, typ)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, id+".json"), append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/fhir/go/bulkdata"
+	"github.com/google/go-cmp/cmp"
+)
+
+// setFlags sets the flags the tested functions read for the duration of a
+// test.
+func setFlags(t *testing.T, values map[string]string) {
+	t.Helper()
+	for name, v := range values {
+		name, old := name, flag.Lookup(name).Value.String()
+		if err := flag.Set(name, v); err != nil {
+			t.Fatalf("flag.Set(%q, %q) returned unexpected error: %v", name, v, err)
+		}
+		t.Cleanup(func() { flag.Set(name, old) })
+	}
+}
+
+func TestDownload_InvalidType(t *testing.T) {
+	tests := []string{"", "../Patient", "Patient/../../x", "/etc/passwd", "patient", "Patient.ndjson"}
+	for _, typ := range tests {
+		t.Run(typ, func(t *testing.T) {
+			dir := t.TempDir()
+			setFlags(t, map[string]string{"out": dir})
+			files := []bulkdata.File{{Type: typ, URL: "https://example.com/files/1"}}
+			if _, err := download(context.Background(), nil, &bulkdata.Manifest{}, files, ""); err == nil {
+				t.Errorf("download() with type %q succeeded, want error", typ)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("os.ReadDir() returned unexpected error: %v", err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("download() with type %q wrote %d files, want none", typ, len(entries))
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	dir := t.TempDir()
+	setFlags(t, map[string]string{"out": dir})
+	path := filepath.Join(dir, "Patient-1.ndjson")
+	data := `{"resourceType":"Patient","id":"p1"}
+
+{"resourceType":"Observation","status":"final","code":{"text":"x"}}
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	if err := split(path); err != nil {
+		t.Fatalf("split() returned unexpected error: %v", err)
+	}
+	var got []string
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		got = append(got, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("filepath.WalkDir() returned unexpected error: %v", err)
+	}
+	sort.Strings(got)
+	want := []string{"Observation/line-3.json", "Patient-1.ndjson", "Patient/p1.json"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("split() wrote unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func TestSplit_InvalidID(t *testing.T) {
+	dir := t.TempDir()
+	setFlags(t, map[string]string{"out": filepath.Join(dir, "out")})
+	path := filepath.Join(dir, "Patient-1.ndjson")
+	if err := os.WriteFile(path, []byte(`{"resourceType":"Patient","id":"../../p1"}`), 0644); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	if err := split(path); err == nil {
+		t.Errorf("split() with id %q succeeded, want error", "../../p1")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "p1.json")); !os.IsNotExist(err) {
+		t.Errorf("split() wrote a file outside -out")
+	}
+}
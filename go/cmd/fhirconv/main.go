@@ -0,0 +1,454 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirconv converts FHIR resources between representations and FHIR
+// versions.
+//
+// Usage:
+//
+//	fhirconv -to ndjson -out patients.ndjson patient1.json patient2.json
+//	fhirconv -to proto -out protos/ json/
+//	fhirconv -version STU3 -to_version R4 -lenient -pretty observation.json
+//
+// The formats are json (one resource per file), ndjson (one resource per
+// line), xml (one resource per file), proto (one binary ContainedResource per
+// file) and textproto (one ContainedResource in the text format per file).
+// The input format defaults to the one of the extension of each input file.
+//
+// Inputs are files or directories, whose files are converted recursively.
+// With a directory input, or an -out ending in a path separator, each file is
+// written to the -out directory under its relative name with the extension of
+// the output format. Otherwise the resources of all inputs are written to
+// -out, or to standard output if it is empty.
+//
+// Resources are converted between FHIR versions through their JSON, so only
+// resources whose JSON is valid in both versions can be converted.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/fhir/go/fhirerrors"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/fhir/go/xmlformat"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	r3pb "github.com/google/fhir/go/proto/google/fhir/proto/stu3/resources_go_proto"
+)
+
+var (
+	from      = flag.String("from", "", "input format: json, ndjson, xml, proto or textproto; from the file extensions if empty")
+	to        = flag.String("to", "", "output format: json, ndjson, xml, proto or textproto")
+	version   = flag.String("version", "R4", "FHIR version of the input: STU3 or R4")
+	toVersion = flag.String("to_version", "", "FHIR version of the output; the input version if empty")
+	lenient   = flag.Bool("lenient", false, "do not validate resources when reading them")
+	profile   = flag.String("profile", "", "canonical URL of a profile added to the meta.profile of every resource")
+	pretty    = flag.Bool("pretty", false, "indent json and xml output")
+	timeZone  = flag.String("timezone", "UTC", "time zone of dates and times without one")
+	out       = flag.String("out", "", "output file or directory; standard output if empty")
+)
+
+// extensions maps file extensions to formats. The first extension of a
+// format is the one of its output files.
+var extensions = []struct {
+	ext, format string
+}{
+	{".json", "json"},
+	{".ndjson", "ndjson"},
+	{".jsonl", "ndjson"},
+	{".pb", "proto"},
+	{".binpb", "proto"},
+	{".textproto", "textproto"},
+	{".textpb", "textproto"},
+	{".prototxt", "textproto"},
+	{".xml", "xml"},
+}
+
+func main() {
+	flag.Parse()
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "fhirconv: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(inputs []string) error {
+	if *to == "" || len(inputs) == 0 {
+		flag.Usage()
+		return fmt.Errorf("-to and at least one input are required")
+	}
+	c, err := newConverter()
+	if err != nil {
+		return err
+	}
+	toDir := strings.HasSuffix(*out, string(filepath.Separator))
+	var files []string
+	for _, in := range inputs {
+		fi, err := os.Stat(in)
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			files = append(files, in)
+			continue
+		}
+		toDir = true
+	}
+	if toDir {
+		if *out == "" {
+			return fmt.Errorf("-out is required to convert directories")
+		}
+		return c.convertToDir(inputs, *out)
+	}
+
+	var resources []proto.Message
+	for _, f := range files {
+		rs, err := c.read(f)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, rs...)
+	}
+	data, err := c.write(resources)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0644)
+}
+
+// converter reads resources of one representation and version and writes
+// them in another.
+type converter struct {
+	fromVer, toVer fhirversion.Version
+	um             *jsonformat.Unmarshaller
+	// srcM and dstUM convert resources between versions, if they differ.
+	srcM  *jsonformat.Marshaller
+	dstUM *jsonformat.Unmarshaller
+	m     *jsonformat.Marshaller
+	// ndjsonM writes the lines of NDJSON, which are never indented.
+	ndjsonM *jsonformat.Marshaller
+	xmlUM   *xmlformat.Unmarshaller
+	xmlM    *xmlformat.Marshaller
+}
+
+func newConverter() (*converter, error) {
+	if _, err := checkFormat(*to); err != nil {
+		return nil, err
+	}
+	if *from != "" {
+		if _, err := checkFormat(*from); err != nil {
+			return nil, err
+		}
+	}
+	fromVer, err := parseVersion(*version)
+	if err != nil {
+		return nil, err
+	}
+	toVer := fromVer
+	if *toVersion != "" {
+		if toVer, err = parseVersion(*toVersion); err != nil {
+			return nil, err
+		}
+	}
+	newUnmarshaller := jsonformat.NewUnmarshaller
+	newXMLUnmarshaller := xmlformat.NewUnmarshaller
+	if *lenient {
+		newUnmarshaller = jsonformat.NewUnmarshallerWithoutValidation
+		newXMLUnmarshaller = xmlformat.NewUnmarshallerWithoutValidation
+	}
+	c := &converter{fromVer: fromVer, toVer: toVer}
+	if c.um, err = newUnmarshaller(*timeZone, fromVer); err != nil {
+		return nil, err
+	}
+	if c.xmlUM, err = newXMLUnmarshaller(*timeZone, fromVer); err != nil {
+		return nil, err
+	}
+	if fromVer != toVer {
+		if c.srcM, err = jsonformat.NewMarshaller(false, "", "", fromVer); err != nil {
+			return nil, err
+		}
+		if c.dstUM, err = newUnmarshaller(*timeZone, toVer); err != nil {
+			return nil, err
+		}
+	}
+	if c.ndjsonM, err = jsonformat.NewMarshaller(false, "", "", toVer); err != nil {
+		return nil, err
+	}
+	c.m = c.ndjsonM
+	if *pretty {
+		if c.m, err = jsonformat.NewPrettyMarshaller(toVer); err != nil {
+			return nil, err
+		}
+	}
+	if c.xmlM, err = xmlformat.NewMarshaller(*pretty, "", "  ", toVer); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func parseVersion(s string) (fhirversion.Version, error) {
+	for _, v := range []fhirversion.Version{fhirversion.STU3, fhirversion.R4} {
+		if strings.EqualFold(s, v.String()) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", fhirerrors.ErrUnsupportedVersion, s)
+}
+
+func checkFormat(f string) (string, error) {
+	switch f {
+	case "json", "ndjson", "xml", "proto", "textproto":
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q", f)
+	}
+}
+
+// formatOf returns the format of the file path, from -from or its extension.
+func formatOf(path string) (string, error) {
+	if *from != "" {
+		return *from, nil
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range extensions {
+		if e.ext == ext {
+			return checkFormat(e.format)
+		}
+	}
+	return "", fmt.Errorf("%s: unknown format, set -from", path)
+}
+
+// outputName returns the name of the output file of the input file name.
+func outputName(name string) string {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	for _, e := range extensions {
+		if e.format == *to {
+			return name + e.ext
+		}
+	}
+	return name
+}
+
+// convertToDir converts the files of inputs to files in the directory dir.
+func (c *converter) convertToDir(inputs []string, dir string) error {
+	for _, in := range inputs {
+		fi, err := os.Stat(in)
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			if err := c.convertFile(in, filepath.Join(dir, outputName(filepath.Base(in)))); err != nil {
+				return err
+			}
+			continue
+		}
+		err = filepath.WalkDir(in, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if _, err := formatOf(path); err != nil {
+				// Files of unknown formats in directories are skipped.
+				return nil
+			}
+			rel, err := filepath.Rel(in, path)
+			if err != nil {
+				return err
+			}
+			return c.convertFile(path, filepath.Join(dir, outputName(rel)))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *converter) convertFile(in, out string) error {
+	resources, err := c.read(in)
+	if err != nil {
+		return err
+	}
+	data, err := c.write(resources)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(out, data, 0644)
+}
+
+// read returns the ContainedResources of the file path, in the output
+// version.
+func (c *converter) read(path string) ([]proto.Message, error) {
+	format, err := formatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var resources []proto.Message
+	switch format {
+	case "json":
+		cr, err := c.um.Unmarshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		resources = append(resources, cr)
+	case "xml":
+		cr, err := c.xmlUM.Unmarshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		resources = append(resources, cr)
+	case "ndjson":
+		s := bufio.NewScanner(bytes.NewReader(data))
+		s.Buffer(nil, len(data)+1)
+		for line := 1; s.Scan(); line++ {
+			if len(bytes.TrimSpace(s.Bytes())) == 0 {
+				continue
+			}
+			cr, err := c.um.Unmarshal(s.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			resources = append(resources, cr)
+		}
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case "proto", "textproto":
+		cr := newContainedResource(c.fromVer)
+		unmarshal := proto.Unmarshal
+		if format == "textproto" {
+			unmarshal = prototext.Unmarshal
+		}
+		if err := unmarshal(data, cr); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		resources = append(resources, cr)
+	}
+	for i, cr := range resources {
+		if resources[i], err = c.convertVersion(cr); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if *profile != "" {
+			addProfile(resources[i], *profile)
+		}
+	}
+	return resources, nil
+}
+
+func newContainedResource(ver fhirversion.Version) proto.Message {
+	if ver == fhirversion.STU3 {
+		return &r3pb.ContainedResource{}
+	}
+	return &r4pb.ContainedResource{}
+}
+
+// convertVersion returns cr, a ContainedResource of the input version, in
+// the output version.
+func (c *converter) convertVersion(cr proto.Message) (proto.Message, error) {
+	if c.fromVer == c.toVer {
+		return cr, nil
+	}
+	data, err := c.srcM.Marshal(cr)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := c.dstUM.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("converting to %v: %w", c.toVer, err)
+	}
+	return converted, nil
+}
+
+// addProfile adds url to the meta.profile of cr, a ContainedResource, unless
+// it is already there.
+func addProfile(cr proto.Message, url string) {
+	rcr := cr.ProtoReflect()
+	f := rcr.WhichOneof(rcr.Descriptor().Oneofs().Get(0))
+	if f == nil {
+		return
+	}
+	res := rcr.Mutable(f).Message()
+	metaField := res.Descriptor().Fields().ByName("meta")
+	if metaField == nil {
+		return
+	}
+	meta := res.Mutable(metaField).Message()
+	profiles := meta.Mutable(meta.Descriptor().Fields().ByName("profile")).List()
+	for i := 0; i < profiles.Len(); i++ {
+		p := profiles.Get(i).Message()
+		if p.Get(p.Descriptor().Fields().ByName("value")).String() == url {
+			return
+		}
+	}
+	p := profiles.NewElement()
+	p.Message().Set(p.Message().Descriptor().Fields().ByName("value"), protoreflect.ValueOfString(url))
+	profiles.Append(p)
+}
+
+// write returns resources in the output format.
+func (c *converter) write(resources []proto.Message) ([]byte, error) {
+	if *to != "ndjson" && len(resources) != 1 {
+		return nil, fmt.Errorf("%s output holds one resource, got %d; use ndjson or an output directory", *to, len(resources))
+	}
+	switch *to {
+	case "json":
+		data, err := c.m.Marshal(resources[0])
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "ndjson":
+		var buf bytes.Buffer
+		for _, cr := range resources {
+			data, err := c.ndjsonM.Marshal(cr)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	case "xml":
+		data, err := c.xmlM.Marshal(resources[0])
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "proto":
+		return proto.MarshalOptions{Deterministic: true}.Marshal(resources[0])
+	default:
+		return prototext.MarshalOptions{Multiline: true}.Marshal(resources[0])
+	}
+}
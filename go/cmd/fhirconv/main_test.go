@@ -0,0 +1,172 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/fhir/go/xmlformat"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+var formats = []string{"json", "ndjson", "xml", "proto", "textproto"}
+
+const patientJSON = `{"resourceType":"Patient","id":"p1","active":true,"name":[{"family":"Doe","given":["Jane"]}],"birthDate":"1970-05-17"}`
+
+// setFlags sets the flags run reads for the duration of a test.
+func setFlags(t *testing.T, values map[string]string) {
+	t.Helper()
+	for name, v := range values {
+		name, old := name, flag.Lookup(name).Value.String()
+		if err := flag.Set(name, v); err != nil {
+			t.Fatalf("flag.Set(%q, %q) returned unexpected error: %v", name, v, err)
+		}
+		t.Cleanup(func() { flag.Set(name, old) })
+	}
+}
+
+// extension returns the extension of the files of format.
+func extension(format string) string {
+	for _, e := range extensions {
+		if e.format == format {
+			return e.ext
+		}
+	}
+	return ""
+}
+
+// encode returns the R4 ContainedResource cr in format.
+func encode(t *testing.T, format string, cr proto.Message) []byte {
+	t.Helper()
+	var data []byte
+	var err error
+	switch format {
+	case "json", "ndjson":
+		var m *jsonformat.Marshaller
+		if m, err = jsonformat.NewMarshaller(false, "", "", fhirversion.R4); err == nil {
+			data, err = m.Marshal(cr)
+		}
+	case "xml":
+		var m *xmlformat.Marshaller
+		if m, err = xmlformat.NewMarshaller(false, "", "", fhirversion.R4); err == nil {
+			data, err = m.Marshal(cr)
+		}
+	case "proto":
+		data, err = proto.Marshal(cr)
+	case "textproto":
+		data, err = prototext.Marshal(cr)
+	}
+	if err != nil {
+		t.Fatalf("encoding %s returned unexpected error: %v", format, err)
+	}
+	return data
+}
+
+// decode returns the R4 ContainedResource of data, in format.
+func decode(t *testing.T, format string, data []byte) proto.Message {
+	t.Helper()
+	var cr proto.Message
+	var err error
+	switch format {
+	case "json", "ndjson":
+		var um *jsonformat.Unmarshaller
+		if um, err = jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4); err == nil {
+			cr, err = um.Unmarshal(bytes.TrimSpace(data))
+		}
+	case "xml":
+		var um *xmlformat.Unmarshaller
+		if um, err = xmlformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4); err == nil {
+			cr, err = um.Unmarshal(data)
+		}
+	case "proto":
+		cr = &r4pb.ContainedResource{}
+		err = proto.Unmarshal(data, cr)
+	case "textproto":
+		cr = &r4pb.ContainedResource{}
+		err = prototext.Unmarshal(data, cr)
+	}
+	if err != nil {
+		t.Fatalf("decoding %s returned unexpected error: %v", format, err)
+	}
+	return cr
+}
+
+func TestRun_Formats(t *testing.T) {
+	want := decode(t, "json", []byte(patientJSON))
+	for _, from := range formats {
+		for _, to := range formats {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				dir := t.TempDir()
+				in := filepath.Join(dir, "patient"+extension(from))
+				if err := os.WriteFile(in, encode(t, from, want), 0644); err != nil {
+					t.Fatalf("WriteFile() returned unexpected error: %v", err)
+				}
+				out := filepath.Join(dir, "out"+extension(to))
+				setFlags(t, map[string]string{"to": to, "out": out})
+				if err := run([]string{in}); err != nil {
+					t.Fatalf("run() returned unexpected error: %v", err)
+				}
+				data, err := os.ReadFile(out)
+				if err != nil {
+					t.Fatalf("ReadFile() returned unexpected error: %v", err)
+				}
+				if diff := cmp.Diff(want, decode(t, to, data), protocmp.Transform()); diff != "" {
+					t.Errorf("run() output diff (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestRun_Lenient(t *testing.T) {
+	// An Observation without its required status and code.
+	invalid := decode(t, "json", []byte(`{"resourceType":"Observation","id":"o1"}`))
+	for _, from := range []string{"json", "xml"} {
+		t.Run(from, func(t *testing.T) {
+			dir := t.TempDir()
+			in := filepath.Join(dir, "observation"+extension(from))
+			if err := os.WriteFile(in, encode(t, from, invalid), 0644); err != nil {
+				t.Fatalf("WriteFile() returned unexpected error: %v", err)
+			}
+			out := filepath.Join(dir, "out.ndjson")
+			setFlags(t, map[string]string{"to": "ndjson", "out": out})
+			if err := run([]string{in}); err == nil {
+				t.Errorf("run() of an invalid resource succeeded, want error")
+			}
+			setFlags(t, map[string]string{"lenient": "true"})
+			if err := run([]string{in}); err != nil {
+				t.Fatalf("run() with -lenient returned unexpected error: %v", err)
+			}
+			data, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("ReadFile() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(invalid, decode(t, "ndjson", data), protocmp.Transform()); diff != "" {
+				t.Errorf("run() output diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
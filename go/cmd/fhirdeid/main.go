@@ -0,0 +1,382 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirdeid de-identifies NDJSON files of FHIR R4 resources with the
+// rules of package deid.
+//
+// Usage:
+//
+//	fhirdeid -rules rules.yaml -key_file key -out deidentified/ -report report.json export/
+//	fhirdeid -patterns patterns.yaml < patients.ndjson > patients.deid.ndjson
+//
+// The rules are read from a YAML rule file, as parsed by deid.ParseConfig,
+// and default to the HIPAA Safe Harbor rules of deid.SafeHarbor. Hash,
+// pseudonymize and shift rules require the secret key of -key_file; scrub
+// rules require -patterns, a YAML file mapping the labels of identifiers to
+// the regular expressions finding them in free text, i.e.
+//
+//	phone: '\d{3}-\d{3}-\d{4}'
+//	mrn: 'MRN-\d+'
+//
+// Inputs are NDJSON files, or directories whose .ndjson files are processed
+// recursively, and each is written to the -out directory under its relative
+// name, with its resources in the order of its lines. Without inputs,
+// standard input is de-identified to standard output. Resources are
+// processed by -workers goroutines, all the CPUs by default.
+//
+// fhirdeid stops at the first invalid resource, unless -skip_errors is set,
+// in which case invalid resources are reported and left out of the output.
+//
+// The redaction report, a JSON object written to -report or to standard
+// error, counts the resources of each input, the elements each rule applied
+// to by path and action, and the spans of free text scrubbed by label. It
+// holds no data of the resources.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/google/fhir/go/bulkio"
+	"github.com/google/fhir/go/deid"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	rulesFile    = flag.String("rules", "", "YAML rule file; the Safe Harbor rules if empty")
+	keyFile      = flag.String("key_file", "", "file holding the secret key of hash, pseudonymize and shift rules")
+	patternsFile = flag.String("patterns", "", "YAML file mapping labels to the regular expressions scrub rules mask")
+	out          = flag.String("out", "", "output directory; required with inputs")
+	reportFile   = flag.String("report", "", "file to write the redaction report to; standard error if empty")
+	workers      = flag.Int("workers", 0, "number of resources processed concurrently; the number of CPUs if 0")
+	timeZone     = flag.String("timezone", "UTC", "time zone of dates and times without one")
+	skipErrors   = flag.Bool("skip_errors", false, "report and leave out invalid resources instead of stopping")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: fhirdeid [flags] [file or directory...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "fhirdeid: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// report is the redaction report.
+type report struct {
+	Files []*fileReport `json:"files"`
+	// Resources and Failed are the totals of Files.
+	Resources int64 `json:"resources"`
+	Failed    int64 `json:"failed"`
+	// Rules counts the elements each rule applied to, by rule index.
+	Rules []ruleReport `json:"rules"`
+	// Elements counts the elements de-identified, by path and action.
+	Elements []elementReport `json:"elements"`
+	// Scrubbed counts the spans of free text scrubbed, by label.
+	Scrubbed map[string]int64 `json:"scrubbed,omitempty"`
+}
+
+type fileReport struct {
+	Input     string `json:"input"`
+	Output    string `json:"output"`
+	Resources int64  `json:"resources"`
+	Failed    int64  `json:"failed"`
+}
+
+type ruleReport struct {
+	Rule     int         `json:"rule"`
+	Path     string      `json:"path,omitempty"`
+	Type     string      `json:"type,omitempty"`
+	Action   deid.Action `json:"action"`
+	Elements int64       `json:"elements"`
+}
+
+type elementReport struct {
+	Path     string      `json:"path"`
+	Action   deid.Action `json:"action"`
+	Elements int64       `json:"elements"`
+}
+
+// tally aggregates the audits of the resources de-identified concurrently.
+type tally struct {
+	mu       sync.Mutex
+	rules    []int64
+	elements map[elementKey]int64
+	scrubbed map[string]int64
+}
+
+type elementKey struct {
+	path   string
+	action deid.Action
+}
+
+func (t *tally) add(a *deid.Audit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ap := range a.Applied {
+		t.rules[ap.Rule]++
+		t.elements[elementKey{ap.Path, ap.Action}]++
+	}
+	for _, s := range a.Scrubbed {
+		t.scrubbed[s.Label]++
+	}
+}
+
+func run(ctx context.Context) error {
+	cfg, opts, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	d, err := deid.New(cfg, opts)
+	if err != nil {
+		return err
+	}
+	t := &tally{
+		rules:    make([]int64, len(cfg.Rules)),
+		elements: map[elementKey]int64{},
+		scrubbed: map[string]int64{},
+	}
+	p := &bulkio.Pipeline{
+		TimeZone: *timeZone,
+		Workers:  *workers,
+		Ordered:  true,
+		Transform: func(ctx context.Context, res proto.Message) (proto.Message, error) {
+			a, err := d.ResourceAudit(res)
+			if err != nil {
+				return nil, err
+			}
+			t.add(a)
+			return res, nil
+		},
+	}
+	rep := &report{}
+	if flag.NArg() == 0 {
+		fr := &fileReport{Input: "stdin", Output: "stdout"}
+		if err := runPipeline(ctx, p, fr, func(p *bulkio.Pipeline) (bulkio.Metrics, error) {
+			return p.Run(ctx, os.Stdin, os.Stdout)
+		}); err != nil {
+			return err
+		}
+		rep.Files = append(rep.Files, fr)
+	} else {
+		if *out == "" {
+			flag.Usage()
+			return errors.New("-out is required with inputs")
+		}
+		files, err := inputFiles(flag.Args())
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			fr, err := runFile(ctx, p, f)
+			if fr != nil {
+				rep.Files = append(rep.Files, fr)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, fr := range rep.Files {
+		rep.Resources += fr.Resources
+		rep.Failed += fr.Failed
+	}
+	for i, r := range cfg.Rules {
+		rep.Rules = append(rep.Rules, ruleReport{Rule: i, Path: r.Path, Type: r.Type, Action: r.Action, Elements: t.rules[i]})
+	}
+	for k, n := range t.elements {
+		rep.Elements = append(rep.Elements, elementReport{Path: k.path, Action: k.action, Elements: n})
+	}
+	sort.Slice(rep.Elements, func(i, j int) bool {
+		a, b := rep.Elements[i], rep.Elements[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Action < b.Action
+	})
+	if len(t.scrubbed) > 0 {
+		rep.Scrubbed = t.scrubbed
+	}
+	return writeReport(rep)
+}
+
+// loadConfig returns the rules and options of the flags.
+func loadConfig() (*deid.Config, deid.Options, error) {
+	var opts deid.Options
+	cfg := deid.SafeHarbor()
+	if *rulesFile != "" {
+		data, err := os.ReadFile(*rulesFile)
+		if err != nil {
+			return nil, opts, err
+		}
+		if cfg, err = deid.ParseConfig(data); err != nil {
+			return nil, opts, fmt.Errorf("%s: %w", *rulesFile, err)
+		}
+	}
+	if *keyFile != "" {
+		key, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return nil, opts, err
+		}
+		opts.Key = key
+	}
+	if *patternsFile != "" {
+		data, err := os.ReadFile(*patternsFile)
+		if err != nil {
+			return nil, opts, err
+		}
+		var exprs map[string]string
+		if err := yaml.UnmarshalStrict(data, &exprs); err != nil {
+			return nil, opts, fmt.Errorf("%s: %w", *patternsFile, err)
+		}
+		patterns := deid.Patterns{}
+		for label, expr := range exprs {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, opts, fmt.Errorf("%s: pattern %q: %w", *patternsFile, label, err)
+			}
+			patterns[label] = re
+		}
+		opts.Scrubber = patterns
+	}
+	return cfg, opts, nil
+}
+
+// input is an NDJSON file to de-identify and the name of its output,
+// relative to the output directory.
+type input struct {
+	path, name string
+}
+
+// inputFiles returns the NDJSON files of the files and directories paths.
+func inputFiles(paths []string) ([]input, error) {
+	var files []input
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, input{path: p, name: filepath.Base(p)})
+			continue
+		}
+		var dir []input
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Ext(path) != ".ndjson" {
+				return nil
+			}
+			rel, err := filepath.Rel(p, path)
+			if err != nil {
+				return err
+			}
+			dir = append(dir, input{path: path, name: rel})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(dir, func(i, j int) bool { return dir[i].path < dir[j].path })
+		files = append(files, dir...)
+	}
+	seen := map[string]bool{}
+	for _, f := range files {
+		if seen[f.name] {
+			return nil, fmt.Errorf("two inputs are written to %s", f.name)
+		}
+		seen[f.name] = true
+	}
+	return files, nil
+}
+
+func runFile(ctx context.Context, p *bulkio.Pipeline, in input) (*fileReport, error) {
+	name := filepath.Join(*out, in.name)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	fr := &fileReport{Input: in.path, Output: name}
+	err = runPipeline(ctx, p, fr, func(p *bulkio.Pipeline) (bulkio.Metrics, error) {
+		return p.RunFile(ctx, in.path, f)
+	})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return fr, err
+}
+
+// runPipeline runs p with run and fills fr with its metrics. With
+// -skip_errors, the errors of lines are printed to stderr as they occur.
+func runPipeline(ctx context.Context, p *bulkio.Pipeline, fr *fileReport, run func(*bulkio.Pipeline) (bulkio.Metrics, error)) error {
+	c := *p
+	p = &c
+	var done chan struct{}
+	if *skipErrors {
+		errs := make(chan *bulkio.LineError)
+		done = make(chan struct{})
+		go func() {
+			defer close(done)
+			for e := range errs {
+				fmt.Fprintf(os.Stderr, "fhirdeid: %s: %v\n", fr.Input, e)
+			}
+		}()
+		p.Errors = errs
+		defer func() {
+			close(errs)
+			<-done
+		}()
+	}
+	m, err := run(p)
+	fr.Resources = m.Write.Processed - m.Write.Failed
+	fr.Failed = m.Read.Failed + m.Unmarshal.Failed + m.Transform.Failed + m.Marshal.Failed + m.Write.Failed
+	if err != nil {
+		return fmt.Errorf("%s: %w", fr.Input, err)
+	}
+	return nil
+}
+
+func writeReport(rep *report) error {
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *reportFile == "" {
+		_, err := os.Stderr.Write(data)
+		return err
+	}
+	return os.WriteFile(*reportFile, data, 0644)
+}
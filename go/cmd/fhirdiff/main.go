@@ -0,0 +1,323 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirdiff reports the element-level differences between two FHIR
+// resources or two datasets of resources.
+//
+// Usage:
+//
+//	fhirdiff old/patient.json new/patient.json
+//	fhirdiff -ignore meta.versionId,meta.lastUpdated -patch patches/ before.ndjson after.ndjson
+//
+// Two JSON files are compared as two versions of one resource. Otherwise the
+// arguments are datasets, NDJSON files or directories of JSON and NDJSON
+// files, whose resources are matched by type and id: fhirdiff reports the
+// resources only in the first, removed, only in the second, added, and the
+// changes of those in both. See package fhirdiff for how resources are
+// compared; -ignore lists elements to leave out, i.e. metadata updated by a
+// migration.
+//
+// The differences are printed as text, followed by a summary, or with
+// -format json as one JSON line per differing resource. With -patch, the JSON
+// Patch turning each changed resource into its new version is written to the
+// directory as <type>-<id>.json.
+//
+// Like diff, fhirdiff exits with status 0 if the inputs are the same, 1 if
+// they differ and 2 on trouble.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/fhirdiff"
+)
+
+var (
+	format   = flag.String("format", "text", "output format: text or json")
+	ignore   = flag.String("ignore", "", "comma separated paths of elements not to compare, i.e. meta.lastUpdated")
+	patchDir = flag.String("patch", "", "directory to write the JSON Patch of each changed resource to")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: fhirdiff [flags] old new\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	differ, err := run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fhirdiff: %v\n", err)
+		os.Exit(2)
+	}
+	if differ {
+		os.Exit(1)
+	}
+}
+
+// result is the comparison of a resource of the inputs.
+type result struct {
+	Resource string            `json:"resource"`
+	Status   string            `json:"status"`
+	Changes  []fhirdiff.Change `json:"changes,omitempty"`
+}
+
+// Statuses of results.
+const (
+	added   = "added"
+	removed = "removed"
+	changed = "changed"
+)
+
+// printer prints results and counts them.
+type printer struct {
+	w         *bufio.Writer
+	counts    map[string]int
+	unchanged int
+}
+
+func run() (bool, error) {
+	if flag.NArg() != 2 {
+		flag.Usage()
+		return false, errors.New("want two inputs")
+	}
+	if *format != "text" && *format != "json" {
+		return false, fmt.Errorf("unknown -format %q", *format)
+	}
+	var opts fhirdiff.Options
+	for _, p := range strings.Split(*ignore, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			opts.Ignore = append(opts.Ignore, p)
+		}
+	}
+	if *patchDir != "" {
+		if err := os.MkdirAll(*patchDir, 0755); err != nil {
+			return false, err
+		}
+	}
+	p := &printer{w: bufio.NewWriter(os.Stdout), counts: map[string]int{}}
+	defer p.w.Flush()
+
+	oldPath, newPath := flag.Arg(0), flag.Arg(1)
+	if isJSONFile(oldPath) && isJSONFile(newPath) {
+		a, err := os.ReadFile(oldPath)
+		if err != nil {
+			return false, err
+		}
+		b, err := os.ReadFile(newPath)
+		if err != nil {
+			return false, err
+		}
+		key, err := resourceKey(b)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", newPath, err)
+		}
+		if err := p.compare(key, a, b, opts); err != nil {
+			return false, err
+		}
+		return p.done(false)
+	}
+
+	// The old dataset is held in memory and the new one streamed.
+	old := map[string][]byte{}
+	err := readDataset(oldPath, func(key string, data []byte) error {
+		if _, ok := old[key]; ok {
+			return fmt.Errorf("duplicate resource %s", key)
+		}
+		old[key] = data
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	seen := map[string]bool{}
+	err = readDataset(newPath, func(key string, data []byte) error {
+		if seen[key] {
+			return fmt.Errorf("duplicate resource %s", key)
+		}
+		seen[key] = true
+		a, ok := old[key]
+		if !ok {
+			return p.print(result{Resource: key, Status: added})
+		}
+		delete(old, key)
+		return p.compare(key, a, data, opts)
+	})
+	if err != nil {
+		return false, err
+	}
+	var gone []string
+	for key := range old {
+		gone = append(gone, key)
+	}
+	sort.Strings(gone)
+	for _, key := range gone {
+		if err := p.print(result{Resource: key, Status: removed}); err != nil {
+			return false, err
+		}
+	}
+	return p.done(true)
+}
+
+func (p *printer) compare(key string, a, b []byte, opts fhirdiff.Options) error {
+	changes, err := fhirdiff.JSON(a, b, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if len(changes) == 0 {
+		p.unchanged++
+		return nil
+	}
+	if *patchDir != "" {
+		patch, err := fhirdiff.Patch(changes)
+		if err != nil {
+			return err
+		}
+		name := strings.ReplaceAll(key, "/", "-") + ".json"
+		if err := os.WriteFile(filepath.Join(*patchDir, name), append(patch, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	return p.print(result{Resource: key, Status: changed, Changes: changes})
+}
+
+func (p *printer) print(r result) error {
+	p.counts[r.Status]++
+	if *format == "json" {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		p.w.Write(line)
+		return p.w.WriteByte('\n')
+	}
+	fmt.Fprintf(p.w, "%s %s\n", r.Status, r.Resource)
+	for _, c := range r.Changes {
+		switch c.Op {
+		case fhirdiff.Add:
+			fmt.Fprintf(p.w, "  + %s: %s\n", c.Path, c.New)
+		case fhirdiff.Remove:
+			fmt.Fprintf(p.w, "  - %s: %s\n", c.Path, c.Old)
+		default:
+			fmt.Fprintf(p.w, "  ~ %s: %s -> %s\n", c.Path, c.Old, c.New)
+		}
+	}
+	return nil
+}
+
+// done prints the summary of the comparison of datasets, or of resources if
+// datasets is false, and tells whether the inputs differ.
+func (p *printer) done(datasets bool) (bool, error) {
+	differ := p.counts[added]+p.counts[removed]+p.counts[changed] > 0
+	if *format == "text" && datasets {
+		fmt.Fprintf(p.w, "%d changed, %d added, %d removed, %d unchanged\n", p.counts[changed], p.counts[added], p.counts[removed], p.unchanged)
+	}
+	return differ, p.w.Flush()
+}
+
+func isJSONFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir() && filepath.Ext(path) == ".json"
+}
+
+// readDataset calls fn with the "<type>/<id>" key and the JSON of each
+// resource of the NDJSON file, JSON file or directory path.
+func readDataset(path string, fn func(key string, data []byte) error) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	files := []string{path}
+	if fi.IsDir() {
+		files = nil
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ext := filepath.Ext(p); !d.IsDir() && (ext == ".json" || ext == ".ndjson") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+	}
+	for _, f := range files {
+		if err := readFile(f, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readFile(path string, fn func(key string, data []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if filepath.Ext(path) != ".ndjson" {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		key, err := resourceKey(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return fn(key, data)
+	}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 64<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		data := append([]byte(nil), sc.Bytes()...)
+		key, err := resourceKey(data)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if err := fn(key, data); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+	return sc.Err()
+}
+
+// resourceKey returns the "<type>/<id>" of the resource JSON data.
+func resourceKey(data []byte) (string, error) {
+	var res struct {
+		ResourceType string `json:"resourceType"`
+		ID           string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return "", err
+	}
+	if res.ResourceType == "" {
+		return "", errors.New("no resourceType")
+	}
+	return res.ResourceType + "/" + res.ID, nil
+}
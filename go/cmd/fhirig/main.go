@@ -0,0 +1,248 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirig inspects a FHIR implementation guide package.
+//
+// Usage:
+//
+//	fhirig hl7.fhir.us.core-6.1.0.tgz
+//	fhirig -list -format json hl7.fhir.us.core#6.1.0
+//
+// The package is a .tgz file, an extracted directory, or the "name#version"
+// of a package of the FHIR package cache of -cache. fhirig prints the number
+// of profiles, extensions, value sets, code systems and other resources of
+// the package, or with -list their canonical URLs, followed by its
+// dependency tree, whose packages are loaded from the cache, and the
+// conflicts between the loaded packages: packages required in several
+// versions and canonical resources defined differently by several packages.
+// Those are what make validation depend on the order packages are loaded in.
+//
+// Dependencies missing from the cache are reported in the tree; fhirig does
+// not download packages.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/fhir/go/igpackage"
+)
+
+var (
+	cache  = flag.String("cache", defaultCache(), "FHIR package cache directory holding name#version package directories")
+	list   = flag.Bool("list", false, "list the resources of the package rather than counting them")
+	noDeps = flag.Bool("no_deps", false, "do not resolve the dependencies of the package")
+	format = flag.String("format", "text", "output format: text or json")
+)
+
+func defaultCache() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".fhir", "packages")
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: fhirig [flags] package\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "fhirig: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// inspection is the JSON output.
+type inspection struct {
+	Name         string               `json:"name"`
+	Version      string               `json:"version"`
+	Contents     contents             `json:"contents"`
+	Dependencies []*dependency        `json:"dependencies,omitempty"`
+	Conflicts    []igpackage.Conflict `json:"conflicts,omitempty"`
+}
+
+type contents struct {
+	Profiles    []artifact `json:"profiles"`
+	Extensions  []artifact `json:"extensions"`
+	ValueSets   []artifact `json:"valueSets"`
+	CodeSystems []artifact `json:"codeSystems"`
+	Other       []artifact `json:"other"`
+}
+
+type artifact struct {
+	Type    string `json:"type"`
+	URL     string `json:"url,omitempty"`
+	Version string `json:"version,omitempty"`
+	Name    string `json:"name,omitempty"`
+}
+
+type dependency struct {
+	Name         string        `json:"name"`
+	Version      string        `json:"version"`
+	Error        string        `json:"error,omitempty"`
+	Repeated     bool          `json:"repeated,omitempty"`
+	Dependencies []*dependency `json:"dependencies,omitempty"`
+}
+
+func run() error {
+	if flag.NArg() != 1 {
+		flag.Usage()
+		return fmt.Errorf("want one package")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown -format %q", *format)
+	}
+	p, err := load(flag.Arg(0))
+	if err != nil {
+		return err
+	}
+	var tree *igpackage.Dependency
+	var conflicts []igpackage.Conflict
+	if !*noDeps {
+		tree = igpackage.Resolve(p, igpackage.CacheLoader(*cache))
+		conflicts = igpackage.Conflicts(tree)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if *format == "json" {
+		out := inspection{Name: p.Name, Version: p.Version, Contents: convertContents(p.Contents()), Conflicts: conflicts}
+		if tree != nil {
+			out.Dependencies = convertTree(tree).Dependencies
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+		return w.Flush()
+	}
+
+	fmt.Fprintln(w, p.ID())
+	c := p.Contents()
+	kinds := []struct {
+		name      string
+		artifacts []igpackage.Artifact
+	}{
+		{"profiles", c.Profiles},
+		{"extensions", c.Extensions},
+		{"value sets", c.ValueSets},
+		{"code systems", c.CodeSystems},
+		{"other resources", c.Other},
+	}
+	for _, k := range kinds {
+		fmt.Fprintf(w, "  %d %s\n", len(k.artifacts), k.name)
+		if !*list {
+			continue
+		}
+		for _, a := range k.artifacts {
+			printArtifact(w, a)
+		}
+	}
+	if tree == nil {
+		return w.Flush()
+	}
+	fmt.Fprintln(w, "dependencies:")
+	if len(tree.Dependencies) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, d := range tree.Dependencies {
+		printTree(w, d, "  ")
+	}
+	fmt.Fprintln(w, "conflicts:")
+	if len(conflicts) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, c := range conflicts {
+		fmt.Fprintf(w, "  %s %s: %s\n", c.Kind, c.Subject, strings.Join(c.Packages, ", "))
+	}
+	return w.Flush()
+}
+
+// load loads the package arg, a path or the ID of a package of the cache.
+func load(arg string) (*igpackage.Package, error) {
+	if _, err := os.Stat(arg); err != nil {
+		if name, version, ok := strings.Cut(arg, "#"); ok {
+			return igpackage.CacheLoader(*cache)(name, version)
+		}
+	}
+	return igpackage.Load(arg)
+}
+
+func printArtifact(w io.Writer, a igpackage.Artifact) {
+	id := a.URL
+	if id == "" {
+		id = a.Type + " " + a.Name
+	}
+	if a.Version != "" {
+		id += "|" + a.Version
+	}
+	if a.URL != "" && a.Name != "" {
+		id += " (" + a.Name + ")"
+	}
+	fmt.Fprintf(w, "    %s\n", id)
+}
+
+func printTree(w io.Writer, d *igpackage.Dependency, indent string) {
+	fmt.Fprintf(w, "%s%s#%s", indent, d.Name, d.Version)
+	switch {
+	case d.Err != nil:
+		fmt.Fprintf(w, " (not loaded: %v)", d.Err)
+	case d.Repeated:
+		fmt.Fprint(w, " (see above)")
+	}
+	fmt.Fprintln(w)
+	for _, dep := range d.Dependencies {
+		printTree(w, dep, indent+"  ")
+	}
+}
+
+func convertContents(c igpackage.Contents) contents {
+	return contents{
+		Profiles:    convertArtifacts(c.Profiles),
+		Extensions:  convertArtifacts(c.Extensions),
+		ValueSets:   convertArtifacts(c.ValueSets),
+		CodeSystems: convertArtifacts(c.CodeSystems),
+		Other:       convertArtifacts(c.Other),
+	}
+}
+
+func convertArtifacts(as []igpackage.Artifact) []artifact {
+	out := []artifact{}
+	for _, a := range as {
+		out = append(out, artifact{Type: a.Type, URL: a.URL, Version: a.Version, Name: a.Name})
+	}
+	return out
+}
+
+func convertTree(d *igpackage.Dependency) *dependency {
+	out := &dependency{Name: d.Name, Version: d.Version, Repeated: d.Repeated}
+	if d.Err != nil {
+		out.Error = d.Err.Error()
+	}
+	for _, dep := range d.Dependencies {
+		out.Dependencies = append(out.Dependencies, convertTree(dep))
+	}
+	return out
+}
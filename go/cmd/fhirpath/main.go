@@ -0,0 +1,335 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirpath evaluates a FHIRPath expression against FHIR R4 resources.
+//
+// Usage:
+//
+//	fhirpath "Patient.name.where(use = 'official').given" patient.json
+//	fhirpath -bool "value.exists() or component.exists()" < observations.ndjson
+//
+// The inputs are JSON files holding one resource, NDJSON files holding one
+// resource per line, or directories of them; NDJSON is read from stdin if
+// there are none. For each resource, fhirpath prints a line with the JSON of
+// the source of the resource and the result of the expression, or the error
+// evaluating it, in which case fhirpath exits with status 1 at the end:
+//
+//	{"source":"patients.ndjson:3","result":["Peter","James"]}
+//	{"source":"patients.ndjson:4","error":"..."}
+//
+// Primitives are printed as JSON values, FHIRPath dates, times and quantities
+// computed by the expression as their string form, and other elements and
+// resources as their FHIR JSON.
+// With -bool, the result is converted to a boolean as for an invariant, and
+// with -types each item is printed with its FHIRPath type.
+//
+// With -resolve, the resolve() function finds the resources of the inputs by
+// their relative references, i.e. "Patient/123".
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+var (
+	asBool   = flag.Bool("bool", false, "convert the result to a boolean, as for invariants")
+	types    = flag.Bool("types", false, "print the FHIRPath type of each item of the result")
+	resolve  = flag.Bool("resolve", false, "resolve references to the resources of the inputs")
+	now      = flag.String("now", "", "RFC 3339 time returned by now(); the current time if empty")
+	timezone = flag.String("timezone", "UTC", "time zone of dates and times without one")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: fhirpath [flags] expression [file or directory...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := run(flag.Args(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "fhirpath: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// input is a resource to evaluate the expression against.
+type input struct {
+	source string
+	res    proto.Message
+}
+
+// run evaluates the expression args[0] against the resources of the files
+// and directories args[1:], or of stdin if there are none, and writes the
+// results to stdout.
+func run(args []string, stdin io.Reader, stdout io.Writer) (err error) {
+	if len(args) == 0 {
+		flag.Usage()
+		return fmt.Errorf("no expression")
+	}
+	expr, err := fhirpath.Compile(args[0])
+	if err != nil {
+		return err
+	}
+	var opts []fhirpath.EvaluateOption
+	if *now != "" {
+		t, err := time.Parse(time.RFC3339, *now)
+		if err != nil {
+			return fmt.Errorf("invalid -now: %w", err)
+		}
+		opts = append(opts, fhirpath.WithNow(t))
+	}
+	um, err := jsonformat.NewUnmarshallerWithoutValidation(*timezone, fhirversion.R4)
+	if err != nil {
+		return err
+	}
+	m, err := jsonformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(stdout)
+	defer w.Flush()
+	failed := 0
+	eval := func(in input) error {
+		out := struct {
+			Source string      `json:"source"`
+			Result interface{} `json:"result,omitempty"`
+			Error  string      `json:"error,omitempty"`
+		}{Source: in.source}
+		res, err := evaluate(expr, in.res, opts)
+		var items []interface{}
+		if err == nil {
+			items, err = encode(m, res)
+		}
+		if err != nil {
+			out.Error = err.Error()
+			failed++
+		} else {
+			// An empty result is printed as [], unlike a failure.
+			out.Result = items
+		}
+		line, err := json.Marshal(out)
+		if err != nil {
+			return err
+		}
+		w.Write(line)
+		return w.WriteByte('\n')
+	}
+	defer func() {
+		if err == nil && failed > 0 {
+			err = fmt.Errorf("evaluation failed for %d resources", failed)
+		}
+	}()
+
+	if !*resolve {
+		return readInputs(um, args[1:], stdin, eval)
+	}
+	var inputs []input
+	byRef := map[string]proto.Message{}
+	err = readInputs(um, args[1:], stdin, func(in input) error {
+		inputs = append(inputs, in)
+		if id := elementpath.ID(in.res); id != "" {
+			byRef[elementpath.ResourceType(in.res)+"/"+id] = in.res
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	opts = append(opts, fhirpath.WithResolver(func(ref string) (proto.Message, error) {
+		if i := strings.Index(ref, "/_history/"); i >= 0 {
+			ref = ref[:i]
+		}
+		return byRef[ref], nil
+	}))
+	for _, in := range inputs {
+		if err := eval(in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evaluate(expr *fhirpath.Expression, res proto.Message, opts []fhirpath.EvaluateOption) (fhirpath.Collection, error) {
+	if *asBool {
+		b, err := expr.EvaluateBool(res, opts...)
+		return fhirpath.Collection{b}, err
+	}
+	return expr.Evaluate(res, opts...)
+}
+
+// encode returns the JSON values of the items of c.
+func encode(m *jsonformat.Marshaller, c fhirpath.Collection) ([]interface{}, error) {
+	out := []interface{}{}
+	for _, item := range c {
+		typ := fhirpathType(item)
+		v, err := encodeItem(m, item)
+		if err != nil {
+			return nil, err
+		}
+		if *types {
+			v = map[string]interface{}{"type": typ, "value": v}
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func encodeItem(m *jsonformat.Marshaller, item interface{}) (interface{}, error) {
+	if msg, ok := item.(proto.Message); ok && !elementpath.IsPrimitive(msg.ProtoReflect().Descriptor()) {
+		var data []byte
+		var err error
+		if res := elementpath.Unwrap(msg); res != nil && elementpath.IsResource(res.ProtoReflect().Descriptor()) {
+			data, err = m.MarshalResource(res)
+		} else {
+			data, err = m.MarshalElement(msg)
+		}
+		return json.RawMessage(data), err
+	}
+	v, ok := fhirpath.SystemValue(item)
+	if !ok {
+		return nil, nil
+	}
+	switch x := v.(type) {
+	case bool, string, int64, float64:
+		return x, nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+// fhirpathType returns the FHIRPath type name of item, i.e. "System.String"
+// or "FHIR.HumanName".
+func fhirpathType(item interface{}) string {
+	if m, ok := item.(proto.Message); ok {
+		if res := elementpath.Unwrap(m); res != nil {
+			m = res
+		}
+		return "FHIR." + fhirpath.TypeName(m.ProtoReflect().Descriptor())
+	}
+	switch x := item.(type) {
+	case bool:
+		return "System.Boolean"
+	case string:
+		return "System.String"
+	case int64:
+		return "System.Integer"
+	case float64:
+		return "System.Decimal"
+	case fhirpath.Quantity:
+		return "System.Quantity"
+	case fhirpath.Temporal:
+		return "System." + [...]string{"Date", "DateTime", "Time"}[x.Kind]
+	}
+	return fmt.Sprintf("%T", item)
+}
+
+// readInputs calls fn with the resources of the files and directories paths,
+// or of stdin if there are none.
+func readInputs(um *jsonformat.Unmarshaller, paths []string, stdin io.Reader, fn func(input) error) error {
+	if len(paths) == 0 {
+		return readNDJSON(um, "stdin", stdin, fn)
+	}
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		files := []string{p}
+		if fi.IsDir() {
+			files = nil
+			err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if ext := filepath.Ext(path); !d.IsDir() && (ext == ".json" || ext == ".ndjson") {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			sort.Strings(files)
+		}
+		for _, path := range files {
+			if err := readFile(um, path, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readFile(um *jsonformat.Unmarshaller, path string, fn func(input) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if filepath.Ext(path) == ".ndjson" {
+		return readNDJSON(um, path, f, fn)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	cr, err := um.UnmarshalR4(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return fn(input{source: path, res: resource(cr)})
+}
+
+func readNDJSON(um *jsonformat.Unmarshaller, name string, r io.Reader, fn func(input) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		source := fmt.Sprintf("%s:%d", name, line)
+		cr, err := um.UnmarshalR4(sc.Bytes())
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if err := fn(input{source: source, res: resource(cr)}); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// resource returns the resource of cr, which is the focus of the expression.
+func resource(cr *r4pb.ContainedResource) proto.Message {
+	if res := elementpath.Unwrap(cr); res != nil {
+		return res
+	}
+	return cr
+}
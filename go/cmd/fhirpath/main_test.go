@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	patientJSON = `{"resourceType":"Patient","id":"p1","name":[{"use":"official","family":"Doe","given":["Jane","Q"]}]}`
+	obsNDJSON   = `{"resourceType":"Observation","id":"o1","status":"final","code":{"text":"x"},"subject":{"reference":"Patient/p1"},"valueString":"a"}
+
+{"resourceType":"Observation","id":"o2","status":"final","code":{"text":"y"}}
+`
+)
+
+// setFlags sets the flags run reads for the duration of a test.
+func setFlags(t *testing.T, values map[string]string) {
+	t.Helper()
+	for name, v := range values {
+		name, old := name, flag.Lookup(name).Value.String()
+		if err := flag.Set(name, v); err != nil {
+			t.Fatalf("flag.Set(%q, %q) returned unexpected error: %v", name, v, err)
+		}
+		t.Cleanup(func() { flag.Set(name, old) })
+	}
+}
+
+// writeFile writes data to the file name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	return path
+}
+
+// decodeLines returns the JSON values of the lines of out.
+func decodeLines(t *testing.T, out []byte) []interface{} {
+	t.Helper()
+	var got []interface{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+		var v interface{}
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("json.Unmarshal(%q) returned unexpected error: %v", line, err)
+		}
+		got = append(got, v)
+	}
+	return got
+}
+
+func TestRun_Files(t *testing.T) {
+	dir := t.TempDir()
+	patient := writeFile(t, dir, "patient.json", patientJSON)
+	obs := writeFile(t, dir, "observations.ndjson", obsNDJSON)
+
+	tests := []struct {
+		name  string
+		flags map[string]string
+		args  []string
+		want  string
+	}{
+		{
+			name: "json file",
+			args: []string{"Patient.name.where(use = 'official').given", patient},
+			want: `{"source":"` + patient + `","result":["Jane","Q"]}`,
+		},
+		{
+			name: "ndjson file",
+			args: []string{"value", obs},
+			want: `{"source":"` + obs + `:1","result":["a"]}
+{"source":"` + obs + `:3","result":[]}`,
+		},
+		{
+			name: "directory",
+			args: []string{"id", dir},
+			want: `{"source":"` + obs + `:1","result":["o1"]}
+{"source":"` + obs + `:3","result":["o2"]}
+{"source":"` + patient + `","result":["p1"]}`,
+		},
+		{
+			name: "element",
+			args: []string{"name", patient},
+			want: `{"source":"` + patient + `","result":[{"use":"official","family":"Doe","given":["Jane","Q"]}]}`,
+		},
+		{
+			name:  "types",
+			flags: map[string]string{"types": "true"},
+			args:  []string{"name.family | name.given.count()", patient},
+			want:  `{"source":"` + patient + `","result":[{"type":"FHIR.string","value":"Doe"},{"type":"System.Integer","value":2}]}`,
+		},
+		{
+			name:  "resolve",
+			flags: map[string]string{"resolve": "true"},
+			args:  []string{"subject.resolve().name.family", obs, patient},
+			want: `{"source":"` + obs + `:1","result":["Doe"]}
+{"source":"` + obs + `:3","result":[]}
+{"source":"` + patient + `","result":[]}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setFlags(t, tc.flags)
+			var out bytes.Buffer
+			if err := run(tc.args, strings.NewReader(""), &out); err != nil {
+				t.Fatalf("run(%q) returned unexpected error: %v", tc.args, err)
+			}
+			if diff := cmp.Diff(decodeLines(t, []byte(tc.want)), decodeLines(t, out.Bytes())); diff != "" {
+				t.Errorf("run(%q) output diff (-want +got):\n%s", tc.args, diff)
+			}
+		})
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	setFlags(t, map[string]string{"bool": "true"})
+	var out bytes.Buffer
+	if err := run([]string{"value.exists()"}, strings.NewReader(obsNDJSON), &out); err != nil {
+		t.Fatalf("run() returned unexpected error: %v", err)
+	}
+	want := `{"source":"stdin:1","result":[true]}
+{"source":"stdin:3","result":[false]}`
+	if diff := cmp.Diff(decodeLines(t, []byte(want)), decodeLines(t, out.Bytes())); diff != "" {
+		t.Errorf("run() output diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_EvaluationError(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"name.given.single()"}, strings.NewReader(patientJSON+"\n"), &out)
+	if err == nil {
+		t.Errorf("run() succeeded, want error")
+	}
+	got := decodeLines(t, out.Bytes())
+	if len(got) != 1 {
+		t.Fatalf("run() printed %d lines, want 1", len(got))
+	}
+	line, _ := got[0].(map[string]interface{})
+	if line["source"] != "stdin:1" || line["error"] == nil || line["result"] != nil {
+		t.Errorf("run() printed %v, want the error of stdin:1", got[0])
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirschema generates BigQuery, Avro or Parquet schemas for FHIR R4
+// resources and profiles.
+//
+// Usage:
+//
+//	fhirschema -format bigquery -resources Patient,Observation -out schemas/
+//	fhirschema -format avro -namespace org.example -package us-core.tgz -profiles http://hl7.org/fhir/us/core/StructureDefinition/us-core-patient
+//	fhirschema -format parquet -flatten -depth 1 -resources Encounter
+//
+// The schemas describe the rows of the analytics marshaller of jsonformat
+// with the same -depth; see package schemagen. Profiles are read from the
+// implementation guide -package and restrict the schema of their resource
+// type.
+//
+// Each schema is written to the -out directory as <name>.json for BigQuery,
+// <name>.avsc for Avro and <name>.parquet.txt for Parquet, where name is the
+// resource type or the name of the profile, or to standard output if there
+// is a single schema and no -out.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/fhir/go/igpackage"
+	"github.com/google/fhir/go/schemagen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	// Registers every R4 resource type.
+	_ "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+const r4Package = "google.fhir.r4.core"
+
+var (
+	format    = flag.String("format", "bigquery", "schema format: bigquery, avro or parquet")
+	resources = flag.String("resources", "", "comma separated FHIR resource types")
+	pkg       = flag.String("package", "", "implementation guide package, a .tgz file or directory, holding the -profiles")
+	profiles  = flag.String("profiles", "", "comma separated canonical URLs of profiles of -package")
+	depth     = flag.Int("depth", schemagen.DefaultMaxDepth, "maximum number of times a field of the same name occurs on a path")
+	flat      = flag.Bool("flatten", false, "replace the records of single elements by their fields")
+	namespace = flag.String("namespace", "", "namespace of Avro schemas")
+	out       = flag.String("out", "", "output directory; standard output if empty")
+)
+
+var extensions = map[string]string{
+	"bigquery": ".json",
+	"avro":     ".avsc",
+	"parquet":  ".parquet.txt",
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "fhirschema: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ext, ok := extensions[*format]
+	if !ok {
+		return fmt.Errorf("unknown -format %q", *format)
+	}
+	if *resources == "" && *profiles == "" {
+		flag.Usage()
+		return errors.New("-resources or -profiles is required")
+	}
+	opts := schemagen.Options{MaxDepth: *depth, Flatten: *flat}
+	var schemas []*schemagen.Schema
+	for _, name := range split(*resources) {
+		md, err := resourceDescriptor(name)
+		if err != nil {
+			return err
+		}
+		s, err := schemagen.Generate(md, opts)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, s)
+	}
+	if urls := split(*profiles); len(urls) > 0 {
+		if *pkg == "" {
+			return errors.New("-package is required with -profiles")
+		}
+		p, err := igpackage.Load(*pkg)
+		if err != nil {
+			return err
+		}
+		for _, url := range urls {
+			sd, ok := p.StructureDefinition(url)
+			if !ok {
+				return fmt.Errorf("no profile %s in %s", url, p.ID())
+			}
+			md, err := resourceDescriptor(sd.GetType().GetValue())
+			if err != nil {
+				return fmt.Errorf("profile %s: %w", url, err)
+			}
+			popts := opts
+			popts.Profile = sd
+			s, err := schemagen.Generate(md, popts)
+			if err != nil {
+				return err
+			}
+			schemas = append(schemas, s)
+		}
+	}
+
+	if *out == "" && len(schemas) > 1 {
+		return errors.New("-out is required for several schemas")
+	}
+	if *out != "" {
+		if err := os.MkdirAll(*out, 0755); err != nil {
+			return err
+		}
+	}
+	for _, s := range schemas {
+		data, err := render(s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+		if *out == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(*out, s.Name+ext), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func render(s *schemagen.Schema) ([]byte, error) {
+	switch *format {
+	case "avro":
+		data, err := s.Avro(*namespace)
+		return append(data, '\n'), err
+	case "parquet":
+		return []byte(s.Parquet()), nil
+	default:
+		data, err := s.BigQuery()
+		return append(data, '\n'), err
+	}
+}
+
+func resourceDescriptor(name string) (protoreflect.MessageDescriptor, error) {
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(r4Package + "." + name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown resource type %q", name)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message", name)
+	}
+	return md, nil
+}
+
+func split(list string) []string {
+	var out []string
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirupload uploads FHIR R4 resources to a server as transaction
+// Bundles.
+//
+// Usage:
+//
+//	fhirupload -base https://example.com/fhir -ids ids.csv data/ more.ndjson
+//
+// The arguments are JSON files of one resource each, NDJSON files, or
+// directories of them. The resources are uploaded in transactions of at most
+// -bundle_size resources, ordered so that referenced resources are uploaded
+// before or with the resources referencing them. The server assigns the ids of
+// the resources, and references to uploaded resources are rewritten to them;
+// with -keep_ids, the resources are instead updated at their own ids.
+//
+// Requests failing with a 429 or 5xx status or a network error are retried
+// up to -retries times, with exponential backoff.
+//
+// The -ids file records the id each resource was assigned, as lines of
+// "<local reference>,<server reference>", i.e. "Patient/a,Patient/123", after
+// each transaction. An interrupted upload is resumed by running fhirupload
+// again with the same -ids: transactions whose resources all are in the file
+// are skipped.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/bulkdata"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/fhir/go/transaction"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	base       = flag.String("base", "", "FHIR base URL of the server")
+	token      = flag.String("token", "", "access token of the requests")
+	idsFile    = flag.String("ids", "", "CSV file recording the server references of the uploaded resources")
+	bundleSize = flag.Int("bundle_size", transaction.DefaultBundleSize, "largest number of resources of a transaction")
+	keepIDs    = flag.Bool("keep_ids", false, "update the resources at their own ids rather than have the server assign them")
+	retries    = flag.Int("retries", transaction.DefaultMaxAttempts, "number of attempts of a transaction")
+	timezone   = flag.String("timezone", "UTC", "time zone of dates and times without one")
+	dryRun     = flag.Bool("dry_run", false, "print the transactions instead of uploading them")
+)
+
+func main() {
+	flag.Parse()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "fhirupload: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context) error {
+	if (*base == "" && !*dryRun) || flag.NArg() == 0 {
+		flag.Usage()
+		return fmt.Errorf("-base and at least one input are required")
+	}
+	resources, err := readResources(flag.Args())
+	if err != nil {
+		return err
+	}
+	batches, err := transaction.Plan(resources, transaction.Options{BundleSize: *bundleSize, KeepIDs: *keepIDs})
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		return printBatches(batches)
+	}
+
+	ids := transaction.IDMap{}
+	var record *csv.Writer
+	if *idsFile != "" {
+		if ids, err = readIDs(*idsFile); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(*idsFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		record = csv.NewWriter(f)
+	}
+	u := &transaction.Uploader{BaseURL: *base, MaxAttempts: *retries}
+	if *token != "" {
+		u.Auth = bulkdata.StaticToken(*token)
+	}
+	uploaded := 0
+	for i, b := range batches {
+		if err := u.UploadBatch(ctx, b, ids); err != nil {
+			return fmt.Errorf("transaction %d of %d: %w", i+1, len(batches), err)
+		}
+		if record != nil {
+			for _, key := range b.Keys {
+				if key != "" {
+					record.Write([]string{key, ids[key]})
+				}
+			}
+			if record.Flush(); record.Error() != nil {
+				return record.Error()
+			}
+		}
+		uploaded += len(b.Keys)
+		fmt.Fprintf(os.Stderr, "fhirupload: %d of %d resources uploaded\n", uploaded, len(resources))
+	}
+	return nil
+}
+
+// readResources reads the resources of the files and directories paths.
+func readResources(paths []string) ([]proto.Message, error) {
+	um, err := jsonformat.NewUnmarshaller(*timezone, fhirversion.R4)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		var found []string
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if ext := filepath.Ext(path); !d.IsDir() && (ext == ".json" || ext == ".ndjson") {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+
+	var out []proto.Message
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Ext(path) == ".ndjson" {
+			err = readNDJSON(um, f, &out)
+		} else {
+			err = readJSON(um, f, &out)
+		}
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return out, nil
+}
+
+func readJSON(um *jsonformat.Unmarshaller, r io.Reader, out *[]proto.Message) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	cr, err := um.UnmarshalR4(data)
+	if err != nil {
+		return err
+	}
+	*out = append(*out, cr)
+	return nil
+}
+
+func readNDJSON(um *jsonformat.Unmarshaller, r io.Reader, out *[]proto.Message) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		cr, err := um.UnmarshalR4(sc.Bytes())
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		*out = append(*out, cr)
+	}
+	return sc.Err()
+}
+
+// readIDs reads the id mapping recorded in path, if it exists.
+func readIDs(path string) (transaction.IDMap, error) {
+	ids := transaction.IDMap{}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ids, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = 2
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ids[rec[0]] = rec[1]
+	}
+}
+
+func printBatches(batches []*transaction.Batch) error {
+	m, err := jsonformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(os.Stdout)
+	for _, b := range batches {
+		data, err := m.MarshalResource(b.Bundle)
+		if err != nil {
+			return err
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+	}
+	return w.Flush()
+}
@@ -0,0 +1,448 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command fhirvalidate validates FHIR R4 resources against the core
+// specification and the profiles of implementation guide packages.
+//
+// Usage:
+//
+//	fhirvalidate -ig hl7.fhir.us.core-6.1.0.tgz patient.json observations.ndjson
+//	fhirvalidate -ig us-core/ -profile http://hl7.org/fhir/us/core/StructureDefinition/us-core-patient -format outcome patients/
+//	fhirvalidate -rules org-rules.yaml observations.ndjson
+//	fhirvalidate -ig us-core/ -suppress known-issues.yaml data/
+//
+// Inputs are JSON files holding one resource, NDJSON files holding one
+// resource per line, or directories, whose .json and .ndjson files are
+// validated recursively. Every resource is checked against the core rules of
+// package fhirvalidate, the profiles of its meta.profile found in the -ig
+// packages and the -profile profiles; see package igpackage for what is
+// checked of profiles. Required bindings of profiles to core value sets are
+// checked offline with the codes of package terminology. The -rules files add
+// local business rules; see package rules for their format.
+//
+// The -suppress files list known issues that are accepted, with their
+// justification; see package suppress for their format. Suppressed issues
+// neither count nor fail the validation. They are only counted in the table
+// summary and are information issues, with their justification, of the
+// OperationOutcome. Expired suppressions are reported on standard error and
+// no longer apply.
+//
+// The issues are printed as a table, followed by a summary, or as the JSON of
+// an OperationOutcome with -format outcome. fhirvalidate exits with status 1
+// if a resource has an error that is not suppressed and 2 if the validation
+// could not run.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/igpackage"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+	"github.com/google/fhir/go/rules"
+	"github.com/google/fhir/go/suppress"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+)
+
+var (
+	igs          = flag.String("ig", "", "comma separated implementation guide packages, as .tgz files or directories")
+	profiles     = flag.String("profile", "", "comma separated canonical URLs of profiles every resource is validated against")
+	format       = flag.String("format", "table", "output format: table or outcome")
+	timeZone     = flag.String("timezone", "UTC", "time zone of dates and times without one")
+	ruleFiles    = flag.String("rules", "", "comma separated YAML or JSON files of business rules")
+	suppressions = flag.String("suppress", "", "comma separated YAML or JSON files of suppressed issues")
+)
+
+// coreKey is the key of the constraint of the core rules.
+const coreKey = "core"
+
+func main() {
+	flag.Parse()
+	failed, err := run(flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fhirvalidate: %v\n", err)
+		os.Exit(2)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// issue is an issue of a resource.
+type issue struct {
+	// location is the file, and line for NDJSON, of the resource.
+	location string
+	code     c4pb.IssueTypeCode_Value
+	revalidate.Issue
+	// suppressed is the suppression of the issue, if any.
+	suppressed *suppress.Suppression
+}
+
+func run(inputs []string) (bool, error) {
+	if len(inputs) == 0 {
+		flag.Usage()
+		return false, fmt.Errorf("no input")
+	}
+	if *format != "table" && *format != "outcome" {
+		return false, fmt.Errorf("unknown format %q", *format)
+	}
+	v, err := newValidator()
+	if err != nil {
+		return false, err
+	}
+	files, err := inputFiles(inputs)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range files {
+		if err := v.validateFile(f); err != nil {
+			return false, err
+		}
+	}
+	errors := 0
+	for _, i := range v.issues {
+		if i.suppressed == nil && i.Severity == errorreporter.IssueSeverityError {
+			errors++
+		}
+	}
+	if *format == "outcome" {
+		err = writeOutcome(v.issues)
+	} else {
+		err = writeTable(v.issues, v.resources)
+	}
+	return errors > 0, err
+}
+
+// inputFiles returns the files of inputs, expanding directories.
+func inputFiles(inputs []string) ([]string, error) {
+	var files []string
+	for _, in := range inputs {
+		fi, err := os.Stat(in)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, in)
+			continue
+		}
+		err = filepath.WalkDir(in, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if ext := filepath.Ext(path); ext == ".json" || ext == ".ndjson" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// validator validates resources and collects their issues.
+type validator struct {
+	um       *jsonformat.Unmarshaller
+	packages []*igpackage.Package
+	// profiles are the profiles of -profile.
+	profiles []string
+	// rules are the constraints of the -rules files.
+	rules []revalidate.Constraint
+	// suppressions are the suppressions of the -suppress files that have not
+	// expired.
+	suppressions []suppress.Suppression
+	// found and constraints cache whether profiles are in the packages and
+	// their constraints, by URL.
+	found       map[string]bool
+	constraints map[string][]revalidate.Constraint
+	// validators caches the validators of the sets of profiles, by their
+	// joined URLs.
+	validators map[string]*revalidate.Validator
+	resources  int
+	issues     []issue
+}
+
+func newValidator() (*validator, error) {
+	um, err := jsonformat.NewUnmarshallerWithoutValidation(*timeZone, fhirversion.R4)
+	if err != nil {
+		return nil, err
+	}
+	v := &validator{
+		um:          um,
+		found:       map[string]bool{},
+		constraints: map[string][]revalidate.Constraint{},
+		validators:  map[string]*revalidate.Validator{},
+	}
+	for _, path := range splitList(*igs) {
+		p, err := igpackage.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		v.packages = append(v.packages, p)
+	}
+	for _, url := range splitList(*profiles) {
+		if _, ok := v.profile(url); !ok {
+			return nil, fmt.Errorf("profile %s is not in the -ig packages", url)
+		}
+		v.profiles = append(v.profiles, url)
+	}
+	for _, path := range splitList(*ruleFiles) {
+		cs, err := rules.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		v.rules = append(v.rules, cs...)
+	}
+	var ss []suppress.Suppression
+	for _, path := range splitList(*suppressions) {
+		l, err := suppress.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, l...)
+	}
+	var expired []suppress.Suppression
+	v.suppressions, expired = suppress.Active(ss, time.Now())
+	for _, s := range expired {
+		fmt.Fprintf(os.Stderr, "fhirvalidate: suppression of %s expired on %s\n", s, s.Expires)
+	}
+	return v, nil
+}
+
+func splitList(s string) []string {
+	var l []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			l = append(l, e)
+		}
+	}
+	return l
+}
+
+// profile returns the constraints of the profile url, and whether it is in
+// the packages. Profiles whose constraints cannot be built are reported and
+// treated as missing.
+func (v *validator) profile(url string) ([]revalidate.Constraint, bool) {
+	if found, ok := v.found[url]; ok {
+		return v.constraints[url], found
+	}
+	v.found[url] = false
+	for _, p := range v.packages {
+		sd, ok := p.StructureDefinition(url)
+		if !ok {
+			continue
+		}
+		cs, err := igpackage.Constraints(sd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fhirvalidate: %v\n", err)
+			break
+		}
+		v.found[url], v.constraints[url] = true, cs
+		break
+	}
+	return v.constraints[url], v.found[url]
+}
+
+func (v *validator) validateFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(path) != ".ndjson" {
+		v.validate(path, data)
+		return nil
+	}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, len(data)+1)
+	for line := 1; s.Scan(); line++ {
+		if len(bytes.TrimSpace(s.Bytes())) > 0 {
+			v.validate(fmt.Sprintf("%s:%d", path, line), s.Bytes())
+		}
+	}
+	return s.Err()
+}
+
+// validate validates the resource of the JSON data at location.
+func (v *validator) validate(location string, data []byte) {
+	v.resources++
+	cr, err := v.um.UnmarshalR4(data)
+	if err != nil {
+		v.add(issue{location: location, code: c4pb.IssueTypeCode_STRUCTURE, Issue: revalidate.Issue{
+			Severity: errorreporter.IssueSeverityError,
+			Message:  err.Error(),
+		}})
+		return
+	}
+	urls := append([]string(nil), v.profiles...)
+	for _, p := range metaProfiles(cr) {
+		urls = append(urls, p.GetValue())
+	}
+	sort.Strings(urls)
+	var known []string
+	for i, url := range urls {
+		if i > 0 && url == urls[i-1] {
+			continue
+		}
+		if _, ok := v.profile(url); !ok {
+			v.add(issue{location: location, code: c4pb.IssueTypeCode_NOT_FOUND, Issue: revalidate.Issue{
+				Path:     "meta.profile",
+				Severity: errorreporter.IssueSeverityWarning,
+				Message:  fmt.Sprintf("profile %s is not in the -ig packages", url),
+			}})
+			continue
+		}
+		known = append(known, url)
+	}
+	rv, err := v.validatorOf(known)
+	if err == nil {
+		var res *revalidate.Result
+		if res, err = rv.Validate(cr); err == nil {
+			for _, i := range res.Issues {
+				code := c4pb.IssueTypeCode_INVARIANT
+				if i.Constraint == coreKey {
+					code = c4pb.IssueTypeCode_VALUE
+				}
+				v.add(issue{location: location, code: code, Issue: i})
+			}
+			return
+		}
+	}
+	v.add(issue{location: location, code: c4pb.IssueTypeCode_EXCEPTION, Issue: revalidate.Issue{
+		Severity: errorreporter.IssueSeverityError,
+		Message:  err.Error(),
+	}})
+}
+
+// add adds the issue i, with its suppression if any.
+func (v *validator) add(i issue) {
+	if s, ok := suppress.Find(v.suppressions, i.Issue); ok {
+		i.suppressed = &s
+	}
+	v.issues = append(v.issues, i)
+}
+
+// validatorOf returns the validator of the core rules and the profiles urls.
+func (v *validator) validatorOf(urls []string) (*revalidate.Validator, error) {
+	key := strings.Join(urls, " ")
+	if rv, ok := v.validators[key]; ok {
+		return rv, nil
+	}
+	cs := append([]revalidate.Constraint{revalidate.Structure(coreKey)}, v.rules...)
+	for _, url := range urls {
+		pcs, _ := v.profile(url)
+		cs = append(cs, pcs...)
+	}
+	rv, err := revalidate.NewValidator(cs...)
+	if err != nil {
+		return nil, err
+	}
+	v.validators[key] = rv
+	return rv, nil
+}
+
+// metaProfiles returns the meta.profile of the resource of cr.
+func metaProfiles(cr *r4pb.ContainedResource) []*d4pb.Canonical {
+	rcr := cr.ProtoReflect()
+	f := rcr.WhichOneof(rcr.Descriptor().Oneofs().Get(0))
+	if f == nil {
+		return nil
+	}
+	res, ok := rcr.Get(f).Message().Interface().(interface{ GetMeta() *d4pb.Meta })
+	if !ok {
+		return nil
+	}
+	return res.GetMeta().GetProfile()
+}
+
+func writeTable(issues []issue, resources int) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	errors, warnings, suppressed := 0, 0, 0
+	header := false
+	for _, i := range issues {
+		if i.suppressed != nil {
+			suppressed++
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "LOCATION\tSEVERITY\tPATH\tMESSAGE")
+			header = true
+		}
+		switch i.Severity {
+		case errorreporter.IssueSeverityError:
+			errors++
+		case errorreporter.IssueSeverityWarning:
+			warnings++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", i.location, i.Severity, i.Path, i.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Printf("%d resources, %d errors, %d warnings, %d suppressed\n", resources, errors, warnings, suppressed)
+	return err
+}
+
+func writeOutcome(issues []issue) error {
+	outcome := &oopb.OperationOutcome{}
+	for _, i := range issues {
+		oi := &oopb.OperationOutcome_Issue{
+			Severity:    &oopb.OperationOutcome_Issue_SeverityCode{Value: errorreporter.R4IssueSeverityCodeMap[i.Severity]},
+			Code:        &oopb.OperationOutcome_Issue_CodeType{Value: i.code},
+			Diagnostics: &d4pb.String{Value: i.Message},
+			Location:    []*d4pb.String{{Value: i.location}},
+		}
+		if i.Path != "" {
+			oi.Expression = []*d4pb.String{{Value: i.Path}}
+		}
+		if i.suppressed != nil {
+			oi.Severity.Value = c4pb.IssueSeverityCode_INFORMATION
+			oi.Diagnostics.Value = fmt.Sprintf("suppressed %s: %s (%s)", i.Severity, i.Message, i.suppressed.Justification)
+		}
+		outcome.Issue = append(outcome.Issue, oi)
+	}
+	if len(outcome.Issue) == 0 {
+		outcome.Issue = append(outcome.Issue, &oopb.OperationOutcome_Issue{
+			Severity:    &oopb.OperationOutcome_Issue_SeverityCode{Value: c4pb.IssueSeverityCode_INFORMATION},
+			Code:        &oopb.OperationOutcome_Issue_CodeType{Value: c4pb.IssueTypeCode_INFORMATIONAL},
+			Diagnostics: &d4pb.String{Value: "No issues found"},
+		})
+	}
+	m, err := jsonformat.NewPrettyMarshaller(fhirversion.R4)
+	if err != nil {
+		return err
+	}
+	data, err := m.MarshalResource(outcome)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(append(data, '\n'))
+	return err
+}
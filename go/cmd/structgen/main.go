@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command structgen generates plain Go structs and proto converters for FHIR
+// R4 resources.
+//
+// Usage:
+//
+//	structgen -package mystructs -resources Patient,Observation -out structs.go
+//
+// Structs are generated for the named resources and every FHIR data type and
+// backbone element they use. See package structgen for the representation.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/fhir/go/structgen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	// Registers every R4 resource type.
+	_ "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+const r4Package = "google.fhir.r4.core"
+
+var (
+	pkg       = flag.String("package", "", "name of the generated Go package")
+	resources = flag.String("resources", "", "comma separated FHIR resource types to generate")
+	out       = flag.String("out", "", "output file; standard output if empty")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "structgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if *pkg == "" || *resources == "" {
+		flag.Usage()
+		return fmt.Errorf("-package and -resources are required")
+	}
+	cfg := structgen.Config{
+		Package: *pkg,
+		Command: "structgen " + strings.Join(os.Args[1:], " "),
+	}
+	for _, name := range strings.Split(*resources, ",") {
+		name = strings.TrimSpace(name)
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(r4Package + "." + name))
+		if err != nil {
+			return fmt.Errorf("unknown resource type %q", name)
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			return fmt.Errorf("%s is not a message", name)
+		}
+		cfg.Messages = append(cfg.Messages, md)
+	}
+	src, err := structgen.Generate(cfg)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0644)
+}
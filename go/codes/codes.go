@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codes converts the generated enums of the R4 code and value set
+// protos to and from their FHIR code strings, i.e. "entered-in-error" for
+// c4pb.ObservationStatusCode_ENTERED_IN_ERROR:
+//
+//	status, err := codes.ParseObservationStatusCode("final")
+//	...
+//	fmt.Println(codes.ObservationStatusCodeString(status))
+//
+// Every enum has generated Parse, String and IsValid helpers. Parse and Code
+// are their generic counterparts, and FromString works from the descriptor
+// of an enum, as for fields found by reflection. Codes are matched exactly,
+// as FHIR codes are case-sensitive, and honor the original code annotations
+// of codes that are not valid proto identifiers, such as "<=".
+package codes
+
+//go:generate go run ./internal/gen -out enums.go
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+)
+
+// ErrUnknownCode is returned for strings that are not a code of an enum.
+var ErrUnknownCode = errors.New("unknown code")
+
+// Enum is a generated code enum, such as c4pb.ObservationStatusCode_Value.
+type Enum interface {
+	~int32
+	protoreflect.Enum
+}
+
+// tables caches the codes of enums by their descriptor.
+var tables sync.Map // protoreflect.EnumDescriptor -> *table
+
+type table struct {
+	byCode   map[string]protoreflect.EnumNumber
+	byNumber map[protoreflect.EnumNumber]string
+}
+
+func lookup(ed protoreflect.EnumDescriptor) *table {
+	if t, ok := tables.Load(ed); ok {
+		return t.(*table)
+	}
+	t := &table{byCode: map[string]protoreflect.EnumNumber{}, byNumber: map[protoreflect.EnumNumber]string{}}
+	values := ed.Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		if v.Number() == 0 {
+			// INVALID_UNINITIALIZED is not a code.
+			continue
+		}
+		c := ValueCode(v)
+		t.byCode[c] = v.Number()
+		t.byNumber[v.Number()] = c
+	}
+	actual, _ := tables.LoadOrStore(ed, t)
+	return actual.(*table)
+}
+
+// ValueCode returns the FHIR code of the enum value v, as for enums found
+// by walking descriptors.
+func ValueCode(v protoreflect.EnumValueDescriptor) string {
+	if orig := proto.GetExtension(v.Options(), apb.E_FhirOriginalCode).(string); orig != "" {
+		return orig
+	}
+	return strings.ReplaceAll(strings.ToLower(string(v.Name())), "_", "-")
+}
+
+// FromString returns the number of the value of ed whose FHIR code is s.
+func FromString(ed protoreflect.EnumDescriptor, s string) (protoreflect.EnumNumber, error) {
+	n, ok := lookup(ed).byCode[s]
+	if !ok {
+		return 0, fmt.Errorf("%w %q for %s", ErrUnknownCode, s, ed.Parent().Name())
+	}
+	return n, nil
+}
+
+// Parse returns the value of E whose FHIR code is s.
+func Parse[E Enum](s string) (E, error) {
+	var zero E
+	n, err := FromString(zero.Descriptor(), s)
+	if err != nil {
+		return zero, err
+	}
+	return E(n), nil
+}
+
+// Code returns the FHIR code of e, or "" if e is INVALID_UNINITIALIZED or
+// not a value of its enum.
+func Code(e protoreflect.Enum) string {
+	return lookup(e.Descriptor()).byNumber[e.Number()]
+}
+
+// Valid reports whether e is a code of its enum, rather than
+// INVALID_UNINITIALIZED or a number the enum does not define.
+func Valid(e protoreflect.Enum) bool {
+	_, ok := lookup(e.Descriptor()).byNumber[e.Number()]
+	return ok
+}
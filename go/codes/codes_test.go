@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codes
+
+import (
+	"errors"
+	"testing"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+func TestParse(t *testing.T) {
+	if got, err := ParseObservationStatusCode("entered-in-error"); err != nil || got != c4pb.ObservationStatusCode_ENTERED_IN_ERROR {
+		t.Errorf("ParseObservationStatusCode(%q) = %v, %v, want %v", "entered-in-error", got, err, c4pb.ObservationStatusCode_ENTERED_IN_ERROR)
+	}
+	if got, err := ParseQuantityComparatorCode("<="); err != nil || got != c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO {
+		t.Errorf("ParseQuantityComparatorCode(%q) = %v, %v, want %v", "<=", got, err, c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO)
+	}
+	if got, err := Parse[vspb.UnitsOfTimeValueSet_Value]("wk"); err != nil || got != vspb.UnitsOfTimeValueSet_WK {
+		t.Errorf("Parse(%q) = %v, %v, want %v", "wk", got, err, vspb.UnitsOfTimeValueSet_WK)
+	}
+	for _, s := range []string{"", "FINAL", "invalid-uninitialized", "final "} {
+		if _, err := ParseObservationStatusCode(s); !errors.Is(err, ErrUnknownCode) {
+			t.Errorf("ParseObservationStatusCode(%q) returned error %v, want %v", s, err, ErrUnknownCode)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		v     c4pb.ObservationStatusCode_Value
+		want  string
+		valid bool
+	}{
+		{c4pb.ObservationStatusCode_FINAL, "final", true},
+		{c4pb.ObservationStatusCode_ENTERED_IN_ERROR, "entered-in-error", true},
+		{c4pb.ObservationStatusCode_INVALID_UNINITIALIZED, "", false},
+		{c4pb.ObservationStatusCode_Value(999), "", false},
+	}
+	for _, tc := range tests {
+		if got := ObservationStatusCodeString(tc.v); got != tc.want {
+			t.Errorf("ObservationStatusCodeString(%v) = %q, want %q", tc.v, got, tc.want)
+		}
+		if got := IsValidObservationStatusCode(tc.v); got != tc.valid {
+			t.Errorf("IsValidObservationStatusCode(%v) = %v, want %v", tc.v, got, tc.valid)
+		}
+	}
+	if got, want := Code(c4pb.QuantityComparatorCode_GREATER_THAN), ">"; got != want {
+		t.Errorf("Code(%v) = %q, want %q", c4pb.QuantityComparatorCode_GREATER_THAN, got, want)
+	}
+}
+
+func TestFromString(t *testing.T) {
+	ed := c4pb.AdministrativeGenderCode_FEMALE.Descriptor()
+	n, err := FromString(ed, "female")
+	if err != nil {
+		t.Fatalf("FromString() returned unexpected error: %v", err)
+	}
+	if n != c4pb.AdministrativeGenderCode_FEMALE.Number() {
+		t.Errorf("FromString(%q) = %v, want %v", "female", n, c4pb.AdministrativeGenderCode_FEMALE.Number())
+	}
+	if _, err := FromString(ed, "f"); err == nil {
+		t.Errorf("FromString(%q) succeeded, want error", "f")
+	}
+}
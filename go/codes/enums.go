@@ -0,0 +1,3280 @@
+// Code generated by codes/internal/gen. DO NOT EDIT.
+
+package codes
+
+import (
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+// ParseAbstractTypeCode returns the AbstractTypeCode of the FHIR code s, i.e. "Type".
+func ParseAbstractTypeCode(s string) (c4pb.AbstractTypeCode_Value, error) {
+	return Parse[c4pb.AbstractTypeCode_Value](s)
+}
+
+// AbstractTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AbstractTypeCodeString(v c4pb.AbstractTypeCode_Value) string { return Code(v) }
+
+// IsValidAbstractTypeCode reports whether v is a code of AbstractTypeCode.
+func IsValidAbstractTypeCode(v c4pb.AbstractTypeCode_Value) bool { return Valid(v) }
+
+// ParseAccountStatusCode returns the AccountStatusCode of the FHIR code s, i.e. "active".
+func ParseAccountStatusCode(s string) (c4pb.AccountStatusCode_Value, error) {
+	return Parse[c4pb.AccountStatusCode_Value](s)
+}
+
+// AccountStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func AccountStatusCodeString(v c4pb.AccountStatusCode_Value) string { return Code(v) }
+
+// IsValidAccountStatusCode reports whether v is a code of AccountStatusCode.
+func IsValidAccountStatusCode(v c4pb.AccountStatusCode_Value) bool { return Valid(v) }
+
+// ParseActionCardinalityBehaviorCode returns the ActionCardinalityBehaviorCode of the FHIR code s, i.e. "single".
+func ParseActionCardinalityBehaviorCode(s string) (c4pb.ActionCardinalityBehaviorCode_Value, error) {
+	return Parse[c4pb.ActionCardinalityBehaviorCode_Value](s)
+}
+
+// ActionCardinalityBehaviorCodeString returns the FHIR code of v, or "" if it is not valid.
+func ActionCardinalityBehaviorCodeString(v c4pb.ActionCardinalityBehaviorCode_Value) string {
+	return Code(v)
+}
+
+// IsValidActionCardinalityBehaviorCode reports whether v is a code of ActionCardinalityBehaviorCode.
+func IsValidActionCardinalityBehaviorCode(v c4pb.ActionCardinalityBehaviorCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseActionConditionKindCode returns the ActionConditionKindCode of the FHIR code s, i.e. "applicability".
+func ParseActionConditionKindCode(s string) (c4pb.ActionConditionKindCode_Value, error) {
+	return Parse[c4pb.ActionConditionKindCode_Value](s)
+}
+
+// ActionConditionKindCodeString returns the FHIR code of v, or "" if it is not valid.
+func ActionConditionKindCodeString(v c4pb.ActionConditionKindCode_Value) string { return Code(v) }
+
+// IsValidActionConditionKindCode reports whether v is a code of ActionConditionKindCode.
+func IsValidActionConditionKindCode(v c4pb.ActionConditionKindCode_Value) bool { return Valid(v) }
+
+// ParseActionGroupingBehaviorCode returns the ActionGroupingBehaviorCode of the FHIR code s, i.e. "visual-group".
+func ParseActionGroupingBehaviorCode(s string) (c4pb.ActionGroupingBehaviorCode_Value, error) {
+	return Parse[c4pb.ActionGroupingBehaviorCode_Value](s)
+}
+
+// ActionGroupingBehaviorCodeString returns the FHIR code of v, or "" if it is not valid.
+func ActionGroupingBehaviorCodeString(v c4pb.ActionGroupingBehaviorCode_Value) string { return Code(v) }
+
+// IsValidActionGroupingBehaviorCode reports whether v is a code of ActionGroupingBehaviorCode.
+func IsValidActionGroupingBehaviorCode(v c4pb.ActionGroupingBehaviorCode_Value) bool { return Valid(v) }
+
+// ParseActionParticipantTypeCode returns the ActionParticipantTypeCode of the FHIR code s, i.e. "patient".
+func ParseActionParticipantTypeCode(s string) (c4pb.ActionParticipantTypeCode_Value, error) {
+	return Parse[c4pb.ActionParticipantTypeCode_Value](s)
+}
+
+// ActionParticipantTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ActionParticipantTypeCodeString(v c4pb.ActionParticipantTypeCode_Value) string { return Code(v) }
+
+// IsValidActionParticipantTypeCode reports whether v is a code of ActionParticipantTypeCode.
+func IsValidActionParticipantTypeCode(v c4pb.ActionParticipantTypeCode_Value) bool { return Valid(v) }
+
+// ParseActionPrecheckBehaviorCode returns the ActionPrecheckBehaviorCode of the FHIR code s, i.e. "yes".
+func ParseActionPrecheckBehaviorCode(s string) (c4pb.ActionPrecheckBehaviorCode_Value, error) {
+	return Parse[c4pb.ActionPrecheckBehaviorCode_Value](s)
+}
+
+// ActionPrecheckBehaviorCodeString returns the FHIR code of v, or "" if it is not valid.
+func ActionPrecheckBehaviorCodeString(v c4pb.ActionPrecheckBehaviorCode_Value) string { return Code(v) }
+
+// IsValidActionPrecheckBehaviorCode reports whether v is a code of ActionPrecheckBehaviorCode.
+func IsValidActionPrecheckBehaviorCode(v c4pb.ActionPrecheckBehaviorCode_Value) bool { return Valid(v) }
+
+// ParseActionRelationshipTypeCode returns the ActionRelationshipTypeCode of the FHIR code s, i.e. "before-start".
+func ParseActionRelationshipTypeCode(s string) (c4pb.ActionRelationshipTypeCode_Value, error) {
+	return Parse[c4pb.ActionRelationshipTypeCode_Value](s)
+}
+
+// ActionRelationshipTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ActionRelationshipTypeCodeString(v c4pb.ActionRelationshipTypeCode_Value) string { return Code(v) }
+
+// IsValidActionRelationshipTypeCode reports whether v is a code of ActionRelationshipTypeCode.
+func IsValidActionRelationshipTypeCode(v c4pb.ActionRelationshipTypeCode_Value) bool { return Valid(v) }
+
+// ParseActionRequiredBehaviorCode returns the ActionRequiredBehaviorCode of the FHIR code s, i.e. "must".
+func ParseActionRequiredBehaviorCode(s string) (c4pb.ActionRequiredBehaviorCode_Value, error) {
+	return Parse[c4pb.ActionRequiredBehaviorCode_Value](s)
+}
+
+// ActionRequiredBehaviorCodeString returns the FHIR code of v, or "" if it is not valid.
+func ActionRequiredBehaviorCodeString(v c4pb.ActionRequiredBehaviorCode_Value) string { return Code(v) }
+
+// IsValidActionRequiredBehaviorCode reports whether v is a code of ActionRequiredBehaviorCode.
+func IsValidActionRequiredBehaviorCode(v c4pb.ActionRequiredBehaviorCode_Value) bool { return Valid(v) }
+
+// ParseActionSelectionBehaviorCode returns the ActionSelectionBehaviorCode of the FHIR code s, i.e. "any".
+func ParseActionSelectionBehaviorCode(s string) (c4pb.ActionSelectionBehaviorCode_Value, error) {
+	return Parse[c4pb.ActionSelectionBehaviorCode_Value](s)
+}
+
+// ActionSelectionBehaviorCodeString returns the FHIR code of v, or "" if it is not valid.
+func ActionSelectionBehaviorCodeString(v c4pb.ActionSelectionBehaviorCode_Value) string {
+	return Code(v)
+}
+
+// IsValidActionSelectionBehaviorCode reports whether v is a code of ActionSelectionBehaviorCode.
+func IsValidActionSelectionBehaviorCode(v c4pb.ActionSelectionBehaviorCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseAddressTypeCode returns the AddressTypeCode of the FHIR code s, i.e. "postal".
+func ParseAddressTypeCode(s string) (c4pb.AddressTypeCode_Value, error) {
+	return Parse[c4pb.AddressTypeCode_Value](s)
+}
+
+// AddressTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AddressTypeCodeString(v c4pb.AddressTypeCode_Value) string { return Code(v) }
+
+// IsValidAddressTypeCode reports whether v is a code of AddressTypeCode.
+func IsValidAddressTypeCode(v c4pb.AddressTypeCode_Value) bool { return Valid(v) }
+
+// ParseAddressUseCode returns the AddressUseCode of the FHIR code s, i.e. "home".
+func ParseAddressUseCode(s string) (c4pb.AddressUseCode_Value, error) {
+	return Parse[c4pb.AddressUseCode_Value](s)
+}
+
+// AddressUseCodeString returns the FHIR code of v, or "" if it is not valid.
+func AddressUseCodeString(v c4pb.AddressUseCode_Value) string { return Code(v) }
+
+// IsValidAddressUseCode reports whether v is a code of AddressUseCode.
+func IsValidAddressUseCode(v c4pb.AddressUseCode_Value) bool { return Valid(v) }
+
+// ParseAdministrativeGenderCode returns the AdministrativeGenderCode of the FHIR code s, i.e. "male".
+func ParseAdministrativeGenderCode(s string) (c4pb.AdministrativeGenderCode_Value, error) {
+	return Parse[c4pb.AdministrativeGenderCode_Value](s)
+}
+
+// AdministrativeGenderCodeString returns the FHIR code of v, or "" if it is not valid.
+func AdministrativeGenderCodeString(v c4pb.AdministrativeGenderCode_Value) string { return Code(v) }
+
+// IsValidAdministrativeGenderCode reports whether v is a code of AdministrativeGenderCode.
+func IsValidAdministrativeGenderCode(v c4pb.AdministrativeGenderCode_Value) bool { return Valid(v) }
+
+// ParseAdverseEventActualityCode returns the AdverseEventActualityCode of the FHIR code s, i.e. "actual".
+func ParseAdverseEventActualityCode(s string) (c4pb.AdverseEventActualityCode_Value, error) {
+	return Parse[c4pb.AdverseEventActualityCode_Value](s)
+}
+
+// AdverseEventActualityCodeString returns the FHIR code of v, or "" if it is not valid.
+func AdverseEventActualityCodeString(v c4pb.AdverseEventActualityCode_Value) string { return Code(v) }
+
+// IsValidAdverseEventActualityCode reports whether v is a code of AdverseEventActualityCode.
+func IsValidAdverseEventActualityCode(v c4pb.AdverseEventActualityCode_Value) bool { return Valid(v) }
+
+// ParseAdverseEventOutcomeCode returns the AdverseEventOutcomeCode of the FHIR code s, i.e. "resolved".
+func ParseAdverseEventOutcomeCode(s string) (c4pb.AdverseEventOutcomeCode_Value, error) {
+	return Parse[c4pb.AdverseEventOutcomeCode_Value](s)
+}
+
+// AdverseEventOutcomeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AdverseEventOutcomeCodeString(v c4pb.AdverseEventOutcomeCode_Value) string { return Code(v) }
+
+// IsValidAdverseEventOutcomeCode reports whether v is a code of AdverseEventOutcomeCode.
+func IsValidAdverseEventOutcomeCode(v c4pb.AdverseEventOutcomeCode_Value) bool { return Valid(v) }
+
+// ParseAdverseEventSeverityCode returns the AdverseEventSeverityCode of the FHIR code s, i.e. "mild".
+func ParseAdverseEventSeverityCode(s string) (c4pb.AdverseEventSeverityCode_Value, error) {
+	return Parse[c4pb.AdverseEventSeverityCode_Value](s)
+}
+
+// AdverseEventSeverityCodeString returns the FHIR code of v, or "" if it is not valid.
+func AdverseEventSeverityCodeString(v c4pb.AdverseEventSeverityCode_Value) string { return Code(v) }
+
+// IsValidAdverseEventSeverityCode reports whether v is a code of AdverseEventSeverityCode.
+func IsValidAdverseEventSeverityCode(v c4pb.AdverseEventSeverityCode_Value) bool { return Valid(v) }
+
+// ParseAggregationModeCode returns the AggregationModeCode of the FHIR code s, i.e. "contained".
+func ParseAggregationModeCode(s string) (c4pb.AggregationModeCode_Value, error) {
+	return Parse[c4pb.AggregationModeCode_Value](s)
+}
+
+// AggregationModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AggregationModeCodeString(v c4pb.AggregationModeCode_Value) string { return Code(v) }
+
+// IsValidAggregationModeCode reports whether v is a code of AggregationModeCode.
+func IsValidAggregationModeCode(v c4pb.AggregationModeCode_Value) bool { return Valid(v) }
+
+// ParseAllergyIntoleranceCategoryCode returns the AllergyIntoleranceCategoryCode of the FHIR code s, i.e. "food".
+func ParseAllergyIntoleranceCategoryCode(s string) (c4pb.AllergyIntoleranceCategoryCode_Value, error) {
+	return Parse[c4pb.AllergyIntoleranceCategoryCode_Value](s)
+}
+
+// AllergyIntoleranceCategoryCodeString returns the FHIR code of v, or "" if it is not valid.
+func AllergyIntoleranceCategoryCodeString(v c4pb.AllergyIntoleranceCategoryCode_Value) string {
+	return Code(v)
+}
+
+// IsValidAllergyIntoleranceCategoryCode reports whether v is a code of AllergyIntoleranceCategoryCode.
+func IsValidAllergyIntoleranceCategoryCode(v c4pb.AllergyIntoleranceCategoryCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseAllergyIntoleranceClinicalStatusCode returns the AllergyIntoleranceClinicalStatusCode of the FHIR code s, i.e. "active".
+func ParseAllergyIntoleranceClinicalStatusCode(s string) (c4pb.AllergyIntoleranceClinicalStatusCode_Value, error) {
+	return Parse[c4pb.AllergyIntoleranceClinicalStatusCode_Value](s)
+}
+
+// AllergyIntoleranceClinicalStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func AllergyIntoleranceClinicalStatusCodeString(v c4pb.AllergyIntoleranceClinicalStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidAllergyIntoleranceClinicalStatusCode reports whether v is a code of AllergyIntoleranceClinicalStatusCode.
+func IsValidAllergyIntoleranceClinicalStatusCode(v c4pb.AllergyIntoleranceClinicalStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseAllergyIntoleranceCriticalityCode returns the AllergyIntoleranceCriticalityCode of the FHIR code s, i.e. "low".
+func ParseAllergyIntoleranceCriticalityCode(s string) (c4pb.AllergyIntoleranceCriticalityCode_Value, error) {
+	return Parse[c4pb.AllergyIntoleranceCriticalityCode_Value](s)
+}
+
+// AllergyIntoleranceCriticalityCodeString returns the FHIR code of v, or "" if it is not valid.
+func AllergyIntoleranceCriticalityCodeString(v c4pb.AllergyIntoleranceCriticalityCode_Value) string {
+	return Code(v)
+}
+
+// IsValidAllergyIntoleranceCriticalityCode reports whether v is a code of AllergyIntoleranceCriticalityCode.
+func IsValidAllergyIntoleranceCriticalityCode(v c4pb.AllergyIntoleranceCriticalityCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseAllergyIntoleranceSeverityCode returns the AllergyIntoleranceSeverityCode of the FHIR code s, i.e. "mild".
+func ParseAllergyIntoleranceSeverityCode(s string) (c4pb.AllergyIntoleranceSeverityCode_Value, error) {
+	return Parse[c4pb.AllergyIntoleranceSeverityCode_Value](s)
+}
+
+// AllergyIntoleranceSeverityCodeString returns the FHIR code of v, or "" if it is not valid.
+func AllergyIntoleranceSeverityCodeString(v c4pb.AllergyIntoleranceSeverityCode_Value) string {
+	return Code(v)
+}
+
+// IsValidAllergyIntoleranceSeverityCode reports whether v is a code of AllergyIntoleranceSeverityCode.
+func IsValidAllergyIntoleranceSeverityCode(v c4pb.AllergyIntoleranceSeverityCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseAllergyIntoleranceSubstanceExposureRiskCode returns the AllergyIntoleranceSubstanceExposureRiskCode of the FHIR code s, i.e. "known-reaction-risk".
+func ParseAllergyIntoleranceSubstanceExposureRiskCode(s string) (c4pb.AllergyIntoleranceSubstanceExposureRiskCode_Value, error) {
+	return Parse[c4pb.AllergyIntoleranceSubstanceExposureRiskCode_Value](s)
+}
+
+// AllergyIntoleranceSubstanceExposureRiskCodeString returns the FHIR code of v, or "" if it is not valid.
+func AllergyIntoleranceSubstanceExposureRiskCodeString(v c4pb.AllergyIntoleranceSubstanceExposureRiskCode_Value) string {
+	return Code(v)
+}
+
+// IsValidAllergyIntoleranceSubstanceExposureRiskCode reports whether v is a code of AllergyIntoleranceSubstanceExposureRiskCode.
+func IsValidAllergyIntoleranceSubstanceExposureRiskCode(v c4pb.AllergyIntoleranceSubstanceExposureRiskCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseAllergyIntoleranceTypeCode returns the AllergyIntoleranceTypeCode of the FHIR code s, i.e. "allergy".
+func ParseAllergyIntoleranceTypeCode(s string) (c4pb.AllergyIntoleranceTypeCode_Value, error) {
+	return Parse[c4pb.AllergyIntoleranceTypeCode_Value](s)
+}
+
+// AllergyIntoleranceTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AllergyIntoleranceTypeCodeString(v c4pb.AllergyIntoleranceTypeCode_Value) string { return Code(v) }
+
+// IsValidAllergyIntoleranceTypeCode reports whether v is a code of AllergyIntoleranceTypeCode.
+func IsValidAllergyIntoleranceTypeCode(v c4pb.AllergyIntoleranceTypeCode_Value) bool { return Valid(v) }
+
+// ParseAllergyIntoleranceVerificationStatusCode returns the AllergyIntoleranceVerificationStatusCode of the FHIR code s, i.e. "unconfirmed".
+func ParseAllergyIntoleranceVerificationStatusCode(s string) (c4pb.AllergyIntoleranceVerificationStatusCode_Value, error) {
+	return Parse[c4pb.AllergyIntoleranceVerificationStatusCode_Value](s)
+}
+
+// AllergyIntoleranceVerificationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func AllergyIntoleranceVerificationStatusCodeString(v c4pb.AllergyIntoleranceVerificationStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidAllergyIntoleranceVerificationStatusCode reports whether v is a code of AllergyIntoleranceVerificationStatusCode.
+func IsValidAllergyIntoleranceVerificationStatusCode(v c4pb.AllergyIntoleranceVerificationStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseAppointmentStatusCode returns the AppointmentStatusCode of the FHIR code s, i.e. "proposed".
+func ParseAppointmentStatusCode(s string) (c4pb.AppointmentStatusCode_Value, error) {
+	return Parse[c4pb.AppointmentStatusCode_Value](s)
+}
+
+// AppointmentStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func AppointmentStatusCodeString(v c4pb.AppointmentStatusCode_Value) string { return Code(v) }
+
+// IsValidAppointmentStatusCode reports whether v is a code of AppointmentStatusCode.
+func IsValidAppointmentStatusCode(v c4pb.AppointmentStatusCode_Value) bool { return Valid(v) }
+
+// ParseAssertionDirectionTypeCode returns the AssertionDirectionTypeCode of the FHIR code s, i.e. "response".
+func ParseAssertionDirectionTypeCode(s string) (c4pb.AssertionDirectionTypeCode_Value, error) {
+	return Parse[c4pb.AssertionDirectionTypeCode_Value](s)
+}
+
+// AssertionDirectionTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AssertionDirectionTypeCodeString(v c4pb.AssertionDirectionTypeCode_Value) string { return Code(v) }
+
+// IsValidAssertionDirectionTypeCode reports whether v is a code of AssertionDirectionTypeCode.
+func IsValidAssertionDirectionTypeCode(v c4pb.AssertionDirectionTypeCode_Value) bool { return Valid(v) }
+
+// ParseAssertionOperatorTypeCode returns the AssertionOperatorTypeCode of the FHIR code s, i.e. "equals".
+func ParseAssertionOperatorTypeCode(s string) (c4pb.AssertionOperatorTypeCode_Value, error) {
+	return Parse[c4pb.AssertionOperatorTypeCode_Value](s)
+}
+
+// AssertionOperatorTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AssertionOperatorTypeCodeString(v c4pb.AssertionOperatorTypeCode_Value) string { return Code(v) }
+
+// IsValidAssertionOperatorTypeCode reports whether v is a code of AssertionOperatorTypeCode.
+func IsValidAssertionOperatorTypeCode(v c4pb.AssertionOperatorTypeCode_Value) bool { return Valid(v) }
+
+// ParseAssertionResponseTypesCode returns the AssertionResponseTypesCode of the FHIR code s, i.e. "okay".
+func ParseAssertionResponseTypesCode(s string) (c4pb.AssertionResponseTypesCode_Value, error) {
+	return Parse[c4pb.AssertionResponseTypesCode_Value](s)
+}
+
+// AssertionResponseTypesCodeString returns the FHIR code of v, or "" if it is not valid.
+func AssertionResponseTypesCodeString(v c4pb.AssertionResponseTypesCode_Value) string { return Code(v) }
+
+// IsValidAssertionResponseTypesCode reports whether v is a code of AssertionResponseTypesCode.
+func IsValidAssertionResponseTypesCode(v c4pb.AssertionResponseTypesCode_Value) bool { return Valid(v) }
+
+// ParseAuditEventActionCode returns the AuditEventActionCode of the FHIR code s, i.e. "C".
+func ParseAuditEventActionCode(s string) (c4pb.AuditEventActionCode_Value, error) {
+	return Parse[c4pb.AuditEventActionCode_Value](s)
+}
+
+// AuditEventActionCodeString returns the FHIR code of v, or "" if it is not valid.
+func AuditEventActionCodeString(v c4pb.AuditEventActionCode_Value) string { return Code(v) }
+
+// IsValidAuditEventActionCode reports whether v is a code of AuditEventActionCode.
+func IsValidAuditEventActionCode(v c4pb.AuditEventActionCode_Value) bool { return Valid(v) }
+
+// ParseAuditEventAgentNetworkTypeCode returns the AuditEventAgentNetworkTypeCode of the FHIR code s, i.e. "1".
+func ParseAuditEventAgentNetworkTypeCode(s string) (c4pb.AuditEventAgentNetworkTypeCode_Value, error) {
+	return Parse[c4pb.AuditEventAgentNetworkTypeCode_Value](s)
+}
+
+// AuditEventAgentNetworkTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AuditEventAgentNetworkTypeCodeString(v c4pb.AuditEventAgentNetworkTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidAuditEventAgentNetworkTypeCode reports whether v is a code of AuditEventAgentNetworkTypeCode.
+func IsValidAuditEventAgentNetworkTypeCode(v c4pb.AuditEventAgentNetworkTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseAuditEventOutcomeCode returns the AuditEventOutcomeCode of the FHIR code s, i.e. "0".
+func ParseAuditEventOutcomeCode(s string) (c4pb.AuditEventOutcomeCode_Value, error) {
+	return Parse[c4pb.AuditEventOutcomeCode_Value](s)
+}
+
+// AuditEventOutcomeCodeString returns the FHIR code of v, or "" if it is not valid.
+func AuditEventOutcomeCodeString(v c4pb.AuditEventOutcomeCode_Value) string { return Code(v) }
+
+// IsValidAuditEventOutcomeCode reports whether v is a code of AuditEventOutcomeCode.
+func IsValidAuditEventOutcomeCode(v c4pb.AuditEventOutcomeCode_Value) bool { return Valid(v) }
+
+// ParseBenefitCostApplicabilityCode returns the BenefitCostApplicabilityCode of the FHIR code s, i.e. "in-network".
+func ParseBenefitCostApplicabilityCode(s string) (c4pb.BenefitCostApplicabilityCode_Value, error) {
+	return Parse[c4pb.BenefitCostApplicabilityCode_Value](s)
+}
+
+// BenefitCostApplicabilityCodeString returns the FHIR code of v, or "" if it is not valid.
+func BenefitCostApplicabilityCodeString(v c4pb.BenefitCostApplicabilityCode_Value) string {
+	return Code(v)
+}
+
+// IsValidBenefitCostApplicabilityCode reports whether v is a code of BenefitCostApplicabilityCode.
+func IsValidBenefitCostApplicabilityCode(v c4pb.BenefitCostApplicabilityCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseBindingStrengthCode returns the BindingStrengthCode of the FHIR code s, i.e. "required".
+func ParseBindingStrengthCode(s string) (c4pb.BindingStrengthCode_Value, error) {
+	return Parse[c4pb.BindingStrengthCode_Value](s)
+}
+
+// BindingStrengthCodeString returns the FHIR code of v, or "" if it is not valid.
+func BindingStrengthCodeString(v c4pb.BindingStrengthCode_Value) string { return Code(v) }
+
+// IsValidBindingStrengthCode reports whether v is a code of BindingStrengthCode.
+func IsValidBindingStrengthCode(v c4pb.BindingStrengthCode_Value) bool { return Valid(v) }
+
+// ParseBiologicallyDerivedProductCategoryCode returns the BiologicallyDerivedProductCategoryCode of the FHIR code s, i.e. "organ".
+func ParseBiologicallyDerivedProductCategoryCode(s string) (c4pb.BiologicallyDerivedProductCategoryCode_Value, error) {
+	return Parse[c4pb.BiologicallyDerivedProductCategoryCode_Value](s)
+}
+
+// BiologicallyDerivedProductCategoryCodeString returns the FHIR code of v, or "" if it is not valid.
+func BiologicallyDerivedProductCategoryCodeString(v c4pb.BiologicallyDerivedProductCategoryCode_Value) string {
+	return Code(v)
+}
+
+// IsValidBiologicallyDerivedProductCategoryCode reports whether v is a code of BiologicallyDerivedProductCategoryCode.
+func IsValidBiologicallyDerivedProductCategoryCode(v c4pb.BiologicallyDerivedProductCategoryCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseBiologicallyDerivedProductStatusCode returns the BiologicallyDerivedProductStatusCode of the FHIR code s, i.e. "available".
+func ParseBiologicallyDerivedProductStatusCode(s string) (c4pb.BiologicallyDerivedProductStatusCode_Value, error) {
+	return Parse[c4pb.BiologicallyDerivedProductStatusCode_Value](s)
+}
+
+// BiologicallyDerivedProductStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func BiologicallyDerivedProductStatusCodeString(v c4pb.BiologicallyDerivedProductStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidBiologicallyDerivedProductStatusCode reports whether v is a code of BiologicallyDerivedProductStatusCode.
+func IsValidBiologicallyDerivedProductStatusCode(v c4pb.BiologicallyDerivedProductStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseBiologicallyDerivedProductStorageScaleCode returns the BiologicallyDerivedProductStorageScaleCode of the FHIR code s, i.e. "farenheit".
+func ParseBiologicallyDerivedProductStorageScaleCode(s string) (c4pb.BiologicallyDerivedProductStorageScaleCode_Value, error) {
+	return Parse[c4pb.BiologicallyDerivedProductStorageScaleCode_Value](s)
+}
+
+// BiologicallyDerivedProductStorageScaleCodeString returns the FHIR code of v, or "" if it is not valid.
+func BiologicallyDerivedProductStorageScaleCodeString(v c4pb.BiologicallyDerivedProductStorageScaleCode_Value) string {
+	return Code(v)
+}
+
+// IsValidBiologicallyDerivedProductStorageScaleCode reports whether v is a code of BiologicallyDerivedProductStorageScaleCode.
+func IsValidBiologicallyDerivedProductStorageScaleCode(v c4pb.BiologicallyDerivedProductStorageScaleCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseBundleTypeCode returns the BundleTypeCode of the FHIR code s, i.e. "document".
+func ParseBundleTypeCode(s string) (c4pb.BundleTypeCode_Value, error) {
+	return Parse[c4pb.BundleTypeCode_Value](s)
+}
+
+// BundleTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func BundleTypeCodeString(v c4pb.BundleTypeCode_Value) string { return Code(v) }
+
+// IsValidBundleTypeCode reports whether v is a code of BundleTypeCode.
+func IsValidBundleTypeCode(v c4pb.BundleTypeCode_Value) bool { return Valid(v) }
+
+// ParseCanonicalStatusCodesForFHIRResourcesCode returns the CanonicalStatusCodesForFHIRResourcesCode of the FHIR code s, i.e. "error".
+func ParseCanonicalStatusCodesForFHIRResourcesCode(s string) (c4pb.CanonicalStatusCodesForFHIRResourcesCode_Value, error) {
+	return Parse[c4pb.CanonicalStatusCodesForFHIRResourcesCode_Value](s)
+}
+
+// CanonicalStatusCodesForFHIRResourcesCodeString returns the FHIR code of v, or "" if it is not valid.
+func CanonicalStatusCodesForFHIRResourcesCodeString(v c4pb.CanonicalStatusCodesForFHIRResourcesCode_Value) string {
+	return Code(v)
+}
+
+// IsValidCanonicalStatusCodesForFHIRResourcesCode reports whether v is a code of CanonicalStatusCodesForFHIRResourcesCode.
+func IsValidCanonicalStatusCodesForFHIRResourcesCode(v c4pb.CanonicalStatusCodesForFHIRResourcesCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseCapabilityStatementKindCode returns the CapabilityStatementKindCode of the FHIR code s, i.e. "instance".
+func ParseCapabilityStatementKindCode(s string) (c4pb.CapabilityStatementKindCode_Value, error) {
+	return Parse[c4pb.CapabilityStatementKindCode_Value](s)
+}
+
+// CapabilityStatementKindCodeString returns the FHIR code of v, or "" if it is not valid.
+func CapabilityStatementKindCodeString(v c4pb.CapabilityStatementKindCode_Value) string {
+	return Code(v)
+}
+
+// IsValidCapabilityStatementKindCode reports whether v is a code of CapabilityStatementKindCode.
+func IsValidCapabilityStatementKindCode(v c4pb.CapabilityStatementKindCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseCarePlanActivityStatusCode returns the CarePlanActivityStatusCode of the FHIR code s, i.e. "not-started".
+func ParseCarePlanActivityStatusCode(s string) (c4pb.CarePlanActivityStatusCode_Value, error) {
+	return Parse[c4pb.CarePlanActivityStatusCode_Value](s)
+}
+
+// CarePlanActivityStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func CarePlanActivityStatusCodeString(v c4pb.CarePlanActivityStatusCode_Value) string { return Code(v) }
+
+// IsValidCarePlanActivityStatusCode reports whether v is a code of CarePlanActivityStatusCode.
+func IsValidCarePlanActivityStatusCode(v c4pb.CarePlanActivityStatusCode_Value) bool { return Valid(v) }
+
+// ParseCareTeamStatusCode returns the CareTeamStatusCode of the FHIR code s, i.e. "proposed".
+func ParseCareTeamStatusCode(s string) (c4pb.CareTeamStatusCode_Value, error) {
+	return Parse[c4pb.CareTeamStatusCode_Value](s)
+}
+
+// CareTeamStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func CareTeamStatusCodeString(v c4pb.CareTeamStatusCode_Value) string { return Code(v) }
+
+// IsValidCareTeamStatusCode reports whether v is a code of CareTeamStatusCode.
+func IsValidCareTeamStatusCode(v c4pb.CareTeamStatusCode_Value) bool { return Valid(v) }
+
+// ParseCatalogEntryRelationTypeCode returns the CatalogEntryRelationTypeCode of the FHIR code s, i.e. "triggers".
+func ParseCatalogEntryRelationTypeCode(s string) (c4pb.CatalogEntryRelationTypeCode_Value, error) {
+	return Parse[c4pb.CatalogEntryRelationTypeCode_Value](s)
+}
+
+// CatalogEntryRelationTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func CatalogEntryRelationTypeCodeString(v c4pb.CatalogEntryRelationTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidCatalogEntryRelationTypeCode reports whether v is a code of CatalogEntryRelationTypeCode.
+func IsValidCatalogEntryRelationTypeCode(v c4pb.CatalogEntryRelationTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseChargeItemStatusCode returns the ChargeItemStatusCode of the FHIR code s, i.e. "planned".
+func ParseChargeItemStatusCode(s string) (c4pb.ChargeItemStatusCode_Value, error) {
+	return Parse[c4pb.ChargeItemStatusCode_Value](s)
+}
+
+// ChargeItemStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ChargeItemStatusCodeString(v c4pb.ChargeItemStatusCode_Value) string { return Code(v) }
+
+// IsValidChargeItemStatusCode reports whether v is a code of ChargeItemStatusCode.
+func IsValidChargeItemStatusCode(v c4pb.ChargeItemStatusCode_Value) bool { return Valid(v) }
+
+// ParseChoiceListOrientationCode returns the ChoiceListOrientationCode of the FHIR code s, i.e. "horizontal".
+func ParseChoiceListOrientationCode(s string) (c4pb.ChoiceListOrientationCode_Value, error) {
+	return Parse[c4pb.ChoiceListOrientationCode_Value](s)
+}
+
+// ChoiceListOrientationCodeString returns the FHIR code of v, or "" if it is not valid.
+func ChoiceListOrientationCodeString(v c4pb.ChoiceListOrientationCode_Value) string { return Code(v) }
+
+// IsValidChoiceListOrientationCode reports whether v is a code of ChoiceListOrientationCode.
+func IsValidChoiceListOrientationCode(v c4pb.ChoiceListOrientationCode_Value) bool { return Valid(v) }
+
+// ParseClaimProcessingCode returns the ClaimProcessingCode of the FHIR code s, i.e. "queued".
+func ParseClaimProcessingCode(s string) (c4pb.ClaimProcessingCode_Value, error) {
+	return Parse[c4pb.ClaimProcessingCode_Value](s)
+}
+
+// ClaimProcessingCodeString returns the FHIR code of v, or "" if it is not valid.
+func ClaimProcessingCodeString(v c4pb.ClaimProcessingCode_Value) string { return Code(v) }
+
+// IsValidClaimProcessingCode reports whether v is a code of ClaimProcessingCode.
+func IsValidClaimProcessingCode(v c4pb.ClaimProcessingCode_Value) bool { return Valid(v) }
+
+// ParseCodeSearchSupportCode returns the CodeSearchSupportCode of the FHIR code s, i.e. "explicit".
+func ParseCodeSearchSupportCode(s string) (c4pb.CodeSearchSupportCode_Value, error) {
+	return Parse[c4pb.CodeSearchSupportCode_Value](s)
+}
+
+// CodeSearchSupportCodeString returns the FHIR code of v, or "" if it is not valid.
+func CodeSearchSupportCodeString(v c4pb.CodeSearchSupportCode_Value) string { return Code(v) }
+
+// IsValidCodeSearchSupportCode reports whether v is a code of CodeSearchSupportCode.
+func IsValidCodeSearchSupportCode(v c4pb.CodeSearchSupportCode_Value) bool { return Valid(v) }
+
+// ParseCodeSystemContentModeCode returns the CodeSystemContentModeCode of the FHIR code s, i.e. "not-present".
+func ParseCodeSystemContentModeCode(s string) (c4pb.CodeSystemContentModeCode_Value, error) {
+	return Parse[c4pb.CodeSystemContentModeCode_Value](s)
+}
+
+// CodeSystemContentModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func CodeSystemContentModeCodeString(v c4pb.CodeSystemContentModeCode_Value) string { return Code(v) }
+
+// IsValidCodeSystemContentModeCode reports whether v is a code of CodeSystemContentModeCode.
+func IsValidCodeSystemContentModeCode(v c4pb.CodeSystemContentModeCode_Value) bool { return Valid(v) }
+
+// ParseCodeSystemHierarchyMeaningCode returns the CodeSystemHierarchyMeaningCode of the FHIR code s, i.e. "grouped-by".
+func ParseCodeSystemHierarchyMeaningCode(s string) (c4pb.CodeSystemHierarchyMeaningCode_Value, error) {
+	return Parse[c4pb.CodeSystemHierarchyMeaningCode_Value](s)
+}
+
+// CodeSystemHierarchyMeaningCodeString returns the FHIR code of v, or "" if it is not valid.
+func CodeSystemHierarchyMeaningCodeString(v c4pb.CodeSystemHierarchyMeaningCode_Value) string {
+	return Code(v)
+}
+
+// IsValidCodeSystemHierarchyMeaningCode reports whether v is a code of CodeSystemHierarchyMeaningCode.
+func IsValidCodeSystemHierarchyMeaningCode(v c4pb.CodeSystemHierarchyMeaningCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseCompartmentTypeCode returns the CompartmentTypeCode of the FHIR code s, i.e. "Patient".
+func ParseCompartmentTypeCode(s string) (c4pb.CompartmentTypeCode_Value, error) {
+	return Parse[c4pb.CompartmentTypeCode_Value](s)
+}
+
+// CompartmentTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func CompartmentTypeCodeString(v c4pb.CompartmentTypeCode_Value) string { return Code(v) }
+
+// IsValidCompartmentTypeCode reports whether v is a code of CompartmentTypeCode.
+func IsValidCompartmentTypeCode(v c4pb.CompartmentTypeCode_Value) bool { return Valid(v) }
+
+// ParseCompositionAttestationModeCode returns the CompositionAttestationModeCode of the FHIR code s, i.e. "personal".
+func ParseCompositionAttestationModeCode(s string) (c4pb.CompositionAttestationModeCode_Value, error) {
+	return Parse[c4pb.CompositionAttestationModeCode_Value](s)
+}
+
+// CompositionAttestationModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func CompositionAttestationModeCodeString(v c4pb.CompositionAttestationModeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidCompositionAttestationModeCode reports whether v is a code of CompositionAttestationModeCode.
+func IsValidCompositionAttestationModeCode(v c4pb.CompositionAttestationModeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseCompositionStatusCode returns the CompositionStatusCode of the FHIR code s, i.e. "preliminary".
+func ParseCompositionStatusCode(s string) (c4pb.CompositionStatusCode_Value, error) {
+	return Parse[c4pb.CompositionStatusCode_Value](s)
+}
+
+// CompositionStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func CompositionStatusCodeString(v c4pb.CompositionStatusCode_Value) string { return Code(v) }
+
+// IsValidCompositionStatusCode reports whether v is a code of CompositionStatusCode.
+func IsValidCompositionStatusCode(v c4pb.CompositionStatusCode_Value) bool { return Valid(v) }
+
+// ParseConceptMapEquivalenceCode returns the ConceptMapEquivalenceCode of the FHIR code s, i.e. "relatedto".
+func ParseConceptMapEquivalenceCode(s string) (c4pb.ConceptMapEquivalenceCode_Value, error) {
+	return Parse[c4pb.ConceptMapEquivalenceCode_Value](s)
+}
+
+// ConceptMapEquivalenceCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConceptMapEquivalenceCodeString(v c4pb.ConceptMapEquivalenceCode_Value) string { return Code(v) }
+
+// IsValidConceptMapEquivalenceCode reports whether v is a code of ConceptMapEquivalenceCode.
+func IsValidConceptMapEquivalenceCode(v c4pb.ConceptMapEquivalenceCode_Value) bool { return Valid(v) }
+
+// ParseConceptMapGroupUnmappedModeCode returns the ConceptMapGroupUnmappedModeCode of the FHIR code s, i.e. "provided".
+func ParseConceptMapGroupUnmappedModeCode(s string) (c4pb.ConceptMapGroupUnmappedModeCode_Value, error) {
+	return Parse[c4pb.ConceptMapGroupUnmappedModeCode_Value](s)
+}
+
+// ConceptMapGroupUnmappedModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConceptMapGroupUnmappedModeCodeString(v c4pb.ConceptMapGroupUnmappedModeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidConceptMapGroupUnmappedModeCode reports whether v is a code of ConceptMapGroupUnmappedModeCode.
+func IsValidConceptMapGroupUnmappedModeCode(v c4pb.ConceptMapGroupUnmappedModeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseConditionClinicalStatusCode returns the ConditionClinicalStatusCode of the FHIR code s, i.e. "active".
+func ParseConditionClinicalStatusCode(s string) (c4pb.ConditionClinicalStatusCode_Value, error) {
+	return Parse[c4pb.ConditionClinicalStatusCode_Value](s)
+}
+
+// ConditionClinicalStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConditionClinicalStatusCodeString(v c4pb.ConditionClinicalStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidConditionClinicalStatusCode reports whether v is a code of ConditionClinicalStatusCode.
+func IsValidConditionClinicalStatusCode(v c4pb.ConditionClinicalStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseConditionVerificationStatusCode returns the ConditionVerificationStatusCode of the FHIR code s, i.e. "unconfirmed".
+func ParseConditionVerificationStatusCode(s string) (c4pb.ConditionVerificationStatusCode_Value, error) {
+	return Parse[c4pb.ConditionVerificationStatusCode_Value](s)
+}
+
+// ConditionVerificationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConditionVerificationStatusCodeString(v c4pb.ConditionVerificationStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidConditionVerificationStatusCode reports whether v is a code of ConditionVerificationStatusCode.
+func IsValidConditionVerificationStatusCode(v c4pb.ConditionVerificationStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseConditionalDeleteStatusCode returns the ConditionalDeleteStatusCode of the FHIR code s, i.e. "not-supported".
+func ParseConditionalDeleteStatusCode(s string) (c4pb.ConditionalDeleteStatusCode_Value, error) {
+	return Parse[c4pb.ConditionalDeleteStatusCode_Value](s)
+}
+
+// ConditionalDeleteStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConditionalDeleteStatusCodeString(v c4pb.ConditionalDeleteStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidConditionalDeleteStatusCode reports whether v is a code of ConditionalDeleteStatusCode.
+func IsValidConditionalDeleteStatusCode(v c4pb.ConditionalDeleteStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseConditionalReadStatusCode returns the ConditionalReadStatusCode of the FHIR code s, i.e. "not-supported".
+func ParseConditionalReadStatusCode(s string) (c4pb.ConditionalReadStatusCode_Value, error) {
+	return Parse[c4pb.ConditionalReadStatusCode_Value](s)
+}
+
+// ConditionalReadStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConditionalReadStatusCodeString(v c4pb.ConditionalReadStatusCode_Value) string { return Code(v) }
+
+// IsValidConditionalReadStatusCode reports whether v is a code of ConditionalReadStatusCode.
+func IsValidConditionalReadStatusCode(v c4pb.ConditionalReadStatusCode_Value) bool { return Valid(v) }
+
+// ParseConformanceExpectationCode returns the ConformanceExpectationCode of the FHIR code s, i.e. "SHALL".
+func ParseConformanceExpectationCode(s string) (c4pb.ConformanceExpectationCode_Value, error) {
+	return Parse[c4pb.ConformanceExpectationCode_Value](s)
+}
+
+// ConformanceExpectationCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConformanceExpectationCodeString(v c4pb.ConformanceExpectationCode_Value) string { return Code(v) }
+
+// IsValidConformanceExpectationCode reports whether v is a code of ConformanceExpectationCode.
+func IsValidConformanceExpectationCode(v c4pb.ConformanceExpectationCode_Value) bool { return Valid(v) }
+
+// ParseConsentDataMeaningCode returns the ConsentDataMeaningCode of the FHIR code s, i.e. "instance".
+func ParseConsentDataMeaningCode(s string) (c4pb.ConsentDataMeaningCode_Value, error) {
+	return Parse[c4pb.ConsentDataMeaningCode_Value](s)
+}
+
+// ConsentDataMeaningCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConsentDataMeaningCodeString(v c4pb.ConsentDataMeaningCode_Value) string { return Code(v) }
+
+// IsValidConsentDataMeaningCode reports whether v is a code of ConsentDataMeaningCode.
+func IsValidConsentDataMeaningCode(v c4pb.ConsentDataMeaningCode_Value) bool { return Valid(v) }
+
+// ParseConsentProvisionTypeCode returns the ConsentProvisionTypeCode of the FHIR code s, i.e. "deny".
+func ParseConsentProvisionTypeCode(s string) (c4pb.ConsentProvisionTypeCode_Value, error) {
+	return Parse[c4pb.ConsentProvisionTypeCode_Value](s)
+}
+
+// ConsentProvisionTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConsentProvisionTypeCodeString(v c4pb.ConsentProvisionTypeCode_Value) string { return Code(v) }
+
+// IsValidConsentProvisionTypeCode reports whether v is a code of ConsentProvisionTypeCode.
+func IsValidConsentProvisionTypeCode(v c4pb.ConsentProvisionTypeCode_Value) bool { return Valid(v) }
+
+// ParseConsentStateCode returns the ConsentStateCode of the FHIR code s, i.e. "draft".
+func ParseConsentStateCode(s string) (c4pb.ConsentStateCode_Value, error) {
+	return Parse[c4pb.ConsentStateCode_Value](s)
+}
+
+// ConsentStateCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConsentStateCodeString(v c4pb.ConsentStateCode_Value) string { return Code(v) }
+
+// IsValidConsentStateCode reports whether v is a code of ConsentStateCode.
+func IsValidConsentStateCode(v c4pb.ConsentStateCode_Value) bool { return Valid(v) }
+
+// ParseConstraintSeverityCode returns the ConstraintSeverityCode of the FHIR code s, i.e. "error".
+func ParseConstraintSeverityCode(s string) (c4pb.ConstraintSeverityCode_Value, error) {
+	return Parse[c4pb.ConstraintSeverityCode_Value](s)
+}
+
+// ConstraintSeverityCodeString returns the FHIR code of v, or "" if it is not valid.
+func ConstraintSeverityCodeString(v c4pb.ConstraintSeverityCode_Value) string { return Code(v) }
+
+// IsValidConstraintSeverityCode reports whether v is a code of ConstraintSeverityCode.
+func IsValidConstraintSeverityCode(v c4pb.ConstraintSeverityCode_Value) bool { return Valid(v) }
+
+// ParseContactPointSystemCode returns the ContactPointSystemCode of the FHIR code s, i.e. "phone".
+func ParseContactPointSystemCode(s string) (c4pb.ContactPointSystemCode_Value, error) {
+	return Parse[c4pb.ContactPointSystemCode_Value](s)
+}
+
+// ContactPointSystemCodeString returns the FHIR code of v, or "" if it is not valid.
+func ContactPointSystemCodeString(v c4pb.ContactPointSystemCode_Value) string { return Code(v) }
+
+// IsValidContactPointSystemCode reports whether v is a code of ContactPointSystemCode.
+func IsValidContactPointSystemCode(v c4pb.ContactPointSystemCode_Value) bool { return Valid(v) }
+
+// ParseContactPointUseCode returns the ContactPointUseCode of the FHIR code s, i.e. "home".
+func ParseContactPointUseCode(s string) (c4pb.ContactPointUseCode_Value, error) {
+	return Parse[c4pb.ContactPointUseCode_Value](s)
+}
+
+// ContactPointUseCodeString returns the FHIR code of v, or "" if it is not valid.
+func ContactPointUseCodeString(v c4pb.ContactPointUseCode_Value) string { return Code(v) }
+
+// IsValidContactPointUseCode reports whether v is a code of ContactPointUseCode.
+func IsValidContactPointUseCode(v c4pb.ContactPointUseCode_Value) bool { return Valid(v) }
+
+// ParseContractResourcePublicationStatusCode returns the ContractResourcePublicationStatusCode of the FHIR code s, i.e. "amended".
+func ParseContractResourcePublicationStatusCode(s string) (c4pb.ContractResourcePublicationStatusCode_Value, error) {
+	return Parse[c4pb.ContractResourcePublicationStatusCode_Value](s)
+}
+
+// ContractResourcePublicationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ContractResourcePublicationStatusCodeString(v c4pb.ContractResourcePublicationStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidContractResourcePublicationStatusCode reports whether v is a code of ContractResourcePublicationStatusCode.
+func IsValidContractResourcePublicationStatusCode(v c4pb.ContractResourcePublicationStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseContractResourceStatusCode returns the ContractResourceStatusCode of the FHIR code s, i.e. "amended".
+func ParseContractResourceStatusCode(s string) (c4pb.ContractResourceStatusCode_Value, error) {
+	return Parse[c4pb.ContractResourceStatusCode_Value](s)
+}
+
+// ContractResourceStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ContractResourceStatusCodeString(v c4pb.ContractResourceStatusCode_Value) string { return Code(v) }
+
+// IsValidContractResourceStatusCode reports whether v is a code of ContractResourceStatusCode.
+func IsValidContractResourceStatusCode(v c4pb.ContractResourceStatusCode_Value) bool { return Valid(v) }
+
+// ParseContributorTypeCode returns the ContributorTypeCode of the FHIR code s, i.e. "author".
+func ParseContributorTypeCode(s string) (c4pb.ContributorTypeCode_Value, error) {
+	return Parse[c4pb.ContributorTypeCode_Value](s)
+}
+
+// ContributorTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ContributorTypeCodeString(v c4pb.ContributorTypeCode_Value) string { return Code(v) }
+
+// IsValidContributorTypeCode reports whether v is a code of ContributorTypeCode.
+func IsValidContributorTypeCode(v c4pb.ContributorTypeCode_Value) bool { return Valid(v) }
+
+// ParseDataAbsentReasonCode returns the DataAbsentReasonCode of the FHIR code s, i.e. "unknown".
+func ParseDataAbsentReasonCode(s string) (c4pb.DataAbsentReasonCode_Value, error) {
+	return Parse[c4pb.DataAbsentReasonCode_Value](s)
+}
+
+// DataAbsentReasonCodeString returns the FHIR code of v, or "" if it is not valid.
+func DataAbsentReasonCodeString(v c4pb.DataAbsentReasonCode_Value) string { return Code(v) }
+
+// IsValidDataAbsentReasonCode reports whether v is a code of DataAbsentReasonCode.
+func IsValidDataAbsentReasonCode(v c4pb.DataAbsentReasonCode_Value) bool { return Valid(v) }
+
+// ParseDataTypeCode returns the DataTypeCode of the FHIR code s, i.e. "Address".
+func ParseDataTypeCode(s string) (c4pb.DataTypeCode_Value, error) {
+	return Parse[c4pb.DataTypeCode_Value](s)
+}
+
+// DataTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func DataTypeCodeString(v c4pb.DataTypeCode_Value) string { return Code(v) }
+
+// IsValidDataTypeCode reports whether v is a code of DataTypeCode.
+func IsValidDataTypeCode(v c4pb.DataTypeCode_Value) bool { return Valid(v) }
+
+// ParseDaysOfWeekCode returns the DaysOfWeekCode of the FHIR code s, i.e. "mon".
+func ParseDaysOfWeekCode(s string) (c4pb.DaysOfWeekCode_Value, error) {
+	return Parse[c4pb.DaysOfWeekCode_Value](s)
+}
+
+// DaysOfWeekCodeString returns the FHIR code of v, or "" if it is not valid.
+func DaysOfWeekCodeString(v c4pb.DaysOfWeekCode_Value) string { return Code(v) }
+
+// IsValidDaysOfWeekCode reports whether v is a code of DaysOfWeekCode.
+func IsValidDaysOfWeekCode(v c4pb.DaysOfWeekCode_Value) bool { return Valid(v) }
+
+// ParseDetectedIssueSeverityCode returns the DetectedIssueSeverityCode of the FHIR code s, i.e. "high".
+func ParseDetectedIssueSeverityCode(s string) (c4pb.DetectedIssueSeverityCode_Value, error) {
+	return Parse[c4pb.DetectedIssueSeverityCode_Value](s)
+}
+
+// DetectedIssueSeverityCodeString returns the FHIR code of v, or "" if it is not valid.
+func DetectedIssueSeverityCodeString(v c4pb.DetectedIssueSeverityCode_Value) string { return Code(v) }
+
+// IsValidDetectedIssueSeverityCode reports whether v is a code of DetectedIssueSeverityCode.
+func IsValidDetectedIssueSeverityCode(v c4pb.DetectedIssueSeverityCode_Value) bool { return Valid(v) }
+
+// ParseDeviceMetricCalibrationStateCode returns the DeviceMetricCalibrationStateCode of the FHIR code s, i.e. "not-calibrated".
+func ParseDeviceMetricCalibrationStateCode(s string) (c4pb.DeviceMetricCalibrationStateCode_Value, error) {
+	return Parse[c4pb.DeviceMetricCalibrationStateCode_Value](s)
+}
+
+// DeviceMetricCalibrationStateCodeString returns the FHIR code of v, or "" if it is not valid.
+func DeviceMetricCalibrationStateCodeString(v c4pb.DeviceMetricCalibrationStateCode_Value) string {
+	return Code(v)
+}
+
+// IsValidDeviceMetricCalibrationStateCode reports whether v is a code of DeviceMetricCalibrationStateCode.
+func IsValidDeviceMetricCalibrationStateCode(v c4pb.DeviceMetricCalibrationStateCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseDeviceMetricCalibrationTypeCode returns the DeviceMetricCalibrationTypeCode of the FHIR code s, i.e. "unspecified".
+func ParseDeviceMetricCalibrationTypeCode(s string) (c4pb.DeviceMetricCalibrationTypeCode_Value, error) {
+	return Parse[c4pb.DeviceMetricCalibrationTypeCode_Value](s)
+}
+
+// DeviceMetricCalibrationTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func DeviceMetricCalibrationTypeCodeString(v c4pb.DeviceMetricCalibrationTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidDeviceMetricCalibrationTypeCode reports whether v is a code of DeviceMetricCalibrationTypeCode.
+func IsValidDeviceMetricCalibrationTypeCode(v c4pb.DeviceMetricCalibrationTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseDeviceMetricCategoryCode returns the DeviceMetricCategoryCode of the FHIR code s, i.e. "measurement".
+func ParseDeviceMetricCategoryCode(s string) (c4pb.DeviceMetricCategoryCode_Value, error) {
+	return Parse[c4pb.DeviceMetricCategoryCode_Value](s)
+}
+
+// DeviceMetricCategoryCodeString returns the FHIR code of v, or "" if it is not valid.
+func DeviceMetricCategoryCodeString(v c4pb.DeviceMetricCategoryCode_Value) string { return Code(v) }
+
+// IsValidDeviceMetricCategoryCode reports whether v is a code of DeviceMetricCategoryCode.
+func IsValidDeviceMetricCategoryCode(v c4pb.DeviceMetricCategoryCode_Value) bool { return Valid(v) }
+
+// ParseDeviceMetricColorCode returns the DeviceMetricColorCode of the FHIR code s, i.e. "black".
+func ParseDeviceMetricColorCode(s string) (c4pb.DeviceMetricColorCode_Value, error) {
+	return Parse[c4pb.DeviceMetricColorCode_Value](s)
+}
+
+// DeviceMetricColorCodeString returns the FHIR code of v, or "" if it is not valid.
+func DeviceMetricColorCodeString(v c4pb.DeviceMetricColorCode_Value) string { return Code(v) }
+
+// IsValidDeviceMetricColorCode reports whether v is a code of DeviceMetricColorCode.
+func IsValidDeviceMetricColorCode(v c4pb.DeviceMetricColorCode_Value) bool { return Valid(v) }
+
+// ParseDeviceMetricOperationalStatusCode returns the DeviceMetricOperationalStatusCode of the FHIR code s, i.e. "on".
+func ParseDeviceMetricOperationalStatusCode(s string) (c4pb.DeviceMetricOperationalStatusCode_Value, error) {
+	return Parse[c4pb.DeviceMetricOperationalStatusCode_Value](s)
+}
+
+// DeviceMetricOperationalStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func DeviceMetricOperationalStatusCodeString(v c4pb.DeviceMetricOperationalStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidDeviceMetricOperationalStatusCode reports whether v is a code of DeviceMetricOperationalStatusCode.
+func IsValidDeviceMetricOperationalStatusCode(v c4pb.DeviceMetricOperationalStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseDeviceNameTypeCode returns the DeviceNameTypeCode of the FHIR code s, i.e. "udi-label-name".
+func ParseDeviceNameTypeCode(s string) (c4pb.DeviceNameTypeCode_Value, error) {
+	return Parse[c4pb.DeviceNameTypeCode_Value](s)
+}
+
+// DeviceNameTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func DeviceNameTypeCodeString(v c4pb.DeviceNameTypeCode_Value) string { return Code(v) }
+
+// IsValidDeviceNameTypeCode reports whether v is a code of DeviceNameTypeCode.
+func IsValidDeviceNameTypeCode(v c4pb.DeviceNameTypeCode_Value) bool { return Valid(v) }
+
+// ParseDeviceUseStatementStatusCode returns the DeviceUseStatementStatusCode of the FHIR code s, i.e. "active".
+func ParseDeviceUseStatementStatusCode(s string) (c4pb.DeviceUseStatementStatusCode_Value, error) {
+	return Parse[c4pb.DeviceUseStatementStatusCode_Value](s)
+}
+
+// DeviceUseStatementStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func DeviceUseStatementStatusCodeString(v c4pb.DeviceUseStatementStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidDeviceUseStatementStatusCode reports whether v is a code of DeviceUseStatementStatusCode.
+func IsValidDeviceUseStatementStatusCode(v c4pb.DeviceUseStatementStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseDiagnosticReportStatusCode returns the DiagnosticReportStatusCode of the FHIR code s, i.e. "registered".
+func ParseDiagnosticReportStatusCode(s string) (c4pb.DiagnosticReportStatusCode_Value, error) {
+	return Parse[c4pb.DiagnosticReportStatusCode_Value](s)
+}
+
+// DiagnosticReportStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func DiagnosticReportStatusCodeString(v c4pb.DiagnosticReportStatusCode_Value) string { return Code(v) }
+
+// IsValidDiagnosticReportStatusCode reports whether v is a code of DiagnosticReportStatusCode.
+func IsValidDiagnosticReportStatusCode(v c4pb.DiagnosticReportStatusCode_Value) bool { return Valid(v) }
+
+// ParseDiscriminatorTypeCode returns the DiscriminatorTypeCode of the FHIR code s, i.e. "value".
+func ParseDiscriminatorTypeCode(s string) (c4pb.DiscriminatorTypeCode_Value, error) {
+	return Parse[c4pb.DiscriminatorTypeCode_Value](s)
+}
+
+// DiscriminatorTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func DiscriminatorTypeCodeString(v c4pb.DiscriminatorTypeCode_Value) string { return Code(v) }
+
+// IsValidDiscriminatorTypeCode reports whether v is a code of DiscriminatorTypeCode.
+func IsValidDiscriminatorTypeCode(v c4pb.DiscriminatorTypeCode_Value) bool { return Valid(v) }
+
+// ParseDocumentModeCode returns the DocumentModeCode of the FHIR code s, i.e. "producer".
+func ParseDocumentModeCode(s string) (c4pb.DocumentModeCode_Value, error) {
+	return Parse[c4pb.DocumentModeCode_Value](s)
+}
+
+// DocumentModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func DocumentModeCodeString(v c4pb.DocumentModeCode_Value) string { return Code(v) }
+
+// IsValidDocumentModeCode reports whether v is a code of DocumentModeCode.
+func IsValidDocumentModeCode(v c4pb.DocumentModeCode_Value) bool { return Valid(v) }
+
+// ParseDocumentReferenceStatusCode returns the DocumentReferenceStatusCode of the FHIR code s, i.e. "current".
+func ParseDocumentReferenceStatusCode(s string) (c4pb.DocumentReferenceStatusCode_Value, error) {
+	return Parse[c4pb.DocumentReferenceStatusCode_Value](s)
+}
+
+// DocumentReferenceStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func DocumentReferenceStatusCodeString(v c4pb.DocumentReferenceStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidDocumentReferenceStatusCode reports whether v is a code of DocumentReferenceStatusCode.
+func IsValidDocumentReferenceStatusCode(v c4pb.DocumentReferenceStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseDocumentRelationshipTypeCode returns the DocumentRelationshipTypeCode of the FHIR code s, i.e. "replaces".
+func ParseDocumentRelationshipTypeCode(s string) (c4pb.DocumentRelationshipTypeCode_Value, error) {
+	return Parse[c4pb.DocumentRelationshipTypeCode_Value](s)
+}
+
+// DocumentRelationshipTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func DocumentRelationshipTypeCodeString(v c4pb.DocumentRelationshipTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidDocumentRelationshipTypeCode reports whether v is a code of DocumentRelationshipTypeCode.
+func IsValidDocumentRelationshipTypeCode(v c4pb.DocumentRelationshipTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseEligibilityRequestPurposeCode returns the EligibilityRequestPurposeCode of the FHIR code s, i.e. "auth-requirements".
+func ParseEligibilityRequestPurposeCode(s string) (c4pb.EligibilityRequestPurposeCode_Value, error) {
+	return Parse[c4pb.EligibilityRequestPurposeCode_Value](s)
+}
+
+// EligibilityRequestPurposeCodeString returns the FHIR code of v, or "" if it is not valid.
+func EligibilityRequestPurposeCodeString(v c4pb.EligibilityRequestPurposeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidEligibilityRequestPurposeCode reports whether v is a code of EligibilityRequestPurposeCode.
+func IsValidEligibilityRequestPurposeCode(v c4pb.EligibilityRequestPurposeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseEligibilityResponsePurposeCode returns the EligibilityResponsePurposeCode of the FHIR code s, i.e. "auth-requirements".
+func ParseEligibilityResponsePurposeCode(s string) (c4pb.EligibilityResponsePurposeCode_Value, error) {
+	return Parse[c4pb.EligibilityResponsePurposeCode_Value](s)
+}
+
+// EligibilityResponsePurposeCodeString returns the FHIR code of v, or "" if it is not valid.
+func EligibilityResponsePurposeCodeString(v c4pb.EligibilityResponsePurposeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidEligibilityResponsePurposeCode reports whether v is a code of EligibilityResponsePurposeCode.
+func IsValidEligibilityResponsePurposeCode(v c4pb.EligibilityResponsePurposeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseEnableWhenBehaviorCode returns the EnableWhenBehaviorCode of the FHIR code s, i.e. "all".
+func ParseEnableWhenBehaviorCode(s string) (c4pb.EnableWhenBehaviorCode_Value, error) {
+	return Parse[c4pb.EnableWhenBehaviorCode_Value](s)
+}
+
+// EnableWhenBehaviorCodeString returns the FHIR code of v, or "" if it is not valid.
+func EnableWhenBehaviorCodeString(v c4pb.EnableWhenBehaviorCode_Value) string { return Code(v) }
+
+// IsValidEnableWhenBehaviorCode reports whether v is a code of EnableWhenBehaviorCode.
+func IsValidEnableWhenBehaviorCode(v c4pb.EnableWhenBehaviorCode_Value) bool { return Valid(v) }
+
+// ParseEncounterLocationStatusCode returns the EncounterLocationStatusCode of the FHIR code s, i.e. "planned".
+func ParseEncounterLocationStatusCode(s string) (c4pb.EncounterLocationStatusCode_Value, error) {
+	return Parse[c4pb.EncounterLocationStatusCode_Value](s)
+}
+
+// EncounterLocationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func EncounterLocationStatusCodeString(v c4pb.EncounterLocationStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidEncounterLocationStatusCode reports whether v is a code of EncounterLocationStatusCode.
+func IsValidEncounterLocationStatusCode(v c4pb.EncounterLocationStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseEncounterStatusCode returns the EncounterStatusCode of the FHIR code s, i.e. "planned".
+func ParseEncounterStatusCode(s string) (c4pb.EncounterStatusCode_Value, error) {
+	return Parse[c4pb.EncounterStatusCode_Value](s)
+}
+
+// EncounterStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func EncounterStatusCodeString(v c4pb.EncounterStatusCode_Value) string { return Code(v) }
+
+// IsValidEncounterStatusCode reports whether v is a code of EncounterStatusCode.
+func IsValidEncounterStatusCode(v c4pb.EncounterStatusCode_Value) bool { return Valid(v) }
+
+// ParseEndpointStatusCode returns the EndpointStatusCode of the FHIR code s, i.e. "active".
+func ParseEndpointStatusCode(s string) (c4pb.EndpointStatusCode_Value, error) {
+	return Parse[c4pb.EndpointStatusCode_Value](s)
+}
+
+// EndpointStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func EndpointStatusCodeString(v c4pb.EndpointStatusCode_Value) string { return Code(v) }
+
+// IsValidEndpointStatusCode reports whether v is a code of EndpointStatusCode.
+func IsValidEndpointStatusCode(v c4pb.EndpointStatusCode_Value) bool { return Valid(v) }
+
+// ParseEpisodeOfCareStatusCode returns the EpisodeOfCareStatusCode of the FHIR code s, i.e. "planned".
+func ParseEpisodeOfCareStatusCode(s string) (c4pb.EpisodeOfCareStatusCode_Value, error) {
+	return Parse[c4pb.EpisodeOfCareStatusCode_Value](s)
+}
+
+// EpisodeOfCareStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func EpisodeOfCareStatusCodeString(v c4pb.EpisodeOfCareStatusCode_Value) string { return Code(v) }
+
+// IsValidEpisodeOfCareStatusCode reports whether v is a code of EpisodeOfCareStatusCode.
+func IsValidEpisodeOfCareStatusCode(v c4pb.EpisodeOfCareStatusCode_Value) bool { return Valid(v) }
+
+// ParseEventCapabilityModeCode returns the EventCapabilityModeCode of the FHIR code s, i.e. "sender".
+func ParseEventCapabilityModeCode(s string) (c4pb.EventCapabilityModeCode_Value, error) {
+	return Parse[c4pb.EventCapabilityModeCode_Value](s)
+}
+
+// EventCapabilityModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func EventCapabilityModeCodeString(v c4pb.EventCapabilityModeCode_Value) string { return Code(v) }
+
+// IsValidEventCapabilityModeCode reports whether v is a code of EventCapabilityModeCode.
+func IsValidEventCapabilityModeCode(v c4pb.EventCapabilityModeCode_Value) bool { return Valid(v) }
+
+// ParseEventStatusCode returns the EventStatusCode of the FHIR code s, i.e. "preparation".
+func ParseEventStatusCode(s string) (c4pb.EventStatusCode_Value, error) {
+	return Parse[c4pb.EventStatusCode_Value](s)
+}
+
+// EventStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func EventStatusCodeString(v c4pb.EventStatusCode_Value) string { return Code(v) }
+
+// IsValidEventStatusCode reports whether v is a code of EventStatusCode.
+func IsValidEventStatusCode(v c4pb.EventStatusCode_Value) bool { return Valid(v) }
+
+// ParseEventTimingCode returns the EventTimingCode of the FHIR code s, i.e. "MORN".
+func ParseEventTimingCode(s string) (c4pb.EventTimingCode_Value, error) {
+	return Parse[c4pb.EventTimingCode_Value](s)
+}
+
+// EventTimingCodeString returns the FHIR code of v, or "" if it is not valid.
+func EventTimingCodeString(v c4pb.EventTimingCode_Value) string { return Code(v) }
+
+// IsValidEventTimingCode reports whether v is a code of EventTimingCode.
+func IsValidEventTimingCode(v c4pb.EventTimingCode_Value) bool { return Valid(v) }
+
+// ParseEvidenceVariableTypeCode returns the EvidenceVariableTypeCode of the FHIR code s, i.e. "dichotomous".
+func ParseEvidenceVariableTypeCode(s string) (c4pb.EvidenceVariableTypeCode_Value, error) {
+	return Parse[c4pb.EvidenceVariableTypeCode_Value](s)
+}
+
+// EvidenceVariableTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func EvidenceVariableTypeCodeString(v c4pb.EvidenceVariableTypeCode_Value) string { return Code(v) }
+
+// IsValidEvidenceVariableTypeCode reports whether v is a code of EvidenceVariableTypeCode.
+func IsValidEvidenceVariableTypeCode(v c4pb.EvidenceVariableTypeCode_Value) bool { return Valid(v) }
+
+// ParseExampleScenarioActorTypeCode returns the ExampleScenarioActorTypeCode of the FHIR code s, i.e. "person".
+func ParseExampleScenarioActorTypeCode(s string) (c4pb.ExampleScenarioActorTypeCode_Value, error) {
+	return Parse[c4pb.ExampleScenarioActorTypeCode_Value](s)
+}
+
+// ExampleScenarioActorTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ExampleScenarioActorTypeCodeString(v c4pb.ExampleScenarioActorTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidExampleScenarioActorTypeCode reports whether v is a code of ExampleScenarioActorTypeCode.
+func IsValidExampleScenarioActorTypeCode(v c4pb.ExampleScenarioActorTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseExpansionParameterSourceCode returns the ExpansionParameterSourceCode of the FHIR code s, i.e. "input".
+func ParseExpansionParameterSourceCode(s string) (c4pb.ExpansionParameterSourceCode_Value, error) {
+	return Parse[c4pb.ExpansionParameterSourceCode_Value](s)
+}
+
+// ExpansionParameterSourceCodeString returns the FHIR code of v, or "" if it is not valid.
+func ExpansionParameterSourceCodeString(v c4pb.ExpansionParameterSourceCode_Value) string {
+	return Code(v)
+}
+
+// IsValidExpansionParameterSourceCode reports whether v is a code of ExpansionParameterSourceCode.
+func IsValidExpansionParameterSourceCode(v c4pb.ExpansionParameterSourceCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseExpansionProcessingRuleCode returns the ExpansionProcessingRuleCode of the FHIR code s, i.e. "all-codes".
+func ParseExpansionProcessingRuleCode(s string) (c4pb.ExpansionProcessingRuleCode_Value, error) {
+	return Parse[c4pb.ExpansionProcessingRuleCode_Value](s)
+}
+
+// ExpansionProcessingRuleCodeString returns the FHIR code of v, or "" if it is not valid.
+func ExpansionProcessingRuleCodeString(v c4pb.ExpansionProcessingRuleCode_Value) string {
+	return Code(v)
+}
+
+// IsValidExpansionProcessingRuleCode reports whether v is a code of ExpansionProcessingRuleCode.
+func IsValidExpansionProcessingRuleCode(v c4pb.ExpansionProcessingRuleCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseExplanationOfBenefitStatusCode returns the ExplanationOfBenefitStatusCode of the FHIR code s, i.e. "active".
+func ParseExplanationOfBenefitStatusCode(s string) (c4pb.ExplanationOfBenefitStatusCode_Value, error) {
+	return Parse[c4pb.ExplanationOfBenefitStatusCode_Value](s)
+}
+
+// ExplanationOfBenefitStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ExplanationOfBenefitStatusCodeString(v c4pb.ExplanationOfBenefitStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidExplanationOfBenefitStatusCode reports whether v is a code of ExplanationOfBenefitStatusCode.
+func IsValidExplanationOfBenefitStatusCode(v c4pb.ExplanationOfBenefitStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseExposureStateCode returns the ExposureStateCode of the FHIR code s, i.e. "exposure".
+func ParseExposureStateCode(s string) (c4pb.ExposureStateCode_Value, error) {
+	return Parse[c4pb.ExposureStateCode_Value](s)
+}
+
+// ExposureStateCodeString returns the FHIR code of v, or "" if it is not valid.
+func ExposureStateCodeString(v c4pb.ExposureStateCode_Value) string { return Code(v) }
+
+// IsValidExposureStateCode reports whether v is a code of ExposureStateCode.
+func IsValidExposureStateCode(v c4pb.ExposureStateCode_Value) bool { return Valid(v) }
+
+// ParseExtensionContextTypeCode returns the ExtensionContextTypeCode of the FHIR code s, i.e. "fhirpath".
+func ParseExtensionContextTypeCode(s string) (c4pb.ExtensionContextTypeCode_Value, error) {
+	return Parse[c4pb.ExtensionContextTypeCode_Value](s)
+}
+
+// ExtensionContextTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ExtensionContextTypeCodeString(v c4pb.ExtensionContextTypeCode_Value) string { return Code(v) }
+
+// IsValidExtensionContextTypeCode reports whether v is a code of ExtensionContextTypeCode.
+func IsValidExtensionContextTypeCode(v c4pb.ExtensionContextTypeCode_Value) bool { return Valid(v) }
+
+// ParseFHIRDeviceStatusCode returns the FHIRDeviceStatusCode of the FHIR code s, i.e. "active".
+func ParseFHIRDeviceStatusCode(s string) (c4pb.FHIRDeviceStatusCode_Value, error) {
+	return Parse[c4pb.FHIRDeviceStatusCode_Value](s)
+}
+
+// FHIRDeviceStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func FHIRDeviceStatusCodeString(v c4pb.FHIRDeviceStatusCode_Value) string { return Code(v) }
+
+// IsValidFHIRDeviceStatusCode reports whether v is a code of FHIRDeviceStatusCode.
+func IsValidFHIRDeviceStatusCode(v c4pb.FHIRDeviceStatusCode_Value) bool { return Valid(v) }
+
+// ParseFHIRRestfulInteractionsCode returns the FHIRRestfulInteractionsCode of the FHIR code s, i.e. "read".
+func ParseFHIRRestfulInteractionsCode(s string) (c4pb.FHIRRestfulInteractionsCode_Value, error) {
+	return Parse[c4pb.FHIRRestfulInteractionsCode_Value](s)
+}
+
+// FHIRRestfulInteractionsCodeString returns the FHIR code of v, or "" if it is not valid.
+func FHIRRestfulInteractionsCodeString(v c4pb.FHIRRestfulInteractionsCode_Value) string {
+	return Code(v)
+}
+
+// IsValidFHIRRestfulInteractionsCode reports whether v is a code of FHIRRestfulInteractionsCode.
+func IsValidFHIRRestfulInteractionsCode(v c4pb.FHIRRestfulInteractionsCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseFHIRSubstanceStatusCode returns the FHIRSubstanceStatusCode of the FHIR code s, i.e. "active".
+func ParseFHIRSubstanceStatusCode(s string) (c4pb.FHIRSubstanceStatusCode_Value, error) {
+	return Parse[c4pb.FHIRSubstanceStatusCode_Value](s)
+}
+
+// FHIRSubstanceStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func FHIRSubstanceStatusCodeString(v c4pb.FHIRSubstanceStatusCode_Value) string { return Code(v) }
+
+// IsValidFHIRSubstanceStatusCode reports whether v is a code of FHIRSubstanceStatusCode.
+func IsValidFHIRSubstanceStatusCode(v c4pb.FHIRSubstanceStatusCode_Value) bool { return Valid(v) }
+
+// ParseFHIRVersionCode returns the FHIRVersionCode of the FHIR code s, i.e. "0.01".
+func ParseFHIRVersionCode(s string) (c4pb.FHIRVersionCode_Value, error) {
+	return Parse[c4pb.FHIRVersionCode_Value](s)
+}
+
+// FHIRVersionCodeString returns the FHIR code of v, or "" if it is not valid.
+func FHIRVersionCodeString(v c4pb.FHIRVersionCode_Value) string { return Code(v) }
+
+// IsValidFHIRVersionCode reports whether v is a code of FHIRVersionCode.
+func IsValidFHIRVersionCode(v c4pb.FHIRVersionCode_Value) bool { return Valid(v) }
+
+// ParseFamilyHistoryStatusCode returns the FamilyHistoryStatusCode of the FHIR code s, i.e. "partial".
+func ParseFamilyHistoryStatusCode(s string) (c4pb.FamilyHistoryStatusCode_Value, error) {
+	return Parse[c4pb.FamilyHistoryStatusCode_Value](s)
+}
+
+// FamilyHistoryStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func FamilyHistoryStatusCodeString(v c4pb.FamilyHistoryStatusCode_Value) string { return Code(v) }
+
+// IsValidFamilyHistoryStatusCode reports whether v is a code of FamilyHistoryStatusCode.
+func IsValidFamilyHistoryStatusCode(v c4pb.FamilyHistoryStatusCode_Value) bool { return Valid(v) }
+
+// ParseFilterOperatorCode returns the FilterOperatorCode of the FHIR code s, i.e. "=".
+func ParseFilterOperatorCode(s string) (c4pb.FilterOperatorCode_Value, error) {
+	return Parse[c4pb.FilterOperatorCode_Value](s)
+}
+
+// FilterOperatorCodeString returns the FHIR code of v, or "" if it is not valid.
+func FilterOperatorCodeString(v c4pb.FilterOperatorCode_Value) string { return Code(v) }
+
+// IsValidFilterOperatorCode reports whether v is a code of FilterOperatorCode.
+func IsValidFilterOperatorCode(v c4pb.FilterOperatorCode_Value) bool { return Valid(v) }
+
+// ParseFinancialResourceStatusCode returns the FinancialResourceStatusCode of the FHIR code s, i.e. "active".
+func ParseFinancialResourceStatusCode(s string) (c4pb.FinancialResourceStatusCode_Value, error) {
+	return Parse[c4pb.FinancialResourceStatusCode_Value](s)
+}
+
+// FinancialResourceStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func FinancialResourceStatusCodeString(v c4pb.FinancialResourceStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidFinancialResourceStatusCode reports whether v is a code of FinancialResourceStatusCode.
+func IsValidFinancialResourceStatusCode(v c4pb.FinancialResourceStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseFlagStatusCode returns the FlagStatusCode of the FHIR code s, i.e. "active".
+func ParseFlagStatusCode(s string) (c4pb.FlagStatusCode_Value, error) {
+	return Parse[c4pb.FlagStatusCode_Value](s)
+}
+
+// FlagStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func FlagStatusCodeString(v c4pb.FlagStatusCode_Value) string { return Code(v) }
+
+// IsValidFlagStatusCode reports whether v is a code of FlagStatusCode.
+func IsValidFlagStatusCode(v c4pb.FlagStatusCode_Value) bool { return Valid(v) }
+
+// ParseGoalAcceptanceStatusCode returns the GoalAcceptanceStatusCode of the FHIR code s, i.e. "agree".
+func ParseGoalAcceptanceStatusCode(s string) (c4pb.GoalAcceptanceStatusCode_Value, error) {
+	return Parse[c4pb.GoalAcceptanceStatusCode_Value](s)
+}
+
+// GoalAcceptanceStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func GoalAcceptanceStatusCodeString(v c4pb.GoalAcceptanceStatusCode_Value) string { return Code(v) }
+
+// IsValidGoalAcceptanceStatusCode reports whether v is a code of GoalAcceptanceStatusCode.
+func IsValidGoalAcceptanceStatusCode(v c4pb.GoalAcceptanceStatusCode_Value) bool { return Valid(v) }
+
+// ParseGoalLifecycleStatusCode returns the GoalLifecycleStatusCode of the FHIR code s, i.e. "proposed".
+func ParseGoalLifecycleStatusCode(s string) (c4pb.GoalLifecycleStatusCode_Value, error) {
+	return Parse[c4pb.GoalLifecycleStatusCode_Value](s)
+}
+
+// GoalLifecycleStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func GoalLifecycleStatusCodeString(v c4pb.GoalLifecycleStatusCode_Value) string { return Code(v) }
+
+// IsValidGoalLifecycleStatusCode reports whether v is a code of GoalLifecycleStatusCode.
+func IsValidGoalLifecycleStatusCode(v c4pb.GoalLifecycleStatusCode_Value) bool { return Valid(v) }
+
+// ParseGraphCompartmentRuleCode returns the GraphCompartmentRuleCode of the FHIR code s, i.e. "identical".
+func ParseGraphCompartmentRuleCode(s string) (c4pb.GraphCompartmentRuleCode_Value, error) {
+	return Parse[c4pb.GraphCompartmentRuleCode_Value](s)
+}
+
+// GraphCompartmentRuleCodeString returns the FHIR code of v, or "" if it is not valid.
+func GraphCompartmentRuleCodeString(v c4pb.GraphCompartmentRuleCode_Value) string { return Code(v) }
+
+// IsValidGraphCompartmentRuleCode reports whether v is a code of GraphCompartmentRuleCode.
+func IsValidGraphCompartmentRuleCode(v c4pb.GraphCompartmentRuleCode_Value) bool { return Valid(v) }
+
+// ParseGraphCompartmentUseCode returns the GraphCompartmentUseCode of the FHIR code s, i.e. "condition".
+func ParseGraphCompartmentUseCode(s string) (c4pb.GraphCompartmentUseCode_Value, error) {
+	return Parse[c4pb.GraphCompartmentUseCode_Value](s)
+}
+
+// GraphCompartmentUseCodeString returns the FHIR code of v, or "" if it is not valid.
+func GraphCompartmentUseCodeString(v c4pb.GraphCompartmentUseCode_Value) string { return Code(v) }
+
+// IsValidGraphCompartmentUseCode reports whether v is a code of GraphCompartmentUseCode.
+func IsValidGraphCompartmentUseCode(v c4pb.GraphCompartmentUseCode_Value) bool { return Valid(v) }
+
+// ParseGroupMeasureCode returns the GroupMeasureCode of the FHIR code s, i.e. "mean".
+func ParseGroupMeasureCode(s string) (c4pb.GroupMeasureCode_Value, error) {
+	return Parse[c4pb.GroupMeasureCode_Value](s)
+}
+
+// GroupMeasureCodeString returns the FHIR code of v, or "" if it is not valid.
+func GroupMeasureCodeString(v c4pb.GroupMeasureCode_Value) string { return Code(v) }
+
+// IsValidGroupMeasureCode reports whether v is a code of GroupMeasureCode.
+func IsValidGroupMeasureCode(v c4pb.GroupMeasureCode_Value) bool { return Valid(v) }
+
+// ParseGroupTypeCode returns the GroupTypeCode of the FHIR code s, i.e. "person".
+func ParseGroupTypeCode(s string) (c4pb.GroupTypeCode_Value, error) {
+	return Parse[c4pb.GroupTypeCode_Value](s)
+}
+
+// GroupTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func GroupTypeCodeString(v c4pb.GroupTypeCode_Value) string { return Code(v) }
+
+// IsValidGroupTypeCode reports whether v is a code of GroupTypeCode.
+func IsValidGroupTypeCode(v c4pb.GroupTypeCode_Value) bool { return Valid(v) }
+
+// ParseGuidanceResponseStatusCode returns the GuidanceResponseStatusCode of the FHIR code s, i.e. "success".
+func ParseGuidanceResponseStatusCode(s string) (c4pb.GuidanceResponseStatusCode_Value, error) {
+	return Parse[c4pb.GuidanceResponseStatusCode_Value](s)
+}
+
+// GuidanceResponseStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func GuidanceResponseStatusCodeString(v c4pb.GuidanceResponseStatusCode_Value) string { return Code(v) }
+
+// IsValidGuidanceResponseStatusCode reports whether v is a code of GuidanceResponseStatusCode.
+func IsValidGuidanceResponseStatusCode(v c4pb.GuidanceResponseStatusCode_Value) bool { return Valid(v) }
+
+// ParseGuidePageGenerationCode returns the GuidePageGenerationCode of the FHIR code s, i.e. "html".
+func ParseGuidePageGenerationCode(s string) (c4pb.GuidePageGenerationCode_Value, error) {
+	return Parse[c4pb.GuidePageGenerationCode_Value](s)
+}
+
+// GuidePageGenerationCodeString returns the FHIR code of v, or "" if it is not valid.
+func GuidePageGenerationCodeString(v c4pb.GuidePageGenerationCode_Value) string { return Code(v) }
+
+// IsValidGuidePageGenerationCode reports whether v is a code of GuidePageGenerationCode.
+func IsValidGuidePageGenerationCode(v c4pb.GuidePageGenerationCode_Value) bool { return Valid(v) }
+
+// ParseGuideParameterCode returns the GuideParameterCode of the FHIR code s, i.e. "apply".
+func ParseGuideParameterCode(s string) (c4pb.GuideParameterCode_Value, error) {
+	return Parse[c4pb.GuideParameterCode_Value](s)
+}
+
+// GuideParameterCodeString returns the FHIR code of v, or "" if it is not valid.
+func GuideParameterCodeString(v c4pb.GuideParameterCode_Value) string { return Code(v) }
+
+// IsValidGuideParameterCode reports whether v is a code of GuideParameterCode.
+func IsValidGuideParameterCode(v c4pb.GuideParameterCode_Value) bool { return Valid(v) }
+
+// ParseHL7WorkgroupCode returns the HL7WorkgroupCode of the FHIR code s, i.e. "cbcc".
+func ParseHL7WorkgroupCode(s string) (c4pb.HL7WorkgroupCode_Value, error) {
+	return Parse[c4pb.HL7WorkgroupCode_Value](s)
+}
+
+// HL7WorkgroupCodeString returns the FHIR code of v, or "" if it is not valid.
+func HL7WorkgroupCodeString(v c4pb.HL7WorkgroupCode_Value) string { return Code(v) }
+
+// IsValidHL7WorkgroupCode reports whether v is a code of HL7WorkgroupCode.
+func IsValidHL7WorkgroupCode(v c4pb.HL7WorkgroupCode_Value) bool { return Valid(v) }
+
+// ParseHTTPVerbCode returns the HTTPVerbCode of the FHIR code s, i.e. "GET".
+func ParseHTTPVerbCode(s string) (c4pb.HTTPVerbCode_Value, error) {
+	return Parse[c4pb.HTTPVerbCode_Value](s)
+}
+
+// HTTPVerbCodeString returns the FHIR code of v, or "" if it is not valid.
+func HTTPVerbCodeString(v c4pb.HTTPVerbCode_Value) string { return Code(v) }
+
+// IsValidHTTPVerbCode reports whether v is a code of HTTPVerbCode.
+func IsValidHTTPVerbCode(v c4pb.HTTPVerbCode_Value) bool { return Valid(v) }
+
+// ParseHumanNameAssemblyOrderCode returns the HumanNameAssemblyOrderCode of the FHIR code s, i.e. "NL1".
+func ParseHumanNameAssemblyOrderCode(s string) (c4pb.HumanNameAssemblyOrderCode_Value, error) {
+	return Parse[c4pb.HumanNameAssemblyOrderCode_Value](s)
+}
+
+// HumanNameAssemblyOrderCodeString returns the FHIR code of v, or "" if it is not valid.
+func HumanNameAssemblyOrderCodeString(v c4pb.HumanNameAssemblyOrderCode_Value) string { return Code(v) }
+
+// IsValidHumanNameAssemblyOrderCode reports whether v is a code of HumanNameAssemblyOrderCode.
+func IsValidHumanNameAssemblyOrderCode(v c4pb.HumanNameAssemblyOrderCode_Value) bool { return Valid(v) }
+
+// ParseIdentifierUseCode returns the IdentifierUseCode of the FHIR code s, i.e. "usual".
+func ParseIdentifierUseCode(s string) (c4pb.IdentifierUseCode_Value, error) {
+	return Parse[c4pb.IdentifierUseCode_Value](s)
+}
+
+// IdentifierUseCodeString returns the FHIR code of v, or "" if it is not valid.
+func IdentifierUseCodeString(v c4pb.IdentifierUseCode_Value) string { return Code(v) }
+
+// IsValidIdentifierUseCode reports whether v is a code of IdentifierUseCode.
+func IsValidIdentifierUseCode(v c4pb.IdentifierUseCode_Value) bool { return Valid(v) }
+
+// ParseIdentityAssuranceLevelCode returns the IdentityAssuranceLevelCode of the FHIR code s, i.e. "level1".
+func ParseIdentityAssuranceLevelCode(s string) (c4pb.IdentityAssuranceLevelCode_Value, error) {
+	return Parse[c4pb.IdentityAssuranceLevelCode_Value](s)
+}
+
+// IdentityAssuranceLevelCodeString returns the FHIR code of v, or "" if it is not valid.
+func IdentityAssuranceLevelCodeString(v c4pb.IdentityAssuranceLevelCode_Value) string { return Code(v) }
+
+// IsValidIdentityAssuranceLevelCode reports whether v is a code of IdentityAssuranceLevelCode.
+func IsValidIdentityAssuranceLevelCode(v c4pb.IdentityAssuranceLevelCode_Value) bool { return Valid(v) }
+
+// ParseImagingStudyStatusCode returns the ImagingStudyStatusCode of the FHIR code s, i.e. "registered".
+func ParseImagingStudyStatusCode(s string) (c4pb.ImagingStudyStatusCode_Value, error) {
+	return Parse[c4pb.ImagingStudyStatusCode_Value](s)
+}
+
+// ImagingStudyStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ImagingStudyStatusCodeString(v c4pb.ImagingStudyStatusCode_Value) string { return Code(v) }
+
+// IsValidImagingStudyStatusCode reports whether v is a code of ImagingStudyStatusCode.
+func IsValidImagingStudyStatusCode(v c4pb.ImagingStudyStatusCode_Value) bool { return Valid(v) }
+
+// ParseImplantStatusCode returns the ImplantStatusCode of the FHIR code s, i.e. "functional".
+func ParseImplantStatusCode(s string) (c4pb.ImplantStatusCode_Value, error) {
+	return Parse[c4pb.ImplantStatusCode_Value](s)
+}
+
+// ImplantStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ImplantStatusCodeString(v c4pb.ImplantStatusCode_Value) string { return Code(v) }
+
+// IsValidImplantStatusCode reports whether v is a code of ImplantStatusCode.
+func IsValidImplantStatusCode(v c4pb.ImplantStatusCode_Value) bool { return Valid(v) }
+
+// ParseInvoicePriceComponentTypeCode returns the InvoicePriceComponentTypeCode of the FHIR code s, i.e. "base".
+func ParseInvoicePriceComponentTypeCode(s string) (c4pb.InvoicePriceComponentTypeCode_Value, error) {
+	return Parse[c4pb.InvoicePriceComponentTypeCode_Value](s)
+}
+
+// InvoicePriceComponentTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func InvoicePriceComponentTypeCodeString(v c4pb.InvoicePriceComponentTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidInvoicePriceComponentTypeCode reports whether v is a code of InvoicePriceComponentTypeCode.
+func IsValidInvoicePriceComponentTypeCode(v c4pb.InvoicePriceComponentTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseInvoiceStatusCode returns the InvoiceStatusCode of the FHIR code s, i.e. "draft".
+func ParseInvoiceStatusCode(s string) (c4pb.InvoiceStatusCode_Value, error) {
+	return Parse[c4pb.InvoiceStatusCode_Value](s)
+}
+
+// InvoiceStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func InvoiceStatusCodeString(v c4pb.InvoiceStatusCode_Value) string { return Code(v) }
+
+// IsValidInvoiceStatusCode reports whether v is a code of InvoiceStatusCode.
+func IsValidInvoiceStatusCode(v c4pb.InvoiceStatusCode_Value) bool { return Valid(v) }
+
+// ParseIssueSeverityCode returns the IssueSeverityCode of the FHIR code s, i.e. "fatal".
+func ParseIssueSeverityCode(s string) (c4pb.IssueSeverityCode_Value, error) {
+	return Parse[c4pb.IssueSeverityCode_Value](s)
+}
+
+// IssueSeverityCodeString returns the FHIR code of v, or "" if it is not valid.
+func IssueSeverityCodeString(v c4pb.IssueSeverityCode_Value) string { return Code(v) }
+
+// IsValidIssueSeverityCode reports whether v is a code of IssueSeverityCode.
+func IsValidIssueSeverityCode(v c4pb.IssueSeverityCode_Value) bool { return Valid(v) }
+
+// ParseIssueTypeCode returns the IssueTypeCode of the FHIR code s, i.e. "invalid".
+func ParseIssueTypeCode(s string) (c4pb.IssueTypeCode_Value, error) {
+	return Parse[c4pb.IssueTypeCode_Value](s)
+}
+
+// IssueTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func IssueTypeCodeString(v c4pb.IssueTypeCode_Value) string { return Code(v) }
+
+// IsValidIssueTypeCode reports whether v is a code of IssueTypeCode.
+func IsValidIssueTypeCode(v c4pb.IssueTypeCode_Value) bool { return Valid(v) }
+
+// ParseLinkTypeCode returns the LinkTypeCode of the FHIR code s, i.e. "replaced-by".
+func ParseLinkTypeCode(s string) (c4pb.LinkTypeCode_Value, error) {
+	return Parse[c4pb.LinkTypeCode_Value](s)
+}
+
+// LinkTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func LinkTypeCodeString(v c4pb.LinkTypeCode_Value) string { return Code(v) }
+
+// IsValidLinkTypeCode reports whether v is a code of LinkTypeCode.
+func IsValidLinkTypeCode(v c4pb.LinkTypeCode_Value) bool { return Valid(v) }
+
+// ParseLinkageTypeCode returns the LinkageTypeCode of the FHIR code s, i.e. "source".
+func ParseLinkageTypeCode(s string) (c4pb.LinkageTypeCode_Value, error) {
+	return Parse[c4pb.LinkageTypeCode_Value](s)
+}
+
+// LinkageTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func LinkageTypeCodeString(v c4pb.LinkageTypeCode_Value) string { return Code(v) }
+
+// IsValidLinkageTypeCode reports whether v is a code of LinkageTypeCode.
+func IsValidLinkageTypeCode(v c4pb.LinkageTypeCode_Value) bool { return Valid(v) }
+
+// ParseListModeCode returns the ListModeCode of the FHIR code s, i.e. "working".
+func ParseListModeCode(s string) (c4pb.ListModeCode_Value, error) {
+	return Parse[c4pb.ListModeCode_Value](s)
+}
+
+// ListModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ListModeCodeString(v c4pb.ListModeCode_Value) string { return Code(v) }
+
+// IsValidListModeCode reports whether v is a code of ListModeCode.
+func IsValidListModeCode(v c4pb.ListModeCode_Value) bool { return Valid(v) }
+
+// ParseListStatusCode returns the ListStatusCode of the FHIR code s, i.e. "current".
+func ParseListStatusCode(s string) (c4pb.ListStatusCode_Value, error) {
+	return Parse[c4pb.ListStatusCode_Value](s)
+}
+
+// ListStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ListStatusCodeString(v c4pb.ListStatusCode_Value) string { return Code(v) }
+
+// IsValidListStatusCode reports whether v is a code of ListStatusCode.
+func IsValidListStatusCode(v c4pb.ListStatusCode_Value) bool { return Valid(v) }
+
+// ParseLocationModeCode returns the LocationModeCode of the FHIR code s, i.e. "instance".
+func ParseLocationModeCode(s string) (c4pb.LocationModeCode_Value, error) {
+	return Parse[c4pb.LocationModeCode_Value](s)
+}
+
+// LocationModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func LocationModeCodeString(v c4pb.LocationModeCode_Value) string { return Code(v) }
+
+// IsValidLocationModeCode reports whether v is a code of LocationModeCode.
+func IsValidLocationModeCode(v c4pb.LocationModeCode_Value) bool { return Valid(v) }
+
+// ParseLocationStatusCode returns the LocationStatusCode of the FHIR code s, i.e. "active".
+func ParseLocationStatusCode(s string) (c4pb.LocationStatusCode_Value, error) {
+	return Parse[c4pb.LocationStatusCode_Value](s)
+}
+
+// LocationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func LocationStatusCodeString(v c4pb.LocationStatusCode_Value) string { return Code(v) }
+
+// IsValidLocationStatusCode reports whether v is a code of LocationStatusCode.
+func IsValidLocationStatusCode(v c4pb.LocationStatusCode_Value) bool { return Valid(v) }
+
+// ParseMatchGradeCode returns the MatchGradeCode of the FHIR code s, i.e. "certain".
+func ParseMatchGradeCode(s string) (c4pb.MatchGradeCode_Value, error) {
+	return Parse[c4pb.MatchGradeCode_Value](s)
+}
+
+// MatchGradeCodeString returns the FHIR code of v, or "" if it is not valid.
+func MatchGradeCodeString(v c4pb.MatchGradeCode_Value) string { return Code(v) }
+
+// IsValidMatchGradeCode reports whether v is a code of MatchGradeCode.
+func IsValidMatchGradeCode(v c4pb.MatchGradeCode_Value) bool { return Valid(v) }
+
+// ParseMeasureImprovementNotationCode returns the MeasureImprovementNotationCode of the FHIR code s, i.e. "increase".
+func ParseMeasureImprovementNotationCode(s string) (c4pb.MeasureImprovementNotationCode_Value, error) {
+	return Parse[c4pb.MeasureImprovementNotationCode_Value](s)
+}
+
+// MeasureImprovementNotationCodeString returns the FHIR code of v, or "" if it is not valid.
+func MeasureImprovementNotationCodeString(v c4pb.MeasureImprovementNotationCode_Value) string {
+	return Code(v)
+}
+
+// IsValidMeasureImprovementNotationCode reports whether v is a code of MeasureImprovementNotationCode.
+func IsValidMeasureImprovementNotationCode(v c4pb.MeasureImprovementNotationCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseMeasureReportStatusCode returns the MeasureReportStatusCode of the FHIR code s, i.e. "complete".
+func ParseMeasureReportStatusCode(s string) (c4pb.MeasureReportStatusCode_Value, error) {
+	return Parse[c4pb.MeasureReportStatusCode_Value](s)
+}
+
+// MeasureReportStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func MeasureReportStatusCodeString(v c4pb.MeasureReportStatusCode_Value) string { return Code(v) }
+
+// IsValidMeasureReportStatusCode reports whether v is a code of MeasureReportStatusCode.
+func IsValidMeasureReportStatusCode(v c4pb.MeasureReportStatusCode_Value) bool { return Valid(v) }
+
+// ParseMeasureReportTypeCode returns the MeasureReportTypeCode of the FHIR code s, i.e. "individual".
+func ParseMeasureReportTypeCode(s string) (c4pb.MeasureReportTypeCode_Value, error) {
+	return Parse[c4pb.MeasureReportTypeCode_Value](s)
+}
+
+// MeasureReportTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func MeasureReportTypeCodeString(v c4pb.MeasureReportTypeCode_Value) string { return Code(v) }
+
+// IsValidMeasureReportTypeCode reports whether v is a code of MeasureReportTypeCode.
+func IsValidMeasureReportTypeCode(v c4pb.MeasureReportTypeCode_Value) bool { return Valid(v) }
+
+// ParseMedicationAdministrationStatusCode returns the MedicationAdministrationStatusCode of the FHIR code s, i.e. "in-progress".
+func ParseMedicationAdministrationStatusCode(s string) (c4pb.MedicationAdministrationStatusCode_Value, error) {
+	return Parse[c4pb.MedicationAdministrationStatusCode_Value](s)
+}
+
+// MedicationAdministrationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func MedicationAdministrationStatusCodeString(v c4pb.MedicationAdministrationStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidMedicationAdministrationStatusCode reports whether v is a code of MedicationAdministrationStatusCode.
+func IsValidMedicationAdministrationStatusCode(v c4pb.MedicationAdministrationStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseMedicationDispenseStatusCode returns the MedicationDispenseStatusCode of the FHIR code s, i.e. "preparation".
+func ParseMedicationDispenseStatusCode(s string) (c4pb.MedicationDispenseStatusCode_Value, error) {
+	return Parse[c4pb.MedicationDispenseStatusCode_Value](s)
+}
+
+// MedicationDispenseStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func MedicationDispenseStatusCodeString(v c4pb.MedicationDispenseStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidMedicationDispenseStatusCode reports whether v is a code of MedicationDispenseStatusCode.
+func IsValidMedicationDispenseStatusCode(v c4pb.MedicationDispenseStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseMedicationKnowledgeStatusCode returns the MedicationKnowledgeStatusCode of the FHIR code s, i.e. "active".
+func ParseMedicationKnowledgeStatusCode(s string) (c4pb.MedicationKnowledgeStatusCode_Value, error) {
+	return Parse[c4pb.MedicationKnowledgeStatusCode_Value](s)
+}
+
+// MedicationKnowledgeStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func MedicationKnowledgeStatusCodeString(v c4pb.MedicationKnowledgeStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidMedicationKnowledgeStatusCode reports whether v is a code of MedicationKnowledgeStatusCode.
+func IsValidMedicationKnowledgeStatusCode(v c4pb.MedicationKnowledgeStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseMedicationRequestIntentCode returns the MedicationRequestIntentCode of the FHIR code s, i.e. "proposal".
+func ParseMedicationRequestIntentCode(s string) (c4pb.MedicationRequestIntentCode_Value, error) {
+	return Parse[c4pb.MedicationRequestIntentCode_Value](s)
+}
+
+// MedicationRequestIntentCodeString returns the FHIR code of v, or "" if it is not valid.
+func MedicationRequestIntentCodeString(v c4pb.MedicationRequestIntentCode_Value) string {
+	return Code(v)
+}
+
+// IsValidMedicationRequestIntentCode reports whether v is a code of MedicationRequestIntentCode.
+func IsValidMedicationRequestIntentCode(v c4pb.MedicationRequestIntentCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseMedicationStatementStatusCodes returns the MedicationStatementStatusCodes of the FHIR code s, i.e. "active".
+func ParseMedicationStatementStatusCodes(s string) (c4pb.MedicationStatementStatusCodes_Value, error) {
+	return Parse[c4pb.MedicationStatementStatusCodes_Value](s)
+}
+
+// MedicationStatementStatusCodesString returns the FHIR code of v, or "" if it is not valid.
+func MedicationStatementStatusCodesString(v c4pb.MedicationStatementStatusCodes_Value) string {
+	return Code(v)
+}
+
+// IsValidMedicationStatementStatusCodes reports whether v is a code of MedicationStatementStatusCodes.
+func IsValidMedicationStatementStatusCodes(v c4pb.MedicationStatementStatusCodes_Value) bool {
+	return Valid(v)
+}
+
+// ParseMedicationStatusCode returns the MedicationStatusCode of the FHIR code s, i.e. "active".
+func ParseMedicationStatusCode(s string) (c4pb.MedicationStatusCode_Value, error) {
+	return Parse[c4pb.MedicationStatusCode_Value](s)
+}
+
+// MedicationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func MedicationStatusCodeString(v c4pb.MedicationStatusCode_Value) string { return Code(v) }
+
+// IsValidMedicationStatusCode reports whether v is a code of MedicationStatusCode.
+func IsValidMedicationStatusCode(v c4pb.MedicationStatusCode_Value) bool { return Valid(v) }
+
+// ParseMedicationrequestStatusCode returns the MedicationrequestStatusCode of the FHIR code s, i.e. "active".
+func ParseMedicationrequestStatusCode(s string) (c4pb.MedicationrequestStatusCode_Value, error) {
+	return Parse[c4pb.MedicationrequestStatusCode_Value](s)
+}
+
+// MedicationrequestStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func MedicationrequestStatusCodeString(v c4pb.MedicationrequestStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidMedicationrequestStatusCode reports whether v is a code of MedicationrequestStatusCode.
+func IsValidMedicationrequestStatusCode(v c4pb.MedicationrequestStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseMessageSignificanceCategoryCode returns the MessageSignificanceCategoryCode of the FHIR code s, i.e. "consequence".
+func ParseMessageSignificanceCategoryCode(s string) (c4pb.MessageSignificanceCategoryCode_Value, error) {
+	return Parse[c4pb.MessageSignificanceCategoryCode_Value](s)
+}
+
+// MessageSignificanceCategoryCodeString returns the FHIR code of v, or "" if it is not valid.
+func MessageSignificanceCategoryCodeString(v c4pb.MessageSignificanceCategoryCode_Value) string {
+	return Code(v)
+}
+
+// IsValidMessageSignificanceCategoryCode reports whether v is a code of MessageSignificanceCategoryCode.
+func IsValidMessageSignificanceCategoryCode(v c4pb.MessageSignificanceCategoryCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseMessageheaderResponseRequestCode returns the MessageheaderResponseRequestCode of the FHIR code s, i.e. "always".
+func ParseMessageheaderResponseRequestCode(s string) (c4pb.MessageheaderResponseRequestCode_Value, error) {
+	return Parse[c4pb.MessageheaderResponseRequestCode_Value](s)
+}
+
+// MessageheaderResponseRequestCodeString returns the FHIR code of v, or "" if it is not valid.
+func MessageheaderResponseRequestCodeString(v c4pb.MessageheaderResponseRequestCode_Value) string {
+	return Code(v)
+}
+
+// IsValidMessageheaderResponseRequestCode reports whether v is a code of MessageheaderResponseRequestCode.
+func IsValidMessageheaderResponseRequestCode(v c4pb.MessageheaderResponseRequestCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseNameUseCode returns the NameUseCode of the FHIR code s, i.e. "usual".
+func ParseNameUseCode(s string) (c4pb.NameUseCode_Value, error) {
+	return Parse[c4pb.NameUseCode_Value](s)
+}
+
+// NameUseCodeString returns the FHIR code of v, or "" if it is not valid.
+func NameUseCodeString(v c4pb.NameUseCode_Value) string { return Code(v) }
+
+// IsValidNameUseCode reports whether v is a code of NameUseCode.
+func IsValidNameUseCode(v c4pb.NameUseCode_Value) bool { return Valid(v) }
+
+// ParseNamingSystemIdentifierTypeCode returns the NamingSystemIdentifierTypeCode of the FHIR code s, i.e. "oid".
+func ParseNamingSystemIdentifierTypeCode(s string) (c4pb.NamingSystemIdentifierTypeCode_Value, error) {
+	return Parse[c4pb.NamingSystemIdentifierTypeCode_Value](s)
+}
+
+// NamingSystemIdentifierTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func NamingSystemIdentifierTypeCodeString(v c4pb.NamingSystemIdentifierTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidNamingSystemIdentifierTypeCode reports whether v is a code of NamingSystemIdentifierTypeCode.
+func IsValidNamingSystemIdentifierTypeCode(v c4pb.NamingSystemIdentifierTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseNamingSystemTypeCode returns the NamingSystemTypeCode of the FHIR code s, i.e. "codesystem".
+func ParseNamingSystemTypeCode(s string) (c4pb.NamingSystemTypeCode_Value, error) {
+	return Parse[c4pb.NamingSystemTypeCode_Value](s)
+}
+
+// NamingSystemTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func NamingSystemTypeCodeString(v c4pb.NamingSystemTypeCode_Value) string { return Code(v) }
+
+// IsValidNamingSystemTypeCode reports whether v is a code of NamingSystemTypeCode.
+func IsValidNamingSystemTypeCode(v c4pb.NamingSystemTypeCode_Value) bool { return Valid(v) }
+
+// ParseNarrativeStatusCode returns the NarrativeStatusCode of the FHIR code s, i.e. "generated".
+func ParseNarrativeStatusCode(s string) (c4pb.NarrativeStatusCode_Value, error) {
+	return Parse[c4pb.NarrativeStatusCode_Value](s)
+}
+
+// NarrativeStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func NarrativeStatusCodeString(v c4pb.NarrativeStatusCode_Value) string { return Code(v) }
+
+// IsValidNarrativeStatusCode reports whether v is a code of NarrativeStatusCode.
+func IsValidNarrativeStatusCode(v c4pb.NarrativeStatusCode_Value) bool { return Valid(v) }
+
+// ParseNoteTypeCode returns the NoteTypeCode of the FHIR code s, i.e. "display".
+func ParseNoteTypeCode(s string) (c4pb.NoteTypeCode_Value, error) {
+	return Parse[c4pb.NoteTypeCode_Value](s)
+}
+
+// NoteTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func NoteTypeCodeString(v c4pb.NoteTypeCode_Value) string { return Code(v) }
+
+// IsValidNoteTypeCode reports whether v is a code of NoteTypeCode.
+func IsValidNoteTypeCode(v c4pb.NoteTypeCode_Value) bool { return Valid(v) }
+
+// ParseObservationDataTypeCode returns the ObservationDataTypeCode of the FHIR code s, i.e. "Quantity".
+func ParseObservationDataTypeCode(s string) (c4pb.ObservationDataTypeCode_Value, error) {
+	return Parse[c4pb.ObservationDataTypeCode_Value](s)
+}
+
+// ObservationDataTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ObservationDataTypeCodeString(v c4pb.ObservationDataTypeCode_Value) string { return Code(v) }
+
+// IsValidObservationDataTypeCode reports whether v is a code of ObservationDataTypeCode.
+func IsValidObservationDataTypeCode(v c4pb.ObservationDataTypeCode_Value) bool { return Valid(v) }
+
+// ParseObservationRangeCategoryCode returns the ObservationRangeCategoryCode of the FHIR code s, i.e. "reference".
+func ParseObservationRangeCategoryCode(s string) (c4pb.ObservationRangeCategoryCode_Value, error) {
+	return Parse[c4pb.ObservationRangeCategoryCode_Value](s)
+}
+
+// ObservationRangeCategoryCodeString returns the FHIR code of v, or "" if it is not valid.
+func ObservationRangeCategoryCodeString(v c4pb.ObservationRangeCategoryCode_Value) string {
+	return Code(v)
+}
+
+// IsValidObservationRangeCategoryCode reports whether v is a code of ObservationRangeCategoryCode.
+func IsValidObservationRangeCategoryCode(v c4pb.ObservationRangeCategoryCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseObservationStatusCode returns the ObservationStatusCode of the FHIR code s, i.e. "registered".
+func ParseObservationStatusCode(s string) (c4pb.ObservationStatusCode_Value, error) {
+	return Parse[c4pb.ObservationStatusCode_Value](s)
+}
+
+// ObservationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ObservationStatusCodeString(v c4pb.ObservationStatusCode_Value) string { return Code(v) }
+
+// IsValidObservationStatusCode reports whether v is a code of ObservationStatusCode.
+func IsValidObservationStatusCode(v c4pb.ObservationStatusCode_Value) bool { return Valid(v) }
+
+// ParseOperationKindCode returns the OperationKindCode of the FHIR code s, i.e. "operation".
+func ParseOperationKindCode(s string) (c4pb.OperationKindCode_Value, error) {
+	return Parse[c4pb.OperationKindCode_Value](s)
+}
+
+// OperationKindCodeString returns the FHIR code of v, or "" if it is not valid.
+func OperationKindCodeString(v c4pb.OperationKindCode_Value) string { return Code(v) }
+
+// IsValidOperationKindCode reports whether v is a code of OperationKindCode.
+func IsValidOperationKindCode(v c4pb.OperationKindCode_Value) bool { return Valid(v) }
+
+// ParseOperationParameterUseCode returns the OperationParameterUseCode of the FHIR code s, i.e. "in".
+func ParseOperationParameterUseCode(s string) (c4pb.OperationParameterUseCode_Value, error) {
+	return Parse[c4pb.OperationParameterUseCode_Value](s)
+}
+
+// OperationParameterUseCodeString returns the FHIR code of v, or "" if it is not valid.
+func OperationParameterUseCodeString(v c4pb.OperationParameterUseCode_Value) string { return Code(v) }
+
+// IsValidOperationParameterUseCode reports whether v is a code of OperationParameterUseCode.
+func IsValidOperationParameterUseCode(v c4pb.OperationParameterUseCode_Value) bool { return Valid(v) }
+
+// ParseOrientationTypeCode returns the OrientationTypeCode of the FHIR code s, i.e. "sense".
+func ParseOrientationTypeCode(s string) (c4pb.OrientationTypeCode_Value, error) {
+	return Parse[c4pb.OrientationTypeCode_Value](s)
+}
+
+// OrientationTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func OrientationTypeCodeString(v c4pb.OrientationTypeCode_Value) string { return Code(v) }
+
+// IsValidOrientationTypeCode reports whether v is a code of OrientationTypeCode.
+func IsValidOrientationTypeCode(v c4pb.OrientationTypeCode_Value) bool { return Valid(v) }
+
+// ParseParticipantRequiredCode returns the ParticipantRequiredCode of the FHIR code s, i.e. "required".
+func ParseParticipantRequiredCode(s string) (c4pb.ParticipantRequiredCode_Value, error) {
+	return Parse[c4pb.ParticipantRequiredCode_Value](s)
+}
+
+// ParticipantRequiredCodeString returns the FHIR code of v, or "" if it is not valid.
+func ParticipantRequiredCodeString(v c4pb.ParticipantRequiredCode_Value) string { return Code(v) }
+
+// IsValidParticipantRequiredCode reports whether v is a code of ParticipantRequiredCode.
+func IsValidParticipantRequiredCode(v c4pb.ParticipantRequiredCode_Value) bool { return Valid(v) }
+
+// ParseParticipationStatusCode returns the ParticipationStatusCode of the FHIR code s, i.e. "accepted".
+func ParseParticipationStatusCode(s string) (c4pb.ParticipationStatusCode_Value, error) {
+	return Parse[c4pb.ParticipationStatusCode_Value](s)
+}
+
+// ParticipationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ParticipationStatusCodeString(v c4pb.ParticipationStatusCode_Value) string { return Code(v) }
+
+// IsValidParticipationStatusCode reports whether v is a code of ParticipationStatusCode.
+func IsValidParticipationStatusCode(v c4pb.ParticipationStatusCode_Value) bool { return Valid(v) }
+
+// ParsePropertyRepresentationCode returns the PropertyRepresentationCode of the FHIR code s, i.e. "xmlAttr".
+func ParsePropertyRepresentationCode(s string) (c4pb.PropertyRepresentationCode_Value, error) {
+	return Parse[c4pb.PropertyRepresentationCode_Value](s)
+}
+
+// PropertyRepresentationCodeString returns the FHIR code of v, or "" if it is not valid.
+func PropertyRepresentationCodeString(v c4pb.PropertyRepresentationCode_Value) string { return Code(v) }
+
+// IsValidPropertyRepresentationCode reports whether v is a code of PropertyRepresentationCode.
+func IsValidPropertyRepresentationCode(v c4pb.PropertyRepresentationCode_Value) bool { return Valid(v) }
+
+// ParsePropertyTypeCode returns the PropertyTypeCode of the FHIR code s, i.e. "code".
+func ParsePropertyTypeCode(s string) (c4pb.PropertyTypeCode_Value, error) {
+	return Parse[c4pb.PropertyTypeCode_Value](s)
+}
+
+// PropertyTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func PropertyTypeCodeString(v c4pb.PropertyTypeCode_Value) string { return Code(v) }
+
+// IsValidPropertyTypeCode reports whether v is a code of PropertyTypeCode.
+func IsValidPropertyTypeCode(v c4pb.PropertyTypeCode_Value) bool { return Valid(v) }
+
+// ParseProvenanceEntityRoleCode returns the ProvenanceEntityRoleCode of the FHIR code s, i.e. "derivation".
+func ParseProvenanceEntityRoleCode(s string) (c4pb.ProvenanceEntityRoleCode_Value, error) {
+	return Parse[c4pb.ProvenanceEntityRoleCode_Value](s)
+}
+
+// ProvenanceEntityRoleCodeString returns the FHIR code of v, or "" if it is not valid.
+func ProvenanceEntityRoleCodeString(v c4pb.ProvenanceEntityRoleCode_Value) string { return Code(v) }
+
+// IsValidProvenanceEntityRoleCode reports whether v is a code of ProvenanceEntityRoleCode.
+func IsValidProvenanceEntityRoleCode(v c4pb.ProvenanceEntityRoleCode_Value) bool { return Valid(v) }
+
+// ParsePublicationStatusCode returns the PublicationStatusCode of the FHIR code s, i.e. "draft".
+func ParsePublicationStatusCode(s string) (c4pb.PublicationStatusCode_Value, error) {
+	return Parse[c4pb.PublicationStatusCode_Value](s)
+}
+
+// PublicationStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func PublicationStatusCodeString(v c4pb.PublicationStatusCode_Value) string { return Code(v) }
+
+// IsValidPublicationStatusCode reports whether v is a code of PublicationStatusCode.
+func IsValidPublicationStatusCode(v c4pb.PublicationStatusCode_Value) bool { return Valid(v) }
+
+// ParseQualityTypeCode returns the QualityTypeCode of the FHIR code s, i.e. "indel".
+func ParseQualityTypeCode(s string) (c4pb.QualityTypeCode_Value, error) {
+	return Parse[c4pb.QualityTypeCode_Value](s)
+}
+
+// QualityTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func QualityTypeCodeString(v c4pb.QualityTypeCode_Value) string { return Code(v) }
+
+// IsValidQualityTypeCode reports whether v is a code of QualityTypeCode.
+func IsValidQualityTypeCode(v c4pb.QualityTypeCode_Value) bool { return Valid(v) }
+
+// ParseQuantityComparatorCode returns the QuantityComparatorCode of the FHIR code s, i.e. "<".
+func ParseQuantityComparatorCode(s string) (c4pb.QuantityComparatorCode_Value, error) {
+	return Parse[c4pb.QuantityComparatorCode_Value](s)
+}
+
+// QuantityComparatorCodeString returns the FHIR code of v, or "" if it is not valid.
+func QuantityComparatorCodeString(v c4pb.QuantityComparatorCode_Value) string { return Code(v) }
+
+// IsValidQuantityComparatorCode reports whether v is a code of QuantityComparatorCode.
+func IsValidQuantityComparatorCode(v c4pb.QuantityComparatorCode_Value) bool { return Valid(v) }
+
+// ParseQuestionnaireItemOperatorCode returns the QuestionnaireItemOperatorCode of the FHIR code s, i.e. "exists".
+func ParseQuestionnaireItemOperatorCode(s string) (c4pb.QuestionnaireItemOperatorCode_Value, error) {
+	return Parse[c4pb.QuestionnaireItemOperatorCode_Value](s)
+}
+
+// QuestionnaireItemOperatorCodeString returns the FHIR code of v, or "" if it is not valid.
+func QuestionnaireItemOperatorCodeString(v c4pb.QuestionnaireItemOperatorCode_Value) string {
+	return Code(v)
+}
+
+// IsValidQuestionnaireItemOperatorCode reports whether v is a code of QuestionnaireItemOperatorCode.
+func IsValidQuestionnaireItemOperatorCode(v c4pb.QuestionnaireItemOperatorCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseQuestionnaireItemTypeCode returns the QuestionnaireItemTypeCode of the FHIR code s, i.e. "group".
+func ParseQuestionnaireItemTypeCode(s string) (c4pb.QuestionnaireItemTypeCode_Value, error) {
+	return Parse[c4pb.QuestionnaireItemTypeCode_Value](s)
+}
+
+// QuestionnaireItemTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func QuestionnaireItemTypeCodeString(v c4pb.QuestionnaireItemTypeCode_Value) string { return Code(v) }
+
+// IsValidQuestionnaireItemTypeCode reports whether v is a code of QuestionnaireItemTypeCode.
+func IsValidQuestionnaireItemTypeCode(v c4pb.QuestionnaireItemTypeCode_Value) bool { return Valid(v) }
+
+// ParseQuestionnaireItemUsageModeCode returns the QuestionnaireItemUsageModeCode of the FHIR code s, i.e. "capture-display".
+func ParseQuestionnaireItemUsageModeCode(s string) (c4pb.QuestionnaireItemUsageModeCode_Value, error) {
+	return Parse[c4pb.QuestionnaireItemUsageModeCode_Value](s)
+}
+
+// QuestionnaireItemUsageModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func QuestionnaireItemUsageModeCodeString(v c4pb.QuestionnaireItemUsageModeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidQuestionnaireItemUsageModeCode reports whether v is a code of QuestionnaireItemUsageModeCode.
+func IsValidQuestionnaireItemUsageModeCode(v c4pb.QuestionnaireItemUsageModeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseQuestionnaireResponseStatusCode returns the QuestionnaireResponseStatusCode of the FHIR code s, i.e. "in-progress".
+func ParseQuestionnaireResponseStatusCode(s string) (c4pb.QuestionnaireResponseStatusCode_Value, error) {
+	return Parse[c4pb.QuestionnaireResponseStatusCode_Value](s)
+}
+
+// QuestionnaireResponseStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func QuestionnaireResponseStatusCodeString(v c4pb.QuestionnaireResponseStatusCode_Value) string {
+	return Code(v)
+}
+
+// IsValidQuestionnaireResponseStatusCode reports whether v is a code of QuestionnaireResponseStatusCode.
+func IsValidQuestionnaireResponseStatusCode(v c4pb.QuestionnaireResponseStatusCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseReferenceHandlingPolicyCode returns the ReferenceHandlingPolicyCode of the FHIR code s, i.e. "literal".
+func ParseReferenceHandlingPolicyCode(s string) (c4pb.ReferenceHandlingPolicyCode_Value, error) {
+	return Parse[c4pb.ReferenceHandlingPolicyCode_Value](s)
+}
+
+// ReferenceHandlingPolicyCodeString returns the FHIR code of v, or "" if it is not valid.
+func ReferenceHandlingPolicyCodeString(v c4pb.ReferenceHandlingPolicyCode_Value) string {
+	return Code(v)
+}
+
+// IsValidReferenceHandlingPolicyCode reports whether v is a code of ReferenceHandlingPolicyCode.
+func IsValidReferenceHandlingPolicyCode(v c4pb.ReferenceHandlingPolicyCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseReferenceVersionRulesCode returns the ReferenceVersionRulesCode of the FHIR code s, i.e. "either".
+func ParseReferenceVersionRulesCode(s string) (c4pb.ReferenceVersionRulesCode_Value, error) {
+	return Parse[c4pb.ReferenceVersionRulesCode_Value](s)
+}
+
+// ReferenceVersionRulesCodeString returns the FHIR code of v, or "" if it is not valid.
+func ReferenceVersionRulesCodeString(v c4pb.ReferenceVersionRulesCode_Value) string { return Code(v) }
+
+// IsValidReferenceVersionRulesCode reports whether v is a code of ReferenceVersionRulesCode.
+func IsValidReferenceVersionRulesCode(v c4pb.ReferenceVersionRulesCode_Value) bool { return Valid(v) }
+
+// ParseRelatedArtifactTypeCode returns the RelatedArtifactTypeCode of the FHIR code s, i.e. "documentation".
+func ParseRelatedArtifactTypeCode(s string) (c4pb.RelatedArtifactTypeCode_Value, error) {
+	return Parse[c4pb.RelatedArtifactTypeCode_Value](s)
+}
+
+// RelatedArtifactTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func RelatedArtifactTypeCodeString(v c4pb.RelatedArtifactTypeCode_Value) string { return Code(v) }
+
+// IsValidRelatedArtifactTypeCode reports whether v is a code of RelatedArtifactTypeCode.
+func IsValidRelatedArtifactTypeCode(v c4pb.RelatedArtifactTypeCode_Value) bool { return Valid(v) }
+
+// ParseRepositoryTypeCode returns the RepositoryTypeCode of the FHIR code s, i.e. "directlink".
+func ParseRepositoryTypeCode(s string) (c4pb.RepositoryTypeCode_Value, error) {
+	return Parse[c4pb.RepositoryTypeCode_Value](s)
+}
+
+// RepositoryTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func RepositoryTypeCodeString(v c4pb.RepositoryTypeCode_Value) string { return Code(v) }
+
+// IsValidRepositoryTypeCode reports whether v is a code of RepositoryTypeCode.
+func IsValidRepositoryTypeCode(v c4pb.RepositoryTypeCode_Value) bool { return Valid(v) }
+
+// ParseRequestIntentCode returns the RequestIntentCode of the FHIR code s, i.e. "proposal".
+func ParseRequestIntentCode(s string) (c4pb.RequestIntentCode_Value, error) {
+	return Parse[c4pb.RequestIntentCode_Value](s)
+}
+
+// RequestIntentCodeString returns the FHIR code of v, or "" if it is not valid.
+func RequestIntentCodeString(v c4pb.RequestIntentCode_Value) string { return Code(v) }
+
+// IsValidRequestIntentCode reports whether v is a code of RequestIntentCode.
+func IsValidRequestIntentCode(v c4pb.RequestIntentCode_Value) bool { return Valid(v) }
+
+// ParseRequestPriorityCode returns the RequestPriorityCode of the FHIR code s, i.e. "routine".
+func ParseRequestPriorityCode(s string) (c4pb.RequestPriorityCode_Value, error) {
+	return Parse[c4pb.RequestPriorityCode_Value](s)
+}
+
+// RequestPriorityCodeString returns the FHIR code of v, or "" if it is not valid.
+func RequestPriorityCodeString(v c4pb.RequestPriorityCode_Value) string { return Code(v) }
+
+// IsValidRequestPriorityCode reports whether v is a code of RequestPriorityCode.
+func IsValidRequestPriorityCode(v c4pb.RequestPriorityCode_Value) bool { return Valid(v) }
+
+// ParseRequestResourceTypeCode returns the RequestResourceTypeCode of the FHIR code s, i.e. "Appointment".
+func ParseRequestResourceTypeCode(s string) (c4pb.RequestResourceTypeCode_Value, error) {
+	return Parse[c4pb.RequestResourceTypeCode_Value](s)
+}
+
+// RequestResourceTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func RequestResourceTypeCodeString(v c4pb.RequestResourceTypeCode_Value) string { return Code(v) }
+
+// IsValidRequestResourceTypeCode reports whether v is a code of RequestResourceTypeCode.
+func IsValidRequestResourceTypeCode(v c4pb.RequestResourceTypeCode_Value) bool { return Valid(v) }
+
+// ParseRequestStatusCode returns the RequestStatusCode of the FHIR code s, i.e. "draft".
+func ParseRequestStatusCode(s string) (c4pb.RequestStatusCode_Value, error) {
+	return Parse[c4pb.RequestStatusCode_Value](s)
+}
+
+// RequestStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func RequestStatusCodeString(v c4pb.RequestStatusCode_Value) string { return Code(v) }
+
+// IsValidRequestStatusCode reports whether v is a code of RequestStatusCode.
+func IsValidRequestStatusCode(v c4pb.RequestStatusCode_Value) bool { return Valid(v) }
+
+// ParseResearchElementTypeCode returns the ResearchElementTypeCode of the FHIR code s, i.e. "population".
+func ParseResearchElementTypeCode(s string) (c4pb.ResearchElementTypeCode_Value, error) {
+	return Parse[c4pb.ResearchElementTypeCode_Value](s)
+}
+
+// ResearchElementTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ResearchElementTypeCodeString(v c4pb.ResearchElementTypeCode_Value) string { return Code(v) }
+
+// IsValidResearchElementTypeCode reports whether v is a code of ResearchElementTypeCode.
+func IsValidResearchElementTypeCode(v c4pb.ResearchElementTypeCode_Value) bool { return Valid(v) }
+
+// ParseResearchStudyStatusCode returns the ResearchStudyStatusCode of the FHIR code s, i.e. "active".
+func ParseResearchStudyStatusCode(s string) (c4pb.ResearchStudyStatusCode_Value, error) {
+	return Parse[c4pb.ResearchStudyStatusCode_Value](s)
+}
+
+// ResearchStudyStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ResearchStudyStatusCodeString(v c4pb.ResearchStudyStatusCode_Value) string { return Code(v) }
+
+// IsValidResearchStudyStatusCode reports whether v is a code of ResearchStudyStatusCode.
+func IsValidResearchStudyStatusCode(v c4pb.ResearchStudyStatusCode_Value) bool { return Valid(v) }
+
+// ParseResearchSubjectStatusCode returns the ResearchSubjectStatusCode of the FHIR code s, i.e. "candidate".
+func ParseResearchSubjectStatusCode(s string) (c4pb.ResearchSubjectStatusCode_Value, error) {
+	return Parse[c4pb.ResearchSubjectStatusCode_Value](s)
+}
+
+// ResearchSubjectStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func ResearchSubjectStatusCodeString(v c4pb.ResearchSubjectStatusCode_Value) string { return Code(v) }
+
+// IsValidResearchSubjectStatusCode reports whether v is a code of ResearchSubjectStatusCode.
+func IsValidResearchSubjectStatusCode(v c4pb.ResearchSubjectStatusCode_Value) bool { return Valid(v) }
+
+// ParseResourceSecurityCategoryCode returns the ResourceSecurityCategoryCode of the FHIR code s, i.e. "anonymous".
+func ParseResourceSecurityCategoryCode(s string) (c4pb.ResourceSecurityCategoryCode_Value, error) {
+	return Parse[c4pb.ResourceSecurityCategoryCode_Value](s)
+}
+
+// ResourceSecurityCategoryCodeString returns the FHIR code of v, or "" if it is not valid.
+func ResourceSecurityCategoryCodeString(v c4pb.ResourceSecurityCategoryCode_Value) string {
+	return Code(v)
+}
+
+// IsValidResourceSecurityCategoryCode reports whether v is a code of ResourceSecurityCategoryCode.
+func IsValidResourceSecurityCategoryCode(v c4pb.ResourceSecurityCategoryCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseResourceTypeCode returns the ResourceTypeCode of the FHIR code s, i.e. "Account".
+func ParseResourceTypeCode(s string) (c4pb.ResourceTypeCode_Value, error) {
+	return Parse[c4pb.ResourceTypeCode_Value](s)
+}
+
+// ResourceTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ResourceTypeCodeString(v c4pb.ResourceTypeCode_Value) string { return Code(v) }
+
+// IsValidResourceTypeCode reports whether v is a code of ResourceTypeCode.
+func IsValidResourceTypeCode(v c4pb.ResourceTypeCode_Value) bool { return Valid(v) }
+
+// ParseResourceVersionPolicyCode returns the ResourceVersionPolicyCode of the FHIR code s, i.e. "no-version".
+func ParseResourceVersionPolicyCode(s string) (c4pb.ResourceVersionPolicyCode_Value, error) {
+	return Parse[c4pb.ResourceVersionPolicyCode_Value](s)
+}
+
+// ResourceVersionPolicyCodeString returns the FHIR code of v, or "" if it is not valid.
+func ResourceVersionPolicyCodeString(v c4pb.ResourceVersionPolicyCode_Value) string { return Code(v) }
+
+// IsValidResourceVersionPolicyCode reports whether v is a code of ResourceVersionPolicyCode.
+func IsValidResourceVersionPolicyCode(v c4pb.ResourceVersionPolicyCode_Value) bool { return Valid(v) }
+
+// ParseResponseTypeCode returns the ResponseTypeCode of the FHIR code s, i.e. "ok".
+func ParseResponseTypeCode(s string) (c4pb.ResponseTypeCode_Value, error) {
+	return Parse[c4pb.ResponseTypeCode_Value](s)
+}
+
+// ResponseTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func ResponseTypeCodeString(v c4pb.ResponseTypeCode_Value) string { return Code(v) }
+
+// IsValidResponseTypeCode reports whether v is a code of ResponseTypeCode.
+func IsValidResponseTypeCode(v c4pb.ResponseTypeCode_Value) bool { return Valid(v) }
+
+// ParseRestfulCapabilityModeCode returns the RestfulCapabilityModeCode of the FHIR code s, i.e. "client".
+func ParseRestfulCapabilityModeCode(s string) (c4pb.RestfulCapabilityModeCode_Value, error) {
+	return Parse[c4pb.RestfulCapabilityModeCode_Value](s)
+}
+
+// RestfulCapabilityModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func RestfulCapabilityModeCodeString(v c4pb.RestfulCapabilityModeCode_Value) string { return Code(v) }
+
+// IsValidRestfulCapabilityModeCode reports whether v is a code of RestfulCapabilityModeCode.
+func IsValidRestfulCapabilityModeCode(v c4pb.RestfulCapabilityModeCode_Value) bool { return Valid(v) }
+
+// ParseSPDXLicenseCode returns the SPDXLicenseCode of the FHIR code s, i.e. "not-open-source".
+func ParseSPDXLicenseCode(s string) (c4pb.SPDXLicenseCode_Value, error) {
+	return Parse[c4pb.SPDXLicenseCode_Value](s)
+}
+
+// SPDXLicenseCodeString returns the FHIR code of v, or "" if it is not valid.
+func SPDXLicenseCodeString(v c4pb.SPDXLicenseCode_Value) string { return Code(v) }
+
+// IsValidSPDXLicenseCode reports whether v is a code of SPDXLicenseCode.
+func IsValidSPDXLicenseCode(v c4pb.SPDXLicenseCode_Value) bool { return Valid(v) }
+
+// ParseSearchComparatorCode returns the SearchComparatorCode of the FHIR code s, i.e. "eq".
+func ParseSearchComparatorCode(s string) (c4pb.SearchComparatorCode_Value, error) {
+	return Parse[c4pb.SearchComparatorCode_Value](s)
+}
+
+// SearchComparatorCodeString returns the FHIR code of v, or "" if it is not valid.
+func SearchComparatorCodeString(v c4pb.SearchComparatorCode_Value) string { return Code(v) }
+
+// IsValidSearchComparatorCode reports whether v is a code of SearchComparatorCode.
+func IsValidSearchComparatorCode(v c4pb.SearchComparatorCode_Value) bool { return Valid(v) }
+
+// ParseSearchEntryModeCode returns the SearchEntryModeCode of the FHIR code s, i.e. "match".
+func ParseSearchEntryModeCode(s string) (c4pb.SearchEntryModeCode_Value, error) {
+	return Parse[c4pb.SearchEntryModeCode_Value](s)
+}
+
+// SearchEntryModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func SearchEntryModeCodeString(v c4pb.SearchEntryModeCode_Value) string { return Code(v) }
+
+// IsValidSearchEntryModeCode reports whether v is a code of SearchEntryModeCode.
+func IsValidSearchEntryModeCode(v c4pb.SearchEntryModeCode_Value) bool { return Valid(v) }
+
+// ParseSearchModifierCode returns the SearchModifierCode of the FHIR code s, i.e. "missing".
+func ParseSearchModifierCode(s string) (c4pb.SearchModifierCode_Value, error) {
+	return Parse[c4pb.SearchModifierCode_Value](s)
+}
+
+// SearchModifierCodeString returns the FHIR code of v, or "" if it is not valid.
+func SearchModifierCodeString(v c4pb.SearchModifierCode_Value) string { return Code(v) }
+
+// IsValidSearchModifierCode reports whether v is a code of SearchModifierCode.
+func IsValidSearchModifierCode(v c4pb.SearchModifierCode_Value) bool { return Valid(v) }
+
+// ParseSearchParamTypeCode returns the SearchParamTypeCode of the FHIR code s, i.e. "number".
+func ParseSearchParamTypeCode(s string) (c4pb.SearchParamTypeCode_Value, error) {
+	return Parse[c4pb.SearchParamTypeCode_Value](s)
+}
+
+// SearchParamTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func SearchParamTypeCodeString(v c4pb.SearchParamTypeCode_Value) string { return Code(v) }
+
+// IsValidSearchParamTypeCode reports whether v is a code of SearchParamTypeCode.
+func IsValidSearchParamTypeCode(v c4pb.SearchParamTypeCode_Value) bool { return Valid(v) }
+
+// ParseSequenceTypeCode returns the SequenceTypeCode of the FHIR code s, i.e. "aa".
+func ParseSequenceTypeCode(s string) (c4pb.SequenceTypeCode_Value, error) {
+	return Parse[c4pb.SequenceTypeCode_Value](s)
+}
+
+// SequenceTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func SequenceTypeCodeString(v c4pb.SequenceTypeCode_Value) string { return Code(v) }
+
+// IsValidSequenceTypeCode reports whether v is a code of SequenceTypeCode.
+func IsValidSequenceTypeCode(v c4pb.SequenceTypeCode_Value) bool { return Valid(v) }
+
+// ParseSlicingRulesCode returns the SlicingRulesCode of the FHIR code s, i.e. "closed".
+func ParseSlicingRulesCode(s string) (c4pb.SlicingRulesCode_Value, error) {
+	return Parse[c4pb.SlicingRulesCode_Value](s)
+}
+
+// SlicingRulesCodeString returns the FHIR code of v, or "" if it is not valid.
+func SlicingRulesCodeString(v c4pb.SlicingRulesCode_Value) string { return Code(v) }
+
+// IsValidSlicingRulesCode reports whether v is a code of SlicingRulesCode.
+func IsValidSlicingRulesCode(v c4pb.SlicingRulesCode_Value) bool { return Valid(v) }
+
+// ParseSlotStatusCode returns the SlotStatusCode of the FHIR code s, i.e. "busy".
+func ParseSlotStatusCode(s string) (c4pb.SlotStatusCode_Value, error) {
+	return Parse[c4pb.SlotStatusCode_Value](s)
+}
+
+// SlotStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func SlotStatusCodeString(v c4pb.SlotStatusCode_Value) string { return Code(v) }
+
+// IsValidSlotStatusCode reports whether v is a code of SlotStatusCode.
+func IsValidSlotStatusCode(v c4pb.SlotStatusCode_Value) bool { return Valid(v) }
+
+// ParseSmartCapabilitiesCode returns the SmartCapabilitiesCode of the FHIR code s, i.e. "launch-ehr".
+func ParseSmartCapabilitiesCode(s string) (c4pb.SmartCapabilitiesCode_Value, error) {
+	return Parse[c4pb.SmartCapabilitiesCode_Value](s)
+}
+
+// SmartCapabilitiesCodeString returns the FHIR code of v, or "" if it is not valid.
+func SmartCapabilitiesCodeString(v c4pb.SmartCapabilitiesCode_Value) string { return Code(v) }
+
+// IsValidSmartCapabilitiesCode reports whether v is a code of SmartCapabilitiesCode.
+func IsValidSmartCapabilitiesCode(v c4pb.SmartCapabilitiesCode_Value) bool { return Valid(v) }
+
+// ParseSortDirectionCode returns the SortDirectionCode of the FHIR code s, i.e. "ascending".
+func ParseSortDirectionCode(s string) (c4pb.SortDirectionCode_Value, error) {
+	return Parse[c4pb.SortDirectionCode_Value](s)
+}
+
+// SortDirectionCodeString returns the FHIR code of v, or "" if it is not valid.
+func SortDirectionCodeString(v c4pb.SortDirectionCode_Value) string { return Code(v) }
+
+// IsValidSortDirectionCode reports whether v is a code of SortDirectionCode.
+func IsValidSortDirectionCode(v c4pb.SortDirectionCode_Value) bool { return Valid(v) }
+
+// ParseSpecimenContainedPreferenceCode returns the SpecimenContainedPreferenceCode of the FHIR code s, i.e. "preferred".
+func ParseSpecimenContainedPreferenceCode(s string) (c4pb.SpecimenContainedPreferenceCode_Value, error) {
+	return Parse[c4pb.SpecimenContainedPreferenceCode_Value](s)
+}
+
+// SpecimenContainedPreferenceCodeString returns the FHIR code of v, or "" if it is not valid.
+func SpecimenContainedPreferenceCodeString(v c4pb.SpecimenContainedPreferenceCode_Value) string {
+	return Code(v)
+}
+
+// IsValidSpecimenContainedPreferenceCode reports whether v is a code of SpecimenContainedPreferenceCode.
+func IsValidSpecimenContainedPreferenceCode(v c4pb.SpecimenContainedPreferenceCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseSpecimenStatusCode returns the SpecimenStatusCode of the FHIR code s, i.e. "available".
+func ParseSpecimenStatusCode(s string) (c4pb.SpecimenStatusCode_Value, error) {
+	return Parse[c4pb.SpecimenStatusCode_Value](s)
+}
+
+// SpecimenStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func SpecimenStatusCodeString(v c4pb.SpecimenStatusCode_Value) string { return Code(v) }
+
+// IsValidSpecimenStatusCode reports whether v is a code of SpecimenStatusCode.
+func IsValidSpecimenStatusCode(v c4pb.SpecimenStatusCode_Value) bool { return Valid(v) }
+
+// ParseStandardsStatusCode returns the StandardsStatusCode of the FHIR code s, i.e. "draft".
+func ParseStandardsStatusCode(s string) (c4pb.StandardsStatusCode_Value, error) {
+	return Parse[c4pb.StandardsStatusCode_Value](s)
+}
+
+// StandardsStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func StandardsStatusCodeString(v c4pb.StandardsStatusCode_Value) string { return Code(v) }
+
+// IsValidStandardsStatusCode reports whether v is a code of StandardsStatusCode.
+func IsValidStandardsStatusCode(v c4pb.StandardsStatusCode_Value) bool { return Valid(v) }
+
+// ParseStatusCode returns the StatusCode of the FHIR code s, i.e. "attested".
+func ParseStatusCode(s string) (c4pb.StatusCode_Value, error) { return Parse[c4pb.StatusCode_Value](s) }
+
+// StatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func StatusCodeString(v c4pb.StatusCode_Value) string { return Code(v) }
+
+// IsValidStatusCode reports whether v is a code of StatusCode.
+func IsValidStatusCode(v c4pb.StatusCode_Value) bool { return Valid(v) }
+
+// ParseStrandTypeCode returns the StrandTypeCode of the FHIR code s, i.e. "watson".
+func ParseStrandTypeCode(s string) (c4pb.StrandTypeCode_Value, error) {
+	return Parse[c4pb.StrandTypeCode_Value](s)
+}
+
+// StrandTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func StrandTypeCodeString(v c4pb.StrandTypeCode_Value) string { return Code(v) }
+
+// IsValidStrandTypeCode reports whether v is a code of StrandTypeCode.
+func IsValidStrandTypeCode(v c4pb.StrandTypeCode_Value) bool { return Valid(v) }
+
+// ParseStructureDefinitionKindCode returns the StructureDefinitionKindCode of the FHIR code s, i.e. "primitive-type".
+func ParseStructureDefinitionKindCode(s string) (c4pb.StructureDefinitionKindCode_Value, error) {
+	return Parse[c4pb.StructureDefinitionKindCode_Value](s)
+}
+
+// StructureDefinitionKindCodeString returns the FHIR code of v, or "" if it is not valid.
+func StructureDefinitionKindCodeString(v c4pb.StructureDefinitionKindCode_Value) string {
+	return Code(v)
+}
+
+// IsValidStructureDefinitionKindCode reports whether v is a code of StructureDefinitionKindCode.
+func IsValidStructureDefinitionKindCode(v c4pb.StructureDefinitionKindCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseStructureMapContextTypeCode returns the StructureMapContextTypeCode of the FHIR code s, i.e. "type".
+func ParseStructureMapContextTypeCode(s string) (c4pb.StructureMapContextTypeCode_Value, error) {
+	return Parse[c4pb.StructureMapContextTypeCode_Value](s)
+}
+
+// StructureMapContextTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func StructureMapContextTypeCodeString(v c4pb.StructureMapContextTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidStructureMapContextTypeCode reports whether v is a code of StructureMapContextTypeCode.
+func IsValidStructureMapContextTypeCode(v c4pb.StructureMapContextTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseStructureMapGroupTypeModeCode returns the StructureMapGroupTypeModeCode of the FHIR code s, i.e. "none".
+func ParseStructureMapGroupTypeModeCode(s string) (c4pb.StructureMapGroupTypeModeCode_Value, error) {
+	return Parse[c4pb.StructureMapGroupTypeModeCode_Value](s)
+}
+
+// StructureMapGroupTypeModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func StructureMapGroupTypeModeCodeString(v c4pb.StructureMapGroupTypeModeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidStructureMapGroupTypeModeCode reports whether v is a code of StructureMapGroupTypeModeCode.
+func IsValidStructureMapGroupTypeModeCode(v c4pb.StructureMapGroupTypeModeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseStructureMapInputModeCode returns the StructureMapInputModeCode of the FHIR code s, i.e. "source".
+func ParseStructureMapInputModeCode(s string) (c4pb.StructureMapInputModeCode_Value, error) {
+	return Parse[c4pb.StructureMapInputModeCode_Value](s)
+}
+
+// StructureMapInputModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func StructureMapInputModeCodeString(v c4pb.StructureMapInputModeCode_Value) string { return Code(v) }
+
+// IsValidStructureMapInputModeCode reports whether v is a code of StructureMapInputModeCode.
+func IsValidStructureMapInputModeCode(v c4pb.StructureMapInputModeCode_Value) bool { return Valid(v) }
+
+// ParseStructureMapModelModeCode returns the StructureMapModelModeCode of the FHIR code s, i.e. "source".
+func ParseStructureMapModelModeCode(s string) (c4pb.StructureMapModelModeCode_Value, error) {
+	return Parse[c4pb.StructureMapModelModeCode_Value](s)
+}
+
+// StructureMapModelModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func StructureMapModelModeCodeString(v c4pb.StructureMapModelModeCode_Value) string { return Code(v) }
+
+// IsValidStructureMapModelModeCode reports whether v is a code of StructureMapModelModeCode.
+func IsValidStructureMapModelModeCode(v c4pb.StructureMapModelModeCode_Value) bool { return Valid(v) }
+
+// ParseStructureMapSourceListModeCode returns the StructureMapSourceListModeCode of the FHIR code s, i.e. "first".
+func ParseStructureMapSourceListModeCode(s string) (c4pb.StructureMapSourceListModeCode_Value, error) {
+	return Parse[c4pb.StructureMapSourceListModeCode_Value](s)
+}
+
+// StructureMapSourceListModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func StructureMapSourceListModeCodeString(v c4pb.StructureMapSourceListModeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidStructureMapSourceListModeCode reports whether v is a code of StructureMapSourceListModeCode.
+func IsValidStructureMapSourceListModeCode(v c4pb.StructureMapSourceListModeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseStructureMapTargetListModeCode returns the StructureMapTargetListModeCode of the FHIR code s, i.e. "first".
+func ParseStructureMapTargetListModeCode(s string) (c4pb.StructureMapTargetListModeCode_Value, error) {
+	return Parse[c4pb.StructureMapTargetListModeCode_Value](s)
+}
+
+// StructureMapTargetListModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func StructureMapTargetListModeCodeString(v c4pb.StructureMapTargetListModeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidStructureMapTargetListModeCode reports whether v is a code of StructureMapTargetListModeCode.
+func IsValidStructureMapTargetListModeCode(v c4pb.StructureMapTargetListModeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseStructureMapTransformCode returns the StructureMapTransformCode of the FHIR code s, i.e. "create".
+func ParseStructureMapTransformCode(s string) (c4pb.StructureMapTransformCode_Value, error) {
+	return Parse[c4pb.StructureMapTransformCode_Value](s)
+}
+
+// StructureMapTransformCodeString returns the FHIR code of v, or "" if it is not valid.
+func StructureMapTransformCodeString(v c4pb.StructureMapTransformCode_Value) string { return Code(v) }
+
+// IsValidStructureMapTransformCode reports whether v is a code of StructureMapTransformCode.
+func IsValidStructureMapTransformCode(v c4pb.StructureMapTransformCode_Value) bool { return Valid(v) }
+
+// ParseSubscriptionChannelTypeCode returns the SubscriptionChannelTypeCode of the FHIR code s, i.e. "rest-hook".
+func ParseSubscriptionChannelTypeCode(s string) (c4pb.SubscriptionChannelTypeCode_Value, error) {
+	return Parse[c4pb.SubscriptionChannelTypeCode_Value](s)
+}
+
+// SubscriptionChannelTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func SubscriptionChannelTypeCodeString(v c4pb.SubscriptionChannelTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidSubscriptionChannelTypeCode reports whether v is a code of SubscriptionChannelTypeCode.
+func IsValidSubscriptionChannelTypeCode(v c4pb.SubscriptionChannelTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseSubscriptionStatusCode returns the SubscriptionStatusCode of the FHIR code s, i.e. "requested".
+func ParseSubscriptionStatusCode(s string) (c4pb.SubscriptionStatusCode_Value, error) {
+	return Parse[c4pb.SubscriptionStatusCode_Value](s)
+}
+
+// SubscriptionStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func SubscriptionStatusCodeString(v c4pb.SubscriptionStatusCode_Value) string { return Code(v) }
+
+// IsValidSubscriptionStatusCode reports whether v is a code of SubscriptionStatusCode.
+func IsValidSubscriptionStatusCode(v c4pb.SubscriptionStatusCode_Value) bool { return Valid(v) }
+
+// ParseSupplyDeliveryStatusCode returns the SupplyDeliveryStatusCode of the FHIR code s, i.e. "in-progress".
+func ParseSupplyDeliveryStatusCode(s string) (c4pb.SupplyDeliveryStatusCode_Value, error) {
+	return Parse[c4pb.SupplyDeliveryStatusCode_Value](s)
+}
+
+// SupplyDeliveryStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func SupplyDeliveryStatusCodeString(v c4pb.SupplyDeliveryStatusCode_Value) string { return Code(v) }
+
+// IsValidSupplyDeliveryStatusCode reports whether v is a code of SupplyDeliveryStatusCode.
+func IsValidSupplyDeliveryStatusCode(v c4pb.SupplyDeliveryStatusCode_Value) bool { return Valid(v) }
+
+// ParseSupplyItemTypeCode returns the SupplyItemTypeCode of the FHIR code s, i.e. "medication".
+func ParseSupplyItemTypeCode(s string) (c4pb.SupplyItemTypeCode_Value, error) {
+	return Parse[c4pb.SupplyItemTypeCode_Value](s)
+}
+
+// SupplyItemTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func SupplyItemTypeCodeString(v c4pb.SupplyItemTypeCode_Value) string { return Code(v) }
+
+// IsValidSupplyItemTypeCode reports whether v is a code of SupplyItemTypeCode.
+func IsValidSupplyItemTypeCode(v c4pb.SupplyItemTypeCode_Value) bool { return Valid(v) }
+
+// ParseSupplyRequestStatusCode returns the SupplyRequestStatusCode of the FHIR code s, i.e. "draft".
+func ParseSupplyRequestStatusCode(s string) (c4pb.SupplyRequestStatusCode_Value, error) {
+	return Parse[c4pb.SupplyRequestStatusCode_Value](s)
+}
+
+// SupplyRequestStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func SupplyRequestStatusCodeString(v c4pb.SupplyRequestStatusCode_Value) string { return Code(v) }
+
+// IsValidSupplyRequestStatusCode reports whether v is a code of SupplyRequestStatusCode.
+func IsValidSupplyRequestStatusCode(v c4pb.SupplyRequestStatusCode_Value) bool { return Valid(v) }
+
+// ParseTaskIntentCode returns the TaskIntentCode of the FHIR code s, i.e. "unknown".
+func ParseTaskIntentCode(s string) (c4pb.TaskIntentCode_Value, error) {
+	return Parse[c4pb.TaskIntentCode_Value](s)
+}
+
+// TaskIntentCodeString returns the FHIR code of v, or "" if it is not valid.
+func TaskIntentCodeString(v c4pb.TaskIntentCode_Value) string { return Code(v) }
+
+// IsValidTaskIntentCode reports whether v is a code of TaskIntentCode.
+func IsValidTaskIntentCode(v c4pb.TaskIntentCode_Value) bool { return Valid(v) }
+
+// ParseTaskStatusCode returns the TaskStatusCode of the FHIR code s, i.e. "draft".
+func ParseTaskStatusCode(s string) (c4pb.TaskStatusCode_Value, error) {
+	return Parse[c4pb.TaskStatusCode_Value](s)
+}
+
+// TaskStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func TaskStatusCodeString(v c4pb.TaskStatusCode_Value) string { return Code(v) }
+
+// IsValidTaskStatusCode reports whether v is a code of TaskStatusCode.
+func IsValidTaskStatusCode(v c4pb.TaskStatusCode_Value) bool { return Valid(v) }
+
+// ParseTemplateStatusCodeLifeCycleCode returns the TemplateStatusCodeLifeCycleCode of the FHIR code s, i.e. "draft".
+func ParseTemplateStatusCodeLifeCycleCode(s string) (c4pb.TemplateStatusCodeLifeCycleCode_Value, error) {
+	return Parse[c4pb.TemplateStatusCodeLifeCycleCode_Value](s)
+}
+
+// TemplateStatusCodeLifeCycleCodeString returns the FHIR code of v, or "" if it is not valid.
+func TemplateStatusCodeLifeCycleCodeString(v c4pb.TemplateStatusCodeLifeCycleCode_Value) string {
+	return Code(v)
+}
+
+// IsValidTemplateStatusCodeLifeCycleCode reports whether v is a code of TemplateStatusCodeLifeCycleCode.
+func IsValidTemplateStatusCodeLifeCycleCode(v c4pb.TemplateStatusCodeLifeCycleCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseTestReportActionResultCode returns the TestReportActionResultCode of the FHIR code s, i.e. "pass".
+func ParseTestReportActionResultCode(s string) (c4pb.TestReportActionResultCode_Value, error) {
+	return Parse[c4pb.TestReportActionResultCode_Value](s)
+}
+
+// TestReportActionResultCodeString returns the FHIR code of v, or "" if it is not valid.
+func TestReportActionResultCodeString(v c4pb.TestReportActionResultCode_Value) string { return Code(v) }
+
+// IsValidTestReportActionResultCode reports whether v is a code of TestReportActionResultCode.
+func IsValidTestReportActionResultCode(v c4pb.TestReportActionResultCode_Value) bool { return Valid(v) }
+
+// ParseTestReportParticipantTypeCode returns the TestReportParticipantTypeCode of the FHIR code s, i.e. "test-engine".
+func ParseTestReportParticipantTypeCode(s string) (c4pb.TestReportParticipantTypeCode_Value, error) {
+	return Parse[c4pb.TestReportParticipantTypeCode_Value](s)
+}
+
+// TestReportParticipantTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func TestReportParticipantTypeCodeString(v c4pb.TestReportParticipantTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidTestReportParticipantTypeCode reports whether v is a code of TestReportParticipantTypeCode.
+func IsValidTestReportParticipantTypeCode(v c4pb.TestReportParticipantTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseTestReportResultCode returns the TestReportResultCode of the FHIR code s, i.e. "pass".
+func ParseTestReportResultCode(s string) (c4pb.TestReportResultCode_Value, error) {
+	return Parse[c4pb.TestReportResultCode_Value](s)
+}
+
+// TestReportResultCodeString returns the FHIR code of v, or "" if it is not valid.
+func TestReportResultCodeString(v c4pb.TestReportResultCode_Value) string { return Code(v) }
+
+// IsValidTestReportResultCode reports whether v is a code of TestReportResultCode.
+func IsValidTestReportResultCode(v c4pb.TestReportResultCode_Value) bool { return Valid(v) }
+
+// ParseTestReportStatusCode returns the TestReportStatusCode of the FHIR code s, i.e. "completed".
+func ParseTestReportStatusCode(s string) (c4pb.TestReportStatusCode_Value, error) {
+	return Parse[c4pb.TestReportStatusCode_Value](s)
+}
+
+// TestReportStatusCodeString returns the FHIR code of v, or "" if it is not valid.
+func TestReportStatusCodeString(v c4pb.TestReportStatusCode_Value) string { return Code(v) }
+
+// IsValidTestReportStatusCode reports whether v is a code of TestReportStatusCode.
+func IsValidTestReportStatusCode(v c4pb.TestReportStatusCode_Value) bool { return Valid(v) }
+
+// ParseTestScriptRequestMethodCode returns the TestScriptRequestMethodCode of the FHIR code s, i.e. "delete".
+func ParseTestScriptRequestMethodCode(s string) (c4pb.TestScriptRequestMethodCode_Value, error) {
+	return Parse[c4pb.TestScriptRequestMethodCode_Value](s)
+}
+
+// TestScriptRequestMethodCodeString returns the FHIR code of v, or "" if it is not valid.
+func TestScriptRequestMethodCodeString(v c4pb.TestScriptRequestMethodCode_Value) string {
+	return Code(v)
+}
+
+// IsValidTestScriptRequestMethodCode reports whether v is a code of TestScriptRequestMethodCode.
+func IsValidTestScriptRequestMethodCode(v c4pb.TestScriptRequestMethodCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseTriggerTypeCode returns the TriggerTypeCode of the FHIR code s, i.e. "named-event".
+func ParseTriggerTypeCode(s string) (c4pb.TriggerTypeCode_Value, error) {
+	return Parse[c4pb.TriggerTypeCode_Value](s)
+}
+
+// TriggerTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func TriggerTypeCodeString(v c4pb.TriggerTypeCode_Value) string { return Code(v) }
+
+// IsValidTriggerTypeCode reports whether v is a code of TriggerTypeCode.
+func IsValidTriggerTypeCode(v c4pb.TriggerTypeCode_Value) bool { return Valid(v) }
+
+// ParseTypeDerivationRuleCode returns the TypeDerivationRuleCode of the FHIR code s, i.e. "specialization".
+func ParseTypeDerivationRuleCode(s string) (c4pb.TypeDerivationRuleCode_Value, error) {
+	return Parse[c4pb.TypeDerivationRuleCode_Value](s)
+}
+
+// TypeDerivationRuleCodeString returns the FHIR code of v, or "" if it is not valid.
+func TypeDerivationRuleCodeString(v c4pb.TypeDerivationRuleCode_Value) string { return Code(v) }
+
+// IsValidTypeDerivationRuleCode reports whether v is a code of TypeDerivationRuleCode.
+func IsValidTypeDerivationRuleCode(v c4pb.TypeDerivationRuleCode_Value) bool { return Valid(v) }
+
+// ParseUDIEntryTypeCode returns the UDIEntryTypeCode of the FHIR code s, i.e. "barcode".
+func ParseUDIEntryTypeCode(s string) (c4pb.UDIEntryTypeCode_Value, error) {
+	return Parse[c4pb.UDIEntryTypeCode_Value](s)
+}
+
+// UDIEntryTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func UDIEntryTypeCodeString(v c4pb.UDIEntryTypeCode_Value) string { return Code(v) }
+
+// IsValidUDIEntryTypeCode reports whether v is a code of UDIEntryTypeCode.
+func IsValidUDIEntryTypeCode(v c4pb.UDIEntryTypeCode_Value) bool { return Valid(v) }
+
+// ParseUseCode returns the UseCode of the FHIR code s, i.e. "claim".
+func ParseUseCode(s string) (c4pb.UseCode_Value, error) { return Parse[c4pb.UseCode_Value](s) }
+
+// UseCodeString returns the FHIR code of v, or "" if it is not valid.
+func UseCodeString(v c4pb.UseCode_Value) string { return Code(v) }
+
+// IsValidUseCode reports whether v is a code of UseCode.
+func IsValidUseCode(v c4pb.UseCode_Value) bool { return Valid(v) }
+
+// ParseV20444Code returns the V20444Code of the FHIR code s, i.e. "F".
+func ParseV20444Code(s string) (c4pb.V20444Code_Value, error) { return Parse[c4pb.V20444Code_Value](s) }
+
+// V20444CodeString returns the FHIR code of v, or "" if it is not valid.
+func V20444CodeString(v c4pb.V20444Code_Value) string { return Code(v) }
+
+// IsValidV20444Code reports whether v is a code of V20444Code.
+func IsValidV20444Code(v c4pb.V20444Code_Value) bool { return Valid(v) }
+
+// ParseV3AddressUseCode returns the V3AddressUseCode of the FHIR code s, i.e. "_GeneralAddressUse".
+func ParseV3AddressUseCode(s string) (c4pb.V3AddressUseCode_Value, error) {
+	return Parse[c4pb.V3AddressUseCode_Value](s)
+}
+
+// V3AddressUseCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3AddressUseCodeString(v c4pb.V3AddressUseCode_Value) string { return Code(v) }
+
+// IsValidV3AddressUseCode reports whether v is a code of V3AddressUseCode.
+func IsValidV3AddressUseCode(v c4pb.V3AddressUseCode_Value) bool { return Valid(v) }
+
+// ParseV3ConfidentialityCode returns the V3ConfidentialityCode of the FHIR code s, i.e. "_Confidentiality".
+func ParseV3ConfidentialityCode(s string) (c4pb.V3ConfidentialityCode_Value, error) {
+	return Parse[c4pb.V3ConfidentialityCode_Value](s)
+}
+
+// V3ConfidentialityCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3ConfidentialityCodeString(v c4pb.V3ConfidentialityCode_Value) string { return Code(v) }
+
+// IsValidV3ConfidentialityCode reports whether v is a code of V3ConfidentialityCode.
+func IsValidV3ConfidentialityCode(v c4pb.V3ConfidentialityCode_Value) bool { return Valid(v) }
+
+// ParseV3EntityNamePartQualifierCode returns the V3EntityNamePartQualifierCode of the FHIR code s, i.e. "_OrganizationNamePartQualifier".
+func ParseV3EntityNamePartQualifierCode(s string) (c4pb.V3EntityNamePartQualifierCode_Value, error) {
+	return Parse[c4pb.V3EntityNamePartQualifierCode_Value](s)
+}
+
+// V3EntityNamePartQualifierCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3EntityNamePartQualifierCodeString(v c4pb.V3EntityNamePartQualifierCode_Value) string {
+	return Code(v)
+}
+
+// IsValidV3EntityNamePartQualifierCode reports whether v is a code of V3EntityNamePartQualifierCode.
+func IsValidV3EntityNamePartQualifierCode(v c4pb.V3EntityNamePartQualifierCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseV3EntityNamePartQualifierR2Code returns the V3EntityNamePartQualifierR2Code of the FHIR code s, i.e. "AD".
+func ParseV3EntityNamePartQualifierR2Code(s string) (c4pb.V3EntityNamePartQualifierR2Code_Value, error) {
+	return Parse[c4pb.V3EntityNamePartQualifierR2Code_Value](s)
+}
+
+// V3EntityNamePartQualifierR2CodeString returns the FHIR code of v, or "" if it is not valid.
+func V3EntityNamePartQualifierR2CodeString(v c4pb.V3EntityNamePartQualifierR2Code_Value) string {
+	return Code(v)
+}
+
+// IsValidV3EntityNamePartQualifierR2Code reports whether v is a code of V3EntityNamePartQualifierR2Code.
+func IsValidV3EntityNamePartQualifierR2Code(v c4pb.V3EntityNamePartQualifierR2Code_Value) bool {
+	return Valid(v)
+}
+
+// ParseV3EntityNameUseCode returns the V3EntityNameUseCode of the FHIR code s, i.e. "_NameRepresentationUse".
+func ParseV3EntityNameUseCode(s string) (c4pb.V3EntityNameUseCode_Value, error) {
+	return Parse[c4pb.V3EntityNameUseCode_Value](s)
+}
+
+// V3EntityNameUseCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3EntityNameUseCodeString(v c4pb.V3EntityNameUseCode_Value) string { return Code(v) }
+
+// IsValidV3EntityNameUseCode reports whether v is a code of V3EntityNameUseCode.
+func IsValidV3EntityNameUseCode(v c4pb.V3EntityNameUseCode_Value) bool { return Valid(v) }
+
+// ParseV3EntityNameUseR2Code returns the V3EntityNameUseR2Code of the FHIR code s, i.e. "Assumed".
+func ParseV3EntityNameUseR2Code(s string) (c4pb.V3EntityNameUseR2Code_Value, error) {
+	return Parse[c4pb.V3EntityNameUseR2Code_Value](s)
+}
+
+// V3EntityNameUseR2CodeString returns the FHIR code of v, or "" if it is not valid.
+func V3EntityNameUseR2CodeString(v c4pb.V3EntityNameUseR2Code_Value) string { return Code(v) }
+
+// IsValidV3EntityNameUseR2Code reports whether v is a code of V3EntityNameUseR2Code.
+func IsValidV3EntityNameUseR2Code(v c4pb.V3EntityNameUseR2Code_Value) bool { return Valid(v) }
+
+// ParseV3NullFlavorCode returns the V3NullFlavorCode of the FHIR code s, i.e. "NI".
+func ParseV3NullFlavorCode(s string) (c4pb.V3NullFlavorCode_Value, error) {
+	return Parse[c4pb.V3NullFlavorCode_Value](s)
+}
+
+// V3NullFlavorCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3NullFlavorCodeString(v c4pb.V3NullFlavorCode_Value) string { return Code(v) }
+
+// IsValidV3NullFlavorCode reports whether v is a code of V3NullFlavorCode.
+func IsValidV3NullFlavorCode(v c4pb.V3NullFlavorCode_Value) bool { return Valid(v) }
+
+// ParseV3ParticipationModeCode returns the V3ParticipationModeCode of the FHIR code s, i.e. "ELECTRONIC".
+func ParseV3ParticipationModeCode(s string) (c4pb.V3ParticipationModeCode_Value, error) {
+	return Parse[c4pb.V3ParticipationModeCode_Value](s)
+}
+
+// V3ParticipationModeCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3ParticipationModeCodeString(v c4pb.V3ParticipationModeCode_Value) string { return Code(v) }
+
+// IsValidV3ParticipationModeCode reports whether v is a code of V3ParticipationModeCode.
+func IsValidV3ParticipationModeCode(v c4pb.V3ParticipationModeCode_Value) bool { return Valid(v) }
+
+// ParseV3ProbabilityDistributionTypeCode returns the V3ProbabilityDistributionTypeCode of the FHIR code s, i.e. "B".
+func ParseV3ProbabilityDistributionTypeCode(s string) (c4pb.V3ProbabilityDistributionTypeCode_Value, error) {
+	return Parse[c4pb.V3ProbabilityDistributionTypeCode_Value](s)
+}
+
+// V3ProbabilityDistributionTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3ProbabilityDistributionTypeCodeString(v c4pb.V3ProbabilityDistributionTypeCode_Value) string {
+	return Code(v)
+}
+
+// IsValidV3ProbabilityDistributionTypeCode reports whether v is a code of V3ProbabilityDistributionTypeCode.
+func IsValidV3ProbabilityDistributionTypeCode(v c4pb.V3ProbabilityDistributionTypeCode_Value) bool {
+	return Valid(v)
+}
+
+// ParseV3RoleCode returns the V3RoleCode of the FHIR code s, i.e. "_AffiliationRoleType".
+func ParseV3RoleCode(s string) (c4pb.V3RoleCode_Value, error) { return Parse[c4pb.V3RoleCode_Value](s) }
+
+// V3RoleCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3RoleCodeString(v c4pb.V3RoleCode_Value) string { return Code(v) }
+
+// IsValidV3RoleCode reports whether v is a code of V3RoleCode.
+func IsValidV3RoleCode(v c4pb.V3RoleCode_Value) bool { return Valid(v) }
+
+// ParseV3TimingEventCode returns the V3TimingEventCode of the FHIR code s, i.e. "AC".
+func ParseV3TimingEventCode(s string) (c4pb.V3TimingEventCode_Value, error) {
+	return Parse[c4pb.V3TimingEventCode_Value](s)
+}
+
+// V3TimingEventCodeString returns the FHIR code of v, or "" if it is not valid.
+func V3TimingEventCodeString(v c4pb.V3TimingEventCode_Value) string { return Code(v) }
+
+// IsValidV3TimingEventCode reports whether v is a code of V3TimingEventCode.
+func IsValidV3TimingEventCode(v c4pb.V3TimingEventCode_Value) bool { return Valid(v) }
+
+// ParseVisionBaseCode returns the VisionBaseCode of the FHIR code s, i.e. "up".
+func ParseVisionBaseCode(s string) (c4pb.VisionBaseCode_Value, error) {
+	return Parse[c4pb.VisionBaseCode_Value](s)
+}
+
+// VisionBaseCodeString returns the FHIR code of v, or "" if it is not valid.
+func VisionBaseCodeString(v c4pb.VisionBaseCode_Value) string { return Code(v) }
+
+// IsValidVisionBaseCode reports whether v is a code of VisionBaseCode.
+func IsValidVisionBaseCode(v c4pb.VisionBaseCode_Value) bool { return Valid(v) }
+
+// ParseVisionEyesCode returns the VisionEyesCode of the FHIR code s, i.e. "right".
+func ParseVisionEyesCode(s string) (c4pb.VisionEyesCode_Value, error) {
+	return Parse[c4pb.VisionEyesCode_Value](s)
+}
+
+// VisionEyesCodeString returns the FHIR code of v, or "" if it is not valid.
+func VisionEyesCodeString(v c4pb.VisionEyesCode_Value) string { return Code(v) }
+
+// IsValidVisionEyesCode reports whether v is a code of VisionEyesCode.
+func IsValidVisionEyesCode(v c4pb.VisionEyesCode_Value) bool { return Valid(v) }
+
+// ParseXPathUsageTypeCode returns the XPathUsageTypeCode of the FHIR code s, i.e. "normal".
+func ParseXPathUsageTypeCode(s string) (c4pb.XPathUsageTypeCode_Value, error) {
+	return Parse[c4pb.XPathUsageTypeCode_Value](s)
+}
+
+// XPathUsageTypeCodeString returns the FHIR code of v, or "" if it is not valid.
+func XPathUsageTypeCodeString(v c4pb.XPathUsageTypeCode_Value) string { return Code(v) }
+
+// IsValidXPathUsageTypeCode reports whether v is a code of XPathUsageTypeCode.
+func IsValidXPathUsageTypeCode(v c4pb.XPathUsageTypeCode_Value) bool { return Valid(v) }
+
+// ParseBodyLengthUnitsValueSet returns the BodyLengthUnitsValueSet of the FHIR code s, i.e. "cm".
+func ParseBodyLengthUnitsValueSet(s string) (vspb.BodyLengthUnitsValueSet_Value, error) {
+	return Parse[vspb.BodyLengthUnitsValueSet_Value](s)
+}
+
+// BodyLengthUnitsValueSetString returns the FHIR code of v, or "" if it is not valid.
+func BodyLengthUnitsValueSetString(v vspb.BodyLengthUnitsValueSet_Value) string { return Code(v) }
+
+// IsValidBodyLengthUnitsValueSet reports whether v is a code of BodyLengthUnitsValueSet.
+func IsValidBodyLengthUnitsValueSet(v vspb.BodyLengthUnitsValueSet_Value) bool { return Valid(v) }
+
+// ParseBodyTemperatureUnitsValueSet returns the BodyTemperatureUnitsValueSet of the FHIR code s, i.e. "Cel".
+func ParseBodyTemperatureUnitsValueSet(s string) (vspb.BodyTemperatureUnitsValueSet_Value, error) {
+	return Parse[vspb.BodyTemperatureUnitsValueSet_Value](s)
+}
+
+// BodyTemperatureUnitsValueSetString returns the FHIR code of v, or "" if it is not valid.
+func BodyTemperatureUnitsValueSetString(v vspb.BodyTemperatureUnitsValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidBodyTemperatureUnitsValueSet reports whether v is a code of BodyTemperatureUnitsValueSet.
+func IsValidBodyTemperatureUnitsValueSet(v vspb.BodyTemperatureUnitsValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseBodyWeightUnitsValueSet returns the BodyWeightUnitsValueSet of the FHIR code s, i.e. "kg".
+func ParseBodyWeightUnitsValueSet(s string) (vspb.BodyWeightUnitsValueSet_Value, error) {
+	return Parse[vspb.BodyWeightUnitsValueSet_Value](s)
+}
+
+// BodyWeightUnitsValueSetString returns the FHIR code of v, or "" if it is not valid.
+func BodyWeightUnitsValueSetString(v vspb.BodyWeightUnitsValueSet_Value) string { return Code(v) }
+
+// IsValidBodyWeightUnitsValueSet reports whether v is a code of BodyWeightUnitsValueSet.
+func IsValidBodyWeightUnitsValueSet(v vspb.BodyWeightUnitsValueSet_Value) bool { return Valid(v) }
+
+// ParseCarePlanActivityKindValueSet returns the CarePlanActivityKindValueSet of the FHIR code s, i.e. "Appointment".
+func ParseCarePlanActivityKindValueSet(s string) (vspb.CarePlanActivityKindValueSet_Value, error) {
+	return Parse[vspb.CarePlanActivityKindValueSet_Value](s)
+}
+
+// CarePlanActivityKindValueSetString returns the FHIR code of v, or "" if it is not valid.
+func CarePlanActivityKindValueSetString(v vspb.CarePlanActivityKindValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidCarePlanActivityKindValueSet reports whether v is a code of CarePlanActivityKindValueSet.
+func IsValidCarePlanActivityKindValueSet(v vspb.CarePlanActivityKindValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseCarePlanIntentValueSet returns the CarePlanIntentValueSet of the FHIR code s, i.e. "proposal".
+func ParseCarePlanIntentValueSet(s string) (vspb.CarePlanIntentValueSet_Value, error) {
+	return Parse[vspb.CarePlanIntentValueSet_Value](s)
+}
+
+// CarePlanIntentValueSetString returns the FHIR code of v, or "" if it is not valid.
+func CarePlanIntentValueSetString(v vspb.CarePlanIntentValueSet_Value) string { return Code(v) }
+
+// IsValidCarePlanIntentValueSet reports whether v is a code of CarePlanIntentValueSet.
+func IsValidCarePlanIntentValueSet(v vspb.CarePlanIntentValueSet_Value) bool { return Valid(v) }
+
+// ParseClinicalImpressionStatusValueSet returns the ClinicalImpressionStatusValueSet of the FHIR code s, i.e. "in-progress".
+func ParseClinicalImpressionStatusValueSet(s string) (vspb.ClinicalImpressionStatusValueSet_Value, error) {
+	return Parse[vspb.ClinicalImpressionStatusValueSet_Value](s)
+}
+
+// ClinicalImpressionStatusValueSetString returns the FHIR code of v, or "" if it is not valid.
+func ClinicalImpressionStatusValueSetString(v vspb.ClinicalImpressionStatusValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidClinicalImpressionStatusValueSet reports whether v is a code of ClinicalImpressionStatusValueSet.
+func IsValidClinicalImpressionStatusValueSet(v vspb.ClinicalImpressionStatusValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseEntityNamePartQualifierValueSet returns the EntityNamePartQualifierValueSet of the FHIR code s, i.e. "LS".
+func ParseEntityNamePartQualifierValueSet(s string) (vspb.EntityNamePartQualifierValueSet_Value, error) {
+	return Parse[vspb.EntityNamePartQualifierValueSet_Value](s)
+}
+
+// EntityNamePartQualifierValueSetString returns the FHIR code of v, or "" if it is not valid.
+func EntityNamePartQualifierValueSetString(v vspb.EntityNamePartQualifierValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidEntityNamePartQualifierValueSet reports whether v is a code of EntityNamePartQualifierValueSet.
+func IsValidEntityNamePartQualifierValueSet(v vspb.EntityNamePartQualifierValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseEventTimingValueSet returns the EventTimingValueSet of the FHIR code s, i.e. "MORN".
+func ParseEventTimingValueSet(s string) (vspb.EventTimingValueSet_Value, error) {
+	return Parse[vspb.EventTimingValueSet_Value](s)
+}
+
+// EventTimingValueSetString returns the FHIR code of v, or "" if it is not valid.
+func EventTimingValueSetString(v vspb.EventTimingValueSet_Value) string { return Code(v) }
+
+// IsValidEventTimingValueSet reports whether v is a code of EventTimingValueSet.
+func IsValidEventTimingValueSet(v vspb.EventTimingValueSet_Value) bool { return Valid(v) }
+
+// ParseFHIRAllTypesValueSet returns the FHIRAllTypesValueSet of the FHIR code s, i.e. "Address".
+func ParseFHIRAllTypesValueSet(s string) (vspb.FHIRAllTypesValueSet_Value, error) {
+	return Parse[vspb.FHIRAllTypesValueSet_Value](s)
+}
+
+// FHIRAllTypesValueSetString returns the FHIR code of v, or "" if it is not valid.
+func FHIRAllTypesValueSetString(v vspb.FHIRAllTypesValueSet_Value) string { return Code(v) }
+
+// IsValidFHIRAllTypesValueSet reports whether v is a code of FHIRAllTypesValueSet.
+func IsValidFHIRAllTypesValueSet(v vspb.FHIRAllTypesValueSet_Value) bool { return Valid(v) }
+
+// ParseFHIRDefinedTypeValueSet returns the FHIRDefinedTypeValueSet of the FHIR code s, i.e. "Address".
+func ParseFHIRDefinedTypeValueSet(s string) (vspb.FHIRDefinedTypeValueSet_Value, error) {
+	return Parse[vspb.FHIRDefinedTypeValueSet_Value](s)
+}
+
+// FHIRDefinedTypeValueSetString returns the FHIR code of v, or "" if it is not valid.
+func FHIRDefinedTypeValueSetString(v vspb.FHIRDefinedTypeValueSet_Value) string { return Code(v) }
+
+// IsValidFHIRDefinedTypeValueSet reports whether v is a code of FHIRDefinedTypeValueSet.
+func IsValidFHIRDefinedTypeValueSet(v vspb.FHIRDefinedTypeValueSet_Value) bool { return Valid(v) }
+
+// ParseHumanNameAssemblyOrderValueSet returns the HumanNameAssemblyOrderValueSet of the FHIR code s, i.e. "NL1".
+func ParseHumanNameAssemblyOrderValueSet(s string) (vspb.HumanNameAssemblyOrderValueSet_Value, error) {
+	return Parse[vspb.HumanNameAssemblyOrderValueSet_Value](s)
+}
+
+// HumanNameAssemblyOrderValueSetString returns the FHIR code of v, or "" if it is not valid.
+func HumanNameAssemblyOrderValueSetString(v vspb.HumanNameAssemblyOrderValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidHumanNameAssemblyOrderValueSet reports whether v is a code of HumanNameAssemblyOrderValueSet.
+func IsValidHumanNameAssemblyOrderValueSet(v vspb.HumanNameAssemblyOrderValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseImmunizationEvaluationStatusCodesValueSet returns the ImmunizationEvaluationStatusCodesValueSet of the FHIR code s, i.e. "completed".
+func ParseImmunizationEvaluationStatusCodesValueSet(s string) (vspb.ImmunizationEvaluationStatusCodesValueSet_Value, error) {
+	return Parse[vspb.ImmunizationEvaluationStatusCodesValueSet_Value](s)
+}
+
+// ImmunizationEvaluationStatusCodesValueSetString returns the FHIR code of v, or "" if it is not valid.
+func ImmunizationEvaluationStatusCodesValueSetString(v vspb.ImmunizationEvaluationStatusCodesValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidImmunizationEvaluationStatusCodesValueSet reports whether v is a code of ImmunizationEvaluationStatusCodesValueSet.
+func IsValidImmunizationEvaluationStatusCodesValueSet(v vspb.ImmunizationEvaluationStatusCodesValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseImmunizationStatusCodesValueSet returns the ImmunizationStatusCodesValueSet of the FHIR code s, i.e. "completed".
+func ParseImmunizationStatusCodesValueSet(s string) (vspb.ImmunizationStatusCodesValueSet_Value, error) {
+	return Parse[vspb.ImmunizationStatusCodesValueSet_Value](s)
+}
+
+// ImmunizationStatusCodesValueSetString returns the FHIR code of v, or "" if it is not valid.
+func ImmunizationStatusCodesValueSetString(v vspb.ImmunizationStatusCodesValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidImmunizationStatusCodesValueSet reports whether v is a code of ImmunizationStatusCodesValueSet.
+func IsValidImmunizationStatusCodesValueSet(v vspb.ImmunizationStatusCodesValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseLDLCodesValueSet returns the LDLCodesValueSet of the FHIR code s, i.e. "18262-6".
+func ParseLDLCodesValueSet(s string) (vspb.LDLCodesValueSet_Value, error) {
+	return Parse[vspb.LDLCodesValueSet_Value](s)
+}
+
+// LDLCodesValueSetString returns the FHIR code of v, or "" if it is not valid.
+func LDLCodesValueSetString(v vspb.LDLCodesValueSet_Value) string { return Code(v) }
+
+// IsValidLDLCodesValueSet reports whether v is a code of LDLCodesValueSet.
+func IsValidLDLCodesValueSet(v vspb.LDLCodesValueSet_Value) bool { return Valid(v) }
+
+// ParseNameRepresentationUseValueSet returns the NameRepresentationUseValueSet of the FHIR code s, i.e. "ABC".
+func ParseNameRepresentationUseValueSet(s string) (vspb.NameRepresentationUseValueSet_Value, error) {
+	return Parse[vspb.NameRepresentationUseValueSet_Value](s)
+}
+
+// NameRepresentationUseValueSetString returns the FHIR code of v, or "" if it is not valid.
+func NameRepresentationUseValueSetString(v vspb.NameRepresentationUseValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidNameRepresentationUseValueSet reports whether v is a code of NameRepresentationUseValueSet.
+func IsValidNameRepresentationUseValueSet(v vspb.NameRepresentationUseValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseParentRelationshipCodesValueSet returns the ParentRelationshipCodesValueSet of the FHIR code s, i.e. "PRN".
+func ParseParentRelationshipCodesValueSet(s string) (vspb.ParentRelationshipCodesValueSet_Value, error) {
+	return Parse[vspb.ParentRelationshipCodesValueSet_Value](s)
+}
+
+// ParentRelationshipCodesValueSetString returns the FHIR code of v, or "" if it is not valid.
+func ParentRelationshipCodesValueSetString(v vspb.ParentRelationshipCodesValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidParentRelationshipCodesValueSet reports whether v is a code of ParentRelationshipCodesValueSet.
+func IsValidParentRelationshipCodesValueSet(v vspb.ParentRelationshipCodesValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParsePostalAddressUseValueSet returns the PostalAddressUseValueSet of the FHIR code s, i.e. "BAD".
+func ParsePostalAddressUseValueSet(s string) (vspb.PostalAddressUseValueSet_Value, error) {
+	return Parse[vspb.PostalAddressUseValueSet_Value](s)
+}
+
+// PostalAddressUseValueSetString returns the FHIR code of v, or "" if it is not valid.
+func PostalAddressUseValueSetString(v vspb.PostalAddressUseValueSet_Value) string { return Code(v) }
+
+// IsValidPostalAddressUseValueSet reports whether v is a code of PostalAddressUseValueSet.
+func IsValidPostalAddressUseValueSet(v vspb.PostalAddressUseValueSet_Value) bool { return Valid(v) }
+
+// ParseProbabilityDistributionTypeValueSet returns the ProbabilityDistributionTypeValueSet of the FHIR code s, i.e. "B".
+func ParseProbabilityDistributionTypeValueSet(s string) (vspb.ProbabilityDistributionTypeValueSet_Value, error) {
+	return Parse[vspb.ProbabilityDistributionTypeValueSet_Value](s)
+}
+
+// ProbabilityDistributionTypeValueSetString returns the FHIR code of v, or "" if it is not valid.
+func ProbabilityDistributionTypeValueSetString(v vspb.ProbabilityDistributionTypeValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidProbabilityDistributionTypeValueSet reports whether v is a code of ProbabilityDistributionTypeValueSet.
+func IsValidProbabilityDistributionTypeValueSet(v vspb.ProbabilityDistributionTypeValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseQuestionnaireResponseModeValueSet returns the QuestionnaireResponseModeValueSet of the FHIR code s, i.e. "ELECTRONIC".
+func ParseQuestionnaireResponseModeValueSet(s string) (vspb.QuestionnaireResponseModeValueSet_Value, error) {
+	return Parse[vspb.QuestionnaireResponseModeValueSet_Value](s)
+}
+
+// QuestionnaireResponseModeValueSetString returns the FHIR code of v, or "" if it is not valid.
+func QuestionnaireResponseModeValueSetString(v vspb.QuestionnaireResponseModeValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidQuestionnaireResponseModeValueSet reports whether v is a code of QuestionnaireResponseModeValueSet.
+func IsValidQuestionnaireResponseModeValueSet(v vspb.QuestionnaireResponseModeValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseSiblingRelationshipCodesValueSet returns the SiblingRelationshipCodesValueSet of the FHIR code s, i.e. "SIB".
+func ParseSiblingRelationshipCodesValueSet(s string) (vspb.SiblingRelationshipCodesValueSet_Value, error) {
+	return Parse[vspb.SiblingRelationshipCodesValueSet_Value](s)
+}
+
+// SiblingRelationshipCodesValueSetString returns the FHIR code of v, or "" if it is not valid.
+func SiblingRelationshipCodesValueSetString(v vspb.SiblingRelationshipCodesValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidSiblingRelationshipCodesValueSet reports whether v is a code of SiblingRelationshipCodesValueSet.
+func IsValidSiblingRelationshipCodesValueSet(v vspb.SiblingRelationshipCodesValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseSystemRestfulInteractionValueSet returns the SystemRestfulInteractionValueSet of the FHIR code s, i.e. "transaction".
+func ParseSystemRestfulInteractionValueSet(s string) (vspb.SystemRestfulInteractionValueSet_Value, error) {
+	return Parse[vspb.SystemRestfulInteractionValueSet_Value](s)
+}
+
+// SystemRestfulInteractionValueSetString returns the FHIR code of v, or "" if it is not valid.
+func SystemRestfulInteractionValueSetString(v vspb.SystemRestfulInteractionValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidSystemRestfulInteractionValueSet reports whether v is a code of SystemRestfulInteractionValueSet.
+func IsValidSystemRestfulInteractionValueSet(v vspb.SystemRestfulInteractionValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseTaskIntentValueSet returns the TaskIntentValueSet of the FHIR code s, i.e. "unknown".
+func ParseTaskIntentValueSet(s string) (vspb.TaskIntentValueSet_Value, error) {
+	return Parse[vspb.TaskIntentValueSet_Value](s)
+}
+
+// TaskIntentValueSetString returns the FHIR code of v, or "" if it is not valid.
+func TaskIntentValueSetString(v vspb.TaskIntentValueSet_Value) string { return Code(v) }
+
+// IsValidTaskIntentValueSet reports whether v is a code of TaskIntentValueSet.
+func IsValidTaskIntentValueSet(v vspb.TaskIntentValueSet_Value) bool { return Valid(v) }
+
+// ParseTemplateStatusCodeValueSet returns the TemplateStatusCodeValueSet of the FHIR code s, i.e. "draft".
+func ParseTemplateStatusCodeValueSet(s string) (vspb.TemplateStatusCodeValueSet_Value, error) {
+	return Parse[vspb.TemplateStatusCodeValueSet_Value](s)
+}
+
+// TemplateStatusCodeValueSetString returns the FHIR code of v, or "" if it is not valid.
+func TemplateStatusCodeValueSetString(v vspb.TemplateStatusCodeValueSet_Value) string { return Code(v) }
+
+// IsValidTemplateStatusCodeValueSet reports whether v is a code of TemplateStatusCodeValueSet.
+func IsValidTemplateStatusCodeValueSet(v vspb.TemplateStatusCodeValueSet_Value) bool { return Valid(v) }
+
+// ParseTypeRestfulInteractionValueSet returns the TypeRestfulInteractionValueSet of the FHIR code s, i.e. "read".
+func ParseTypeRestfulInteractionValueSet(s string) (vspb.TypeRestfulInteractionValueSet_Value, error) {
+	return Parse[vspb.TypeRestfulInteractionValueSet_Value](s)
+}
+
+// TypeRestfulInteractionValueSetString returns the FHIR code of v, or "" if it is not valid.
+func TypeRestfulInteractionValueSetString(v vspb.TypeRestfulInteractionValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidTypeRestfulInteractionValueSet reports whether v is a code of TypeRestfulInteractionValueSet.
+func IsValidTypeRestfulInteractionValueSet(v vspb.TypeRestfulInteractionValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseUnitsOfTimeValueSet returns the UnitsOfTimeValueSet of the FHIR code s, i.e. "s".
+func ParseUnitsOfTimeValueSet(s string) (vspb.UnitsOfTimeValueSet_Value, error) {
+	return Parse[vspb.UnitsOfTimeValueSet_Value](s)
+}
+
+// UnitsOfTimeValueSetString returns the FHIR code of v, or "" if it is not valid.
+func UnitsOfTimeValueSetString(v vspb.UnitsOfTimeValueSet_Value) string { return Code(v) }
+
+// IsValidUnitsOfTimeValueSet reports whether v is a code of UnitsOfTimeValueSet.
+func IsValidUnitsOfTimeValueSet(v vspb.UnitsOfTimeValueSet_Value) bool { return Valid(v) }
+
+// ParseV3ConfidentialityClassificationValueSet returns the V3ConfidentialityClassificationValueSet of the FHIR code s, i.e. "U".
+func ParseV3ConfidentialityClassificationValueSet(s string) (vspb.V3ConfidentialityClassificationValueSet_Value, error) {
+	return Parse[vspb.V3ConfidentialityClassificationValueSet_Value](s)
+}
+
+// V3ConfidentialityClassificationValueSetString returns the FHIR code of v, or "" if it is not valid.
+func V3ConfidentialityClassificationValueSetString(v vspb.V3ConfidentialityClassificationValueSet_Value) string {
+	return Code(v)
+}
+
+// IsValidV3ConfidentialityClassificationValueSet reports whether v is a code of V3ConfidentialityClassificationValueSet.
+func IsValidV3ConfidentialityClassificationValueSet(v vspb.V3ConfidentialityClassificationValueSet_Value) bool {
+	return Valid(v)
+}
+
+// ParseVitalSignsUnitsValueSet returns the VitalSignsUnitsValueSet of the FHIR code s, i.e. "%".
+func ParseVitalSignsUnitsValueSet(s string) (vspb.VitalSignsUnitsValueSet_Value, error) {
+	return Parse[vspb.VitalSignsUnitsValueSet_Value](s)
+}
+
+// VitalSignsUnitsValueSetString returns the FHIR code of v, or "" if it is not valid.
+func VitalSignsUnitsValueSetString(v vspb.VitalSignsUnitsValueSet_Value) string { return Code(v) }
+
+// IsValidVitalSignsUnitsValueSet reports whether v is a code of VitalSignsUnitsValueSet.
+func IsValidVitalSignsUnitsValueSet(v vspb.VitalSignsUnitsValueSet_Value) bool { return Valid(v) }
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command gen generates the per-enum helpers of package codes, for every
+// enum of the R4 code and value set protos.
+//
+// Usage:
+//
+//	go run ./internal/gen -out enums.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+var out = flag.String("out", "", "output file; standard output if empty")
+
+// source is a proto file whose enums get helpers.
+type source struct {
+	file   protoreflect.FileDescriptor
+	alias  string
+	goPath string
+}
+
+var sources = []source{
+	{c4pb.File_proto_google_fhir_proto_r4_core_codes_proto, "c4pb", "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"},
+	{vspb.File_proto_google_fhir_proto_r4_core_valuesets_proto, "vspb", "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"},
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	src, err := generate()
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0644)
+}
+
+// generate returns the formatted source of the helpers of the enums of
+// sources, which are nested in messages named for their code system or
+// value set, i.e. ObservationStatusCode.Value.
+func generate() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`// Code generated by codes/internal/gen. DO NOT EDIT.
+
+package codes
+
+import (
+`)
+	for _, s := range sources {
+		fmt.Fprintf(&b, "\t%s %q\n", s.alias, s.goPath)
+	}
+	b.WriteString(")\n")
+
+	seen := map[string]bool{}
+	for _, s := range sources {
+		msgs := s.file.Messages()
+		for i := 0; i < msgs.Len(); i++ {
+			md := msgs.Get(i)
+			ed := md.Enums().ByName("Value")
+			if ed == nil {
+				continue
+			}
+			name := string(md.Name())
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate enum %s", name)
+			}
+			seen[name] = true
+			typ := fmt.Sprintf("%s.%s_Value", s.alias, name)
+			fmt.Fprintf(&b, `
+// Parse%[1]s returns the %[1]s of the FHIR code s%[3]s.
+func Parse%[1]s(s string) (%[2]s, error) { return Parse[%[2]s](s) }
+
+// %[1]sString returns the FHIR code of v, or "" if it is not valid.
+func %[1]sString(v %[2]s) string { return Code(v) }
+
+// IsValid%[1]s reports whether v is a code of %[1]s.
+func IsValid%[1]s(v %[2]s) bool { return Valid(v) }
+`, name, typ, example(ed))
+		}
+	}
+	return format.Source(b.Bytes())
+}
+
+// example returns the first code of ed as an example for doc comments.
+func example(ed protoreflect.EnumDescriptor) string {
+	values := ed.Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		if v.Number() == 0 {
+			continue
+		}
+		c := strings.ReplaceAll(strings.ToLower(string(v.Name())), "_", "-")
+		if orig := proto.GetExtension(v.Options(), apb.E_FhirOriginalCode).(string); orig != "" {
+			c = orig
+		}
+		if strings.ContainsAny(c, "\n*/") {
+			return ""
+		}
+		return fmt.Sprintf(", i.e. %q", c)
+	}
+	return ""
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conceptmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Rule remaps the coded values found at an element path through a ConceptMap.
+type Rule struct {
+	// Path is the element path of the coded element, i.e. "Observation.code".
+	// It must resolve to CodeableConcept, Coding or code elements.
+	Path string `json:"path"`
+	// ConceptMap is the canonical URL of the ConceptMap used for translation.
+	ConceptMap string `json:"conceptMap"`
+	// System is the code system of bare code elements, which carry no system
+	// of their own. It also restricts which codings of a CodeableConcept are
+	// translated; if empty, all codings in a source system of the ConceptMap
+	// are.
+	System string `json:"system,omitempty"`
+	// Replace drops the source codings of a CodeableConcept in favor of their
+	// translations. By default translations are appended. Coding and code
+	// elements are always replaced.
+	Replace bool `json:"replace,omitempty"`
+}
+
+// ParseRules parses a JSON array of rules, i.e.
+//
+//	[{"path": "Observation.code", "conceptMap": "http://example.com/cm"}]
+func ParseRules(data []byte) ([]Rule, error) {
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing remap rules: %w", err)
+	}
+	return rules, nil
+}
+
+// UnmappedCode is a coded value for which a ConceptMap had no translation.
+type UnmappedCode struct {
+	ResourceType string
+	ResourceID   string
+	ElementPath  string
+	ConceptMap   string
+	System       string
+	Code         string
+}
+
+// A DeadLetterReporter receives the codes a Remapper could not translate.
+//
+// If the code can be satisfactorily reported it should return nil, allowing
+// remapping to proceed. Returning an error aborts remapping of the resource.
+type DeadLetterReporter interface {
+	ReportUnmapped(u *UnmappedCode) error
+}
+
+// DeadLetterReport is a DeadLetterReporter that stores all unmapped codes.
+// It is safe for concurrent use.
+type DeadLetterReport struct {
+	mu       sync.Mutex
+	Unmapped []*UnmappedCode
+}
+
+// NewDeadLetterReport returns an empty DeadLetterReport.
+func NewDeadLetterReport() *DeadLetterReport {
+	return &DeadLetterReport{}
+}
+
+// ReportUnmapped stores u in the report.
+func (r *DeadLetterReport) ReportUnmapped(u *UnmappedCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Unmapped = append(r.Unmapped, u)
+	return nil
+}
+
+// Remapper applies a set of Rules to resources.
+type Remapper struct {
+	t *Translator
+	// rules are keyed by the resource type the rule path is rooted at.
+	rules map[string][]Rule
+}
+
+// NewRemapper returns a Remapper applying rules with the ConceptMaps known to
+// t. It returns an error if a rule has an invalid path or refers to an
+// unknown ConceptMap.
+func NewRemapper(t *Translator, rules ...Rule) (*Remapper, error) {
+	r := &Remapper{t: t, rules: map[string][]Rule{}}
+	for _, rule := range rules {
+		rt, _, err := elementpath.Split(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !t.Has(rule.ConceptMap) {
+			return nil, fmt.Errorf("rule for %s: unknown ConceptMap %q", rule.Path, rule.ConceptMap)
+		}
+		r.rules[rt] = append(r.rules[rt], rule)
+	}
+	return r, nil
+}
+
+// Remap translates the coded elements of the resource msg in place, which may
+// be a resource or a ContainedResource of any FHIR version. Codes without a
+// translation are left untouched and reported to dl, which may be nil.
+func (r *Remapper) Remap(msg proto.Message, dl DeadLetterReporter) error {
+	rt := elementpath.ResourceType(msg)
+	if rt == "" {
+		return nil
+	}
+	res := elementpath.Unwrap(msg).ProtoReflect()
+	id := getString(res, "id")
+	for _, rule := range r.rules[rt] {
+		rule := rule
+		err := elementpath.Walk(msg, rule.Path, func(path string, elem protoreflect.Message) error {
+			report := func(system, code string) error {
+				if dl == nil {
+					return nil
+				}
+				return dl.ReportUnmapped(&UnmappedCode{
+					ResourceType: rt,
+					ResourceID:   id,
+					ElementPath:  path,
+					ConceptMap:   rule.ConceptMap,
+					System:       system,
+					Code:         code,
+				})
+			}
+			return r.remapElement(rule, elem, report)
+		})
+		if err != nil {
+			return fmt.Errorf("remapping %s: %w", rule.Path, err)
+		}
+	}
+	return nil
+}
+
+func (r *Remapper) remapElement(rule Rule, elem protoreflect.Message, report func(system, code string) error) error {
+	switch elem.Descriptor().Name() {
+	case "CodeableConcept":
+		return r.remapCodeableConcept(rule, elem, report)
+	case "Coding":
+		system, code := getString(elem, "system"), getString(elem, "code")
+		if code == "" || !r.t.HasSource(rule.ConceptMap, system) {
+			return nil
+		}
+		matches, err := r.t.Translate(rule.ConceptMap, system, code)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return report(system, code)
+		}
+		setCoding(elem, matches[0])
+		return nil
+	}
+	vf := elem.Descriptor().Fields().ByName("value")
+	if !elementpath.IsPrimitive(elem.Descriptor()) || vf == nil || vf.Kind() != protoreflect.StringKind {
+		return fmt.Errorf("cannot remap element of type %s", elem.Descriptor().Name())
+	}
+	code := elem.Get(vf).String()
+	if code == "" {
+		return nil
+	}
+	matches, err := r.t.Translate(rule.ConceptMap, rule.System, code)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return report(rule.System, code)
+	}
+	elem.Set(vf, protoreflect.ValueOfString(matches[0].Code))
+	return nil
+}
+
+func (r *Remapper) remapCodeableConcept(rule Rule, cc protoreflect.Message, report func(system, code string) error) error {
+	fd := cc.Descriptor().Fields().ByName("coding")
+	if !cc.Has(fd) {
+		return nil
+	}
+	codings := cc.Mutable(fd).List()
+	seen := map[string]bool{}
+	for i := 0; i < codings.Len(); i++ {
+		c := codings.Get(i).Message()
+		seen[getString(c, "system")+"|"+getString(c, "code")] = true
+	}
+	var kept, added []protoreflect.Value
+	for i := 0; i < codings.Len(); i++ {
+		coding := codings.Get(i)
+		system, code := getString(coding.Message(), "system"), getString(coding.Message(), "code")
+		if code == "" || (rule.System != "" && system != rule.System) || !r.t.HasSource(rule.ConceptMap, system) {
+			kept = append(kept, coding)
+			continue
+		}
+		matches, err := r.t.Translate(rule.ConceptMap, system, code)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			if err := report(system, code); err != nil {
+				return err
+			}
+			kept = append(kept, coding)
+			continue
+		}
+		if !rule.Replace {
+			kept = append(kept, coding)
+		}
+		for _, m := range matches {
+			if key := m.System + "|" + m.Code; !seen[key] {
+				seen[key] = true
+				nc := codings.NewElement()
+				setCoding(nc.Message(), m)
+				added = append(added, nc)
+			}
+		}
+	}
+	codings.Truncate(0)
+	for _, v := range append(kept, added...) {
+		codings.Append(v)
+	}
+	return nil
+}
+
+func setCoding(coding protoreflect.Message, m Match) {
+	setString(coding, "system", m.System)
+	setString(coding, "version", m.Version)
+	setString(coding, "code", m.Code)
+	setString(coding, "display", m.Display)
+}
+
+// getString returns the value of the string-valued FHIR primitive in field.
+func getString(m protoreflect.Message, field protoreflect.Name) string {
+	fd := m.Descriptor().Fields().ByName(field)
+	if fd == nil || fd.Message() == nil || !m.Has(fd) {
+		return ""
+	}
+	v := m.Get(fd).Message()
+	vf := v.Descriptor().Fields().ByName("value")
+	if vf == nil || vf.Kind() != protoreflect.StringKind {
+		return ""
+	}
+	return v.Get(vf).String()
+}
+
+// setString sets the string-valued FHIR primitive in field, clearing it if
+// val is empty.
+func setString(m protoreflect.Message, field protoreflect.Name, val string) {
+	fd := m.Descriptor().Fields().ByName(field)
+	if val == "" {
+		m.Clear(fd)
+		return
+	}
+	v := m.NewField(fd).Message()
+	v.Set(v.Descriptor().Fields().ByName("value"), protoreflect.ValueOfString(val))
+	m.Set(fd, protoreflect.ValueOfMessage(v))
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conceptmap
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+)
+
+func coding(system, code, display string) *d4pb.Coding {
+	c := &d4pb.Coding{
+		System: &d4pb.Uri{Value: system},
+		Code:   &d4pb.Code{Value: code},
+	}
+	if display != "" {
+		c.Display = &d4pb.String{Value: display}
+	}
+	return c
+}
+
+func observation(codings ...*d4pb.Coding) *obspb.Observation {
+	return &obspb.Observation{
+		Id:   &d4pb.Id{Value: "obs1"},
+		Code: &d4pb.CodeableConcept{Coding: codings},
+	}
+}
+
+func TestRemap(t *testing.T) {
+	tests := []struct {
+		name         string
+		rule         Rule
+		in           *obspb.Observation
+		want         *obspb.Observation
+		wantUnmapped []*UnmappedCode
+	}{
+		{
+			name: "append",
+			rule: Rule{Path: "Observation.code", ConceptMap: labMap},
+			in:   observation(coding(localSystem, "GLU", "")),
+			want: observation(coding(localSystem, "GLU", ""), coding(loinc, "2345-7", "Glucose")),
+		},
+		{
+			name: "replace",
+			rule: Rule{Path: "Observation.code", ConceptMap: labMap, Replace: true},
+			in:   observation(coding(localSystem, "GLU", "")),
+			want: observation(coding(loinc, "2345-7", "Glucose")),
+		},
+		{
+			name: "already translated",
+			rule: Rule{Path: "Observation.code", ConceptMap: labMap},
+			in:   observation(coding(localSystem, "GLU", ""), coding(loinc, "2345-7", "Glucose")),
+			want: observation(coding(localSystem, "GLU", ""), coding(loinc, "2345-7", "Glucose")),
+		},
+		{
+			name: "coding",
+			rule: Rule{Path: "Observation.code.coding", ConceptMap: labMap},
+			in:   observation(coding(localSystem, "GLU", "")),
+			want: observation(coding(loinc, "2345-7", "Glucose")),
+		},
+		{
+			name: "code",
+			rule: Rule{Path: "Observation.code.coding.code", ConceptMap: labMap, System: localSystem},
+			in:   observation(coding(localSystem, "GLU", "")),
+			want: observation(coding(localSystem, "2345-7", "")),
+		},
+		{
+			name: "other system untouched",
+			rule: Rule{Path: "Observation.code", ConceptMap: labMap, System: localSystem},
+			in:   observation(coding(loinc, "GLU", "")),
+			want: observation(coding(loinc, "GLU", "")),
+		},
+		{
+			name: "unmapped",
+			rule: Rule{Path: "Observation.code", ConceptMap: labMap, Replace: true},
+			in:   observation(coding(localSystem, "CL", "")),
+			want: observation(coding(localSystem, "CL", "")),
+			wantUnmapped: []*UnmappedCode{{
+				ResourceType: "Observation",
+				ResourceID:   "obs1",
+				ElementPath:  "Observation.code",
+				ConceptMap:   labMap,
+				System:       localSystem,
+				Code:         "CL",
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tr, err := NewTranslator(testMaps()...)
+			if err != nil {
+				t.Fatalf("NewTranslator() returned unexpected error: %v", err)
+			}
+			r, err := NewRemapper(tr, test.rule)
+			if err != nil {
+				t.Fatalf("NewRemapper() returned unexpected error: %v", err)
+			}
+			report := NewDeadLetterReport()
+			cr := &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: test.in}}
+			if err := r.Remap(cr, report); err != nil {
+				t.Fatalf("Remap() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, cr.GetObservation(), protocmp.Transform()); diff != "" {
+				t.Errorf("Remap() produced unexpected resource, diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantUnmapped, report.Unmapped); diff != "" {
+				t.Errorf("Remap() reported unexpected unmapped codes, diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewRemapper_Errors(t *testing.T) {
+	tr, err := NewTranslator(testMaps()...)
+	if err != nil {
+		t.Fatalf("NewTranslator() returned unexpected error: %v", err)
+	}
+	for _, rule := range []Rule{
+		{Path: "Observation..code", ConceptMap: labMap},
+		{Path: "Observation.code", ConceptMap: "http://example.com/unknown"},
+	} {
+		if _, err := NewRemapper(tr, rule); err == nil {
+			t.Errorf("NewRemapper(%v) succeeded, want error", rule)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	got, err := ParseRules([]byte(`[{"path": "Observation.code", "conceptMap": "http://example.com/cm", "replace": true}]`))
+	if err != nil {
+		t.Fatalf("ParseRules() returned unexpected error: %v", err)
+	}
+	want := []Rule{{Path: "Observation.code", ConceptMap: "http://example.com/cm", Replace: true}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseRules() returned unexpected rules, diff (-want +got):\n%s", diff)
+	}
+}
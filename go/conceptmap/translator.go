@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conceptmap translates coded values using FHIR R4 ConceptMap
+// resources, and remaps the coded elements of resources during conversion or
+// ingestion.
+package conceptmap
+
+import (
+	"fmt"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	cmpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/concept_map_go_proto"
+)
+
+// maxMapDepth bounds how many ConceptMaps can be chained through
+// group.unmapped.url before translation gives up.
+const maxMapDepth = 8
+
+// Match is a single translation result.
+type Match struct {
+	// ConceptMap is the canonical URL of the ConceptMap that produced the match.
+	ConceptMap  string
+	System      string
+	Version     string
+	Code        string
+	Display     string
+	Equivalence cpb.ConceptMapEquivalenceCode_Value
+}
+
+// Translator translates codes using a fixed set of ConceptMaps. It is safe for
+// concurrent use once constructed.
+type Translator struct {
+	maps map[string]*cmpb.ConceptMap
+}
+
+// NewTranslator returns a Translator over the given ConceptMaps. ConceptMaps
+// can be referred to either by url or by url|version; when several versions
+// of the same map are provided, the unversioned url refers to the last one.
+func NewTranslator(maps ...*cmpb.ConceptMap) (*Translator, error) {
+	t := &Translator{maps: map[string]*cmpb.ConceptMap{}}
+	for _, cm := range maps {
+		url := cm.GetUrl().GetValue()
+		if url == "" {
+			return nil, fmt.Errorf("ConceptMap %q has no url", cm.GetId().GetValue())
+		}
+		t.maps[url] = cm
+		if v := cm.GetVersion().GetValue(); v != "" {
+			t.maps[url+"|"+v] = cm
+		}
+	}
+	return t, nil
+}
+
+// Has returns true iff the Translator knows the ConceptMap with the given
+// canonical URL.
+func (t *Translator) Has(url string) bool {
+	_, ok := t.maps[url]
+	return ok
+}
+
+// HasSource returns true iff the ConceptMap identified by url has a group
+// translating codes from system. Groups without a source system match every
+// system.
+func (t *Translator) HasSource(url, system string) bool {
+	for _, g := range t.maps[url].GetGroup() {
+		if src := g.GetSource().GetValue(); src == "" || src == system {
+			return true
+		}
+	}
+	return false
+}
+
+// Translate returns the translations of code in system provided by the
+// ConceptMap identified by url. An empty system matches every group of the
+// map. Targets marked unmatched or disjoint are not returned; an empty result
+// means that the code is unmapped.
+func (t *Translator) Translate(url, system, code string) ([]Match, error) {
+	return t.translate(url, system, code, 0)
+}
+
+func (t *Translator) translate(url, system, code string, depth int) ([]Match, error) {
+	if depth > maxMapDepth {
+		return nil, fmt.Errorf("ConceptMap %q: unmapped chain exceeds %d maps", url, maxMapDepth)
+	}
+	cm, ok := t.maps[url]
+	if !ok {
+		return nil, fmt.Errorf("unknown ConceptMap %q", url)
+	}
+	var matches []Match
+	for _, g := range cm.GetGroup() {
+		if system != "" && g.GetSource().GetValue() != "" && g.GetSource().GetValue() != system {
+			continue
+		}
+		found := false
+		for _, e := range g.GetElement() {
+			if e.GetCode().GetValue() != code {
+				continue
+			}
+			found = true
+			for _, tgt := range e.GetTarget() {
+				eq := tgt.GetEquivalence().GetValue()
+				if eq == cpb.ConceptMapEquivalenceCode_UNMATCHED || eq == cpb.ConceptMapEquivalenceCode_DISJOINT {
+					continue
+				}
+				matches = append(matches, Match{
+					ConceptMap:  url,
+					System:      g.GetTarget().GetValue(),
+					Version:     g.GetTargetVersion().GetValue(),
+					Code:        tgt.GetCode().GetValue(),
+					Display:     tgt.GetDisplay().GetValue(),
+					Equivalence: eq,
+				})
+			}
+		}
+		if found || g.GetUnmapped() == nil {
+			continue
+		}
+		um := g.GetUnmapped()
+		switch um.GetMode().GetValue() {
+		case cpb.ConceptMapGroupUnmappedModeCode_PROVIDED:
+			matches = append(matches, Match{
+				ConceptMap:  url,
+				System:      g.GetTarget().GetValue(),
+				Version:     g.GetTargetVersion().GetValue(),
+				Code:        code,
+				Equivalence: cpb.ConceptMapEquivalenceCode_EQUAL,
+			})
+		case cpb.ConceptMapGroupUnmappedModeCode_FIXED:
+			matches = append(matches, Match{
+				ConceptMap:  url,
+				System:      g.GetTarget().GetValue(),
+				Version:     g.GetTargetVersion().GetValue(),
+				Code:        um.GetCode().GetValue(),
+				Display:     um.GetDisplay().GetValue(),
+				Equivalence: cpb.ConceptMapEquivalenceCode_EQUIVALENT,
+			})
+		case cpb.ConceptMapGroupUnmappedModeCode_OTHER_MAP:
+			other, err := t.translate(um.GetUrl().GetValue(), system, code, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, other...)
+		}
+	}
+	return matches, nil
+}
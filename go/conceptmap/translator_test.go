@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conceptmap
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cmpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/concept_map_go_proto"
+)
+
+const (
+	localSystem = "http://example.com/lab-codes"
+	loinc       = "http://loinc.org"
+	labMap      = "http://example.com/ConceptMap/lab"
+	fallbackMap = "http://example.com/ConceptMap/fallback"
+)
+
+func target(code, display string, eq cpb.ConceptMapEquivalenceCode_Value) *cmpb.ConceptMap_Group_SourceElement_TargetElement {
+	return &cmpb.ConceptMap_Group_SourceElement_TargetElement{
+		Code:        &d4pb.Code{Value: code},
+		Display:     &d4pb.String{Value: display},
+		Equivalence: &cmpb.ConceptMap_Group_SourceElement_TargetElement_EquivalenceCode{Value: eq},
+	}
+}
+
+func element(code string, targets ...*cmpb.ConceptMap_Group_SourceElement_TargetElement) *cmpb.ConceptMap_Group_SourceElement {
+	return &cmpb.ConceptMap_Group_SourceElement{Code: &d4pb.Code{Value: code}, Target: targets}
+}
+
+func testMaps() []*cmpb.ConceptMap {
+	return []*cmpb.ConceptMap{
+		{
+			Url:     &d4pb.Uri{Value: labMap},
+			Version: &d4pb.String{Value: "1"},
+			Group: []*cmpb.ConceptMap_Group{{
+				Source: &d4pb.Uri{Value: localSystem},
+				Target: &d4pb.Uri{Value: loinc},
+				Element: []*cmpb.ConceptMap_Group_SourceElement{
+					element("GLU", target("2345-7", "Glucose", cpb.ConceptMapEquivalenceCode_EQUIVALENT)),
+					element("NA",
+						target("2951-2", "Sodium", cpb.ConceptMapEquivalenceCode_EQUIVALENT),
+						target("2947-0", "Sodium, blood", cpb.ConceptMapEquivalenceCode_WIDER)),
+					element("XX", target("", "", cpb.ConceptMapEquivalenceCode_UNMATCHED)),
+				},
+				Unmapped: &cmpb.ConceptMap_Group_Unmapped{
+					Mode: &cmpb.ConceptMap_Group_Unmapped_ModeCode{Value: cpb.ConceptMapGroupUnmappedModeCode_OTHER_MAP},
+					Url:  &d4pb.Canonical{Value: fallbackMap},
+				},
+			}},
+		},
+		{
+			Url: &d4pb.Uri{Value: fallbackMap},
+			Group: []*cmpb.ConceptMap_Group{{
+				Source: &d4pb.Uri{Value: localSystem},
+				Target: &d4pb.Uri{Value: loinc},
+				Element: []*cmpb.ConceptMap_Group_SourceElement{
+					element("K", target("2823-3", "Potassium", cpb.ConceptMapEquivalenceCode_EQUIVALENT)),
+				},
+			}},
+		},
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tr, err := NewTranslator(testMaps()...)
+	if err != nil {
+		t.Fatalf("NewTranslator() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		name   string
+		url    string
+		system string
+		code   string
+		want   []Match
+	}{
+		{
+			name:   "single target",
+			url:    labMap,
+			system: localSystem,
+			code:   "GLU",
+			want:   []Match{{ConceptMap: labMap, System: loinc, Code: "2345-7", Display: "Glucose", Equivalence: cpb.ConceptMapEquivalenceCode_EQUIVALENT}},
+		},
+		{
+			name: "multiple targets, any system",
+			url:  labMap + "|1",
+			code: "NA",
+			want: []Match{
+				{ConceptMap: labMap + "|1", System: loinc, Code: "2951-2", Display: "Sodium", Equivalence: cpb.ConceptMapEquivalenceCode_EQUIVALENT},
+				{ConceptMap: labMap + "|1", System: loinc, Code: "2947-0", Display: "Sodium, blood", Equivalence: cpb.ConceptMapEquivalenceCode_WIDER},
+			},
+		},
+		{
+			name:   "unmatched target",
+			url:    labMap,
+			system: localSystem,
+			code:   "XX",
+		},
+		{
+			name:   "other map",
+			url:    labMap,
+			system: localSystem,
+			code:   "K",
+			want:   []Match{{ConceptMap: fallbackMap, System: loinc, Code: "2823-3", Display: "Potassium", Equivalence: cpb.ConceptMapEquivalenceCode_EQUIVALENT}},
+		},
+		{
+			name:   "unmapped",
+			url:    labMap,
+			system: localSystem,
+			code:   "CL",
+		},
+		{
+			name:   "other system",
+			url:    labMap,
+			system: "http://example.com/other",
+			code:   "GLU",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := tr.Translate(test.url, test.system, test.code)
+			if err != nil {
+				t.Fatalf("Translate() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Translate() returned unexpected matches, diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTranslate_UnmappedModes(t *testing.T) {
+	group := func(mode cpb.ConceptMapGroupUnmappedModeCode_Value) *cmpb.ConceptMap {
+		return &cmpb.ConceptMap{
+			Url: &d4pb.Uri{Value: labMap},
+			Group: []*cmpb.ConceptMap_Group{{
+				Target: &d4pb.Uri{Value: loinc},
+				Unmapped: &cmpb.ConceptMap_Group_Unmapped{
+					Mode: &cmpb.ConceptMap_Group_Unmapped_ModeCode{Value: mode},
+					Code: &d4pb.Code{Value: "fixed-code"},
+				},
+			}},
+		}
+	}
+	tests := []struct {
+		mode cpb.ConceptMapGroupUnmappedModeCode_Value
+		want string
+	}{
+		{cpb.ConceptMapGroupUnmappedModeCode_PROVIDED, "GLU"},
+		{cpb.ConceptMapGroupUnmappedModeCode_FIXED, "fixed-code"},
+	}
+	for _, test := range tests {
+		t.Run(test.mode.String(), func(t *testing.T) {
+			tr, err := NewTranslator(group(test.mode))
+			if err != nil {
+				t.Fatalf("NewTranslator() returned unexpected error: %v", err)
+			}
+			got, err := tr.Translate(labMap, localSystem, "GLU")
+			if err != nil {
+				t.Fatalf("Translate() returned unexpected error: %v", err)
+			}
+			if len(got) != 1 || got[0].Code != test.want {
+				t.Errorf("Translate() = %v, want a single match with code %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTranslate_Errors(t *testing.T) {
+	if _, err := NewTranslator(&cmpb.ConceptMap{}); err == nil {
+		t.Errorf("NewTranslator() with a map without url succeeded, want error")
+	}
+	tr, err := NewTranslator(testMaps()...)
+	if err != nil {
+		t.Fatalf("NewTranslator() returned unexpected error: %v", err)
+	}
+	if _, err := tr.Translate("http://example.com/unknown", "", "GLU"); err == nil {
+		t.Errorf("Translate() with unknown map succeeded, want error")
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package consent enforces patient privacy Consents on the resources a
+// server returns.
+//
+// A Filter decides, for every resource of a response, whether the active
+// Consents of the patient the resource belongs to permit its disclosure to
+// the requester, from the type, period, actor and class of their
+// provisions. Resources that are not permitted are removed from the
+// response or, if a masking rule set is configured, de-identified with it.
+// Middleware plugs a Filter into any net/http server.
+package consent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/fhir/go/deid"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/consent_go_proto"
+)
+
+const (
+	// resourceTypesSystem is the system of provision classes naming a
+	// resource type.
+	resourceTypesSystem = "http://hl7.org/fhir/resource-types"
+	// securityLabelSystem is the system of the labels marking redacted and
+	// masked content.
+	securityLabelSystem = "http://terminology.hl7.org/CodeSystem/v3-ObservationValue"
+)
+
+// Requester is the party a response is disclosed to.
+type Requester struct {
+	// Reference is the relative reference of the requester, i.e.
+	// "Practitioner/123", matched against the references of provision
+	// actors.
+	Reference string
+	// Roles are the roles the requester acts in, matched against the roles
+	// of provision actors without a reference.
+	Roles []*d4pb.Coding
+}
+
+// Source returns the Consents of a patient, given as a relative reference,
+// i.e. "Patient/123".
+type Source func(ctx context.Context, patient string) ([]*cpb.Consent, error)
+
+// Consents returns a Source serving a fixed set of Consents.
+func Consents(consents ...*cpb.Consent) (Source, error) {
+	byPatient := map[string][]*cpb.Consent{}
+	for _, c := range consents {
+		patient := referenceString(c.GetPatient())
+		if patient == "" {
+			return nil, fmt.Errorf("consent %q has no patient", c.GetId().GetValue())
+		}
+		byPatient[patient] = append(byPatient[patient], c)
+	}
+	return func(_ context.Context, patient string) ([]*cpb.Consent, error) {
+		return byPatient[patient], nil
+	}, nil
+}
+
+// Options configures a Filter.
+type Options struct {
+	// Mask, if set, de-identifies the resources the Consents do not permit,
+	// which are otherwise removed.
+	Mask *deid.Deidentifier
+	// Now returns the time provision periods are evaluated at. time.Now is
+	// used if it is nil.
+	Now func() time.Time
+}
+
+// Filter removes or masks the resources the Consents of their patients do
+// not permit. It is safe for concurrent use if its Source is.
+type Filter struct {
+	source Source
+	opts   Options
+}
+
+// NewFilter returns a Filter enforcing the Consents of source.
+func NewFilter(source Source, opts Options) *Filter {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Filter{source: source, opts: opts}
+}
+
+// Permitted reports whether the Consents of the patient res belongs to
+// permit its disclosure to r. Resources that belong to no patient, and
+// those of patients without active Consents, are permitted. A resource is
+// not permitted if any active Consent denies it.
+//
+// Within a Consent, the type of the root provision is the base decision
+// and nested provisions are exceptions to the decision of their parent;
+// the last nested provision that applies wins. A nested provision without
+// a type denies what its parent permits and the reverse. A provision
+// applies if the current time is within its period, r is one of its
+// actors, and the resource is one of its classes, given as resource types
+// or profile URLs; a provision without actors or classes applies to all.
+func (f *Filter) Permitted(ctx context.Context, res proto.Message, r Requester) (bool, error) {
+	res = elementpath.Unwrap(res)
+	if res == nil {
+		return false, fmt.Errorf("no resource")
+	}
+	m := res.ProtoReflect()
+	patient := elementpath.PatientOf(m)
+	if patient == "" {
+		return true, nil
+	}
+	consents, err := f.source(ctx, patient)
+	if err != nil {
+		return false, fmt.Errorf("fetching consents of %s: %w", patient, err)
+	}
+	t := target{
+		resourceType: string(m.Descriptor().Name()),
+		profiles:     profiles(m),
+		requester:    r,
+		now:          f.opts.Now(),
+	}
+	for _, c := range consents {
+		if c.GetStatus().GetValue() != c4pb.ConsentStateCode_ACTIVE {
+			continue
+		}
+		if permit, ok := decide(c.GetProvision(), true, t); ok && !permit {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Resource applies f to res for r. It reports whether res is to be
+// disclosed: resources that are not permitted are masked and disclosed if
+// f has a masking rule set, and withheld otherwise.
+func (f *Filter) Resource(ctx context.Context, res proto.Message, r Requester) (bool, error) {
+	ok, err := f.Permitted(ctx, res, r)
+	if err != nil || ok {
+		return ok, err
+	}
+	if f.opts.Mask == nil {
+		return false, nil
+	}
+	if err := f.opts.Mask.Resource(res); err != nil {
+		return false, fmt.Errorf("masking: %w", err)
+	}
+	label(elementpath.Unwrap(res).ProtoReflect(), "MASKED")
+	return true, nil
+}
+
+// Bundle applies f to the resources of the entries of b for r. Entries
+// whose resource is withheld are removed, the total of b is reduced by the
+// number of search matches removed, and b is labeled as redacted.
+func (f *Filter) Bundle(ctx context.Context, b *r4pb.Bundle, r Requester) error {
+	entries := b.GetEntry()[:0]
+	removed := 0
+	for i, e := range b.GetEntry() {
+		if e.GetResource() == nil || elementpath.Unwrap(e.GetResource()) == nil {
+			entries = append(entries, e)
+			continue
+		}
+		ok, err := f.Resource(ctx, e.GetResource(), r)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		if ok {
+			entries = append(entries, e)
+			continue
+		}
+		if mode := e.GetSearch().GetMode().GetValue(); mode == c4pb.SearchEntryModeCode_INVALID_UNINITIALIZED || mode == c4pb.SearchEntryModeCode_MATCH {
+			removed++
+		}
+	}
+	if len(entries) == len(b.GetEntry()) {
+		return nil
+	}
+	for i := len(entries); i < len(b.Entry); i++ {
+		b.Entry[i] = nil
+	}
+	b.Entry = entries
+	if b.GetTotal() != nil && removed > 0 {
+		if int(b.Total.Value) < removed {
+			b.Total.Value = 0
+		} else {
+			b.Total.Value -= uint32(removed)
+		}
+	}
+	label(b.ProtoReflect(), "REDACTED")
+	return nil
+}
+
+// target is what a provision is evaluated for.
+type target struct {
+	resourceType string
+	profiles     []string
+	requester    Requester
+	now          time.Time
+}
+
+// decide returns the decision of p, permit or deny, for t, and whether p
+// applies to t at all. base is the decision p makes if it has no type.
+func decide(p *cpb.Consent_Provision, base bool, t target) (bool, bool) {
+	if p == nil || !applies(p, t) {
+		return false, false
+	}
+	permit := base
+	switch p.GetType().GetValue() {
+	case c4pb.ConsentProvisionTypeCode_PERMIT:
+		permit = true
+	case c4pb.ConsentProvisionTypeCode_DENY:
+		permit = false
+	}
+	decision := permit
+	for _, nested := range p.GetProvision() {
+		if d, ok := decide(nested, !permit, t); ok {
+			decision = d
+		}
+	}
+	return decision, true
+}
+
+// applies reports whether the period, actors and classes of p cover t.
+func applies(p *cpb.Consent_Provision, t target) bool {
+	return inPeriod(p.GetPeriod(), t.now) && hasActor(p.GetActor(), t.requester) && hasClass(p.GetClassValue(), t)
+}
+
+func inPeriod(p *d4pb.Period, now time.Time) bool {
+	if s := p.GetStart(); s != nil && now.Before(time.UnixMicro(s.GetValueUs())) {
+		return false
+	}
+	if e := p.GetEnd(); e != nil && !now.Before(periodEnd(e)) {
+		return false
+	}
+	return true
+}
+
+// periodEnd returns the instant after the last one dt covers at its
+// precision, i.e. the first day of 2021 for an end of 2020.
+func periodEnd(dt *d4pb.DateTime) time.Time {
+	t := time.UnixMicro(dt.GetValueUs()).UTC()
+	switch dt.GetPrecision() {
+	case d4pb.DateTime_YEAR:
+		return t.AddDate(1, 0, 0)
+	case d4pb.DateTime_MONTH:
+		return t.AddDate(0, 1, 0)
+	case d4pb.DateTime_DAY:
+		return t.AddDate(0, 0, 1)
+	case d4pb.DateTime_SECOND:
+		return t.Add(time.Second)
+	case d4pb.DateTime_MILLISECOND:
+		return t.Add(time.Millisecond)
+	}
+	return t.Add(time.Microsecond)
+}
+
+func hasActor(actors []*cpb.Consent_Provision_ProvisionActor, r Requester) bool {
+	if len(actors) == 0 {
+		return true
+	}
+	for _, a := range actors {
+		if a.GetReference() != nil {
+			if ref := referenceString(a.GetReference()); ref != "" && ref == r.Reference {
+				return true
+			}
+			continue
+		}
+		for _, c := range a.GetRole().GetCoding() {
+			for _, role := range r.Roles {
+				if c.GetSystem().GetValue() == role.GetSystem().GetValue() && c.GetCode().GetValue() == role.GetCode().GetValue() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func hasClass(classes []*d4pb.Coding, t target) bool {
+	if len(classes) == 0 {
+		return true
+	}
+	for _, c := range classes {
+		code := c.GetCode().GetValue()
+		switch c.GetSystem().GetValue() {
+		case resourceTypesSystem:
+			if code == t.resourceType {
+				return true
+			}
+		default:
+			// Profiles and CDA templates have no defined system.
+			for _, p := range t.profiles {
+				if code == p {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// profiles returns the profiles m, a resource, declares in its meta.
+func profiles(m protoreflect.Message) []string {
+	meta, ok := metaOf(m)
+	if !ok {
+		return nil
+	}
+	var ps []string
+	for _, p := range meta.GetProfile() {
+		ps = append(ps, p.GetValue())
+	}
+	return ps
+}
+
+// label adds the security label code to the meta of m, a resource, unless
+// it is there already.
+func label(m protoreflect.Message, code string) {
+	fd := m.Descriptor().Fields().ByName("meta")
+	if fd == nil {
+		return
+	}
+	meta, ok := m.Mutable(fd).Message().Interface().(*d4pb.Meta)
+	if !ok {
+		return
+	}
+	for _, s := range meta.GetSecurity() {
+		if s.GetSystem().GetValue() == securityLabelSystem && s.GetCode().GetValue() == code {
+			return
+		}
+	}
+	meta.Security = append(meta.Security, &d4pb.Coding{
+		System: &d4pb.Uri{Value: securityLabelSystem},
+		Code:   &d4pb.Code{Value: code},
+	})
+}
+
+func metaOf(m protoreflect.Message) (*d4pb.Meta, bool) {
+	fd := m.Descriptor().Fields().ByName("meta")
+	if fd == nil || !m.Has(fd) {
+		return nil, false
+	}
+	meta, ok := m.Get(fd).Message().Interface().(*d4pb.Meta)
+	return meta, ok
+}
+
+// referenceString returns the relative reference or URL ref points to, or
+// "" if it has none.
+func referenceString(ref *d4pb.Reference) string {
+	if ref == nil {
+		return ""
+	}
+	den, err := jsonformat.NewDenormalizedReference(ref)
+	if err != nil {
+		return ""
+	}
+	return den.(*d4pb.Reference).GetUri().GetValue()
+}
@@ -0,0 +1,326 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/deid"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/consent_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/organization_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+var now = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+const (
+	sensitiveProfile = "http://example.com/StructureDefinition/sensitive-observation"
+	doctor           = "Practitioner/dr"
+)
+
+func provision(typ c4pb.ConsentProvisionTypeCode_Value, nested ...*cpb.Consent_Provision) *cpb.Consent_Provision {
+	return &cpb.Consent_Provision{Type: &cpb.Consent_Provision_TypeCode{Value: typ}, Provision: nested}
+}
+
+func class(typ string) *d4pb.Coding {
+	return &d4pb.Coding{System: &d4pb.Uri{Value: resourceTypesSystem}, Code: &d4pb.Code{Value: typ}}
+}
+
+// actor returns a provision actor referring to Practitioner id.
+func actor(id string) *cpb.Consent_Provision_ProvisionActor {
+	return &cpb.Consent_Provision_ProvisionActor{Reference: &d4pb.Reference{
+		Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: id}},
+	}}
+}
+
+func consent(p *cpb.Consent_Provision) *cpb.Consent {
+	return &cpb.Consent{
+		Id:        &d4pb.Id{Value: "c1"},
+		Status:    &cpb.Consent_StatusCode{Value: c4pb.ConsentStateCode_ACTIVE},
+		Patient:   &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Provision: p,
+	}
+}
+
+func observation(profile string) *obspb.Observation {
+	o := &obspb.Observation{
+		Id:      &d4pb.Id{Value: "o1"},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+	}
+	if profile != "" {
+		o.Meta = &d4pb.Meta{Profile: []*d4pb.Canonical{{Value: profile}}}
+	}
+	return o
+}
+
+func filter(t *testing.T, opts Options, consents ...*cpb.Consent) *Filter {
+	t.Helper()
+	src, err := Consents(consents...)
+	if err != nil {
+		t.Fatalf("Consents() returned unexpected error: %v", err)
+	}
+	opts.Now = func() time.Time { return now }
+	return NewFilter(src, opts)
+}
+
+func TestPermitted(t *testing.T) {
+	denyObservations := provision(c4pb.ConsentProvisionTypeCode_PERMIT,
+		&cpb.Consent_Provision{ClassValue: []*d4pb.Coding{class("Observation")}})
+	tests := []struct {
+		name      string
+		consent   *cpb.Consent
+		res       proto.Message
+		requester Requester
+		want      bool
+	}{
+		{
+			name:    "no consent",
+			consent: nil,
+			res:     observation(""),
+			want:    true,
+		},
+		{
+			name:    "deny all",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_DENY)),
+			res:     observation(""),
+			want:    false,
+		},
+		{
+			name:    "deny all applies to the patient",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_DENY)),
+			res:     &ppb.Patient{Id: &d4pb.Id{Value: "p1"}},
+			want:    false,
+		},
+		{
+			name:    "other patient",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_DENY)),
+			res:     &ppb.Patient{Id: &d4pb.Id{Value: "p2"}},
+			want:    true,
+		},
+		{
+			name:    "no patient",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_DENY)),
+			res:     &opb.Organization{Id: &d4pb.Id{Value: "org"}},
+			want:    true,
+		},
+		{
+			name:    "denied class",
+			consent: consent(denyObservations),
+			res:     observation(""),
+			want:    false,
+		},
+		{
+			name:    "other class",
+			consent: consent(denyObservations),
+			res:     &ppb.Patient{Id: &d4pb.Id{Value: "p1"}},
+			want:    true,
+		},
+		{
+			name: "denied profile",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_PERMIT, &cpb.Consent_Provision{
+				ClassValue: []*d4pb.Coding{{Code: &d4pb.Code{Value: sensitiveProfile}}},
+			})),
+			res:  observation(sensitiveProfile),
+			want: false,
+		},
+		{
+			name: "permitted actor",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_DENY, &cpb.Consent_Provision{
+				Actor: []*cpb.Consent_Provision_ProvisionActor{actor("dr")},
+			})),
+			res:       observation(""),
+			requester: Requester{Reference: doctor},
+			want:      true,
+		},
+		{
+			name: "other actor",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_DENY, &cpb.Consent_Provision{
+				Actor: []*cpb.Consent_Provision_ProvisionActor{actor("dr")},
+			})),
+			res:       observation(""),
+			requester: Requester{Reference: "Practitioner/other"},
+			want:      false,
+		},
+		{
+			name: "permitted role",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_DENY, &cpb.Consent_Provision{
+				Actor: []*cpb.Consent_Provision_ProvisionActor{{Role: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{
+					{System: &d4pb.Uri{Value: "urn:roles"}, Code: &d4pb.Code{Value: "emergency"}},
+				}}}},
+			})),
+			res: observation(""),
+			requester: Requester{Roles: []*d4pb.Coding{
+				{System: &d4pb.Uri{Value: "urn:roles"}, Code: &d4pb.Code{Value: "emergency"}},
+			}},
+			want: true,
+		},
+		{
+			name: "expired provision",
+			consent: consent(&cpb.Consent_Provision{
+				Type: &cpb.Consent_Provision_TypeCode{Value: c4pb.ConsentProvisionTypeCode_DENY},
+				Period: &d4pb.Period{End: &d4pb.DateTime{
+					ValueUs: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro(), Precision: d4pb.DateTime_YEAR,
+				}},
+			}),
+			res:  observation(""),
+			want: true,
+		},
+		{
+			name: "period end covers its precision",
+			consent: consent(&cpb.Consent_Provision{
+				Type: &cpb.Consent_Provision_TypeCode{Value: c4pb.ConsentProvisionTypeCode_DENY},
+				Period: &d4pb.Period{End: &d4pb.DateTime{
+					ValueUs: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC).UnixMicro(), Precision: d4pb.DateTime_DAY,
+				}},
+			}),
+			res:  observation(""),
+			want: false,
+		},
+		{
+			name: "nested exception to exception",
+			consent: consent(provision(c4pb.ConsentProvisionTypeCode_PERMIT,
+				&cpb.Consent_Provision{
+					ClassValue: []*d4pb.Coding{class("Observation")},
+					Provision: []*cpb.Consent_Provision{{
+						Actor: []*cpb.Consent_Provision_ProvisionActor{actor("dr")},
+					}},
+				})),
+			res:       observation(""),
+			requester: Requester{Reference: doctor},
+			want:      true,
+		},
+		{
+			name: "inactive consent",
+			consent: func() *cpb.Consent {
+				c := consent(provision(c4pb.ConsentProvisionTypeCode_DENY))
+				c.Status.Value = c4pb.ConsentStateCode_INACTIVE
+				return c
+			}(),
+			res:  observation(""),
+			want: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var consents []*cpb.Consent
+			if test.consent != nil {
+				consents = append(consents, test.consent)
+			}
+			got, err := filter(t, Options{}, consents...).Permitted(context.Background(), test.res, test.requester)
+			if err != nil {
+				t.Fatalf("Permitted() returned unexpected error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("Permitted() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func searchset() *r4pb.Bundle {
+	match := &r4pb.Bundle_Entry_Search{Mode: &r4pb.Bundle_Entry_Search_ModeCode{Value: c4pb.SearchEntryModeCode_MATCH}}
+	include := &r4pb.Bundle_Entry_Search{Mode: &r4pb.Bundle_Entry_Search_ModeCode{Value: c4pb.SearchEntryModeCode_INCLUDE}}
+	return &r4pb.Bundle{
+		Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+		Total: &d4pb.UnsignedInt{Value: 10},
+		Entry: []*r4pb.Bundle_Entry{
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: observation("")}}, Search: match},
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{
+				Id:   &d4pb.Id{Value: "p1"},
+				Name: []*d4pb.HumanName{{Family: &d4pb.String{Value: "Doe"}}},
+			}}}, Search: include},
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Organization{Organization: &opb.Organization{
+				Id: &d4pb.Id{Value: "org"},
+			}}}, Search: include},
+		},
+	}
+}
+
+func TestBundle(t *testing.T) {
+	f := filter(t, Options{}, consent(provision(c4pb.ConsentProvisionTypeCode_DENY)))
+	b := searchset()
+	if err := f.Bundle(context.Background(), b, Requester{}); err != nil {
+		t.Fatalf("Bundle() returned unexpected error: %v", err)
+	}
+	if got := len(b.GetEntry()); got != 1 {
+		t.Fatalf("Bundle() kept %d entries, want 1", got)
+	}
+	if got := b.GetEntry()[0].GetResource().GetOrganization().GetId().GetValue(); got != "org" {
+		t.Errorf("Bundle() kept entry %q, want org", got)
+	}
+	if got := b.GetTotal().GetValue(); got != 9 {
+		t.Errorf("Bundle() set total to %d, want 9", got)
+	}
+	if !hasLabel(b.GetMeta(), "REDACTED") {
+		t.Errorf("Bundle() did not label the bundle REDACTED")
+	}
+}
+
+func TestBundle_Mask(t *testing.T) {
+	mask, err := deid.New(&deid.Config{Rules: []deid.Rule{{Type: "HumanName", Action: deid.Redact}}}, deid.Options{})
+	if err != nil {
+		t.Fatalf("deid.New() returned unexpected error: %v", err)
+	}
+	f := filter(t, Options{Mask: mask}, consent(provision(c4pb.ConsentProvisionTypeCode_DENY)))
+	b := searchset()
+	if err := f.Bundle(context.Background(), b, Requester{}); err != nil {
+		t.Fatalf("Bundle() returned unexpected error: %v", err)
+	}
+	if got := len(b.GetEntry()); got != 3 {
+		t.Fatalf("Bundle() kept %d entries, want 3", got)
+	}
+	p := b.GetEntry()[1].GetResource().GetPatient()
+	if len(p.GetName()) != 0 {
+		t.Errorf("Bundle() did not mask the patient name")
+	}
+	if !hasLabel(p.GetMeta(), "MASKED") {
+		t.Errorf("Bundle() did not label the patient MASKED")
+	}
+	if hasLabel(b.GetEntry()[2].GetResource().GetOrganization().GetMeta(), "MASKED") {
+		t.Errorf("Bundle() labeled the permitted organization MASKED")
+	}
+}
+
+func hasLabel(meta *d4pb.Meta, code string) bool {
+	for _, s := range meta.GetSecurity() {
+		if s.GetSystem().GetValue() == securityLabelSystem && s.GetCode().GetValue() == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPermitted_NoResource(t *testing.T) {
+	f := filter(t, Options{}, consent(provision(c4pb.ConsentProvisionTypeCode_DENY)))
+	if _, err := f.Permitted(context.Background(), &r4pb.ContainedResource{}, Requester{Reference: doctor}); err == nil {
+		t.Errorf("Permitted() of an empty ContainedResource succeeded, want error")
+	}
+}
+
+func TestConsents_NoPatient(t *testing.T) {
+	c := consent(nil)
+	c.Patient = nil
+	if _, err := Consents(c); err == nil {
+		t.Errorf("Consents() succeeded, want error")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consent
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/fhirhttp"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// RequesterFunc returns the requester of r, typically from its
+// authorization. An error rejects the request.
+type RequesterFunc func(r *http.Request) (Requester, error)
+
+// Middleware returns middleware applying f to the successful responses of
+// the handler it wraps: Bundles are filtered entry by entry, and a single
+// resource that is withheld is replaced by a 403 Forbidden. Successful
+// responses that are not FHIR JSON, such as FHIR XML, cannot be filtered
+// and are replaced by a 406 Not Acceptable, and those that cannot be parsed
+// by a 500 Internal Server Error, rather than disclosed unfiltered. Other
+// responses are passed on unchanged.
+func Middleware(f *Filter, requester RequesterFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, err := requester(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			buf := fhirhttp.NewResponseBuffer()
+			next.ServeHTTP(buf, r)
+			for k, v := range buf.Header() {
+				w.Header()[k] = v
+			}
+			if buf.Status < 200 || buf.Status > 299 || buf.Body.Len() == 0 {
+				w.WriteHeader(buf.Status)
+				w.Write(buf.Body.Bytes())
+				return
+			}
+			if ct := buf.Header().Get("Content-Type"); !fhirhttp.IsFHIRJSON(ct) {
+				w.Header().Del("Content-Length")
+				http.Error(w, fmt.Sprintf("cannot apply patient consent to a %q response; request FHIR JSON", ct), http.StatusNotAcceptable)
+				return
+			}
+			body, ok, err := f.response(r, buf.Body.Bytes(), req)
+			if err != nil {
+				w.Header().Del("Content-Length")
+				http.Error(w, "filtering response: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				w.Header().Del("Content-Length")
+				http.Error(w, "access denied by patient consent", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(buf.Status)
+			w.Write(body)
+		})
+	}
+}
+
+// response applies f to the FHIR JSON body of a response to r. It reports
+// whether the body is to be disclosed, and returns it as filtered.
+func (f *Filter) response(r *http.Request, body []byte, req Requester) ([]byte, bool, error) {
+	cr, err := fhirhttp.Unmarshaller.Unmarshal(body)
+	if err != nil {
+		return nil, false, err
+	}
+	res := elementpath.Unwrap(cr)
+	if res == nil {
+		return nil, false, fmt.Errorf("response has no resource")
+	}
+	if b, ok := res.(*r4pb.Bundle); ok {
+		if err := f.Bundle(r.Context(), b, req); err != nil {
+			return nil, false, err
+		}
+	} else if ok, err := f.Resource(r.Context(), res, req); err != nil || !ok {
+		return nil, false, err
+	}
+	out, err := fhirhttp.Marshaller.MarshalResource(res)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/fhirhttp"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+func TestMiddleware(t *testing.T) {
+	bundle, err := fhirhttp.Marshaller.MarshalResource(searchset())
+	if err != nil {
+		t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+	}
+	single, err := fhirhttp.Marshaller.MarshalResource(observation(""))
+	if err != nil {
+		t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+	}
+	responses := map[string][]byte{"/Observation": bundle, "/Observation/o1": single}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("_format") {
+		case "xml":
+			w.Header().Set("Content-Type", "application/fhir+xml")
+			io.WriteString(w, `<Bundle xmlns="http://hl7.org/fhir"><type value="searchset"/></Bundle>`)
+			return
+		case "none":
+			w.Write(body)
+			return
+		}
+		w.Header().Set("Content-Type", "application/fhir+json; charset=utf-8")
+		w.Write(body)
+	})
+	f := filter(t, Options{}, consent(provision(c4pb.ConsentProvisionTypeCode_DENY)))
+	requester := func(r *http.Request) (Requester, error) {
+		if r.Header.Get("Authorization") == "" {
+			return Requester{}, errors.New("no authorization")
+		}
+		return Requester{Reference: doctor}, nil
+	}
+	s := httptest.NewServer(Middleware(f, requester)(next))
+	defer s.Close()
+
+	get := func(path string, authorized bool) (*http.Response, []byte) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, s.URL+path, nil)
+		if err != nil {
+			t.Fatalf("http.NewRequest() returned unexpected error: %v", err)
+		}
+		if authorized {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s returned unexpected error: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("reading response to GET %s: %v", path, err)
+		}
+		return resp, body
+	}
+
+	resp, body := get("/Observation", true)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /Observation returned status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	cr, err := fhirhttp.Unmarshaller.Unmarshal(body)
+	if err != nil {
+		t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+	}
+	b, ok := elementpath.Unwrap(cr).(*r4pb.Bundle)
+	if !ok {
+		t.Fatalf("GET /Observation returned %T, want a Bundle", elementpath.Unwrap(cr))
+	}
+	want := searchset()
+	if err := f.Bundle(context.Background(), want, Requester{Reference: doctor}); err != nil {
+		t.Fatalf("Bundle() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, b, protocmp.Transform()); diff != "" {
+		t.Errorf("GET /Observation diff (-want +got):\n%s", diff)
+	}
+
+	for _, test := range []struct {
+		path       string
+		authorized bool
+		want       int
+	}{
+		{"/Observation/o1", true, http.StatusForbidden},
+		{"/Observation?_format=xml", true, http.StatusNotAcceptable},
+		{"/Observation/o1?_format=xml", true, http.StatusNotAcceptable},
+		{"/Observation?_format=none", true, http.StatusNotAcceptable},
+		{"/Observation", false, http.StatusUnauthorized},
+		{"/Patient", true, http.StatusNotFound},
+	} {
+		if resp, _ := get(test.path, test.authorized); resp.StatusCode != test.want {
+			t.Errorf("GET %s (authorized: %v) returned status %d, want %d", test.path, test.authorized, resp.StatusCode, test.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package coverage determines which R4 Coverages of a patient apply to a
+// service and in which order, and lists them on Claims.
+//
+// A Coverage is in force on a date if it is active and its period contains
+// the date. Coverages in force are ordered for coordination of benefits by
+// their order element, the precedence the patient or payors gave them;
+// Coverages without one follow those with one, self-pay Coverages come
+// last, and among the rest the most recently started Coverage comes first.
+package coverage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	clpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/claim_go_proto"
+	covpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/coverage_go_proto"
+)
+
+// SelfPaySystem is the code system of the type of self-pay Coverages, whose
+// code is "pay".
+const SelfPaySystem = "http://terminology.hl7.org/CodeSystem/coverage-selfpay"
+
+// InForce reports whether cov is active on date: its status is active and
+// its period, if any, contains date, with the end of the period including
+// the whole of its precision.
+func InForce(cov *covpb.Coverage, date time.Time) (bool, error) {
+	if cov.GetStatus().GetValue() != c4pb.FinancialResourceStatusCode_ACTIVE {
+		return false, nil
+	}
+	in, err := fhirtypes.PeriodContains(cov.GetPeriod(), date)
+	if err != nil {
+		return false, fmt.Errorf("Coverage %s: period %w", cov.GetId().GetValue(), err)
+	}
+	return in, nil
+}
+
+// SelfPay reports whether cov is self-pay rather than insurance.
+func SelfPay(cov *covpb.Coverage) bool {
+	for _, c := range cov.GetType().GetCoding() {
+		if c.GetSystem().GetValue() == SelfPaySystem && c.GetCode().GetValue() == "pay" {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort sorts covs in place in the order of precedence of the package
+// documentation, ties keeping their order.
+func Sort(covs []*covpb.Coverage) {
+	starts := map[*covpb.Coverage]time.Time{}
+	for _, c := range covs {
+		if p := c.GetPeriod().GetStart(); p != nil {
+			starts[c], _, _ = fhirtypes.Span(p)
+		}
+	}
+	sort.SliceStable(covs, func(i, j int) bool {
+		a, b := covs[i], covs[j]
+		if sa, sb := SelfPay(a), SelfPay(b); sa != sb {
+			return sb
+		}
+		oa, ob := a.GetOrder().GetValue(), b.GetOrder().GetValue()
+		switch {
+		case oa != 0 && ob != 0 && oa != ob:
+			return oa < ob
+		case (oa == 0) != (ob == 0):
+			return ob == 0
+		}
+		return starts[a].After(starts[b])
+	})
+}
+
+// InForceOn returns the Coverages of covs in force on date, in order of
+// precedence.
+func InForceOn(covs []*covpb.Coverage, date time.Time) ([]*covpb.Coverage, error) {
+	var out []*covpb.Coverage
+	for _, c := range covs {
+		in, err := InForce(c, date)
+		if err != nil {
+			return nil, err
+		}
+		if in {
+			out = append(out, c)
+		}
+	}
+	Sort(out)
+	return out, nil
+}
+
+// Primary returns the Coverage of covs that pays first for a service on
+// date, or nil if none is in force.
+func Primary(covs []*covpb.Coverage, date time.Time) (*covpb.Coverage, error) {
+	in, err := InForceOn(covs, date)
+	if err != nil || len(in) == 0 {
+		return nil, err
+	}
+	return in[0], nil
+}
+
+// SetInsurance sets the insurance of claim to the Coverages of covs in force
+// on date, the service date of the claim, in order of precedence: sequence
+// numbers follow the order from 1 and the first Coverage is the focal one.
+// Insurance the claim already lists for a Coverage keeps its other
+// elements, such as its prior authorizations; insurance for Coverages no
+// longer in force is dropped. Coverages must have ids, as insurance refers
+// to them by reference.
+func SetInsurance(claim *clpb.Claim, covs []*covpb.Coverage, date time.Time) error {
+	in, err := InForceOn(covs, date)
+	if err != nil {
+		return err
+	}
+	if len(in) == 0 {
+		return fmt.Errorf("no Coverage in force on %s", date.Format("2006-01-02"))
+	}
+	var insurance []*clpb.Claim_Insurance
+	for i, c := range in {
+		if c.GetId().GetValue() == "" {
+			return fmt.Errorf("Coverage without id")
+		}
+		ref := fhirtypes.Reference("Coverage", c.GetId().GetValue())
+		var ins *clpb.Claim_Insurance
+		for _, old := range claim.GetInsurance() {
+			if fhirtypes.SameReference(old.GetCoverage(), ref, "") {
+				ins = proto.Clone(old).(*clpb.Claim_Insurance)
+				break
+			}
+		}
+		if ins == nil {
+			ins = &clpb.Claim_Insurance{Coverage: ref}
+		}
+		ins.Sequence = &d4pb.PositiveInt{Value: uint32(i + 1)}
+		ins.Focal = &d4pb.Boolean{Value: i == 0}
+		insurance = append(insurance, ins)
+	}
+	claim.Insurance = insurance
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	clpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/claim_go_proto"
+	covpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/coverage_go_proto"
+)
+
+func day(year int, month time.Month, d int) *d4pb.DateTime {
+	return &d4pb.DateTime{ValueUs: time.Date(year, month, d, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_DAY}
+}
+
+// coverage returns an active Coverage with the order, or none if order is
+// 0, and period.
+func coverage(id string, order uint32, start, end *d4pb.DateTime) *covpb.Coverage {
+	c := &covpb.Coverage{
+		Id:     &d4pb.Id{Value: id},
+		Status: &covpb.Coverage_StatusCode{Value: c4pb.FinancialResourceStatusCode_ACTIVE},
+	}
+	if order != 0 {
+		c.Order = &d4pb.PositiveInt{Value: order}
+	}
+	if start != nil || end != nil {
+		c.Period = &d4pb.Period{Start: start, End: end}
+	}
+	return c
+}
+
+func testCoverages() []*covpb.Coverage {
+	selfPay := coverage("self", 0, nil, nil)
+	selfPay.Type = &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: SelfPaySystem}, Code: &d4pb.Code{Value: "pay"}}}}
+	cancelled := coverage("cancelled", 1, nil, nil)
+	cancelled.Status.Value = c4pb.FinancialResourceStatusCode_CANCELLED
+	return []*covpb.Coverage{
+		selfPay,
+		coverage("old", 0, day(2020, 1, 1), day(2024, 12, 31)),
+		coverage("new", 0, day(2024, 6, 1), nil),
+		coverage("secondary", 2, day(2023, 1, 1), nil),
+		coverage("primary", 1, day(2023, 1, 1), nil),
+		cancelled,
+		coverage("future", 1, day(2026, 1, 1), nil),
+	}
+}
+
+func ids(covs []*covpb.Coverage) []string {
+	var out []string
+	for _, c := range covs {
+		out = append(out, c.GetId().GetValue())
+	}
+	return out
+}
+
+func TestInForceOn(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want []string
+	}{
+		{time.Date(2024, 12, 31, 18, 0, 0, 0, time.UTC), []string{"primary", "secondary", "new", "old", "self"}},
+		{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), []string{"primary", "secondary", "new", "self"}},
+		{time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), []string{"self"}},
+	}
+	for _, tc := range tests {
+		got, err := InForceOn(testCoverages(), tc.date)
+		if err != nil {
+			t.Fatalf("InForceOn(%v) returned unexpected error: %v", tc.date, err)
+		}
+		if diff := cmp.Diff(tc.want, ids(got)); diff != "" {
+			t.Errorf("InForceOn(%v) diff (-want +got):\n%s", tc.date, diff)
+		}
+	}
+	p, err := Primary(testCoverages()[:3], time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Primary() returned unexpected error: %v", err)
+	}
+	if p.GetId().GetValue() != "new" {
+		t.Errorf("Primary() = %s, want new", p.GetId().GetValue())
+	}
+}
+
+func TestSetInsurance(t *testing.T) {
+	claim := &clpb.Claim{Insurance: []*clpb.Claim_Insurance{{
+		Sequence:   &d4pb.PositiveInt{Value: 1},
+		Focal:      &d4pb.Boolean{Value: true},
+		Coverage:   &d4pb.Reference{Reference: &d4pb.Reference_CoverageId{CoverageId: &d4pb.ReferenceId{Value: "secondary"}}},
+		PreAuthRef: []*d4pb.String{{Value: "PA-1"}},
+	}, {
+		Sequence: &d4pb.PositiveInt{Value: 2},
+		Coverage: &d4pb.Reference{Reference: &d4pb.Reference_CoverageId{CoverageId: &d4pb.ReferenceId{Value: "old"}}},
+	}}}
+	covs := testCoverages()[3:5]
+	if err := SetInsurance(claim, covs, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SetInsurance() returned unexpected error: %v", err)
+	}
+	type ins struct {
+		Seq      uint32
+		Focal    bool
+		Coverage string
+		PreAuth  int
+	}
+	var got []ins
+	for _, i := range claim.GetInsurance() {
+		got = append(got, ins{i.GetSequence().GetValue(), i.GetFocal().GetValue(), i.GetCoverage().GetCoverageId().GetValue(), len(i.GetPreAuthRef())})
+	}
+	want := []ins{{1, true, "primary", 0}, {2, false, "secondary", 1}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SetInsurance() diff (-want +got):\n%s", diff)
+	}
+
+	if err := SetInsurance(claim, covs, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("SetInsurance() without Coverage in force succeeded, want error")
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Library is an ELM library, the compiled form of a CQL library, as produced
+// by the CQL-to-ELM translator in its JSON format.
+type Library struct {
+	Identifier  VersionedIdentifier `json:"identifier"`
+	Usings      usings              `json:"usings"`
+	Includes    includes            `json:"includes"`
+	Parameters  parameters          `json:"parameters"`
+	CodeSystems codeSystems         `json:"codeSystems"`
+	ValueSets   valueSets           `json:"valueSets"`
+	Codes       codes               `json:"codes"`
+	Concepts    concepts            `json:"concepts"`
+	Statements  statements          `json:"statements"`
+}
+
+// The ELM JSON format wraps each list of definitions in an object with a
+// single "def" member.
+type (
+	usings      struct{ Def []*UsingDef }
+	includes    struct{ Def []*IncludeDef }
+	parameters  struct{ Def []*ParameterDef }
+	codeSystems struct{ Def []*CodeSystemDef }
+	valueSets   struct{ Def []*ValueSetDef }
+	codes       struct{ Def []*CodeDef }
+	concepts    struct{ Def []*ConceptDef }
+	statements  struct{ Def []*ExpressionDef }
+)
+
+// VersionedIdentifier names a library.
+type VersionedIdentifier struct {
+	ID      string `json:"id"`
+	System  string `json:"system"`
+	Version string `json:"version"`
+}
+
+// UsingDef declares a data model used by the library.
+type UsingDef struct {
+	LocalIdentifier string `json:"localIdentifier"`
+	URI             string `json:"uri"`
+	Version         string `json:"version"`
+}
+
+// IncludeDef declares a library referenced by this one.
+type IncludeDef struct {
+	LocalIdentifier string `json:"localIdentifier"`
+	Path            string `json:"path"`
+	Version         string `json:"version"`
+}
+
+// ParameterDef declares a parameter and its optional default value.
+type ParameterDef struct {
+	Name    string      `json:"name"`
+	Default *Expression `json:"default"`
+}
+
+// CodeSystemDef declares a code system by its canonical URL.
+type CodeSystemDef struct {
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// ValueSetDef declares a value set by its canonical URL.
+type ValueSetDef struct {
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// CodeDef declares a code from a code system.
+type CodeDef struct {
+	Name       string `json:"name"`
+	ID         string `json:"id"`
+	Display    string `json:"display"`
+	CodeSystem *Ref   `json:"codeSystem"`
+}
+
+// ConceptDef declares a concept made of previously declared codes.
+type ConceptDef struct {
+	Name    string `json:"name"`
+	Display string `json:"display"`
+	Code    []*Ref `json:"code"`
+}
+
+// Ref refers to a definition, optionally in an included library.
+type Ref struct {
+	Name        string `json:"name"`
+	LibraryName string `json:"libraryName"`
+}
+
+// ExpressionDef is a named expression or, if Type is "FunctionDef", a
+// function.
+type ExpressionDef struct {
+	Type       string        `json:"type"`
+	Name       string        `json:"name"`
+	Context    string        `json:"context"`
+	Expression *Expression   `json:"expression"`
+	Operand    []*OperandDef `json:"operand"`
+	External   bool          `json:"external"`
+}
+
+// OperandDef declares a function operand.
+type OperandDef struct {
+	Name string `json:"name"`
+}
+
+// TypeSpecifier describes a type in As, Is and conversion expressions.
+type TypeSpecifier struct {
+	Type        string           `json:"type"`
+	Name        string           `json:"name"`
+	ElementType *TypeSpecifier   `json:"elementType"`
+	Choice      []*TypeSpecifier `json:"choice"`
+}
+
+// Expression is an ELM expression node. ELM defines a class per operator;
+// they are represented by this single type, discriminated by Type, holding
+// the union of the members used by the supported operators.
+type Expression struct {
+	Type string `json:"type"`
+
+	// References and properties.
+	Name        string      `json:"name"`
+	LibraryName string      `json:"libraryName"`
+	Path        string      `json:"path"`
+	Scope       string      `json:"scope"`
+	Operand     Expressions `json:"operand"`
+	Source      Expressions `json:"source"`
+
+	// Literals and selectors. Value and Code hold either a literal or nested
+	// expressions, see UnmarshalJSON.
+	ValueType   string        `json:"valueType"`
+	Value       string        `json:"-"`
+	ValueExpr   *Expression   `json:"-"`
+	Unit        string        `json:"unit"`
+	Code        string        `json:"-"`
+	CodeExpr    *Expression   `json:"-"`
+	Codes       []*Expression `json:"-"`
+	System      *Expression   `json:"system"`
+	Display     string        `json:"display"`
+	ClassType   string        `json:"classType"`
+	Element     []*Expression `json:"element"`
+	Low         *Expression   `json:"low"`
+	High        *Expression   `json:"high"`
+	LowClosed   *bool         `json:"lowClosed"`
+	HighClosed  *bool         `json:"highClosed"`
+	Year        *Expression   `json:"year"`
+	Month       *Expression   `json:"month"`
+	Day         *Expression   `json:"day"`
+	Hour        *Expression   `json:"hour"`
+	Minute      *Expression   `json:"minute"`
+	Second      *Expression   `json:"second"`
+	Millisecond *Expression   `json:"millisecond"`
+	Timezone    *Expression   `json:"timezoneOffset"`
+
+	// Retrieve.
+	DataType     string      `json:"dataType"`
+	TemplateID   string      `json:"templateId"`
+	CodeProperty string      `json:"codeProperty"`
+	CodesExpr    *Expression `json:"codes"`
+	DateProperty string      `json:"dateProperty"`
+	DateRange    *Expression `json:"dateRange"`
+
+	// Queries. Source also holds the aliased query sources, which use Alias
+	// and Expression.
+	Alias        string        `json:"alias"`
+	Expression   *Expression   `json:"expression"`
+	Identifier   string        `json:"identifier"`
+	Let          []*Expression `json:"let"`
+	Relationship []*Expression `json:"relationship"`
+	SuchThat     *Expression   `json:"suchThat"`
+	Where        *Expression   `json:"where"`
+	Return       *Expression   `json:"return"`
+	Distinct     *bool         `json:"distinct"`
+	Sort         *Expression   `json:"sort"`
+	By           []*Expression `json:"by"`
+	Direction    string        `json:"direction"`
+
+	// Conditionals.
+	Condition *Expression   `json:"condition"`
+	Then      *Expression   `json:"then"`
+	Else      *Expression   `json:"else"`
+	Comparand *Expression   `json:"comparand"`
+	CaseItem  []*Expression `json:"caseItem"`
+	When      *Expression   `json:"when"`
+
+	// Types.
+	AsType          string         `json:"asType"`
+	AsTypeSpecifier *TypeSpecifier `json:"asTypeSpecifier"`
+	IsType          string         `json:"isType"`
+	IsTypeSpecifier *TypeSpecifier `json:"isTypeSpecifier"`
+	ToType          string         `json:"toType"`
+	Strict          bool           `json:"strict"`
+
+	// Operator modifiers.
+	Precision string      `json:"precision"`
+	ValueSet  *Expression `json:"valueset"`
+}
+
+// Expressions is a list of expressions. ELM JSON writes operands as a single
+// object for unary operators and as an array otherwise.
+type Expressions []*Expression
+
+// UnmarshalJSON accepts a single expression or an array of them.
+func (es *Expressions) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var e Expression
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		*es = Expressions{&e}
+		return nil
+	}
+	var list []*Expression
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*es = list
+	return nil
+}
+
+// UnmarshalJSON decodes an expression. The "value" and "code" members are
+// overloaded in ELM: "value" is a literal string for Literal and an
+// expression for tuple and instance elements, and "code" is a literal for
+// Code, a list of codes for Concept and an expression for InValueSet.
+func (e *Expression) UnmarshalJSON(data []byte) error {
+	type plain Expression
+	var raw struct {
+		*plain
+		RawValue json.RawMessage `json:"value"`
+		RawCode  json.RawMessage `json:"code"`
+	}
+	raw.plain = (*plain)(e)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var err error
+	if e.Value, e.ValueExpr, _, err = overloaded(raw.RawValue); err != nil {
+		return fmt.Errorf("%s value: %w", e.Type, err)
+	}
+	if e.Code, e.CodeExpr, e.Codes, err = overloaded(raw.RawCode); err != nil {
+		return fmt.Errorf("%s code: %w", e.Type, err)
+	}
+	return nil
+}
+
+// overloaded decodes a member that is a literal, an expression or a list of
+// expressions.
+func overloaded(data json.RawMessage) (string, *Expression, []*Expression, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return "", nil, nil, nil
+	}
+	switch data[0] {
+	case '{':
+		var e Expression
+		err := json.Unmarshal(data, &e)
+		return "", &e, nil, err
+	case '[':
+		var list []*Expression
+		err := json.Unmarshal(data, &list)
+		return "", nil, list, err
+	case '"':
+		var s string
+		err := json.Unmarshal(data, &s)
+		return s, nil, nil, err
+	}
+	// Numbers and booleans, i.e. the value of a Quantity.
+	return string(data), nil, nil, nil
+}
+
+// Parse parses an ELM library in the JSON format produced by the CQL-to-ELM
+// translator, i.e. {"library": {...}}.
+func Parse(data []byte) (*Library, error) {
+	var doc struct {
+		Library *Library `json:"library"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing ELM: %w", err)
+	}
+	if doc.Library == nil {
+		return nil, fmt.Errorf("parsing ELM: no library")
+	}
+	return doc.Library, nil
+}
@@ -0,0 +1,817 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cql executes ELM, the compiled form of Clinical Quality Language
+// (CQL) libraries, with the FHIR R4 data model bound to the protos of this
+// module.
+//
+// Libraries are produced by the CQL-to-ELM translator in its JSON format and
+// loaded with Parse, or from the content of FHIR Library resources with
+// FromResource, which picks the ELM among their representations. Data is
+// obtained through a RetrieveProvider, which returns the resources matching
+// each Retrieve of the library, and value set membership is checked by a
+// TerminologyProvider.
+//
+// Values are represented as follows: null is nil, Boolean is bool, Integer
+// and Long are int64, Decimal is float64, String is string, Date, DateTime
+// and Time are fhirpath.Temporal, Quantity is fhirpath.Quantity, lists are
+// []interface{}, and Code, Concept, Interval and Tuple are the types of this
+// package. FHIR elements and resources are their proto messages; FHIR
+// primitives, Coding, CodeableConcept, Period and Range are implicitly
+// converted to the corresponding system values where an operator requires
+// them. Calls to the FHIRHelpers library are implemented natively, so it
+// does not need to be supplied.
+package cql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// RetrieveRequest describes the data requested by a Retrieve expression.
+type RetrieveRequest struct {
+	// DataType is the FHIR resource type, i.e. "Observation".
+	DataType string
+	// TemplateID is the profile the resources must conform to, if any.
+	TemplateID string
+	// Context is the context of the expression, i.e. "Patient", and
+	// ContextValue the id of the subject. ContextValue is empty for
+	// expressions evaluated in the Unfiltered context.
+	Context      string
+	ContextValue string
+	// CodePath is the element to filter on, i.e. "code". The resources must
+	// have a code in Codes or, if ValueSet is set, in that value set.
+	CodePath string
+	Codes    []Code
+	ValueSet *ValueSet
+	// DatePath is the element to filter on with DateRange, if set.
+	DatePath  string
+	DateRange *Interval
+}
+
+// RetrieveProvider returns the resources for Retrieve expressions.
+// Implementations must apply the context, code and date filters of the
+// request.
+type RetrieveProvider interface {
+	Retrieve(ctx context.Context, req *RetrieveRequest) ([]proto.Message, error)
+}
+
+// TerminologyProvider answers value set membership questions.
+type TerminologyProvider interface {
+	InValueSet(ctx context.Context, code Code, vs ValueSet) (bool, error)
+}
+
+// LibraryResolver returns the library with the given name and version, for
+// resolving include declarations. version may be empty.
+type LibraryResolver func(name, version string) (*Library, error)
+
+// Options configures an Engine.
+type Options struct {
+	Retriever   RetrieveProvider
+	Terminology TerminologyProvider
+	Libraries   LibraryResolver
+	// Parameters holds values for the parameters of the main library by
+	// name. Parameters without a value use their default.
+	Parameters map[string]interface{}
+	// Now is the evaluation timestamp returned by Now() and Today(). It
+	// defaults to the time Evaluate is called.
+	Now time.Time
+}
+
+// Engine evaluates the expressions of a library.
+type Engine struct {
+	main *library
+	opts Options
+}
+
+// library is a loaded ELM library with its includes resolved.
+type library struct {
+	elm       *Library
+	native    bool
+	includes  map[string]*library
+	defs      map[string]*ExpressionDef
+	functions map[string][]*ExpressionDef
+}
+
+const fhirHelpers = "FHIRHelpers"
+
+// New loads lib and the libraries it includes.
+func New(lib *Library, opts Options) (*Engine, error) {
+	main, err := load(lib, opts.Libraries, map[string]*library{})
+	if err != nil {
+		return nil, err
+	}
+	for name := range opts.Parameters {
+		if findParameter(main, name) == nil {
+			return nil, fmt.Errorf("library %s has no parameter %q", lib.Identifier.ID, name)
+		}
+	}
+	return &Engine{main: main, opts: opts}, nil
+}
+
+func load(lib *Library, resolve LibraryResolver, loaded map[string]*library) (*library, error) {
+	key := lib.Identifier.ID + "|" + lib.Identifier.Version
+	if l, ok := loaded[key]; ok {
+		return l, nil
+	}
+	l := &library{
+		elm:       lib,
+		includes:  map[string]*library{},
+		defs:      map[string]*ExpressionDef{},
+		functions: map[string][]*ExpressionDef{},
+	}
+	loaded[key] = l
+	for _, def := range lib.Statements.Def {
+		if def.Type == "FunctionDef" {
+			l.functions[def.Name] = append(l.functions[def.Name], def)
+		} else {
+			l.defs[def.Name] = def
+		}
+	}
+	for _, inc := range lib.Includes.Def {
+		if inc.Path == fhirHelpers {
+			l.includes[inc.LocalIdentifier] = &library{elm: &Library{Identifier: VersionedIdentifier{ID: fhirHelpers}}, native: true}
+			continue
+		}
+		if resolve == nil {
+			return nil, fmt.Errorf("library %s includes %s but no library resolver was given", lib.Identifier.ID, inc.Path)
+		}
+		incLib, err := resolve(inc.Path, inc.Version)
+		if err != nil {
+			return nil, fmt.Errorf("resolving library %s: %w", inc.Path, err)
+		}
+		if l.includes[inc.LocalIdentifier], err = load(incLib, resolve, loaded); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func findParameter(l *library, name string) *ParameterDef {
+	for _, p := range l.elm.Parameters.Def {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Evaluate evaluates the named expressions of the library for the subject,
+// the id of the resource the Patient context refers to, and returns their
+// values by name. All expressions are evaluated if no names are given.
+// Expressions defined in the Unfiltered context ignore subject.
+func (e *Engine) Evaluate(ctx context.Context, subject string, names ...string) (map[string]interface{}, error) {
+	ev := e.newEvaluation(ctx, subject)
+	if len(names) == 0 {
+		for _, def := range e.main.elm.Statements.Def {
+			if def.Type != "FunctionDef" {
+				names = append(names, def.Name)
+			}
+		}
+	}
+	out := map[string]interface{}{}
+	for _, name := range names {
+		def, ok := e.main.defs[name]
+		if !ok {
+			return nil, fmt.Errorf("library %s has no expression %q", e.main.elm.Identifier.ID, name)
+		}
+		v, err := ev.expressionDef(e.main, def)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = v
+	}
+	return out, nil
+}
+
+// Call invokes the named function of the library for the subject with args,
+// which are values in the representation described in the package
+// documentation.
+func (e *Engine) Call(ctx context.Context, subject, name string, args ...interface{}) (interface{}, error) {
+	return e.newEvaluation(ctx, subject).call(e.main, name, args)
+}
+
+func (e *Engine) newEvaluation(ctx context.Context, subject string) *evaluation {
+	now := e.opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return &evaluation{
+		ctx:     ctx,
+		engine:  e,
+		subject: subject,
+		now:     fhirpath.Temporal{Kind: fhirpath.DateTime, Time: now, Precision: fhirpath.PrecisionMillisecond},
+		results: map[*ExpressionDef]interface{}{},
+		params:  map[*ParameterDef]interface{}{},
+	}
+}
+
+// evaluation holds the state of a single Evaluate call.
+type evaluation struct {
+	ctx     context.Context
+	engine  *Engine
+	subject string
+	now     fhirpath.Temporal
+	results map[*ExpressionDef]interface{}
+	params  map[*ParameterDef]interface{}
+	active  []*ExpressionDef
+}
+
+// scope is the lexical environment of an expression.
+type scope struct {
+	lib     *library
+	context string
+	vars    map[string]interface{}
+	// this is the item identifier references resolve against in sort
+	// clauses.
+	this interface{}
+}
+
+// with returns a copy of s with the variable name bound to v.
+func (s *scope) with(name string, v interface{}) *scope {
+	vars := make(map[string]interface{}, len(s.vars)+1)
+	for k, val := range s.vars {
+		vars[k] = val
+	}
+	vars[name] = v
+	return &scope{lib: s.lib, context: s.context, vars: vars, this: s.this}
+}
+
+func (ev *evaluation) expressionDef(l *library, def *ExpressionDef) (interface{}, error) {
+	if v, ok := ev.results[def]; ok {
+		return v, nil
+	}
+	for _, a := range ev.active {
+		if a == def {
+			return nil, fmt.Errorf("expression %q refers to itself", def.Name)
+		}
+	}
+	ev.active = append(ev.active, def)
+	defer func() { ev.active = ev.active[:len(ev.active)-1] }()
+	v, err := ev.eval(def.Expression, &scope{lib: l, context: def.Context})
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s: %w", l.elm.Identifier.ID, def.Name, err)
+	}
+	ev.results[def] = v
+	return v, nil
+}
+
+// eval evaluates x in scope s.
+func (ev *evaluation) eval(x *Expression, s *scope) (interface{}, error) {
+	if x == nil {
+		return nil, nil
+	}
+	if err := ev.ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch x.Type {
+	case "ExpressionRef":
+		l, err := ev.library(s.lib, x.LibraryName)
+		if err != nil {
+			return nil, err
+		}
+		def, ok := l.defs[x.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown expression %q", x.Name)
+		}
+		return ev.expressionDef(l, def)
+	case "FunctionRef":
+		return ev.functionRef(x, s)
+	case "ParameterRef":
+		return ev.parameterRef(x, s)
+	case "OperandRef", "AliasRef", "QueryLetRef":
+		v, ok := s.vars[x.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown %s %q", strings.TrimSuffix(x.Type, "Ref"), x.Name)
+		}
+		return v, nil
+	case "IdentifierRef":
+		if v, ok := s.vars[x.Name]; ok {
+			return v, nil
+		}
+		if s.this != nil {
+			return property(s.this, x.Name)
+		}
+		return nil, fmt.Errorf("unknown identifier %q", x.Name)
+	case "CodeSystemRef", "ValueSetRef", "CodeRef", "ConceptRef":
+		return ev.terminologyRef(x, s)
+	case "Property":
+		var src interface{}
+		if x.Scope != "" {
+			v, ok := s.vars[x.Scope]
+			if !ok {
+				return nil, fmt.Errorf("unknown alias %q", x.Scope)
+			}
+			src = v
+		} else {
+			v, err := ev.eval(first(x.Source), s)
+			if err != nil {
+				return nil, err
+			}
+			src = v
+		}
+		return property(src, x.Path)
+	case "Query":
+		return ev.query(x, s)
+	case "Retrieve":
+		return ev.retrieve(x, s)
+	}
+	return ev.operator(x, s)
+}
+
+func first(es Expressions) *Expression {
+	if len(es) == 0 {
+		return nil
+	}
+	return es[0]
+}
+
+// library returns the library called name as seen from l, or l itself if
+// name is empty.
+func (ev *evaluation) library(l *library, name string) (*library, error) {
+	if name == "" {
+		return l, nil
+	}
+	inc, ok := l.includes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown library %q", name)
+	}
+	return inc, nil
+}
+
+func (ev *evaluation) functionRef(x *Expression, s *scope) (interface{}, error) {
+	l, err := ev.library(s.lib, x.LibraryName)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]interface{}, len(x.Operand))
+	for i, op := range x.Operand {
+		if args[i], err = ev.eval(op, s); err != nil {
+			return nil, err
+		}
+	}
+	return ev.call(l, x.Name, args)
+}
+
+// call invokes the function name of l, choosing the overload by arity.
+func (ev *evaluation) call(l *library, name string, args []interface{}) (interface{}, error) {
+	if l.native {
+		fn, ok := fhirHelperFunctions[name]
+		if !ok || len(args) != 1 {
+			return nil, fmt.Errorf("unsupported function FHIRHelpers.%s", name)
+		}
+		return fn(args[0])
+	}
+	for _, def := range l.functions[name] {
+		if len(def.Operand) != len(args) {
+			continue
+		}
+		if def.External {
+			return nil, fmt.Errorf("external function %q is not supported", name)
+		}
+		fs := &scope{lib: l, context: def.Context, vars: map[string]interface{}{}}
+		for i, op := range def.Operand {
+			fs.vars[op.Name] = args[i]
+		}
+		v, err := ev.eval(def.Expression, fs)
+		if err != nil {
+			return nil, fmt.Errorf("%s(): %w", name, err)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unknown function %q with %d arguments", name, len(args))
+}
+
+func (ev *evaluation) parameterRef(x *Expression, s *scope) (interface{}, error) {
+	l, err := ev.library(s.lib, x.LibraryName)
+	if err != nil {
+		return nil, err
+	}
+	p := findParameter(l, x.Name)
+	if p == nil {
+		return nil, fmt.Errorf("unknown parameter %q", x.Name)
+	}
+	if v, ok := ev.params[p]; ok {
+		return v, nil
+	}
+	v, given := interface{}(nil), false
+	if l == ev.engine.main {
+		v, given = ev.engine.opts.Parameters[x.Name]
+	}
+	if !given {
+		if v, err = ev.eval(p.Default, &scope{lib: l}); err != nil {
+			return nil, fmt.Errorf("parameter %q: %w", x.Name, err)
+		}
+	}
+	ev.params[p] = v
+	return v, nil
+}
+
+func (ev *evaluation) terminologyRef(x *Expression, s *scope) (interface{}, error) {
+	l, err := ev.library(s.lib, x.LibraryName)
+	if err != nil {
+		return nil, err
+	}
+	lib := l.elm
+	switch x.Type {
+	case "CodeSystemRef":
+		for _, cs := range lib.CodeSystems.Def {
+			if cs.Name == x.Name {
+				return CodeSystem{ID: cs.ID, Version: cs.Version}, nil
+			}
+		}
+	case "ValueSetRef":
+		for _, vs := range lib.ValueSets.Def {
+			if vs.Name == x.Name {
+				return ValueSet{ID: vs.ID, Version: vs.Version}, nil
+			}
+		}
+	case "CodeRef":
+		return ev.codeDef(l, x.Name)
+	case "ConceptRef":
+		for _, c := range lib.Concepts.Def {
+			if c.Name != x.Name {
+				continue
+			}
+			concept := Concept{Display: c.Display}
+			for _, ref := range c.Code {
+				cl, err := ev.library(l, ref.LibraryName)
+				if err != nil {
+					return nil, err
+				}
+				code, err := ev.codeDef(cl, ref.Name)
+				if err != nil {
+					return nil, err
+				}
+				concept.Codes = append(concept.Codes, code)
+			}
+			return concept, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown %s %q", strings.TrimSuffix(x.Type, "Ref"), x.Name)
+}
+
+func (ev *evaluation) codeDef(l *library, name string) (Code, error) {
+	for _, c := range l.elm.Codes.Def {
+		if c.Name != name {
+			continue
+		}
+		code := Code{Code: c.ID, Display: c.Display}
+		if c.CodeSystem != nil {
+			cl, err := ev.library(l, c.CodeSystem.LibraryName)
+			if err != nil {
+				return Code{}, err
+			}
+			cs, err := ev.terminologyRef(&Expression{Type: "CodeSystemRef", Name: c.CodeSystem.Name}, &scope{lib: cl})
+			if err != nil {
+				return Code{}, err
+			}
+			code.System, code.Version = cs.(CodeSystem).ID, cs.(CodeSystem).Version
+		}
+		return code, nil
+	}
+	return Code{}, fmt.Errorf("unknown code %q", name)
+}
+
+// property returns the element path of v, which is a dotted list of element
+// names. Navigating from a list returns the flattened list of results.
+func property(v interface{}, path string) (interface{}, error) {
+	for _, name := range strings.Split(path, ".") {
+		var err error
+		if v, err = child(v, name); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func child(v interface{}, name string) (interface{}, error) {
+	switch x := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		var out []interface{}
+		for _, item := range x {
+			c, err := child(item, name)
+			if err != nil {
+				return nil, err
+			}
+			if l, ok := c.([]interface{}); ok {
+				out = append(out, l...)
+			} else if c != nil {
+				out = append(out, c)
+			}
+		}
+		return out, nil
+	case Tuple:
+		return x[name], nil
+	case Interval:
+		switch name {
+		case "low":
+			return x.Low, nil
+		case "high":
+			return x.High, nil
+		case "lowClosed":
+			return x.LowClosed, nil
+		case "highClosed":
+			return x.HighClosed, nil
+		}
+	case Code:
+		switch name {
+		case "code":
+			return x.Code, nil
+		case "system":
+			return x.System, nil
+		case "version":
+			return x.Version, nil
+		case "display":
+			return x.Display, nil
+		}
+	case Concept:
+		switch name {
+		case "codes":
+			var out []interface{}
+			for _, c := range x.Codes {
+				out = append(out, c)
+			}
+			return out, nil
+		case "display":
+			return x.Display, nil
+		}
+	case fhirpath.Quantity:
+		switch name {
+		case "value":
+			return x.Value, nil
+		case "unit":
+			return x.Unit, nil
+		}
+	case proto.Message:
+		return protoChild(x, name)
+	}
+	return nil, fmt.Errorf("%s has no property %q", typeName(v), name)
+}
+
+func protoChild(m proto.Message, name string) (interface{}, error) {
+	if m = elementpath.Unwrap(m); m == nil {
+		return nil, nil
+	}
+	rm := m.ProtoReflect()
+	d := rm.Descriptor()
+	if name == "value" && elementpath.IsPrimitive(d) {
+		return system(m), nil
+	}
+	fd, choice, err := elementpath.LookupField(d, name)
+	if err != nil {
+		return nil, err
+	}
+	if fd.IsList() {
+		l := rm.Get(fd).List()
+		var out []interface{}
+		for i := 0; i < l.Len(); i++ {
+			if e := element(l.Get(i).Message(), choice); e != nil {
+				out = append(out, e)
+			}
+		}
+		return out, nil
+	}
+	if !rm.Has(fd) {
+		return nil, nil
+	}
+	if e := element(rm.Get(fd).Message(), choice); e != nil {
+		return e, nil
+	}
+	return nil, nil
+}
+
+// element returns the element held in rm, unwrapping choice types,
+// google.protobuf.Any and ContainedResource. It returns nil for a choice that
+// is unset or set to another type than choice.
+func element(rm protoreflect.Message, choice string) proto.Message {
+	if elementpath.IsChoice(rm.Descriptor()) {
+		set := rm.WhichOneof(rm.Descriptor().Oneofs().Get(0))
+		if set == nil || (choice != "" && set.JSONName() != choice) {
+			return nil
+		}
+		rm = rm.Get(set).Message()
+	}
+	m := rm.Interface()
+	if a, ok := m.(*anypb.Any); ok {
+		var err error
+		if m, err = a.UnmarshalNew(); err != nil {
+			return nil
+		}
+	}
+	return elementpath.Unwrap(m)
+}
+
+func (ev *evaluation) retrieve(x *Expression, s *scope) (interface{}, error) {
+	p := ev.engine.opts.Retriever
+	if p == nil {
+		return nil, fmt.Errorf("retrieve of %s requires a RetrieveProvider", x.DataType)
+	}
+	_, typ := splitTypeName(x.DataType)
+	req := &RetrieveRequest{
+		DataType:   typ,
+		TemplateID: x.TemplateID,
+		Context:    s.context,
+		CodePath:   x.CodeProperty,
+		DatePath:   x.DateProperty,
+	}
+	if s.context != "" && s.context != "Unfiltered" && s.context != "Population" {
+		req.ContextValue = ev.subject
+	}
+	if x.CodesExpr != nil {
+		v, err := ev.eval(x.CodesExpr, s)
+		if err != nil {
+			return nil, err
+		}
+		if vs, ok := v.(ValueSet); ok {
+			req.ValueSet = &vs
+		} else if req.Codes = codesOf(v); len(req.Codes) == 0 {
+			return []interface{}{}, nil
+		}
+	}
+	if x.DateRange != nil {
+		v, err := ev.eval(x.DateRange, s)
+		if err != nil {
+			return nil, err
+		}
+		if iv, ok := v.(Interval); ok {
+			req.DateRange = &iv
+		}
+	}
+	res, err := p.Retrieve(ev.ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving %s: %w", typ, err)
+	}
+	out := make([]interface{}, 0, len(res))
+	for _, r := range res {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (ev *evaluation) query(x *Expression, s *scope) (interface{}, error) {
+	rows := []map[string]interface{}{{}}
+	singular := false
+	for _, src := range x.Source {
+		v, err := ev.eval(src.Expression, s)
+		if err != nil {
+			return nil, err
+		}
+		_, isList := v.([]interface{})
+		singular = len(x.Source) == 1 && !isList
+		var next []map[string]interface{}
+		for _, row := range rows {
+			for _, item := range toList(v) {
+				r := make(map[string]interface{}, len(row)+1)
+				for k, val := range row {
+					r[k] = val
+				}
+				r[src.Alias] = item
+				next = append(next, r)
+			}
+		}
+		rows = next
+	}
+	var out []interface{}
+	for _, row := range rows {
+		rs := s
+		for alias, item := range row {
+			rs = rs.with(alias, item)
+		}
+		for _, let := range x.Let {
+			v, err := ev.eval(let.Expression, rs)
+			if err != nil {
+				return nil, err
+			}
+			rs = rs.with(let.Identifier, v)
+		}
+		keep, err := ev.relationships(x.Relationship, rs)
+		if err != nil {
+			return nil, err
+		}
+		if keep && x.Where != nil {
+			w, err := ev.eval(x.Where, rs)
+			if err != nil {
+				return nil, err
+			}
+			keep = w == true
+		}
+		if !keep {
+			continue
+		}
+		var result interface{}
+		switch {
+		case x.Return != nil:
+			if result, err = ev.eval(x.Return.Expression, rs); err != nil {
+				return nil, err
+			}
+		case len(x.Source) == 1:
+			result = row[x.Source[0].Alias]
+		default:
+			t := Tuple{}
+			for alias, item := range row {
+				t[alias] = item
+			}
+			result = t
+		}
+		out = append(out, result)
+	}
+	if x.Return != nil && (x.Return.Distinct == nil || *x.Return.Distinct) {
+		out = distinct(out)
+	}
+	if x.Sort != nil {
+		if err := ev.sort(out, x.Sort.By, s); err != nil {
+			return nil, err
+		}
+	}
+	if singular {
+		if len(out) == 0 {
+			return nil, nil
+		}
+		return out[0], nil
+	}
+	if out == nil {
+		out = []interface{}{}
+	}
+	return out, nil
+}
+
+// relationships evaluates the with and without clauses of a query row.
+func (ev *evaluation) relationships(rels []*Expression, s *scope) (bool, error) {
+	for _, rel := range rels {
+		v, err := ev.eval(rel.Expression, s)
+		if err != nil {
+			return false, err
+		}
+		found := false
+		for _, item := range toList(v) {
+			st, err := ev.eval(rel.SuchThat, s.with(rel.Alias, item))
+			if err != nil {
+				return false, err
+			}
+			if st == true {
+				found = true
+				break
+			}
+		}
+		if found != (rel.Type == "With") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// sort sorts a query result by the sort items, the first being the primary
+// key.
+func (ev *evaluation) sort(list []interface{}, by []*Expression, s *scope) error {
+	for i := len(by) - 1; i >= 0; i-- {
+		item := by[i]
+		desc := strings.HasPrefix(item.Direction, "desc")
+		var evalErr error
+		key := func(v interface{}) interface{} {
+			var k interface{}
+			var err error
+			switch item.Type {
+			case "ByColumn":
+				k, err = property(v, item.Path)
+			case "ByExpression":
+				ks := &scope{lib: s.lib, context: s.context, vars: s.vars, this: v}
+				k, err = ev.eval(item.Expression, ks)
+			default:
+				k = v
+			}
+			if err != nil && evalErr == nil {
+				evalErr = err
+			}
+			return k
+		}
+		if err := sortValues(list, key, desc); err != nil {
+			return err
+		}
+		if evalErr != nil {
+			return evalErr
+		}
+	}
+	return nil
+}
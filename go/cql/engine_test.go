@@ -0,0 +1,533 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	lpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/library_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+// The helpers below build ELM JSON fragments.
+
+func lit(typ, value string) string {
+	return fmt.Sprintf(`{"type":"Literal","valueType":"{urn:hl7-org:elm-types:r1}%s","value":%q}`, typ, value)
+}
+
+func integer(i int) string { return lit("Integer", fmt.Sprint(i)) }
+
+func op(typ string, operands ...string) string {
+	if len(operands) == 1 {
+		return fmt.Sprintf(`{"type":%q,"operand":%s}`, typ, operands[0])
+	}
+	return fmt.Sprintf(`{"type":%q,"operand":[%s]}`, typ, strings.Join(operands, ","))
+}
+
+func opWithPrecision(typ, precision string, operands ...string) string {
+	return fmt.Sprintf(`{"type":%q,"precision":%q,"operand":[%s]}`, typ, precision, strings.Join(operands, ","))
+}
+
+func dateTime(parts ...int) string {
+	names := []string{"year", "month", "day", "hour", "minute", "second", "millisecond"}
+	var fields []string
+	for i, p := range parts {
+		fields = append(fields, fmt.Sprintf("%q:%s", names[i], integer(p)))
+	}
+	return fmt.Sprintf(`{"type":"DateTime",%s,"timezoneOffset":%s}`, strings.Join(fields, ","), lit("Decimal", "0"))
+}
+
+func date(parts ...int) string {
+	names := []string{"year", "month", "day"}
+	var fields []string
+	for i, p := range parts {
+		fields = append(fields, fmt.Sprintf("%q:%s", names[i], integer(p)))
+	}
+	return fmt.Sprintf(`{"type":"Date",%s}`, strings.Join(fields, ","))
+}
+
+func list(elems ...string) string {
+	return fmt.Sprintf(`{"type":"List","element":[%s]}`, strings.Join(elems, ","))
+}
+
+func interval(low, high string, lowClosed, highClosed bool) string {
+	return fmt.Sprintf(`{"type":"Interval","low":%s,"high":%s,"lowClosed":%t,"highClosed":%t}`, low, high, lowClosed, highClosed)
+}
+
+func quantity(value, unit string) string {
+	return fmt.Sprintf(`{"type":"Quantity","value":%s,"unit":%q}`, value, unit)
+}
+
+func fhirHelper(name, operand string) string {
+	return fmt.Sprintf(`{"type":"FunctionRef","libraryName":"FHIRHelpers","name":%q,"operand":[%s]}`, name, operand)
+}
+
+func prop(path, source string) string {
+	return fmt.Sprintf(`{"type":"Property","path":%q,"source":%s}`, path, source)
+}
+
+func scoped(path, scope string) string {
+	return fmt.Sprintf(`{"type":"Property","path":%q,"scope":%q}`, path, scope)
+}
+
+func as(typ, operand string) string {
+	return fmt.Sprintf(`{"type":"As","asType":"{http://hl7.org/fhir}%s","operand":%s}`, typ, operand)
+}
+
+func ref(name string) string { return fmt.Sprintf(`{"type":"ExpressionRef","name":%q}`, name) }
+
+func def(name, expr string) string {
+	return fmt.Sprintf(`{"name":%q,"context":"Patient","expression":%s}`, name, expr)
+}
+
+func testLibrary(defs ...string) string {
+	return `{"library":{
+		"identifier":{"id":"Test","version":"1.0.0"},
+		"usings":{"def":[{"localIdentifier":"FHIR","uri":"http://hl7.org/fhir","version":"4.0.1"}]},
+		"includes":{"def":[{"localIdentifier":"FHIRHelpers","path":"FHIRHelpers","version":"4.0.1"}]},
+		"parameters":{"def":[{"name":"Measurement Period","default":` + interval(dateTime(2024, 1, 1, 0, 0, 0, 0), dateTime(2024, 12, 31, 23, 59, 59, 999), true, true) + `}]},
+		"codeSystems":{"def":[{"name":"LOINC","id":"http://loinc.org"}]},
+		"valueSets":{"def":[{"name":"Diabetes","id":"http://example.com/ValueSet/diabetes"}]},
+		"codes":{"def":[{"name":"Body weight","id":"29463-7","display":"Body weight","codeSystem":{"name":"LOINC"}}]},
+		"concepts":{"def":[{"name":"Weight","display":"Weight","code":[{"name":"Body weight"}]}]},
+		"statements":{"def":[` + strings.Join(defs, ",") + `]}}}`
+}
+
+const snomed = "http://snomed.info/sct"
+
+func testResources() []proto.Message {
+	patient := &ppb.Patient{
+		Id:        &d4pb.Id{Value: "p1"},
+		Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+		BirthDate: &d4pb.Date{ValueUs: time.Date(1980, 6, 15, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.Date_DAY},
+	}
+	other := &ppb.Patient{Id: &d4pb.Id{Value: "p2"}}
+	subject := &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}}
+	weight := func(id string, kg string, month time.Month, subject *d4pb.Reference) *obspb.Observation {
+		return &obspb.Observation{
+			Id:      &d4pb.Id{Value: id},
+			Status:  &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+			Code:    &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: "http://loinc.org"}, Code: &d4pb.Code{Value: "29463-7"}}}},
+			Subject: subject,
+			Effective: &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: &d4pb.DateTime{
+				ValueUs: time.Date(2024, month, 1, 12, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND,
+			}}},
+			Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+				Value: &d4pb.Decimal{Value: kg}, Unit: &d4pb.String{Value: "kg"}, System: &d4pb.Uri{Value: "http://unitsofmeasure.org"}, Code: &d4pb.Code{Value: "kg"},
+			}}},
+		}
+	}
+	diabetes := &cpb.Condition{
+		Id:      &d4pb.Id{Value: "c1"},
+		Subject: subject,
+		Code:    &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: snomed}, Code: &d4pb.Code{Value: "44054006"}}}},
+	}
+	otherWeight := weight("o4", "90", time.March, &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p2"}}})
+	return []proto.Message{
+		patient, other, diabetes, otherWeight,
+		weight("o1", "70.5", time.February, subject),
+		weight("o2", "72", time.May, subject),
+		weight("o3", "69", time.May, subject),
+	}
+}
+
+func diabetesValueSet() *vspb.ValueSet {
+	return &vspb.ValueSet{
+		Url: &d4pb.Uri{Value: "http://example.com/ValueSet/diabetes"},
+		Compose: &vspb.ValueSet_Compose{Include: []*vspb.ValueSet_Compose_ConceptSet{{
+			System:  &d4pb.Uri{Value: snomed},
+			Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{{Code: &d4pb.Code{Value: "44054006"}}},
+		}}},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	patient := `{"type":"SingletonFrom","operand":{"type":"Retrieve","dataType":"{http://hl7.org/fhir}Patient"}}`
+	weights := `{"type":"Retrieve","dataType":"{http://hl7.org/fhir}Observation","codeProperty":"code",
+		"codes":{"type":"ToList","operand":{"type":"CodeRef","name":"Body weight"}}}`
+	effective := fhirHelper("ToDateTime", as("dateTime", scoped("effective", "O")))
+	weightQuery := `{"type":"Query",
+		"source":[{"alias":"O","expression":` + weights + `}],
+		"relationship":[{"type":"With","alias":"D","expression":` + ref("Diabetes Conditions") + `,"suchThat":` + op("Not", op("IsNull", scoped("id", "D"))) + `}],
+		"where":` + opWithPrecision("In", "Day", effective, `{"type":"ParameterRef","name":"Measurement Period"}`) + `,
+		"return":{"distinct":false,"expression":` + fhirHelper("ToQuantity", as("Quantity", scoped("value", "O"))) + `},
+		"sort":{"by":[{"type":"ByDirection","direction":"desc"}]}}`
+	lib, err := Parse([]byte(testLibrary(
+		def("Patient", patient),
+		def("Gender", fhirHelper("ToString", prop("gender", ref("Patient")))),
+		def("Age", opWithPrecision("CalculateAgeAt", "Year",
+			fhirHelper("ToDate", prop("birthDate", ref("Patient"))),
+			op("Start", `{"type":"ParameterRef","name":"Measurement Period"}`))),
+		def("Is Adult", op("GreaterOrEqual", ref("Age"), integer(18))),
+		def("Diabetes Conditions", `{"type":"Retrieve","dataType":"{http://hl7.org/fhir}Condition","codeProperty":"code",
+			"codes":{"type":"ValueSetRef","name":"Diabetes"}}`),
+		def("Has Diabetes", op("Exists", ref("Diabetes Conditions"))),
+		def("Weights", weightQuery),
+		def("Max Weight", op("Max", ref("Weights"))),
+		def("Weight Count", op("Count", ref("Weights"))),
+		def("Doubled Age", `{"type":"FunctionRef","name":"Double","operand":[`+ref("Age")+`]}`),
+		`{"type":"FunctionDef","name":"Double","context":"Patient","operand":[{"name":"x"}],
+			"expression":`+op("Multiply", `{"type":"OperandRef","name":"x"}`, integer(2))+`}`,
+		def("Weight Concept", `{"type":"ConceptRef","name":"Weight"}`),
+	)))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	tp := NewValueSetTerminology(diabetesValueSet())
+	e, err := New(lib, Options{Retriever: NewMemoryRetriever(tp, testResources()...), Terminology: tp})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	got, err := e.Evaluate(context.Background(), "p1", "Gender", "Age", "Is Adult", "Has Diabetes", "Weights", "Max Weight", "Weight Count", "Doubled Age", "Weight Concept")
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	kg := func(v float64) fhirpath.Quantity { return fhirpath.Quantity{Value: v, Unit: "kg"} }
+	want := map[string]interface{}{
+		"Gender":       "female",
+		"Age":          int64(43),
+		"Is Adult":     true,
+		"Has Diabetes": true,
+		"Weights":      []interface{}{kg(72), kg(70.5), kg(69)},
+		"Max Weight":   kg(72),
+		"Weight Count": int64(3),
+		"Doubled Age":  int64(86),
+		"Weight Concept": Concept{
+			Display: "Weight",
+			Codes:   []Code{{System: "http://loinc.org", Code: "29463-7", Display: "Body weight"}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Evaluate() diff (-want +got):\n%s", diff)
+	}
+
+	// The second patient has a weight but no diabetes diagnosis.
+	got, err = e.Evaluate(context.Background(), "p2", "Has Diabetes", "Weights", "Age")
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	want = map[string]interface{}{"Has Diabetes": false, "Weights": []interface{}{}, "Age": nil}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Evaluate() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvaluate_Parameters(t *testing.T) {
+	lib, err := Parse([]byte(testLibrary(def("Year", op("DateTimeComponentFrom",
+		`{"type":"Start","operand":{"type":"ParameterRef","name":"Measurement Period"}}`)))))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	// DateTimeComponentFrom takes its precision from the expression.
+	lib.Statements.Def[0].Expression.Precision = "Year"
+	period := Interval{
+		Low:       fhirpath.Temporal{Kind: fhirpath.DateTime, Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Precision: fhirpath.PrecisionDay},
+		High:      fhirpath.Temporal{Kind: fhirpath.DateTime, Time: time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC), Precision: fhirpath.PrecisionDay},
+		LowClosed: true, HighClosed: true,
+	}
+	for _, test := range []struct {
+		name   string
+		params map[string]interface{}
+		want   int64
+	}{
+		{"default", nil, 2024},
+		{"given", map[string]interface{}{"Measurement Period": period}, 2020},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			e, err := New(lib, Options{Parameters: test.params})
+			if err != nil {
+				t.Fatalf("New() returned unexpected error: %v", err)
+			}
+			got, err := e.Evaluate(context.Background(), "")
+			if err != nil {
+				t.Fatalf("Evaluate() returned unexpected error: %v", err)
+			}
+			if got["Year"] != test.want {
+				t.Errorf("Evaluate() = %v, want %d", got["Year"], test.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate_Includes(t *testing.T) {
+	common := `{"library":{"identifier":{"id":"Common","version":"1"},
+		"statements":{"def":[{"name":"Answer","expression":` + integer(42) + `}]}}}`
+	main := `{"library":{"identifier":{"id":"Main"},
+		"includes":{"def":[{"localIdentifier":"C","path":"Common","version":"1"}]},
+		"statements":{"def":[{"name":"Result","expression":` +
+		op("Add", `{"type":"ExpressionRef","libraryName":"C","name":"Answer"}`, integer(1)) + `}]}}}`
+	resolve := func(name, version string) (*Library, error) {
+		if name != "Common" || version != "1" {
+			return nil, fmt.Errorf("unknown library %s|%s", name, version)
+		}
+		return Parse([]byte(common))
+	}
+	lib, err := Parse([]byte(main))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	e, err := New(lib, Options{Libraries: resolve})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	got, err := e.Evaluate(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	if got["Result"] != int64(43) {
+		t.Errorf("Evaluate() = %v, want 43", got["Result"])
+	}
+	if _, err := New(lib, Options{}); err == nil {
+		t.Errorf("New() without a library resolver succeeded, want error")
+	}
+}
+
+// evaluate evaluates a single ELM expression.
+func evaluate(t *testing.T, expr string) (interface{}, error) {
+	t.Helper()
+	lib, err := Parse([]byte(testLibrary(def("X", expr))))
+	if err != nil {
+		t.Fatalf("Parse(%s) returned unexpected error: %v", expr, err)
+	}
+	e, err := New(lib, Options{Now: time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	got, err := e.Evaluate(context.Background(), "", "X")
+	if err != nil {
+		return nil, err
+	}
+	return got["X"], nil
+}
+
+func TestOperators(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want interface{}
+	}{
+		{"integer addition", op("Add", integer(1), integer(2)), int64(3)},
+		{"decimal division", op("Divide", integer(1), integer(4)), 0.25},
+		{"division by zero", op("Divide", integer(1), integer(0)), nil},
+		{"null propagation", op("Add", integer(1), `{"type":"Null"}`), nil},
+		{"modulo", op("Modulo", integer(7), integer(3)), int64(1)},
+		{"and unknown", op("And", lit("Boolean", "true"), `{"type":"Null"}`), nil},
+		{"and false", op("And", lit("Boolean", "false"), `{"type":"Null"}`), false},
+		{"or true", op("Or", `{"type":"Null"}`, lit("Boolean", "true")), true},
+		{"implies", op("Implies", lit("Boolean", "false"), `{"type":"Null"}`), true},
+		{"equal strings", op("Equal", lit("String", "a"), lit("String", "a")), true},
+		{"equal null", op("Equal", lit("String", "a"), `{"type":"Null"}`), nil},
+		{"equivalent strings", op("Equivalent", lit("String", "A  b"), lit("String", "a b")), true},
+		{"equivalent nulls", op("Equivalent", `{"type":"Null"}`, `{"type":"Null"}`), true},
+		{"less", op("Less", integer(1), lit("Decimal", "1.5")), true},
+		{"uncertain date comparison", op("Less", date(2024), date(2024, 3, 1)), nil},
+		{"quantity comparison", op("Greater", quantity("1", "kg"), quantity("900", "g")), true},
+		{"concatenate", op("Concatenate", lit("String", "a"), lit("String", "b")), "ab"},
+		{"upper", op("Upper", lit("String", "abc")), "ABC"},
+		{"matches", op("Matches", lit("String", "abc"), lit("String", "a.c")), true},
+		{"length", op("Length", list(integer(1), integer(2))), int64(2)},
+		{"indexer", op("Indexer", list(integer(5), integer(6)), integer(1)), int64(6)},
+		{"count skips nulls", op("Count", list(integer(1), `{"type":"Null"}`)), int64(1)},
+		{"sum", op("Sum", list(integer(1), integer(2), integer(3))), int64(6)},
+		{"avg", op("Avg", list(integer(1), integer(2))), 1.5},
+		{"min", op("Min", list(integer(3), integer(1), integer(2))), int64(1)},
+		{"first", op("First", list(integer(3), integer(1))), int64(3)},
+		{"singleton of empty", op("SingletonFrom", list()), nil},
+		{"distinct", op("Distinct", list(integer(1), integer(1), integer(2))), []interface{}{int64(1), int64(2)}},
+		{"union", op("Union", list(integer(1), integer(2)), list(integer(2), integer(3))), []interface{}{int64(1), int64(2), int64(3)}},
+		{"intersect", op("Intersect", list(integer(1), integer(2)), list(integer(2), integer(3))), []interface{}{int64(2)}},
+		{"except", op("Except", list(integer(1), integer(2)), list(integer(2))), []interface{}{int64(1)}},
+		{"flatten", op("Flatten", list(list(integer(1)), list(integer(2)))), []interface{}{int64(1), int64(2)}},
+		{"in list", op("In", integer(2), list(integer(1), integer(2))), true},
+		{"in interval", op("In", integer(5), interval(integer(1), integer(5), true, false)), false},
+		{"includes interval", op("Includes", interval(integer(1), integer(10), true, true), interval(integer(2), integer(3), true, true)), true},
+		{"overlaps", op("Overlaps", interval(integer(1), integer(5), true, true), interval(integer(5), integer(8), true, true)), true},
+		{"start of open interval", op("Start", interval(integer(1), integer(5), false, true)), int64(2)},
+		{"date in interval at day precision", opWithPrecision("In", "Day", dateTime(2024, 12, 31, 18, 0, 0, 0),
+			interval(date(2024, 1, 1), date(2024, 12, 31), true, true)), true},
+		{"before", opWithPrecision("Before", "Day", dateTime(2024, 1, 1, 23, 0, 0, 0), dateTime(2024, 1, 2, 0, 0, 0, 0)), true},
+		{"same or after", opWithPrecision("SameOrAfter", "Month", date(2024, 2, 1), date(2024, 2, 28)), true},
+		{"date arithmetic", op("Add", date(2024, 1, 31), quantity("1", "month")),
+			fhirpath.Temporal{Kind: fhirpath.Date, Time: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Precision: fhirpath.PrecisionDay}},
+		{"duration in years", opWithPrecision("DurationBetween", "Year", date(2000, 3, 16), date(2024, 3, 15)), int64(23)},
+		{"duration in months", opWithPrecision("DurationBetween", "Month", date(2024, 1, 31), date(2024, 2, 29)), int64(0)},
+		{"difference in years", opWithPrecision("DifferenceBetween", "Year", date(2023, 12, 31), date(2024, 1, 1)), int64(1)},
+		{"duration in days", opWithPrecision("DurationBetween", "Day", dateTime(2024, 3, 1, 12, 0, 0, 0), dateTime(2024, 3, 3, 11, 0, 0, 0)), int64(1)},
+		{"uncertain duration", opWithPrecision("DurationBetween", "Day", date(2024), date(2024, 3, 1)), nil},
+		{"age", opWithPrecision("CalculateAge", "Year", date(2000, 3, 16)), int64(23)},
+		{"component", opWithPrecision("DateTimeComponentFrom", "Month", date(2024, 7, 4)), int64(7)},
+		{"today", op("Today"), fhirpath.Temporal{Kind: fhirpath.Date, Time: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), Precision: fhirpath.PrecisionDay}},
+		{"to integer", op("ToInteger", lit("String", "12")), int64(12)},
+		{"to string", op("ToString", lit("Decimal", "1.5")), "1.5"},
+		{"to date time", op("ToDateTime", lit("String", "2024-01-02")),
+			fhirpath.Temporal{Kind: fhirpath.DateTime, Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Precision: fhirpath.PrecisionDay}},
+		{"if", `{"type":"If","condition":` + lit("Boolean", "false") + `,"then":` + integer(1) + `,"else":` + integer(2) + `}`, int64(2)},
+		{"case", `{"type":"Case","comparand":` + integer(2) + `,"caseItem":[{"when":` + integer(1) + `,"then":` + lit("String", "one") + `},{"when":` + integer(2) + `,"then":` + lit("String", "two") + `}],"else":{"type":"Null"}}`, "two"},
+		{"coalesce", op("Coalesce", `{"type":"Null"}`, integer(3)), int64(3)},
+		{"is", `{"type":"Is","isType":"{urn:hl7-org:elm-types:r1}Integer","operand":` + integer(1) + `}`, true},
+		{"as mismatch", `{"type":"As","asType":"{urn:hl7-org:elm-types:r1}String","operand":` + integer(1) + `}`, nil},
+		{"tuple property", prop("b", `{"type":"Tuple","element":[{"name":"a","value":`+integer(1)+`},{"name":"b","value":`+integer(2)+`}]}`), int64(2)},
+		{"code equivalence", op("Equivalent",
+			`{"type":"Code","code":"29463-7","system":{"type":"CodeSystemRef","name":"LOINC"},"display":"Weight"}`,
+			`{"type":"CodeRef","name":"Body weight"}`), true},
+		{"query over list", `{"type":"Query","source":[{"alias":"X","expression":` + list(integer(3), integer(1), integer(2), integer(1)) + `}],
+			"where":` + op("Greater", `{"type":"AliasRef","name":"X"}`, integer(1)) + `,
+			"return":{"expression":` + op("Multiply", `{"type":"AliasRef","name":"X"}`, integer(10)) + `},
+			"sort":{"by":[{"type":"ByDirection","direction":"asc"}]}}`, []interface{}{int64(20), int64(30)}},
+		{"multi-source query", `{"type":"Query","source":[
+			{"alias":"A","expression":` + list(integer(1), integer(2)) + `},
+			{"alias":"B","expression":` + list(integer(10)) + `}],
+			"let":[{"identifier":"S","expression":` + op("Add", `{"type":"AliasRef","name":"A"}`, `{"type":"AliasRef","name":"B"}`) + `}],
+			"return":{"expression":{"type":"QueryLetRef","name":"S"}}}`, []interface{}{int64(11), int64(12)}},
+		{"singular query", `{"type":"Query","source":[{"alias":"X","expression":` + integer(5) + `}],
+			"where":` + op("Less", `{"type":"AliasRef","name":"X"}`, integer(3)) + `}`, nil},
+		{"sort by column", prop("a", op("First", `{"type":"Query","source":[{"alias":"T","expression":`+list(
+			`{"type":"Tuple","element":[{"name":"a","value":`+integer(2)+`}]}`,
+			`{"type":"Tuple","element":[{"name":"a","value":`+integer(1)+`}]}`)+`}],
+			"sort":{"by":[{"type":"ByColumn","path":"a","direction":"asc"}]}}`)), int64(1)},
+		{"sort by expression", op("First", `{"type":"Query","source":[{"alias":"T","expression":`+list(
+			`{"type":"Tuple","element":[{"name":"a","value":`+integer(2)+`}]}`,
+			`{"type":"Tuple","element":[{"name":"a","value":`+integer(1)+`}]}`)+`}],
+			"return":{"expression":{"type":"AliasRef","name":"T"}},
+			"sort":{"by":[{"type":"ByExpression","direction":"desc","expression":{"type":"IdentifierRef","name":"a"}}]}}`),
+			Tuple{"a": int64(2)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := evaluate(t, test.expr)
+			if err != nil {
+				t.Fatalf("Evaluate() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Evaluate() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOperators_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"unsupported operator", op("Bogus", integer(1))},
+		{"singleton of many", op("SingletonFrom", list(integer(1), integer(2)))},
+		{"incompatible comparison", op("Less", integer(1), lit("String", "a"))},
+		{"strict cast", `{"type":"As","strict":true,"asType":"{urn:hl7-org:elm-types:r1}String","operand":` + integer(1) + `}`},
+		{"unknown expression", ref("Missing")},
+		{"retrieve without provider", `{"type":"Retrieve","dataType":"{http://hl7.org/fhir}Patient"}`},
+		{"value set without terminology", `{"type":"InValueSet","code":{"type":"CodeRef","name":"Body weight"},"valueset":{"type":"ValueSetRef","name":"Diabetes"}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got, err := evaluate(t, test.expr); err == nil {
+				t.Errorf("Evaluate() = %v, want error", got)
+			}
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	lib, err := Parse([]byte(testLibrary(`{"type":"FunctionDef","name":"Add","context":"Patient","operand":[{"name":"a"},{"name":"b"}],
+		"expression":` + op("Add", `{"type":"OperandRef","name":"a"}`, `{"type":"OperandRef","name":"b"}`) + `}`)))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	e, err := New(lib, Options{})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	got, err := e.Call(context.Background(), "", "Add", int64(1), int64(2))
+	if err != nil {
+		t.Fatalf("Call() returned unexpected error: %v", err)
+	}
+	if got != int64(3) {
+		t.Errorf("Call() = %v, want 3", got)
+	}
+	if _, err := e.Call(context.Background(), "", "Add", int64(1)); err == nil {
+		t.Errorf("Call() with one argument succeeded, want error")
+	}
+}
+
+func TestFromResource(t *testing.T) {
+	data := []byte(`{"library":{"identifier":{"id":"Answer"},"statements":{"def":[{"name":"X","expression":` + integer(42) + `}]}}}`)
+	lib, err := FromResource(&lpb.Library{Content: []*d4pb.Attachment{
+		{ContentType: &d4pb.Attachment_ContentTypeCode{Value: "text/cql"}, Data: &d4pb.Base64Binary{Value: []byte("library Answer")}},
+		{ContentType: &d4pb.Attachment_ContentTypeCode{Value: ELMContentType}, Data: &d4pb.Base64Binary{Value: data}},
+	}})
+	if err != nil {
+		t.Fatalf("FromResource() returned unexpected error: %v", err)
+	}
+	if lib.Identifier.ID != "Answer" {
+		t.Errorf("FromResource() returned library %q, want Answer", lib.Identifier.ID)
+	}
+	if _, err := FromResource(&lpb.Library{}); err == nil {
+		t.Errorf("FromResource() without ELM content succeeded, want error")
+	}
+}
+
+func TestProtoValue(t *testing.T) {
+	loinc := Code{System: "http://loinc.org", Code: "29463-7", Display: "Body weight"}
+	coding := &d4pb.Coding{
+		System: &d4pb.Uri{Value: "http://loinc.org"}, Code: &d4pb.Code{Value: "29463-7"}, Display: &d4pb.String{Value: "Body weight"},
+	}
+	day := func(d int) fhirpath.Temporal {
+		return fhirpath.Temporal{Kind: fhirpath.Date, Time: time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC), Precision: fhirpath.PrecisionDay}
+	}
+	dateTime := func(d int) *d4pb.DateTime {
+		return &d4pb.DateTime{ValueUs: time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_DAY}
+	}
+	tests := []struct {
+		name  string
+		value interface{}
+		want  proto.Message
+	}{
+		{"code", loinc, coding},
+		{"concept", Concept{Codes: []Code{loinc}, Display: "weight"}, &d4pb.CodeableConcept{Coding: []*d4pb.Coding{coding}, Text: &d4pb.String{Value: "weight"}}},
+		{"date interval", Interval{Low: day(1), High: day(31), LowClosed: true, HighClosed: true}, &d4pb.Period{Start: dateTime(1), End: dateTime(31)}},
+		{"quantity interval", Interval{Low: fhirpath.Quantity{Value: 1, Unit: "kg"}, LowClosed: true}, &d4pb.Range{Low: &d4pb.SimpleQuantity{
+			Value: &d4pb.Decimal{Value: "1"}, Unit: &d4pb.String{Value: "kg"}, System: &d4pb.Uri{Value: "http://unitsofmeasure.org"}, Code: &d4pb.Code{Value: "kg"},
+		}}},
+		{"string", "urgent", &d4pb.String{Value: "urgent"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := ProtoValue(test.value)
+			if !ok {
+				t.Fatalf("ProtoValue(%v) failed", test.value)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ProtoValue(%v) diff (-want +got):\n%s", test.value, diff)
+			}
+		})
+	}
+	if _, ok := ProtoValue([]interface{}{int64(1)}); ok {
+		t.Errorf("ProtoValue() of a list succeeded, want failure")
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"fmt"
+
+	"github.com/google/fhir/go/fhirpath"
+	"google.golang.org/protobuf/proto"
+)
+
+// fhirHelperFunctions implements the functions of the FHIRHelpers library
+// that the CQL-to-ELM translator inserts to convert FHIR elements to system
+// values.
+var fhirHelperFunctions = map[string]func(interface{}) (interface{}, error){
+	"ToBoolean":                    helper("Boolean"),
+	"ToInteger":                    helper("Integer"),
+	"ToDecimal":                    helper("Decimal"),
+	"ToString":                     helper("String"),
+	"ToDate":                       helper("Date"),
+	"ToDateTime":                   helper("DateTime"),
+	"ToTime":                       helper("Time"),
+	"ToQuantity":                   helper("Quantity"),
+	"ToQuantityIgnoringComparator": helper("Quantity"),
+	"ToCode":                       helper("Code"),
+	"ToConcept":                    helper("Concept"),
+	"ToInterval":                   helper("Interval"),
+	"ToValue":                      func(v interface{}) (interface{}, error) { return system(v), nil },
+}
+
+// helper returns a FHIRHelpers conversion to the system type typ.
+func helper(typ string) func(interface{}) (interface{}, error) {
+	return func(v interface{}) (interface{}, error) {
+		if v == nil {
+			return nil, nil
+		}
+		m, ok := v.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("FHIRHelpers.To%s of %s", typ, typeName(v))
+		}
+		sv := system(m)
+		if sv == nil {
+			return nil, nil
+		}
+		if typ == "DateTime" || typ == "Date" {
+			if t, ok := sv.(fhirpath.Temporal); ok && t.Kind != fhirpath.Time {
+				return sv, nil
+			}
+		}
+		if typeName(sv) != typ {
+			return nil, fmt.Errorf("FHIRHelpers.To%s of %s", typ, typeName(v))
+		}
+		return sv, nil
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	lpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/library_go_proto"
+)
+
+const (
+	// ELMContentType is the media type of ELM JSON attached to a FHIR
+	// Library.
+	ELMContentType = "application/elm+json"
+	// CQLContentType is the media type of CQL source attached to a FHIR
+	// Library.
+	CQLContentType = "text/cql"
+)
+
+// ErrNoContent is returned for Libraries without content of an acceptable
+// type.
+var ErrNoContent = errors.New("no acceptable content")
+
+// Content is a representation of a FHIR Library: the decoded data of one of
+// its content Attachments.
+type Content struct {
+	// ContentType is the lowercase media type of the content, without
+	// parameters, i.e. "application/elm+json".
+	ContentType string
+	// Params are the parameters of the content type, such as the charset
+	// of text or the version of CQL, with lowercase names.
+	Params map[string]string
+	// Data is the content. Text in other charsets than UTF-8 is converted
+	// to UTF-8.
+	Data []byte
+}
+
+// Contents returns the representations of lib that are inline, in the order
+// of lib.content; content held in Binaries must be inlined first, as by
+// attachment.Inline. Data is checked against the size and hash of its
+// Attachment.
+func Contents(lib *lpb.Library) ([]Content, error) {
+	var out []Content
+	for i, a := range lib.GetContent() {
+		if a.GetData() == nil {
+			continue
+		}
+		ct, params, err := mime.ParseMediaType(a.GetContentType().GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("content %d: invalid content type %q: %w", i, a.GetContentType().GetValue(), err)
+		}
+		data := a.GetData().GetValue()
+		if a.GetSize() != nil && int64(a.GetSize().GetValue()) != int64(len(data)) {
+			return nil, fmt.Errorf("content %d: data is %d bytes, the attachment declares %d", i, len(data), a.GetSize().GetValue())
+		}
+		if sum := sha1.Sum(data); a.GetHash() != nil && !bytes.Equal(a.GetHash().GetValue(), sum[:]) {
+			return nil, fmt.Errorf("content %d: data does not match the attachment hash", i)
+		}
+		if strings.HasPrefix(ct, "text/") || strings.HasSuffix(ct, "+json") {
+			if data, err = toUTF8(data, params["charset"]); err != nil {
+				return nil, fmt.Errorf("content %d: %w", i, err)
+			}
+		}
+		out = append(out, Content{ContentType: ct, Params: params, Data: data})
+	}
+	return out, nil
+}
+
+// toUTF8 returns text in charset as UTF-8, without a byte order mark.
+func toUTF8(text []byte, charset string) ([]byte, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii":
+		text = bytes.TrimPrefix(text, []byte("\xef\xbb\xbf"))
+		if !utf8.Valid(text) {
+			return nil, fmt.Errorf("text is not valid UTF-8")
+		}
+		return text, nil
+	case "iso-8859-1", "latin1":
+		var b bytes.Buffer
+		for _, c := range text {
+			b.WriteRune(rune(c))
+		}
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
+
+// acceptRange is a media range of an Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+	index        int
+}
+
+func (r acceptRange) matches(contentType string) bool {
+	typ, subtype, _ := strings.Cut(contentType, "/")
+	return (r.typ == "*" || r.typ == typ) && (r.subtype == "*" || r.subtype == subtype)
+}
+
+// specificity orders ranges matching the same type, so that the most
+// specific one gives its quality.
+func (r acceptRange) specificity() int {
+	switch {
+	case r.typ == "*":
+		return 0
+	case r.subtype == "*":
+		return 1
+	}
+	return 2
+}
+
+// Negotiate picks the preferred of contents for accept, a list of media
+// ranges with optional quality values in the form of an HTTP Accept header,
+// i.e. "application/elm+json, text/cql;q=0.5". Contents of higher quality
+// are preferred, and then those of ranges listed earlier and contents
+// listed earlier. Contents of quality 0 are never picked.
+func Negotiate(contents []Content, accept string) (Content, bool) {
+	var ranges []acceptRange
+	for i, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		r := acceptRange{q: 1, index: i}
+		r.typ, r.subtype, _ = strings.Cut(mt, "/")
+		if q, ok := params["q"]; ok {
+			if r.q, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	type candidate struct {
+		content Content
+		r       acceptRange
+		index   int
+	}
+	var candidates []candidate
+	for i, c := range contents {
+		best, found := acceptRange{}, false
+		for _, r := range ranges {
+			if r.matches(c.ContentType) && (!found || r.specificity() > best.specificity()) {
+				best, found = r, true
+			}
+		}
+		if found && best.q > 0 {
+			candidates = append(candidates, candidate{c, best, i})
+		}
+	}
+	if len(candidates) == 0 {
+		return Content{}, false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.r.q != b.r.q {
+			return a.r.q > b.r.q
+		}
+		return a.r.index < b.r.index
+	})
+	return candidates[0].content, true
+}
+
+// Preferred returns the representation of lib that accept, as by Negotiate,
+// prefers.
+func Preferred(lib *lpb.Library, accept string) (Content, error) {
+	contents, err := Contents(lib)
+	if err != nil {
+		return Content{}, fmt.Errorf("library %s: %w", libraryName(lib), err)
+	}
+	c, ok := Negotiate(contents, accept)
+	if !ok {
+		return Content{}, fmt.Errorf("library %s: %w for %q", libraryName(lib), ErrNoContent, accept)
+	}
+	return c, nil
+}
+
+// FromResource parses the ELM JSON content of a FHIR Library resource, as
+// published with measures and decision support artifacts.
+func FromResource(lib *lpb.Library) (*Library, error) {
+	c, err := Preferred(lib, ELMContentType)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(c.Data)
+}
+
+// NewFromResource returns an Engine of the ELM content of the FHIR Library
+// lib, as by FromResource and New.
+func NewFromResource(lib *lpb.Library, opts Options) (*Engine, error) {
+	elm, err := FromResource(lib)
+	if err != nil {
+		return nil, err
+	}
+	return New(elm, opts)
+}
+
+// ResourceResolver returns a LibraryResolver of the ELM content of FHIR
+// Libraries, for the includes of libraries loaded from resources. Libraries
+// are found by the identifier of their ELM, and an empty version picks the
+// last of libs with the name.
+func ResourceResolver(libs ...*lpb.Library) (LibraryResolver, error) {
+	var elms []*Library
+	for _, lib := range libs {
+		elm, err := FromResource(lib)
+		if err != nil {
+			return nil, err
+		}
+		elms = append(elms, elm)
+	}
+	return func(name, version string) (*Library, error) {
+		var found *Library
+		for _, elm := range elms {
+			if elm.Identifier.ID == name && (version == "" || elm.Identifier.Version == version) {
+				found = elm
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("no library %s version %q", name, version)
+		}
+		return found, nil
+	}, nil
+}
+
+// libraryName names lib in errors.
+func libraryName(lib *lpb.Library) string {
+	if url := lib.GetUrl().GetValue(); url != "" {
+		return url
+	}
+	if name := lib.GetName().GetValue(); name != "" {
+		return name
+	}
+	return lib.GetId().GetValue()
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"context"
+	"crypto/sha1"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	lpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/library_go_proto"
+)
+
+func content(contentType string, data []byte) *d4pb.Attachment {
+	return &d4pb.Attachment{ContentType: &d4pb.Attachment_ContentTypeCode{Value: contentType}, Data: &d4pb.Base64Binary{Value: data}}
+}
+
+func TestContents(t *testing.T) {
+	elm := []byte(`{"library":{}}`)
+	sum := sha1.Sum(elm)
+	hashed := content("application/elm+json", elm)
+	hashed.Size = &d4pb.UnsignedInt{Value: uint32(len(elm))}
+	hashed.Hash = &d4pb.Base64Binary{Value: sum[:]}
+	lib := &lpb.Library{Content: []*d4pb.Attachment{
+		content("text/cql; charset=ISO-8859-1; version=1.5", []byte("define \"Caf\xe9\": 1")),
+		hashed,
+		content("text/cql", []byte("\xef\xbb\xbflibrary Answer")),
+		{ContentType: &d4pb.Attachment_ContentTypeCode{Value: "application/elm+xml"}, Url: &d4pb.Url{Value: "Binary/1"}},
+	}}
+	got, err := Contents(lib)
+	if err != nil {
+		t.Fatalf("Contents() returned unexpected error: %v", err)
+	}
+	want := []Content{
+		{ContentType: "text/cql", Params: map[string]string{"charset": "ISO-8859-1", "version": "1.5"}, Data: []byte("define \"Café\": 1")},
+		{ContentType: "application/elm+json", Params: map[string]string{}, Data: elm},
+		{ContentType: "text/cql", Params: map[string]string{}, Data: []byte("library Answer")},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Contents() diff (-want +got):\n%s", diff)
+	}
+
+	for name, a := range map[string]*d4pb.Attachment{
+		"bad hash":         {ContentType: hashed.ContentType, Data: &d4pb.Base64Binary{Value: []byte("{}")}, Hash: hashed.Hash},
+		"bad size":         {ContentType: hashed.ContentType, Data: &d4pb.Base64Binary{Value: []byte("{}")}, Size: hashed.Size},
+		"bad content type": content("text/", []byte("x")),
+		"bad charset":      content("text/cql; charset=ebcdic", []byte("x")),
+		"invalid UTF-8":    content("text/cql", []byte("\xff")),
+	} {
+		if _, err := Contents(&lpb.Library{Content: []*d4pb.Attachment{a}}); err == nil {
+			t.Errorf("Contents() with %s succeeded, want error", name)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	contents := []Content{
+		{ContentType: "text/cql"},
+		{ContentType: "application/elm+xml"},
+		{ContentType: "application/elm+json"},
+	}
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"application/elm+json", "application/elm+json"},
+		{"text/cql, application/elm+json", "text/cql"},
+		{"text/cql;q=0.5, application/elm+json", "application/elm+json"},
+		{"application/*", "application/elm+xml"},
+		{"*/*", "text/cql"},
+		{"*/*, text/cql;q=0", "application/elm+xml"},
+		{"application/elm+json;q=0.1, application/*;q=0.2", "application/elm+xml"},
+		{"image/png", ""},
+		{"", ""},
+	}
+	for _, tc := range tests {
+		got, ok := Negotiate(contents, tc.accept)
+		if got.ContentType != tc.want || ok != (tc.want != "") {
+			t.Errorf("Negotiate(%q) = %q, %v, want %q", tc.accept, got.ContentType, ok, tc.want)
+		}
+	}
+}
+
+func TestNewFromResource(t *testing.T) {
+	common := &lpb.Library{Content: []*d4pb.Attachment{content(ELMContentType+"; charset=utf-8", []byte(`{"library":{"identifier":{"id":"Common","version":"1"},
+		"statements":{"def":[{"name":"Answer","expression":`+integer(42)+`}]}}}`))}}
+	main := &lpb.Library{Content: []*d4pb.Attachment{
+		content(CQLContentType, []byte("library Main")),
+		content(ELMContentType, []byte(`{"library":{"identifier":{"id":"Main"},
+		"includes":{"def":[{"localIdentifier":"C","path":"Common","version":"1"}]},
+		"statements":{"def":[{"name":"Result","expression":`+
+			op("Add", `{"type":"ExpressionRef","libraryName":"C","name":"Answer"}`, integer(1))+`}]}}}`)),
+	}}
+	resolve, err := ResourceResolver(common)
+	if err != nil {
+		t.Fatalf("ResourceResolver() returned unexpected error: %v", err)
+	}
+	e, err := NewFromResource(main, Options{Libraries: resolve})
+	if err != nil {
+		t.Fatalf("NewFromResource() returned unexpected error: %v", err)
+	}
+	got, err := e.Evaluate(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	if got["Result"] != int64(43) {
+		t.Errorf("Evaluate() = %v, want 43", got["Result"])
+	}
+	if _, err := resolve("Common", "2"); err == nil {
+		t.Errorf("resolve(Common, 2) succeeded, want error")
+	}
+	if _, err := NewFromResource(&lpb.Library{Content: main.Content[:1]}, Options{}); err == nil {
+		t.Errorf("NewFromResource() with only CQL source succeeded, want error")
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+var (
+	// compartmentReferences finds the references that place a resource in a
+	// patient's compartment.
+	compartmentReferences = fhirpath.MustCompile("subject.reference | patient.reference | beneficiary.reference | individual.reference")
+	resourceID            = fhirpath.MustCompile("id")
+)
+
+// MemoryRetriever is a RetrieveProvider over a fixed set of resources, for
+// tests and for evaluating libraries against a Bundle. In the Patient
+// context it returns the patient with the context id and the resources
+// whose subject, patient, beneficiary or individual refers to it.
+type MemoryRetriever struct {
+	resources   []proto.Message
+	terminology TerminologyProvider
+
+	mu    sync.Mutex
+	paths map[string]*fhirpath.Expression
+}
+
+// NewMemoryRetriever returns a retriever over resources. terminology is used
+// for value set filters and may be nil if the libraries use none.
+func NewMemoryRetriever(terminology TerminologyProvider, resources ...proto.Message) *MemoryRetriever {
+	var rs []proto.Message
+	for _, r := range resources {
+		if r = elementpath.Unwrap(r); r != nil {
+			rs = append(rs, r)
+		}
+	}
+	return &MemoryRetriever{resources: rs, terminology: terminology, paths: map[string]*fhirpath.Expression{}}
+}
+
+// Retrieve implements RetrieveProvider.
+func (r *MemoryRetriever) Retrieve(ctx context.Context, req *RetrieveRequest) ([]proto.Message, error) {
+	var out []proto.Message
+	for _, res := range r.resources {
+		if elementpath.ResourceType(res) != req.DataType {
+			continue
+		}
+		ok, err := r.matches(ctx, res, req)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, res)
+		}
+	}
+	return out, nil
+}
+
+func (r *MemoryRetriever) matches(ctx context.Context, res proto.Message, req *RetrieveRequest) (bool, error) {
+	if req.Context == "Patient" && req.ContextValue != "" {
+		in, err := inPatientCompartment(res, req.ContextValue)
+		if err != nil || !in {
+			return false, err
+		}
+	}
+	if req.CodePath != "" && (req.ValueSet != nil || req.Codes != nil) {
+		values, err := r.evaluate(req.CodePath, res)
+		if err != nil {
+			return false, err
+		}
+		if ok, err := r.codesMatch(ctx, codesOf([]interface{}(values)), req); err != nil || !ok {
+			return false, err
+		}
+	}
+	if req.DatePath != "" && req.DateRange != nil {
+		values, err := r.evaluate(req.DatePath, res)
+		if err != nil {
+			return false, err
+		}
+		if len(values) != 1 {
+			return false, nil
+		}
+		var in interface{}
+		switch v := system(values[0]).(type) {
+		case fhirpath.Temporal:
+			in, err = inInterval(v, *req.DateRange, "")
+		case Interval:
+			in, err = operators["Overlaps"](nil, &Expression{}, []interface{}{v, *req.DateRange})
+		}
+		if err != nil || in != true {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func inPatientCompartment(res proto.Message, id string) (bool, error) {
+	if elementpath.ResourceType(res) == "Patient" {
+		ids, err := resourceID.Evaluate(res)
+		if err != nil {
+			return false, err
+		}
+		got, _ := ids.StringValue()
+		return got == id, nil
+	}
+	refs, err := compartmentReferences.Evaluate(res)
+	if err != nil {
+		return false, err
+	}
+	for _, ref := range refs {
+		if s, ok := ref.(string); ok && s == "Patient/"+id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryRetriever) codesMatch(ctx context.Context, codes []Code, req *RetrieveRequest) (bool, error) {
+	for _, c := range codes {
+		if req.ValueSet != nil {
+			if r.terminology == nil {
+				return false, fmt.Errorf("value set filter on %s requires a TerminologyProvider", req.DataType)
+			}
+			in, err := r.terminology.InValueSet(ctx, c, *req.ValueSet)
+			if err != nil {
+				return false, err
+			}
+			if in {
+				return true, nil
+			}
+			continue
+		}
+		for _, want := range req.Codes {
+			if c.Code == want.Code && (want.System == "" || c.System == want.System) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *MemoryRetriever) evaluate(path string, res proto.Message) (fhirpath.Collection, error) {
+	r.mu.Lock()
+	expr, ok := r.paths[path]
+	if !ok {
+		var err error
+		if expr, err = fhirpath.Compile(path); err != nil {
+			r.mu.Unlock()
+			return nil, err
+		}
+		r.paths[path] = expr
+	}
+	r.mu.Unlock()
+	return expr.Evaluate(res)
+}
+
+// ValueSetTerminology is a TerminologyProvider over a fixed set of value
+// sets. Membership is determined from the expansion of a value set if it has
+// one and otherwise from the concepts enumerated by its compose; filters and
+// value set imports are not supported.
+type ValueSetTerminology struct {
+	valueSets map[string]*vspb.ValueSet
+}
+
+// NewValueSetTerminology returns a provider for the value sets, which are
+// identified by their url.
+func NewValueSetTerminology(valueSets ...*vspb.ValueSet) *ValueSetTerminology {
+	t := &ValueSetTerminology{valueSets: map[string]*vspb.ValueSet{}}
+	for _, vs := range valueSets {
+		t.valueSets[vs.GetUrl().GetValue()] = vs
+	}
+	return t
+}
+
+// InValueSet implements TerminologyProvider.
+func (t *ValueSetTerminology) InValueSet(_ context.Context, code Code, ref ValueSet) (bool, error) {
+	vs, ok := t.valueSets[ref.ID]
+	if !ok {
+		return false, fmt.Errorf("unknown value set %q", ref.ID)
+	}
+	if exp := vs.GetExpansion(); exp != nil {
+		return expansionContains(exp.GetContains(), code), nil
+	}
+	compose := vs.GetCompose()
+	for _, set := range compose.GetExclude() {
+		if conceptSetContains(set, code) {
+			return false, nil
+		}
+	}
+	for _, set := range compose.GetInclude() {
+		if len(set.GetFilter()) > 0 || len(set.GetValueSet()) > 0 {
+			return false, fmt.Errorf("value set %q uses filters or imports, which are not supported", ref.ID)
+		}
+		if conceptSetContains(set, code) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func expansionContains(contains []*vspb.ValueSet_Expansion_Contains, code Code) bool {
+	for _, c := range contains {
+		if c.GetSystem().GetValue() == code.System && c.GetCode().GetValue() == code.Code {
+			return true
+		}
+		if expansionContains(c.GetContains(), code) {
+			return true
+		}
+	}
+	return false
+}
+
+func conceptSetContains(set *vspb.ValueSet_Compose_ConceptSet, code Code) bool {
+	if set.GetSystem().GetValue() != code.System {
+		return false
+	}
+	if len(set.GetConcept()) == 0 {
+		return true
+	}
+	for _, c := range set.GetConcept() {
+		if c.GetCode().GetValue() == code.Code {
+			return true
+		}
+	}
+	return false
+}
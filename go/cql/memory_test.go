@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+func TestMemoryRetriever(t *testing.T) {
+	tp := NewValueSetTerminology(diabetesValueSet())
+	r := NewMemoryRetriever(tp, testResources()...)
+	may := Interval{
+		Low:  mustTemporal(t, "@2024-05-01T00:00:00.000Z"),
+		High: mustTemporal(t, "@2024-05-31T23:59:59.999Z"), LowClosed: true, HighClosed: true,
+	}
+	tests := []struct {
+		name string
+		req  *RetrieveRequest
+		want []string
+	}{
+		{
+			name: "all of type",
+			req:  &RetrieveRequest{DataType: "Patient"},
+			want: []string{"p1", "p2"},
+		},
+		{
+			name: "patient context",
+			req:  &RetrieveRequest{DataType: "Observation", Context: "Patient", ContextValue: "p2"},
+			want: []string{"o4"},
+		},
+		{
+			name: "patient resource in its own compartment",
+			req:  &RetrieveRequest{DataType: "Patient", Context: "Patient", ContextValue: "p1"},
+			want: []string{"p1"},
+		},
+		{
+			name: "codes",
+			req: &RetrieveRequest{DataType: "Observation", Context: "Patient", ContextValue: "p1",
+				CodePath: "code", Codes: []Code{{System: "http://loinc.org", Code: "29463-7"}}},
+			want: []string{"o1", "o2", "o3"},
+		},
+		{
+			name: "no matching code",
+			req:  &RetrieveRequest{DataType: "Observation", CodePath: "code", Codes: []Code{{Code: "8302-2"}}},
+		},
+		{
+			name: "value set",
+			req: &RetrieveRequest{DataType: "Condition", CodePath: "code",
+				ValueSet: &ValueSet{ID: "http://example.com/ValueSet/diabetes"}},
+			want: []string{"c1"},
+		},
+		{
+			name: "date range",
+			req: &RetrieveRequest{DataType: "Observation", Context: "Patient", ContextValue: "p1",
+				DatePath: "effective", DateRange: &may},
+			want: []string{"o2", "o3"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := r.Retrieve(context.Background(), test.req)
+			if err != nil {
+				t.Fatalf("Retrieve() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, ids(got)); diff != "" {
+				t.Errorf("Retrieve() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func mustTemporal(t *testing.T, literal string) fhirpath.Temporal {
+	t.Helper()
+	c, err := fhirpath.MustCompile(literal).Evaluate(nil)
+	if err != nil || len(c) != 1 {
+		t.Fatalf("Evaluate(%s) = %v, %v", literal, c, err)
+	}
+	return c[0].(fhirpath.Temporal)
+}
+
+func ids(resources []proto.Message) []string {
+	var out []string
+	for _, r := range resources {
+		c, _ := resourceID.Evaluate(r)
+		id, _ := c.StringValue()
+		out = append(out, id)
+	}
+	return out
+}
+
+func TestValueSetTerminology(t *testing.T) {
+	expanded := &vspb.ValueSet{
+		Url: &d4pb.Uri{Value: "http://example.com/ValueSet/expanded"},
+		Expansion: &vspb.ValueSet_Expansion{Contains: []*vspb.ValueSet_Expansion_Contains{{
+			System: &d4pb.Uri{Value: snomed}, Code: &d4pb.Code{Value: "73211009"},
+			Contains: []*vspb.ValueSet_Expansion_Contains{{System: &d4pb.Uri{Value: snomed}, Code: &d4pb.Code{Value: "44054006"}}},
+		}}},
+	}
+	whole := &vspb.ValueSet{
+		Url: &d4pb.Uri{Value: "http://example.com/ValueSet/whole"},
+		Compose: &vspb.ValueSet_Compose{
+			Include: []*vspb.ValueSet_Compose_ConceptSet{{System: &d4pb.Uri{Value: snomed}}},
+			Exclude: []*vspb.ValueSet_Compose_ConceptSet{{
+				System:  &d4pb.Uri{Value: snomed},
+				Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{{Code: &d4pb.Code{Value: "73211009"}}},
+			}},
+		},
+	}
+	tp := NewValueSetTerminology(diabetesValueSet(), expanded, whole)
+	tests := []struct {
+		name string
+		code Code
+		vs   string
+		want bool
+	}{
+		{"enumerated", Code{System: snomed, Code: "44054006"}, "http://example.com/ValueSet/diabetes", true},
+		{"other system", Code{System: "http://loinc.org", Code: "44054006"}, "http://example.com/ValueSet/diabetes", false},
+		{"nested expansion", Code{System: snomed, Code: "44054006"}, "http://example.com/ValueSet/expanded", true},
+		{"not in expansion", Code{System: snomed, Code: "1"}, "http://example.com/ValueSet/expanded", false},
+		{"whole system", Code{System: snomed, Code: "1"}, "http://example.com/ValueSet/whole", true},
+		{"excluded", Code{System: snomed, Code: "73211009"}, "http://example.com/ValueSet/whole", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := tp.InValueSet(context.Background(), test.code, ValueSet{ID: test.vs})
+			if err != nil {
+				t.Fatalf("InValueSet() returned unexpected error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("InValueSet(%v, %s) = %t, want %t", test.code, test.vs, got, test.want)
+			}
+		})
+	}
+	if _, err := tp.InValueSet(context.Background(), Code{}, ValueSet{ID: "http://example.com/unknown"}); err == nil {
+		t.Errorf("InValueSet() of an unknown value set succeeded, want error")
+	}
+}
@@ -0,0 +1,1372 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+)
+
+// operatorFunc implements an ELM operator whose operands are all evaluated
+// before it is applied.
+type operatorFunc func(ev *evaluation, x *Expression, args []interface{}) (interface{}, error)
+
+var operators map[string]operatorFunc
+
+func init() {
+	operators = map[string]operatorFunc{
+		// Logic.
+		"Not": unary(func(v interface{}) (interface{}, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("Not of %s", typeName(v))
+			}
+			return !b, nil
+		}),
+		"Xor": binary(func(a, b interface{}) (interface{}, error) {
+			x, okA := a.(bool)
+			y, okB := b.(bool)
+			if !okA || !okB {
+				return nil, fmt.Errorf("Xor of %s and %s", typeName(a), typeName(b))
+			}
+			return x != y, nil
+		}),
+		"IsNull": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			return system(args[0]) == nil, nil
+		},
+		"IsTrue": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			return system(args[0]) == true, nil
+		},
+		"IsFalse": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			return system(args[0]) == false, nil
+		},
+
+		// Comparison.
+		"Equal": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			eq, known := equal(args[0], args[1])
+			if !known {
+				return nil, nil
+			}
+			return eq, nil
+		},
+		"NotEqual": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			eq, known := equal(args[0], args[1])
+			if !known {
+				return nil, nil
+			}
+			return !eq, nil
+		},
+		"Equivalent": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			return equivalent(args[0], args[1]), nil
+		},
+		"Less":           comparison(func(c int) bool { return c < 0 }),
+		"Greater":        comparison(func(c int) bool { return c > 0 }),
+		"LessOrEqual":    comparison(func(c int) bool { return c <= 0 }),
+		"GreaterOrEqual": comparison(func(c int) bool { return c >= 0 }),
+
+		// Arithmetic.
+		"Add":             binary(add),
+		"Subtract":        binary(subtract),
+		"Multiply":        binary(multiply),
+		"Divide":          binary(divide),
+		"TruncatedDivide": binary(func(a, b interface{}) (interface{}, error) { return integerDivision(a, b, false) }),
+		"Modulo":          binary(func(a, b interface{}) (interface{}, error) { return integerDivision(a, b, true) }),
+		"Negate": unary(func(v interface{}) (interface{}, error) {
+			switch x := v.(type) {
+			case int64:
+				return -x, nil
+			case float64:
+				return -x, nil
+			case fhirpath.Quantity:
+				x.Value = -x.Value
+				return x, nil
+			}
+			return nil, fmt.Errorf("cannot negate %s", typeName(v))
+		}),
+		"Abs": unary(func(v interface{}) (interface{}, error) {
+			switch x := v.(type) {
+			case int64:
+				if x < 0 {
+					return -x, nil
+				}
+				return x, nil
+			case float64:
+				return math.Abs(x), nil
+			case fhirpath.Quantity:
+				x.Value = math.Abs(x.Value)
+				return x, nil
+			}
+			return nil, fmt.Errorf("cannot take the absolute value of %s", typeName(v))
+		}),
+		"Ceiling":  rounding(math.Ceil),
+		"Floor":    rounding(math.Floor),
+		"Truncate": rounding(math.Trunc),
+		"Round": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			x, ok := toFloat(system(args[0]))
+			if !ok {
+				return nil, nil
+			}
+			prec := int64(0)
+			if len(args) > 1 {
+				if p, ok := system(args[1]).(int64); ok {
+					prec = p
+				}
+			}
+			scale := math.Pow(10, float64(prec))
+			return math.Floor(x*scale+0.5) / scale, nil
+		},
+		"Power": binary(func(a, b interface{}) (interface{}, error) {
+			x, okA := toFloat(a)
+			y, okB := toFloat(b)
+			if !okA || !okB {
+				return nil, fmt.Errorf("Power of %s and %s", typeName(a), typeName(b))
+			}
+			r := math.Pow(x, y)
+			_, intA := a.(int64)
+			_, intB := b.(int64)
+			if intA && intB && y >= 0 {
+				return int64(r), nil
+			}
+			return r, nil
+		}),
+
+		// Strings.
+		"Concatenate": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			var sb strings.Builder
+			for _, a := range args {
+				s, ok := system(a).(string)
+				if !ok {
+					return nil, nil
+				}
+				sb.WriteString(s)
+			}
+			return sb.String(), nil
+		},
+		"Combine": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			if args[0] == nil {
+				return nil, nil
+			}
+			sep := ""
+			if len(args) > 1 {
+				s, ok := system(args[1]).(string)
+				if !ok {
+					return nil, nil
+				}
+				sep = s
+			}
+			var parts []string
+			for _, item := range toList(args[0]) {
+				if s, ok := system(item).(string); ok {
+					parts = append(parts, s)
+				}
+			}
+			return strings.Join(parts, sep), nil
+		},
+		"Upper":      stringOp(func(s string) interface{} { return strings.ToUpper(s) }),
+		"Lower":      stringOp(func(s string) interface{} { return strings.ToLower(s) }),
+		"StartsWith": stringPredicate(strings.HasPrefix),
+		"EndsWith":   stringPredicate(strings.HasSuffix),
+		"Matches": binary(func(a, b interface{}) (interface{}, error) {
+			s, okA := a.(string)
+			pattern, okB := b.(string)
+			if !okA || !okB {
+				return nil, fmt.Errorf("Matches of %s and %s", typeName(a), typeName(b))
+			}
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, err
+			}
+			return re.MatchString(s), nil
+		}),
+		"Length": unary(func(v interface{}) (interface{}, error) {
+			switch x := v.(type) {
+			case string:
+				return int64(len([]rune(x))), nil
+			case []interface{}:
+				return int64(len(x)), nil
+			}
+			return nil, fmt.Errorf("Length of %s", typeName(v))
+		}),
+		"Indexer": binary(func(a, b interface{}) (interface{}, error) {
+			i, ok := b.(int64)
+			if !ok {
+				return nil, fmt.Errorf("index must be an Integer, got %s", typeName(b))
+			}
+			switch x := a.(type) {
+			case string:
+				r := []rune(x)
+				if i < 0 || i >= int64(len(r)) {
+					return nil, nil
+				}
+				return string(r[i]), nil
+			case []interface{}:
+				if i < 0 || i >= int64(len(x)) {
+					return nil, nil
+				}
+				return x[i], nil
+			}
+			return nil, fmt.Errorf("cannot index %s", typeName(a))
+		}),
+		"Substring": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			s, okS := system(args[0]).(string)
+			start, okStart := system(args[1]).(int64)
+			if !okS || !okStart {
+				return nil, nil
+			}
+			r := []rune(s)
+			if start < 0 || start >= int64(len(r)) {
+				return nil, nil
+			}
+			end := int64(len(r))
+			if len(args) > 2 {
+				if n, ok := system(args[2]).(int64); ok && start+n < end {
+					end = start + n
+				}
+			}
+			return string(r[start:end]), nil
+		},
+
+		// Lists.
+		"Exists": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			for _, item := range toList(args[0]) {
+				if item != nil {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		"Count": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			n := int64(0)
+			for _, item := range toList(args[0]) {
+				if item != nil {
+					n++
+				}
+			}
+			return n, nil
+		},
+		"Sum": aggregate(func(items []interface{}) (interface{}, error) {
+			var sum interface{} = int64(0)
+			for _, item := range items {
+				var err error
+				if sum, err = add(sum, item); err != nil {
+					return nil, err
+				}
+			}
+			return sum, nil
+		}),
+		"Avg": aggregate(func(items []interface{}) (interface{}, error) {
+			sum := 0.0
+			for _, item := range items {
+				x, ok := toFloat(item)
+				if !ok {
+					return nil, fmt.Errorf("Avg of %s", typeName(item))
+				}
+				sum += x
+			}
+			return sum / float64(len(items)), nil
+		}),
+		"Min": aggregate(func(items []interface{}) (interface{}, error) { return extreme(items, -1) }),
+		"Max": aggregate(func(items []interface{}) (interface{}, error) { return extreme(items, 1) }),
+		"AllTrue": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			for _, item := range toList(args[0]) {
+				if b := system(item); b != nil && b != true {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+		"AnyTrue": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			for _, item := range toList(args[0]) {
+				if system(item) == true {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		"First": unary(func(v interface{}) (interface{}, error) {
+			if l := toList(v); len(l) > 0 {
+				return l[0], nil
+			}
+			return nil, nil
+		}),
+		"Last": unary(func(v interface{}) (interface{}, error) {
+			if l := toList(v); len(l) > 0 {
+				return l[len(l)-1], nil
+			}
+			return nil, nil
+		}),
+		"SingletonFrom": unary(func(v interface{}) (interface{}, error) {
+			l := toList(v)
+			switch len(l) {
+			case 0:
+				return nil, nil
+			case 1:
+				return l[0], nil
+			}
+			return nil, fmt.Errorf("SingletonFrom of a list with %d items", len(l))
+		}),
+		"Distinct": unary(func(v interface{}) (interface{}, error) { return nonNil(distinct(toList(v))), nil }),
+		"Flatten": unary(func(v interface{}) (interface{}, error) {
+			out := []interface{}{}
+			for _, item := range toList(v) {
+				if l, ok := item.([]interface{}); ok {
+					out = append(out, l...)
+				} else {
+					out = append(out, item)
+				}
+			}
+			return out, nil
+		}),
+		"ToList": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			return nonNil(toList(args[0])), nil
+		},
+		"Union": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			return nonNil(distinct(append(append([]interface{}{}, toList(args[0])...), toList(args[1])...))), nil
+		},
+		"Intersect": binary(func(a, b interface{}) (interface{}, error) {
+			out := []interface{}{}
+			for _, item := range distinct(toList(a)) {
+				if listContains(toList(b), item) {
+					out = append(out, item)
+				}
+			}
+			return out, nil
+		}),
+		"Except": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+			if args[0] == nil {
+				return nil, nil
+			}
+			out := []interface{}{}
+			for _, item := range distinct(toList(args[0])) {
+				if !listContains(toList(args[1]), item) {
+					out = append(out, item)
+				}
+			}
+			return out, nil
+		},
+		"In": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return membership(args[0], args[1], x.Precision)
+		},
+		"Contains": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return membership(args[1], args[0], x.Precision)
+		},
+		"IncludedIn": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return inclusion(args[1], args[0], x.Precision)
+		},
+		"Includes": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return inclusion(args[0], args[1], x.Precision)
+		},
+
+		// Intervals and temporal ordering.
+		"Start": unary(func(v interface{}) (interface{}, error) {
+			iv, ok := v.(Interval)
+			if !ok {
+				return nil, fmt.Errorf("Start of %s", typeName(v))
+			}
+			return lowBound(iv), nil
+		}),
+		"End": unary(func(v interface{}) (interface{}, error) {
+			iv, ok := v.(Interval)
+			if !ok {
+				return nil, fmt.Errorf("End of %s", typeName(v))
+			}
+			return highBound(iv), nil
+		}),
+		"Overlaps": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			a, okA := system(args[0]).(Interval)
+			b, okB := system(args[1]).(Interval)
+			if !okA || !okB {
+				return nil, nil
+			}
+			startsBefore, err := boundsLE(lowBound(a), true, highBound(b), false, x.Precision)
+			if err != nil || startsBefore == nil || startsBefore == false {
+				return startsBefore, err
+			}
+			return boundsLE(lowBound(b), true, highBound(a), false, x.Precision)
+		},
+		"Before":       ordering(func(c int) bool { return c < 0 }, true),
+		"After":        ordering(func(c int) bool { return c > 0 }, false),
+		"SameOrBefore": ordering(func(c int) bool { return c <= 0 }, true),
+		"SameOrAfter":  ordering(func(c int) bool { return c >= 0 }, false),
+		"SameAs": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return comparePoints(args[0], args[1], x.Precision, func(c int) bool { return c == 0 })
+		},
+
+		// Dates and times.
+		"DateFrom": unary(func(v interface{}) (interface{}, error) {
+			t, ok := v.(fhirpath.Temporal)
+			if !ok {
+				return nil, fmt.Errorf("DateFrom of %s", typeName(v))
+			}
+			t = truncate(t, fhirpath.PrecisionDay)
+			t.Kind = fhirpath.Date
+			return t, nil
+		}),
+		"DateTimeComponentFrom": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			t, ok := system(args[0]).(fhirpath.Temporal)
+			if !ok {
+				return nil, nil
+			}
+			p, err := precisionOf(x.Precision)
+			if err != nil {
+				return nil, err
+			}
+			if p > t.Precision {
+				return nil, nil
+			}
+			return int64(temporalParts(t)[p]), nil
+		},
+		"CalculateAge": func(ev *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return ev.age(args[0], nil, x.Precision)
+		},
+		"CalculateAgeAt": func(ev *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return ev.age(args[0], args[1], x.Precision)
+		},
+		"DurationBetween": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return between(args[0], args[1], x.Precision, durationBetween)
+		},
+		"DifferenceBetween": func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+			return between(args[0], args[1], x.Precision, differenceBetween)
+		},
+
+		// Conversions.
+		"ToBoolean":  conversion("toBoolean()"),
+		"ToInteger":  conversion("toInteger()"),
+		"ToLong":     conversion("toInteger()"),
+		"ToDecimal":  conversion("toDecimal()"),
+		"ToString":   toStringOp,
+		"ToDate":     conversion("toDate()"),
+		"ToDateTime": conversion("toDateTime()"),
+		"ToTime":     conversion("toTime()"),
+		"ToQuantity": conversion("toQuantity()"),
+		"ToConcept": unary(func(v interface{}) (interface{}, error) {
+			switch x := v.(type) {
+			case Concept:
+				return x, nil
+			case Code:
+				return Concept{Codes: []Code{x}}, nil
+			case []interface{}:
+				return Concept{Codes: codesOf(x)}, nil
+			}
+			return nil, fmt.Errorf("cannot convert %s to Concept", typeName(v))
+		}),
+		"Message": func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) { return args[0], nil },
+	}
+}
+
+// operator evaluates the operators that are not references, queries or
+// retrieves.
+func (ev *evaluation) operator(x *Expression, s *scope) (interface{}, error) {
+	switch x.Type {
+	case "Null":
+		return nil, nil
+	case "Literal":
+		return literal(x)
+	case "Quantity":
+		v, err := strconv.ParseFloat(x.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Quantity value %q", x.Value)
+		}
+		return fhirpath.Quantity{Value: v, Unit: x.Unit}, nil
+	case "List":
+		out := []interface{}{}
+		for _, e := range x.Element {
+			v, err := ev.eval(e, s)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case "Tuple", "Instance":
+		return ev.tuple(x, s)
+	case "Interval":
+		return ev.interval(x, s)
+	case "Code":
+		code := Code{Code: x.Code, Display: x.Display}
+		if x.System != nil {
+			cs, err := ev.eval(x.System, s)
+			if err != nil {
+				return nil, err
+			}
+			if c, ok := cs.(CodeSystem); ok {
+				code.System, code.Version = c.ID, c.Version
+			}
+		}
+		return code, nil
+	case "Concept":
+		c := Concept{Display: x.Display}
+		for _, e := range x.Codes {
+			v, err := ev.eval(e, s)
+			if err != nil {
+				return nil, err
+			}
+			c.Codes = append(c.Codes, codesOf(v)...)
+		}
+		return c, nil
+	case "And", "Or", "Implies":
+		return ev.logical(x, s)
+	case "If":
+		cond, err := ev.eval(x.Condition, s)
+		if err != nil {
+			return nil, err
+		}
+		if system(cond) == true {
+			return ev.eval(x.Then, s)
+		}
+		return ev.eval(x.Else, s)
+	case "Case":
+		return ev.caseExpr(x, s)
+	case "Coalesce":
+		ops := x.Operand
+		for _, op := range ops {
+			v, err := ev.eval(op, s)
+			if err != nil {
+				return nil, err
+			}
+			if l, ok := v.([]interface{}); ok && len(ops) == 1 {
+				for _, item := range l {
+					if item != nil {
+						return item, nil
+					}
+				}
+				return nil, nil
+			}
+			if v != nil {
+				return v, nil
+			}
+		}
+		return nil, nil
+	case "As", "Is":
+		v, err := ev.eval(first(x.Operand), s)
+		if err != nil {
+			return nil, err
+		}
+		name, spec := x.AsType, x.AsTypeSpecifier
+		if x.Type == "Is" {
+			name, spec = x.IsType, x.IsTypeSpecifier
+		}
+		ok := v != nil && matchesType(v, name, spec)
+		switch {
+		case x.Type == "Is":
+			return ok, nil
+		case ok || v == nil:
+			return v, nil
+		case x.Strict:
+			return nil, fmt.Errorf("cannot cast %s to %s", typeName(v), name)
+		}
+		return nil, nil
+	case "Convert":
+		_, to := splitTypeName(x.ToType)
+		conv, ok := operators["To"+to]
+		if !ok {
+			return nil, fmt.Errorf("unsupported conversion to %s", x.ToType)
+		}
+		v, err := ev.eval(first(x.Operand), s)
+		if err != nil {
+			return nil, err
+		}
+		return conv(ev, x, []interface{}{v})
+	case "InValueSet", "AnyInValueSet":
+		return ev.inValueSet(x, s)
+	case "Now":
+		return ev.now, nil
+	case "Today":
+		t := truncate(ev.now, fhirpath.PrecisionDay)
+		t.Kind = fhirpath.Date
+		return t, nil
+	case "TimeOfDay":
+		tm := ev.now.Time
+		return fhirpath.Temporal{Kind: fhirpath.Time, Time: time.Date(0, 1, 1, tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond(), time.UTC), Precision: fhirpath.PrecisionMillisecond}, nil
+	case "Date", "DateTime", "Time":
+		return ev.temporal(x, s)
+	}
+	fn, ok := operators[x.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ELM expression type %q", x.Type)
+	}
+	args := make([]interface{}, len(x.Operand))
+	for i, op := range x.Operand {
+		var err error
+		if args[i], err = ev.eval(op, s); err != nil {
+			return nil, err
+		}
+	}
+	return fn(ev, x, args)
+}
+
+// unary wraps a function of one operand, which returns null for a null
+// operand. The operand is converted to a system value.
+func unary(fn func(interface{}) (interface{}, error)) operatorFunc {
+	return func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+		v := system(args[0])
+		if v == nil {
+			return nil, nil
+		}
+		return fn(v)
+	}
+}
+
+// binary wraps a function of two operands, which returns null if either
+// operand is null.
+func binary(fn func(a, b interface{}) (interface{}, error)) operatorFunc {
+	return func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+		a, b := system(args[0]), system(args[1])
+		if a == nil || b == nil {
+			return nil, nil
+		}
+		return fn(a, b)
+	}
+}
+
+func comparison(pred func(int) bool) operatorFunc {
+	return func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+		c, known, err := compare(args[0], args[1])
+		if err != nil || !known {
+			return nil, err
+		}
+		return pred(c), nil
+	}
+}
+
+func rounding(fn func(float64) float64) operatorFunc {
+	return unary(func(v interface{}) (interface{}, error) {
+		x, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot round %s", typeName(v))
+		}
+		return int64(fn(x)), nil
+	})
+}
+
+func stringOp(fn func(string) interface{}) operatorFunc {
+	return unary(func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a String, got %s", typeName(v))
+		}
+		return fn(s), nil
+	})
+}
+
+func stringPredicate(fn func(s, arg string) bool) operatorFunc {
+	return binary(func(a, b interface{}) (interface{}, error) {
+		s, okA := a.(string)
+		arg, okB := b.(string)
+		if !okA || !okB {
+			return nil, fmt.Errorf("expected Strings, got %s and %s", typeName(a), typeName(b))
+		}
+		return fn(s, arg), nil
+	})
+}
+
+// aggregate wraps an aggregate function, which is applied to the non-null
+// items of its list operand and returns null if there are none.
+func aggregate(fn func([]interface{}) (interface{}, error)) operatorFunc {
+	return func(_ *evaluation, _ *Expression, args []interface{}) (interface{}, error) {
+		var items []interface{}
+		for _, item := range toList(args[0]) {
+			if v := system(item); v != nil {
+				items = append(items, v)
+			}
+		}
+		if len(items) == 0 {
+			return nil, nil
+		}
+		return fn(items)
+	}
+}
+
+func extreme(items []interface{}, sign int) (interface{}, error) {
+	best := items[0]
+	for _, item := range items[1:] {
+		c, _, err := compare(item, best)
+		if err != nil {
+			return nil, err
+		}
+		if c*sign > 0 {
+			best = item
+		}
+	}
+	return best, nil
+}
+
+// nonNil returns an empty list instead of a nil one, so that empty list
+// results are distinguishable from null.
+func nonNil(l []interface{}) []interface{} {
+	if l == nil {
+		return []interface{}{}
+	}
+	return l
+}
+
+func listContains(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if eq, known := equal(item, v); known && eq {
+			return true
+		}
+	}
+	return false
+}
+
+func literal(x *Expression) (interface{}, error) {
+	_, typ := splitTypeName(x.ValueType)
+	switch typ {
+	case "Boolean":
+		return x.Value == "true", nil
+	case "Integer", "Long":
+		v, err := strconv.ParseInt(strings.TrimSuffix(x.Value, "L"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s literal %q", typ, x.Value)
+		}
+		return v, nil
+	case "Decimal":
+		v, err := strconv.ParseFloat(x.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Decimal literal %q", x.Value)
+		}
+		return v, nil
+	case "String":
+		return x.Value, nil
+	}
+	return nil, fmt.Errorf("unsupported literal type %q", x.ValueType)
+}
+
+func (ev *evaluation) tuple(x *Expression, s *scope) (interface{}, error) {
+	t := Tuple{}
+	for _, e := range x.Element {
+		v, err := ev.eval(e.ValueExpr, s)
+		if err != nil {
+			return nil, err
+		}
+		t[e.Name] = v
+	}
+	if x.Type == "Tuple" {
+		return t, nil
+	}
+	_, class := splitTypeName(x.ClassType)
+	switch class {
+	case "Code":
+		c := Code{}
+		c.Code, _ = system(t["code"]).(string)
+		c.System, _ = system(t["system"]).(string)
+		c.Version, _ = system(t["version"]).(string)
+		c.Display, _ = system(t["display"]).(string)
+		return c, nil
+	case "Concept":
+		c := Concept{Codes: codesOf(t["codes"])}
+		c.Display, _ = system(t["display"]).(string)
+		return c, nil
+	case "Quantity":
+		q := fhirpath.Quantity{}
+		q.Value, _ = toFloat(system(t["value"]))
+		q.Unit, _ = system(t["unit"]).(string)
+		return q, nil
+	}
+	return nil, fmt.Errorf("unsupported Instance of %s", x.ClassType)
+}
+
+func (ev *evaluation) interval(x *Expression, s *scope) (interface{}, error) {
+	low, err := ev.eval(x.Low, s)
+	if err != nil {
+		return nil, err
+	}
+	high, err := ev.eval(x.High, s)
+	if err != nil {
+		return nil, err
+	}
+	return Interval{
+		Low:        system(low),
+		High:       system(high),
+		LowClosed:  x.LowClosed == nil || *x.LowClosed,
+		HighClosed: x.HighClosed == nil || *x.HighClosed,
+	}, nil
+}
+
+// logical implements three-valued And, Or and Implies.
+func (ev *evaluation) logical(x *Expression, s *scope) (interface{}, error) {
+	if len(x.Operand) != 2 {
+		return nil, fmt.Errorf("%s requires two operands", x.Type)
+	}
+	l, err := ev.eval(x.Operand[0], s)
+	if err != nil {
+		return nil, err
+	}
+	a := system(l)
+	switch {
+	case x.Type == "And" && a == false:
+		return false, nil
+	case x.Type == "Or" && a == true:
+		return true, nil
+	case x.Type == "Implies" && a == false:
+		return true, nil
+	}
+	r, err := ev.eval(x.Operand[1], s)
+	if err != nil {
+		return nil, err
+	}
+	b := system(r)
+	switch x.Type {
+	case "And":
+		if b == false {
+			return false, nil
+		}
+		if a == true && b == true {
+			return true, nil
+		}
+	case "Or":
+		if b == true {
+			return true, nil
+		}
+		if a == false && b == false {
+			return false, nil
+		}
+	case "Implies":
+		if b == true {
+			return true, nil
+		}
+		if a == true && b == false {
+			return false, nil
+		}
+	}
+	return nil, nil
+}
+
+func (ev *evaluation) caseExpr(x *Expression, s *scope) (interface{}, error) {
+	var comparand interface{}
+	if x.Comparand != nil {
+		var err error
+		if comparand, err = ev.eval(x.Comparand, s); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range x.CaseItem {
+		w, err := ev.eval(item.When, s)
+		if err != nil {
+			return nil, err
+		}
+		match := system(w) == true
+		if x.Comparand != nil {
+			eq, known := equal(comparand, w)
+			match = known && eq
+		}
+		if match {
+			return ev.eval(item.Then, s)
+		}
+	}
+	return ev.eval(x.Else, s)
+}
+
+// matchesType reports whether v is of the type named by name or described by
+// spec.
+func matchesType(v interface{}, name string, spec *TypeSpecifier) bool {
+	if spec == nil {
+		return isType(v, name)
+	}
+	switch spec.Type {
+	case "NamedTypeSpecifier":
+		return isType(v, spec.Name)
+	case "ListTypeSpecifier":
+		l, ok := v.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, item := range l {
+			if item != nil && !matchesType(item, "", spec.ElementType) {
+				return false
+			}
+		}
+		return true
+	case "ChoiceTypeSpecifier":
+		for _, c := range spec.Choice {
+			if matchesType(v, "", c) {
+				return true
+			}
+		}
+		return false
+	case "IntervalTypeSpecifier":
+		_, ok := v.(Interval)
+		return ok
+	case "TupleTypeSpecifier":
+		_, ok := v.(Tuple)
+		return ok
+	}
+	return false
+}
+
+func (ev *evaluation) inValueSet(x *Expression, s *scope) (interface{}, error) {
+	codeExpr := x.CodeExpr
+	if codeExpr == nil {
+		codeExpr = first(x.Operand)
+	}
+	v, err := ev.eval(codeExpr, s)
+	if err != nil {
+		return nil, err
+	}
+	vsv, err := ev.eval(x.ValueSet, s)
+	if err != nil {
+		return nil, err
+	}
+	vs, ok := vsv.(ValueSet)
+	if !ok {
+		return nil, fmt.Errorf("%s requires a value set, got %s", x.Type, typeName(vsv))
+	}
+	if v == nil {
+		return false, nil
+	}
+	tp := ev.engine.opts.Terminology
+	if tp == nil {
+		return nil, fmt.Errorf("%s requires a TerminologyProvider", x.Type)
+	}
+	for _, c := range codesOf(v) {
+		in, err := tp.InValueSet(ev.ctx, c, vs)
+		if err != nil {
+			return nil, err
+		}
+		if in {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ev *evaluation) temporal(x *Expression, s *scope) (interface{}, error) {
+	parts := []*Expression{x.Year, x.Month, x.Day, x.Hour, x.Minute, x.Second, x.Millisecond}
+	kind := fhirpath.DateTime
+	switch x.Type {
+	case "Date":
+		kind = fhirpath.Date
+	case "Time":
+		kind = fhirpath.Time
+		parts = []*Expression{nil, nil, nil, x.Hour, x.Minute, x.Second, x.Millisecond}
+	}
+	vals := [7]int{0, 1, 1, 0, 0, 0, 0}
+	prec := fhirpath.Precision(-1)
+	for i, p := range parts {
+		if p == nil {
+			continue
+		}
+		v, err := ev.eval(p, s)
+		if err != nil {
+			return nil, err
+		}
+		n, ok := system(v).(int64)
+		if !ok {
+			if v == nil {
+				break
+			}
+			return nil, fmt.Errorf("%s component must be an Integer, got %s", x.Type, typeName(v))
+		}
+		vals[i] = int(n)
+		prec = fhirpath.Precision(i)
+	}
+	if prec < 0 {
+		return nil, nil
+	}
+	loc := ev.now.Time.Location()
+	if kind == fhirpath.Date || kind == fhirpath.Time {
+		loc = time.UTC
+	}
+	if x.Timezone != nil {
+		v, err := ev.eval(x.Timezone, s)
+		if err != nil {
+			return nil, err
+		}
+		if hours, ok := toFloat(system(v)); ok {
+			loc = time.FixedZone("", int(hours*3600))
+		}
+	}
+	if kind == fhirpath.Time {
+		vals[0] = 0
+	}
+	t := time.Date(vals[0], time.Month(vals[1]), vals[2], vals[3], vals[4], vals[5], vals[6]*1e6, loc)
+	return fhirpath.Temporal{Kind: kind, Time: t, Precision: prec}, nil
+}
+
+func (ev *evaluation) age(birth, asOf interface{}, precision string) (interface{}, error) {
+	b, ok := system(birth).(fhirpath.Temporal)
+	if !ok {
+		return nil, nil
+	}
+	p, err := precisionOf(precision)
+	if err != nil {
+		return nil, err
+	}
+	var at fhirpath.Temporal
+	if asOf == nil {
+		at = ev.now
+		if p <= fhirpath.PrecisionDay {
+			at = truncate(at, fhirpath.PrecisionDay)
+		}
+	} else if at, ok = system(asOf).(fhirpath.Temporal); !ok {
+		return nil, nil
+	}
+	n, known := durationBetween(b, at, p, strings.HasPrefix(strings.ToLower(precision), "week"))
+	if !known {
+		return nil, nil
+	}
+	return n, nil
+}
+
+func between(a, b interface{}, precision string, fn func(a, b fhirpath.Temporal, p fhirpath.Precision, weeks bool) (int64, bool)) (interface{}, error) {
+	x, okA := system(a).(fhirpath.Temporal)
+	y, okB := system(b).(fhirpath.Temporal)
+	if !okA || !okB {
+		return nil, nil
+	}
+	p, err := precisionOf(precision)
+	if err != nil {
+		return nil, err
+	}
+	n, known := fn(x, y, p, strings.HasPrefix(strings.ToLower(precision), "week"))
+	if !known {
+		return nil, nil
+	}
+	return n, nil
+}
+
+func add(a, b interface{}) (interface{}, error) {
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return x + y, nil
+		}
+		if y, ok := b.(float64); ok {
+			return float64(x) + y, nil
+		}
+	case float64:
+		if y, ok := toFloat(b); ok {
+			return x + y, nil
+		}
+	case fhirpath.Quantity:
+		if y, ok := b.(fhirpath.Quantity); ok {
+			yv, ok := convertUnit(y, x.Unit)
+			if !ok {
+				return nil, fmt.Errorf("cannot add quantities in %q and %q", x.Unit, y.Unit)
+			}
+			x.Value += yv
+			return x, nil
+		}
+	case fhirpath.Temporal:
+		if y, ok := b.(fhirpath.Quantity); ok {
+			return addQuantity(x, y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return x + y, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot add %s and %s", typeName(a), typeName(b))
+}
+
+func subtract(a, b interface{}) (interface{}, error) {
+	switch y := b.(type) {
+	case int64:
+		return add(a, -y)
+	case float64:
+		return add(a, -y)
+	case fhirpath.Quantity:
+		y.Value = -y.Value
+		return add(a, y)
+	}
+	return nil, fmt.Errorf("cannot subtract %s from %s", typeName(b), typeName(a))
+}
+
+func multiply(a, b interface{}) (interface{}, error) {
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			return x * y, nil
+		}
+	}
+	if q, ok := a.(fhirpath.Quantity); ok {
+		if y, ok := toFloat(b); ok {
+			q.Value *= y
+			return q, nil
+		}
+	}
+	if q, ok := b.(fhirpath.Quantity); ok {
+		if x, ok := toFloat(a); ok {
+			q.Value *= x
+			return q, nil
+		}
+	}
+	x, okA := toFloat(a)
+	y, okB := toFloat(b)
+	if !okA || !okB {
+		return nil, fmt.Errorf("cannot multiply %s and %s", typeName(a), typeName(b))
+	}
+	return x * y, nil
+}
+
+func divide(a, b interface{}) (interface{}, error) {
+	y, ok := toFloat(b)
+	if !ok {
+		return nil, fmt.Errorf("cannot divide by %s", typeName(b))
+	}
+	if y == 0 {
+		return nil, nil
+	}
+	if q, ok := a.(fhirpath.Quantity); ok {
+		q.Value /= y
+		return q, nil
+	}
+	x, ok := toFloat(a)
+	if !ok {
+		return nil, fmt.Errorf("cannot divide %s", typeName(a))
+	}
+	return x / y, nil
+}
+
+func integerDivision(a, b interface{}, modulo bool) (interface{}, error) {
+	x, okA := a.(int64)
+	y, okB := b.(int64)
+	if okA && okB {
+		if y == 0 {
+			return nil, nil
+		}
+		if modulo {
+			return x % y, nil
+		}
+		return x / y, nil
+	}
+	fx, okA := toFloat(a)
+	fy, okB := toFloat(b)
+	if !okA || !okB {
+		return nil, fmt.Errorf("cannot divide %s by %s", typeName(a), typeName(b))
+	}
+	if fy == 0 {
+		return nil, nil
+	}
+	if modulo {
+		return math.Mod(fx, fy), nil
+	}
+	return math.Trunc(fx / fy), nil
+}
+
+// conversion implements a conversion operator with the FHIRPath function fn,
+// whose semantics match the CQL one for system values.
+func conversion(fn string) operatorFunc {
+	expr := fhirpath.MustCompile(fn)
+	return unary(func(v interface{}) (interface{}, error) {
+		res, err := expr.Evaluate(v)
+		if err != nil || len(res) == 0 {
+			return nil, err
+		}
+		return res[0], nil
+	})
+}
+
+var toStringOp = unary(func(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case bool:
+		return strconv.FormatBool(x), nil
+	case int64:
+		return strconv.FormatInt(x, 10), nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	case string:
+		return x, nil
+	case fhirpath.Temporal:
+		return x.String(), nil
+	case fhirpath.Quantity:
+		return x.String(), nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to String", typeName(v))
+})
+
+// membership implements In for lists and intervals.
+func membership(v, container interface{}, precision string) (interface{}, error) {
+	switch c := system(container).(type) {
+	case nil:
+		if _, isList := container.([]interface{}); isList || container == nil {
+			return false, nil
+		}
+		return nil, nil
+	case Interval:
+		if system(v) == nil {
+			return nil, nil
+		}
+		return inInterval(v, c, precision)
+	case []interface{}:
+		return listContains(c, v), nil
+	}
+	return nil, fmt.Errorf("cannot test membership in %s", typeName(container))
+}
+
+// inclusion implements Includes for lists and intervals; a point is treated
+// as a unit interval or list.
+func inclusion(container, v interface{}, precision string) (interface{}, error) {
+	switch c := system(container).(type) {
+	case nil:
+		return nil, nil
+	case Interval:
+		switch x := system(v).(type) {
+		case nil:
+			return nil, nil
+		case Interval:
+			lowOK, err := boundsLE(lowBound(c), true, lowBound(x), true, precision)
+			if err != nil || lowOK != true {
+				return lowOK, err
+			}
+			return boundsLE(highBound(x), false, highBound(c), false, precision)
+		default:
+			return inInterval(x, c, precision)
+		}
+	case []interface{}:
+		for _, item := range toList(v) {
+			if !listContains(c, item) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return nil, fmt.Errorf("cannot test inclusion in %s", typeName(container))
+}
+
+func lowBound(iv Interval) interface{} {
+	if iv.Low == nil || iv.LowClosed {
+		return iv.Low
+	}
+	return step(iv.Low, 1)
+}
+
+func highBound(iv Interval) interface{} {
+	if iv.High == nil || iv.HighClosed {
+		return iv.High
+	}
+	return step(iv.High, -1)
+}
+
+// step returns the successor (dir 1) or predecessor (dir -1) of v.
+func step(v interface{}, dir int) interface{} {
+	switch x := v.(type) {
+	case int64:
+		return x + int64(dir)
+	case float64:
+		return x + float64(dir)*1e-8
+	case fhirpath.Temporal:
+		units := [...]string{"year", "month", "day", "hour", "minute", "second", "millisecond"}
+		t, err := addQuantity(x, fhirpath.Quantity{Value: float64(dir), Unit: units[x.Precision]})
+		if err != nil {
+			return v
+		}
+		return t
+	case fhirpath.Quantity:
+		x.Value += float64(dir) * 1e-8
+		return x
+	}
+	return v
+}
+
+// boundsLE reports whether the interval boundary a is at or before b. aLow
+// and bLow tell whether they are low boundaries, for which nil is unbounded
+// below; a nil high boundary is unbounded above.
+func boundsLE(a interface{}, aLow bool, b interface{}, bLow bool, precision string) (interface{}, error) {
+	switch {
+	case a == nil && aLow:
+		return true, nil
+	case a == nil:
+		return b == nil && !bLow, nil
+	case b == nil:
+		return !bLow, nil
+	}
+	return comparePoints(a, b, precision, func(c int) bool { return c <= 0 })
+}
+
+func inInterval(v interface{}, iv Interval, precision string) (interface{}, error) {
+	v = system(v)
+	if iv.Low != nil {
+		c, err := comparePoints(iv.Low, v, precision, func(c int) bool {
+			if iv.LowClosed {
+				return c <= 0
+			}
+			return c < 0
+		})
+		if err != nil || c != true {
+			return c, err
+		}
+	}
+	if iv.High != nil {
+		return comparePoints(v, iv.High, precision, func(c int) bool {
+			if iv.HighClosed {
+				return c <= 0
+			}
+			return c < 0
+		})
+	}
+	return true, nil
+}
+
+// comparePoints compares two points, temporal ones up to precision, and
+// returns pred applied to the result or null if it is unknown.
+func comparePoints(a, b interface{}, precision string, pred func(int) bool) (interface{}, error) {
+	a, b = system(a), system(b)
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	if ta, ok := a.(fhirpath.Temporal); ok {
+		tb, ok := b.(fhirpath.Temporal)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %s and %s", typeName(a), typeName(b))
+		}
+		p, err := precisionOf(precision)
+		if err != nil {
+			return nil, err
+		}
+		c, known := compareTemporal(ta, tb, p)
+		if !known {
+			return nil, nil
+		}
+		return pred(c), nil
+	}
+	c, known, err := compare(a, b)
+	if err != nil || !known {
+		return nil, err
+	}
+	return pred(c), nil
+}
+
+// ordering implements Before, After, SameOrBefore and SameOrAfter for points
+// and intervals. For intervals the end of the first operand is compared to
+// the start of the second one if before is set, and vice versa.
+func ordering(pred func(int) bool, before bool) operatorFunc {
+	return func(_ *evaluation, x *Expression, args []interface{}) (interface{}, error) {
+		a, b := system(args[0]), system(args[1])
+		if a == nil || b == nil {
+			return nil, nil
+		}
+		if iv, ok := a.(Interval); ok {
+			if before {
+				a = highBound(iv)
+			} else {
+				a = lowBound(iv)
+			}
+		}
+		if iv, ok := b.(Interval); ok {
+			if before {
+				b = lowBound(iv)
+			} else {
+				b = highBound(iv)
+			}
+		}
+		return comparePoints(a, b, x.Precision, pred)
+	}
+}
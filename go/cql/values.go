@@ -0,0 +1,751 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// Code is a CQL Code.
+type Code struct {
+	System  string
+	Version string
+	Code    string
+	Display string
+}
+
+// Concept is a CQL Concept, a set of codes with the same meaning.
+type Concept struct {
+	Codes   []Code
+	Display string
+}
+
+// Interval is a CQL Interval. Low and High are nil if the boundary is
+// unknown.
+type Interval struct {
+	Low, High             interface{}
+	LowClosed, HighClosed bool
+}
+
+// Tuple is a CQL Tuple.
+type Tuple map[string]interface{}
+
+// ValueSet is a reference to a value set by its canonical URL, the value of a
+// ValueSetRef.
+type ValueSet struct {
+	ID      string
+	Version string
+}
+
+// CodeSystem is a reference to a code system, the value of a CodeSystemRef.
+type CodeSystem struct {
+	ID      string
+	Version string
+}
+
+// system converts FHIR elements to the corresponding CQL system value,
+// implementing the implicit conversions of the FHIR model info: primitives to
+// their value, Coding to Code, CodeableConcept to Concept and Period and Range
+// to Interval. Resources, lists and other values are returned unchanged.
+func system(v interface{}) interface{} {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return v
+	}
+	switch x := m.(type) {
+	case *d4pb.Coding:
+		return codeFromProto(x)
+	case *d4pb.CodeableConcept:
+		return conceptFromProto(x)
+	case *d4pb.Period:
+		return Interval{Low: system(x.GetStart()), High: system(x.GetEnd()), LowClosed: true, HighClosed: true}
+	case *d4pb.Range:
+		return Interval{Low: system(x.GetLow()), High: system(x.GetHigh()), LowClosed: true, HighClosed: true}
+	}
+	if !m.ProtoReflect().IsValid() {
+		return nil
+	}
+	sv, ok := fhirpath.SystemValue(m)
+	if !ok {
+		return nil
+	}
+	return sv
+}
+
+func codeFromProto(c *d4pb.Coding) interface{} {
+	if c == nil {
+		return nil
+	}
+	return Code{
+		System:  c.GetSystem().GetValue(),
+		Version: c.GetVersion().GetValue(),
+		Code:    c.GetCode().GetValue(),
+		Display: c.GetDisplay().GetValue(),
+	}
+}
+
+func conceptFromProto(cc *d4pb.CodeableConcept) interface{} {
+	if cc == nil {
+		return nil
+	}
+	c := Concept{Display: cc.GetText().GetValue()}
+	for _, coding := range cc.GetCoding() {
+		c.Codes = append(c.Codes, codeFromProto(coding).(Code))
+	}
+	return c
+}
+
+// ProtoValue converts a CQL value to the corresponding R4 proto, the inverse
+// of the implicit conversions: a Code to a *d4pb.Coding, a Concept to a
+// *d4pb.CodeableConcept, an Interval of DateTimes to a *d4pb.Period and one
+// of Quantities to a *d4pb.Range. Other values are converted with
+// fhirpath.ProtoValue. ok is false for values without a proto counterpart,
+// such as lists and tuples.
+func ProtoValue(v interface{}) (value proto.Message, ok bool) {
+	switch x := v.(type) {
+	case Code:
+		return codeToProto(x), true
+	case Concept:
+		cc := &d4pb.CodeableConcept{}
+		for _, c := range x.Codes {
+			cc.Coding = append(cc.Coding, codeToProto(c))
+		}
+		if x.Display != "" {
+			cc.Text = &d4pb.String{Value: x.Display}
+		}
+		return cc, true
+	case Interval:
+		low, high := boundToProto(x.Low), boundToProto(x.High)
+		start, sok := low.(*d4pb.DateTime)
+		end, eok := high.(*d4pb.DateTime)
+		if (sok || low == nil) && (eok || high == nil) {
+			return &d4pb.Period{Start: start, End: end}, true
+		}
+		lq, lok := low.(*d4pb.Quantity)
+		hq, hok := high.(*d4pb.Quantity)
+		if (lok || low == nil) && (hok || high == nil) {
+			return &d4pb.Range{Low: simpleQuantity(lq), High: simpleQuantity(hq)}, true
+		}
+		return nil, false
+	}
+	return fhirpath.ProtoValue(v)
+}
+
+func codeToProto(c Code) *d4pb.Coding {
+	out := &d4pb.Coding{Code: &d4pb.Code{Value: c.Code}}
+	if c.System != "" {
+		out.System = &d4pb.Uri{Value: c.System}
+	}
+	if c.Version != "" {
+		out.Version = &d4pb.String{Value: c.Version}
+	}
+	if c.Display != "" {
+		out.Display = &d4pb.String{Value: c.Display}
+	}
+	return out
+}
+
+// boundToProto converts an interval boundary, returning nil for open or
+// unconvertible boundaries. Dates become DateTimes so that they fit a Period.
+func boundToProto(v interface{}) proto.Message {
+	if t, ok := v.(fhirpath.Temporal); ok && t.Kind == fhirpath.Date {
+		t.Kind = fhirpath.DateTime
+		v = t
+	}
+	m, ok := fhirpath.ProtoValue(v)
+	if !ok {
+		return nil
+	}
+	return m
+}
+
+func simpleQuantity(q *d4pb.Quantity) *d4pb.SimpleQuantity {
+	if q == nil {
+		return nil
+	}
+	return &d4pb.SimpleQuantity{Value: q.Value, Unit: q.Unit, System: q.System, Code: q.Code}
+}
+
+// toList returns v as a list: nil is empty and other values are singleton
+// lists.
+func toList(v interface{}) []interface{} {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return x
+	}
+	return []interface{}{v}
+}
+
+// codesOf returns the codes of a Code, Concept or list of them.
+func codesOf(v interface{}) []Code {
+	var out []Code
+	for _, item := range toList(v) {
+		switch x := system(item).(type) {
+		case Code:
+			out = append(out, x)
+		case Concept:
+			out = append(out, x.Codes...)
+		case string:
+			out = append(out, Code{Code: x})
+		}
+	}
+	return out
+}
+
+// equal implements CQL equality. The second result is false if equality is
+// unknown, i.e. either operand is null.
+func equal(a, b interface{}) (bool, bool) {
+	a, b = system(a), system(b)
+	if a == nil || b == nil {
+		return false, false
+	}
+	switch x := a.(type) {
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false, true
+		}
+		for i := range x {
+			if eq, known := equal(x[i], y[i]); !known || !eq {
+				return eq, known
+			}
+		}
+		return true, true
+	case Tuple:
+		y, ok := b.(Tuple)
+		if !ok || len(x) != len(y) {
+			return false, true
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok {
+				return false, true
+			}
+			if xv == nil && yv == nil {
+				continue
+			}
+			if eq, known := equal(xv, yv); !known || !eq {
+				return eq, known
+			}
+		}
+		return true, true
+	case Interval:
+		y, ok := b.(Interval)
+		if !ok {
+			return false, true
+		}
+		if x.LowClosed != y.LowClosed || x.HighClosed != y.HighClosed {
+			return false, true
+		}
+		lo, loKnown := equal(x.Low, y.Low)
+		hi, hiKnown := equal(x.High, y.High)
+		if (loKnown && !lo) || (hiKnown && !hi) {
+			return false, true
+		}
+		return true, loKnown && hiKnown
+	case Code:
+		y, ok := b.(Code)
+		return ok && x == y, true
+	case Concept:
+		y, ok := b.(Concept)
+		if !ok || len(x.Codes) != len(y.Codes) || x.Display != y.Display {
+			return false, true
+		}
+		for i := range x.Codes {
+			if x.Codes[i] != y.Codes[i] {
+				return false, true
+			}
+		}
+		return true, true
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y, true
+	case proto.Message:
+		y, ok := b.(proto.Message)
+		return ok && proto.Equal(x, y), true
+	}
+	c, known, err := compare(a, b)
+	if err != nil {
+		return false, true
+	}
+	return c == 0, known
+}
+
+// equivalent implements CQL equivalence, which is never unknown: null is
+// equivalent to null, strings ignore case and whitespace differences and
+// codes only compare their system and code.
+func equivalent(a, b interface{}) bool {
+	a, b = system(a), system(b)
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && strings.EqualFold(strings.Join(strings.Fields(x), " "), strings.Join(strings.Fields(y), " "))
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equivalent(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case Tuple:
+		y, ok := b.(Tuple)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k := range x {
+			if !equivalent(x[k], y[k]) {
+				return false
+			}
+		}
+		return true
+	case Code, Concept:
+		if _, ok := b.(Code); !ok {
+			if _, ok := b.(Concept); !ok {
+				return false
+			}
+		}
+		for _, cx := range codesOf(x) {
+			for _, cy := range codesOf(b) {
+				if cx.System == cy.System && cx.Code == cy.Code {
+					return true
+				}
+			}
+		}
+		return false
+	case float64:
+		y, ok := toFloat(b)
+		return ok && math.Abs(x-y) < 1e-8
+	case fhirpath.Temporal:
+		y, ok := b.(fhirpath.Temporal)
+		if !ok {
+			return false
+		}
+		c, known := compareTemporal(x, y, fhirpath.PrecisionMillisecond)
+		return known && c == 0
+	}
+	eq, known := equal(a, b)
+	return known && eq
+}
+
+// compare orders two system values. known is false if the order is unknown,
+// i.e. for dates of different precisions. An error is returned for values
+// that cannot be ordered.
+func compare(a, b interface{}) (c int, known bool, err error) {
+	a, b = system(a), system(b)
+	if a == nil || b == nil {
+		return 0, false, nil
+	}
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmpFloat(float64(x), float64(y)), true, nil
+		case float64:
+			return cmpFloat(float64(x), y), true, nil
+		}
+	case float64:
+		if y, ok := toFloat(b); ok {
+			return cmpFloat(x, y), true, nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true, nil
+		}
+	case fhirpath.Temporal:
+		if y, ok := b.(fhirpath.Temporal); ok {
+			c, known := compareTemporal(x, y, fhirpath.PrecisionMillisecond)
+			return c, known, nil
+		}
+	case fhirpath.Quantity:
+		if y, ok := b.(fhirpath.Quantity); ok {
+			yv, ok := convertUnit(y, x.Unit)
+			if !ok {
+				return 0, false, fmt.Errorf("cannot compare quantities in %q and %q", x.Unit, y.Unit)
+			}
+			return cmpFloat(x.Value, yv), true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("cannot compare %s and %s", typeName(a), typeName(b))
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// unitScale holds conversion factors to a base unit for the UCUM units
+// commonly found in clinical data. Units of the same dimension share a base.
+var unitScale = map[string]struct {
+	base  string
+	scale float64
+}{
+	"g": {"g", 1}, "mg": {"g", 1e-3}, "ug": {"g", 1e-6}, "kg": {"g", 1e3},
+	"m": {"m", 1}, "cm": {"m", 1e-2}, "mm": {"m", 1e-3}, "km": {"m", 1e3},
+	"L": {"L", 1}, "dL": {"L", 0.1}, "mL": {"L", 1e-3},
+	"ms": {"s", 1e-3}, "s": {"s", 1}, "min": {"s", 60}, "h": {"s", 3600}, "d": {"s", 86400}, "wk": {"s", 604800},
+	"millisecond": {"s", 1e-3}, "milliseconds": {"s", 1e-3}, "second": {"s", 1}, "seconds": {"s", 1},
+	"minute": {"s", 60}, "minutes": {"s", 60}, "hour": {"s", 3600}, "hours": {"s", 3600},
+	"day": {"s", 86400}, "days": {"s", 86400}, "week": {"s", 604800}, "weeks": {"s", 604800},
+	"a": {"mo", 12}, "year": {"mo", 12}, "years": {"mo", 12}, "mo": {"mo", 1}, "month": {"mo", 1}, "months": {"mo", 1},
+}
+
+// convertUnit returns the value of q in unit, if the units are comparable.
+func convertUnit(q fhirpath.Quantity, unit string) (float64, bool) {
+	if q.Unit == unit || (isUnity(q.Unit) && isUnity(unit)) {
+		return q.Value, true
+	}
+	from, okFrom := unitScale[q.Unit]
+	to, okTo := unitScale[unit]
+	if !okFrom || !okTo || from.base != to.base {
+		return 0, false
+	}
+	return q.Value * from.scale / to.scale, true
+}
+
+func isUnity(u string) bool { return u == "" || u == "1" }
+
+// temporalParts returns the calendar fields of t, in UTC for values with a
+// time of day so that values with different offsets compare correctly.
+func temporalParts(t fhirpath.Temporal) [7]int {
+	tm := t.Time
+	if t.Precision > fhirpath.PrecisionDay && t.Kind != fhirpath.Time {
+		tm = tm.UTC()
+	}
+	return [7]int{tm.Year(), int(tm.Month()), tm.Day(), tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond() / 1e6}
+}
+
+// compareTemporal compares a and b up to precision. known is false if the
+// values are equal up to the precision of the less precise one and the other
+// one is more precise.
+func compareTemporal(a, b fhirpath.Temporal, precision fhirpath.Precision) (int, bool) {
+	pa, pb := temporalParts(a), temporalParts(b)
+	start := fhirpath.PrecisionYear
+	if a.Kind == fhirpath.Time {
+		start = fhirpath.PrecisionHour
+	}
+	for p := start; p <= precision; p++ {
+		if p > a.Precision && p > b.Precision {
+			break
+		}
+		if p > a.Precision || p > b.Precision {
+			return 0, false
+		}
+		if pa[p] != pb[p] {
+			return cmpFloat(float64(pa[p]), float64(pb[p])), true
+		}
+	}
+	return 0, true
+}
+
+// precisionOf parses an ELM precision name, i.e. "Day".
+func precisionOf(s string) (fhirpath.Precision, error) {
+	switch strings.ToLower(s) {
+	case "year", "years":
+		return fhirpath.PrecisionYear, nil
+	case "month", "months":
+		return fhirpath.PrecisionMonth, nil
+	case "week", "weeks", "day", "days":
+		return fhirpath.PrecisionDay, nil
+	case "hour", "hours":
+		return fhirpath.PrecisionHour, nil
+	case "minute", "minutes":
+		return fhirpath.PrecisionMinute, nil
+	case "second", "seconds":
+		return fhirpath.PrecisionSecond, nil
+	case "", "millisecond", "milliseconds":
+		return fhirpath.PrecisionMillisecond, nil
+	}
+	return 0, fmt.Errorf("unknown precision %q", s)
+}
+
+// addQuantity adds the calendar duration q to t, truncating the duration to
+// the precision of t as required by CQL.
+func addQuantity(t fhirpath.Temporal, q fhirpath.Quantity) (fhirpath.Temporal, error) {
+	n := int(q.Value)
+	switch q.Unit {
+	case "year", "years", "a":
+		t.Time = t.Time.AddDate(n, 0, 0)
+	case "month", "months", "mo":
+		t.Time = t.Time.AddDate(0, n, 0)
+	case "week", "weeks", "wk":
+		t.Time = t.Time.AddDate(0, 0, 7*n)
+	case "day", "days", "d":
+		t.Time = t.Time.AddDate(0, 0, n)
+	case "hour", "hours", "h":
+		t.Time = t.Time.Add(time.Duration(n) * time.Hour)
+	case "minute", "minutes", "min":
+		t.Time = t.Time.Add(time.Duration(n) * time.Minute)
+	case "second", "seconds", "s":
+		t.Time = t.Time.Add(time.Duration(q.Value * float64(time.Second)))
+	case "millisecond", "milliseconds", "ms":
+		t.Time = t.Time.Add(time.Duration(q.Value * float64(time.Millisecond)))
+	default:
+		return fhirpath.Temporal{}, fmt.Errorf("cannot add a quantity in %q to a date", q.Unit)
+	}
+	return t, nil
+}
+
+// durationBetween returns the number of whole periods of precision between a
+// and b, negative if b is before a.
+func durationBetween(a, b fhirpath.Temporal, precision fhirpath.Precision, weeks bool) (int64, bool) {
+	if a.Precision < precision || b.Precision < precision {
+		if precision > fhirpath.PrecisionDay || a.Precision < fhirpath.PrecisionMonth || b.Precision < fhirpath.PrecisionMonth {
+			return 0, false
+		}
+	}
+	ta, tb := a.Time, b.Time
+	if a.Precision > fhirpath.PrecisionDay || b.Precision > fhirpath.PrecisionDay {
+		ta, tb = ta.UTC(), tb.UTC()
+	}
+	sign := int64(1)
+	if tb.Before(ta) {
+		ta, tb, sign = tb, ta, -1
+	}
+	var n int64
+	switch precision {
+	case fhirpath.PrecisionYear, fhirpath.PrecisionMonth:
+		months := (tb.Year()-ta.Year())*12 + int(tb.Month()) - int(ta.Month())
+		if a.Precision >= fhirpath.PrecisionDay && b.Precision >= fhirpath.PrecisionDay {
+			anchor := time.Date(tb.Year(), tb.Month(), ta.Day(), ta.Hour(), ta.Minute(), ta.Second(), ta.Nanosecond(), tb.Location())
+			if anchor.After(tb) {
+				months--
+			}
+		}
+		if precision == fhirpath.PrecisionYear {
+			n = int64(months / 12)
+		} else {
+			n = int64(months)
+		}
+	case fhirpath.PrecisionDay:
+		da := time.Date(ta.Year(), ta.Month(), ta.Day(), 0, 0, 0, 0, time.UTC)
+		db := time.Date(tb.Year(), tb.Month(), tb.Day(), 0, 0, 0, 0, time.UTC)
+		days := int64(db.Sub(da).Hours() / 24)
+		if tb.Sub(time.Date(tb.Year(), tb.Month(), tb.Day(), 0, 0, 0, 0, tb.Location())) <
+			ta.Sub(time.Date(ta.Year(), ta.Month(), ta.Day(), 0, 0, 0, 0, ta.Location())) {
+			days--
+		}
+		n = days
+		if weeks {
+			n = days / 7
+		}
+	case fhirpath.PrecisionHour:
+		n = int64(tb.Sub(ta) / time.Hour)
+	case fhirpath.PrecisionMinute:
+		n = int64(tb.Sub(ta) / time.Minute)
+	case fhirpath.PrecisionSecond:
+		n = int64(tb.Sub(ta) / time.Second)
+	default:
+		n = int64(tb.Sub(ta) / time.Millisecond)
+	}
+	return sign * n, true
+}
+
+// differenceBetween returns the number of boundaries of precision crossed
+// between a and b.
+func differenceBetween(a, b fhirpath.Temporal, precision fhirpath.Precision, weeks bool) (int64, bool) {
+	if a.Precision < precision || b.Precision < precision {
+		return 0, false
+	}
+	return durationBetween(truncate(a, precision), truncate(b, precision), precision, weeks)
+}
+
+// truncate drops the parts of t finer than precision.
+func truncate(t fhirpath.Temporal, precision fhirpath.Precision) fhirpath.Temporal {
+	if t.Precision <= precision {
+		return t
+	}
+	p := temporalParts(t)
+	loc := t.Time.Location()
+	if t.Precision > fhirpath.PrecisionDay && t.Kind != fhirpath.Time {
+		loc = time.UTC
+	}
+	for i := int(precision) + 1; i < len(p); i++ {
+		p[i] = 0
+		if i <= int(fhirpath.PrecisionDay) {
+			p[i] = 1
+		}
+	}
+	t.Time = time.Date(p[0], time.Month(p[1]), p[2], p[3], p[4], p[5], p[6]*1e6, loc)
+	t.Precision = precision
+	return t
+}
+
+// typeName returns the CQL name of the type of v, for error messages.
+func typeName(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "Boolean"
+	case int64:
+		return "Integer"
+	case float64:
+		return "Decimal"
+	case string:
+		return "String"
+	case fhirpath.Quantity:
+		return "Quantity"
+	case fhirpath.Temporal:
+		return [...]string{"Date", "DateTime", "Time"}[x.Kind]
+	case Code:
+		return "Code"
+	case Concept:
+		return "Concept"
+	case Interval:
+		return "Interval"
+	case Tuple:
+		return "Tuple"
+	case []interface{}:
+		return "List"
+	case ValueSet:
+		return "ValueSet"
+	case proto.Message:
+		return "FHIR." + fhirpath.TypeName(x.ProtoReflect().Descriptor())
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// isType reports whether v is of the ELM type name, a qualified name such as
+// "{urn:hl7-org:elm-types:r1}Integer" or "{http://hl7.org/fhir}Observation".
+func isType(v interface{}, name string) bool {
+	ns, local := splitTypeName(name)
+	if m, ok := v.(proto.Message); ok {
+		if ns == systemNamespace {
+			return isType(system(m), name)
+		}
+		m = elementpath.Unwrap(m)
+		if m == nil {
+			return false
+		}
+		tn := fhirpath.TypeName(m.ProtoReflect().Descriptor())
+		switch local {
+		case tn, "Any":
+			return true
+		case "Resource", "DomainResource":
+			return elementpath.IsResource(m.ProtoReflect().Descriptor())
+		case "Quantity":
+			return tn == "Age" || tn == "Count" || tn == "Distance" || tn == "Duration" || tn == "SimpleQuantity" || tn == "MoneyQuantity"
+		}
+		return false
+	}
+	if ns != systemNamespace && ns != "" {
+		return false
+	}
+	switch local {
+	case "Any":
+		return v != nil
+	case "Long":
+		_, ok := v.(int64)
+		return ok
+	case "Vocabulary":
+		_, isVS := v.(ValueSet)
+		_, isCS := v.(CodeSystem)
+		return isVS || isCS
+	}
+	return typeName(v) == local
+}
+
+const systemNamespace = "urn:hl7-org:elm-types:r1"
+
+// splitTypeName splits "{namespace}Name" into its parts.
+func splitTypeName(name string) (string, string) {
+	if strings.HasPrefix(name, "{") {
+		if i := strings.Index(name, "}"); i > 0 {
+			return name[1:i], name[i+1:]
+		}
+	}
+	if ns, local, ok := strings.Cut(name, "."); ok {
+		switch ns {
+		case "System":
+			return systemNamespace, local
+		case "FHIR":
+			return "http://hl7.org/fhir", local
+		}
+	}
+	return "", name
+}
+
+// distinct removes duplicates from list, using equivalence so that nulls are
+// deduplicated too.
+func distinct(list []interface{}) []interface{} {
+	var out []interface{}
+	for _, v := range list {
+		dup := false
+		for _, w := range out {
+			if eq, known := equal(v, w); (known && eq) || (v == nil && w == nil) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// sortValues sorts list in place by key, nulls first.
+func sortValues(list []interface{}, key func(interface{}) interface{}, desc bool) error {
+	var err error
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := system(key(list[i])), system(key(list[j]))
+		if a == nil || b == nil {
+			return (a == nil && b != nil) != desc
+		}
+		c, _, cerr := compare(a, b)
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	return err
+}
@@ -0,0 +1,438 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvimport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/fhir/go/conceptmap"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+var stepRegexp = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_]*)(?:\[(\d+)\])?$`)
+
+// Options configure an Importer.
+type Options struct {
+	// TimeZone is the IANA time zone used for dates and times without an
+	// explicit offset, both by transforms and by validation; UTC if empty.
+	TimeZone string
+	// Translator resolves the ConceptMaps referenced by Field.ConceptMap.
+	Translator *conceptmap.Translator
+	// OnError is called by Import for every row that fails to convert. If it
+	// returns nil the row is skipped and the import continues; if OnError is
+	// nil the import stops at the first bad row.
+	OnError func(*RowError) error
+}
+
+// RowError is a failure to convert one row of a file.
+type RowError struct {
+	// Line is the 1-based line of the row in the file, counting the header.
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// step is one element of a compiled path. index is -1 for single elements.
+type step struct {
+	name  string
+	index int
+}
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindBoolean
+	kindInteger
+	kindDecimal
+)
+
+type field struct {
+	Field
+	steps      []step
+	kind       kind
+	transforms []Transform
+	lookup     map[string]string
+}
+
+// Importer converts rows into resources according to a Spec. It is safe for
+// concurrent use.
+type Importer struct {
+	spec       *Spec
+	fields     []*field
+	translator *conceptmap.Translator
+	um         *jsonformat.Unmarshaller
+	delimiter  rune
+	onError    func(*RowError) error
+}
+
+// NewImporter compiles spec, checking every element path against the
+// definition of the resource type.
+func NewImporter(spec *Spec, opts Options) (*Importer, error) {
+	tz := opts.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	um, err := jsonformat.NewUnmarshaller(tz, fhirversion.R4)
+	if err != nil {
+		return nil, err
+	}
+	res := resourceDescriptor(spec.ResourceType)
+	if res == nil {
+		return nil, fmt.Errorf("unknown resource type %q", spec.ResourceType)
+	}
+	im := &Importer{spec: spec, translator: opts.Translator, um: um, delimiter: ',', onError: opts.OnError}
+	if spec.Delimiter != "" {
+		r, n := utf8.DecodeRuneInString(spec.Delimiter)
+		if n != len(spec.Delimiter) {
+			return nil, fmt.Errorf("delimiter %q is not a single character", spec.Delimiter)
+		}
+		im.delimiter = r
+	}
+	for i, f := range spec.Fields {
+		cf, err := im.compile(res, f, loc)
+		if err != nil {
+			return nil, fmt.Errorf("field %d (%s): %w", i, f.Path, err)
+		}
+		im.fields = append(im.fields, cf)
+	}
+	return im, nil
+}
+
+func resourceDescriptor(name string) protoreflect.MessageDescriptor {
+	d := (&bcrpb.ContainedResource{}).ProtoReflect().Descriptor()
+	fields := d.Oneofs().Get(0).Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if md := fields.Get(i).Message(); string(md.Name()) == name {
+			return md
+		}
+	}
+	return nil
+}
+
+func (im *Importer) compile(res protoreflect.MessageDescriptor, f Field, loc *time.Location) (*field, error) {
+	if (f.Column == "") == (f.Value == "") {
+		return nil, errors.New("exactly one of column and value must be set")
+	}
+	if f.Lookup != "" && f.ConceptMap != "" {
+		return nil, errors.New("lookup and conceptMap are mutually exclusive")
+	}
+	cf := &field{Field: f}
+	if f.Lookup != "" {
+		if cf.lookup = im.spec.Lookups[f.Lookup]; cf.lookup == nil {
+			return nil, fmt.Errorf("unknown lookup %q", f.Lookup)
+		}
+	}
+	if f.ConceptMap != "" && (im.translator == nil || !im.translator.Has(f.ConceptMap)) {
+		return nil, fmt.Errorf("unknown ConceptMap %q", f.ConceptMap)
+	}
+	for _, t := range f.Transforms {
+		fn, err := ParseTransform(t, loc)
+		if err != nil {
+			return nil, err
+		}
+		cf.transforms = append(cf.transforms, fn)
+	}
+	var err error
+	if cf.steps, cf.kind, err = compilePath(res, f.Path); err != nil {
+		return nil, err
+	}
+	return cf, nil
+}
+
+// compilePath resolves path against the resource descriptor d. It must end on
+// a primitive element; the kind of that element decides how values are
+// encoded in JSON.
+func compilePath(d protoreflect.MessageDescriptor, path string) ([]step, kind, error) {
+	if path == "" {
+		return nil, 0, errors.New("empty element path")
+	}
+	var steps []step
+	parts := strings.Split(path, ".")
+	for i, p := range parts {
+		m := stepRegexp.FindStringSubmatch(p)
+		if m == nil {
+			return nil, 0, fmt.Errorf("invalid element %q", p)
+		}
+		s := step{name: m[1], index: -1}
+		// The literal reference of a Reference is held in a oneof of typed ids
+		// in the proto, but is a plain string in JSON.
+		if s.name == "reference" && d.Name() == "Reference" && i == len(parts)-1 && m[2] == "" {
+			return append(steps, s), kindString, nil
+		}
+		fd, choice, err := elementpath.LookupField(d, s.name)
+		if err != nil {
+			return nil, 0, err
+		}
+		if fd.IsList() {
+			s.index = 0
+			if m[2] != "" {
+				if s.index, err = strconv.Atoi(m[2]); err != nil {
+					return nil, 0, err
+				}
+			}
+		} else if m[2] != "" {
+			return nil, 0, fmt.Errorf("element %q is not repeated", s.name)
+		}
+		steps = append(steps, s)
+		d = fd.Message()
+		if choice != "" {
+			d = d.Fields().ByJSONName(choice).Message()
+		} else if elementpath.IsChoice(d) {
+			return nil, 0, fmt.Errorf("choice element %q must be addressed by its typed name", s.name)
+		}
+	}
+	if !elementpath.IsPrimitive(d) {
+		return nil, 0, fmt.Errorf("element %q is a %s, not a primitive", path, d.Name())
+	}
+	switch d.Name() {
+	case "Boolean":
+		return steps, kindBoolean, nil
+	case "Integer", "PositiveInt", "UnsignedInt":
+		return steps, kindInteger, nil
+	case "Decimal":
+		return steps, kindDecimal, nil
+	}
+	return steps, kindString, nil
+}
+
+// Convert builds a resource from a record keyed by column header. The
+// resource is returned as a validated ContainedResource.
+func (im *Importer) Convert(record map[string]string) (proto.Message, error) {
+	root := map[string]interface{}{"resourceType": im.spec.ResourceType}
+	for _, f := range im.fields {
+		raw, ok := f.Value, true
+		if f.Column != "" {
+			raw, ok = record[f.Column]
+			if !ok {
+				return nil, fmt.Errorf("missing column %q", f.Column)
+			}
+		}
+		v, err := im.value(f, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		if v != nil {
+			setValue(root, f.steps, v)
+		}
+	}
+	compact(root)
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	return im.um.Unmarshal(data)
+}
+
+// value computes the JSON value of f from the cell raw. It returns nil if the
+// element should be left unset.
+func (im *Importer) value(f *field, raw string) (interface{}, error) {
+	v := raw
+	if v == "" {
+		v = f.Default
+	}
+	if v != "" {
+		var err error
+		for _, t := range f.transforms {
+			if v, err = t(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if v == "" {
+		if f.Required {
+			return nil, errors.New("value is required")
+		}
+		return nil, nil
+	}
+	switch {
+	case f.lookup != nil:
+		mapped, ok := f.lookup[v]
+		if !ok {
+			return nil, fmt.Errorf("value %q not found in lookup %q", v, f.Lookup)
+		}
+		v = mapped
+	case f.ConceptMap != "":
+		matches, err := im.translator.Translate(f.ConceptMap, f.System, v)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("code %q has no translation in %s", v, f.ConceptMap)
+		}
+		v = matches[0].Code
+	}
+	switch f.kind {
+	case kindBoolean:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", v)
+		}
+		return b, nil
+	case kindInteger:
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return json.Number(v), nil
+	case kindDecimal:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("invalid decimal %q", v)
+		}
+		return json.Number(v), nil
+	}
+	return v, nil
+}
+
+// setValue stores v at the element addressed by steps, creating the
+// intermediate objects and arrays.
+func setValue(obj map[string]interface{}, steps []step, v interface{}) {
+	s, last := steps[0], len(steps) == 1
+	if s.index < 0 {
+		if last {
+			obj[s.name] = v
+			return
+		}
+		child, ok := obj[s.name].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			obj[s.name] = child
+		}
+		setValue(child, steps[1:], v)
+		return
+	}
+	arr, _ := obj[s.name].([]interface{})
+	for len(arr) <= s.index {
+		arr = append(arr, nil)
+	}
+	if last {
+		arr[s.index] = v
+	} else {
+		child, ok := arr[s.index].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			arr[s.index] = child
+		}
+		setValue(child, steps[1:], v)
+	}
+	obj[s.name] = arr
+}
+
+// compact drops the gaps left in arrays by sparse indices, i.e. a row that
+// populates given[1] but leaves given[0] empty.
+func compact(obj map[string]interface{}) {
+	for k, v := range obj {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			compact(v)
+		case []interface{}:
+			var out []interface{}
+			for _, e := range v {
+				if e == nil {
+					continue
+				}
+				if m, ok := e.(map[string]interface{}); ok {
+					compact(m)
+				}
+				out = append(out, e)
+			}
+			obj[k] = out
+		}
+	}
+}
+
+// Import reads a delimited file with a header row from r and passes every
+// converted resource to emit. Errors returned by emit stop the import.
+func (im *Importer) Import(r io.Reader, emit func(proto.Message) error) error {
+	cr := csv.NewReader(r)
+	cr.Comma = im.delimiter
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	header = append([]string(nil), header...)
+	cols := map[string]bool{}
+	for _, h := range header {
+		cols[h] = true
+	}
+	for _, f := range im.fields {
+		if f.Column != "" && !cols[f.Column] {
+			return fmt.Errorf("header has no column %q", f.Column)
+		}
+	}
+	record := make(map[string]string, len(header))
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return err
+			}
+			if err := im.rowError(&RowError{Line: perr.Line, Err: perr.Err}); err != nil {
+				return err
+			}
+			continue
+		}
+		for i, h := range header {
+			record[h] = row[i]
+		}
+		res, err := im.Convert(record)
+		if err != nil {
+			if err := im.rowError(&RowError{Line: line, Err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := emit(res); err != nil {
+			return err
+		}
+	}
+}
+
+func (im *Importer) rowError(e *RowError) error {
+	if im.onError == nil {
+		return e
+	}
+	return im.onError(e)
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvimport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/fhir/go/conceptmap"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cmpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/concept_map_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+const patientSpec = `
+resourceType: Patient
+lookups:
+  sex: {M: male, F: female}
+fields:
+- path: identifier[0].system
+  value: urn:example:mrn
+- path: identifier[0].value
+  column: mrn
+  required: true
+- path: name.family
+  column: last
+  transforms: [trim]
+- path: name.given[1]
+  column: middle
+- path: name.given[0]
+  column: first
+- path: gender
+  column: sex
+  transforms: [upper]
+  lookup: sex
+- path: birthDate
+  column: dob
+  transforms: ["date:01/02/2006"]
+- path: active
+  column: active
+  default: "true"
+`
+
+func mustImporter(t *testing.T, spec string, opts Options) *Importer {
+	t.Helper()
+	s, err := ParseSpec([]byte(spec))
+	if err != nil {
+		t.Fatalf("ParseSpec() returned unexpected error: %v", err)
+	}
+	im, err := NewImporter(s, opts)
+	if err != nil {
+		t.Fatalf("NewImporter() returned unexpected error: %v", err)
+	}
+	return im
+}
+
+func importAll(t *testing.T, im *Importer, in string) []proto.Message {
+	t.Helper()
+	var got []proto.Message
+	err := im.Import(strings.NewReader(in), func(res proto.Message) error {
+		got = append(got, elementpath.Unwrap(res))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Import() returned unexpected error: %v", err)
+	}
+	return got
+}
+
+func TestImport(t *testing.T) {
+	im := mustImporter(t, patientSpec, Options{})
+	in := "mrn,first,middle,last,sex,dob,active\n" +
+		"123,Jane,,  Doe ,f,03/15/1980,\n" +
+		"456,John,Q,Roe,M,,false\n"
+	got := importAll(t, im, in)
+	want := []proto.Message{
+		&ppb.Patient{
+			Identifier: []*d4pb.Identifier{{
+				System: &d4pb.Uri{Value: "urn:example:mrn"},
+				Value:  &d4pb.String{Value: "123"},
+			}},
+			Active: &d4pb.Boolean{Value: true},
+			Name: []*d4pb.HumanName{{
+				Family: &d4pb.String{Value: "Doe"},
+				Given:  []*d4pb.String{{Value: "Jane"}},
+			}},
+			Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+			BirthDate: &d4pb.Date{ValueUs: 321926400000000, Timezone: "UTC", Precision: d4pb.Date_DAY},
+		},
+		&ppb.Patient{
+			Identifier: []*d4pb.Identifier{{
+				System: &d4pb.Uri{Value: "urn:example:mrn"},
+				Value:  &d4pb.String{Value: "456"},
+			}},
+			Active: &d4pb.Boolean{Value: false},
+			Name: []*d4pb.HumanName{{
+				Family: &d4pb.String{Value: "Roe"},
+				Given:  []*d4pb.String{{Value: "John"}, {Value: "Q"}},
+			}},
+			Gender: &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_MALE},
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Import() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestImport_ObservationWithConceptMap(t *testing.T) {
+	tr, err := conceptmap.NewTranslator(&cmpb.ConceptMap{
+		Url: &d4pb.Uri{Value: "http://example.com/cm/lab"},
+		Group: []*cmpb.ConceptMap_Group{{
+			Source: &d4pb.Uri{Value: "urn:example:local"},
+			Target: &d4pb.Uri{Value: "http://loinc.org"},
+			Element: []*cmpb.ConceptMap_Group_SourceElement{{
+				Code: &d4pb.Code{Value: "GLU"},
+				Target: []*cmpb.ConceptMap_Group_SourceElement_TargetElement{{
+					Code:        &d4pb.Code{Value: "2345-7"},
+					Equivalence: &cmpb.ConceptMap_Group_SourceElement_TargetElement_EquivalenceCode{Value: c4pb.ConceptMapEquivalenceCode_EQUIVALENT},
+				}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewTranslator() returned unexpected error: %v", err)
+	}
+	spec := `{
+		"resourceType": "Observation",
+		"delimiter": "|",
+		"fields": [
+			{"path": "status", "value": "final"},
+			{"path": "code.coding.system", "value": "http://loinc.org"},
+			{"path": "code.coding.code", "column": "test", "conceptMap": "http://example.com/cm/lab", "system": "urn:example:local"},
+			{"path": "subject.reference", "column": "mrn", "transforms": ["prefix:Patient/"]},
+			{"path": "valueQuantity.value", "column": "result", "transforms": ["replace:,=."]},
+			{"path": "valueQuantity.unit", "value": "mg/dL"}
+		]
+	}`
+	im := mustImporter(t, spec, Options{Translator: tr})
+	got := importAll(t, im, "mrn|test|result\n123|GLU|98,5\n")
+	want := []proto.Message{&obspb.Observation{
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: "http://loinc.org"},
+			Code:   &d4pb.Code{Value: "2345-7"},
+		}}},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "123"}}},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+			Value: &d4pb.Decimal{Value: "98.5"},
+			Unit:  &d4pb.String{Value: "mg/dL"},
+		}}},
+	}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Import() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestImport_RowErrors(t *testing.T) {
+	var rowErrs []*RowError
+	im := mustImporter(t, patientSpec, Options{OnError: func(e *RowError) error {
+		rowErrs = append(rowErrs, e)
+		return nil
+	}})
+	in := "mrn,first,middle,last,sex,dob,active\n" +
+		",Jane,,Doe,F,,\n" +
+		"2,Jane,,Doe,X,,\n" +
+		"3,Jane,,Doe,F,1980-01-01,\n" +
+		"4,Jane,,Doe,F,,maybe\n" +
+		"5,Jane,,Doe,F,,\n"
+	got := importAll(t, im, in)
+	if len(got) != 1 {
+		t.Errorf("Import() emitted %d resources, want 1", len(got))
+	}
+	var lines []int
+	for _, e := range rowErrs {
+		lines = append(lines, e.Line)
+	}
+	if diff := cmp.Diff([]int{2, 3, 4, 5}, lines); diff != "" {
+		t.Errorf("Import() reported unexpected row errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestImport_StopsAtFirstError(t *testing.T) {
+	im := mustImporter(t, patientSpec, Options{})
+	in := "mrn,first,middle,last,sex,dob,active\n1,Jane,,Doe,F,,\n2,Jane,,Doe,X,,\n"
+	err := im.Import(strings.NewReader(in), func(proto.Message) error { return nil })
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) || rowErr.Line != 3 {
+		t.Errorf("Import() returned error %v, want RowError on line 3", err)
+	}
+}
+
+func TestImport_MissingColumn(t *testing.T) {
+	im := mustImporter(t, patientSpec, Options{})
+	err := im.Import(strings.NewReader("mrn,first\n1,Jane\n"), func(proto.Message) error { return nil })
+	if err == nil {
+		t.Errorf("Import() succeeded, want error")
+	}
+}
+
+func TestConvert_Validates(t *testing.T) {
+	// Observation.status and Observation.code are required.
+	im := mustImporter(t, `{"resourceType": "Observation", "fields": [{"path": "id", "column": "id"}]}`, Options{})
+	if _, err := im.Convert(map[string]string{"id": "a"}); err == nil {
+		t.Errorf("Convert() succeeded, want validation error")
+	}
+}
+
+func TestNewImporter_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"unknown resource", `{"resourceType": "Unicorn", "fields": []}`},
+		{"unknown element", `{"resourceType": "Patient", "fields": [{"path": "nickname", "column": "a"}]}`},
+		{"complex leaf", `{"resourceType": "Patient", "fields": [{"path": "name", "column": "a"}]}`},
+		{"untyped choice", `{"resourceType": "Patient", "fields": [{"path": "deceased", "column": "a"}]}`},
+		{"index on single", `{"resourceType": "Patient", "fields": [{"path": "gender[0]", "column": "a"}]}`},
+		{"column and value", `{"resourceType": "Patient", "fields": [{"path": "gender", "column": "a", "value": "male"}]}`},
+		{"unknown lookup", `{"resourceType": "Patient", "fields": [{"path": "gender", "column": "a", "lookup": "sex"}]}`},
+		{"unknown transform", `{"resourceType": "Patient", "fields": [{"path": "gender", "column": "a", "transforms": ["rot13"]}]}`},
+		{"unknown concept map", `{"resourceType": "Patient", "fields": [{"path": "gender", "column": "a", "conceptMap": "http://x"}]}`},
+		{"long delimiter", `{"resourceType": "Patient", "delimiter": "||", "fields": []}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := ParseSpec([]byte(test.spec))
+			if err != nil {
+				t.Fatalf("ParseSpec() returned unexpected error: %v", err)
+			}
+			if _, err := NewImporter(s, Options{}); err == nil {
+				t.Errorf("NewImporter() succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csvimport converts rows of delimited flat files into FHIR R4
+// resources according to a declarative mapping spec.
+//
+// A spec names the resource type to produce and lists the elements to
+// populate, each from a column or a constant value, optionally passed through
+// a chain of transforms and a code lookup:
+//
+//	resourceType: Patient
+//	lookups:
+//	  sex: {M: male, F: female}
+//	fields:
+//	- path: identifier[0].system
+//	  value: urn:example:mrn
+//	- path: identifier[0].value
+//	  column: mrn
+//	  required: true
+//	- path: name.family
+//	  column: last_name
+//	  transforms: [trim]
+//	- path: gender
+//	  column: sex
+//	  lookup: sex
+//	- path: birthDate
+//	  column: dob
+//	  transforms: ["date:01/02/2006"]
+//
+// Element paths are relative to the resource and use FHIR JSON element names.
+// Repeated elements may carry an index and default to the first entry. Choice
+// elements must be addressed by their typed name, i.e. "valueQuantity.value".
+// Every resource is run through the jsonformat validation before it is
+// returned.
+package csvimport
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Spec maps the columns of a flat file to the elements of a resource.
+type Spec struct {
+	// ResourceType is the type of resource produced for every row.
+	ResourceType string `yaml:"resourceType" json:"resourceType"`
+	// Delimiter is the field delimiter of the file; a comma if empty.
+	Delimiter string `yaml:"delimiter,omitempty" json:"delimiter,omitempty"`
+	// Lookups are named code tables fields can translate their values through.
+	Lookups map[string]map[string]string `yaml:"lookups,omitempty" json:"lookups,omitempty"`
+	// Fields are applied in order; later fields overwrite earlier ones that
+	// target the same element.
+	Fields []Field `yaml:"fields" json:"fields"`
+}
+
+// Field populates one primitive element of the resource.
+type Field struct {
+	// Path is the element path relative to the resource, i.e. "name[0].given[1]".
+	Path string `yaml:"path" json:"path"`
+	// Column is the header of the column holding the value. Exactly one of
+	// Column and Value must be set.
+	Column string `yaml:"column,omitempty" json:"column,omitempty"`
+	// Value is a constant written to every resource.
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+	// Default replaces an empty cell. It is transformed like any other value.
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+	// Required rejects rows where the cell is empty and there is no default.
+	// Otherwise empty cells leave the element unset.
+	Required bool `yaml:"required,omitempty" json:"required,omitempty"`
+	// Transforms are applied to the value in order. See ParseTransform.
+	Transforms []string `yaml:"transforms,omitempty" json:"transforms,omitempty"`
+	// Lookup names an entry of Spec.Lookups the transformed value is
+	// translated through. Values missing from the table are an error.
+	Lookup string `yaml:"lookup,omitempty" json:"lookup,omitempty"`
+	// ConceptMap is the canonical URL of a ConceptMap the transformed value is
+	// translated through, taking the code of the first match. It requires
+	// Options.Translator.
+	ConceptMap string `yaml:"conceptMap,omitempty" json:"conceptMap,omitempty"`
+	// System is the source code system used with ConceptMap; if empty every
+	// group of the map is searched.
+	System string `yaml:"system,omitempty" json:"system,omitempty"`
+}
+
+// ParseSpec parses a mapping spec in YAML or JSON.
+func ParseSpec(data []byte) (*Spec, error) {
+	var s Spec
+	if err := yaml.UnmarshalStrict(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing mapping spec: %w", err)
+	}
+	return &s, nil
+}
+
+// Transform rewrites a single cell value.
+type Transform func(string) (string, error)
+
+// ParseTransform returns the transform named by spec, which is one of
+//
+//	trim              removes leading and trailing white space
+//	upper, lower      changes the case of the value
+//	prefix:<s>        prepends s, i.e. "prefix:Patient/" for references
+//	suffix:<s>        appends s
+//	replace:<a>=<b>   replaces every a with b
+//	date:<layout>     parses a Go time layout and formats a FHIR date
+//	dateTime:<layout> parses a Go time layout and formats a FHIR dateTime
+//
+// Dates and times without a zone are interpreted in loc.
+func ParseTransform(spec string, loc *time.Location) (Transform, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	switch name {
+	case "trim", "upper", "lower":
+		if hasArg {
+			return nil, fmt.Errorf("transform %q takes no argument", name)
+		}
+	default:
+		if !hasArg || arg == "" {
+			return nil, fmt.Errorf("transform %q requires an argument", name)
+		}
+	}
+	switch name {
+	case "trim":
+		return func(v string) (string, error) { return strings.TrimSpace(v), nil }, nil
+	case "upper":
+		return func(v string) (string, error) { return strings.ToUpper(v), nil }, nil
+	case "lower":
+		return func(v string) (string, error) { return strings.ToLower(v), nil }, nil
+	case "prefix":
+		return func(v string) (string, error) { return arg + v, nil }, nil
+	case "suffix":
+		return func(v string) (string, error) { return v + arg, nil }, nil
+	case "replace":
+		old, repl, ok := strings.Cut(arg, "=")
+		if !ok || old == "" {
+			return nil, fmt.Errorf("transform %q: argument must be <old>=<new>", spec)
+		}
+		return func(v string) (string, error) { return strings.ReplaceAll(v, old, repl), nil }, nil
+	case "date":
+		return func(v string) (string, error) {
+			t, err := time.ParseInLocation(arg, v, loc)
+			if err != nil {
+				return "", err
+			}
+			return t.Format("2006-01-02"), nil
+		}, nil
+	case "dateTime":
+		return func(v string) (string, error) {
+			t, err := time.ParseInLocation(arg, v, loc)
+			if err != nil {
+				return "", err
+			}
+			return t.Format(time.RFC3339Nano), nil
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown transform %q", name)
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csvimport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSpec(t *testing.T) {
+	want := &Spec{
+		ResourceType: "Patient",
+		Lookups:      map[string]map[string]string{"sex": {"M": "male"}},
+		Fields: []Field{
+			{Path: "gender", Column: "sex", Lookup: "sex"},
+			{Path: "active", Value: "true"},
+		},
+	}
+	yamlSpec := `
+resourceType: Patient
+lookups:
+  sex: {M: male}
+fields:
+- {path: gender, column: sex, lookup: sex}
+- path: active
+  value: "true"
+`
+	jsonSpec := `{
+  "resourceType": "Patient",
+  "lookups": {"sex": {"M": "male"}},
+  "fields": [
+    {"path": "gender", "column": "sex", "lookup": "sex"},
+    {"path": "active", "value": "true"}
+  ]
+}`
+	for name, in := range map[string]string{"yaml": yamlSpec, "json": jsonSpec} {
+		got, err := ParseSpec([]byte(in))
+		if err != nil {
+			t.Fatalf("ParseSpec(%s) returned unexpected error: %v", name, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ParseSpec(%s) returned unexpected diff (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestParseSpec_UnknownKey(t *testing.T) {
+	if _, err := ParseSpec([]byte("resourceType: Patient\nfeilds: []\n")); err == nil {
+		t.Errorf("ParseSpec() succeeded, want error")
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	est, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		spec, in, want string
+	}{
+		{"trim", "  a b ", "a b"},
+		{"upper", "abc", "ABC"},
+		{"lower", "ABC", "abc"},
+		{"prefix:Patient/", "1", "Patient/1"},
+		{"suffix:-x", "1", "1-x"},
+		{"replace:,=.", "1,5", "1.5"},
+		{"date:02.01.2006", "31.12.1999", "1999-12-31"},
+		{"dateTime:2006-01-02 15:04", "2020-06-01 08:30", "2020-06-01T08:30:00-04:00"},
+		{"dateTime:2006-01-02T15:04Z07:00", "2020-06-01T08:30+02:00", "2020-06-01T08:30:00+02:00"},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			fn, err := ParseTransform(test.spec, est)
+			if err != nil {
+				t.Fatalf("ParseTransform() returned unexpected error: %v", err)
+			}
+			got, err := fn(test.in)
+			if err != nil {
+				t.Fatalf("transform returned unexpected error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("transform(%q) = %q, want %q", test.in, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTransform_Errors(t *testing.T) {
+	for _, spec := range []string{"trim:x", "prefix", "prefix:", "replace:abc", "date", "soundex"} {
+		if _, err := ParseTransform(spec, time.UTC); err == nil {
+			t.Errorf("ParseTransform(%q) succeeded, want error", spec)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dataset packages FHIR R4 resources into archives that can be
+// exchanged between environments and read back unchanged.
+//
+// An archive, a gzipped tarball or a zip file, holds a manifest.json and an
+// NDJSON file per resource type, i.e. Patient.ndjson. The manifest records
+// the FHIR version of the resources and the number of resources and SHA-256
+// hash of every file, which Read verifies. Resources are written sorted by
+// type and id, and archives carry no timestamps, so the same resources
+// always give the same archive.
+//
+// WriteStore archives the current resources of a fhirstore.ResourceStore and
+// Restore writes those of a Dataset to one, keeping their ids.
+package dataset
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/fhirstore"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// FHIRVersion is the FHIR version of the archives written and read by this
+// package.
+const FHIRVersion = "4.0.1"
+
+// ManifestName is the name of the manifest in archives.
+const ManifestName = "manifest.json"
+
+// Format is an archive format.
+type Format int
+
+const (
+	// TarGz is a gzipped tarball.
+	TarGz Format = iota
+	// Zip is a zip file.
+	Zip
+)
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	FHIRVersion string `json:"fhirVersion"`
+	// Total is the number of resources of the archive.
+	Total int    `json:"total"`
+	Files []File `json:"files"`
+}
+
+// File describes an NDJSON file of an archive.
+type File struct {
+	Name         string `json:"name"`
+	ResourceType string `json:"resourceType"`
+	Count        int    `json:"count"`
+	// SHA256 is the hex encoded SHA-256 hash of the file.
+	SHA256 string `json:"sha256"`
+}
+
+// Dataset is an archive read by Read.
+type Dataset struct {
+	Manifest *Manifest
+	// Resources are the resources of the archive, in the order of its
+	// manifest and of their lines.
+	Resources []*r4pb.ContainedResource
+}
+
+// Write writes an archive of resources in format to w and returns its
+// manifest.
+func Write(w io.Writer, format Format, resources []*r4pb.ContainedResource) (*Manifest, error) {
+	m, err := jsonformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		return nil, err
+	}
+	type entry struct {
+		id   string
+		data []byte
+	}
+	byType := map[string][]entry{}
+	for _, cr := range resources {
+		res := elementpath.Unwrap(cr)
+		typ := elementpath.ResourceType(res)
+		if typ == "" {
+			return nil, fmt.Errorf("empty ContainedResource")
+		}
+		data, err := m.MarshalResource(res)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling %s: %w", typ, err)
+		}
+		byType[typ] = append(byType[typ], entry{elementpath.ID(cr), data})
+	}
+
+	man := &Manifest{FHIRVersion: FHIRVersion, Total: len(resources), Files: []File{}}
+	contents := map[string][]byte{}
+	for typ, entries := range byType {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].id < entries[j].id })
+		var b bytes.Buffer
+		for _, e := range entries {
+			b.Write(e.data)
+			b.WriteByte('\n')
+		}
+		f := File{Name: typ + ".ndjson", ResourceType: typ, Count: len(entries), SHA256: hash(b.Bytes())}
+		man.Files = append(man.Files, f)
+		contents[f.Name] = b.Bytes()
+	}
+	sort.Slice(man.Files, func(i, j int) bool { return man.Files[i].Name < man.Files[j].Name })
+	manData, err := json.MarshalIndent(man, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	aw, err := newArchiveWriter(w, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := aw.add(ManifestName, manData); err != nil {
+		return nil, err
+	}
+	for _, f := range man.Files {
+		if err := aw.add(f.Name, contents[f.Name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := aw.close(); err != nil {
+		return nil, err
+	}
+	return man, nil
+}
+
+// WriteStore writes an archive of the current resources of s, without
+// deleted ones, in format to w and returns its manifest.
+func WriteStore(ctx context.Context, w io.Writer, format Format, s fhirstore.ResourceStore) (*Manifest, error) {
+	versions, err := s.History(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	// History is newest first, so the first version of a resource is its
+	// current one.
+	seen := map[string]bool{}
+	var resources []*r4pb.ContainedResource
+	for _, v := range versions {
+		key := v.ResourceType + "/" + v.ID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !v.Deleted {
+			resources = append(resources, v.Resource)
+		}
+	}
+	return Write(w, format, resources)
+}
+
+// Read reads an archive written by Write, in either format, and verifies
+// the hashes and counts of its files against its manifest.
+func Read(r io.Reader) (*Dataset, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	files := map[string][]byte{}
+	switch {
+	case magic[0] == 0x1f && magic[1] == 0x8b:
+		err = readTarGz(br, files)
+	case magic[0] == 'P' && magic[1] == 'K':
+		err = readZip(br, files)
+	default:
+		return nil, fmt.Errorf("reading archive: not a gzipped tarball or zip file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	return parse(files)
+}
+
+func parse(files map[string][]byte) (*Dataset, error) {
+	data, ok := files[ManifestName]
+	if !ok {
+		return nil, fmt.Errorf("no %s", ManifestName)
+	}
+	man := &Manifest{}
+	if err := json.Unmarshal(data, man); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestName, err)
+	}
+	if man.FHIRVersion != FHIRVersion {
+		return nil, fmt.Errorf("unsupported FHIR version %q", man.FHIRVersion)
+	}
+	um, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4)
+	if err != nil {
+		return nil, err
+	}
+	ds := &Dataset{Manifest: man}
+	for _, f := range man.Files {
+		data, ok := files[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: missing from archive", f.Name)
+		}
+		if got := hash(data); got != f.SHA256 {
+			return nil, fmt.Errorf("%s: SHA-256 %s, manifest has %s", f.Name, got, f.SHA256)
+		}
+		n := 0
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			n++
+			cr, err := um.UnmarshalR4(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", f.Name, n, err)
+			}
+			if typ := elementpath.ResourceType(elementpath.Unwrap(cr)); typ != f.ResourceType {
+				return nil, fmt.Errorf("%s:%d: %s in file of %s", f.Name, n, typ, f.ResourceType)
+			}
+			ds.Resources = append(ds.Resources, cr)
+		}
+		if n != f.Count {
+			return nil, fmt.Errorf("%s: %d resources, manifest has %d", f.Name, n, f.Count)
+		}
+	}
+	if len(ds.Resources) != man.Total {
+		return nil, fmt.Errorf("%d resources, manifest has %d", len(ds.Resources), man.Total)
+	}
+	return ds, nil
+}
+
+// Restore writes the resources of ds to s with their ids, as updates that
+// create the resources s does not have.
+func Restore(ctx context.Context, s fhirstore.ResourceStore, ds *Dataset) error {
+	for _, cr := range ds.Resources {
+		if _, err := s.Update(ctx, cr, ""); err != nil {
+			typ := elementpath.ResourceType(elementpath.Unwrap(cr))
+			return fmt.Errorf("restoring %s/%s: %w", typ, elementpath.ID(cr), err)
+		}
+	}
+	return nil
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// archiveWriter adds files to an archive.
+type archiveWriter struct {
+	add   func(name string, data []byte) error
+	close func() error
+}
+
+func newArchiveWriter(w io.Writer, format Format) (*archiveWriter, error) {
+	switch format {
+	case TarGz:
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		return &archiveWriter{
+			add: func(name string, data []byte) error {
+				h := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg, Format: tar.FormatPAX}
+				if err := tw.WriteHeader(h); err != nil {
+					return err
+				}
+				_, err := tw.Write(data)
+				return err
+			},
+			close: func() error {
+				if err := tw.Close(); err != nil {
+					return err
+				}
+				return gz.Close()
+			},
+		}, nil
+	case Zip:
+		zw := zip.NewWriter(w)
+		return &archiveWriter{
+			add: func(name string, data []byte) error {
+				fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+				if err != nil {
+					return err
+				}
+				_, err = fw.Write(data)
+				return err
+			},
+			close: zw.Close,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown archive format %d", format)
+}
+
+func readTarGz(r io.Reader, files map[string][]byte) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg || strings.Contains(h.Name, "/") {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		files[h.Name] = data
+	}
+}
+
+func readZip(r io.Reader, files map[string][]byte) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || strings.Contains(f.Name, "/") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		files[f.Name] = data
+	}
+	return nil
+}
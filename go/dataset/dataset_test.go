@@ -0,0 +1,190 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataset
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/fhir/go/fhirstore/memory"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func patient(id, family string) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{
+		Id:   &d4pb.Id{Value: id},
+		Name: []*d4pb.HumanName{{Family: &d4pb.String{Value: family}}},
+	}}}
+}
+
+func observation(id, subject string) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: &obspb.Observation{
+		Id:      &d4pb.Id{Value: id},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: subject}}},
+	}}}
+}
+
+func TestWriteRead(t *testing.T) {
+	resources := []*r4pb.ContainedResource{
+		patient("b", "Doe"),
+		observation("o1", "a"),
+		patient("a", "Roe"),
+	}
+	want := []*r4pb.ContainedResource{
+		observation("o1", "a"),
+		patient("a", "Roe"),
+		patient("b", "Doe"),
+	}
+	for _, format := range []Format{TarGz, Zip} {
+		var b bytes.Buffer
+		man, err := Write(&b, format, resources)
+		if err != nil {
+			t.Fatalf("Write(%v) returned unexpected error: %v", format, err)
+		}
+		if man.Total != 3 || len(man.Files) != 2 || man.Files[0].Name != "Observation.ndjson" || man.Files[1].Count != 2 {
+			t.Errorf("Write(%v) returned manifest %+v, want Observation.ndjson with 1 and Patient.ndjson with 2 resources", format, man)
+		}
+
+		var again bytes.Buffer
+		if _, err := Write(&again, format, resources); err != nil {
+			t.Fatalf("Write(%v) returned unexpected error: %v", format, err)
+		}
+		if !bytes.Equal(b.Bytes(), again.Bytes()) {
+			t.Errorf("Write(%v) wrote different archives for the same resources", format)
+		}
+
+		ds, err := Read(bytes.NewReader(b.Bytes()))
+		if err != nil {
+			t.Fatalf("Read(%v) returned unexpected error: %v", format, err)
+		}
+		if diff := cmp.Diff(man, ds.Manifest); diff != "" {
+			t.Errorf("Read(%v) manifest diff (-want +got):\n%s", format, diff)
+		}
+		if diff := cmp.Diff(want, ds.Resources, protocmp.Transform()); diff != "" {
+			t.Errorf("Read(%v) resources diff (-want +got):\n%s", format, diff)
+		}
+	}
+}
+
+// rewrite returns the zip archive data with the file name replaced by data.
+func rewrite(t *testing.T, archive []byte, name string, data []byte) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for _, f := range zr.File {
+		content := data
+		if f.Name != name {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content, err = io.ReadAll(rc); err != nil {
+				t.Fatal(err)
+			}
+			rc.Close()
+		}
+		fw, err := zw.Create(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestRead_Errors(t *testing.T) {
+	var b bytes.Buffer
+	if _, err := Write(&b, Zip, []*r4pb.ContainedResource{patient("a", "Doe")}); err != nil {
+		t.Fatalf("Write() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not an archive", []byte("{}")},
+		{"tampered file", rewrite(t, b.Bytes(), "Patient.ndjson", []byte(`{"resourceType":"Patient","id":"b"}`+"\n"))},
+		{"other FHIR version", rewrite(t, b.Bytes(), ManifestName, []byte(`{"fhirVersion":"3.0.2","total":0,"files":[]}`))},
+		{"wrong count", rewrite(t, b.Bytes(), ManifestName, []byte(`{"fhirVersion":"4.0.1","total":2,"files":[]}`))},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Read(bytes.NewReader(tc.data)); err == nil {
+				t.Errorf("Read() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestWriteStoreRestore(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	for _, cr := range []*r4pb.ContainedResource{patient("a", "Doe"), patient("b", "Roe"), observation("o1", "a")} {
+		if _, err := src.Update(ctx, cr, ""); err != nil {
+			t.Fatalf("Update() returned unexpected error: %v", err)
+		}
+	}
+	if _, err := src.Update(ctx, patient("a", "Poe"), ""); err != nil {
+		t.Fatalf("Update() returned unexpected error: %v", err)
+	}
+	if err := src.Delete(ctx, "Patient", "b"); err != nil {
+		t.Fatalf("Delete() returned unexpected error: %v", err)
+	}
+
+	var b bytes.Buffer
+	man, err := WriteStore(ctx, &b, TarGz, src)
+	if err != nil {
+		t.Fatalf("WriteStore() returned unexpected error: %v", err)
+	}
+	if man.Total != 2 {
+		t.Errorf("WriteStore() wrote %d resources, want 2", man.Total)
+	}
+	ds, err := Read(&b)
+	if err != nil {
+		t.Fatalf("Read() returned unexpected error: %v", err)
+	}
+	dst := memory.New()
+	if err := Restore(ctx, dst, ds); err != nil {
+		t.Fatalf("Restore() returned unexpected error: %v", err)
+	}
+	got, err := dst.Read(ctx, "Patient", "a")
+	if err != nil {
+		t.Fatalf("Read() returned unexpected error: %v", err)
+	}
+	if family := got.GetPatient().GetName()[0].GetFamily().GetValue(); family != "Poe" {
+		t.Errorf("restored Patient/a has family %q, want %q", family, "Poe")
+	}
+	if _, err := dst.Read(ctx, "Patient", "b"); err == nil {
+		t.Errorf("Read(Patient/b) of restored store succeeded, want error")
+	}
+	if _, err := dst.Read(ctx, "Observation", "o1"); err != nil {
+		t.Errorf("Read(Observation/o1) of restored store returned unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dedupe finds and removes duplicate entries of the repeated elements
+// of R4 resources, where a second entry adds nothing or misleads: the same
+// identifier listed twice, a concept with the same coding twice, or a
+// performer referenced twice.
+//
+// Entries are duplicates if they are the same Identifier, as by
+// fhirtypes.SameIdentifier, the same Coding, with the same system and code
+// and no conflicting versions, or the same Reference, as by
+// fhirtypes.SameReference. Elements of other types are not checked, as their
+// repetition may be meaningful, nor are the resources contained in others.
+// Find reports duplicates and Remove drops them, keeping the first entry of
+// each.
+package dedupe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// Duplicate is an entry of a repeated element that is the same as an earlier
+// entry of the element.
+type Duplicate struct {
+	// Path is the path of the entry, i.e. "Patient.identifier[2]".
+	Path string
+	// Of is the path of the earlier entry, i.e. "Patient.identifier[0]".
+	Of string
+}
+
+var (
+	identifierName = (&d4pb.Identifier{}).ProtoReflect().Descriptor().FullName()
+	codingName     = (&d4pb.Coding{}).ProtoReflect().Descriptor().FullName()
+	referenceName  = (&d4pb.Reference{}).ProtoReflect().Descriptor().FullName()
+)
+
+// Find returns the duplicate entries of res, which may be wrapped in a
+// ContainedResource, in document order.
+func Find(res proto.Message) []Duplicate {
+	return run(res, false)
+}
+
+// Remove removes the duplicate entries of res, which may be wrapped in a
+// ContainedResource, and returns them. Their paths are those of res before
+// the removal.
+func Remove(res proto.Message) []Duplicate {
+	return run(res, true)
+}
+
+func run(res proto.Message, remove bool) []Duplicate {
+	m := elementpath.Unwrap(res)
+	if m == nil {
+		return nil
+	}
+	var out []Duplicate
+	walk(m.ProtoReflect(), elementpath.ResourceType(m), remove, &out)
+	return out
+}
+
+func walk(m protoreflect.Message, path string, remove bool, out *[]Duplicate) {
+	choice := elementpath.IsChoice(m.Descriptor())
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil || fd.IsMap() || elementpath.IsPrimitive(fd.Message()) {
+			return true
+		}
+		p := path + "." + fd.JSONName()
+		if choice {
+			p = path + strings.Title(fd.JSONName())
+		}
+		if !fd.IsList() {
+			walk(v.Message(), p, remove, out)
+			return true
+		}
+		l := v.List()
+		dup := duplicates(l, sameFunc(fd.Message().FullName()))
+		kept := 0
+		for i := 0; i < l.Len(); i++ {
+			if j, ok := dup[i]; ok {
+				*out = append(*out, Duplicate{Path: fmt.Sprintf("%s[%d]", p, i), Of: fmt.Sprintf("%s[%d]", p, j)})
+				if remove {
+					continue
+				}
+			}
+			walk(l.Get(i).Message(), fmt.Sprintf("%s[%d]", p, i), remove, out)
+			if remove {
+				l.Set(kept, l.Get(i))
+				kept++
+			}
+		}
+		if remove {
+			l.Truncate(kept)
+		}
+		return true
+	})
+}
+
+// duplicates returns the indices of the entries of l that are the same as
+// an earlier one by same, mapped to the index of the first of them.
+func duplicates(l protoreflect.List, same func(a, b proto.Message) bool) map[int]int {
+	if same == nil {
+		return nil
+	}
+	dup := map[int]int{}
+	for i := 1; i < l.Len(); i++ {
+		for j := 0; j < i; j++ {
+			if _, ok := dup[j]; ok {
+				continue
+			}
+			if same(l.Get(j).Message().Interface(), l.Get(i).Message().Interface()) {
+				dup[i] = j
+				break
+			}
+		}
+	}
+	return dup
+}
+
+// sameFunc returns the function telling whether two entries of the type
+// name are the same, or nil if entries of the type are not checked.
+func sameFunc(name protoreflect.FullName) func(a, b proto.Message) bool {
+	switch name {
+	case identifierName:
+		return func(a, b proto.Message) bool {
+			return fhirtypes.SameIdentifier(a.(*d4pb.Identifier), b.(*d4pb.Identifier))
+		}
+	case codingName:
+		return func(a, b proto.Message) bool {
+			return sameCoding(a.(*d4pb.Coding), b.(*d4pb.Coding))
+		}
+	case referenceName:
+		return func(a, b proto.Message) bool {
+			return fhirtypes.SameReference(a.(*d4pb.Reference), b.(*d4pb.Reference), "")
+		}
+	}
+	return nil
+}
+
+func sameCoding(a, b *d4pb.Coding) bool {
+	system, code := a.GetSystem().GetValue(), a.GetCode().GetValue()
+	if system == "" || code == "" {
+		return false
+	}
+	if v := a.GetVersion().GetValue(); v != "" {
+		system += "|" + v
+	}
+	return fhirtypes.CodingMatches(b, system, code)
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedupe
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+)
+
+func identifier(system, value string) *d4pb.Identifier {
+	return &d4pb.Identifier{System: &d4pb.Uri{Value: system}, Value: &d4pb.String{Value: value}}
+}
+
+func coding(system, version, code, display string) *d4pb.Coding {
+	c := &d4pb.Coding{System: &d4pb.Uri{Value: system}, Code: &d4pb.Code{Value: code}}
+	if version != "" {
+		c.Version = &d4pb.String{Value: version}
+	}
+	if display != "" {
+		c.Display = &d4pb.String{Value: display}
+	}
+	return c
+}
+
+func practitioner(id string) *d4pb.Reference {
+	return &d4pb.Reference{Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: id}}}
+}
+
+func testObservation() *obspb.Observation {
+	return &obspb.Observation{
+		Identifier: []*d4pb.Identifier{
+			identifier("http://example.com/ids", "1"),
+			identifier("http://example.com/ids", "2"),
+			identifier("HTTP://EXAMPLE.com/ids/", "1"),
+			{Value: &d4pb.String{Value: "3"}},
+			{Value: &d4pb.String{Value: "3"}},
+		},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{
+			coding("http://loinc.org", "", "2345-7", "Glucose"),
+			coding("http://loinc.org", "2.74", "2345-7", ""),
+			coding("http://loinc.org", "", "2339-0", ""),
+		}},
+		Performer: []*d4pb.Reference{
+			practitioner("1"),
+			{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: "Practitioner/1"}}},
+			practitioner("2"),
+			{Display: &d4pb.String{Value: "Dr. Smith"}},
+			{Display: &d4pb.String{Value: "Dr. Smith"}},
+		},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_CodeableConcept{CodeableConcept: &d4pb.CodeableConcept{
+			Coding: []*d4pb.Coding{
+				coding("http://snomed.info/sct", "a", "260385009", ""),
+				coding("http://snomed.info/sct", "b", "260385009", ""),
+				coding("http://snomed.info/sct", "a", "260385009", "Negative"),
+			},
+		}}},
+	}
+}
+
+func TestFind(t *testing.T) {
+	want := []Duplicate{
+		{Path: "Observation.identifier[2]", Of: "Observation.identifier[0]"},
+		{Path: "Observation.code.coding[1]", Of: "Observation.code.coding[0]"},
+		{Path: "Observation.performer[1]", Of: "Observation.performer[0]"},
+		{Path: "Observation.valueCodeableConcept.coding[2]", Of: "Observation.valueCodeableConcept.coding[0]"},
+	}
+	obs := testObservation()
+	if diff := cmp.Diff(want, Find(obs)); diff != "" {
+		t.Errorf("Find() diff (-want +got):\n%s", diff)
+	}
+	if !proto.Equal(obs, testObservation()) {
+		t.Errorf("Find() modified the resource")
+	}
+	cr := &bcrpb.ContainedResource{OneofResource: &bcrpb.ContainedResource_Observation{Observation: obs}}
+	if diff := cmp.Diff(want, Find(cr)); diff != "" {
+		t.Errorf("Find(ContainedResource) diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	obs := testObservation()
+	if got := Remove(obs); len(got) != 4 {
+		t.Errorf("Remove() returned %d duplicates, want 4", len(got))
+	}
+	want := testObservation()
+	want.Identifier = append(want.Identifier[:2], want.Identifier[3:]...)
+	want.Code.Coding = append(want.Code.Coding[:1], want.Code.Coding[2])
+	want.Performer = append(want.Performer[:1], want.Performer[2:]...)
+	vc := want.GetValue().GetCodeableConcept()
+	vc.Coding = vc.Coding[:2]
+	if diff := cmp.Diff(want, obs, protocmp.Transform()); diff != "" {
+		t.Errorf("Remove() diff (-want +got):\n%s", diff)
+	}
+	if got := Find(obs); len(got) != 0 {
+		t.Errorf("Find() after Remove() = %v, want none", got)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deid
+
+import (
+	"fmt"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"gopkg.in/yaml.v2"
+)
+
+// Action is what is done to the elements a rule matches.
+type Action string
+
+// Actions.
+const (
+	// Keep leaves the element and everything in it untouched.
+	Keep Action = "keep"
+	// Redact removes the element.
+	Redact Action = "redact"
+	// Hash replaces string values with their keyed HMAC-SHA256, in hex. It
+	// applies to primitives with a string value, to the value of
+	// Identifiers, to the target ids of References, whose display is
+	// removed, and to the ids of resource URLs such as the fullUrls of Bundle
+	// entries.
+	Hash Action = "hash"
+	// Generalize truncates date, dateTime and instant values to the
+	// precision of the rule.
+	Generalize Action = "generalize"
+	// Jitter moves date, dateTime and instant values by a random number of
+	// days, up to the days of the rule in either direction.
+	Jitter Action = "jitter"
+	// Pseudonymize replaces resource ids, references, Identifier values and
+	// other string values with pseudonyms derived from the key, so that the
+	// same value always gets the same pseudonym, across resources, files and
+	// runs using the same key.
+	Pseudonymize Action = "pseudonymize"
+	// Shift moves date, dateTime and instant values by a number of days, up
+	// to the days of the rule in either direction, that is constant for the
+	// patient the resource belongs to. Intervals between the dates of a
+	// patient are thus preserved.
+	Shift Action = "shift"
+	// Scrub masks the spans of free text the Scrubber of the Deidentifier
+	// finds, keeping the length of the text. It applies to primitives with
+	// a string value, to the div of Narratives, of which only the character
+	// data is scrubbed, and to the text of Annotations.
+	Scrub Action = "scrub"
+)
+
+// Config lists the de-identification rules. For every element, the first
+// rule matching it applies; elements no rule matches are kept, but the
+// rules still apply to their children.
+type Config struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule selects elements by path, type or extension URL. Exactly one of
+// Path, Type and Extension must be set.
+type Rule struct {
+	// Path is an element path using FHIR JSON names, i.e.
+	// "Patient.address.postalCode". Its first segment is a resource type,
+	// or "*" for any. Choice elements match both their base name,
+	// "Observation.effective", and their typed name,
+	// "Observation.effectiveDateTime".
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Type is a FHIR data type name, i.e. "HumanName" or "dateTime".
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Extension is the URL of the extensions to match.
+	Extension string `yaml:"extension,omitempty" json:"extension,omitempty"`
+	Action    Action `yaml:"action" json:"action"`
+	// Precision is the precision Generalize keeps: "year", the default, or
+	// "month".
+	Precision string `yaml:"precision,omitempty" json:"precision,omitempty"`
+	// Days is the largest shift of Jitter and Shift, in days.
+	Days int `yaml:"days,omitempty" json:"days,omitempty"`
+}
+
+// ParseConfig parses a rule set in YAML or JSON, i.e.
+//
+//	rules:
+//	- {path: "*.id", action: hash}
+//	- {type: HumanName, action: redact}
+//	- {type: date, action: generalize, precision: month}
+func ParseConfig(data []byte) (*Config, error) {
+	var c Config
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing de-identification config: %w", err)
+	}
+	return &c, nil
+}
+
+func (r Rule) validate() error {
+	set := 0
+	for _, s := range []string{r.Path, r.Type, r.Extension} {
+		if s != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("rule must have exactly one of path, type and extension")
+	}
+	if r.Path != "" {
+		if _, _, err := elementpath.Split(r.Path); err != nil {
+			return err
+		}
+	}
+	switch r.Action {
+	case Keep, Redact, Hash, Pseudonymize, Scrub:
+	case Generalize:
+		switch r.Precision {
+		case "", "year", "month":
+		default:
+			return fmt.Errorf("invalid precision %q", r.Precision)
+		}
+	case Jitter, Shift:
+		if r.Days <= 0 {
+			return fmt.Errorf("%s requires a positive number of days", r.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+	return nil
+}
+
+// SafeHarbor returns a rule set implementing the HIPAA Safe Harbor method,
+// 45 CFR 164.514(b)(2), for the elements it can recognize by type: names,
+// addresses, contact points, identifiers, attachments such as photos,
+// narratives and free-text annotations are removed, and dates are
+// generalized to their year. Resource ids and references, including the
+// identifiers of logical references and the URLs of Bundle entries, are
+// hashed, which keeps the resources linked without revealing the original
+// ids.
+//
+// Ages over 89, which Safe Harbor requires to be aggregated, and
+// identifying values in free-text string elements are not handled.
+func SafeHarbor() *Config {
+	return &Config{Rules: []Rule{
+		{Path: "*.id", Action: Hash},
+		{Type: "Reference", Action: Hash},
+		{Path: "Bundle.link.url", Action: Hash},
+		{Path: "Bundle.entry.fullUrl", Action: Hash},
+		{Path: "Bundle.entry.request.url", Action: Hash},
+		{Path: "Bundle.entry.response.location", Action: Hash},
+		{Path: "Device.udiCarrier", Action: Redact},
+		{Path: "Device.serialNumber", Action: Redact},
+		{Type: "HumanName", Action: Redact},
+		{Type: "Address", Action: Redact},
+		{Type: "ContactPoint", Action: Redact},
+		{Type: "Identifier", Action: Redact},
+		{Type: "Attachment", Action: Redact},
+		{Type: "Narrative", Action: Redact},
+		{Type: "Annotation", Action: Redact},
+		{Type: "date", Action: Generalize},
+		{Type: "dateTime", Action: Generalize},
+		{Type: "instant", Action: Generalize},
+	}}
+}
@@ -0,0 +1,478 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deid de-identifies FHIR R4 resources according to configurable
+// rule sets.
+//
+// Rules select elements by path, data type or extension URL and redact,
+// hash, pseudonymize, generalize, jitter, shift or scrub them, or keep them
+// untouched. The rules apply to every element of a resource, including
+// extensions, contained resources and the resources of Bundle entries, which
+// are matched by paths rooted at their own type. SafeHarbor returns a
+// default rule set for the HIPAA Safe Harbor method.
+//
+// Pseudonyms and date shifts are derived from a key rather than drawn at
+// random, so that data de-identified in separate files and runs with the
+// same key remains linked: a patient keeps the same pseudonym and all their
+// dates move by the same number of days.
+//
+// Free text is scrubbed by a caller-supplied Scrubber, such as Patterns or
+// a named entity recognizer, whose spans are masked in place so that
+// offsets into the text remain valid; ResourceReport returns them.
+package deid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+	"regexp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	// Registers ContainedResource, which contained resources are packed as.
+	_ "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// Options configures a Deidentifier.
+type Options struct {
+	// Key is the HMAC key of Hash, Pseudonymize and Shift, which is
+	// required if a rule uses them. Keeping the key of a tenant keeps its
+	// pseudonyms and date shifts stable over time.
+	Key []byte
+	// Scrubber finds the identifying spans of free text, which Scrub rules
+	// require.
+	Scrubber Scrubber
+}
+
+// Deidentifier applies a rule set to resources. It is safe for concurrent
+// use.
+type Deidentifier struct {
+	rules    []Rule
+	key      []byte
+	scrubber Scrubber
+}
+
+// New returns a Deidentifier for cfg.
+func New(cfg *Config, opts Options) (*Deidentifier, error) {
+	for i, r := range cfg.Rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		switch r.Action {
+		case Hash, Pseudonymize, Shift:
+			if len(opts.Key) == 0 {
+				return nil, fmt.Errorf("rule %d: %s requires a key", i, r.Action)
+			}
+		case Scrub:
+			if opts.Scrubber == nil {
+				return nil, fmt.Errorf("rule %d: %s requires a scrubber", i, r.Action)
+			}
+		}
+	}
+	return &Deidentifier{rules: cfg.Rules, key: opts.Key, scrubber: opts.Scrubber}, nil
+}
+
+// Resource de-identifies res, which may be a ContainedResource, in place.
+func (d *Deidentifier) Resource(res proto.Message) error {
+	_, err := d.ResourceReport(res)
+	return err
+}
+
+// ResourceReport de-identifies res like Resource and returns the spans of
+// free text that Scrub rules scrubbed, in the order they were scrubbed.
+func (d *Deidentifier) ResourceReport(res proto.Message) ([]Scrubbed, error) {
+	a, err := d.ResourceAudit(res)
+	return a.Scrubbed, err
+}
+
+// Applied is the application of a rule to an element.
+type Applied struct {
+	// Path is the element path of the element, i.e. "Patient.name". Choice
+	// elements are reported by their base name.
+	Path string
+	// Rule is the index of the rule in the Config.
+	Rule   int
+	Action Action
+}
+
+// Audit is what the de-identification of a resource did.
+type Audit struct {
+	// Applied are the rules applied to elements, other than Keep, in the
+	// order they were applied.
+	Applied []Applied
+	// Scrubbed are the spans of free text that Scrub rules scrubbed.
+	Scrubbed []Scrubbed
+}
+
+// ResourceAudit de-identifies res like Resource and returns what was done
+// to it. The Audit is returned even on error, for the elements processed
+// before it.
+func (d *Deidentifier) ResourceAudit(res proto.Message) (*Audit, error) {
+	a := &Audit{}
+	res = elementpath.Unwrap(res)
+	if res == nil {
+		return a, nil
+	}
+	err := d.resource(res.ProtoReflect(), nil, a)
+	return a, err
+}
+
+// scope is the resource being de-identified.
+type scope struct {
+	resourceType string
+	// contained is set for contained resources, whose ids are local to
+	// their container.
+	contained bool
+	// patient is the relative reference of the patient the resource belongs
+	// to, or of the patient of its container.
+	patient string
+	// audit collects the applied rules and scrubbed spans.
+	audit *Audit
+}
+
+// resource de-identifies m, which is contained in the resource of parent if
+// parent is not nil, and records what it does in audit.
+func (d *Deidentifier) resource(m protoreflect.Message, parent *scope, audit *Audit) error {
+	s := &scope{resourceType: string(m.Descriptor().Name()), contained: parent != nil, audit: audit}
+	// The patient is found before the references are de-identified.
+	s.patient = elementpath.PatientOf(m)
+	if s.patient == "" && parent != nil {
+		s.patient = parent.patient
+	}
+	return d.fields(m, s.resourceType, s)
+}
+
+// fields applies the rules to the fields of m, the element at path.
+func (d *Deidentifier) fields(m protoreflect.Message, path string, s *scope) error {
+	var fds []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if fd.Message() != nil {
+			fds = append(fds, fd)
+		}
+		return true
+	})
+	for _, fd := range fds {
+		child := path + "." + fd.JSONName()
+		if !fd.IsList() {
+			remove, err := d.element(m.Mutable(fd).Message(), child, s)
+			if err != nil {
+				return err
+			}
+			if remove {
+				m.Clear(fd)
+			}
+			continue
+		}
+		l := m.Mutable(fd).List()
+		kept := 0
+		for i := 0; i < l.Len(); i++ {
+			v := l.Get(i)
+			remove, err := d.element(v.Message(), child, s)
+			if err != nil {
+				return err
+			}
+			if !remove {
+				l.Set(kept, v)
+				kept++
+			}
+		}
+		l.Truncate(kept)
+		if kept == 0 {
+			m.Clear(fd)
+		}
+	}
+	return nil
+}
+
+// element applies the rules to m, the element at path, and reports whether
+// it is to be removed.
+func (d *Deidentifier) element(m protoreflect.Message, path string, s *scope) (bool, error) {
+	desc := m.Descriptor()
+	switch {
+	case elementpath.IsContainedResource(desc):
+		// The resources of Bundle entries and the like stand on their own.
+		if res := elementpath.Unwrap(m.Interface()); res != nil {
+			return false, d.resource(res.ProtoReflect(), nil, s.audit)
+		}
+		return false, nil
+	case desc.FullName() == "google.protobuf.Any":
+		// Contained resources are ContainedResources packed in Anys.
+		a := m.Interface().(*anypb.Any)
+		cr, err := a.UnmarshalNew()
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", path, err)
+		}
+		if res := elementpath.Unwrap(cr); res != nil {
+			if err := d.resource(res.ProtoReflect(), s, s.audit); err != nil {
+				return false, err
+			}
+		}
+		return false, a.MarshalFrom(cr)
+	case elementpath.IsChoice(desc):
+		set := m.WhichOneof(desc.Oneofs().Get(0))
+		if set == nil {
+			return false, nil
+		}
+		name := set.JSONName()
+		typed := path + strings.ToUpper(name[:1]) + name[1:]
+		return d.apply(m.Mutable(set).Message(), path, s, path, typed)
+	}
+	return d.apply(m, path, s, path)
+}
+
+// apply applies the first rule matching m, the element at path, whose
+// paths are given, or the rules to its children if there is none.
+func (d *Deidentifier) apply(m protoreflect.Message, path string, s *scope, paths ...string) (bool, error) {
+	i := d.match(m, paths)
+	if i < 0 {
+		return false, d.fields(m, path, s)
+	}
+	r := d.rules[i]
+	if r.Action != Keep {
+		s.audit.Applied = append(s.audit.Applied, Applied{Path: path, Rule: i, Action: r.Action})
+	}
+	var err error
+	switch r.Action {
+	case Keep:
+	case Redact:
+		return true, nil
+	case Hash:
+		err = d.hash(m)
+	case Generalize:
+		err = generalize(m, r.Precision)
+	case Jitter:
+		err = shiftDate(m, rand.Intn(2*r.Days+1)-r.Days)
+	case Pseudonymize:
+		err = d.pseudonymize(m, path, s)
+	case Shift:
+		err = shiftDate(m, d.patientShift(s.patient, r.Days))
+	case Scrub:
+		err = d.scrub(m, path, s)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	return false, nil
+}
+
+// match returns the index of the first rule matching m, whose paths are
+// given, or -1 if there is none.
+func (d *Deidentifier) match(m protoreflect.Message, paths []string) int {
+	typ := fhirpath.TypeName(m.Descriptor())
+	for i, r := range d.rules {
+		switch {
+		case r.Path != "":
+			for _, p := range paths {
+				if matchPath(r.Path, p) {
+					return i
+				}
+			}
+		case r.Type != "":
+			if r.Type == typ {
+				return i
+			}
+		case r.Extension != "":
+			if ext, ok := m.Interface().(*d4pb.Extension); ok && ext.GetUrl().GetValue() == r.Extension {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// matchPath reports whether the rule path pattern matches the element path.
+func matchPath(pattern, path string) bool {
+	if rest := strings.TrimPrefix(pattern, "*."); rest != pattern {
+		_, elems, ok := strings.Cut(path, ".")
+		return ok && elems == rest
+	}
+	return pattern == path
+}
+
+func (d *Deidentifier) hash(m protoreflect.Message) error {
+	if u, ok := m.Interface().(*d4pb.Uri); ok {
+		if u.GetValue() != "" {
+			u.Value = d.hashURL(u.GetValue())
+		}
+		return nil
+	}
+	desc := m.Descriptor()
+	if fd := desc.Fields().ByName("value"); fd != nil {
+		switch {
+		case fd.Kind() == protoreflect.StringKind:
+			if v := m.Get(fd).String(); v != "" {
+				m.Set(fd, protoreflect.ValueOfString(d.hashString(v)))
+			}
+			return nil
+		case fd.Message() != nil && m.Has(fd):
+			// Identifier.value.
+			return d.hash(m.Mutable(fd).Message())
+		case fd.Message() != nil:
+			return nil
+		}
+	}
+	if ref, ok := m.Interface().(*d4pb.Reference); ok {
+		ref.Display = nil
+		if id := ref.GetIdentifier(); id != nil {
+			// A logical reference names its target by an identifier such as
+			// an MRN, which is hashed like the id of a literal reference.
+			ref.Identifier = &d4pb.Identifier{System: id.GetSystem(), Value: id.GetValue()}
+			if err := d.hash(ref.Identifier.ProtoReflect()); err != nil {
+				return err
+			}
+		}
+		if uri := ref.GetUri(); uri != nil {
+			// Relative and absolute URLs keep their base and type, as the
+			// fullUrls of Bundle entries do.
+			uri.Value = d.hashURL(uri.GetValue())
+			return nil
+		}
+		rm := ref.ProtoReflect()
+		if set := rm.WhichOneof(rm.Descriptor().Oneofs().ByName("reference")); set != nil {
+			return d.hash(rm.Mutable(set).Message())
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot hash %s", fhirpath.TypeName(desc))
+}
+
+// resourceTypeRE matches the type of type-level URLs, as those creating or
+// searching for resources.
+var resourceTypeRE = regexp.MustCompile(`^[A-Z][A-Za-z]+$`)
+
+// hashURL hashes the id of the resource URL v, i.e. "Patient/1" or
+// "https://example.com/fhir/Patient/1/_history/2", keeping its base, type
+// and version, so that it names the resource by its hashed id. The search
+// parameters of conditional and search URLs, which may hold identifiers, are
+// hashed as a whole, as are values that are not resource URLs.
+func (d *Deidentifier) hashURL(v string) string {
+	path, query, search := strings.Cut(v, "?")
+	if p, err := fhirtypes.ParseReference(path); err == nil && p.Type != "" {
+		p.ID = d.hashString(p.ID)
+		path = p.String()
+	} else if !resourceTypeRE.MatchString(path[strings.LastIndex(path, "/")+1:]) {
+		return d.hashString(v)
+	}
+	if search {
+		path += "?" + d.hashString(query)
+	}
+	return path
+}
+
+func (d *Deidentifier) hashString(v string) string {
+	h := hmac.New(sha256.New, d.key)
+	h.Write([]byte(v))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// generalize truncates a date, dateTime or instant to precision.
+func generalize(m protoreflect.Message, precision string) error {
+	month := precision == "month"
+	truncate := func(us int64, tz string) (int64, error) {
+		loc, err := parseZone(tz)
+		if err != nil {
+			return 0, err
+		}
+		t := time.UnixMicro(us).In(loc)
+		mon := time.January
+		if month {
+			mon = t.Month()
+		}
+		return time.Date(t.Year(), mon, 1, 0, 0, 0, 0, loc).UnixMicro(), nil
+	}
+	var err error
+	switch v := m.Interface().(type) {
+	case *d4pb.Date:
+		if month && v.Precision == d4pb.Date_YEAR {
+			return nil
+		}
+		v.ValueUs, err = truncate(v.ValueUs, v.Timezone)
+		v.Precision = d4pb.Date_YEAR
+		if month {
+			v.Precision = d4pb.Date_MONTH
+		}
+	case *d4pb.DateTime:
+		if month && v.Precision == d4pb.DateTime_YEAR {
+			return nil
+		}
+		v.ValueUs, err = truncate(v.ValueUs, v.Timezone)
+		v.Precision = d4pb.DateTime_YEAR
+		if month {
+			v.Precision = d4pb.DateTime_MONTH
+		}
+	case *d4pb.Instant:
+		// Instants have no coarser precision than seconds; the value is
+		// truncated nonetheless.
+		v.ValueUs, err = truncate(v.ValueUs, v.Timezone)
+		v.Precision = d4pb.Instant_SECOND
+	default:
+		return fmt.Errorf("cannot generalize %s", fhirpath.TypeName(m.Descriptor()))
+	}
+	return err
+}
+
+// shiftDate moves a date, dateTime or instant by days.
+func shiftDate(m protoreflect.Message, days int) error {
+	shift := func(us int64, tz string) (int64, error) {
+		loc, err := parseZone(tz)
+		if err != nil {
+			return 0, err
+		}
+		return time.UnixMicro(us).In(loc).AddDate(0, 0, days).UnixMicro(), nil
+	}
+	var err error
+	switch v := m.Interface().(type) {
+	case *d4pb.Date:
+		v.ValueUs, err = shift(v.ValueUs, v.Timezone)
+	case *d4pb.DateTime:
+		v.ValueUs, err = shift(v.ValueUs, v.Timezone)
+	case *d4pb.Instant:
+		v.ValueUs, err = shift(v.ValueUs, v.Timezone)
+	default:
+		return fmt.Errorf("cannot shift %s", fhirpath.TypeName(m.Descriptor()))
+	}
+	return err
+}
+
+// parseZone parses "Z", a "+hh:mm" offset or an IANA time zone name.
+func parseZone(tz string) (*time.Location, error) {
+	if tz == "" || tz == "Z" || tz == "UTC" {
+		return time.UTC, nil
+	}
+	if tz[0] == '+' || tz[0] == '-' {
+		h, m, ok := strings.Cut(tz[1:], ":")
+		hours, err1 := strconv.Atoi(h)
+		mins, err2 := strconv.Atoi(m)
+		if !ok || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid time zone offset %q", tz)
+		}
+		offset := hours*3600 + mins*60
+		if tz[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(tz, offset), nil
+	}
+	return time.LoadLocation(tz)
+}
@@ -0,0 +1,334 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/organization_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+var key = []byte("test key")
+
+func hashed(v string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(v))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func date(t time.Time, p d4pb.Date_Precision) *d4pb.Date {
+	return &d4pb.Date{ValueUs: t.UnixMicro(), Timezone: "UTC", Precision: p}
+}
+
+const raceURL = "http://hl7.org/fhir/us/core/StructureDefinition/us-core-race"
+
+func patient(t *testing.T) *ppb.Patient {
+	t.Helper()
+	org, err := anypb.New(&r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Organization{Organization: &opb.Organization{
+		Id:   &d4pb.Id{Value: "org"},
+		Name: &d4pb.String{Value: "General Hospital"},
+		Telecom: []*d4pb.ContactPoint{{
+			Value: &d4pb.String{Value: "555-0100"},
+		}},
+	}}})
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+	return &ppb.Patient{
+		Id:        &d4pb.Id{Value: "p1"},
+		Contained: []*anypb.Any{org},
+		Extension: []*d4pb.Extension{{
+			Url:   &d4pb.Uri{Value: raceURL},
+			Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_StringValue{StringValue: &d4pb.String{Value: "x"}}},
+		}},
+		Identifier: []*d4pb.Identifier{{Value: &d4pb.String{Value: "MRN-1"}}},
+		Name:       []*d4pb.HumanName{{Family: &d4pb.String{Value: "Doe"}}},
+		Gender:     &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+		BirthDate:  date(time.Date(1970, 5, 17, 0, 0, 0, 0, time.UTC), d4pb.Date_DAY),
+		Address:    []*d4pb.Address{{City: &d4pb.String{Value: "Springfield"}}},
+		ManagingOrganization: &d4pb.Reference{
+			Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: "org"}},
+			Display:   &d4pb.String{Value: "General Hospital"},
+		},
+	}
+}
+
+func TestSafeHarbor(t *testing.T) {
+	d, err := New(SafeHarbor(), Options{Key: key})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	p := patient(t)
+	if err := d.Resource(p); err != nil {
+		t.Fatalf("Resource() returned unexpected error: %v", err)
+	}
+	org, err := anypb.New(&r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Organization{Organization: &opb.Organization{
+		Id:   &d4pb.Id{Value: hashed("org")},
+		Name: &d4pb.String{Value: "General Hospital"},
+	}}})
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+	want := &ppb.Patient{
+		Id:        &d4pb.Id{Value: hashed("p1")},
+		Contained: []*anypb.Any{org},
+		Extension: p.Extension,
+		Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+		BirthDate: date(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), d4pb.Date_YEAR),
+		ManagingOrganization: &d4pb.Reference{
+			Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: hashed("org")}},
+		},
+	}
+	if diff := cmp.Diff(want, p, protocmp.Transform()); diff != "" {
+		t.Errorf("Resource() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSafeHarbor_LogicalReference(t *testing.T) {
+	d, err := New(SafeHarbor(), Options{Key: key})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	o := &obspb.Observation{
+		Subject: &d4pb.Reference{
+			Type: &d4pb.Uri{Value: "Patient"},
+			Identifier: &d4pb.Identifier{
+				System:   &d4pb.Uri{Value: "urn:oid:1.2.36.146.595.217.0.1"},
+				Value:    &d4pb.String{Value: "MRN-1"},
+				Type:     &d4pb.CodeableConcept{Text: &d4pb.String{Value: "Medical record number"}},
+				Assigner: &d4pb.Reference{Display: &d4pb.String{Value: "General Hospital"}},
+			},
+			Display: &d4pb.String{Value: "Jane Doe"},
+		},
+	}
+	if err := d.Resource(o); err != nil {
+		t.Fatalf("Resource() returned unexpected error: %v", err)
+	}
+	want := &obspb.Observation{
+		Subject: &d4pb.Reference{
+			Type: &d4pb.Uri{Value: "Patient"},
+			Identifier: &d4pb.Identifier{
+				System: &d4pb.Uri{Value: "urn:oid:1.2.36.146.595.217.0.1"},
+				Value:  &d4pb.String{Value: hashed("MRN-1")},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, o, protocmp.Transform()); diff != "" {
+		t.Errorf("Resource() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSafeHarbor_Bundle(t *testing.T) {
+	d, err := New(SafeHarbor(), Options{Key: key})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	const urn = "urn:uuid:0f7c8a3e-5d1c-4b0e-9a7d-2f3f6b8e1c2a"
+	entry := func(fullURL string, res *r4pb.ContainedResource, method c4pb.HTTPVerbCode_Value, url string) *r4pb.Bundle_Entry {
+		e := &r4pb.Bundle_Entry{Resource: res, Request: &r4pb.Bundle_Entry_Request{
+			Method: &r4pb.Bundle_Entry_Request_MethodCode{Value: method},
+			Url:    &d4pb.Uri{Value: url},
+		}}
+		if fullURL != "" {
+			e.FullUrl = &d4pb.Uri{Value: fullURL}
+		}
+		return e
+	}
+	pat := func(id string) *r4pb.ContainedResource {
+		return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{Id: &d4pb.Id{Value: id}}}}
+	}
+	obs := func(subject string) *r4pb.ContainedResource {
+		return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: &obspb.Observation{
+			Subject: &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: subject}}},
+		}}}
+	}
+	b := &r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{
+		entry("https://example.com/fhir/Patient/p1", pat("p1"), c4pb.HTTPVerbCode_PUT, "Patient/p1"),
+		entry(urn, obs("https://example.com/fhir/Patient/p1"), c4pb.HTTPVerbCode_POST, "Observation"),
+		entry("", nil, c4pb.HTTPVerbCode_DELETE, "Patient?identifier=urn:oid:1.2.36.146.595.217.0.1|MRN-1"),
+	}}
+	if err := d.Resource(b); err != nil {
+		t.Fatalf("Resource() returned unexpected error: %v", err)
+	}
+	want := &r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{
+		entry("https://example.com/fhir/Patient/"+hashed("p1"), pat(hashed("p1")), c4pb.HTTPVerbCode_PUT, "Patient/"+hashed("p1")),
+		entry(hashed(urn), obs("https://example.com/fhir/Patient/"+hashed("p1")), c4pb.HTTPVerbCode_POST, "Observation"),
+		entry("", nil, c4pb.HTTPVerbCode_DELETE, "Patient?"+hashed("identifier=urn:oid:1.2.36.146.595.217.0.1|MRN-1")),
+	}}
+	if diff := cmp.Diff(want, b, protocmp.Transform()); diff != "" {
+		t.Errorf("Resource() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestResource_Rules(t *testing.T) {
+	observation := func() *obspb.Observation {
+		return &obspb.Observation{
+			Id:      &d4pb.Id{Value: "o1"},
+			Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+			Effective: &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: &d4pb.DateTime{
+				ValueUs: time.Date(2020, 3, 15, 10, 30, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND,
+			}}},
+			Issued: &d4pb.Instant{ValueUs: time.Date(2020, 3, 15, 11, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_SECOND},
+		}
+	}
+	tests := []struct {
+		name   string
+		rules  []Rule
+		modify func(o *obspb.Observation)
+	}{
+		{
+			name:  "typed choice path",
+			rules: []Rule{{Path: "Observation.effectiveDateTime", Action: Redact}},
+			modify: func(o *obspb.Observation) {
+				o.Effective = nil
+			},
+		},
+		{
+			name:  "generalize to month",
+			rules: []Rule{{Path: "Observation.effective", Action: Generalize, Precision: "month"}},
+			modify: func(o *obspb.Observation) {
+				dt := o.GetEffective().GetDateTime()
+				dt.ValueUs = time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC).UnixMicro()
+				dt.Precision = d4pb.DateTime_MONTH
+			},
+		},
+		{
+			name:  "hash reference",
+			rules: []Rule{{Type: "Reference", Action: Hash}},
+			modify: func(o *obspb.Observation) {
+				o.Subject.GetPatientId().Value = hashed("p1")
+			},
+		},
+		{
+			name:   "keep before redact",
+			rules:  []Rule{{Path: "*.issued", Action: Keep}, {Type: "instant", Action: Redact}},
+			modify: func(o *obspb.Observation) {},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, err := New(&Config{Rules: test.rules}, Options{Key: key})
+			if err != nil {
+				t.Fatalf("New() returned unexpected error: %v", err)
+			}
+			got := observation()
+			if err := d.Resource(got); err != nil {
+				t.Fatalf("Resource() returned unexpected error: %v", err)
+			}
+			want := observation()
+			test.modify(want)
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Resource() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResource_BundleAndExtensions(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+rules:
+- {extension: "` + raceURL + `", action: redact}
+- {path: Patient.birthDate, action: jitter, days: 10}
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig() returned unexpected error: %v", err)
+	}
+	d, err := New(cfg, Options{})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	p := patient(t)
+	b := &r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{{
+		Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: p}},
+	}}}
+	if err := d.Resource(b); err != nil {
+		t.Fatalf("Resource() returned unexpected error: %v", err)
+	}
+	if len(p.GetExtension()) != 0 {
+		t.Errorf("Resource() kept extension %s", raceURL)
+	}
+	shift := time.UnixMicro(p.GetBirthDate().GetValueUs()).Sub(time.Date(1970, 5, 17, 0, 0, 0, 0, time.UTC))
+	if shift < -10*24*time.Hour || shift > 10*24*time.Hour {
+		t.Errorf("Resource() shifted birthDate by %v, want at most 10 days", shift)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		opts Options
+	}{
+		{"no selector", Rule{Action: Redact}, Options{}},
+		{"two selectors", Rule{Path: "Patient.name", Type: "HumanName", Action: Redact}, Options{}},
+		{"unknown action", Rule{Type: "HumanName", Action: "scramble"}, Options{}},
+		{"hash without key", Rule{Type: "Identifier", Action: Hash}, Options{}},
+		{"jitter without days", Rule{Type: "date", Action: Jitter}, Options{}},
+		{"invalid precision", Rule{Type: "date", Action: Generalize, Precision: "week"}, Options{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := New(&Config{Rules: []Rule{test.rule}}, test.opts); err == nil {
+				t.Errorf("New() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestResource_InapplicableAction(t *testing.T) {
+	d, err := New(&Config{Rules: []Rule{{Type: "HumanName", Action: Generalize}}}, Options{})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := d.Resource(patient(t)); err == nil {
+		t.Errorf("Resource() succeeded, want error")
+	}
+}
+
+func TestResourceAudit(t *testing.T) {
+	rules := []Rule{
+		{Path: "Patient.gender", Action: Keep},
+		{Path: "Patient.name", Action: Redact},
+		{Type: "date", Action: Generalize, Precision: "year"},
+	}
+	d, err := New(&Config{Rules: rules}, Options{Key: key})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	got, err := d.ResourceAudit(patient(t))
+	if err != nil {
+		t.Fatalf("ResourceAudit() returned unexpected error: %v", err)
+	}
+	want := &Audit{Applied: []Applied{
+		{Path: "Patient.name", Rule: 1, Action: Redact},
+		{Path: "Patient.birthDate", Rule: 2, Action: Generalize},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ResourceAudit() diff (-want +got):\n%s", diff)
+	}
+}
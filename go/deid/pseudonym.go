@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// pseudonymEncoding encodes pseudonyms so that they are valid ids.
+var pseudonymEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// pseudonym returns the pseudonym of v: the first 160 bits of its keyed
+// HMAC-SHA256, in lowercase base32.
+func (d *Deidentifier) pseudonym(v string) string {
+	h := hmac.New(sha256.New, d.key)
+	h.Write([]byte(v))
+	return pseudonymEncoding.EncodeToString(h.Sum(nil)[:20])
+}
+
+// pseudonymize replaces the value of m, the element at path of the resource
+// of s, with its pseudonym. The pseudonyms of resource ids and references
+// are derived from the relative reference to the resource, so that a
+// resource and the references to it keep matching; ids of contained
+// resources and fragment references are derived from the fragment.
+// Identifier values are qualified by their system, and other string values
+// are used as they are.
+func (d *Deidentifier) pseudonymize(m protoreflect.Message, path string, s *scope) error {
+	switch v := m.Interface().(type) {
+	case *d4pb.Id:
+		if path != s.resourceType+".id" || v.GetValue() == "" {
+			break
+		}
+		if s.contained {
+			v.Value = d.pseudonym("#" + v.GetValue())
+		} else {
+			v.Value = d.pseudonym(s.resourceType + "/" + v.GetValue())
+		}
+		return nil
+	case *d4pb.Identifier:
+		if v.GetValue().GetValue() != "" {
+			v.Value.Value = d.pseudonym(v.GetSystem().GetValue() + "|" + v.GetValue().GetValue())
+		}
+		return nil
+	case *d4pb.Reference:
+		return d.pseudonymizeReference(v)
+	}
+	fd := m.Descriptor().Fields().ByName("value")
+	if fd == nil || fd.Kind() != protoreflect.StringKind {
+		return fmt.Errorf("cannot pseudonymize %s", fhirpath.TypeName(m.Descriptor()))
+	}
+	if v := m.Get(fd).String(); v != "" {
+		m.Set(fd, protoreflect.ValueOfString(d.pseudonym(v)))
+	}
+	return nil
+}
+
+// pseudonymizeReference replaces the id of the target of ref with the
+// pseudonym pseudonymize gives to the id of the target itself. The display
+// is removed. Absolute references are replaced by the pseudonym of the URL.
+func (d *Deidentifier) pseudonymizeReference(ref *d4pb.Reference) error {
+	ref.Display = nil
+	if f := ref.GetFragment(); f != nil {
+		f.Value = d.pseudonym("#" + f.GetValue())
+		return nil
+	}
+	den, err := jsonformat.NewDenormalizedReference(ref)
+	if err != nil {
+		return err
+	}
+	uri := den.(*d4pb.Reference).GetUri().GetValue()
+	if uri == "" {
+		// Logical references by identifier.
+		if id := ref.GetIdentifier(); id.GetValue().GetValue() != "" {
+			id.Value.Value = d.pseudonym(id.GetSystem().GetValue() + "|" + id.GetValue().GetValue())
+		}
+		return nil
+	}
+	rel := uri
+	if typ, id, ok := strings.Cut(uri, "/"); ok && !strings.Contains(id, "/") && typ != "" && id != "" {
+		rel = typ + "/" + d.pseudonym(uri)
+	} else {
+		rel = d.pseudonym(uri)
+	}
+	identifier := ref.Identifier
+	ref.Reset()
+	ref.Reference = &d4pb.Reference_Uri{Uri: &d4pb.String{Value: rel}}
+	ref.Identifier = identifier
+	if identifier.GetValue().GetValue() != "" {
+		identifier.Value.Value = d.pseudonym(identifier.GetSystem().GetValue() + "|" + identifier.GetValue().GetValue())
+	}
+	return jsonformat.NormalizeReference(ref)
+}
+
+// patientShift returns the shift of the dates of patient, a number of days
+// between -days and days derived from the key and the patient. Resources
+// without a patient share a shift derived from the key alone.
+func (d *Deidentifier) patientShift(patient string, days int) int {
+	h := hmac.New(sha256.New, d.key)
+	h.Write([]byte("shift|" + patient))
+	n := binary.BigEndian.Uint64(h.Sum(nil))
+	return int(n%uint64(2*days+1)) - days
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+)
+
+var pseudonymRules = &Config{Rules: []Rule{
+	{Path: "*.id", Action: Pseudonymize},
+	{Type: "Reference", Action: Pseudonymize},
+	{Type: "Identifier", Action: Pseudonymize},
+	{Type: "date", Action: Shift, Days: 30},
+	{Type: "dateTime", Action: Shift, Days: 30},
+}}
+
+var (
+	birthDate = time.Date(1970, 5, 17, 0, 0, 0, 0, time.UTC)
+	effective = time.Date(2020, 3, 15, 10, 30, 0, 0, time.UTC)
+)
+
+// bundles returns a patient and an observation about them, in separate
+// Bundles as if they came from separate files.
+func bundles(t *testing.T) (*r4pb.Bundle, *r4pb.Bundle) {
+	p := patient(t)
+	p.Identifier = []*d4pb.Identifier{
+		{System: &d4pb.Uri{Value: "urn:mrn"}, Value: &d4pb.String{Value: "1"}},
+		{System: &d4pb.Uri{Value: "urn:ssn"}, Value: &d4pb.String{Value: "1"}},
+	}
+	o := &obspb.Observation{
+		Id:      &d4pb.Id{Value: "o1"},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Effective: &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: &d4pb.DateTime{
+			ValueUs: effective.UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND,
+		}}},
+	}
+	return &r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: p}}}}},
+		&r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: o}}}}}
+}
+
+func deidentify(t *testing.T, k []byte, bs ...*r4pb.Bundle) {
+	t.Helper()
+	// Every bundle is de-identified by a new Deidentifier, as in separate
+	// runs.
+	for _, b := range bs {
+		d, err := New(pseudonymRules, Options{Key: k})
+		if err != nil {
+			t.Fatalf("New() returned unexpected error: %v", err)
+		}
+		if err := d.Resource(b); err != nil {
+			t.Fatalf("Resource() returned unexpected error: %v", err)
+		}
+	}
+}
+
+func TestPseudonymize(t *testing.T) {
+	pb, ob := bundles(t)
+	deidentify(t, key, pb, ob)
+	p := pb.GetEntry()[0].GetResource().GetPatient()
+	o := ob.GetEntry()[0].GetResource().GetObservation()
+
+	if p.GetId().GetValue() == "p1" {
+		t.Errorf("Resource() kept the patient id")
+	}
+	if got, want := o.GetSubject().GetPatientId().GetValue(), p.GetId().GetValue(); got != want {
+		t.Errorf("Resource() pseudonymized the subject to Patient/%s, want Patient/%s", got, want)
+	}
+	if a, b := p.GetIdentifier()[0].GetValue().GetValue(), p.GetIdentifier()[1].GetValue().GetValue(); a == b {
+		t.Errorf("Resource() gave the same pseudonym %s to identifiers of different systems", a)
+	}
+	contained, err := p.GetContained()[0].UnmarshalNew()
+	if err != nil {
+		t.Fatalf("UnmarshalNew() returned unexpected error: %v", err)
+	}
+	orgID := contained.(*r4pb.ContainedResource).GetOrganization().GetId().GetValue()
+	if got := p.GetManagingOrganization().GetFragment().GetValue(); got != orgID {
+		t.Errorf("Resource() pseudonymized the fragment reference to #%s, want #%s", got, orgID)
+	}
+
+	again, _ := bundles(t)
+	deidentify(t, key, again)
+	if diff := cmp.Diff(pb, again, protocmp.Transform()); diff != "" {
+		t.Errorf("Resource() is not deterministic, diff (-first +second):\n%s", diff)
+	}
+	other, _ := bundles(t)
+	deidentify(t, []byte("other tenant"), other)
+	if other.GetEntry()[0].GetResource().GetPatient().GetId().GetValue() == p.GetId().GetValue() {
+		t.Errorf("Resource() gave the same pseudonym with different keys")
+	}
+}
+
+func TestShift(t *testing.T) {
+	pb, ob := bundles(t)
+	deidentify(t, key, pb, ob)
+	p := pb.GetEntry()[0].GetResource().GetPatient()
+	o := ob.GetEntry()[0].GetResource().GetObservation()
+
+	birthShift := time.UnixMicro(p.GetBirthDate().GetValueUs()).Sub(birthDate)
+	effectiveShift := time.UnixMicro(o.GetEffective().GetDateTime().GetValueUs()).Sub(effective)
+	if birthShift != effectiveShift {
+		t.Errorf("Resource() shifted the birth date by %v and the observation by %v, want the same shift", birthShift, effectiveShift)
+	}
+	if birthShift < -30*24*time.Hour || birthShift > 30*24*time.Hour {
+		t.Errorf("Resource() shifted dates by %v, want at most 30 days", birthShift)
+	}
+}
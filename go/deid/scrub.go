@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deid
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/fhir/go/fhirpath"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// scrubMask replaces every byte of the scrubbed spans, which keeps the
+// length of the text and the offsets of what follows them.
+const scrubMask = '*'
+
+// Span is a range of text, the bytes from Start to End, exclusive.
+type Span struct {
+	Start, End int
+	// Label is what the span identifies, i.e. "NAME" or "PHONE", if the
+	// Scrubber knows.
+	Label string
+}
+
+// Scrubber finds the identifying spans of free text, by pattern matching,
+// named entity recognition or otherwise.
+type Scrubber interface {
+	Scrub(text string) ([]Span, error)
+}
+
+// Patterns is a Scrubber matching regular expressions, labeled by their
+// keys.
+type Patterns map[string]*regexp.Regexp
+
+// Scrub returns the matches of the patterns in text.
+func (p Patterns) Scrub(text string) ([]Span, error) {
+	var spans []Span
+	for label, re := range p {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if loc[1] > loc[0] {
+				spans = append(spans, Span{Start: loc[0], End: loc[1], Label: label})
+			}
+		}
+	}
+	return spans, nil
+}
+
+// Scrubbed is a span of free text that was scrubbed.
+type Scrubbed struct {
+	// Path is the element path of the text, i.e. "Observation.note.text".
+	// The text of Narratives is at their div.
+	Path string
+	Span
+}
+
+// scrub masks the spans the Scrubber finds in the free text of m, the
+// element at path, and records them in the audit of s. It applies to
+// primitives with a string value, the div of Narratives, whose markup is
+// kept, and the text of Annotations.
+func (d *Deidentifier) scrub(m protoreflect.Message, path string, s *scope) error {
+	switch v := m.Interface().(type) {
+	case *d4pb.Narrative:
+		if v.GetDiv() == nil {
+			return nil
+		}
+		return d.scrub(v.GetDiv().ProtoReflect(), path+".div", s)
+	case *d4pb.Annotation:
+		if v.GetText() == nil {
+			return nil
+		}
+		return d.scrub(v.GetText().ProtoReflect(), path+".text", s)
+	case *d4pb.Xhtml:
+		text, spans, err := d.scrubText(v.GetValue(), xhtmlSegments(v.GetValue()))
+		v.Value = text
+		s.record(path, spans)
+		return err
+	}
+	fd := m.Descriptor().Fields().ByName("value")
+	if fd == nil || fd.Kind() != protoreflect.StringKind {
+		return fmt.Errorf("cannot scrub %s", fhirpath.TypeName(m.Descriptor()))
+	}
+	v := m.Get(fd).String()
+	text, spans, err := d.scrubText(v, []Span{{Start: 0, End: len(v)}})
+	m.Set(fd, protoreflect.ValueOfString(text))
+	s.record(path, spans)
+	return err
+}
+
+// scrubText masks the spans the Scrubber finds in the segments of text,
+// which it scrubs separately, and returns the masked text and the spans,
+// sorted and merged where they overlap. Merged spans keep the first label.
+func (d *Deidentifier) scrubText(text string, segments []Span) (string, []Span, error) {
+	var spans []Span
+	for _, seg := range segments {
+		found, err := d.scrubber.Scrub(text[seg.Start:seg.End])
+		if err != nil {
+			return text, nil, err
+		}
+		for _, sp := range found {
+			sp.Start += seg.Start
+			sp.End += seg.Start
+			if sp.Start < seg.Start || sp.End > seg.End || sp.Start > sp.End {
+				return text, nil, fmt.Errorf("scrubbed span [%d, %d) is out of range", sp.Start-seg.Start, sp.End-seg.Start)
+			}
+			if sp.End == sp.Start {
+				continue
+			}
+			if !utf8.RuneStart(text[sp.Start]) || (sp.End < len(text) && !utf8.RuneStart(text[sp.End])) {
+				return text, nil, fmt.Errorf("scrubbed span [%d, %d) splits a character", sp.Start-seg.Start, sp.End-seg.Start)
+			}
+			spans = append(spans, sp)
+		}
+	}
+	if len(spans) == 0 {
+		return text, nil, nil
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	merged := spans[:1]
+	for _, sp := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if sp.Start > last.End {
+			merged = append(merged, sp)
+			continue
+		}
+		if sp.End > last.End {
+			last.End = sp.End
+		}
+		if last.Label == "" {
+			last.Label = sp.Label
+		}
+	}
+	b := []byte(text)
+	for _, sp := range merged {
+		for i := sp.Start; i < sp.End; i++ {
+			b[i] = scrubMask
+		}
+	}
+	return string(b), merged, nil
+}
+
+// xhtmlSegments returns the spans of character data of div, the text
+// between tags and character references, which is all that is scrubbed so
+// that the markup remains well-formed.
+func xhtmlSegments(div string) []Span {
+	var segs []Span
+	start := 0
+	for i := 0; i < len(div); i++ {
+		var end int
+		switch div[i] {
+		case '<':
+			end = strings.IndexByte(div[i:], '>')
+		case '&':
+			end = strings.IndexByte(div[i:], ';')
+		default:
+			continue
+		}
+		if end < 0 {
+			continue
+		}
+		if i > start {
+			segs = append(segs, Span{Start: start, End: i})
+		}
+		i += end
+		start = i + 1
+	}
+	if start < len(div) {
+		segs = append(segs, Span{Start: start, End: len(div)})
+	}
+	return segs
+}
+
+func (s *scope) record(path string, spans []Span) {
+	for _, sp := range spans {
+		s.audit.Scrubbed = append(s.audit.Scrubbed, Scrubbed{Path: path, Span: sp})
+	}
+}
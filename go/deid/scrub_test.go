@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deid
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+)
+
+var patterns = Patterns{
+	"NAME":  regexp.MustCompile(`John Doe|Doe`),
+	"PHONE": regexp.MustCompile(`555-\d{4}`),
+}
+
+func TestResourceReport_Scrub(t *testing.T) {
+	d, err := New(&Config{Rules: []Rule{
+		{Type: "Narrative", Action: Scrub},
+		{Type: "Annotation", Action: Scrub},
+		{Path: "Observation.valueString", Action: Scrub},
+	}}, Options{Scrubber: patterns})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	obs := &obspb.Observation{
+		Text: &d4pb.Narrative{Div: &d4pb.Xhtml{
+			Value: `<div xmlns="http://www.w3.org/1999/xhtml"><p title="Doe">John&#160;Doe, 555-0100</p></div>`,
+		}},
+		Note: []*d4pb.Annotation{{
+			Author: &d4pb.Annotation_AuthorX{Choice: &d4pb.Annotation_AuthorX_StringValue{StringValue: &d4pb.String{Value: "Dr Doe"}}},
+			Text:   &d4pb.Markdown{Value: "Called John Doe at 555-0199."},
+		}},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_StringValue{
+			StringValue: &d4pb.String{Value: "née John Doe"},
+		}},
+	}
+	report, err := d.ResourceReport(obs)
+	if err != nil {
+		t.Fatalf("ResourceReport() returned unexpected error: %v", err)
+	}
+	want := &obspb.Observation{
+		Text: &d4pb.Narrative{Div: &d4pb.Xhtml{
+			Value: `<div xmlns="http://www.w3.org/1999/xhtml"><p title="Doe">John&#160;***, ********</p></div>`,
+		}},
+		Note: []*d4pb.Annotation{{
+			// Scrub applies to the text of Annotations only.
+			Author: &d4pb.Annotation_AuthorX{Choice: &d4pb.Annotation_AuthorX_StringValue{StringValue: &d4pb.String{Value: "Dr Doe"}}},
+			Text:   &d4pb.Markdown{Value: "Called ******** at ********."},
+		}},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_StringValue{
+			StringValue: &d4pb.String{Value: "née ********"},
+		}},
+	}
+	if diff := cmp.Diff(want, obs, protocmp.Transform()); diff != "" {
+		t.Errorf("ResourceReport() diff (-want +got):\n%s", diff)
+	}
+	wantReport := []Scrubbed{
+		{Path: "Observation.text.div", Span: Span{Start: 67, End: 70, Label: "NAME"}},
+		{Path: "Observation.text.div", Span: Span{Start: 72, End: 80, Label: "PHONE"}},
+		{Path: "Observation.value", Span: Span{Start: 5, End: 13, Label: "NAME"}},
+		{Path: "Observation.note.text", Span: Span{Start: 7, End: 15, Label: "NAME"}},
+		{Path: "Observation.note.text", Span: Span{Start: 19, End: 27, Label: "PHONE"}},
+	}
+	if diff := cmp.Diff(wantReport, report); diff != "" {
+		t.Errorf("ResourceReport() report diff (-want +got):\n%s", diff)
+	}
+}
+
+type spans []Span
+
+func (s spans) Scrub(string) ([]Span, error) { return s, nil }
+
+func TestScrubText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		spans   spans
+		want    string
+		wantErr bool
+	}{
+		{"merged", "abcdefgh", spans{{Start: 4, End: 6}, {Start: 1, End: 3, Label: "A"}, {Start: 2, End: 5}}, "a*****gh", false},
+		{"multibyte", "né", spans{{Start: 1, End: 3}}, "n**", false},
+		{"empty", "abc", spans{{Start: 3, End: 3}}, "abc", false},
+		{"out of range", "abc", spans{{Start: 2, End: 4}}, "", true},
+		{"reversed", "abc", spans{{Start: 2, End: 1}}, "", true},
+		{"split character", "né", spans{{Start: 2, End: 3}}, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &Deidentifier{scrubber: test.spans}
+			got, _, err := d.scrubText(test.text, []Span{{Start: 0, End: len(test.text)}})
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("scrubText(%q) succeeded, want error", test.text)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scrubText(%q) returned unexpected error: %v", test.text, err)
+			}
+			if got != test.want {
+				t.Errorf("scrubText(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNew_ScrubRequiresScrubber(t *testing.T) {
+	if _, err := New(&Config{Rules: []Rule{{Type: "Narrative", Action: Scrub}}}, Options{}); err == nil {
+		t.Errorf("New() succeeded, want error")
+	}
+}
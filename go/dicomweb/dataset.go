@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dicomweb converts between DICOMweb metadata, as returned by QIDO-RS
+// and WADO-RS in the DICOM JSON model (PS3.18 Annex F), and R4 ImagingStudy
+// and Endpoint resources.
+package dicomweb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tags of the attributes mapped to and from ImagingStudy, as used for keys in
+// the DICOM JSON model.
+const (
+	TagSOPClassUID                    = "00080016"
+	TagSOPInstanceUID                 = "00080018"
+	TagStudyDate                      = "00080020"
+	TagSeriesDate                     = "00080021"
+	TagStudyTime                      = "00080030"
+	TagSeriesTime                     = "00080031"
+	TagAccessionNumber                = "00080050"
+	TagModality                       = "00080060"
+	TagModalitiesInStudy              = "00080061"
+	TagTimezoneOffsetFromUTC          = "00080201"
+	TagStudyDescription               = "00081030"
+	TagSeriesDescription              = "0008103E"
+	TagBodyPartExamined               = "00180015"
+	TagStudyInstanceUID               = "0020000D"
+	TagSeriesInstanceUID              = "0020000E"
+	TagSeriesNumber                   = "00200011"
+	TagInstanceNumber                 = "00200013"
+	TagNumberOfStudyRelatedSeries     = "00201206"
+	TagNumberOfStudyRelatedInstances  = "00201208"
+	TagNumberOfSeriesRelatedInstances = "00201209"
+	TagContentLabel                   = "00700080"
+)
+
+// Element is a DICOM attribute in the DICOM JSON model.
+type Element struct {
+	VR    string        `json:"vr"`
+	Value []interface{} `json:"Value,omitempty"`
+}
+
+// Dataset is a DICOM JSON object, mapping upper case hexadecimal tags to
+// attributes.
+type Dataset map[string]*Element
+
+// ParseJSON parses a DICOM JSON array, such as a QIDO-RS response or WADO-RS
+// metadata.
+func ParseJSON(in []byte) ([]Dataset, error) {
+	d := json.NewDecoder(bytes.NewReader(in))
+	d.UseNumber()
+	var out []Dataset
+	if err := d.Decode(&out); err != nil {
+		return nil, fmt.Errorf("parsing DICOM JSON: %w", err)
+	}
+	return out, nil
+}
+
+// Strings returns the values of the attribute as strings. Person names are
+// returned in their alphabetic representation.
+func (ds Dataset) Strings(tag string) []string {
+	e := ds[strings.ToUpper(tag)]
+	if e == nil {
+		return nil
+	}
+	var out []string
+	for _, v := range e.Value {
+		switch v := v.(type) {
+		case string:
+			out = append(out, v)
+		case json.Number:
+			out = append(out, v.String())
+		case float64:
+			out = append(out, strconv.FormatFloat(v, 'f', -1, 64))
+		case map[string]interface{}:
+			if s, ok := v["Alphabetic"].(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// String returns the first value of the attribute, or "" if absent.
+func (ds Dataset) String(tag string) string {
+	if s := ds.Strings(tag); len(s) > 0 {
+		return s[0]
+	}
+	return ""
+}
+
+// Int returns the first value of an integer attribute.
+func (ds Dataset) Int(tag string) (int, bool) {
+	s := strings.TrimSpace(ds.String(tag))
+	if s == "" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(s)
+	return i, err == nil
+}
+
+// SetStrings sets the attribute to the non-empty values given, or removes it
+// if there are none.
+func (ds Dataset) SetStrings(tag, vr string, values ...string) {
+	e := &Element{VR: vr}
+	for _, v := range values {
+		if v != "" {
+			e.Value = append(e.Value, v)
+		}
+	}
+	if len(e.Value) == 0 {
+		delete(ds, tag)
+		return
+	}
+	ds[tag] = e
+}
+
+// SetInt sets an integer attribute.
+func (ds Dataset) SetInt(tag, vr string, v int) {
+	ds[tag] = &Element{VR: vr, Value: []interface{}{json.Number(strconv.Itoa(v))}}
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicomweb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseJSON(t *testing.T) {
+	in := `[{
+		"00100010": {"vr": "PN", "Value": [{"Alphabetic": "Doe^Jane"}]},
+		"00080061": {"vr": "CS", "Value": ["CT", "PT"]},
+		"00201206": {"vr": "IS", "Value": [3]},
+		"00081030": {"vr": "LO"}
+	}]`
+	got, err := ParseJSON([]byte(in))
+	if err != nil {
+		t.Fatalf("ParseJSON() returned unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("ParseJSON() returned %d datasets, want 1", len(got))
+	}
+	ds := got[0]
+	if got, want := ds.String("00100010"), "Doe^Jane"; got != want {
+		t.Errorf("String(PatientName) = %q, want %q", got, want)
+	}
+	if diff := cmp.Diff([]string{"CT", "PT"}, ds.Strings(TagModalitiesInStudy)); diff != "" {
+		t.Errorf("Strings(ModalitiesInStudy) unexpected diff (-want +got):\n%s", diff)
+	}
+	if n, ok := ds.Int(TagNumberOfStudyRelatedSeries); !ok || n != 3 {
+		t.Errorf("Int(NumberOfStudyRelatedSeries) = %d, %v, want 3, true", n, ok)
+	}
+	if _, ok := ds.Int(TagStudyDescription); ok {
+		t.Errorf("Int() of an empty attribute succeeded, want false")
+	}
+}
+
+func TestParseJSON_Invalid(t *testing.T) {
+	if _, err := ParseJSON([]byte(`{"0020000D": {}}`)); err == nil {
+		t.Errorf("ParseJSON() of an object succeeded, want error")
+	}
+}
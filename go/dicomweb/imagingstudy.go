@@ -0,0 +1,436 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicomweb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	epb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/endpoint_go_proto"
+	ispb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/imaging_study_go_proto"
+)
+
+// Code systems used in the generated resources.
+const (
+	DICOMUIDSystem       = "urn:dicom:uid"
+	DCMSystem            = "http://dicom.nema.org/resources/ontology/DCM"
+	URISystem            = "urn:ietf:rfc:3986"
+	IdentifierTypeSystem = "http://terminology.hl7.org/CodeSystem/v2-0203"
+	ConnectionTypeSystem = "http://terminology.hl7.org/CodeSystem/endpoint-connection-type"
+	PayloadTypeSystem    = "http://terminology.hl7.org/CodeSystem/endpoint-payload-type"
+
+	oidPrefix = "urn:oid:"
+)
+
+// Options configure the conversion of DICOM metadata to ImagingStudy.
+type Options struct {
+	// Subject is the reference used as ImagingStudy.subject, which DICOM
+	// metadata cannot provide.
+	Subject *d4pb.Reference
+	// Endpoint, if set, is referenced from each ImagingStudy as the location
+	// the study can be retrieved from.
+	Endpoint *d4pb.Reference
+	// TimeZone is the location DICOM dates and times are interpreted in when
+	// the metadata has no TimezoneOffsetFromUTC attribute. Defaults to UTC.
+	TimeZone string
+}
+
+// ToImagingStudies converts DICOM JSON datasets into ImagingStudy resources,
+// one per distinct StudyInstanceUID in order of first appearance. Datasets
+// may be at the study, series or instance level and may be mixed; each
+// dataset contributes the series and instance it identifies, if any.
+func ToImagingStudies(datasets []Dataset, opts Options) ([]*ispb.ImagingStudy, error) {
+	defLoc, defTZ := time.UTC, "UTC"
+	if opts.TimeZone != "" {
+		loc, err := time.LoadLocation(opts.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", opts.TimeZone, err)
+		}
+		defLoc, defTZ = loc, opts.TimeZone
+	}
+
+	var studies []*ispb.ImagingStudy
+	byUID := map[string]*ispb.ImagingStudy{}
+	seriesByUID := map[string]*ispb.ImagingStudy_Series{}
+	for i, ds := range datasets {
+		uid := ds.String(TagStudyInstanceUID)
+		if uid == "" {
+			return nil, fmt.Errorf("dataset %d has no StudyInstanceUID", i)
+		}
+		loc, tz := defLoc, defTZ
+		if off := ds.String(TagTimezoneOffsetFromUTC); off != "" {
+			var err error
+			if loc, tz, err = parseOffset(off); err != nil {
+				return nil, fmt.Errorf("dataset %d: %w", i, err)
+			}
+		}
+		study, ok := byUID[uid]
+		if !ok {
+			study = &ispb.ImagingStudy{
+				Identifier: []*d4pb.Identifier{{
+					System: &d4pb.Uri{Value: DICOMUIDSystem},
+					Value:  &d4pb.String{Value: oidPrefix + uid},
+				}},
+				Status:  &ispb.ImagingStudy_StatusCode{Value: c4pb.ImagingStudyStatusCode_AVAILABLE},
+				Subject: opts.Subject,
+			}
+			if opts.Endpoint != nil {
+				study.Endpoint = []*d4pb.Reference{opts.Endpoint}
+			}
+			byUID[uid] = study
+			studies = append(studies, study)
+		}
+		if err := mergeStudy(study, ds, loc, tz); err != nil {
+			return nil, fmt.Errorf("dataset %d: %w", i, err)
+		}
+
+		seriesUID := ds.String(TagSeriesInstanceUID)
+		if seriesUID == "" {
+			continue
+		}
+		series, ok := seriesByUID[uid+"|"+seriesUID]
+		if !ok {
+			series = &ispb.ImagingStudy_Series{Uid: &d4pb.Id{Value: seriesUID}}
+			seriesByUID[uid+"|"+seriesUID] = series
+			study.Series = append(study.Series, series)
+		}
+		if err := mergeSeries(series, ds, loc, tz); err != nil {
+			return nil, fmt.Errorf("dataset %d: %w", i, err)
+		}
+
+		if sop := ds.String(TagSOPInstanceUID); sop != "" {
+			inst := &ispb.ImagingStudy_Series_Instance{
+				Uid:      &d4pb.Id{Value: sop},
+				SopClass: &d4pb.Coding{System: &d4pb.Uri{Value: URISystem}, Code: &d4pb.Code{Value: oidPrefix + ds.String(TagSOPClassUID)}},
+			}
+			if n, ok := ds.Int(TagInstanceNumber); ok {
+				inst.Number = &d4pb.UnsignedInt{Value: uint32(n)}
+			}
+			if t := ds.String(TagContentLabel); t != "" {
+				inst.Title = &d4pb.String{Value: t}
+			}
+			series.Instance = append(series.Instance, inst)
+		}
+	}
+	for _, s := range studies {
+		summarize(s)
+	}
+	return studies, nil
+}
+
+func mergeStudy(s *ispb.ImagingStudy, ds Dataset, loc *time.Location, tz string) error {
+	if acc := ds.String(TagAccessionNumber); acc != "" && accessionNumber(s) == "" {
+		s.Identifier = append(s.Identifier, &d4pb.Identifier{
+			Type: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+				System: &d4pb.Uri{Value: IdentifierTypeSystem},
+				Code:   &d4pb.Code{Value: "ACSN"},
+			}}},
+			Value: &d4pb.String{Value: acc},
+		})
+	}
+	if d := ds.String(TagStudyDescription); d != "" && s.Description == nil {
+		s.Description = &d4pb.String{Value: d}
+	}
+	if s.Started == nil {
+		dt, err := parseDateTime(ds.String(TagStudyDate), ds.String(TagStudyTime), loc, tz)
+		if err != nil {
+			return err
+		}
+		s.Started = dt
+	}
+	for _, m := range ds.Strings(TagModalitiesInStudy) {
+		addModality(s, m)
+	}
+	if n, ok := ds.Int(TagNumberOfStudyRelatedSeries); ok && s.NumberOfSeries == nil {
+		s.NumberOfSeries = &d4pb.UnsignedInt{Value: uint32(n)}
+	}
+	if n, ok := ds.Int(TagNumberOfStudyRelatedInstances); ok && s.NumberOfInstances == nil {
+		s.NumberOfInstances = &d4pb.UnsignedInt{Value: uint32(n)}
+	}
+	return nil
+}
+
+func mergeSeries(s *ispb.ImagingStudy_Series, ds Dataset, loc *time.Location, tz string) error {
+	if n, ok := ds.Int(TagSeriesNumber); ok && s.Number == nil {
+		s.Number = &d4pb.UnsignedInt{Value: uint32(n)}
+	}
+	if m := ds.String(TagModality); m != "" && s.Modality == nil {
+		s.Modality = modalityCoding(m)
+	}
+	if d := ds.String(TagSeriesDescription); d != "" && s.Description == nil {
+		s.Description = &d4pb.String{Value: d}
+	}
+	if b := ds.String(TagBodyPartExamined); b != "" && s.BodySite == nil {
+		s.BodySite = &d4pb.Coding{Display: &d4pb.String{Value: b}}
+	}
+	if n, ok := ds.Int(TagNumberOfSeriesRelatedInstances); ok && s.NumberOfInstances == nil {
+		s.NumberOfInstances = &d4pb.UnsignedInt{Value: uint32(n)}
+	}
+	if s.Started == nil {
+		dt, err := parseDateTime(ds.String(TagSeriesDate), ds.String(TagSeriesTime), loc, tz)
+		if err != nil {
+			return err
+		}
+		s.Started = dt
+	}
+	return nil
+}
+
+// summarize fills in the counts and modalities of a study from its series
+// and instances, where the metadata includes them.
+func summarize(s *ispb.ImagingStudy) {
+	total := 0
+	for _, series := range s.Series {
+		if n := len(series.Instance); n > 0 {
+			series.NumberOfInstances = &d4pb.UnsignedInt{Value: uint32(n)}
+		}
+		total += int(series.GetNumberOfInstances().GetValue())
+		if m := series.GetModality().GetCode().GetValue(); m != "" {
+			addModality(s, m)
+		}
+	}
+	if n := uint32(len(s.Series)); n > s.GetNumberOfSeries().GetValue() {
+		s.NumberOfSeries = &d4pb.UnsignedInt{Value: n}
+	}
+	if n := uint32(total); n > s.GetNumberOfInstances().GetValue() {
+		s.NumberOfInstances = &d4pb.UnsignedInt{Value: n}
+	}
+}
+
+func modalityCoding(m string) *d4pb.Coding {
+	return &d4pb.Coding{System: &d4pb.Uri{Value: DCMSystem}, Code: &d4pb.Code{Value: m}}
+}
+
+func addModality(s *ispb.ImagingStudy, m string) {
+	for _, c := range s.Modality {
+		if c.GetCode().GetValue() == m {
+			return
+		}
+	}
+	s.Modality = append(s.Modality, modalityCoding(m))
+}
+
+func accessionNumber(s *ispb.ImagingStudy) string {
+	for _, id := range s.GetIdentifier() {
+		for _, c := range id.GetType().GetCoding() {
+			if c.GetSystem().GetValue() == IdentifierTypeSystem && c.GetCode().GetValue() == "ACSN" {
+				return id.GetValue().GetValue()
+			}
+		}
+	}
+	return ""
+}
+
+func studyUID(s *ispb.ImagingStudy) string {
+	for _, id := range s.GetIdentifier() {
+		if id.GetSystem().GetValue() == DICOMUIDSystem {
+			return strings.TrimPrefix(id.GetValue().GetValue(), oidPrefix)
+		}
+	}
+	return ""
+}
+
+// FromImagingStudy converts an ImagingStudy into DICOM JSON datasets at the
+// most detailed level available: one per instance, one per series without
+// instances, or a single study level dataset if there are no series.
+func FromImagingStudy(s *ispb.ImagingStudy) ([]Dataset, error) {
+	uid := studyUID(s)
+	if uid == "" {
+		return nil, fmt.Errorf("ImagingStudy has no %s identifier", DICOMUIDSystem)
+	}
+	study := Dataset{}
+	study.SetStrings(TagStudyInstanceUID, "UI", uid)
+	study.SetStrings(TagAccessionNumber, "SH", accessionNumber(s))
+	study.SetStrings(TagStudyDescription, "LO", s.GetDescription().GetValue())
+	if err := setDateTime(study, TagStudyDate, TagStudyTime, s.GetStarted()); err != nil {
+		return nil, err
+	}
+	var modalities []string
+	for _, m := range s.GetModality() {
+		modalities = append(modalities, m.GetCode().GetValue())
+	}
+	study.SetStrings(TagModalitiesInStudy, "CS", modalities...)
+	if s.NumberOfSeries != nil {
+		study.SetInt(TagNumberOfStudyRelatedSeries, "IS", int(s.GetNumberOfSeries().GetValue()))
+	}
+	if s.NumberOfInstances != nil {
+		study.SetInt(TagNumberOfStudyRelatedInstances, "IS", int(s.GetNumberOfInstances().GetValue()))
+	}
+	if len(s.GetSeries()) == 0 {
+		return []Dataset{study}, nil
+	}
+
+	var out []Dataset
+	for _, series := range s.GetSeries() {
+		sds := study.clone()
+		sds.SetStrings(TagSeriesInstanceUID, "UI", series.GetUid().GetValue())
+		if series.Number != nil {
+			sds.SetInt(TagSeriesNumber, "IS", int(series.GetNumber().GetValue()))
+		}
+		sds.SetStrings(TagModality, "CS", series.GetModality().GetCode().GetValue())
+		sds.SetStrings(TagSeriesDescription, "LO", series.GetDescription().GetValue())
+		sds.SetStrings(TagBodyPartExamined, "CS", series.GetBodySite().GetDisplay().GetValue())
+		if series.NumberOfInstances != nil {
+			sds.SetInt(TagNumberOfSeriesRelatedInstances, "IS", int(series.GetNumberOfInstances().GetValue()))
+		}
+		if err := setDateTime(sds, TagSeriesDate, TagSeriesTime, series.GetStarted()); err != nil {
+			return nil, err
+		}
+		if len(series.GetInstance()) == 0 {
+			out = append(out, sds)
+			continue
+		}
+		for _, inst := range series.GetInstance() {
+			ids := sds.clone()
+			ids.SetStrings(TagSOPInstanceUID, "UI", inst.GetUid().GetValue())
+			ids.SetStrings(TagSOPClassUID, "UI", strings.TrimPrefix(inst.GetSopClass().GetCode().GetValue(), oidPrefix))
+			if inst.Number != nil {
+				ids.SetInt(TagInstanceNumber, "IS", int(inst.GetNumber().GetValue()))
+			}
+			ids.SetStrings(TagContentLabel, "CS", inst.GetTitle().GetValue())
+			out = append(out, ids)
+		}
+	}
+	return out, nil
+}
+
+func (ds Dataset) clone() Dataset {
+	out := make(Dataset, len(ds))
+	for k, v := range ds {
+		out[k] = v
+	}
+	return out
+}
+
+// NewEndpoint returns an active DICOMweb WADO-RS Endpoint with the given id
+// and service base URL, for ImagingStudy.endpoint to reference.
+func NewEndpoint(id, address string) *epb.Endpoint {
+	return &epb.Endpoint{
+		Id:     &d4pb.Id{Value: id},
+		Status: &epb.Endpoint_StatusCode{Value: c4pb.EndpointStatusCode_ACTIVE},
+		ConnectionType: &d4pb.Coding{
+			System: &d4pb.Uri{Value: ConnectionTypeSystem},
+			Code:   &d4pb.Code{Value: "dicom-wado-rs"},
+		},
+		PayloadType: []*d4pb.CodeableConcept{{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: PayloadTypeSystem},
+			Code:   &d4pb.Code{Value: "DICOM"},
+		}}}},
+		PayloadMimeType: []*epb.Endpoint_PayloadMimeTypeCode{{Value: "application/dicom"}},
+		Address:         &d4pb.Url{Value: address},
+	}
+}
+
+// parseDateTime combines a DICOM DA and TM value into a DateTime with the
+// precision of the input. It returns nil if the date is empty.
+func parseDateTime(da, tm string, loc *time.Location, tz string) (*d4pb.DateTime, error) {
+	if da == "" {
+		return nil, nil
+	}
+	if len(da) != 8 {
+		return nil, fmt.Errorf("invalid DICOM date %q", da)
+	}
+	tm = strings.ReplaceAll(tm, ":", "")
+	frac := ""
+	if i := strings.IndexByte(tm, '.'); i >= 0 {
+		tm, frac = tm[:i], tm[i+1:]
+	}
+	precision := d4pb.DateTime_DAY
+	if tm != "" {
+		precision = d4pb.DateTime_SECOND
+		tm = padRight(tm, 6)
+		switch {
+		case len(frac) > 3:
+			precision = d4pb.DateTime_MICROSECOND
+		case len(frac) > 0:
+			precision = d4pb.DateTime_MILLISECOND
+		}
+	}
+	layout, value := "20060102", da
+	if tm != "" {
+		layout, value = "20060102150405", da+tm
+	}
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DICOM date and time %q %q", da, tm)
+	}
+	if frac != "" {
+		us, err := strconv.Atoi(padRight(frac, 6)[:6])
+		if err != nil {
+			return nil, fmt.Errorf("invalid DICOM time fraction %q", frac)
+		}
+		t = t.Add(time.Duration(us) * time.Microsecond)
+	}
+	return &d4pb.DateTime{ValueUs: t.UnixMicro(), Timezone: tz, Precision: precision}, nil
+}
+
+func padRight(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return s + strings.Repeat("0", n-len(s))
+}
+
+// parseOffset parses a TimezoneOffsetFromUTC value such as "-0500".
+func parseOffset(off string) (*time.Location, string, error) {
+	t, err := time.Parse("-0700", off)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid TimezoneOffsetFromUTC %q", off)
+	}
+	tz := off[:3] + ":" + off[3:]
+	return t.Location(), tz, nil
+}
+
+// setDateTime sets DA and TM attributes, and the timezone offset if the
+// DateTime has a fixed offset, from dt.
+func setDateTime(ds Dataset, daTag, tmTag string, dt *d4pb.DateTime) error {
+	if dt == nil {
+		return nil
+	}
+	tz := dt.GetTimezone()
+	loc := time.UTC
+	switch {
+	case tz == "" || tz == "Z" || tz == "UTC":
+	case strings.HasPrefix(tz, "+") || strings.HasPrefix(tz, "-"):
+		t, err := time.Parse("-07:00", tz)
+		if err != nil {
+			return fmt.Errorf("invalid time zone %q", tz)
+		}
+		loc = t.Location()
+		ds.SetStrings(TagTimezoneOffsetFromUTC, "SH", strings.ReplaceAll(tz, ":", ""))
+	default:
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return fmt.Errorf("invalid time zone %q: %w", tz, err)
+		}
+		loc = l
+	}
+	t := time.UnixMicro(dt.GetValueUs()).In(loc)
+	ds.SetStrings(daTag, "DA", t.Format("20060102"))
+	switch dt.GetPrecision() {
+	case d4pb.DateTime_YEAR, d4pb.DateTime_MONTH, d4pb.DateTime_DAY:
+	case d4pb.DateTime_MILLISECOND:
+		ds.SetStrings(tmTag, "TM", t.Format("150405.000"))
+	case d4pb.DateTime_MICROSECOND:
+		ds.SetStrings(tmTag, "TM", t.Format("150405.000000"))
+	default:
+		ds.SetStrings(tmTag, "TM", t.Format("150405"))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dicomweb
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ispb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/imaging_study_go_proto"
+)
+
+const instanceMetadata = `[
+  {
+    "0020000D": {"vr": "UI", "Value": ["1.2.3"]},
+    "00080050": {"vr": "SH", "Value": ["ACC1"]},
+    "00081030": {"vr": "LO", "Value": ["CT chest"]},
+    "00080020": {"vr": "DA", "Value": ["20200102"]},
+    "00080030": {"vr": "TM", "Value": ["101112.5"]},
+    "00080201": {"vr": "SH", "Value": ["-0500"]},
+    "0020000E": {"vr": "UI", "Value": ["1.2.3.1"]},
+    "00200011": {"vr": "IS", "Value": [1]},
+    "00080060": {"vr": "CS", "Value": ["CT"]},
+    "00180015": {"vr": "CS", "Value": ["CHEST"]},
+    "00080018": {"vr": "UI", "Value": ["1.2.3.1.1"]},
+    "00080016": {"vr": "UI", "Value": ["1.2.840.10008.5.1.4.1.1.2"]},
+    "00200013": {"vr": "IS", "Value": [1]}
+  },
+  {
+    "0020000D": {"vr": "UI", "Value": ["1.2.3"]},
+    "0020000E": {"vr": "UI", "Value": ["1.2.3.1"]},
+    "00080018": {"vr": "UI", "Value": ["1.2.3.1.2"]},
+    "00080016": {"vr": "UI", "Value": ["1.2.840.10008.5.1.4.1.1.2"]},
+    "00200013": {"vr": "IS", "Value": [2]}
+  },
+  {
+    "0020000D": {"vr": "UI", "Value": ["1.2.3"]},
+    "0020000E": {"vr": "UI", "Value": ["1.2.3.2"]},
+    "00080060": {"vr": "CS", "Value": ["SR"]},
+    "00201209": {"vr": "IS", "Value": [4]}
+  }
+]`
+
+func wantStudy() *ispb.ImagingStudy {
+	sopClass := &d4pb.Coding{System: &d4pb.Uri{Value: URISystem}, Code: &d4pb.Code{Value: "urn:oid:1.2.840.10008.5.1.4.1.1.2"}}
+	return &ispb.ImagingStudy{
+		Identifier: []*d4pb.Identifier{
+			{System: &d4pb.Uri{Value: DICOMUIDSystem}, Value: &d4pb.String{Value: "urn:oid:1.2.3"}},
+			{
+				Type: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+					System: &d4pb.Uri{Value: IdentifierTypeSystem},
+					Code:   &d4pb.Code{Value: "ACSN"},
+				}}},
+				Value: &d4pb.String{Value: "ACC1"},
+			},
+		},
+		Status:  &ispb.ImagingStudy_StatusCode{Value: c4pb.ImagingStudyStatusCode_AVAILABLE},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Endpoint: []*d4pb.Reference{{
+			Reference: &d4pb.Reference_EndpointId{EndpointId: &d4pb.ReferenceId{Value: "pacs"}},
+		}},
+		Description: &d4pb.String{Value: "CT chest"},
+		// 2020-01-02T10:11:12.5-05:00
+		Started: &d4pb.DateTime{
+			ValueUs:   1577977872500000,
+			Timezone:  "-05:00",
+			Precision: d4pb.DateTime_MILLISECOND,
+		},
+		Modality:          []*d4pb.Coding{modalityCoding("CT"), modalityCoding("SR")},
+		NumberOfSeries:    &d4pb.UnsignedInt{Value: 2},
+		NumberOfInstances: &d4pb.UnsignedInt{Value: 6},
+		Series: []*ispb.ImagingStudy_Series{
+			{
+				Uid:               &d4pb.Id{Value: "1.2.3.1"},
+				Number:            &d4pb.UnsignedInt{Value: 1},
+				Modality:          modalityCoding("CT"),
+				BodySite:          &d4pb.Coding{Display: &d4pb.String{Value: "CHEST"}},
+				NumberOfInstances: &d4pb.UnsignedInt{Value: 2},
+				Instance: []*ispb.ImagingStudy_Series_Instance{
+					{Uid: &d4pb.Id{Value: "1.2.3.1.1"}, SopClass: sopClass, Number: &d4pb.UnsignedInt{Value: 1}},
+					{Uid: &d4pb.Id{Value: "1.2.3.1.2"}, SopClass: sopClass, Number: &d4pb.UnsignedInt{Value: 2}},
+				},
+			},
+			{
+				Uid:               &d4pb.Id{Value: "1.2.3.2"},
+				Modality:          modalityCoding("SR"),
+				NumberOfInstances: &d4pb.UnsignedInt{Value: 4},
+			},
+		},
+	}
+}
+
+func TestToImagingStudies(t *testing.T) {
+	datasets, err := ParseJSON([]byte(instanceMetadata))
+	if err != nil {
+		t.Fatalf("ParseJSON() returned unexpected error: %v", err)
+	}
+	opts := Options{
+		Subject:  &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Endpoint: &d4pb.Reference{Reference: &d4pb.Reference_EndpointId{EndpointId: &d4pb.ReferenceId{Value: "pacs"}}},
+	}
+	got, err := ToImagingStudies(datasets, opts)
+	if err != nil {
+		t.Fatalf("ToImagingStudies() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]*ispb.ImagingStudy{wantStudy()}, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ToImagingStudies() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestToImagingStudies_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		ds   Dataset
+		opts Options
+	}{
+		{"no study uid", Dataset{TagSeriesInstanceUID: {VR: "UI", Value: []interface{}{"1"}}}, Options{}},
+		{"bad date", Dataset{TagStudyInstanceUID: {VR: "UI", Value: []interface{}{"1"}}, TagStudyDate: {VR: "DA", Value: []interface{}{"2020"}}}, Options{}},
+		{"bad offset", Dataset{TagStudyInstanceUID: {VR: "UI", Value: []interface{}{"1"}}, TagTimezoneOffsetFromUTC: {VR: "SH", Value: []interface{}{"EST"}}}, Options{}},
+		{"bad time zone", Dataset{TagStudyInstanceUID: {VR: "UI", Value: []interface{}{"1"}}}, Options{TimeZone: "Nowhere/Special"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ToImagingStudies([]Dataset{test.ds}, test.opts); err == nil {
+				t.Errorf("ToImagingStudies() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestFromImagingStudy_RoundTrip(t *testing.T) {
+	datasets, err := FromImagingStudy(wantStudy())
+	if err != nil {
+		t.Fatalf("FromImagingStudy() returned unexpected error: %v", err)
+	}
+	// Two instances of the first series and the second series without
+	// instances.
+	if len(datasets) != 3 {
+		t.Fatalf("FromImagingStudy() returned %d datasets, want 3", len(datasets))
+	}
+	if got, want := datasets[0].String(TagStudyTime), "101112.500"; got != want {
+		t.Errorf("StudyTime = %q, want %q", got, want)
+	}
+	if got, want := datasets[0].String(TagTimezoneOffsetFromUTC), "-0500"; got != want {
+		t.Errorf("TimezoneOffsetFromUTC = %q, want %q", got, want)
+	}
+	want := wantStudy()
+	got, err := ToImagingStudies(datasets, Options{Subject: want.Subject, Endpoint: want.Endpoint[0]})
+	if err != nil {
+		t.Fatalf("ToImagingStudies() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]*ispb.ImagingStudy{want}, got, protocmp.Transform()); diff != "" {
+		t.Errorf("round trip returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestFromImagingStudy_NoUID(t *testing.T) {
+	if _, err := FromImagingStudy(&ispb.ImagingStudy{}); err == nil {
+		t.Errorf("FromImagingStudy() succeeded, want error")
+	}
+}
+
+func TestNewEndpoint(t *testing.T) {
+	e := NewEndpoint("pacs", "https://pacs.example.com/dicomweb")
+	if got, want := e.GetConnectionType().GetCode().GetValue(), "dicom-wado-rs"; got != want {
+		t.Errorf("NewEndpoint() connection type = %q, want %q", got, want)
+	}
+	if got, want := e.GetAddress().GetValue(), "https://pacs.example.com/dicomweb"; got != want {
+		t.Errorf("NewEndpoint() address = %q, want %q", got, want)
+	}
+}
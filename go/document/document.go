@@ -0,0 +1,221 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package document implements the Composition $document operation, which
+// assembles an R4 document Bundle from a Composition and the resources it
+// refers to, validates the rules of document Bundles, and signs and verifies
+// them. SectionBuilder builds the sections of Compositions to the profile
+// of a type of document, such as the International Patient Summary.
+package document
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/uuid"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/composition_go_proto"
+)
+
+// compositionReferences selects the references of a Composition whose
+// targets belong in its document.
+var compositionReferences = fhirpath.MustCompile(
+	"(subject | encounter | author | attester.party | custodian | repeat(section).select(author | focus | entry)).reference")
+
+// chains selects, by resource type, the references of included resources
+// that are followed too, so that the document identifies the people and
+// organizations behind roles.
+var chains = map[string]*fhirpath.Expression{
+	"PractitionerRole": fhirpath.MustCompile("(practitioner | organization).reference"),
+}
+
+// Options configures Generate.
+type Options struct {
+	// Resolver returns the resources referred to by the Composition. It is
+	// required.
+	Resolver fhirpath.Resolver
+	// BaseURL is the base of the fullUrls of the entries, which are of the
+	// form BaseURL/Type/id. It is required.
+	BaseURL string
+	// NewID returns the UUID identifying the document. A random version 4
+	// UUID is used if it is nil.
+	NewID func() string
+	// Now is the timestamp of the document; it defaults to the current time.
+	Now time.Time
+}
+
+// Generate returns the document Bundle for comp: the Composition followed by
+// the resources it refers to from its subject, encounter, authors,
+// attesters, custodian and sections, including nested sections. Every
+// reference must resolve; references to contained resources are left alone.
+func Generate(comp *cpb.Composition, opts Options) (*r4pb.Bundle, error) {
+	if opts.Resolver == nil {
+		return nil, fmt.Errorf("$document requires a resolver")
+	}
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("$document requires a base URL")
+	}
+	if comp.GetId().GetValue() == "" {
+		return nil, fmt.Errorf("Composition has no id")
+	}
+	if opts.NewID == nil {
+		opts.NewID = uuid.New
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	g := &generator{opts: opts, seen: map[string]bool{"Composition/" + comp.GetId().GetValue(): true}}
+	if err := g.follow(comp, compositionReferences); err != nil {
+		return nil, err
+	}
+	b := &r4pb.Bundle{
+		Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_DOCUMENT},
+		Identifier: &d4pb.Identifier{
+			System: &d4pb.Uri{Value: "urn:ietf:rfc:3986"},
+			Value:  &d4pb.String{Value: "urn:uuid:" + opts.NewID()},
+		},
+		Timestamp: &d4pb.Instant{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_SECOND},
+	}
+	for _, res := range append([]proto.Message{comp}, g.resources...) {
+		entry := &r4pb.Bundle_Entry{FullUrl: &d4pb.Uri{Value: g.fullURL(res)}}
+		if err := elementpath.Set(entry.ProtoReflect(), "resource", res); err != nil {
+			return nil, err
+		}
+		b.Entry = append(b.Entry, entry)
+	}
+	if err := Validate(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+type generator struct {
+	opts      Options
+	resources []proto.Message
+	// seen holds the relative references of the resources in the document.
+	seen map[string]bool
+}
+
+// follow adds the resources referred to by the references expr selects from
+// res, and those they lead to.
+func (g *generator) follow(res proto.Message, expr *fhirpath.Expression) error {
+	refs, err := expr.Evaluate(fhirpath.Collection{res})
+	if err != nil {
+		return err
+	}
+	for _, r := range refs {
+		ref, _ := r.(string)
+		if ref == "" || strings.HasPrefix(ref, "#") {
+			continue
+		}
+		target, err := g.opts.Resolver(ref)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", ref, err)
+		}
+		if target == nil {
+			return fmt.Errorf("unresolved reference %s", ref)
+		}
+		target = elementpath.Unwrap(target)
+		key := relativeReference(target)
+		if key == "" {
+			return fmt.Errorf("%s resolved to a resource without an id", ref)
+		}
+		if g.seen[key] {
+			continue
+		}
+		g.seen[key] = true
+		g.resources = append(g.resources, target)
+		if chain, ok := chains[elementpath.ResourceType(target)]; ok {
+			if err := g.follow(target, chain); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) fullURL(res proto.Message) string {
+	return strings.TrimSuffix(g.opts.BaseURL, "/") + "/" + relativeReference(res)
+}
+
+// relativeReference returns "Type/id" for res, or an empty string if it has
+// no id.
+func relativeReference(res proto.Message) string {
+	id := elementpath.ID(res)
+	if id == "" {
+		return ""
+	}
+	return elementpath.ResourceType(res) + "/" + id
+}
+
+// Validate checks the rules of the FHIR specification for document Bundles:
+// the Bundle has an identifier and timestamp, its first entry is a
+// Composition, every entry has a unique fullUrl and no search, request or
+// response, and the references of the Composition resolve within the
+// Bundle.
+func Validate(b *r4pb.Bundle) error {
+	if b.GetType().GetValue() != c4pb.BundleTypeCode_DOCUMENT {
+		return fmt.Errorf("bundle is not a document")
+	}
+	if b.GetIdentifier().GetSystem().GetValue() == "" || b.GetIdentifier().GetValue().GetValue() == "" {
+		return fmt.Errorf("document has no identifier with a system and value (bdl-9)")
+	}
+	if b.GetTimestamp() == nil {
+		return fmt.Errorf("document has no timestamp (bdl-10)")
+	}
+	if len(b.GetEntry()) == 0 || b.GetEntry()[0].GetResource().GetComposition() == nil {
+		return fmt.Errorf("first entry of the document is not a Composition (bdl-11)")
+	}
+	fullURLs := map[string]bool{}
+	for i, e := range b.GetEntry() {
+		u := e.GetFullUrl().GetValue()
+		switch {
+		case u == "":
+			return fmt.Errorf("entry %d has no fullUrl", i)
+		case fullURLs[u]:
+			return fmt.Errorf("entry %d repeats fullUrl %s (bdl-7)", i, u)
+		case e.GetSearch() != nil:
+			return fmt.Errorf("entry %d has a search (bdl-2)", i)
+		case e.GetRequest() != nil:
+			return fmt.Errorf("entry %d has a request (bdl-3)", i)
+		case e.GetResponse() != nil:
+			return fmt.Errorf("entry %d has a response (bdl-4)", i)
+		}
+		fullURLs[u] = true
+	}
+	// Relative references resolve against the base of the Composition's
+	// fullUrl.
+	comp := b.GetEntry()[0].GetResource().GetComposition()
+	base := strings.TrimSuffix(b.GetEntry()[0].GetFullUrl().GetValue(), "Composition/"+comp.GetId().GetValue())
+	refs, err := compositionReferences.Evaluate(fhirpath.Collection{comp})
+	if err != nil {
+		return err
+	}
+	for _, r := range refs {
+		ref, _ := r.(string)
+		if ref == "" || strings.HasPrefix(ref, "#") || fullURLs[ref] || fullURLs[base+ref] {
+			continue
+		}
+		return fmt.Errorf("Composition reference %s does not resolve within the document", ref)
+	}
+	return nil
+}
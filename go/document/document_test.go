@@ -0,0 +1,179 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package document
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/composition_go_proto"
+	condpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/organization_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	prpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/practitioner_go_proto"
+	prrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/practitioner_role_go_proto"
+)
+
+const baseURL = "http://example.com/fhir"
+
+var now = time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func id(v string) *d4pb.Id { return &d4pb.Id{Value: v} }
+
+func resources() map[string]proto.Message {
+	return map[string]proto.Message{
+		"Patient/p1": &ppb.Patient{Id: id("p1")},
+		"PractitionerRole/pr1": &prrpb.PractitionerRole{
+			Id:           id("pr1"),
+			Practitioner: &d4pb.Reference{Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: "dr1"}}},
+			Organization: &d4pb.Reference{Reference: &d4pb.Reference_OrganizationId{OrganizationId: &d4pb.ReferenceId{Value: "org1"}}},
+		},
+		"Practitioner/dr1":  &prpb.Practitioner{Id: id("dr1")},
+		"Organization/org1": &opb.Organization{Id: id("org1")},
+		"Condition/c1":      &condpb.Condition{Id: id("c1")},
+		"Observation/o1":    &obspb.Observation{Id: id("o1")},
+	}
+}
+
+func resolver(res map[string]proto.Message) func(string) (proto.Message, error) {
+	return func(ref string) (proto.Message, error) { return res[ref], nil }
+}
+
+func composition() *cpb.Composition {
+	ref := func(s string) *d4pb.Reference {
+		return &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: s}}}
+	}
+	return &cpb.Composition{
+		Id:      id("doc1"),
+		Subject: ref("Patient/p1"),
+		Author:  []*d4pb.Reference{ref("PractitionerRole/pr1")},
+		Attester: []*cpb.Composition_Attester{{
+			Mode:  &cpb.Composition_Attester_ModeCode{Value: c4pb.CompositionAttestationModeCode_LEGAL},
+			Party: ref("Practitioner/dr1"),
+		}},
+		Custodian: ref("Organization/org1"),
+		Section: []*cpb.Composition_Section{{
+			Entry: []*d4pb.Reference{ref("Condition/c1"), {Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: "note"}}}},
+			Section: []*cpb.Composition_Section{{
+				Entry: []*d4pb.Reference{ref("Observation/o1")},
+			}},
+		}},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	got, err := Generate(composition(), Options{
+		Resolver: resolver(resources()),
+		BaseURL:  baseURL + "/",
+		NewID:    func() string { return "0d5c9d3e-6b1f-4a6e-9f43-4e0f4b3c2a11" },
+		Now:      now,
+	})
+	if err != nil {
+		t.Fatalf("Generate() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&r4pb.Bundle{
+		Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_DOCUMENT},
+		Identifier: &d4pb.Identifier{
+			System: &d4pb.Uri{Value: "urn:ietf:rfc:3986"},
+			Value:  &d4pb.String{Value: "urn:uuid:0d5c9d3e-6b1f-4a6e-9f43-4e0f4b3c2a11"},
+		},
+		Timestamp: &d4pb.Instant{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_SECOND},
+	}, got, protocmp.Transform(), protocmp.IgnoreFields(&r4pb.Bundle{}, "entry")); diff != "" {
+		t.Errorf("Generate() diff (-want +got):\n%s", diff)
+	}
+	var fullURLs []string
+	for _, e := range got.GetEntry() {
+		fullURLs = append(fullURLs, e.GetFullUrl().GetValue())
+	}
+	want := []string{
+		baseURL + "/Composition/doc1",
+		baseURL + "/Patient/p1",
+		baseURL + "/PractitionerRole/pr1",
+		baseURL + "/Practitioner/dr1",
+		baseURL + "/Organization/org1",
+		baseURL + "/Condition/c1",
+		baseURL + "/Observation/o1",
+	}
+	if diff := cmp.Diff(want, fullURLs); diff != "" {
+		t.Errorf("Generate() entries diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	missing := resources()
+	delete(missing, "Observation/o1")
+	tests := []struct {
+		name string
+		comp *cpb.Composition
+		opts Options
+		want string
+	}{
+		{"no resolver", composition(), Options{BaseURL: baseURL}, "resolver"},
+		{"no base URL", composition(), Options{Resolver: resolver(resources())}, "base URL"},
+		{"no id", &cpb.Composition{}, Options{Resolver: resolver(resources()), BaseURL: baseURL}, "no id"},
+		{"unresolved entry", composition(), Options{Resolver: resolver(missing), BaseURL: baseURL}, "unresolved reference Observation/o1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Generate(test.comp, test.opts); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Generate() returned error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *r4pb.Bundle {
+		b, err := Generate(composition(), Options{Resolver: resolver(resources()), BaseURL: baseURL, Now: now})
+		if err != nil {
+			t.Fatalf("Generate() returned unexpected error: %v", err)
+		}
+		return b
+	}
+	if err := Validate(valid()); err != nil {
+		t.Errorf("Validate() of a generated document returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		name   string
+		modify func(b *r4pb.Bundle)
+		want   string
+	}{
+		{"not a document", func(b *r4pb.Bundle) { b.Type.Value = c4pb.BundleTypeCode_COLLECTION }, "not a document"},
+		{"no identifier", func(b *r4pb.Bundle) { b.Identifier = nil }, "bdl-9"},
+		{"no timestamp", func(b *r4pb.Bundle) { b.Timestamp = nil }, "bdl-10"},
+		{"composition not first", func(b *r4pb.Bundle) { b.Entry[0], b.Entry[1] = b.Entry[1], b.Entry[0] }, "bdl-11"},
+		{"repeated fullUrl", func(b *r4pb.Bundle) { b.Entry[2].FullUrl = b.Entry[1].FullUrl }, "bdl-7"},
+		{"request", func(b *r4pb.Bundle) { b.Entry[1].Request = &r4pb.Bundle_Entry_Request{} }, "bdl-3"},
+		{"dangling reference", func(b *r4pb.Bundle) { b.Entry = b.Entry[:len(b.Entry)-1] }, "Observation/o1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := valid()
+			test.modify(b)
+			if err := Validate(b); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Validate() returned error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package document
+
+import (
+	"html"
+	"sort"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/composition_go_proto"
+)
+
+const (
+	// LOINC is the system of the codes of sections.
+	LOINC = "http://loinc.org"
+	// EmptyReasons is the code system of the reasons sections are empty.
+	EmptyReasons = "http://terminology.hl7.org/CodeSystem/list-empty-reason"
+)
+
+// SectionSpec is a section of a document profile.
+type SectionSpec struct {
+	// Code is the LOINC code of the section, i.e. "11450-4".
+	Code string
+	// Title is the title of the section, i.e. "Problem List".
+	Title string
+	// Required sections are kept without entries, with an empty reason;
+	// other sections without entries are dropped.
+	Required bool
+}
+
+// Profile is the sections of a type of document, in the order documents of
+// the type list them.
+type Profile []SectionSpec
+
+// IPS is the profile of the International Patient Summary, whose medication,
+// allergy and problem sections are required.
+var IPS = Profile{
+	{Code: "10160-0", Title: "Medication Summary", Required: true},
+	{Code: "48765-2", Title: "Allergies and Intolerances", Required: true},
+	{Code: "11450-4", Title: "Problem List", Required: true},
+	{Code: "11369-6", Title: "History of Immunizations"},
+	{Code: "47519-4", Title: "History of Procedures"},
+	{Code: "46264-8", Title: "Medical Devices"},
+	{Code: "30954-2", Title: "Diagnostic Results"},
+	{Code: "8716-3", Title: "Vital Signs"},
+	{Code: "11348-0", Title: "History of Past Illness"},
+	{Code: "10162-6", Title: "History of Pregnancy"},
+	{Code: "29762-2", Title: "Social History"},
+	{Code: "47420-5", Title: "Functional Status"},
+	{Code: "18776-5", Title: "Plan of Care"},
+	{Code: "42348-3", Title: "Advance Directives"},
+}
+
+func (p Profile) spec(code string) (SectionSpec, int, bool) {
+	for i, s := range p {
+		if s.Code == code {
+			return s, i, true
+		}
+	}
+	return SectionSpec{}, 0, false
+}
+
+// SectionBuilder builds the sections of a Composition, which it identifies
+// by their LOINC codes.
+type SectionBuilder struct {
+	profile  Profile
+	sections []*cpb.Composition_Section
+	// reasons are the empty reasons set explicitly, by section code.
+	reasons map[string]*d4pb.CodeableConcept
+}
+
+// NewSectionBuilder returns a SectionBuilder of documents of profile, which
+// may be nil, starting from a copy of the sections of comp, if it is not
+// nil.
+func NewSectionBuilder(comp *cpb.Composition, profile Profile) *SectionBuilder {
+	b := &SectionBuilder{profile: profile, reasons: map[string]*d4pb.CodeableConcept{}}
+	for _, s := range comp.GetSection() {
+		b.sections = append(b.sections, proto.Clone(s).(*cpb.Composition_Section))
+	}
+	return b
+}
+
+// sectionCode returns the LOINC code of s, or "".
+func sectionCode(s *cpb.Composition_Section) string {
+	for _, c := range s.GetCode().GetCoding() {
+		if c.GetSystem().GetValue() == LOINC {
+			return c.GetCode().GetValue()
+		}
+	}
+	return ""
+}
+
+// Section returns the section with the LOINC code, adding it, titled as in
+// the profile, if there is none.
+func (b *SectionBuilder) Section(code string) *cpb.Composition_Section {
+	for _, s := range b.sections {
+		if sectionCode(s) == code {
+			return s
+		}
+	}
+	s := &cpb.Composition_Section{Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+		System: &d4pb.Uri{Value: LOINC},
+		Code:   &d4pb.Code{Value: code},
+	}}}}
+	if spec, _, ok := b.profile.spec(code); ok {
+		s.Title = &d4pb.String{Value: spec.Title}
+		s.Code.Coding[0].Display = &d4pb.String{Value: spec.Title}
+		s.Code.Text = &d4pb.String{Value: spec.Title}
+	}
+	b.sections = append(b.sections, s)
+	return s
+}
+
+// Add adds refs to the entries of the section with the LOINC code, skipping
+// those that refer to the same resource as an entry, as by
+// fhirtypes.SameReference. It returns the number of entries added.
+func (b *SectionBuilder) Add(code string, refs ...*d4pb.Reference) int {
+	s := b.Section(code)
+	added := 0
+next:
+	for _, ref := range refs {
+		for _, e := range s.Entry {
+			if fhirtypes.SameReference(e, ref, "") {
+				continue next
+			}
+		}
+		s.Entry = append(s.Entry, ref)
+		added++
+	}
+	return added
+}
+
+// SetEmptyReason sets the reason the section with the LOINC code is empty to
+// the code of EmptyReasons, i.e. "notasked", which Build records if the
+// section has no entries.
+func (b *SectionBuilder) SetEmptyReason(code, reason string) {
+	b.Section(code)
+	b.reasons[code] = emptyReason(reason)
+}
+
+func emptyReason(reason string) *d4pb.CodeableConcept {
+	display := emptyReasonDisplays[reason]
+	cc := &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+		System: &d4pb.Uri{Value: EmptyReasons},
+		Code:   &d4pb.Code{Value: reason},
+	}}}
+	if display != "" {
+		cc.Coding[0].Display = &d4pb.String{Value: display}
+		cc.Text = &d4pb.String{Value: display}
+	}
+	return cc
+}
+
+var emptyReasonDisplays = map[string]string{
+	"nilknown":    "Nil Known",
+	"notasked":    "Not Asked",
+	"withheld":    "Information Withheld",
+	"unavailable": "Unavailable",
+	"notstarted":  "Not Started",
+	"closed":      "Closed",
+}
+
+// Build returns the sections, adding the missing sections the profile
+// requires: those of the profile first, in its order, and then the others,
+// in the order they were added. Sections with entries lose
+// their empty reason, as a section cannot have both. Sections without
+// entries or subsections are dropped unless they are required by the
+// profile or have an empty reason, set by SetEmptyReason or already in the
+// section; required sections without one get "unavailable". Empty sections
+// without text get a generated narrative stating their empty reason.
+func (b *SectionBuilder) Build() []*cpb.Composition_Section {
+	type ordered struct {
+		s     *cpb.Composition_Section
+		order int
+	}
+	for _, spec := range b.profile {
+		if spec.Required {
+			b.Section(spec.Code)
+		}
+	}
+	var out []ordered
+	for i, s := range b.sections {
+		s = proto.Clone(s).(*cpb.Composition_Section)
+		code := sectionCode(s)
+		spec, pos, inProfile := b.profile.spec(code)
+		order := len(b.profile) + i
+		if inProfile {
+			order = pos
+		}
+		if len(s.Entry) > 0 || len(s.Section) > 0 {
+			s.EmptyReason = nil
+		} else {
+			if r, ok := b.reasons[code]; ok {
+				s.EmptyReason = r
+			}
+			if s.EmptyReason == nil {
+				if !spec.Required {
+					continue
+				}
+				s.EmptyReason = emptyReason("unavailable")
+			}
+			if s.Text == nil {
+				s.Text = emptyNarrative(s.EmptyReason)
+			}
+		}
+		out = append(out, ordered{s, order})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].order < out[j].order })
+	sections := make([]*cpb.Composition_Section, len(out))
+	for i, o := range out {
+		sections[i] = o.s
+	}
+	return sections
+}
+
+// Apply replaces the sections of comp with those Build returns.
+func (b *SectionBuilder) Apply(comp *cpb.Composition) {
+	comp.Section = b.Build()
+}
+
+// emptyNarrative returns the narrative of a section that is empty for
+// reason.
+func emptyNarrative(reason *d4pb.CodeableConcept) *d4pb.Narrative {
+	text := reason.GetText().GetValue()
+	if text == "" {
+		text = "No information"
+	}
+	return &d4pb.Narrative{
+		Status: &d4pb.Narrative_StatusCode{Value: c4pb.NarrativeStatusCode_GENERATED},
+		Div:    &d4pb.Xhtml{Value: `<div xmlns="http://www.w3.org/1999/xhtml">` + html.EscapeString(text) + `</div>`},
+	}
+}
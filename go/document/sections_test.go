@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package document
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/composition_go_proto"
+)
+
+func uriReference(s string) *d4pb.Reference {
+	return &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: s}}}
+}
+
+// summary returns the codes of sections, with their entries, empty reasons
+// and narratives.
+func summary(sections []*cpb.Composition_Section) []string {
+	var out []string
+	for _, s := range sections {
+		line := sectionCode(s) + " " + s.GetTitle().GetValue() + ":"
+		for _, e := range s.GetEntry() {
+			line += " " + e.GetUri().GetValue() + e.GetConditionId().GetValue()
+		}
+		if r := s.GetEmptyReason(); r != nil {
+			line += " empty=" + r.GetCoding()[0].GetCode().GetValue()
+		}
+		if s.GetText() != nil && len(s.GetEntry()) == 0 {
+			line += " text=" + s.GetText().GetDiv().GetValue()
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func TestSectionBuilder(t *testing.T) {
+	comp := &cpb.Composition{Section: []*cpb.Composition_Section{{
+		Title: &d4pb.String{Value: "Notes"},
+		Code:  &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: LOINC}, Code: &d4pb.Code{Value: "34109-9"}}}},
+		Text:  &d4pb.Narrative{Div: &d4pb.Xhtml{Value: "<div>notes</div>"}},
+		Entry: []*d4pb.Reference{uriReference("DocumentReference/d1")},
+	}, {
+		Title:       &d4pb.String{Value: "Problems"},
+		Code:        &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: LOINC}, Code: &d4pb.Code{Value: "11450-4"}}}},
+		EmptyReason: emptyReason("nilknown"),
+	}}}
+	b := NewSectionBuilder(comp, IPS)
+	if n := b.Add("11450-4", uriReference("Condition/c1"), uriReference("Condition/c1"),
+		&d4pb.Reference{Reference: &d4pb.Reference_ConditionId{ConditionId: &d4pb.ReferenceId{Value: "c1"}}}); n != 1 {
+		t.Errorf("Add() added %d entries, want 1", n)
+	}
+	b.Add("8716-3", uriReference("Observation/o1"))
+	b.Section("11369-6")
+	b.SetEmptyReason("48765-2", "notasked")
+
+	got := summary(b.Build())
+	want := []string{
+		`10160-0 Medication Summary: empty=unavailable text=<div xmlns="http://www.w3.org/1999/xhtml">Unavailable</div>`,
+		`48765-2 Allergies and Intolerances: empty=notasked text=<div xmlns="http://www.w3.org/1999/xhtml">Not Asked</div>`,
+		`11450-4 Problems: Condition/c1`,
+		`8716-3 Vital Signs: Observation/o1`,
+		`34109-9 Notes: DocumentReference/d1`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Build() diff (-want +got):\n%s", diff)
+	}
+	if len(comp.Section[1].Entry) != 0 {
+		t.Errorf("NewSectionBuilder() modified the sections of the Composition")
+	}
+
+	b.Apply(comp)
+	if len(comp.Section) != len(want) {
+		t.Errorf("Apply() left %d sections, want %d", len(comp.Section), len(want))
+	}
+}
+
+func TestSectionBuilder_NoProfile(t *testing.T) {
+	b := NewSectionBuilder(nil, nil)
+	b.Add("b", uriReference("Observation/o2"))
+	b.Section("empty")
+	b.SetEmptyReason("reason", "withheld")
+	b.Add("a", uriReference("Observation/o1"))
+	want := []string{
+		"b : Observation/o2",
+		`reason : empty=withheld text=<div xmlns="http://www.w3.org/1999/xhtml">Information Withheld</div>`,
+		"a : Observation/o1",
+	}
+	if diff := cmp.Diff(want, summary(b.Build())); diff != "" {
+		t.Errorf("Build() diff (-want +got):\n%s", diff)
+	}
+}
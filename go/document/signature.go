@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package document
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jose"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+const (
+	// signatureTypeSystem is the system of the ASTM E1762 signature types.
+	signatureTypeSystem = "urn:iso-astm:E1762-95:2013"
+	// authorSignature is the ASTM E1762 type of the signature of the
+	// author of a document.
+	authorSignature   = "1.2.840.10065.1.12.1.1"
+	josePayloadFormat = "application/jose"
+	fhirJSONFormat    = "application/fhir+json"
+)
+
+var marshaller *jsonformat.Marshaller
+
+func init() {
+	var err error
+	if marshaller, err = jsonformat.NewMarshaller(false, "", "", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("document: creating marshaller: %v", err))
+	}
+}
+
+// Canonical returns the canonical JSON of the document Bundle b, which its
+// signature is computed over: its FHIR JSON without Bundle.id, Bundle.meta
+// and Bundle.signature, with no whitespace and with object members in
+// lexicographic order, as http://hl7.org/fhir/canonicalization/json#document
+// specifies.
+func Canonical(b *r4pb.Bundle) ([]byte, error) {
+	b = proto.Clone(b).(*r4pb.Bundle)
+	b.Id, b.Meta, b.Signature = nil, nil, nil
+	data, err := marshaller.MarshalResource(b)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	// encoding/json writes the members of maps sorted by key.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// SignOptions configures Sign.
+type SignOptions struct {
+	// Key is the private key of the signer. It is required.
+	Key crypto.Signer
+	// KeyID identifies Key, i.e. in the JWK Set of the signer.
+	KeyID string
+	// Certificates is the certificate chain of Key, leaf first, which is
+	// included in the signature for verifiers that resolve keys through
+	// trusted roots.
+	Certificates []*x509.Certificate
+	// Who is the signer. It is required.
+	Who *d4pb.Reference
+	// OnBehalfOf is the party the signer represents, if any.
+	OnBehalfOf *d4pb.Reference
+	// Type is the reason for the signature; it defaults to the ASTM E1762
+	// author's signature.
+	Type []*d4pb.Coding
+	// Now is the time of the signature; it defaults to the current time.
+	Now time.Time
+}
+
+// Sign signs the document Bundle b and sets its signature: a detached JWS
+// over the canonical form of b.
+func Sign(b *r4pb.Bundle, opts SignOptions) error {
+	if b.GetType().GetValue() != c4pb.BundleTypeCode_DOCUMENT {
+		return fmt.Errorf("Bundle is not a document")
+	}
+	if opts.Key == nil {
+		return fmt.Errorf("signing requires a key")
+	}
+	if opts.Who == nil {
+		return fmt.Errorf("signing requires a signer")
+	}
+	canonical, err := Canonical(b)
+	if err != nil {
+		return fmt.Errorf("canonicalizing: %w", err)
+	}
+	h := jose.Header{Kid: opts.KeyID}
+	for _, c := range opts.Certificates {
+		h.X5c = append(h.X5c, base64.StdEncoding.EncodeToString(c.Raw))
+	}
+	jws, err := jose.SignDetached(h, canonical, opts.Key)
+	if err != nil {
+		return err
+	}
+	typ := opts.Type
+	if len(typ) == 0 {
+		typ = []*d4pb.Coding{{
+			System:  &d4pb.Uri{Value: signatureTypeSystem},
+			Code:    &d4pb.Code{Value: authorSignature},
+			Display: &d4pb.String{Value: "Author's Signature"},
+		}}
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	b.Signature = &d4pb.Signature{
+		Type:         typ,
+		When:         &d4pb.Instant{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_SECOND},
+		Who:          opts.Who,
+		OnBehalfOf:   opts.OnBehalfOf,
+		TargetFormat: &d4pb.Signature_TargetFormatCode{Value: fhirJSONFormat},
+		SigFormat:    &d4pb.Signature_SigFormatCode{Value: josePayloadFormat},
+		Data:         &d4pb.Base64Binary{Value: []byte(jws)},
+	}
+	return nil
+}
+
+// VerifyOptions configures Verify. At least one of Roots and Keys must be
+// set.
+type VerifyOptions struct {
+	// Roots are the trusted root certificates. Signatures carrying a
+	// certificate chain are verified with the key of its leaf, if the chain
+	// leads to one of Roots.
+	Roots *x509.CertPool
+	// Keys are the keys of trusted signers, i.e. fetched with
+	// jose.FetchKeySet. Signatures without a certificate chain, or all
+	// signatures if Roots is nil, are verified with the key of Keys their
+	// key id names.
+	Keys *jose.KeySet
+	// Now is the time certificate chains are verified at; it defaults to
+	// the current time. Bundle.signature.when is not covered by the
+	// signature, so it is never trusted for this.
+	Now time.Time
+}
+
+// Verify verifies the signature of the document Bundle b.
+func Verify(b *r4pb.Bundle, opts VerifyOptions) error {
+	sig := b.GetSignature()
+	if sig == nil {
+		return fmt.Errorf("Bundle is not signed")
+	}
+	if f := sig.GetSigFormat().GetValue(); f != josePayloadFormat {
+		return fmt.Errorf("unsupported signature format %q", f)
+	}
+	if opts.Roots == nil && opts.Keys == nil {
+		return fmt.Errorf("verifying requires trusted roots or keys")
+	}
+	canonical, err := Canonical(b)
+	if err != nil {
+		return fmt.Errorf("canonicalizing: %w", err)
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	key := func(h jose.Header) (crypto.PublicKey, error) {
+		if len(h.X5c) > 0 && opts.Roots != nil {
+			return chainKey(h.X5c, opts.Roots, now)
+		}
+		if opts.Keys == nil {
+			return nil, fmt.Errorf("signature has no certificate chain and no keys are trusted")
+		}
+		k, ok := opts.Keys.Key(h.Kid)
+		if !ok {
+			return nil, fmt.Errorf("no trusted key with id %q", h.Kid)
+		}
+		return k.PublicKey()
+	}
+	if _, _, err := jose.Verify(string(sig.GetData().GetValue()), canonical, key); err != nil {
+		return fmt.Errorf("verifying signature: %w", err)
+	}
+	return nil
+}
+
+// chainKey returns the key of the leaf of the certificate chain x5c, after
+// verifying the chain against roots at time t.
+func chainKey(x5c []string, roots *x509.CertPool, t time.Time) (crypto.PublicKey, error) {
+	var certs []*x509.Certificate
+	for i, s := range x5c {
+		der, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", i, err)
+		}
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %d: %w", i, err)
+		}
+		certs = append(certs, c)
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   t,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, fmt.Errorf("untrusted certificate: %w", err)
+	}
+	return certs[0].PublicKey, nil
+}
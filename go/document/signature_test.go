@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package document
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/jose"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+func document(t *testing.T) *r4pb.Bundle {
+	t.Helper()
+	b, err := Generate(composition(), Options{
+		Resolver: resolver(resources()),
+		BaseURL:  baseURL,
+		NewID:    func() string { return "0d5c9d3e-6b1f-4a6e-9f43-4e0f4b3c2a11" },
+		Now:      now,
+	})
+	if err != nil {
+		t.Fatalf("Generate() returned unexpected error: %v", err)
+	}
+	return b
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() returned unexpected error: %v", err)
+	}
+	return k
+}
+
+// certificate returns a certificate for key, signed by parent and its key,
+// or self-signed if parent is nil.
+func certificate(t *testing.T, key *ecdsa.PrivateKey, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signer"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		tmpl.Subject.CommonName = "root"
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate() returned unexpected error: %v", err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("x509.ParseCertificate() returned unexpected error: %v", err)
+	}
+	return c
+}
+
+var signer = &d4pb.Reference{Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: "dr1"}}}
+
+func TestSignVerify_KeySet(t *testing.T) {
+	key := newKey(t)
+	jwk, err := jose.NewJWK(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("jose.NewJWK() returned unexpected error: %v", err)
+	}
+	b := document(t)
+	if err := Sign(b, SignOptions{Key: key, KeyID: jwk.Kid, Who: signer, Now: now}); err != nil {
+		t.Fatalf("Sign() returned unexpected error: %v", err)
+	}
+	if got := b.GetSignature().GetSigFormat().GetValue(); got != "application/jose" {
+		t.Errorf("Sign() set sigFormat %q, want application/jose", got)
+	}
+	if parts := strings.Split(string(b.GetSignature().GetData().GetValue()), "."); len(parts) != 3 || parts[1] != "" {
+		t.Errorf("Sign() set data %q, want a detached JWS", b.GetSignature().GetData().GetValue())
+	}
+	keys := &jose.KeySet{Keys: []jose.JWK{jwk}}
+	if err := Verify(b, VerifyOptions{Keys: keys}); err != nil {
+		t.Errorf("Verify() returned unexpected error: %v", err)
+	}
+
+	// Bundle.id and Bundle.meta are outside the signature.
+	b.Id = &d4pb.Id{Value: "stored"}
+	b.Meta = &d4pb.Meta{VersionId: &d4pb.Id{Value: "2"}}
+	if err := Verify(b, VerifyOptions{Keys: keys}); err != nil {
+		t.Errorf("Verify() after setting id and meta returned unexpected error: %v", err)
+	}
+
+	b.GetEntry()[1].GetResource().GetPatient().Active = &d4pb.Boolean{Value: true}
+	if err := Verify(b, VerifyOptions{Keys: keys}); err == nil {
+		t.Errorf("Verify() of a modified document succeeded, want error")
+	}
+
+	other, err := jose.NewJWK(&newKey(t).PublicKey)
+	if err != nil {
+		t.Fatalf("jose.NewJWK() returned unexpected error: %v", err)
+	}
+	if err := Verify(document(t), VerifyOptions{Keys: &jose.KeySet{Keys: []jose.JWK{other}}}); err == nil {
+		t.Errorf("Verify() of an unsigned document succeeded, want error")
+	}
+}
+
+func TestSignVerify_Certificates(t *testing.T) {
+	rootKey, key := newKey(t), newKey(t)
+	root := certificate(t, rootKey, nil, nil)
+	leaf := certificate(t, key, root, rootKey)
+	b := document(t)
+	if err := Sign(b, SignOptions{Key: key, Certificates: []*x509.Certificate{leaf}, Who: signer, Now: now}); err != nil {
+		t.Fatalf("Sign() returned unexpected error: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	if err := Verify(b, VerifyOptions{Roots: roots, Now: now}); err != nil {
+		t.Errorf("Verify() returned unexpected error: %v", err)
+	}
+
+	untrusted := x509.NewCertPool()
+	untrusted.AddCert(certificate(t, newKey(t), nil, nil))
+	if err := Verify(b, VerifyOptions{Roots: untrusted, Now: now}); err == nil {
+		t.Errorf("Verify() with an untrusted chain succeeded, want error")
+	}
+}
+
+func TestVerify_ExpiredCertificate(t *testing.T) {
+	rootKey, key := newKey(t), newKey(t)
+	root := certificate(t, rootKey, nil, nil)
+	leaf := certificate(t, key, root, rootKey)
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	b := document(t)
+	if err := Sign(b, SignOptions{Key: key, Certificates: []*x509.Certificate{leaf}, Who: signer, Now: now}); err != nil {
+		t.Fatalf("Sign() returned unexpected error: %v", err)
+	}
+	later := now.Add(2 * time.Hour)
+	if err := Verify(b, VerifyOptions{Roots: roots, Now: later}); err == nil {
+		t.Errorf("Verify() after the leaf expired succeeded, want error")
+	}
+
+	// Backdating Bundle.signature.when, which the signature does not cover,
+	// must not make the expired leaf valid again.
+	b.GetSignature().When = &d4pb.Instant{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_SECOND}
+	if err := Verify(b, VerifyOptions{Roots: roots, Now: later}); err == nil {
+		t.Errorf("Verify() with a backdated signature time succeeded, want error")
+	}
+	if err := Verify(b, VerifyOptions{Roots: roots}); err == nil {
+		t.Errorf("Verify() at the current time of a leaf expired in 2025 succeeded, want error")
+	}
+}
+
+func TestSign_Errors(t *testing.T) {
+	key := newKey(t)
+	tests := []struct {
+		name string
+		b    *r4pb.Bundle
+		opts SignOptions
+	}{
+		{"not a document", &r4pb.Bundle{}, SignOptions{Key: key, Who: signer}},
+		{"no key", document(t), SignOptions{Who: signer}},
+		{"no signer", document(t), SignOptions{Key: key}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Sign(test.b, test.opts); err == nil {
+				t.Errorf("Sign() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	b := document(t)
+	got, err := Canonical(b)
+	if err != nil {
+		t.Fatalf("Canonical() returned unexpected error: %v", err)
+	}
+	if want := `{"entry":[{"fullUrl":"` + baseURL + `/Composition/doc1",`; !strings.HasPrefix(string(got), want) {
+		t.Errorf("Canonical() = %s, want prefix %s", got, want)
+	}
+	if strings.ContainsAny(string(got), " \n") {
+		t.Errorf("Canonical() = %s, want no whitespace", got)
+	}
+}
@@ -0,0 +1,395 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encounters groups the R4 resources of a patient by the Encounters
+// and EpisodesOfCare they belong to, as analytics and summaries of visits
+// need.
+//
+// A resource belongs to the Encounter it refers to through its encounter or
+// context element, and an Encounter to the EpisodesOfCare of its
+// episodeOfCare element. Resources and Encounters without such references
+// are grouped by time instead: a resource belongs to the Encounter whose
+// period contains its clinically relevant time, such as the effective time
+// of an Observation or the onset of a Condition, and an Encounter to the
+// EpisodeOfCare whose period contains its start. Encounters without an end
+// last until the end of the day they start, and EpisodesOfCare without an
+// end are ongoing.
+package encounters
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	encpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/encounter_go_proto"
+	eocpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/episode_of_care_go_proto"
+)
+
+var (
+	// encounterReferences selects the references of resources to the
+	// Encounters they happen in.
+	encounterReferences = fhirpath.MustCompile("encounter | context | context.encounter")
+	// episodeReferences selects the references of resources to their
+	// EpisodesOfCare.
+	episodeReferences = fhirpath.MustCompile("episodeOfCare | context")
+	// times select the clinically relevant time of resources, in order of
+	// preference.
+	times = []*fhirpath.Expression{
+		fhirpath.MustCompile("effective"),
+		fhirpath.MustCompile("occurrence"),
+		fhirpath.MustCompile("performed"),
+		fhirpath.MustCompile("onset"),
+		fhirpath.MustCompile("authoredOn"),
+		fhirpath.MustCompile("recordedDate"),
+		fhirpath.MustCompile("issued"),
+		fhirpath.MustCompile("period"),
+		fhirpath.MustCompile("date"),
+		fhirpath.MustCompile("created"),
+		fhirpath.MustCompile("recorded"),
+	}
+)
+
+// Options configures Group.
+type Options struct {
+	// ReferencesOnly disables grouping by time, leaving the resources and
+	// Encounters without references ungrouped.
+	ReferencesOnly bool
+	// BaseURL is the service base URL of the resources, which Bundle
+	// prefixes their relative references with to form the fullUrls of
+	// their entries. Entries have no fullUrl if it is empty.
+	BaseURL string
+}
+
+// Grouping is the grouping of the resources of a patient.
+type Grouping struct {
+	// Patients are the Patient resources, which Bundle includes in every
+	// bundle.
+	Patients []*r4pb.ContainedResource
+	// Encounters are the groups of the Encounters, in the order of their
+	// start.
+	Encounters []*EncounterGroup
+	// Episodes are the groups of the EpisodesOfCare, in the order of their
+	// start.
+	Episodes []*EpisodeGroup
+	// Ungrouped are the resources that belong to no Encounter or
+	// EpisodeOfCare, such as Practitioners and Medications, in the order
+	// of the input.
+	Ungrouped []*r4pb.ContainedResource
+
+	opts Options
+}
+
+// EncounterGroup is an Encounter and the resources that belong to it.
+type EncounterGroup struct {
+	Encounter *encpb.Encounter
+	// Resources are the resources that refer to the Encounter, in the order
+	// of the input.
+	Resources []*r4pb.ContainedResource
+	// Overlapping are the resources without a reference to an Encounter
+	// whose time falls in the period of the Encounter.
+	Overlapping []*r4pb.ContainedResource
+
+	start, end time.Time
+}
+
+// EpisodeGroup is an EpisodeOfCare and the Encounters and resources that
+// belong to it.
+type EpisodeGroup struct {
+	Episode *eocpb.EpisodeOfCare
+	// Encounters are the groups of the Encounters of the episode, in the
+	// order of their start.
+	Encounters []*EncounterGroup
+	// Resources are the resources that belong to the episode but to none of
+	// its Encounters, by reference or by time.
+	Resources []*r4pb.ContainedResource
+
+	start, end time.Time
+}
+
+// Group groups resources, the resources of a patient, by Encounter and
+// EpisodeOfCare.
+func Group(resources []*r4pb.ContainedResource, opts Options) (*Grouping, error) {
+	g := &Grouping{opts: opts}
+	encounters := map[string]*EncounterGroup{}
+	episodes := map[string]*EpisodeGroup{}
+	var others []*r4pb.ContainedResource
+	for _, cr := range resources {
+		switch {
+		case cr.GetPatient() != nil:
+			g.Patients = append(g.Patients, cr)
+		case cr.GetEncounter() != nil:
+			e := cr.GetEncounter()
+			eg := &EncounterGroup{Encounter: e}
+			eg.start, eg.end = periodSpan(e.GetPeriod())
+			if !eg.start.IsZero() && e.GetPeriod().GetEnd() == nil {
+				eg.end = eg.start.Truncate(24*time.Hour).AddDate(0, 0, 1)
+			}
+			g.Encounters = append(g.Encounters, eg)
+			encounters[e.GetId().GetValue()] = eg
+		case cr.GetEpisodeOfCare() != nil:
+			ep := cr.GetEpisodeOfCare()
+			epg := &EpisodeGroup{Episode: ep}
+			epg.start, epg.end = periodSpan(ep.GetPeriod())
+			g.Episodes = append(g.Episodes, epg)
+			episodes[ep.GetId().GetValue()] = epg
+		default:
+			others = append(others, cr)
+		}
+	}
+	sortByStart(g.Encounters, func(eg *EncounterGroup) time.Time { return eg.start })
+	sortByStart(g.Episodes, func(epg *EpisodeGroup) time.Time { return epg.start })
+
+	// The episodes each encounter belongs to.
+	encounterEpisodes := map[*EncounterGroup]bool{}
+	for _, eg := range g.Encounters {
+		found := false
+		for _, id := range referencedIDs(eg.Encounter.GetEpisodeOfCare(), "EpisodeOfCare") {
+			if epg, ok := episodes[id]; ok {
+				epg.Encounters = append(epg.Encounters, eg)
+				found = true
+			}
+		}
+		if !found && !opts.ReferencesOnly && !eg.start.IsZero() {
+			if epg := containingEpisode(g.Episodes, eg.start); epg != nil {
+				epg.Encounters = append(epg.Encounters, eg)
+				found = true
+			}
+		}
+		encounterEpisodes[eg] = found
+	}
+
+	for _, cr := range others {
+		res := elementpath.Unwrap(cr)
+		if res == nil {
+			continue
+		}
+		ids, err := references(res, encounterReferences, "Encounter")
+		if err != nil {
+			return nil, err
+		}
+		grouped := false
+		for _, id := range ids {
+			if eg, ok := encounters[id]; ok {
+				eg.Resources = append(eg.Resources, cr)
+				grouped = true
+			}
+		}
+		if grouped {
+			continue
+		}
+		ids, err = references(res, episodeReferences, "EpisodeOfCare")
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if epg, ok := episodes[id]; ok {
+				epg.Resources = append(epg.Resources, cr)
+				grouped = true
+			}
+		}
+		if grouped || opts.ReferencesOnly {
+			if !grouped {
+				g.Ungrouped = append(g.Ungrouped, cr)
+			}
+			continue
+		}
+		t, ok, err := clinicalTime(res)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			g.Ungrouped = append(g.Ungrouped, cr)
+			continue
+		}
+		if eg := containingEncounter(g.Encounters, t); eg != nil {
+			eg.Overlapping = append(eg.Overlapping, cr)
+		} else if epg := containingEpisode(g.Episodes, t); epg != nil {
+			epg.Resources = append(epg.Resources, cr)
+		} else {
+			g.Ungrouped = append(g.Ungrouped, cr)
+		}
+	}
+	return g, nil
+}
+
+func sortByStart[T any](s []T, start func(T) time.Time) {
+	sort.SliceStable(s, func(i, j int) bool { return start(s[i]).Before(start(s[j])) })
+}
+
+// references returns the ids of the resources of resourceType that expr
+// selects references to from res.
+func references(res proto.Message, expr *fhirpath.Expression, resourceType string) ([]string, error) {
+	vs, err := expr.Evaluate(fhirpath.Collection{res})
+	if err != nil {
+		return nil, err
+	}
+	var refs []*d4pb.Reference
+	for _, v := range vs {
+		if ref, ok := v.(*d4pb.Reference); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return referencedIDs(refs, resourceType), nil
+}
+
+// referencedIDs returns the ids of the resources of resourceType refs refer
+// to, ignoring logical references and references to other types.
+func referencedIDs(refs []*d4pb.Reference, resourceType string) []string {
+	var ids []string
+	for _, ref := range refs {
+		p, err := fhirtypes.ParseReference(fhirtypes.ReferenceURI(ref))
+		if err != nil || p.Type != resourceType {
+			continue
+		}
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+// clinicalTime returns the clinically relevant time of res, or the start of
+// its Period.
+func clinicalTime(res proto.Message) (time.Time, bool, error) {
+	for _, expr := range times {
+		vs, err := expr.Evaluate(fhirpath.Collection{res})
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		for _, v := range vs {
+			if p, ok := v.(*d4pb.Period); ok {
+				if start, _ := periodSpan(p); !start.IsZero() {
+					return start, true, nil
+				}
+				continue
+			}
+			if t, ok := timeOf(v); ok {
+				return t, true, nil
+			}
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// timeOf returns the start of v if it is a Date, DateTime or Instant.
+func timeOf(v interface{}) (time.Time, bool) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return time.Time{}, false
+	}
+	start, _, err := fhirtypes.Span(m)
+	return start, err == nil
+}
+
+// periodSpan returns the start and end of p, zero where they are missing.
+// The end includes the whole of its precision, so that a period ending on
+// 2025-01-31 includes the day.
+func periodSpan(p *d4pb.Period) (start, end time.Time) {
+	if p.GetStart() != nil {
+		start, _, _ = fhirtypes.Span(p.GetStart())
+	}
+	if p.GetEnd() != nil {
+		_, end, _ = fhirtypes.Span(p.GetEnd())
+	}
+	return start, end
+}
+
+// contains reports whether t falls in [start, end), where a zero end is
+// open.
+func contains(start, end, t time.Time) bool {
+	return !start.IsZero() && !t.Before(start) && (end.IsZero() || t.Before(end))
+}
+
+// containingEncounter returns the Encounter of encounters whose period
+// contains t that started last, which is the innermost of nested
+// Encounters, or nil if none does.
+func containingEncounter(encounters []*EncounterGroup, t time.Time) *EncounterGroup {
+	var found *EncounterGroup
+	for _, eg := range encounters {
+		if contains(eg.start, eg.end, t) {
+			found = eg
+		}
+	}
+	return found
+}
+
+// containingEpisode returns the EpisodeOfCare of episodes whose period
+// contains t that started last, or nil if none does.
+func containingEpisode(episodes []*EpisodeGroup, t time.Time) *EpisodeGroup {
+	var found *EpisodeGroup
+	for _, epg := range episodes {
+		if contains(epg.start, epg.end, t) {
+			found = epg
+		}
+	}
+	return found
+}
+
+// Bundle returns a collection Bundle of the Patients of g, the Encounter of
+// eg and its resources.
+func (g *Grouping) Bundle(eg *EncounterGroup) *r4pb.Bundle {
+	b := g.newBundle()
+	g.add(b, &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Encounter{Encounter: eg.Encounter}})
+	g.addGroup(b, eg)
+	return b
+}
+
+// EpisodeBundle returns a collection Bundle of the Patients of g, the
+// EpisodeOfCare of epg, its Encounters and their resources, and its other
+// resources.
+func (g *Grouping) EpisodeBundle(epg *EpisodeGroup) *r4pb.Bundle {
+	b := g.newBundle()
+	g.add(b, &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_EpisodeOfCare{EpisodeOfCare: epg.Episode}})
+	for _, eg := range epg.Encounters {
+		g.add(b, &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Encounter{Encounter: eg.Encounter}})
+		g.addGroup(b, eg)
+	}
+	for _, cr := range epg.Resources {
+		g.add(b, cr)
+	}
+	return b
+}
+
+func (g *Grouping) newBundle() *r4pb.Bundle {
+	b := &r4pb.Bundle{Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_COLLECTION}}
+	for _, p := range g.Patients {
+		g.add(b, p)
+	}
+	return b
+}
+
+func (g *Grouping) addGroup(b *r4pb.Bundle, eg *EncounterGroup) {
+	for _, cr := range eg.Resources {
+		g.add(b, cr)
+	}
+	for _, cr := range eg.Overlapping {
+		g.add(b, cr)
+	}
+}
+
+func (g *Grouping) add(b *r4pb.Bundle, cr *r4pb.ContainedResource) {
+	e := &r4pb.Bundle_Entry{Resource: cr}
+	if g.opts.BaseURL != "" {
+		if id := elementpath.ID(cr); id != "" {
+			e.FullUrl = &d4pb.Uri{Value: strings.TrimSuffix(g.opts.BaseURL, "/") + "/" + elementpath.ResourceType(cr) + "/" + id}
+		}
+	}
+	b.Entry = append(b.Entry, e)
+}
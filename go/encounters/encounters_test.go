@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encounters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/go-cmp/cmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	encpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/encounter_go_proto"
+	eocpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/episode_of_care_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	pracpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/practitioner_go_proto"
+)
+
+// dateTime returns the DateTime of the given day and hour of January 2025.
+func dateTime(day, hour int) *d4pb.DateTime {
+	return &d4pb.DateTime{ValueUs: time.Date(2025, 1, day, hour, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND}
+}
+
+func period(start, end *d4pb.DateTime) *d4pb.Period {
+	return &d4pb.Period{Start: start, End: end}
+}
+
+func encounter(id string, p *d4pb.Period, episodes ...string) *r4pb.ContainedResource {
+	e := &encpb.Encounter{
+		Id:     &d4pb.Id{Value: id},
+		Status: &encpb.Encounter_StatusCode{Value: c4pb.EncounterStatusCode_FINISHED},
+		Period: p,
+	}
+	for _, ep := range episodes {
+		e.EpisodeOfCare = append(e.EpisodeOfCare, &d4pb.Reference{Reference: &d4pb.Reference_EpisodeOfCareId{EpisodeOfCareId: &d4pb.ReferenceId{Value: ep}}})
+	}
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Encounter{Encounter: e}}
+}
+
+func episode(id string, p *d4pb.Period) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_EpisodeOfCare{EpisodeOfCare: &eocpb.EpisodeOfCare{
+		Id:     &d4pb.Id{Value: id},
+		Status: &eocpb.EpisodeOfCare_StatusCode{Value: c4pb.EpisodeOfCareStatusCode_ACTIVE},
+		Period: p,
+	}}}
+}
+
+// observation returns an Observation made at t in encounter, either of which
+// may be missing.
+func observation(id string, t *d4pb.DateTime, encounter string) *r4pb.ContainedResource {
+	o := &obspb.Observation{
+		Id:     &d4pb.Id{Value: id},
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code:   &d4pb.CodeableConcept{Text: &d4pb.String{Value: id}},
+	}
+	if t != nil {
+		o.Effective = &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: t}}
+	}
+	if encounter != "" {
+		o.Encounter = &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: "Encounter/" + encounter}}}
+	}
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: o}}
+}
+
+func condition(id string, onset *d4pb.DateTime) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Condition{Condition: &cpb.Condition{
+		Id:      &d4pb.Id{Value: id},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Onset:   &cpb.Condition_OnsetX{Choice: &cpb.Condition_OnsetX_DateTime{DateTime: onset}},
+	}}}
+}
+
+func testResources() []*r4pb.ContainedResource {
+	return []*r4pb.ContainedResource{
+		{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{Id: &d4pb.Id{Value: "p1"}}}},
+		{OneofResource: &r4pb.ContainedResource_Practitioner{Practitioner: &pracpb.Practitioner{Id: &d4pb.Id{Value: "dr1"}}}},
+		episode("ep1", period(dateTime(1, 0), dateTime(20, 0))),
+		// e2 starts before e1 to check that groups are ordered by start.
+		encounter("e2", period(dateTime(10, 8), nil)),
+		encounter("e1", period(dateTime(2, 8), dateTime(2, 10)), "ep1"),
+		encounter("e3", period(dateTime(25, 8), dateTime(25, 9))),
+		observation("o1", dateTime(10, 9), "e1"),
+		observation("o2", dateTime(10, 20), ""),
+		observation("o3", dateTime(11, 9), ""),
+		observation("o4", nil, ""),
+		condition("c1", dateTime(28, 0)),
+	}
+}
+
+func ids(crs []*r4pb.ContainedResource) []string {
+	var out []string
+	for _, cr := range crs {
+		out = append(out, elementpath.ID(cr))
+	}
+	return out
+}
+
+func encounterIDs(egs []*EncounterGroup) []string {
+	var out []string
+	for _, eg := range egs {
+		out = append(out, eg.Encounter.GetId().GetValue())
+	}
+	return out
+}
+
+func TestGroup(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		// want maps the ids of Encounters and EpisodesOfCare to the ids of
+		// their Encounters and resources, and "" to the ungrouped resources.
+		want map[string][]string
+	}{
+		{
+			name: "references and time",
+			want: map[string][]string{
+				"e1":  {"o1"},
+				"e2":  {"o2"},
+				"e3":  nil,
+				"ep1": {"e1", "e2", "o3"},
+				"":    {"dr1", "o4", "c1"},
+			},
+		},
+		{
+			name: "references only",
+			opts: Options{ReferencesOnly: true},
+			want: map[string][]string{
+				"e1":  {"o1"},
+				"e2":  nil,
+				"e3":  nil,
+				"ep1": {"e1"},
+				"":    {"dr1", "o2", "o3", "o4", "c1"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := Group(testResources(), tc.opts)
+			if err != nil {
+				t.Fatalf("Group() returned unexpected error: %v", err)
+			}
+			got := map[string][]string{"": ids(g.Ungrouped)}
+			for _, eg := range g.Encounters {
+				got[eg.Encounter.GetId().GetValue()] = append(ids(eg.Resources), ids(eg.Overlapping)...)
+			}
+			for _, epg := range g.Episodes {
+				got[epg.Episode.GetId().GetValue()] = append(encounterIDs(epg.Encounters), ids(epg.Resources)...)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Group() diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]string{"e1", "e2", "e3"}, encounterIDs(g.Encounters)); diff != "" {
+				t.Errorf("Group() encounter order diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]string{"p1"}, ids(g.Patients)); diff != "" {
+				t.Errorf("Group() patients diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGroup_NestedEncounters(t *testing.T) {
+	// A resource in nested Encounters belongs to the innermost one.
+	g, err := Group([]*r4pb.ContainedResource{
+		encounter("stay", period(dateTime(1, 0), dateTime(5, 0))),
+		encounter("visit", period(dateTime(3, 8), dateTime(3, 10))),
+		observation("o1", dateTime(3, 9), ""),
+		observation("o2", dateTime(4, 9), ""),
+	}, Options{})
+	if err != nil {
+		t.Fatalf("Group() returned unexpected error: %v", err)
+	}
+	got := map[string][]string{}
+	for _, eg := range g.Encounters {
+		got[eg.Encounter.GetId().GetValue()] = ids(eg.Overlapping)
+	}
+	want := map[string][]string{"stay": {"o2"}, "visit": {"o1"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Group() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestBundle(t *testing.T) {
+	g, err := Group(testResources(), Options{BaseURL: "https://example.com/fhir/"})
+	if err != nil {
+		t.Fatalf("Group() returned unexpected error: %v", err)
+	}
+	b := g.Bundle(g.Encounters[1])
+	if got := b.GetType().GetValue(); got != c4pb.BundleTypeCode_COLLECTION {
+		t.Errorf("Bundle() type = %v, want %v", got, c4pb.BundleTypeCode_COLLECTION)
+	}
+	var got []string
+	for _, e := range b.GetEntry() {
+		got = append(got, e.GetFullUrl().GetValue())
+	}
+	want := []string{
+		"https://example.com/fhir/Patient/p1",
+		"https://example.com/fhir/Encounter/e2",
+		"https://example.com/fhir/Observation/o2",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Bundle() fullUrls diff (-want +got):\n%s", diff)
+	}
+
+	var entries []string
+	for _, e := range g.EpisodeBundle(g.Episodes[0]).GetEntry() {
+		entries = append(entries, elementpath.ID(e.GetResource()))
+	}
+	want = []string{"p1", "ep1", "e1", "o1", "e2", "o2", "o3"}
+	if diff := cmp.Diff(want, entries); diff != "" {
+		t.Errorf("EpisodeBundle() entries diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fhirdiff computes the element-level differences between two
+// versions of a FHIR resource and renders them as JSON Patch documents.
+//
+// Resources are compared in their FHIR JSON form, so that two protos holding
+// the same data compare equal however they were built, and the differences
+// can be applied to the JSON of the old version with a JSON Patch (RFC 6902),
+// as accepted by the FHIR patch interaction. Lists are compared position by
+// position: an element inserted in the middle of a list is reported as
+// changes of the elements after it.
+package fhirdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+)
+
+// Op is the kind of a Change, named after the JSON Patch operations.
+type Op string
+
+// Kinds of changes.
+const (
+	Add     Op = "add"
+	Remove  Op = "remove"
+	Replace Op = "replace"
+)
+
+// Change is a difference between two versions of a resource.
+type Change struct {
+	Op Op `json:"op"`
+	// Path is the element path of the changed element, i.e.
+	// "Patient.name[0].given[1]".
+	Path string `json:"path"`
+	// Pointer is the JSON Pointer of the element in the JSON of the resource,
+	// i.e. "/name/0/given/1".
+	Pointer string `json:"pointer"`
+	// Old and New are the JSON of the element in the old and new versions;
+	// Old is empty for additions and New for removals.
+	Old json.RawMessage `json:"old,omitempty"`
+	New json.RawMessage `json:"new,omitempty"`
+}
+
+// Options configures a comparison.
+type Options struct {
+	// Ignore are the paths of the elements not to compare, without the
+	// resource type and indices, i.e. "meta.lastUpdated" or "text".
+	Ignore []string
+}
+
+// Resources returns the changes from the resource old to the resource new,
+// which are resources or ContainedResources of any FHIR version supported by
+// jsonformat.
+func Resources(old, new proto.Message, ver fhirversion.Version, opts Options) ([]Change, error) {
+	m, err := jsonformat.NewMarshaller(false, "", "", ver)
+	if err != nil {
+		return nil, err
+	}
+	a, err := m.Marshal(old)
+	if err != nil {
+		return nil, err
+	}
+	b, err := m.Marshal(new)
+	if err != nil {
+		return nil, err
+	}
+	return JSON(a, b, opts)
+}
+
+// JSON returns the changes from the resource JSON old to new.
+func JSON(old, new []byte, opts Options) ([]Change, error) {
+	a, err := decode(old)
+	if err != nil {
+		return nil, fmt.Errorf("old resource: %w", err)
+	}
+	b, err := decode(new)
+	if err != nil {
+		return nil, fmt.Errorf("new resource: %w", err)
+	}
+	root, _ := a["resourceType"].(string)
+	if t, _ := b["resourceType"].(string); t != root {
+		return nil, fmt.Errorf("resource types differ: %q and %q", root, t)
+	}
+	d := &differ{ignore: map[string]bool{}}
+	for _, p := range opts.Ignore {
+		d.ignore[p] = true
+	}
+	if err := d.object(root, "", "", a, b); err != nil {
+		return nil, err
+	}
+	return d.changes, nil
+}
+
+func decode(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Numbers are kept as written, since FHIR decimals have a precision.
+	dec.UseNumber()
+	var v map[string]interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type differ struct {
+	ignore  map[string]bool
+	changes []Change
+}
+
+// object compares the JSON objects a and b at path, pointer and the path
+// without indices field.
+func (d *differ) object(path, pointer, field string, a, b map[string]interface{}) error {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		f := k
+		if field != "" {
+			f = field + "." + k
+		}
+		if d.ignore[f] {
+			continue
+		}
+		av, inA := a[k]
+		bv, inB := b[k]
+		p, ptr := path+"."+k, pointer+"/"+escape(k)
+		switch {
+		case !inA:
+			if err := d.add(Add, p, ptr, nil, bv); err != nil {
+				return err
+			}
+		case !inB:
+			if err := d.add(Remove, p, ptr, av, nil); err != nil {
+				return err
+			}
+		default:
+			if err := d.value(p, ptr, f, av, bv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *differ) value(path, pointer, field string, a, b interface{}) error {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			// Contained and Bundle resources are replaced as a whole if their
+			// type changes.
+			if av["resourceType"] == bv["resourceType"] {
+				return d.object(path, pointer, field, av, bv)
+			}
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			return d.list(path, pointer, field, av, bv)
+		}
+	}
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return d.add(Replace, path, pointer, a, b)
+}
+
+func (d *differ) list(path, pointer, field string, a, b []interface{}) error {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if err := d.value(fmt.Sprintf("%s[%d]", path, i), pointer+"/"+strconv.Itoa(i), field, a[i], b[i]); err != nil {
+			return err
+		}
+	}
+	for i := n; i < len(b); i++ {
+		if err := d.add(Add, fmt.Sprintf("%s[%d]", path, i), pointer+"/"+strconv.Itoa(i), nil, b[i]); err != nil {
+			return err
+		}
+	}
+	// Removals are listed from the end, so that the patch removes each
+	// element at the index it is reported at.
+	for i := len(a) - 1; i >= n; i-- {
+		if err := d.add(Remove, fmt.Sprintf("%s[%d]", path, i), pointer+"/"+strconv.Itoa(i), a[i], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *differ) add(op Op, path, pointer string, old, new interface{}) error {
+	c := Change{Op: op, Path: path, Pointer: pointer}
+	var err error
+	if old != nil {
+		if c.Old, err = json.Marshal(old); err != nil {
+			return err
+		}
+	}
+	if new != nil {
+		if c.New, err = json.Marshal(new); err != nil {
+			return err
+		}
+	}
+	d.changes = append(d.changes, c)
+	return nil
+}
+
+// escape escapes a key of a JSON Pointer.
+func escape(k string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+}
+
+// Patch returns the JSON Patch document applying changes to the JSON of the
+// old version of the resource.
+func Patch(changes []Change) ([]byte, error) {
+	type op struct {
+		Op    Op              `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}
+	ops := make([]op, 0, len(changes))
+	for _, c := range changes {
+		o := op{Op: c.Op, Path: c.Pointer}
+		if c.Op != Remove {
+			o.Value = c.New
+		}
+		ops = append(ops, o)
+	}
+	return json.Marshal(ops)
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirdiff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/go-cmp/cmp"
+)
+
+func raw(s string) json.RawMessage { return json.RawMessage(s) }
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		opts     Options
+		want     []Change
+	}{
+		{
+			name: "equal",
+			old:  `{"resourceType":"Patient","id":"1","name":[{"family":"Smith"}]}`,
+			new:  `{"id":"1","name":[{"family":"Smith"}],"resourceType":"Patient"}`,
+		},
+		{
+			name: "replace add remove",
+			old:  `{"resourceType":"Patient","id":"1","gender":"male","name":[{"family":"Smith","given":["A","B"]}]}`,
+			new:  `{"resourceType":"Patient","id":"1","birthDate":"1970","name":[{"family":"Smyth","given":["A"]}]}`,
+			want: []Change{
+				{Op: Add, Path: "Patient.birthDate", Pointer: "/birthDate", New: raw(`"1970"`)},
+				{Op: Remove, Path: "Patient.gender", Pointer: "/gender", Old: raw(`"male"`)},
+				{Op: Replace, Path: "Patient.name[0].family", Pointer: "/name/0/family", Old: raw(`"Smith"`), New: raw(`"Smyth"`)},
+				{Op: Remove, Path: "Patient.name[0].given[1]", Pointer: "/name/0/given/1", Old: raw(`"B"`)},
+			},
+		},
+		{
+			name: "decimal precision",
+			old:  `{"resourceType":"Observation","valueQuantity":{"value":1.0}}`,
+			new:  `{"resourceType":"Observation","valueQuantity":{"value":1.00}}`,
+			want: []Change{
+				{Op: Replace, Path: "Observation.valueQuantity.value", Pointer: "/valueQuantity/value", Old: raw(`1.0`), New: raw(`1.00`)},
+			},
+		},
+		{
+			name: "list growth and removal",
+			old:  `{"resourceType":"Patient","identifier":[{"value":"a"},{"value":"b"},{"value":"c"}],"telecom":[{"value":"1"}]}`,
+			new:  `{"resourceType":"Patient","identifier":[{"value":"a"}],"telecom":[{"value":"1"},{"value":"2"}]}`,
+			want: []Change{
+				{Op: Remove, Path: "Patient.identifier[2]", Pointer: "/identifier/2", Old: raw(`{"value":"c"}`)},
+				{Op: Remove, Path: "Patient.identifier[1]", Pointer: "/identifier/1", Old: raw(`{"value":"b"}`)},
+				{Op: Add, Path: "Patient.telecom[1]", Pointer: "/telecom/1", New: raw(`{"value":"2"}`)},
+			},
+		},
+		{
+			name: "ignored",
+			old:  `{"resourceType":"Patient","meta":{"versionId":"1","lastUpdated":"2020-01-01T00:00:00Z"},"name":[{"family":"A"}]}`,
+			new:  `{"resourceType":"Patient","meta":{"versionId":"2","lastUpdated":"2021-01-01T00:00:00Z"},"name":[{"family":"A"}]}`,
+			opts: Options{Ignore: []string{"meta.versionId", "meta.lastUpdated"}},
+		},
+		{
+			name: "contained resource of another type",
+			old:  `{"resourceType":"Patient","contained":[{"resourceType":"Organization","id":"o"}]}`,
+			new:  `{"resourceType":"Patient","contained":[{"resourceType":"Practitioner","id":"o"}]}`,
+			want: []Change{{
+				Op: Replace, Path: "Patient.contained[0]", Pointer: "/contained/0",
+				Old: raw(`{"id":"o","resourceType":"Organization"}`), New: raw(`{"id":"o","resourceType":"Practitioner"}`),
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := JSON([]byte(test.old), []byte(test.new), test.opts)
+			if err != nil {
+				t.Fatalf("JSON() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("JSON() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSON_Errors(t *testing.T) {
+	if _, err := JSON([]byte(`{"resourceType":"Patient"}`), []byte(`{"resourceType":"Group"}`), Options{}); err == nil {
+		t.Errorf("JSON() of resources of different types succeeded, want error")
+	}
+	if _, err := JSON([]byte(`{`), []byte(`{"resourceType":"Patient"}`), Options{}); err == nil {
+		t.Errorf("JSON() of invalid JSON succeeded, want error")
+	}
+}
+
+func TestResources(t *testing.T) {
+	um, err := jsonformat.NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	old, err := um.UnmarshalR4([]byte(`{"resourceType":"Patient","id":"1","active":true}`))
+	if err != nil {
+		t.Fatalf("UnmarshalR4() returned unexpected error: %v", err)
+	}
+	new, err := um.UnmarshalR4([]byte(`{"resourceType":"Patient","id":"1","active":false}`))
+	if err != nil {
+		t.Fatalf("UnmarshalR4() returned unexpected error: %v", err)
+	}
+	changes, err := Resources(old, new, fhirversion.R4, Options{})
+	if err != nil {
+		t.Fatalf("Resources() returned unexpected error: %v", err)
+	}
+	patch, err := Patch(changes)
+	if err != nil {
+		t.Fatalf("Patch() returned unexpected error: %v", err)
+	}
+	if want := `[{"op":"replace","path":"/active","value":false}]`; string(patch) != want {
+		t.Errorf("Patch() = %s, want %s", patch, want)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	changes := []Change{
+		{Op: Add, Pointer: "/name~1x", New: raw(`"a"`)},
+		{Op: Remove, Pointer: "/gender", Old: raw(`"male"`)},
+	}
+	got, err := Patch(changes)
+	if err != nil {
+		t.Fatalf("Patch() returned unexpected error: %v", err)
+	}
+	if want := `[{"op":"add","path":"/name~1x","value":"a"},{"op":"remove","path":"/gender"}]`; string(got) != want {
+		t.Errorf("Patch() = %s, want %s", got, want)
+	}
+}
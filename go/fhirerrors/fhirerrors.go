@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fhirerrors defines the classes of failure shared by the packages
+// that parse and validate FHIR data, so that callers can tell them apart with
+// errors.Is rather than by matching messages:
+//
+//	_, err := u.Unmarshal(data)
+//	switch {
+//	case errors.Is(err, fhirerrors.ErrInvalidJSON):
+//		// Reject the request as malformed.
+//	case errors.Is(err, fhirerrors.ErrUnknownField):
+//		// Retry with a lenient unmarshaller.
+//	}
+//
+// The errors returned keep their detailed types, such as the UnmarshalError
+// of jsonformat, and match the classes of this package in addition. A list of
+// errors matches every class of its elements.
+package fhirerrors
+
+import "errors"
+
+// Classes of failure.
+var (
+	// ErrInvalidJSON is the class of input that is not well-formed JSON, or
+	// is nested deeper than allowed.
+	ErrInvalidJSON = errors.New("invalid JSON")
+	// ErrUnknownField is the class of elements that the resource does not
+	// define.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrUnknownResourceType is the class of resources whose resourceType is
+	// missing or not one of the FHIR version.
+	ErrUnknownResourceType = errors.New("unknown resource type")
+	// ErrInvalidValue is the class of values that are not valid for their
+	// type, such as malformed dates, codes outside their value set or JSON of
+	// the wrong kind.
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrMissingRequiredField is the class of required elements that are
+	// absent or empty.
+	ErrMissingRequiredField = errors.New("missing required field")
+	// ErrInvalidReferenceType is the class of references to resources of a
+	// type the element does not allow.
+	ErrInvalidReferenceType = errors.New("invalid reference type")
+	// ErrConstraintViolation is the class of resources that fail a constraint
+	// or invariant of their profile.
+	ErrConstraintViolation = errors.New("constraint violation")
+	// ErrUnsupportedVersion is the class of FHIR versions a package does not
+	// support.
+	ErrUnsupportedVersion = errors.New("unsupported FHIR version")
+)
+
+var classes = []error{
+	ErrInvalidJSON,
+	ErrUnknownField,
+	ErrUnknownResourceType,
+	ErrInvalidValue,
+	ErrMissingRequiredField,
+	ErrInvalidReferenceType,
+	ErrConstraintViolation,
+	ErrUnsupportedVersion,
+}
+
+// Class returns the first class of this package err matches, in the order
+// they are declared, or nil if it matches none.
+func Class(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, c := range classes {
+		if errors.Is(err, c) {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{nil, nil},
+		{errors.New("other"), nil},
+		{ErrUnknownField, ErrUnknownField},
+		{fmt.Errorf("%w R5", ErrUnsupportedVersion), ErrUnsupportedVersion},
+		{fmt.Errorf("outer: %w", fmt.Errorf("%w: obs-6", ErrConstraintViolation)), ErrConstraintViolation},
+	}
+	for _, tc := range tests {
+		if got := Class(tc.err); got != tc.want {
+			t.Errorf("Class(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
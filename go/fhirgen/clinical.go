@@ -0,0 +1,265 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirgen
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	encpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/encounter_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+)
+
+// Code systems of the generated resources.
+const (
+	LOINC  = "http://loinc.org"
+	UCUM   = "http://unitsofmeasure.org"
+	SNOMED = "http://snomed.info/sct"
+	RxNorm = "http://www.nlm.nih.gov/research/umls/rxnorm"
+
+	actCodeSystem  = "http://terminology.hl7.org/CodeSystem/v3-ActCode"
+	categorySystem = "http://terminology.hl7.org/CodeSystem/observation-category"
+)
+
+type code struct{ system, code, display string }
+
+func (c code) concept() *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{
+		Coding: []*d4pb.Coding{{
+			System:  &d4pb.Uri{Value: c.system},
+			Code:    &d4pb.Code{Value: c.code},
+			Display: &d4pb.String{Value: c.display},
+		}},
+		Text: &d4pb.String{Value: c.display},
+	}
+}
+
+var (
+	checkUp      = code{SNOMED, "185349003", "Encounter for check up"}
+	hypertension = code{SNOMED, "38341003", "Hypertensive disorder"}
+	diabetes     = code{SNOMED, "44054006", "Diabetes mellitus type 2"}
+	bpPanel      = code{LOINC, "85354-9", "Blood pressure panel with all children optional"}
+	vitalSigns   = code{categorySystem, "vital-signs", "Vital Signs"}
+	laboratory   = code{categorySystem, "laboratory", "Laboratory"}
+)
+
+// measurement is a kind of quantitative observation.
+type measurement struct {
+	code code
+	// unit is the UCUM unit of the values, which are rounded to decimals
+	// digits.
+	unit     string
+	decimals int
+}
+
+var (
+	bodyHeight      = measurement{code{LOINC, "8302-2", "Body height"}, "cm", 1}
+	bodyWeight      = measurement{code{LOINC, "29463-7", "Body weight"}, "kg", 1}
+	bodyMassIndex   = measurement{code{LOINC, "39156-5", "Body mass index (BMI) [Ratio]"}, "kg/m2", 1}
+	heartRate       = measurement{code{LOINC, "8867-4", "Heart rate"}, "/min", 0}
+	bodyTemperature = measurement{code{LOINC, "8310-5", "Body temperature"}, "Cel", 1}
+	systolicBP      = measurement{code{LOINC, "8480-6", "Systolic blood pressure"}, "mm[Hg]", 0}
+	diastolicBP     = measurement{code{LOINC, "8462-4", "Diastolic blood pressure"}, "mm[Hg]", 0}
+	glucose         = measurement{code{LOINC, "2339-0", "Glucose [Mass/volume] in Blood"}, "mg/dL", 0}
+	hemoglobinA1c   = measurement{code{LOINC, "4548-4", "Hemoglobin A1c/Hemoglobin.total in Blood"}, "%", 1}
+)
+
+func (m measurement) quantity(v float64) *d4pb.Quantity {
+	return &d4pb.Quantity{
+		Value:  &d4pb.Decimal{Value: strconv.FormatFloat(v, 'f', m.decimals, 64)},
+		Unit:   &d4pb.String{Value: m.unit},
+		System: &d4pb.Uri{Value: UCUM},
+		Code:   &d4pb.Code{Value: m.unit},
+	}
+}
+
+// medication is a medication prescribed for a condition.
+type medication struct {
+	code, reason code
+	dosage       string
+}
+
+var (
+	lisinopril = medication{code{RxNorm, "314076", "lisinopril 10 MG Oral Tablet"}, hypertension, "Take 1 tablet by mouth once daily"}
+	metformin  = medication{code{RxNorm, "861007", "metformin hydrochloride 500 MG Oral Tablet"}, diabetes, "Take 1 tablet by mouth twice daily with meals"}
+)
+
+// encounter adds to r a check-up encounter of p starting at start, with its
+// observations and the prescriptions started at it.
+func (g *Generator) encounter(p *patient, r *Record, start time.Time) {
+	id := g.uuid()
+	end := start.Add(time.Duration(15+g.rnd.Intn(31)) * time.Minute)
+	r.Encounters = append(r.Encounters, &encpb.Encounter{
+		Id:     &d4pb.Id{Value: id},
+		Status: &encpb.Encounter_StatusCode{Value: c4pb.EncounterStatusCode_FINISHED},
+		ClassValue: &d4pb.Coding{
+			System:  &d4pb.Uri{Value: actCodeSystem},
+			Code:    &d4pb.Code{Value: "AMB"},
+			Display: &d4pb.String{Value: "ambulatory"},
+		},
+		Type:    []*d4pb.CodeableConcept{checkUp.concept()},
+		Subject: fhirtypes.Reference("Patient", p.id),
+		Period:  &d4pb.Period{Start: dateTime(start), End: dateTime(end)},
+	})
+
+	age := start.Sub(p.birth).Hours() / 24 / 365.25
+	vitals := start.Add(5 * time.Minute)
+	obs := func(t time.Time, category code, m measurement, v float64) {
+		o := g.observation(p, id, t, category, m.code)
+		o.Value = &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: m.quantity(v)}}
+		r.Observations = append(r.Observations, o)
+	}
+
+	height := p.height(age) + g.rnd.NormFloat64()*0.5
+	if age >= 2 {
+		bmi := p.bmiAt(age) + g.rnd.NormFloat64()*0.5
+		weight := bmi * height * height / 10000
+		obs(vitals, vitalSigns, bodyHeight, height)
+		obs(vitals, vitalSigns, bodyWeight, weight)
+		// The BMI is computed from the rounded measurements, as it would be.
+		obs(vitals, vitalSigns, bodyMassIndex, round(weight, 1)/math.Pow(round(height, 1)/100, 2))
+	} else {
+		obs(vitals, vitalSigns, bodyHeight, height)
+		obs(vitals, vitalSigns, bodyWeight, g.infantWeight(age))
+	}
+	switch {
+	case age < 1:
+		obs(vitals, vitalSigns, heartRate, g.normal(130, 12, 100, 170))
+	case age < 12:
+		obs(vitals, vitalSigns, heartRate, g.normal(95, 10, 70, 130))
+	default:
+		obs(vitals, vitalSigns, heartRate, g.normal(72, 8, 50, 105))
+	}
+	obs(vitals, vitalSigns, bodyTemperature, g.normal(36.8, 0.3, 36, 37.9))
+	if age >= 3 {
+		sys, dia := g.bloodPressure(p, age)
+		o := g.observation(p, id, vitals, vitalSigns, bpPanel)
+		for _, c := range []struct {
+			m measurement
+			v float64
+		}{{systolicBP, sys}, {diastolicBP, dia}} {
+			o.Component = append(o.Component, &obspb.Observation_Component{
+				Code:  c.m.code.concept(),
+				Value: &obspb.Observation_Component_ValueX{Choice: &obspb.Observation_Component_ValueX_Quantity{Quantity: c.m.quantity(c.v)}},
+			})
+		}
+		r.Observations = append(r.Observations, o)
+	}
+	if age >= 18 {
+		labs := start.Add(10 * time.Minute)
+		switch {
+		case !p.diabetes:
+			obs(labs, laboratory, glucose, g.normal(90, 10, 65, 125))
+		case p.onMetformin:
+			obs(labs, laboratory, glucose, g.normal(130, 20, 80, 250))
+			obs(labs, laboratory, hemoglobinA1c, g.normal(7, 0.6, 5.7, 10))
+		default:
+			obs(labs, laboratory, glucose, g.normal(165, 30, 110, 350))
+			obs(labs, laboratory, hemoglobinA1c, g.normal(8, 0.9, 6.5, 13))
+		}
+	}
+
+	// Conditions are diagnosed, and treated from then on, at the first
+	// encounter showing them.
+	authored := start.Add(15 * time.Minute)
+	if p.hypertension && !p.onLisinopril {
+		r.MedicationRequests = append(r.MedicationRequests, g.prescription(p, id, authored, lisinopril))
+		p.onLisinopril = true
+	}
+	if p.diabetes && !p.onMetformin && age >= 18 {
+		r.MedicationRequests = append(r.MedicationRequests, g.prescription(p, id, authored, metformin))
+		p.onMetformin = true
+	}
+}
+
+func (g *Generator) observation(p *patient, encID string, t time.Time, category, c code) *obspb.Observation {
+	return &obspb.Observation{
+		Id:        &d4pb.Id{Value: g.uuid()},
+		Status:    &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Category:  []*d4pb.CodeableConcept{category.concept()},
+		Code:      c.concept(),
+		Subject:   fhirtypes.Reference("Patient", p.id),
+		Encounter: fhirtypes.Reference("Encounter", encID),
+		Effective: &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: dateTime(t)}},
+	}
+}
+
+func (g *Generator) prescription(p *patient, encID string, t time.Time, m medication) *mrpb.MedicationRequest {
+	return &mrpb.MedicationRequest{
+		Id:                &d4pb.Id{Value: g.uuid()},
+		Status:            &mrpb.MedicationRequest_StatusCode{Value: c4pb.MedicationrequestStatusCode_ACTIVE},
+		Intent:            &mrpb.MedicationRequest_IntentCode{Value: c4pb.MedicationRequestIntentCode_ORDER},
+		Medication:        &mrpb.MedicationRequest_MedicationX{Choice: &mrpb.MedicationRequest_MedicationX_CodeableConcept{CodeableConcept: m.code.concept()}},
+		Subject:           fhirtypes.Reference("Patient", p.id),
+		Encounter:         fhirtypes.Reference("Encounter", encID),
+		AuthoredOn:        dateTime(t),
+		ReasonCode:        []*d4pb.CodeableConcept{m.reason.concept()},
+		DosageInstruction: []*d4pb.Dosage{{Text: &d4pb.String{Value: m.dosage}}},
+	}
+}
+
+// bloodPressure returns the systolic and diastolic blood pressure of p at
+// age.
+func (g *Generator) bloodPressure(p *patient, age float64) (float64, float64) {
+	var sys, dia float64
+	switch {
+	case age < 13:
+		sys, dia = g.normal(100, 8, 80, 120), g.normal(62, 6, 45, 80)
+	case !p.hypertension:
+		sys, dia = g.normal(118, 9, 95, 135), g.normal(76, 6, 60, 88)
+	case p.onLisinopril:
+		sys, dia = g.normal(132, 8, 110, 155), g.normal(83, 5, 70, 95)
+	default:
+		sys, dia = g.normal(150, 10, 135, 190), g.normal(93, 6, 85, 115)
+	}
+	if dia > sys-25 {
+		dia = sys - 25
+	}
+	return sys, dia
+}
+
+// height returns the height in cm of p at age, on an approximate growth
+// curve from 50 cm at birth to the adult height at 18.
+func (p *patient) height(age float64) float64 {
+	if age >= 18 {
+		return p.adultHeight
+	}
+	return 50 + (p.adultHeight-50)*math.Pow(age/18, 0.6)
+}
+
+// bmiAt returns the usual body mass index of p at age, which is lower in
+// childhood.
+func (p *patient) bmiAt(age float64) float64 {
+	if age >= 18 {
+		return p.bmi
+	}
+	return 16 + (p.bmi-16)*age/18
+}
+
+// infantWeight returns the weight in kg of an infant under two.
+func (g *Generator) infantWeight(age float64) float64 {
+	return g.normal(3.4+8.6*math.Sqrt(age/2), 0.4, 2, 16)
+}
+
+func round(v float64, decimals int) float64 {
+	f := math.Pow(10, float64(decimals))
+	return math.Round(v*f) / f
+}
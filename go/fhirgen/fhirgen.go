@@ -0,0 +1,276 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fhirgen generates synthetic FHIR R4 patient records for tests and
+// load tests.
+//
+// A Generator produces Records of a Patient with their ambulatory
+// Encounters, the vital signs and laboratory Observations of each Encounter
+// and their MedicationRequests. The records are internally consistent: the
+// resources reference the Patient and the Encounter they belong to,
+// observations fall within their Encounter and match the age and sex of the
+// Patient, and patients with hypertension or type 2 diabetes have the
+// measurements and the prescriptions of their condition. The codes are LOINC
+// for observations, UCUM for units, SNOMED CT for conditions and RxNorm for
+// medications.
+//
+// Random and Conformant generate resources of any type instead, with random
+// elements of random shapes, for testing systems against the variety of
+// resources they may receive. Conformant keeps to the cardinalities,
+// bindings, choice types and invariants of a profile.
+//
+// The output depends only on the seed and the Options of the Generator, so
+// that tests can rely on it.
+package fhirgen
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/fhir/go/internal/uuid"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	encpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/encounter_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+// DefaultNow is the default end of the generated histories. It is fixed,
+// rather than the current time, so that the output does not change over time.
+var DefaultNow = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Options configures a Generator.
+type Options struct {
+	// Now is the time of the generation: patients are born and encounters
+	// happen before it. DefaultNow if zero.
+	Now time.Time
+	// Years is the length of the history of the patients, the period before
+	// Now their encounters happen in; 5 if 0.
+	Years int
+	// MaxEncounters is the largest number of encounters of a patient, which
+	// have at least one; 5 if 0.
+	MaxEncounters int
+	// MaxAge is the largest age of the patients in years; 90 if 0.
+	MaxAge int
+}
+
+// Record is the record of a generated patient.
+type Record struct {
+	Patient            *ppb.Patient
+	Encounters         []*encpb.Encounter
+	Observations       []*obspb.Observation
+	MedicationRequests []*mrpb.MedicationRequest
+}
+
+// Resources returns the resources of r, the Patient first.
+func (r *Record) Resources() []*r4pb.ContainedResource {
+	out := []*r4pb.ContainedResource{{OneofResource: &r4pb.ContainedResource_Patient{Patient: r.Patient}}}
+	for _, e := range r.Encounters {
+		out = append(out, &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Encounter{Encounter: e}})
+	}
+	for _, o := range r.Observations {
+		out = append(out, &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: o}})
+	}
+	for _, m := range r.MedicationRequests {
+		out = append(out, &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_MedicationRequest{MedicationRequest: m}})
+	}
+	return out
+}
+
+// Generator generates patient records. A Generator is not safe for
+// concurrent use.
+type Generator struct {
+	rnd  *rand.Rand
+	opts Options
+}
+
+// New returns a Generator of the records determined by seed and opts.
+func New(seed int64, opts Options) *Generator {
+	if opts.Now.IsZero() {
+		opts.Now = DefaultNow
+	}
+	if opts.Years <= 0 {
+		opts.Years = 5
+	}
+	if opts.MaxEncounters <= 0 {
+		opts.MaxEncounters = 5
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 90
+	}
+	return &Generator{rnd: rand.New(rand.NewSource(seed)), opts: opts}
+}
+
+// Patients returns the records of n new patients.
+func (g *Generator) Patients(n int) []*Record {
+	out := make([]*Record, n)
+	for i := range out {
+		out[i] = g.Patient()
+	}
+	return out
+}
+
+// patient is the hidden state of a generated patient, which the resources of
+// its record are derived from.
+type patient struct {
+	id     string
+	female bool
+	birth  time.Time
+	// adultHeight is the height in cm the patient reaches at 18, and bmi
+	// their usual body mass index as an adult.
+	adultHeight, bmi float64
+	hypertension     bool
+	diabetes         bool
+	// onLisinopril and onMetformin tell whether the treatments of the
+	// conditions were prescribed.
+	onLisinopril, onMetformin bool
+}
+
+// Patient returns the record of a new patient.
+func (g *Generator) Patient() *Record {
+	p := &patient{id: g.uuid(), female: g.rnd.Intn(2) == 0}
+	ageDays := g.rnd.Intn(g.opts.MaxAge*365 + 1)
+	p.birth = g.opts.Now.AddDate(0, 0, -ageDays).Truncate(24 * time.Hour)
+	if p.female {
+		p.adultHeight = g.normal(163, 6, 145, 185)
+	} else {
+		p.adultHeight = g.normal(176, 7, 155, 200)
+	}
+	p.bmi = g.normal(26, 4, 18, 42)
+	// The risk of both conditions grows with age and weight.
+	if age := years(p.birth, g.opts.Now); age >= 30 {
+		risk := float64(age-30)/100 + (p.bmi-25)/50
+		p.hypertension = g.rnd.Float64() < risk
+		p.diabetes = g.rnd.Float64() < risk/2
+	}
+
+	r := &Record{Patient: g.patientResource(p)}
+	for _, t := range g.encounterTimes(p) {
+		g.encounter(p, r, t)
+	}
+	return r
+}
+
+// encounterTimes returns the sorted start times of the encounters of p.
+func (g *Generator) encounterTimes(p *patient) []time.Time {
+	from := g.opts.Now.AddDate(-g.opts.Years, 0, 0)
+	if p.birth.After(from) {
+		from = p.birth
+	}
+	span := g.opts.Now.Sub(from)
+	n := 1 + g.rnd.Intn(g.opts.MaxEncounters)
+	var out []time.Time
+	for i := 0; i < n; i++ {
+		day := from.Add(time.Duration(g.rnd.Int63n(int64(span)))).Truncate(24 * time.Hour)
+		// Office hours, 8:00 to 17:00, in quarter hours.
+		out = append(out, day.Add(8*time.Hour+time.Duration(g.rnd.Intn(36))*15*time.Minute))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+func (g *Generator) patientResource(p *patient) *ppb.Patient {
+	given, family := g.pick(maleNames), g.pick(familyNames)
+	gender := c4pb.AdministrativeGenderCode_MALE
+	if p.female {
+		given = g.pick(femaleNames)
+		gender = c4pb.AdministrativeGenderCode_FEMALE
+	}
+	city := cities[g.rnd.Intn(len(cities))]
+	return &ppb.Patient{
+		Id: &d4pb.Id{Value: p.id},
+		Identifier: []*d4pb.Identifier{{
+			System: &d4pb.Uri{Value: MRNSystem},
+			Value:  &d4pb.String{Value: fmt.Sprintf("MRN%08d", g.rnd.Intn(100000000))},
+		}},
+		Active: &d4pb.Boolean{Value: true},
+		Name: []*d4pb.HumanName{{
+			Use:    &d4pb.HumanName_UseCode{Value: c4pb.NameUseCode_OFFICIAL},
+			Family: &d4pb.String{Value: family},
+			Given:  []*d4pb.String{{Value: given}},
+		}},
+		Telecom: []*d4pb.ContactPoint{{
+			System: &d4pb.ContactPoint_SystemCode{Value: c4pb.ContactPointSystemCode_PHONE},
+			Value:  &d4pb.String{Value: fmt.Sprintf("555-%03d-%04d", 100+g.rnd.Intn(900), g.rnd.Intn(10000))},
+			Use:    &d4pb.ContactPoint_UseCode{Value: c4pb.ContactPointUseCode_HOME},
+		}},
+		Gender:    &ppb.Patient_GenderCode{Value: gender},
+		BirthDate: &d4pb.Date{ValueUs: p.birth.UnixMicro(), Timezone: "UTC", Precision: d4pb.Date_DAY},
+		Address: []*d4pb.Address{{
+			Use:        &d4pb.Address_UseCode{Value: c4pb.AddressUseCode_HOME},
+			Line:       []*d4pb.String{{Value: fmt.Sprintf("%d %s", 1+g.rnd.Intn(9999), g.pick(streets))}},
+			City:       &d4pb.String{Value: city.name},
+			State:      &d4pb.String{Value: city.state},
+			PostalCode: &d4pb.String{Value: fmt.Sprintf("%s%02d", city.zip, g.rnd.Intn(100))},
+			Country:    &d4pb.String{Value: "US"},
+		}},
+	}
+}
+
+// MRNSystem is the identifier system of the medical record numbers of the
+// generated patients.
+const MRNSystem = "urn:fhirgen:mrn"
+
+func (g *Generator) pick(s []string) string {
+	return s[g.rnd.Intn(len(s))]
+}
+
+// normal returns a normally distributed value of mean m and standard
+// deviation sd, clamped to [lo, hi].
+func (g *Generator) normal(m, sd, lo, hi float64) float64 {
+	v := m + sd*g.rnd.NormFloat64()
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// uuid returns a random version 4 UUID drawn from the source of g.
+func (g *Generator) uuid() string {
+	var b [16]byte
+	g.rnd.Read(b[:])
+	return uuid.FromBytes(b)
+}
+
+// years returns the age in whole years at t of someone born at birth.
+func years(birth, t time.Time) int {
+	y := t.Year() - birth.Year()
+	if t.YearDay() < birth.YearDay() {
+		y--
+	}
+	return y
+}
+
+func dateTime(t time.Time) *d4pb.DateTime {
+	return &d4pb.DateTime{ValueUs: t.UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND}
+}
+
+var (
+	femaleNames = []string{"Mary", "Patricia", "Jennifer", "Linda", "Elizabeth", "Barbara", "Susan", "Jessica", "Sarah", "Karen", "Maria", "Nancy", "Lisa", "Emily", "Aisha", "Mei", "Priya", "Sofia"}
+	maleNames   = []string{"James", "Robert", "John", "Michael", "David", "William", "Richard", "Joseph", "Thomas", "Daniel", "Carlos", "Wei", "Arjun", "Mohammed", "Kenji", "Luis", "Samuel", "Omar"}
+	familyNames = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Nguyen", "Kim", "Patel", "Chen", "Wilson", "Anderson", "Taylor", "Thomas", "Lee", "Walker"}
+	streets     = []string{"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Park Rd", "Elm St", "Washington Blvd", "Lake View Ct", "Hillside Ave", "River Rd"}
+	cities      = []struct{ name, state, zip string }{
+		{"Springfield", "IL", "627"}, {"Columbus", "OH", "432"}, {"Austin", "TX", "787"}, {"Portland", "OR", "972"},
+		{"Madison", "WI", "537"}, {"Raleigh", "NC", "276"}, {"Denver", "CO", "802"}, {"Sacramento", "CA", "958"},
+	}
+)
@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirgen
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func TestGenerator_Reproducible(t *testing.T) {
+	resources := func(seed int64) []proto.Message {
+		var out []proto.Message
+		for _, r := range New(seed, Options{}).Patients(20) {
+			for _, cr := range r.Resources() {
+				out = append(out, cr)
+			}
+		}
+		return out
+	}
+	a, b := resources(42), resources(42)
+	if diff := cmp.Diff(a, b, protocmp.Transform()); diff != "" {
+		t.Errorf("Patients() with the same seed diff (-first +second):\n%s", diff)
+	}
+	if c := resources(43); len(a) == len(c) && proto.Equal(a[0], c[0]) {
+		t.Errorf("Patients() with different seeds returned the same records")
+	}
+}
+
+func TestGenerator_Valid(t *testing.T) {
+	m, err := jsonformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	um, err := jsonformat.NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	for _, r := range New(1, Options{}).Patients(50) {
+		for _, cr := range r.Resources() {
+			data, err := m.Marshal(cr)
+			if err != nil {
+				t.Fatalf("Marshal() returned unexpected error: %v", err)
+			}
+			if _, err := um.UnmarshalR4(data); err != nil {
+				t.Errorf("UnmarshalR4(%s) returned unexpected error: %v", data, err)
+			}
+		}
+	}
+}
+
+func TestGenerator_Consistent(t *testing.T) {
+	now := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+	var prescriptions int
+	for _, r := range New(7, Options{Now: now, MaxEncounters: 3}).Patients(100) {
+		pid := r.Patient.GetId().GetValue()
+		birth := time.UnixMicro(r.Patient.GetBirthDate().GetValueUs())
+		if birth.After(now) {
+			t.Errorf("patient %s born %v, after %v", pid, birth, now)
+		}
+		if n := len(r.Encounters); n < 1 || n > 3 {
+			t.Errorf("patient %s has %d encounters, want 1 to 3", pid, n)
+		}
+		periods := map[string]*d4pb.Period{}
+		for _, e := range r.Encounters {
+			if got := e.GetSubject().GetPatientId().GetValue(); got != pid {
+				t.Errorf("encounter subject %q, want %q", got, pid)
+			}
+			start := time.UnixMicro(e.GetPeriod().GetStart().GetValueUs())
+			if start.Before(birth) || start.After(now) {
+				t.Errorf("encounter of patient %s at %v, outside %v to %v", pid, start, birth, now)
+			}
+			periods[e.GetId().GetValue()] = e.GetPeriod()
+		}
+		for _, o := range r.Observations {
+			if got := o.GetSubject().GetPatientId().GetValue(); got != pid {
+				t.Errorf("observation subject %q, want %q", got, pid)
+			}
+			p, ok := periods[o.GetEncounter().GetEncounterId().GetValue()]
+			if !ok {
+				t.Fatalf("observation %s references an unknown encounter", o.GetId().GetValue())
+			}
+			at := o.GetEffective().GetDateTime().GetValueUs()
+			if at < p.GetStart().GetValueUs() || at > p.GetEnd().GetValueUs() {
+				t.Errorf("observation %s is outside its encounter", o.GetId().GetValue())
+			}
+			if code := o.GetCode().GetCoding()[0].GetCode().GetValue(); code == systolicBP.code.code {
+				t.Errorf("systolic blood pressure outside of a panel")
+			}
+			if q := o.GetValue().GetQuantity(); q != nil {
+				if _, err := strconv.ParseFloat(q.GetValue().GetValue(), 64); err != nil {
+					t.Errorf("observation %s value %q is not a number", o.GetId().GetValue(), q.GetValue().GetValue())
+				}
+			}
+		}
+		for _, m := range r.MedicationRequests {
+			prescriptions++
+			if _, ok := periods[m.GetEncounter().GetEncounterId().GetValue()]; !ok {
+				t.Errorf("prescription %s references an unknown encounter", m.GetId().GetValue())
+			}
+		}
+	}
+	if prescriptions == 0 {
+		t.Errorf("no prescriptions among 100 patients")
+	}
+}
@@ -0,0 +1,478 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/codes"
+	"github.com/google/fhir/go/igpackage"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/revalidate"
+	"github.com/google/fhir/go/terminology"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+)
+
+// CodeSystem is the code system of the codes of generated elements that are
+// not bound to a value set package terminology knows.
+const CodeSystem = "urn:fhirgen:code"
+
+const (
+	// maxDepth is the depth of nesting below which only required elements
+	// are generated, which keeps recursive types finite.
+	maxDepth = 4
+	// maxRepeats is the largest number of elements generated for repeated
+	// elements beyond their minimum.
+	maxRepeats = 3
+	// attempts is the number of resources Conformant generates before
+	// giving up on the invariants of a profile.
+	attempts = 50
+)
+
+// skipped are the elements that are only generated if required, as random
+// values of them would make no sense or break the resource.
+var skipped = map[string]bool{
+	"id":                true,
+	"extension":         true,
+	"modifierExtension": true,
+	"contained":         true,
+	"text":              true,
+	"meta":              true,
+	"implicitRules":     true,
+	"language":          true,
+}
+
+// Random returns a resource of resourceType with random values, with the
+// elements FHIR requires and a random subset of the others. Coded elements
+// have codes of their value sets, where the proto enumerates them, or of
+// CodeSystem. Random resources parse, but need not satisfy the invariants
+// of their type.
+func (g *Generator) Random(resourceType string) (*r4pb.ContainedResource, error) {
+	return g.shape(resourceType, nil)
+}
+
+// Conformant returns a random resource conforming to the profile sd, as by
+// Random but within its cardinalities, with the codes of its required and
+// extensible bindings to value sets known to package terminology and with
+// the types its choice elements allow. Resources are generated until one
+// satisfies the invariants of sd, up to a limit after which an error is
+// returned. Slices, fixed and pattern values are not generated.
+func (g *Generator) Conformant(sd *sdpb.StructureDefinition) (*r4pb.ContainedResource, error) {
+	constraints, err := igpackage.Constraints(sd)
+	if err != nil {
+		return nil, err
+	}
+	v, err := revalidate.NewValidator(constraints...)
+	if err != nil {
+		return nil, err
+	}
+	elems := map[string]*d4pb.ElementDefinition{}
+	list := sd.GetSnapshot().GetElement()
+	if len(list) == 0 {
+		list = sd.GetDifferential().GetElement()
+	}
+	for _, e := range list {
+		if e.GetSliceName() != nil || strings.Contains(e.GetId().GetValue(), ":") {
+			continue
+		}
+		elems[e.GetPath().GetValue()] = e
+	}
+	var last error
+	for i := 0; i < attempts; i++ {
+		cr, err := g.shape(sd.GetType().GetValue(), elems)
+		if err != nil {
+			return nil, err
+		}
+		r, err := v.Validate(elementpath.Unwrap(cr))
+		if err != nil {
+			return nil, err
+		}
+		if last = r.Err(); last == nil {
+			return cr, nil
+		}
+	}
+	return nil, fmt.Errorf("no resource conforming to %s in %d attempts: %w", sd.GetUrl().GetValue(), attempts, last)
+}
+
+// shaper fills resources with random values within the cardinalities and
+// bindings of the elements of a profile by path.
+type shaper struct {
+	g     *Generator
+	elems map[string]*d4pb.ElementDefinition
+}
+
+func (g *Generator) shape(resourceType string, elems map[string]*d4pb.ElementDefinition) (*r4pb.ContainedResource, error) {
+	cr := &r4pb.ContainedResource{}
+	m := cr.ProtoReflect()
+	od := m.Descriptor().Oneofs().ByName("oneof_resource")
+	var fd protoreflect.FieldDescriptor
+	for i := 0; i < od.Fields().Len(); i++ {
+		if f := od.Fields().Get(i); string(f.Message().Name()) == resourceType {
+			fd = f
+		}
+	}
+	if fd == nil {
+		return nil, fmt.Errorf("unknown resource type %q", resourceType)
+	}
+	s := &shaper{g: g, elems: elems}
+	res := m.Mutable(fd).Message()
+	s.fill(res, resourceType, 1)
+	res.Set(res.Descriptor().Fields().ByName("id"), protoreflect.ValueOfMessage((&d4pb.Id{Value: g.uuid()}).ProtoReflect()))
+	return cr, nil
+}
+
+// constrained reports whether the profile has elements nested in path.
+func (s *shaper) constrained(path string) bool {
+	for p := range s.elems {
+		if strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// cardinality returns the cardinality of the element at path of the field
+// fd, from the profile if it has one, or else from the proto, where max is
+// -1 for unbounded elements.
+func (s *shaper) cardinality(fd protoreflect.FieldDescriptor, path string) (min, max int) {
+	if proto.GetExtension(fd.Options(), apb.E_ValidationRequirement).(apb.Requirement) == apb.Requirement_REQUIRED_BY_FHIR {
+		min = 1
+	}
+	max = 1
+	if fd.IsList() {
+		max = -1
+	}
+	e := s.element(path)
+	if e == nil {
+		return min, max
+	}
+	if e.GetMin() != nil {
+		min = int(e.GetMin().GetValue())
+	}
+	switch m := e.GetMax().GetValue(); m {
+	case "", "*":
+	default:
+		if n, err := strconv.Atoi(m); err == nil && (max < 0 || n < max) {
+			max = n
+		}
+	}
+	return min, max
+}
+
+func (s *shaper) element(path string) *d4pb.ElementDefinition {
+	if e, ok := s.elems[path]; ok {
+		return e
+	}
+	return s.elems[path+"[x]"]
+}
+
+// fill sets random fields of the element m at path, at least one.
+func (s *shaper) fill(m protoreflect.Message, path string, depth int) {
+	fields := m.Descriptor().Fields()
+	var candidates []protoreflect.FieldDescriptor
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.ContainingOneof() != nil && !elementpath.IsChoice(m.Descriptor()) {
+			continue
+		}
+		name := fd.JSONName()
+		min, max := s.cardinality(fd, path+"."+name)
+		if max == 0 {
+			continue
+		}
+		n := min
+		if n == 0 && !skipped[name] && !unsupported(fd.Message()) {
+			candidates = append(candidates, fd)
+			if depth <= maxDepth && s.g.rnd.Float64() < 0.5/float64(depth) {
+				n = 1
+			}
+		}
+		if n > 0 && fd.IsList() {
+			extra := maxRepeats
+			if max > 0 && max-n < extra {
+				extra = max - n
+			}
+			if depth <= maxDepth && extra > 0 {
+				n += s.g.rnd.Intn(extra + 1)
+			}
+		}
+		s.set(m, fd, path+"."+name, n, depth)
+	}
+	if !populated(m) && len(candidates) > 0 {
+		fd := candidates[s.g.rnd.Intn(len(candidates))]
+		s.set(m, fd, path+"."+fd.JSONName(), 1, depth)
+	}
+}
+
+// unsupported reports whether elements of the type md are left out of
+// random resources.
+func unsupported(md protoreflect.MessageDescriptor) bool {
+	switch md.Name() {
+	case "Extension", "Narrative", "Xhtml", "ContainedResource", "Any":
+		return true
+	}
+	return elementpath.IsResource(md)
+}
+
+// set sets n random values of the field fd of m at path.
+func (s *shaper) set(m protoreflect.Message, fd protoreflect.FieldDescriptor, path string, n, depth int) {
+	if n == 0 || unsupported(fd.Message()) {
+		return
+	}
+	if !fd.IsList() {
+		v := m.NewField(fd).Message()
+		if s.value(v, path, depth+1) {
+			m.Set(fd, protoreflect.ValueOfMessage(v))
+		}
+		return
+	}
+	l := m.Mutable(fd).List()
+	for i := 0; i < n; i++ {
+		v := l.NewElement()
+		if s.value(v.Message(), path, depth+1) {
+			l.Append(v)
+		}
+	}
+	if l.Len() == 0 {
+		m.Clear(fd)
+	}
+}
+
+func populated(m protoreflect.Message) bool {
+	set := false
+	m.Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+		set = true
+		return false
+	})
+	return set
+}
+
+// value sets m, the element at path, to a random value, and reports whether
+// it could.
+func (s *shaper) value(m protoreflect.Message, path string, depth int) bool {
+	md := m.Descriptor()
+	vs := s.valueSet(path)
+	switch {
+	case elementpath.IsChoice(md):
+		return s.choice(m, path, depth)
+	case md.Fields().ByName("value") != nil && md.Fields().ByName("value").Enum() != nil:
+		return s.enum(m, vs)
+	case elementpath.IsPrimitive(md):
+		return s.primitive(m, vs)
+	}
+	if !s.constrained(path) {
+		if v := s.complex(md.Name(), vs); v != nil {
+			proto.Merge(m.Interface(), v)
+			return true
+		}
+	}
+	s.fill(m, path, depth)
+	return populated(m)
+}
+
+// valueSet returns the value set of the required or extensible binding of
+// the element at path, if package terminology knows it.
+func (s *shaper) valueSet(path string) *terminology.ValueSet {
+	b := s.element(path).GetBinding()
+	switch b.GetStrength().GetValue() {
+	case c4pb.BindingStrengthCode_REQUIRED, c4pb.BindingStrengthCode_EXTENSIBLE:
+	default:
+		return nil
+	}
+	vs, _ := terminology.Lookup(b.GetValueSet().GetValue())
+	return vs
+}
+
+// choice sets one of the types of the choice element m that the profile
+// allows.
+func (s *shaper) choice(m protoreflect.Message, path string, depth int) bool {
+	od := m.Descriptor().Oneofs().Get(0)
+	allowed := s.element(path).GetType()
+	var options []protoreflect.FieldDescriptor
+	for i := 0; i < od.Fields().Len(); i++ {
+		fd := od.Fields().Get(i)
+		if unsupported(fd.Message()) {
+			continue
+		}
+		ok := len(allowed) == 0
+		for _, t := range allowed {
+			ok = ok || strings.EqualFold(t.GetCode().GetValue(), fd.JSONName())
+		}
+		if ok {
+			options = append(options, fd)
+		}
+	}
+	if len(options) == 0 {
+		return false
+	}
+	fd := options[s.g.rnd.Intn(len(options))]
+	v := m.NewField(fd).Message()
+	if !s.value(v, path, depth) {
+		return false
+	}
+	m.Set(fd, protoreflect.ValueOfMessage(v))
+	return true
+}
+
+// enum sets the code enum of m to a random code, one of vs if it is not nil.
+func (s *shaper) enum(m protoreflect.Message, vs *terminology.ValueSet) bool {
+	fd := m.Descriptor().Fields().ByName("value")
+	var numbers []protoreflect.EnumNumber
+	if vs != nil {
+		for _, c := range vs.Codings() {
+			if n, err := codes.FromString(fd.Enum(), c.GetCode().GetValue()); err == nil {
+				numbers = append(numbers, n)
+			}
+		}
+	}
+	if len(numbers) == 0 {
+		values := fd.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			if n := values.Get(i).Number(); n != 0 {
+				numbers = append(numbers, n)
+			}
+		}
+	}
+	if len(numbers) == 0 {
+		return false
+	}
+	m.Set(fd, protoreflect.ValueOfEnum(numbers[s.g.rnd.Intn(len(numbers))]))
+	return true
+}
+
+// coding returns a random code of vs, or of CodeSystem if vs is nil.
+func (s *shaper) coding(vs *terminology.ValueSet) *d4pb.Coding {
+	if vs != nil {
+		if cs := vs.Codings(); len(cs) > 0 {
+			return cs[s.g.rnd.Intn(len(cs))]
+		}
+	}
+	n := s.g.rnd.Intn(1000)
+	return &d4pb.Coding{
+		System:  &d4pb.Uri{Value: CodeSystem},
+		Code:    &d4pb.Code{Value: fmt.Sprintf("code-%d", n)},
+		Display: &d4pb.String{Value: fmt.Sprintf("Code %d", n)},
+	}
+}
+
+// primitive sets the primitive m to a random value valid for its type.
+func (s *shaper) primitive(m protoreflect.Message, vs *terminology.ValueSet) bool {
+	g := s.g
+	now := g.opts.Now
+	t := now.Add(-time.Duration(g.rnd.Int63n(int64(time.Duration(g.opts.Years) * 365 * 24 * time.Hour)))).Truncate(time.Second)
+	var v proto.Message
+	switch md := m.Descriptor(); md.Name() {
+	case "Boolean":
+		v = &d4pb.Boolean{Value: g.rnd.Intn(2) == 0}
+	case "Integer":
+		v = &d4pb.Integer{Value: int32(g.rnd.Intn(2001) - 1000)}
+	case "PositiveInt":
+		v = &d4pb.PositiveInt{Value: uint32(1 + g.rnd.Intn(100))}
+	case "UnsignedInt":
+		v = &d4pb.UnsignedInt{Value: uint32(g.rnd.Intn(101))}
+	case "Decimal":
+		v = &d4pb.Decimal{Value: strconv.FormatFloat(g.rnd.Float64()*1000, 'f', 2, 64)}
+	case "String":
+		v = &d4pb.String{Value: g.pick(familyNames) + " " + g.pick(streets)}
+	case "Markdown":
+		v = &d4pb.Markdown{Value: "*" + g.pick(familyNames) + "*"}
+	case "Code":
+		v = &d4pb.Code{Value: s.coding(vs).GetCode().GetValue()}
+	case "Id":
+		v = &d4pb.Id{Value: g.uuid()}
+	case "Uri":
+		v = &d4pb.Uri{Value: "urn:uuid:" + g.uuid()}
+	case "Url":
+		v = &d4pb.Url{Value: "https://example.org/" + strings.ToLower(g.pick(familyNames))}
+	case "Canonical":
+		v = &d4pb.Canonical{Value: "http://example.org/fhir/StructureDefinition/" + strings.ToLower(g.pick(familyNames))}
+	case "Oid":
+		v = &d4pb.Oid{Value: fmt.Sprintf("urn:oid:1.2.3.%d", g.rnd.Intn(10000))}
+	case "Uuid":
+		v = &d4pb.Uuid{Value: "urn:uuid:" + g.uuid()}
+	case "Base64Binary":
+		b := make([]byte, 1+g.rnd.Intn(32))
+		g.rnd.Read(b)
+		v = &d4pb.Base64Binary{Value: b}
+	case "Date":
+		v = &d4pb.Date{ValueUs: t.Truncate(24 * time.Hour).UnixMicro(), Timezone: "UTC", Precision: d4pb.Date_DAY}
+	case "DateTime":
+		v = dateTime(t)
+	case "Instant":
+		v = &d4pb.Instant{ValueUs: t.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_SECOND}
+	case "Time":
+		v = &d4pb.Time{ValueUs: int64(g.rnd.Intn(24*3600)) * 1e6, Precision: d4pb.Time_SECOND}
+	default:
+		// Codes specialized by their element, such as
+		// Attachment.contentType, have messages of their own.
+		if fd := md.Fields().ByName("value"); fd != nil && fd.Kind() == protoreflect.StringKind {
+			m.Set(fd, protoreflect.ValueOfString(s.coding(vs).GetCode().GetValue()))
+			return true
+		}
+		return false
+	}
+	if v.ProtoReflect().Descriptor() != m.Descriptor() {
+		return false
+	}
+	proto.Merge(m.Interface(), v)
+	return true
+}
+
+// complex returns a realistic random value of the complex type name, one of
+// vs for coded types, or nil to fill it element by element.
+func (s *shaper) complex(name protoreflect.Name, vs *terminology.ValueSet) proto.Message {
+	g := s.g
+	switch name {
+	case "Coding":
+		return s.coding(vs)
+	case "CodeableConcept":
+		c := s.coding(vs)
+		return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{c}, Text: c.GetDisplay()}
+	case "Quantity":
+		m := []measurement{bodyHeight, bodyWeight, heartRate, bodyTemperature, glucose}[g.rnd.Intn(5)]
+		return m.quantity(g.rnd.Float64() * 200)
+	case "HumanName":
+		return &d4pb.HumanName{
+			Family: &d4pb.String{Value: g.pick(familyNames)},
+			Given:  []*d4pb.String{{Value: g.pick(append(femaleNames, maleNames...))}},
+		}
+	case "Identifier":
+		return &d4pb.Identifier{
+			System: &d4pb.Uri{Value: MRNSystem},
+			Value:  &d4pb.String{Value: fmt.Sprintf("MRN%08d", g.rnd.Intn(100000000))},
+		}
+	case "Period":
+		start := g.opts.Now.Add(-time.Duration(g.rnd.Int63n(int64(time.Duration(g.opts.Years) * 365 * 24 * time.Hour)))).Truncate(time.Second)
+		return &d4pb.Period{Start: dateTime(start), End: dateTime(start.Add(time.Duration(1+g.rnd.Intn(48*60)) * time.Minute))}
+	case "Reference":
+		return &d4pb.Reference{Display: &d4pb.String{Value: g.pick(familyNames)}}
+	case "Attachment":
+		return &d4pb.Attachment{
+			ContentType: &d4pb.Attachment_ContentTypeCode{Value: "text/plain"},
+			Data:        &d4pb.Base64Binary{Value: []byte(g.pick(streets))},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirgen
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+)
+
+const profileURL = "http://example.org/StructureDefinition/strict-observation"
+
+const profileJSON = `{
+  "resourceType": "StructureDefinition",
+  "url": "` + profileURL + `",
+  "name": "StrictObservation",
+  "status": "active",
+  "kind": "resource",
+  "abstract": false,
+  "type": "Observation",
+  "baseDefinition": "http://hl7.org/fhir/StructureDefinition/Observation",
+  "derivation": "constraint",
+  "differential": {
+    "element": [
+      {"id": "Observation", "path": "Observation"},
+      {
+        "id": "Observation.status",
+        "path": "Observation.status",
+        "binding": {"strength": "required", "valueSet": "http://example.org/ValueSet/unknown"}
+      },
+      {"id": "Observation.subject", "path": "Observation.subject", "min": 1},
+      {"id": "Observation.effective[x]", "path": "Observation.effective[x]", "min": 1, "type": [{"code": "dateTime"}]},
+      {"id": "Observation.value[x]", "path": "Observation.value[x]", "min": 1, "type": [{"code": "Quantity"}]},
+      {"id": "Observation.interpretation", "path": "Observation.interpretation", "max": "0"},
+      {
+        "id": "Observation.bodySite",
+        "path": "Observation.bodySite",
+        "min": 1,
+        "binding": {"strength": "required", "valueSet": "http://hl7.org/fhir/ValueSet/administrative-gender"}
+      },
+      {
+        "id": "Observation.note",
+        "path": "Observation.note",
+        "max": "1",
+        "constraint": [{
+          "key": "so-1",
+          "severity": "error",
+          "human": "Notes are not authored by references",
+          "expression": "author.exists().not()",
+          "source": "` + profileURL + `"
+        }]
+      }
+    ]
+  }
+}`
+
+func profile(t *testing.T) *sdpb.StructureDefinition {
+	t.Helper()
+	um, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshallerWithoutValidation() returned unexpected error: %v", err)
+	}
+	cr, err := um.UnmarshalR4([]byte(profileJSON))
+	if err != nil {
+		t.Fatalf("UnmarshalR4() returned unexpected error: %v", err)
+	}
+	return cr.GetStructureDefinition()
+}
+
+// checkValid fails t if cr does not survive a round trip through the
+// validating unmarshaller.
+func checkValid(t *testing.T, cr *r4pb.ContainedResource) {
+	t.Helper()
+	m, err := jsonformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	um, err := jsonformat.NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	data, err := m.Marshal(cr)
+	if err != nil {
+		t.Fatalf("Marshal() returned unexpected error: %v", err)
+	}
+	if _, err := um.UnmarshalR4(data); err != nil {
+		t.Errorf("UnmarshalR4(%s) returned unexpected error: %v", data, err)
+	}
+}
+
+func TestGenerator_Random(t *testing.T) {
+	types := []string{"Patient", "Observation", "Encounter", "Condition", "MedicationRequest", "Questionnaire", "Practitioner", "DiagnosticReport"}
+	g := New(3, Options{})
+	for _, typ := range types {
+		for i := 0; i < 20; i++ {
+			cr, err := g.Random(typ)
+			if err != nil {
+				t.Fatalf("Random(%q) returned unexpected error: %v", typ, err)
+			}
+			checkValid(t, cr)
+		}
+	}
+	a, err := New(5, Options{}).Random("Patient")
+	if err != nil {
+		t.Fatalf("Random() returned unexpected error: %v", err)
+	}
+	b, err := New(5, Options{}).Random("Patient")
+	if err != nil {
+		t.Fatalf("Random() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(a, b, protocmp.Transform()); diff != "" {
+		t.Errorf("Random() with the same seed diff (-first +second):\n%s", diff)
+	}
+	if _, err := g.Random("Unicorn"); err == nil {
+		t.Errorf("Random(%q) succeeded, want error", "Unicorn")
+	}
+}
+
+func TestGenerator_Conformant(t *testing.T) {
+	sd := profile(t)
+	g := New(11, Options{})
+	for i := 0; i < 50; i++ {
+		cr, err := g.Conformant(sd)
+		if err != nil {
+			t.Fatalf("Conformant() returned unexpected error: %v", err)
+		}
+		checkValid(t, cr)
+		obs := cr.GetObservation()
+		if obs.GetSubject() == nil {
+			t.Errorf("Conformant() returned observation without subject")
+		}
+		if obs.GetEffective().GetDateTime() == nil {
+			t.Errorf("Conformant() returned effective %v, want a dateTime", obs.GetEffective())
+		}
+		if obs.GetValue().GetQuantity() == nil {
+			t.Errorf("Conformant() returned value %v, want a Quantity", obs.GetValue())
+		}
+		if len(obs.GetInterpretation()) != 0 {
+			t.Errorf("Conformant() returned %d interpretations, want none", len(obs.GetInterpretation()))
+		}
+		if len(obs.GetNote()) > 1 {
+			t.Errorf("Conformant() returned %d notes, want at most 1", len(obs.GetNote()))
+		}
+		for _, n := range obs.GetNote() {
+			if n.GetAuthor() != nil {
+				t.Errorf("Conformant() returned note with author %v, violating so-1", n.GetAuthor())
+			}
+		}
+		if len(obs.GetBodySite().GetCoding()) == 0 {
+			t.Fatalf("Conformant() returned body site %v, want a coding", obs.GetBodySite())
+		}
+		if c := obs.GetBodySite().GetCoding()[0]; c.GetSystem().GetValue() != "http://hl7.org/fhir/administrative-gender" {
+			t.Errorf("Conformant() returned body site %v, want an administrative gender", c)
+		}
+		if obs.GetStatus().GetValue() == c4pb.ObservationStatusCode_INVALID_UNINITIALIZED {
+			t.Errorf("Conformant() returned observation without status")
+		}
+	}
+}
@@ -0,0 +1,640 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirpath
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+
+	// Registers the resource types held in google.protobuf.Any fields.
+	_ "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// env holds the state shared by a whole evaluation.
+type env struct {
+	vars     map[string]Collection
+	resolver Resolver
+	now      time.Time
+}
+
+// scope is the evaluation context of a (sub)expression. this is the focus
+// that identifiers and functions without an explicit target apply to.
+type scope struct {
+	env   *env
+	this  Collection
+	index int
+	total Collection
+	// iterating is set while evaluating the argument of where(), select() and
+	// similar functions, where $index is defined.
+	iterating bool
+}
+
+// item returns a scope focused on a single item of an iteration.
+func (s *scope) item(v interface{}, i int) *scope {
+	return &scope{env: s.env, this: Collection{v}, index: i, total: s.total, iterating: true}
+}
+
+func (n *literalNode) eval(*scope) (Collection, error) { return n.val, nil }
+
+func (n *variableNode) eval(s *scope) (Collection, error) {
+	v, ok := s.env.vars[n.name]
+	if !ok {
+		return nil, fmt.Errorf("undefined environment variable %%%s", n.name)
+	}
+	return v, nil
+}
+
+func (n *specialNode) eval(s *scope) (Collection, error) {
+	switch n.name {
+	case "$this":
+		return s.this, nil
+	case "$index":
+		if !s.iterating {
+			return nil, fmt.Errorf("$index used outside of an iteration")
+		}
+		return Collection{int64(s.index)}, nil
+	default:
+		return s.total, nil
+	}
+}
+
+func (n *memberNode) eval(s *scope) (Collection, error) {
+	focus := s.this
+	if n.target != nil {
+		var err error
+		if focus, err = n.target.eval(s); err != nil {
+			return nil, err
+		}
+	}
+	return navigate(focus, n.name), nil
+}
+
+func (n *callNode) eval(s *scope) (Collection, error) {
+	focus := s.this
+	if n.target != nil {
+		var err error
+		if focus, err = n.target.eval(s); err != nil {
+			return nil, err
+		}
+	}
+	fn, ok := functions[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s()", n.name)
+	}
+	if len(n.args) < fn.minArgs || len(n.args) > fn.maxArgs {
+		return nil, fmt.Errorf("%s() takes %d to %d arguments, got %d", n.name, fn.minArgs, fn.maxArgs, len(n.args))
+	}
+	res, err := fn.call(s, focus, n.args)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", n.name, err)
+	}
+	return res, nil
+}
+
+func (n *indexNode) eval(s *scope) (Collection, error) {
+	focus, err := n.target.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := n.index.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	v, ok, err := singleton(idx)
+	if err != nil || !ok {
+		return nil, err
+	}
+	i, isInt := v.(int64)
+	if !isInt {
+		return nil, fmt.Errorf("index must be an integer, got %s", typeOf(v))
+	}
+	if i < 0 || i >= int64(len(focus)) {
+		return nil, nil
+	}
+	return Collection{focus[i]}, nil
+}
+
+func (n *unaryNode) eval(s *scope) (Collection, error) {
+	c, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	v, ok, err := singleton(c)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if n.op == "+" {
+		return Collection{v}, nil
+	}
+	switch x := v.(type) {
+	case int64:
+		return Collection{-x}, nil
+	case float64:
+		return Collection{-x}, nil
+	case Quantity:
+		return Collection{Quantity{Value: -x.Value, Unit: x.Unit}}, nil
+	}
+	return nil, fmt.Errorf("cannot negate %s", typeOf(v))
+}
+
+func (n *typeNode) eval(s *scope) (Collection, error) {
+	c, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(c) == 0 {
+		return nil, nil
+	}
+	if len(c) > 1 {
+		return nil, fmt.Errorf("%q operator requires a single item, got %d", n.op, len(c))
+	}
+	match := isType(c[0], n.typ)
+	if n.op == "is" {
+		return Collection{match}, nil
+	}
+	if match {
+		return c, nil
+	}
+	return nil, nil
+}
+
+func (n *binaryNode) eval(s *scope) (Collection, error) {
+	left, err := n.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "and", "or", "xor", "implies":
+		return logical(n.op, left, func() (Collection, error) { return n.right.eval(s) })
+	}
+	right, err := n.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "|":
+		return union(left, right), nil
+	case "=", "!=":
+		eq, ok := equalCollections(left, right)
+		if !ok {
+			return nil, nil
+		}
+		return Collection{eq == (n.op == "=")}, nil
+	case "~", "!~":
+		return Collection{equivalentCollections(left, right) == (n.op == "~")}, nil
+	case "in":
+		return membership(left, right)
+	case "contains":
+		return membership(right, left)
+	case "&":
+		ls, err := concatOperand(left)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := concatOperand(right)
+		if err != nil {
+			return nil, err
+		}
+		return Collection{ls + rs}, nil
+	}
+	l, ok, err := singleton(left)
+	if err != nil || !ok {
+		return nil, err
+	}
+	r, ok, err := singleton(right)
+	if err != nil || !ok {
+		return nil, err
+	}
+	switch n.op {
+	case "<", ">", "<=", ">=":
+		c, ok, err := compareValues(l, r)
+		if err != nil || !ok {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return Collection{c < 0}, nil
+		case ">":
+			return Collection{c > 0}, nil
+		case "<=":
+			return Collection{c <= 0}, nil
+		}
+		return Collection{c >= 0}, nil
+	}
+	return arithmetic(n.op, l, r)
+}
+
+// navigate returns the children called name of each item in focus. A
+// capitalized name selects items that are resources of that type, which
+// allows paths such as "Patient.name" to start with the resource type.
+func navigate(focus Collection, name string) Collection {
+	var out Collection
+	for _, item := range focus {
+		m, ok := item.(proto.Message)
+		if !ok {
+			continue
+		}
+		if m = elementpath.Unwrap(m); m == nil {
+			continue
+		}
+		rm := m.ProtoReflect()
+		d := rm.Descriptor()
+		if name != "" && 'A' <= name[0] && name[0] <= 'Z' {
+			if isResource(m) && (TypeName(d) == name || name == "Resource" || name == "DomainResource") {
+				out = append(out, m)
+			}
+			continue
+		}
+		if ref, ok := m.(*d4pb.Reference); ok && name == "reference" {
+			if s := referenceString(ref); s != "" {
+				out = append(out, s)
+			}
+			continue
+		}
+		fd, choice, err := elementpath.LookupField(d, name)
+		if err != nil {
+			continue
+		}
+		if fd.IsList() {
+			l := rm.Get(fd).List()
+			for i := 0; i < l.Len(); i++ {
+				out = appendElement(out, l.Get(i).Message(), choice)
+			}
+		} else if rm.Has(fd) {
+			out = appendElement(out, rm.Get(fd).Message(), choice)
+		}
+	}
+	return out
+}
+
+// appendElement appends the element held in rm, unwrapping choice types,
+// google.protobuf.Any and ContainedResource.
+func appendElement(out Collection, rm protoreflect.Message, choice string) Collection {
+	if elementpath.IsChoice(rm.Descriptor()) {
+		set := rm.WhichOneof(rm.Descriptor().Oneofs().Get(0))
+		if set == nil || (choice != "" && set.JSONName() != choice) {
+			return out
+		}
+		rm = rm.Get(set).Message()
+	}
+	m := rm.Interface()
+	if a, ok := m.(*anypb.Any); ok {
+		var err error
+		if m, err = a.UnmarshalNew(); err != nil {
+			return out
+		}
+	}
+	if m = elementpath.Unwrap(m); m == nil {
+		return out
+	}
+	return append(out, m)
+}
+
+// children returns all child elements of the items in focus.
+func children(focus Collection) Collection {
+	var out Collection
+	for _, item := range focus {
+		m, ok := item.(proto.Message)
+		if !ok {
+			continue
+		}
+		m.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			if fd.Message() == nil {
+				return true
+			}
+			if fd.IsList() {
+				for i := 0; i < v.List().Len(); i++ {
+					out = appendElement(out, v.List().Get(i).Message(), "")
+				}
+			} else {
+				out = appendElement(out, v.Message(), "")
+			}
+			return true
+		})
+	}
+	return out
+}
+
+// referenceString returns the literal reference of ref, i.e. "Patient/1".
+func referenceString(ref *d4pb.Reference) string {
+	if f := ref.GetFragment(); f != nil {
+		return "#" + f.GetValue()
+	}
+	den, err := jsonformat.NewDenormalizedReference(ref)
+	if err != nil {
+		return ""
+	}
+	r := den.(*d4pb.Reference)
+	if f := r.GetFragment(); f != nil {
+		return "#" + f.GetValue()
+	}
+	return r.GetUri().GetValue()
+}
+
+func isResource(m proto.Message) bool {
+	return elementpath.IsResource(m.ProtoReflect().Descriptor())
+}
+
+// isType reports whether item is of the type named by spec, which may be
+// qualified with the System or FHIR namespace.
+func isType(item interface{}, spec string) bool {
+	ns, name, qualified := strings.Cut(spec, ".")
+	if !qualified {
+		ns, name = "", spec
+	}
+	if m, ok := item.(proto.Message); ok {
+		if ns == "System" {
+			return false
+		}
+		tn := TypeName(m.ProtoReflect().Descriptor())
+		switch {
+		case tn == name:
+			return true
+		case name == "Resource" || name == "DomainResource":
+			return isResource(m)
+		case name == "Quantity":
+			return quantityTypes[tn]
+		}
+		return false
+	}
+	if ns == "FHIR" {
+		return false
+	}
+	return strings.TrimPrefix(typeOf(item), "System.") == name
+}
+
+// singleton returns the only item of c converted to a system value. ok is
+// false if c is empty.
+func singleton(c Collection) (interface{}, bool, error) {
+	switch len(c) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		v, ok := toSystem(c[0])
+		return v, ok, nil
+	}
+	return nil, false, fmt.Errorf("expected a single item, got %d", len(c))
+}
+
+// singletonBool applies the FHIRPath singleton evaluation of collections to
+// c in a boolean context.
+func singletonBool(c Collection) (value, ok bool, err error) {
+	v, ok, err := singleton(c)
+	if err != nil || !ok {
+		return false, false, err
+	}
+	if b, isBool := v.(bool); isBool {
+		return b, true, nil
+	}
+	return true, true, nil
+}
+
+func logical(op string, left Collection, rightFn func() (Collection, error)) (Collection, error) {
+	l, lok, err := singletonBool(left)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit where the right operand cannot change the result.
+	switch {
+	case op == "and" && lok && !l, op == "or" && lok && l:
+		return Collection{l}, nil
+	case op == "implies" && lok && !l:
+		return Collection{true}, nil
+	}
+	right, err := rightFn()
+	if err != nil {
+		return nil, err
+	}
+	r, rok, err := singletonBool(right)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "and":
+		if rok && !r {
+			return Collection{false}, nil
+		}
+		if lok && rok {
+			return Collection{true}, nil
+		}
+	case "or":
+		if rok && r {
+			return Collection{true}, nil
+		}
+		if lok && rok {
+			return Collection{false}, nil
+		}
+	case "xor":
+		if lok && rok {
+			return Collection{l != r}, nil
+		}
+	case "implies":
+		if rok && r {
+			return Collection{true}, nil
+		}
+		if lok && rok {
+			return Collection{false}, nil
+		}
+	}
+	return nil, nil
+}
+
+func contains(c Collection, v interface{}) bool {
+	for _, item := range c {
+		if eq, _ := equalItems(item, v); eq {
+			return true
+		}
+	}
+	return false
+}
+
+func distinct(c Collection) Collection {
+	var out Collection
+	for _, item := range c {
+		if !contains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func union(a, b Collection) Collection {
+	return distinct(append(append(Collection{}, a...), b...))
+}
+
+func equalCollections(a, b Collection) (bool, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return false, false
+	}
+	if len(a) != len(b) {
+		return false, true
+	}
+	for i := range a {
+		eq, ok := equalItems(a[i], b[i])
+		if !ok {
+			return false, false
+		}
+		if !eq {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+func equivalentCollections(a, b Collection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, x := range a {
+		found := false
+		for j, y := range b {
+			if !used[j] && equivalentItems(x, y) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func membership(item, coll Collection) (Collection, error) {
+	if len(item) == 0 {
+		return nil, nil
+	}
+	if len(item) > 1 {
+		return nil, fmt.Errorf("membership operator requires a single item, got %d", len(item))
+	}
+	return Collection{contains(coll, item[0])}, nil
+}
+
+func concatOperand(c Collection) (string, error) {
+	v, ok, err := singleton(c)
+	if err != nil || !ok {
+		return "", err
+	}
+	s, isString := v.(string)
+	if !isString {
+		return "", fmt.Errorf("& requires strings, got %s", typeOf(v))
+	}
+	return s, nil
+}
+
+func arithmetic(op string, l, r interface{}) (Collection, error) {
+	switch x := l.(type) {
+	case string:
+		if y, ok := r.(string); ok && op == "+" {
+			return Collection{x + y}, nil
+		}
+	case Temporal:
+		if q, ok := r.(Quantity); ok && (op == "+" || op == "-") {
+			if op == "-" {
+				q.Value = -q.Value
+			}
+			t, err := addDuration(x, q)
+			if err != nil {
+				return nil, err
+			}
+			return Collection{t}, nil
+		}
+	case Quantity:
+		switch y := r.(type) {
+		case Quantity:
+			if (op == "+" || op == "-") && canonicalUnit(x.Unit) == canonicalUnit(y.Unit) {
+				res, err := numeric(op, x.Value, y.Value)
+				if err != nil || len(res) == 0 {
+					return nil, err
+				}
+				v, _ := toFloat(res[0])
+				return Collection{Quantity{Value: v, Unit: x.Unit}}, nil
+			}
+		case int64, float64:
+			if op == "*" || op == "/" {
+				f, _ := toFloat(y)
+				res, err := numeric(op, x.Value, f)
+				if err != nil || len(res) == 0 {
+					return nil, err
+				}
+				v, _ := toFloat(res[0])
+				return Collection{Quantity{Value: v, Unit: x.Unit}}, nil
+			}
+		}
+	case int64:
+		if y, ok := r.(int64); ok {
+			switch op {
+			case "+":
+				return Collection{x + y}, nil
+			case "-":
+				return Collection{x - y}, nil
+			case "*":
+				return Collection{x * y}, nil
+			case "div":
+				if y == 0 {
+					return nil, nil
+				}
+				return Collection{x / y}, nil
+			case "mod":
+				if y == 0 {
+					return nil, nil
+				}
+				return Collection{x % y}, nil
+			}
+		}
+		if y, ok := toFloat(r); ok {
+			return numeric(op, float64(x), y)
+		}
+	case float64:
+		if y, ok := toFloat(r); ok {
+			return numeric(op, x, y)
+		}
+	}
+	return nil, fmt.Errorf("operator %s is not defined for %s and %s", op, typeOf(l), typeOf(r))
+}
+
+func numeric(op string, x, y float64) (Collection, error) {
+	switch op {
+	case "+":
+		return Collection{x + y}, nil
+	case "-":
+		return Collection{x - y}, nil
+	case "*":
+		return Collection{x * y}, nil
+	}
+	if y == 0 {
+		return nil, nil
+	}
+	switch op {
+	case "/":
+		return Collection{x / y}, nil
+	case "div":
+		return Collection{int64(math.Trunc(x / y))}, nil
+	case "mod":
+		return Collection{math.Mod(x, y)}, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fhirpath evaluates FHIRPath expressions against FHIR R4 protos.
+//
+// Expressions are compiled once and can be evaluated any number of times:
+//
+//	expr, err := fhirpath.Compile("Patient.name.where(use = 'official').given.first()")
+//	...
+//	got, err := expr.Evaluate(patient)
+//
+// Element names are FHIR JSON names. Navigating into a FHIR element returns
+// the proto message, so results can be used directly in other protos;
+// operators and functions convert primitives to the FHIRPath system types as
+// needed. Codes backed by proto enums are reported as their FHIR code string.
+// Reference.reference is computed from the normalized reference fields.
+//
+// The implementation covers the FHIRPath normative functions and operators
+// that do not depend on a terminology service or on model information beyond
+// the protos themselves. resolve() requires a Resolver to be configured.
+package fhirpath
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Resolver returns the resource identified by a reference string, i.e.
+// "Patient/123" or "#contained". It returns nil if the reference cannot be
+// resolved.
+type Resolver func(reference string) (proto.Message, error)
+
+// Expression is a compiled FHIRPath expression.
+type Expression struct {
+	src  string
+	root node
+}
+
+// Compile parses a FHIRPath expression.
+func Compile(expr string) (*Expression, error) {
+	root, err := parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing FHIRPath %q: %w", expr, err)
+	}
+	return &Expression{src: expr, root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed.
+func MustCompile(expr string) *Expression {
+	e, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string { return e.src }
+
+// EvaluateOption configures an evaluation.
+type EvaluateOption func(*env)
+
+// WithVariable binds the environment variable %name to value.
+func WithVariable(name string, value Collection) EvaluateOption {
+	return func(e *env) { e.vars[name] = value }
+}
+
+// WithResource sets %resource and %rootResource, which otherwise default to
+// the evaluation input when it is a single resource.
+func WithResource(res proto.Message) EvaluateOption {
+	return func(e *env) {
+		e.vars["resource"] = Collection{res}
+		e.vars["rootResource"] = Collection{res}
+	}
+}
+
+// WithResolver sets the function used by resolve().
+func WithResolver(r Resolver) EvaluateOption {
+	return func(e *env) { e.resolver = r }
+}
+
+// WithNow fixes the time returned by now(), today() and timeOfDay().
+func WithNow(t time.Time) EvaluateOption {
+	return func(e *env) { e.now = t }
+}
+
+// Evaluate evaluates the expression with input as its focus. input may be a
+// proto.Message, a system value or a Collection.
+func (e *Expression) Evaluate(input interface{}, opts ...EvaluateOption) (Collection, error) {
+	var focus Collection
+	switch x := input.(type) {
+	case nil:
+	case Collection:
+		focus = x
+	default:
+		focus = Collection{x}
+	}
+	en := &env{
+		vars: map[string]Collection{
+			"ucum":    {"http://unitsofmeasure.org"},
+			"sct":     {"http://snomed.info/sct"},
+			"loinc":   {"http://loinc.org"},
+			"context": focus,
+		},
+		now: time.Now(),
+	}
+	if len(focus) == 1 {
+		if m, ok := focus[0].(proto.Message); ok && isResource(m) {
+			en.vars["resource"] = focus
+			en.vars["rootResource"] = focus
+		}
+	}
+	for _, opt := range opts {
+		opt(en)
+	}
+	res, err := e.root.eval(&scope{env: en, this: focus})
+	if err != nil {
+		return nil, fmt.Errorf("evaluating FHIRPath %q: %w", e.src, err)
+	}
+	return res, nil
+}
+
+// EvaluateBool evaluates the expression and converts the result to a
+// boolean. An empty result is reported as false. It is an error for the
+// result to contain more than one item.
+func (e *Expression) EvaluateBool(input interface{}, opts ...EvaluateOption) (bool, error) {
+	res, err := e.Evaluate(input, opts...)
+	if err != nil {
+		return false, err
+	}
+	b, ok, err := singletonBool(res)
+	if err != nil {
+		return false, fmt.Errorf("evaluating FHIRPath %q: %w", e.src, err)
+	}
+	return ok && b, nil
+}
+
+// StringValue returns the string form of the single item in c. ok is false
+// if c does not hold exactly one item with a string representation.
+func (c Collection) StringValue() (s string, ok bool) {
+	if len(c) != 1 {
+		return "", false
+	}
+	v, ok := toSystem(c[0])
+	if !ok {
+		return "", false
+	}
+	return formatValue(v)
+}
@@ -0,0 +1,315 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirpath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func testPatient() *ppb.Patient {
+	return &ppb.Patient{
+		Id: &d4pb.Id{Value: "p1"},
+		Extension: []*d4pb.Extension{{
+			Url:   &d4pb.Uri{Value: "http://example.com/ext"},
+			Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_StringValue{StringValue: &d4pb.String{Value: "x"}}},
+		}},
+		Active: &d4pb.Boolean{Value: true},
+		Name: []*d4pb.HumanName{{
+			Use:    &d4pb.HumanName_UseCode{Value: c4pb.NameUseCode_OFFICIAL},
+			Family: &d4pb.String{Value: "Doe"},
+			Given:  []*d4pb.String{{Value: "Jane"}, {Value: "Q"}},
+		}, {
+			Use:   &d4pb.HumanName_UseCode{Value: c4pb.NameUseCode_NICKNAME},
+			Given: []*d4pb.String{{Value: "JJ"}},
+		}},
+		Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+		BirthDate: &d4pb.Date{ValueUs: 321926400000000, Timezone: "UTC", Precision: d4pb.Date_DAY},
+		GeneralPractitioner: []*d4pb.Reference{
+			{Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: "dr1"}}},
+		},
+	}
+}
+
+func testObservation() *obspb.Observation {
+	return &obspb.Observation{
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: "http://loinc.org"},
+			Code:   &d4pb.Code{Value: "2345-7"},
+		}}},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+			Value: &d4pb.Decimal{Value: "98.50"},
+			Code:  &d4pb.Code{Value: "mg/dL"},
+		}}},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr  string
+		input interface{}
+		want  Collection
+	}{
+		{"Patient.name.given", testPatient(), Collection{&d4pb.String{Value: "Jane"}, &d4pb.String{Value: "Q"}, &d4pb.String{Value: "JJ"}}},
+		{"name.where(use = 'official').given.first()", testPatient(), Collection{&d4pb.String{Value: "Jane"}}},
+		{"Patient.name[1].given", testPatient(), Collection{&d4pb.String{Value: "JJ"}}},
+		{"Observation.name", testPatient(), nil},
+		{"Patient.gender = 'female'", testPatient(), Collection{true}},
+		{"Patient.gender", testPatient(), Collection{&ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE}}},
+		{"Patient.birthDate < @2000-01-01", testPatient(), Collection{true}},
+		{"Patient.birthDate = @1980-03-15", testPatient(), Collection{true}},
+		{"Patient.birthDate = @1980-03", testPatient(), nil},
+		{"Patient.name.given.count()", testPatient(), Collection{int64(3)}},
+		{"Patient.name.family | Patient.name.given.first()", testPatient(), Collection{&d4pb.String{Value: "Doe"}, &d4pb.String{Value: "Jane"}}},
+		{"Patient.name.given.exists($this = 'Q')", testPatient(), Collection{true}},
+		{"Patient.name.select(given.first() & ' ' & family)", testPatient(), Collection{"Jane Doe", "JJ "}},
+		{"Patient.generalPractitioner.reference", testPatient(), Collection{"Practitioner/dr1"}},
+		{"Patient.extension('http://example.com/ext').value", testPatient(), Collection{&d4pb.String{Value: "x"}}},
+		{"Patient.extension('http://example.com/other').exists()", testPatient(), Collection{false}},
+		{"Patient.active and Patient.deceased.empty()", testPatient(), Collection{true}},
+		{"Patient.active.not()", testPatient(), Collection{false}},
+		{"Patient.id.length()", testPatient(), Collection{int64(2)}},
+		{"Patient.name.family.upper() + '!'", testPatient(), Collection{"DOE!"}},
+		{"Observation.value.value > 98", testObservation(), Collection{true}},
+		{"Observation.value > 90 'mg/dL'", testObservation(), Collection{true}},
+		{"Observation.valueQuantity.code", testObservation(), Collection{&d4pb.Code{Value: "mg/dL"}}},
+		{"Observation.valueString.exists()", testObservation(), Collection{false}},
+		{"Observation.value is Quantity", testObservation(), Collection{true}},
+		{"Observation.value.ofType(Quantity).value", testObservation(), Collection{&d4pb.Decimal{Value: "98.50"}}},
+		{"Observation.code.coding.where(system = %loinc).code", testObservation(), Collection{&d4pb.Code{Value: "2345-7"}}},
+		{"Observation.status", testObservation(), Collection{&obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL}}},
+		{"Observation.subject.reference", testObservation(), Collection{"Patient/p1"}},
+		{"%resource.id.exists()", testObservation(), Collection{false}},
+		{"1 + 2 * 3", nil, Collection{int64(7)}},
+		{"(1 + 2) * 3", nil, Collection{int64(9)}},
+		{"7 div 2", nil, Collection{int64(3)}},
+		{"7 mod 2", nil, Collection{int64(1)}},
+		{"7 / 2", nil, Collection{3.5}},
+		{"1 / 0", nil, nil},
+		{"-5.abs()", nil, Collection{int64(-5)}},
+		{"(-5).abs()", nil, Collection{int64(5)}},
+		{"2.5.round()", nil, Collection{3.0}},
+		{"3.7.floor()", nil, Collection{int64(3)}},
+		{"2.power(10)", nil, Collection{int64(1024)}},
+		{"'abc' ~ 'ABC'", nil, Collection{true}},
+		{"'abc' = 'ABC'", nil, Collection{false}},
+		{"1.0 = 1", nil, Collection{true}},
+		{"{} = 1", nil, nil},
+		{"{}.empty()", nil, Collection{true}},
+		{"true and {}", nil, nil},
+		{"false and {}", nil, Collection{false}},
+		{"true or {}", nil, Collection{true}},
+		{"false implies {}", nil, Collection{true}},
+		{"true xor false", nil, Collection{true}},
+		{"2 in (1 | 2 | 3)", nil, Collection{true}},
+		{"(1 | 2 | 3) contains 4", nil, Collection{false}},
+		{"(1 | 2 | 2 | 3).count()", nil, Collection{int64(3)}},
+		{"(1 | 2).combine(2 | 3).count()", nil, Collection{int64(4)}},
+		{"(1 | 2 | 3).where($this > 1).select($this * 10)", nil, Collection{int64(20), int64(30)}},
+		{"(1 | 2 | 3).aggregate($this + $total, 0)", nil, Collection{int64(6)}},
+		{"(1 | 2 | 3).select($index)", nil, Collection{int64(0), int64(1), int64(2)}},
+		{"(1 | 2 | 3).skip(1).take(1)", nil, Collection{int64(2)}},
+		{"(1 | 2 | 3).tail().last()", nil, Collection{int64(3)}},
+		{"(1 | 2 | 3).intersect(2 | 4)", nil, Collection{int64(2)}},
+		{"(1 | 2 | 3).exclude(2)", nil, Collection{int64(1), int64(3)}},
+		{"(1 | 2).subsetOf(1 | 2 | 3)", nil, Collection{true}},
+		{"(true | false).anyTrue()", nil, Collection{true}},
+		{"(true | false).allTrue()", nil, Collection{false}},
+		{"iif(1 > 2, 'a', 'b')", nil, Collection{"b"}},
+		{"'a,b,c'.split(',').join('-')", nil, Collection{"a-b-c"}},
+		{"'hello'.substring(1, 3)", nil, Collection{"ell"}},
+		{"'hello'.indexOf('l')", nil, Collection{int64(2)}},
+		{"'hello'.replace('l', 'L')", nil, Collection{"heLLo"}},
+		{"'hello'.matches('^h.*o$')", nil, Collection{true}},
+		{"'hello'.replaceMatches('[aeiou]', '_')", nil, Collection{"h_ll_"}},
+		{"'a\\'b'", nil, Collection{"a'b"}},
+		{"'12'.toInteger() + 1", nil, Collection{int64(13)}},
+		{"'1.5'.toDecimal()", nil, Collection{1.5}},
+		{"'yes'.toBoolean()", nil, Collection{true}},
+		{"'abc'.convertsToInteger()", nil, Collection{false}},
+		{"5.toString()", nil, Collection{"5"}},
+		{"'2020-01-15'.toDate() = @2020-01-15", nil, Collection{true}},
+		{"@2020-01-31 + 1 month", nil, Collection{Temporal{Kind: Date, Time: time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC), Precision: PrecisionDay}}},
+		{"@2020-01-01T10:00:00Z < @2020-01-01T11:00:00+00:30", nil, Collection{true}},
+		{"@T10:00 < @T11:00", nil, Collection{true}},
+		{"today()", nil, Collection{Temporal{Kind: Date, Time: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), Precision: PrecisionDay}}},
+		{"today() - 18 years", nil, Collection{Temporal{Kind: Date, Time: time.Date(2008, 5, 4, 0, 0, 0, 0, time.UTC), Precision: PrecisionDay}}},
+		{"now() > @2026-01-01T00:00:00Z", nil, Collection{true}},
+		{"5 'mg' = 5 'mg'", nil, Collection{true}},
+		{"'5 mg'.toQuantity()", nil, nil},
+		{"'5 \\'mg\\''.toQuantity() = 5 'mg'", nil, Collection{true}},
+		{"1 is Integer", nil, Collection{true}},
+		{"1 is System.Decimal", nil, Collection{false}},
+		{"'a' as String", nil, Collection{"a"}},
+		{"%ucum", nil, Collection{"http://unitsofmeasure.org"}},
+		{"// comment\n 1 /* more */ + 1", nil, Collection{int64(2)}},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			expr, err := Compile(test.expr)
+			if err != nil {
+				t.Fatalf("Compile(%q) returned unexpected error: %v", test.expr, err)
+			}
+			got, err := expr.Evaluate(test.input, WithNow(now))
+			if err != nil {
+				t.Fatalf("Evaluate() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Evaluate(%q) diff (-want +got):\n%s", test.expr, diff)
+			}
+		})
+	}
+}
+
+func TestEvaluate_ContainedResource(t *testing.T) {
+	cr := &bcrpb.ContainedResource{OneofResource: &bcrpb.ContainedResource_Patient{Patient: testPatient()}}
+	got, err := MustCompile("Patient.name.family").Evaluate(navigate(Collection{&bcrpb.Bundle_Entry{Resource: cr}}, "resource"))
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Collection{&d4pb.String{Value: "Doe"}}, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Evaluate() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvaluate_Variables(t *testing.T) {
+	got, err := MustCompile("%patient.name.family & '/' & %n").Evaluate(nil,
+		WithVariable("patient", Collection{testPatient()}), WithVariable("n", Collection{"x"}))
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	if s, ok := got.StringValue(); !ok || s != "Doe/x" {
+		t.Errorf("Evaluate() = %v, want Doe/x", got)
+	}
+}
+
+func TestEvaluate_Resolve(t *testing.T) {
+	dr := &ppb.Patient{Id: &d4pb.Id{Value: "dr1"}}
+	resolver := func(ref string) (proto.Message, error) {
+		if ref == "Practitioner/dr1" {
+			return dr, nil
+		}
+		return nil, nil
+	}
+	got, err := MustCompile("Patient.generalPractitioner.resolve().id").Evaluate(testPatient(), WithResolver(resolver))
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Collection{&d4pb.Id{Value: "dr1"}}, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Evaluate() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvaluateBool(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"Patient.active", true},
+		{"Patient.deceased", false},
+		{"Patient.name.exists(family = 'Doe')", true},
+	}
+	for _, test := range tests {
+		got, err := MustCompile(test.expr).EvaluateBool(testPatient())
+		if err != nil {
+			t.Fatalf("EvaluateBool(%q) returned unexpected error: %v", test.expr, err)
+		}
+		if got != test.want {
+			t.Errorf("EvaluateBool(%q) = %v, want %v", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestProtoValue(t *testing.T) {
+	tests := []struct {
+		expr string
+		want proto.Message
+	}{
+		{"true", &d4pb.Boolean{Value: true}},
+		{"'a'", &d4pb.String{Value: "a"}},
+		{"42", &d4pb.Integer{Value: 42}},
+		{"1.25", &d4pb.Decimal{Value: "1.25"}},
+		{"3 'mg'", &d4pb.Quantity{
+			Value:  &d4pb.Decimal{Value: "3"},
+			Unit:   &d4pb.String{Value: "mg"},
+			System: &d4pb.Uri{Value: "http://unitsofmeasure.org"},
+			Code:   &d4pb.Code{Value: "mg"},
+		}},
+		{"@2020-03", &d4pb.Date{ValueUs: 1583020800000000, Timezone: "Z", Precision: d4pb.Date_MONTH}},
+		{"@2020-03-01T10:00:00Z", &d4pb.DateTime{ValueUs: 1583056800000000, Timezone: "Z", Precision: d4pb.DateTime_SECOND}},
+		{"@T10:30", &d4pb.Time{ValueUs: 37800000000, Precision: d4pb.Time_SECOND}},
+	}
+	for _, test := range tests {
+		res, err := MustCompile(test.expr).Evaluate(nil)
+		if err != nil || len(res) != 1 {
+			t.Fatalf("Evaluate(%q) = %v, %v, want a single value", test.expr, res, err)
+		}
+		got, ok := ProtoValue(res[0])
+		if !ok {
+			t.Fatalf("ProtoValue(%v) failed", res[0])
+		}
+		if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("ProtoValue(%q) diff (-want +got):\n%s", test.expr, diff)
+		}
+	}
+}
+
+func TestEvaluate_Errors(t *testing.T) {
+	tests := []string{
+		"Patient.name.given.single()",
+		"Patient.name.family + 1",
+		"%undefined",
+		"unknownFunction()",
+		"Patient.name.given.substring('a')",
+		"Patient.generalPractitioner.resolve()",
+	}
+	for _, expr := range tests {
+		if got, err := MustCompile(expr).Evaluate(testPatient()); err == nil {
+			t.Errorf("Evaluate(%q) = %v, want error", expr, got)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []string{
+		"Patient.",
+		"(1 + 2",
+		"'unterminated",
+		"1 +",
+		"Patient.name[0",
+		"@2020-13-45T",
+		"a ^ b",
+		"$unknown",
+		"1 is",
+	}
+	for _, expr := range tests {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("Compile(%q) succeeded, want error", expr)
+		}
+	}
+}
@@ -0,0 +1,966 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirpath
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// function implements a FHIRPath function. Arguments are passed unevaluated
+// so that functions such as where() can evaluate them once per item.
+type function struct {
+	minArgs, maxArgs int
+	call             func(s *scope, focus Collection, args []node) (Collection, error)
+}
+
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		// Existence.
+		"empty":      {0, 0, func(_ *scope, f Collection, _ []node) (Collection, error) { return Collection{len(f) == 0}, nil }},
+		"exists":     {0, 1, fnExists},
+		"all":        {1, 1, fnAll},
+		"allTrue":    {0, 0, boolAggregate(true, true)},
+		"anyTrue":    {0, 0, boolAggregate(false, true)},
+		"allFalse":   {0, 0, boolAggregate(true, false)},
+		"anyFalse":   {0, 0, boolAggregate(false, false)},
+		"subsetOf":   {1, 1, fnSubsetOf},
+		"supersetOf": {1, 1, fnSupersetOf},
+		"count":      {0, 0, func(_ *scope, f Collection, _ []node) (Collection, error) { return Collection{int64(len(f))}, nil }},
+		"distinct":   {0, 0, func(_ *scope, f Collection, _ []node) (Collection, error) { return distinct(f), nil }},
+		"isDistinct": {0, 0, func(_ *scope, f Collection, _ []node) (Collection, error) {
+			return Collection{len(distinct(f)) == len(f)}, nil
+		}},
+		// Filtering and projection.
+		"where":     {1, 1, fnWhere},
+		"select":    {1, 1, fnSelect},
+		"repeat":    {1, 1, fnRepeat},
+		"ofType":    {1, 1, fnOfType},
+		"aggregate": {1, 2, fnAggregate},
+		// Subsetting.
+		"single":    {0, 0, fnSingle},
+		"first":     {0, 0, func(_ *scope, f Collection, _ []node) (Collection, error) { return slice(f, 0, 1), nil }},
+		"last":      {0, 0, func(_ *scope, f Collection, _ []node) (Collection, error) { return slice(f, len(f)-1, len(f)), nil }},
+		"tail":      {0, 0, func(_ *scope, f Collection, _ []node) (Collection, error) { return slice(f, 1, len(f)), nil }},
+		"skip":      {1, 1, fnSkip},
+		"take":      {1, 1, fnTake},
+		"intersect": {1, 1, fnIntersect},
+		"exclude":   {1, 1, fnExclude},
+		// Combining.
+		"union":   {1, 1, fnUnion},
+		"combine": {1, 1, fnCombine},
+		// Conversion.
+		"iif":                {2, 3, fnIif},
+		"toBoolean":          {0, 0, conversion(toBoolean)},
+		"convertsToBoolean":  {0, 0, convertsTo(toBoolean)},
+		"toInteger":          {0, 0, conversion(toInteger)},
+		"convertsToInteger":  {0, 0, convertsTo(toInteger)},
+		"toDecimal":          {0, 0, conversion(toDecimal)},
+		"convertsToDecimal":  {0, 0, convertsTo(toDecimal)},
+		"toString":           {0, 0, conversion(toStringValue)},
+		"convertsToString":   {0, 0, convertsTo(toStringValue)},
+		"toDate":             {0, 0, conversion(toTemporal(Date))},
+		"convertsToDate":     {0, 0, convertsTo(toTemporal(Date))},
+		"toDateTime":         {0, 0, conversion(toTemporal(DateTime))},
+		"convertsToDateTime": {0, 0, convertsTo(toTemporal(DateTime))},
+		"toTime":             {0, 0, conversion(toTemporal(Time))},
+		"convertsToTime":     {0, 0, convertsTo(toTemporal(Time))},
+		"toQuantity":         {0, 1, conversion(toQuantity)},
+		"convertsToQuantity": {0, 1, convertsTo(toQuantity)},
+		// Strings.
+		"indexOf":        {1, 1, stringFunc(fnIndexOf)},
+		"substring":      {1, 2, stringFunc(fnSubstring)},
+		"startsWith":     {1, 1, stringFunc(stringPredicate(strings.HasPrefix))},
+		"endsWith":       {1, 1, stringFunc(stringPredicate(strings.HasSuffix))},
+		"contains":       {1, 1, stringFunc(stringPredicate(strings.Contains))},
+		"upper":          {0, 0, stringFunc(stringMap(strings.ToUpper))},
+		"lower":          {0, 0, stringFunc(stringMap(strings.ToLower))},
+		"trim":           {0, 0, stringFunc(stringMap(strings.TrimSpace))},
+		"replace":        {2, 2, stringFunc(fnReplace)},
+		"matches":        {1, 1, stringFunc(fnMatches)},
+		"replaceMatches": {2, 2, stringFunc(fnReplaceMatches)},
+		"length":         {0, 0, stringFunc(fnLength)},
+		"toChars":        {0, 0, stringFunc(fnToChars)},
+		"split":          {1, 1, stringFunc(fnSplit)},
+		"join":           {0, 1, fnJoin},
+		// Math.
+		"abs":      {0, 0, fnAbs},
+		"ceiling":  {0, 0, mathFunc(math.Ceil, true)},
+		"floor":    {0, 0, mathFunc(math.Floor, true)},
+		"truncate": {0, 0, mathFunc(math.Trunc, true)},
+		"exp":      {0, 0, mathFunc(math.Exp, false)},
+		"ln":       {0, 0, mathFunc(math.Log, false)},
+		"sqrt":     {0, 0, mathFunc(math.Sqrt, false)},
+		"log":      {1, 1, fnLog},
+		"power":    {1, 1, fnPower},
+		"round":    {0, 1, fnRound},
+		// Tree navigation.
+		"children":    {0, 0, func(_ *scope, f Collection, _ []node) (Collection, error) { return children(f), nil }},
+		"descendants": {0, 0, fnDescendants},
+		// Boolean logic.
+		"not": {0, 0, fnNot},
+		// Utility.
+		"trace":     {1, 2, func(_ *scope, f Collection, _ []node) (Collection, error) { return f, nil }},
+		"now":       {0, 0, fnNow},
+		"today":     {0, 0, fnToday},
+		"timeOfDay": {0, 0, fnTimeOfDay},
+		// Types.
+		"is": {1, 1, typeFunc("is")},
+		"as": {1, 1, typeFunc("as")},
+		// FHIR additions.
+		"extension": {1, 1, fnExtension},
+		"hasValue":  {0, 0, fnHasValue},
+		"resolve":   {0, 0, fnResolve},
+	}
+}
+
+// evalArg evaluates a non-iterating argument against the outer focus.
+func evalArg(s *scope, n node) (Collection, error) { return n.eval(s) }
+
+// singletonArg evaluates an argument that must produce at most one item.
+func singletonArg(s *scope, n node) (interface{}, bool, error) {
+	c, err := evalArg(s, n)
+	if err != nil {
+		return nil, false, err
+	}
+	return singleton(c)
+}
+
+func intArg(s *scope, n node) (int64, bool, error) {
+	v, ok, err := singletonArg(s, n)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	i, isInt := v.(int64)
+	if !isInt {
+		return 0, false, fmt.Errorf("expected an integer argument, got %s", typeOf(v))
+	}
+	return i, true, nil
+}
+
+func stringArg(s *scope, n node) (string, bool, error) {
+	v, ok, err := singletonArg(s, n)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	str, isString := v.(string)
+	if !isString {
+		return "", false, fmt.Errorf("expected a string argument, got %s", typeOf(v))
+	}
+	return str, true, nil
+}
+
+// forEach evaluates the lambda argument n once for each item of focus.
+func forEach(s *scope, focus Collection, n node, fn func(item interface{}, res Collection) error) error {
+	for i, item := range focus {
+		res, err := n.eval(s.item(item, i))
+		if err != nil {
+			return err
+		}
+		if err := fn(item, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fnExists(s *scope, f Collection, args []node) (Collection, error) {
+	if len(args) == 0 {
+		return Collection{len(f) > 0}, nil
+	}
+	filtered, err := fnWhere(s, f, args)
+	if err != nil {
+		return nil, err
+	}
+	return Collection{len(filtered) > 0}, nil
+}
+
+func fnAll(s *scope, f Collection, args []node) (Collection, error) {
+	all := true
+	err := forEach(s, f, args[0], func(_ interface{}, res Collection) error {
+		b, ok, err := singletonBool(res)
+		if err != nil {
+			return err
+		}
+		if !ok || !b {
+			all = false
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Collection{all}, nil
+}
+
+// boolAggregate implements allTrue() and friends. If every is true all items
+// must equal want, otherwise any item must.
+func boolAggregate(every, want bool) func(*scope, Collection, []node) (Collection, error) {
+	return func(_ *scope, f Collection, _ []node) (Collection, error) {
+		for _, item := range f {
+			v, _ := toSystem(item)
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("expected booleans, got %s", typeOf(v))
+			}
+			if every && b != want {
+				return Collection{false}, nil
+			}
+			if !every && b == want {
+				return Collection{true}, nil
+			}
+		}
+		return Collection{every}, nil
+	}
+}
+
+func subset(a, b Collection) bool {
+	for _, item := range a {
+		if !contains(b, item) {
+			return false
+		}
+	}
+	return true
+}
+
+func fnSubsetOf(s *scope, f Collection, args []node) (Collection, error) {
+	other, err := evalArg(s, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return Collection{subset(f, other)}, nil
+}
+
+func fnSupersetOf(s *scope, f Collection, args []node) (Collection, error) {
+	other, err := evalArg(s, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return Collection{subset(other, f)}, nil
+}
+
+func fnWhere(s *scope, f Collection, args []node) (Collection, error) {
+	var out Collection
+	err := forEach(s, f, args[0], func(item interface{}, res Collection) error {
+		b, ok, err := singletonBool(res)
+		if err != nil {
+			return err
+		}
+		if ok && b {
+			out = append(out, item)
+		}
+		return nil
+	})
+	return out, err
+}
+
+func fnSelect(s *scope, f Collection, args []node) (Collection, error) {
+	var out Collection
+	err := forEach(s, f, args[0], func(_ interface{}, res Collection) error {
+		out = append(out, res...)
+		return nil
+	})
+	return out, err
+}
+
+func fnRepeat(s *scope, f Collection, args []node) (Collection, error) {
+	var out Collection
+	queue := f
+	for len(queue) > 0 {
+		next, err := fnSelect(s, queue, args)
+		if err != nil {
+			return nil, err
+		}
+		queue = nil
+		for _, item := range next {
+			if !containsIdentical(out, item) {
+				out = append(out, item)
+				queue = append(queue, item)
+			}
+		}
+	}
+	return out, nil
+}
+
+// containsIdentical is like contains but compares messages by identity, so
+// that repeat() terminates without merging equal sibling elements.
+func containsIdentical(c Collection, v interface{}) bool {
+	if m, ok := v.(proto.Message); ok {
+		for _, item := range c {
+			if item == m {
+				return true
+			}
+		}
+		return false
+	}
+	return contains(c, v)
+}
+
+func fnDescendants(_ *scope, f Collection, _ []node) (Collection, error) {
+	var out Collection
+	for queue := children(f); len(queue) > 0; queue = children(queue) {
+		out = append(out, queue...)
+	}
+	return out, nil
+}
+
+func fnOfType(_ *scope, f Collection, args []node) (Collection, error) {
+	typ, err := typeArgument(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var out Collection
+	for _, item := range f {
+		if isType(item, typ) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func typeFunc(op string) func(*scope, Collection, []node) (Collection, error) {
+	return func(s *scope, f Collection, args []node) (Collection, error) {
+		typ, err := typeArgument(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return (&typeNode{op: op, operand: &literalNode{val: f}, typ: typ}).eval(s)
+	}
+}
+
+func fnAggregate(s *scope, f Collection, args []node) (Collection, error) {
+	var total Collection
+	if len(args) == 2 {
+		var err error
+		if total, err = evalArg(s, args[1]); err != nil {
+			return nil, err
+		}
+	}
+	for i, item := range f {
+		is := s.item(item, i)
+		is.total = total
+		var err error
+		if total, err = args[0].eval(is); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+func fnSingle(_ *scope, f Collection, _ []node) (Collection, error) {
+	if len(f) > 1 {
+		return nil, fmt.Errorf("expected a single item, got %d", len(f))
+	}
+	return f, nil
+}
+
+func slice(f Collection, from, to int) Collection {
+	if from < 0 {
+		from = 0
+	}
+	if to > len(f) {
+		to = len(f)
+	}
+	if from >= to {
+		return nil
+	}
+	return f[from:to]
+}
+
+func fnSkip(s *scope, f Collection, args []node) (Collection, error) {
+	n, ok, err := intArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	if n > int64(len(f)) {
+		return nil, nil
+	}
+	return slice(f, int(n), len(f)), nil
+}
+
+func fnTake(s *scope, f Collection, args []node) (Collection, error) {
+	n, ok, err := intArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	if n > int64(len(f)) {
+		n = int64(len(f))
+	}
+	return slice(f, 0, int(n)), nil
+}
+
+func fnIntersect(s *scope, f Collection, args []node) (Collection, error) {
+	other, err := evalArg(s, args[0])
+	if err != nil {
+		return nil, err
+	}
+	var out Collection
+	for _, item := range distinct(f) {
+		if contains(other, item) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func fnExclude(s *scope, f Collection, args []node) (Collection, error) {
+	other, err := evalArg(s, args[0])
+	if err != nil {
+		return nil, err
+	}
+	var out Collection
+	for _, item := range f {
+		if !contains(other, item) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func fnUnion(s *scope, f Collection, args []node) (Collection, error) {
+	other, err := evalArg(s, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return union(f, other), nil
+}
+
+func fnCombine(s *scope, f Collection, args []node) (Collection, error) {
+	other, err := evalArg(s, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return append(append(Collection{}, f...), other...), nil
+}
+
+func fnIif(s *scope, f Collection, args []node) (Collection, error) {
+	cond, err := evalArg(s, args[0])
+	if err != nil {
+		return nil, err
+	}
+	b, ok, err := singletonBool(cond)
+	if err != nil {
+		return nil, err
+	}
+	if ok && b {
+		return evalArg(s, args[1])
+	}
+	if len(args) == 3 {
+		return evalArg(s, args[2])
+	}
+	return nil, nil
+}
+
+func fnNot(_ *scope, f Collection, _ []node) (Collection, error) {
+	b, ok, err := singletonBool(f)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return Collection{!b}, nil
+}
+
+// converter converts a single system value. ok is false if the value cannot
+// be converted.
+type converter func(s *scope, v interface{}, args []node) (interface{}, bool, error)
+
+func conversion(c converter) func(*scope, Collection, []node) (Collection, error) {
+	return func(s *scope, f Collection, args []node) (Collection, error) {
+		v, ok, err := singleton(f)
+		if err != nil || !ok {
+			return nil, err
+		}
+		res, ok, err := c(s, v, args)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return Collection{res}, nil
+	}
+}
+
+func convertsTo(c converter) func(*scope, Collection, []node) (Collection, error) {
+	return func(s *scope, f Collection, args []node) (Collection, error) {
+		v, ok, err := singleton(f)
+		if err != nil || !ok {
+			return nil, err
+		}
+		_, ok, err = c(s, v, args)
+		if err != nil {
+			return nil, err
+		}
+		return Collection{ok}, nil
+	}
+}
+
+func toBoolean(_ *scope, v interface{}, _ []node) (interface{}, bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, true, nil
+	case int64:
+		if x == 0 || x == 1 {
+			return x == 1, true, nil
+		}
+	case float64:
+		if x == 0 || x == 1 {
+			return x == 1, true, nil
+		}
+	case string:
+		switch strings.ToLower(x) {
+		case "true", "t", "yes", "y", "1", "1.0":
+			return true, true, nil
+		case "false", "f", "no", "n", "0", "0.0":
+			return false, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func toInteger(_ *scope, v interface{}, _ []node) (interface{}, bool, error) {
+	switch x := v.(type) {
+	case int64:
+		return x, true, nil
+	case bool:
+		if x {
+			return int64(1), true, nil
+		}
+		return int64(0), true, nil
+	case string:
+		if i, err := strconv.ParseInt(x, 10, 64); err == nil {
+			return i, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func toDecimal(_ *scope, v interface{}, _ []node) (interface{}, bool, error) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true, nil
+	case float64:
+		return x, true, nil
+	case bool:
+		if x {
+			return 1.0, true, nil
+		}
+		return 0.0, true, nil
+	case string:
+		if f, err := strconv.ParseFloat(x, 64); err == nil && !strings.ContainsAny(x, "eEinfINFaA") {
+			return f, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func toStringValue(_ *scope, v interface{}, _ []node) (interface{}, bool, error) {
+	s, ok := formatValue(v)
+	return s, ok, nil
+}
+
+func toTemporal(kind TemporalKind) converter {
+	return func(_ *scope, v interface{}, _ []node) (interface{}, bool, error) {
+		var t Temporal
+		switch x := v.(type) {
+		case Temporal:
+			t = x
+		case string:
+			if kind == Time {
+				x = "T" + x
+			}
+			var err error
+			if t, err = parseTemporal(x); err != nil {
+				return nil, false, nil
+			}
+		default:
+			return nil, false, nil
+		}
+		switch {
+		case (kind == Time) != (t.Kind == Time):
+			return nil, false, nil
+		case kind == Date && t.Precision > PrecisionDay:
+			t.Precision = PrecisionDay
+		}
+		t.Kind = kind
+		return t, true, nil
+	}
+}
+
+var quantityRE = regexp.MustCompile(`^\s*([+-]?\d+(?:\.\d+)?)\s*(?:'([^']+)'|([a-zA-Z]+))?\s*$`)
+
+func toQuantity(s *scope, v interface{}, args []node) (interface{}, bool, error) {
+	var q Quantity
+	switch x := v.(type) {
+	case Quantity:
+		q = x
+	case int64:
+		q = Quantity{Value: float64(x), Unit: "1"}
+	case float64:
+		q = Quantity{Value: x, Unit: "1"}
+	case string:
+		m := quantityRE.FindStringSubmatch(x)
+		if m == nil || (m[3] != "" && !calendarKeywords[m[3]]) {
+			return nil, false, nil
+		}
+		q.Value, _ = strconv.ParseFloat(m[1], 64)
+		q.Unit = m[2] + m[3]
+		if q.Unit == "" {
+			q.Unit = "1"
+		}
+	default:
+		return nil, false, nil
+	}
+	if len(args) == 1 {
+		unit, ok, err := stringArg(s, args[0])
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		if canonicalUnit(unit) != canonicalUnit(q.Unit) {
+			return nil, false, nil
+		}
+	}
+	return q, true, nil
+}
+
+// stringFunc wraps functions that operate on a single string input.
+func stringFunc(fn func(s *scope, str string, args []node) (Collection, error)) func(*scope, Collection, []node) (Collection, error) {
+	return func(s *scope, f Collection, args []node) (Collection, error) {
+		v, ok, err := singleton(f)
+		if err != nil || !ok {
+			return nil, err
+		}
+		str, isString := v.(string)
+		if !isString {
+			return nil, fmt.Errorf("expected a string, got %s", typeOf(v))
+		}
+		return fn(s, str, args)
+	}
+}
+
+func stringPredicate(pred func(s, arg string) bool) func(*scope, string, []node) (Collection, error) {
+	return func(s *scope, str string, args []node) (Collection, error) {
+		arg, ok, err := stringArg(s, args[0])
+		if err != nil || !ok {
+			return nil, err
+		}
+		return Collection{pred(str, arg)}, nil
+	}
+}
+
+func stringMap(fn func(string) string) func(*scope, string, []node) (Collection, error) {
+	return func(_ *scope, str string, _ []node) (Collection, error) { return Collection{fn(str)}, nil }
+}
+
+func fnIndexOf(s *scope, str string, args []node) (Collection, error) {
+	sub, ok, err := stringArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	i := strings.Index(str, sub)
+	if i < 0 {
+		return Collection{int64(-1)}, nil
+	}
+	return Collection{int64(utf8.RuneCountInString(str[:i]))}, nil
+}
+
+func fnSubstring(s *scope, str string, args []node) (Collection, error) {
+	runes := []rune(str)
+	start, ok, err := intArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	if start < 0 || start >= int64(len(runes)) {
+		return nil, nil
+	}
+	end := int64(len(runes))
+	if len(args) == 2 {
+		n, ok, err := intArg(s, args[1])
+		if err != nil {
+			return nil, err
+		}
+		if ok && start+n < end {
+			end = start + n
+		}
+	}
+	if end < start {
+		end = start
+	}
+	return Collection{string(runes[start:end])}, nil
+}
+
+func fnReplace(s *scope, str string, args []node) (Collection, error) {
+	pattern, ok, err := stringArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	repl, ok, err := stringArg(s, args[1])
+	if err != nil || !ok {
+		return nil, err
+	}
+	return Collection{strings.ReplaceAll(str, pattern, repl)}, nil
+}
+
+func fnMatches(s *scope, str string, args []node) (Collection, error) {
+	pattern, ok, err := stringArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	re, err := regexp.Compile("(?s)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+	return Collection{re.MatchString(str)}, nil
+}
+
+func fnReplaceMatches(s *scope, str string, args []node) (Collection, error) {
+	pattern, ok, err := stringArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	repl, ok, err := stringArg(s, args[1])
+	if err != nil || !ok {
+		return nil, err
+	}
+	re, err := regexp.Compile("(?s)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+	return Collection{re.ReplaceAllString(str, repl)}, nil
+}
+
+func fnLength(_ *scope, str string, _ []node) (Collection, error) {
+	return Collection{int64(utf8.RuneCountInString(str))}, nil
+}
+
+func fnToChars(_ *scope, str string, _ []node) (Collection, error) {
+	var out Collection
+	for _, r := range str {
+		out = append(out, string(r))
+	}
+	return out, nil
+}
+
+func fnSplit(s *scope, str string, args []node) (Collection, error) {
+	sep, ok, err := stringArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	var out Collection
+	for _, part := range strings.Split(str, sep) {
+		out = append(out, part)
+	}
+	return out, nil
+}
+
+func fnJoin(s *scope, f Collection, args []node) (Collection, error) {
+	sep := ""
+	if len(args) == 1 {
+		var err error
+		if sep, _, err = stringArg(s, args[0]); err != nil {
+			return nil, err
+		}
+	}
+	parts := make([]string, 0, len(f))
+	for _, item := range f {
+		v, _ := toSystem(item)
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected strings, got %s", typeOf(v))
+		}
+		parts = append(parts, str)
+	}
+	return Collection{strings.Join(parts, sep)}, nil
+}
+
+// mathFunc wraps single-argument math functions. Results are Integers if
+// intResult is set and Decimals otherwise.
+func mathFunc(fn func(float64) float64, intResult bool) func(*scope, Collection, []node) (Collection, error) {
+	return func(_ *scope, f Collection, _ []node) (Collection, error) {
+		v, ok, err := singleton(f)
+		if err != nil || !ok {
+			return nil, err
+		}
+		x, isNum := toFloat(v)
+		if !isNum {
+			return nil, fmt.Errorf("expected a number, got %s", typeOf(v))
+		}
+		res := fn(x)
+		if math.IsNaN(res) || math.IsInf(res, 0) {
+			return nil, nil
+		}
+		if intResult {
+			return Collection{int64(res)}, nil
+		}
+		return Collection{res}, nil
+	}
+}
+
+func fnAbs(_ *scope, f Collection, _ []node) (Collection, error) {
+	v, ok, err := singleton(f)
+	if err != nil || !ok {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case int64:
+		if x < 0 {
+			x = -x
+		}
+		return Collection{x}, nil
+	case float64:
+		return Collection{math.Abs(x)}, nil
+	case Quantity:
+		return Collection{Quantity{Value: math.Abs(x.Value), Unit: x.Unit}}, nil
+	}
+	return nil, fmt.Errorf("expected a number, got %s", typeOf(v))
+}
+
+func fnLog(s *scope, f Collection, args []node) (Collection, error) {
+	v, ok, err := singleton(f)
+	if err != nil || !ok {
+		return nil, err
+	}
+	b, ok, err := singletonArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	x, ok1 := toFloat(v)
+	base, ok2 := toFloat(b)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("expected numbers")
+	}
+	res := math.Log(x) / math.Log(base)
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return nil, nil
+	}
+	return Collection{res}, nil
+}
+
+func fnPower(s *scope, f Collection, args []node) (Collection, error) {
+	v, ok, err := singleton(f)
+	if err != nil || !ok {
+		return nil, err
+	}
+	e, ok, err := singletonArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	x, ok1 := toFloat(v)
+	y, ok2 := toFloat(e)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("expected numbers")
+	}
+	res := math.Pow(x, y)
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return nil, nil
+	}
+	_, xInt := v.(int64)
+	_, yInt := e.(int64)
+	if xInt && yInt && y >= 0 {
+		return Collection{int64(res)}, nil
+	}
+	return Collection{res}, nil
+}
+
+func fnRound(s *scope, f Collection, args []node) (Collection, error) {
+	v, ok, err := singleton(f)
+	if err != nil || !ok {
+		return nil, err
+	}
+	x, isNum := toFloat(v)
+	if !isNum {
+		return nil, fmt.Errorf("expected a number, got %s", typeOf(v))
+	}
+	var digits int64
+	if len(args) == 1 {
+		if digits, _, err = intArg(s, args[0]); err != nil {
+			return nil, err
+		}
+	}
+	scale := math.Pow(10, float64(digits))
+	return Collection{math.Round(x*scale) / scale}, nil
+}
+
+func fnNow(s *scope, _ Collection, _ []node) (Collection, error) {
+	return Collection{Temporal{Kind: DateTime, Time: s.env.now, Precision: PrecisionMillisecond}}, nil
+}
+
+func fnToday(s *scope, _ Collection, _ []node) (Collection, error) {
+	n := s.env.now
+	return Collection{Temporal{Kind: Date, Time: time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, n.Location()), Precision: PrecisionDay}}, nil
+}
+
+func fnTimeOfDay(s *scope, _ Collection, _ []node) (Collection, error) {
+	n := s.env.now
+	return Collection{Temporal{Kind: Time, Time: time.Date(0, 1, 1, n.Hour(), n.Minute(), n.Second(), n.Nanosecond(), time.UTC), Precision: PrecisionMillisecond}}, nil
+}
+
+func fnExtension(s *scope, f Collection, args []node) (Collection, error) {
+	url, ok, err := stringArg(s, args[0])
+	if err != nil || !ok {
+		return nil, err
+	}
+	var out Collection
+	for _, ext := range navigate(f, "extension") {
+		if u, ok := navigate(Collection{ext}, "url").StringValue(); ok && u == url {
+			out = append(out, ext)
+		}
+	}
+	return out, nil
+}
+
+func fnHasValue(_ *scope, f Collection, _ []node) (Collection, error) {
+	if len(f) != 1 {
+		return Collection{false}, nil
+	}
+	m, ok := f[0].(proto.Message)
+	if !ok {
+		return Collection{true}, nil
+	}
+	v, ok := toSystem(m)
+	_, stillMsg := v.(proto.Message)
+	return Collection{ok && !stillMsg}, nil
+}
+
+func fnResolve(s *scope, f Collection, _ []node) (Collection, error) {
+	if s.env.resolver == nil {
+		return nil, fmt.Errorf("no resolver configured")
+	}
+	var out Collection
+	for _, item := range f {
+		var ref string
+		if refs := navigate(Collection{item}, "reference"); len(refs) == 1 {
+			ref, _ = refs[0].(string)
+		} else if v, _ := toSystem(item); v != nil {
+			ref, _ = v.(string)
+		}
+		if ref == "" {
+			continue
+		}
+		res, err := s.env.resolver(ref)
+		if err != nil {
+			return nil, err
+		}
+		if res != nil {
+			out = append(out, res)
+		}
+	}
+	return out, nil
+}
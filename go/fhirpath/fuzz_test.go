@@ -0,0 +1,43 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package fhirpath
+
+import (
+	"testing"
+)
+
+// FuzzCompile compiles arbitrary expressions and evaluates those that
+// compile against a patient. Errors are expected for most expressions;
+// panics are failures.
+func FuzzCompile(f *testing.F) {
+	for _, s := range []string{
+		"name.where(use = 'official').given.first()",
+		"birthDate < @2000-01-01 and active",
+		"extension('http://example.com/ext').value as String",
+		"(1 | 2 | 3).aggregate($this + $total, 0)",
+		"iif(gender.exists(), gender, 'unknown') ~ 'FEMALE'",
+		"'a' + 1 div 0",
+		"((((",
+		"%resource.id.matches('[')",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		expr, err := Compile(s)
+		if err != nil {
+			return
+		}
+		_, _ = expr.Evaluate(testPatient())
+	})
+}
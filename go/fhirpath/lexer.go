@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokDelimitedIdent
+	tokString
+	tokNumber
+	tokDateTime
+	tokVariable // %name
+	tokSpecial  // $this, $index, $total
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string // decoded text for strings and identifiers
+	pos  int
+}
+
+// lex splits a FHIRPath expression into tokens.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at %d", i)
+			}
+			i += end + 4
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[start:i], pos: start})
+		case c == '`':
+			s, n, err := unquote(src[i:], '`')
+			if err != nil {
+				return nil, fmt.Errorf("at %d: %w", i, err)
+			}
+			toks = append(toks, token{kind: tokDelimitedIdent, text: s, pos: i})
+			i += n
+		case c == '\'':
+			s, n, err := unquote(src[i:], '\'')
+			if err != nil {
+				return nil, fmt.Errorf("at %d: %w", i, err)
+			}
+			toks = append(toks, token{kind: tokString, text: s, pos: i})
+			i += n
+		case isDigit(c):
+			start := i
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			if i+1 < len(src) && src[i] == '.' && isDigit(src[i+1]) {
+				i++
+				for i < len(src) && isDigit(src[i]) {
+					i++
+				}
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[start:i], pos: start})
+		case c == '@':
+			start := i
+			i++
+			for i < len(src) && isDateTimeChar(src[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokDateTime, text: src[start+1 : i], pos: start})
+		case c == '%':
+			start := i
+			i++
+			switch {
+			case i < len(src) && (src[i] == '`' || src[i] == '\''):
+				s, n, err := unquote(src[i:], src[i])
+				if err != nil {
+					return nil, fmt.Errorf("at %d: %w", i, err)
+				}
+				toks = append(toks, token{kind: tokVariable, text: s, pos: start})
+				i += n
+			case i < len(src) && isIdentStart(src[i]):
+				j := i
+				for i < len(src) && (isIdentPart(src[i]) || src[i] == '-') {
+					i++
+				}
+				toks = append(toks, token{kind: tokVariable, text: src[j:i], pos: start})
+			default:
+				return nil, fmt.Errorf("invalid variable at %d", start)
+			}
+		case c == '$':
+			start := i
+			i++
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokSpecial, text: src[start:i], pos: start})
+		default:
+			op := ""
+			for _, o := range []string{"<=", ">=", "!=", "!~"} {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune(".[](){},+-*/&|=~<>", rune(c)) {
+				op = string(c)
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(src[i:])
+				return nil, fmt.Errorf("unexpected character %q at %d", r, i)
+			}
+			toks = append(toks, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// unquote decodes the quoted string at the start of s and returns it together
+// with the number of bytes consumed.
+func unquote(s string, quote byte) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\':
+			i++
+			if i >= len(s) {
+				break
+			}
+			switch s[i] {
+			case '\'', '"', '`', '\\', '/':
+				b.WriteByte(s[i])
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+4 >= len(s) {
+					return "", 0, fmt.Errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid unicode escape %q", s[i-1:i+5])
+				}
+				b.WriteRune(rune(r))
+				i += 4
+			default:
+				return "", 0, fmt.Errorf("invalid escape \\%c", s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool { return isIdentStart(c) || isDigit(c) }
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isDateTimeChar(c byte) bool {
+	return isDigit(c) || c == '-' || c == ':' || c == 'T' || c == '.' || c == '+' || c == 'Z'
+}
@@ -0,0 +1,361 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// node is an element of a parsed expression tree.
+type node interface {
+	eval(s *scope) (Collection, error)
+}
+
+type (
+	literalNode struct{ val Collection }
+	// memberNode navigates to the child name of target, or of the focus if
+	// target is nil.
+	memberNode struct {
+		target node
+		name   string
+	}
+	// callNode invokes a function on target, or on the focus if target is nil.
+	callNode struct {
+		target node
+		name   string
+		args   []node
+	}
+	indexNode struct{ target, index node }
+	unaryNode struct {
+		op      string
+		operand node
+	}
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	typeNode struct {
+		op      string
+		operand node
+		typ     string
+	}
+	variableNode struct{ name string }
+	specialNode  struct{ name string } // $this, $index or $total
+)
+
+// Operator precedence, from loosest to tightest binding.
+var precedence = map[string]int{
+	"implies": 1,
+	"or":      2, "xor": 2,
+	"and": 3,
+	"in":  4, "contains": 4,
+	"=": 5, "~": 5, "!=": 5, "!~": 5,
+	"<": 6, ">": 6, "<=": 6, ">=": 6,
+	"|":  7,
+	"is": 8, "as": 8,
+	"+": 9, "-": 9, "&": 9,
+	"*": 10, "/": 10, "div": 10, "mod": 10,
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func parse(src string) (node, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	n, err := p.expr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(text string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if !p.isOp(text) {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return fmt.Errorf("expected %q at end of expression", text)
+		}
+		return fmt.Errorf("expected %q at %d", text, t.pos)
+	}
+	p.next()
+	return nil
+}
+
+// infixOp returns the binary operator at the current position, if any.
+func (p *parser) infixOp() (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp && t.kind != tokIdent {
+		return "", false
+	}
+	if _, ok := precedence[t.text]; !ok {
+		return "", false
+	}
+	return t.text, true
+}
+
+func (p *parser) expr(minPrec int) (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.infixOp()
+		if !ok || precedence[op] <= minPrec {
+			return left, nil
+		}
+		p.next()
+		if op == "is" || op == "as" {
+			typ, err := p.typeSpecifier()
+			if err != nil {
+				return nil, err
+			}
+			left = &typeNode{op: op, operand: left, typ: typ}
+			continue
+		}
+		right, err := p.expr(precedence[op])
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) typeSpecifier() (string, error) {
+	var parts []string
+	for {
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokDelimitedIdent {
+			return "", fmt.Errorf("expected type name at %d", t.pos)
+		}
+		parts = append(parts, t.text)
+		if !p.isOp(".") {
+			return strings.Join(parts, "."), nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if p.isOp("+") || p.isOp("-") {
+		op := p.next().text
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOp("."):
+			p.next()
+			n, err = p.invocation(n)
+		case p.isOp("["):
+			p.next()
+			var idx node
+			if idx, err = p.expr(0); err != nil {
+				return nil, err
+			}
+			err = p.expect("]")
+			n = &indexNode{target: n, index: idx}
+		default:
+			return n, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// invocation parses a member name or function call applied to target.
+func (p *parser) invocation(target node) (node, error) {
+	t := p.next()
+	if t.kind != tokIdent && t.kind != tokDelimitedIdent {
+		return nil, fmt.Errorf("expected identifier at %d", t.pos)
+	}
+	if t.kind == tokIdent && p.isOp("(") {
+		args, err := p.args()
+		if err != nil {
+			return nil, err
+		}
+		return &callNode{target: target, name: t.text, args: args}, nil
+	}
+	return &memberNode{target: target, name: t.text}, nil
+}
+
+func (p *parser) args() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []node
+	if p.isOp(")") {
+		p.next()
+		return nil, nil
+	}
+	for {
+		a, err := p.expr(0)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		return args, p.expect(")")
+	}
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case tokOp:
+		if t.text == "(" {
+			p.next()
+			n, err := p.expr(0)
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		}
+		if t.text == "{" {
+			p.next()
+			return &literalNode{}, p.expect("}")
+		}
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	case tokString:
+		p.next()
+		return &literalNode{val: Collection{t.text}}, nil
+	case tokNumber:
+		p.next()
+		return p.number(t)
+	case tokDateTime:
+		p.next()
+		v, err := parseTemporal(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("at %d: %w", t.pos, err)
+		}
+		return &literalNode{val: Collection{v}}, nil
+	case tokVariable:
+		p.next()
+		return &variableNode{name: t.text}, nil
+	case tokSpecial:
+		p.next()
+		switch t.text {
+		case "$this", "$index", "$total":
+			return &specialNode{name: t.text}, nil
+		}
+		return nil, fmt.Errorf("unknown special variable %s at %d", t.text, t.pos)
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			p.next()
+			return &literalNode{val: Collection{t.text == "true"}}, nil
+		}
+		return p.invocation(nil)
+	case tokDelimitedIdent:
+		return p.invocation(nil)
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+// number parses an Integer, Decimal or Quantity literal.
+func (p *parser) number(t token) (node, error) {
+	var v interface{}
+	if strings.Contains(t.text, ".") {
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		v = f
+	} else {
+		i, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", t.text)
+		}
+		v = i
+	}
+	next := p.peek()
+	unit := ""
+	switch {
+	case next.kind == tokString:
+		unit = next.text
+	case next.kind == tokIdent && calendarKeywords[next.text]:
+		unit = next.text
+	default:
+		return &literalNode{val: Collection{v}}, nil
+	}
+	p.next()
+	f, _ := toFloat(v)
+	return &literalNode{val: Collection{Quantity{Value: f, Unit: unit}}}, nil
+}
+
+var calendarKeywords = map[string]bool{
+	"year": true, "years": true, "month": true, "months": true, "week": true, "weeks": true,
+	"day": true, "days": true, "hour": true, "hours": true, "minute": true, "minutes": true,
+	"second": true, "seconds": true, "millisecond": true, "milliseconds": true,
+}
+
+// typeArgument returns the type specifier passed as a function argument, as
+// in ofType(Patient) or is(FHIR.string).
+func typeArgument(n node) (string, error) {
+	switch x := n.(type) {
+	case *memberNode:
+		if x.target == nil {
+			return x.name, nil
+		}
+		prefix, err := typeArgument(x.target)
+		if err != nil {
+			return "", err
+		}
+		return prefix + "." + x.name, nil
+	}
+	return "", fmt.Errorf("expected a type specifier")
+}
@@ -0,0 +1,724 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirpath
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// Collection is the result of evaluating an expression. Items are either FHIR
+// elements, held as proto.Message, or FHIRPath system values of type bool,
+// string, int64 (Integer), float64 (Decimal), Temporal or Quantity.
+type Collection []interface{}
+
+// TemporalKind distinguishes the three FHIRPath date and time types.
+type TemporalKind int
+
+const (
+	// Date values have at most day precision.
+	Date TemporalKind = iota
+	// DateTime values have a date and an optional time of day.
+	DateTime
+	// Time values are a time of day without a date.
+	Time
+)
+
+// Precision is the precision of a Temporal value.
+type Precision int
+
+// Temporal precisions, from coarsest to finest.
+const (
+	PrecisionYear Precision = iota
+	PrecisionMonth
+	PrecisionDay
+	PrecisionHour
+	PrecisionMinute
+	PrecisionSecond
+	PrecisionMillisecond
+)
+
+// Temporal is a FHIRPath Date, DateTime or Time value. Time values use the
+// zero date in UTC.
+type Temporal struct {
+	Kind      TemporalKind
+	Time      time.Time
+	Precision Precision
+}
+
+func (t Temporal) String() string {
+	var layout string
+	switch t.Kind {
+	case Time:
+		layout = []string{"", "", "", "15", "15:04", "15:04:05", "15:04:05.000"}[t.Precision]
+		return t.Time.Format(layout)
+	default:
+		layout = []string{"2006", "2006-01", "2006-01-02", "2006-01-02T15", "2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02T15:04:05.000"}[t.Precision]
+	}
+	s := t.Time.Format(layout)
+	if t.Precision > PrecisionDay {
+		s += t.Time.Format("Z07:00")
+	}
+	return s
+}
+
+// Quantity is a FHIRPath Quantity. Unit is a UCUM code or a calendar
+// duration keyword such as "year".
+type Quantity struct {
+	Value float64
+	Unit  string
+}
+
+func (q Quantity) String() string {
+	return strconv.FormatFloat(q.Value, 'f', -1, 64) + " '" + q.Unit + "'"
+}
+
+var (
+	dateRE = regexp.MustCompile(`^(\d{4})(?:-(\d{2})(?:-(\d{2}))?)?$`)
+	timeRE = regexp.MustCompile(`^(\d{2})(?::(\d{2})(?::(\d{2})(?:\.(\d+))?)?)?$`)
+	zoneRE = regexp.MustCompile(`(Z|[+-]\d{2}:\d{2})$`)
+)
+
+// parseTemporal parses the lexical form of a FHIRPath date, dateTime or time
+// literal without the leading '@'.
+func parseTemporal(s string) (Temporal, error) {
+	if strings.HasPrefix(s, "T") {
+		t, p, err := parseTimeOfDay(s[1:])
+		if err != nil {
+			return Temporal{}, err
+		}
+		return Temporal{Kind: Time, Time: t, Precision: p}, nil
+	}
+	datePart, timePart, hasTime := strings.Cut(s, "T")
+	m := dateRE.FindStringSubmatch(datePart)
+	if m == nil {
+		return Temporal{}, fmt.Errorf("invalid date %q", s)
+	}
+	loc := time.UTC
+	if hasTime && timePart != "" {
+		if z := zoneRE.FindString(timePart); z != "" {
+			timePart = strings.TrimSuffix(timePart, z)
+			var err error
+			if loc, err = parseZone(z); err != nil {
+				return Temporal{}, err
+			}
+		}
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, day, prec := 1, 1, PrecisionYear
+	if m[2] != "" {
+		month, _ = strconv.Atoi(m[2])
+		prec = PrecisionMonth
+	}
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+		prec = PrecisionDay
+	}
+	if d := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC); int(d.Month()) != month || d.Day() != day {
+		return Temporal{}, fmt.Errorf("invalid date %q", s)
+	}
+	kind := Date
+	if hasTime {
+		kind = DateTime
+	}
+	if !hasTime || timePart == "" {
+		return Temporal{Kind: kind, Time: time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc), Precision: prec}, nil
+	}
+	if prec != PrecisionDay {
+		return Temporal{}, fmt.Errorf("invalid dateTime %q", s)
+	}
+	tod, p, err := parseTimeOfDay(timePart)
+	if err != nil {
+		return Temporal{}, err
+	}
+	t := time.Date(year, time.Month(month), day, tod.Hour(), tod.Minute(), tod.Second(), tod.Nanosecond(), loc)
+	return Temporal{Kind: DateTime, Time: t, Precision: p}, nil
+}
+
+func parseTimeOfDay(s string) (time.Time, Precision, error) {
+	m := timeRE.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, 0, fmt.Errorf("invalid time %q", s)
+	}
+	h, _ := strconv.Atoi(m[1])
+	mi, sec, ns, prec := 0, 0, 0, PrecisionHour
+	if m[2] != "" {
+		mi, _ = strconv.Atoi(m[2])
+		prec = PrecisionMinute
+	}
+	if m[3] != "" {
+		sec, _ = strconv.Atoi(m[3])
+		prec = PrecisionSecond
+	}
+	if m[4] != "" {
+		frac := (m[4] + "000000000")[:9]
+		ns, _ = strconv.Atoi(frac)
+		prec = PrecisionMillisecond
+	}
+	if h > 23 || mi > 59 || sec > 59 {
+		return time.Time{}, 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Date(0, 1, 1, h, mi, sec, ns, time.UTC), prec, nil
+}
+
+// parseZone parses "Z", a "+hh:mm" offset or an IANA time zone name.
+func parseZone(tz string) (*time.Location, error) {
+	if tz == "" || tz == "Z" || tz == "UTC" {
+		return time.UTC, nil
+	}
+	if tz[0] == '+' || tz[0] == '-' {
+		h, m, ok := strings.Cut(tz[1:], ":")
+		hours, err1 := strconv.Atoi(h)
+		mins, err2 := strconv.Atoi(m)
+		if !ok || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid time zone offset %q", tz)
+		}
+		offset := hours*3600 + mins*60
+		if tz[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(tz, offset), nil
+	}
+	return time.LoadLocation(tz)
+}
+
+// TypeName returns the FHIR type name of the message type d, i.e. "Patient",
+// "HumanName" or "code". Backbone elements are reported as
+// "BackboneElement".
+func TypeName(d protoreflect.MessageDescriptor) string {
+	if url := proto.GetExtension(d.Options(), apb.E_FhirStructureDefinitionUrl).(string); url != "" {
+		return url[strings.LastIndex(url, "/")+1:]
+	}
+	if bases := proto.GetExtension(d.Options(), apb.E_FhirProfileBase).([]string); len(bases) > 0 {
+		return bases[0][strings.LastIndex(bases[0], "/")+1:]
+	}
+	return "BackboneElement"
+}
+
+// CodeString returns the FHIR code of the enum value v, honoring the original
+// code annotation used for codes that are not valid proto identifiers.
+func CodeString(v protoreflect.EnumValueDescriptor) string {
+	if orig := proto.GetExtension(v.Options(), apb.E_FhirOriginalCode).(string); orig != "" {
+		return orig
+	}
+	return strings.ReplaceAll(strings.ToLower(string(v.Name())), "_", "-")
+}
+
+var quantityTypes = map[string]bool{
+	"Quantity": true, "Age": true, "Count": true, "Distance": true, "Duration": true, "SimpleQuantity": true, "MoneyQuantity": true,
+}
+
+// SystemValue converts a FHIR primitive or quantity element to the
+// corresponding FHIRPath system value, i.e. a *d4pb.Date to a Temporal. Other
+// values are returned unchanged. ok is false for primitives without a value.
+func SystemValue(v interface{}) (value interface{}, ok bool) { return toSystem(v) }
+
+// ProtoValue converts a FHIRPath system value to the corresponding R4 proto,
+// i.e. a Temporal of kind Date to a *d4pb.Date and a Quantity to a UCUM
+// *d4pb.Quantity. FHIR elements are returned unchanged. ok is false for values
+// of any other type.
+func ProtoValue(v interface{}) (value proto.Message, ok bool) {
+	switch x := v.(type) {
+	case proto.Message:
+		return x, true
+	case bool:
+		return &d4pb.Boolean{Value: x}, true
+	case string:
+		return &d4pb.String{Value: x}, true
+	case int64:
+		if x < math.MinInt32 || x > math.MaxInt32 {
+			return &d4pb.Decimal{Value: strconv.FormatInt(x, 10)}, true
+		}
+		return &d4pb.Integer{Value: int32(x)}, true
+	case float64:
+		return &d4pb.Decimal{Value: strconv.FormatFloat(x, 'f', -1, 64)}, true
+	case Quantity:
+		q := &d4pb.Quantity{Value: &d4pb.Decimal{Value: strconv.FormatFloat(x.Value, 'f', -1, 64)}}
+		if x.Unit != "" && x.Unit != "1" {
+			q.Unit = &d4pb.String{Value: x.Unit}
+			q.System = &d4pb.Uri{Value: "http://unitsofmeasure.org"}
+			q.Code = &d4pb.Code{Value: x.Unit}
+		}
+		return q, true
+	case Temporal:
+		switch x.Kind {
+		case Date:
+			return dateProto(x), true
+		case Time:
+			return timeProto(x), true
+		}
+		return dateTimeProto(x), true
+	}
+	return nil, false
+}
+
+// toSystem converts FHIR primitives and quantities to the corresponding
+// system value. Other items are returned unchanged. The boolean result is
+// false for primitives without a value.
+func toSystem(v interface{}) (interface{}, bool) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return v, true
+	}
+	rm := m.ProtoReflect()
+	d := rm.Descriptor()
+	if quantityTypes[TypeName(d)] {
+		return quantityFromProto(rm)
+	}
+	if !elementpath.IsPrimitive(d) {
+		return v, true
+	}
+	if f := d.Fields().ByName("value_us"); f != nil {
+		if !rm.Has(f) && !rm.Has(d.Fields().ByName("precision")) {
+			return nil, false
+		}
+		return temporalFromProto(rm), true
+	}
+	f := d.Fields().ByName("value")
+	if f == nil {
+		return nil, false
+	}
+	val := rm.Get(f)
+	switch f.Kind() {
+	case protoreflect.BoolKind:
+		return val.Bool(), true
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return val.Int(), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return int64(val.Uint()), true
+	case protoreflect.EnumKind:
+		if val.Enum() == 0 {
+			return nil, false
+		}
+		ev := f.Enum().Values().ByNumber(val.Enum())
+		if ev == nil {
+			return nil, false
+		}
+		return CodeString(ev), true
+	case protoreflect.BytesKind:
+		if len(val.Bytes()) == 0 {
+			return nil, false
+		}
+		return base64.StdEncoding.EncodeToString(val.Bytes()), true
+	case protoreflect.StringKind:
+		s := val.String()
+		if s == "" {
+			return nil, false
+		}
+		if TypeName(d) == "decimal" {
+			x, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, false
+			}
+			return x, true
+		}
+		return s, true
+	}
+	return nil, false
+}
+
+func temporalFromProto(rm protoreflect.Message) Temporal {
+	d := rm.Descriptor()
+	us := rm.Get(d.Fields().ByName("value_us")).Int()
+	var prec string
+	if f := d.Fields().ByName("precision"); f != nil {
+		if ev := f.Enum().Values().ByNumber(rm.Get(f).Enum()); ev != nil {
+			prec = string(ev.Name())
+		}
+	}
+	p := map[string]Precision{
+		"YEAR": PrecisionYear, "MONTH": PrecisionMonth, "DAY": PrecisionDay, "SECOND": PrecisionSecond,
+		"MILLISECOND": PrecisionMillisecond, "MICROSECOND": PrecisionMillisecond,
+	}[prec]
+	if TypeName(d) == "time" {
+		if prec == "" || prec == "PRECISION_UNSPECIFIED" {
+			p = PrecisionSecond
+		}
+		t := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(us) * time.Microsecond)
+		return Temporal{Kind: Time, Time: t, Precision: p}
+	}
+	loc := time.UTC
+	if f := d.Fields().ByName("timezone"); f != nil {
+		if l, err := parseZone(rm.Get(f).String()); err == nil {
+			loc = l
+		}
+	}
+	kind := DateTime
+	if TypeName(d) == "date" {
+		kind = Date
+	}
+	return Temporal{Kind: kind, Time: time.UnixMicro(us).In(loc), Precision: p}
+}
+
+func quantityFromProto(rm protoreflect.Message) (interface{}, bool) {
+	d := rm.Descriptor()
+	val, ok := toSystem(rm.Get(d.Fields().ByName("value")).Message().Interface())
+	x, isNum := val.(float64)
+	if !ok || !isNum {
+		return nil, false
+	}
+	q := Quantity{Value: x}
+	for _, name := range []protoreflect.Name{"code", "unit"} {
+		if f := d.Fields().ByName(name); f != nil && rm.Has(f) {
+			if s, ok := toSystem(rm.Get(f).Message().Interface()); ok {
+				q.Unit = s.(string)
+				break
+			}
+		}
+	}
+	return q, true
+}
+
+// calendarUnits maps calendar duration keywords, singular and plural, and
+// their UCUM equivalents to a canonical name.
+var calendarUnits = map[string]string{
+	"year": "year", "years": "year", "a": "year",
+	"month": "month", "months": "month", "mo": "month",
+	"week": "week", "weeks": "week", "wk": "week",
+	"day": "day", "days": "day", "d": "day",
+	"hour": "hour", "hours": "hour", "h": "hour",
+	"minute": "minute", "minutes": "minute", "min": "minute",
+	"second": "second", "seconds": "second", "s": "second",
+	"millisecond": "millisecond", "milliseconds": "millisecond", "ms": "millisecond",
+}
+
+func canonicalUnit(u string) string {
+	if c, ok := calendarUnits[u]; ok {
+		return c
+	}
+	return u
+}
+
+// addDuration adds q calendar units to t.
+func addDuration(t Temporal, q Quantity) (Temporal, error) {
+	n := q.Value
+	switch canonicalUnit(q.Unit) {
+	case "year":
+		t.Time = t.Time.AddDate(int(n), 0, 0)
+	case "month":
+		t.Time = t.Time.AddDate(0, int(n), 0)
+	case "week":
+		t.Time = t.Time.AddDate(0, 0, 7*int(n))
+	case "day":
+		t.Time = t.Time.AddDate(0, 0, int(n))
+	case "hour":
+		t.Time = t.Time.Add(time.Duration(n * float64(time.Hour)))
+	case "minute":
+		t.Time = t.Time.Add(time.Duration(n * float64(time.Minute)))
+	case "second":
+		t.Time = t.Time.Add(time.Duration(n * float64(time.Second)))
+	case "millisecond":
+		t.Time = t.Time.Add(time.Duration(n * float64(time.Millisecond)))
+	default:
+		return Temporal{}, fmt.Errorf("cannot add quantity with unit %q to a date", q.Unit)
+	}
+	return t, nil
+}
+
+// temporalParts returns the calendar fields of t in its own location.
+func temporalParts(t Temporal) [7]int {
+	tm := t.Time
+	if t.Precision > PrecisionDay && t.Kind != Time {
+		tm = tm.UTC()
+	}
+	return [7]int{tm.Year(), int(tm.Month()), tm.Day(), tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond() / 1e6}
+}
+
+// compareTemporal compares a and b. ok is false if the result is unknown
+// because the values have different precisions.
+func compareTemporal(a, b Temporal) (int, bool) {
+	if (a.Kind == Time) != (b.Kind == Time) {
+		return 0, false
+	}
+	pa, pb := temporalParts(a), temporalParts(b)
+	start := 0
+	if a.Kind == Time {
+		start = int(PrecisionHour)
+	}
+	minPrec := a.Precision
+	if b.Precision < minPrec {
+		minPrec = b.Precision
+	}
+	for i := start; i <= int(minPrec); i++ {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	if a.Precision != b.Precision {
+		// Seconds and milliseconds are a single precision in FHIRPath.
+		if minPrec >= PrecisionSecond {
+			return 0, true
+		}
+		return 0, false
+	}
+	return 0, true
+}
+
+// compareValues orders two system values of compatible types.
+func compareValues(a, b interface{}) (int, bool, error) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return cmpInt(x, y), true, nil
+		case float64:
+			return cmpFloat(float64(x), y), true, nil
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return cmpFloat(x, float64(y)), true, nil
+		case float64:
+			return cmpFloat(x, y), true, nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true, nil
+		}
+	case Temporal:
+		if y, ok := b.(Temporal); ok {
+			c, ok := compareTemporal(x, y)
+			return c, ok, nil
+		}
+	case Quantity:
+		if y, ok := b.(Quantity); ok {
+			if canonicalUnit(x.Unit) != canonicalUnit(y.Unit) {
+				return 0, false, nil
+			}
+			return cmpFloat(x.Value, y.Value), true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("cannot compare %s and %s", typeOf(a), typeOf(b))
+}
+
+func cmpInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// equalItems implements FHIRPath equality of two items. ok is false if
+// equality is unknown.
+func equalItems(a, b interface{}) (bool, bool) {
+	sa, oka := toSystem(a)
+	sb, okb := toSystem(b)
+	if !oka || !okb {
+		return false, false
+	}
+	ma, isMsgA := sa.(proto.Message)
+	mb, isMsgB := sb.(proto.Message)
+	if isMsgA || isMsgB {
+		return isMsgA && isMsgB && proto.Equal(ma, mb), true
+	}
+	if ba, ok := sa.(bool); ok {
+		bb, ok := sb.(bool)
+		return ok && ba == bb, true
+	}
+	c, ok, err := compareValues(sa, sb)
+	if err != nil {
+		return false, true
+	}
+	if !ok {
+		return false, false
+	}
+	return c == 0, true
+}
+
+// equivalentItems implements FHIRPath equivalence of two items.
+func equivalentItems(a, b interface{}) bool {
+	sa, oka := toSystem(a)
+	sb, okb := toSystem(b)
+	if !oka || !okb {
+		return oka == okb
+	}
+	switch x := sa.(type) {
+	case string:
+		y, ok := sb.(string)
+		return ok && normalizeSpace(x) == normalizeSpace(y)
+	case float64, int64:
+		fx, _ := toFloat(x)
+		fy, ok := toFloat(sb)
+		if !ok {
+			return false
+		}
+		p := decimalPlaces(fx)
+		if q := decimalPlaces(fy); q < p {
+			p = q
+		}
+		scale := math.Pow(10, float64(p))
+		return math.Round(fx*scale) == math.Round(fy*scale)
+	case Temporal:
+		y, ok := sb.(Temporal)
+		if !ok {
+			return false
+		}
+		c, known := compareTemporal(x, y)
+		return known && c == 0
+	}
+	eq, _ := equalItems(sa, sb)
+	return eq
+}
+
+func normalizeSpace(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func decimalPlaces(f float64) int {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// typeOf returns the FHIRPath type name of an item, i.e. "System.Integer" or
+// "FHIR.Patient".
+func typeOf(v interface{}) string {
+	switch x := v.(type) {
+	case bool:
+		return "System.Boolean"
+	case string:
+		return "System.String"
+	case int64:
+		return "System.Integer"
+	case float64:
+		return "System.Decimal"
+	case Quantity:
+		return "System.Quantity"
+	case Temporal:
+		return "System." + [...]string{"Date", "DateTime", "Time"}[x.Kind]
+	case proto.Message:
+		return "FHIR." + TypeName(x.ProtoReflect().Descriptor())
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// formatValue renders a system value as a FHIRPath string.
+func formatValue(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case bool:
+		return strconv.FormatBool(x), true
+	case string:
+		return x, true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case Temporal:
+		return x.String(), true
+	case Quantity:
+		return x.String(), true
+	}
+	return "", false
+}
+
+func dateProto(t Temporal) *d4pb.Date {
+	prec := d4pb.Date_DAY
+	switch t.Precision {
+	case PrecisionYear:
+		prec = d4pb.Date_YEAR
+	case PrecisionMonth:
+		prec = d4pb.Date_MONTH
+	}
+	tm := t.Time
+	day := time.Date(tm.Year(), tm.Month(), tm.Day(), 0, 0, 0, 0, tm.Location())
+	return &d4pb.Date{ValueUs: day.UnixMicro(), Timezone: timezone(tm), Precision: prec}
+}
+
+func dateTimeProto(t Temporal) *d4pb.DateTime {
+	var prec d4pb.DateTime_Precision
+	switch t.Precision {
+	case PrecisionYear:
+		prec = d4pb.DateTime_YEAR
+	case PrecisionMonth:
+		prec = d4pb.DateTime_MONTH
+	case PrecisionDay:
+		prec = d4pb.DateTime_DAY
+	case PrecisionMillisecond:
+		prec = d4pb.DateTime_MILLISECOND
+	default:
+		prec = d4pb.DateTime_SECOND
+	}
+	return &d4pb.DateTime{ValueUs: t.Time.UnixMicro(), Timezone: timezone(t.Time), Precision: prec}
+}
+
+func timeProto(t Temporal) *d4pb.Time {
+	tm := t.Time
+	us := (int64(tm.Hour())*3600+int64(tm.Minute())*60+int64(tm.Second()))*1e6 + int64(tm.Nanosecond()/1000)
+	prec := d4pb.Time_SECOND
+	if t.Precision == PrecisionMillisecond {
+		prec = d4pb.Time_MILLISECOND
+	}
+	return &d4pb.Time{ValueUs: us, Precision: prec}
+}
+
+// timezone returns the FHIR proto timezone of t: "Z" for UTC, the IANA name
+// for named locations and a "+hh:mm" offset otherwise.
+func timezone(t time.Time) string {
+	loc := t.Location()
+	if loc == time.UTC {
+		return "Z"
+	}
+	if name := loc.String(); name != "Local" && name != "" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
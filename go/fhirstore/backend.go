@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirstore
+
+import (
+	"context"
+)
+
+// Backend is the interface storage adapters implement, so that a Store can
+// be built on any database with transactions, such as PostgreSQL, Spanner
+// or Bigtable, while search indexing and history retention are handled once,
+// by the Store and its IndexHooks. Adapters store versions as they are
+// given; the Store numbers them, sets their meta and decides which to keep.
+type Backend interface {
+	// Begin starts a transaction, which only reads if readOnly is set.
+	Begin(ctx context.Context, readOnly bool) (Tx, error)
+}
+
+// Tx is a transaction of a Backend. The changes of a transaction are only
+// visible to others once it commits, and the transactions of a Backend must
+// be serializable with respect to the resources they write, which Put
+// checks optimistically. Versions passed to and returned by a Tx are not
+// shared with the Backend.
+type Tx interface {
+	// Get returns the current version of the resource of resourceType with
+	// the id, which is Deleted if the resource was deleted, or an error
+	// wrapping ErrNotFound.
+	Get(ctx context.Context, resourceType, id string) (*Version, error)
+	// GetVersion returns a version of the resource, or an error wrapping
+	// ErrNotFound.
+	GetVersion(ctx context.Context, resourceType, id, versionID string) (*Version, error)
+	// Put writes v as the current version of its resource, if the current
+	// version is ifVersion, or the resource does not exist if ifVersion is
+	// "", and otherwise fails with an error wrapping ErrVersionConflict.
+	// Backends may report the conflict when the transaction commits
+	// instead.
+	Put(ctx context.Context, v *Version, ifVersion string) error
+	// History returns the versions of the resource of resourceType with the
+	// id, newest first. If id is "", it returns the versions of every
+	// resource of resourceType, and if both are "", of every resource.
+	History(ctx context.Context, resourceType, id string) ([]*Version, error)
+	// Scan calls fn with the current version of every resource of
+	// resourceType, or of every type if it is "", that is not deleted,
+	// stopping at the first error fn returns.
+	Scan(ctx context.Context, resourceType string, fn func(*Version) error) error
+	// DeleteVersions removes versions of the resource of resourceType with
+	// the id from its history. The current version is never removed.
+	DeleteVersions(ctx context.Context, resourceType, id string, versionIDs []string) error
+	// Commit commits the transaction.
+	Commit(ctx context.Context) error
+	// Rollback discards the changes of the transaction. It may be called
+	// after Commit, when it does nothing.
+	Rollback() error
+}
+
+// IndexHook is called by a Store on every write, in the transaction of the
+// write, so that indexes such as those of search parameters are updated
+// atomically with the resources. prev is the version replaced, or nil for
+// new resources, and next the version written, which is Deleted for
+// deletions. An error aborts the write.
+type IndexHook interface {
+	Index(ctx context.Context, tx Tx, prev, next *Version) error
+}
+
+// IndexHookFunc is an IndexHook of a function.
+type IndexHookFunc func(ctx context.Context, tx Tx, prev, next *Version) error
+
+// Index calls f.
+func (f IndexHookFunc) Index(ctx context.Context, tx Tx, prev, next *Version) error {
+	return f(ctx, tx, prev, next)
+}
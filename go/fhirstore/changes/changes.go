@@ -0,0 +1,279 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package changes streams the writes of a fhirstore.Store as events, to
+// drive subscriptions and downstream pipelines.
+//
+// A Feed is a fhirstore.Listener that queues an Event for every committed
+// write and delivers them, in order and in batches, to a Sink:
+//
+//	feed := changes.NewFeed(sink, changes.FeedOptions{})
+//	store := memory.NewWithOptions(fhirstore.Options{Listeners: []fhirstore.Listener{feed}})
+//	go feed.Run(ctx)
+//
+// Delivery is at least once: a batch is retried, with increasing delays,
+// until the Sink accepts it, so sinks must tolerate events they have seen,
+// identified by their resource type, id and version. Events are queued in
+// memory; after a restart, Replay rebuilds the events of the writes since
+// the last event a sink processed from the history of the store.
+//
+// Events carry Handles to the versions before and after the write rather
+// than the resources, which sinks read from the store if they need them.
+package changes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/fhir/go/fhirstore"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// Interaction is the kind of write of an event, named after the
+// interaction of the FHIR RESTful API.
+type Interaction string
+
+// Interactions of events.
+const (
+	Create Interaction = "create"
+	Update Interaction = "update"
+	Delete Interaction = "delete"
+)
+
+// Handle identifies a version of a resource.
+type Handle struct {
+	ResourceType, ID, VersionID string
+}
+
+// String returns the relative reference of h, i.e.
+// "Patient/1/_history/2".
+func (h Handle) String() string {
+	return h.ResourceType + "/" + h.ID + "/_history/" + h.VersionID
+}
+
+// Resolve reads the version of h from store. It fails if the version was
+// pruned from the history of the resource.
+func (h Handle) Resolve(ctx context.Context, store fhirstore.ResourceStore) (*r4pb.ContainedResource, error) {
+	return store.VRead(ctx, h.ResourceType, h.ID, h.VersionID)
+}
+
+// Event is a write of a resource.
+type Event struct {
+	// Seq numbers the events of a Feed, from 1.
+	Seq         int64
+	Interaction Interaction
+	// ResourceType, ID and VersionID identify the version written, which is
+	// the deletion for Delete events.
+	ResourceType, ID, VersionID string
+	// Time is the time of the write.
+	Time time.Time
+	// Before is the version replaced, nil for Create events, and After the
+	// version written, nil for Delete events.
+	Before, After *Handle
+}
+
+// EventOf returns the event of the write of next after prev, as passed to
+// fhirstore.Listeners, without a sequence number.
+func EventOf(prev, next *fhirstore.Version) Event {
+	e := Event{
+		Interaction:  Update,
+		ResourceType: next.ResourceType,
+		ID:           next.ID,
+		VersionID:    next.VersionID,
+		Time:         next.LastUpdated,
+	}
+	if prev != nil && !prev.Deleted {
+		e.Before = &Handle{prev.ResourceType, prev.ID, prev.VersionID}
+	} else {
+		e.Interaction = Create
+	}
+	if next.Deleted {
+		e.Interaction = Delete
+	} else {
+		e.After = &Handle{next.ResourceType, next.ID, next.VersionID}
+	}
+	return e
+}
+
+// Sink receives events.
+type Sink interface {
+	// Send processes events, in order. An error has the events sent again.
+	Send(ctx context.Context, events []Event) error
+}
+
+// SinkFunc is a Sink of a function.
+type SinkFunc func(ctx context.Context, events []Event) error
+
+// Send calls f.
+func (f SinkFunc) Send(ctx context.Context, events []Event) error {
+	return f(ctx, events)
+}
+
+// FeedOptions configures a Feed.
+type FeedOptions struct {
+	// BatchSize is the largest number of events sent at once; 100 if 0.
+	BatchSize int
+	// RetryDelay is the delay before a failed batch is sent again, which
+	// doubles with every failure up to MaxRetryDelay; 100ms and 30s if 0.
+	RetryDelay, MaxRetryDelay time.Duration
+	// OnError is called with the errors of the Sink, i.e. to log them.
+	OnError func(error)
+}
+
+// Feed delivers the events of the writes of a store to a Sink. It is a
+// fhirstore.Listener.
+type Feed struct {
+	sink Sink
+	opts FeedOptions
+
+	mu        sync.Mutex
+	queue     []Event
+	seq       int64
+	delivered int64
+	// notify is signaled when events are queued.
+	notify chan struct{}
+}
+
+var _ fhirstore.Listener = (*Feed)(nil)
+
+// NewFeed returns a Feed delivering to sink.
+func NewFeed(sink Sink, opts FeedOptions) *Feed {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = 100 * time.Millisecond
+	}
+	if opts.MaxRetryDelay <= 0 {
+		opts.MaxRetryDelay = 30 * time.Second
+	}
+	return &Feed{sink: sink, opts: opts, notify: make(chan struct{}, 1)}
+}
+
+// Written implements fhirstore.Listener, queuing the event of the write.
+func (f *Feed) Written(ctx context.Context, prev, next *fhirstore.Version) {
+	e := EventOf(prev, next)
+	f.mu.Lock()
+	f.seq++
+	e.Seq = f.seq
+	f.queue = append(f.queue, e)
+	f.mu.Unlock()
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Delivered returns the sequence number of the last event the Sink
+// accepted, or 0 if it has accepted none.
+func (f *Feed) Delivered() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.delivered
+}
+
+// Pending returns the number of events not yet delivered.
+func (f *Feed) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.queue)
+}
+
+// Run delivers events until ctx is done, and returns its error.
+func (f *Feed) Run(ctx context.Context) error {
+	delay := f.opts.RetryDelay
+	for {
+		f.mu.Lock()
+		batch := f.queue
+		if len(batch) > f.opts.BatchSize {
+			batch = batch[:f.opts.BatchSize]
+		}
+		batch = append([]Event(nil), batch...)
+		f.mu.Unlock()
+		if len(batch) == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-f.notify:
+			}
+			continue
+		}
+		if err := f.sink.Send(ctx, batch); err != nil {
+			if f.opts.OnError != nil {
+				f.opts.OnError(fmt.Errorf("sending events %d to %d: %w", batch[0].Seq, batch[len(batch)-1].Seq, err))
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > f.opts.MaxRetryDelay {
+				delay = f.opts.MaxRetryDelay
+			}
+			continue
+		}
+		delay = f.opts.RetryDelay
+		f.mu.Lock()
+		f.queue = f.queue[len(batch):]
+		f.delivered = batch[len(batch)-1].Seq
+		f.mu.Unlock()
+	}
+}
+
+// Replay sends sink the events of the writes of store after since, oldest
+// first and in batches of up to batchSize, as when a sink restarts from the
+// time of the last event it processed. The events have no sequence numbers.
+// Writes whose versions were pruned from the history of the store are not
+// replayed, and the events of the writes after them have no Before.
+func Replay(ctx context.Context, store fhirstore.ResourceStore, since time.Time, sink Sink, batchSize int) error {
+	versions, err := store.History(ctx, "", "")
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	type key struct{ typ, id string }
+	last := map[key]*fhirstore.Version{}
+	var batch []Event
+	// History is newest first; events are sent oldest first.
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := &versions[i]
+		k := key{v.ResourceType, v.ID}
+		prev := last[k]
+		last[k] = v
+		if !v.LastUpdated.After(since) {
+			continue
+		}
+		e := EventOf(prev, v)
+		if prev == nil && e.Interaction == Create && v.VersionID != "1" {
+			// The versions before v were pruned.
+			e.Interaction = Update
+		}
+		batch = append(batch, e)
+		if len(batch) == batchSize {
+			if err := sink.Send(ctx, batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		return sink.Send(ctx, batch)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirstore"
+	"github.com/google/fhir/go/fhirstore/memory"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func patient(id, family string) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{
+		Id:   &d4pb.Id{Value: id},
+		Name: []*d4pb.HumanName{{Family: &d4pb.String{Value: family}}},
+	}}}
+}
+
+// write updates Patient/a twice, deletes it and creates it again.
+func write(t *testing.T, s fhirstore.ResourceStore) {
+	t.Helper()
+	ctx := context.Background()
+	for _, family := range []string{"Doe", "Roe"} {
+		if _, err := s.Update(ctx, patient("a", family), ""); err != nil {
+			t.Fatalf("Update() returned unexpected error: %v", err)
+		}
+	}
+	if err := s.Delete(ctx, "Patient", "a"); err != nil {
+		t.Fatalf("Delete() returned unexpected error: %v", err)
+	}
+	if _, err := s.Update(ctx, patient("a", "Poe"), ""); err != nil {
+		t.Fatalf("Update() returned unexpected error: %v", err)
+	}
+}
+
+var wantEvents = []Event{
+	{Seq: 1, Interaction: Create, ResourceType: "Patient", ID: "a", VersionID: "1", After: &Handle{"Patient", "a", "1"}},
+	{Seq: 2, Interaction: Update, ResourceType: "Patient", ID: "a", VersionID: "2", Before: &Handle{"Patient", "a", "1"}, After: &Handle{"Patient", "a", "2"}},
+	{Seq: 3, Interaction: Delete, ResourceType: "Patient", ID: "a", VersionID: "3", Before: &Handle{"Patient", "a", "2"}},
+	{Seq: 4, Interaction: Create, ResourceType: "Patient", ID: "a", VersionID: "4", After: &Handle{"Patient", "a", "4"}},
+}
+
+func TestFeed(t *testing.T) {
+	var mu sync.Mutex
+	var got []Event
+	fail := true
+	sink := SinkFunc(func(ctx context.Context, events []Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, events...)
+		if fail {
+			fail = false
+			return errors.New("sink unavailable")
+		}
+		return nil
+	})
+	var errs []error
+	feed := NewFeed(sink, FeedOptions{BatchSize: 3, RetryDelay: time.Millisecond, OnError: func(err error) { errs = append(errs, err) }})
+	s := memory.NewWithOptions(fhirstore.Options{Listeners: []fhirstore.Listener{feed}})
+	write(t, s)
+	if got := feed.Pending(); got != 4 {
+		t.Errorf("Pending() = %d before Run, want 4", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- feed.Run(ctx) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for feed.Pending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() returned %v, want %v", err, context.Canceled)
+	}
+
+	// The first batch is sent again after the Sink fails.
+	want := append(append([]Event(nil), wantEvents[:3]...), wantEvents...)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Event{}, "Time")); diff != "" {
+		t.Errorf("sent events diff (-want +got):\n%s", diff)
+	}
+	if got := feed.Delivered(); got != 4 {
+		t.Errorf("Delivered() = %d, want 4", got)
+	}
+	if len(errs) != 1 {
+		t.Errorf("OnError() called with %v, want one error", errs)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	s := memory.NewWithOptions(fhirstore.Options{})
+	write(t, s)
+	var got []Event
+	sink := SinkFunc(func(ctx context.Context, events []Event) error {
+		got = append(got, events...)
+		return nil
+	})
+	if err := Replay(context.Background(), s, time.Time{}, sink, 2); err != nil {
+		t.Fatalf("Replay() returned unexpected error: %v", err)
+	}
+	var want []Event
+	for _, e := range wantEvents {
+		e.Seq = 0
+		want = append(want, e)
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Event{}, "Time")); diff != "" {
+		t.Errorf("Replay() events diff (-want +got):\n%s", diff)
+	}
+
+	got = nil
+	if err := Replay(context.Background(), s, time.Now().Add(time.Hour), sink, 2); err != nil {
+		t.Fatalf("Replay() returned unexpected error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Replay() since a later time sent %v, want no events", got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s := memory.New()
+	write(t, s)
+	res, err := Handle{"Patient", "a", "2"}.Resolve(context.Background(), s)
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	if got := res.GetPatient().GetName()[0].GetFamily().GetValue(); got != "Roe" {
+		t.Errorf("Resolve() family = %q, want %q", got, "Roe")
+	}
+	if got, want := (Handle{"Patient", "a", "2"}).String(), "Patient/a/_history/2"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fhirstore defines ResourceStore, the interface of versioned stores
+// of FHIR R4 resources that support the interactions of the FHIR RESTful
+// API: create, read, vread, update, delete, search and history, and searches
+// within patient compartments.
+//
+// Stores keep every version of a resource, numbering them "1", "2" and so
+// on, and set the meta.versionId and meta.lastUpdated of the resources they
+// write. Match evaluates search parameters against resources, for stores
+// that cannot translate them to queries of their own.
+//
+// Store implements ResourceStore over a Backend, the interface storage
+// adapters implement: transactions that read and write versions, with
+// optimistic locking. The Store numbers versions, runs IndexHooks in the
+// transaction of every write and prunes history according to its Retention,
+// so adapters for other databases only store what they are given, and tells
+// its Listeners of the writes it commits, which package changes streams as
+// events. Package memory has a Backend, and a Store over it, that hold
+// resources in memory.
+package fhirstore
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+var (
+	// ErrNotFound is returned for resources and versions that do not
+	// exist.
+	ErrNotFound = errors.New("resource not found")
+	// ErrDeleted is returned for reads of deleted resources, to which
+	// servers respond with 410 Gone rather than 404 Not Found.
+	ErrDeleted = errors.New("resource deleted")
+	// ErrVersionConflict is returned for updates conditional on a version
+	// that is not the current version of the resource.
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// PatientCompartment is the name of the patient compartment.
+const PatientCompartment = "Patient"
+
+// ResourceStore is a versioned store of resources. Resources are passed and
+// returned in ContainedResources, and returned resources are copies that
+// callers may modify.
+type ResourceStore interface {
+	// Create stores res as a new resource with a new id, which replaces any
+	// id of res, and returns it as stored.
+	Create(ctx context.Context, res *r4pb.ContainedResource) (*r4pb.ContainedResource, error)
+	// Read returns the current version of the resource of resourceType
+	// with the id.
+	Read(ctx context.Context, resourceType, id string) (*r4pb.ContainedResource, error)
+	// VRead returns a version of the resource of resourceType with the id.
+	VRead(ctx context.Context, resourceType, id, version string) (*r4pb.ContainedResource, error)
+	// Update stores res as a new version of the resource with its id,
+	// creating it if it does not exist, and returns it as stored. If
+	// ifMatch is not "", the update fails with ErrVersionConflict unless
+	// it is the current version of the resource.
+	Update(ctx context.Context, res *r4pb.ContainedResource, ifMatch string) (*r4pb.ContainedResource, error)
+	// Delete deletes the resource of resourceType with the id, recording
+	// the deletion as a version without a resource. Deleting a deleted
+	// resource succeeds.
+	Delete(ctx context.Context, resourceType, id string) error
+	// Search returns the current resources of resourceType that match the
+	// search parameters params, as by Match. The _count parameter limits
+	// the number of resources returned.
+	Search(ctx context.Context, resourceType string, params url.Values) ([]*r4pb.ContainedResource, error)
+	// History returns the versions of the resource of resourceType with the
+	// id, newest first. If id is "", it returns the versions of every
+	// resource of resourceType, and if both are "", of every resource.
+	History(ctx context.Context, resourceType, id string) ([]Version, error)
+	// Compartment searches for the resources of resourceType in the
+	// compartment with the id, as by InCompartment, or for those of every
+	// type if resourceType is "".
+	Compartment(ctx context.Context, compartment, id, resourceType string, params url.Values) ([]*r4pb.ContainedResource, error)
+}
+
+// Version is a version of a resource.
+type Version struct {
+	ResourceType, ID, VersionID string
+	LastUpdated                 time.Time
+	// Deleted is set for the versions recording deletions, which have no
+	// resource.
+	Deleted  bool
+	Resource *r4pb.ContainedResource
+}
@@ -0,0 +1,292 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memory holds FHIR resources in memory, for tests, caches and small
+// servers: Backend is a fhirstore.Backend, the reference for adapters of
+// other databases, and Store the fhirstore.Store over one. Both are safe for
+// concurrent use.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/fhir/go/fhirstore"
+	"google.golang.org/protobuf/proto"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// Store is a fhirstore.Store over a Backend.
+type Store struct {
+	*fhirstore.Store
+}
+
+// New returns an empty Store, which numbers the resources it creates "1",
+// "2" and so on.
+func New() *Store {
+	return NewWithOptions(fhirstore.Options{})
+}
+
+// NewWithOptions returns an empty Store with the options, numbering the
+// resources it creates unless opts sets NewID.
+func NewWithOptions(opts fhirstore.Options) *Store {
+	if opts.NewID == nil {
+		var n int64
+		opts.NewID = func() string { return strconv.FormatInt(atomic.AddInt64(&n, 1), 10) }
+	}
+	return &Store{fhirstore.NewStore(NewBackend(), opts)}
+}
+
+// Backend is an in-memory fhirstore.Backend. Transactions that write run one
+// at a time, and hold off those that read.
+type Backend struct {
+	mu sync.RWMutex
+	// resources holds the resources by type and id.
+	resources map[string]map[string]*resource
+	// seq numbers the creations of resources and the versions written,
+	// which order scans and histories.
+	seq int
+}
+
+type resource struct {
+	created int
+	// versions are the versions of the resource, oldest first, and seqs
+	// their sequence numbers.
+	versions []*fhirstore.Version
+	seqs     []int
+}
+
+var _ fhirstore.Backend = (*Backend)(nil)
+
+// NewBackend returns an empty Backend.
+func NewBackend() *Backend {
+	return &Backend{resources: map[string]map[string]*resource{}}
+}
+
+// Begin implements fhirstore.Backend.
+func (b *Backend) Begin(ctx context.Context, readOnly bool) (fhirstore.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if readOnly {
+		b.mu.RLock()
+	} else {
+		b.mu.Lock()
+	}
+	return &tx{b: b, readOnly: readOnly}, nil
+}
+
+// tx is a transaction of a Backend, which holds its lock until it ends.
+// Writes apply immediately, recording how to undo them on rollback.
+type tx struct {
+	b        *Backend
+	readOnly bool
+	done     bool
+	undo     []func()
+}
+
+func (t *tx) end() error {
+	if t.done {
+		return fmt.Errorf("transaction has ended")
+	}
+	t.done = true
+	if t.readOnly {
+		t.b.mu.RUnlock()
+	} else {
+		t.b.mu.Unlock()
+	}
+	return nil
+}
+
+func (t *tx) Commit(ctx context.Context) error {
+	return t.end()
+}
+
+func (t *tx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	return t.end()
+}
+
+func (t *tx) check(write bool) error {
+	switch {
+	case t.done:
+		return fmt.Errorf("transaction has ended")
+	case write && t.readOnly:
+		return fmt.Errorf("write in a read-only transaction")
+	}
+	return nil
+}
+
+func clone(v *fhirstore.Version) *fhirstore.Version {
+	c := *v
+	if v.Resource != nil {
+		c.Resource = proto.Clone(v.Resource).(*r4pb.ContainedResource)
+	}
+	return &c
+}
+
+func (t *tx) Get(ctx context.Context, resourceType, id string) (*fhirstore.Version, error) {
+	if err := t.check(false); err != nil {
+		return nil, err
+	}
+	r, ok := t.b.resources[resourceType][id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", fhirstore.ErrNotFound, resourceType, id)
+	}
+	return clone(r.versions[len(r.versions)-1]), nil
+}
+
+func (t *tx) GetVersion(ctx context.Context, resourceType, id, versionID string) (*fhirstore.Version, error) {
+	if err := t.check(false); err != nil {
+		return nil, err
+	}
+	if r, ok := t.b.resources[resourceType][id]; ok {
+		for _, v := range r.versions {
+			if v.VersionID == versionID {
+				return clone(v), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s/_history/%s", fhirstore.ErrNotFound, resourceType, id, versionID)
+}
+
+func (t *tx) Put(ctx context.Context, v *fhirstore.Version, ifVersion string) error {
+	if err := t.check(true); err != nil {
+		return err
+	}
+	byID := t.b.resources[v.ResourceType]
+	if byID == nil {
+		byID = map[string]*resource{}
+		t.b.resources[v.ResourceType] = byID
+	}
+	r, ok := byID[v.ID]
+	current := ""
+	if ok {
+		current = r.versions[len(r.versions)-1].VersionID
+	}
+	if current != ifVersion {
+		return fmt.Errorf("%w: %s/%s is at version %q, not %q", fhirstore.ErrVersionConflict, v.ResourceType, v.ID, current, ifVersion)
+	}
+	seq := t.b.seq
+	t.b.seq++
+	if !ok {
+		r = &resource{created: t.b.seq}
+		byID[v.ID] = r
+	}
+	r.versions = append(r.versions, clone(v))
+	r.seqs = append(r.seqs, t.b.seq)
+	t.undo = append(t.undo, func() {
+		t.b.seq = seq
+		if !ok {
+			delete(byID, v.ID)
+			return
+		}
+		r.versions = r.versions[:len(r.versions)-1]
+		r.seqs = r.seqs[:len(r.seqs)-1]
+	})
+	return nil
+}
+
+func (t *tx) DeleteVersions(ctx context.Context, resourceType, id string, versionIDs []string) error {
+	if err := t.check(true); err != nil {
+		return err
+	}
+	r, ok := t.b.resources[resourceType][id]
+	if !ok {
+		return fmt.Errorf("%w: %s/%s", fhirstore.ErrNotFound, resourceType, id)
+	}
+	drop := map[string]bool{}
+	for _, v := range versionIDs {
+		drop[v] = true
+	}
+	versions, seqs := r.versions, r.seqs
+	r.versions, r.seqs = nil, nil
+	for i, v := range versions {
+		if !drop[v.VersionID] || i == len(versions)-1 {
+			r.versions = append(r.versions, v)
+			r.seqs = append(r.seqs, seqs[i])
+		}
+	}
+	t.undo = append(t.undo, func() { r.versions, r.seqs = versions, seqs })
+	return nil
+}
+
+func (t *tx) History(ctx context.Context, resourceType, id string) ([]*fhirstore.Version, error) {
+	if err := t.check(false); err != nil {
+		return nil, err
+	}
+	type entry struct {
+		v   *fhirstore.Version
+		seq int
+	}
+	var all []entry
+	for typ, byID := range t.b.resources {
+		if resourceType != "" && typ != resourceType {
+			continue
+		}
+		for rid, r := range byID {
+			if id != "" && rid != id {
+				continue
+			}
+			for i, v := range r.versions {
+				all = append(all, entry{v, r.seqs[i]})
+			}
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].seq > all[j].seq })
+	out := make([]*fhirstore.Version, len(all))
+	for i, e := range all {
+		out[i] = clone(e.v)
+	}
+	return out, nil
+}
+
+// Scan implements fhirstore.Tx, calling fn in the order the resources were
+// created.
+func (t *tx) Scan(ctx context.Context, resourceType string, fn func(*fhirstore.Version) error) error {
+	if err := t.check(false); err != nil {
+		return err
+	}
+	var rs []*resource
+	for typ, byID := range t.b.resources {
+		if resourceType != "" && typ != resourceType {
+			continue
+		}
+		for _, r := range byID {
+			if !r.versions[len(r.versions)-1].Deleted {
+				rs = append(rs, r)
+			}
+		}
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].created < rs[j].created })
+	for _, r := range rs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(clone(r.versions[len(r.versions)-1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
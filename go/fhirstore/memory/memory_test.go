@@ -0,0 +1,325 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirstore"
+	"github.com/google/go-cmp/cmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func patient(id, family string) *r4pb.ContainedResource {
+	p := &ppb.Patient{Name: []*d4pb.HumanName{{Family: &d4pb.String{Value: family}}}}
+	if id != "" {
+		p.Id = &d4pb.Id{Value: id}
+	}
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: p}}
+}
+
+func observation(subject string) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: &obspb.Observation{
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: subject}}},
+	}}}
+}
+
+// clock returns a Now function whose time advances by a second on every
+// call.
+func clock() func() time.Time {
+	t := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		t = t.Add(time.Second)
+		return t
+	}
+}
+
+func newStore() *Store {
+	return NewWithOptions(fhirstore.Options{Now: clock()})
+}
+
+func ids(rs []*r4pb.ContainedResource) []string {
+	var out []string
+	for _, r := range rs {
+		if p := r.GetPatient(); p != nil {
+			out = append(out, "Patient/"+p.GetId().GetValue())
+		} else {
+			out = append(out, "Observation/"+r.GetObservation().GetId().GetValue())
+		}
+	}
+	return out
+}
+
+func TestVersions(t *testing.T) {
+	ctx := context.Background()
+	s := newStore()
+	created, err := s.Create(ctx, patient("ignored", "Doe"))
+	if err != nil {
+		t.Fatalf("Create() returned unexpected error: %v", err)
+	}
+	id := created.GetPatient().GetId().GetValue()
+	if id == "ignored" || created.GetPatient().GetMeta().GetVersionId().GetValue() != "1" {
+		t.Fatalf("Create() = %v, want a new id at version 1", created)
+	}
+	if _, err := s.Update(ctx, patient(id, "Roe"), "2"); !errors.Is(err, fhirstore.ErrVersionConflict) {
+		t.Errorf("Update() with a stale version returned %v, want %v", err, fhirstore.ErrVersionConflict)
+	}
+	updated, err := s.Update(ctx, patient(id, "Roe"), "1")
+	if err != nil {
+		t.Fatalf("Update() returned unexpected error: %v", err)
+	}
+	if got := updated.GetPatient().GetMeta().GetVersionId().GetValue(); got != "2" {
+		t.Errorf("Update() version = %q, want %q", got, "2")
+	}
+	read, err := s.Read(ctx, "Patient", id)
+	if err != nil {
+		t.Fatalf("Read() returned unexpected error: %v", err)
+	}
+	if got := read.GetPatient().GetName()[0].GetFamily().GetValue(); got != "Roe" {
+		t.Errorf("Read() family = %q, want %q", got, "Roe")
+	}
+	v1, err := s.VRead(ctx, "Patient", id, "1")
+	if err != nil {
+		t.Fatalf("VRead() returned unexpected error: %v", err)
+	}
+	if got := v1.GetPatient().GetName()[0].GetFamily().GetValue(); got != "Doe" {
+		t.Errorf("VRead() family = %q, want %q", got, "Doe")
+	}
+	if err := s.Delete(ctx, "Patient", id); err != nil {
+		t.Fatalf("Delete() returned unexpected error: %v", err)
+	}
+	if err := s.Delete(ctx, "Patient", id); err != nil {
+		t.Errorf("Delete() of a deleted resource returned unexpected error: %v", err)
+	}
+	if _, err := s.Read(ctx, "Patient", id); !errors.Is(err, fhirstore.ErrDeleted) {
+		t.Errorf("Read() of a deleted resource returned %v, want %v", err, fhirstore.ErrDeleted)
+	}
+	if _, err := s.Read(ctx, "Patient", "missing"); !errors.Is(err, fhirstore.ErrNotFound) {
+		t.Errorf("Read() of a missing resource returned %v, want %v", err, fhirstore.ErrNotFound)
+	}
+	if _, err := s.VRead(ctx, "Patient", id, "9"); !errors.Is(err, fhirstore.ErrNotFound) {
+		t.Errorf("VRead() of a missing version returned %v, want %v", err, fhirstore.ErrNotFound)
+	}
+
+	history, err := s.History(ctx, "Patient", id)
+	if err != nil {
+		t.Fatalf("History() returned unexpected error: %v", err)
+	}
+	var got []string
+	for _, v := range history {
+		if v.Deleted {
+			got = append(got, v.VersionID+" deleted")
+			continue
+		}
+		got = append(got, v.VersionID+" "+v.Resource.GetPatient().GetName()[0].GetFamily().GetValue())
+	}
+	if diff := cmp.Diff([]string{"3 deleted", "2 Roe", "1 Doe"}, got); diff != "" {
+		t.Errorf("History() diff (-want +got):\n%s", diff)
+	}
+	if !history[1].LastUpdated.After(history[2].LastUpdated) {
+		t.Errorf("History() last updated times are not increasing: %v, %v", history[2].LastUpdated, history[1].LastUpdated)
+	}
+}
+
+func TestIsolation(t *testing.T) {
+	ctx := context.Background()
+	s := newStore()
+	in := patient("a", "Doe")
+	out, err := s.Update(ctx, in, "")
+	if err != nil {
+		t.Fatalf("Update() returned unexpected error: %v", err)
+	}
+	in.GetPatient().Name[0].Family.Value = "changed"
+	out.GetPatient().Name[0].Family.Value = "changed"
+	read, err := s.Read(ctx, "Patient", "a")
+	if err != nil {
+		t.Fatalf("Read() returned unexpected error: %v", err)
+	}
+	if got := read.GetPatient().GetName()[0].GetFamily().GetValue(); got != "Doe" {
+		t.Errorf("Read() family = %q after changing the written and returned resources, want %q", got, "Doe")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	s := newStore()
+	for _, p := range []*r4pb.ContainedResource{patient("a", "Doe"), patient("b", "Dole"), patient("c", "Roe")} {
+		if _, err := s.Update(ctx, p, ""); err != nil {
+			t.Fatalf("Update() returned unexpected error: %v", err)
+		}
+	}
+	for _, o := range []*r4pb.ContainedResource{observation("a"), observation("c")} {
+		if _, err := s.Create(ctx, o); err != nil {
+			t.Fatalf("Create() returned unexpected error: %v", err)
+		}
+	}
+	if err := s.Delete(ctx, "Patient", "b"); err != nil {
+		t.Fatalf("Delete() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		name  string
+		query string
+		run   func(url.Values) ([]*r4pb.ContainedResource, error)
+		want  []string
+	}{
+		{
+			name:  "search",
+			query: "family=do",
+			run:   func(q url.Values) ([]*r4pb.ContainedResource, error) { return s.Search(ctx, "Patient", q) },
+			want:  []string{"Patient/a"},
+		},
+		{
+			name:  "count",
+			query: "_count=1",
+			run:   func(q url.Values) ([]*r4pb.ContainedResource, error) { return s.Search(ctx, "Patient", q) },
+			want:  []string{"Patient/a"},
+		},
+		{
+			name: "compartment",
+			run: func(q url.Values) ([]*r4pb.ContainedResource, error) {
+				return s.Compartment(ctx, fhirstore.PatientCompartment, "c", "", q)
+			},
+			want: []string{"Patient/c", "Observation/2"},
+		},
+		{
+			name: "compartment of type",
+			run: func(q url.Values) ([]*r4pb.ContainedResource, error) {
+				return s.Compartment(ctx, fhirstore.PatientCompartment, "a", "Observation", q)
+			},
+			want: []string{"Observation/1"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tc.run(q)
+			if err != nil {
+				t.Fatalf("returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, ids(got)); diff != "" {
+				t.Errorf("diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRetention(t *testing.T) {
+	ctx := context.Background()
+	s := NewWithOptions(fhirstore.Options{Now: clock(), Retention: fhirstore.Retention{MaxVersions: 2}})
+	for _, family := range []string{"A", "B", "C"} {
+		if _, err := s.Update(ctx, patient("a", family), ""); err != nil {
+			t.Fatalf("Update() returned unexpected error: %v", err)
+		}
+	}
+	history, err := s.History(ctx, "Patient", "a")
+	if err != nil {
+		t.Fatalf("History() returned unexpected error: %v", err)
+	}
+	var got []string
+	for _, v := range history {
+		got = append(got, v.VersionID)
+	}
+	if diff := cmp.Diff([]string{"3", "2"}, got); diff != "" {
+		t.Errorf("History() versions diff (-want +got):\n%s", diff)
+	}
+	if _, err := s.VRead(ctx, "Patient", "a", "1"); !errors.Is(err, fhirstore.ErrNotFound) {
+		t.Errorf("VRead() of a pruned version returned %v, want %v", err, fhirstore.ErrNotFound)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+	var writes []string
+	fail := false
+	hook := fhirstore.IndexHookFunc(func(ctx context.Context, tx fhirstore.Tx, prev, next *fhirstore.Version) error {
+		if fail {
+			return errors.New("index unavailable")
+		}
+		prevVersion := "none"
+		if prev != nil {
+			prevVersion = prev.VersionID
+		}
+		writes = append(writes, fmt.Sprintf("%s/%s %s->%s deleted=%v", next.ResourceType, next.ID, prevVersion, next.VersionID, next.Deleted))
+		return nil
+	})
+	s := NewWithOptions(fhirstore.Options{Now: clock(), Hooks: []fhirstore.IndexHook{hook}})
+	if _, err := s.Update(ctx, patient("a", "Doe"), ""); err != nil {
+		t.Fatalf("Update() returned unexpected error: %v", err)
+	}
+	if err := s.Delete(ctx, "Patient", "a"); err != nil {
+		t.Fatalf("Delete() returned unexpected error: %v", err)
+	}
+	want := []string{"Patient/a none->1 deleted=false", "Patient/a 1->2 deleted=true"}
+	if diff := cmp.Diff(want, writes); diff != "" {
+		t.Errorf("hook calls diff (-want +got):\n%s", diff)
+	}
+
+	// A failing hook rolls the write back.
+	fail = true
+	if _, err := s.Update(ctx, patient("b", "Roe"), ""); err == nil {
+		t.Fatalf("Update() with a failing hook succeeded, want error")
+	}
+	if _, err := s.Read(ctx, "Patient", "b"); !errors.Is(err, fhirstore.ErrNotFound) {
+		t.Errorf("Read() of a rolled back resource returned %v, want %v", err, fhirstore.ErrNotFound)
+	}
+}
+
+func TestBackend_Put(t *testing.T) {
+	ctx := context.Background()
+	b := NewBackend()
+	tx, err := b.Begin(ctx, false)
+	if err != nil {
+		t.Fatalf("Begin() returned unexpected error: %v", err)
+	}
+	v := &fhirstore.Version{ResourceType: "Patient", ID: "a", VersionID: "1", Resource: patient("a", "Doe")}
+	if err := tx.Put(ctx, v, ""); err != nil {
+		t.Fatalf("Put() returned unexpected error: %v", err)
+	}
+	v2 := &fhirstore.Version{ResourceType: "Patient", ID: "a", VersionID: "2", Resource: patient("a", "Roe")}
+	if err := tx.Put(ctx, v2, ""); !errors.Is(err, fhirstore.ErrVersionConflict) {
+		t.Errorf("Put() of an existing resource returned %v, want %v", err, fhirstore.ErrVersionConflict)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit() returned unexpected error: %v", err)
+	}
+
+	ro, err := b.Begin(ctx, true)
+	if err != nil {
+		t.Fatalf("Begin() returned unexpected error: %v", err)
+	}
+	defer ro.Rollback()
+	if err := ro.Put(ctx, v2, "1"); err == nil {
+		t.Errorf("Put() in a read-only transaction succeeded, want error")
+	}
+	got, err := ro.Get(ctx, "Patient", "a")
+	if err != nil {
+		t.Fatalf("Get() returned unexpected error: %v", err)
+	}
+	if got.VersionID != "1" {
+		t.Errorf("Get() version = %q, want %q", got.VersionID, "1")
+	}
+}
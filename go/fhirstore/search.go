@@ -0,0 +1,479 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirstore
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/geocode"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	locpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/location_go_proto"
+)
+
+// resultParams are the parameters that control the results of a search
+// rather than which resources match, and are ignored by Match.
+var resultParams = map[string]bool{
+	"_count":      true,
+	"_sort":       true,
+	"_include":    true,
+	"_revinclude": true,
+	"_summary":    true,
+	"_elements":   true,
+	"_total":      true,
+	"_contained":  true,
+	"_format":     true,
+	"_pretty":     true,
+}
+
+// element is an element a search parameter searches, with the resource type
+// its references must target, if any.
+type element struct {
+	path, target string
+}
+
+// aliases are the elements of the search parameters whose names are not
+// those of the elements they search in every resource type.
+var aliases = map[string][]element{
+	"_id":          {{"id", ""}},
+	"_lastUpdated": {{"meta.lastUpdated", ""}},
+	"_profile":     {{"meta.profile", ""}},
+	"_tag":         {{"meta.tag", ""}},
+	"_security":    {{"meta.security", ""}},
+	"patient":      {{"patient", ""}, {"subject", "Patient"}, {"beneficiary", "Patient"}},
+	"family":       {{"name.family", ""}},
+	"given":        {{"name.given", ""}},
+	"birthdate":    {{"birthDate", ""}},
+	"phone":        {{"telecom.where(system = 'phone')", ""}},
+	"email":        {{"telecom.where(system = 'email')", ""}},
+	"date": {
+		{"date", ""}, {"effective", ""}, {"occurrence", ""}, {"authoredOn", ""},
+		{"recordedDate", ""}, {"onset", ""}, {"performed", ""}, {"period", ""},
+	},
+}
+
+// compartmentElements are the elements whose references place resources in
+// the compartment of a patient.
+var compartmentElements = []element{
+	{"patient", ""}, {"subject", "Patient"}, {"beneficiary", "Patient"}, {"individual", "Patient"},
+}
+
+// expressions caches compiled FHIRPath expressions by their source.
+var expressions sync.Map // string -> *fhirpath.Expression
+
+func evaluate(res proto.Message, path string) (fhirpath.Collection, error) {
+	v, ok := expressions.Load(path)
+	if !ok {
+		expr, err := fhirpath.Compile(path)
+		if err != nil {
+			return nil, err
+		}
+		v, _ = expressions.LoadOrStore(path, expr)
+	}
+	return v.(*fhirpath.Expression).Evaluate(res)
+}
+
+// Match reports whether res, which may be a ContainedResource, matches every
+// search parameter of params, which are joined with AND; the comma-separated
+// values of a parameter are joined with OR. A parameter searches the element
+// named after it, i.e. clinicalStatus for clinical-status, or for common
+// parameters such as patient and date the elements they search in the
+// resource types that define them; the type of the element decides how its
+// values match:
+//
+//   - Codings, CodeableConcepts, Identifiers and codes match tokens of the
+//     form "code", "system|code", "|code" or "system|".
+//   - References match "Type/id", "id" or absolute URLs; a resource type
+//     modifier, as in subject:Patient=1, restricts the type.
+//   - Strings, HumanNames and Addresses match case-insensitive prefixes, or
+//     whole values with the :exact modifier and substrings with :contains.
+//   - Dates, date times, instants and Periods match dates with an optional
+//     eq, ne, lt, gt, le or ge prefix, comparing the ranges of time their
+//     precision spans.
+//   - Booleans match "true" or "false".
+//
+// The near parameter of Locations matches positions within a distance of a
+// point, given as "latitude|longitude|distance|units" with units of km, m
+// or [mi_i]; the distance defaults to 10 km and the units to km.
+// The :missing modifier matches resources with or without the element.
+// Parameters that control results, such as _count and _sort, are ignored.
+func Match(res proto.Message, params url.Values) (bool, error) {
+	res = elementpath.Unwrap(res)
+	if res == nil {
+		return false, fmt.Errorf("empty ContainedResource")
+	}
+	for name, values := range params {
+		name, modifier, _ := strings.Cut(name, ":")
+		if resultParams[name] {
+			continue
+		}
+		for _, v := range values {
+			ok, err := matchParam(res, name, modifier, v)
+			if err != nil {
+				return false, fmt.Errorf("search parameter %s: %w", name, err)
+			}
+			if !ok {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// InCompartment reports whether res is in the compartment with the id: for
+// the patient compartment, the only one supported, whether it is the
+// patient or its patient, subject, beneficiary or individual refers to the
+// patient.
+func InCompartment(res proto.Message, compartment, id string) (bool, error) {
+	if compartment != PatientCompartment {
+		return false, fmt.Errorf("unsupported compartment %q", compartment)
+	}
+	res = elementpath.Unwrap(res)
+	if res == nil {
+		return false, fmt.Errorf("empty ContainedResource")
+	}
+	if elementpath.ResourceType(res) == PatientCompartment {
+		return elementpath.ID(res) == id, nil
+	}
+	return matchElements(res, compartmentElements, "", id)
+}
+
+func matchParam(res proto.Message, name, modifier, value string) (bool, error) {
+	if name == "near" {
+		return matchNear(res, value)
+	}
+	if strings.HasPrefix(name, "_") && aliases[name] == nil {
+		return false, fmt.Errorf("unsupported parameter")
+	}
+	elems, ok := aliases[name]
+	if !ok {
+		elems = []element{{elementName(name), ""}}
+	}
+	if modifier == "missing" {
+		if value != "true" && value != "false" {
+			return false, fmt.Errorf("invalid :missing value %q", value)
+		}
+		found, err := collect(res, elems)
+		if err != nil {
+			return false, err
+		}
+		return (len(found) == 0) == (value == "true"), nil
+	}
+	return matchElements(res, elems, modifier, value)
+}
+
+// value is a value of an element, with the resource type its references
+// must target, if any.
+type value struct {
+	v      interface{}
+	target string
+}
+
+// collect returns the values of the elements elems of res.
+func collect(res proto.Message, elems []element) ([]value, error) {
+	typ := elementpath.ResourceType(res)
+	var out []value
+	for _, e := range elems {
+		values, err := evaluate(res, typ+"."+e.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			out = append(out, value{v, e.target})
+		}
+	}
+	return out, nil
+}
+
+func matchElements(res proto.Message, elems []element, modifier, value string) (bool, error) {
+	values, err := collect(res, elems)
+	if err != nil {
+		return false, err
+	}
+	for _, q := range strings.Split(value, ",") {
+		for _, v := range values {
+			ok, err := matchValue(v.v, v.target, modifier, q)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// elementName returns the element name of a search parameter name.
+func elementName(param string) string {
+	parts := strings.Split(param, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// matchValue reports whether the element value v matches the query q of a
+// parameter with the modifier. target is the type references must target.
+func matchValue(v interface{}, target, modifier, q string) (bool, error) {
+	switch x := v.(type) {
+	case *d4pb.CodeableConcept:
+		for _, c := range x.GetCoding() {
+			if matchToken(c.GetSystem().GetValue(), c.GetCode().GetValue(), q) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case *d4pb.Coding:
+		return matchToken(x.GetSystem().GetValue(), x.GetCode().GetValue(), q), nil
+	case *d4pb.Identifier:
+		return matchToken(x.GetSystem().GetValue(), x.GetValue().GetValue(), q), nil
+	case *d4pb.ContactPoint:
+		return matchToken("", x.GetValue().GetValue(), q), nil
+	case *d4pb.Reference:
+		return matchReference(fhirtypes.ReferenceURI(x), target, modifier, q), nil
+	case *d4pb.HumanName:
+		parts := append([]*d4pb.String{x.GetText(), x.GetFamily()}, x.GetGiven()...)
+		return matchStrings(parts, modifier, q)
+	case *d4pb.Address:
+		parts := append([]*d4pb.String{x.GetText(), x.GetCity(), x.GetDistrict(), x.GetState(), x.GetPostalCode(), x.GetCountry()}, x.GetLine()...)
+		return matchStrings(parts, modifier, q)
+	case *d4pb.Period:
+		var start, end time.Time
+		if t, ok := temporal(x.GetStart()); ok {
+			start = t.Time
+		}
+		if t, ok := temporal(x.GetEnd()); ok {
+			end = spanEnd(t)
+		}
+		return matchDate(start, end, q)
+	case *d4pb.String:
+		return matchStrings([]*d4pb.String{x}, modifier, q)
+	case *d4pb.Markdown:
+		return matchStrings([]*d4pb.String{{Value: x.GetValue()}}, modifier, q)
+	case string:
+		return matchReference(x, target, modifier, q), nil
+	}
+	sv, ok := fhirpath.SystemValue(v)
+	if !ok {
+		return false, fmt.Errorf("unsupported element of type %T", v)
+	}
+	switch s := sv.(type) {
+	case fhirpath.Temporal:
+		return matchDate(s.Time, spanEnd(s), q)
+	case string:
+		return s == q || matchToken("", s, q), nil
+	case bool:
+		return q == strconv.FormatBool(s), nil
+	}
+	return false, fmt.Errorf("unsupported element of type %T", v)
+}
+
+// matchToken reports whether the code of system matches the token q.
+func matchToken(system, code, q string) bool {
+	qs, qc, hasSystem := strings.Cut(q, "|")
+	if !hasSystem {
+		return code == q
+	}
+	return system == qs && (qc == "" || code == qc)
+}
+
+// matchReference reports whether the literal reference ref, which must be
+// to a resource of target if it is not "", matches the query q.
+func matchReference(ref, target, modifier, q string) bool {
+	if modifier != "" && modifier[0] >= 'A' && modifier[0] <= 'Z' {
+		if target != "" && target != modifier {
+			return false
+		}
+		target = modifier
+	}
+	p, err := fhirtypes.ParseReference(ref)
+	if err != nil {
+		return ref == q
+	}
+	if target != "" && p.Type != target {
+		return false
+	}
+	if !strings.Contains(q, "/") {
+		return p.ID == q
+	}
+	qp, err := fhirtypes.ParseReference(q)
+	if err != nil {
+		return false
+	}
+	return p.Type == qp.Type && p.ID == qp.ID && (qp.Base == "" || p.Base == "" || qp.Base == p.Base)
+}
+
+func matchStrings(values []*d4pb.String, modifier, q string) (bool, error) {
+	for _, s := range values {
+		v := s.GetValue()
+		if v == "" {
+			continue
+		}
+		switch modifier {
+		case "":
+			if strings.HasPrefix(strings.ToLower(v), strings.ToLower(q)) {
+				return true, nil
+			}
+		case "exact":
+			if v == q {
+				return true, nil
+			}
+		case "contains":
+			if strings.Contains(strings.ToLower(v), strings.ToLower(q)) {
+				return true, nil
+			}
+		default:
+			return false, fmt.Errorf("unsupported modifier %q", modifier)
+		}
+	}
+	return false, nil
+}
+
+// temporal returns the value of the date or time m, if it is set.
+func temporal(m proto.Message) (fhirpath.Temporal, bool) {
+	if !m.ProtoReflect().IsValid() {
+		return fhirpath.Temporal{}, false
+	}
+	v, ok := fhirpath.SystemValue(m)
+	if !ok {
+		return fhirpath.Temporal{}, false
+	}
+	t, ok := v.(fhirpath.Temporal)
+	return t, ok
+}
+
+// spanEnd returns the end of the range of time t spans at its precision.
+func spanEnd(t fhirpath.Temporal) time.Time {
+	switch t.Precision {
+	case fhirpath.PrecisionYear:
+		return t.Time.AddDate(1, 0, 0)
+	case fhirpath.PrecisionMonth:
+		return t.Time.AddDate(0, 1, 0)
+	case fhirpath.PrecisionDay:
+		return t.Time.AddDate(0, 0, 1)
+	case fhirpath.PrecisionHour:
+		return t.Time.Add(time.Hour)
+	case fhirpath.PrecisionMinute:
+		return t.Time.Add(time.Minute)
+	case fhirpath.PrecisionSecond:
+		return t.Time.Add(time.Second)
+	}
+	return t.Time.Add(time.Millisecond)
+}
+
+// dateLayouts are the layouts of date search values, with the precision of
+// each.
+var dateLayouts = []struct {
+	layout    string
+	precision fhirpath.Precision
+}{
+	{"2006", fhirpath.PrecisionYear},
+	{"2006-01", fhirpath.PrecisionMonth},
+	{"2006-01-02", fhirpath.PrecisionDay},
+	{"2006-01-02T15:04Z07:00", fhirpath.PrecisionMinute},
+	{"2006-01-02T15:04:05Z07:00", fhirpath.PrecisionSecond},
+	{time.RFC3339Nano, fhirpath.PrecisionMillisecond},
+}
+
+// nearUnits are the units of near distances, in meters.
+var nearUnits = map[string]float64{"km": 1000, "m": 1, "[mi_i]": 1609.344}
+
+// defaultNear is the distance of near queries that omit it, in meters.
+const defaultNear = 10000
+
+// matchNear reports whether res is a Location whose position is within the
+// distance of the near query q.
+func matchNear(res proto.Message, q string) (bool, error) {
+	loc, ok := res.(*locpb.Location)
+	if !ok {
+		return false, fmt.Errorf("unsupported parameter")
+	}
+	parts := strings.Split(q, "|")
+	if len(parts) < 2 || len(parts) > 4 {
+		return false, fmt.Errorf("invalid near value %q", q)
+	}
+	var p geocode.Point
+	var err1, err2 error
+	p.Latitude, err1 = strconv.ParseFloat(parts[0], 64)
+	p.Longitude, err2 = strconv.ParseFloat(parts[1], 64)
+	if err1 != nil || err2 != nil || !p.Valid() {
+		return false, fmt.Errorf("invalid near point %q", q)
+	}
+	distance := float64(defaultNear)
+	if len(parts) > 2 && parts[2] != "" {
+		d, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || d < 0 {
+			return false, fmt.Errorf("invalid near distance %q", parts[2])
+		}
+		unit := "km"
+		if len(parts) > 3 && parts[3] != "" {
+			unit = parts[3]
+		}
+		scale, ok := nearUnits[unit]
+		if !ok {
+			return false, fmt.Errorf("unsupported near units %q", unit)
+		}
+		distance = d * scale
+	}
+	pos, ok := geocode.Position(loc)
+	return ok && geocode.Distance(p, pos) <= distance, nil
+}
+
+// matchDate reports whether the range [start, end) matches the date query
+// q. A zero start or end leaves the range open on that side.
+func matchDate(start, end time.Time, q string) (bool, error) {
+	prefix := "eq"
+	if len(q) > 2 && q[0] >= 'a' && q[0] <= 'z' {
+		prefix, q = q[:2], q[2:]
+	}
+	var qt fhirpath.Temporal
+	parsed := false
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l.layout, q); err == nil {
+			qt, parsed = fhirpath.Temporal{Kind: fhirpath.DateTime, Time: t, Precision: l.precision}, true
+			break
+		}
+	}
+	if !parsed {
+		return false, fmt.Errorf("invalid date %q", q)
+	}
+	qstart, qend := qt.Time, spanEnd(qt)
+	startsBefore := func(t time.Time) bool { return start.IsZero() || start.Before(t) }
+	endsAfter := func(t time.Time) bool { return end.IsZero() || end.After(t) }
+	switch prefix {
+	case "eq":
+		return !start.IsZero() && !end.IsZero() && !start.Before(qstart) && !end.After(qend), nil
+	case "ne":
+		return start.IsZero() || end.IsZero() || start.Before(qstart) || end.After(qend), nil
+	case "lt":
+		return startsBefore(qstart), nil
+	case "le":
+		return startsBefore(qend), nil
+	case "gt":
+		return endsAfter(qend), nil
+	case "ge":
+		return endsAfter(qstart), nil
+	}
+	return false, fmt.Errorf("unsupported date prefix %q", prefix)
+}
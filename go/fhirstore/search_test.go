@@ -0,0 +1,238 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirstore
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	locpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/location_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func dateTime(s string) *d4pb.DateTime {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return &d4pb.DateTime{ValueUs: t.UnixMicro(), Precision: d4pb.DateTime_SECOND, Timezone: "UTC"}
+}
+
+var (
+	testPatient = &ppb.Patient{
+		Id:         &d4pb.Id{Value: "p1"},
+		Identifier: []*d4pb.Identifier{{System: &d4pb.Uri{Value: "http://example.org/mrn"}, Value: &d4pb.String{Value: "123"}}},
+		Name:       []*d4pb.HumanName{{Family: &d4pb.String{Value: "Doe"}, Given: []*d4pb.String{{Value: "Jane"}}}},
+		Gender:     &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+		BirthDate:  &d4pb.Date{ValueUs: time.Date(1980, 5, 1, 0, 0, 0, 0, time.UTC).UnixMicro(), Precision: d4pb.Date_DAY, Timezone: "UTC"},
+		Active:     &d4pb.Boolean{Value: true},
+		Telecom: []*d4pb.ContactPoint{{
+			System: &d4pb.ContactPoint_SystemCode{Value: c4pb.ContactPointSystemCode_PHONE},
+			Value:  &d4pb.String{Value: "555-0100"},
+		}},
+	}
+	testObservation = &obspb.Observation{
+		Id:     &d4pb.Id{Value: "o1"},
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{
+			{System: &d4pb.Uri{Value: "http://loinc.org"}, Code: &d4pb.Code{Value: "8867-4"}},
+		}},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Effective: &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{
+			DateTime: dateTime("2024-03-10T08:30:00Z"),
+		}},
+	}
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"_count=10", true},
+		{"_id=p1", true},
+		{"_id=p2", false},
+		{"family=do", true},
+		{"family:exact=do", false},
+		{"family:exact=Doe", true},
+		{"name=jan", true},
+		{"name:contains=an", true},
+		{"given=john", false},
+		{"gender=female", true},
+		{"gender=male,female", true},
+		{"gender=male", false},
+		{"identifier=http://example.org/mrn|123", true},
+		{"identifier=http://example.org/mrn|", true},
+		{"identifier=http://example.org/other|123", false},
+		{"identifier=123", true},
+		{"birthdate=1980-05-01", true},
+		{"birthdate=1980", true},
+		{"birthdate=1980-06", false},
+		{"birthdate=ge1980-01-01&birthdate=lt1981", true},
+		{"birthdate=gt1980-05-01", false},
+		{"birthdate=ne1980-05-01", false},
+		{"phone=555-0100", true},
+		{"email=555-0100", false},
+		{"active=true", true},
+		{"active=false", false},
+		{"deceased:missing=true", true},
+		{"birthdate:missing=true", false},
+	}
+	for _, tc := range tests {
+		params, err := url.ParseQuery(tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Match(testPatient, params)
+		if err != nil {
+			t.Errorf("Match(%q) returned unexpected error: %v", tc.query, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Match(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestMatch_Observation(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"code=http://loinc.org|8867-4", true},
+		{"code=8867-4", true},
+		{"code=http://snomed.info/sct|8867-4", false},
+		{"status=final", true},
+		{"subject=Patient/p1", true},
+		{"subject=p1", true},
+		{"subject:Patient=p1", true},
+		{"subject:Group=p1", false},
+		{"subject=https://example.com/fhir/Patient/p1", true},
+		{"patient=p1", true},
+		{"patient=p2", false},
+		{"date=2024-03-10", true},
+		{"date=2024-03-10T08:30:00Z", true},
+		{"date=lt2024-03-10", false},
+		{"date=le2024-03-10", true},
+		{"date=gt2024-03", false},
+		{"date=ge2024-03", true},
+	}
+	for _, tc := range tests {
+		params, err := url.ParseQuery(tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Match(testObservation, params)
+		if err != nil {
+			t.Errorf("Match(%q) returned unexpected error: %v", tc.query, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Match(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestMatch_Location(t *testing.T) {
+	loc := &locpb.Location{
+		Id: &d4pb.Id{Value: "l1"},
+		Position: &locpb.Location_Position{
+			Latitude:  &d4pb.Decimal{Value: "42.3601"},
+			Longitude: &d4pb.Decimal{Value: "-71.0589"},
+		},
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"near=42.3736|-71.1097|5|km", true},
+		{"near=42.3736|-71.1097|4|km", false},
+		{"near=42.3736|-71.1097|4500|m", true},
+		{"near=42.3736|-71.1097|3|[mi_i]", true},
+		{"near=42.3736|-71.1097", true},
+		{"near=40.7128|-74.0060", false},
+		{"near=40.7128|-74.0060|400", true},
+	}
+	for _, tc := range tests {
+		params, err := url.ParseQuery(tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Match(loc, params)
+		if err != nil {
+			t.Errorf("Match(%q) returned unexpected error: %v", tc.query, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Match(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+	for _, query := range []string{"near=42.3736", "near=91|0", "near=42|-71|5|ft"} {
+		params, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Match(loc, params); err == nil {
+			t.Errorf("Match(%q) succeeded, want error", query)
+		}
+	}
+	if got, err := Match(&locpb.Location{}, url.Values{"near": {"42|-71"}}); err != nil || got {
+		t.Errorf("Match(near) of a Location without position = %v, %v, want false", got, err)
+	}
+}
+
+func TestMatch_Errors(t *testing.T) {
+	for _, query := range []string{
+		"_unknown=1",
+		"birthdate=yesterday",
+		"birthdate=xx1980",
+		"family:text=Doe",
+		"active:missing=maybe",
+		"near=42|-71",
+	} {
+		params, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Match(testPatient, params); err == nil {
+			t.Errorf("Match(%q) succeeded, want error", query)
+		}
+	}
+}
+
+func TestInCompartment(t *testing.T) {
+	for _, tc := range []struct {
+		id           string
+		patient, obs bool
+	}{
+		{"p1", true, true},
+		{"p2", false, false},
+	} {
+		if got, err := InCompartment(testPatient, PatientCompartment, tc.id); err != nil || got != tc.patient {
+			t.Errorf("InCompartment(Patient, %q) = %v, %v, want %v", tc.id, got, err, tc.patient)
+		}
+		if got, err := InCompartment(testObservation, PatientCompartment, tc.id); err != nil || got != tc.obs {
+			t.Errorf("InCompartment(Observation, %q) = %v, %v, want %v", tc.id, got, err, tc.obs)
+		}
+	}
+	if _, err := InCompartment(testObservation, "Encounter", "e1"); err == nil {
+		t.Errorf("InCompartment(Encounter) succeeded, want error")
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/uuid"
+	"github.com/google/fhir/go/meta"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// Retention limits the history a Store keeps of each resource. The current
+// version of a resource is always kept.
+type Retention struct {
+	// MaxVersions is the number of versions kept, including the current
+	// one, or 0 to keep every version.
+	MaxVersions int
+	// MaxAge is how long versions are kept after they are replaced, or 0
+	// to keep them forever.
+	MaxAge time.Duration
+}
+
+// Listener is told of the writes of a Store once they are committed, in the
+// order they commit. prev is the version replaced, or nil for new resources,
+// and next the version written, which is Deleted for deletions. Listeners
+// are called by the goroutine of the write and must neither block nor modify
+// the versions.
+type Listener interface {
+	Written(ctx context.Context, prev, next *Version)
+}
+
+// Options configures a Store.
+type Options struct {
+	// Hooks are called on every write.
+	Hooks []IndexHook
+	// Listeners are told of every committed write.
+	Listeners []Listener
+	// Retention limits the history of resources, which is pruned when they
+	// are written.
+	Retention Retention
+	// Now returns the time of writes; time.Now if nil.
+	Now func() time.Time
+	// NewID returns the ids of created resources, which are retried until
+	// they are not the id of an existing resource; random UUIDs if nil.
+	NewID func() string
+}
+
+// Store is a ResourceStore over a Backend. Searches evaluate Match over the
+// resources the Backend scans.
+type Store struct {
+	backend Backend
+	opts    Options
+}
+
+var _ ResourceStore = (*Store)(nil)
+
+// NewStore returns a Store over b.
+func NewStore(b Backend, opts Options) *Store {
+	return &Store{backend: b, opts: opts}
+}
+
+func (s *Store) now() time.Time {
+	if s.opts.Now != nil {
+		return s.opts.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func (s *Store) newID() string {
+	if s.opts.NewID != nil {
+		return s.opts.NewID()
+	}
+	return uuid.New()
+}
+
+// change is a write of a transaction.
+type change struct {
+	prev, next *Version
+}
+
+// update runs fn in a transaction that writes, commits it if fn succeeds and
+// tells the listeners of the changes fn appended to its argument.
+func (s *Store) update(ctx context.Context, fn func(Tx, *[]change) error) error {
+	tx, err := s.backend.Begin(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var changes []change
+	if err := fn(tx, &changes); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		for _, l := range s.opts.Listeners {
+			l.Written(ctx, c.prev, c.next)
+		}
+	}
+	return nil
+}
+
+// view runs fn in a read-only transaction.
+func (s *Store) view(ctx context.Context, fn func(Tx) error) error {
+	tx, err := s.backend.Begin(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return fn(tx)
+}
+
+// current returns the current version of a resource, or nil if it does not
+// exist.
+func current(ctx context.Context, tx Tx, resourceType, id string) (*Version, error) {
+	v, err := tx.Get(ctx, resourceType, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return v, err
+}
+
+// Create implements ResourceStore.
+func (s *Store) Create(ctx context.Context, res *r4pb.ContainedResource) (*r4pb.ContainedResource, error) {
+	typ := elementpath.ResourceType(res)
+	if typ == "" {
+		return nil, fmt.Errorf("empty ContainedResource")
+	}
+	var out *r4pb.ContainedResource
+	err := s.update(ctx, func(tx Tx, changes *[]change) error {
+		for {
+			id := s.newID()
+			prev, err := current(ctx, tx, typ, id)
+			if err != nil {
+				return err
+			}
+			if prev == nil {
+				out, err = s.write(ctx, tx, changes, res, nil, typ, id)
+				return err
+			}
+		}
+	})
+	return out, err
+}
+
+// Read implements ResourceStore.
+func (s *Store) Read(ctx context.Context, resourceType, id string) (*r4pb.ContainedResource, error) {
+	var v *Version
+	err := s.view(ctx, func(tx Tx) error {
+		var err error
+		v, err = tx.Get(ctx, resourceType, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resourceOf(v)
+}
+
+// VRead implements ResourceStore.
+func (s *Store) VRead(ctx context.Context, resourceType, id, versionID string) (*r4pb.ContainedResource, error) {
+	var v *Version
+	err := s.view(ctx, func(tx Tx) error {
+		var err error
+		v, err = tx.GetVersion(ctx, resourceType, id, versionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resourceOf(v)
+}
+
+func resourceOf(v *Version) (*r4pb.ContainedResource, error) {
+	if v.Deleted {
+		return nil, fmt.Errorf("%w: %s/%s", ErrDeleted, v.ResourceType, v.ID)
+	}
+	return v.Resource, nil
+}
+
+// Update implements ResourceStore.
+func (s *Store) Update(ctx context.Context, res *r4pb.ContainedResource, ifMatch string) (*r4pb.ContainedResource, error) {
+	typ := elementpath.ResourceType(res)
+	if typ == "" {
+		return nil, fmt.Errorf("empty ContainedResource")
+	}
+	id := elementpath.ID(res)
+	if id == "" {
+		return nil, fmt.Errorf("%s to update has no id", typ)
+	}
+	var out *r4pb.ContainedResource
+	err := s.update(ctx, func(tx Tx, changes *[]change) error {
+		prev, err := current(ctx, tx, typ, id)
+		if err != nil {
+			return err
+		}
+		if ifMatch != "" && (prev == nil || prev.VersionID != ifMatch) {
+			return fmt.Errorf("%w: %s/%s is not at version %s", ErrVersionConflict, typ, id, ifMatch)
+		}
+		out, err = s.write(ctx, tx, changes, res, prev, typ, id)
+		return err
+	})
+	return out, err
+}
+
+// Delete implements ResourceStore.
+func (s *Store) Delete(ctx context.Context, resourceType, id string) error {
+	return s.update(ctx, func(tx Tx, changes *[]change) error {
+		prev, err := tx.Get(ctx, resourceType, id)
+		if err != nil || prev.Deleted {
+			return err
+		}
+		next := &Version{
+			ResourceType: resourceType,
+			ID:           id,
+			VersionID:    nextVersion(prev.VersionID),
+			LastUpdated:  s.now(),
+			Deleted:      true,
+		}
+		return s.put(ctx, tx, changes, prev, next)
+	})
+}
+
+// write writes res as the version after prev of the resource of typ with
+// the id, and returns a copy of it as written.
+func (s *Store) write(ctx context.Context, tx Tx, changes *[]change, res *r4pb.ContainedResource, prev *Version, typ, id string) (*r4pb.ContainedResource, error) {
+	res = proto.Clone(res).(*r4pb.ContainedResource)
+	if err := setID(res, id); err != nil {
+		return nil, err
+	}
+	next := &Version{ResourceType: typ, ID: id, VersionID: "1", LastUpdated: s.now(), Resource: res}
+	if prev != nil {
+		next.VersionID = nextVersion(prev.VersionID)
+	}
+	if err := meta.SetVersion(res, next.VersionID, next.LastUpdated); err != nil {
+		return nil, err
+	}
+	if err := s.put(ctx, tx, changes, prev, next); err != nil {
+		return nil, err
+	}
+	return proto.Clone(res).(*r4pb.ContainedResource), nil
+}
+
+// put puts next after prev, runs the hooks, prunes the history of the
+// resource and appends the change to changes.
+func (s *Store) put(ctx context.Context, tx Tx, changes *[]change, prev, next *Version) error {
+	ifVersion := ""
+	if prev != nil {
+		ifVersion = prev.VersionID
+	}
+	if err := tx.Put(ctx, next, ifVersion); err != nil {
+		return err
+	}
+	for _, h := range s.opts.Hooks {
+		if err := h.Index(ctx, tx, prev, next); err != nil {
+			return err
+		}
+	}
+	if err := s.prune(ctx, tx, next.ResourceType, next.ID); err != nil {
+		return err
+	}
+	*changes = append(*changes, change{prev, next})
+	return nil
+}
+
+// prune removes the versions of a resource that its retention does not
+// keep.
+func (s *Store) prune(ctx context.Context, tx Tx, resourceType, id string) error {
+	r := s.opts.Retention
+	if r.MaxVersions <= 0 && r.MaxAge <= 0 {
+		return nil
+	}
+	versions, err := tx.History(ctx, resourceType, id)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	var drop []string
+	for i := 1; i < len(versions); i++ {
+		// Versions are replaced when the next version is written.
+		replaced := versions[i-1].LastUpdated
+		if r.MaxVersions > 0 && i >= r.MaxVersions || r.MaxAge > 0 && now.Sub(replaced) > r.MaxAge {
+			drop = append(drop, versions[i].VersionID)
+		}
+	}
+	if len(drop) == 0 {
+		return nil
+	}
+	return tx.DeleteVersions(ctx, resourceType, id, drop)
+}
+
+// Search implements ResourceStore.
+func (s *Store) Search(ctx context.Context, resourceType string, params url.Values) ([]*r4pb.ContainedResource, error) {
+	return s.search(ctx, resourceType, params, nil)
+}
+
+// Compartment implements ResourceStore.
+func (s *Store) Compartment(ctx context.Context, compartment, id, resourceType string, params url.Values) ([]*r4pb.ContainedResource, error) {
+	if compartment != PatientCompartment {
+		return nil, fmt.Errorf("unsupported compartment %q", compartment)
+	}
+	return s.search(ctx, resourceType, params, func(res proto.Message) (bool, error) {
+		return InCompartment(res, compartment, id)
+	})
+}
+
+func (s *Store) search(ctx context.Context, resourceType string, params url.Values, filter func(proto.Message) (bool, error)) ([]*r4pb.ContainedResource, error) {
+	count, err := Count(params)
+	if err != nil {
+		return nil, err
+	}
+	var out []*r4pb.ContainedResource
+	errEnough := errors.New("enough results")
+	err = s.view(ctx, func(tx Tx) error {
+		return tx.Scan(ctx, resourceType, func(v *Version) error {
+			if filter != nil {
+				if ok, err := filter(v.Resource); err != nil || !ok {
+					return err
+				}
+			}
+			ok, err := Match(v.Resource, params)
+			if err != nil || !ok {
+				return err
+			}
+			if count >= 0 && len(out) == count {
+				return errEnough
+			}
+			out = append(out, v.Resource)
+			return nil
+		})
+	})
+	if err != nil && err != errEnough {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Count returns the value of the _count parameter of params, or -1 if it has
+// none.
+func Count(params url.Values) (int, error) {
+	c := params.Get("_count")
+	if c == "" {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(c)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid _count %q", c)
+	}
+	return n, nil
+}
+
+// History implements ResourceStore.
+func (s *Store) History(ctx context.Context, resourceType, id string) ([]Version, error) {
+	var versions []*Version
+	err := s.view(ctx, func(tx Tx) error {
+		var err error
+		versions, err = tx.History(ctx, resourceType, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if id != "" && len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, resourceType, id)
+	}
+	out := make([]Version, len(versions))
+	for i, v := range versions {
+		out[i] = *v
+	}
+	return out, nil
+}
+
+func nextVersion(v string) string {
+	n, _ := strconv.Atoi(v)
+	return strconv.Itoa(n + 1)
+}
+
+func setID(res *r4pb.ContainedResource, id string) error {
+	m := elementpath.Unwrap(res).ProtoReflect()
+	fd := m.Descriptor().Fields().ByName("id")
+	if fd == nil {
+		return fmt.Errorf("%s has no id", m.Descriptor().FullName())
+	}
+	m.Set(fd, protoreflect.ValueOfMessage((&d4pb.Id{Value: id}).ProtoReflect()))
+	return nil
+}
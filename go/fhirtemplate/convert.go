@@ -0,0 +1,250 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+)
+
+// Converter renders input payloads through a Template and parses the result
+// into a ContainedResource of the configured FHIR version.
+type Converter struct {
+	tmpl *Template
+	um   *jsonformat.Unmarshaller
+}
+
+// NewConverter returns a Converter for tmpl. Unzoned times in the rendered
+// resources are interpreted in the tz time zone.
+func NewConverter(tmpl *Template, tz string, ver fhirversion.Version) (*Converter, error) {
+	um, err := jsonformat.NewUnmarshaller(tz, ver)
+	if err != nil {
+		return nil, err
+	}
+	return &Converter{tmpl: tmpl, um: um}, nil
+}
+
+// Render renders data through the template and returns the cleaned up FHIR
+// JSON, without parsing it.
+func (c *Converter) Render(data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return CleanJSON(buf.Bytes())
+}
+
+// Convert renders data, i.e. the result of decoding a JSON payload into an
+// interface{}, and parses the output into a ContainedResource.
+func (c *Converter) Convert(data interface{}) (proto.Message, error) {
+	out, err := c.Render(data)
+	if err != nil {
+		return nil, err
+	}
+	return c.um.Unmarshal(out)
+}
+
+// ConvertJSON converts a JSON payload.
+func (c *Converter) ConvertJSON(in []byte) (proto.Message, error) {
+	var data interface{}
+	d := json.NewDecoder(bytes.NewReader(in))
+	if err := d.Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding input JSON: %w", err)
+	}
+	return c.Convert(data)
+}
+
+// ConvertXML converts an XML payload, made available to the template as
+// described by DecodeXML.
+func (c *Converter) ConvertXML(in []byte) (proto.Message, error) {
+	data, err := DecodeXML(bytes.NewReader(in))
+	if err != nil {
+		return nil, err
+	}
+	return c.Convert(data)
+}
+
+// CleanJSON removes the trailing commas and empty strings, objects and arrays
+// that conditional template sections leave behind, and validates that the
+// result is well-formed JSON.
+func CleanJSON(in []byte) ([]byte, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(stripTrailingCommas(in)))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("template produced invalid JSON: %w", err)
+	}
+	v = prune(v)
+	if v == nil {
+		return nil, fmt.Errorf("template produced an empty resource")
+	}
+	return json.Marshal(v)
+}
+
+// stripTrailingCommas removes commas directly followed by a closing bracket
+// or brace, outside of strings, and commas directly following an opening one.
+func stripTrailingCommas(in []byte) []byte {
+	out := make([]byte, 0, len(in))
+	inString, escaped := false, false
+	for i := 0; i < len(in); i++ {
+		c := in[i]
+		if inString {
+			out = append(out, c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			j := i + 1
+			for j < len(in) && isSpace(in[j]) {
+				j++
+			}
+			if j < len(in) && (in[j] == '}' || in[j] == ']' || in[j] == ',') {
+				continue
+			}
+			k := len(out) - 1
+			for k >= 0 && isSpace(out[k]) {
+				k--
+			}
+			if k >= 0 && (out[k] == '{' || out[k] == '[') {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func prune(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		return v
+	case map[string]interface{}:
+		for k, e := range v {
+			if p := prune(e); p == nil {
+				delete(v, k)
+			} else {
+				v[k] = p
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	case []interface{}:
+		var out []interface{}
+		for _, e := range v {
+			if p := prune(e); p != nil {
+				out = append(out, p)
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	}
+	return v
+}
+
+// DecodeXML decodes an XML document into the generic structure templates
+// operate on. Each element becomes a map from child element names to values;
+// attributes are stored under their name prefixed with "@", and character
+// data under "#text". Elements with neither attributes nor children are
+// reduced to their text. Repeated child elements become lists. The root
+// element is returned as a single entry map keyed by its name.
+func DecodeXML(r io.Reader) (map[string]interface{}, error) {
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decoding input XML: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			v, err := decodeElement(d, se)
+			if err != nil {
+				return nil, fmt.Errorf("decoding input XML: %w", err)
+			}
+			return map[string]interface{}{se.Name.Local: v}, nil
+		}
+	}
+}
+
+func decodeElement(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	m := map[string]interface{}{}
+	for _, a := range start.Attr {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		m["@"+a.Name.Local] = a.Value
+	}
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeElement(d, tok)
+			if err != nil {
+				return nil, err
+			}
+			name := tok.Name.Local
+			switch prev := m[name].(type) {
+			case nil:
+				m[name] = child
+			case []interface{}:
+				m[name] = append(prev, child)
+			default:
+				m[name] = []interface{}{prev, child}
+			}
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				m["#text"] = s
+			}
+			return m, nil
+		}
+	}
+}
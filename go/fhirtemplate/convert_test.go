@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtemplate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	patientpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+const patientTemplate = `{
+  "resourceType": "Patient",
+  "id": "{{ msg.patient.mrn }}",
+  "name": [{
+    "family": "{{ msg.patient.last }}",
+    "given": [{% for g in msg.patient.given %}"{{ g }}",{% endfor %}]
+  }],
+  "gender": "{% if msg.patient.sex == 'F' %}female{% elsif msg.patient.sex == 'M' %}male{% endif %}",
+  "birthDate": "{{ msg.patient.dob | date }}",
+  "generalPractitioner": [{{ msg.patient.gp | reference: 'Practitioner' }}],
+}`
+
+func wantPatient() *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{
+		OneofResource: &r4pb.ContainedResource_Patient{Patient: &patientpb.Patient{
+			Id: &d4pb.Id{Value: "123"},
+			Name: []*d4pb.HumanName{{
+				Family: &d4pb.String{Value: "Doe"},
+				Given:  []*d4pb.String{{Value: "Jane"}, {Value: "Q"}},
+			}},
+			Gender: &patientpb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+			BirthDate: &d4pb.Date{
+				ValueUs:   315619200000000,
+				Timezone:  "UTC",
+				Precision: d4pb.Date_DAY,
+			},
+		}},
+	}
+}
+
+func newTestConverter(t *testing.T) *Converter {
+	t.Helper()
+	tmpl, err := Parse("patient", patientTemplate)
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	c, err := NewConverter(tmpl, "UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewConverter() returned unexpected error: %v", err)
+	}
+	return c
+}
+
+func TestConvertJSON(t *testing.T) {
+	c := newTestConverter(t)
+	got, err := c.ConvertJSON([]byte(`{"patient": {"mrn": "123", "last": "Doe", "given": ["Jane", "Q"], "sex": "F", "dob": "19800102"}}`))
+	if err != nil {
+		t.Fatalf("ConvertJSON() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(wantPatient(), got, protocmp.Transform()); diff != "" {
+		t.Errorf("ConvertJSON() returned unexpected resource, diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertXML(t *testing.T) {
+	// DecodeXML keys the document by its root element, so the template
+	// addresses the payload through msg.record.
+	tmpl, err := Parse("patient", strings.ReplaceAll(patientTemplate, "msg.patient", "msg.record.patient"))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	c, err := NewConverter(tmpl, "UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewConverter() returned unexpected error: %v", err)
+	}
+	got, err := c.ConvertXML([]byte(`<record>
+  <patient>
+    <mrn>123</mrn>
+    <last>Doe</last>
+    <given>Jane</given>
+    <given>Q</given>
+    <sex>F</sex>
+    <dob>1980-01-02</dob>
+    <gp>p1</gp>
+  </patient>
+</record>`))
+	if err != nil {
+		t.Fatalf("ConvertXML() returned unexpected error: %v", err)
+	}
+	want := wantPatient()
+	want.GetPatient().GeneralPractitioner = []*d4pb.Reference{{
+		Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: "p1"}},
+	}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ConvertXML() returned unexpected resource, diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	got, err := DecodeXML(strings.NewReader(`<a xmlns="urn:x" id="1"><b>x</b><b>y</b><c code="z"/><d u="1">text</d></a>`))
+	if err != nil {
+		t.Fatalf("DecodeXML() returned unexpected error: %v", err)
+	}
+	want := map[string]interface{}{
+		"a": map[string]interface{}{
+			"@id": "1",
+			"b":   []interface{}{"x", "y"},
+			"c":   map[string]interface{}{"@code": "z"},
+			"d":   map[string]interface{}{"@u": "1", "#text": "text"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DecodeXML() returned unexpected data, diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCleanJSON(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{"a": "x",}`, `{"a":"x"}`},
+		{`{"a": [,"x",,"y",], "b": ""}`, `{"a":["x","y"]}`},
+		{`{"a": {"b": [{}]}, "c": "a,}"}`, `{"c":"a,}"}`},
+		{`{"a": 1.50, "b": false}`, `{"a":1.50,"b":false}`},
+	}
+	for _, test := range tests {
+		got, err := CleanJSON([]byte(test.in))
+		if err != nil {
+			t.Fatalf("CleanJSON(%s) returned unexpected error: %v", test.in, err)
+		}
+		if string(got) != test.want {
+			t.Errorf("CleanJSON(%s) = %s, want %s", test.in, got, test.want)
+		}
+	}
+	if _, err := CleanJSON([]byte(`{"a": ""}`)); err == nil {
+		t.Errorf("CleanJSON() of an empty object succeeded, want error")
+	}
+}
@@ -0,0 +1,408 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtemplate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// operand is a literal or a variable path such as msg.patient.names[0].
+type operand struct {
+	literal   interface{}
+	isLiteral bool
+	path      []interface{} // string keys and int indexes
+}
+
+type filterCall struct {
+	name string
+	args []operand
+}
+
+// pipeline is an operand followed by any number of filters.
+type pipeline struct {
+	value   operand
+	filters []filterCall
+}
+
+type comparison struct {
+	left  *pipeline
+	op    string
+	right *pipeline
+}
+
+// condition is a chain of comparisons joined by "and"/"or", evaluated right
+// to left as in Liquid.
+type condition struct {
+	comparisons []comparison
+	joins       []string
+}
+
+func lexExpr(s string) ([]string, error) {
+	var toks []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(s[i+1:], c)
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated string in %q", s)
+			}
+			toks = append(toks, s[i:i+j+2])
+			i += j + 2
+		case c == '|' || c == ':' || c == ',':
+			toks = append(toks, string(c))
+			i++
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			if i+1 < len(s) && s[i+1] == '=' {
+				toks = append(toks, s[i:i+2])
+				i += 2
+			} else if c == '<' || c == '>' {
+				toks = append(toks, string(c))
+				i++
+			} else {
+				return nil, fmt.Errorf("unexpected %q in %q", c, s)
+			}
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n\r|:,=!<>\"'", rune(s[j])) {
+				if s[j] == '[' {
+					k := strings.IndexByte(s[j:], ']')
+					if k < 0 {
+						return nil, fmt.Errorf("unterminated index in %q", s)
+					}
+					j += k
+				}
+				j++
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+func parseOperand(tok string) (operand, error) {
+	switch {
+	case tok == "":
+		return operand{}, fmt.Errorf("missing operand")
+	case tok[0] == '"' || tok[0] == '\'':
+		return operand{literal: tok[1 : len(tok)-1], isLiteral: true}, nil
+	case tok == "true" || tok == "false":
+		return operand{literal: tok == "true", isLiteral: true}, nil
+	case tok == "nil" || tok == "null" || tok == "empty":
+		return operand{isLiteral: true}, nil
+	case tok[0] == '-' || (tok[0] >= '0' && tok[0] <= '9'):
+		f, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return operand{}, fmt.Errorf("invalid number %q", tok)
+		}
+		return operand{literal: f, isLiteral: true}, nil
+	}
+	var path []interface{}
+	for _, part := range strings.Split(tok, ".") {
+		name := part
+		var idxs []interface{}
+		if b := strings.IndexByte(part, '['); b >= 0 {
+			name = part[:b]
+			for rest := part[b:]; rest != ""; {
+				e := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || e < 0 {
+					return operand{}, fmt.Errorf("invalid path %q", tok)
+				}
+				inner := rest[1:e]
+				if n, err := strconv.Atoi(inner); err == nil {
+					idxs = append(idxs, n)
+				} else {
+					idxs = append(idxs, strings.Trim(inner, `"'`))
+				}
+				rest = rest[e+1:]
+			}
+		}
+		if name == "" && len(path) > 0 {
+			return operand{}, fmt.Errorf("invalid path %q", tok)
+		}
+		if name != "" {
+			path = append(path, name)
+		}
+		path = append(path, idxs...)
+	}
+	return operand{path: path}, nil
+}
+
+func parsePipeline(s string) (*pipeline, error) {
+	toks, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	pl, rest, err := parsePipelineTokens(toks)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected %q in %q", rest[0], s)
+	}
+	return pl, nil
+}
+
+func parsePipelineTokens(toks []string) (*pipeline, []string, error) {
+	if len(toks) == 0 {
+		return nil, nil, fmt.Errorf("empty expression")
+	}
+	v, err := parseOperand(toks[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	pl := &pipeline{value: v}
+	toks = toks[1:]
+	for len(toks) > 0 && toks[0] == "|" {
+		if len(toks) < 2 {
+			return nil, nil, fmt.Errorf("missing filter name")
+		}
+		fc := filterCall{name: toks[1]}
+		if _, ok := filters[fc.name]; !ok {
+			return nil, nil, fmt.Errorf("unknown filter %q", fc.name)
+		}
+		toks = toks[2:]
+		if len(toks) > 0 && toks[0] == ":" {
+			toks = toks[1:]
+			for {
+				if len(toks) == 0 {
+					return nil, nil, fmt.Errorf("missing argument for filter %q", fc.name)
+				}
+				arg, err := parseOperand(toks[0])
+				if err != nil {
+					return nil, nil, err
+				}
+				fc.args = append(fc.args, arg)
+				toks = toks[1:]
+				if len(toks) == 0 || toks[0] != "," {
+					break
+				}
+				toks = toks[1:]
+			}
+		}
+		pl.filters = append(pl.filters, fc)
+	}
+	return pl, toks, nil
+}
+
+func parseCondition(s string) (*condition, error) {
+	toks, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	c := &condition{}
+	for {
+		left, rest, err := parsePipelineTokens(toks)
+		if err != nil {
+			return nil, err
+		}
+		cmp := comparison{left: left}
+		if len(rest) > 0 && isComparisonOp(rest[0]) {
+			cmp.op = rest[0]
+			cmp.right, rest, err = parsePipelineTokens(rest[1:])
+			if err != nil {
+				return nil, err
+			}
+		}
+		c.comparisons = append(c.comparisons, cmp)
+		if len(rest) == 0 {
+			return c, nil
+		}
+		if rest[0] != "and" && rest[0] != "or" {
+			return nil, fmt.Errorf("unexpected %q in condition %q", rest[0], s)
+		}
+		c.joins = append(c.joins, rest[0])
+		toks = rest[1:]
+	}
+}
+
+func isComparisonOp(s string) bool {
+	switch s {
+	case "==", "!=", "<", ">", "<=", ">=", "contains":
+		return true
+	}
+	return false
+}
+
+func (o operand) eval(sc *scope) interface{} {
+	if o.isLiteral {
+		return o.literal
+	}
+	v, ok := sc.lookup(o.path[0].(string))
+	if !ok {
+		return nil
+	}
+	for _, p := range o.path[1:] {
+		switch p := p.(type) {
+		case string:
+			switch cur := v.(type) {
+			case map[string]interface{}:
+				v = cur[p]
+			case []interface{}:
+				switch p {
+				case "size":
+					v = float64(len(cur))
+				case "first":
+					v = index(cur, 0)
+				case "last":
+					v = index(cur, -1)
+				default:
+					// Convenience for XML-derived data, where a repeating element
+					// may hold one or many values: look into the first item.
+					if len(cur) == 0 {
+						return nil
+					}
+					m, _ := cur[0].(map[string]interface{})
+					v = m[p]
+				}
+			default:
+				return nil
+			}
+		case int:
+			v = index(toList(v), p)
+		}
+	}
+	return v
+}
+
+func index(l []interface{}, i int) interface{} {
+	if i < 0 {
+		i += len(l)
+	}
+	if i < 0 || i >= len(l) {
+		return nil
+	}
+	return l[i]
+}
+
+func (p *pipeline) eval(sc *scope) (interface{}, error) {
+	v := p.value.eval(sc)
+	for _, f := range p.filters {
+		args := make([]interface{}, len(f.args))
+		for i, a := range f.args {
+			args[i] = a.eval(sc)
+		}
+		var err error
+		if v, err = filters[f.name](v, args); err != nil {
+			return nil, fmt.Errorf("filter %s: %w", f.name, err)
+		}
+	}
+	return v, nil
+}
+
+func (c *condition) eval(sc *scope) (bool, error) {
+	// Liquid evaluates and/or right to left without precedence.
+	result, err := c.comparisons[len(c.comparisons)-1].eval(sc)
+	if err != nil {
+		return false, err
+	}
+	for i := len(c.joins) - 1; i >= 0; i-- {
+		left, err := c.comparisons[i].eval(sc)
+		if err != nil {
+			return false, err
+		}
+		if c.joins[i] == "and" {
+			result = left && result
+		} else {
+			result = left || result
+		}
+	}
+	return result, nil
+}
+
+func (c comparison) eval(sc *scope) (bool, error) {
+	l, err := c.left.eval(sc)
+	if err != nil {
+		return false, err
+	}
+	if c.op == "" {
+		return truthy(l), nil
+	}
+	r, err := c.right.eval(sc)
+	if err != nil {
+		return false, err
+	}
+	switch c.op {
+	case "==":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	case "contains":
+		switch l := l.(type) {
+		case string:
+			return strings.Contains(l, toString(r)), nil
+		case []interface{}:
+			for _, e := range l {
+				if equal(e, r) {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+	lf, lok := toNumber(l)
+	rf, rok := toNumber(r)
+	if !lok || !rok {
+		ls, rs := toString(l), toString(r)
+		switch c.op {
+		case "<":
+			return ls < rs, nil
+		case ">":
+			return ls > rs, nil
+		case "<=":
+			return ls <= rs, nil
+		default:
+			return ls >= rs, nil
+		}
+	}
+	switch c.op {
+	case "<":
+		return lf < rf, nil
+	case ">":
+		return lf > rf, nil
+	case "<=":
+		return lf <= rf, nil
+	default:
+		return lf >= rf, nil
+	}
+}
+
+func equal(l, r interface{}) bool {
+	if l == nil || r == nil {
+		return !truthy(l) && !truthy(r)
+	}
+	if lf, ok := toNumber(l); ok {
+		if rf, ok := toNumber(r); ok {
+			return lf == rf
+		}
+	}
+	return toString(l) == toString(r)
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
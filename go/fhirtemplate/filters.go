@@ -0,0 +1,259 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type filterFunc func(v interface{}, args []interface{}) (interface{}, error)
+
+var filters map[string]filterFunc
+
+func init() {
+	filters = map[string]filterFunc{
+		"default":         defaultFilter,
+		"upcase":          stringFilter(strings.ToUpper),
+		"downcase":        stringFilter(strings.ToLower),
+		"strip":           stringFilter(strings.TrimSpace),
+		"prepend":         prependFilter,
+		"append":          appendFilter,
+		"replace":         replaceFilter,
+		"split":           splitFilter,
+		"size":            sizeFilter,
+		"first":           func(v interface{}, _ []interface{}) (interface{}, error) { return index(toList(v), 0), nil },
+		"last":            func(v interface{}, _ []interface{}) (interface{}, error) { return index(toList(v), -1), nil },
+		"json":            jsonFilter,
+		"date":            dateFilter,
+		"datetime":        dateTimeFilter,
+		"reference":       referenceFilter,
+		"coding":          codingFilter,
+		"codeableConcept": codeableConceptFilter,
+	}
+}
+
+func arg(args []interface{}, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+	return toString(args[i])
+}
+
+func stringFilter(f func(string) string) filterFunc {
+	return func(v interface{}, _ []interface{}) (interface{}, error) {
+		if v == nil {
+			return nil, nil
+		}
+		return f(toString(v)), nil
+	}
+}
+
+func defaultFilter(v interface{}, args []interface{}) (interface{}, error) {
+	if truthy(v) || len(args) == 0 {
+		return v, nil
+	}
+	return args[0], nil
+}
+
+func prependFilter(v interface{}, args []interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return arg(args, 0) + toString(v), nil
+}
+
+func appendFilter(v interface{}, args []interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return toString(v) + arg(args, 0), nil
+}
+
+func replaceFilter(v interface{}, args []interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return strings.ReplaceAll(toString(v), arg(args, 0), arg(args, 1)), nil
+}
+
+func splitFilter(v interface{}, args []interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var out []interface{}
+	for _, s := range strings.Split(toString(v), arg(args, 0)) {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func sizeFilter(v interface{}, _ []interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	return float64(len(toList(v))), nil
+}
+
+func jsonFilter(v interface{}, _ []interface{}) (interface{}, error) {
+	if r, ok := v.(rawJSON); ok {
+		return r, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return rawJSON(b), nil
+}
+
+// Layouts accepted by the date and datetime filters, from most to least
+// precise. HL7v2 and CDA style compact timestamps are included.
+var (
+	dateTimeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"20060102150405.000-0700",
+		"20060102150405-0700",
+		"20060102150405",
+		"200601021504-0700",
+		"200601021504",
+	}
+	dateLayouts = []struct {
+		layout, out string
+	}{
+		{"2006-01-02", "2006-01-02"},
+		{"20060102", "2006-01-02"},
+		{"01/02/2006", "2006-01-02"},
+		{"2006-01", "2006-01"},
+		{"200601", "2006-01"},
+		{"2006", "2006"},
+	}
+)
+
+// dateTimeFilter converts a timestamp into a FHIR dateTime, keeping the
+// precision of the input. Times without a zone are interpreted in UTC, or in
+// the zone given as the first argument.
+func dateTimeFilter(v interface{}, args []interface{}) (interface{}, error) {
+	s := strings.TrimSpace(toString(v))
+	if s == "" {
+		return nil, nil
+	}
+	loc := time.UTC
+	if tz := arg(args, 0); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, err
+		}
+		loc = l
+	}
+	for _, layout := range dateTimeLayouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err != nil {
+			continue
+		}
+		if strings.Contains(layout, "05") {
+			if t.Nanosecond() != 0 {
+				return t.Format("2006-01-02T15:04:05.000Z07:00"), nil
+			}
+			return t.Format("2006-01-02T15:04:05Z07:00"), nil
+		}
+		return t.Format("2006-01-02T15:04:00Z07:00"), nil
+	}
+	return dateFilter(s, nil)
+}
+
+// dateFilter converts a date into a FHIR date, keeping the precision of the
+// input. Timestamps are truncated to the day.
+func dateFilter(v interface{}, _ []interface{}) (interface{}, error) {
+	s := strings.TrimSpace(toString(v))
+	if s == "" {
+		return nil, nil
+	}
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l.layout, s); err == nil {
+			return t.Format(l.out), nil
+		}
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized date %q", s)
+}
+
+// referenceFilter renders a Reference to the resource of the type given as the
+// first argument with the input as its id. An optional second argument is
+// used as the display.
+func referenceFilter(v interface{}, args []interface{}) (interface{}, error) {
+	id := toString(v)
+	if id == "" {
+		return nil, nil
+	}
+	typ := arg(args, 0)
+	if typ == "" {
+		return nil, fmt.Errorf("missing resource type")
+	}
+	ref := map[string]string{"reference": typ + "/" + id}
+	if d := arg(args, 1); d != "" {
+		ref["display"] = d
+	}
+	return jsonFilter(ref, nil)
+}
+
+func coding(v interface{}, args []interface{}) map[string]string {
+	code := toString(v)
+	if code == "" {
+		return nil
+	}
+	c := map[string]string{"code": code}
+	if s := arg(args, 0); s != "" {
+		c["system"] = s
+	}
+	if d := arg(args, 1); d != "" {
+		c["display"] = d
+	}
+	return c
+}
+
+// codingFilter renders a Coding with the input as code, and the system and
+// display given as arguments.
+func codingFilter(v interface{}, args []interface{}) (interface{}, error) {
+	c := coding(v, args)
+	if c == nil {
+		return nil, nil
+	}
+	return jsonFilter(c, nil)
+}
+
+// codeableConceptFilter renders a CodeableConcept with a single coding built
+// like the coding filter. An optional third argument is used as the text.
+func codeableConceptFilter(v interface{}, args []interface{}) (interface{}, error) {
+	c := coding(v, args)
+	if c == nil {
+		return nil, nil
+	}
+	cc := map[string]interface{}{"coding": []interface{}{c}}
+	if t := arg(args, 2); t != "" {
+		cc["text"] = t
+	}
+	return jsonFilter(cc, nil)
+}
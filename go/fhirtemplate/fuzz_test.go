@@ -0,0 +1,37 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package fhirtemplate
+
+import (
+	"bytes"
+	"testing"
+)
+
+// FuzzDecodeXML decodes arbitrary XML documents for templates. Errors are
+// expected for most inputs; panics are failures.
+func FuzzDecodeXML(f *testing.F) {
+	for _, s := range []string{
+		`<ADT><PID id="1"><name>Doe</name><name>Roe</name></PID></ADT>`,
+		`<?xml version="1.0"?><ClinicalDocument xmlns="urn:hl7-org:v3"><id root="2.16.840.1.113883.19" extension="1"/></ClinicalDocument>`,
+		`<a><b>x<c/>y</b></a>`,
+		`<a>&amp;&lt;</a>`,
+		`<a><b></a>`,
+		``,
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = DecodeXML(bytes.NewReader(data))
+	})
+}
@@ -0,0 +1,444 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fhirtemplate converts bespoke JSON and XML payloads into FHIR
+// resources using Liquid-style templates.
+//
+// A template renders the FHIR JSON representation of a resource. Output tags
+// ({{ expr | filter: arg }}) insert values, and logic tags ({% if %},
+// {% unless %}, {% for %}, {% assign %}) control rendering. Strings inserted by
+// output tags are JSON-escaped, so templates are written as JSON with the
+// quotes around string values in place:
+//
+//	{
+//	  "resourceType": "Patient",
+//	  "id": "{{ msg.id }}",
+//	  "birthDate": "{{ msg.dob | date }}",
+//	  "generalPractitioner": [{{ msg.gp | reference: "Practitioner" }}]
+//	}
+//
+// Filters producing FHIR structures (reference, coding, codeableConcept and
+// json) emit raw JSON. Trailing commas and empty values left behind by
+// conditional sections are removed before the output is parsed.
+package fhirtemplate
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Template is a parsed template. It is safe for concurrent use.
+type Template struct {
+	name string
+	root []node
+}
+
+type node interface{}
+
+type textNode string
+
+type outputNode struct {
+	expr *pipeline
+}
+
+type ifNode struct {
+	branches []ifBranch
+	elseBody []node
+}
+
+type ifBranch struct {
+	cond   *condition
+	negate bool
+	body   []node
+}
+
+type forNode struct {
+	variable   string
+	collection *pipeline
+	body       []node
+}
+
+type assignNode struct {
+	variable string
+	value    *pipeline
+}
+
+// Parse parses the template source. name is used in error messages.
+func Parse(name, src string) (*Template, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	p := &parser{toks: toks}
+	root, end, err := p.parseBody()
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", name, err)
+	}
+	if end != "" {
+		return nil, fmt.Errorf("template %s: unexpected {%% %s %%}", name, end)
+	}
+	return &Template{name: name, root: root}, nil
+}
+
+// Execute renders the template for data, which is made available to
+// expressions as the "msg" variable, and writes the output to w.
+func (t *Template) Execute(w io.Writer, data interface{}) error {
+	sc := &scope{vars: map[string]interface{}{"msg": data}}
+	var sb strings.Builder
+	if err := render(&sb, t.root, sc); err != nil {
+		return fmt.Errorf("template %s: %w", t.name, err)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type tokenKind int
+
+const (
+	textToken tokenKind = iota
+	outputToken
+	tagToken
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+}
+
+// tokenize splits src into text, output and tag tokens, applying the "-"
+// whitespace control markers.
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	trimNext := false
+	for len(src) > 0 {
+		i := strings.Index(src, "{")
+		for i >= 0 && i+1 < len(src) && src[i+1] != '{' && src[i+1] != '%' {
+			j := strings.Index(src[i+1:], "{")
+			if j < 0 {
+				i = -1
+				break
+			}
+			i += j + 1
+		}
+		if i < 0 || i+1 >= len(src) {
+			toks = appendText(toks, src, trimNext, false)
+			break
+		}
+		closer, kind := "}}", outputToken
+		if src[i+1] == '%' {
+			closer, kind = "%}", tagToken
+		}
+		end := strings.Index(src[i+2:], closer)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed %s", src[i:i+2])
+		}
+		inner := src[i+2 : i+2+end]
+		trimPrev := strings.HasPrefix(inner, "-")
+		toks = appendText(toks, src[:i], trimNext, trimPrev)
+		trimNext = strings.HasSuffix(inner, "-")
+		inner = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(inner, "-"), "-"))
+		toks = append(toks, token{kind: kind, val: inner})
+		src = src[i+2+end+2:]
+	}
+	return toks, nil
+}
+
+func appendText(toks []token, text string, trimLeft, trimRight bool) []token {
+	if trimLeft {
+		text = strings.TrimLeft(text, " \t\r\n")
+	}
+	if trimRight {
+		text = strings.TrimRight(text, " \t\r\n")
+	}
+	if text == "" {
+		return toks
+	}
+	return append(toks, token{kind: textToken, val: text})
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+// parseBody parses nodes until the end of input or an unmatched block tag
+// (else, elsif, endif, ...), whose full text is returned.
+func (p *parser) parseBody() ([]node, string, error) {
+	var nodes []node
+	for p.pos < len(p.toks) {
+		tok := p.toks[p.pos]
+		p.pos++
+		switch tok.kind {
+		case textToken:
+			nodes = append(nodes, textNode(tok.val))
+		case outputToken:
+			pl, err := parsePipeline(tok.val)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, &outputNode{expr: pl})
+		case tagToken:
+			name, args := splitTag(tok.val)
+			switch name {
+			case "if", "unless":
+				n, err := p.parseIf(name == "unless", args)
+				if err != nil {
+					return nil, "", err
+				}
+				nodes = append(nodes, n)
+			case "for":
+				n, err := p.parseFor(args)
+				if err != nil {
+					return nil, "", err
+				}
+				nodes = append(nodes, n)
+			case "assign":
+				eq := strings.Index(args, "=")
+				if eq < 0 {
+					return nil, "", fmt.Errorf("invalid assign %q", args)
+				}
+				pl, err := parsePipeline(args[eq+1:])
+				if err != nil {
+					return nil, "", err
+				}
+				nodes = append(nodes, &assignNode{variable: strings.TrimSpace(args[:eq]), value: pl})
+			case "comment":
+				if err := p.skipUntil("endcomment"); err != nil {
+					return nil, "", err
+				}
+			case "else", "elsif", "endif", "endunless", "endfor":
+				return nodes, tok.val, nil
+			default:
+				return nil, "", fmt.Errorf("unknown tag %q", name)
+			}
+		}
+	}
+	return nodes, "", nil
+}
+
+func (p *parser) skipUntil(tag string) error {
+	for ; p.pos < len(p.toks); p.pos++ {
+		if name, _ := splitTag(p.toks[p.pos].val); p.toks[p.pos].kind == tagToken && name == tag {
+			p.pos++
+			return nil
+		}
+	}
+	return fmt.Errorf("missing {%% %s %%}", tag)
+}
+
+func (p *parser) parseIf(negate bool, args string) (node, error) {
+	endTag := "endif"
+	if negate {
+		endTag = "endunless"
+	}
+	n := &ifNode{}
+	for {
+		cond, err := parseCondition(args)
+		if err != nil {
+			return nil, err
+		}
+		body, end, err := p.parseBody()
+		if err != nil {
+			return nil, err
+		}
+		n.branches = append(n.branches, ifBranch{cond: cond, negate: negate, body: body})
+		negate = false
+		name, rest := splitTag(end)
+		switch name {
+		case endTag:
+			return n, nil
+		case "elsif":
+			args = rest
+		case "else":
+			body, end, err := p.parseBody()
+			if err != nil {
+				return nil, err
+			}
+			if name, _ := splitTag(end); name != endTag {
+				return nil, fmt.Errorf("expected {%% %s %%}, found %q", endTag, end)
+			}
+			n.elseBody = body
+			return n, nil
+		default:
+			return nil, fmt.Errorf("expected {%% %s %%}, found %q", endTag, end)
+		}
+	}
+}
+
+func (p *parser) parseFor(args string) (node, error) {
+	parts := strings.SplitN(args, " in ", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid for %q", args)
+	}
+	coll, err := parsePipeline(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	body, end, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	if end != "endfor" {
+		return nil, fmt.Errorf("expected {%% endfor %%}, found %q", end)
+	}
+	return &forNode{variable: strings.TrimSpace(parts[0]), collection: coll, body: body}, nil
+}
+
+func splitTag(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t\n"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+// scope holds template variables; inner scopes are created by for loops.
+type scope struct {
+	vars   map[string]interface{}
+	parent *scope
+}
+
+func (s *scope) lookup(name string) (interface{}, bool) {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func render(sb *strings.Builder, nodes []node, sc *scope) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case textNode:
+			sb.WriteString(string(n))
+		case *outputNode:
+			v, err := n.expr.eval(sc)
+			if err != nil {
+				return err
+			}
+			sb.WriteString(toOutput(v))
+		case *assignNode:
+			v, err := n.value.eval(sc)
+			if err != nil {
+				return err
+			}
+			sc.vars[n.variable] = v
+		case *ifNode:
+			done := false
+			for _, b := range n.branches {
+				ok, err := b.cond.eval(sc)
+				if err != nil {
+					return err
+				}
+				if ok != b.negate {
+					if err := render(sb, b.body, sc); err != nil {
+						return err
+					}
+					done = true
+					break
+				}
+			}
+			if !done {
+				if err := render(sb, n.elseBody, sc); err != nil {
+					return err
+				}
+			}
+		case *forNode:
+			v, err := n.collection.eval(sc)
+			if err != nil {
+				return err
+			}
+			items := toList(v)
+			for i, item := range items {
+				inner := &scope{parent: sc, vars: map[string]interface{}{
+					n.variable: item,
+					"forloop": map[string]interface{}{
+						"index":  float64(i + 1),
+						"index0": float64(i),
+						"first":  i == 0,
+						"last":   i == len(items)-1,
+						"length": float64(len(items)),
+					},
+				}}
+				if err := render(sb, n.body, inner); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rawJSON is a value rendered verbatim by output tags.
+type rawJSON string
+
+func toOutput(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case rawJSON:
+		return string(v)
+	default:
+		q := strconv.Quote(toString(v))
+		return q[1 : len(q)-1]
+	}
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case rawJSON:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toList(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	default:
+		return []interface{}{v}
+	}
+}
+
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtemplate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	data := map[string]interface{}{
+		"name":   "Doe",
+		"quote":  `say "hi"`,
+		"count":  float64(3),
+		"gender": "F",
+		"ids":    []interface{}{"a", "b", "c"},
+		"nested": map[string]interface{}{"list": []interface{}{map[string]interface{}{"v": "x"}}},
+		"dob":    "19800102",
+		"ts":     "20200102030405",
+	}
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"text", "plain", "plain"},
+		{"variable", "{{ msg.name }}", "Doe"},
+		{"escaped", "{{ msg.quote }}", `say \"hi\"`},
+		{"missing", "[{{ msg.missing }}]", "[]"},
+		{"index", "{{ msg.ids[1] }}{{ msg.ids[-1] }}", "bc"},
+		{"nested", "{{ msg.nested.list[0].v }}", "x"},
+		{"list shortcut", "{{ msg.nested.list.v }}", "x"},
+		{"size", "{{ msg.ids.size }}", "3"},
+		{"filters", "{{ msg.name | downcase | prepend: 'n-' }}", "n-doe"},
+		{"default", "{{ msg.missing | default: 'none' }}", "none"},
+		{"if", "{% if msg.gender == 'F' %}female{% elsif msg.gender == 'M' %}male{% else %}other{% endif %}", "female"},
+		{"if else", "{% if msg.gender == 'M' %}male{% else %}other{% endif %}", "other"},
+		{"unless", "{% unless msg.missing %}absent{% endunless %}", "absent"},
+		{"and or", "{% if msg.count > 2 and msg.name or msg.missing %}yes{% endif %}", "yes"},
+		{"contains", "{% if msg.ids contains 'b' %}yes{% endif %}", "yes"},
+		{"for", "{% for id in msg.ids %}{{ forloop.index }}:{{ id }}{% unless forloop.last %},{% endunless %}{% endfor %}", "1:a,2:b,3:c"},
+		{"assign", "{% assign n = msg.name | upcase %}{{ n }}", "DOE"},
+		{"comment", "a{% comment %}{{ ignored }}{% endcomment %}b", "ab"},
+		{"whitespace control", "a  {%- if true -%}  b  {%- endif -%}  c", "abc"},
+		{"date", "{{ msg.dob | date }}", "1980-01-02"},
+		{"datetime", "{{ msg.ts | datetime }}", "2020-01-02T03:04:05Z"},
+		{"reference", "{{ msg.name | reference: 'Patient' }}", `{"reference":"Patient/Doe"}`},
+		{"codeableConcept", "{{ msg.gender | codeableConcept: 'http://example.com', 'Female' }}", `{"coding":[{"code":"F","display":"Female","system":"http://example.com"}]}`},
+		{"json", "{{ msg.ids | json }}", `["a","b","c"]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl, err := Parse(test.name, test.tmpl)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned unexpected error: %v", test.tmpl, err)
+			}
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, data); err != nil {
+				t.Fatalf("Execute() returned unexpected error: %v", err)
+			}
+			if got := sb.String(); got != test.want {
+				t.Errorf("Execute() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, tmpl := range []string{
+		"{{ msg.name ",
+		"{% if msg.x %}no end",
+		"{% endif %}",
+		"{% for x msg.ids %}{% endfor %}",
+		"{{ msg.x | nosuchfilter }}",
+		"{% frobnicate %}",
+		"{% if msg.x %}{% endfor %}",
+	} {
+		t.Run(tmpl, func(t *testing.T) {
+			if _, err := Parse("test", tmpl); err == nil {
+				t.Errorf("Parse(%q) succeeded, want error", tmpl)
+			}
+		})
+	}
+}
+
+func TestExecute_FilterError(t *testing.T) {
+	tmpl, err := Parse("test", "{{ msg | date }}")
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, "not a date"); err == nil {
+		t.Errorf("Execute() succeeded, want error")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"strings"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// addressStyle is the layout of the locality line of a mailing label.
+type addressStyle int
+
+const (
+	// cityStatePostal is "City, ST 12345", as in North America and Australia.
+	cityStatePostal addressStyle = iota
+	// postalCity is "12345 City", as in most of continental Europe.
+	postalCity
+	// cityThenPostal puts the postal code on its own line, as in the United
+	// Kingdom.
+	cityThenPostal
+	// postalStateCity is "123-4567 State City", before the street lines, as
+	// in Japan, China and Korea.
+	postalStateCity
+)
+
+// addressStyles are the address layouts of countries, by ISO 3166 code.
+// Countries without an entry use cityStatePostal.
+var addressStyles = map[string]addressStyle{
+	"DE": postalCity, "FR": postalCity, "IT": postalCity, "ES": postalCity, "NL": postalCity, "BE": postalCity,
+	"CH": postalCity, "AT": postalCity, "DK": postalCity, "SE": postalCity, "NO": postalCity, "FI": postalCity,
+	"PL": postalCity, "PT": postalCity, "CZ": postalCity, "BR": postalCity, "MX": postalCity,
+	"GB": cityThenPostal, "UK": cityThenPostal, "IE": cityThenPostal,
+	"JP": postalStateCity, "CN": postalStateCity, "KR": postalStateCity, "TW": postalStateCity,
+}
+
+// countryNames maps common country names of addresses to their ISO 3166
+// codes.
+var countryNames = map[string]string{
+	"united states": "US", "usa": "US", "united states of america": "US",
+	"canada": "CA", "australia": "AU", "germany": "DE", "deutschland": "DE",
+	"france": "FR", "italy": "IT", "spain": "ES", "netherlands": "NL",
+	"united kingdom": "GB", "great britain": "GB", "ireland": "IE",
+	"japan": "JP", "china": "CN", "korea": "KR", "brazil": "BR", "mexico": "MX",
+}
+
+// countryCode returns the ISO 3166 code of the country of an address, or
+// "" if it is unknown.
+func countryCode(country string) string {
+	c := strings.TrimSpace(country)
+	if len(c) == 2 {
+		return strings.ToUpper(c)
+	}
+	return countryNames[strings.ToLower(c)]
+}
+
+// FormatAddress returns the mailing label of a, one line per line of the
+// label. The layout is that of the country of a, or of the region of locale,
+// i.e. "en-US", for addresses without one; the country is written on the
+// last line unless it is the region of locale. The text of a is returned if
+// it has one.
+func FormatAddress(a *d4pb.Address, locale string) string {
+	if text, ok := StringValue(a.GetText()); ok && text != "" {
+		return text
+	}
+	_, region := splitLocale(locale)
+	country := a.GetCountry().GetValue()
+	code := countryCode(country)
+	if code == "" && country == "" {
+		code = region
+	}
+	var street []string
+	for _, l := range a.GetLine() {
+		street = append(street, l.GetValue())
+	}
+	city, state, postal := a.GetCity().GetValue(), a.GetState().GetValue(), a.GetPostalCode().GetValue()
+
+	var lines []string
+	switch addressStyles[code] {
+	case postalCity:
+		lines = append(street, join(" ", postal, city))
+		if state != "" && code != "DE" && code != "FR" {
+			lines = append(lines, state)
+		}
+	case cityThenPostal:
+		lines = append(street, city, state, postal)
+	case postalStateCity:
+		lines = append([]string{join(" ", postal, state, city)}, street...)
+	default:
+		lines = append(street, join(", ", city, join(" ", state, postal)))
+	}
+	if country != "" && (region == "" || code != region) {
+		lines = append(lines, country)
+	}
+	return strings.Join(nonEmpty(lines), "\n")
+}
+
+// join joins the non-empty parts with sep.
+func join(sep string, parts ...string) string {
+	return strings.Join(nonEmpty(parts), sep)
+}
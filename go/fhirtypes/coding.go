@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"strings"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+// caseInsensitiveSystems are the code systems whose codes match regardless
+// of case. Codes of all other systems are case-sensitive, as the FHIR
+// specification requires unless a CodeSystem states otherwise.
+var caseInsensitiveSystems = map[string]bool{
+	"http://hl7.org/fhir/sid/icd-9-cm":  true,
+	"http://hl7.org/fhir/sid/icd-10":    true,
+	"http://hl7.org/fhir/sid/icd-10-cm": true,
+	"urn:ietf:bcp:47":                   true,
+}
+
+// Coding returns a Coding of code in system.
+func Coding(system, code string) *d4pb.Coding {
+	return &d4pb.Coding{System: URI(system), Code: Code(code)}
+}
+
+// CodingMatches reports whether c is code in system. system may name a
+// version as "system|version", in which case c matches only if it has that
+// version or none; a system without a version matches every version of c.
+// Codes are compared case-sensitively except in ICD-9-CM, ICD-10, ICD-10-CM
+// and BCP 47.
+func CodingMatches(c *d4pb.Coding, system, code string) bool {
+	return matches(c.GetSystem().GetValue(), c.GetVersion().GetValue(), c.GetCode().GetValue(), system, code)
+}
+
+// HasCoding reports whether one of the codings of cc is code in system, as
+// by CodingMatches.
+func HasCoding(cc *d4pb.CodeableConcept, system, code string) bool {
+	for _, c := range cc.GetCoding() {
+		if CodingMatches(c, system, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// FirstCodingIn returns the first coding of cc in system, which may name a
+// version as for CodingMatches, or nil if there is none.
+func FirstCodingIn(cc *d4pb.CodeableConcept, system string) *d4pb.Coding {
+	sys, version := splitVersion(system)
+	for _, c := range cc.GetCoding() {
+		if c.GetSystem().GetValue() == sys && versionMatches(c.GetVersion().GetValue(), version) {
+			return c
+		}
+	}
+	return nil
+}
+
+// MatchesAny reports whether one of the codings of cc is in the expansion of
+// vs, including nested contains. Abstract entries of the expansion, which
+// only group the codes below them, match nothing. A coding and an entry with
+// different versions of the same system do not match, but a version on only
+// one of them is ignored.
+func MatchesAny(cc *d4pb.CodeableConcept, vs *vspb.ValueSet) bool {
+	for _, c := range cc.GetCoding() {
+		if expansionHas(vs.GetExpansion().GetContains(), c) {
+			return true
+		}
+	}
+	return false
+}
+
+func expansionHas(contains []*vspb.ValueSet_Expansion_Contains, c *d4pb.Coding) bool {
+	for _, e := range contains {
+		if !e.GetAbstract().GetValue() && e.GetCode().GetValue() != "" {
+			system := e.GetSystem().GetValue()
+			if v := e.GetVersion().GetValue(); v != "" {
+				system += "|" + v
+			}
+			if CodingMatches(c, system, e.GetCode().GetValue()) {
+				return true
+			}
+		}
+		if expansionHas(e.GetContains(), c) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the coding with system, version and code is
+// wantCode in wantSystem, which may include a version.
+func matches(system, version, code, wantSystem, wantCode string) bool {
+	wantSystem, wantVersion := splitVersion(wantSystem)
+	if system != wantSystem || !versionMatches(version, wantVersion) || code == "" {
+		return false
+	}
+	if caseInsensitiveSystems[system] {
+		return strings.EqualFold(code, wantCode)
+	}
+	return code == wantCode
+}
+
+// splitVersion splits a canonical "system|version" into its parts.
+func splitVersion(system string) (string, string) {
+	if i := strings.LastIndexByte(system, '|'); i >= 0 {
+		return system[:i], system[i+1:]
+	}
+	return system, ""
+}
+
+func versionMatches(version, want string) bool {
+	return version == "" || want == "" || version == want
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"testing"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+const (
+	loinc  = "http://loinc.org"
+	icd10  = "http://hl7.org/fhir/sid/icd-10-cm"
+	snomed = "http://snomed.info/sct"
+)
+
+func TestHasCoding(t *testing.T) {
+	versioned := Coding(snomed, "38341003")
+	versioned.Version = String("http://snomed.info/sct/731000124108/version/20240301")
+	cc := &d4pb.CodeableConcept{Coding: []*d4pb.Coding{
+		Coding(loinc, "8867-4"),
+		Coding(icd10, "E11.9"),
+		versioned,
+		{System: URI("http://example.com/codes"), Code: Code("Abc")},
+	}}
+	tests := []struct {
+		name         string
+		system, code string
+		want         bool
+	}{
+		{"exact", loinc, "8867-4", true},
+		{"other system", snomed, "8867-4", false},
+		{"case-insensitive system", icd10, "e11.9", true},
+		{"case-sensitive system", "http://example.com/codes", "abc", false},
+		{"matching version", snomed + "|http://snomed.info/sct/731000124108/version/20240301", "38341003", true},
+		{"other version", snomed + "|http://snomed.info/sct/731000124108/version/20230901", "38341003", false},
+		{"unversioned coding", loinc + "|2.76", "8867-4", true},
+		{"no code", loinc, "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := HasCoding(cc, tc.system, tc.code); got != tc.want {
+				t.Errorf("HasCoding(%q, %q) = %v, want %v", tc.system, tc.code, got, tc.want)
+			}
+		})
+	}
+
+	if got := FirstCodingIn(cc, snomed); got != versioned {
+		t.Errorf("FirstCodingIn(%q) = %v, want %v", snomed, got, versioned)
+	}
+	if got := FirstCodingIn(cc, snomed+"|other"); got != nil {
+		t.Errorf("FirstCodingIn(%q) = %v, want nil", snomed+"|other", got)
+	}
+	if got := FirstCodingIn(nil, loinc); got != nil {
+		t.Errorf("FirstCodingIn(nil, %q) = %v, want nil", loinc, got)
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	vs := &vspb.ValueSet{Expansion: &vspb.ValueSet_Expansion{Contains: []*vspb.ValueSet_Expansion_Contains{
+		{System: URI(icd10), Code: Code("E11"), Abstract: Boolean(true), Contains: []*vspb.ValueSet_Expansion_Contains{
+			{System: URI(icd10), Code: Code("E11.9")},
+		}},
+		{System: URI(loinc), Version: String("2.76"), Code: Code("4548-4")},
+	}}}
+	tests := []struct {
+		name string
+		c    *d4pb.Coding
+		want bool
+	}{
+		{"nested", Coding(icd10, "e11.9"), true},
+		{"abstract", Coding(icd10, "E11"), false},
+		{"same version", &d4pb.Coding{System: URI(loinc), Version: String("2.76"), Code: Code("4548-4")}, true},
+		{"other version", &d4pb.Coding{System: URI(loinc), Version: String("2.70"), Code: Code("4548-4")}, false},
+		{"unversioned", Coding(loinc, "4548-4"), true},
+		{"absent", Coding(loinc, "8867-4"), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cc := &d4pb.CodeableConcept{Coding: []*d4pb.Coding{Coding(snomed, "1"), tc.c}}
+			if got := MatchesAny(cc, vs); got != tc.want {
+				t.Errorf("MatchesAny(%v) = %v, want %v", tc.c, got, tc.want)
+			}
+		})
+	}
+}
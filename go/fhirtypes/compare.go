@@ -0,0 +1,41 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+// Ordering is the result of comparing two FHIR values, which may be
+// indeterminate for values of different precisions.
+type Ordering int
+
+// Orderings.
+const (
+	// Indeterminate is the result of comparing values that may be either
+	// equal or different, such as 2024-03 and 2024-03-15.
+	Indeterminate Ordering = iota
+	Less
+	Equal
+	Greater
+)
+
+func (o Ordering) String() string {
+	switch o {
+	case Less:
+		return "less"
+	case Equal:
+		return "equal"
+	case Greater:
+		return "greater"
+	}
+	return "indeterminate"
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fhirtypes provides constructors and accessors for the FHIR R4
+// primitive data type protos, sparing callers the wrapper structs and nil
+// checks:
+//
+//	obs.Code = &d4pb.CodeableConcept{Text: fhirtypes.String("Heart rate")}
+//	obs.Issued = fhirtypes.InstantFromTime(time.Now(), d4pb.Instant_MILLISECOND)
+//	if text, ok := fhirtypes.StringValue(obs.GetCode().GetText()); ok {
+//		...
+//	}
+//
+// The accessors report whether the primitive has a value: they return false
+// for nil primitives and for primitives without a value, which the protos
+// represent with the primitiveHasNoValue extension, as for an element holding
+// only extensions.
+//
+// Dates, dateTimes and instants convert to and from time.Time, keeping their
+// precision and timezone, and compare with the semantics of partial dates:
+// CompareTemporal reports 2024-03 and 2024-03-15 as Indeterminate rather than
+// ordering them.
+//
+// Quantities compare, add and subtract across UCUM units, i.e. mg/dL and g/L,
+// treating those with a comparator as the range of values they allow, and
+// Money is added, rounded and allocated exactly in decimal. Periods and
+// Ranges test for overlap and containment, with missing bounds leaving them
+// open-ended.
+//
+// HumanNames and Addresses format for display and mailing labels in the
+// conventions of a locale, and ParseName splits display names. HasCoding,
+// FirstCodingIn and MatchesAny test CodeableConcepts for codes and value set
+// membership. References build from a type and id, parse into their
+// components, convert between literal and logical forms, and compare across
+// service base URLs with SameReference. Identifier systems normalize
+// between their urn:oid:, urn:uuid: and URL forms for SameIdentifier.
+package fhirtypes
+
+import (
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// primitiveHasNoValueURL is the extension marking primitives without a value.
+const primitiveHasNoValueURL = "https://g.co/fhir/StructureDefinition/primitiveHasNoValue"
+
+// hasNoValue reports whether exts mark their primitive as having no value.
+func hasNoValue(exts []*d4pb.Extension) bool {
+	for _, e := range exts {
+		if e.GetUrl().GetValue() == primitiveHasNoValueURL {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a String of value v.
+func String(v string) *d4pb.String { return &d4pb.String{Value: v} }
+
+// Code returns a Code of value v.
+func Code(v string) *d4pb.Code { return &d4pb.Code{Value: v} }
+
+// ID returns an Id of value v.
+func ID(v string) *d4pb.Id { return &d4pb.Id{Value: v} }
+
+// URI returns a Uri of value v.
+func URI(v string) *d4pb.Uri { return &d4pb.Uri{Value: v} }
+
+// URL returns a Url of value v.
+func URL(v string) *d4pb.Url { return &d4pb.Url{Value: v} }
+
+// Canonical returns a Canonical of value v, i.e.
+// "http://hl7.org/fhir/StructureDefinition/Patient|4.0.1".
+func Canonical(v string) *d4pb.Canonical { return &d4pb.Canonical{Value: v} }
+
+// OID returns an Oid of value v, i.e. "urn:oid:1.2.3".
+func OID(v string) *d4pb.Oid { return &d4pb.Oid{Value: v} }
+
+// UUID returns a Uuid of value v, i.e. "urn:uuid:...".
+func UUID(v string) *d4pb.Uuid { return &d4pb.Uuid{Value: v} }
+
+// Markdown returns a Markdown of value v.
+func Markdown(v string) *d4pb.Markdown { return &d4pb.Markdown{Value: v} }
+
+// Boolean returns a Boolean of value v.
+func Boolean(v bool) *d4pb.Boolean { return &d4pb.Boolean{Value: v} }
+
+// Integer returns an Integer of value v.
+func Integer(v int32) *d4pb.Integer { return &d4pb.Integer{Value: v} }
+
+// PositiveInt returns a PositiveInt of value v, which must be at least 1.
+func PositiveInt(v uint32) *d4pb.PositiveInt { return &d4pb.PositiveInt{Value: v} }
+
+// UnsignedInt returns an UnsignedInt of value v.
+func UnsignedInt(v uint32) *d4pb.UnsignedInt { return &d4pb.UnsignedInt{Value: v} }
+
+// Decimal returns a Decimal of the decimal string v, i.e. "72.50". Decimals
+// are kept as strings to preserve their precision.
+func Decimal(v string) *d4pb.Decimal { return &d4pb.Decimal{Value: v} }
+
+// Base64Binary returns a Base64Binary of the bytes v.
+func Base64Binary(v []byte) *d4pb.Base64Binary { return &d4pb.Base64Binary{Value: v} }
+
+// StringValue returns the value of p and whether it has one.
+func StringValue(p *d4pb.String) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// CodeValue returns the value of p and whether it has one.
+func CodeValue(p *d4pb.Code) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// IDValue returns the value of p and whether it has one.
+func IDValue(p *d4pb.Id) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// URIValue returns the value of p and whether it has one.
+func URIValue(p *d4pb.Uri) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// URLValue returns the value of p and whether it has one.
+func URLValue(p *d4pb.Url) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// CanonicalValue returns the value of p and whether it has one.
+func CanonicalValue(p *d4pb.Canonical) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// OIDValue returns the value of p and whether it has one.
+func OIDValue(p *d4pb.Oid) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// UUIDValue returns the value of p and whether it has one.
+func UUIDValue(p *d4pb.Uuid) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// MarkdownValue returns the value of p and whether it has one.
+func MarkdownValue(p *d4pb.Markdown) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// BooleanValue returns the value of p and whether it has one.
+func BooleanValue(p *d4pb.Boolean) (bool, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// IntegerValue returns the value of p and whether it has one.
+func IntegerValue(p *d4pb.Integer) (int32, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// PositiveIntValue returns the value of p and whether it has one.
+func PositiveIntValue(p *d4pb.PositiveInt) (uint32, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// UnsignedIntValue returns the value of p and whether it has one.
+func UnsignedIntValue(p *d4pb.UnsignedInt) (uint32, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// DecimalValue returns the decimal string of p and whether it has one.
+func DecimalValue(p *d4pb.Decimal) (string, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
+
+// Base64BinaryValue returns the bytes of p and whether it has any.
+func Base64BinaryValue(p *d4pb.Base64Binary) ([]byte, bool) {
+	return p.GetValue(), p != nil && !hasNoValue(p.GetExtension())
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func TestValues(t *testing.T) {
+	noValue := &d4pb.String{Extension: []*d4pb.Extension{{Url: URI(primitiveHasNoValueURL)}}}
+	tests := []struct {
+		name   string
+		value  func() (interface{}, bool)
+		want   interface{}
+		wantOK bool
+	}{
+		{"string", func() (interface{}, bool) { return StringValue(String("x")) }, "x", true},
+		{"empty string", func() (interface{}, bool) { return StringValue(String("")) }, "", true},
+		{"nil string", func() (interface{}, bool) { return StringValue(nil) }, "", false},
+		{"no value", func() (interface{}, bool) { return StringValue(noValue) }, "", false},
+		{"code", func() (interface{}, bool) { return CodeValue(Code("final")) }, "final", true},
+		{"nil uri", func() (interface{}, bool) { return URIValue(nil) }, "", false},
+		{"boolean", func() (interface{}, bool) { return BooleanValue(Boolean(false)) }, false, true},
+		{"nil boolean", func() (interface{}, bool) { return BooleanValue(nil) }, false, false},
+		{"integer", func() (interface{}, bool) { return IntegerValue(Integer(-3)) }, int32(-3), true},
+		{"positiveInt", func() (interface{}, bool) { return PositiveIntValue(PositiveInt(2)) }, uint32(2), true},
+		{"decimal", func() (interface{}, bool) { return DecimalValue(Decimal("1.50")) }, "1.50", true},
+		{"base64Binary", func() (interface{}, bool) { return Base64BinaryValue(Base64Binary([]byte("ab"))) }, []byte("ab"), true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := tc.value()
+			if ok != tc.wantOK {
+				t.Errorf("value ok = %v, want %v", ok, tc.wantOK)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("value diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFromTime(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() returned unexpected error: %v", err)
+	}
+	ts := time.Date(2024, time.March, 15, 10, 30, 45, 123456789, time.UTC)
+	tests := []struct {
+		name string
+		got  proto.Message
+		want proto.Message
+	}{
+		{
+			name: "date",
+			got:  DateFromTime(ts, d4pb.Date_MONTH),
+			want: &d4pb.Date{ValueUs: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "UTC", Precision: d4pb.Date_MONTH},
+		},
+		{
+			name: "date in location",
+			got:  DateFromTime(ts.In(ny), d4pb.Date_DAY),
+			want: &d4pb.Date{ValueUs: time.Date(2024, time.March, 15, 0, 0, 0, 0, ny).UnixMicro(), Timezone: "America/New_York", Precision: d4pb.Date_DAY},
+		},
+		{
+			name: "dateTime",
+			got:  DateTimeFromTime(ts, d4pb.DateTime_MILLISECOND),
+			want: &d4pb.DateTime{ValueUs: ts.Truncate(time.Millisecond).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_MILLISECOND},
+		},
+		{
+			name: "dateTime with offset",
+			got:  DateTimeFromTime(ts.In(time.FixedZone("", -5*3600-1800)), d4pb.DateTime_SECOND),
+			want: &d4pb.DateTime{ValueUs: ts.Truncate(time.Second).UnixMicro(), Timezone: "-05:30", Precision: d4pb.DateTime_SECOND},
+		},
+		{
+			name: "partial dateTime",
+			got:  DateTimeFromTime(ts, d4pb.DateTime_YEAR),
+			want: &d4pb.DateTime{ValueUs: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "UTC", Precision: d4pb.DateTime_YEAR},
+		},
+		{
+			name: "instant",
+			got:  InstantFromTime(ts.In(ny), d4pb.Instant_MICROSECOND),
+			want: &d4pb.Instant{ValueUs: ts.Truncate(time.Microsecond).UnixMicro(), Timezone: "-04:00", Precision: d4pb.Instant_MICROSECOND},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.got, protocmp.Transform()); diff != "" {
+				t.Errorf("FromTime() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToTime(t *testing.T) {
+	ts := time.Date(2024, time.March, 15, 10, 30, 45, 123000000, time.FixedZone("+01:00", 3600))
+	for _, p := range []d4pb.DateTime_Precision{d4pb.DateTime_YEAR, d4pb.DateTime_DAY, d4pb.DateTime_SECOND, d4pb.DateTime_MILLISECOND} {
+		dt := DateTimeFromTime(ts, p)
+		got, err := DateTimeToTime(dt)
+		if err != nil {
+			t.Fatalf("DateTimeToTime(%v) returned unexpected error: %v", dt, err)
+		}
+		if !got.Equal(truncate(ts, p)) {
+			t.Errorf("DateTimeToTime(%v) = %v, want %v", dt, got, truncate(ts, p))
+		}
+		if got := DateTimeFromTime(got, p); !proto.Equal(got, dt) {
+			t.Errorf("DateTimeFromTime(DateTimeToTime(%v)) = %v, want round trip", dt, got)
+		}
+	}
+	got, err := InstantToTime(&d4pb.Instant{ValueUs: ts.UnixMicro(), Timezone: "America/New_York", Precision: d4pb.Instant_MILLISECOND})
+	if err != nil {
+		t.Fatalf("InstantToTime() returned unexpected error: %v", err)
+	}
+	if !got.Equal(ts) || got.Location().String() != "America/New_York" {
+		t.Errorf("InstantToTime() = %v, want %v in America/New_York", got, ts)
+	}
+	parsed, err := time.Parse(time.RFC3339, "2024-03-15T10:00:00+05:00")
+	if err != nil {
+		t.Fatalf("time.Parse() returned unexpected error: %v", err)
+	}
+	d := DateFromTime(parsed, d4pb.Date_DAY)
+	if d.GetTimezone() != "+05:00" {
+		t.Errorf("DateFromTime(%v) set timezone %q, want +05:00", parsed, d.GetTimezone())
+	}
+	if got, err := DateToTime(d); err != nil || !got.Equal(time.Date(2024, time.March, 15, 0, 0, 0, 0, parsed.Location())) {
+		t.Errorf("DateToTime(%v) = %v, %v, want 2024-03-15 00:00 +05:00", d, got, err)
+	}
+	if _, err := DateToTime(&d4pb.Date{Timezone: "+25:00"}); err == nil {
+		t.Errorf("DateToTime() with timezone +25:00 succeeded, want error")
+	}
+}
+
+func TestCompareTemporal(t *testing.T) {
+	day := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	noon := day.Add(12 * time.Hour)
+	tests := []struct {
+		name string
+		a, b proto.Message
+		want Ordering
+	}{
+		{"same day", DateFromTime(day, d4pb.Date_DAY), DateFromTime(noon, d4pb.Date_DAY), Equal},
+		{"month and day", DateFromTime(day, d4pb.Date_MONTH), DateFromTime(day, d4pb.Date_DAY), Indeterminate},
+		{"previous month", DateFromTime(day.AddDate(0, -1, 0), d4pb.Date_MONTH), DateFromTime(day, d4pb.Date_DAY), Less},
+		{"next year", DateFromTime(day.AddDate(1, 0, 0), d4pb.Date_YEAR), DateTimeFromTime(noon, d4pb.DateTime_SECOND), Greater},
+		{"day and time", DateFromTime(day, d4pb.Date_DAY), DateTimeFromTime(noon, d4pb.DateTime_SECOND), Indeterminate},
+		{"seconds and milliseconds", DateTimeFromTime(noon, d4pb.DateTime_SECOND), InstantFromTime(noon, d4pb.Instant_MILLISECOND), Equal},
+		{"milliseconds", DateTimeFromTime(noon, d4pb.DateTime_SECOND), InstantFromTime(noon.Add(time.Millisecond), d4pb.Instant_MILLISECOND), Less},
+		{"offsets", DateTimeFromTime(noon, d4pb.DateTime_SECOND), DateTimeFromTime(noon.In(time.FixedZone("", -3600)), d4pb.DateTime_SECOND), Equal},
+		{"day in other timezone", DateFromTime(day, d4pb.Date_DAY), DateFromTime(noon.In(time.FixedZone("", -3600)), d4pb.Date_DAY), Indeterminate},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CompareTemporal(tc.a, tc.b)
+			if err != nil {
+				t.Fatalf("CompareTemporal() returned unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("CompareTemporal(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+	if _, err := CompareTemporal(&d4pb.Date{}, DateFromTime(day, d4pb.Date_DAY)); err == nil {
+		t.Errorf("CompareTemporal() of a Date without precision succeeded, want error")
+	}
+}
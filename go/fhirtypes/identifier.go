@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+const (
+	oidPrefix  = "urn:oid:"
+	uuidPrefix = "urn:uuid:"
+)
+
+var uuidRE = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// oidSystems are the URLs FHIR defines for systems that documents such as
+// CDA and HL7 v2 messages name by OID.
+var oidSystems = map[string]string{
+	"2.16.840.1.113883.4.1":    "http://hl7.org/fhir/sid/us-ssn",
+	"2.16.840.1.113883.4.6":    "http://hl7.org/fhir/sid/us-npi",
+	"2.16.840.1.113883.6.1":    "http://loinc.org",
+	"2.16.840.1.113883.6.8":    "http://unitsofmeasure.org",
+	"2.16.840.1.113883.6.12":   "http://www.ama-assn.org/go/cpt",
+	"2.16.840.1.113883.6.69":   "http://hl7.org/fhir/sid/ndc",
+	"2.16.840.1.113883.6.88":   "http://www.nlm.nih.gov/research/umls/rxnorm",
+	"2.16.840.1.113883.6.90":   "http://hl7.org/fhir/sid/icd-10-cm",
+	"2.16.840.1.113883.6.96":   "http://snomed.info/sct",
+	"2.16.840.1.113883.6.3":    "http://hl7.org/fhir/sid/icd-10",
+	"2.16.840.1.113883.6.103":  "http://hl7.org/fhir/sid/icd-9-cm",
+	"2.16.840.1.113883.12.292": "http://hl7.org/fhir/sid/cvx",
+}
+
+// systemOIDs is the inverse of oidSystems.
+var systemOIDs = func() map[string]string {
+	m := map[string]string{}
+	for oid, u := range oidSystems {
+		m[u] = oid
+	}
+	return m
+}()
+
+// ValidOID reports whether s is an OID in dotted decimal form, such as
+// "2.16.840.1.113883.6.1", without the urn:oid: prefix: at least two arcs,
+// without leading zeros, the first 0, 1 or 2 and the second below 40 under
+// 0 and 1.
+func ValidOID(s string) bool {
+	arcs := strings.Split(s, ".")
+	if len(arcs) < 2 {
+		return false
+	}
+	for _, a := range arcs {
+		if a == "" || len(a) > 1 && a[0] == '0' || strings.Trim(a, "0123456789") != "" {
+			return false
+		}
+	}
+	if arcs[0] != "0" && arcs[0] != "1" && arcs[0] != "2" {
+		return false
+	}
+	if arcs[0] != "2" {
+		n, err := strconv.Atoi(arcs[1])
+		return err == nil && n < 40
+	}
+	return true
+}
+
+// NormalizeSystem returns the canonical form of the identifier or code
+// system s: the URL FHIR defines for well-known OIDs, i.e. http://loinc.org
+// for urn:oid:2.16.840.1.113883.6.1; urn:oid: and lowercase urn:uuid: URIs
+// for bare or differently cased OIDs and UUIDs; and URLs with a lowercase
+// scheme and host and without a trailing slash. Other systems are returned
+// unchanged.
+func NormalizeSystem(s string) string {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasPrefix(lower, oidPrefix) && ValidOID(s[len(oidPrefix):]):
+		return oidSystem(s[len(oidPrefix):])
+	case ValidOID(s):
+		return oidSystem(s)
+	case strings.HasPrefix(lower, uuidPrefix) && uuidRE.MatchString(s[len(uuidPrefix):]):
+		return lower
+	case uuidRE.MatchString(s):
+		return uuidPrefix + lower
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return s
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+func oidSystem(oid string) string {
+	if u, ok := oidSystems[oid]; ok {
+		return u
+	}
+	return oidPrefix + oid
+}
+
+// SystemOID returns the OID of the system s, without the urn:oid: prefix,
+// for systems that are OIDs or well-known URLs with one, as when writing
+// CDA documents.
+func SystemOID(s string) (string, bool) {
+	n := NormalizeSystem(s)
+	if oid, ok := systemOIDs[n]; ok {
+		return oid, true
+	}
+	if strings.HasPrefix(n, oidPrefix) {
+		return n[len(oidPrefix):], true
+	}
+	return "", false
+}
+
+// NormalizeIdentifier returns a copy of id with its system normalized by
+// NormalizeSystem and the surrounding whitespace of its value removed.
+func NormalizeIdentifier(id *d4pb.Identifier) *d4pb.Identifier {
+	out := proto.Clone(id).(*d4pb.Identifier)
+	if out.GetSystem() != nil {
+		out.System.Value = NormalizeSystem(out.GetSystem().GetValue())
+	}
+	if out.GetValue() != nil {
+		out.Value.Value = strings.TrimSpace(out.GetValue().GetValue())
+	}
+	return out
+}
+
+// SameIdentifier reports whether a and b identify the same thing: their
+// systems are the same under NormalizeSystem and their values, which are
+// case-sensitive, are equal. Identifiers without a system or value are not
+// the same as any other, as their value means nothing outside the
+// organization that assigned it.
+func SameIdentifier(a, b *d4pb.Identifier) bool {
+	sa, sb := a.GetSystem().GetValue(), b.GetSystem().GetValue()
+	va, vb := strings.TrimSpace(a.GetValue().GetValue()), strings.TrimSpace(b.GetValue().GetValue())
+	return sa != "" && va != "" && NormalizeSystem(sa) == NormalizeSystem(sb) && va == vb
+}
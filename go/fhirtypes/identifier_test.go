@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func TestValidOID(t *testing.T) {
+	for s, want := range map[string]bool{
+		"2.16.840.1.113883.6.1": true,
+		"1.3.6.1":               true,
+		"0.39":                  true,
+		"1.40":                  false,
+		"3.1":                   false,
+		"2":                     false,
+		"2.16..1":               false,
+		"2.016.840":             false,
+		"urn:oid:2.16.840":      false,
+		"2.16.x":                false,
+	} {
+		if got := ValidOID(s); got != want {
+			t.Errorf("ValidOID(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestNormalizeSystem(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"urn:oid:2.16.840.1.113883.6.1", "http://loinc.org"},
+		{"2.16.840.1.113883.4.1", "http://hl7.org/fhir/sid/us-ssn"},
+		{"URN:OID:1.2.3.4", "urn:oid:1.2.3.4"},
+		{"1.2.3.4", "urn:oid:1.2.3.4"},
+		{"urn:uuid:0F7C8A3E-5D1C-4B0E-9A7D-2F3F6B8E1C2A", "urn:uuid:0f7c8a3e-5d1c-4b0e-9a7d-2f3f6b8e1c2a"},
+		{"0f7c8a3e-5d1c-4b0e-9a7d-2f3f6b8e1c2a", "urn:uuid:0f7c8a3e-5d1c-4b0e-9a7d-2f3f6b8e1c2a"},
+		{"HTTP://Example.COM/mrn/", "http://example.com/mrn"},
+		{" http://loinc.org ", "http://loinc.org"},
+		{"urn:ietf:rfc:3986", "urn:ietf:rfc:3986"},
+	}
+	for _, tc := range tests {
+		if got := NormalizeSystem(tc.in); got != tc.want {
+			t.Errorf("NormalizeSystem(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	if got, ok := SystemOID("http://snomed.info/sct"); !ok || got != "2.16.840.1.113883.6.96" {
+		t.Errorf("SystemOID(snomed) = %q, %v, want %q", got, ok, "2.16.840.1.113883.6.96")
+	}
+	if got, ok := SystemOID("urn:oid:1.2.3"); !ok || got != "1.2.3" {
+		t.Errorf("SystemOID(urn:oid:1.2.3) = %q, %v, want %q", got, ok, "1.2.3")
+	}
+	if _, ok := SystemOID("http://example.com"); ok {
+		t.Errorf("SystemOID(http://example.com) succeeded, want false")
+	}
+}
+
+func TestSameIdentifier(t *testing.T) {
+	id := func(system, value string) *d4pb.Identifier {
+		return &d4pb.Identifier{System: URI(system), Value: String(value)}
+	}
+	tests := []struct {
+		name string
+		a, b *d4pb.Identifier
+		want bool
+	}{
+		{"oid and url", id("urn:oid:2.16.840.1.113883.4.6", "1234567893"), id("http://hl7.org/fhir/sid/us-npi", "1234567893"), true},
+		{"trailing slash", id("https://example.com/mrn/", " 42"), id("https://EXAMPLE.com/mrn", "42"), true},
+		{"value case", id("https://example.com/mrn", "ab"), id("https://example.com/mrn", "AB"), false},
+		{"other system", id("https://example.com/mrn", "42"), id("https://example.org/mrn", "42"), false},
+		{"no system", id("", "42"), id("", "42"), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SameIdentifier(tc.a, tc.b); got != tc.want {
+				t.Errorf("SameIdentifier(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+	got := NormalizeIdentifier(id("urn:oid:2.16.840.1.113883.4.1", "123-45-6789 "))
+	if diff := cmp.Diff(id("http://hl7.org/fhir/sid/us-ssn", "123-45-6789"), got, protocmp.Transform()); diff != "" {
+		t.Errorf("NormalizeIdentifier() diff (-want +got):\n%s", diff)
+	}
+}
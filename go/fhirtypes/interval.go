@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// period returns the start and end of p, from the start of the span of its
+// start to the end of the span of its end, so that a period ending on
+// 2024-03 includes all of March. Zero times are unbounded.
+func period(p *d4pb.Period) (start, end time.Time, err error) {
+	if s := p.GetStart(); s != nil && !hasNoValue(s.GetExtension()) {
+		if start, _, err = Span(s); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start: %w", err)
+		}
+	}
+	if e := p.GetEnd(); e != nil && !hasNoValue(e.GetExtension()) {
+		if _, end, err = Span(e); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end: %w", err)
+		}
+	}
+	return start, end, nil
+}
+
+// PeriodContains reports whether t is within p. A period without a start
+// began before any time and one without an end, such as an ongoing
+// encounter, continues after it; the end of p includes the whole of its
+// precision, so 2024-01-01 to 2024-01-31 contains noon on January 31st.
+func PeriodContains(p *d4pb.Period, t time.Time) (bool, error) {
+	start, end, err := period(p)
+	if err != nil {
+		return false, err
+	}
+	return (start.IsZero() || !t.Before(start)) && (end.IsZero() || t.Before(end)), nil
+}
+
+// PeriodsOverlap reports whether a and b have a time in common, with the
+// open-ended boundaries of PeriodContains.
+func PeriodsOverlap(a, b *d4pb.Period) (bool, error) {
+	as, ae, err := period(a)
+	if err != nil {
+		return false, err
+	}
+	bs, be, err := period(b)
+	if err != nil {
+		return false, err
+	}
+	return (ae.IsZero() || bs.IsZero() || bs.Before(ae)) && (be.IsZero() || as.IsZero() || as.Before(be)), nil
+}
+
+// PeriodDuration returns the length of p, with the end of p including the
+// whole of its precision: 2024-01-01 to 2024-01-31 is 31 days. It returns
+// ErrIndeterminate for periods without a start or an end.
+func PeriodDuration(p *d4pb.Period) (time.Duration, error) {
+	start, end, err := period(p)
+	if err != nil {
+		return 0, err
+	}
+	if start.IsZero() || end.IsZero() {
+		return 0, fmt.Errorf("%w: open-ended period", ErrIndeterminate)
+	}
+	return end.Sub(start), nil
+}
+
+// RangeContains reports whether every value q allows is within r. The
+// bounds of a Range are inclusive, and a Range without a low or high bound
+// is open-ended. q is compared in its unit, converting those of r as by
+// CompareQuantities; a q with a comparator is contained only if the whole
+// range of values it allows is, so <5 mg is not within 1-10 mg.
+func RangeContains(r *d4pb.Range, q *d4pb.Quantity) (bool, error) {
+	rb, err := rangeBounds(r, q)
+	if err != nil {
+		return false, err
+	}
+	qb, err := interval(q, big.NewRat(1, 1))
+	if err != nil {
+		return false, err
+	}
+	return rb.contains(qb), nil
+}
+
+// RangesOverlap reports whether a and b have a value in common, with the
+// open-ended boundaries of RangeContains.
+func RangesOverlap(a, b *d4pb.Range) (bool, error) {
+	var unit *d4pb.Quantity
+	for _, s := range []*d4pb.SimpleQuantity{a.GetLow(), a.GetHigh(), b.GetLow(), b.GetHigh()} {
+		if s != nil {
+			unit = QuantityOfSimple(s)
+			break
+		}
+	}
+	if unit == nil {
+		return true, nil
+	}
+	ab, err := rangeBounds(a, unit)
+	if err != nil {
+		return false, err
+	}
+	bb, err := rangeBounds(b, unit)
+	if err != nil {
+		return false, err
+	}
+	return !ab.before(bb) && !bb.before(ab), nil
+}
+
+// rangeBounds returns the bounds of r in the unit of q.
+func rangeBounds(r *d4pb.Range, q *d4pb.Quantity) (bounds, error) {
+	var b bounds
+	for _, x := range []struct {
+		s *d4pb.SimpleQuantity
+		v **big.Rat
+	}{{r.GetLow(), &b.lo}, {r.GetHigh(), &b.hi}} {
+		if x.s == nil {
+			continue
+		}
+		sq := QuantityOfSimple(x.s)
+		v, err := value(sq)
+		if err != nil {
+			return bounds{}, err
+		}
+		f, err := conversion(sq, q)
+		if err != nil {
+			return bounds{}, err
+		}
+		*x.v = v.Mul(v, f)
+	}
+	return b, nil
+}
+
+// contains reports whether every value of c is within b.
+func (b bounds) contains(c bounds) bool {
+	if b.lo != nil {
+		if c.lo == nil {
+			return false
+		}
+		if d := b.lo.Cmp(c.lo); d > 0 || d == 0 && b.loOpen && !c.loOpen {
+			return false
+		}
+	}
+	if b.hi != nil {
+		if c.hi == nil {
+			return false
+		}
+		if d := b.hi.Cmp(c.hi); d < 0 || d == 0 && b.hiOpen && !c.hiOpen {
+			return false
+		}
+	}
+	return true
+}
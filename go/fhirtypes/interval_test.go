@@ -0,0 +1,155 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func day(y int, m time.Month, d int) *d4pb.DateTime {
+	return DateTimeFromTime(time.Date(y, m, d, 0, 0, 0, 0, time.UTC), d4pb.DateTime_DAY)
+}
+
+func TestPeriod(t *testing.T) {
+	january := &d4pb.Period{Start: day(2024, 1, 1), End: day(2024, 1, 31)}
+	ongoing := &d4pb.Period{Start: day(2024, 1, 31)}
+	untilFebruary := &d4pb.Period{End: DateTimeFromTime(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), d4pb.DateTime_MONTH)}
+	march := &d4pb.Period{Start: day(2024, 3, 1), End: day(2024, 3, 31)}
+
+	containsTests := []struct {
+		name string
+		p    *d4pb.Period
+		t    time.Time
+		want bool
+	}{
+		{"last day", january, time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), true},
+		{"after end", january, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), false},
+		{"before start", january, time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC), false},
+		{"no end", ongoing, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"no start", untilFebruary, time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"end of month", untilFebruary, time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC), true},
+	}
+	for _, tc := range containsTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := PeriodContains(tc.p, tc.t)
+			if err != nil {
+				t.Fatalf("PeriodContains() returned unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("PeriodContains(%v, %v) = %v, want %v", tc.p, tc.t, got, tc.want)
+			}
+		})
+	}
+
+	overlapTests := []struct {
+		name string
+		a, b *d4pb.Period
+		want bool
+	}{
+		{"touching days", january, ongoing, true},
+		{"disjoint", january, march, false},
+		{"open ends", ongoing, untilFebruary, true},
+		{"open end before", untilFebruary, march, false},
+		{"unbounded", &d4pb.Period{}, march, true},
+	}
+	for _, tc := range overlapTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := PeriodsOverlap(tc.a, tc.b)
+			if err != nil {
+				t.Fatalf("PeriodsOverlap() returned unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("PeriodsOverlap(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+
+	d, err := PeriodDuration(january)
+	if err != nil {
+		t.Fatalf("PeriodDuration() returned unexpected error: %v", err)
+	}
+	if want := 31 * 24 * time.Hour; d != want {
+		t.Errorf("PeriodDuration(%v) = %v, want %v", january, d, want)
+	}
+	if _, err := PeriodDuration(ongoing); !errors.Is(err, ErrIndeterminate) {
+		t.Errorf("PeriodDuration(%v) returned error %v, want %v", ongoing, err, ErrIndeterminate)
+	}
+}
+
+func simple(value, code string) *d4pb.SimpleQuantity {
+	return &d4pb.SimpleQuantity{Value: Decimal(value), System: URI(UCUMSystem), Code: Code(code)}
+}
+
+func TestRange(t *testing.T) {
+	lt := c4pb.QuantityComparatorCode_LESS_THAN
+	normal := &d4pb.Range{Low: simple("70", "mg/dL"), High: simple("100", "mg/dL")}
+	atLeast := &d4pb.Range{Low: simple("2", "g/L")}
+
+	containsTests := []struct {
+		name string
+		r    *d4pb.Range
+		q    *d4pb.Quantity
+		want bool
+	}{
+		{"inside", normal, Quantity("85", "mg/dL"), true},
+		{"inclusive", normal, Quantity("100", "mg/dL"), true},
+		{"converted", normal, Quantity("0.9", "g/L"), true},
+		{"above", normal, Quantity("101", "mg/dL"), false},
+		{"open high", atLeast, Quantity("500", "mg/dL"), true},
+		{"comparator", normal, withComparator(Quantity("90", "mg/dL"), lt), false},
+		{"comparator in open range", &d4pb.Range{High: simple("100", "mg/dL")}, withComparator(Quantity("90", "mg/dL"), lt), true},
+	}
+	for _, tc := range containsTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RangeContains(tc.r, tc.q)
+			if err != nil {
+				t.Fatalf("RangeContains() returned unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("RangeContains(%v, %v) = %v, want %v", tc.r, tc.q, got, tc.want)
+			}
+		})
+	}
+
+	overlapTests := []struct {
+		name string
+		a, b *d4pb.Range
+		want bool
+	}{
+		{"touching", normal, &d4pb.Range{Low: simple("1", "g/L"), High: simple("2", "g/L")}, true},
+		{"disjoint", normal, atLeast, false},
+		{"open", &d4pb.Range{High: simple("80", "mg/dL")}, normal, true},
+		{"unbounded", &d4pb.Range{}, &d4pb.Range{}, true},
+	}
+	for _, tc := range overlapTests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RangesOverlap(tc.a, tc.b)
+			if err != nil {
+				t.Fatalf("RangesOverlap() returned unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("RangesOverlap(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+	if _, err := RangeContains(normal, Quantity("1", "min")); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("RangeContains() with minutes returned error %v, want %v", err, ErrIncompatibleUnits)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// ErrCurrencyMismatch is returned for arithmetic on amounts of different
+// currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// RoundingMode is the rounding of amounts to the minor unit of their
+// currency.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest minor unit, and ties to the even
+	// one, as banks do.
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest minor unit, and ties away from zero.
+	RoundHalfUp
+	// RoundDown rounds toward zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+// minorUnits are the ISO 4217 currencies whose minor unit is not a
+// hundredth.
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnits returns the number of decimal places of the minor unit of the
+// ISO 4217 currency, i.e. 2 for USD and 0 for JPY.
+func MinorUnits(currency string) int {
+	if n, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return n
+	}
+	return 2
+}
+
+// Money returns a Money of the decimal value in the ISO 4217 currency, i.e.
+// Money("12.50", "USD").
+func Money(value, currency string) *d4pb.Money {
+	return &d4pb.Money{Value: Decimal(value), Currency: &d4pb.Money_CurrencyCode{Value: currency}}
+}
+
+// AddMoney returns a+b, with the decimal places of the more precise of them.
+// Amounts are added exactly, and only in the same currency.
+func AddMoney(a, b *d4pb.Money) (*d4pb.Money, error) {
+	return SumMoney(a, b)
+}
+
+// SubtractMoney returns a-b, as for AddMoney.
+func SubtractMoney(a, b *d4pb.Money) (*d4pb.Money, error) {
+	vb, err := amount(b)
+	if err != nil {
+		return nil, err
+	}
+	neg := Money(vb.Neg(vb).FloatString(scale(b.GetValue().GetValue())), b.GetCurrency().GetValue())
+	return SumMoney(a, neg)
+}
+
+// SumMoney returns the sum of ms, as for AddMoney, skipping nil amounts. It
+// returns nil if there are none.
+func SumMoney(ms ...*d4pb.Money) (*d4pb.Money, error) {
+	var out *d4pb.Money
+	sum := new(big.Rat)
+	digits := 0
+	for _, m := range ms {
+		if m == nil {
+			continue
+		}
+		v, err := amount(m)
+		if err != nil {
+			return nil, err
+		}
+		if out == nil {
+			out = Money("", m.GetCurrency().GetValue())
+		} else if err := sameCurrency(out, m); err != nil {
+			return nil, err
+		}
+		sum.Add(sum, v)
+		if s := scale(m.GetValue().GetValue()); s > digits {
+			digits = s
+		}
+	}
+	if out != nil {
+		out.Value = Decimal(sum.FloatString(digits))
+	}
+	return out, nil
+}
+
+// CompareMoney compares a and b, which must be in the same currency.
+func CompareMoney(a, b *d4pb.Money) (Ordering, error) {
+	if err := sameCurrency(a, b); err != nil {
+		return Indeterminate, err
+	}
+	va, err := amount(a)
+	if err != nil {
+		return Indeterminate, err
+	}
+	vb, err := amount(b)
+	if err != nil {
+		return Indeterminate, err
+	}
+	return [...]Ordering{Less, Equal, Greater}[va.Cmp(vb)+1], nil
+}
+
+// MultiplyMoney returns m times the decimal factor, such as "0.8" for the
+// part of a charge paid with 20% coinsurance, rounded to the minor unit of
+// its currency with mode.
+func MultiplyMoney(m *d4pb.Money, factor string, mode RoundingMode) (*d4pb.Money, error) {
+	v, err := amount(m)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := new(big.Rat).SetString(factor)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", factor)
+	}
+	return roundedMoney(v.Mul(v, f), m.GetCurrency().GetValue(), mode), nil
+}
+
+// RoundMoney returns m rounded to the minor unit of its currency with mode.
+func RoundMoney(m *d4pb.Money, mode RoundingMode) (*d4pb.Money, error) {
+	v, err := amount(m)
+	if err != nil {
+		return nil, err
+	}
+	return roundedMoney(v, m.GetCurrency().GetValue(), mode), nil
+}
+
+// AllocateMoney splits m, rounded half even to the minor unit of its
+// currency, into parts proportional to the decimal weights, such as the
+// charges of the line items a payment is spread over. The parts are in
+// minor units and sum to exactly the rounded m: the units left over by
+// rounding every part down go to those with the largest remainders, the
+// earliest first.
+func AllocateMoney(m *d4pb.Money, weights ...string) ([]*d4pb.Money, error) {
+	v, err := amount(m)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Rat)
+	ws := make([]*big.Rat, len(weights))
+	for i, w := range weights {
+		r, ok := new(big.Rat).SetString(w)
+		if !ok || r.Sign() < 0 {
+			return nil, fmt.Errorf("invalid weight %q", w)
+		}
+		ws[i] = r
+		total.Add(total, r)
+	}
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("weights sum to zero")
+	}
+	currency := m.GetCurrency().GetValue()
+	places := MinorUnits(currency)
+	unit := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil))
+	// units is the rounded m in minor units, allocated as a magnitude.
+	units := new(big.Rat).Quo(round(v, places, RoundHalfEven), unit).Num()
+	sign := units.Sign()
+	units.Abs(units)
+
+	parts := make([]*big.Int, len(ws))
+	rems := make([]*big.Rat, len(ws))
+	left := new(big.Int).Set(units)
+	for i, w := range ws {
+		share := new(big.Rat).Mul(new(big.Rat).SetInt(units), w)
+		share.Quo(share, total)
+		parts[i] = new(big.Int).Quo(share.Num(), share.Denom())
+		rems[i] = share.Sub(share, new(big.Rat).SetInt(parts[i]))
+		left.Sub(left, parts[i])
+	}
+	order := make([]int, len(ws))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return rems[order[i]].Cmp(rems[order[j]]) > 0 })
+	for i := 0; left.Sign() > 0; i++ {
+		parts[order[i]].Add(parts[order[i]], big.NewInt(1))
+		left.Sub(left, big.NewInt(1))
+	}
+	out := make([]*d4pb.Money, len(parts))
+	for i, p := range parts {
+		if sign < 0 {
+			p.Neg(p)
+		}
+		r := new(big.Rat).Mul(new(big.Rat).SetInt(p), unit)
+		out[i] = Money(r.FloatString(places), currency)
+	}
+	return out, nil
+}
+
+// Adjudication is the adjudication of a line item of a ClaimResponse or an
+// ExplanationOfBenefit, i.e. an *eobpb.ExplanationOfBenefit_Item_Adjudication.
+type Adjudication interface {
+	GetCategory() *d4pb.CodeableConcept
+	GetAmount() *d4pb.Money
+}
+
+// AdjudicationTotals returns the sums of the amounts of adjs by category,
+// keyed by the "system|code" of the first coding of their category, i.e.
+// "http://terminology.hl7.org/CodeSystem/adjudication|benefit". Adjudications
+// without an amount are skipped.
+func AdjudicationTotals[A Adjudication](adjs []A) (map[string]*d4pb.Money, error) {
+	out := map[string]*d4pb.Money{}
+	for _, a := range adjs {
+		if a.GetAmount() == nil {
+			continue
+		}
+		var key string
+		if cs := a.GetCategory().GetCoding(); len(cs) > 0 {
+			key = cs[0].GetSystem().GetValue() + "|" + cs[0].GetCode().GetValue()
+		}
+		sum, err := SumMoney(out[key], a.GetAmount())
+		if err != nil {
+			return nil, fmt.Errorf("category %q: %w", key, err)
+		}
+		out[key] = sum
+	}
+	return out, nil
+}
+
+// amount returns the value of m.
+func amount(m *d4pb.Money) (*big.Rat, error) {
+	s, ok := DecimalValue(m.GetValue())
+	if !ok {
+		return nil, fmt.Errorf("money has no value")
+	}
+	v, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	return v, nil
+}
+
+func sameCurrency(a, b *d4pb.Money) error {
+	ca, cb := a.GetCurrency().GetValue(), b.GetCurrency().GetValue()
+	if !strings.EqualFold(ca, cb) {
+		return fmt.Errorf("%w: %q and %q", ErrCurrencyMismatch, ca, cb)
+	}
+	return nil
+}
+
+func roundedMoney(v *big.Rat, currency string, mode RoundingMode) *d4pb.Money {
+	places := MinorUnits(currency)
+	return Money(round(v, places, mode).FloatString(places), currency)
+}
+
+// round returns r rounded to places decimal places with mode.
+func round(r *big.Rat, places int, mode RoundingMode) *big.Rat {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	x := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow))
+	q, rem := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		// half compares the remainder to half of the denominator.
+		half := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(x.Denom())
+		var away bool
+		switch mode {
+		case RoundHalfEven:
+			away = half > 0 || half == 0 && q.Bit(0) == 1
+		case RoundHalfUp:
+			away = half >= 0
+		case RoundUp:
+			away = true
+		}
+		if away {
+			q.Add(q, big.NewInt(int64(x.Sign())))
+		}
+	}
+	return new(big.Rat).SetFrac(q, pow)
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	eobpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/explanation_of_benefit_go_proto"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := SumMoney(Money("0.10", "USD"), nil, Money("0.2", "usd"), Money("100", "USD"))
+	if err != nil {
+		t.Fatalf("SumMoney() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Money("100.30", "USD"), sum, protocmp.Transform()); diff != "" {
+		t.Errorf("SumMoney() diff (-want +got):\n%s", diff)
+	}
+	diff, err := SubtractMoney(Money("10.00", "EUR"), Money("10.01", "EUR"))
+	if err != nil {
+		t.Fatalf("SubtractMoney() returned unexpected error: %v", err)
+	}
+	if d := cmp.Diff(Money("-0.01", "EUR"), diff, protocmp.Transform()); d != "" {
+		t.Errorf("SubtractMoney() diff (-want +got):\n%s", d)
+	}
+	if _, err := AddMoney(Money("1", "USD"), Money("1", "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("AddMoney(USD, EUR) returned error %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if got, err := CompareMoney(Money("1.50", "USD"), Money("1.5", "USD")); err != nil || got != Equal {
+		t.Errorf("CompareMoney(1.50, 1.5) = %v, %v, want %v", got, err, Equal)
+	}
+	if got, _ := SumMoney(); got != nil {
+		t.Errorf("SumMoney() = %v, want nil", got)
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		value, currency string
+		mode            RoundingMode
+		want            string
+	}{
+		{"2.345", "USD", RoundHalfEven, "2.34"},
+		{"2.355", "USD", RoundHalfEven, "2.36"},
+		{"2.345", "USD", RoundHalfUp, "2.35"},
+		{"-2.345", "USD", RoundHalfUp, "-2.35"},
+		{"2.349", "USD", RoundDown, "2.34"},
+		{"-2.341", "USD", RoundUp, "-2.35"},
+		{"1234.5", "JPY", RoundHalfEven, "1234"},
+		{"1.2345", "KWD", RoundHalfUp, "1.235"},
+	}
+	for _, tc := range tests {
+		got, err := RoundMoney(Money(tc.value, tc.currency), tc.mode)
+		if err != nil {
+			t.Fatalf("RoundMoney(%s %s) returned unexpected error: %v", tc.value, tc.currency, err)
+		}
+		if got.GetValue().GetValue() != tc.want {
+			t.Errorf("RoundMoney(%s %s, %v) = %s, want %s", tc.value, tc.currency, tc.mode, got.GetValue().GetValue(), tc.want)
+		}
+	}
+	got, err := MultiplyMoney(Money("123.45", "USD"), "0.8", RoundHalfEven)
+	if err != nil {
+		t.Fatalf("MultiplyMoney() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Money("98.76", "USD"), got, protocmp.Transform()); diff != "" {
+		t.Errorf("MultiplyMoney() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestAllocateMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       *d4pb.Money
+		weights []string
+		want    []*d4pb.Money
+	}{
+		{"thirds", Money("100", "USD"), []string{"1", "1", "1"}, []*d4pb.Money{Money("33.34", "USD"), Money("33.33", "USD"), Money("33.33", "USD")}},
+		{"proportional", Money("10.00", "USD"), []string{"150.00", "50.00"}, []*d4pb.Money{Money("7.50", "USD"), Money("2.50", "USD")}},
+		{"largest remainder", Money("1.00", "USD"), []string{"1", "2"}, []*d4pb.Money{Money("0.33", "USD"), Money("0.67", "USD")}},
+		{"negative", Money("-5", "JPY"), []string{"1", "1"}, []*d4pb.Money{Money("-3", "JPY"), Money("-2", "JPY")}},
+		{"zero weight", Money("1", "USD"), []string{"0", "1"}, []*d4pb.Money{Money("0.00", "USD"), Money("1.00", "USD")}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := AllocateMoney(tc.m, tc.weights...)
+			if err != nil {
+				t.Fatalf("AllocateMoney() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("AllocateMoney() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := AllocateMoney(Money("1", "USD"), "0"); err == nil {
+		t.Errorf("AllocateMoney() with zero weights succeeded, want error")
+	}
+}
+
+func TestAdjudicationTotals(t *testing.T) {
+	const adjudication = "http://terminology.hl7.org/CodeSystem/adjudication"
+	adj := func(code, value string) *eobpb.ExplanationOfBenefit_Item_Adjudication {
+		return &eobpb.ExplanationOfBenefit_Item_Adjudication{
+			Category: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{Coding(adjudication, code)}},
+			Amount:   Money(value, "USD"),
+		}
+	}
+	var adjs []*eobpb.ExplanationOfBenefit_Item_Adjudication
+	for _, item := range []*eobpb.ExplanationOfBenefit_Item{
+		{Adjudication: []*eobpb.ExplanationOfBenefit_Item_Adjudication{adj("submitted", "100.00"), adj("benefit", "80.10")}},
+		{Adjudication: []*eobpb.ExplanationOfBenefit_Item_Adjudication{adj("submitted", "50.00"), adj("benefit", "39.95")}},
+	} {
+		adjs = append(adjs, item.GetAdjudication()...)
+	}
+	got, err := AdjudicationTotals(adjs)
+	if err != nil {
+		t.Fatalf("AdjudicationTotals() returned unexpected error: %v", err)
+	}
+	want := map[string]*d4pb.Money{
+		adjudication + "|submitted": Money("150.00", "USD"),
+		adjudication + "|benefit":   Money("120.05", "USD"),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("AdjudicationTotals() diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"strings"
+	"unicode"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// familyFirst are the languages writing the family name first.
+var familyFirst = map[string]bool{"zh": true, "ja": true, "ko": true, "hu": true, "vi": true, "mn": true}
+
+// splitLocale returns the lowercase language and uppercase region of a BCP
+// 47 locale such as "en-US" or "de_DE".
+func splitLocale(locale string) (lang, region string) {
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return "", ""
+	}
+	lang = strings.ToLower(parts[0])
+	for _, p := range parts[1:] {
+		if len(p) == 2 {
+			region = strings.ToUpper(p)
+		}
+	}
+	return lang, region
+}
+
+// FormatName returns the display form of n in locale, i.e. "Dr. Jane Q Doe"
+// for "en-US" and "Doe Jane" for "hu". The text of n is returned if it has
+// one. Family names come first in Chinese, Japanese, Korean, Hungarian,
+// Vietnamese and Mongolian, and names written in CJK scripts are not
+// separated by spaces.
+func FormatName(n *d4pb.HumanName, locale string) string {
+	if text, ok := StringValue(n.GetText()); ok && text != "" {
+		return text
+	}
+	var given []string
+	for _, g := range n.GetGiven() {
+		given = append(given, g.GetValue())
+	}
+	family := n.GetFamily().GetValue()
+	var parts []string
+	lang, _ := splitLocale(locale)
+	if familyFirst[lang] {
+		parts = append([]string{family}, given...)
+	} else {
+		for _, p := range n.GetPrefix() {
+			parts = append(parts, p.GetValue())
+		}
+		parts = append(append(parts, given...), family)
+		for _, s := range n.GetSuffix() {
+			parts = append(parts, s.GetValue())
+		}
+	}
+	parts = nonEmpty(parts)
+	sep := " "
+	if isCJK(strings.Join(parts, "")) {
+		sep = ""
+	}
+	return strings.Join(parts, sep)
+}
+
+// SortName returns n as "Family, Given Middle", the form of name indexes and
+// sorted lists, or the text of n if it has no family name.
+func SortName(n *d4pb.HumanName) string {
+	family := n.GetFamily().GetValue()
+	if family == "" {
+		return FormatName(n, "")
+	}
+	var given []string
+	for _, g := range n.GetGiven() {
+		given = append(given, g.GetValue())
+	}
+	if g := strings.Join(nonEmpty(given), " "); g != "" {
+		return family + ", " + g
+	}
+	return family
+}
+
+func nonEmpty(parts []string) []string {
+	out := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func isCJK(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return false
+		}
+	}
+	return true
+}
+
+// namePrefixes and nameSuffixes are the honorifics and qualifications
+// recognized by ParseName, lowercase and without periods.
+var (
+	namePrefixes = map[string]bool{"mr": true, "mrs": true, "ms": true, "miss": true, "mx": true, "dr": true, "prof": true, "rev": true, "sir": true, "dame": true}
+	nameSuffixes = map[string]bool{"jr": true, "sr": true, "ii": true, "iii": true, "iv": true, "md": true, "phd": true, "do": true, "rn": true, "esq": true}
+)
+
+// ParseName splits the display name s into the parts of a HumanName, keeping
+// s as its text. It understands "Given Middle Family" and "Family, Given
+// Middle", leading honorifics such as "Dr." and trailing qualifications such
+// as "Jr." or "PhD". The last word is taken as the family name, so compound
+// family names are best written in the comma form.
+func ParseName(s string) *d4pb.HumanName {
+	n := &d4pb.HumanName{Text: String(strings.TrimSpace(s))}
+	var family string
+	if i := strings.IndexByte(s, ','); i >= 0 && !allSuffixes(strings.Fields(s[i+1:])) {
+		family = strings.TrimSpace(s[:i])
+		s = s[i+1:]
+	}
+	words := strings.Fields(s)
+	for len(words) > 0 && namePrefixes[normalizeAffix(words[0])] {
+		n.Prefix = append(n.Prefix, String(words[0]))
+		words = words[1:]
+	}
+	var suffixes []string
+	for len(words) > 0 && nameSuffixes[normalizeAffix(words[len(words)-1])] {
+		suffixes = append([]string{strings.TrimSuffix(words[len(words)-1], ",")}, suffixes...)
+		words = words[:len(words)-1]
+	}
+	if family == "" && len(words) > 0 {
+		family = strings.TrimSuffix(words[len(words)-1], ",")
+		words = words[:len(words)-1]
+	}
+	if family != "" {
+		n.Family = String(family)
+	}
+	for _, w := range words {
+		n.Given = append(n.Given, String(strings.TrimSuffix(w, ",")))
+	}
+	for _, sfx := range suffixes {
+		n.Suffix = append(n.Suffix, String(sfx))
+	}
+	return n
+}
+
+// allSuffixes reports whether words, as in "John Doe, Jr.", are
+// qualifications only.
+func allSuffixes(words []string) bool {
+	for _, w := range words {
+		if !nameSuffixes[normalizeAffix(w)] {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeAffix(w string) string {
+	return strings.ToLower(strings.NewReplacer(".", "", ",", "").Replace(w))
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func stringList(vs ...string) []*d4pb.String {
+	var out []*d4pb.String
+	for _, v := range vs {
+		out = append(out, String(v))
+	}
+	return out
+}
+
+func TestFormatName(t *testing.T) {
+	jane := &d4pb.HumanName{Prefix: stringList("Dr."), Given: stringList("Jane", "Q"), Family: String("Doe"), Suffix: stringList("PhD")}
+	tests := []struct {
+		name   string
+		n      *d4pb.HumanName
+		locale string
+		want   string
+	}{
+		{"western", jane, "en-US", "Dr. Jane Q Doe PhD"},
+		{"family first", jane, "hu", "Doe Jane Q"},
+		{"cjk", &d4pb.HumanName{Given: stringList("太郎"), Family: String("山田")}, "ja-JP", "山田太郎"},
+		{"text", &d4pb.HumanName{Text: String("J. Doe"), Family: String("Doe")}, "en", "J. Doe"},
+		{"given only", &d4pb.HumanName{Given: stringList("Cher")}, "", "Cher"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FormatName(tc.n, tc.locale); got != tc.want {
+				t.Errorf("FormatName(%v, %q) = %q, want %q", tc.n, tc.locale, got, tc.want)
+			}
+		})
+	}
+	if got, want := SortName(jane), "Doe, Jane Q"; got != want {
+		t.Errorf("SortName(%v) = %q, want %q", jane, got, want)
+	}
+}
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *d4pb.HumanName
+	}{
+		{"Jane Q Doe", &d4pb.HumanName{Given: stringList("Jane", "Q"), Family: String("Doe")}},
+		{"Dr. Jane Doe, MD", &d4pb.HumanName{Prefix: stringList("Dr."), Given: stringList("Jane"), Family: String("Doe"), Suffix: stringList("MD")}},
+		{"van der Berg, Anna Maria", &d4pb.HumanName{Given: stringList("Anna", "Maria"), Family: String("van der Berg")}},
+		{"Doe, John, Jr.", &d4pb.HumanName{Given: stringList("John"), Family: String("Doe"), Suffix: stringList("Jr.")}},
+		{"Madonna", &d4pb.HumanName{Family: String("Madonna")}},
+	}
+	for _, tc := range tests {
+		tc.want.Text = String(tc.in)
+		if diff := cmp.Diff(tc.want, ParseName(tc.in), protocmp.Transform()); diff != "" {
+			t.Errorf("ParseName(%q) diff (-want +got):\n%s", tc.in, diff)
+		}
+	}
+}
+
+func TestFormatAddress(t *testing.T) {
+	us := &d4pb.Address{Line: stringList("1 Main St", "Apt 2"), City: String("Springfield"), State: String("IL"), PostalCode: String("62701"), Country: String("US")}
+	de := &d4pb.Address{Line: stringList("Hauptstraße 5"), City: String("Berlin"), PostalCode: String("10115"), Country: String("DE")}
+	gb := &d4pb.Address{Line: stringList("10 Downing St"), City: String("London"), PostalCode: String("SW1A 2AA")}
+	tests := []struct {
+		name   string
+		a      *d4pb.Address
+		locale string
+		want   string
+	}{
+		{"domestic", us, "en-US", "1 Main St\nApt 2\nSpringfield, IL 62701"},
+		{"foreign", us, "de-DE", "1 Main St\nApt 2\nSpringfield, IL 62701\nUS"},
+		{"postal first", de, "en-US", "Hauptstraße 5\n10115 Berlin\nDE"},
+		{"locale region", gb, "en-GB", "10 Downing St\nLondon\nSW1A 2AA"},
+		{"text", &d4pb.Address{Text: String("somewhere"), City: String("x")}, "", "somewhere"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FormatAddress(tc.a, tc.locale); got != tc.want {
+				t.Errorf("FormatAddress(%v, %q) = %q, want %q", tc.a, tc.locale, got, tc.want)
+			}
+		})
+	}
+}
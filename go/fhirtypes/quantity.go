@@ -0,0 +1,312 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+var (
+	// ErrIncompatibleUnits is returned for quantities whose units cannot be
+	// converted into each other.
+	ErrIncompatibleUnits = errors.New("incompatible units")
+	// ErrIndeterminate is returned for arithmetic whose result cannot be
+	// stated as a quantity, such as the difference of two upper bounds.
+	ErrIndeterminate = errors.New("indeterminate result")
+)
+
+// Quantity returns a Quantity of the decimal value in the UCUM unit code,
+// i.e. Quantity("5.4", "mmol/L").
+func Quantity(value, code string) *d4pb.Quantity {
+	return &d4pb.Quantity{
+		Value:  Decimal(value),
+		Unit:   String(code),
+		System: URI(UCUMSystem),
+		Code:   Code(code),
+	}
+}
+
+// QuantityOfSimple returns the SimpleQuantity s as a Quantity, or nil if s
+// is nil.
+func QuantityOfSimple(s *d4pb.SimpleQuantity) *d4pb.Quantity {
+	if s == nil {
+		return nil
+	}
+	return &d4pb.Quantity{Value: s.GetValue(), Unit: s.GetUnit(), System: s.GetSystem(), Code: s.GetCode()}
+}
+
+// ConvertQuantity returns q in the UCUM unit code. Units are converted
+// exactly; the decimal places of the result are those of q, or as many as
+// the conversion needs up to 18.
+func ConvertQuantity(q *d4pb.Quantity, code string) (*d4pb.Quantity, error) {
+	v, err := value(q)
+	if err != nil {
+		return nil, err
+	}
+	to := Quantity("0", code)
+	f, err := conversion(q, to)
+	if err != nil {
+		return nil, err
+	}
+	out := proto.Clone(q).(*d4pb.Quantity)
+	out.Value = Decimal(formatRat(new(big.Rat).Mul(v, f), scale(q.GetValue().GetValue())))
+	out.Unit, out.System, out.Code = to.Unit, to.System, to.Code
+	return out, nil
+}
+
+// CompareQuantities compares a and b, converting b to the unit of a. A
+// quantity with a comparator stands for the range of values it allows, so
+// that <5 mg is Less than 5 mg and 6 mg, and Indeterminate against 4 mg.
+func CompareQuantities(a, b *d4pb.Quantity) (Ordering, error) {
+	ra, err := interval(a, big.NewRat(1, 1))
+	if err != nil {
+		return Indeterminate, err
+	}
+	f, err := conversion(b, a)
+	if err != nil {
+		return Indeterminate, err
+	}
+	rb, err := interval(b, f)
+	if err != nil {
+		return Indeterminate, err
+	}
+	switch {
+	case ra.point() && rb.point() && ra.lo.Cmp(rb.lo) == 0:
+		return Equal, nil
+	case ra.before(rb):
+		return Less, nil
+	case rb.before(ra):
+		return Greater, nil
+	}
+	return Indeterminate, nil
+}
+
+// AddQuantities returns a+b in the unit of a. Comparators bound the sum: <5
+// mg plus 2 mg is <7 mg, while the sum of a lower and an upper bound is
+// ErrIndeterminate.
+func AddQuantities(a, b *d4pb.Quantity) (*d4pb.Quantity, error) {
+	return combine(a, b, false)
+}
+
+// SubtractQuantities returns a-b in the unit of a, with the comparators of
+// AddQuantities: subtracting an upper bound gives a lower bound.
+func SubtractQuantities(a, b *d4pb.Quantity) (*d4pb.Quantity, error) {
+	return combine(a, b, true)
+}
+
+func combine(a, b *d4pb.Quantity, subtract bool) (*d4pb.Quantity, error) {
+	va, err := value(a)
+	if err != nil {
+		return nil, err
+	}
+	vb, err := value(b)
+	if err != nil {
+		return nil, err
+	}
+	f, err := conversion(b, a)
+	if err != nil {
+		return nil, err
+	}
+	vb.Mul(vb, f)
+	ca, cb := a.GetComparator().GetValue(), b.GetComparator().GetValue()
+	if subtract {
+		vb.Neg(vb)
+		cb = flip(cb)
+	}
+	c, err := combineComparators(ca, cb)
+	if err != nil {
+		return nil, err
+	}
+	digits := scale(a.GetValue().GetValue())
+	if s := scale(b.GetValue().GetValue()); s > digits {
+		digits = s
+	}
+	out := proto.Clone(a).(*d4pb.Quantity)
+	out.Value = Decimal(formatRat(va.Add(va, vb), digits))
+	out.Comparator = nil
+	if c != c4pb.QuantityComparatorCode_INVALID_UNINITIALIZED {
+		out.Comparator = &d4pb.Quantity_ComparatorCode{Value: c}
+	}
+	return out, nil
+}
+
+// flip returns the comparator of the negation of a quantity with comparator
+// c.
+func flip(c c4pb.QuantityComparatorCode_Value) c4pb.QuantityComparatorCode_Value {
+	switch c {
+	case c4pb.QuantityComparatorCode_LESS_THAN:
+		return c4pb.QuantityComparatorCode_GREATER_THAN
+	case c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO:
+		return c4pb.QuantityComparatorCode_GREATER_THAN_OR_EQUAL_TO
+	case c4pb.QuantityComparatorCode_GREATER_THAN_OR_EQUAL_TO:
+		return c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO
+	case c4pb.QuantityComparatorCode_GREATER_THAN:
+		return c4pb.QuantityComparatorCode_LESS_THAN
+	}
+	return c
+}
+
+// combineComparators returns the comparator of the sum of quantities with
+// comparators a and b.
+func combineComparators(a, b c4pb.QuantityComparatorCode_Value) (c4pb.QuantityComparatorCode_Value, error) {
+	const none = c4pb.QuantityComparatorCode_INVALID_UNINITIALIZED
+	switch {
+	case a == none:
+		return b, nil
+	case b == none:
+		return a, nil
+	case isUpper(a) != isUpper(b):
+		return none, ErrIndeterminate
+	case a == b:
+		return a, nil
+	case isUpper(a):
+		return c4pb.QuantityComparatorCode_LESS_THAN, nil
+	}
+	return c4pb.QuantityComparatorCode_GREATER_THAN, nil
+}
+
+func isUpper(c c4pb.QuantityComparatorCode_Value) bool {
+	return c == c4pb.QuantityComparatorCode_LESS_THAN || c == c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO
+}
+
+// value returns the value of q.
+func value(q *d4pb.Quantity) (*big.Rat, error) {
+	s, ok := DecimalValue(q.GetValue())
+	if !ok {
+		return nil, fmt.Errorf("quantity has no value")
+	}
+	v, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	return v, nil
+}
+
+// unitCode returns the unit of q: its code, or its human readable unit if it
+// has none.
+func unitCode(q *d4pb.Quantity) string {
+	if c := q.GetCode().GetValue(); c != "" {
+		return c
+	}
+	return q.GetUnit().GetValue()
+}
+
+// conversion returns the factor converting values in the unit of from into
+// the unit of to.
+func conversion(from, to *d4pb.Quantity) (*big.Rat, error) {
+	fu, tu := unitCode(from), unitCode(to)
+	fs, ts := from.GetSystem().GetValue(), to.GetSystem().GetValue()
+	if fu == tu && fs == ts {
+		return big.NewRat(1, 1), nil
+	}
+	if (fs != "" && fs != UCUMSystem) || (ts != "" && ts != UCUMSystem) {
+		return nil, fmt.Errorf("%w: %q and %q", ErrIncompatibleUnits, fu, tu)
+	}
+	f, err := parseUnit(orUnity(fu))
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseUnit(orUnity(tu))
+	if err != nil {
+		return nil, err
+	}
+	if !f.commensurable(t) {
+		return nil, fmt.Errorf("%w: %q and %q", ErrIncompatibleUnits, fu, tu)
+	}
+	return f.factor.Quo(f.factor, t.factor), nil
+}
+
+func orUnity(u string) string {
+	if u == "" {
+		return "1"
+	}
+	return u
+}
+
+// bounds is the range of values a quantity allows. nil bounds are
+// unbounded.
+type bounds struct {
+	lo, hi         *big.Rat
+	loOpen, hiOpen bool
+}
+
+// interval returns the bounds of q, scaled by f.
+func interval(q *d4pb.Quantity, f *big.Rat) (bounds, error) {
+	v, err := value(q)
+	if err != nil {
+		return bounds{}, err
+	}
+	v.Mul(v, f)
+	switch q.GetComparator().GetValue() {
+	case c4pb.QuantityComparatorCode_LESS_THAN:
+		return bounds{hi: v, hiOpen: true}, nil
+	case c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO:
+		return bounds{hi: v}, nil
+	case c4pb.QuantityComparatorCode_GREATER_THAN_OR_EQUAL_TO:
+		return bounds{lo: v}, nil
+	case c4pb.QuantityComparatorCode_GREATER_THAN:
+		return bounds{lo: v, loOpen: true}, nil
+	}
+	return bounds{lo: v, hi: v}, nil
+}
+
+func (b bounds) point() bool { return b.lo != nil && b.hi != nil && b.lo.Cmp(b.hi) == 0 }
+
+// before reports whether every value of b is less than every value of c.
+func (b bounds) before(c bounds) bool {
+	if b.hi == nil || c.lo == nil {
+		return false
+	}
+	switch b.hi.Cmp(c.lo) {
+	case -1:
+		return true
+	case 0:
+		return b.hiOpen || c.loOpen
+	}
+	return false
+}
+
+// scale returns the number of decimal places of the decimal string s.
+func scale(s string) int {
+	s = strings.ToLower(s)
+	if i := strings.IndexByte(s, 'e'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// formatRat formats r with at least digits decimal places, or as many as
+// needed to represent it exactly up to 18.
+func formatRat(r *big.Rat, digits int) string {
+	ten := big.NewInt(10)
+	for ; digits < 18; digits++ {
+		scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(ten, big.NewInt(int64(digits)), nil)))
+		if scaled.IsInt() {
+			break
+		}
+	}
+	return r.FloatString(digits)
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func withComparator(q *d4pb.Quantity, c c4pb.QuantityComparatorCode_Value) *d4pb.Quantity {
+	q.Comparator = &d4pb.Quantity_ComparatorCode{Value: c}
+	return q
+}
+
+func TestParseUnit(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"mg/dL", "g/L", "1/100"},
+		{"10*3/uL", "10*9/L", "1"},
+		{"mm[Hg]", "kPa", "133322/1000000"},
+		{"[lb_av]", "kg", "45359237/100000000"},
+		{"{beats}/min", "/s", "1/60"},
+		{"m[iU]/mL", "[iU]/L", "1"},
+		{"(kg.m)/s2", "N", "1"},
+		{"%", "1", "1/100"},
+		{"mmol/L", "umol/mL", "1"},
+		{"cm3", "mL", "1"},
+	}
+	for _, tc := range tests {
+		a, err := parseUnit(tc.a)
+		if err != nil {
+			t.Fatalf("parseUnit(%q) returned unexpected error: %v", tc.a, err)
+		}
+		b, err := parseUnit(tc.b)
+		if err != nil {
+			t.Fatalf("parseUnit(%q) returned unexpected error: %v", tc.b, err)
+		}
+		if !a.commensurable(b) {
+			t.Errorf("%q and %q are not commensurable", tc.a, tc.b)
+			continue
+		}
+		if got := a.factor.Quo(a.factor, b.factor); got.Cmp(rat(tc.want)) != 0 {
+			t.Errorf("%q in %q = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+	for _, s := range []string{"", "foo", "mg/", "(mg", "Cel", "km[lb_av]"} {
+		if _, err := parseUnit(s); err == nil {
+			t.Errorf("parseUnit(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestQuantityOfSimple(t *testing.T) {
+	s := &d4pb.SimpleQuantity{Value: Decimal("5.4"), Unit: String("mmol/l"), System: URI(UCUMSystem), Code: Code("mmol/L")}
+	want := &d4pb.Quantity{Value: Decimal("5.4"), Unit: String("mmol/l"), System: URI(UCUMSystem), Code: Code("mmol/L")}
+	if diff := cmp.Diff(want, QuantityOfSimple(s), protocmp.Transform()); diff != "" {
+		t.Errorf("QuantityOfSimple() diff (-want +got):\n%s", diff)
+	}
+	if got := QuantityOfSimple(nil); got != nil {
+		t.Errorf("QuantityOfSimple(nil) = %v, want nil", got)
+	}
+}
+
+func TestConvertQuantity(t *testing.T) {
+	got, err := ConvertQuantity(Quantity("180", "[lb_av]"), "kg")
+	if err != nil {
+		t.Fatalf("ConvertQuantity() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(Quantity("81.6466266", "kg"), got, protocmp.Transform()); diff != "" {
+		t.Errorf("ConvertQuantity() diff (-want +got):\n%s", diff)
+	}
+	if _, err := ConvertQuantity(Quantity("1", "mg"), "mL"); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("ConvertQuantity(mg, mL) returned error %v, want %v", err, ErrIncompatibleUnits)
+	}
+}
+
+func TestCompareQuantities(t *testing.T) {
+	lt := c4pb.QuantityComparatorCode_LESS_THAN
+	le := c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO
+	ge := c4pb.QuantityComparatorCode_GREATER_THAN_OR_EQUAL_TO
+	tests := []struct {
+		name string
+		a, b *d4pb.Quantity
+		want Ordering
+	}{
+		{"equal", Quantity("5.0", "mg"), Quantity("5", "mg"), Equal},
+		{"converted", Quantity("1", "g"), Quantity("999", "mg"), Greater},
+		{"converted equal", Quantity("100", "mg/dL"), Quantity("1", "g/L"), Equal},
+		{"less", Quantity("4", "mmol/L"), Quantity("5", "mmol/L"), Less},
+		{"upper bound below", withComparator(Quantity("5", "mg"), lt), Quantity("5", "mg"), Less},
+		{"upper bound above", withComparator(Quantity("5", "mg"), lt), Quantity("4", "mg"), Indeterminate},
+		{"closed bounds touching", withComparator(Quantity("5", "mg"), le), withComparator(Quantity("5", "mg"), ge), Indeterminate},
+		{"lower bound above", withComparator(Quantity("6", "mg"), ge), Quantity("5", "mg"), Greater},
+		{"same bounds", withComparator(Quantity("5", "mg"), lt), withComparator(Quantity("5", "mg"), lt), Indeterminate},
+		{"non UCUM same code", &d4pb.Quantity{Value: Decimal("2"), System: URI("http://example.com"), Code: Code("tab")}, &d4pb.Quantity{Value: Decimal("1"), System: URI("http://example.com"), Code: Code("tab")}, Greater},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CompareQuantities(tc.a, tc.b)
+			if err != nil {
+				t.Fatalf("CompareQuantities() returned unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("CompareQuantities() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+	if _, err := CompareQuantities(Quantity("1", "mg"), Quantity("1", "min")); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("CompareQuantities(mg, min) returned error %v, want %v", err, ErrIncompatibleUnits)
+	}
+}
+
+func TestAddSubtractQuantities(t *testing.T) {
+	lt := c4pb.QuantityComparatorCode_LESS_THAN
+	le := c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO
+	gt := c4pb.QuantityComparatorCode_GREATER_THAN
+	tests := []struct {
+		name     string
+		a, b     *d4pb.Quantity
+		subtract bool
+		want     *d4pb.Quantity
+	}{
+		{"add", Quantity("1.5", "g"), Quantity("250", "mg"), false, Quantity("1.75", "g")},
+		{"subtract", Quantity("2", "h"), Quantity("30", "min"), true, Quantity("1.5", "h")},
+		{"keeps decimal places", Quantity("1.20", "L"), Quantity("1.1", "L"), false, Quantity("2.30", "L")},
+		{"upper bounds", withComparator(Quantity("5", "mg"), lt), withComparator(Quantity("2", "mg"), le), false, withComparator(Quantity("7", "mg"), lt)},
+		{"minus upper bound", Quantity("5", "mg"), withComparator(Quantity("2", "mg"), lt), true, withComparator(Quantity("3", "mg"), gt)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			op, fn := AddQuantities, "AddQuantities"
+			if tc.subtract {
+				op, fn = SubtractQuantities, "SubtractQuantities"
+			}
+			got, err := op(tc.a, tc.b)
+			if err != nil {
+				t.Fatalf("%s() returned unexpected error: %v", fn, err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("%s() diff (-want +got):\n%s", fn, diff)
+			}
+		})
+	}
+	if _, err := SubtractQuantities(withComparator(Quantity("5", "mg"), lt), withComparator(Quantity("2", "mg"), lt)); !errors.Is(err, ErrIndeterminate) {
+		t.Errorf("SubtractQuantities() of upper bounds returned error %v, want %v", err, ErrIndeterminate)
+	}
+}
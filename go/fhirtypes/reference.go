@@ -0,0 +1,270 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+var (
+	resourceTypeRE = regexp.MustCompile(`^[A-Z][A-Za-z]+$`)
+	resourceIDRE   = regexp.MustCompile(`^[A-Za-z0-9\-.]{1,64}$`)
+)
+
+// ParsedReference holds the components of a literal reference. Exactly one
+// of Type, URN and Fragment is set.
+type ParsedReference struct {
+	// Base is the service base URL of an absolute reference, i.e.
+	// "https://example.com/fhir", or "" for a relative one.
+	Base string
+	// Type, ID and Version identify the resource, with Version set only for
+	// references to a version, as in "Patient/1/_history/2".
+	Type, ID, Version string
+	// URN is a urn:uuid: or urn:oid: reference, as to the entries of a
+	// Bundle.
+	URN string
+	// Fragment is the id of a contained resource, without the leading "#".
+	Fragment string
+}
+
+// String returns the literal reference of p.
+func (p ParsedReference) String() string {
+	switch {
+	case p.URN != "":
+		return p.URN
+	case p.Type == "":
+		return "#" + p.Fragment
+	}
+	s := p.Type + "/" + p.ID
+	if p.Version != "" {
+		s += "/_history/" + p.Version
+	}
+	if p.Base != "" {
+		s = strings.TrimSuffix(p.Base, "/") + "/" + s
+	}
+	return s
+}
+
+// Reference returns a reference to the resource of resourceType with the
+// logical id, using the typed id field of resourceType, i.e. PatientId,
+// where the Reference proto has one.
+func Reference(resourceType, id string) *d4pb.Reference {
+	ref := &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: String(resourceType + "/" + id)}}
+	// Unknown resource types remain URI references.
+	_ = jsonformat.NormalizeReference(ref)
+	return ref
+}
+
+// ReferenceFromURI returns a reference to the literal reference s, using
+// the typed id field of its resource type where the Reference proto has
+// one, as jsonformat does. Absolute URLs and references to unknown resource
+// types remain URI references.
+func ReferenceFromURI(s string) (*d4pb.Reference, error) {
+	ref := &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: String(s)}}
+	if err := jsonformat.NormalizeReference(ref); err != nil {
+		return nil, fmt.Errorf("invalid reference %q: %w", s, err)
+	}
+	return ref, nil
+}
+
+// ResourceReference is ReferenceFromURI for relative references to a
+// resource, i.e. "Patient/1" or "Patient/1/_history/2". Other references
+// are rejected.
+func ResourceReference(s string) (*d4pb.Reference, error) {
+	if p, err := ParseReference(s); err != nil || p.Type == "" || p.Base != "" {
+		return nil, fmt.Errorf("%q is not a relative reference to a resource", s)
+	}
+	ref, err := ReferenceFromURI(s)
+	if err != nil {
+		return nil, err
+	}
+	if ref.GetUri() != nil {
+		return nil, fmt.Errorf("%q is not a reference to a known resource type", s)
+	}
+	return ref, nil
+}
+
+// ReferenceURI returns the literal reference of ref, whichever of its fields
+// holds it, or "" for a logical reference.
+func ReferenceURI(ref *d4pb.Reference) string {
+	den, err := jsonformat.NewDenormalizedReference(ref)
+	if err != nil {
+		return ""
+	}
+	return den.(*d4pb.Reference).GetUri().GetValue()
+}
+
+// ParseReference splits a literal reference into its components. It accepts
+// relative references such as "Patient/1", absolute ones such as
+// "https://example.com/fhir/Patient/1/_history/2", urn:uuid: and urn:oid:
+// references and fragments such as "#med1".
+func ParseReference(s string) (ParsedReference, error) {
+	switch {
+	case strings.HasPrefix(s, "#") && len(s) > 1:
+		return ParsedReference{Fragment: s[1:]}, nil
+	case strings.HasPrefix(s, "urn:uuid:") || strings.HasPrefix(s, "urn:oid:"):
+		return ParsedReference{URN: s}, nil
+	}
+	parts := strings.Split(s, "/")
+	var p ParsedReference
+	if n := len(parts); n >= 4 && parts[n-2] == "_history" {
+		p.Version = parts[n-1]
+		parts = parts[:n-2]
+	}
+	n := len(parts)
+	if n < 2 || !resourceTypeRE.MatchString(parts[n-2]) || !resourceIDRE.MatchString(parts[n-1]) {
+		return ParsedReference{}, fmt.Errorf("invalid reference %q", s)
+	}
+	p.Type, p.ID = parts[n-2], parts[n-1]
+	if n > 2 {
+		p.Base = strings.Join(parts[:n-2], "/")
+		if u, err := url.Parse(p.Base); err != nil || !u.IsAbs() {
+			return ParsedReference{}, fmt.Errorf("invalid reference %q", s)
+		}
+	}
+	return p, nil
+}
+
+// LogicalReference returns a reference to the resource of resourceType with
+// the business identifier id.
+func LogicalReference(resourceType string, id *d4pb.Identifier) *d4pb.Reference {
+	return &d4pb.Reference{Type: URI(resourceType), Identifier: id}
+}
+
+// ToLogical returns the logical reference equivalent to the literal
+// reference ref, given the resource it refers to: ref with the first
+// identifier of target in system, or its first identifier if system is "",
+// and without the literal reference.
+func ToLogical(ref *d4pb.Reference, target proto.Message, system string) (*d4pb.Reference, error) {
+	res := elementpath.Unwrap(target)
+	typ := elementpath.ResourceType(res)
+	if typ == "" {
+		return nil, fmt.Errorf("%T is not a resource", target)
+	}
+	var id *d4pb.Identifier
+	for _, i := range identifiers(res) {
+		if system == "" || i.GetSystem().GetValue() == system {
+			id = i
+			break
+		}
+	}
+	if id == nil {
+		return nil, fmt.Errorf("%s has no identifier in system %q", typ, system)
+	}
+	out := proto.Clone(ref).(*d4pb.Reference)
+	out.Reference = nil
+	out.Type = URI(typ)
+	out.Identifier = id
+	return out, nil
+}
+
+// ToLiteral returns the literal reference equivalent to the logical
+// reference ref, looking its identifier up with lookup, which returns the id
+// of the resource of resourceType with identifier id. The identifier of ref
+// is kept, as FHIR allows references to carry both.
+func ToLiteral(ref *d4pb.Reference, lookup func(resourceType string, id *d4pb.Identifier) (string, error)) (*d4pb.Reference, error) {
+	typ := ref.GetType().GetValue()
+	if i := strings.LastIndexByte(typ, '/'); i >= 0 {
+		typ = typ[i+1:]
+	}
+	if typ == "" || ref.GetIdentifier() == nil {
+		return nil, fmt.Errorf("reference has no type and identifier")
+	}
+	id, err := lookup(typ, ref.GetIdentifier())
+	if err != nil {
+		return nil, err
+	}
+	out := proto.Clone(ref).(*d4pb.Reference)
+	out.Reference = Reference(typ, id).GetReference()
+	return out, nil
+}
+
+// identifiers returns the identifiers of the resource res.
+func identifiers(res proto.Message) []*d4pb.Identifier {
+	m := res.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName("identifier")
+	if fd == nil || fd.Message() == nil {
+		return nil
+	}
+	var out []*d4pb.Identifier
+	add := func(v protoreflect.Value) {
+		if id, ok := v.Message().Interface().(*d4pb.Identifier); ok {
+			out = append(out, id)
+		}
+	}
+	if fd.IsList() {
+		l := m.Get(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			add(l.Get(i))
+		}
+	} else if m.Has(fd) {
+		add(m.Get(fd))
+	}
+	return out
+}
+
+// SameReference reports whether a and b refer to the same resource. Relative
+// references are resolved against base, the service base URL of the
+// resource holding them, so that "Patient/1" on https://example.com/fhir is
+// the same as "https://example.com/fhir/Patient/1"; bases are compared
+// ignoring the case of their scheme and host and a trailing slash. A
+// reference without a version is the same as one to any version of the
+// resource. Logical references are the same if their types and identifiers
+// are, as by SameIdentifier.
+func SameReference(a, b *d4pb.Reference, base string) bool {
+	sa, sb := ReferenceURI(a), ReferenceURI(b)
+	if sa == "" || sb == "" {
+		return sa == sb && a.GetType().GetValue() == b.GetType().GetValue() &&
+			SameIdentifier(a.GetIdentifier(), b.GetIdentifier())
+	}
+	pa, err := ParseReference(sa)
+	if err != nil {
+		return sa == sb
+	}
+	pb, err := ParseReference(sb)
+	if err != nil {
+		return false
+	}
+	for _, p := range []*ParsedReference{&pa, &pb} {
+		if p.Type != "" && p.Base == "" {
+			p.Base = base
+		}
+		p.Base = normalizeBase(p.Base)
+	}
+	return pa.Base == pb.Base && pa.Type == pb.Type && pa.ID == pb.ID &&
+		pa.URN == pb.URN && pa.Fragment == pb.Fragment &&
+		versionMatches(pa.Version, pb.Version)
+}
+
+// normalizeBase lowercases the scheme and host of the service base URL base
+// and removes its trailing slash.
+func normalizeBase(base string) string {
+	u, err := url.Parse(strings.TrimSuffix(base, "/"))
+	if err != nil {
+		return base
+	}
+	u.Scheme, u.Host = strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	return u.String()
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestReference(t *testing.T) {
+	want := &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "1"}}}
+	if diff := cmp.Diff(want, Reference("Patient", "1"), protocmp.Transform()); diff != "" {
+		t.Errorf("Reference() diff (-want +got):\n%s", diff)
+	}
+	if got := ReferenceURI(Reference("Patient", "1")); got != "Patient/1" {
+		t.Errorf("ReferenceURI() = %q, want %q", got, "Patient/1")
+	}
+}
+
+func TestReferenceFromURI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *d4pb.Reference
+	}{
+		{"Patient/1", &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "1"}}}},
+		{"Observation/2/_history/3", &d4pb.Reference{Reference: &d4pb.Reference_ObservationId{ObservationId: &d4pb.ReferenceId{Value: "2", History: &d4pb.Id{Value: "3"}}}}},
+		{"#med1", &d4pb.Reference{Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: "med1"}}}},
+		{"https://example.com/fhir/Patient/1", &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: "https://example.com/fhir/Patient/1"}}}},
+	}
+	for _, tc := range tests {
+		got, err := ReferenceFromURI(tc.in)
+		if err != nil {
+			t.Fatalf("ReferenceFromURI(%q) returned unexpected error: %v", tc.in, err)
+		}
+		if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("ReferenceFromURI(%q) diff (-want +got):\n%s", tc.in, diff)
+		}
+	}
+}
+
+func TestResourceReference(t *testing.T) {
+	want := &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "1"}}}
+	got, err := ResourceReference("Patient/1")
+	if err != nil {
+		t.Fatalf("ResourceReference() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ResourceReference() diff (-want +got):\n%s", diff)
+	}
+	for _, in := range []string{"#med1", "urn:uuid:0f7c8a3e-5d1c-4b0e-9a7d-2f3f6b8e1c2a", "https://example.com/fhir/Patient/1", "Unknown/1", "Patient"} {
+		if _, err := ResourceReference(in); err == nil {
+			t.Errorf("ResourceReference(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ParsedReference
+	}{
+		{"Patient/1", ParsedReference{Type: "Patient", ID: "1"}},
+		{"Observation/a.b-c/_history/3", ParsedReference{Type: "Observation", ID: "a.b-c", Version: "3"}},
+		{"https://example.com/fhir/Patient/1", ParsedReference{Base: "https://example.com/fhir", Type: "Patient", ID: "1"}},
+		{"urn:uuid:0f7c8a3e-5d1c-4b0e-9a7d-2f3f6b8e1c2a", ParsedReference{URN: "urn:uuid:0f7c8a3e-5d1c-4b0e-9a7d-2f3f6b8e1c2a"}},
+		{"#med1", ParsedReference{Fragment: "med1"}},
+	}
+	for _, tc := range tests {
+		got, err := ParseReference(tc.in)
+		if err != nil {
+			t.Fatalf("ParseReference(%q) returned unexpected error: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Errorf("ParseReference(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+		if s := got.String(); s != tc.in {
+			t.Errorf("ParseReference(%q).String() = %q", tc.in, s)
+		}
+	}
+	for _, s := range []string{"", "Patient", "patient/1", "Patient/a b", "example.com/Patient/1", "#"} {
+		if _, err := ParseReference(s); err == nil {
+			t.Errorf("ParseReference(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestLogicalReference(t *testing.T) {
+	mrn := &d4pb.Identifier{System: URI("urn:oid:1.2.3"), Value: String("12345")}
+	patient := &ppb.Patient{Id: ID("p1"), Identifier: []*d4pb.Identifier{
+		{System: URI("http://example.com/ssn"), Value: String("999")}, mrn,
+	}}
+	literal := Reference("Patient", "p1")
+	literal.Display = String("Jane Doe")
+
+	logical, err := ToLogical(literal, patient, "urn:oid:1.2.3")
+	if err != nil {
+		t.Fatalf("ToLogical() returned unexpected error: %v", err)
+	}
+	want := LogicalReference("Patient", mrn)
+	want.Display = String("Jane Doe")
+	if diff := cmp.Diff(want, logical, protocmp.Transform()); diff != "" {
+		t.Errorf("ToLogical() diff (-want +got):\n%s", diff)
+	}
+	if _, err := ToLogical(literal, patient, "urn:oid:9"); err == nil {
+		t.Errorf("ToLogical() with unknown system succeeded, want error")
+	}
+
+	got, err := ToLiteral(logical, func(typ string, id *d4pb.Identifier) (string, error) {
+		if typ != "Patient" || id.GetValue().GetValue() != "12345" {
+			return "", fmt.Errorf("no %s with identifier %v", typ, id)
+		}
+		return "p1", nil
+	})
+	if err != nil {
+		t.Fatalf("ToLiteral() returned unexpected error: %v", err)
+	}
+	want = Reference("Patient", "p1")
+	want.Type, want.Identifier, want.Display = URI("Patient"), mrn, String("Jane Doe")
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ToLiteral() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSameReference(t *testing.T) {
+	uri := func(s string) *d4pb.Reference { return &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: String(s)}} }
+	mrn := &d4pb.Identifier{System: URI("urn:oid:1.2.3"), Value: String("12345")}
+	const base = "https://example.com/fhir"
+	tests := []struct {
+		name string
+		a, b *d4pb.Reference
+		want bool
+	}{
+		{"relative and typed", uri("Patient/1"), Reference("Patient", "1"), true},
+		{"relative and absolute", Reference("Patient", "1"), uri("HTTPS://Example.com/fhir/Patient/1"), true},
+		{"other base", Reference("Patient", "1"), uri("https://other.com/fhir/Patient/1"), false},
+		{"version", uri("Patient/1/_history/2"), Reference("Patient", "1"), true},
+		{"other versions", uri("Patient/1/_history/2"), uri("Patient/1/_history/3"), false},
+		{"other id", Reference("Patient", "1"), Reference("Patient", "2"), false},
+		{"logical", LogicalReference("Patient", mrn), LogicalReference("Patient", mrn), true},
+		{"logical and literal", LogicalReference("Patient", mrn), Reference("Patient", "1"), false},
+		{"urn", uri("urn:uuid:1"), uri("urn:uuid:1"), true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SameReference(tc.a, tc.b, base); got != tc.want {
+				t.Errorf("SameReference(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// DateFromTime returns the Date of t at precision p, i.e. the month of t for
+// MONTH, in the location of t.
+func DateFromTime(t time.Time, p d4pb.Date_Precision) *d4pb.Date {
+	return &d4pb.Date{
+		ValueUs:   truncate(t, dateTimePrecision(p)).UnixMicro(),
+		Timezone:  partialTimezone(t.Location()),
+		Precision: p,
+	}
+}
+
+// DateTimeFromTime returns the DateTime of t at precision p. Times of second
+// precision or finer keep the UTC offset of t, and dates that of the location
+// of t.
+func DateTimeFromTime(t time.Time, p d4pb.DateTime_Precision) *d4pb.DateTime {
+	tz := offset(t)
+	if p <= d4pb.DateTime_DAY {
+		tz = partialTimezone(t.Location())
+	}
+	return &d4pb.DateTime{ValueUs: truncate(t, p).UnixMicro(), Timezone: tz, Precision: p}
+}
+
+// InstantFromTime returns the Instant of t at precision p, keeping the UTC
+// offset of t.
+func InstantFromTime(t time.Time, p d4pb.Instant_Precision) *d4pb.Instant {
+	dp := d4pb.DateTime_SECOND
+	switch p {
+	case d4pb.Instant_MILLISECOND:
+		dp = d4pb.DateTime_MILLISECOND
+	case d4pb.Instant_MICROSECOND:
+		dp = d4pb.DateTime_MICROSECOND
+	}
+	return &d4pb.Instant{ValueUs: truncate(t, dp).UnixMicro(), Timezone: offset(t), Precision: p}
+}
+
+// dateTimePrecision returns the DateTime precision of the Date precision p.
+func dateTimePrecision(p d4pb.Date_Precision) d4pb.DateTime_Precision {
+	switch p {
+	case d4pb.Date_YEAR:
+		return d4pb.DateTime_YEAR
+	case d4pb.Date_MONTH:
+		return d4pb.DateTime_MONTH
+	}
+	return d4pb.DateTime_DAY
+}
+
+// truncate returns the start of the period of precision p holding t, in the
+// location of t.
+func truncate(t time.Time, p d4pb.DateTime_Precision) time.Time {
+	switch p {
+	case d4pb.DateTime_YEAR:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	case d4pb.DateTime_MONTH:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case d4pb.DateTime_DAY:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case d4pb.DateTime_SECOND:
+		return t.Truncate(time.Second)
+	case d4pb.DateTime_MILLISECOND:
+		return t.Truncate(time.Millisecond)
+	}
+	return t.Truncate(time.Microsecond)
+}
+
+// offset returns the timezone of a time of second precision or finer as
+// jsonformat records it: "Z" for UTC and the UTC offset, i.e. "+01:00",
+// otherwise.
+func offset(t time.Time) string {
+	if t.Location() == time.UTC {
+		return "Z"
+	}
+	_, secs := t.Zone()
+	sign := '+'
+	if secs < 0 {
+		sign, secs = '-', -secs
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, secs/3600, secs%3600/60)
+}
+
+// partialTimezone returns the timezone of a date in location l: its IANA
+// name, or its current UTC offset for locations without one, such as the
+// unnamed fixed zones time.Parse returns for offsets.
+func partialTimezone(l *time.Location) string {
+	if l == time.UTC {
+		return "UTC"
+	}
+	// time.LoadLocation("") returns UTC, so an empty name is no name.
+	if name := l.String(); name != "" && name != "Local" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+	return offset(time.Now().In(l))
+}
+
+// DateToTime returns the start of the Date d in its timezone.
+func DateToTime(d *d4pb.Date) (time.Time, error) {
+	return toTime(d.GetValueUs(), d.GetTimezone())
+}
+
+// DateTimeToTime returns the time of the DateTime d in its timezone, or the
+// start of the date of partial DateTimes.
+func DateTimeToTime(d *d4pb.DateTime) (time.Time, error) {
+	return toTime(d.GetValueUs(), d.GetTimezone())
+}
+
+// InstantToTime returns the time of the Instant i in its timezone.
+func InstantToTime(i *d4pb.Instant) (time.Time, error) {
+	return toTime(i.GetValueUs(), i.GetTimezone())
+}
+
+func toTime(us int64, tz string) (time.Time, error) {
+	l, err := location(tz)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMicro(us).In(l), nil
+}
+
+// location returns the location of the timezone tz of a proto: an IANA
+// name, "UTC", "Z" or a UTC offset such as "-05:00".
+func location(tz string) (*time.Location, error) {
+	switch tz {
+	case "", "Z", "UTC":
+		return time.UTC, nil
+	}
+	if (tz[0] == '+' || tz[0] == '-') && len(tz) == len("+00:00") && tz[3] == ':' {
+		h, herr := strconv.Atoi(tz[1:3])
+		m, merr := strconv.Atoi(tz[4:])
+		if herr != nil || merr != nil || h > 14 || m > 59 {
+			return nil, fmt.Errorf("invalid timezone offset %q", tz)
+		}
+		secs := h*3600 + m*60
+		if tz[0] == '-' {
+			secs = -secs
+		}
+		return time.FixedZone(tz, secs), nil
+	}
+	l, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	return l, nil
+}
+
+// Span returns the period covered by the Date, DateTime or Instant m, from
+// start inclusive to end exclusive: the whole day of a Date of DAY
+// precision, in its timezone, and the microsecond of times of second
+// precision or finer. Seconds, milliseconds and microseconds are a single
+// precision, as in FHIRPath, so 10:30:00 is the same time as 10:30:00.000.
+func Span(m proto.Message) (start, end time.Time, err error) {
+	var us int64
+	var tz string
+	var p d4pb.DateTime_Precision
+	switch v := m.(type) {
+	case *d4pb.Date:
+		us, tz, p = v.GetValueUs(), v.GetTimezone(), dateTimePrecision(v.GetPrecision())
+		if v.GetPrecision() == d4pb.Date_PRECISION_UNSPECIFIED {
+			p = d4pb.DateTime_PRECISION_UNSPECIFIED
+		}
+	case *d4pb.DateTime:
+		us, tz, p = v.GetValueUs(), v.GetTimezone(), v.GetPrecision()
+	case *d4pb.Instant:
+		us, tz, p = v.GetValueUs(), v.GetTimezone(), d4pb.DateTime_SECOND
+		if v.GetPrecision() == d4pb.Instant_PRECISION_UNSPECIFIED {
+			p = d4pb.DateTime_PRECISION_UNSPECIFIED
+		}
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("%T is not a Date, DateTime or Instant", m)
+	}
+	start, err = toTime(us, tz)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	switch p {
+	case d4pb.DateTime_YEAR:
+		end = start.AddDate(1, 0, 0)
+	case d4pb.DateTime_MONTH:
+		end = start.AddDate(0, 1, 0)
+	case d4pb.DateTime_DAY:
+		end = start.AddDate(0, 0, 1)
+	case d4pb.DateTime_SECOND, d4pb.DateTime_MILLISECOND, d4pb.DateTime_MICROSECOND:
+		end = start.Add(time.Microsecond)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unspecified precision")
+	}
+	return start, end, nil
+}
+
+// CompareTemporal compares the Dates, DateTimes or Instants a and b, which
+// may be of different types, with the partial date semantics of FHIRPath:
+// values are equal if they are the same period, and indeterminate if their
+// periods overlap otherwise, i.e. 2024-03 and 2024-03-15, or the same day in
+// different timezones.
+func CompareTemporal(a, b proto.Message) (Ordering, error) {
+	as, ae, err := Span(a)
+	if err != nil {
+		return Indeterminate, err
+	}
+	bs, be, err := Span(b)
+	if err != nil {
+		return Indeterminate, err
+	}
+	switch {
+	case as.Equal(bs) && ae.Equal(be):
+		return Equal, nil
+	case !ae.After(bs):
+		return Less, nil
+	case !be.After(as):
+		return Greater, nil
+	}
+	return Indeterminate, nil
+}
@@ -0,0 +1,346 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirtypes
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// UCUMSystem is the system of UCUM coded units.
+const UCUMSystem = "http://unitsofmeasure.org"
+
+// unit is a parsed UCUM unit: a factor applied to a product of powers of base
+// units.
+type unit struct {
+	factor *big.Rat
+	dims   map[string]int
+}
+
+func (u unit) mul(v unit, exp int) unit {
+	out := unit{factor: new(big.Rat).Set(u.factor), dims: map[string]int{}}
+	for d, n := range u.dims {
+		out.dims[d] = n
+	}
+	f := ratPow(v.factor, exp)
+	out.factor.Mul(out.factor, f)
+	for d, n := range v.dims {
+		if out.dims[d] += n * exp; out.dims[d] == 0 {
+			delete(out.dims, d)
+		}
+	}
+	return out
+}
+
+// commensurable reports whether u and v measure the same dimension.
+func (u unit) commensurable(v unit) bool {
+	if len(u.dims) != len(v.dims) {
+		return false
+	}
+	for d, n := range u.dims {
+		if v.dims[d] != n {
+			return false
+		}
+	}
+	return true
+}
+
+func ratPow(r *big.Rat, exp int) *big.Rat {
+	out := big.NewRat(1, 1)
+	base := r
+	if exp < 0 {
+		base = new(big.Rat).Inv(r)
+		exp = -exp
+	}
+	for i := 0; i < exp; i++ {
+		out.Mul(out, base)
+	}
+	return out
+}
+
+func rat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("invalid rational " + s)
+	}
+	return r
+}
+
+// atom is a UCUM unit atom, defined by a factor and a unit expression of
+// base units.
+type atom struct {
+	factor string
+	def    string
+	metric bool
+}
+
+// atoms are the UCUM atoms of clinical data. Base units are defined by
+// themselves. Moles are kept as a base unit rather than a number of
+// particles, and special units with an offset, such as Cel, are left out.
+var atoms = map[string]atom{
+	"m":   {"1", "m", true},
+	"s":   {"1", "s", true},
+	"g":   {"1", "g", true},
+	"K":   {"1", "K", true},
+	"C":   {"1", "C", true},
+	"cd":  {"1", "cd", true},
+	"mol": {"1", "mol", true},
+	"rad": {"1", "rad", true},
+	"eq":  {"1", "mol", true},
+
+	"L":      {"1/1000", "m3", true},
+	"l":      {"1/1000", "m3", true},
+	"Hz":     {"1", "s-1", true},
+	"N":      {"1000", "g.m/s2", true},
+	"Pa":     {"1000", "g/m/s2", true},
+	"J":      {"1000", "g.m2/s2", true},
+	"W":      {"1000", "g.m2/s3", true},
+	"A":      {"1", "C/s", true},
+	"V":      {"1000", "g.m2/s2/C", true},
+	"kat":    {"1", "mol/s", true},
+	"U":      {"1/60000000", "mol/s", true},
+	"bar":    {"100000000", "g/m/s2", true},
+	"cal":    {"4184", "g.m2/s2", true},
+	"m[Hg]":  {"133322000", "g/m/s2", true},
+	"m[H2O]": {"9806650", "g/m/s2", true},
+	"[iU]":   {"1", "[iU]", true},
+	"[IU]":   {"1", "[iU]", true},
+
+	"min":     {"60", "s", false},
+	"h":       {"3600", "s", false},
+	"d":       {"86400", "s", false},
+	"wk":      {"604800", "s", false},
+	"mo":      {"2629800", "s", false},
+	"a":       {"31557600", "s", false},
+	"[lb_av]": {"45359237/100000", "g", false},
+	"[oz_av]": {"28349523125/1000000000", "g", false},
+	"[in_i]":  {"254/10000", "m", false},
+	"[ft_i]":  {"3048/10000", "m", false},
+	"[drp]":   {"1/20000000", "m3", false},
+	"%":       {"1/100", "", false},
+	"[ppm]":   {"1/1000000", "", false},
+	"[ppb]":   {"1/1000000000", "", false},
+	"10*":     {"10", "", false},
+	"10^":     {"10", "", false},
+}
+
+// prefixes are the UCUM metric prefixes.
+var prefixes = map[string]string{
+	"Y": "1e24", "Z": "1e21", "E": "1e18", "P": "1e15", "T": "1e12", "G": "1e9", "M": "1e6", "k": "1e3", "h": "1e2", "da": "1e1",
+	"d": "1e-1", "c": "1e-2", "m": "1e-3", "u": "1e-6", "n": "1e-9", "p": "1e-12", "f": "1e-15", "a": "1e-18", "z": "1e-21", "y": "1e-24",
+}
+
+// parseUnit parses the UCUM unit expression s, i.e. "mg/dL" or
+// "10*3/uL".
+func parseUnit(s string) (unit, error) {
+	p := &unitParser{s: s}
+	u, err := p.term()
+	if err == nil && p.i < len(s) {
+		err = fmt.Errorf("unexpected %q", s[p.i:])
+	}
+	if err != nil {
+		return unit{}, fmt.Errorf("invalid UCUM unit %q: %w", s, err)
+	}
+	return u, nil
+}
+
+type unitParser struct {
+	s string
+	i int
+}
+
+func one() unit { return unit{factor: big.NewRat(1, 1), dims: map[string]int{}} }
+
+func (p *unitParser) term() (unit, error) {
+	u := one()
+	exp := 1
+	if p.i < len(p.s) && p.s[p.i] == '/' {
+		p.i++
+		exp = -1
+	}
+	for {
+		c, err := p.component()
+		if err != nil {
+			return unit{}, err
+		}
+		u = u.mul(c, exp)
+		if p.i >= len(p.s) || (p.s[p.i] != '.' && p.s[p.i] != '/') {
+			return u, nil
+		}
+		exp = 1
+		if p.s[p.i] == '/' {
+			exp = -1
+		}
+		p.i++
+	}
+}
+
+func (p *unitParser) component() (unit, error) {
+	if p.i >= len(p.s) {
+		return unit{}, fmt.Errorf("missing unit")
+	}
+	var u unit
+	switch c := p.s[p.i]; {
+	case c == '{':
+		p.annotation()
+		return one(), nil
+	case c == '(':
+		p.i++
+		t, err := p.term()
+		if err != nil {
+			return unit{}, err
+		}
+		if p.i >= len(p.s) || p.s[p.i] != ')' {
+			return unit{}, fmt.Errorf("missing )")
+		}
+		p.i++
+		u = t
+	case c >= '0' && c <= '9' && !strings.HasPrefix(p.s[p.i:], "10*") && !strings.HasPrefix(p.s[p.i:], "10^"):
+		start := p.i
+		for p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+			p.i++
+		}
+		u = one()
+		u.factor.SetString(p.s[start:p.i])
+		p.annotation()
+		return u, nil
+	default:
+		var err error
+		if u, err = p.simpleUnit(); err != nil {
+			return unit{}, err
+		}
+	}
+	start := p.i
+	if p.i < len(p.s) && (p.s[p.i] == '+' || p.s[p.i] == '-') {
+		p.i++
+	}
+	for p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+		p.i++
+	}
+	if p.i > start {
+		exp, err := strconv.Atoi(p.s[start:p.i])
+		if err != nil {
+			return unit{}, fmt.Errorf("invalid exponent %q", p.s[start:p.i])
+		}
+		u = one().mul(u, exp)
+	}
+	p.annotation()
+	return u, nil
+}
+
+// annotation skips a {...} annotation, which does not change the unit.
+func (p *unitParser) annotation() {
+	if p.i < len(p.s) && p.s[p.i] == '{' {
+		if j := strings.IndexByte(p.s[p.i:], '}'); j >= 0 {
+			p.i += j + 1
+		}
+	}
+}
+
+func (p *unitParser) simpleUnit() (unit, error) {
+	start := p.i
+	if strings.HasPrefix(p.s[p.i:], "10*") || strings.HasPrefix(p.s[p.i:], "10^") {
+		p.i += 3
+	} else {
+		for p.i < len(p.s) {
+			c := p.s[p.i]
+			if c == '[' {
+				j := strings.IndexByte(p.s[p.i:], ']')
+				if j < 0 {
+					return unit{}, fmt.Errorf("missing ]")
+				}
+				p.i += j + 1
+				continue
+			}
+			if strings.IndexByte("./(){}+-0123456789", c) >= 0 {
+				break
+			}
+			p.i++
+		}
+	}
+	sym := p.s[start:p.i]
+	if sym == "" {
+		return unit{}, fmt.Errorf("missing unit at %q", p.s[start:])
+	}
+	if a, ok := atoms[sym]; ok {
+		return resolve(a, "1")
+	}
+	for _, n := range []int{2, 1} {
+		if len(sym) <= n {
+			continue
+		}
+		if f, ok := prefixes[sym[:n]]; ok {
+			if a, ok := atoms[sym[n:]]; ok && a.metric {
+				return resolve(a, f)
+			}
+		}
+	}
+	return unit{}, fmt.Errorf("unknown unit %q", sym)
+}
+
+// resolve returns the unit of the atom a with the prefix factor prefix.
+func resolve(a atom, prefix string) (unit, error) {
+	u, err := parseBase(a.def)
+	if err != nil {
+		return unit{}, err
+	}
+	u.factor.Mul(rat(a.factor), rat(prefix))
+	return u, nil
+}
+
+// parseBase parses the definition of an atom as a product of powers of base
+// units.
+func parseBase(def string) (unit, error) {
+	u := one()
+	if def == "" {
+		return u, nil
+	}
+	exp := 1
+	for _, part := range splitUnit(def) {
+		switch part {
+		case ".":
+			exp = 1
+			continue
+		case "/":
+			exp = -1
+			continue
+		}
+		name := strings.TrimRight(part, "-0123456789")
+		n := 1
+		if name != part {
+			var err error
+			if n, err = strconv.Atoi(part[len(name):]); err != nil {
+				return unit{}, err
+			}
+		}
+		u.dims[name] += n * exp
+	}
+	return u, nil
+}
+
+// splitUnit splits a definition into its symbols and operators.
+func splitUnit(def string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(def); i++ {
+		if def[i] == '.' || def[i] == '/' {
+			out = append(out, def[start:i], def[i:i+1])
+			start = i + 1
+		}
+	}
+	return append(out, def[start:])
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geocode places R4 Addresses and Locations on the map. Geocoder is
+// the interface of geocoding providers; Resource geocodes the Addresses of a
+// resource into their geolocation extensions and sets the position of
+// Locations, and Distance measures between the points found.
+package geocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	locpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/location_go_proto"
+)
+
+// GeolocationURL is the URL of the extension holding the latitude and
+// longitude of an Address.
+const GeolocationURL = "http://hl7.org/fhir/StructureDefinition/geolocation"
+
+// ErrNotFound is returned by Geocoders for addresses they cannot place.
+var ErrNotFound = errors.New("address not found")
+
+// earthRadius is the mean radius of the earth in meters.
+const earthRadius = 6371008.8
+
+// Point is a position on the earth in decimal degrees of WGS84, as in
+// Location.position.
+type Point struct {
+	Latitude, Longitude float64
+}
+
+// Valid reports whether p is within the range of latitudes and longitudes.
+func (p Point) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// Distance returns the great-circle distance between a and b in meters, by
+// the haversine formula.
+func Distance(a, b Point) float64 {
+	rad := func(d float64) float64 { return d * math.Pi / 180 }
+	dlat, dlon := rad(b.Latitude-a.Latitude), rad(b.Longitude-a.Longitude)
+	h := math.Pow(math.Sin(dlat/2), 2) + math.Cos(rad(a.Latitude))*math.Cos(rad(b.Latitude))*math.Pow(math.Sin(dlon/2), 2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Geocoder finds the position of addresses, as an adapter of a geocoding
+// service does.
+type Geocoder interface {
+	// Geocode returns the position of a, or an error wrapping ErrNotFound
+	// if it cannot place it.
+	Geocode(ctx context.Context, a *d4pb.Address) (Point, error)
+}
+
+// Geolocation returns the position of the geolocation extension of a and
+// whether it has a valid one.
+func Geolocation(a *d4pb.Address) (Point, bool) {
+	for _, e := range a.GetExtension() {
+		if e.GetUrl().GetValue() != GeolocationURL {
+			continue
+		}
+		var lat, lon string
+		for _, sub := range e.GetExtension() {
+			switch sub.GetUrl().GetValue() {
+			case "latitude":
+				lat = sub.GetValue().GetDecimal().GetValue()
+			case "longitude":
+				lon = sub.GetValue().GetDecimal().GetValue()
+			}
+		}
+		return parsePoint(lat, lon)
+	}
+	return Point{}, false
+}
+
+// SetGeolocation replaces the geolocation extension of a with one at p.
+func SetGeolocation(a *d4pb.Address, p Point) {
+	var exts []*d4pb.Extension
+	for _, e := range a.GetExtension() {
+		if e.GetUrl().GetValue() != GeolocationURL {
+			exts = append(exts, e)
+		}
+	}
+	a.Extension = append(exts, &d4pb.Extension{
+		Url: fhirtypes.URI(GeolocationURL),
+		Extension: []*d4pb.Extension{
+			decimalExtension("latitude", p.Latitude),
+			decimalExtension("longitude", p.Longitude),
+		},
+	})
+}
+
+func decimalExtension(url string, v float64) *d4pb.Extension {
+	return &d4pb.Extension{
+		Url:   fhirtypes.URI(url),
+		Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Decimal{Decimal: decimal(v)}},
+	}
+}
+
+// Position returns the position of loc and whether it has a valid one.
+func Position(loc *locpb.Location) (Point, bool) {
+	pos := loc.GetPosition()
+	return parsePoint(pos.GetLatitude().GetValue(), pos.GetLongitude().GetValue())
+}
+
+// SetPosition sets the latitude and longitude of the position of loc to p,
+// keeping its altitude.
+func SetPosition(loc *locpb.Location, p Point) {
+	if loc.Position == nil {
+		loc.Position = &locpb.Location_Position{}
+	}
+	loc.Position.Latitude = decimal(p.Latitude)
+	loc.Position.Longitude = decimal(p.Longitude)
+}
+
+func decimal(v float64) *d4pb.Decimal {
+	return fhirtypes.Decimal(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+func parsePoint(lat, lon string) (Point, bool) {
+	var p Point
+	var err1, err2 error
+	p.Latitude, err1 = strconv.ParseFloat(lat, 64)
+	p.Longitude, err2 = strconv.ParseFloat(lon, 64)
+	return p, err1 == nil && err2 == nil && p.Valid()
+}
+
+// Resource geocodes the Addresses of res, which may be a ContainedResource,
+// with g, setting the geolocation extension of those without one, and sets
+// the position of a Location without one to that of its address. Addresses
+// g cannot place are left alone; other errors of g are returned.
+func Resource(ctx context.Context, g Geocoder, res proto.Message) error {
+	if err := walk(res.ProtoReflect(), func(a *d4pb.Address) error {
+		if _, ok := Geolocation(a); ok {
+			return nil
+		}
+		p, err := g.Geocode(ctx, a)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			return nil
+		case err != nil:
+			return fmt.Errorf("geocoding address: %w", err)
+		case !p.Valid():
+			return fmt.Errorf("geocoding address: invalid position %v", p)
+		}
+		SetGeolocation(a, p)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if loc, ok := elementpath.Unwrap(res).(*locpb.Location); ok {
+		if _, has := Position(loc); !has {
+			if p, ok := Geolocation(loc.GetAddress()); ok {
+				SetPosition(loc, p)
+			}
+		}
+	}
+	return nil
+}
+
+var addressName = (&d4pb.Address{}).ProtoReflect().Descriptor().FullName()
+
+// walk calls f with the Addresses of m. Contained resources are not
+// visited.
+func walk(m protoreflect.Message, f func(*d4pb.Address) error) error {
+	if m.Descriptor().FullName() == addressName {
+		return f(m.Interface().(*d4pb.Address))
+	}
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil || fd.IsMap() {
+			return true
+		}
+		if fd.IsList() {
+			for i := 0; i < v.List().Len() && err == nil; i++ {
+				err = walk(v.List().Get(i).Message(), f)
+			}
+		} else {
+			err = walk(v.Message(), f)
+		}
+		return err == nil
+	})
+	return err
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	locpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/location_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+// cityGeocoder places addresses by their city.
+type cityGeocoder map[string]Point
+
+func (g cityGeocoder) Geocode(_ context.Context, a *d4pb.Address) (Point, error) {
+	if a.GetCity().GetValue() == "Error" {
+		return Point{}, errors.New("service unavailable")
+	}
+	p, ok := g[a.GetCity().GetValue()]
+	if !ok {
+		return Point{}, fmt.Errorf("%w: %s", ErrNotFound, a.GetCity().GetValue())
+	}
+	return p, nil
+}
+
+var cities = cityGeocoder{
+	"Boston": {42.3601, -71.0589},
+	"London": {51.5074, -0.1278},
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b Point
+		want float64
+	}{
+		{cities["Boston"], cities["Boston"], 0},
+		{cities["Boston"], cities["London"], 5265000},
+		{Point{0, 0}, Point{0, 180}, math.Pi * earthRadius},
+	}
+	for _, tc := range tests {
+		if got := Distance(tc.a, tc.b); math.Abs(got-tc.want) > 5000 {
+			t.Errorf("Distance(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestGeolocation(t *testing.T) {
+	other := &d4pb.Extension{Url: fhirtypes.URI("http://example.org/other")}
+	a := &d4pb.Address{Extension: []*d4pb.Extension{other}}
+	if _, ok := Geolocation(a); ok {
+		t.Errorf("Geolocation() of an address without one succeeded")
+	}
+	SetGeolocation(a, Point{1, 2})
+	SetGeolocation(a, cities["Boston"])
+	want := &d4pb.Address{Extension: []*d4pb.Extension{other, {
+		Url: fhirtypes.URI(GeolocationURL),
+		Extension: []*d4pb.Extension{
+			{Url: fhirtypes.URI("latitude"), Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Decimal{Decimal: fhirtypes.Decimal("42.3601")}}},
+			{Url: fhirtypes.URI("longitude"), Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Decimal{Decimal: fhirtypes.Decimal("-71.0589")}}},
+		},
+	}}}
+	if diff := cmp.Diff(want, a, protocmp.Transform()); diff != "" {
+		t.Errorf("SetGeolocation() diff (-want +got):\n%s", diff)
+	}
+	if got, ok := Geolocation(a); !ok || got != cities["Boston"] {
+		t.Errorf("Geolocation() = %v, %v, want %v", got, ok, cities["Boston"])
+	}
+}
+
+func TestResource(t *testing.T) {
+	ctx := context.Background()
+	p := &ppb.Patient{Address: []*d4pb.Address{
+		{City: fhirtypes.String("Boston")},
+		{City: fhirtypes.String("Atlantis")},
+	}}
+	if err := Resource(ctx, cities, p); err != nil {
+		t.Fatalf("Resource() returned unexpected error: %v", err)
+	}
+	if got, ok := Geolocation(p.GetAddress()[0]); !ok || got != cities["Boston"] {
+		t.Errorf("Resource() geolocation = %v, %v, want %v", got, ok, cities["Boston"])
+	}
+	if _, ok := Geolocation(p.GetAddress()[1]); ok {
+		t.Errorf("Resource() geolocated an address the geocoder cannot place")
+	}
+
+	loc := &locpb.Location{Address: &d4pb.Address{City: fhirtypes.String("London")}}
+	cr := &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Location{Location: loc}}
+	if err := Resource(ctx, cities, cr); err != nil {
+		t.Fatalf("Resource() returned unexpected error: %v", err)
+	}
+	if got, ok := Position(loc); !ok || got != cities["London"] {
+		t.Errorf("Resource() position = %v, %v, want %v", got, ok, cities["London"])
+	}
+
+	if err := Resource(ctx, cities, &ppb.Patient{Address: []*d4pb.Address{{City: fhirtypes.String("Error")}}}); err == nil {
+		t.Errorf("Resource() with a failing geocoder succeeded, want error")
+	}
+}
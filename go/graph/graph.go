@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graph extracts the graph of resources described by an R4
+// GraphDefinition, starting from a given resource.
+//
+// Links with a path are followed by evaluating the path as a FHIRPath
+// expression on the source resource and resolving the references it yields.
+// Links without a path are reverse links: their targets are found by
+// searching with the target's params, in which "{ref}" stands for the
+// reference to the source resource. Profile and compartment rules of the
+// GraphDefinition are not checked.
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	gdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/graph_definition_go_proto"
+)
+
+// Options configures Traverse.
+type Options struct {
+	// Resolver resolves the references found by links with a path. A nil
+	// result leaves the reference unfollowed.
+	Resolver fhirpath.Resolver
+	// Search returns the resources of type resourceType matching params, the
+	// query string of a search, for reverse links.
+	Search func(resourceType, params string) ([]proto.Message, error)
+	// BaseURL, if set, is used to give the entries of the Bundle a fullUrl
+	// of the form BaseURL/Type/id.
+	BaseURL string
+}
+
+// Traverse follows gd from start and returns the resources reached, start
+// first, as a collection Bundle. Every resource appears once, even if it is
+// reached by several links.
+func Traverse(gd *gdpb.GraphDefinition, start proto.Message, opts Options) (*r4pb.Bundle, error) {
+	start = elementpath.Unwrap(start)
+	if want := code(gd.GetStart().GetValue()); want != elementpath.ResourceType(start) {
+		return nil, fmt.Errorf("GraphDefinition starts at %s, not %s", want, elementpath.ResourceType(start))
+	}
+	t := &traversal{opts: opts, seen: map[string]bool{}, followed: map[followKey]bool{}}
+	if err := t.visit(start, gd.GetLink()); err != nil {
+		return nil, err
+	}
+	b := &r4pb.Bundle{Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_COLLECTION}}
+	for _, res := range t.resources {
+		cr, err := elementpath.Convert(res, (&r4pb.ContainedResource{}).ProtoReflect().Descriptor())
+		if err != nil {
+			return nil, err
+		}
+		entry := &r4pb.Bundle_Entry{Resource: cr.(*r4pb.ContainedResource)}
+		if key := resourceKey(res); opts.BaseURL != "" && key != "" {
+			entry.FullUrl = &d4pb.Uri{Value: strings.TrimSuffix(opts.BaseURL, "/") + "/" + key}
+		}
+		b.Entry = append(b.Entry, entry)
+	}
+	return b, nil
+}
+
+type traversal struct {
+	opts      Options
+	resources []proto.Message
+	seen      map[string]bool
+	// followed records the links already followed from a resource, so that
+	// cyclic graphs terminate.
+	followed map[followKey]bool
+}
+
+type followKey struct {
+	resource string
+	link     *gdpb.GraphDefinition_Link
+}
+
+// visit adds res to the graph and follows links from it.
+func (t *traversal) visit(res proto.Message, links []*gdpb.GraphDefinition_Link) error {
+	key := resourceKey(res)
+	if key == "" {
+		key = fmt.Sprintf("%p", res)
+	}
+	if !t.seen[key] {
+		t.seen[key] = true
+		t.resources = append(t.resources, res)
+	}
+	for _, l := range links {
+		fk := followKey{key, l}
+		if t.followed[fk] {
+			continue
+		}
+		t.followed[fk] = true
+		if err := t.follow(res, key, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *traversal) follow(res proto.Message, key string, l *gdpb.GraphDefinition_Link) error {
+	name := l.GetPath().GetValue()
+	if name == "" {
+		name = l.GetSliceName().GetValue()
+	}
+	type hit struct {
+		res    proto.Message
+		target *gdpb.GraphDefinition_Link_Target
+	}
+	var hits []hit
+	if path := l.GetPath().GetValue(); path != "" {
+		if t.opts.Resolver == nil {
+			return fmt.Errorf("link %s: no resolver", name)
+		}
+		expr, err := fhirpath.Compile("(" + path + ").resolve()")
+		if err != nil {
+			return fmt.Errorf("link %s: %w", name, err)
+		}
+		found, err := expr.Evaluate(fhirpath.Collection{res}, fhirpath.WithResolver(t.opts.Resolver))
+		if err != nil {
+			return fmt.Errorf("link %s: %w", name, err)
+		}
+		for _, item := range found {
+			m, ok := item.(proto.Message)
+			if !ok {
+				continue
+			}
+			m = elementpath.Unwrap(m)
+			if target, ok := matchTarget(l.GetTarget(), m); ok {
+				hits = append(hits, hit{m, target})
+			}
+		}
+	} else {
+		if t.opts.Search == nil {
+			return fmt.Errorf("reverse link %s: no search function", name)
+		}
+		for _, target := range l.GetTarget() {
+			params := target.GetParams().GetValue()
+			if params == "" {
+				return fmt.Errorf("reverse link %s: target %s has no params", name, code(target.GetType().GetValue()))
+			}
+			found, err := t.opts.Search(code(target.GetType().GetValue()), strings.ReplaceAll(params, "{ref}", key))
+			if err != nil {
+				return fmt.Errorf("reverse link %s: %w", name, err)
+			}
+			for _, m := range found {
+				hits = append(hits, hit{elementpath.Unwrap(m), target})
+			}
+		}
+	}
+	if min := int(l.GetMin().GetValue()); len(hits) < min {
+		return fmt.Errorf("link %s from %s: found %d resources, want at least %d", name, key, len(hits), min)
+	}
+	if max := l.GetMax().GetValue(); max != "" && max != "*" {
+		n, err := strconv.Atoi(max)
+		if err != nil {
+			return fmt.Errorf("link %s: invalid max %q", name, max)
+		}
+		if len(hits) > n {
+			return fmt.Errorf("link %s from %s: found %d resources, want at most %d", name, key, len(hits), n)
+		}
+	}
+	for _, h := range hits {
+		if err := t.visit(h.res, h.target.GetLink()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchTarget returns the target of a link with the type of res. A link
+// without targets accepts every resource.
+func matchTarget(targets []*gdpb.GraphDefinition_Link_Target, res proto.Message) (*gdpb.GraphDefinition_Link_Target, bool) {
+	if len(targets) == 0 {
+		return nil, true
+	}
+	typ := elementpath.ResourceType(res)
+	for _, target := range targets {
+		if code(target.GetType().GetValue()) == typ {
+			return target, true
+		}
+	}
+	return nil, false
+}
+
+// code returns the resource type named by a ResourceTypeCode value.
+func code(v c4pb.ResourceTypeCode_Value) string {
+	return fhirpath.CodeString(v.Descriptor().Values().ByNumber(v.Number()))
+}
+
+// resourceKey returns the relative reference "Type/id" of res, or an empty
+// string if it has no id.
+func resourceKey(res proto.Message) string {
+	id := elementpath.ID(res)
+	if id == "" {
+		return ""
+	}
+	return elementpath.ResourceType(res) + "/" + id
+}
+
+// BundleResolver returns a Resolver for the resources of b, which are
+// identified by their fullUrl or their relative reference "Type/id".
+func BundleResolver(b *r4pb.Bundle) fhirpath.Resolver {
+	index := map[string]proto.Message{}
+	for _, e := range b.GetEntry() {
+		res := elementpath.Unwrap(e.GetResource())
+		if res == nil {
+			continue
+		}
+		if u := e.GetFullUrl().GetValue(); u != "" {
+			index[u] = res
+		}
+		if key := resourceKey(res); key != "" {
+			index[key] = res
+		}
+	}
+	return func(ref string) (proto.Message, error) {
+		return index[ref], nil
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	gdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/graph_definition_go_proto"
+	opb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/organization_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func orgRef(id string) *d4pb.Reference {
+	return &d4pb.Reference{Reference: &d4pb.Reference_OrganizationId{OrganizationId: &d4pb.ReferenceId{Value: id}}}
+}
+
+// testBundle holds a patient managed by org1, which is part of org2, which in
+// turn is part of org1, and two conditions of the patient.
+func testBundle() *r4pb.Bundle {
+	patient := &ppb.Patient{
+		Id:                   &d4pb.Id{Value: "p1"},
+		ManagingOrganization: orgRef("org1"),
+	}
+	org := func(id, parent string) *opb.Organization {
+		return &opb.Organization{Id: &d4pb.Id{Value: id}, PartOf: orgRef(parent)}
+	}
+	condition := func(id string) *cpb.Condition {
+		return &cpb.Condition{
+			Id:      &d4pb.Id{Value: id},
+			Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		}
+	}
+	return &r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{
+		{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: patient}}},
+		{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Organization{Organization: org("org1", "org2")}}},
+		{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Organization{Organization: org("org2", "org1")}}},
+		{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Condition{Condition: condition("c1")}}},
+		{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Condition{Condition: condition("c2")}}},
+	}}
+}
+
+func search(b *r4pb.Bundle) func(string, string) ([]proto.Message, error) {
+	return func(typ, params string) ([]proto.Message, error) {
+		if typ != "Condition" || params != "patient=Patient/p1" {
+			return nil, fmt.Errorf("unexpected search %s?%s", typ, params)
+		}
+		var out []proto.Message
+		for _, e := range b.GetEntry() {
+			if c := e.GetResource().GetCondition(); c != nil {
+				out = append(out, c)
+			}
+		}
+		return out, nil
+	}
+}
+
+func link(path string, max string, targets ...*gdpb.GraphDefinition_Link_Target) *gdpb.GraphDefinition_Link {
+	l := &gdpb.GraphDefinition_Link{Target: targets}
+	if path != "" {
+		l.Path = &d4pb.String{Value: path}
+	}
+	if max != "" {
+		l.Max = &d4pb.String{Value: max}
+	}
+	return l
+}
+
+func target(typ c4pb.ResourceTypeCode_Value, params string, links ...*gdpb.GraphDefinition_Link) *gdpb.GraphDefinition_Link_Target {
+	t := &gdpb.GraphDefinition_Link_Target{Type: &gdpb.GraphDefinition_Link_Target_TypeCode{Value: typ}, Link: links}
+	if params != "" {
+		t.Params = &d4pb.String{Value: params}
+	}
+	return t
+}
+
+func graphDefinition(links ...*gdpb.GraphDefinition_Link) *gdpb.GraphDefinition {
+	return &gdpb.GraphDefinition{
+		Start: &gdpb.GraphDefinition_StartCode{Value: c4pb.ResourceTypeCode_PATIENT},
+		Link:  links,
+	}
+}
+
+func entries(b *r4pb.Bundle) []string {
+	var out []string
+	for _, e := range b.GetEntry() {
+		out = append(out, e.GetFullUrl().GetValue())
+	}
+	return out
+}
+
+func TestTraverse(t *testing.T) {
+	partOf := link("partOf", "1", target(c4pb.ResourceTypeCode_ORGANIZATION, ""))
+	partOf.Target[0].Link = []*gdpb.GraphDefinition_Link{partOf}
+	tests := []struct {
+		name string
+		gd   *gdpb.GraphDefinition
+		want []string
+	}{
+		{
+			name: "start only",
+			gd:   graphDefinition(),
+			want: []string{"http://example.com/fhir/Patient/p1"},
+		},
+		{
+			name: "cyclic links",
+			gd:   graphDefinition(link("managingOrganization", "1", target(c4pb.ResourceTypeCode_ORGANIZATION, "", partOf))),
+			want: []string{
+				"http://example.com/fhir/Patient/p1",
+				"http://example.com/fhir/Organization/org1",
+				"http://example.com/fhir/Organization/org2",
+			},
+		},
+		{
+			name: "reverse link",
+			gd:   graphDefinition(link("", "*", target(c4pb.ResourceTypeCode_CONDITION, "patient={ref}"))),
+			want: []string{
+				"http://example.com/fhir/Patient/p1",
+				"http://example.com/fhir/Condition/c1",
+				"http://example.com/fhir/Condition/c2",
+			},
+		},
+		{
+			name: "other target type",
+			gd:   graphDefinition(link("managingOrganization", "", target(c4pb.ResourceTypeCode_PRACTITIONER, ""))),
+			want: []string{"http://example.com/fhir/Patient/p1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := testBundle()
+			opts := Options{Resolver: BundleResolver(b), Search: search(b), BaseURL: "http://example.com/fhir/"}
+			got, err := Traverse(test.gd, b.GetEntry()[0].GetResource(), opts)
+			if err != nil {
+				t.Fatalf("Traverse() returned unexpected error: %v", err)
+			}
+			if got.GetType().GetValue() != c4pb.BundleTypeCode_COLLECTION {
+				t.Errorf("Traverse() returned a %v Bundle, want a collection", got.GetType().GetValue())
+			}
+			if diff := cmp.Diff(test.want, entries(got)); diff != "" {
+				t.Errorf("Traverse() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTraverse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		gd   *gdpb.GraphDefinition
+		want string
+	}{
+		{
+			name: "wrong start",
+			gd:   &gdpb.GraphDefinition{Start: &gdpb.GraphDefinition_StartCode{Value: c4pb.ResourceTypeCode_ENCOUNTER}},
+			want: "starts at Encounter",
+		},
+		{
+			name: "too many",
+			gd:   graphDefinition(link("", "1", target(c4pb.ResourceTypeCode_CONDITION, "patient={ref}"))),
+			want: "at most 1",
+		},
+		{
+			name: "too few",
+			gd: func() *gdpb.GraphDefinition {
+				l := link("generalPractitioner", "", target(c4pb.ResourceTypeCode_PRACTITIONER, ""))
+				l.Min = &d4pb.Integer{Value: 1}
+				return graphDefinition(l)
+			}(),
+			want: "at least 1",
+		},
+		{
+			name: "reverse link without params",
+			gd:   graphDefinition(link("", "*", target(c4pb.ResourceTypeCode_CONDITION, ""))),
+			want: "no params",
+		},
+		{
+			name: "invalid path",
+			gd:   graphDefinition(link("managingOrganization(", "", target(c4pb.ResourceTypeCode_ORGANIZATION, ""))),
+			want: "link managingOrganization(",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := testBundle()
+			_, err := Traverse(test.gd, b.GetEntry()[0].GetResource(), Options{Resolver: BundleResolver(b), Search: search(b)})
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Traverse() returned error %v, want one containing %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package healthcards issues and verifies SMART Health Cards, FHIR Bundles
+// signed by their issuer as compressed JWS, and SMART Health Links, which
+// share encrypted content through a URL.
+//
+// Cards are minified as the specification requires before they are
+// signed with ES256, and can be rendered as the numeric content of one or
+// more QR codes. Verification resolves the key of the issuer from its JWK
+// Set and returns the Bundle of the card.
+package healthcards
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jose"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// Credential types.
+const (
+	HealthCard   = "https://smarthealth.cards#health-card"
+	Immunization = "https://smarthealth.cards#immunization"
+	Laboratory   = "https://smarthealth.cards#laboratory"
+)
+
+// fhirVersion is the FHIR version of the Bundles of cards.
+const fhirVersion = "4.0.1"
+
+// maxPayload bounds the decompressed size of a card, against payloads
+// that inflate without limit.
+const maxPayload = 10 << 20
+
+// clockSkew is how far ahead of the verifier's clock the issuer's may be:
+// a card is accepted this long before its nbf.
+const clockSkew = 5 * time.Minute
+
+var (
+	marshaller   *jsonformat.Marshaller
+	unmarshaller *jsonformat.Unmarshaller
+)
+
+func init() {
+	var err error
+	if marshaller, err = jsonformat.NewMarshaller(false, "", "", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("healthcards: creating marshaller: %v", err))
+	}
+	if unmarshaller, err = jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("healthcards: creating unmarshaller: %v", err))
+	}
+}
+
+// claims are the JWT claims of a card.
+type claims struct {
+	Issuer    string     `json:"iss"`
+	NotBefore int64      `json:"nbf"`
+	Expires   int64      `json:"exp,omitempty"`
+	VC        credential `json:"vc"`
+}
+
+type credential struct {
+	Type              []string `json:"type"`
+	CredentialSubject struct {
+		FHIRVersion string          `json:"fhirVersion"`
+		FHIRBundle  json.RawMessage `json:"fhirBundle"`
+	} `json:"credentialSubject"`
+}
+
+// Issuer issues cards.
+type Issuer struct {
+	// URL is the issuer URL, which verifiers fetch its JWK Set from. It has
+	// no trailing slash.
+	URL string
+	// Key is the signing key, on P-256.
+	Key *ecdsa.PrivateKey
+	// Now returns the time cards are issued at. time.Now is used if it is
+	// nil.
+	Now func() time.Time
+}
+
+// KeySet returns the JWK Set of i, to be served at
+// URL/.well-known/jwks.json.
+func (i *Issuer) KeySet() (*jose.KeySet, error) {
+	k, err := i.jwk()
+	if err != nil {
+		return nil, err
+	}
+	return &jose.KeySet{Keys: []jose.JWK{k}}, nil
+}
+
+func (i *Issuer) jwk() (jose.JWK, error) {
+	if i.Key == nil || i.Key.Curve != elliptic.P256() {
+		return jose.JWK{}, fmt.Errorf("issuer key must be on P-256")
+	}
+	return jose.NewJWK(&i.Key.PublicKey)
+}
+
+// Issue returns the JWS of a card holding b, of the given types in addition
+// to HealthCard. b is minified for the card and left unchanged.
+func (i *Issuer) Issue(b *r4pb.Bundle, types ...string) (string, error) {
+	if i.URL == "" || strings.HasSuffix(i.URL, "/") {
+		return "", fmt.Errorf("invalid issuer URL %q", i.URL)
+	}
+	k, err := i.jwk()
+	if err != nil {
+		return "", err
+	}
+	minified, err := Minify(b)
+	if err != nil {
+		return "", err
+	}
+	bundle, err := marshaller.MarshalResource(minified)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now
+	if i.Now != nil {
+		now = i.Now
+	}
+	c := claims{Issuer: i.URL, NotBefore: now().Unix()}
+	c.VC.Type = append([]string{HealthCard}, types...)
+	c.VC.CredentialSubject.FHIRVersion = fhirVersion
+	c.VC.CredentialSubject.FHIRBundle = bundle
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return jose.Sign(jose.Header{Kid: k.Kid, Zip: "DEF"}, buf.Bytes(), i.Key)
+}
+
+// Minify returns a copy of b minified for a card: entries are identified by
+// fullUrls of the form resource:N, which references to them are rewritten
+// to, and resource ids, narratives, metadata other than security labels,
+// Coding.display and CodeableConcept.text are removed.
+func Minify(b *r4pb.Bundle) (*r4pb.Bundle, error) {
+	b = proto.Clone(b).(*r4pb.Bundle)
+	refs := map[string]string{}
+	for n, e := range b.GetEntry() {
+		short := fmt.Sprintf("resource:%d", n)
+		if u := e.GetFullUrl().GetValue(); u != "" {
+			refs[u] = short
+		}
+		if id := elementpath.ID(e.GetResource()); id != "" {
+			refs[elementpath.ResourceType(e.GetResource())+"/"+id] = short
+		}
+		e.FullUrl = &d4pb.Uri{Value: short}
+	}
+	for _, e := range b.GetEntry() {
+		e.Request, e.Response, e.Search = nil, nil, nil
+		if res := elementpath.Unwrap(e.GetResource()); res != nil {
+			if err := minify(res.ProtoReflect(), refs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// minify minifies m and its elements in place.
+func minify(m protoreflect.Message, refs map[string]string) error {
+	switch v := m.Interface().(type) {
+	case *d4pb.Reference:
+		return minifyReference(v, refs)
+	case *d4pb.CodeableConcept:
+		v.Text = nil
+	case *d4pb.Coding:
+		v.Display = nil
+	}
+	if elementpath.IsResource(m.Descriptor()) {
+		fields := m.Descriptor().Fields()
+		for _, name := range []protoreflect.Name{"id", "text"} {
+			if fd := fields.ByName(name); fd != nil {
+				m.Clear(fd)
+			}
+		}
+		if fd := fields.ByName("meta"); fd != nil && m.Has(fd) {
+			meta := m.Get(fd).Message().Interface().(*d4pb.Meta)
+			if len(meta.GetSecurity()) == 0 {
+				m.Clear(fd)
+			} else {
+				m.Set(fd, protoreflect.ValueOfMessage((&d4pb.Meta{Security: meta.GetSecurity()}).ProtoReflect()))
+			}
+		}
+	}
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil || fd.IsMap() {
+			return true
+		}
+		if fd.IsList() {
+			for i := 0; i < v.List().Len() && err == nil; i++ {
+				err = minify(v.List().Get(i).Message(), refs)
+			}
+		} else {
+			err = minify(v.Message(), refs)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// minifyReference rewrites ref to the entry it points to, if any, and
+// removes its display.
+func minifyReference(ref *d4pb.Reference, refs map[string]string) error {
+	ref.Display = nil
+	if ref.GetFragment() != nil {
+		return nil
+	}
+	den, err := jsonformat.NewDenormalizedReference(ref)
+	if err != nil {
+		return err
+	}
+	short, ok := refs[den.(*d4pb.Reference).GetUri().GetValue()]
+	if !ok {
+		return nil
+	}
+	ref.Reference = &d4pb.Reference_Uri{Uri: &d4pb.String{Value: short}}
+	ref.Type = nil
+	return nil
+}
+
+// Card is a verified card.
+type Card struct {
+	Issuer   string
+	IssuedAt time.Time
+	// Expires is the expiration of the card, or the zero time if it does
+	// not expire.
+	Expires time.Time
+	Types   []string
+	Bundle  *r4pb.Bundle
+}
+
+// KeySource returns the JWK Set of an issuer, given by its URL.
+type KeySource func(ctx context.Context, issuer string) (*jose.KeySet, error)
+
+// IssuerKeys returns a KeySource fetching the JWK Sets of trusted issuers
+// from their URL/.well-known/jwks.json with client, or http.DefaultClient
+// if it is nil. The keys of other issuers are not resolved.
+func IssuerKeys(client *http.Client, trusted ...string) KeySource {
+	return func(ctx context.Context, issuer string) (*jose.KeySet, error) {
+		for _, t := range trusted {
+			if t == issuer {
+				return jose.FetchKeySet(ctx, client, issuer+"/.well-known/jwks.json")
+			}
+		}
+		return nil, fmt.Errorf("untrusted issuer %s", issuer)
+	}
+}
+
+// Verify verifies the JWS of a card with the key of its issuer and returns
+// the card. now is the time the card is checked for validity at: cards
+// that are expired, or not valid until later, are rejected.
+func Verify(ctx context.Context, jws string, keys KeySource, now time.Time) (*Card, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed JWS: want 3 parts")
+	}
+	// The issuer, and with it the key, is known only from the payload,
+	// which is read before it is verified.
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWS payload: %w", err)
+	}
+	c, err := parseClaims(raw)
+	if err != nil {
+		return nil, err
+	}
+	set, err := keys(ctx, c.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	h, _, err := jose.Verify(jws, nil, func(h jose.Header) (crypto.PublicKey, error) {
+		if h.Alg != jose.ES256 {
+			return nil, fmt.Errorf("unsupported algorithm %s", h.Alg)
+		}
+		k, ok := set.Key(h.Kid)
+		if !ok {
+			return nil, fmt.Errorf("issuer %s has no key %q", c.Issuer, h.Kid)
+		}
+		return k.PublicKey()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verifying card: %w", err)
+	}
+	if h.Zip != "DEF" {
+		return nil, fmt.Errorf("card payload is not compressed")
+	}
+	card := &Card{Issuer: c.Issuer, IssuedAt: time.Unix(c.NotBefore, 0), Types: c.VC.Type}
+	if now.Add(clockSkew).Before(card.IssuedAt) {
+		return nil, fmt.Errorf("card is not valid before %v", card.IssuedAt)
+	}
+	if c.Expires != 0 {
+		card.Expires = time.Unix(c.Expires, 0)
+		if !now.Before(card.Expires) {
+			return nil, fmt.Errorf("card expired at %v", card.Expires)
+		}
+	}
+	cr, err := unmarshaller.Unmarshal(c.VC.CredentialSubject.FHIRBundle)
+	if err != nil {
+		return nil, fmt.Errorf("parsing card Bundle: %w", err)
+	}
+	b, ok := elementpath.Unwrap(cr).(*r4pb.Bundle)
+	if !ok {
+		return nil, fmt.Errorf("card holds a %s, want a Bundle", elementpath.ResourceType(cr))
+	}
+	card.Bundle = b
+	return card, nil
+}
+
+// parseClaims decompresses and parses the payload of a card.
+func parseClaims(deflated []byte) (*claims, error) {
+	r := flate.NewReader(bytes.NewReader(deflated))
+	defer r.Close()
+	payload, err := io.ReadAll(io.LimitReader(r, maxPayload+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing card: %w", err)
+	}
+	if len(payload) > maxPayload {
+		return nil, fmt.Errorf("card payload exceeds %d bytes", maxPayload)
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("parsing card: %w", err)
+	}
+	if c.Issuer == "" {
+		return nil, fmt.Errorf("card has no issuer")
+	}
+	if len(c.VC.CredentialSubject.FHIRBundle) == 0 {
+		return nil, fmt.Errorf("card has no Bundle")
+	}
+	return &c, nil
+}
+
+// file is the content of a .smart-health-card file.
+type file struct {
+	VerifiableCredential []string `json:"verifiableCredential"`
+}
+
+// MarshalFile returns the content of a .smart-health-card file, of media
+// type application/smart-health-card, holding the JWS of cards.
+func MarshalFile(jws ...string) ([]byte, error) {
+	return json.Marshal(file{VerifiableCredential: jws})
+}
+
+// ParseFile returns the JWS of the cards of a .smart-health-card file.
+func ParseFile(data []byte) ([]string, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing health card file: %w", err)
+	}
+	return f.VerifiableCredential, nil
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcards
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/jose"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/immunization_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+var now = time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func bundle() *r4pb.Bundle {
+	return &r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{
+		{
+			FullUrl: &d4pb.Uri{Value: "http://example.com/fhir/Patient/p1"},
+			Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{
+				Id:   &d4pb.Id{Value: "p1"},
+				Meta: &d4pb.Meta{VersionId: &d4pb.Id{Value: "3"}},
+				Text: &d4pb.Narrative{Div: &d4pb.Xhtml{Value: "<div>Jane Doe</div>"}},
+				Name: []*d4pb.HumanName{{Family: &d4pb.String{Value: "Doe"}, Given: []*d4pb.String{{Value: "Jane"}}}},
+				BirthDate: &d4pb.Date{
+					ValueUs: time.Date(1980, 1, 2, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "UTC", Precision: d4pb.Date_DAY,
+				},
+			}}},
+		},
+		{
+			FullUrl: &d4pb.Uri{Value: "http://example.com/fhir/Immunization/i1"},
+			Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Immunization{Immunization: &ipb.Immunization{
+				Id: &d4pb.Id{Value: "i1"},
+				VaccineCode: &d4pb.CodeableConcept{
+					Coding: []*d4pb.Coding{{
+						System:  &d4pb.Uri{Value: "http://hl7.org/fhir/sid/cvx"},
+						Code:    &d4pb.Code{Value: "207"},
+						Display: &d4pb.String{Value: "COVID-19, mRNA"},
+					}},
+					Text: &d4pb.String{Value: "COVID-19 vaccine"},
+				},
+				Patient: &d4pb.Reference{
+					Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}},
+					Display:   &d4pb.String{Value: "Jane Doe"},
+				},
+				Occurrence: &ipb.Immunization_OccurrenceX{Choice: &ipb.Immunization_OccurrenceX_DateTime{DateTime: &d4pb.DateTime{
+					ValueUs: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "UTC", Precision: d4pb.DateTime_DAY,
+				}}},
+			}}},
+		},
+	}}
+}
+
+func issuer(t *testing.T) *Issuer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() returned unexpected error: %v", err)
+	}
+	return &Issuer{Key: key, Now: func() time.Time { return now }}
+}
+
+// serve sets the URL of i to a server serving its JWK Set.
+func serve(t *testing.T, i *Issuer) {
+	t.Helper()
+	set, err := i.KeySet()
+	if err != nil {
+		t.Fatalf("KeySet() returned unexpected error: %v", err)
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/jwks.json" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.Close)
+	i.URL = s.URL
+}
+
+func TestMinify(t *testing.T) {
+	got, err := Minify(bundle())
+	if err != nil {
+		t.Fatalf("Minify() returned unexpected error: %v", err)
+	}
+	want := &r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{
+		{
+			FullUrl: &d4pb.Uri{Value: "resource:0"},
+			Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{
+				Name:      bundle().GetEntry()[0].GetResource().GetPatient().GetName(),
+				BirthDate: bundle().GetEntry()[0].GetResource().GetPatient().GetBirthDate(),
+			}}},
+		},
+		{
+			FullUrl: &d4pb.Uri{Value: "resource:1"},
+			Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Immunization{Immunization: &ipb.Immunization{
+				VaccineCode: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+					System: &d4pb.Uri{Value: "http://hl7.org/fhir/sid/cvx"},
+					Code:   &d4pb.Code{Value: "207"},
+				}}},
+				Patient:    &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: "resource:0"}}},
+				Occurrence: bundle().GetEntry()[1].GetResource().GetImmunization().GetOccurrence(),
+			}}},
+		},
+	}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Minify() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestIssueVerify(t *testing.T) {
+	i := issuer(t)
+	serve(t, i)
+	jws, err := i.Issue(bundle(), Immunization)
+	if err != nil {
+		t.Fatalf("Issue() returned unexpected error: %v", err)
+	}
+	chunks, err := QRChunks(jws)
+	if err != nil {
+		t.Fatalf("QRChunks() returned unexpected error: %v", err)
+	}
+	if len(chunks) != 1 || !strings.HasPrefix(chunks[0], "shc:/") {
+		t.Fatalf("QRChunks() = %v, want one shc:/ chunk", chunks)
+	}
+	scanned, err := ParseQR(chunks...)
+	if err != nil {
+		t.Fatalf("ParseQR() returned unexpected error: %v", err)
+	}
+	if scanned != jws {
+		t.Fatalf("ParseQR() = %q, want %q", scanned, jws)
+	}
+
+	card, err := Verify(context.Background(), scanned, IssuerKeys(nil, i.URL), now)
+	if err != nil {
+		t.Fatalf("Verify() returned unexpected error: %v", err)
+	}
+	if card.Issuer != i.URL || !card.IssuedAt.Equal(now) {
+		t.Errorf("Verify() returned issuer %s at %v, want %s at %v", card.Issuer, card.IssuedAt, i.URL, now)
+	}
+	if diff := cmp.Diff([]string{HealthCard, Immunization}, card.Types); diff != "" {
+		t.Errorf("Verify() types diff (-want +got):\n%s", diff)
+	}
+	want, err := Minify(bundle())
+	if err != nil {
+		t.Fatalf("Minify() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, card.Bundle, protocmp.Transform()); diff != "" {
+		t.Errorf("Verify() Bundle diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestVerify_Errors(t *testing.T) {
+	i := issuer(t)
+	serve(t, i)
+	jws, err := i.Issue(bundle())
+	if err != nil {
+		t.Fatalf("Issue() returned unexpected error: %v", err)
+	}
+	impostor := issuer(t)
+	impostor.URL = i.URL
+	forged, err := impostor.Issue(bundle(), Laboratory)
+	if err != nil {
+		t.Fatalf("Issue() returned unexpected error: %v", err)
+	}
+	parts := strings.Split(jws, ".")
+	otherParts := strings.Split(forged, ".")
+
+	tests := []struct {
+		name string
+		jws  string
+		keys KeySource
+	}{
+		{"untrusted issuer", jws, IssuerKeys(nil, "https://other.example.com")},
+		{"unknown key", forged, IssuerKeys(nil, i.URL)},
+		{"swapped payload", parts[0] + "." + otherParts[1] + "." + parts[2], IssuerKeys(nil, i.URL)},
+		{"malformed", "not a card", IssuerKeys(nil, i.URL)},
+		{"key set without the key", jws, func(context.Context, string) (*jose.KeySet, error) { return &jose.KeySet{}, nil }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Verify(context.Background(), test.jws, test.keys, now); err == nil {
+				t.Errorf("Verify() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestVerify_NotBefore(t *testing.T) {
+	i := issuer(t)
+	serve(t, i)
+	jws, err := i.Issue(bundle())
+	if err != nil {
+		t.Fatalf("Issue() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"at nbf", now, true},
+		{"within clock skew", now.Add(-time.Minute), true},
+		{"before nbf", now.Add(-time.Hour), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Verify(context.Background(), jws, IssuerKeys(nil, i.URL), test.at)
+			if test.ok && err != nil {
+				t.Errorf("Verify() returned unexpected error: %v", err)
+			}
+			if !test.ok && err == nil {
+				t.Errorf("Verify() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestQRChunks_Split(t *testing.T) {
+	jws := strings.Repeat("abc.DEF-_", 300)
+	chunks, err := QRChunks(jws)
+	if err != nil {
+		t.Fatalf("QRChunks() returned unexpected error: %v", err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("QRChunks() returned %d chunks, want 3", len(chunks))
+	}
+	for n, c := range chunks {
+		if want := "shc:/" + string(rune('1'+n)) + "/3/"; !strings.HasPrefix(c, want) {
+			t.Errorf("chunk %d = %.20s..., want prefix %s", n, c, want)
+		}
+	}
+	got, err := ParseQR(chunks[2], chunks[0], strings.ToUpper(chunks[1][:5])+chunks[1][5:])
+	if err != nil {
+		t.Fatalf("ParseQR() returned unexpected error: %v", err)
+	}
+	if got != jws {
+		t.Errorf("ParseQR() did not restore the JWS")
+	}
+	if _, err := ParseQR(chunks[0], chunks[1]); err == nil {
+		t.Errorf("ParseQR() of an incomplete set succeeded, want error")
+	}
+}
+
+func TestLink(t *testing.T) {
+	l, err := NewLink("https://shl.example.com/manifest/abc")
+	if err != nil {
+		t.Fatalf("NewLink() returned unexpected error: %v", err)
+	}
+	l.Label = "Vaccination record"
+	l.Exp = now.Add(time.Hour).Unix()
+	parsed, err := ParseLink("https://viewer.example.com/#" + l.String())
+	if err != nil {
+		t.Fatalf("ParseLink() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(l, parsed); diff != "" {
+		t.Errorf("ParseLink() diff (-want +got):\n%s", diff)
+	}
+	if parsed.Expired(now) || !parsed.Expired(now.Add(2*time.Hour)) {
+		t.Errorf("Expired() does not honor exp %d", parsed.Exp)
+	}
+
+	content, err := MarshalFile("a.b.c")
+	if err != nil {
+		t.Fatalf("MarshalFile() returned unexpected error: %v", err)
+	}
+	jwe, err := l.Encrypt(content, HealthCardType)
+	if err != nil {
+		t.Fatalf("Encrypt() returned unexpected error: %v", err)
+	}
+	got, typ, err := parsed.Decrypt(jwe)
+	if err != nil {
+		t.Fatalf("Decrypt() returned unexpected error: %v", err)
+	}
+	if typ != HealthCardType || string(got) != string(content) {
+		t.Errorf("Decrypt() = %s, %s, want %s, %s", got, typ, content, HealthCardType)
+	}
+	cards, err := ParseFile(got)
+	if err != nil {
+		t.Fatalf("ParseFile() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a.b.c"}, cards); diff != "" {
+		t.Errorf("ParseFile() diff (-want +got):\n%s", diff)
+	}
+
+	other, err := NewLink(l.URL)
+	if err != nil {
+		t.Fatalf("NewLink() returned unexpected error: %v", err)
+	}
+	if _, _, err := other.Decrypt(jwe); err == nil {
+		t.Errorf("Decrypt() with another key succeeded, want error")
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcards
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Media types of the files SMART Health Links share.
+const (
+	HealthCardType = "application/smart-health-card"
+	FHIRJSONType   = "application/fhir+json"
+)
+
+const linkPrefix = "shlink:/"
+
+// Link is a SMART Health Link.
+type Link struct {
+	// URL is the manifest URL, or the URL of the file itself if Flag
+	// contains "U".
+	URL string `json:"url"`
+	// Key is the key of the shared files, 32 bytes in base64url.
+	Key string `json:"key"`
+	// Exp is the expiration of the link, in seconds since the epoch, or 0.
+	Exp int64 `json:"exp,omitempty"`
+	// Flag holds "L" for long-term links, "P" for links requiring a
+	// passcode and "U" for links to a single file.
+	Flag  string `json:"flag,omitempty"`
+	Label string `json:"label,omitempty"`
+	V     int    `json:"v,omitempty"`
+}
+
+// NewLink returns a Link to url with a new random key.
+func NewLink(url string) (*Link, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return &Link{URL: url, Key: base64.RawURLEncoding.EncodeToString(key)}, nil
+}
+
+// String returns the shlink:/ URI of l.
+func (l *Link) String() string {
+	data, _ := json.Marshal(l)
+	return linkPrefix + base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Expired reports whether l has expired at t.
+func (l *Link) Expired(t time.Time) bool {
+	return l.Exp != 0 && !t.Before(time.Unix(l.Exp, 0))
+}
+
+// ParseLink parses a shlink:/ URI, which may follow the URL of a viewer and
+// a "#".
+func ParseLink(s string) (*Link, error) {
+	if i := strings.Index(s, "#"+linkPrefix); i >= 0 {
+		s = s[i+1:]
+	}
+	rest, ok := cutPrefixFold(s, linkPrefix)
+	if !ok {
+		return nil, fmt.Errorf("link does not start with %s", linkPrefix)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(rest)
+	if err != nil {
+		return nil, fmt.Errorf("malformed link: %w", err)
+	}
+	var l Link
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("malformed link: %w", err)
+	}
+	if l.URL == "" {
+		return nil, fmt.Errorf("link has no URL")
+	}
+	if _, err := l.key(); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (l *Link) key() ([]byte, error) {
+	key, err := base64.RawURLEncoding.DecodeString(l.Key)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("link key must be 32 bytes in base64url")
+	}
+	return key, nil
+}
+
+// jweHeader is the protected header of the files of links.
+type jweHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	Cty string `json:"cty"`
+	Zip string `json:"zip,omitempty"`
+}
+
+// Encrypt returns the compact JWE of content, of media type contentType,
+// encrypted with the key of l for serving at its URL. content is compressed
+// first.
+func (l *Link) Encrypt(content []byte, contentType string) (string, error) {
+	gcm, err := l.gcm()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(content); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	hj, err := json.Marshal(jweHeader{Alg: "dir", Enc: "A256GCM", Cty: contentType, Zip: "DEF"})
+	if err != nil {
+		return "", err
+	}
+	header := base64.RawURLEncoding.EncodeToString(hj)
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, buf.Bytes(), []byte(header))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	enc := base64.RawURLEncoding.EncodeToString
+	return strings.Join([]string{header, "", enc(iv), enc(ciphertext), enc(tag)}, "."), nil
+}
+
+// Decrypt decrypts a file of l, a compact JWE, and returns its content and
+// media type.
+func (l *Link) Decrypt(jwe string) ([]byte, string, error) {
+	gcm, err := l.gcm()
+	if err != nil {
+		return nil, "", err
+	}
+	parts := strings.Split(jwe, ".")
+	if len(parts) != 5 || parts[1] != "" {
+		return nil, "", fmt.Errorf("malformed JWE")
+	}
+	var decoded [5][]byte
+	for i, p := range parts {
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(p); err != nil {
+			return nil, "", fmt.Errorf("malformed JWE: %w", err)
+		}
+	}
+	var h jweHeader
+	if err := json.Unmarshal(decoded[0], &h); err != nil {
+		return nil, "", fmt.Errorf("malformed JWE header: %w", err)
+	}
+	if h.Alg != "dir" || h.Enc != "A256GCM" {
+		return nil, "", fmt.Errorf("unsupported JWE algorithm %s/%s", h.Alg, h.Enc)
+	}
+	if len(decoded[2]) != gcm.NonceSize() {
+		return nil, "", fmt.Errorf("malformed JWE: invalid IV")
+	}
+	plain, err := gcm.Open(nil, decoded[2], append(decoded[3], decoded[4]...), []byte(parts[0]))
+	if err != nil {
+		return nil, "", fmt.Errorf("decrypting: %w", err)
+	}
+	switch h.Zip {
+	case "":
+	case "DEF":
+		r := flate.NewReader(bytes.NewReader(plain))
+		defer r.Close()
+		if plain, err = io.ReadAll(io.LimitReader(r, maxPayload+1)); err != nil {
+			return nil, "", fmt.Errorf("decompressing: %w", err)
+		}
+		if len(plain) > maxPayload {
+			return nil, "", fmt.Errorf("file exceeds %d bytes", maxPayload)
+		}
+	default:
+		return nil, "", fmt.Errorf("unsupported compression %q", h.Zip)
+	}
+	return plain, h.Cty, nil
+}
+
+func (l *Link) gcm() (cipher.AEAD, error) {
+	key, err := l.key()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
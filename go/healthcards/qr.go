@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcards
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	qrPrefix = "shc:/"
+	// maxChunk is the largest number of JWS characters in a single QR code,
+	// which fits a version 22 QR code at error correction level L.
+	maxChunk = 1195
+)
+
+// QRChunks returns the content of the QR codes of a card: "shc:/" and the
+// numeric encoding of jws if it fits in one QR code, or otherwise
+// "shc:/i/n/" and the numeric encoding of the i-th of n chunks of nearly
+// equal size. The numeric part is meant to be encoded in numeric mode, in
+// a separate segment from the prefix.
+//
+// The splitting into chunks is deprecated by the specification, which
+// recommends SMART Health Links for cards that do not fit in one QR code.
+func QRChunks(jws string) ([]string, error) {
+	for i := 0; i < len(jws); i++ {
+		if jws[i] < '-' || jws[i] > 'z' {
+			return nil, fmt.Errorf("JWS has character %q, which has no numeric encoding", jws[i])
+		}
+	}
+	n := (len(jws) + maxChunk - 1) / maxChunk
+	if n <= 1 {
+		return []string{qrPrefix + numeric(jws)}, nil
+	}
+	size := (len(jws) + n - 1) / n
+	var chunks []string
+	for i := 0; i < n; i++ {
+		end := (i + 1) * size
+		if end > len(jws) {
+			end = len(jws)
+		}
+		chunks = append(chunks, fmt.Sprintf("%s%d/%d/%s", qrPrefix, i+1, n, numeric(jws[i*size:end])))
+	}
+	return chunks, nil
+}
+
+// numeric encodes each character of s as two digits, its code minus 45.
+func numeric(s string) string {
+	var b strings.Builder
+	b.Grow(2 * len(s))
+	for i := 0; i < len(s); i++ {
+		fmt.Fprintf(&b, "%02d", s[i]-'-')
+	}
+	return b.String()
+}
+
+// ParseQR returns the JWS of a card from the content of its QR codes, in
+// any order.
+func ParseQR(chunks ...string) (string, error) {
+	if len(chunks) == 0 {
+		return "", fmt.Errorf("no QR codes")
+	}
+	parts := make([]string, len(chunks))
+	for _, c := range chunks {
+		rest, ok := cutPrefixFold(c, qrPrefix)
+		if !ok {
+			return "", fmt.Errorf("QR code does not start with %s", qrPrefix)
+		}
+		i, n := 1, 1
+		if fields := strings.Split(rest, "/"); len(fields) == 3 {
+			var err1, err2 error
+			i, err1 = strconv.Atoi(fields[0])
+			n, err2 = strconv.Atoi(fields[1])
+			if err1 != nil || err2 != nil {
+				return "", fmt.Errorf("malformed chunk header %s/%s", fields[0], fields[1])
+			}
+			rest = fields[2]
+		} else if len(fields) != 1 {
+			return "", fmt.Errorf("malformed QR code")
+		}
+		if n != len(chunks) || i < 1 || i > n {
+			return "", fmt.Errorf("chunk %d of %d in a set of %d QR codes", i, n, len(chunks))
+		}
+		if parts[i-1] != "" {
+			return "", fmt.Errorf("duplicate chunk %d", i)
+		}
+		s, err := fromNumeric(rest)
+		if err != nil {
+			return "", err
+		}
+		parts[i-1] = s
+	}
+	return strings.Join(parts, ""), nil
+}
+
+func fromNumeric(digits string) (string, error) {
+	if len(digits)%2 != 0 {
+		return "", fmt.Errorf("odd number of digits")
+	}
+	b := make([]byte, len(digits)/2)
+	for i := range b {
+		d, err := strconv.Atoi(digits[2*i : 2*i+2])
+		if err != nil || d < 0 || d > 'z'-'-' {
+			return "", fmt.Errorf("invalid digits %q", digits[2*i:2*i+2])
+		}
+		b[i] = byte(d) + '-'
+	}
+	return string(b), nil
+}
+
+// cutPrefixFold is strings.CutPrefix, ignoring case: QR scanners may return
+// the alphanumeric-mode prefix in upper case.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package igpackage loads FHIR R4 implementation guide packages and checks
+// resources against their profiles.
+//
+// Packages are NPM packages as published to the FHIR package registry: a
+// gzipped tarball, or its extracted directory, holding a package.json and the
+// JSON conformance resources of the guide in its package folder. Examples and
+// other subfolders are not loaded.
+//
+// Constraints turns a profile into revalidate constraints checking the
+// cardinality of its elements, its FHIRPath invariants and its required
+// bindings to the core value sets known to package terminology, which need no
+// terminology server. Slices, fixed and pattern values, other bindings and
+// type restrictions are not checked.
+//
+// Resolve loads the dependency tree of a package, i.e. from the FHIR package
+// cache with CacheLoader, and Conflicts reports the packages it requires in
+// several versions and the canonical resources its packages define
+// differently.
+//
+// Write publishes locally authored conformance resources and examples as a
+// package, with the package.json and .index.json files tools expect.
+package igpackage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+)
+
+// Package is a loaded implementation guide package.
+type Package struct {
+	// Name and Version are the NPM name and version of the package, i.e.
+	// "hl7.fhir.us.core" and "6.1.0".
+	Name, Version string
+	// Dependencies maps the names of the packages this package depends on to
+	// their versions.
+	Dependencies map[string]string
+	// Resources are the conformance resources of the package, in the order of
+	// their file names.
+	Resources []*r4pb.ContainedResource
+
+	profiles map[string]*sdpb.StructureDefinition
+}
+
+// manifest is the subset of package.json read by Load.
+type manifest struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// Load reads the package at path, a .tgz file or a directory.
+func Load(path string) (*Package, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	if fi.IsDir() {
+		err = readDir(path, files)
+	} else {
+		err = readTarball(path, files)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading package %s: %w", path, err)
+	}
+	p, err := parse(files)
+	if err != nil {
+		return nil, fmt.Errorf("loading package %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// readDir reads the JSON files of the package folder of dir, or of dir
+// itself if it has none.
+func readDir(dir string, files map[string][]byte) error {
+	if fi, err := os.Stat(filepath.Join(dir, "package")); err == nil && fi.IsDir() {
+		dir = filepath.Join(dir, "package")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		files[e.Name()] = data
+	}
+	return nil
+}
+
+// readTarball reads the JSON files of the package folder of the gzipped
+// tarball name.
+func readTarball(name string, files map[string][]byte) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		dir, file := path.Split(path.Clean(h.Name))
+		if h.Typeflag != tar.TypeReg || dir != "package/" || !strings.HasSuffix(file, ".json") {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		files[file] = data
+	}
+}
+
+func parse(files map[string][]byte) (*Package, error) {
+	data, ok := files["package.json"]
+	if !ok {
+		return nil, fmt.Errorf("no package.json")
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("package.json: %w", err)
+	}
+	p := &Package{
+		Name:         m.Name,
+		Version:      m.Version,
+		Dependencies: m.Dependencies,
+		profiles:     map[string]*sdpb.StructureDefinition{},
+	}
+	um, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range files {
+		// Dot files such as .index.json describe the package, not resources.
+		if name != "package.json" && !strings.HasPrefix(name, ".") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cr, err := um.UnmarshalR4(files[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		p.Resources = append(p.Resources, cr)
+		if sd := cr.GetStructureDefinition(); sd != nil && sd.GetUrl().GetValue() != "" {
+			p.profiles[sd.GetUrl().GetValue()] = sd
+		}
+	}
+	return p, nil
+}
+
+// StructureDefinition returns the StructureDefinition of the package with
+// the canonical url, which may carry a version.
+func (p *Package) StructureDefinition(url string) (*sdpb.StructureDefinition, bool) {
+	url, _, _ = strings.Cut(url, "|")
+	sd, ok := p.profiles[url]
+	return sd, ok
+}
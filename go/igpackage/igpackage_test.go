@@ -0,0 +1,459 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package igpackage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+const profileURL = "http://example.org/StructureDefinition/strict-patient"
+
+var testFiles = map[string]string{
+	"package/package.json": `{
+  "name": "example.strict",
+  "version": "1.0.0",
+  "dependencies": {"hl7.fhir.r4.core": "4.0.1"}
+}`,
+	"package/.index.json": `{"index-version": 1, "files": []}`,
+	"package/StructureDefinition-strict-patient.json": `{
+  "resourceType": "StructureDefinition",
+  "id": "strict-patient",
+  "url": "` + profileURL + `",
+  "name": "StrictPatient",
+  "status": "active",
+  "kind": "resource",
+  "abstract": false,
+  "type": "Patient",
+  "baseDefinition": "http://hl7.org/fhir/StructureDefinition/Patient",
+  "derivation": "constraint",
+  "differential": {
+    "element": [
+      {"id": "Patient", "path": "Patient"},
+      {"id": "Patient.birthDate", "path": "Patient.birthDate", "min": 1},
+      {
+        "id": "Patient.name",
+        "path": "Patient.name",
+        "max": "1",
+        "constraint": [{
+          "key": "sp-1",
+          "severity": "error",
+          "human": "A name has a family name",
+          "expression": "family.exists()",
+          "source": "` + profileURL + `"
+        }, {
+          "key": "ele-1",
+          "severity": "error",
+          "human": "All FHIR elements must have a @value or children",
+          "expression": "hasValue() or (children().count() > id.count())",
+          "source": "http://hl7.org/fhir/StructureDefinition/Element"
+        }]
+      },
+      {
+        "id": "Patient.maritalStatus",
+        "path": "Patient.maritalStatus",
+        "binding": {"strength": "required", "valueSet": "http://hl7.org/fhir/ValueSet/administrative-gender|4.0.1"}
+      },
+      {
+        "id": "Patient.language",
+        "path": "Patient.language",
+        "binding": {"strength": "required", "valueSet": "http://example.org/ValueSet/unknown"}
+      },
+      {"id": "Patient.identifier:mrn", "path": "Patient.identifier", "sliceName": "mrn", "min": 1}
+    ]
+  }
+}`,
+	"package/example/Patient-example.json": `{"resourceType": "Patient", "id": "example"}`,
+}
+
+func writeTarball(t *testing.T) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "example.strict-1.0.0.tgz")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatalf("os.Create() returned unexpected error: %v", err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for path, content := range testFiles {
+		if err := tw.WriteHeader(&tar.Header{Name: path, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("WriteHeader() returned unexpected error: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Write() returned unexpected error: %v", err)
+		}
+	}
+	for _, c := range []interface{ Close() error }{tw, gz, f} {
+		if err := c.Close(); err != nil {
+			t.Fatalf("Close() returned unexpected error: %v", err)
+		}
+	}
+	return name
+}
+
+func writeDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range testFiles {
+		name := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	for name, path := range map[string]string{"tarball": writeTarball(t), "directory": writeDir(t)} {
+		t.Run(name, func(t *testing.T) {
+			p, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() returned unexpected error: %v", err)
+			}
+			if p.Name != "example.strict" || p.Version != "1.0.0" {
+				t.Errorf("Load() returned package %s@%s, want example.strict@1.0.0", p.Name, p.Version)
+			}
+			if diff := cmp.Diff(map[string]string{"hl7.fhir.r4.core": "4.0.1"}, p.Dependencies); diff != "" {
+				t.Errorf("Load() dependencies diff (-want +got):\n%s", diff)
+			}
+			if len(p.Resources) != 1 {
+				t.Errorf("Load() returned %d resources, want 1", len(p.Resources))
+			}
+			sd, ok := p.StructureDefinition(profileURL + "|1.0.0")
+			if !ok || sd.GetName().GetValue() != "StrictPatient" {
+				t.Errorf("StructureDefinition(%q) = %v, %v, want StrictPatient", profileURL, sd, ok)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Patient.json"), []byte(`{"resourceType": "Patient"}`), 0644); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Errorf("Load() without package.json succeeded, want error")
+	}
+	if _, err := Load(filepath.Join(dir, "Patient.json")); err == nil {
+		t.Errorf("Load() of a JSON file succeeded, want error")
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	p, err := Load(writeDir(t))
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	sd, _ := p.StructureDefinition(profileURL)
+	cs, err := Constraints(sd)
+	if err != nil {
+		t.Fatalf("Constraints() returned unexpected error: %v", err)
+	}
+	var keys []string
+	for _, c := range cs {
+		keys = append(keys, c.Key)
+	}
+	wantKeys := []string{
+		profileURL + "#Patient.birthDate:card",
+		profileURL + "#Patient.name:card",
+		profileURL + "#sp-1",
+		profileURL + "#Patient.maritalStatus:binding",
+	}
+	if diff := cmp.Diff(wantKeys, keys); diff != "" {
+		t.Fatalf("Constraints() keys diff (-want +got):\n%s", diff)
+	}
+	v, err := revalidate.NewValidator(cs...)
+	if err != nil {
+		t.Fatalf("NewValidator() returned unexpected error: %v", err)
+	}
+
+	type issue struct {
+		Path     string
+		Severity errorreporter.IssueSeverityCode
+	}
+	tests := []struct {
+		name    string
+		patient *ppb.Patient
+		want    []issue
+	}{
+		{
+			name: "valid",
+			patient: &ppb.Patient{
+				BirthDate: &d4pb.Date{ValueUs: 1, Precision: d4pb.Date_DAY},
+				Name:      []*d4pb.HumanName{{Family: &d4pb.String{Value: "Doe"}}},
+				MaritalStatus: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{
+					{System: &d4pb.Uri{Value: "http://terminology.hl7.org/CodeSystem/v3-MaritalStatus"}, Code: &d4pb.Code{Value: "M"}},
+					{System: &d4pb.Uri{Value: "http://hl7.org/fhir/administrative-gender"}, Code: &d4pb.Code{Value: "female"}},
+				}},
+			},
+		},
+		{
+			name: "invalid",
+			patient: &ppb.Patient{
+				Name: []*d4pb.HumanName{
+					{Family: &d4pb.String{Value: "Doe"}},
+					{Given: []*d4pb.String{{Value: "Jane"}}},
+				},
+				MaritalStatus: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{
+					{System: &d4pb.Uri{Value: "http://terminology.hl7.org/CodeSystem/v3-MaritalStatus"}, Code: &d4pb.Code{Value: "M"}},
+				}},
+			},
+			want: []issue{
+				{"Patient.birthDate", errorreporter.IssueSeverityError},
+				{"Patient.name", errorreporter.IssueSeverityError},
+				{"Patient.name[1]", errorreporter.IssueSeverityError},
+				{"Patient.maritalStatus", errorreporter.IssueSeverityError},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := v.Validate(test.patient)
+			if err != nil {
+				t.Fatalf("Validate() returned unexpected error: %v", err)
+			}
+			var got []issue
+			for _, i := range res.Issues {
+				got = append(got, issue{i.Path, i.Severity})
+			}
+			if diff := cmp.Diff(test.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Validate() issues diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// testPackage returns a package named id, "name#version", with the
+// dependencies deps and the JSON resources.
+func testPackage(t *testing.T, id string, deps map[string]string, resources ...string) *Package {
+	t.Helper()
+	name, version, _ := strings.Cut(id, "#")
+	manifest, err := json.Marshal(manifest{Name: name, Version: version, Dependencies: deps})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+	}
+	files := map[string][]byte{"package.json": manifest}
+	for i, r := range resources {
+		files[fmt.Sprintf("resource-%d.json", i)] = []byte(r)
+	}
+	p, err := parse(files)
+	if err != nil {
+		t.Fatalf("parse() returned unexpected error: %v", err)
+	}
+	return p
+}
+
+func TestContents(t *testing.T) {
+	p := testPackage(t, "example#1.0.0", nil,
+		`{"resourceType": "StructureDefinition", "url": "http://example.org/sd/p", "name": "P", "status": "active", "kind": "resource", "abstract": false, "type": "Patient", "derivation": "constraint"}`,
+		`{"resourceType": "StructureDefinition", "url": "http://example.org/sd/e", "version": "1.0.0", "name": "E", "status": "active", "kind": "complex-type", "abstract": false, "type": "Extension", "derivation": "constraint"}`,
+		`{"resourceType": "ValueSet", "url": "http://example.org/vs", "status": "active"}`,
+		`{"resourceType": "CodeSystem", "url": "http://example.org/cs", "status": "active", "content": "complete"}`,
+		`{"resourceType": "SearchParameter", "url": "http://example.org/sp", "name": "sp", "status": "active", "description": "d", "code": "sp", "base": ["Patient"], "type": "token"}`,
+	)
+	want := Contents{
+		Profiles:    []Artifact{{Type: "StructureDefinition", URL: "http://example.org/sd/p", Name: "P"}},
+		Extensions:  []Artifact{{Type: "StructureDefinition", URL: "http://example.org/sd/e", Version: "1.0.0", Name: "E"}},
+		ValueSets:   []Artifact{{Type: "ValueSet", URL: "http://example.org/vs"}},
+		CodeSystems: []Artifact{{Type: "CodeSystem", URL: "http://example.org/cs"}},
+		Other:       []Artifact{{Type: "SearchParameter", URL: "http://example.org/sp", Name: "sp"}},
+	}
+	if diff := cmp.Diff(want, p.Contents()); diff != "" {
+		t.Errorf("Contents() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestResolveAndConflicts(t *testing.T) {
+	vs := func(title string) string {
+		return `{"resourceType": "ValueSet", "url": "http://example.org/vs", "status": "active", "title": "` + title + `"}`
+	}
+	packages := map[string]*Package{
+		"a#1":        testPackage(t, "a#1", map[string]string{"core": "4.0.1", "b": "2"}, vs("A")),
+		"b#2":        testPackage(t, "b#2", map[string]string{"core": "4.0.0", "a": "1"}, vs("B")),
+		"core#4.0.1": testPackage(t, "core#4.0.1", nil, vs("A")),
+	}
+	load := func(name, version string) (*Package, error) {
+		if p, ok := packages[name+"#"+version]; ok {
+			return p, nil
+		}
+		return nil, fmt.Errorf("package %s#%s not found", name, version)
+	}
+	root := testPackage(t, "app#0.1", map[string]string{"a": "1", "core": "4.0.1"})
+	tree := Resolve(root, load)
+
+	type node struct {
+		ID       string
+		Loaded   bool
+		Repeated bool
+		Deps     []node
+	}
+	var flatten func(d *Dependency) node
+	flatten = func(d *Dependency) node {
+		n := node{ID: d.Name + "#" + d.Version, Loaded: d.Err == nil, Repeated: d.Repeated}
+		for _, dep := range d.Dependencies {
+			n.Deps = append(n.Deps, flatten(dep))
+		}
+		return n
+	}
+	want := node{ID: "app#0.1", Loaded: true, Deps: []node{
+		{ID: "a#1", Loaded: true, Deps: []node{
+			{ID: "b#2", Loaded: true, Deps: []node{
+				{ID: "a#1", Loaded: true, Repeated: true},
+				{ID: "core#4.0.0"},
+			}},
+			{ID: "core#4.0.1", Loaded: true},
+		}},
+		{ID: "core#4.0.1", Loaded: true, Repeated: true},
+	}}
+	if diff := cmp.Diff(want, flatten(tree)); diff != "" {
+		t.Errorf("Resolve() diff (-want +got):\n%s", diff)
+	}
+	var ids []string
+	for _, p := range tree.Packages() {
+		ids = append(ids, p.ID())
+	}
+	if diff := cmp.Diff([]string{"app#0.1", "a#1", "b#2", "core#4.0.1"}, ids); diff != "" {
+		t.Errorf("Packages() diff (-want +got):\n%s", diff)
+	}
+
+	wantConflicts := []Conflict{
+		{Kind: VersionConflict, Subject: "core", Packages: []string{"core#4.0.0", "core#4.0.1"}},
+		{Kind: CanonicalConflict, Subject: "http://example.org/vs", Packages: []string{"a#1", "b#2", "core#4.0.1"}},
+	}
+	if diff := cmp.Diff(wantConflicts, Conflicts(tree)); diff != "" {
+		t.Errorf("Conflicts() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	src := testPackage(t, "example#1.0.0", nil,
+		`{"resourceType": "StructureDefinition", "id": "p", "url": "http://example.org/sd/p", "name": "P", "status": "active", "kind": "resource", "abstract": false, "type": "Patient", "derivation": "constraint"}`,
+		`{"resourceType": "ValueSet", "id": "vs", "url": "http://example.org/vs", "version": "1.0.0", "status": "active"}`,
+	)
+	example := testPackage(t, "example#1.0.0", nil, `{"resourceType": "Patient", "id": "pat1"}`)
+	spec := Spec{
+		Name:         "example.fhir.ig",
+		Version:      "0.1.0",
+		Canonical:    "http://example.org",
+		License:      "CC0-1.0",
+		Dependencies: map[string]string{"hl7.fhir.us.core": "6.1.0"},
+	}
+	name := filepath.Join(t.TempDir(), "example.fhir.ig-0.1.0.tgz")
+	if err := WriteFile(name, spec, src.Resources, example.Resources); err != nil {
+		t.Fatalf("WriteFile() returned unexpected error: %v", err)
+	}
+
+	p, err := Load(name)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if p.ID() != "example.fhir.ig#0.1.0" {
+		t.Errorf("Load() returned package %s, want example.fhir.ig#0.1.0", p.ID())
+	}
+	if diff := cmp.Diff(map[string]string{"hl7.fhir.r4.core": "4.0.1", "hl7.fhir.us.core": "6.1.0"}, p.Dependencies); diff != "" {
+		t.Errorf("Load() dependencies diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(src.Contents(), p.Contents()); diff != "" {
+		t.Errorf("Contents() diff (-want +got):\n%s", diff)
+	}
+
+	files := map[string][]byte{}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("os.Open() returned unexpected error: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() returned unexpected error: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() returned unexpected error: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("ReadAll() returned unexpected error: %v", err)
+		}
+		files[h.Name] = data
+	}
+	var idx index
+	if err := json.Unmarshal(files["package/.index.json"], &idx); err != nil {
+		t.Fatalf("json.Unmarshal(.index.json) returned unexpected error: %v", err)
+	}
+	wantIndex := index{IndexVersion: 1, Files: []indexEntry{
+		{Filename: "StructureDefinition-p.json", ResourceType: "StructureDefinition", ID: "p", URL: "http://example.org/sd/p", Kind: "resource", Type: "Patient"},
+		{Filename: "ValueSet-vs.json", ResourceType: "ValueSet", ID: "vs", URL: "http://example.org/vs", Version: "1.0.0"},
+	}}
+	if diff := cmp.Diff(wantIndex, idx); diff != "" {
+		t.Errorf(".index.json diff (-want +got):\n%s", diff)
+	}
+	if _, ok := files["package/example/Patient-pat1.json"]; !ok {
+		t.Errorf("package has no package/example/Patient-pat1.json")
+	}
+	if _, ok := files["package/example/.index.json"]; !ok {
+		t.Errorf("package has no package/example/.index.json")
+	}
+}
+
+func TestWrite_Errors(t *testing.T) {
+	noID := testPackage(t, "example#1.0.0", nil, `{"resourceType": "ValueSet", "url": "http://example.org/vs", "status": "active"}`)
+	dup := testPackage(t, "example#1.0.0", nil,
+		`{"resourceType": "ValueSet", "id": "vs", "url": "http://example.org/vs1", "status": "active"}`,
+		`{"resourceType": "ValueSet", "id": "vs", "url": "http://example.org/vs2", "status": "active"}`,
+	)
+	tests := []struct {
+		name      string
+		spec      Spec
+		resources []*r4pb.ContainedResource
+	}{
+		{"invalid name", Spec{Name: "Example", Version: "1.0.0"}, nil},
+		{"no version", Spec{Name: "example.ig"}, nil},
+		{"no id", Spec{Name: "example.ig", Version: "1.0.0"}, noID.Resources},
+		{"duplicate id", Spec{Name: "example.ig", Version: "1.0.0"}, dup.Resources},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Write(io.Discard, tc.spec, tc.resources, nil); err == nil {
+				t.Errorf("Write() succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package igpackage
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// ID returns the "name#version" identifying p, as in the FHIR package cache.
+func (p *Package) ID() string {
+	return p.Name + "#" + p.Version
+}
+
+// Artifact is a conformance resource of a package.
+type Artifact struct {
+	// Type is the resource type, i.e. "ValueSet".
+	Type string
+	// URL and Version are the canonical URL and business version of the
+	// resource, if it has them.
+	URL, Version string
+	Name         string
+}
+
+// Contents are the artifacts of a package by kind, in the order of their
+// files.
+type Contents struct {
+	// Profiles are the StructureDefinitions other than extensions, including
+	// logical models.
+	Profiles    []Artifact
+	Extensions  []Artifact
+	ValueSets   []Artifact
+	CodeSystems []Artifact
+	Other       []Artifact
+}
+
+// Contents returns the artifacts of p.
+func (p *Package) Contents() Contents {
+	var c Contents
+	for _, cr := range p.Resources {
+		a := artifact(cr)
+		switch {
+		case cr.GetStructureDefinition() != nil:
+			sd := cr.GetStructureDefinition()
+			if sd.GetType().GetValue() == "Extension" && sd.GetDerivation().GetValue() == c4pb.TypeDerivationRuleCode_CONSTRAINT {
+				c.Extensions = append(c.Extensions, a)
+			} else {
+				c.Profiles = append(c.Profiles, a)
+			}
+		case cr.GetValueSet() != nil:
+			c.ValueSets = append(c.ValueSets, a)
+		case cr.GetCodeSystem() != nil:
+			c.CodeSystems = append(c.CodeSystems, a)
+		default:
+			c.Other = append(c.Other, a)
+		}
+	}
+	return c
+}
+
+func artifact(cr *r4pb.ContainedResource) Artifact {
+	res := elementpath.Unwrap(cr)
+	if res == nil {
+		return Artifact{}
+	}
+	m := res.ProtoReflect()
+	return Artifact{
+		Type:    elementpath.ResourceType(res),
+		URL:     stringField(m, "url"),
+		Version: stringField(m, "version"),
+		Name:    stringField(m, "name"),
+	}
+}
+
+// stringField returns the value of the primitive field name of m, or "" if
+// it is unset or not a string.
+func stringField(m protoreflect.Message, name protoreflect.Name) string {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || fd.Message() == nil || fd.IsList() || !m.Has(fd) {
+		return ""
+	}
+	v := m.Get(fd).Message()
+	vd := v.Descriptor().Fields().ByName("value")
+	if vd == nil || vd.Kind() != protoreflect.StringKind {
+		return ""
+	}
+	return v.Get(vd).String()
+}
+
+// Loader returns the package of a name and version.
+type Loader func(name, version string) (*Package, error)
+
+// CacheLoader returns a Loader reading the FHIR package cache dir, usually
+// ~/.fhir/packages, which holds each package extracted in a "name#version"
+// directory. Versions are matched exactly.
+func CacheLoader(dir string) Loader {
+	return func(name, version string) (*Package, error) {
+		return Load(filepath.Join(dir, name+"#"+version))
+	}
+}
+
+// Dependency is a node of the dependency tree of a package.
+type Dependency struct {
+	Name, Version string
+	// Package is the loaded package, or nil if Err is set.
+	Package *Package
+	Err     error
+	// Repeated is set for a package already expanded earlier in the tree,
+	// whose Dependencies are left empty. It also breaks dependency cycles.
+	Repeated bool
+	// Dependencies are the dependencies of the package, by name.
+	Dependencies []*Dependency
+}
+
+// Resolve returns the dependency tree of p, loading its dependencies and
+// theirs with load. A package failing to load is reported in the Err of its
+// nodes.
+func Resolve(p *Package, load Loader) *Dependency {
+	r := &resolver{load: load, loaded: map[string]*Dependency{}}
+	root := &Dependency{Name: p.Name, Version: p.Version, Package: p}
+	r.loaded[p.ID()] = root
+	r.expand(root)
+	return root
+}
+
+type resolver struct {
+	load Loader
+	// loaded are the first nodes of the packages, by ID.
+	loaded map[string]*Dependency
+}
+
+func (r *resolver) expand(d *Dependency) {
+	var names []string
+	for name := range d.Package.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dep := &Dependency{Name: name, Version: d.Package.Dependencies[name]}
+		d.Dependencies = append(d.Dependencies, dep)
+		if first, ok := r.loaded[name+"#"+dep.Version]; ok {
+			dep.Package, dep.Err, dep.Repeated = first.Package, first.Err, true
+			continue
+		}
+		r.loaded[name+"#"+dep.Version] = dep
+		dep.Package, dep.Err = r.load(name, dep.Version)
+		if dep.Err == nil {
+			r.expand(dep)
+		}
+	}
+}
+
+// Packages returns the distinct packages loaded in the tree of d, starting
+// with its own.
+func (d *Dependency) Packages() []*Package {
+	var out []*Package
+	var walk func(d *Dependency)
+	walk = func(d *Dependency) {
+		if d.Repeated {
+			return
+		}
+		if d.Package != nil {
+			out = append(out, d.Package)
+		}
+		for _, dep := range d.Dependencies {
+			walk(dep)
+		}
+	}
+	walk(d)
+	return out
+}
+
+// ConflictKind is the kind of a Conflict.
+type ConflictKind string
+
+// Kinds of conflicts.
+const (
+	// VersionConflict is a package required in several versions.
+	VersionConflict ConflictKind = "version"
+	// CanonicalConflict is a canonical URL defined differently by several
+	// packages.
+	CanonicalConflict ConflictKind = "canonical"
+)
+
+// Conflict is an inconsistency between the packages of a dependency tree,
+// which makes what a validator resolves depend on the order packages are
+// loaded in.
+type Conflict struct {
+	Kind ConflictKind `json:"kind"`
+	// Subject is the name of the package or the canonical URL in conflict.
+	Subject string `json:"subject"`
+	// Packages are the IDs of the packages in conflict, sorted: the versions
+	// of the package required, or the packages defining the canonical URL.
+	Packages []string `json:"packages"`
+}
+
+// Conflicts returns the conflicts of the dependency tree d: the packages
+// required in several versions, by name, then the canonical URLs defined
+// differently by packages of different names, by URL. Definitions of a
+// canonical URL by two versions of the same package are not reported, being
+// covered by the version conflict.
+func Conflicts(d *Dependency) []Conflict {
+	var out []Conflict
+	versions := map[string]map[string]bool{}
+	var walk func(d *Dependency)
+	walk = func(d *Dependency) {
+		if versions[d.Name] == nil {
+			versions[d.Name] = map[string]bool{}
+		}
+		versions[d.Name][d.Version] = true
+		for _, dep := range d.Dependencies {
+			walk(dep)
+		}
+	}
+	walk(d)
+	var names []string
+	for name, vs := range versions {
+		if len(vs) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := Conflict{Kind: VersionConflict, Subject: name}
+		for v := range versions[name] {
+			c.Packages = append(c.Packages, name+"#"+v)
+		}
+		sort.Strings(c.Packages)
+		out = append(out, c)
+	}
+
+	type definition struct {
+		pkg *Package
+		res *r4pb.ContainedResource
+	}
+	defs := map[string][]definition{}
+	for _, p := range d.Packages() {
+		for _, cr := range p.Resources {
+			if url := artifact(cr).URL; url != "" {
+				defs[url] = append(defs[url], definition{p, cr})
+			}
+		}
+	}
+	var urls []string
+	for url := range defs {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		ds := defs[url]
+		conflict := false
+		for i := range ds {
+			for j := i + 1; j < len(ds); j++ {
+				if ds[i].pkg.Name != ds[j].pkg.Name && !proto.Equal(ds[i].res, ds[j].res) {
+					conflict = true
+				}
+			}
+		}
+		if !conflict {
+			continue
+		}
+		c := Conflict{Kind: CanonicalConflict, Subject: url}
+		seen := map[string]bool{}
+		for _, def := range ds {
+			if id := def.pkg.ID(); !seen[id] {
+				seen[id] = true
+				c.Packages = append(c.Packages, id)
+			}
+		}
+		sort.Strings(c.Packages)
+		out = append(out, c)
+	}
+	return out
+}
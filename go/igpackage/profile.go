@@ -0,0 +1,265 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package igpackage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/fhir/go/codes"
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+	"github.com/google/fhir/go/terminology"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+)
+
+// Constraints returns the constraints of the profile sd: the cardinality of
+// each of its elements outside slices, the required bindings of its coded
+// elements to value sets of package terminology, and the invariants it
+// defines. The
+// elements are those of its snapshot, or of its differential if it has no
+// snapshot. Invariants inherited from the base definitions, whose source is
+// another StructureDefinition, are left to the validation of the base types.
+//
+// Each constraint depends on the path of its element, so that Revalidate
+// checks it again only when the element changes. Invariants whose expression
+// reads elements outside their own, i.e. through %resource, may need to be
+// revalidated in full.
+func Constraints(sd *sdpb.StructureDefinition) ([]revalidate.Constraint, error) {
+	url := sd.GetUrl().GetValue()
+	elems := sd.GetSnapshot().GetElement()
+	if len(elems) == 0 {
+		elems = sd.GetDifferential().GetElement()
+	}
+	var cs []revalidate.Constraint
+	for _, e := range elems {
+		path := e.GetPath().GetValue()
+		if path == "" || e.GetSliceName() != nil || strings.Contains(e.GetId().GetValue(), ":") {
+			continue
+		}
+		if strings.Contains(path, ".") && (e.GetMin() != nil || e.GetMax() != nil) {
+			c, err := cardinality(url, path, e)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				cs = append(cs, *c)
+			}
+		}
+		if b := e.GetBinding(); b.GetStrength().GetValue() == c4pb.BindingStrengthCode_REQUIRED && strings.Contains(path, ".") {
+			if vs, ok := terminology.Lookup(b.GetValueSet().GetValue()); ok {
+				cs = append(cs, binding(url, path, vs))
+			}
+		}
+		for _, inv := range e.GetConstraint() {
+			if src := inv.GetSource().GetValue(); src != "" && src != url {
+				continue
+			}
+			if inv.GetExpression().GetValue() == "" {
+				continue
+			}
+			c, err := invariant(url, path, inv)
+			if err != nil {
+				return nil, err
+			}
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+// cardinality returns the constraint of the cardinality of the element e at
+// path, or nil if it allows any number of elements.
+func cardinality(url, path string, e *d4pb.ElementDefinition) (*revalidate.Constraint, error) {
+	min := int(e.GetMin().GetValue())
+	max := -1
+	if m := e.GetMax().GetValue(); m != "" && m != "*" {
+		n, err := strconv.Atoi(m)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid max cardinality %q of %s", url, m, path)
+		}
+		max = n
+	}
+	if min == 0 && max < 0 {
+		return nil, nil
+	}
+	i := strings.LastIndexByte(path, '.')
+	parent, name := stripChoice(path[:i]), strings.TrimSuffix(path[i+1:], "[x]")
+	return &revalidate.Constraint{
+		Key:   url + "#" + path + ":card",
+		Paths: []string{path},
+		Check: func(res proto.Message) ([]revalidate.Issue, error) {
+			var issues []revalidate.Issue
+			err := elementpath.Walk(res, parent, func(parentPath string, elem protoreflect.Message) error {
+				n, err := count(elem, name)
+				if err != nil {
+					return err
+				}
+				switch {
+				case n < min:
+					issues = append(issues, revalidate.Issue{
+						Path:     parentPath + "." + name,
+						Severity: errorreporter.IssueSeverityError,
+						Message:  fmt.Sprintf("%s: minimum required = %d, but only found %d (from %s)", path, min, n, url),
+					})
+				case max >= 0 && n > max:
+					issues = append(issues, revalidate.Issue{
+						Path:     parentPath + "." + name,
+						Severity: errorreporter.IssueSeverityError,
+						Message:  fmt.Sprintf("%s: max allowed = %d, but found %d (from %s)", path, max, n, url),
+					})
+				}
+				return nil
+			})
+			return issues, err
+		},
+	}, nil
+}
+
+// count returns the number of elements name of msg.
+func count(msg protoreflect.Message, name string) (int, error) {
+	fd, _, err := elementpath.LookupField(msg.Descriptor(), name)
+	if err != nil {
+		return 0, err
+	}
+	if fd.IsList() {
+		return msg.Get(fd).List().Len(), nil
+	}
+	if msg.Has(fd) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// binding returns the constraint of the required binding of the element at
+// path to vs. Elements of types other than code, Coding and CodeableConcept
+// are not checked.
+func binding(url, path string, vs *terminology.ValueSet) revalidate.Constraint {
+	return revalidate.Constraint{
+		Key:   url + "#" + path + ":binding",
+		Paths: []string{path},
+		Check: func(res proto.Message) ([]revalidate.Issue, error) {
+			var issues []revalidate.Issue
+			err := elementpath.Walk(res, stripChoice(path), func(elemPath string, elem protoreflect.Message) error {
+				if problem := bindingProblem(vs, elem); problem != "" {
+					issues = append(issues, revalidate.Issue{
+						Path:     elemPath,
+						Severity: errorreporter.IssueSeverityError,
+						Message:  fmt.Sprintf("%s: %s (from %s)", path, problem, url),
+					})
+				}
+				return nil
+			})
+			return issues, err
+		},
+	}
+}
+
+// bindingProblem returns why the coded element elem is not in vs, or "" if
+// it is or has no code.
+func bindingProblem(vs *terminology.ValueSet, elem protoreflect.Message) string {
+	var system, code string
+	switch e := elem.Interface().(type) {
+	case *d4pb.CodeableConcept:
+		if vs.ContainsConcept(e) {
+			return ""
+		}
+		return fmt.Sprintf("no coding is in the required value set %s", vs.URL)
+	case *d4pb.Coding:
+		system, code = e.GetSystem().GetValue(), e.GetCode().GetValue()
+		if system == "" {
+			return fmt.Sprintf("code %q has no system", code)
+		}
+	case *d4pb.Code:
+		code = e.GetValue()
+	default:
+		fd := elem.Descriptor().Fields().ByName("value")
+		if fd == nil || fd.Enum() == nil || !elem.Has(fd) {
+			return ""
+		}
+		v := fd.Enum().Values().ByNumber(elem.Get(fd).Enum())
+		if v == nil {
+			return ""
+		}
+		code = codes.ValueCode(v)
+	}
+	if code == "" || vs.Contains(system, code) {
+		return ""
+	}
+	return fmt.Sprintf("code %q is not in the required value set %s", code, vs.URL)
+}
+
+// invariant returns the constraint of the invariant inv of the element at
+// path, evaluated with each such element as its focus.
+func invariant(url, path string, inv *d4pb.ElementDefinition_Constraint) (revalidate.Constraint, error) {
+	key := inv.GetKey().GetValue()
+	expr, err := fhirpath.Compile(inv.GetExpression().GetValue())
+	if err != nil {
+		return revalidate.Constraint{}, fmt.Errorf("%s: invariant %s: %w", url, key, err)
+	}
+	severity := errorreporter.IssueSeverityError
+	if inv.GetSeverity().GetValue() == c4pb.ConstraintSeverityCode_WARNING {
+		severity = errorreporter.IssueSeverityWarning
+	}
+	return revalidate.Constraint{
+		Key:   url + "#" + key,
+		Paths: []string{path},
+		Check: func(res proto.Message) ([]revalidate.Issue, error) {
+			var issues []revalidate.Issue
+			root := elementpath.Unwrap(res)
+			check := func(elemPath string, focus proto.Message) {
+				ok, err := expr.EvaluateBool(focus, fhirpath.WithResource(root))
+				switch {
+				case err != nil:
+					issues = append(issues, revalidate.Issue{
+						Path:     elemPath,
+						Severity: errorreporter.IssueSeverityWarning,
+						Message:  fmt.Sprintf("invariant %s could not be evaluated: %v", key, err),
+					})
+				case !ok:
+					issues = append(issues, revalidate.Issue{
+						Path:     elemPath,
+						Severity: severity,
+						Message:  fmt.Sprintf("%s: %s (from %s)", key, inv.GetHuman().GetValue(), url),
+					})
+				}
+			}
+			if !strings.Contains(path, ".") {
+				if root != nil && string(root.ProtoReflect().Descriptor().Name()) == path {
+					check(path, root)
+				}
+				return issues, nil
+			}
+			err := elementpath.Walk(res, stripChoice(path), func(elemPath string, elem protoreflect.Message) error {
+				check(elemPath, elem.Interface())
+				return nil
+			})
+			return issues, err
+		},
+	}, nil
+}
+
+// stripChoice removes the [x] suffixes of the choice elements of path.
+func stripChoice(path string) string {
+	return strings.ReplaceAll(path, "[x]", "")
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package igpackage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/google/fhir/go/codes"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// coreName and coreVersion identify the package of the R4 core
+// specification, on which every R4 package depends.
+const (
+	coreName    = "hl7.fhir.r4.core"
+	coreVersion = "4.0.1"
+)
+
+var (
+	packageNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9\-_]*(\.[a-z0-9][a-z0-9\-_]*)+$`)
+	resourceIDRE  = regexp.MustCompile(`^[A-Za-z0-9\-.]{1,64}$`)
+)
+
+// Spec describes a package for Write.
+type Spec struct {
+	// Name and Version are the NPM name and version of the package, i.e.
+	// "example.fhir.ig" and "1.0.0". Names are lowercase and have at
+	// least two dot separated parts.
+	Name, Version string
+	Description   string
+	Author        string
+	// License is the SPDX identifier of the license of the package, i.e.
+	// "CC0-1.0".
+	License string
+	// Canonical is the canonical URL of the guide and URL where it is
+	// published.
+	Canonical, URL string
+	// Dependencies maps the names of the packages the package depends on to
+	// their versions. The R4 core package is added if missing.
+	Dependencies map[string]string
+}
+
+// packageJSON is the package.json written by Write.
+type packageJSON struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Description  string            `json:"description,omitempty"`
+	Author       string            `json:"author,omitempty"`
+	License      string            `json:"license,omitempty"`
+	Canonical    string            `json:"canonical,omitempty"`
+	URL          string            `json:"url,omitempty"`
+	Type         string            `json:"type"`
+	FHIRVersions []string          `json:"fhirVersions"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// index is an .index.json file, listing the resources of a package folder.
+type index struct {
+	IndexVersion int          `json:"index-version"`
+	Files        []indexEntry `json:"files"`
+}
+
+type indexEntry struct {
+	Filename     string `json:"filename"`
+	ResourceType string `json:"resourceType"`
+	ID           string `json:"id"`
+	URL          string `json:"url,omitempty"`
+	Version      string `json:"version,omitempty"`
+	Kind         string `json:"kind,omitempty"`
+	Type         string `json:"type,omitempty"`
+}
+
+// Write writes a package of spec to w as a gzipped tarball: a package.json,
+// the conformance resources, such as StructureDefinitions and ValueSets, in
+// the package folder and the examples in its example subfolder, which both
+// have an .index.json. Resources are written to files named for their type
+// and id, i.e. StructureDefinition-my-patient.json, so they need ids that
+// are unique per type. The tarball has no timestamps, so the same package
+// is always written the same way.
+func Write(w io.Writer, spec Spec, resources, examples []*r4pb.ContainedResource) error {
+	if !packageNameRE.MatchString(spec.Name) {
+		return fmt.Errorf("invalid package name %q", spec.Name)
+	}
+	if spec.Version == "" {
+		return fmt.Errorf("package %s has no version", spec.Name)
+	}
+	deps := map[string]string{}
+	for name, version := range spec.Dependencies {
+		deps[name] = version
+	}
+	if _, ok := deps[coreName]; !ok {
+		deps[coreName] = coreVersion
+	}
+	pj, err := json.MarshalIndent(packageJSON{
+		Name:         spec.Name,
+		Version:      spec.Version,
+		Description:  spec.Description,
+		Author:       spec.Author,
+		License:      spec.License,
+		Canonical:    spec.Canonical,
+		URL:          spec.URL,
+		Type:         "fhir.ig",
+		FHIRVersions: []string{coreVersion},
+		Dependencies: deps,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	m, err := jsonformat.NewMarshaller(true, "", "  ", fhirversion.R4)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{"package/package.json": pj}
+	for _, folder := range []struct {
+		dir       string
+		resources []*r4pb.ContainedResource
+	}{{"package/", resources}, {"package/example/", examples}} {
+		if err := addFolder(files, m, folder.dir, folder.resources); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, name := range names {
+		data := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// addFolder adds the files of resources and their .index.json in the
+// folder dir to files.
+func addFolder(files map[string][]byte, m *jsonformat.Marshaller, dir string, resources []*r4pb.ContainedResource) error {
+	idx := index{IndexVersion: 1, Files: []indexEntry{}}
+	for _, cr := range resources {
+		res := elementpath.Unwrap(cr)
+		if res == nil {
+			return fmt.Errorf("empty ContainedResource")
+		}
+		msg := res.ProtoReflect()
+		a := artifact(cr)
+		id := stringField(msg, "id")
+		if !resourceIDRE.MatchString(id) {
+			return fmt.Errorf("%s %q has no valid id", a.Type, a.URL)
+		}
+		e := indexEntry{
+			Filename:     a.Type + "-" + id + ".json",
+			ResourceType: a.Type,
+			ID:           id,
+			URL:          a.URL,
+			Version:      a.Version,
+		}
+		if sd := cr.GetStructureDefinition(); sd != nil {
+			e.Kind = codes.Code(sd.GetKind().GetValue())
+			e.Type = sd.GetType().GetValue()
+		}
+		if _, ok := files[dir+e.Filename]; ok {
+			return fmt.Errorf("duplicate %s/%s", a.Type, id)
+		}
+		data, err := m.MarshalResource(res)
+		if err != nil {
+			return fmt.Errorf("marshalling %s/%s: %w", a.Type, id, err)
+		}
+		files[dir+e.Filename] = data
+		idx.Files = append(idx.Files, e)
+	}
+	if len(idx.Files) == 0 && dir != "package/" {
+		return nil
+	}
+	sort.Slice(idx.Files, func(i, j int) bool { return idx.Files[i].Filename < idx.Files[j].Filename })
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	files[dir+".index.json"] = data
+	return nil
+}
+
+// WriteFile writes a package as by Write to the file name, conventionally
+// "name-version.tgz".
+func WriteFile(name string, spec Spec, resources, examples []*r4pb.ContainedResource) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, spec, resources, examples); err != nil {
+		f.Close()
+		return fmt.Errorf("writing package %s: %w", name, err)
+	}
+	return f.Close()
+}
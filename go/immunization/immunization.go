@@ -0,0 +1,437 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package immunization evaluates the R4 Immunization history of a patient
+// against immunization schedules and forecasts the next doses as an
+// ImmunizationRecommendation.
+//
+// A schedule is a set of Series, each an ordered list of Doses with the
+// vaccines that count as the dose, the age from which it may be given and
+// the interval it must follow the previous dose by. Series are written by
+// hand or read from PlanDefinitions by SeriesFromPlanDefinition, in which
+// each action of the PlanDefinition is a dose:
+//
+//   - the codes of the action are the vaccines of the dose, and default to
+//     those of the previous dose;
+//   - timingAge is the age the dose is due at, and may be given from;
+//     timingRange gives the age it may be given from as its low and the age
+//     it is overdue from as its high;
+//   - a relatedAction on the action of the previous dose, with relationship
+//     after, after-start or after-end, gives the interval since that dose as
+//     offsetDuration, or as offsetRange with the minimum interval as its low
+//     and the interval after which the dose is overdue as its high;
+//   - the addresses of the first goal of the PlanDefinition are the target
+//     disease of the series.
+//
+// Completed, potent Immunizations count as the next dose of a series, in
+// the order they were given, if their vaccine is one of its vaccines and
+// they were given no earlier than the dose may be, less the grace period of
+// Options. Doses given too early do not count, and the dose is forecast
+// again.
+package immunization
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	impb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/immunization_go_proto"
+	irpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/immunization_recommendation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	pdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/plan_definition_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+// ForecastStatusSystem is the code system of the forecast status of
+// recommendations.
+const ForecastStatusSystem = "http://terminology.hl7.org/CodeSystem/immunization-recommendation-status"
+
+// Forecast statuses of recommendations.
+const (
+	StatusDue      = "due"
+	StatusOverdue  = "overdue"
+	StatusComplete = "complete"
+)
+
+// LOINC codes of the date criteria of recommendations.
+const (
+	EarliestDate = "30981-5"
+	DueDate      = "30980-7"
+	OverdueDate  = "59778-1"
+)
+
+const loinc = "http://loinc.org"
+
+// ErrNoBirthDate is returned by Evaluate for patients without a birth date,
+// from which the ages of doses are measured.
+var ErrNoBirthDate = errors.New("patient has no birth date")
+
+// Offset is a length of time in UCUM units of days ("d"), weeks ("wk"),
+// months ("mo") or years ("a"), such as an age or an interval between
+// doses. The zero Offset is unset.
+type Offset struct {
+	Value int
+	Unit  string
+}
+
+// IsZero reports whether o is unset.
+func (o Offset) IsZero() bool { return o.Unit == "" }
+
+// AddTo returns t plus o. Months and years are calendar months and years,
+// so that 2 mo from January 31 is March 31 or, past the end of the month,
+// the days after it.
+func (o Offset) AddTo(t time.Time) time.Time {
+	switch o.Unit {
+	case "d":
+		return t.AddDate(0, 0, o.Value)
+	case "wk":
+		return t.AddDate(0, 0, 7*o.Value)
+	case "mo":
+		return t.AddDate(0, o.Value, 0)
+	case "a":
+		return t.AddDate(o.Value, 0, 0)
+	}
+	return t
+}
+
+// String returns o as a UCUM quantity, i.e. "6 mo".
+func (o Offset) String() string {
+	return fmt.Sprintf("%d %s", o.Value, o.Unit)
+}
+
+// ParseOffset returns the Offset of the value and UCUM unit code of a
+// quantity, such as an Age or Duration.
+func ParseOffset(value *d4pb.Decimal, code *d4pb.Code) (Offset, error) {
+	v, err := strconv.ParseFloat(value.GetValue(), 64)
+	if err != nil {
+		return Offset{}, fmt.Errorf("invalid value %q", value.GetValue())
+	}
+	if v != math.Trunc(v) || v < 0 {
+		return Offset{}, fmt.Errorf("value %s is not a whole number", value.GetValue())
+	}
+	switch u := code.GetValue(); u {
+	case "d", "wk", "mo", "a":
+		return Offset{Value: int(v), Unit: u}, nil
+	default:
+		return Offset{}, fmt.Errorf("unsupported unit %q", u)
+	}
+}
+
+// Dose is a dose of a Series.
+type Dose struct {
+	// Vaccines are the vaccines that count as the dose, such as CVX codes.
+	Vaccines []*d4pb.CodeableConcept
+	// MinAge is the age from which the dose may be given, DueAge the age it
+	// is recommended at and OverdueAge the age from which it is overdue.
+	MinAge, DueAge, OverdueAge Offset
+	// MinInterval, DueInterval and OverdueInterval are the same since the
+	// previous dose, and are ignored for the first dose.
+	MinInterval, DueInterval, OverdueInterval Offset
+}
+
+// Series is an immunization series: the doses that make up a complete
+// immunization against its target disease.
+type Series struct {
+	// Name identifies the series, as the series of recommendations.
+	Name          string
+	TargetDisease *d4pb.CodeableConcept
+	Doses         []Dose
+}
+
+// SeriesFromPlanDefinition returns the Series of pd, by the conventions of
+// the package documentation.
+func SeriesFromPlanDefinition(pd *pdpb.PlanDefinition) (*Series, error) {
+	s := &Series{Name: pd.GetTitle().GetValue()}
+	if s.Name == "" {
+		s.Name = pd.GetName().GetValue()
+	}
+	if goals := pd.GetGoal(); len(goals) > 0 && len(goals[0].GetAddresses()) > 0 {
+		s.TargetDisease = goals[0].GetAddresses()[0]
+	}
+	var vaccines []*d4pb.CodeableConcept
+	prev := ""
+	for i, a := range pd.GetAction() {
+		d := Dose{Vaccines: a.GetCode()}
+		if len(d.Vaccines) == 0 {
+			d.Vaccines = vaccines
+		}
+		if len(d.Vaccines) == 0 {
+			return nil, fmt.Errorf("PlanDefinition %s: action %d has no vaccine codes", pd.GetId().GetValue(), i)
+		}
+		vaccines = d.Vaccines
+		if err := doseTiming(&d, a, prev); err != nil {
+			return nil, fmt.Errorf("PlanDefinition %s: action %d: %w", pd.GetId().GetValue(), i, err)
+		}
+		prev = a.GetId().GetValue()
+		s.Doses = append(s.Doses, d)
+	}
+	if len(s.Doses) == 0 {
+		return nil, fmt.Errorf("PlanDefinition %s has no actions", pd.GetId().GetValue())
+	}
+	return s, nil
+}
+
+// doseTiming sets the ages and intervals of d from the timing and related
+// actions of a, whose previous action has the id prev.
+func doseTiming(d *Dose, a *pdpb.PlanDefinition_Action, prev string) error {
+	var err error
+	switch t := a.GetTiming(); {
+	case t.GetAge() != nil:
+		if d.MinAge, err = ParseOffset(t.GetAge().GetValue(), t.GetAge().GetCode()); err != nil {
+			return fmt.Errorf("timingAge: %w", err)
+		}
+		d.DueAge = d.MinAge
+	case t.GetRange() != nil:
+		if d.MinAge, d.OverdueAge, err = rangeOffsets(t.GetRange()); err != nil {
+			return fmt.Errorf("timingRange: %w", err)
+		}
+		d.DueAge = d.MinAge
+	case t != nil:
+		return fmt.Errorf("timing is neither an Age nor a Range")
+	}
+	for _, r := range a.GetRelatedAction() {
+		switch r.GetRelationship().GetValue() {
+		case c4pb.ActionRelationshipTypeCode_AFTER, c4pb.ActionRelationshipTypeCode_AFTER_START, c4pb.ActionRelationshipTypeCode_AFTER_END:
+		default:
+			continue
+		}
+		if prev == "" || r.GetActionId().GetValue() != prev {
+			return fmt.Errorf("related action %q is not the previous dose", r.GetActionId().GetValue())
+		}
+		switch o := r.GetOffset(); {
+		case o.GetDuration() != nil:
+			if d.MinInterval, err = ParseOffset(o.GetDuration().GetValue(), o.GetDuration().GetCode()); err != nil {
+				return fmt.Errorf("offsetDuration: %w", err)
+			}
+			d.DueInterval = d.MinInterval
+		case o.GetRange() != nil:
+			if d.MinInterval, d.OverdueInterval, err = rangeOffsets(o.GetRange()); err != nil {
+				return fmt.Errorf("offsetRange: %w", err)
+			}
+			d.DueInterval = d.MinInterval
+		}
+	}
+	return nil
+}
+
+// rangeOffsets returns the Offsets of the low and high of r, either of
+// which may be unset.
+func rangeOffsets(r *d4pb.Range) (low, high Offset, err error) {
+	if l := r.GetLow(); l != nil {
+		if low, err = ParseOffset(l.GetValue(), l.GetCode()); err != nil {
+			return Offset{}, Offset{}, err
+		}
+	}
+	if h := r.GetHigh(); h != nil {
+		if high, err = ParseOffset(h.GetValue(), h.GetCode()); err != nil {
+			return Offset{}, Offset{}, err
+		}
+	}
+	return low, high, nil
+}
+
+// Options configures Evaluate.
+type Options struct {
+	// Date is the date of the evaluation, which the forecast status is as
+	// of. It defaults to the current time.
+	Date time.Time
+	// GraceDays is the number of days before the minimum age or interval of
+	// a dose from which a dose given still counts, such as the 4 days of the
+	// ACIP schedules.
+	GraceDays int
+	// Authority is the organization responsible for the recommendations.
+	Authority *d4pb.Reference
+}
+
+// Evaluate evaluates the immunizations of the patient p, the Immunizations
+// of history, against series, and returns an ImmunizationRecommendation
+// with a recommendation for each series, in their order: the next dose of
+// the series, due or overdue as of the date of opts, or complete if every
+// dose has been given. Immunizations that are not completed, are subpotent
+// or have no date of occurrence are ignored.
+func Evaluate(p *ppb.Patient, history []*impb.Immunization, series []*Series, opts Options) (*irpb.ImmunizationRecommendation, error) {
+	if p.GetBirthDate() == nil {
+		return nil, ErrNoBirthDate
+	}
+	birth, err := fhirtypes.DateToTime(p.GetBirthDate())
+	if err != nil {
+		return nil, fmt.Errorf("birth date: %w", err)
+	}
+	if opts.Date.IsZero() {
+		opts.Date = time.Now()
+	}
+	given, err := administered(history)
+	if err != nil {
+		return nil, err
+	}
+	out := &irpb.ImmunizationRecommendation{
+		Date:      fhirtypes.DateTimeFromTime(opts.Date, d4pb.DateTime_DAY),
+		Authority: opts.Authority,
+	}
+	if id := p.GetId().GetValue(); id != "" {
+		out.Patient = fhirtypes.Reference("Patient", id)
+	}
+	for _, s := range series {
+		if len(s.Doses) == 0 {
+			return nil, fmt.Errorf("series %q has no doses", s.Name)
+		}
+		out.Recommendation = append(out.Recommendation, evaluate(s, given, birth, opts))
+	}
+	return out, nil
+}
+
+// administration is an Immunization that may count as a dose.
+type administration struct {
+	imm  *impb.Immunization
+	date time.Time
+}
+
+// administered returns the Immunizations of history that may count as
+// doses, ordered by the date they were given.
+func administered(history []*impb.Immunization) ([]administration, error) {
+	var out []administration
+	for _, imm := range history {
+		if imm.GetStatus().GetValue() != vspb.ImmunizationStatusCodesValueSet_COMPLETED ||
+			imm.GetIsSubpotent().GetValue() || imm.GetOccurrence().GetDateTime() == nil {
+			continue
+		}
+		t, err := fhirtypes.DateTimeToTime(imm.GetOccurrence().GetDateTime())
+		if err != nil {
+			return nil, fmt.Errorf("Immunization %s: occurrence %w", imm.GetId().GetValue(), err)
+		}
+		out = append(out, administration{imm, t})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].date.Before(out[j].date) })
+	return out, nil
+}
+
+// evaluate returns the recommendation of s given the administrations of the
+// patient born on birth.
+func evaluate(s *Series, given []administration, birth time.Time, opts Options) *irpb.ImmunizationRecommendation_Recommendation {
+	rec := &irpb.ImmunizationRecommendation_Recommendation{
+		TargetDisease: s.TargetDisease,
+		SeriesDoses: &irpb.ImmunizationRecommendation_Recommendation_SeriesDosesX{
+			Choice: &irpb.ImmunizationRecommendation_Recommendation_SeriesDosesX_PositiveInt{PositiveInt: fhirtypes.PositiveInt(uint32(len(s.Doses)))},
+		},
+	}
+	if s.Name != "" {
+		rec.Series = fhirtypes.String(s.Name)
+	}
+	n := 0
+	var last time.Time
+	for _, a := range given {
+		if n == len(s.Doses) {
+			break
+		}
+		d := s.Doses[n]
+		if !isVaccine(a.imm.GetVaccineCode(), d.Vaccines) {
+			continue
+		}
+		earliest, _, _ := d.dates(birth, last, n)
+		if a.date.Before(earliest.AddDate(0, 0, -opts.GraceDays)) {
+			continue
+		}
+		n++
+		last = a.date
+		if id := a.imm.GetId().GetValue(); id != "" {
+			rec.SupportingImmunization = append(rec.SupportingImmunization, fhirtypes.Reference("Immunization", id))
+		}
+	}
+	if n == len(s.Doses) {
+		rec.VaccineCode = s.Doses[n-1].Vaccines
+		rec.ForecastStatus = status(StatusComplete)
+		return rec
+	}
+	d := s.Doses[n]
+	rec.VaccineCode = d.Vaccines
+	rec.DoseNumber = &irpb.ImmunizationRecommendation_Recommendation_DoseNumberX{
+		Choice: &irpb.ImmunizationRecommendation_Recommendation_DoseNumberX_PositiveInt{PositiveInt: fhirtypes.PositiveInt(uint32(n + 1))},
+	}
+	earliest, due, overdue := d.dates(birth, last, n)
+	rec.DateCriterion = append(rec.DateCriterion, criterion(EarliestDate, earliest), criterion(DueDate, due))
+	rec.ForecastStatus = status(StatusDue)
+	if !overdue.IsZero() {
+		rec.DateCriterion = append(rec.DateCriterion, criterion(OverdueDate, overdue))
+		if !opts.Date.Before(overdue) {
+			rec.ForecastStatus = status(StatusOverdue)
+		}
+	}
+	return rec
+}
+
+// dates returns the earliest, due and overdue dates of d as the dose n,
+// counting from 0, of a patient born on birth whose previous dose was given
+// on last. The overdue date is zero if d is never overdue.
+func (d Dose) dates(birth, last time.Time, n int) (earliest, due, overdue time.Time) {
+	latest := func(age, interval Offset) time.Time {
+		var t time.Time
+		if !age.IsZero() {
+			t = age.AddTo(birth)
+		}
+		if n > 0 && !interval.IsZero() {
+			if i := interval.AddTo(last); i.After(t) {
+				t = i
+			}
+		}
+		return t
+	}
+	earliest = latest(d.MinAge, d.MinInterval)
+	if earliest.IsZero() {
+		earliest = birth
+		if n > 0 {
+			earliest = last
+		}
+	}
+	due = latest(d.DueAge, d.DueInterval)
+	if due.Before(earliest) {
+		due = earliest
+	}
+	return earliest, due, latest(d.OverdueAge, d.OverdueInterval)
+}
+
+// isVaccine reports whether the vaccine code cc is one of vaccines, sharing
+// a coding with one of them. Systems are compared as by
+// fhirtypes.NormalizeSystem, so that CVX codes may be in urn:oid: form.
+func isVaccine(cc *d4pb.CodeableConcept, vaccines []*d4pb.CodeableConcept) bool {
+	for _, c := range cc.GetCoding() {
+		system := fhirtypes.NormalizeSystem(c.GetSystem().GetValue())
+		for _, v := range vaccines {
+			for _, vc := range v.GetCoding() {
+				if c.GetCode().GetValue() != "" && vc.GetCode().GetValue() == c.GetCode().GetValue() &&
+					fhirtypes.NormalizeSystem(vc.GetSystem().GetValue()) == system {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func status(code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(ForecastStatusSystem, code)}}
+}
+
+func criterion(code string, t time.Time) *irpb.ImmunizationRecommendation_Recommendation_DateCriterion {
+	return &irpb.ImmunizationRecommendation_Recommendation_DateCriterion{
+		Code:  &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(loinc, code)}},
+		Value: fhirtypes.DateTimeFromTime(t, d4pb.DateTime_DAY),
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package immunization
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	impb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/immunization_go_proto"
+	irpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/immunization_recommendation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	pdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/plan_definition_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+const cvx = "http://hl7.org/fhir/sid/cvx"
+
+func concept(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(system, code)}}
+}
+
+func age(value, unit string) *d4pb.Age {
+	return &d4pb.Age{Value: fhirtypes.Decimal(value), Code: fhirtypes.Code(unit)}
+}
+
+func quantity(value, unit string) *d4pb.SimpleQuantity {
+	return &d4pb.SimpleQuantity{Value: fhirtypes.Decimal(value), Code: fhirtypes.Code(unit)}
+}
+
+func after(id string, offset *pdpb.PlanDefinition_Action_RelatedAction_OffsetX) *pdpb.PlanDefinition_Action_RelatedAction {
+	return &pdpb.PlanDefinition_Action_RelatedAction{
+		ActionId:     fhirtypes.ID(id),
+		Relationship: &pdpb.PlanDefinition_Action_RelatedAction_RelationshipCode{Value: c4pb.ActionRelationshipTypeCode_AFTER_END},
+		Offset:       offset,
+	}
+}
+
+// hepB is a three dose hepatitis B series: at birth, at 1 to 2 months and 4
+// weeks after the first dose, and at 6 to 18 months and 8 weeks after the
+// second dose.
+func hepB() *pdpb.PlanDefinition {
+	return &pdpb.PlanDefinition{
+		Id:    fhirtypes.ID("hepb"),
+		Title: fhirtypes.String("HepB"),
+		Goal: []*pdpb.PlanDefinition_Goal{{
+			Addresses: []*d4pb.CodeableConcept{concept("http://snomed.info/sct", "66071002")},
+		}},
+		Action: []*pdpb.PlanDefinition_Action{{
+			Id:   fhirtypes.String("dose1"),
+			Code: []*d4pb.CodeableConcept{concept(cvx, "08")},
+			Timing: &pdpb.PlanDefinition_Action_TimingX{
+				Choice: &pdpb.PlanDefinition_Action_TimingX_Age{Age: age("0", "d")},
+			},
+		}, {
+			Id: fhirtypes.String("dose2"),
+			Timing: &pdpb.PlanDefinition_Action_TimingX{
+				Choice: &pdpb.PlanDefinition_Action_TimingX_Range{Range: &d4pb.Range{Low: quantity("1", "mo"), High: quantity("3", "mo")}},
+			},
+			RelatedAction: []*pdpb.PlanDefinition_Action_RelatedAction{after("dose1", &pdpb.PlanDefinition_Action_RelatedAction_OffsetX{
+				Choice: &pdpb.PlanDefinition_Action_RelatedAction_OffsetX_Duration{Duration: &d4pb.Duration{Value: fhirtypes.Decimal("4"), Code: fhirtypes.Code("wk")}},
+			})},
+		}, {
+			Id: fhirtypes.String("dose3"),
+			Timing: &pdpb.PlanDefinition_Action_TimingX{
+				Choice: &pdpb.PlanDefinition_Action_TimingX_Range{Range: &d4pb.Range{Low: quantity("6", "mo"), High: quantity("19", "mo")}},
+			},
+			RelatedAction: []*pdpb.PlanDefinition_Action_RelatedAction{after("dose2", &pdpb.PlanDefinition_Action_RelatedAction_OffsetX{
+				Choice: &pdpb.PlanDefinition_Action_RelatedAction_OffsetX_Range{Range: &d4pb.Range{Low: quantity("8", "wk")}},
+			})},
+		}},
+	}
+}
+
+func TestSeriesFromPlanDefinition(t *testing.T) {
+	got, err := SeriesFromPlanDefinition(hepB())
+	if err != nil {
+		t.Fatalf("SeriesFromPlanDefinition() returned unexpected error: %v", err)
+	}
+	vaccines := []*d4pb.CodeableConcept{concept(cvx, "08")}
+	want := &Series{
+		Name:          "HepB",
+		TargetDisease: concept("http://snomed.info/sct", "66071002"),
+		Doses: []Dose{
+			{Vaccines: vaccines, MinAge: Offset{0, "d"}, DueAge: Offset{0, "d"}},
+			{Vaccines: vaccines, MinAge: Offset{1, "mo"}, DueAge: Offset{1, "mo"}, OverdueAge: Offset{3, "mo"}, MinInterval: Offset{4, "wk"}, DueInterval: Offset{4, "wk"}},
+			{Vaccines: vaccines, MinAge: Offset{6, "mo"}, DueAge: Offset{6, "mo"}, OverdueAge: Offset{19, "mo"}, MinInterval: Offset{8, "wk"}, DueInterval: Offset{8, "wk"}},
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("SeriesFromPlanDefinition() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSeriesFromPlanDefinitionErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*pdpb.PlanDefinition)
+	}{
+		{"no actions", func(pd *pdpb.PlanDefinition) { pd.Action = nil }},
+		{"no vaccines", func(pd *pdpb.PlanDefinition) { pd.Action[0].Code = nil }},
+		{"fractional age", func(pd *pdpb.PlanDefinition) { pd.Action[0].GetTiming().GetAge().Value = fhirtypes.Decimal("1.5") }},
+		{"unit", func(pd *pdpb.PlanDefinition) { pd.Action[0].GetTiming().GetAge().Code = fhirtypes.Code("h") }},
+		{"related action", func(pd *pdpb.PlanDefinition) { pd.Action[2].GetRelatedAction()[0].ActionId = fhirtypes.ID("dose1") }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pd := hepB()
+			tc.modify(pd)
+			if _, err := SeriesFromPlanDefinition(pd); err == nil {
+				t.Errorf("SeriesFromPlanDefinition() succeeded, want error")
+			}
+		})
+	}
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func given(id, code, on string) *impb.Immunization {
+	return &impb.Immunization{
+		Id:          fhirtypes.ID(id),
+		Status:      &impb.Immunization_StatusCode{Value: vspb.ImmunizationStatusCodesValueSet_COMPLETED},
+		VaccineCode: concept(cvx, code),
+		Occurrence: &impb.Immunization_OccurrenceX{
+			Choice: &impb.Immunization_OccurrenceX_DateTime{DateTime: fhirtypes.DateTimeFromTime(date(on), d4pb.DateTime_DAY)},
+		},
+	}
+}
+
+// forecast summarizes a recommendation.
+type forecast struct {
+	Status     string
+	DoseNumber uint32
+	Dates      map[string]string
+	Supporting []string
+}
+
+func summarize(rec *irpb.ImmunizationRecommendation_Recommendation) forecast {
+	f := forecast{
+		Status:     rec.GetForecastStatus().GetCoding()[0].GetCode().GetValue(),
+		DoseNumber: rec.GetDoseNumber().GetPositiveInt().GetValue(),
+	}
+	for _, c := range rec.GetDateCriterion() {
+		if f.Dates == nil {
+			f.Dates = map[string]string{}
+		}
+		t, _ := fhirtypes.DateTimeToTime(c.GetValue())
+		f.Dates[c.GetCode().GetCoding()[0].GetCode().GetValue()] = t.Format("2006-01-02")
+	}
+	for _, r := range rec.GetSupportingImmunization() {
+		f.Supporting = append(f.Supporting, fhirtypes.ReferenceURI(r))
+	}
+	return f
+}
+
+func TestEvaluate(t *testing.T) {
+	series, err := SeriesFromPlanDefinition(hepB())
+	if err != nil {
+		t.Fatalf("SeriesFromPlanDefinition() returned unexpected error: %v", err)
+	}
+	patient := &ppb.Patient{Id: fhirtypes.ID("p1"), BirthDate: fhirtypes.DateFromTime(date("2024-01-01"), d4pb.Date_DAY)}
+	voided := given("void", "08", "2024-02-01")
+	voided.Status.Value = vspb.ImmunizationStatusCodesValueSet_ENTERED_IN_ERROR
+	tests := []struct {
+		name    string
+		history []*impb.Immunization
+		opts    Options
+		want    forecast
+	}{{
+		name: "first dose",
+		opts: Options{Date: date("2024-01-10")},
+		want: forecast{Status: StatusDue, DoseNumber: 1, Dates: map[string]string{EarliestDate: "2024-01-01", DueDate: "2024-01-01"}},
+	}, {
+		name:    "second dose due",
+		history: []*impb.Immunization{given("i1", "08", "2024-01-01")},
+		opts:    Options{Date: date("2024-03-01")},
+		want: forecast{
+			Status: StatusDue, DoseNumber: 2,
+			Dates:      map[string]string{EarliestDate: "2024-02-01", DueDate: "2024-02-01", OverdueDate: "2024-04-01"},
+			Supporting: []string{"Immunization/i1"},
+		},
+	}, {
+		name:    "second dose overdue",
+		history: []*impb.Immunization{given("i1", "08", "2024-01-01")},
+		opts:    Options{Date: date("2024-04-01")},
+		want: forecast{
+			Status: StatusOverdue, DoseNumber: 2,
+			Dates:      map[string]string{EarliestDate: "2024-02-01", DueDate: "2024-02-01", OverdueDate: "2024-04-01"},
+			Supporting: []string{"Immunization/i1"},
+		},
+	}, {
+		name:    "interval",
+		history: []*impb.Immunization{given("i1", "08", "2024-01-20")},
+		opts:    Options{Date: date("2024-02-01")},
+		want: forecast{
+			Status: StatusDue, DoseNumber: 2,
+			Dates:      map[string]string{EarliestDate: "2024-02-17", DueDate: "2024-02-17", OverdueDate: "2024-04-01"},
+			Supporting: []string{"Immunization/i1"},
+		},
+	}, {
+		name:    "too early",
+		history: []*impb.Immunization{given("i1", "08", "2024-01-01"), given("i2", "08", "2024-01-29")},
+		opts:    Options{Date: date("2024-03-01")},
+		want: forecast{
+			Status: StatusDue, DoseNumber: 2,
+			Dates:      map[string]string{EarliestDate: "2024-02-01", DueDate: "2024-02-01", OverdueDate: "2024-04-01"},
+			Supporting: []string{"Immunization/i1"},
+		},
+	}, {
+		name:    "grace period",
+		history: []*impb.Immunization{given("i1", "08", "2024-01-01"), given("i2", "08", "2024-01-29")},
+		opts:    Options{Date: date("2024-03-01"), GraceDays: 4},
+		want: forecast{
+			Status: StatusDue, DoseNumber: 3,
+			Dates:      map[string]string{EarliestDate: "2024-07-01", DueDate: "2024-07-01", OverdueDate: "2025-08-01"},
+			Supporting: []string{"Immunization/i1", "Immunization/i2"},
+		},
+	}, {
+		name:    "other vaccines and voided",
+		history: []*impb.Immunization{given("i1", "08", "2024-01-01"), given("i2", "20", "2024-02-01"), voided},
+		opts:    Options{Date: date("2024-03-01")},
+		want: forecast{
+			Status: StatusDue, DoseNumber: 2,
+			Dates:      map[string]string{EarliestDate: "2024-02-01", DueDate: "2024-02-01", OverdueDate: "2024-04-01"},
+			Supporting: []string{"Immunization/i1"},
+		},
+	}, {
+		name: "complete",
+		history: []*impb.Immunization{
+			given("i3", "08", "2024-07-01"), given("i1", "08", "2024-01-01"), given("i2", "08", "2024-02-01"),
+		},
+		opts: Options{Date: date("2024-08-01")},
+		want: forecast{Status: StatusComplete, Supporting: []string{"Immunization/i1", "Immunization/i2", "Immunization/i3"}},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Evaluate(patient, tc.history, []*Series{series}, tc.opts)
+			if err != nil {
+				t.Fatalf("Evaluate() returned unexpected error: %v", err)
+			}
+			if ref := fhirtypes.ReferenceURI(got.GetPatient()); ref != "Patient/p1" {
+				t.Errorf("Evaluate() patient = %q, want %q", ref, "Patient/p1")
+			}
+			if n := len(got.GetRecommendation()); n != 1 {
+				t.Fatalf("Evaluate() returned %d recommendations, want 1", n)
+			}
+			rec := got.GetRecommendation()[0]
+			if rec.GetSeries().GetValue() != "HepB" || rec.GetSeriesDoses().GetPositiveInt().GetValue() != 3 {
+				t.Errorf("Evaluate() series = %q of %d doses, want %q of 3", rec.GetSeries().GetValue(), rec.GetSeriesDoses().GetPositiveInt().GetValue(), "HepB")
+			}
+			if diff := cmp.Diff(tc.want, summarize(rec)); diff != "" {
+				t.Errorf("Evaluate() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEvaluateNoBirthDate(t *testing.T) {
+	if _, err := Evaluate(&ppb.Patient{}, nil, nil, Options{}); !errors.Is(err, ErrNoBirthDate) {
+		t.Errorf("Evaluate() returned error %v, want %v", err, ErrNoBirthDate)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interaction
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/conceptmap"
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	aipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/allergy_intolerance_go_proto"
+	dipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/detected_issue_go_proto"
+	medpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	spb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/substance_go_proto"
+)
+
+const (
+	clinicalStatusSystem     = "http://terminology.hl7.org/CodeSystem/allergyintolerance-clinical"
+	verificationStatusSystem = "http://terminology.hl7.org/CodeSystem/allergyintolerance-verification"
+	actCodeSystem            = "http://terminology.hl7.org/CodeSystem/v3-ActCode"
+)
+
+// AllergyChecker cross-references the substances of AllergyIntolerances
+// against the ingredients of MedicationRequests. Ingredients and substances
+// are compared by code, and by the ingredient classes the configured
+// ConceptMaps translate them to, so that an allergy to penicillins matches a
+// request for amoxicillin, and an allergy to amoxicillin flags a request for
+// ampicillin as a possible cross-sensitivity.
+type AllergyChecker struct {
+	translator *conceptmap.Translator
+	classMaps  []string
+	resolver   fhirpath.Resolver
+}
+
+// NewAllergyChecker returns an AllergyChecker translating codes to ingredient
+// classes with the ConceptMaps of t identified by classMaps. t may be nil if
+// there are no classMaps. resolver, which may be nil, resolves the Medication
+// and Substance references of requests.
+func NewAllergyChecker(t *conceptmap.Translator, resolver fhirpath.Resolver, classMaps ...string) (*AllergyChecker, error) {
+	for _, url := range classMaps {
+		if t == nil || !t.Has(url) {
+			return nil, fmt.Errorf("unknown ConceptMap %q", url)
+		}
+	}
+	return &AllergyChecker{translator: t, classMaps: classMaps, resolver: resolver}, nil
+}
+
+// Check returns a DetectedIssue for every request having an ingredient the
+// patient is allergic or possibly cross-sensitive to. Allergies that are
+// inactive, resolved, refuted or entered in error are ignored. The issues are
+// final, coded as drug allergy alerts, and implicate the request and the
+// allergy; those for allergies of high criticality have high severity.
+func (c *AllergyChecker) Check(allergies []*aipb.AllergyIntolerance, requests ...*mrpb.MedicationRequest) ([]*dipb.DetectedIssue, error) {
+	type allergen struct {
+		allergy *aipb.AllergyIntolerance
+		codes   map[string]string
+		classes map[string]string
+	}
+	var allergens []allergen
+	for _, ai := range allergies {
+		if !relevant(ai) {
+			continue
+		}
+		codes := map[string]string{}
+		addCodes(codes, ai.GetCode())
+		for _, r := range ai.GetReaction() {
+			addCodes(codes, r.GetSubstance())
+		}
+		classes, err := c.classes(codes)
+		if err != nil {
+			return nil, err
+		}
+		allergens = append(allergens, allergen{ai, codes, classes})
+	}
+	var out []*dipb.DetectedIssue
+	for _, mr := range requests {
+		codes, err := c.ingredients(mr)
+		if err != nil {
+			return nil, fmt.Errorf("MedicationRequest %s: %w", mr.GetId().GetValue(), err)
+		}
+		classes, err := c.classes(codes)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range classes {
+			codes[k] = v
+		}
+		for _, a := range allergens {
+			if match := intersect(a.codes, codes); match != "" {
+				out = append(out, issue(mr, a.allergy, false, fmt.Sprintf(
+					"%s contains %s, to which the patient is allergic", medicationName(mr), match)))
+			} else if match := intersect(a.classes, classes); match != "" {
+				out = append(out, issue(mr, a.allergy, true, fmt.Sprintf(
+					"Patient is allergic to %s and %s contains an ingredient of the same class, %s",
+					display(a.codes), medicationName(mr), match)))
+			}
+		}
+	}
+	return out, nil
+}
+
+// relevant reports whether ai should be checked, based on its clinical and
+// verification statuses.
+func relevant(ai *aipb.AllergyIntolerance) bool {
+	for _, c := range ai.GetClinicalStatus().GetCoding() {
+		if c.GetSystem().GetValue() == clinicalStatusSystem && c.GetCode().GetValue() != "active" {
+			return false
+		}
+	}
+	for _, c := range ai.GetVerificationStatus().GetCoding() {
+		if c.GetSystem().GetValue() != verificationStatusSystem {
+			continue
+		}
+		switch c.GetCode().GetValue() {
+		case "refuted", "entered-in-error":
+			return false
+		}
+	}
+	return true
+}
+
+// ingredients returns the codes of the medication of mr and its ingredients,
+// keyed by "system|code" and mapped to their displays.
+func (c *AllergyChecker) ingredients(mr *mrpb.MedicationRequest) (map[string]string, error) {
+	codes := map[string]string{}
+	switch m := mr.GetMedication().GetChoice().(type) {
+	case *mrpb.MedicationRequest_MedicationX_CodeableConcept:
+		addCodes(codes, m.CodeableConcept)
+	case *mrpb.MedicationRequest_MedicationX_Reference:
+		if err := c.addReferenced(codes, m.Reference, 0); err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// maxIngredientDepth bounds the nesting of Medications through their
+// ingredients.
+const maxIngredientDepth = 4
+
+// addReferenced adds the codes of the Medication or Substance ref resolves
+// to, including those of the ingredients of a Medication. References that do
+// not resolve are ignored.
+func (c *AllergyChecker) addReferenced(codes map[string]string, ref *d4pb.Reference, depth int) error {
+	if c.resolver == nil || depth > maxIngredientDepth {
+		return nil
+	}
+	den, err := jsonformat.NewDenormalizedReference(ref)
+	if err != nil {
+		return err
+	}
+	s := den.(*d4pb.Reference).GetUri().GetValue()
+	if s == "" {
+		return nil
+	}
+	res, err := c.resolver(s)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", s, err)
+	}
+	if res == nil {
+		return nil
+	}
+	switch r := elementpath.Unwrap(res).(type) {
+	case *medpb.Medication:
+		addCodes(codes, r.GetCode())
+		for _, in := range r.GetIngredient() {
+			switch item := in.GetItem().GetChoice().(type) {
+			case *medpb.Medication_Ingredient_ItemX_CodeableConcept:
+				addCodes(codes, item.CodeableConcept)
+			case *medpb.Medication_Ingredient_ItemX_Reference:
+				if err := c.addReferenced(codes, item.Reference, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+	case *spb.Substance:
+		addCodes(codes, r.GetCode())
+	}
+	return nil
+}
+
+// classes returns the translations of codes by the class ConceptMaps.
+func (c *AllergyChecker) classes(codes map[string]string) (map[string]string, error) {
+	out := map[string]string{}
+	for key := range codes {
+		system, code, _ := strings.Cut(key, "|")
+		for _, url := range c.classMaps {
+			if !c.translator.HasSource(url, system) {
+				continue
+			}
+			matches, err := c.translator.Translate(url, system, code)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range matches {
+				out[m.System+"|"+m.Code] = m.Display
+			}
+		}
+	}
+	return out, nil
+}
+
+func addCodes(codes map[string]string, cc *d4pb.CodeableConcept) {
+	for _, c := range cc.GetCoding() {
+		if c.GetCode().GetValue() == "" {
+			continue
+		}
+		codes[c.GetSystem().GetValue()+"|"+c.GetCode().GetValue()] = c.GetDisplay().GetValue()
+	}
+}
+
+// intersect returns the display, or the code if there is none, of the first
+// key, in sorted order, that is in both a and b, or an empty string if there
+// is none.
+func intersect(a, b map[string]string) string {
+	var keys []string
+	for k := range a {
+		if _, ok := b[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	if d := a[keys[0]]; d != "" {
+		return d
+	}
+	_, code, _ := strings.Cut(keys[0], "|")
+	return code
+}
+
+// display returns a name for the codes, preferring displays.
+func display(codes map[string]string) string {
+	return intersect(codes, codes)
+}
+
+func medicationName(mr *mrpb.MedicationRequest) string {
+	if cc := mr.GetMedication().GetCodeableConcept(); cc != nil {
+		if t := cc.GetText().GetValue(); t != "" {
+			return t
+		}
+		for _, c := range cc.GetCoding() {
+			if d := c.GetDisplay().GetValue(); d != "" {
+				return d
+			}
+		}
+	}
+	if d := mr.GetMedication().GetReference().GetDisplay().GetValue(); d != "" {
+		return d
+	}
+	return "the requested medication"
+}
+
+func issue(mr *mrpb.MedicationRequest, ai *aipb.AllergyIntolerance, crossSensitivity bool, detail string) *dipb.DetectedIssue {
+	severity := c4pb.DetectedIssueSeverityCode_MODERATE
+	high := ai.GetCriticality().GetValue() == c4pb.AllergyIntoleranceCriticalityCode_HIGH
+	switch {
+	case high && !crossSensitivity:
+		severity = c4pb.DetectedIssueSeverityCode_HIGH
+	case !high && crossSensitivity:
+		severity = c4pb.DetectedIssueSeverityCode_LOW
+	}
+	di := &dipb.DetectedIssue{
+		Status: &dipb.DetectedIssue_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System:  &d4pb.Uri{Value: actCodeSystem},
+			Code:    &d4pb.Code{Value: "DALG"},
+			Display: &d4pb.String{Value: "Drug Allergy"},
+		}}},
+		Severity: &dipb.DetectedIssue_SeverityCode{Value: severity},
+		Detail:   &d4pb.String{Value: detail},
+	}
+	if p := mr.GetSubject(); p != nil {
+		di.Patient = proto.Clone(p).(*d4pb.Reference)
+	} else if p := ai.GetPatient(); p != nil {
+		di.Patient = proto.Clone(p).(*d4pb.Reference)
+	}
+	if id := mr.GetId().GetValue(); id != "" {
+		di.Implicated = append(di.Implicated, &d4pb.Reference{
+			Reference: &d4pb.Reference_MedicationRequestId{MedicationRequestId: &d4pb.ReferenceId{Value: id}},
+		})
+	}
+	if id := ai.GetId().GetValue(); id != "" {
+		di.Implicated = append(di.Implicated, &d4pb.Reference{
+			Reference: &d4pb.Reference_AllergyIntoleranceId{AllergyIntoleranceId: &d4pb.ReferenceId{Value: id}},
+		})
+	}
+	return di
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interaction
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/conceptmap"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	aipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/allergy_intolerance_go_proto"
+	cmpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/concept_map_go_proto"
+	dipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/detected_issue_go_proto"
+	medpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+)
+
+const (
+	classSystem = "http://example.com/ingredient-class"
+	classMap    = "http://example.com/ConceptMap/ingredient-class"
+	amoxicillin = "723"
+	ampicillin  = "733"
+	ibuprofen   = "5640"
+)
+
+func classTranslator(t *testing.T) *conceptmap.Translator {
+	t.Helper()
+	element := func(code string) *cmpb.ConceptMap_Group_SourceElement {
+		return &cmpb.ConceptMap_Group_SourceElement{
+			Code: &d4pb.Code{Value: code},
+			Target: []*cmpb.ConceptMap_Group_SourceElement_TargetElement{{
+				Code:        &d4pb.Code{Value: "penicillins"},
+				Display:     &d4pb.String{Value: "Penicillins"},
+				Equivalence: &cmpb.ConceptMap_Group_SourceElement_TargetElement_EquivalenceCode{Value: c4pb.ConceptMapEquivalenceCode_SPECIALIZES},
+			}},
+		}
+	}
+	tr, err := conceptmap.NewTranslator(&cmpb.ConceptMap{
+		Url: &d4pb.Uri{Value: classMap},
+		Group: []*cmpb.ConceptMap_Group{{
+			Source:  &d4pb.Uri{Value: RxNormSystem},
+			Target:  &d4pb.Uri{Value: classSystem},
+			Element: []*cmpb.ConceptMap_Group_SourceElement{element(amoxicillin), element(ampicillin)},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewTranslator() returned unexpected error: %v", err)
+	}
+	return tr
+}
+
+func allergy(id string, cc *d4pb.CodeableConcept, criticality c4pb.AllergyIntoleranceCriticalityCode_Value) *aipb.AllergyIntolerance {
+	return &aipb.AllergyIntolerance{
+		Id:          &d4pb.Id{Value: id},
+		Code:        cc,
+		Criticality: &aipb.AllergyIntolerance_CriticalityCode{Value: criticality},
+		Patient:     &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+	}
+}
+
+func TestAllergyChecker(t *testing.T) {
+	draft := c4pb.MedicationrequestStatusCode_DRAFT
+	high := c4pb.AllergyIntoleranceCriticalityCode_HIGH
+	low := c4pb.AllergyIntoleranceCriticalityCode_LOW
+	type result struct {
+		Request, Allergy string
+		Severity         c4pb.DetectedIssueSeverityCode_Value
+	}
+	tests := []struct {
+		name      string
+		allergies []*aipb.AllergyIntolerance
+		requests  []*mrpb.MedicationRequest
+		want      []result
+	}{
+		{
+			name:      "same ingredient",
+			allergies: []*aipb.AllergyIntolerance{allergy("a1", concept(RxNormSystem, amoxicillin), high)},
+			requests:  []*mrpb.MedicationRequest{request("mr1", draft, concept(RxNormSystem, amoxicillin))},
+			want:      []result{{"mr1", "a1", c4pb.DetectedIssueSeverityCode_HIGH}},
+		},
+		{
+			name:      "allergy to class",
+			allergies: []*aipb.AllergyIntolerance{allergy("a1", concept(classSystem, "penicillins"), low)},
+			requests:  []*mrpb.MedicationRequest{request("mr1", draft, concept(RxNormSystem, ampicillin))},
+			want:      []result{{"mr1", "a1", c4pb.DetectedIssueSeverityCode_MODERATE}},
+		},
+		{
+			name:      "cross-sensitivity",
+			allergies: []*aipb.AllergyIntolerance{allergy("a1", concept(RxNormSystem, amoxicillin), low)},
+			requests:  []*mrpb.MedicationRequest{request("mr1", draft, concept(RxNormSystem, ampicillin))},
+			want:      []result{{"mr1", "a1", c4pb.DetectedIssueSeverityCode_LOW}},
+		},
+		{
+			name:      "unrelated",
+			allergies: []*aipb.AllergyIntolerance{allergy("a1", concept(RxNormSystem, amoxicillin), high)},
+			requests:  []*mrpb.MedicationRequest{request("mr1", draft, concept(RxNormSystem, ibuprofen))},
+		},
+		{
+			name: "refuted",
+			allergies: []*aipb.AllergyIntolerance{func() *aipb.AllergyIntolerance {
+				a := allergy("a1", concept(RxNormSystem, amoxicillin), high)
+				a.VerificationStatus = concept(verificationStatusSystem, "refuted")
+				return a
+			}()},
+			requests: []*mrpb.MedicationRequest{request("mr1", draft, concept(RxNormSystem, amoxicillin))},
+		},
+		{
+			name:      "ingredient of referenced medication",
+			allergies: []*aipb.AllergyIntolerance{allergy("a1", concept(RxNormSystem, amoxicillin), high)},
+			requests: []*mrpb.MedicationRequest{{
+				Id: &d4pb.Id{Value: "mr1"},
+				Medication: &mrpb.MedicationRequest_MedicationX{
+					Choice: &mrpb.MedicationRequest_MedicationX_Reference{Reference: &d4pb.Reference{
+						Reference: &d4pb.Reference_MedicationId{MedicationId: &d4pb.ReferenceId{Value: "augmentin"}},
+					}},
+				},
+			}},
+			want: []result{{"mr1", "a1", c4pb.DetectedIssueSeverityCode_HIGH}},
+		},
+	}
+	augmentin := &medpb.Medication{
+		Id: &d4pb.Id{Value: "augmentin"},
+		Ingredient: []*medpb.Medication_Ingredient{{
+			Item: &medpb.Medication_Ingredient_ItemX{
+				Choice: &medpb.Medication_Ingredient_ItemX_CodeableConcept{CodeableConcept: concept(RxNormSystem, amoxicillin)},
+			},
+		}},
+	}
+	resolver := func(ref string) (proto.Message, error) {
+		if ref == "Medication/augmentin" {
+			return augmentin, nil
+		}
+		return nil, nil
+	}
+	c, err := NewAllergyChecker(classTranslator(t), resolver, classMap)
+	if err != nil {
+		t.Fatalf("NewAllergyChecker() returned unexpected error: %v", err)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issues, err := c.Check(test.allergies, test.requests...)
+			if err != nil {
+				t.Fatalf("Check() returned unexpected error: %v", err)
+			}
+			var got []result
+			for _, di := range issues {
+				got = append(got, result{
+					Request:  di.GetImplicated()[0].GetMedicationRequestId().GetValue(),
+					Allergy:  di.GetImplicated()[1].GetAllergyIntoleranceId().GetValue(),
+					Severity: di.GetSeverity().GetValue(),
+				})
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Check() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAllergyChecker_DetectedIssue(t *testing.T) {
+	c, err := NewAllergyChecker(nil, nil)
+	if err != nil {
+		t.Fatalf("NewAllergyChecker() returned unexpected error: %v", err)
+	}
+	mr := request("mr1", c4pb.MedicationrequestStatusCode_DRAFT, concept(RxNormSystem, amoxicillin))
+	mr.Medication.GetCodeableConcept().Text = &d4pb.String{Value: "Amoxicillin 500 mg"}
+	got, err := c.Check([]*aipb.AllergyIntolerance{allergy("a1", concept(RxNormSystem, amoxicillin), c4pb.AllergyIntoleranceCriticalityCode_HIGH)}, mr)
+	if err != nil {
+		t.Fatalf("Check() returned unexpected error: %v", err)
+	}
+	want := []*dipb.DetectedIssue{{
+		Status: &dipb.DetectedIssue_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System:  &d4pb.Uri{Value: actCodeSystem},
+			Code:    &d4pb.Code{Value: "DALG"},
+			Display: &d4pb.String{Value: "Drug Allergy"},
+		}}},
+		Severity: &dipb.DetectedIssue_SeverityCode{Value: c4pb.DetectedIssueSeverityCode_HIGH},
+		Patient:  &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Implicated: []*d4pb.Reference{
+			{Reference: &d4pb.Reference_MedicationRequestId{MedicationRequestId: &d4pb.ReferenceId{Value: "mr1"}}},
+			{Reference: &d4pb.Reference_AllergyIntoleranceId{AllergyIntoleranceId: &d4pb.ReferenceId{Value: "a1"}}},
+		},
+		Detail: &d4pb.String{Value: "Amoxicillin 500 mg contains " + amoxicillin + ", to which the patient is allergic"},
+	}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Check() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewAllergyChecker_UnknownMap(t *testing.T) {
+	if _, err := NewAllergyChecker(classTranslator(t), nil, "http://example.com/ConceptMap/unknown"); err == nil {
+		t.Errorf("NewAllergyChecker() succeeded, want error")
+	}
+}
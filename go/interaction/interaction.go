@@ -0,0 +1,282 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package interaction checks the medications of a patient for drug-drug
+// interactions described by R4 MedicinalProductInteraction resources, and
+// proposed medications for allergies recorded as AllergyIntolerances.
+//
+// For drug-drug interactions, medications and interactants are matched by
+// their RxNorm and ATC codes, or by reference. ATC codes match
+// hierarchically: an interactant coded with an ATC class, i.e. C09AA for ACE
+// inhibitors, matches every medication coded with a substance of that class,
+// i.e. C09AA05 for ramipril.
+package interaction
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/graph"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	medpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	mspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_statement_go_proto"
+	mpipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medicinal_product_interaction_go_proto"
+	spb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/substance_go_proto"
+)
+
+// Code systems used to match medications.
+const (
+	RxNormSystem = "http://www.nlm.nih.gov/research/umls/rxnorm"
+	ATCSystem    = "http://www.whocc.no/atc"
+)
+
+// KnowledgeBase holds the interactions checked by Check.
+type KnowledgeBase struct {
+	interactions []*entry
+	resolver     fhirpath.Resolver
+}
+
+// entry is an interaction with the keys matching each of its participants:
+// the subject, if any, and the interactants.
+type entry struct {
+	def          *mpipb.MedicinalProductInteraction
+	participants [][]string
+}
+
+// Load returns a KnowledgeBase of the given interactions. resolver, which may
+// be nil, resolves the Medication and Substance references of subjects and
+// interactants, and later of checked medications, so that they can be
+// matched by code.
+func Load(resolver fhirpath.Resolver, interactions ...*mpipb.MedicinalProductInteraction) (*KnowledgeBase, error) {
+	kb := &KnowledgeBase{resolver: resolver}
+	for _, mpi := range interactions {
+		e := &entry{def: mpi}
+		if len(mpi.GetSubject()) > 0 {
+			var keys []string
+			for _, ref := range mpi.GetSubject() {
+				k, err := kb.referenceKeys(ref)
+				if err != nil {
+					return nil, fmt.Errorf("interaction %s: %w", mpi.GetId().GetValue(), err)
+				}
+				keys = append(keys, k...)
+			}
+			e.participants = append(e.participants, keys)
+		}
+		for _, in := range mpi.GetInteractant() {
+			var keys []string
+			if ref := in.GetItem().GetReference(); ref != nil {
+				k, err := kb.referenceKeys(ref)
+				if err != nil {
+					return nil, fmt.Errorf("interaction %s: %w", mpi.GetId().GetValue(), err)
+				}
+				keys = k
+			} else {
+				keys = codeKeys(in.GetItem().GetCodeableConcept())
+			}
+			e.participants = append(e.participants, keys)
+		}
+		if len(e.participants) < 2 {
+			// Interactions with food, conditions and the like do not concern
+			// pairs of medications.
+			continue
+		}
+		kb.interactions = append(kb.interactions, e)
+	}
+	return kb, nil
+}
+
+// LoadBundle returns a KnowledgeBase of the MedicinalProductInteractions in
+// b. Other resources of b serve to resolve references.
+func LoadBundle(b *r4pb.Bundle) (*KnowledgeBase, error) {
+	var interactions []*mpipb.MedicinalProductInteraction
+	for _, e := range b.GetEntry() {
+		if mpi := e.GetResource().GetMedicinalProductInteraction(); mpi != nil {
+			interactions = append(interactions, mpi)
+		}
+	}
+	return Load(graph.BundleResolver(b), interactions...)
+}
+
+// Interaction is an interaction that applies to the checked medications.
+type Interaction struct {
+	// Definition is the MedicinalProductInteraction describing the
+	// interaction.
+	Definition *mpipb.MedicinalProductInteraction
+	// Medications are the interacting MedicationRequests and
+	// MedicationStatements.
+	Medications []proto.Message
+	// Description, Type, Effect, Incidence and Management are copied from the
+	// definition for convenience.
+	Description                         string
+	Type, Effect, Incidence, Management *d4pb.CodeableConcept
+}
+
+// Check returns the interactions between the active medications among meds,
+// which are MedicationRequests and MedicationStatements. An interaction
+// applies if each of its participants matches a medication and at least two
+// distinct medications are involved.
+func (kb *KnowledgeBase) Check(meds ...proto.Message) ([]*Interaction, error) {
+	type medication struct {
+		res  proto.Message
+		keys []string
+	}
+	var active []medication
+	for _, m := range meds {
+		m = elementpath.Unwrap(m)
+		var med proto.Message
+		switch x := m.(type) {
+		case *mrpb.MedicationRequest:
+			if x.GetStatus().GetValue() != c4pb.MedicationrequestStatusCode_ACTIVE {
+				continue
+			}
+			med = x.GetMedication()
+		case *mspb.MedicationStatement:
+			if x.GetStatus().GetValue() != c4pb.MedicationStatementStatusCodes_ACTIVE {
+				continue
+			}
+			med = x.GetMedication()
+		default:
+			return nil, fmt.Errorf("%s is not a MedicationRequest or MedicationStatement", elementpath.ResourceType(m))
+		}
+		keys, err := kb.medicationKeys(med)
+		if err != nil {
+			return nil, err
+		}
+		active = append(active, medication{m, keys})
+	}
+	var out []*Interaction
+	for _, e := range kb.interactions {
+		var involved []proto.Message
+		seen := map[int]bool{}
+		applies := true
+		for _, p := range e.participants {
+			matched := false
+			for i, med := range active {
+				if matchesAny(p, med.keys) {
+					matched = true
+					if !seen[i] {
+						seen[i] = true
+						involved = append(involved, med.res)
+					}
+				}
+			}
+			if !matched {
+				applies = false
+				break
+			}
+		}
+		if !applies || len(involved) < 2 {
+			continue
+		}
+		out = append(out, &Interaction{
+			Definition:  e.def,
+			Medications: involved,
+			Description: e.def.GetDescription().GetValue(),
+			Type:        e.def.GetType(),
+			Effect:      e.def.GetEffect(),
+			Incidence:   e.def.GetIncidence(),
+			Management:  e.def.GetManagement(),
+		})
+	}
+	return out, nil
+}
+
+// medicationKeys returns the keys of the medication[x] element of a request
+// or statement.
+func (kb *KnowledgeBase) medicationKeys(med proto.Message) ([]string, error) {
+	rm := med.ProtoReflect()
+	if !rm.IsValid() {
+		return nil, nil
+	}
+	set := rm.WhichOneof(rm.Descriptor().Oneofs().Get(0))
+	if set == nil {
+		return nil, nil
+	}
+	switch v := rm.Get(set).Message().Interface().(type) {
+	case *d4pb.CodeableConcept:
+		return codeKeys(v), nil
+	case *d4pb.Reference:
+		return kb.referenceKeys(v)
+	}
+	return nil, nil
+}
+
+// referenceKeys returns the keys of the resource ref refers to: the
+// reference itself, and the codes of the Medication or Substance it resolves
+// to, including those of their ingredients.
+func (kb *KnowledgeBase) referenceKeys(ref *d4pb.Reference) ([]string, error) {
+	den, err := jsonformat.NewDenormalizedReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	s := den.(*d4pb.Reference).GetUri().GetValue()
+	if s == "" {
+		return codeKeys(ref.GetIdentifier().GetType()), nil
+	}
+	keys := []string{s}
+	if kb.resolver == nil {
+		return keys, nil
+	}
+	res, err := kb.resolver(s)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", s, err)
+	}
+	if res == nil {
+		return keys, nil
+	}
+	switch r := elementpath.Unwrap(res).(type) {
+	case *medpb.Medication:
+		keys = append(keys, codeKeys(r.GetCode())...)
+		for _, in := range r.GetIngredient() {
+			keys = append(keys, codeKeys(in.GetItem().GetCodeableConcept())...)
+		}
+	case *spb.Substance:
+		keys = append(keys, codeKeys(r.GetCode())...)
+	}
+	return keys, nil
+}
+
+// codeKeys returns the keys of the RxNorm and ATC codings of cc.
+func codeKeys(cc *d4pb.CodeableConcept) []string {
+	var out []string
+	for _, c := range cc.GetCoding() {
+		switch system := c.GetSystem().GetValue(); system {
+		case RxNormSystem, ATCSystem:
+			out = append(out, system+"|"+c.GetCode().GetValue())
+		}
+	}
+	return out
+}
+
+// matchesAny reports whether one of the participant keys matches one of the
+// medication keys. ATC keys of the participant match medication keys for
+// codes they are a prefix of.
+func matchesAny(participant, medication []string) bool {
+	for _, p := range participant {
+		for _, m := range medication {
+			if p == m || strings.HasPrefix(p, ATCSystem+"|") && strings.HasPrefix(m, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
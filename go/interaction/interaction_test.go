@@ -0,0 +1,260 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interaction
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	medpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	mspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_statement_go_proto"
+	mpipb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medicinal_product_interaction_go_proto"
+)
+
+const (
+	warfarin  = "11289"
+	aspirin   = "1191"
+	ramipril  = "C09AA05"
+	lisinopil = "C09AA03"
+	spiro     = "C03DA01"
+)
+
+func concept(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+		System: &d4pb.Uri{Value: system},
+		Code:   &d4pb.Code{Value: code},
+	}}}
+}
+
+func interactant(cc *d4pb.CodeableConcept) *mpipb.MedicinalProductInteraction_Interactant {
+	return &mpipb.MedicinalProductInteraction_Interactant{
+		Item: &mpipb.MedicinalProductInteraction_Interactant_ItemX{
+			Choice: &mpipb.MedicinalProductInteraction_Interactant_ItemX_CodeableConcept{CodeableConcept: cc},
+		},
+	}
+}
+
+func request(id string, status c4pb.MedicationrequestStatusCode_Value, cc *d4pb.CodeableConcept) *mrpb.MedicationRequest {
+	return &mrpb.MedicationRequest{
+		Id:     &d4pb.Id{Value: id},
+		Status: &mrpb.MedicationRequest_StatusCode{Value: status},
+		Medication: &mrpb.MedicationRequest_MedicationX{
+			Choice: &mrpb.MedicationRequest_MedicationX_CodeableConcept{CodeableConcept: cc},
+		},
+	}
+}
+
+func statement(id string, cc *d4pb.CodeableConcept) *mspb.MedicationStatement {
+	return &mspb.MedicationStatement{
+		Id:     &d4pb.Id{Value: id},
+		Status: &mspb.MedicationStatement_StatusCode{Value: c4pb.MedicationStatementStatusCodes_ACTIVE},
+		Medication: &mspb.MedicationStatement_MedicationX{
+			Choice: &mspb.MedicationStatement_MedicationX_CodeableConcept{CodeableConcept: cc},
+		},
+	}
+}
+
+// knowledgeBase returns interactions between warfarin and aspirin, keyed by
+// RxNorm, and between ACE inhibitors and spironolactone, keyed by ATC.
+func knowledgeBase(t *testing.T) *KnowledgeBase {
+	t.Helper()
+	kb, err := Load(nil,
+		&mpipb.MedicinalProductInteraction{
+			Id:          &d4pb.Id{Value: "bleeding"},
+			Description: &d4pb.String{Value: "Increased risk of bleeding"},
+			Interactant: []*mpipb.MedicinalProductInteraction_Interactant{
+				interactant(concept(RxNormSystem, warfarin)),
+				interactant(concept(RxNormSystem, aspirin)),
+			},
+			Effect:     concept("http://example.com/effect", "bleeding"),
+			Incidence:  concept("http://example.com/incidence", "common"),
+			Management: concept("http://example.com/management", "monitor-inr"),
+		},
+		&mpipb.MedicinalProductInteraction{
+			Id: &d4pb.Id{Value: "hyperkalemia"},
+			Interactant: []*mpipb.MedicinalProductInteraction_Interactant{
+				interactant(concept(ATCSystem, "C09AA")),
+				interactant(concept(ATCSystem, spiro)),
+			},
+		},
+		&mpipb.MedicinalProductInteraction{
+			Id:          &d4pb.Id{Value: "food"},
+			Interactant: []*mpipb.MedicinalProductInteraction_Interactant{interactant(concept(RxNormSystem, warfarin))},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	return kb
+}
+
+func ids(in []*Interaction) map[string][]string {
+	out := map[string][]string{}
+	for _, i := range in {
+		var meds []string
+		for _, m := range i.Medications {
+			switch x := m.(type) {
+			case *mrpb.MedicationRequest:
+				meds = append(meds, x.GetId().GetValue())
+			case *mspb.MedicationStatement:
+				meds = append(meds, x.GetId().GetValue())
+			}
+		}
+		out[i.Definition.GetId().GetValue()] = meds
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	active := c4pb.MedicationrequestStatusCode_ACTIVE
+	tests := []struct {
+		name string
+		meds []proto.Message
+		want map[string][]string
+	}{
+		{
+			name: "rxnorm pair",
+			meds: []proto.Message{
+				request("mr1", active, concept(RxNormSystem, warfarin)),
+				statement("ms1", concept(RxNormSystem, aspirin)),
+			},
+			want: map[string][]string{"bleeding": {"mr1", "ms1"}},
+		},
+		{
+			name: "atc class",
+			meds: []proto.Message{
+				request("mr1", active, concept(ATCSystem, ramipril)),
+				request("mr2", active, concept(ATCSystem, spiro)),
+			},
+			want: map[string][]string{"hyperkalemia": {"mr1", "mr2"}},
+		},
+		{
+			name: "same class without partner",
+			meds: []proto.Message{
+				request("mr1", active, concept(ATCSystem, ramipril)),
+				request("mr2", active, concept(ATCSystem, lisinopil)),
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "inactive request",
+			meds: []proto.Message{
+				request("mr1", c4pb.MedicationrequestStatusCode_STOPPED, concept(RxNormSystem, warfarin)),
+				statement("ms1", concept(RxNormSystem, aspirin)),
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "single medication",
+			meds: []proto.Message{request("mr1", active, concept(RxNormSystem, warfarin))},
+			want: map[string][]string{},
+		},
+	}
+	kb := knowledgeBase(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := kb.Check(test.meds...)
+			if err != nil {
+				t.Fatalf("Check() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, ids(got)); diff != "" {
+				t.Errorf("Check() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheck_Details(t *testing.T) {
+	kb := knowledgeBase(t)
+	got, err := kb.Check(
+		request("mr1", c4pb.MedicationrequestStatusCode_ACTIVE, concept(RxNormSystem, warfarin)),
+		statement("ms1", concept(RxNormSystem, aspirin)),
+	)
+	if err != nil {
+		t.Fatalf("Check() returned unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Check() returned %d interactions, want 1", len(got))
+	}
+	if got[0].Description != "Increased risk of bleeding" {
+		t.Errorf("Check() description = %q, want %q", got[0].Description, "Increased risk of bleeding")
+	}
+	for name, cc := range map[string]*d4pb.CodeableConcept{"effect": got[0].Effect, "incidence": got[0].Incidence, "management": got[0].Management} {
+		if cc == nil {
+			t.Errorf("Check() %s is nil", name)
+		}
+	}
+}
+
+func TestLoadBundle(t *testing.T) {
+	// The interactant refers to a Medication whose ingredient is warfarin; the
+	// subject is aspirin.
+	med := &medpb.Medication{
+		Id: &d4pb.Id{Value: "coumadin"},
+		Ingredient: []*medpb.Medication_Ingredient{{
+			Item: &medpb.Medication_Ingredient_ItemX{
+				Choice: &medpb.Medication_Ingredient_ItemX_CodeableConcept{CodeableConcept: concept(RxNormSystem, warfarin)},
+			},
+		}},
+	}
+	mpi := &mpipb.MedicinalProductInteraction{
+		Id: &d4pb.Id{Value: "bleeding"},
+		Subject: []*d4pb.Reference{{
+			Reference: &d4pb.Reference_MedicationId{MedicationId: &d4pb.ReferenceId{Value: "aspirin"}},
+		}},
+		Interactant: []*mpipb.MedicinalProductInteraction_Interactant{{
+			Item: &mpipb.MedicinalProductInteraction_Interactant_ItemX{
+				Choice: &mpipb.MedicinalProductInteraction_Interactant_ItemX_Reference{Reference: &d4pb.Reference{
+					Reference: &d4pb.Reference_MedicationId{MedicationId: &d4pb.ReferenceId{Value: "coumadin"}},
+				}},
+			},
+		}},
+	}
+	kb, err := LoadBundle(&r4pb.Bundle{Entry: []*r4pb.Bundle_Entry{
+		{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Medication{Medication: med}}},
+		{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_MedicinalProductInteraction{MedicinalProductInteraction: mpi}}},
+	}})
+	if err != nil {
+		t.Fatalf("LoadBundle() returned unexpected error: %v", err)
+	}
+	aspirinRef := &mrpb.MedicationRequest{
+		Id:     &d4pb.Id{Value: "mr2"},
+		Status: &mrpb.MedicationRequest_StatusCode{Value: c4pb.MedicationrequestStatusCode_ACTIVE},
+		Medication: &mrpb.MedicationRequest_MedicationX{
+			Choice: &mrpb.MedicationRequest_MedicationX_Reference{Reference: &d4pb.Reference{
+				Reference: &d4pb.Reference_MedicationId{MedicationId: &d4pb.ReferenceId{Value: "aspirin"}},
+			}},
+		},
+	}
+	got, err := kb.Check(request("mr1", c4pb.MedicationrequestStatusCode_ACTIVE, concept(RxNormSystem, warfarin)), aspirinRef)
+	if err != nil {
+		t.Fatalf("Check() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string][]string{"bleeding": {"mr2", "mr1"}}, ids(got)); diff != "" {
+		t.Errorf("Check() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheck_Error(t *testing.T) {
+	if _, err := knowledgeBase(t).Check(&medpb.Medication{}); err == nil {
+		t.Errorf("Check() succeeded, want error")
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package elementpath resolves simple FHIR element paths against FHIR protos.
+//
+// A path is a period-delimited list of FHIR JSON element names starting with
+// the resource type, i.e. "Observation.code.coding". Choice elements can be
+// addressed either by their base name ("Observation.value"), which selects
+// whichever type is populated, or by their typed name
+// ("Observation.valueCodeableConcept"). Repeated elements are expanded, so a
+// path can resolve to any number of elements.
+package elementpath
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+)
+
+const containedResourceOneof = "oneof_resource"
+
+// VisitFunc is called for every element a path resolves to. elementPath is
+// the indexed location of the element, i.e. "Observation.code.coding[1]".
+type VisitFunc func(elementPath string, elem protoreflect.Message) error
+
+// IsPrimitive returns true iff the message type d is a primitive FHIR data type.
+func IsPrimitive(d protoreflect.MessageDescriptor) bool {
+	ext := proto.GetExtension(d.Options(), apb.E_StructureDefinitionKind).(apb.StructureDefinitionKindValue)
+	return ext == apb.StructureDefinitionKindValue_KIND_PRIMITIVE_TYPE
+}
+
+// IsResource returns true iff the message type d is a FHIR resource type.
+func IsResource(d protoreflect.MessageDescriptor) bool {
+	ext := proto.GetExtension(d.Options(), apb.E_StructureDefinitionKind).(apb.StructureDefinitionKindValue)
+	return ext == apb.StructureDefinitionKindValue_KIND_RESOURCE
+}
+
+// IsChoice returns true iff the message type d is a FHIR choice type.
+func IsChoice(d protoreflect.MessageDescriptor) bool {
+	return d != nil && proto.HasExtension(d.Options(), apb.E_IsChoiceType)
+}
+
+// IsContainedResource returns true iff the message type d is a ContainedResource.
+func IsContainedResource(d protoreflect.MessageDescriptor) bool {
+	return d.Oneofs().ByName(containedResourceOneof) != nil
+}
+
+// Unwrap returns the resource held by a ContainedResource, or msg itself if
+// it is not a ContainedResource. It returns nil for an empty ContainedResource.
+func Unwrap(msg proto.Message) proto.Message {
+	rm := msg.ProtoReflect()
+	oneof := rm.Descriptor().Oneofs().ByName(containedResourceOneof)
+	if oneof == nil {
+		return msg
+	}
+	f := rm.WhichOneof(oneof)
+	if f == nil {
+		return nil
+	}
+	return rm.Get(f).Message().Interface()
+}
+
+// ResourceType returns the FHIR resource type name of msg, unwrapping
+// ContainedResources. It returns an empty string if msg is not a resource.
+func ResourceType(msg proto.Message) string {
+	res := Unwrap(msg)
+	if res == nil || !IsResource(res.ProtoReflect().Descriptor()) {
+		return ""
+	}
+	return string(res.ProtoReflect().Descriptor().Name())
+}
+
+// ID returns the logical id of the resource msg, unwrapping
+// ContainedResources. It returns an empty string if msg is not a resource or
+// has no id.
+func ID(msg proto.Message) string {
+	res := Unwrap(msg)
+	if res == nil || !IsResource(res.ProtoReflect().Descriptor()) {
+		return ""
+	}
+	rm := res.ProtoReflect()
+	fd := rm.Descriptor().Fields().ByName("id")
+	if fd == nil || fd.Message() == nil || !rm.Has(fd) {
+		return ""
+	}
+	id := rm.Get(fd).Message()
+	value := id.Descriptor().Fields().ByName("value")
+	if value == nil {
+		return ""
+	}
+	return id.Get(value).String()
+}
+
+// Split returns the resource type and the element names of path.
+func Split(path string) (string, []string, error) {
+	parts := strings.Split(path, ".")
+	for _, p := range parts {
+		if p == "" {
+			return "", nil, fmt.Errorf("invalid element path %q", path)
+		}
+	}
+	return parts[0], parts[1:], nil
+}
+
+// Walk resolves path against the resource msg and calls fn for each element
+// it matches. If the path is rooted at a different resource type than msg,
+// Walk returns without calling fn. Unknown element names are reported as
+// errors. Elements are passed to fn as mutable messages, so fn may modify them
+// in place.
+func Walk(msg proto.Message, path string, fn VisitFunc) error {
+	rt, elems, err := Split(path)
+	if err != nil {
+		return err
+	}
+	res := Unwrap(msg)
+	if res == nil {
+		return nil
+	}
+	rm := res.ProtoReflect()
+	if string(rm.Descriptor().Name()) != rt {
+		return nil
+	}
+	return walk(rm, rt, elems, fn)
+}
+
+func walk(msg protoreflect.Message, jsonPath string, elems []string, fn VisitFunc) error {
+	if len(elems) == 0 {
+		return fn(jsonPath, msg)
+	}
+	fd, choice, err := LookupField(msg.Descriptor(), elems[0])
+	if err != nil {
+		return err
+	}
+	jsonPath = jsonPath + "." + elems[0]
+	if fd.IsList() {
+		if !msg.Has(fd) {
+			return nil
+		}
+		l := msg.Mutable(fd).List()
+		for i := 0; i < l.Len(); i++ {
+			if err := walkValue(l.Get(i).Message(), fmt.Sprintf("%s[%d]", jsonPath, i), choice, elems[1:], fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !msg.Has(fd) {
+		return nil
+	}
+	return walkValue(msg.Mutable(fd).Message(), jsonPath, choice, elems[1:], fn)
+}
+
+func walkValue(msg protoreflect.Message, jsonPath, choice string, elems []string, fn VisitFunc) error {
+	if !IsChoice(msg.Descriptor()) {
+		return walk(msg, jsonPath, elems, fn)
+	}
+	od := msg.Descriptor().Oneofs().Get(0)
+	set := msg.WhichOneof(od)
+	if set == nil || (choice != "" && set.JSONName() != choice) {
+		return nil
+	}
+	return walk(msg.Mutable(set).Message(), jsonPath, elems, fn)
+}
+
+// LookupField finds the field for the JSON element name in d. For typed choice
+// names such as "valueQuantity" it also returns the JSON name of the selected
+// choice type.
+func LookupField(d protoreflect.MessageDescriptor, name string) (protoreflect.FieldDescriptor, string, error) {
+	if fd := d.Fields().ByJSONName(name); fd != nil && fd.Message() != nil {
+		return fd, "", nil
+	}
+	fields := d.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !IsChoice(fd.Message()) || !strings.HasPrefix(name, fd.JSONName()) {
+			continue
+		}
+		typ := name[len(fd.JSONName()):]
+		if typ == "" {
+			continue
+		}
+		typ = strings.ToLower(typ[:1]) + typ[1:]
+		if fd.Message().Fields().ByJSONName(typ) != nil {
+			return fd, typ, nil
+		}
+	}
+	return nil, "", fmt.Errorf("unknown element %q in %s", name, d.Name())
+}
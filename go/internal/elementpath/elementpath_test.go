@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elementpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	patientpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func testObservation() *obspb.Observation {
+	return &obspb.Observation{
+		Code: &d4pb.CodeableConcept{
+			Coding: []*d4pb.Coding{
+				{Code: &d4pb.Code{Value: "a"}},
+				{Code: &d4pb.Code{Value: "b"}},
+			},
+		},
+		Value: &obspb.Observation_ValueX{
+			Choice: &obspb.Observation_ValueX_CodeableConcept{
+				CodeableConcept: &d4pb.CodeableConcept{Text: &d4pb.String{Value: "positive"}},
+			},
+		},
+	}
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{
+			name: "repeated",
+			path: "Observation.code.coding",
+			want: []string{"Observation.code.coding[0]", "Observation.code.coding[1]"},
+		},
+		{
+			name: "singular",
+			path: "Observation.code",
+			want: []string{"Observation.code"},
+		},
+		{
+			name: "choice base name",
+			path: "Observation.value",
+			want: []string{"Observation.value"},
+		},
+		{
+			name: "choice typed name",
+			path: "Observation.valueCodeableConcept.text",
+			want: []string{"Observation.valueCodeableConcept.text"},
+		},
+		{
+			name: "choice typed name not set",
+			path: "Observation.valueQuantity",
+		},
+		{
+			name: "unset field",
+			path: "Observation.method",
+		},
+		{
+			name: "other resource type",
+			path: "Patient.name",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, msg := range []proto.Message{
+				testObservation(),
+				&r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: testObservation()}},
+			} {
+				var got []string
+				err := Walk(msg, test.path, func(p string, _ protoreflect.Message) error {
+					got = append(got, p)
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("Walk(%q) returned unexpected error: %v", test.path, err)
+				}
+				if diff := cmp.Diff(test.want, got); diff != "" {
+					t.Errorf("Walk(%q) visited unexpected paths, diff (-want +got):\n%s", test.path, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestWalk_Mutates(t *testing.T) {
+	obs := testObservation()
+	err := Walk(obs, "Observation.code.coding.code", func(_ string, m protoreflect.Message) error {
+		m.Set(m.Descriptor().Fields().ByName("value"), protoreflect.ValueOfString("x"))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() returned unexpected error: %v", err)
+	}
+	for _, c := range obs.GetCode().GetCoding() {
+		if got := c.GetCode().GetValue(); got != "x" {
+			t.Errorf("Walk() did not update code, got %q, want %q", got, "x")
+		}
+	}
+}
+
+func TestWalk_Errors(t *testing.T) {
+	for _, path := range []string{
+		"Observation.unknown",
+		"Observation..code",
+		"Observation.valueFoo",
+	} {
+		t.Run(path, func(t *testing.T) {
+			err := Walk(testObservation(), path, func(string, protoreflect.Message) error { return nil })
+			if err == nil {
+				t.Errorf("Walk(%q) succeeded, want error", path)
+			}
+		})
+	}
+}
+
+func TestResourceType(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want string
+	}{
+		{"resource", &patientpb.Patient{}, "Patient"},
+		{"contained resource", &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &patientpb.Patient{}}}, "Patient"},
+		{"empty contained resource", &r4pb.ContainedResource{}, ""},
+		{"datatype", &d4pb.Coding{}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ResourceType(test.msg); got != test.want {
+				t.Errorf("ResourceType() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestID(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want string
+	}{
+		{"resource", &patientpb.Patient{Id: &d4pb.Id{Value: "1"}}, "1"},
+		{"contained resource", &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &patientpb.Patient{Id: &d4pb.Id{Value: "1"}}}}, "1"},
+		{"no id", &patientpb.Patient{}, ""},
+		{"empty contained resource", &r4pb.ContainedResource{}, ""},
+		{"datatype", &d4pb.Coding{Id: &d4pb.String{Value: "1"}}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ID(test.msg); got != test.want {
+				t.Errorf("ID() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
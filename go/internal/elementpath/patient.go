@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elementpath
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// patientFields are the fields whose references identify the patient a
+// resource belongs to, in order of preference.
+var patientFields = []protoreflect.Name{"patient", "subject", "beneficiary"}
+
+// PatientOf returns the relative reference of the patient m, a resource,
+// belongs to: itself if it is a Patient, or the Patient its patient, subject
+// or beneficiary refers to. It returns "" if m belongs to no patient.
+func PatientOf(m protoreflect.Message) string {
+	desc := m.Descriptor()
+	if desc.Name() == "Patient" {
+		if id := stringValue(m, "id"); id != "" {
+			return "Patient/" + id
+		}
+		return ""
+	}
+	for _, name := range patientFields {
+		fd := desc.Fields().ByName(name)
+		if fd == nil || fd.IsList() || fd.Message() == nil || fd.Message().Name() != "Reference" || !m.Has(fd) {
+			continue
+		}
+		if id := stringValue(m.Get(fd).Message(), "patient_id"); id != "" {
+			return "Patient/" + id
+		}
+	}
+	return ""
+}
+
+// stringValue returns the value of the string primitive in the field name of
+// m, or "" if it is not set.
+func stringValue(m protoreflect.Message, name protoreflect.Name) string {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || fd.Message() == nil || !m.Has(fd) {
+		return ""
+	}
+	v := m.Get(fd).Message()
+	vfd := v.Descriptor().Fields().ByName("value")
+	if vfd == nil || vfd.Kind() != protoreflect.StringKind {
+		return ""
+	}
+	return v.Get(vfd).String()
+}
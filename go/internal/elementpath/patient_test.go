@@ -0,0 +1,48 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elementpath
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestPatientOf(t *testing.T) {
+	tests := []struct {
+		name string
+		res  proto.Message
+		want string
+	}{
+		{"patient", &ppb.Patient{Id: &d4pb.Id{Value: "p1"}}, "Patient/p1"},
+		{"patient without id", &ppb.Patient{}, ""},
+		{"subject", &obspb.Observation{Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p2"}}}}, "Patient/p2"},
+		{"condition subject", &cpb.Condition{Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p3"}}}}, "Patient/p3"},
+		{"group subject", &obspb.Observation{Subject: &d4pb.Reference{Reference: &d4pb.Reference_GroupId{GroupId: &d4pb.ReferenceId{Value: "g1"}}}}, ""},
+		{"no subject", &obspb.Observation{Id: &d4pb.Id{Value: "o1"}}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PatientOf(test.res.ProtoReflect()); got != test.want {
+				t.Errorf("PatientOf() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elementpath
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+)
+
+// Child returns the element name of msg for modification, creating it if it
+// is not set. For repeated elements the last element is returned, unless the
+// list is empty or appendNew is set, in which case a new element is appended.
+// A typed choice name such as "valueQuantity" selects the choice type; a base
+// choice name only resolves if the choice is already set.
+func Child(msg protoreflect.Message, name string, appendNew bool) (protoreflect.Message, error) {
+	fd, choice, err := LookupField(msg.Descriptor(), strings.TrimSuffix(name, "[x]"))
+	if err != nil {
+		return nil, err
+	}
+	var elem protoreflect.Message
+	if fd.IsList() {
+		l := msg.Mutable(fd).List()
+		if l.Len() == 0 || appendNew {
+			l.Append(l.NewElement())
+		}
+		elem = l.Get(l.Len() - 1).Message()
+	} else {
+		elem = msg.Mutable(fd).Message()
+	}
+	if !IsChoice(elem.Descriptor()) {
+		return elem, nil
+	}
+	set := elem.WhichOneof(elem.Descriptor().Oneofs().Get(0))
+	if choice == "" {
+		if set == nil {
+			return nil, fmt.Errorf("choice element %q in %s has no type", name, msg.Descriptor().Name())
+		}
+		return elem.Mutable(set).Message(), nil
+	}
+	return elem.Mutable(elem.Descriptor().Fields().ByJSONName(choice)).Message(), nil
+}
+
+// Set assigns value to the element name of msg, converting it to the
+// element's type with Convert. Values are appended to repeated elements. For
+// choice elements addressed by their base name, i.e. "value" or "value[x]",
+// the choice type is the one matching the type of value.
+func Set(msg protoreflect.Message, name string, value proto.Message) error {
+	fd, choice, err := LookupField(msg.Descriptor(), strings.TrimSuffix(name, "[x]"))
+	if err != nil {
+		return err
+	}
+	target := fd.Message()
+	if IsChoice(target) {
+		cm := msg.NewField(fd).Message()
+		var option protoreflect.FieldDescriptor
+		if choice != "" {
+			option = target.Fields().ByJSONName(choice)
+		} else if option = choiceOption(target, value); option == nil {
+			return fmt.Errorf("%s is not a valid type for %s.%s", value.ProtoReflect().Descriptor().Name(), msg.Descriptor().Name(), name)
+		}
+		v, err := Convert(value, option.Message())
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", msg.Descriptor().Name(), name, err)
+		}
+		cm.Set(option, protoreflect.ValueOfMessage(v.ProtoReflect()))
+		setField(msg, fd, cm)
+		return nil
+	}
+	v, err := Convert(value, target)
+	if err != nil {
+		return fmt.Errorf("%s.%s: %w", msg.Descriptor().Name(), name, err)
+	}
+	setField(msg, fd, v.ProtoReflect())
+	return nil
+}
+
+func setField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, v protoreflect.Message) {
+	if fd.IsList() {
+		msg.Mutable(fd).List().Append(protoreflect.ValueOfMessage(v))
+		return
+	}
+	msg.Set(fd, protoreflect.ValueOfMessage(v))
+}
+
+// choiceOption returns the option of the choice type d that value can be
+// assigned to, preferring an exact type match.
+func choiceOption(d protoreflect.MessageDescriptor, value proto.Message) protoreflect.FieldDescriptor {
+	vd := value.ProtoReflect().Descriptor()
+	fields := d.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if f := fields.Get(i); f.Message() != nil && f.Message().FullName() == vd.FullName() {
+			return f
+		}
+	}
+	for i := 0; i < fields.Len(); i++ {
+		if f := fields.Get(i); f.Message() != nil {
+			if _, err := Convert(value, f.Message()); err == nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// Convert returns value as a message of type d. Besides values that already
+// have type d, it accepts
+//   - resources for ContainedResource targets, and ContainedResources for
+//     resource targets,
+//   - primitives with the same kind of value, i.e. a String for a Code or an
+//     Integer for a PositiveInt in range,
+//   - Codes, Strings and Codings for the enum-valued code types,
+//   - Codings for CodeableConcepts and Codes,
+//   - Quantities for the Quantity profiles such as Age, and vice versa,
+//   - other primitives with the same field layout, i.e. a Date for a DateTime.
+func Convert(value proto.Message, d protoreflect.MessageDescriptor) (proto.Message, error) {
+	src := value.ProtoReflect()
+	sd := src.Descriptor()
+	if sd.FullName() == d.FullName() {
+		return proto.Clone(value), nil
+	}
+	if IsContainedResource(sd) {
+		if res := Unwrap(value); res != nil {
+			return Convert(res, d)
+		}
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(d.FullName())
+	if err != nil {
+		return nil, err
+	}
+	out := mt.New()
+	if IsContainedResource(d) {
+		fields := d.Oneofs().ByName(containedResourceOneof).Fields()
+		for i := 0; i < fields.Len(); i++ {
+			if f := fields.Get(i); f.Message().FullName() == sd.FullName() {
+				out.Set(f, protoreflect.ValueOfMessage(proto.Clone(value).ProtoReflect()))
+				return out.Interface(), nil
+			}
+		}
+		return nil, fmt.Errorf("%s is not a resource", sd.Name())
+	}
+	if sd.Name() == "Coding" {
+		if d.Name() == "CodeableConcept" {
+			fd := d.Fields().ByName("coding")
+			out.Mutable(fd).List().Append(protoreflect.ValueOfMessage(proto.Clone(value).ProtoReflect()))
+			return out.Interface(), nil
+		}
+		if code := src.Get(sd.Fields().ByName("code")).Message(); code.IsValid() && d.Fields().ByName("value") != nil {
+			return Convert(code.Interface(), d)
+		}
+	}
+	if (quantityTypes[sd.Name()] && quantityTypes[d.Name()] || IsPrimitive(sd) && IsPrimitive(d)) && copyCompatible(out, src) {
+		return out.Interface(), nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to %s", sd.Name(), d.Name())
+}
+
+// quantityTypes are the Quantity data type and its profiles, which share its
+// field layout.
+var quantityTypes = map[protoreflect.Name]bool{
+	"Quantity": true, "Age": true, "Count": true, "Distance": true, "Duration": true, "SimpleQuantity": true, "MoneyQuantity": true,
+}
+
+// copyCompatible copies src to dst field by field. It returns false if a set
+// field of src has no counterpart in dst with a compatible type.
+func copyCompatible(dst, src protoreflect.Message) bool {
+	ok := true
+	src.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		dfd := dst.Descriptor().Fields().ByName(fd.Name())
+		if dfd == nil || dfd.IsList() != fd.IsList() {
+			ok = false
+			return false
+		}
+		if fd.IsList() {
+			if fd.Message() == nil || dfd.Message() == nil || fd.Message().FullName() != dfd.Message().FullName() {
+				ok = false
+				return false
+			}
+			dst.Set(dfd, v)
+			return true
+		}
+		cv, converted := convertScalar(dfd, fd, v)
+		if !converted {
+			ok = false
+			return false
+		}
+		dst.Set(dfd, cv)
+		return true
+	})
+	return ok
+}
+
+func convertScalar(dfd, fd protoreflect.FieldDescriptor, v protoreflect.Value) (protoreflect.Value, bool) {
+	switch {
+	case dfd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.MessageKind:
+		if dfd.Message() == nil || fd.Message() == nil {
+			return protoreflect.Value{}, false
+		}
+		if dfd.Message().FullName() == fd.Message().FullName() {
+			return v, true
+		}
+		mt, err := protoregistry.GlobalTypes.FindMessageByName(dfd.Message().FullName())
+		if err != nil {
+			return protoreflect.Value{}, false
+		}
+		m := mt.New()
+		if !copyCompatible(m, v.Message()) {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfMessage(m), true
+	case dfd.Kind() == fd.Kind() && dfd.Kind() != protoreflect.EnumKind:
+		return v, true
+	case dfd.Kind() == protoreflect.EnumKind && fd.Kind() == protoreflect.EnumKind:
+		name := fd.Enum().Values().ByNumber(v.Enum()).Name()
+		ev := dfd.Enum().Values().ByName(name)
+		if ev == nil {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfEnum(ev.Number()), true
+	case dfd.Kind() == protoreflect.EnumKind && fd.Kind() == protoreflect.StringKind:
+		ev := enumByCode(dfd.Enum(), v.String())
+		if ev == nil {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfEnum(ev.Number()), true
+	case dfd.Kind() == protoreflect.StringKind && fd.Kind() == protoreflect.EnumKind:
+		return protoreflect.ValueOfString(enumCode(fd.Enum().Values().ByNumber(v.Enum()))), true
+	}
+	var n int64
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Int64Kind, protoreflect.Sint64Kind:
+		n = v.Int()
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		if v.Uint() > math.MaxInt64 {
+			return protoreflect.Value{}, false
+		}
+		n = int64(v.Uint())
+	default:
+		return protoreflect.Value{}, false
+	}
+	switch dfd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind:
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfInt32(int32(n)), true
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind:
+		return protoreflect.ValueOfInt64(n), true
+	case protoreflect.Uint32Kind:
+		if n < 0 || n > math.MaxUint32 {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfUint32(uint32(n)), true
+	case protoreflect.Uint64Kind:
+		if n < 0 {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfUint64(uint64(n)), true
+	}
+	return protoreflect.Value{}, false
+}
+
+func enumByCode(ed protoreflect.EnumDescriptor, code string) protoreflect.EnumValueDescriptor {
+	values := ed.Values()
+	for i := 0; i < values.Len(); i++ {
+		if ev := values.Get(i); ev.Number() != 0 && enumCode(ev) == code {
+			return ev
+		}
+	}
+	return nil
+}
+
+func enumCode(ev protoreflect.EnumValueDescriptor) string {
+	if orig := proto.GetExtension(ev.Options(), apb.E_FhirOriginalCode).(string); orig != "" {
+		return orig
+	}
+	return strings.ReplaceAll(strings.ToLower(string(ev.Name())), "_", "-")
+}
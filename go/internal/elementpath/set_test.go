@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elementpath
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	patientpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestSet(t *testing.T) {
+	coding := &d4pb.Coding{
+		System: &d4pb.Uri{Value: "http://loinc.org"},
+		Code:   &d4pb.Code{Value: "8302-2"},
+	}
+	tests := []struct {
+		name  string
+		msg   proto.Message
+		elem  string
+		value proto.Message
+		want  proto.Message
+	}{
+		{
+			name:  "same type",
+			msg:   &obspb.Observation{},
+			elem:  "code",
+			value: &d4pb.CodeableConcept{Text: &d4pb.String{Value: "height"}},
+			want:  &obspb.Observation{Code: &d4pb.CodeableConcept{Text: &d4pb.String{Value: "height"}}},
+		},
+		{
+			name:  "coding to codeable concept",
+			msg:   &obspb.Observation{},
+			elem:  "code",
+			value: coding,
+			want:  &obspb.Observation{Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{coding}}},
+		},
+		{
+			name:  "coding to enum code",
+			msg:   &patientpb.Patient{},
+			elem:  "gender",
+			value: &d4pb.Coding{Code: &d4pb.Code{Value: "female"}},
+			want:  &patientpb.Patient{Gender: &patientpb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE}},
+		},
+		{
+			name:  "string to enum code",
+			msg:   &obspb.Observation{},
+			elem:  "status",
+			value: &d4pb.String{Value: "entered-in-error"},
+			want:  &obspb.Observation{Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_ENTERED_IN_ERROR}},
+		},
+		{
+			name:  "date to date time",
+			msg:   &patientpb.Patient{},
+			elem:  "deceasedDateTime",
+			value: &d4pb.Date{ValueUs: 1000, Timezone: "Z", Precision: d4pb.Date_DAY},
+			want: &patientpb.Patient{Deceased: &patientpb.Patient_DeceasedX{
+				Choice: &patientpb.Patient_DeceasedX_DateTime{DateTime: &d4pb.DateTime{ValueUs: 1000, Timezone: "Z", Precision: d4pb.DateTime_DAY}},
+			}},
+		},
+		{
+			name:  "choice by value type",
+			msg:   &obspb.Observation{},
+			elem:  "value[x]",
+			value: &d4pb.Quantity{Value: &d4pb.Decimal{Value: "1.5"}},
+			want: &obspb.Observation{Value: &obspb.Observation_ValueX{
+				Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{Value: &d4pb.Decimal{Value: "1.5"}}},
+			}},
+		},
+		{
+			name:  "coding to choice",
+			msg:   &obspb.Observation{},
+			elem:  "value",
+			value: coding,
+			want: &obspb.Observation{Value: &obspb.Observation_ValueX{
+				Choice: &obspb.Observation_ValueX_CodeableConcept{CodeableConcept: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{coding}}},
+			}},
+		},
+		{
+			name:  "quantity profile",
+			msg:   &cpb.Condition{},
+			elem:  "onsetAge",
+			value: &d4pb.Quantity{Value: &d4pb.Decimal{Value: "40"}, Code: &d4pb.Code{Value: "a"}},
+			want: &cpb.Condition{Onset: &cpb.Condition_OnsetX{
+				Choice: &cpb.Condition_OnsetX_Age{Age: &d4pb.Age{Value: &d4pb.Decimal{Value: "40"}, Code: &d4pb.Code{Value: "a"}}},
+			}},
+		},
+		{
+			name:  "repeated",
+			msg:   &patientpb.Patient{Name: []*d4pb.HumanName{{Family: &d4pb.String{Value: "Doe"}}}},
+			elem:  "name",
+			value: &d4pb.HumanName{Family: &d4pb.String{Value: "Roe"}},
+			want:  &patientpb.Patient{Name: []*d4pb.HumanName{{Family: &d4pb.String{Value: "Doe"}}, {Family: &d4pb.String{Value: "Roe"}}}},
+		},
+		{
+			name:  "resource to contained resource",
+			msg:   &r4pb.Bundle_Entry{},
+			elem:  "resource",
+			value: &patientpb.Patient{Id: &d4pb.Id{Value: "p1"}},
+			want: &r4pb.Bundle_Entry{Resource: &r4pb.ContainedResource{
+				OneofResource: &r4pb.ContainedResource_Patient{Patient: &patientpb.Patient{Id: &d4pb.Id{Value: "p1"}}},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Set(test.msg.ProtoReflect(), test.elem, test.value); err != nil {
+				t.Fatalf("Set(%q) returned unexpected error: %v", test.elem, err)
+			}
+			if diff := cmp.Diff(test.want, test.msg, protocmp.Transform()); diff != "" {
+				t.Errorf("Set(%q) diff (-want +got):\n%s", test.elem, diff)
+			}
+		})
+	}
+}
+
+func TestSet_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		elem  string
+		value proto.Message
+	}{
+		{"unknown element", "unknown", &d4pb.String{Value: "x"}},
+		{"unknown code", "status", &d4pb.String{Value: "bogus"}},
+		{"incompatible type", "subject", &d4pb.String{Value: "x"}},
+		{"invalid choice type", "effective", &d4pb.Boolean{Value: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Set((&obspb.Observation{}).ProtoReflect(), test.elem, test.value); err == nil {
+				t.Errorf("Set(%q) succeeded, want error", test.elem)
+			}
+		})
+	}
+}
+
+func TestChild(t *testing.T) {
+	p := &patientpb.Patient{}
+	for _, step := range []struct {
+		elem      string
+		appendNew bool
+		value     string
+	}{
+		{"name", false, "Doe"},
+		{"name", false, "Roe"},
+		{"name", true, "Poe"},
+	} {
+		name, err := Child(p.ProtoReflect(), step.elem, step.appendNew)
+		if err != nil {
+			t.Fatalf("Child(%q) returned unexpected error: %v", step.elem, err)
+		}
+		if err := Set(name, "family", &d4pb.String{Value: step.value}); err != nil {
+			t.Fatalf("Set(family) returned unexpected error: %v", err)
+		}
+	}
+	want := &patientpb.Patient{Name: []*d4pb.HumanName{
+		{Family: &d4pb.String{Value: "Roe"}},
+		{Family: &d4pb.String{Value: "Poe"}},
+	}}
+	if diff := cmp.Diff(want, p, protocmp.Transform()); diff != "" {
+		t.Errorf("Child() diff (-want +got):\n%s", diff)
+	}
+
+	o := &obspb.Observation{}
+	q, err := Child(o.ProtoReflect(), "valueQuantity", false)
+	if err != nil {
+		t.Fatalf("Child(valueQuantity) returned unexpected error: %v", err)
+	}
+	if got := q.Descriptor().Name(); got != "Quantity" {
+		t.Errorf("Child(valueQuantity) returned %s, want Quantity", got)
+	}
+	if _, err := Child((&obspb.Observation{}).ProtoReflect(), "value", false); err == nil {
+		t.Errorf("Child(value) on an unset choice succeeded, want error")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fhirhttp holds the plumbing shared by the middleware that filters
+// FHIR responses on their way to the client.
+package fhirhttp
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+)
+
+var (
+	// Marshaller marshals R4 resources to FHIR JSON without whitespace.
+	Marshaller *jsonformat.Marshaller
+	// Unmarshaller unmarshals R4 FHIR JSON without validating it, as
+	// servers are trusted to return valid resources.
+	Unmarshaller *jsonformat.Unmarshaller
+)
+
+func init() {
+	var err error
+	if Marshaller, err = jsonformat.NewMarshaller(false, "", "", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("fhirhttp: creating marshaller: %v", err))
+	}
+	if Unmarshaller, err = jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("fhirhttp: creating unmarshaller: %v", err))
+	}
+}
+
+// IsFHIRJSON reports whether contentType is that of FHIR JSON.
+func IsFHIRJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/fhir+json" || mt == "application/json"
+}
+
+// ResponseBuffer is an http.ResponseWriter holding the response of a
+// wrapped handler until it has been filtered.
+type ResponseBuffer struct {
+	// Status is the status code of the response, 200 OK unless the handler
+	// writes another.
+	Status int
+	// Body is the body of the response.
+	Body bytes.Buffer
+
+	header      http.Header
+	wroteHeader bool
+}
+
+// NewResponseBuffer returns an empty ResponseBuffer.
+func NewResponseBuffer() *ResponseBuffer {
+	return &ResponseBuffer{Status: http.StatusOK, header: http.Header{}}
+}
+
+// Header returns the header of the response.
+func (b *ResponseBuffer) Header() http.Header {
+	return b.header
+}
+
+// WriteHeader records the status code of the response, if none has been
+// recorded or implied by a Write yet.
+func (b *ResponseBuffer) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.Status = status
+	b.wroteHeader = true
+}
+
+// Write appends p to the body of the response.
+func (b *ResponseBuffer) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.Body.Write(p)
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fhirhttp
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestIsFHIRJSON(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/fhir+json", true},
+		{"application/fhir+json; charset=utf-8", true},
+		{"application/json", true},
+		{"application/fhir+xml", false},
+		{"text/html", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := IsFHIRJSON(test.contentType); got != test.want {
+			t.Errorf("IsFHIRJSON(%q) = %v, want %v", test.contentType, got, test.want)
+		}
+	}
+}
+
+func TestResponseBuffer(t *testing.T) {
+	b := NewResponseBuffer()
+	b.Header().Set("Content-Type", "application/fhir+json")
+	io.WriteString(b, `{"resourceType":"Patient"}`)
+	// The status is implied by the first Write.
+	b.WriteHeader(http.StatusCreated)
+	if b.Status != http.StatusOK || b.Body.String() != `{"resourceType":"Patient"}` || b.Header().Get("Content-Type") != "application/fhir+json" {
+		t.Errorf("ResponseBuffer holds status %d, body %q and header %v, want 200 and the written body and header", b.Status, b.Body.String(), b.Header())
+	}
+
+	b = NewResponseBuffer()
+	b.WriteHeader(http.StatusNotFound)
+	b.WriteHeader(http.StatusOK)
+	if b.Status != http.StatusNotFound {
+		t.Errorf("ResponseBuffer holds status %d, want the first status written, %d", b.Status, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lines splits the input of NDJSON into lines, skipping those longer
+// than a limit, for the readers of bulk data.
+package lines
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// Source returns the next line of the input without its line ending, "\n" or
+// "\r\n", or reports it as too long, and io.EOF along with the last line.
+type Source func() (line []byte, tooLong bool, err error)
+
+// Buffered returns the lines of r. Lines longer than max bytes are skipped
+// without being held in memory.
+func Buffered(r io.Reader, max int) Source {
+	br := bufio.NewReader(r)
+	return func() ([]byte, bool, error) {
+		var line []byte
+		tooLong := false
+		for {
+			chunk, err := br.ReadSlice('\n')
+			// The line ending, of up to 2 bytes, does not count toward max.
+			if !tooLong {
+				if len(line)+len(chunk) > max+2 {
+					tooLong, line = true, nil
+				} else {
+					line = append(line, chunk...)
+				}
+			}
+			if err == bufio.ErrBufferFull {
+				continue
+			}
+			if tooLong {
+				return nil, true, err
+			}
+			return check(trimEnding(line), max, err)
+		}
+	}
+}
+
+// Mapped returns the lines of data, which are slices of it.
+func Mapped(data []byte, max int) Source {
+	return func() ([]byte, bool, error) {
+		if len(data) == 0 {
+			return nil, false, io.EOF
+		}
+		var line []byte
+		var err error
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i+1], data[i+1:]
+		} else {
+			line, data, err = data, nil, io.EOF
+		}
+		return check(trimEnding(line), max, err)
+	}
+}
+
+func trimEnding(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
+
+func check(line []byte, max int, err error) ([]byte, bool, error) {
+	if len(line) > max {
+		return nil, true, err
+	}
+	return line, false, err
+}
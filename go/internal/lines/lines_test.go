@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lines
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type line struct {
+	Data    string
+	TooLong bool
+}
+
+func readAll(t *testing.T, next Source) []line {
+	t.Helper()
+	var got []line
+	for {
+		data, tooLong, err := next()
+		if err != nil && err != io.EOF {
+			t.Fatalf("Source returned unexpected error: %v", err)
+		}
+		if len(data) > 0 || tooLong || err == nil {
+			got = append(got, line{string(data), tooLong})
+		}
+		if err == io.EOF {
+			return got
+		}
+	}
+}
+
+func TestSources(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		max   int
+		want  []line
+	}{
+		{
+			name:  "lf",
+			input: "a\nbb\n",
+			max:   10,
+			want:  []line{{"a", false}, {"bb", false}},
+		},
+		{
+			name:  "crlf",
+			input: "a\r\nbb\r\n",
+			max:   10,
+			want:  []line{{"a", false}, {"bb", false}},
+		},
+		{
+			name:  "no final line ending",
+			input: "a\nbb\r",
+			max:   10,
+			want:  []line{{"a", false}, {"bb", false}},
+		},
+		{
+			name:  "blank lines",
+			input: "\n\r\na",
+			max:   10,
+			want:  []line{{"", false}, {"", false}, {"a", false}},
+		},
+		{
+			name:  "at the limit with crlf",
+			input: "abc\r\nd",
+			max:   3,
+			want:  []line{{"abc", false}, {"d", false}},
+		},
+		{
+			name:  "too long",
+			input: "abcd\nab\n",
+			max:   3,
+			want:  []line{{"", true}, {"ab", false}},
+		},
+		{
+			name:  "too long at the end",
+			input: "ab\nabcd",
+			max:   3,
+			want:  []line{{"ab", false}, {"", true}},
+		},
+		{
+			name:  "too long beyond the buffer",
+			input: strings.Repeat("x", 10000) + "\r\nab\n",
+			max:   5000,
+			want:  []line{{"", true}, {"ab", false}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sources := map[string]Source{
+				"Buffered": Buffered(strings.NewReader(test.input), test.max),
+				"Mapped":   Mapped([]byte(test.input), test.max),
+			}
+			for name, next := range sources {
+				if diff := cmp.Diff(test.want, readAll(t, next)); diff != "" {
+					t.Errorf("%s(%q) returned unexpected diff (-want +got):\n%s", name, test.input, diff)
+				}
+			}
+		})
+	}
+}
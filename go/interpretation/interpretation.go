@@ -0,0 +1,347 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package interpretation evaluates the values of R4 Observations against
+// their reference ranges and codes the result as an interpretation, such as
+// H for a value above the normal range, so that alerting on results need
+// not repeat the unit conversions and range selection.
+//
+// A reference range applies to a subject if the subject's age when the
+// Observation was made, counted in whole units of each bound of the age of
+// the range, is within it, and if each of the subjects the range applies to
+// is either the subject's sex or one of their characteristics. Ranges
+// without a type, or of type normal, are normal ranges; ranges whose type
+// is critical in the observation-range-category code system that
+// ObservationDefinition uses are the limits outside of which values are
+// critical. The first applicable range of each kind is used.
+//
+// Values are compared in their unit, converting those of the ranges as by
+// fhirtypes.CompareQuantities, so that a value with a comparator is outside
+// a range only if every value it allows is: >200 mg/dL is above a high of
+// 150 mg/dL, while <5 U/L against a low of 2 U/L is indeterminate.
+package interpretation
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/fhir/go/codes"
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+// Code systems of interpretations, range types and the sexes ranges apply
+// to.
+const (
+	InterpretationSystem = "http://terminology.hl7.org/CodeSystem/v3-ObservationInterpretation"
+	RangeMeaningSystem   = "http://terminology.hl7.org/CodeSystem/referencerange-meaning"
+	RangeCategorySystem  = "http://hl7.org/fhir/observation-range-category"
+	GenderSystem         = "http://hl7.org/fhir/administrative-gender"
+	snomed               = "http://snomed.info/sct"
+)
+
+// Interpretation codes.
+const (
+	Normal        = "N"
+	Low           = "L"
+	High          = "H"
+	CriticalLow   = "LL"
+	CriticalHigh  = "HH"
+	Indeterminate = "IND"
+)
+
+// sexCodes are the SNOMED CT codes of the administrative genders.
+var sexCodes = map[string]string{
+	"248152002": "female",
+	"248153007": "male",
+}
+
+// Subject is the subject of Observations, as far as reference ranges
+// depend on them.
+type Subject struct {
+	// BirthDate is the birth date of the subject, or zero if it is unknown,
+	// in which case ranges for an age do not apply.
+	BirthDate time.Time
+	// Gender is the administrative gender code of the subject, i.e.
+	// "female".
+	Gender string
+	// Characteristics are other codes ranges may apply to, such as a
+	// pregnancy or the race of the subject.
+	Characteristics []*d4pb.Coding
+}
+
+// SubjectOf returns the Subject of the patient p.
+func SubjectOf(p *ppb.Patient) (Subject, error) {
+	var s Subject
+	if p.GetBirthDate() != nil {
+		t, err := fhirtypes.DateToTime(p.GetBirthDate())
+		if err != nil {
+			return Subject{}, fmt.Errorf("Patient %s: birth date %w", p.GetId().GetValue(), err)
+		}
+		s.BirthDate = t
+	}
+	if g := p.GetGender().GetValue(); g != c4pb.AdministrativeGenderCode_INVALID_UNINITIALIZED {
+		s.Gender = codes.Code(g)
+	}
+	return s, nil
+}
+
+// Result is the interpretation of a value.
+type Result struct {
+	// Code is the interpretation code of the value, or "" if no range
+	// applies.
+	Code string
+	// Range is the normal range the value was compared with, and Critical
+	// the critical limits, either of which may be nil.
+	Range, Critical *obspb.Observation_ReferenceRange
+}
+
+// Evaluate interprets the value against ranges for the subject s at the
+// time the value was observed.
+func Evaluate(value *d4pb.Quantity, ranges []*obspb.Observation_ReferenceRange, s Subject, at time.Time) (Result, error) {
+	var res Result
+	for _, r := range ranges {
+		if r.GetLow() == nil && r.GetHigh() == nil || !Applies(r, s, at) {
+			continue
+		}
+		switch {
+		case fhirtypes.HasCoding(r.GetType(), RangeCategorySystem, "critical"):
+			if res.Critical == nil {
+				res.Critical = r
+			}
+		case r.GetType() == nil || fhirtypes.HasCoding(r.GetType(), RangeMeaningSystem, "normal"):
+			if res.Range == nil {
+				res.Range = r
+			}
+		}
+	}
+	if res.Critical != nil {
+		switch p, err := position(value, res.Critical); {
+		case err != nil:
+			return Result{}, err
+		case p == below:
+			res.Code = CriticalLow
+			return res, nil
+		case p == above:
+			res.Code = CriticalHigh
+			return res, nil
+		}
+	}
+	if res.Range == nil {
+		return res, nil
+	}
+	p, err := position(value, res.Range)
+	if err != nil {
+		return Result{}, err
+	}
+	res.Code = positionCodes[p]
+	return res, nil
+}
+
+// Interpret interprets the Quantity value of obs against its reference
+// ranges for the subject s, at the start of the effective time of obs. The
+// Result has no code if obs has no Quantity value.
+func Interpret(obs *obspb.Observation, s Subject) (Result, error) {
+	q := obs.GetValue().GetQuantity()
+	if q == nil {
+		return Result{}, nil
+	}
+	at, err := effective(obs)
+	if err != nil {
+		return Result{}, err
+	}
+	res, err := Evaluate(q, obs.GetReferenceRange(), s, at)
+	if err != nil {
+		return Result{}, fmt.Errorf("Observation %s: %w", obs.GetId().GetValue(), err)
+	}
+	return res, nil
+}
+
+// Apply sets the interpretation of obs and of each of its components from
+// their reference ranges, as by Interpret. Components without ranges of
+// their own are not interpreted. Interpretations in InterpretationSystem
+// are replaced, and other interpretations kept.
+func Apply(obs *obspb.Observation, s Subject) error {
+	res, err := Interpret(obs, s)
+	if err != nil {
+		return err
+	}
+	obs.Interpretation = interpretation(obs.GetInterpretation(), res.Code)
+	at, err := effective(obs)
+	if err != nil {
+		return err
+	}
+	for i, c := range obs.GetComponent() {
+		q := c.GetValue().GetQuantity()
+		if q == nil || len(c.GetReferenceRange()) == 0 {
+			continue
+		}
+		res, err := Evaluate(q, c.GetReferenceRange(), s, at)
+		if err != nil {
+			return fmt.Errorf("Observation %s: component %d: %w", obs.GetId().GetValue(), i, err)
+		}
+		c.Interpretation = interpretation(c.GetInterpretation(), res.Code)
+	}
+	return nil
+}
+
+// interpretation returns ccs without the concepts coded in
+// InterpretationSystem, followed by code if it is not "".
+func interpretation(ccs []*d4pb.CodeableConcept, code string) []*d4pb.CodeableConcept {
+	var out []*d4pb.CodeableConcept
+	for _, cc := range ccs {
+		if fhirtypes.FirstCodingIn(cc, InterpretationSystem) == nil {
+			out = append(out, cc)
+		}
+	}
+	if code != "" {
+		out = append(out, &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(InterpretationSystem, code)}})
+	}
+	return out
+}
+
+// effective returns the start of the effective time of obs, or zero if it
+// has none.
+func effective(obs *obspb.Observation) (time.Time, error) {
+	e := obs.GetEffective()
+	var t time.Time
+	var err error
+	switch {
+	case e.GetDateTime() != nil:
+		t, _, err = fhirtypes.Span(e.GetDateTime())
+	case e.GetInstant() != nil:
+		t, _, err = fhirtypes.Span(e.GetInstant())
+	case e.GetPeriod().GetStart() != nil:
+		t, _, err = fhirtypes.Span(e.GetPeriod().GetStart())
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Observation %s: effective %w", obs.GetId().GetValue(), err)
+	}
+	return t, nil
+}
+
+// Positions of a value relative to a range.
+const (
+	within = iota
+	below
+	above
+	unknown
+)
+
+// positionCodes are the interpretation codes of positions relative to a
+// normal range.
+var positionCodes = [...]string{within: Normal, below: Low, above: High, unknown: Indeterminate}
+
+// position returns the position of value relative to the bounds of r.
+func position(value *d4pb.Quantity, r *obspb.Observation_ReferenceRange) (int, error) {
+	p := within
+	for _, b := range []struct {
+		bound   *d4pb.SimpleQuantity
+		outside fhirtypes.Ordering
+		pos     int
+	}{{r.GetLow(), fhirtypes.Less, below}, {r.GetHigh(), fhirtypes.Greater, above}} {
+		if b.bound == nil {
+			continue
+		}
+		o, err := fhirtypes.CompareQuantities(value, fhirtypes.QuantityOfSimple(b.bound))
+		if err != nil {
+			return unknown, err
+		}
+		switch o {
+		case b.outside:
+			return b.pos, nil
+		case fhirtypes.Indeterminate:
+			p = unknown
+		}
+	}
+	return p, nil
+}
+
+// Applies reports whether r applies to s at the time at, by its age and
+// appliesTo, as for the qualified intervals of ObservationDefinitions.
+func Applies(r *obspb.Observation_ReferenceRange, s Subject, at time.Time) bool {
+	if age := r.GetAge(); age != nil {
+		if s.BirthDate.IsZero() || at.IsZero() {
+			return false
+		}
+		for _, b := range []struct {
+			bound *d4pb.SimpleQuantity
+			cmp   int
+		}{{age.GetLow(), -1}, {age.GetHigh(), 1}} {
+			if b.bound == nil {
+				continue
+			}
+			n, ok := ageIn(s.BirthDate, at, b.bound.GetCode().GetValue())
+			if !ok {
+				return false
+			}
+			v, err := strconv.ParseFloat(b.bound.GetValue().GetValue(), 64)
+			if err != nil || b.cmp < 0 && float64(n) < v || b.cmp > 0 && float64(n) > v {
+				return false
+			}
+		}
+	}
+	for _, cc := range r.GetAppliesTo() {
+		if !appliesTo(cc, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// appliesTo reports whether the subject s is the sex or has one of the
+// characteristics of cc.
+func appliesTo(cc *d4pb.CodeableConcept, s Subject) bool {
+	for _, c := range cc.GetCoding() {
+		system, code := fhirtypes.NormalizeSystem(c.GetSystem().GetValue()), c.GetCode().GetValue()
+		if system == GenderSystem && code == s.Gender || system == snomed && sexCodes[code] != "" && sexCodes[code] == s.Gender {
+			return true
+		}
+		for _, sc := range s.Characteristics {
+			if fhirtypes.NormalizeSystem(sc.GetSystem().GetValue()) == system && sc.GetCode().GetValue() == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ageIn returns the age at the time at of someone born on birth, in whole
+// units of the UCUM unit of time code, or false if the unit is not one of
+// years, months, weeks, days or hours.
+func ageIn(birth, at time.Time, code string) (int, bool) {
+	switch code {
+	case "a", "mo":
+		months := (at.Year()-birth.Year())*12 + int(at.Month()-birth.Month())
+		if birth.AddDate(0, months, 0).After(at) {
+			months--
+		}
+		if code == "a" {
+			return months / 12, true
+		}
+		return months, true
+	case "wk":
+		return int(at.Sub(birth) / (7 * 24 * time.Hour)), true
+	case "d":
+		return int(at.Sub(birth) / (24 * time.Hour)), true
+	case "h":
+		return int(at.Sub(birth) / time.Hour), true
+	}
+	return 0, false
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpretation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func simple(value, code string) *d4pb.SimpleQuantity {
+	return &d4pb.SimpleQuantity{Value: fhirtypes.Decimal(value), System: fhirtypes.URI(fhirtypes.UCUMSystem), Code: fhirtypes.Code(code)}
+}
+
+func concept(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(system, code)}}
+}
+
+func withComparator(q *d4pb.Quantity, c c4pb.QuantityComparatorCode_Value) *d4pb.Quantity {
+	q.Comparator = &d4pb.Quantity_ComparatorCode{Value: c}
+	return q
+}
+
+// hemoglobin has normal ranges by sex for adults and one for children, and
+// critical limits for everyone.
+var hemoglobin = []*obspb.Observation_ReferenceRange{{
+	Low:       simple("13.5", "g/dL"),
+	High:      simple("17.5", "g/dL"),
+	AppliesTo: []*d4pb.CodeableConcept{concept(GenderSystem, "male")},
+	Age:       &d4pb.Range{Low: simple("18", "a")},
+}, {
+	Low:       simple("12", "g/dL"),
+	High:      simple("15.5", "g/dL"),
+	Type:      concept(RangeMeaningSystem, "normal"),
+	AppliesTo: []*d4pb.CodeableConcept{concept("http://snomed.info/sct", "248152002")},
+	Age:       &d4pb.Range{Low: simple("18", "a")},
+}, {
+	Low:  simple("11", "g/dL"),
+	High: simple("16", "g/dL"),
+	Age:  &d4pb.Range{High: simple("17", "a")},
+}, {
+	Low:  simple("70", "g/L"),
+	High: simple("200", "g/L"),
+	Type: concept(RangeCategorySystem, "critical"),
+}}
+
+func TestEvaluate(t *testing.T) {
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	man := Subject{BirthDate: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), Gender: "male"}
+	woman := Subject{BirthDate: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), Gender: "female"}
+	// The child turns 18 the day after the Observation.
+	child := Subject{BirthDate: time.Date(2006, 6, 2, 0, 0, 0, 0, time.UTC), Gender: "female"}
+	tests := []struct {
+		name      string
+		value     *d4pb.Quantity
+		s         Subject
+		wantCode  string
+		wantRange int
+	}{
+		{"normal", fhirtypes.Quantity("14", "g/dL"), man, Normal, 0},
+		{"low by sex", fhirtypes.Quantity("13", "g/dL"), man, Low, 0},
+		{"normal by sex", fhirtypes.Quantity("13", "g/dL"), woman, Normal, 1},
+		{"high", fhirtypes.Quantity("16", "g/dL"), woman, High, 1},
+		{"by age", fhirtypes.Quantity("15.8", "g/dL"), child, Normal, 2},
+		{"other unit", fhirtypes.Quantity("180", "g/L"), man, High, 0},
+		{"critical low", fhirtypes.Quantity("6.5", "g/dL"), man, CriticalLow, 0},
+		{"critical high", fhirtypes.Quantity("21", "g/dL"), woman, CriticalHigh, 1},
+		{"comparator above", withComparator(fhirtypes.Quantity("18", "g/dL"), c4pb.QuantityComparatorCode_GREATER_THAN), man, High, 0},
+		{"comparator indeterminate", withComparator(fhirtypes.Quantity("14", "g/dL"), c4pb.QuantityComparatorCode_LESS_THAN), man, Indeterminate, 0},
+		{"comparator across bound", withComparator(fhirtypes.Quantity("17.5", "g/dL"), c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO), woman, Indeterminate, 1},
+		{"no applicable range", fhirtypes.Quantity("14", "g/dL"), Subject{Gender: "male"}, "", -1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Evaluate(tc.value, hemoglobin, tc.s, at)
+			if err != nil {
+				t.Fatalf("Evaluate() returned unexpected error: %v", err)
+			}
+			if got.Code != tc.wantCode {
+				t.Errorf("Evaluate() code = %q, want %q", got.Code, tc.wantCode)
+			}
+			var want *obspb.Observation_ReferenceRange
+			if tc.wantRange >= 0 {
+				want = hemoglobin[tc.wantRange]
+			}
+			if got.Range != want {
+				t.Errorf("Evaluate() range = %v, want %v", got.Range, want)
+			}
+		})
+	}
+}
+
+func TestEvaluateOpenRange(t *testing.T) {
+	alt := []*obspb.Observation_ReferenceRange{{High: simple("40", "U/L")}}
+	got, err := Evaluate(withComparator(fhirtypes.Quantity("5", "U/L"), c4pb.QuantityComparatorCode_LESS_THAN), alt, Subject{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	if got.Code != Normal {
+		t.Errorf("Evaluate() code = %q, want %q", got.Code, Normal)
+	}
+}
+
+func TestEvaluateIncompatibleUnits(t *testing.T) {
+	if _, err := Evaluate(fhirtypes.Quantity("5", "mmol/L"), hemoglobin[3:], Subject{}, time.Time{}); err == nil {
+		t.Errorf("Evaluate() succeeded, want error")
+	}
+}
+
+func TestApply(t *testing.T) {
+	p := &ppb.Patient{
+		BirthDate: fhirtypes.DateFromTime(time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), d4pb.Date_DAY),
+		Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_MALE},
+	}
+	s, err := SubjectOf(p)
+	if err != nil {
+		t.Fatalf("SubjectOf() returned unexpected error: %v", err)
+	}
+	flag := concept("http://example.com/flags", "reviewed")
+	obs := &obspb.Observation{
+		Effective: &obspb.Observation_EffectiveX{
+			Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: fhirtypes.DateTimeFromTime(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC), d4pb.DateTime_SECOND)},
+		},
+		Value: &obspb.Observation_ValueX{
+			Choice: &obspb.Observation_ValueX_Quantity{Quantity: fhirtypes.Quantity("12", "g/dL")},
+		},
+		Interpretation: []*d4pb.CodeableConcept{concept(InterpretationSystem, Normal), flag},
+		ReferenceRange: hemoglobin,
+		Component: []*obspb.Observation_Component{{
+			Value: &obspb.Observation_Component_ValueX{
+				Choice: &obspb.Observation_Component_ValueX_Quantity{Quantity: fhirtypes.Quantity("150", "mm[Hg]")},
+			},
+			ReferenceRange: []*obspb.Observation_ReferenceRange{{High: simple("120", "mm[Hg]")}},
+		}, {
+			Value: &obspb.Observation_Component_ValueX{
+				Choice: &obspb.Observation_Component_ValueX_Quantity{Quantity: fhirtypes.Quantity("80", "mm[Hg]")},
+			},
+		}},
+	}
+	if err := Apply(obs, s); err != nil {
+		t.Fatalf("Apply() returned unexpected error: %v", err)
+	}
+	want := []*d4pb.CodeableConcept{flag, concept(InterpretationSystem, Low)}
+	if diff := cmp.Diff(want, obs.GetInterpretation(), protocmp.Transform()); diff != "" {
+		t.Errorf("Apply() interpretation diff (-want +got):\n%s", diff)
+	}
+	want = []*d4pb.CodeableConcept{concept(InterpretationSystem, High)}
+	if diff := cmp.Diff(want, obs.GetComponent()[0].GetInterpretation(), protocmp.Transform()); diff != "" {
+		t.Errorf("Apply() component interpretation diff (-want +got):\n%s", diff)
+	}
+	if got := obs.GetComponent()[1].GetInterpretation(); got != nil {
+		t.Errorf("Apply() interpreted component without ranges: %v", got)
+	}
+}
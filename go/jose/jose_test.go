@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jose
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func keys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() returned unexpected error: %v", err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() returned unexpected error: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() returned unexpected error: %v", err)
+	}
+	return map[string]crypto.Signer{ES256: p256, ES384: p384, RS256: rsaKey}
+}
+
+func TestSignVerify(t *testing.T) {
+	payload := []byte(`{"resourceType":"Bundle"}`)
+	for alg, key := range keys(t) {
+		t.Run(alg, func(t *testing.T) {
+			jwk, err := NewJWK(key.Public())
+			if err != nil {
+				t.Fatalf("NewJWK() returned unexpected error: %v", err)
+			}
+			pub, err := jwk.PublicKey()
+			if err != nil {
+				t.Fatalf("PublicKey() returned unexpected error: %v", err)
+			}
+			resolve := func(h Header) (crypto.PublicKey, error) { return pub, nil }
+
+			jws, err := Sign(Header{Kid: jwk.Kid}, payload, key)
+			if err != nil {
+				t.Fatalf("Sign() returned unexpected error: %v", err)
+			}
+			h, got, err := Verify(jws, nil, resolve)
+			if err != nil {
+				t.Fatalf("Verify() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(Header{Alg: alg, Kid: jwk.Kid}, h); diff != "" {
+				t.Errorf("Verify() header diff (-want +got):\n%s", diff)
+			}
+			if string(got) != string(payload) {
+				t.Errorf("Verify() returned payload %s, want %s", got, payload)
+			}
+
+			detached, err := SignDetached(Header{}, payload, key)
+			if err != nil {
+				t.Fatalf("SignDetached() returned unexpected error: %v", err)
+			}
+			if _, _, err := Verify(detached, payload, resolve); err != nil {
+				t.Errorf("Verify() of detached JWS returned unexpected error: %v", err)
+			}
+			if _, _, err := Verify(detached, []byte(`{}`), resolve); err == nil {
+				t.Errorf("Verify() of detached JWS with another payload succeeded, want error")
+			}
+			tampered := jws[:len(jws)-4] + strings.Repeat("A", 4)
+			if _, _, err := Verify(tampered, nil, resolve); err == nil {
+				t.Errorf("Verify() of tampered JWS succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestVerify_AlgorithmMismatch(t *testing.T) {
+	ks := keys(t)
+	jws, err := Sign(Header{}, []byte("x"), ks[ES256])
+	if err != nil {
+		t.Fatalf("Sign() returned unexpected error: %v", err)
+	}
+	resolve := func(h Header) (crypto.PublicKey, error) { return ks[RS256].Public(), nil }
+	if _, _, err := Verify(jws, nil, resolve); err == nil {
+		t.Errorf("Verify() with a key of another algorithm succeeded, want error")
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// The example of RFC 7638, section 3.1.
+	k := JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+	got, err := k.Thumbprint()
+	if err != nil {
+		t.Fatalf("Thumbprint() returned unexpected error: %v", err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; got != want {
+		t.Errorf("Thumbprint() = %q, want %q", got, want)
+	}
+}
+
+func TestFetchKeySet(t *testing.T) {
+	jwk, err := NewJWK(keys(t)[ES256].Public())
+	if err != nil {
+		t.Fatalf("NewJWK() returned unexpected error: %v", err)
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(KeySet{Keys: []JWK{jwk}})
+	}))
+	defer s.Close()
+	set, err := FetchKeySet(context.Background(), nil, s.URL)
+	if err != nil {
+		t.Fatalf("FetchKeySet() returned unexpected error: %v", err)
+	}
+	got, ok := set.Key(jwk.Kid)
+	if !ok {
+		t.Fatalf("Key(%q) found no key", jwk.Kid)
+	}
+	if diff := cmp.Diff(jwk, got); diff != "" {
+		t.Errorf("Key() diff (-want +got):\n%s", diff)
+	}
+}
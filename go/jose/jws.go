@@ -0,0 +1,215 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jose implements the parts of JSON Web Signature (RFC 7515) and
+// JSON Web Key (RFC 7517) that signed FHIR content uses: compact and
+// detached signatures with the ES256, ES384 and RS256 algorithms, and
+// public keys and key sets.
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Algorithms.
+const (
+	ES256 = "ES256"
+	ES384 = "ES384"
+	RS256 = "RS256"
+)
+
+// Header is the protected header of a JWS.
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+	// Zip is the compression of the payload, "DEF" for DEFLATE.
+	Zip string `json:"zip,omitempty"`
+	// X5c is the certificate chain of the key, leaf first, as base64 (not
+	// base64url) DER.
+	X5c []string `json:"x5c,omitempty"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// Sign returns the compact serialization of the JWS of payload signed with
+// key, an *ecdsa.PrivateKey on P-256 or P-384 or an *rsa.PrivateKey, or
+// any crypto.Signer with such a public key. The algorithm of h is set from
+// the key if it is empty.
+func Sign(h Header, payload []byte, key crypto.Signer) (string, error) {
+	alg, err := algorithm(key.Public())
+	if err != nil {
+		return "", err
+	}
+	if h.Alg == "" {
+		h.Alg = alg
+	} else if h.Alg != alg {
+		return "", fmt.Errorf("algorithm %s does not match the key, which uses %s", h.Alg, alg)
+	}
+	hj, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+	input := b64.EncodeToString(hj) + "." + b64.EncodeToString(payload)
+	digest, hash := digest(alg, []byte(input))
+	sig, err := key.Sign(rand.Reader, digest, hash)
+	if err != nil {
+		return "", fmt.Errorf("signing: %w", err)
+	}
+	if pub, ok := key.Public().(*ecdsa.PublicKey); ok {
+		if sig, err = rawECDSA(sig, pub.Curve); err != nil {
+			return "", err
+		}
+	}
+	return input + "." + b64.EncodeToString(sig), nil
+}
+
+// SignDetached returns the JWS of payload signed with key, like Sign, with
+// the payload omitted (RFC 7515, Appendix F).
+func SignDetached(h Header, payload []byte, key crypto.Signer) (string, error) {
+	jws, err := Sign(h, payload, key)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(jws, ".")
+	return parts[0] + ".." + parts[2], nil
+}
+
+// ParseHeader returns the protected header of a JWS in compact
+// serialization, without verifying it.
+func ParseHeader(jws string) (Header, error) {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return Header{}, errors.New("malformed JWS: want 3 parts")
+	}
+	hj, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return Header{}, fmt.Errorf("malformed JWS header: %w", err)
+	}
+	var h Header
+	if err := json.Unmarshal(hj, &h); err != nil {
+		return Header{}, fmt.Errorf("malformed JWS header: %w", err)
+	}
+	return h, nil
+}
+
+// Verify verifies a JWS in compact serialization with the public key key
+// returns for its header, and returns its header and payload. A detached
+// JWS is verified against payload, which must be nil otherwise.
+func Verify(jws string, payload []byte, key func(Header) (crypto.PublicKey, error)) (Header, []byte, error) {
+	h, err := ParseHeader(jws)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	parts := strings.Split(jws, ".")
+	switch {
+	case parts[1] == "" && payload == nil:
+		return Header{}, nil, errors.New("detached JWS verified without a payload")
+	case parts[1] != "" && payload != nil:
+		return Header{}, nil, errors.New("JWS is not detached")
+	case parts[1] != "":
+		if payload, err = b64.DecodeString(parts[1]); err != nil {
+			return Header{}, nil, fmt.Errorf("malformed JWS payload: %w", err)
+		}
+	}
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return Header{}, nil, fmt.Errorf("malformed JWS signature: %w", err)
+	}
+	pub, err := key(h)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	alg, err := algorithm(pub)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	if h.Alg != alg {
+		return Header{}, nil, fmt.Errorf("algorithm %s does not match the key, which uses %s", h.Alg, alg)
+	}
+	digest, hash := digest(alg, []byte(parts[0]+"."+b64.EncodeToString(payload)))
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		size := curveBytes(pub.Curve)
+		if len(sig) != 2*size {
+			return Header{}, nil, errors.New("invalid signature")
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return Header{}, nil, errors.New("invalid signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, hash, digest, sig); err != nil {
+			return Header{}, nil, errors.New("invalid signature")
+		}
+	}
+	return h, payload, nil
+}
+
+// algorithm returns the algorithm of signatures by the private key of pub.
+func algorithm(pub crypto.PublicKey) (string, error) {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return ES256, nil
+		case elliptic.P384():
+			return ES384, nil
+		}
+		return "", fmt.Errorf("unsupported curve %s", pub.Curve.Params().Name)
+	case *rsa.PublicKey:
+		return RS256, nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", pub)
+}
+
+func digest(alg string, input []byte) ([]byte, crypto.Hash) {
+	if alg == ES384 {
+		d := sha512.Sum384(input)
+		return d[:], crypto.SHA384
+	}
+	d := sha256.Sum256(input)
+	return d[:], crypto.SHA256
+}
+
+func curveBytes(c elliptic.Curve) int {
+	return (c.Params().BitSize + 7) / 8
+}
+
+// rawECDSA converts an ASN.1 ECDSA signature to the fixed-size
+// concatenation of r and s that JWS uses.
+func rawECDSA(der []byte, c elliptic.Curve) ([]byte, error) {
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("parsing ECDSA signature: %w", err)
+	}
+	size := curveBytes(c)
+	raw := make([]byte, 2*size)
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+	return raw, nil
+}
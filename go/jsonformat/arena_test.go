@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func TestUnmarshal_Arena(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	arena := &Arena{}
+	ua, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	ua.Arena = arena
+
+	// Enough resources to span several chunks.
+	for i := 0; i < 2*arenaChunkSize; i++ {
+		in := []byte(fmt.Sprintf(`{
+			"resourceType": "Patient",
+			"id": "p%d",
+			"name": [{"family": "Doe", "given": ["Jane", "J"]}, {"text": "J Doe"}],
+			"birthDate": "1970-01-%02d",
+			"_birthDate": {"extension": [{"url": "https://example.com", "valueString": "x"}]},
+			"multipleBirthInteger": %d,
+			"managingOrganization": {"reference": "Organization/o1"}
+		}`, i, i%28+1, i))
+		want, err := u.Unmarshal(in)
+		if err != nil {
+			t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+		}
+		got, err := ua.Unmarshal(in)
+		if err != nil {
+			t.Fatalf("Unmarshal() with Arena returned unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Fatalf("Unmarshal() with Arena diff (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestArena_Reset(t *testing.T) {
+	arena := &Arena{}
+	d := (&d4pb.String{}).ProtoReflect().Descriptor()
+	first := arena.new(d).Interface().(*d4pb.String)
+	first.Value = "x"
+	second := arena.new(d).Interface().(*d4pb.String)
+	if first == second {
+		t.Fatalf("new() returned the same message twice")
+	}
+
+	arena.Reset()
+	if !proto.Equal(first, &d4pb.String{}) {
+		t.Errorf("Reset() left message %v, want it cleared", first)
+	}
+	if got := arena.new(d).Interface().(*d4pb.String); got != first {
+		t.Errorf("new() after Reset() did not reuse the first message")
+	}
+}
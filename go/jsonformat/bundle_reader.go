@@ -0,0 +1,253 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/jsonformat/fhirvalidate"
+	"github.com/google/fhir/go/jsonformat/internal/jsonpbhelper"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// bundleReadBufferSize is the size of the buffer a BundleStreamReader reads
+// its input with. Entries larger than it are read in several reads.
+const bundleReadBufferSize = 64 << 10
+
+// BundleStreamReader reads the entries of a Bundle from JSON one at a time,
+// so that Bundles too large to hold in memory, such as large search results,
+// can be processed with memory bounded by the size of their largest entry:
+//
+//	br, err := u.NewBundleStreamReader(r)
+//	...
+//	for {
+//		entry, err := br.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+// The fields of the Bundle other than its entries are available from Bundle.
+type BundleStreamReader struct {
+	u          *Unmarshaller
+	iter       *jsoniter.Iterator
+	bundle     protoreflect.MessageDescriptor
+	entryField protoreflect.FieldDescriptor
+	// envelope holds the JSON of the fields of the Bundle other than its
+	// entries, as they are read.
+	envelope map[string][]byte
+	// inEntries is set while the entry array is being read, and done once the
+	// Bundle has been read to its end.
+	inEntries, done bool
+	sawEntries      bool
+	entries         int
+	err             error
+}
+
+// NewBundleStreamReader returns a BundleStreamReader reading a Bundle of the
+// version of u from r. Entries are decoded and validated as by Unmarshal,
+// and their resources are kept as raw JSON if u has LazyResources set. The
+// fields of the Bundle preceding its entries are read before
+// NewBundleStreamReader returns.
+func (u *Unmarshaller) NewBundleStreamReader(r io.Reader) (*BundleStreamReader, error) {
+	rcr := u.cfg.newEmptyContainedResource().ProtoReflect()
+	bundleField := rcr.Descriptor().Fields().ByName("bundle")
+	if bundleField == nil || bundleField.Message() == nil {
+		return nil, errors.New("no Bundle resource in this FHIR version")
+	}
+	br := &BundleStreamReader{
+		u:          u,
+		iter:       jsoniter.Parse(jsp, r, bundleReadBufferSize),
+		bundle:     bundleField.Message(),
+		entryField: bundleField.Message().Fields().ByName("entry"),
+		envelope:   map[string][]byte{},
+	}
+	if br.iter.WhatIsNext() != jsoniter.ObjectValue {
+		return nil, br.parseError("expected a Bundle object")
+	}
+	if err := br.readFields(); err != nil {
+		return nil, err
+	}
+	return br, nil
+}
+
+// Next returns the next entry of the Bundle, a message of the Bundle.entry
+// type of the version of the Unmarshaller, and io.EOF once all entries have
+// been read. An entry that fails to decode or validate is returned with the
+// errors Unmarshal would report for it, and reading goes on with the next
+// entry; errors in the JSON of the Bundle itself end the reading.
+func (br *BundleStreamReader) Next() (proto.Message, error) {
+	for br.err == nil && !br.done {
+		if !br.inEntries {
+			br.err = br.readFields()
+			continue
+		}
+		if !br.iter.ReadArray() {
+			if err := br.iterError(); err != nil {
+				br.err = err
+				break
+			}
+			br.inEntries = false
+			continue
+		}
+		raw := br.iter.SkipAndReturnBytes()
+		if err := br.iterError(); err != nil {
+			br.err = err
+			break
+		}
+		path := jsonpbhelper.AddIndexToPath("Bundle.entry", br.entries)
+		br.entries++
+		return br.decode(path, raw, br.entryField.Message())
+	}
+	if br.err != nil {
+		return nil, br.err
+	}
+	return nil, io.EOF
+}
+
+// Bundle returns the Bundle without its entries, with the fields read so
+// far. Fields following the entries in the JSON, which are uncommon, are
+// only included once Next has returned io.EOF.
+func (br *BundleStreamReader) Bundle() (proto.Message, error) {
+	raw := []byte{'{'}
+	for k, v := range br.envelope {
+		if len(raw) > 1 {
+			raw = append(raw, ',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		raw = append(append(append(raw, key...), ':'), v...)
+	}
+	raw = append(raw, '}')
+	return br.decode("Bundle", raw, br.bundle)
+}
+
+// readFields reads the fields of the Bundle up to the start of its entries,
+// or to its end.
+func (br *BundleStreamReader) readFields() error {
+	for {
+		k := br.iter.ReadObject()
+		if err := br.iterError(); err != nil {
+			return err
+		}
+		switch k {
+		case "":
+			br.done = true
+			if _, ok := br.envelope[jsonpbhelper.ResourceTypeField]; !ok {
+				return br.parseError(fmt.Sprintf("missing required field %q", jsonpbhelper.ResourceTypeField))
+			}
+			return nil
+		case "entry":
+			if br.sawEntries {
+				return br.parseError(`duplicate field "entry"`)
+			}
+			br.sawEntries = true
+			if br.iter.ReadNil() {
+				continue
+			}
+			br.inEntries = true
+			return nil
+		case jsonpbhelper.ResourceTypeField:
+			if rt := br.iter.ReadString(); rt != "Bundle" {
+				if err := br.iterError(); err != nil {
+					return err
+				}
+				return &jsonpbhelper.UnmarshalError{
+					Type:        jsonpbhelper.UnknownResourceTypeError,
+					Details:     "expected a Bundle",
+					Diagnostics: fmt.Sprintf("%q", rt),
+				}
+			}
+			br.envelope[k] = []byte(`"Bundle"`)
+		default:
+			br.envelope[k] = br.iter.SkipAndReturnBytes()
+		}
+	}
+}
+
+// decode decodes raw, the JSON object of a message of type desc at path, and
+// validates it.
+func (br *BundleStreamReader) decode(path string, raw []byte, desc protoreflect.MessageDescriptor) (proto.Message, error) {
+	u := br.u
+	pb := u.cfg.newEmptyContainedResource().ProtoReflect()
+	pb = pb.Mutable(pb.Descriptor().Fields().ByName("bundle")).Message()
+	if desc != br.bundle {
+		pb = pb.Get(br.entryField).List().NewElement().Message()
+	}
+	var decmap map[string]json.RawMessage
+	if err := jsp.Unmarshal(raw, &decmap); err != nil {
+		return nil, &jsonpbhelper.UnmarshalError{
+			Type:        jsonpbhelper.InvalidValueError,
+			Path:        path,
+			Details:     fmt.Sprintf("invalid value (expected a %s object)", desc.Name()),
+			Diagnostics: fmt.Sprintf("%.50s", raw),
+		}
+	}
+	delete(decmap, jsonpbhelper.ResourceTypeField)
+	if err := u.mergeMessage(path, decmap, pb); err != nil {
+		return nil, err
+	}
+	m := pb.Interface()
+	er := errorreporter.NewBasicErrorReporter()
+	var err error
+	if u.enableExtendedValidation {
+		err = fhirvalidate.ValidateWithErrorReporter(m, er)
+	} else {
+		err = fhirvalidate.ValidatePrimitivesWithErrorReporter(m, er)
+	}
+	if err != nil {
+		return m, err
+	}
+	var umErrList jsonpbhelper.UnmarshalErrorList
+	for _, e := range er.Errors {
+		if err := jsonpbhelper.AppendUnmarshalError(&umErrList, *e); err != nil {
+			return m, err
+		}
+	}
+	if len(umErrList) > 0 {
+		return m, umErrList
+	}
+	return m, nil
+}
+
+// iterError returns the error of the iterator, if any, as a parsing error.
+func (br *BundleStreamReader) iterError() error {
+	switch err := br.iter.Error; {
+	case err == nil:
+		return nil
+	case err == io.EOF:
+		return br.parseError(io.ErrUnexpectedEOF.Error())
+	default:
+		return br.parseError(err.Error())
+	}
+}
+
+func (br *BundleStreamReader) parseError(diagnostics string) error {
+	return &jsonpbhelper.UnmarshalError{
+		Type:        jsonpbhelper.ParsingError,
+		Details:     "invalid JSON",
+		Diagnostics: diagnostics,
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// readEntries reads all the entries of br, failing the test on errors.
+func readEntries(t *testing.T, br *BundleStreamReader) []proto.Message {
+	t.Helper()
+	var got []proto.Message
+	for {
+		entry, err := br.Next()
+		if err == io.EOF {
+			return got
+		}
+		if err != nil {
+			t.Fatalf("Next() returned unexpected error: %v", err)
+		}
+		got = append(got, entry)
+	}
+}
+
+func TestBundleStreamReader(t *testing.T) {
+	m, err := NewMarshaller(true, "", "  ", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	bundle := &r4pb.Bundle{
+		Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+		Total: &d4pb.UnsignedInt{Value: 3},
+	}
+	var entries []proto.Message
+	for _, id := range []string{"p0", "p1", "p2"} {
+		entries = append(entries, patientEntry(id))
+	}
+	for _, n := range []int{0, 3} {
+		var buf bytes.Buffer
+		bw, err := m.NewBundleWriter(&buf, bundle)
+		if err != nil {
+			t.Fatalf("NewBundleWriter() returned unexpected error: %v", err)
+		}
+		for _, e := range entries[:n] {
+			if err := bw.WriteEntry(e); err != nil {
+				t.Fatalf("WriteEntry() returned unexpected error: %v", err)
+			}
+		}
+		if err := bw.Close(); err != nil {
+			t.Fatalf("Close() returned unexpected error: %v", err)
+		}
+
+		// Reading a byte at a time makes every entry span several reads.
+		br, err := u.NewBundleStreamReader(iotest.OneByteReader(&buf))
+		if err != nil {
+			t.Fatalf("NewBundleStreamReader() returned unexpected error: %v", err)
+		}
+		want := append([]proto.Message(nil), entries[:n]...)
+		if diff := cmp.Diff(want, readEntries(t, br), protocmp.Transform()); diff != "" {
+			t.Errorf("Next() entries diff (-want +got):\n%s", diff)
+		}
+		got, err := br.Bundle()
+		if err != nil {
+			t.Fatalf("Bundle() returned unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(bundle, got, protocmp.Transform()); diff != "" {
+			t.Errorf("Bundle() diff (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestBundleStreamReader_TrailingFields(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	in := `{"entry":[{"fullUrl":"Patient/p0","resource":{"resourceType":"Patient","id":"p0","active":true}}],` +
+		`"type":"searchset","resourceType":"Bundle"}`
+	br, err := u.NewBundleStreamReader(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("NewBundleStreamReader() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]proto.Message{patientEntry("p0")}, readEntries(t, br), protocmp.Transform()); diff != "" {
+		t.Errorf("Next() entries diff (-want +got):\n%s", diff)
+	}
+	got, err := br.Bundle()
+	if err != nil {
+		t.Fatalf("Bundle() returned unexpected error: %v", err)
+	}
+	want := &r4pb.Bundle{Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Bundle() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestBundleStreamReader_InvalidEntry(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	in := `{"resourceType":"Bundle","type":"searchset","entry":[` +
+		`{"resource":{"resourceType":"Patient","id":"p0","active":"yes"}},` +
+		`{"fullUrl":"Patient/p1","resource":{"resourceType":"Patient","id":"p1","active":true}}]}`
+	br, err := u.NewBundleStreamReader(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("NewBundleStreamReader() returned unexpected error: %v", err)
+	}
+	if _, err := br.Next(); err == nil {
+		t.Errorf("Next() of an invalid entry succeeded, want error")
+	}
+	// The invalid entry does not end the reading.
+	if diff := cmp.Diff([]proto.Message{patientEntry("p1")}, readEntries(t, br), protocmp.Transform()); diff != "" {
+		t.Errorf("Next() entries diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestBundleStreamReader_Errors(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"not an object", `[]`},
+		{"not a bundle", `{"resourceType":"Patient","id":"p0"}`},
+		{"no resource type", `{"type":"searchset"}`},
+		{"truncated", `{"resourceType":"Bundle","entry":[{"resource":{"resourceType":"Patient"`},
+		{"duplicate entries", `{"resourceType":"Bundle","entry":[],"entry":[]}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			br, err := u.NewBundleStreamReader(strings.NewReader(tc.in))
+			for err == nil {
+				_, err = br.Next()
+			}
+			if errors.Is(err, io.EOF) {
+				t.Errorf("NewBundleStreamReader(%s) read to the end, want error", tc.in)
+			}
+		})
+	}
+}
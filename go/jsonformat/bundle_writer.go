@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// BundleWriter writes a Bundle as JSON entry by entry, so that large search
+// or history results can be sent before all their entries are produced. The
+// fields of the Bundle other than its entries are written first, and the
+// entries follow in the order they are written.
+type BundleWriter struct {
+	w          io.Writer
+	m          *Marshaller
+	entryField protoreflect.FieldDescriptor
+	// nl, sp, prefix and the indents are the separators of pretty output,
+	// empty otherwise.
+	nl, sp, prefix          string
+	entryIndent, elemIndent string
+	entries                 int
+	closed                  bool
+}
+
+// flusher is implemented by writers, such as http.ResponseWriter, that buffer
+// their output.
+type flusher interface {
+	Flush()
+}
+
+// NewBundleWriter returns a BundleWriter writing bundle, a Bundle of the
+// version of m, to w. Entries already in bundle are written first. Only the
+// Marshallers of the pure JSON format, such as those of NewMarshaller, can
+// write Bundles incrementally.
+func (m *Marshaller) NewBundleWriter(w io.Writer, bundle proto.Message) (*BundleWriter, error) {
+	if m.jsonFormat != formatPure {
+		return nil, errors.New("bundles can only be written incrementally in the pure JSON format")
+	}
+	rb := bundle.ProtoReflect()
+	entryField := rb.Descriptor().Fields().ByName("entry")
+	if rb.Descriptor().Name() != "Bundle" || entryField == nil {
+		return nil, fmt.Errorf("unexpected resource type: %v", rb.Descriptor().Name())
+	}
+	envelope := proto.Clone(bundle).ProtoReflect()
+	envelope.Clear(entryField)
+	head, err := m.MarshalResource(envelope.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	bw := &BundleWriter{w: w, m: m.clone(), entryField: entryField}
+	if m.enableIndent {
+		bw.nl, bw.sp, bw.prefix = "\n", " ", m.prefix
+		bw.entryIndent = m.prefix + m.indent
+		bw.elemIndent = bw.entryIndent + m.indent
+		bw.m.prefix = bw.elemIndent
+	}
+	// Leave the envelope open for the entries.
+	head = bytes.TrimSuffix(head, []byte("}"))
+	head = bytes.TrimSuffix(head, []byte(bw.nl+bw.prefix))
+	if err := bw.write(head); err != nil {
+		return nil, err
+	}
+	entries := rb.Get(entryField).List()
+	for i := 0; i < entries.Len(); i++ {
+		if err := bw.WriteEntry(entries.Get(i).Message().Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return bw, nil
+}
+
+// WriteEntry writes entry, an entry of the Bundle, and flushes w if it
+// buffers its output.
+func (bw *BundleWriter) WriteEntry(entry proto.Message) error {
+	if bw.closed {
+		return errors.New("bundle writer is closed")
+	}
+	if d := entry.ProtoReflect().Descriptor(); d != bw.entryField.Message() {
+		return fmt.Errorf("type mismatch, given proto is a message of type: %v, bundle writer expects message of type: %v", d.FullName(), bw.entryField.Message().FullName())
+	}
+	data, err := bw.m.MarshalElement(entry)
+	if err != nil {
+		return err
+	}
+	var sep string
+	if bw.entries == 0 {
+		sep = "," + bw.nl + bw.entryIndent + `"entry":` + bw.sp + "[" + bw.nl + bw.elemIndent
+	} else {
+		sep = "," + bw.nl + bw.elemIndent
+	}
+	if err := bw.write(append([]byte(sep), data...)); err != nil {
+		return err
+	}
+	bw.entries++
+	if f, ok := bw.w.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// Close ends the Bundle. It does not close the underlying writer.
+func (bw *BundleWriter) Close() error {
+	if bw.closed {
+		return errors.New("bundle writer is closed")
+	}
+	bw.closed = true
+	var tail string
+	if bw.entries > 0 {
+		tail = bw.nl + bw.entryIndent + "]"
+	}
+	tail += bw.nl + bw.prefix + "}"
+	return bw.write([]byte(tail))
+}
+
+func (bw *BundleWriter) write(b []byte) error {
+	_, err := bw.w.Write(b)
+	return err
+}
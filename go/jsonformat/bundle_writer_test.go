@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	r4patientpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+// flushBuffer counts the flushes of a buffer.
+type flushBuffer struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (b *flushBuffer) Flush() {
+	b.flushes++
+}
+
+func patientEntry(id string) *r4pb.Bundle_Entry {
+	return &r4pb.Bundle_Entry{
+		FullUrl: &d4pb.Uri{Value: "Patient/" + id},
+		Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &r4patientpb.Patient{
+			Id:     &d4pb.Id{Value: id},
+			Active: &d4pb.Boolean{Value: true},
+		}}},
+	}
+}
+
+func TestBundleWriter(t *testing.T) {
+	compact, err := NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	pretty, err := NewMarshaller(true, " ", "\t", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	for _, m := range []*Marshaller{compact, pretty} {
+		for _, n := range []int{0, 1, 3} {
+			t.Run(fmt.Sprintf("indent %v, %d entries", m.enableIndent, n), func(t *testing.T) {
+				bundle := &r4pb.Bundle{
+					Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+					Total: &d4pb.UnsignedInt{Value: uint32(n)},
+				}
+				if n > 0 {
+					// Entries of the bundle are written first.
+					bundle.Entry = []*r4pb.Bundle_Entry{patientEntry("p0")}
+				}
+				var out flushBuffer
+				bw, err := m.NewBundleWriter(&out, bundle)
+				if err != nil {
+					t.Fatalf("NewBundleWriter() returned unexpected error: %v", err)
+				}
+				for i := 1; i < n; i++ {
+					e := patientEntry(fmt.Sprintf("p%d", i))
+					bundle.Entry = append(bundle.Entry, e)
+					written := out.Len()
+					if err := bw.WriteEntry(e); err != nil {
+						t.Fatalf("WriteEntry() returned unexpected error: %v", err)
+					}
+					if out.Len() == written {
+						t.Errorf("WriteEntry() wrote nothing before Close()")
+					}
+				}
+				if n > 1 && out.flushes != n {
+					t.Errorf("WriteEntry() flushed %d times, want %d", out.flushes, n)
+				}
+				if err := bw.Close(); err != nil {
+					t.Fatalf("Close() returned unexpected error: %v", err)
+				}
+				if err := bw.WriteEntry(patientEntry("late")); err == nil {
+					t.Errorf("WriteEntry() after Close() succeeded, want error")
+				}
+
+				if !json.Valid(out.Bytes()) {
+					t.Fatalf("BundleWriter wrote invalid JSON:\n%s", out.Bytes())
+				}
+				if m.enableIndent {
+					var want bytes.Buffer
+					if err := json.Indent(&want, compactJSON(t, out.Bytes()), m.prefix, m.indent); err != nil {
+						t.Fatalf("json.Indent() returned unexpected error: %v", err)
+					}
+					if diff := cmp.Diff(want.String(), out.String()); diff != "" {
+						t.Errorf("BundleWriter indentation diff (-want +got):\n%s", diff)
+					}
+				}
+				got, err := u.Unmarshal(out.Bytes())
+				if err != nil {
+					t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+				}
+				want := &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Bundle{Bundle: bundle}}
+				if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+					t.Errorf("BundleWriter diff (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func compactJSON(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := json.Compact(&out, b); err != nil {
+		t.Fatalf("json.Compact() returned unexpected error: %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestNewBundleWriter_Errors(t *testing.T) {
+	m, err := NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	if _, err := m.NewBundleWriter(&bytes.Buffer{}, &r4patientpb.Patient{}); err == nil {
+		t.Errorf("NewBundleWriter() of a Patient succeeded, want error")
+	}
+	am, err := NewAnalyticsMarshaller(0, fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewAnalyticsMarshaller() returned unexpected error: %v", err)
+	}
+	if _, err := am.NewBundleWriter(&bytes.Buffer{}, &r4pb.Bundle{}); err == nil {
+		t.Errorf("NewBundleWriter() of an analytics Marshaller succeeded, want error")
+	}
+	bw, err := m.NewBundleWriter(&bytes.Buffer{}, &r4pb.Bundle{})
+	if err != nil {
+		t.Fatalf("NewBundleWriter() returned unexpected error: %v", err)
+	}
+	if err := bw.WriteEntry(&r4patientpb.Patient{}); err == nil {
+		t.Errorf("WriteEntry() of a Patient succeeded, want error")
+	}
+}
@@ -81,9 +81,11 @@ func parseDateFromStr(date string, l *time.Location, m proto.Message) error {
 
 // parseDateFromJSON parses a FHIR date string into a Date proto message, m.
 func parseDateFromJSON(rm json.RawMessage, l *time.Location, m proto.Message) error {
-	var date string
-	if err := jsonpbhelper.JSP.Unmarshal(rm, &date); err != nil {
-		return err
+	date, ok := jsonpbhelper.UnquoteString(rm)
+	if !ok {
+		if err := jsonpbhelper.JSP.Unmarshal(rm, &date); err != nil {
+			return err
+		}
 	}
 	return parseDateFromStr(date, l, m)
 }
@@ -191,9 +193,11 @@ func parseDateTimeFromStr(date string, l *time.Location, m proto.Message) error
 
 // parseDateTimeFromJSON parses a FHIR date string into a DateTime proto, m.
 func parseDateTimeFromJSON(rm json.RawMessage, l *time.Location, m proto.Message) error {
-	var date string
-	if err := jsonpbhelper.JSP.Unmarshal(rm, &date); err != nil {
-		return err
+	date, ok := jsonpbhelper.UnquoteString(rm)
+	if !ok {
+		if err := jsonpbhelper.JSP.Unmarshal(rm, &date); err != nil {
+			return err
+		}
 	}
 	return parseDateTimeFromStr(date, l, m)
 }
@@ -342,9 +346,11 @@ func serializeTime(t proto.Message) (string, error) {
 // parseInstant parses a FHIR instant string into an Instant proto message, m.
 func parseInstant(rm json.RawMessage, m proto.Message) error {
 	mr := m.ProtoReflect()
-	var instant string
-	if err := jsonpbhelper.JSP.Unmarshal(rm, &instant); err != nil {
-		return err
+	instant, ok := jsonpbhelper.UnquoteString(rm)
+	if !ok {
+		if err := jsonpbhelper.JSP.Unmarshal(rm, &instant); err != nil {
+			return err
+		}
 	}
 	// Instant is dateTime to the precision of at least SECOND and always includes a timezone,
 	// as specified in https://www.hl7.org/fhir/datatypes.html
@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/google/fhir/go/jsonformat/internal/jsonpbhelper"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+)
+
+// valueKind tells how the JSON value of a field is merged into its message.
+type valueKind int
+
+const (
+	// messageValue is a JSON object merged field by field.
+	messageValue valueKind = iota
+	// primitiveValue is a JSON primitive, or an object holding the id and
+	// extensions of a primitive.
+	primitiveValue
+	// referenceValue is a Reference object, normalized once merged.
+	referenceValue
+)
+
+func valueKindOf(f protoreflect.FieldDescriptor) valueKind {
+	d := f.Message()
+	switch {
+	case d == nil:
+		return messageValue
+	case jsonpbhelper.IsPrimitiveType(d):
+		return primitiveValue
+	case proto.HasExtension(d.Options(), apb.E_FhirReferenceType):
+		return referenceValue
+	default:
+		return messageValue
+	}
+}
+
+// fieldDecoder describes how a JSON property is merged into a message.
+type fieldDecoder struct {
+	// field is the field of the message the property is merged into.
+	field protoreflect.FieldDescriptor
+	// isChoice is set for the properties of choice fields, such as
+	// valueQuantity.
+	isChoice bool
+	// choice is the field of the choice type of field the property selects,
+	// or nil if the property names no type of the choice.
+	choice protoreflect.FieldDescriptor
+	// kind is the kind of value of choice, for choice properties, or of field.
+	kind valueKind
+}
+
+// messageDecoder is the decode table of a message type: how each of its JSON
+// properties, keyed by their lower camel case names, is merged.
+type messageDecoder struct {
+	fields map[string]*fieldDecoder
+}
+
+var (
+	messageDecodersMutex = sync.RWMutex{}
+	messageDecoders      = map[protoreflect.MessageDescriptor]*messageDecoder{}
+)
+
+// decoderOf returns the decode table of desc, which is built on first use and
+// shared by all Unmarshallers.
+func decoderOf(desc protoreflect.MessageDescriptor) *messageDecoder {
+	messageDecodersMutex.RLock()
+	dec, ok := messageDecoders[desc]
+	messageDecodersMutex.RUnlock()
+	if ok {
+		return dec
+	}
+
+	dec = buildDecoder(desc)
+	messageDecodersMutex.Lock()
+	defer messageDecodersMutex.Unlock()
+	messageDecoders[desc] = dec
+	return dec
+}
+
+func buildDecoder(desc protoreflect.MessageDescriptor) *messageDecoder {
+	fieldMap := jsonpbhelper.FieldMap(desc)
+	dec := &messageDecoder{fields: make(map[string]*fieldDecoder, len(fieldMap))}
+	for name, f := range fieldMap {
+		fd := &fieldDecoder{field: f}
+		if jsonpbhelper.IsChoice(f.Message()) {
+			fd.isChoice = true
+			fd.choice = jsonpbhelper.FieldMap(f.Message())[choiceFieldName(name, f)]
+			if fd.choice != nil {
+				fd.kind = valueKindOf(fd.choice)
+			}
+		} else {
+			fd.kind = valueKindOf(f)
+		}
+		dec.fields[name] = fd
+	}
+	return dec
+}
+
+// lookup returns how the JSON property k is merged. Upper camel case
+// properties are accepted too.
+func (dec *messageDecoder) lookup(k string) (*fieldDecoder, bool) {
+	if fd, ok := dec.fields[k]; ok {
+		return fd, true
+	}
+	// TODO(b/161479338): reject upper camel case fields names after suitable deprecation warning.
+	fd, ok := dec.fields[normalizeFieldName(k)]
+	return fd, ok
+}
+
+// normalizeFieldName returns the lower camel case form of the JSON property k.
+func normalizeFieldName(k string) string {
+	if strings.HasPrefix(k, "_") {
+		return "_" + lowerFirst(k[1:])
+	}
+	return lowerFirst(k)
+}
+
+// choiceFieldName returns the name, within the choice type of f, of the type
+// selected by name, the lower camel case name of a property of f.
+func choiceFieldName(name string, f protoreflect.FieldDescriptor) string {
+	if strings.HasPrefix(name, "_") {
+		// Convert ex: "_valueString" to "_string".
+		return "_" + lowerFirst(strings.TrimPrefix(name, "_"+f.JSONName()))
+	}
+	// Convert ex: "valueString" to "string".
+	return lowerFirst(strings.TrimPrefix(name, f.JSONName()))
+}
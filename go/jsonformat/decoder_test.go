@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	r4patientpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestDecoderOf(t *testing.T) {
+	dec := decoderOf((&r4patientpb.Patient{}).ProtoReflect().Descriptor())
+	tests := []struct {
+		key      string
+		field    protoreflect.Name
+		isChoice bool
+		choice   protoreflect.Name
+		kind     valueKind
+	}{
+		{key: "gender", field: "gender", kind: primitiveValue},
+		{key: "_birthDate", field: "birth_date", kind: primitiveValue},
+		{key: "managingOrganization", field: "managing_organization", kind: referenceValue},
+		{key: "contact", field: "contact", kind: messageValue},
+		{key: "multipleBirthInteger", field: "multiple_birth", isChoice: true, choice: "integer", kind: primitiveValue},
+		{key: "_multipleBirthBoolean", field: "multiple_birth", isChoice: true, choice: "boolean", kind: primitiveValue},
+		{key: "MultipleBirthInteger", field: "multiple_birth", isChoice: true, choice: "integer", kind: primitiveValue},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			fd, ok := dec.lookup(test.key)
+			if !ok {
+				t.Fatalf("lookup(%q) found no field", test.key)
+			}
+			if fd.field.Name() != test.field || fd.isChoice != test.isChoice || fd.kind != test.kind {
+				t.Errorf("lookup(%q) = {%v, %v, %v}, want {%v, %v, %v}", test.key, fd.field.Name(), fd.isChoice, fd.kind, test.field, test.isChoice, test.kind)
+			}
+			if test.isChoice && (fd.choice == nil || fd.choice.Name() != test.choice) {
+				t.Errorf("lookup(%q) chose %v, want %v", test.key, fd.choice, test.choice)
+			}
+		})
+	}
+	if _, ok := dec.lookup("unknown"); ok {
+		t.Errorf("lookup(%q) found a field, want none", "unknown")
+	}
+	if got := decoderOf((&r4patientpb.Patient{}).ProtoReflect().Descriptor()); got != dec {
+		t.Errorf("decoderOf() built a new table, want the cached one")
+	}
+}
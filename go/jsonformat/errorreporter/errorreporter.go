@@ -20,6 +20,7 @@ package errorreporter
 import (
 	"fmt"
 
+	"github.com/google/fhir/go/fhirerrors"
 	"github.com/google/fhir/go/fhirversion"
 
 	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
@@ -162,7 +163,7 @@ func (oe *OperationErrorReporter) report(elementPath string, err error, typeCode
 		}
 		oe.Outcome.R4Outcome.Issue = append(issues, issue)
 	default:
-		return fmt.Errorf("unsupported FHIR version %s", oe.Outcome.Version)
+		return fmt.Errorf("%w %s", fhirerrors.ErrUnsupportedVersion, oe.Outcome.Version)
 	}
 	return nil
 }
@@ -161,12 +161,14 @@ func validatePrimitives(_ protoreflect.FieldDescriptor, msg protoreflect.Message
 				start = 1
 			}
 			return &jsonpbhelper.UnmarshalError{
+				Type:    jsonpbhelper.InvalidValueError,
 				Details: fmt.Sprintf("non-negative integer out of range %d..2,147,483,647", start),
 			}
 		}
 	case stringRegexMessageNames.Contains(name):
 		if matched := validateStringPrimitiveRegex(msg); !matched {
 			return &jsonpbhelper.UnmarshalError{
+				Type:    jsonpbhelper.InvalidValueError,
 				Details: fmt.Sprintf("invalid %s format", msg.Descriptor().Name()),
 			}
 		}
@@ -174,6 +176,7 @@ func validatePrimitives(_ protoreflect.FieldDescriptor, msg protoreflect.Message
 		val := msg.Get(msg.Descriptor().Fields().ByName("value")).String()
 		if _, err := url.Parse(val); err != nil {
 			return &jsonpbhelper.UnmarshalError{
+				Type:    jsonpbhelper.InvalidValueError,
 				Details: fmt.Sprintf("invalid %s", strings.ToLower(string(msg.Descriptor().Name()))),
 			}
 		}
@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultInternMaxLen is the default length beyond which strings are not
+	// interned.
+	DefaultInternMaxLen = 256
+	// DefaultInternMaxEntries is the default limit on the number of interned
+	// strings.
+	DefaultInternMaxEntries = 1 << 20
+)
+
+// Interner shares the backing memory of the strings that bulk data repeats
+// across resources, such as the systems and codes of codings and the targets
+// of references, so that the decoded resources hold one copy of each. It
+// interns the values of the code, string, uri, url and canonical primitives
+// decoded by an Unmarshaller that has it.
+//
+// Interned strings are kept as long as the Interner, which should be dropped
+// along with the resources decoded with it. The zero Interner is ready for
+// use, and an Interner may be shared by Unmarshallers used concurrently.
+type Interner struct {
+	// MaxLen is the length, in bytes, beyond which strings are not interned;
+	// DefaultInternMaxLen if 0 or less.
+	MaxLen int
+	// MaxEntries is the number of strings beyond which new strings are no
+	// longer interned; DefaultInternMaxEntries if 0 or less.
+	MaxEntries int
+
+	mu sync.RWMutex
+	// strs maps the JSON of strings to their values.
+	strs map[string]string
+
+	hits, misses, saved atomic.Int64
+}
+
+// InternStats are the statistics of an Interner.
+type InternStats struct {
+	// Entries is the number of interned strings.
+	Entries int
+	// Hits and Misses are the numbers of strings found and not found among the
+	// interned strings.
+	Hits, Misses int64
+	// SavedBytes is the size of the strings found, which are not allocated
+	// again.
+	SavedBytes int64
+}
+
+// Stats returns the statistics of in.
+func (in *Interner) Stats() InternStats {
+	in.mu.RLock()
+	entries := len(in.strs)
+	in.mu.RUnlock()
+	return InternStats{
+		Entries:    entries,
+		Hits:       in.hits.Load(),
+		Misses:     in.misses.Load(),
+		SavedBytes: in.saved.Load(),
+	}
+}
+
+// unquote decodes rm, a JSON string, sharing its value with the previous
+// strings of the same JSON.
+func (in *Interner) unquote(rm json.RawMessage) (string, error) {
+	maxLen := in.MaxLen
+	if maxLen <= 0 {
+		maxLen = DefaultInternMaxLen
+	}
+	if len(rm) > maxLen+2 {
+		return unquoteString(rm)
+	}
+	in.mu.RLock()
+	// The conversion of rm is not allocated for the lookup.
+	s, ok := in.strs[string(rm)]
+	in.mu.RUnlock()
+	if ok {
+		in.hits.Add(1)
+		in.saved.Add(int64(len(s)))
+		return s, nil
+	}
+
+	s, err := unquoteString(rm)
+	if err != nil {
+		return "", err
+	}
+	in.misses.Add(1)
+	maxEntries := in.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultInternMaxEntries
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if prev, ok := in.strs[string(rm)]; ok {
+		return prev, nil
+	}
+	if len(in.strs) >= maxEntries {
+		return s, nil
+	}
+	if in.strs == nil {
+		in.strs = map[string]string{}
+	}
+	key := string(rm)
+	if len(key) == len(s)+2 && key[1:len(key)-1] == s {
+		// Strings without escapes share the memory of their key.
+		s = key[1 : len(key)-1]
+	}
+	in.strs[key] = s
+	return s, nil
+}
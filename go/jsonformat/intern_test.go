@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// sameMemory tells whether a and b share their backing memory.
+func sameMemory(a, b string) bool {
+	return (*reflect.StringHeader)(unsafe.Pointer(&a)).Data == (*reflect.StringHeader)(unsafe.Pointer(&b)).Data
+}
+
+func TestUnmarshal_Interner(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	in := &Interner{}
+	ui, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	ui.Interner = in
+
+	var obs []*r4pb.ContainedResource
+	for i := 0; i < 2; i++ {
+		data := []byte(fmt.Sprintf(`{
+			"resourceType": "Observation",
+			"id": "o%d",
+			"status": "final",
+			"code": {"coding": [{"system": "http://loinc.org", "code": "8480-6", "display": "Systolic \"BP\""}]},
+			"subject": {"reference": "Patient/p1"}
+		}`, i))
+		want, err := u.Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+		}
+		got, err := ui.Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal() with Interner returned unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("Unmarshal() with Interner diff (-want +got):\n%s", diff)
+		}
+		obs = append(obs, got.(*r4pb.ContainedResource))
+	}
+
+	first, second := obs[0].GetObservation(), obs[1].GetObservation()
+	for _, s := range []struct {
+		name string
+		a, b string
+	}{
+		{"system", first.GetCode().GetCoding()[0].GetSystem().GetValue(), second.GetCode().GetCoding()[0].GetSystem().GetValue()},
+		{"code", first.GetCode().GetCoding()[0].GetCode().GetValue(), second.GetCode().GetCoding()[0].GetCode().GetValue()},
+		{"display", first.GetCode().GetCoding()[0].GetDisplay().GetValue(), second.GetCode().GetCoding()[0].GetDisplay().GetValue()},
+		{"reference", first.GetSubject().GetPatientId().GetValue(), second.GetSubject().GetPatientId().GetValue()},
+	} {
+		if !sameMemory(s.a, s.b) {
+			t.Errorf("Unmarshal() with Interner did not share the %s %q", s.name, s.a)
+		}
+	}
+	if got := in.Stats(); got.Entries != 4 || got.Hits != 4 || got.Misses != 4 {
+		t.Errorf("Stats() = %+v, want 4 entries, hits and misses", got)
+	}
+}
+
+func TestInterner_Limits(t *testing.T) {
+	in := &Interner{MaxLen: 4, MaxEntries: 1}
+	unquote := func(s string) string {
+		t.Helper()
+		rm, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+		}
+		got, err := in.unquote(rm)
+		if err != nil {
+			t.Fatalf("unquote(%s) returned unexpected error: %v", rm, err)
+		}
+		if got != s {
+			t.Errorf("unquote(%s) = %q, want %q", rm, got, s)
+		}
+		return got
+	}
+	if a, b := unquote("abc"), unquote("abc"); !sameMemory(a, b) {
+		t.Errorf("unquote() did not share %q", a)
+	}
+	// Too long.
+	if a, b := unquote("abcde"), unquote("abcde"); sameMemory(a, b) {
+		t.Errorf("unquote() shared %q longer than MaxLen", a)
+	}
+	// Beyond MaxEntries.
+	if a, b := unquote("xyz"), unquote("xyz"); sameMemory(a, b) {
+		t.Errorf("unquote() shared %q beyond MaxEntries", a)
+	}
+	if _, err := in.unquote(json.RawMessage(`1`)); err == nil {
+		t.Errorf("unquote(1) succeeded, want error")
+	}
+}
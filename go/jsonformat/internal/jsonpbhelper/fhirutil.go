@@ -28,6 +28,7 @@ import (
 	"unicode/utf8"
 
 	"log"
+	"github.com/google/fhir/go/fhirerrors"
 	"github.com/json-iterator/go"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -168,6 +169,9 @@ var (
 	messageFieldsMutex = sync.RWMutex{}
 	messageFields      = map[protoreflect.MessageDescriptor]map[string]protoreflect.FieldDescriptor{}
 
+	enumCodesMutex = sync.RWMutex{}
+	enumCodes      = map[protoreflect.EnumDescriptor]map[string]protoreflect.EnumNumber{}
+
 	// RegexValues stores the proto message full names and the regex validation
 	// for its value fields. This map is supposed to be populated during
 	// initialization (i.e.: func init()), once initialization is done, it should
@@ -210,8 +214,27 @@ const (
 	RequiredFieldError = ErrorType("RequiredFieldError")
 	// ParsingError is the error occurred during json parsing
 	ParsingError = ErrorType("ParsingError")
+	// UnknownFieldError is the error occurred for fields the resource does
+	// not define
+	UnknownFieldError = ErrorType("UnknownFieldError")
+	// UnknownResourceTypeError is the error occurred for missing or unknown
+	// resource types
+	UnknownResourceTypeError = ErrorType("UnknownResourceTypeError")
+	// InvalidValueError is the error occurred for values not valid for their
+	// type
+	InvalidValueError = ErrorType("InvalidValueError")
 )
 
+// errorClasses are the fhirerrors classes of the error types.
+var errorClasses = map[ErrorType]error{
+	ReferenceTypeError:       fhirerrors.ErrInvalidReferenceType,
+	RequiredFieldError:       fhirerrors.ErrMissingRequiredField,
+	ParsingError:             fhirerrors.ErrInvalidJSON,
+	UnknownFieldError:        fhirerrors.ErrUnknownField,
+	UnknownResourceTypeError: fhirerrors.ErrUnknownResourceType,
+	InvalidValueError:        fhirerrors.ErrInvalidValue,
+}
+
 // ErrorSeverity represents different UnmarshalError severity levels.
 type ErrorSeverity string
 
@@ -254,6 +277,12 @@ func (e *UnmarshalError) Unwrap() error {
 	return e.Cause
 }
 
+// Is reports whether target is the fhirerrors class of the Type of e.
+func (e *UnmarshalError) Is(target error) bool {
+	c, ok := errorClasses[e.Type]
+	return ok && c == target
+}
+
 // UnmarshalErrorList is a list of UnmarshalError that implements the Error
 // interface itself.
 type UnmarshalErrorList []*UnmarshalError
@@ -266,6 +295,16 @@ func (el UnmarshalErrorList) Error() string {
 	return strings.Join(msgs, "\n")
 }
 
+// Is reports whether any error of el is target.
+func (el UnmarshalErrorList) Is(target error) bool {
+	for _, e := range el {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsUnmarshalError returns true if the provided error is an UnmarshalError or
 // UnmarshalErrorList.
 func IsUnmarshalError(err error) bool {
@@ -905,11 +944,13 @@ func FullExtensionFieldName(url string) string {
 func ValidateString(s string) error {
 	if len(s) > maxStringSize {
 		return &UnmarshalError{
+			Type:    InvalidValueError,
 			Details: "string exceeds maximum size of 1 MB",
 		}
 	}
 	if matches := invalidStringChars.FindStringSubmatch(s); matches != nil {
 		return &UnmarshalError{
+			Type:    InvalidValueError,
 			Details: fmt.Sprintf("string contains invalid characters: %U", matches[0][0]),
 		}
 	}
@@ -921,7 +962,7 @@ func ValidateString(s string) error {
 func ResourceIDField(ref protoreflect.Message) (protoreflect.FieldDescriptor, error) {
 	od := ref.Descriptor().Oneofs().ByName(RefOneofName)
 	if od == nil {
-		return nil, &UnmarshalError{Details: "unexpected reference"}
+		return nil, &UnmarshalError{Type: InvalidValueError, Details: "unexpected reference"}
 	}
 	f := ref.WhichOneof(od)
 	if f == nil {
@@ -1089,17 +1130,21 @@ func UnmarshalCode(jsonPath string, in protoreflect.Message, rm json.RawMessage)
 	}
 	if !utf8.Valid(rm) {
 		return nil, &UnmarshalError{
+			Type:        InvalidValueError,
 			Path:        jsonPath,
 			Details:     "expected UTF-8 encoding",
 			Diagnostics: fmt.Sprintf("found %q", rm),
 		}
 	}
-	var val string
-	if err := json.Unmarshal([]byte(rm), &val); err != nil {
-		return nil, &UnmarshalError{
-			Path:        jsonPath,
-			Details:     "expected code",
-			Diagnostics: fmt.Sprintf("found %s", rm),
+	val, ok := UnquoteString(rm)
+	if !ok {
+		if err := json.Unmarshal([]byte(rm), &val); err != nil {
+			return nil, &UnmarshalError{
+				Type:        InvalidValueError,
+				Path:        jsonPath,
+				Details:     "expected code",
+				Diagnostics: fmt.Sprintf("found %s", rm),
+			}
 		}
 	}
 	// Create an empty instance of the same type as input proto.
@@ -1109,6 +1154,10 @@ func UnmarshalCode(jsonPath string, in protoreflect.Message, rm json.RawMessage)
 		pb.Set(f, protoreflect.ValueOf(val))
 		return pb.Interface().(proto.Message), nil
 	case protoreflect.EnumKind:
+		if n, ok := codeValues(f.Enum())[val]; ok {
+			pb.Set(f, protoreflect.ValueOfEnum(n))
+			return pb.Interface().(proto.Message), nil
+		}
 		enum := strings.Replace(strings.ToUpper(val), "-", "_", -1)
 		if v := f.Enum().Values().ByName(protoreflect.Name(enum)); v != nil && v.Number() != 0 {
 			pb.Set(f, protoreflect.ValueOf(v.Number()))
@@ -1126,6 +1175,7 @@ func UnmarshalCode(jsonPath string, in protoreflect.Message, rm json.RawMessage)
 		}
 		typeName := f.Enum().FullName().Parent().Name()
 		return nil, &UnmarshalError{
+			Type:        InvalidValueError,
 			Path:        jsonPath,
 			Details:     "code type mismatch",
 			Diagnostics: fmt.Sprintf("%q is not a %s", val, typeName),
@@ -1135,6 +1185,63 @@ func UnmarshalCode(jsonPath string, in protoreflect.Message, rm json.RawMessage)
 	}
 }
 
+// codeValues returns the values of ed, a code enum, by their canonical FHIR
+// codes, i.e. "entered-in-error" for ENTERED_IN_ERROR. It lets UnmarshalCode
+// resolve the usual spelling of a code without case conversion.
+func codeValues(ed protoreflect.EnumDescriptor) map[string]protoreflect.EnumNumber {
+	enumCodesMutex.RLock()
+	codes, ok := enumCodes[ed]
+	enumCodesMutex.RUnlock()
+	if ok {
+		return codes
+	}
+
+	enumCodesMutex.Lock()
+	defer enumCodesMutex.Unlock()
+	codes = map[string]protoreflect.EnumNumber{}
+	values := ed.Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		if v.Number() == 0 {
+			continue
+		}
+		name := string(v.Name())
+		code := strings.ToLower(strings.Replace(name, "_", "-", -1))
+		// Only codes that map back to the name exactly, as UnmarshalCode would
+		// map them, are listed.
+		if strings.Replace(strings.ToUpper(code), "-", "_", -1) == name {
+			codes[code] = v.Number()
+		}
+	}
+	enumCodes[ed] = codes
+	return codes
+}
+
+// UnquoteString returns the contents of rm if it is a JSON string without
+// escape sequences, as almost all FHIR primitive values are, saving a full
+// JSON decode. It returns false for any other JSON value, which callers are
+// expected to decode themselves.
+func UnquoteString(rm json.RawMessage) (string, bool) {
+	n := len(rm)
+	if n < 2 || rm[0] != '"' || rm[n-1] != '"' {
+		return "", false
+	}
+	b := rm[1 : n-1]
+	ascii := true
+	for _, c := range b {
+		switch {
+		case c == '\\' || c == '"' || c < 0x20:
+			return "", false
+		case c >= utf8.RuneSelf:
+			ascii = false
+		}
+	}
+	if !ascii && !utf8.Valid(b) {
+		return "", false
+	}
+	return string(b), true
+}
+
 // FieldMap returns a lookup table for a message's fields from the FHIR JSON
 // field names. Choice fields map to the choice message type.
 func FieldMap(desc protoreflect.MessageDescriptor) map[string]protoreflect.FieldDescriptor {
@@ -17,11 +17,13 @@ package jsonpbhelper
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/google/fhir/go/fhirerrors"
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -1496,3 +1498,93 @@ func TestPrintUnmarshalError_Limit(t *testing.T) {
 		t.Errorf("PrintUnmarshalError(%v, 2) got message %s, want %s", err, got, wantMessage)
 	}
 }
+
+func TestUnmarshalError_Is(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "typed error",
+			err:  &UnmarshalError{Type: UnknownFieldError, Details: "unknown field"},
+			want: fhirerrors.ErrUnknownField,
+		},
+		{
+			name: "wrapped error",
+			err:  fmt.Errorf("reading input: %w", &UnmarshalError{Type: ParsingError, Details: "invalid JSON"}),
+			want: fhirerrors.ErrInvalidJSON,
+		},
+		{
+			name: "error list",
+			err: UnmarshalErrorList{
+				{Type: RequiredFieldError, Details: `missing required field "url"`},
+				{Type: ReferenceTypeError, Details: "invalid reference to a Patient resource, want Organization"},
+			},
+			want: fhirerrors.ErrMissingRequiredField,
+		},
+		{
+			name: "untyped error",
+			err:  &UnmarshalError{Details: "invalid type"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := fhirerrors.Class(test.err); got != test.want {
+				t.Errorf("fhirerrors.Class(%v) = %v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+	el := UnmarshalErrorList{{Type: InvalidValueError}, {Type: ReferenceTypeError}}
+	if !errors.Is(el, fhirerrors.ErrInvalidReferenceType) {
+		t.Errorf("errors.Is(%v, %v) = false, want true", el, fhirerrors.ErrInvalidReferenceType)
+	}
+	if errors.Is(el, fhirerrors.ErrUnknownField) {
+		t.Errorf("errors.Is(%v, %v) = true, want false", el, fhirerrors.ErrUnknownField)
+	}
+}
+
+func TestUnquoteString(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{`"final"`, "final", true},
+		{`""`, "", true},
+		{`"Müller"`, "Müller", true},
+		{`"a\"b"`, "", false},
+		{`"a\nb"`, "", false},
+		{"\"a\tb\"", "", false},
+		{"\"a\xffb\"", "", false},
+		{`"a`, "", false},
+		{`1`, "", false},
+		{`null`, "", false},
+	}
+	for _, test := range tests {
+		got, ok := UnquoteString(json.RawMessage(test.in))
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("UnquoteString(%s) = %q, %v, want %q, %v", test.in, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestUnmarshalCode_Spellings(t *testing.T) {
+	tests := []struct {
+		in   string
+		want c3pb.ObservationStatusCode_Value
+	}{
+		{`"entered-in-error"`, c3pb.ObservationStatusCode_ENTERED_IN_ERROR},
+		{`"Final"`, c3pb.ObservationStatusCode_FINAL},
+		{`"final"`, c3pb.ObservationStatusCode_FINAL},
+	}
+	for _, test := range tests {
+		got, err := UnmarshalCode("status", (&c3pb.ObservationStatusCode{}).ProtoReflect(), json.RawMessage(test.in))
+		if err != nil {
+			t.Fatalf("UnmarshalCode(%s) returned unexpected error: %v", test.in, err)
+		}
+		if diff := cmp.Diff(&c3pb.ObservationStatusCode{Value: test.want}, got, protocmp.Transform()); diff != "" {
+			t.Errorf("UnmarshalCode(%s) diff (-want +got):\n%s", test.in, diff)
+		}
+	}
+}
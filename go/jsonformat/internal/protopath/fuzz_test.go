@@ -0,0 +1,46 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package protopath
+
+import (
+	"testing"
+
+	pptpb "github.com/google/fhir/go/jsonformat/internal/protopath/protopathtest_go_proto"
+)
+
+// FuzzNewPath parses arbitrary paths and uses them to get, set and convert
+// fields of a test message. Errors are expected for most paths; panics are
+// failures.
+func FuzzNewPath(f *testing.F) {
+	for _, s := range []string{
+		"message_field.inner_field",
+		"repeated_message_field.0.inner_field",
+		"-1",
+		"a..b",
+		"oneof_message_field.inner_field",
+		"",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		p := NewPath(s)
+		msg := &pptpb.Message{
+			MessageField:         &pptpb.Message_InnerMessage{InnerField: 1},
+			RepeatedMessageField: []*pptpb.Message_InnerMessage{{InnerField: 2}},
+		}
+		_, _ = Get[int32](msg, p)
+		_ = ToJSONPath(msg, p)
+		_ = Set(msg, p, int32(3))
+	})
+}
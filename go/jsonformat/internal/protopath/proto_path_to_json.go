@@ -33,7 +33,8 @@ func ToJSONPath(p proto.Message, path Path) string {
 
 func toJSON(md protoreflect.MessageDescriptor, path []protoreflect.Name) []string {
 	var jsonParts []string
-	if len(path) == 0 {
+	// Paths may continue past scalar fields, which have no message.
+	if len(path) == 0 || md == nil {
 		return jsonParts
 	}
 
@@ -57,7 +58,7 @@ func toJSON(md protoreflect.MessageDescriptor, path []protoreflect.Name) []strin
 
 	// Extract the JSON representation of the FieldDescriptor.
 	var jsonName string
-	if fd.ContainingOneof() != nil {
+	if fd.ContainingOneof() != nil && fd.Message() != nil {
 		jsonName = "ofType(" + string(fd.Message().Name()) + ")"
 	} else {
 		jsonName = fd.JSONName()
@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/fhir/go/jsonformat/internal/jsonpbhelper"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	anypb "google.golang.org/protobuf/types/known/anypb"
+)
+
+// lazyFieldNumber is the number of the unknown field holding the JSON of a
+// lazy resource. It is the largest field number, which no FHIR proto uses.
+const lazyFieldNumber = protowire.MaxValidNumber
+
+// isLazyTarget tells whether pb, at jsonPath, holds a nested resource that is
+// kept as raw JSON by an Unmarshaller with LazyResources set.
+func (u *Unmarshaller) isLazyTarget(jsonPath string, pb protoreflect.Message) bool {
+	name := pb.Descriptor().Name()
+	return name == u.containedResource ||
+		name == anyProtoName && lastFieldInPath(jsonPath) == jsonpbhelper.ContainedField
+}
+
+// setLazy keeps rm, the JSON object of a resource, as the content of pb.
+func setLazy(pb protoreflect.Message, rm json.RawMessage) {
+	b := protowire.AppendTag(nil, lazyFieldNumber, protowire.BytesType)
+	pb.SetUnknown(protowire.AppendBytes(b, rm))
+}
+
+// lazyJSON returns the JSON of pb if it is a lazy resource.
+func lazyJSON(pb protoreflect.Message) (json.RawMessage, bool) {
+	b := pb.GetUnknown()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, false
+		}
+		b = b[n:]
+		if num == lazyFieldNumber && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, false
+			}
+			return v, true
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return nil, false
+		}
+		b = b[n:]
+	}
+	return nil, false
+}
+
+// LazyJSON returns the JSON of m, a ContainedResource or an Any of a contained
+// resource left undecoded by an Unmarshaller with LazyResources set, and
+// whether m is such a resource.
+func LazyJSON(m proto.Message) ([]byte, bool) {
+	return lazyJSON(m.ProtoReflect())
+}
+
+// DecodeLazy decodes m, a ContainedResource or an Any of a contained resource
+// left undecoded by an Unmarshaller with LazyResources set, in place. The
+// resource is validated as by Unmarshal, and errors are reported relative to
+// it rather than to the resource holding it. Resources nested in m are kept
+// lazy if u has LazyResources set. DecodeLazy does nothing if m is not lazy.
+func (u *Unmarshaller) DecodeLazy(m proto.Message) error {
+	pb := m.ProtoReflect()
+	rm, ok := lazyJSON(pb)
+	if !ok {
+		return nil
+	}
+	cr, err := u.Unmarshal(rm)
+	if err != nil {
+		return err
+	}
+	switch m := m.(type) {
+	case *anypb.Any:
+		if err := m.MarshalFrom(cr); err != nil {
+			return err
+		}
+	default:
+		rcr := cr.ProtoReflect()
+		if pb.Descriptor() != rcr.Descriptor() {
+			return fmt.Errorf("decoding lazy %v: unexpected message type", pb.Descriptor().FullName())
+		}
+		f := rcr.WhichOneof(rcr.Descriptor().Oneofs().ByName(jsonpbhelper.OneofName))
+		pb.Set(f, rcr.Get(f))
+	}
+	pb.SetUnknown(nil)
+	return nil
+}
+
+// isJSONObject tells whether rm, valid JSON, is an object.
+func isJSONObject(rm json.RawMessage) bool {
+	rm = bytes.TrimLeft(rm, " \t\r\n")
+	return len(rm) > 0 && rm[0] == '{'
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	r4patientpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestUnmarshal_LazyResources(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		// lazy returns the lazy resources of the unmarshalled resource.
+		lazy func(cr proto.Message) []proto.Message
+	}{
+		{
+			name: "bundle entries",
+			json: `{
+				"resourceType": "Bundle",
+				"type": "collection",
+				"entry": [
+					{"fullUrl": "Patient/p1", "resource": {"resourceType": "Patient", "id": "p1", "active": true}},
+					{"resource": {"resourceType": "Observation", "id": "o1", "status": "final", "code": {"text": "x"}}}
+				]
+			}`,
+			lazy: func(cr proto.Message) []proto.Message {
+				var res []proto.Message
+				for _, e := range cr.(*r4pb.ContainedResource).GetBundle().GetEntry() {
+					res = append(res, e.GetResource())
+				}
+				return res
+			},
+		},
+		{
+			name: "contained resources",
+			json: `{
+				"resourceType": "Patient",
+				"id": "p1",
+				"contained": [{"resourceType": "Organization", "id": "o1", "name": "Acme"}],
+				"managingOrganization": {"reference": "#o1"}
+			}`,
+			lazy: func(cr proto.Message) []proto.Message {
+				var res []proto.Message
+				for _, c := range cr.(*r4pb.ContainedResource).GetPatient().GetContained() {
+					res = append(res, c)
+				}
+				return res
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := NewUnmarshaller("UTC", fhirversion.R4)
+			if err != nil {
+				t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+			}
+			want, err := u.Unmarshal([]byte(test.json))
+			if err != nil {
+				t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+			}
+			u.LazyResources = true
+			got, err := u.Unmarshal([]byte(test.json))
+			if err != nil {
+				t.Fatalf("Unmarshal() with LazyResources returned unexpected error: %v", err)
+			}
+
+			m, err := NewPrettyMarshaller(fhirversion.R4)
+			if err != nil {
+				t.Fatalf("NewPrettyMarshaller() returned unexpected error: %v", err)
+			}
+			wantJSON, err := m.Marshal(want)
+			if err != nil {
+				t.Fatalf("Marshal() returned unexpected error: %v", err)
+			}
+			gotJSON, err := m.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal() of lazy resources returned unexpected error: %v", err)
+			}
+			// Lazy resources are written as is, so only their content is compared.
+			var wantObj, gotObj any
+			if err := json.Unmarshal(wantJSON, &wantObj); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+			if err := json.Unmarshal(gotJSON, &gotObj); err != nil {
+				t.Fatalf("json.Unmarshal() of lazy resources returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(wantObj, gotObj); diff != "" {
+				t.Errorf("Marshal() of lazy resources diff (-want +got):\n%s", diff)
+			}
+
+			lazy := test.lazy(got)
+			if len(lazy) == 0 {
+				t.Fatalf("no lazy resources in %v", got)
+			}
+			for _, res := range lazy {
+				rm, ok := LazyJSON(res)
+				if !ok || !json.Valid(rm) {
+					t.Errorf("LazyJSON(%v) = %q, %v, want the JSON of the resource", res, rm, ok)
+				}
+				if err := u.DecodeLazy(res); err != nil {
+					t.Fatalf("DecodeLazy() returned unexpected error: %v", err)
+				}
+				if _, ok := LazyJSON(res); ok {
+					t.Errorf("LazyJSON() of decoded resource %v succeeded, want it decoded", res)
+				}
+			}
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("DecodeLazy() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeLazy_Invalid(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	u.LazyResources = true
+	// The Observation lacks its required status, which is only reported once
+	// it is decoded.
+	cr, err := u.Unmarshal([]byte(`{
+		"resourceType": "Bundle",
+		"type": "collection",
+		"entry": [{"resource": {"resourceType": "Observation", "code": {"text": "x"}}}]
+	}`))
+	if err != nil {
+		t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+	}
+	res := cr.(*r4pb.ContainedResource).GetBundle().GetEntry()[0].GetResource()
+	if err := u.DecodeLazy(res); err == nil {
+		t.Errorf("DecodeLazy() succeeded, want error")
+	}
+}
+
+func TestDecodeLazy_NotLazy(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	cr := &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &r4patientpb.Patient{}}}
+	want := proto.Clone(cr)
+	if err := u.DecodeLazy(cr); err != nil {
+		t.Fatalf("DecodeLazy() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, cr, protocmp.Transform()); diff != "" {
+		t.Errorf("DecodeLazy() diff (-want +got):\n%s", diff)
+	}
+}
@@ -634,6 +634,9 @@ func (m *Marshaller) marshalNonPrimitiveFieldValue(f protoreflect.FieldDescripto
 	if jsonpbhelper.IsPrimitiveType(d) {
 		return nil, fmt.Errorf("unexpected primitive type field: %v", f.Name())
 	}
+	if rm, ok := lazyJSON(pb); ok && (d.Name() == containedResourceProtoName(m.cfg) || f.JSONName() == jsonpbhelper.ContainedField) {
+		return m.marshalLazy(rm)
+	}
 	if d.Name() == containedResourceProtoName(m.cfg) {
 		if m.jsonFormat == formatAnalyticV2WithInferredSchema {
 			containedMarshaller := m.clone()
@@ -682,6 +685,20 @@ func (m *Marshaller) marshalNonPrimitiveFieldValue(f protoreflect.FieldDescripto
 	return m.marshalMessageToMap(pb)
 }
 
+// marshalLazy marshals rm, the JSON of a nested resource left undecoded by an
+// Unmarshaller with LazyResources set, which is written as is.
+func (m *Marshaller) marshalLazy(rm []byte) (jsonpbhelper.IsJSON, error) {
+	switch m.jsonFormat {
+	case formatPure:
+		return jsonpbhelper.JSONRawValue(rm), nil
+	case formatAnalyticV2WithInferredSchema:
+		return nil, fmt.Errorf("lazy resources must be decoded with DecodeLazy before being marshalled for analytics")
+	default:
+		// Contained resources are dropped for analytics output
+		return nil, nil
+	}
+}
+
 func (m *Marshaller) marshalReference(rpb protoreflect.Message) (jsonpbhelper.IsJSON, error) {
 	newRef, err := NewDenormalizedReference(rpb.Interface())
 	if err != nil {
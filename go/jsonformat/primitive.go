@@ -18,6 +18,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 
@@ -110,15 +111,90 @@ func parseDecimal(decimal json.RawMessage, m proto.Message) error {
 	if !has {
 		return fmt.Errorf("regex not found for %v type", fn)
 	}
-	if !regex.MatchString(string(decimal)) {
+	if !regex.Match(decimal) {
 		return fmt.Errorf("invalid decimal: %v", decimal)
 	}
-	if err := accessor.SetValue(mr, string(decimal), "value"); err != nil {
-		return err
+	return setPrimitiveValue(mr, protoreflect.ValueOfString(string(decimal)))
+}
+
+// setPrimitiveValue sets the value field of m, a primitive, to v.
+func setPrimitiveValue(m protoreflect.Message, v protoreflect.Value) error {
+	f := m.Descriptor().Fields().ByName("value")
+	if f == nil {
+		return fmt.Errorf("value field not found in proto: %s", m.Descriptor().Name())
 	}
+	m.Set(f, v)
 	return nil
 }
 
+// unquoteString decodes rm, a JSON string. Strings without escape sequences
+// are copied as is rather than decoded.
+func unquoteString(rm json.RawMessage) (string, error) {
+	if s, ok := jsonpbhelper.UnquoteString(rm); ok {
+		return s, nil
+	}
+	var s string
+	err := jsp.Unmarshal(rm, &s)
+	return s, err
+}
+
+// parseBoolean decodes rm, a JSON boolean.
+func parseBoolean(rm json.RawMessage) (bool, error) {
+	switch string(rm) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	var b bool
+	err := jsp.Unmarshal(rm, &b)
+	return b, err
+}
+
+// parseInteger decodes rm, a JSON number holding a 32-bit integer. Canonical
+// integers, without leading zeros, are parsed directly.
+func parseInteger(rm json.RawMessage) (int32, error) {
+	digits, neg := rm, len(rm) > 1 && rm[0] == '-'
+	if neg {
+		digits = rm[1:]
+	}
+	if v, ok := parseDigits(digits, math.MaxInt32+1); ok && !(neg && v == 0) {
+		if neg {
+			return int32(-int64(v)), nil
+		}
+		if v <= math.MaxInt32 {
+			return int32(v), nil
+		}
+	}
+	var v int32
+	err := jsp.Unmarshal(rm, &v)
+	return v, err
+}
+
+// parseCanonicalUint parses rm if it is a canonical unsigned 32-bit integer,
+// "0" or digits without a leading zero.
+func parseCanonicalUint(rm json.RawMessage) (uint32, bool) {
+	v, ok := parseDigits(rm, math.MaxUint32)
+	return uint32(v), ok
+}
+
+// parseDigits parses b, "0" or decimal digits without a leading zero, if its
+// value is at most max.
+func parseDigits(b []byte, max uint64) (uint64, bool) {
+	// Ten digits hold any 32-bit value without overflowing v.
+	if len(b) == 0 || len(b) > 10 || (b[0] == '0' && len(b) > 1) {
+		return 0, false
+	}
+	var v uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		v = v*10 + uint64(c-'0')
+	}
+	return v, v <= max
+}
+
 // Base64BinarySeparatorStrideCreator defines a type of functions that, given the separator string
 // and stride value, returns a new Base64BinarySeparatorStride proto.
 type base64BinarySeparatorStrideCreator func(sep string, stride uint32) proto.Message
@@ -0,0 +1,401 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"github.com/google/fhir/go/jsonformat/internal/jsonpbhelper"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+)
+
+// EstimateSize returns the estimated size, in bytes, of the JSON of res, a
+// resource or a ContainedResource, as written without indentation by the
+// Marshallers of NewMarshaller. The estimate is computed from the message
+// without marshalling it, so that resources can be packed into batches close
+// to a size limit cheaply. It is exact for most resources; the separators of
+// base64Binary values with a separator stride and the escapes of invalid
+// UTF-8 are not accounted for.
+func EstimateSize(res proto.Message) int {
+	return estimateResource(res.ProtoReflect())
+}
+
+// containedResourceName is the name of the ContainedResource of all versions.
+const containedResourceName = "ContainedResource"
+
+// jsonObject accumulates the size of a JSON object.
+type jsonObject struct {
+	size, members int
+}
+
+// add adds a member whose name is n bytes long and whose value is v bytes.
+func (o *jsonObject) add(n, v int) {
+	// The quoted name, its colon and its value.
+	o.size += n + 3 + v
+	o.members++
+}
+
+// total returns the size of the object with its braces and commas.
+func (o *jsonObject) total() int {
+	if o.members == 0 {
+		return 2
+	}
+	return 2 + o.size + o.members - 1
+}
+
+// estimateResource returns the size of pb, a resource or ContainedResource,
+// with its resourceType.
+func estimateResource(pb protoreflect.Message) int {
+	if rm, ok := lazyJSON(pb); ok {
+		return compactSize(rm)
+	}
+	if pb.Descriptor().Name() == containedResourceName {
+		f := pb.WhichOneof(pb.Descriptor().Oneofs().ByName(jsonpbhelper.OneofName))
+		if f == nil || f.Message() == nil {
+			return 0
+		}
+		pb = pb.Get(f).Message()
+	}
+	var o jsonObject
+	o.add(len(jsonpbhelper.ResourceTypeField), quotedSize(string(pb.Descriptor().Name())))
+	estimateFields(&o, pb)
+	return o.total()
+}
+
+func estimateMessage(pb protoreflect.Message) int {
+	var o jsonObject
+	estimateFields(&o, pb)
+	return o.total()
+}
+
+func estimateFields(o *jsonObject, pb protoreflect.Message) {
+	isRef := proto.HasExtension(pb.Descriptor().Options(), apb.E_FhirReferenceType)
+	pb.Range(func(f protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if f.Message() == nil {
+			return true
+		}
+		switch {
+		case isRef && f.ContainingOneof() != nil:
+			o.add(len("reference"), estimateReference(f, v.Message()))
+		case f.IsList():
+			estimateList(o, f, v.List())
+		default:
+			estimateField(o, len(f.JSONName()), f, v.Message())
+		}
+		return true
+	})
+}
+
+// estimateField adds f, whose value is pb and whose name is n bytes long, to
+// o.
+func estimateField(o *jsonObject, n int, f protoreflect.FieldDescriptor, pb protoreflect.Message) {
+	d := f.Message()
+	if jsonpbhelper.IsChoice(d) {
+		// For example, value.quantity is valueQuantity.
+		choice := pb.WhichOneof(d.Oneofs().Get(0))
+		if choice == nil {
+			return
+		}
+		estimateField(o, n+len(choice.JSONName()), choice, pb.Get(choice).Message())
+		return
+	}
+	if jsonpbhelper.IsPrimitiveType(d) {
+		if v, ok := primitiveSize(pb); ok {
+			o.add(n, v)
+		}
+		if ext := primitiveExtensionsSize(pb); ext > 0 {
+			o.add(n+1, ext)
+		}
+		return
+	}
+	o.add(n, estimateValue(f, pb))
+}
+
+// estimateValue returns the size of pb, the value of the non-primitive field
+// f.
+func estimateValue(f protoreflect.FieldDescriptor, pb protoreflect.Message) int {
+	if rm, ok := lazyJSON(pb); ok {
+		return compactSize(rm)
+	}
+	switch {
+	case f.Message().Name() == containedResourceName:
+		return estimateResource(pb)
+	case f.Message().Name() == anyProtoName && f.JSONName() == jsonpbhelper.ContainedField:
+		res, err := anypb.UnmarshalNew(pb.Interface().(*anypb.Any), proto.UnmarshalOptions{})
+		if err != nil {
+			return 0
+		}
+		return estimateResource(res.ProtoReflect())
+	default:
+		return estimateMessage(pb)
+	}
+}
+
+func estimateList(o *jsonObject, f protoreflect.FieldDescriptor, l protoreflect.List) {
+	if l.Len() == 0 {
+		return
+	}
+	n := len(f.JSONName())
+	// Arrays have brackets and a comma between elements.
+	seps := 2 + l.Len() - 1
+	if !jsonpbhelper.IsPrimitiveType(f.Message()) {
+		size := seps
+		for i := 0; i < l.Len(); i++ {
+			size += estimateValue(f, l.Get(i).Message())
+		}
+		o.add(n, size)
+		return
+	}
+	values, exts := seps, seps
+	hasValue, hasExt := false, false
+	for i := 0; i < l.Len(); i++ {
+		pb := l.Get(i).Message()
+		if v, ok := primitiveSize(pb); ok {
+			values += v
+			hasValue = true
+		} else {
+			values += len("null")
+		}
+		if ext := primitiveExtensionsSize(pb); ext > 0 {
+			exts += ext
+			hasExt = true
+		} else {
+			exts += len("null")
+		}
+	}
+	if hasValue {
+		o.add(n, values)
+	}
+	if hasExt {
+		o.add(n+1, exts)
+	}
+}
+
+// estimateReference returns the size of the reference string of a Reference,
+// held by its field f of value pb.
+func estimateReference(f protoreflect.FieldDescriptor, pb protoreflect.Message) int {
+	switch f.Name() {
+	case "uri":
+		v, _ := primitiveSize(pb)
+		return v
+	case "fragment":
+		v, _ := primitiveSize(pb)
+		return v + len(jsonpbhelper.RefFragmentPrefix)
+	}
+	resType, ok := jsonpbhelper.ResourceTypeForReference(f.Name())
+	if !ok {
+		return 0
+	}
+	// For example, "Patient/1/_history/2".
+	size := quotedSize(resType) + 1 + stringSize(stringField(pb, "value"))
+	if history := pb.Descriptor().Fields().ByName("history"); history != nil && pb.Has(history) {
+		size += 2 + len(jsonpbhelper.RefHistory) + stringSize(stringField(pb.Get(history).Message(), "value"))
+	}
+	return size
+}
+
+// primitiveSize returns the size of the value of pb, a primitive, if it has
+// one.
+func primitiveSize(pb protoreflect.Message) (int, bool) {
+	if jsonpbhelper.HasExtension(pb.Interface(), jsonpbhelper.PrimitiveHasNoValueURL) {
+		return 0, false
+	}
+	desc := pb.Descriptor()
+	value := desc.Fields().ByName("value")
+	switch desc.Name() {
+	case "Canonical", "Code", "Markdown", "Oid", "String", "Uri", "Url", "Uuid", "Xhtml", "ReferenceId", "Id", "Decimal":
+		s := pb.Get(value).String()
+		if desc.Name() == "Decimal" {
+			return len(s), true
+		}
+		return quotedSize(s), true
+	case "Boolean":
+		if pb.Get(value).Bool() {
+			return len("true"), true
+		}
+		return len("false"), true
+	case "Integer":
+		i := pb.Get(value).Int()
+		if i < 0 {
+			return 1 + digits(uint64(-i)), true
+		}
+		return digits(uint64(i)), true
+	case "PositiveInt", "UnsignedInt":
+		return digits(pb.Get(value).Uint()), true
+	case "Base64Binary":
+		return 2 + (len(pb.Get(value).Bytes())+2)/3*4, true
+	case "Date", "DateTime", "Instant", "Time":
+		return 2 + timeSize(pb), true
+	}
+	if !proto.HasExtension(desc.Options(), apb.E_FhirValuesetUrl) || value == nil {
+		return 0, false
+	}
+	// Specialized codes.
+	if value.Kind() == protoreflect.StringKind {
+		return quotedSize(pb.Get(value).String()), true
+	}
+	num := pb.Get(value).Enum()
+	if num == 0 {
+		return 0, false
+	}
+	ev := value.Enum().Values().ByNumber(num)
+	if ev == nil {
+		return 0, false
+	}
+	if orig := proto.GetExtension(ev.Options(), apb.E_FhirOriginalCode).(string); orig != "" {
+		return quotedSize(orig), true
+	}
+	// Lower case and dashes keep the length of the enum name.
+	return 2 + len(ev.Name()), true
+}
+
+// timeSize returns the size of the unquoted value of pb, a date, time or
+// instant, from its precision.
+func timeSize(pb protoreflect.Message) int {
+	desc := pb.Descriptor()
+	prec := desc.Fields().ByName("precision")
+	if prec == nil {
+		return 0
+	}
+	ev := prec.Enum().Values().ByNumber(pb.Get(prec).Enum())
+	if ev == nil {
+		return 0
+	}
+	switch ev.Name() {
+	case "YEAR":
+		return len(jsonpbhelper.LayoutYear)
+	case "MONTH":
+		return len(jsonpbhelper.LayoutMonth)
+	case "DAY":
+		return len(jsonpbhelper.LayoutDay)
+	}
+	var size int
+	switch ev.Name() {
+	case "SECOND":
+		size = len(jsonpbhelper.LayoutTimeSecond)
+	case "MILLISECOND":
+		size = len(jsonpbhelper.LayoutTimeMilliSecond)
+	case "MICROSECOND":
+		size = len(jsonpbhelper.LayoutTimeMicroSecond)
+	default:
+		return 0
+	}
+	if desc.Name() == "Time" {
+		return size
+	}
+	// The date, its separator and the offset.
+	size += len(jsonpbhelper.LayoutDay) + 1
+	if stringField(pb, "timezone") == jsonpbhelper.UTC {
+		return size + len(jsonpbhelper.UTC)
+	}
+	return size + len("-07:00")
+}
+
+// primitiveExtensionsSize returns the size of the object holding the id and
+// extensions of pb, a primitive, or 0 if it has neither.
+func primitiveExtensionsSize(pb protoreflect.Message) int {
+	var o jsonObject
+	desc := pb.Descriptor()
+	if id := desc.Fields().ByName("id"); id != nil && pb.Has(id) {
+		o.add(len("id"), quotedSize(stringField(pb.Get(id).Message(), "value")))
+	}
+	if ext := desc.Fields().ByName(jsonpbhelper.Extension); ext != nil {
+		l := pb.Get(ext).List()
+		size, n := 0, 0
+		for i := 0; i < l.Len(); i++ {
+			e := l.Get(i).Message()
+			if url, err := jsonpbhelper.ExtensionURL(e); err == nil && (url == jsonpbhelper.PrimitiveHasNoValueURL || url == jsonpbhelper.Base64BinarySeparatorStrideURL) {
+				continue
+			}
+			size += estimateMessage(e)
+			n++
+		}
+		if n > 0 {
+			o.add(len(jsonpbhelper.Extension), 2+size+n-1)
+		}
+	}
+	if o.members == 0 {
+		return 0
+	}
+	return o.total()
+}
+
+func stringField(pb protoreflect.Message, name protoreflect.Name) string {
+	f := pb.Descriptor().Fields().ByName(name)
+	if f == nil {
+		return ""
+	}
+	return pb.Get(f).String()
+}
+
+// quotedSize returns the size of s as a JSON string.
+func quotedSize(s string) int {
+	return 2 + stringSize(s)
+}
+
+// stringSize returns the size of s in a JSON string, with its escapes.
+func stringSize(s string) int {
+	size := len(s)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\' || c == '\n' || c == '\r' || c == '\t':
+			size++
+		case c < 0x20:
+			// \u00XX
+			size += 5
+		case c == 0xe2 && i+2 < len(s) && s[i+1] == 0x80 && (s[i+2] == 0xa8 || s[i+2] == 0xa9):
+			// U+2028 and U+2029, three bytes escaped as   and  .
+			size += 3
+		}
+	}
+	return size
+}
+
+// compactSize returns the size of rm, valid JSON, without the whitespace
+// between its tokens, as it is written by the Marshallers.
+func compactSize(rm []byte) int {
+	size := 0
+	inString, escaped := false, false
+	for _, c := range rm {
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		case c == '"':
+			inString = true
+		}
+		size++
+	}
+	return size
+}
+
+// digits returns the number of decimal digits of i.
+func digits(i uint64) int {
+	n := 1
+	for i >= 10 {
+		i /= 10
+		n++
+	}
+	return n
+}
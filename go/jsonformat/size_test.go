@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonformat
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+)
+
+func TestEstimateSize(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{
+			name: "patient",
+			json: `{
+				"resourceType": "Patient",
+				"id": "p1",
+				"meta": {"lastUpdated": "2021-03-04T05:06:07.890Z", "versionId": "3"},
+				"active": true,
+				"gender": "female",
+				"birthDate": "1970-01",
+				"_birthDate": {"id": "bd", "extension": [{"url": "https://example.com/precise", "valueDateTime": "1970-01-02T03:04:05+07:00"}]},
+				"name": [{"family": "O\"Brien\\", "given": ["Siobhán", "Line\nBreak ", "Tab\tand\u2028"], "_given": [null, {"id": "g2"}, null]}],
+				"telecom": [{"system": "phone", "value": "555", "rank": 12345}],
+				"multipleBirthInteger": -42,
+				"contained": [{"resourceType": "Organization", "id": "o1", "name": "Acme"}],
+				"managingOrganization": {"reference": "#o1", "display": "Acme"},
+				"generalPractitioner": [
+					{"reference": "Practitioner/pr1/_history/2"},
+					{"reference": "https://example.com/fhir/Practitioner/pr2"},
+					{"reference": "Organization/o2", "type": "Organization"}
+				],
+				"extension": [{"url": "https://example.com/ext", "valueDecimal": 1.50}]
+			}`,
+		},
+		{
+			name: "observation",
+			json: `{
+				"resourceType": "Observation",
+				"id": "bp",
+				"status": "final",
+				"code": {"coding": [{"system": "http://loinc.org", "code": "85354-9"}]},
+				"subject": {"reference": "Patient/p1"},
+				"effectiveDateTime": "2012-09-17T10:00:00.123456-05:00",
+				"issued": "2012-09-17T10:00:00Z",
+				"valueQuantity": {"value": 107.500, "unit": "mmHg", "system": "http://unitsofmeasure.org", "code": "mm[Hg]"},
+				"component": [{"code": {"text": "time"}, "valueTime": "10:11:12.345"}]
+			}`,
+		},
+		{
+			name: "bundle",
+			json: `{
+				"resourceType": "Bundle",
+				"type": "searchset",
+				"total": 2,
+				"entry": [
+					{"fullUrl": "Patient/p1", "resource": {"resourceType": "Patient", "id": "p1"}, "search": {"mode": "match", "score": 0.5}},
+					{"resource": {"resourceType": "Binary", "contentType": "text/plain", "data": "aGVsbG8gd29ybGQ="}}
+				]
+			}`,
+		},
+	}
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	m, err := NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	for _, test := range tests {
+		for _, lazy := range []bool{false, true} {
+			u.LazyResources = lazy
+			cr, err := u.Unmarshal([]byte(test.json))
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) returned unexpected error: %v", test.name, err)
+			}
+			want, err := m.Marshal(cr)
+			if err != nil {
+				t.Fatalf("Marshal(%s) returned unexpected error: %v", test.name, err)
+			}
+			if got := EstimateSize(cr); got != len(want) {
+				t.Errorf("EstimateSize(%s) with lazy resources %v = %d, want %d for %s", test.name, lazy, got, len(want), want)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// Package fuzz_test fuzzes the unmarshaller with arbitrary JSON.
+package fuzz_test
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+)
+
+var seeds = []string{
+	`{"resourceType": "Patient", "id": "p1", "name": [{"family": "Doe", "given": ["Jane"]}], "birthDate": "1970-01-01"}`,
+	`{"resourceType": "Observation", "status": "final", "code": {"text": "x"}, "valueQuantity": {"value": 1.50, "unit": "mg"}, "effectiveDateTime": "2020-01-01T10:00:00+01:00"}`,
+	`{"resourceType": "Bundle", "type": "collection", "entry": [{"resource": {"resourceType": "Patient", "_active": {"extension": [{"url": "http://example.com", "valueBoolean": true}]}}}]}`,
+	`{"resourceType": "Patient", "contained": [{"resourceType": "Organization", "id": "o"}], "managingOrganization": {"reference": "#o"}}`,
+	`{"resourceType": "Binary", "contentType": "text/plain", "data": "aGVsbG8="}`,
+	`{"resourceType": "Patient", "name": [null, {"given": [null, "x"], "_given": [{"id": "a"}, null]}]}`,
+	`{"resourceType": 1}`,
+	`[]`,
+}
+
+// FuzzUnmarshal parses arbitrary JSON as R4 and STU3 resources, with and
+// without validation, and marshals the resources that parse. Errors are
+// expected for most inputs; panics are failures.
+func FuzzUnmarshal(f *testing.F) {
+	for _, s := range seeds {
+		f.Add([]byte(s))
+	}
+	type codec struct {
+		um *jsonformat.Unmarshaller
+		m  *jsonformat.Marshaller
+	}
+	var codecs []codec
+	for _, ver := range []fhirversion.Version{fhirversion.R4, fhirversion.STU3} {
+		m, err := jsonformat.NewMarshaller(false, "", "", ver)
+		if err != nil {
+			f.Fatalf("NewMarshaller(%v) returned unexpected error: %v", ver, err)
+		}
+		um, err := jsonformat.NewUnmarshaller("UTC", ver)
+		if err != nil {
+			f.Fatalf("NewUnmarshaller(%v) returned unexpected error: %v", ver, err)
+		}
+		umNoValidation, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", ver)
+		if err != nil {
+			f.Fatalf("NewUnmarshallerWithoutValidation(%v) returned unexpected error: %v", ver, err)
+		}
+		codecs = append(codecs, codec{um, m}, codec{umNoValidation, m})
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, c := range codecs {
+			res, err := c.um.Unmarshal(data)
+			if err != nil {
+				continue
+			}
+			_, _ = c.m.Marshal(res)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perf_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+)
+
+// retainedBundles is the number of bundles kept in memory to measure their
+// size.
+const retainedBundles = 20
+
+// benchmarkRetained reports the heap retained by the resources of d, with
+// strings interned by in if not nil.
+func benchmarkRetained(b *testing.B, d []byte, in *jsonformat.Interner) {
+	um, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4)
+	if err != nil {
+		b.Fatalf("Failed to create the unmarshaller due to error: %v", err)
+	}
+	b.ReportAllocs()
+	var retained uint64
+	for i := 0; i < b.N; i++ {
+		if in != nil {
+			// Each run starts from an empty Interner, which is part of the
+			// retained heap.
+			in = &jsonformat.Interner{}
+			um.Interner = in
+		}
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		kept := make([]proto.Message, 0, retainedBundles)
+		for j := 0; j < retainedBundles; j++ {
+			res, err := um.Unmarshal(d)
+			if err != nil {
+				b.Fatalf("Failed to unmarshal due to error: %v", err)
+			}
+			kept = append(kept, res)
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		retained += after.HeapAlloc - before.HeapAlloc
+		runtime.KeepAlive(kept)
+		runtime.KeepAlive(in)
+	}
+	b.ReportMetric(float64(retained)/float64(b.N*retainedBundles*bundleSize), "retained-B/resource")
+}
+
+func BenchmarkRetainedPatients(b *testing.B) {
+	benchmarkRetained(b, patientBundle(), nil)
+}
+
+func BenchmarkRetainedPatients_Interned(b *testing.B) {
+	benchmarkRetained(b, patientBundle(), &jsonformat.Interner{})
+}
+
+func BenchmarkRetainedObservations(b *testing.B) {
+	benchmarkRetained(b, observationBundle(), nil)
+}
+
+func BenchmarkRetainedObservations_Interned(b *testing.B) {
+	benchmarkRetained(b, observationBundle(), &jsonformat.Interner{})
+}
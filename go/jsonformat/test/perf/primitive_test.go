@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perf_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+)
+
+const bundleSize = 100
+
+// patientBundle returns a collection Bundle of Patients, which are mostly
+// made of strings and codes.
+func patientBundle() []byte {
+	sb := strings.Builder{}
+	sb.WriteString(`{"resourceType":"Bundle","type":"collection","entry":[`)
+	for i := 0; i < bundleSize; i++ {
+		if i > 0 {
+			sb.WriteRune(',')
+		}
+		fmt.Fprintf(&sb, `{"fullUrl":"urn:uuid:3b0c4a3e-6d4f-4c6b-9d59-%012d","resource":{
+			"resourceType":"Patient","id":"patient-%d","active":true,"gender":"female","birthDate":"1970-01-%02d",
+			"identifier":[{"use":"usual","system":"urn:oid:1.2.36.146.595.217.0.1","value":"%d"}],
+			"name":[{"use":"official","family":"Chalmers","given":["Peter","James"]},{"use":"usual","given":["Jim"]}],
+			"telecom":[{"system":"phone","value":"(03) 5555 6473","use":"work","rank":1},{"system":"email","value":"jim@example.org"}],
+			"address":[{"use":"home","line":["534 Erewhon St"],"city":"PleasantVille","state":"Vic","postalCode":"3999"}],
+			"multipleBirthInteger":%d}}`, i, i, i%28+1, i, i%3)
+	}
+	sb.WriteString("]}")
+	return []byte(sb.String())
+}
+
+// observationBundle returns a collection Bundle of Observations, which are
+// mostly made of codes and decimals.
+func observationBundle() []byte {
+	sb := strings.Builder{}
+	sb.WriteString(`{"resourceType":"Bundle","type":"collection","entry":[`)
+	for i := 0; i < bundleSize; i++ {
+		if i > 0 {
+			sb.WriteRune(',')
+		}
+		fmt.Fprintf(&sb, `{"resource":{
+			"resourceType":"Observation","id":"bp-%d","status":"final",
+			"category":[{"coding":[{"system":"http://terminology.hl7.org/CodeSystem/observation-category","code":"vital-signs"}]}],
+			"code":{"coding":[{"system":"http://loinc.org","code":"85354-9","display":"Blood pressure panel"}]},
+			"subject":{"reference":"Patient/patient-%d"},"effectiveDateTime":"2012-09-17T10:%02d:00+02:00",
+			"component":[
+				{"code":{"coding":[{"system":"http://loinc.org","code":"8480-6"}]},"valueQuantity":{"value":1%02d.5,"unit":"mmHg","system":"http://unitsofmeasure.org","code":"mm[Hg]"}},
+				{"code":{"coding":[{"system":"http://loinc.org","code":"8462-4"}]},"valueQuantity":{"value":%d.25,"unit":"mmHg","system":"http://unitsofmeasure.org","code":"mm[Hg]"}}
+			]}}`, i, i, i%60, i, 60+i%30)
+	}
+	sb.WriteString("]}")
+	return []byte(sb.String())
+}
+
+func benchmarkUnmarshal(b *testing.B, d []byte, enableValidation bool) {
+	benchmarkUnmarshalWithArena(b, d, enableValidation, nil)
+}
+
+// benchmarkUnmarshalWithArena unmarshals d, resetting arena, if not nil, after
+// each bundle.
+func benchmarkUnmarshalWithArena(b *testing.B, d []byte, enableValidation bool, arena *jsonformat.Arena) {
+	var um *jsonformat.Unmarshaller
+	var err error
+	if enableValidation {
+		um, err = jsonformat.NewUnmarshaller("UTC", fhirversion.R4)
+	} else {
+		um, err = jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4)
+	}
+	if err != nil {
+		b.Fatalf("Failed to create the unmarshaller due to error: %v", err)
+	}
+	um.Arena = arena
+	b.ReportAllocs()
+	b.SetBytes(int64(len(d)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := um.Unmarshal(d); err != nil {
+			b.Fatalf("Failed to unmarshal due to error: %v", err)
+		}
+		if arena != nil {
+			arena.Reset()
+		}
+	}
+}
+
+func BenchmarkUnmarshalPatients(b *testing.B) {
+	benchmarkUnmarshal(b, patientBundle(), false)
+}
+
+func BenchmarkUnmarshalPatients_WithValidation(b *testing.B) {
+	benchmarkUnmarshal(b, patientBundle(), true)
+}
+
+func BenchmarkUnmarshalPatients_Arena(b *testing.B) {
+	benchmarkUnmarshalWithArena(b, patientBundle(), false, &jsonformat.Arena{})
+}
+
+func BenchmarkUnmarshalObservations(b *testing.B) {
+	benchmarkUnmarshal(b, observationBundle(), false)
+}
+
+func BenchmarkUnmarshalObservations_WithValidation(b *testing.B) {
+	benchmarkUnmarshal(b, observationBundle(), true)
+}
+
+func BenchmarkUnmarshalObservations_Arena(b *testing.B) {
+	benchmarkUnmarshalWithArena(b, observationBundle(), false, &jsonformat.Arena{})
+}
@@ -19,8 +19,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -30,6 +32,7 @@ import (
 	"github.com/google/fhir/go/jsonformat/internal/accessor"
 	"github.com/google/fhir/go/jsonformat/internal/jsonpbhelper"
 	"github.com/json-iterator/go"
+	"bitbucket.org/creachadair/stringset"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 
@@ -54,11 +57,28 @@ type Unmarshaller struct {
 	// return an error when a resource has a field exceeding this limit. If the value is negative
 	// or 0, then the maximum nesting depth is unbounded.
 	MaxNestingDepth int
+	// Arena, if set, allocates the decoded messages, which are then released
+	// by resetting it. The Unmarshaller must not be used concurrently while
+	// it has an Arena.
+	Arena *Arena
+	// LazyResources keeps the resources nested in others, such as the
+	// resources of Bundle entries and contained resources, as raw JSON until
+	// they are decoded by DecodeLazy. Their ContainedResource or Any is left
+	// empty, and they are neither parsed nor validated with the resource
+	// holding them.
+	LazyResources bool
+	// Interner, if set, shares the strings repeated across the decoded
+	// resources.
+	Interner *Interner
 	// Stores whether extended validation checks like required fields and
 	// reference checking should be run.
 	enableExtendedValidation bool
 	cfg                      config
 	ver                      fhirversion.Version
+	// keysToSkip and containedResource are looked up on every message, so they
+	// are taken from cfg once.
+	keysToSkip        stringset.Set
+	containedResource protoreflect.Name
 }
 
 // NewUnmarshaller returns an Unmarshaller that performs resource validation.
@@ -86,6 +106,8 @@ func newUnmarshaller(tz string, ver fhirversion.Version, enableExtendedValidatio
 		cfg:                      cfg,
 		enableExtendedValidation: enableExtendedValidation,
 		ver:                      ver,
+		keysToSkip:               cfg.keysToSkip(),
+		containedResource:        containedResourceProtoName(cfg),
 	}, nil
 }
 
@@ -119,6 +141,7 @@ func (u *Unmarshaller) UnmarshalWithErrorReporter(in []byte, er errorreporter.Er
 	var decoded map[string]json.RawMessage
 	if err := jsp.Unmarshal(in, &decoded); err != nil {
 		return nil, &jsonpbhelper.UnmarshalError{
+			Type:        jsonpbhelper.ParsingError,
 			Details:     "invalid JSON",
 			Diagnostics: err.Error(),
 			Cause:       err,
@@ -157,6 +180,7 @@ func (u *Unmarshaller) UnmarshalFromReaderWithErrorReporter(in io.Reader, er err
 	decoded, err := readFullResource(in)
 	if err != nil {
 		return nil, &jsonpbhelper.UnmarshalError{
+			Type:        jsonpbhelper.ParsingError,
 			Details:     "invalid JSON",
 			Diagnostics: err.Error(),
 			Cause:       err,
@@ -201,6 +225,7 @@ func (u *Unmarshaller) checkCurrentDepth(jsonPath string) error {
 	depth := strings.Count(jsonPath, ".")
 	if depth > u.MaxNestingDepth {
 		return &jsonpbhelper.UnmarshalError{
+			Type:    jsonpbhelper.ParsingError,
 			Path:    jsonPath,
 			Details: fmt.Sprintf("field exceeded the maximum nesting depth %d", u.MaxNestingDepth),
 		}
@@ -220,6 +245,7 @@ func (u *Unmarshaller) parseContainedResource(jsonPath string, decmap map[string
 	rt, ok := decmap[jsonpbhelper.ResourceTypeField]
 	if !ok {
 		return nil, &jsonpbhelper.UnmarshalError{
+			Type:    jsonpbhelper.UnknownResourceTypeError,
 			Path:    jsonPath,
 			Details: fmt.Sprintf("missing required field %q", jsonpbhelper.ResourceTypeField),
 		}
@@ -227,6 +253,7 @@ func (u *Unmarshaller) parseContainedResource(jsonPath string, decmap map[string
 	var rtstr string
 	if err := jsp.Unmarshal(rt, &rtstr); err != nil {
 		return nil, &jsonpbhelper.UnmarshalError{
+			Type:        jsonpbhelper.UnknownResourceTypeError,
 			Path:        jsonPath,
 			Details:     "invalid resource type",
 			Diagnostics: string(rt),
@@ -251,7 +278,7 @@ func (u *Unmarshaller) parseContainedResource(jsonPath string, decmap map[string
 	for i := 0; i < oneofDesc.Fields().Len(); i++ {
 		f := oneofDesc.Fields().Get(i)
 		if f.Message() != nil && string(f.Message().Name()) == rtstr {
-			if err := u.mergeMessage(jsonPath, decmap, rcr.Mutable(f).Message()); err != nil {
+			if err := u.mergeMessage(jsonPath, decmap, u.mutable(rcr, f)); err != nil {
 				if err := jsonpbhelper.AppendUnmarshalError(&errors, err); err != nil {
 					return nil, err
 				}
@@ -263,6 +290,7 @@ func (u *Unmarshaller) parseContainedResource(jsonPath string, decmap map[string
 		}
 	}
 	return nil, append(errors, &jsonpbhelper.UnmarshalError{
+		Type:        jsonpbhelper.UnknownResourceTypeError,
 		Path:        jsonPath,
 		Details:     fmt.Sprintf("unknown resource type"),
 		Diagnostics: strconv.Quote(rtstr),
@@ -270,9 +298,14 @@ func (u *Unmarshaller) parseContainedResource(jsonPath string, decmap map[string
 }
 
 func (u *Unmarshaller) mergeRawMessage(jsonPath string, rm json.RawMessage, pb protoreflect.Message) error {
+	if u.LazyResources && u.isLazyTarget(jsonPath, pb) && isJSONObject(rm) {
+		setLazy(pb, rm)
+		return nil
+	}
 	var decmap map[string]json.RawMessage
 	if err := jsp.Unmarshal(rm, &decmap); err != nil {
 		return &jsonpbhelper.UnmarshalError{
+			Type:        jsonpbhelper.InvalidValueError,
 			Path:        jsonPath,
 			Details:     fmt.Sprintf("invalid value (expected a %s object)", pb.Descriptor().Name()),
 			Diagnostics: fmt.Sprintf("%.50s", rm),
@@ -289,7 +322,7 @@ func (u *Unmarshaller) mergeMessage(jsonPath string, decmap map[string]json.RawM
 		return fmt.Errorf("nil message for json input: %v", decmap)
 	}
 	pbdesc := pb.Descriptor()
-	if pbdesc.Name() == containedResourceProtoName(u.cfg) {
+	if pbdesc.Name() == u.containedResource {
 		// Special handling of ContainedResource.
 		cr, err := u.parseContainedResource(jsonPath, decmap)
 		if err != nil {
@@ -298,7 +331,7 @@ func (u *Unmarshaller) mergeMessage(jsonPath string, decmap map[string]json.RawM
 		proto.Merge(pb.Interface(), cr)
 		return nil
 	}
-	if pbdesc.Name() == protoName(&anypb.Any{}) && lastFieldInPath(jsonPath) == jsonpbhelper.ContainedField {
+	if pbdesc.Name() == anyProtoName && lastFieldInPath(jsonPath) == jsonpbhelper.ContainedField {
 		// Special handling of inlined resources, with 'contained' JSON field name and Any proto type.
 		cr, err := u.parseContainedResource(jsonPath, decmap)
 		if err != nil {
@@ -312,38 +345,31 @@ func (u *Unmarshaller) mergeMessage(jsonPath string, decmap map[string]json.RawM
 		return nil
 	}
 	var errors jsonpbhelper.UnmarshalErrorList
-	fieldMap := jsonpbhelper.FieldMap(pbdesc)
+	dec := decoderOf(pbdesc)
 	// Iterate through all fields, and merge to the proto.
 	for k, v := range decmap {
-		if u.cfg.keysToSkip().Contains(k) {
+		if u.keysToSkip.Contains(k) {
 			continue
 		}
 
-		// TODO(b/161479338): reject upper camel case fields names after suitable deprecation warning.
-		var normalizedFieldName string
-		if strings.HasPrefix(k, "_") {
-			normalizedFieldName = "_" + lowerFirst(k[1:])
-		} else {
-			normalizedFieldName = lowerFirst(k)
-		}
-
-		f, ok := fieldMap[normalizedFieldName]
+		f, ok := dec.lookup(k)
 		if !ok {
 			errors = append(errors, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.UnknownFieldError,
 				Path:        jsonPath,
 				Details:     "unknown field",
 				Diagnostics: strconv.Quote(k),
 			})
 			continue
 		}
-		if jsonpbhelper.IsChoice(f.Message()) {
+		if f.isChoice {
 			if err := u.mergeChoiceField(jsonPath, f, k, v, pb); err != nil {
 				if err := jsonpbhelper.AppendUnmarshalError(&errors, err); err != nil {
 					return err
 				}
 				continue
 			}
-		} else if err := u.mergeField(jsonpbhelper.AddFieldToPath(jsonPath, k), f, v, pb); err != nil {
+		} else if err := u.mergeField(jsonpbhelper.AddFieldToPath(jsonPath, k), f.field, f.kind, v, pb); err != nil {
 			if err := jsonpbhelper.AppendUnmarshalError(&errors, err); err != nil {
 				return err
 			}
@@ -364,25 +390,11 @@ func lowerFirst(s string) string {
 	return strings.ToLower(s[0:1]) + s[1:]
 }
 
-func (u *Unmarshaller) mergeChoiceField(jsonPath string, f protoreflect.FieldDescriptor, k string, v json.RawMessage, pb protoreflect.Message) error {
-	fieldMap := jsonpbhelper.FieldMap(f.Message())
-
-	// TODO(b/161479338): reject upper camel case fields names after suitable deprecation warning.
-	var choiceFieldName string
-	if strings.HasPrefix(k, "_") {
-		// Convert ex: "_valueString" and "_ValueString" interchangeably to "_string".
-		choiceFieldName = "_" + lowerFirst(k[1:])
-		choiceFieldName = strings.TrimPrefix(choiceFieldName, "_"+f.JSONName())
-		choiceFieldName = "_" + lowerFirst(choiceFieldName)
-	} else {
-		// Convert ex: "ValueString" and "valueString" interchangeably to "string".
-		choiceFieldName = lowerFirst(k)
-		choiceFieldName = lowerFirst(strings.TrimPrefix(choiceFieldName, f.JSONName()))
-	}
-
-	choiceField, ok := fieldMap[choiceFieldName]
-	if !ok {
+func (u *Unmarshaller) mergeChoiceField(jsonPath string, fd *fieldDecoder, k string, v json.RawMessage, pb protoreflect.Message) error {
+	f, choiceField := fd.field, fd.choice
+	if choiceField == nil {
 		return &jsonpbhelper.UnmarshalError{
+			Type:        jsonpbhelper.UnknownFieldError,
 			Path:        jsonPath,
 			Details:     "unknown field",
 			Diagnostics: strconv.Quote(k),
@@ -392,24 +404,26 @@ func (u *Unmarshaller) mergeChoiceField(jsonPath string, f protoreflect.FieldDes
 	choice := pb.Get(f).Message().WhichOneof(choiceField.ContainingOneof())
 	if choice != nil && choiceField.Name() != choice.Name() {
 		return &jsonpbhelper.UnmarshalError{
+			Type:        jsonpbhelper.InvalidValueError,
 			Path:        jsonPath,
 			Details:     fmt.Sprintf("cannot accept multiple values for %s field", string(f.Name())),
 			Diagnostics: strconv.Quote(k),
 		}
 	}
 
-	return u.mergeField(jsonpbhelper.AddFieldToPath(jsonPath, k), choiceField, v, pb.Mutable(f).Message())
+	return u.mergeField(jsonpbhelper.AddFieldToPath(jsonPath, k), choiceField, fd.kind, v, u.mutable(pb, f))
 }
 
-func (u *Unmarshaller) mergeField(jsonPath string, f protoreflect.FieldDescriptor, v json.RawMessage, pb protoreflect.Message) error {
+func (u *Unmarshaller) mergeField(jsonPath string, f protoreflect.FieldDescriptor, kind valueKind, v json.RawMessage, pb protoreflect.Message) error {
 	if err := u.checkCurrentDepth(jsonPath); err != nil {
 		return err
 	}
 	switch f.Cardinality() {
 	case protoreflect.Optional:
 		if pb.Has(f) {
-			if !jsonpbhelper.IsPrimitiveType(f.Message()) {
+			if kind != primitiveValue {
 				return &jsonpbhelper.UnmarshalError{
+					Type:    jsonpbhelper.InvalidValueError,
 					Path:    jsonPath,
 					Details: "invalid extension field",
 				}
@@ -424,22 +438,24 @@ func (u *Unmarshaller) mergeField(jsonPath string, f protoreflect.FieldDescripto
 		// an invalid field such as primitive type's "value" field here.
 		if f.Message() == nil {
 			return &jsonpbhelper.UnmarshalError{
+				Type:    jsonpbhelper.InvalidValueError,
 				Path:    jsonPath,
 				Details: "invalid field",
 			}
 		}
-		if err := u.mergeSingleField(jsonPath, f, v, pb.Mutable(f).Message()); err != nil {
+		if err := u.mergeSingleField(jsonPath, kind, v, u.mutable(pb, f), true); err != nil {
 			return err
 		}
 	case protoreflect.Repeated:
 		var rms []json.RawMessage
 		if err := jsp.Unmarshal(v, &rms); err != nil {
 			return &jsonpbhelper.UnmarshalError{
+				Type:    jsonpbhelper.InvalidValueError,
 				Path:    jsonPath,
 				Details: "expected array",
 			}
 		}
-		if err := u.mergeRepeatedField(jsonPath, f, rms, pb); err != nil {
+		if err := u.mergeRepeatedField(jsonPath, f, kind, rms, pb); err != nil {
 			return err
 		}
 	default:
@@ -448,10 +464,35 @@ func (u *Unmarshaller) mergeField(jsonPath string, f protoreflect.FieldDescripto
 	return nil
 }
 
-func (u *Unmarshaller) mergeRepeatedField(jsonPath string, fd protoreflect.FieldDescriptor, sourceElems []json.RawMessage, targetMsg protoreflect.Message) error {
+// mutable returns the message of the singular message field f of pb, setting
+// it to a new message, from the Arena if any, when unset.
+func (u *Unmarshaller) mutable(pb protoreflect.Message, f protoreflect.FieldDescriptor) protoreflect.Message {
+	if u.Arena != nil && !pb.Has(f) {
+		if m := u.Arena.new(f.Message()); m != nil {
+			pb.Set(f, protoreflect.ValueOfMessage(m))
+			return m
+		}
+	}
+	return pb.Mutable(f).Message()
+}
+
+// appendMutable appends a new message, from the Arena if any, to l, the list
+// of the repeated message field f, and returns it.
+func (u *Unmarshaller) appendMutable(l protoreflect.List, f protoreflect.FieldDescriptor) protoreflect.Message {
+	if u.Arena != nil {
+		if m := u.Arena.new(f.Message()); m != nil {
+			l.Append(protoreflect.ValueOfMessage(m))
+			return m
+		}
+	}
+	return l.AppendMutable().Message()
+}
+
+func (u *Unmarshaller) mergeRepeatedField(jsonPath string, fd protoreflect.FieldDescriptor, kind valueKind, sourceElems []json.RawMessage, targetMsg protoreflect.Message) error {
 	targetList := targetMsg.Mutable(fd).List()
 	if !(targetList.Len() == 0 || targetList.Len() == len(sourceElems)) {
 		return &jsonpbhelper.UnmarshalError{
+			Type:    jsonpbhelper.InvalidValueError,
 			Path:    jsonPath,
 			Details: fmt.Sprintf("array length mismatch, expected %d, found %d", targetList.Len(), len(sourceElems)),
 		}
@@ -459,14 +500,17 @@ func (u *Unmarshaller) mergeRepeatedField(jsonPath string, fd protoreflect.Field
 
 	var errors jsonpbhelper.UnmarshalErrorList
 	fill := targetList.Len() == 0
+	if fill {
+		reserveList(targetMsg, fd, len(sourceElems))
+	}
 	for i, sourceElem := range sourceElems {
 		var targetElem protoreflect.Message
 		if fill {
-			targetElem = targetList.AppendMutable().Message()
+			targetElem = u.appendMutable(targetList, fd)
 		} else {
 			targetElem = targetList.Get(i).Message()
 		}
-		if err := u.mergeSingleField(jsonpbhelper.AddIndexToPath(jsonPath, i), fd, sourceElem, targetElem); err != nil {
+		if err := u.mergeSingleField(jsonpbhelper.AddIndexToPath(jsonPath, i), kind, sourceElem, targetElem, fill); err != nil {
 			if err := jsonpbhelper.AppendUnmarshalError(&errors, err); err != nil {
 				return err
 			}
@@ -479,20 +523,90 @@ func (u *Unmarshaller) mergeRepeatedField(jsonPath string, fd protoreflect.Field
 	return nil
 }
 
-func (u *Unmarshaller) mergeSingleField(jsonPath string, f protoreflect.FieldDescriptor, rm json.RawMessage, pb protoreflect.Message) error {
-	d := f.Message()
-	if jsonpbhelper.IsPrimitiveType(d) {
+type listField struct {
+	t reflect.Type
+	n protoreflect.FieldNumber
+}
+
+var (
+	listFieldsMutex = sync.RWMutex{}
+	// listFields caches the index of the Go struct field backing a repeated
+	// field, or -1 if there is none.
+	listFields = map[listField]int{}
+)
+
+// reserveList presizes fd, an empty repeated field of m, for n elements, so
+// that appending them does not regrow it. Only the fields of generated
+// messages, which are backed by Go slices, are presized.
+func reserveList(m protoreflect.Message, fd protoreflect.FieldDescriptor, n int) {
+	if n < 2 {
+		return
+	}
+	v := reflect.ValueOf(m.Interface())
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	i := listFieldIndex(v.Type(), fd)
+	if i < 0 {
+		return
+	}
+	if f := v.Field(i); f.Len() == 0 && f.Cap() < n {
+		f.Set(reflect.MakeSlice(f.Type(), 0, n))
+	}
+}
+
+func listFieldIndex(t reflect.Type, fd protoreflect.FieldDescriptor) int {
+	key := listField{t, fd.Number()}
+	listFieldsMutex.RLock()
+	i, ok := listFields[key]
+	listFieldsMutex.RUnlock()
+	if ok {
+		return i
+	}
+
+	i = -1
+	tag := "name=" + string(fd.Name())
+	for j := 0; j < t.NumField(); j++ {
+		sf := t.Field(j)
+		if !sf.IsExported() || sf.Type.Kind() != reflect.Slice {
+			continue
+		}
+		for _, part := range strings.Split(sf.Tag.Get("protobuf"), ",") {
+			if part == tag {
+				i = j
+			}
+		}
+	}
+	listFieldsMutex.Lock()
+	defer listFieldsMutex.Unlock()
+	listFields[key] = i
+	return i
+}
+
+// mergeSingleField merges rm, a value of the given kind, into pb. empty tells
+// whether pb is known to be empty, in which case primitives are parsed into it
+// directly.
+func (u *Unmarshaller) mergeSingleField(jsonPath string, kind valueKind, rm json.RawMessage, pb protoreflect.Message, empty bool) error {
+	switch kind {
+	case primitiveValue:
+		if empty {
+			p, err := u.parsePrimitiveTypeInto(jsonPath, pb, rm)
+			if err != nil || p == pb.Interface() {
+				return err
+			}
+			return u.mergePrimitiveType(pb.Interface(), p)
+		}
 		p, err := u.parsePrimitiveType(jsonPath, pb, rm)
 		if err != nil {
 			return err
 		}
 		return u.mergePrimitiveType(pb.Interface(), p)
-	}
-	if !proto.HasExtension(d.Options(), apb.E_FhirReferenceType) {
+	case referenceValue:
+		return u.mergeReference(jsonPath, rm, pb)
+	default:
 		return u.mergeRawMessage(jsonPath, rm, pb)
 	}
-
-	return u.mergeReference(jsonPath, rm, pb)
 }
 
 func (u *Unmarshaller) mergeReference(jsonPath string, rm json.RawMessage, pb protoreflect.Message) error {
@@ -501,6 +615,7 @@ func (u *Unmarshaller) mergeReference(jsonPath string, rm json.RawMessage, pb pr
 	}
 	if err := NormalizeReference(pb.Interface()); err != nil {
 		return &jsonpbhelper.UnmarshalError{
+			Type:        jsonpbhelper.InvalidValueError,
 			Path:        jsonPath,
 			Details:     "invalid reference",
 			Diagnostics: err.Error(),
@@ -559,15 +674,24 @@ func mergePrimitive(dst, src proto.Message) error {
 	return nil
 }
 
+// parsePrimitiveType parses rm into a new message of the primitive type of in,
+// which is not modified.
 func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Message, rm json.RawMessage) (proto.Message, error) {
+	return u.parsePrimitiveTypeInto(jsonPath, in.New(), rm)
+}
+
+// parsePrimitiveTypeInto parses rm into out, an empty primitive, and returns
+// it. Primitive extensions and specialized codes are parsed into a new message
+// of the same type instead, which is returned for the caller to merge.
+func (u *Unmarshaller) parsePrimitiveTypeInto(jsonPath string, out protoreflect.Message, rm json.RawMessage) (proto.Message, error) {
 	// jsoniter doesn't remove the whitespace between an object property and its
 	// value when unmarshaling into a RawMessage. As a result, in {"foo":     "bar"},
 	// rm will contain "    \"bar\"". Trimming does not change the value itself.
 	rm = bytes.TrimSpace(rm)
 	if len(rm) > 0 && (rm[0] == '{' || rm[0] == '[') {
 		// The raw message is a JsonObject, this is a special case for primitive type extensions.
-		// Create an empty instance of the same type as input proto.
-		pb := in.New()
+		// Create an empty instance of the same type as the output proto.
+		pb := out.New()
 		if err := u.mergeRawMessage(jsonPath, rm, pb); err != nil {
 			return nil, err
 		}
@@ -584,19 +708,19 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		}
 		return pb.Interface(), nil
 	}
-	d := in.Descriptor()
-	createAndSetValue := func(val interface{}) (proto.Message, error) {
-		rpb := in.New()
-		if err := accessor.SetValue(rpb, val, "value"); err != nil {
+	d := out.Descriptor()
+	createAndSetValue := func(val protoreflect.Value) (proto.Message, error) {
+		if err := setPrimitiveValue(out, val); err != nil {
 			return nil, err
 		}
-		return rpb.Interface(), nil
+		return out.Interface(), nil
 	}
 	// Make sure string fields have valid UTF-8 encoding.
 	switch d.Name() {
 	case "Code", "Id", "Oid", "String", "Url", "Uri", "Canonical", "Markdown", "Xhtml", "Uuid":
 		if !utf8.Valid(rm) {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected UTF-8 encoding",
 				Diagnostics: fmt.Sprintf("found %q", rm),
@@ -605,9 +729,10 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 	}
 	switch d.Name() {
 	case "Base64Binary":
-		m := in.New().Interface()
+		m := out.Interface()
 		if err := parseBinary(rm, m, u.cfg.newBase64BinarySeparatorStride); err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected binary data",
 				Diagnostics: fmt.Sprintf("found %s", rm),
@@ -615,29 +740,32 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		}
 		return m, nil
 	case "Boolean":
-		var val bool
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := parseBoolean(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected boolean",
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfBool(val))
 	case "Code":
-		var val string
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := u.unquote(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected code",
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfString(val))
 	case "Date":
-		m := in.New().Interface()
+		m := out.Interface()
 		if err := parseDateFromJSON(rm, u.TimeZone, m); err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected date",
 				Diagnostics: fmt.Sprintf("found %s", rm),
@@ -645,9 +773,10 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		}
 		return m, nil
 	case "DateTime":
-		m := in.New().Interface()
+		m := out.Interface()
 		if err := parseDateTimeFromJSON(rm, u.TimeZone, m); err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected datetime",
 				Diagnostics: fmt.Sprintf("found %s", rm),
@@ -655,9 +784,10 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		}
 		return m, nil
 	case "Decimal":
-		m := in.New().Interface()
+		m := out.Interface()
 		if err := parseDecimal(rm, m); err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected decimal",
 				Diagnostics: fmt.Sprintf("found %s", rm),
@@ -665,19 +795,21 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		}
 		return m, nil
 	case "Id":
-		var val string
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := unquoteString(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected ID",
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfString(val))
 	case "Instant":
-		m := in.New().Interface()
+		m := out.Interface()
 		if err := parseInstant(rm, m); err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected instant",
 				Diagnostics: fmt.Sprintf("found %s", rm),
@@ -685,30 +817,36 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		}
 		return m, nil
 	case "Integer":
-		var val int32
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := parseInteger(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected integer",
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfInt32(val))
 	case "Oid":
-		var val string
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := unquoteString(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected OID",
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfString(val))
 	case "PositiveInt":
+		if val, ok := parseCanonicalUint(rm); ok && val > 0 {
+			return createAndSetValue(protoreflect.ValueOfUint32(val))
+		}
 		// Ensure that the JSON object to parse is a positive integer
 		matched := jsonpbhelper.PositiveIntCompiledRegex.MatchString(string(rm))
 		if !matched {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected positive integer",
 				Diagnostics: fmt.Sprintf("found %s", rm),
@@ -717,26 +855,29 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		var val uint32
 		if err := jsp.Unmarshal(rm, &val); err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "invalid positive integer",
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfUint32(val))
 	case "String":
-		var val string
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := u.unquote(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected string",
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfString(val))
 	case "Time":
-		m := in.New().Interface()
+		m := out.Interface()
 		if err := parseTime(rm, m); err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "invalid time",
 				Diagnostics: err.Error(),
@@ -744,10 +885,14 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		}
 		return m, nil
 	case "UnsignedInt":
+		if val, ok := parseCanonicalUint(rm); ok {
+			return createAndSetValue(protoreflect.ValueOfUint32(val))
+		}
 		// Ensure that the JSON object to parse is an unsigned integer
 		matched := jsonpbhelper.UnsignedIntCompiledRegex.MatchString(string(rm))
 		if !matched {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "invalid non-negative integer",
 				Diagnostics: fmt.Sprintf("found %s", rm),
@@ -756,45 +901,50 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 		var val uint32
 		if err := jsp.Unmarshal(rm, &val); err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:    jsonpbhelper.InvalidValueError,
 				Path:    jsonPath,
 				Details: "non-negative integer out of range 0..2,147,483,647",
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfUint32(val))
 	case "Url", "Uri", "Canonical":
 		valType := strings.ToLower(string(d.Name()))
-		var val string
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := u.unquote(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     fmt.Sprintf("expected %s", valType),
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfString(val))
 	case "Markdown", "Xhtml":
 		valType := strings.ToLower(string(d.Name()))
-		var val string
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := unquoteString(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     fmt.Sprintf("expected %s", valType),
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfString(val))
 	case "Uuid":
-		var val string
-		if err := jsp.Unmarshal(rm, &val); err != nil {
+		val, err := unquoteString(rm)
+		if err != nil {
 			return nil, &jsonpbhelper.UnmarshalError{
+				Type:        jsonpbhelper.InvalidValueError,
 				Path:        jsonPath,
 				Details:     "expected UUID",
 				Diagnostics: fmt.Sprintf("found %s", rm),
 			}
 		}
-		return createAndSetValue(val)
+		return createAndSetValue(protoreflect.ValueOfString(val))
 	case "ReferenceId":
 		return nil, &jsonpbhelper.UnmarshalError{
+			Type:    jsonpbhelper.InvalidValueError,
 			Path:    jsonPath,
 			Details: fmt.Sprintf("invalid type: %v", d.Name()),
 		}
@@ -802,11 +952,21 @@ func (u *Unmarshaller) parsePrimitiveType(jsonPath string, in protoreflect.Messa
 
 	// Handles specialized codes.
 	if proto.HasExtension(d.Options(), apb.E_FhirValuesetUrl) {
-		return jsonpbhelper.UnmarshalCode(jsonPath, in, rm)
+		return jsonpbhelper.UnmarshalCode(jsonPath, out, rm)
 	}
 	return nil, fmt.Errorf("unsupported FHIR primitive type: %v", d.Name())
 }
 
+// unquote decodes rm, a JSON string, through the Interner if any.
+func (u *Unmarshaller) unquote(rm json.RawMessage) (string, error) {
+	if u.Interner == nil {
+		return unquoteString(rm)
+	}
+	return u.Interner.unquote(rm)
+}
+
+var anyProtoName = protoName(&anypb.Any{})
+
 func protoName(pb proto.Message) protoreflect.Name {
 	return pb.ProtoReflect().Descriptor().Name()
 }
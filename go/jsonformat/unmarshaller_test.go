@@ -2019,7 +2019,7 @@ func TestUnmarshal_ExtendedValidation_Errors(t *testing.T) {
 					}
 				}]
 			}`,
-			&jsonpbhelper.UnmarshalError{Path: "Bundle.entry[0].resource.ofType(Observation).extension", Details: `expected array`},
+			&jsonpbhelper.UnmarshalError{Path: "Bundle.entry[0].resource.ofType(Observation).extension", Details: `expected array`, Type: jsonpbhelper.InvalidValueError},
 			allVers,
 		},
 		{
@@ -2032,8 +2032,8 @@ func TestUnmarshal_ExtendedValidation_Errors(t *testing.T) {
 					"status": "foo"
 				}]
 			}`,
-			&jsonpbhelper.UnmarshalError{Path: "Patient.contained[0].ofType(Observation).status", Details: `code type mismatch`, Diagnostics: `"foo" is not a ObservationStatusCode`},
-			[]fhirversion.Version{fhirversion.DSTU2, fhirversion.STU3},
+			&jsonpbhelper.UnmarshalError{Path: "Patient.contained[0].ofType(Observation).status", Details: `code type mismatch`, Diagnostics: `"foo" is not a ObservationStatusCode`, Type: jsonpbhelper.InvalidValueError},
+			[]fhirversion.Version{fhirversion.STU3},
 		},
 	}
 	for _, test := range tests {
@@ -17,6 +17,7 @@ package jsonformat
 import (
 	"fmt"
 
+	"github.com/google/fhir/go/fhirerrors"
 	"github.com/google/fhir/go/fhirversion"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -40,7 +41,7 @@ func getConfig(ver fhirversion.Version) (config, error) {
 	case fhirversion.R4:
 		return r4Config{}, nil
 	default:
-		return nil, fmt.Errorf("unsupported FHIR version %s", ver)
+		return nil, fmt.Errorf("%w %s", fhirerrors.ErrUnsupportedVersion, ver)
 	}
 }
 
@@ -0,0 +1,304 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package laborder checks R4 laboratory orders against the
+// ObservationDefinitions and SpecimenDefinitions of a test catalog before
+// they are sent to the performing laboratory: ServiceRequests must order a
+// test of the catalog for a subject its reference intervals cover, and
+// their Specimens must be of a type the test accepts, in the container it
+// requires, of enough volume in a permitted unit and within the time it
+// can be handled.
+//
+// Problems are returned as revalidate Issues, with the paths of the
+// elements at fault, so that they can be reported alongside the issues of
+// other validators.
+package laborder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/fhir/go/codes"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/interpretation"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	odpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_definition_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	srpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/service_request_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/specimen_definition_go_proto"
+	spb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/specimen_go_proto"
+)
+
+// Test is an orderable test of a catalog: the ObservationDefinition of its
+// result and the SpecimenDefinitions of the specimens it accepts.
+type Test struct {
+	Observation *odpb.ObservationDefinition
+	Specimens   []*sdpb.SpecimenDefinition
+}
+
+// Catalog is the test catalog of a laboratory.
+type Catalog []Test
+
+// Find returns the test whose ObservationDefinition has a coding of code,
+// comparing normalized systems.
+func (c Catalog) Find(code *d4pb.CodeableConcept) (Test, bool) {
+	for _, t := range c {
+		if sameConcept(t.Observation.GetCode(), code) {
+			return t, true
+		}
+	}
+	return Test{}, false
+}
+
+// sameConcept reports whether a and b have a coding in common.
+func sameConcept(a, b *d4pb.CodeableConcept) bool {
+	for _, x := range a.GetCoding() {
+		for _, y := range b.GetCoding() {
+			if x.GetCode().GetValue() != "" && x.GetCode().GetValue() == y.GetCode().GetValue() &&
+				fhirtypes.NormalizeSystem(x.GetSystem().GetValue()) == fhirtypes.NormalizeSystem(y.GetSystem().GetValue()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func issue(severity errorreporter.IssueSeverityCode, path, format string, args ...interface{}) revalidate.Issue {
+	return revalidate.Issue{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)}
+}
+
+// CheckRequest returns the issues of the ServiceRequest sr for the subject
+// s: its code must be that of a test of cat, and if the test has reference
+// intervals, one must apply to s when sr was authored, or at now if it has
+// no authoredOn.
+func CheckRequest(sr *srpb.ServiceRequest, s interpretation.Subject, cat Catalog, now time.Time) ([]revalidate.Issue, error) {
+	if sr.GetCode() == nil {
+		return []revalidate.Issue{issue(errorreporter.IssueSeverityError, "ServiceRequest.code", "no test ordered")}, nil
+	}
+	t, ok := cat.Find(sr.GetCode())
+	if !ok {
+		return []revalidate.Issue{issue(errorreporter.IssueSeverityError, "ServiceRequest.code", "%s is not a test of the catalog", display(sr.GetCode()))}, nil
+	}
+	at := now
+	if sr.GetAuthoredOn() != nil {
+		var err error
+		if at, err = fhirtypes.DateTimeToTime(sr.GetAuthoredOn()); err != nil {
+			return nil, fmt.Errorf("ServiceRequest.authoredOn: %w", err)
+		}
+	}
+	var issues []revalidate.Issue
+	reference, covered := false, false
+	for _, qi := range t.Observation.GetQualifiedInterval() {
+		if c := qi.GetCategory().GetValue(); c != c4pb.ObservationRangeCategoryCode_REFERENCE && c != c4pb.ObservationRangeCategoryCode_INVALID_UNINITIALIZED {
+			continue
+		}
+		reference = true
+		if interpretation.Applies(referenceRange(qi), s, at) {
+			covered = true
+			break
+		}
+	}
+	if reference && !covered {
+		issues = append(issues, issue(errorreporter.IssueSeverityWarning, "ServiceRequest.subject", "no reference interval of %s applies to the subject", display(sr.GetCode())))
+	}
+	return issues, nil
+}
+
+// referenceRange returns the reference range of an Observation with the
+// conditions of qi, for interpretation.Applies.
+func referenceRange(qi *odpb.ObservationDefinition_QualifiedInterval) *obspb.Observation_ReferenceRange {
+	r := &obspb.Observation_ReferenceRange{Age: qi.GetAge(), AppliesTo: qi.GetAppliesTo()}
+	if g := qi.GetGender().GetValue(); g != c4pb.AdministrativeGenderCode_INVALID_UNINITIALIZED {
+		gender := fhirtypes.Coding(interpretation.GenderSystem, codes.Code(g))
+		r.AppliesTo = append(append([]*d4pb.CodeableConcept(nil), r.AppliesTo...), &d4pb.CodeableConcept{Coding: []*d4pb.Coding{gender}})
+	}
+	return r
+}
+
+// CheckSpecimen returns the issues of the Specimen sp collected for the
+// ServiceRequest sr: it must be usable and of the subject of sr, its type
+// that a SpecimenDefinition of the test of sr collects or tests, its
+// containers of the type that definition requires, its quantity at least
+// the minimum volume, in a unit convertible to that of the volume, and the
+// time from its collection to its receipt, or to now if it has not been
+// received, within the longest maximum duration of its handling.
+func CheckSpecimen(sp *spb.Specimen, sr *srpb.ServiceRequest, cat Catalog, now time.Time) ([]revalidate.Issue, error) {
+	var issues []revalidate.Issue
+	add := func(severity errorreporter.IssueSeverityCode, path, format string, args ...interface{}) {
+		issues = append(issues, issue(severity, path, format, args...))
+	}
+	switch st := sp.GetStatus().GetValue(); st {
+	case c4pb.SpecimenStatusCode_UNAVAILABLE, c4pb.SpecimenStatusCode_UNSATISFACTORY, c4pb.SpecimenStatusCode_ENTERED_IN_ERROR:
+		add(errorreporter.IssueSeverityError, "Specimen.status", "specimen is %s", codes.Code(st))
+	}
+	if sp.GetSubject() != nil && sr.GetSubject() != nil && !fhirtypes.SameReference(sp.GetSubject(), sr.GetSubject(), "") {
+		add(errorreporter.IssueSeverityError, "Specimen.subject", "specimen is not of the subject of the order")
+	}
+	t, ok := cat.Find(sr.GetCode())
+	if !ok || len(t.Specimens) == 0 {
+		return issues, nil
+	}
+	tt := typeTested(t.Specimens, sp.GetType())
+	if tt == nil {
+		add(errorreporter.IssueSeverityError, "Specimen.type", "%s specimens are not accepted for %s", display(sp.GetType()), display(sr.GetCode()))
+		return issues, nil
+	}
+
+	container := tt.GetContainer()
+	for i, c := range sp.GetContainer() {
+		if container.GetType() != nil && c.GetType() != nil && !sameConcept(container.GetType(), c.GetType()) {
+			add(errorreporter.IssueSeverityError, fmt.Sprintf("Specimen.container[%d].type", i), "container %s is not the required %s", display(c.GetType()), display(container.GetType()))
+		}
+	}
+	if min := container.GetMinimumVolume().GetQuantity(); min != nil {
+		path, q := specimenQuantity(sp)
+		if q != nil {
+			got, err := fhirtypes.ConvertQuantity(fhirtypes.QuantityOfSimple(q), min.GetCode().GetValue())
+			switch {
+			case errors.Is(err, fhirtypes.ErrIncompatibleUnits):
+				add(errorreporter.IssueSeverityError, path, "unit %s is not permitted, want a volume in %s", unitOf(q), unitOf(min))
+			case err != nil:
+				return nil, fmt.Errorf("%s: %w", path, err)
+			default:
+				if o, err := fhirtypes.CompareQuantities(got, fhirtypes.QuantityOfSimple(min)); err == nil && o == fhirtypes.Less {
+					add(errorreporter.IssueSeverityError, path, "%s %s is less than the minimum volume of %s %s",
+						q.GetValue().GetValue(), unitOf(q), min.GetValue().GetValue(), unitOf(min))
+				}
+			}
+		}
+	}
+
+	max, err := maxHandling(tt)
+	if err != nil {
+		return nil, err
+	}
+	collected, ok, err := collectedTime(sp)
+	if err != nil {
+		return nil, err
+	}
+	if max > 0 && ok {
+		end := now
+		if sp.GetReceivedTime() != nil {
+			if end, err = fhirtypes.DateTimeToTime(sp.GetReceivedTime()); err != nil {
+				return nil, fmt.Errorf("Specimen.receivedTime: %w", err)
+			}
+		}
+		if end.Sub(collected) > max {
+			add(errorreporter.IssueSeverityError, "Specimen.collection.collected", "specimen is older than its maximum handling duration of %v", max)
+		}
+	}
+	return issues, nil
+}
+
+// typeTested returns the specimen tested of defs that the specimen type
+// typ is collected or tested as, preferring the first of each definition.
+func typeTested(defs []*sdpb.SpecimenDefinition, typ *d4pb.CodeableConcept) *sdpb.SpecimenDefinition_TypeTested {
+	for _, d := range defs {
+		for _, tt := range d.GetTypeTested() {
+			if sameConcept(tt.GetType(), typ) {
+				return tt
+			}
+		}
+		if sameConcept(d.GetTypeCollected(), typ) && len(d.GetTypeTested()) > 0 {
+			return d.GetTypeTested()[0]
+		}
+	}
+	return nil
+}
+
+// specimenQuantity returns the quantity of sp and its path: that of its
+// first container with one, or else the quantity collected.
+func specimenQuantity(sp *spb.Specimen) (string, *d4pb.SimpleQuantity) {
+	for i, c := range sp.GetContainer() {
+		if c.GetSpecimenQuantity() != nil {
+			return fmt.Sprintf("Specimen.container[%d].specimenQuantity", i), c.GetSpecimenQuantity()
+		}
+	}
+	return "Specimen.collection.quantity", sp.GetCollection().GetQuantity()
+}
+
+// maxHandling returns the longest maximum duration of the handling of tt,
+// or 0 if it has none.
+func maxHandling(tt *sdpb.SpecimenDefinition_TypeTested) (time.Duration, error) {
+	var max time.Duration
+	for _, h := range tt.GetHandling() {
+		d := h.GetMaxDuration()
+		if d == nil {
+			continue
+		}
+		s, err := fhirtypes.ConvertQuantity(&d4pb.Quantity{Value: d.GetValue(), System: d.GetSystem(), Code: d.GetCode()}, "s")
+		if err != nil {
+			return 0, fmt.Errorf("SpecimenDefinition handling maxDuration: %w", err)
+		}
+		f, err := strconv.ParseFloat(s.GetValue().GetValue(), 64)
+		if err != nil {
+			return 0, fmt.Errorf("SpecimenDefinition handling maxDuration: %w", err)
+		}
+		if v := time.Duration(f * float64(time.Second)); v > max {
+			max = v
+		}
+	}
+	return max, nil
+}
+
+// collectedTime returns the time sp was collected: its collected time, or
+// the end of its collection period.
+func collectedTime(sp *spb.Specimen) (time.Time, bool, error) {
+	c := sp.GetCollection().GetCollected()
+	switch {
+	case c.GetDateTime() != nil:
+		t, err := fhirtypes.DateTimeToTime(c.GetDateTime())
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("Specimen.collection.collected: %w", err)
+		}
+		return t, true, nil
+	case c.GetPeriod().GetEnd() != nil:
+		t, err := fhirtypes.DateTimeToTime(c.GetPeriod().GetEnd())
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("Specimen.collection.collected: %w", err)
+		}
+		return t, true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+func unitOf(q *d4pb.SimpleQuantity) string {
+	if u := q.GetUnit().GetValue(); u != "" {
+		return u
+	}
+	return q.GetCode().GetValue()
+}
+
+// display returns the text of cc, or the display or code of its first
+// coding.
+func display(cc *d4pb.CodeableConcept) string {
+	if t := cc.GetText().GetValue(); t != "" {
+		return t
+	}
+	for _, c := range cc.GetCoding() {
+		if d := c.GetDisplay().GetValue(); d != "" {
+			return d
+		}
+		if code := c.GetCode().GetValue(); code != "" {
+			return code
+		}
+	}
+	return "unknown"
+}
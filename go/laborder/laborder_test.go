@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package laborder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/interpretation"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+	"github.com/google/go-cmp/cmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	odpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_definition_go_proto"
+	srpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/service_request_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/specimen_definition_go_proto"
+	spb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/specimen_go_proto"
+)
+
+const (
+	loinc = "http://loinc.org"
+	v2    = "http://terminology.hl7.org/CodeSystem/v2-0487"
+)
+
+func concept(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(system, code)}}
+}
+
+func simple(value, code string) *d4pb.SimpleQuantity {
+	return &d4pb.SimpleQuantity{Value: fhirtypes.Decimal(value), System: fhirtypes.URI("http://unitsofmeasure.org"), Code: fhirtypes.Code(code)}
+}
+
+func dateTime(t time.Time) *d4pb.DateTime {
+	return fhirtypes.DateTimeFromTime(t, d4pb.DateTime_SECOND)
+}
+
+var (
+	now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// psa is a prostate-specific antigen test with a reference interval
+	// for men of 40 years and older, on serum from a red-top tube kept for
+	// at most 24 hours.
+	psa = Test{
+		Observation: &odpb.ObservationDefinition{
+			Code: concept(loinc, "2857-1"),
+			QualifiedInterval: []*odpb.ObservationDefinition_QualifiedInterval{{
+				Category: &odpb.ObservationDefinition_QualifiedInterval_CategoryCode{Value: c4pb.ObservationRangeCategoryCode_REFERENCE},
+				Gender:   &odpb.ObservationDefinition_QualifiedInterval_GenderCode{Value: c4pb.AdministrativeGenderCode_MALE},
+				Age:      &d4pb.Range{Low: simple("40", "a")},
+			}},
+		},
+		Specimens: []*sdpb.SpecimenDefinition{{
+			TypeCollected: concept(v2, "BLD"),
+			TypeTested: []*sdpb.SpecimenDefinition_TypeTested{{
+				Type: concept(v2, "SER"),
+				Container: &sdpb.SpecimenDefinition_TypeTested_Container{
+					Type: concept("http://snomed.info/sct", "702281005"),
+					MinimumVolume: &sdpb.SpecimenDefinition_TypeTested_Container_MinimumVolumeX{
+						Choice: &sdpb.SpecimenDefinition_TypeTested_Container_MinimumVolumeX_Quantity{Quantity: simple("2", "mL")},
+					},
+				},
+				Handling: []*sdpb.SpecimenDefinition_TypeTested_Handling{
+					{MaxDuration: &d4pb.Duration{Value: fhirtypes.Decimal("4"), System: fhirtypes.URI("http://unitsofmeasure.org"), Code: fhirtypes.Code("h")}},
+					{MaxDuration: &d4pb.Duration{Value: fhirtypes.Decimal("1"), System: fhirtypes.URI("http://unitsofmeasure.org"), Code: fhirtypes.Code("d")}},
+				},
+			}},
+		}},
+	}
+	catalog = Catalog{psa}
+
+	order = &srpb.ServiceRequest{
+		Code:       concept("urn:oid:2.16.840.1.113883.6.1", "2857-1"),
+		Subject:    fhirtypes.Reference("Patient", "p1"),
+		AuthoredOn: dateTime(now),
+	}
+)
+
+func TestCheckRequest(t *testing.T) {
+	man := interpretation.Subject{BirthDate: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), Gender: "male"}
+	tests := []struct {
+		name    string
+		sr      *srpb.ServiceRequest
+		subject interpretation.Subject
+		want    []revalidate.Issue
+	}{
+		{"covered", order, man, nil},
+		{
+			"not covered",
+			order,
+			interpretation.Subject{BirthDate: man.BirthDate, Gender: "female"},
+			[]revalidate.Issue{{Path: "ServiceRequest.subject", Severity: errorreporter.IssueSeverityWarning, Message: "no reference interval of 2857-1 applies to the subject"}},
+		},
+		{
+			"unknown test",
+			&srpb.ServiceRequest{Code: &d4pb.CodeableConcept{Text: fhirtypes.String("Glucose")}},
+			man,
+			[]revalidate.Issue{{Path: "ServiceRequest.code", Severity: errorreporter.IssueSeverityError, Message: "Glucose is not a test of the catalog"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CheckRequest(tc.sr, tc.subject, catalog, now)
+			if err != nil {
+				t.Fatalf("CheckRequest() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("CheckRequest() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckSpecimen(t *testing.T) {
+	specimen := func(edit func(*spb.Specimen)) *spb.Specimen {
+		sp := &spb.Specimen{
+			Status:  &spb.Specimen_StatusCode{Value: c4pb.SpecimenStatusCode_AVAILABLE},
+			Type:    concept(v2, "SER"),
+			Subject: fhirtypes.Reference("Patient", "p1"),
+			Collection: &spb.Specimen_Collection{
+				Collected: &spb.Specimen_Collection_CollectedX{Choice: &spb.Specimen_Collection_CollectedX_DateTime{DateTime: dateTime(now.Add(-2 * time.Hour))}},
+				Quantity:  simple("5", "mL"),
+			},
+			Container: []*spb.Specimen_Container{{Type: concept("http://snomed.info/sct", "702281005")}},
+		}
+		if edit != nil {
+			edit(sp)
+		}
+		return sp
+	}
+	tests := []struct {
+		name string
+		sp   *spb.Specimen
+		want []revalidate.Issue
+	}{
+		{"valid", specimen(nil), nil},
+		{"collected type", specimen(func(sp *spb.Specimen) { sp.Type = concept(v2, "BLD") }), nil},
+		{"volume in liters", specimen(func(sp *spb.Specimen) { sp.Collection.Quantity = simple("0.004", "L") }), nil},
+		{
+			"wrong type",
+			specimen(func(sp *spb.Specimen) { sp.Type = concept(v2, "UR") }),
+			[]revalidate.Issue{{Path: "Specimen.type", Severity: errorreporter.IssueSeverityError, Message: "UR specimens are not accepted for 2857-1"}},
+		},
+		{
+			"wrong container and subject",
+			specimen(func(sp *spb.Specimen) {
+				sp.Container[0].Type = concept("http://snomed.info/sct", "702120003")
+				sp.Subject = fhirtypes.Reference("Patient", "p2")
+			}),
+			[]revalidate.Issue{
+				{Path: "Specimen.subject", Severity: errorreporter.IssueSeverityError, Message: "specimen is not of the subject of the order"},
+				{Path: "Specimen.container[0].type", Severity: errorreporter.IssueSeverityError, Message: "container 702120003 is not the required 702281005"},
+			},
+		},
+		{
+			"unit not permitted",
+			specimen(func(sp *spb.Specimen) { sp.Collection.Quantity = simple("5", "g") }),
+			[]revalidate.Issue{{Path: "Specimen.collection.quantity", Severity: errorreporter.IssueSeverityError, Message: "unit g is not permitted, want a volume in mL"}},
+		},
+		{
+			"too little",
+			specimen(func(sp *spb.Specimen) { sp.Container[0].SpecimenQuantity = simple("1.5", "mL") }),
+			[]revalidate.Issue{{Path: "Specimen.container[0].specimenQuantity", Severity: errorreporter.IssueSeverityError, Message: "1.5 mL is less than the minimum volume of 2 mL"}},
+		},
+		{
+			"too old",
+			specimen(func(sp *spb.Specimen) {
+				sp.Status = &spb.Specimen_StatusCode{Value: c4pb.SpecimenStatusCode_UNSATISFACTORY}
+				sp.ReceivedTime = dateTime(now.Add(24 * time.Hour))
+			}),
+			[]revalidate.Issue{
+				{Path: "Specimen.status", Severity: errorreporter.IssueSeverityError, Message: "specimen is unsatisfactory"},
+				{Path: "Specimen.collection.collected", Severity: errorreporter.IssueSeverityError, Message: "specimen is older than its maximum handling duration of 24h0m0s"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CheckSpecimen(tc.sp, order, catalog, now)
+			if err != nil {
+				t.Fatalf("CheckSpecimen() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("CheckSpecimen() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
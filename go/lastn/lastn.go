@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lastn implements the Observation $lastn operation over R4
+// Observation protos: the most recent Observations of a patient for each
+// code, optionally restricted to some categories and codes.
+package lastn
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+)
+
+// Request holds the parameters of a $lastn invocation.
+type Request struct {
+	// Patient restricts the Observations to those whose subject is the given
+	// patient, i.e. "Patient/123" or "123". If empty, Observations of every
+	// subject are grouped together.
+	Patient string
+	// Category and Code are search tokens of the form "system|code", "code"
+	// or "system|". An Observation matches if it has a coding matching any of
+	// the tokens; empty lists match every Observation.
+	Category []string
+	Code     []string
+	// Max is the number of Observations returned for each code. It defaults
+	// to 1.
+	Max int
+}
+
+// Source provides the Observations of a patient to Evaluate. Implementations
+// may return Observations that do not match the request; they are filtered
+// by Evaluate.
+type Source interface {
+	Observations(ctx context.Context, patient string) ([]*obspb.Observation, error)
+}
+
+// Evaluate runs $lastn over the Observations of src and returns the
+// searchset Bundle the operation responds with.
+func Evaluate(ctx context.Context, src Source, req *Request) (*r4pb.Bundle, error) {
+	obs, err := src.Observations(ctx, req.Patient)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := LastN(obs, req)
+	if err != nil {
+		return nil, err
+	}
+	return Bundle(matches), nil
+}
+
+// LastN returns the most recent req.Max Observations of obs for each code
+// that match req. Observations are ordered by code, in order of first
+// appearance in obs, and then by decreasing effective time. Observations
+// entered in error are ignored, as are those without an effective time when
+// Observations of the same code have one.
+func LastN(obs []*obspb.Observation, req *Request) ([]*obspb.Observation, error) {
+	max := req.Max
+	if max == 0 {
+		max = 1
+	}
+	if max < 0 {
+		return nil, fmt.Errorf("invalid max %d", req.Max)
+	}
+	categories, err := parseTokens(req.Category)
+	if err != nil {
+		return nil, fmt.Errorf("category: %w", err)
+	}
+	codes, err := parseTokens(req.Code)
+	if err != nil {
+		return nil, fmt.Errorf("code: %w", err)
+	}
+	patient := req.Patient[strings.LastIndex(req.Patient, "/")+1:]
+
+	var order []string
+	groups := map[string][]*obspb.Observation{}
+	for _, o := range obs {
+		if o.GetStatus().GetValue() == c4pb.ObservationStatusCode_ENTERED_IN_ERROR {
+			continue
+		}
+		if patient != "" && o.GetSubject().GetPatientId().GetValue() != patient {
+			continue
+		}
+		if !matchesAny(categories, o.GetCategory()...) || !matchesAny(codes, o.GetCode()) {
+			continue
+		}
+		key := codeKey(o.GetCode())
+		if key == "" {
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], o)
+	}
+	var out []*obspb.Observation
+	for _, key := range order {
+		group := groups[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			ti, iok := effective(group[i])
+			tj, jok := effective(group[j])
+			if iok != jok {
+				return iok
+			}
+			return ti.After(tj)
+		})
+		if _, ok := effective(group[0]); ok {
+			for len(group) > 0 {
+				if _, ok := effective(group[len(group)-1]); ok {
+					break
+				}
+				group = group[:len(group)-1]
+			}
+		}
+		if len(group) > max {
+			group = group[:max]
+		}
+		out = append(out, group...)
+	}
+	return out, nil
+}
+
+// Bundle returns the searchset Bundle listing obs as matches.
+func Bundle(obs []*obspb.Observation) *r4pb.Bundle {
+	b := &r4pb.Bundle{
+		Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+		Total: &d4pb.UnsignedInt{Value: uint32(len(obs))},
+	}
+	for _, o := range obs {
+		b.Entry = append(b.Entry, &r4pb.Bundle_Entry{
+			Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: o}},
+			Search:   &r4pb.Bundle_Entry_Search{Mode: &r4pb.Bundle_Entry_Search_ModeCode{Value: c4pb.SearchEntryModeCode_MATCH}},
+		})
+	}
+	return b
+}
+
+// effective returns the time an Observation was made: its effective
+// dateTime or instant, or the start of its effective period. ok is false if
+// it has none of these.
+func effective(o *obspb.Observation) (t time.Time, ok bool) {
+	e := o.GetEffective()
+	switch {
+	case e.GetDateTime() != nil:
+		return time.UnixMicro(e.GetDateTime().GetValueUs()), true
+	case e.GetInstant() != nil:
+		return time.UnixMicro(e.GetInstant().GetValueUs()), true
+	case e.GetPeriod().GetStart() != nil:
+		return time.UnixMicro(e.GetPeriod().GetStart().GetValueUs()), true
+	}
+	return time.Time{}, false
+}
+
+// codeKey identifies the code of an Observation by its codings, or by its
+// text if it has none.
+func codeKey(cc *d4pb.CodeableConcept) string {
+	var parts []string
+	for _, c := range cc.GetCoding() {
+		parts = append(parts, c.GetSystem().GetValue()+"|"+c.GetCode().GetValue())
+	}
+	sort.Strings(parts)
+	if len(parts) == 0 {
+		return cc.GetText().GetValue()
+	}
+	return strings.Join(parts, ",")
+}
+
+type token struct {
+	system, code string
+	// hasSystem is set for tokens with a "|", whose empty system matches
+	// codings without one.
+	hasSystem bool
+}
+
+func parseTokens(ts []string) ([]token, error) {
+	var out []token
+	for _, t := range ts {
+		system, code, hasSystem := strings.Cut(t, "|")
+		if !hasSystem {
+			system, code = "", t
+		}
+		if system == "" && code == "" {
+			return nil, fmt.Errorf("empty token %q", t)
+		}
+		out = append(out, token{system: system, code: code, hasSystem: hasSystem})
+	}
+	return out, nil
+}
+
+func (t token) matches(c *d4pb.Coding) bool {
+	if t.hasSystem && t.system != c.GetSystem().GetValue() {
+		return false
+	}
+	return t.code == "" || t.code == c.GetCode().GetValue()
+}
+
+// matchesAny reports whether one of the codings of ccs matches one of ts, or
+// ts is empty.
+func matchesAny(ts []token, ccs ...*d4pb.CodeableConcept) bool {
+	if len(ts) == 0 {
+		return true
+	}
+	for _, cc := range ccs {
+		for _, c := range cc.GetCoding() {
+			for _, t := range ts {
+				if t.matches(c) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lastn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+)
+
+const (
+	loinc      = "http://loinc.org"
+	heartRate  = "8867-4"
+	bodyWeight = "29463-7"
+	glucose    = "2339-0"
+)
+
+func coding(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: system}, Code: &d4pb.Code{Value: code}}}}
+}
+
+var (
+	vitalSigns = coding("http://terminology.hl7.org/CodeSystem/observation-category", "vital-signs")
+	laboratory = coding("http://terminology.hl7.org/CodeSystem/observation-category", "laboratory")
+)
+
+// observation returns an Observation of patient made on the given day of
+// January 2025, or without an effective time if day is 0.
+func observation(id, patient string, category *d4pb.CodeableConcept, code string, day int) *obspb.Observation {
+	o := &obspb.Observation{
+		Id:       &d4pb.Id{Value: id},
+		Status:   &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Category: []*d4pb.CodeableConcept{category},
+		Code:     coding(loinc, code),
+		Subject:  &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: patient}}},
+	}
+	if day != 0 {
+		o.Effective = &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: &d4pb.DateTime{
+			ValueUs: time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_DAY,
+		}}}
+	}
+	return o
+}
+
+func testObservations() []*obspb.Observation {
+	hr1 := observation("hr1", "p1", vitalSigns, heartRate, 1)
+	hr3 := observation("hr3", "p1", vitalSigns, heartRate, 3)
+	hr3.Effective = &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_Period{Period: &d4pb.Period{
+		Start: &d4pb.DateTime{ValueUs: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_DAY},
+	}}}
+	wrong := observation("hr9", "p1", vitalSigns, heartRate, 9)
+	wrong.Status.Value = c4pb.ObservationStatusCode_ENTERED_IN_ERROR
+	return []*obspb.Observation{
+		hr1,
+		observation("wt2", "p1", vitalSigns, bodyWeight, 2),
+		observation("hr2", "p1", vitalSigns, heartRate, 2),
+		hr3,
+		wrong,
+		observation("hr0", "p1", vitalSigns, heartRate, 0),
+		observation("glu1", "p1", laboratory, glucose, 5),
+		observation("hr-other", "p2", vitalSigns, heartRate, 8),
+	}
+}
+
+func ids(obs []*obspb.Observation) []string {
+	var out []string
+	for _, o := range obs {
+		out = append(out, o.GetId().GetValue())
+	}
+	return out
+}
+
+func TestLastN(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *Request
+		want []string
+	}{
+		{
+			name: "latest per code",
+			req:  &Request{Patient: "Patient/p1"},
+			want: []string{"hr3", "wt2", "glu1"},
+		},
+		{
+			name: "max",
+			req:  &Request{Patient: "p1", Max: 2},
+			want: []string{"hr3", "hr2", "wt2", "glu1"},
+		},
+		{
+			name: "category",
+			req:  &Request{Patient: "Patient/p1", Category: []string{"laboratory"}, Max: 3},
+			want: []string{"glu1"},
+		},
+		{
+			name: "code with system",
+			req:  &Request{Patient: "Patient/p1", Code: []string{loinc + "|" + heartRate}, Max: 10},
+			want: []string{"hr3", "hr2", "hr1"},
+		},
+		{
+			name: "all patients",
+			req:  &Request{Code: []string{heartRate}},
+			want: []string{"hr-other"},
+		},
+		{
+			name: "no match",
+			req:  &Request{Patient: "Patient/p1", Code: []string{"http://snomed.info/sct|"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := LastN(testObservations(), test.req)
+			if err != nil {
+				t.Fatalf("LastN() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, ids(got)); diff != "" {
+				t.Errorf("LastN() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLastN_Errors(t *testing.T) {
+	for _, req := range []*Request{
+		{Max: -1},
+		{Code: []string{"|"}},
+		{Category: []string{""}},
+	} {
+		if _, err := LastN(testObservations(), req); err == nil {
+			t.Errorf("LastN(%+v) succeeded, want error", req)
+		}
+	}
+}
+
+type source []*obspb.Observation
+
+func (s source) Observations(ctx context.Context, patient string) ([]*obspb.Observation, error) {
+	return s, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	obs := testObservations()
+	got, err := Evaluate(context.Background(), source(obs), &Request{Patient: "Patient/p1", Category: []string{"laboratory"}})
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	want := &r4pb.Bundle{
+		Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+		Total: &d4pb.UnsignedInt{Value: 1},
+		Entry: []*r4pb.Bundle_Entry{{
+			Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: obs[6]}},
+			Search:   &r4pb.Bundle_Entry_Search{Mode: &r4pb.Bundle_Entry_Search_ModeCode{Value: c4pb.SearchEntryModeCode_MATCH}},
+		}},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Evaluate() diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/cql"
+	"github.com/google/fhir/go/fhirpath"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	mpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/measure_go_proto"
+)
+
+// Population codes from http://terminology.hl7.org/CodeSystem/measure-population.
+const (
+	initialPopulation           = "initial-population"
+	numerator                   = "numerator"
+	numeratorExclusion          = "numerator-exclusion"
+	denominator                 = "denominator"
+	denominatorExclusion        = "denominator-exclusion"
+	denominatorException        = "denominator-exception"
+	measurePopulation           = "measure-population"
+	measurePopulationExclusion  = "measure-population-exclusion"
+	measureObservation          = "measure-observation"
+	populationSystem            = "http://terminology.hl7.org/CodeSystem/measure-population"
+	aggregateMethodExtensionURL = "http://hl7.org/fhir/us/cqfmeasures/StructureDefinition/cqfm-aggregateMethod"
+)
+
+// Scoring codes from http://terminology.hl7.org/CodeSystem/measure-scoring.
+const (
+	proportion         = "proportion"
+	ratio              = "ratio"
+	continuousVariable = "continuous-variable"
+	cohort             = "cohort"
+)
+
+// group is a Measure.group with its criteria resolved.
+type group struct {
+	def         *mpb.Measure_Group
+	scoring     string
+	populations []*population
+	// aggregate is the method combining measure observations into the score
+	// of a continuous variable measure.
+	aggregate   string
+	stratifiers []*stratifier
+}
+
+type population struct {
+	def  *mpb.Measure_Group_Population
+	code string
+	// expression names the CQL expression defining the population, or the
+	// function computing a measure observation.
+	expression string
+}
+
+// stratifier is a Measure.group.stratifier. A stratifier with a single
+// criteria is represented as one component without a code.
+type stratifier struct {
+	def        *mpb.Measure_Group_Stratifier
+	components []*component
+}
+
+type component struct {
+	code       *d4pb.CodeableConcept
+	expression string
+}
+
+func compileGroups(m *mpb.Measure) ([]*group, error) {
+	scoring := ""
+	for _, c := range m.GetScoring().GetCoding() {
+		scoring = c.GetCode().GetValue()
+	}
+	switch scoring {
+	case proportion, ratio, continuousVariable, cohort:
+	default:
+		return nil, fmt.Errorf("unsupported measure scoring %q", scoring)
+	}
+	var groups []*group
+	for i, g := range m.GetGroup() {
+		out := &group{def: g, scoring: scoring, aggregate: "average"}
+		for _, p := range g.GetPopulation() {
+			code := ""
+			for _, c := range p.GetCode().GetCoding() {
+				if c.GetSystem().GetValue() == populationSystem || code == "" {
+					code = c.GetCode().GetValue()
+				}
+			}
+			expr, err := criteria(p.GetCriteria())
+			if err != nil {
+				return nil, fmt.Errorf("group %d population %s: %w", i, code, err)
+			}
+			out.populations = append(out.populations, &population{def: p, code: code, expression: expr})
+			if code == measureObservation {
+				for _, ext := range p.GetExtension() {
+					if ext.GetUrl().GetValue() == aggregateMethodExtensionURL {
+						out.aggregate = ext.GetValue().GetCode().GetValue()
+					}
+				}
+			}
+		}
+		for _, s := range g.GetStratifier() {
+			st := &stratifier{def: s}
+			if s.GetCriteria() != nil {
+				expr, err := criteria(s.GetCriteria())
+				if err != nil {
+					return nil, fmt.Errorf("group %d stratifier: %w", i, err)
+				}
+				st.components = append(st.components, &component{expression: expr})
+			}
+			for _, c := range s.GetComponent() {
+				expr, err := criteria(c.GetCriteria())
+				if err != nil {
+					return nil, fmt.Errorf("group %d stratifier component: %w", i, err)
+				}
+				st.components = append(st.components, &component{code: c.GetCode(), expression: expr})
+			}
+			out.stratifiers = append(out.stratifiers, st)
+		}
+		groups = append(groups, out)
+	}
+	return groups, nil
+}
+
+// criteria returns the name of the CQL expression referred to by e.
+func criteria(e *d4pb.Expression) (string, error) {
+	switch lang := e.GetLanguage().GetValue(); lang {
+	case "text/cql", "text/cql.identifier", "text/cql-identifier":
+	default:
+		return "", fmt.Errorf("unsupported criteria language %q", lang)
+	}
+	if e.GetExpression().GetValue() == "" {
+		return "", fmt.Errorf("criteria has no expression")
+	}
+	return e.GetExpression().GetValue(), nil
+}
+
+// subjectResult is the outcome of a group for a single subject.
+type subjectResult struct {
+	subject      string
+	populations  map[string][]member
+	observations []interface{}
+	// strata holds the stratum of the subject for each stratifier.
+	strata []*stratum
+}
+
+type stratum struct {
+	key        string
+	value      *d4pb.CodeableConcept
+	components []*d4pb.CodeableConcept
+}
+
+// evaluateSubject evaluates every group for subject.
+func (e *evaluator) evaluateSubject(ctx context.Context, subject string) ([]*subjectResult, error) {
+	var names []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, g := range e.groups {
+		for _, p := range g.populations {
+			if p.code != measureObservation {
+				add(p.expression)
+			}
+		}
+		for _, s := range g.stratifiers {
+			for _, c := range s.components {
+				add(c.expression)
+			}
+		}
+	}
+	values, err := e.engine.Evaluate(ctx, subject, names...)
+	if err != nil {
+		return nil, err
+	}
+	var out []*subjectResult
+	for _, g := range e.groups {
+		raw := map[string][]member{}
+		observation := ""
+		for _, p := range g.populations {
+			if p.code == measureObservation {
+				observation = p.expression
+				continue
+			}
+			raw[p.code] = members(subject, values[p.expression])
+		}
+		r := &subjectResult{subject: subject, populations: g.apply(raw)}
+		if observation != "" {
+			for _, m := range minus(r.populations[measurePopulation], r.populations[measurePopulationExclusion]) {
+				var args []interface{}
+				if m.value != nil {
+					args = append(args, m.value)
+				}
+				v, err := e.engine.Call(ctx, subject, observation, args...)
+				if err != nil {
+					return nil, err
+				}
+				if v != nil {
+					r.observations = append(r.observations, v)
+				}
+			}
+		}
+		for _, s := range g.stratifiers {
+			st := &stratum{}
+			var keys []string
+			for _, c := range s.components {
+				cc := stratumValue(values[c.expression])
+				st.components = append(st.components, cc)
+				keys = append(keys, conceptKey(cc))
+			}
+			st.key = strings.Join(keys, "\x00")
+			if len(st.components) == 1 && s.def.GetCriteria() != nil {
+				st.value, st.components = st.components[0], nil
+			}
+			r.strata = append(r.strata, st)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// apply derives the populations of a subject from the raw criteria results
+// according to the scoring of the group, i.e. restricting the numerator of a
+// proportion measure to the denominator members that are not excluded.
+func (g *group) apply(raw map[string][]member) map[string][]member {
+	ip := raw[initialPopulation]
+	out := map[string][]member{initialPopulation: ip}
+	within := func(code string, of []member) []member {
+		if _, ok := raw[code]; !ok {
+			return nil
+		}
+		return intersect(raw[code], of)
+	}
+	switch g.scoring {
+	case proportion:
+		denom := ip
+		if _, ok := raw[denominator]; ok {
+			denom = within(denominator, ip)
+		}
+		out[denominator] = denom
+		out[denominatorExclusion] = within(denominatorExclusion, denom)
+		remaining := minus(denom, out[denominatorExclusion])
+		out[numerator] = within(numerator, remaining)
+		out[numeratorExclusion] = within(numeratorExclusion, out[numerator])
+		out[denominatorException] = within(denominatorException, minus(remaining, out[numerator]))
+	case ratio:
+		out[denominator] = within(denominator, ip)
+		out[denominatorExclusion] = within(denominatorExclusion, out[denominator])
+		out[numerator] = within(numerator, ip)
+		out[numeratorExclusion] = within(numeratorExclusion, out[numerator])
+	case continuousVariable:
+		out[measurePopulation] = within(measurePopulation, ip)
+		out[measurePopulationExclusion] = within(measurePopulationExclusion, out[measurePopulation])
+	}
+	return out
+}
+
+// stratumValue returns the value of a stratum for the stratifier result v.
+func stratumValue(v interface{}) *d4pb.CodeableConcept {
+	if list, ok := v.([]interface{}); ok && len(list) == 1 {
+		v = list[0]
+	}
+	switch x := v.(type) {
+	case *d4pb.CodeableConcept:
+		return x
+	case *d4pb.Coding:
+		return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{x}}
+	case cql.Code, cql.Concept:
+		m, _ := cql.ProtoValue(x)
+		return stratumValue(m)
+	}
+	if m, ok := v.(proto.Message); ok {
+		if sv, ok := fhirpath.SystemValue(m); ok {
+			v = sv
+		} else {
+			v = nil
+		}
+	}
+	text := "null"
+	if v != nil {
+		text = fmt.Sprint(v)
+	}
+	return &d4pb.CodeableConcept{Text: &d4pb.String{Value: text}}
+}
+
+// conceptKey identifies the stratum value cc.
+func conceptKey(cc *d4pb.CodeableConcept) string {
+	var parts []string
+	for _, c := range cc.GetCoding() {
+		parts = append(parts, c.GetSystem().GetValue()+"|"+c.GetCode().GetValue())
+	}
+	sort.Strings(parts)
+	if len(parts) == 0 {
+		parts = append(parts, cc.GetText().GetValue())
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package measure implements the FHIR $evaluate-measure operation for R4
+// Measures whose criteria are CQL expressions, producing MeasureReport
+// protos.
+//
+// The population, stratifier and measure observation criteria of a Measure
+// name expressions and functions of its primary library, which is executed
+// with the cql package. A criterion that evaluates to a Boolean places the
+// subject in the population; one that evaluates to a list places each of its
+// items in the population, which is how episode-based measures count
+// encounters or procedures. Proportion, ratio, continuous variable and
+// cohort scoring are supported.
+package measure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/cql"
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	mpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/measure_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/measure_report_go_proto"
+)
+
+// ReportType is the kind of MeasureReport to produce, as given by the
+// reportType parameter of $evaluate-measure.
+type ReportType string
+
+const (
+	// SubjectReport is an individual report for a single subject.
+	SubjectReport ReportType = "subject"
+	// SubjectListReport is a summary report that also lists the subjects in
+	// each population.
+	SubjectListReport ReportType = "subject-list"
+	// PopulationReport is a summary report of population counts.
+	PopulationReport ReportType = "population"
+)
+
+// MeasurementPeriod is the name of the library parameter that receives the
+// measurement period of the request.
+const MeasurementPeriod = "Measurement Period"
+
+// Request holds the parameters of an $evaluate-measure invocation.
+type Request struct {
+	// PeriodStart and PeriodEnd bound the measurement period, which is passed
+	// to the library as the closed interval [PeriodStart, PeriodEnd] of
+	// DateTimes at millisecond precision. A period covering 2024 therefore
+	// ends at 2024-12-31T23:59:59.999.
+	PeriodStart, PeriodEnd time.Time
+	// ReportType defaults to SubjectReport if Subject is set and to
+	// PopulationReport otherwise.
+	ReportType ReportType
+	// Subject is the patient of an individual report, i.e. "Patient/123".
+	Subject string
+	// Subjects are the patients to include in a summary report. If empty, the
+	// report covers every Patient returned by the retriever.
+	Subjects []string
+}
+
+// Options configures Evaluate.
+type Options struct {
+	// Library returns the ELM library with the given canonical URL. It is
+	// called for the first library of the Measure.
+	Library func(canonical string) (*cql.Library, error)
+	// Engine configures the execution of the library. The measurement period
+	// of the request is added to its parameters.
+	Engine cql.Options
+}
+
+// Evaluate evaluates the Measure m as requested and returns the resulting
+// MeasureReport.
+func Evaluate(ctx context.Context, m *mpb.Measure, req *Request, opts Options) (*mrpb.MeasureReport, error) {
+	if len(m.GetLibrary()) == 0 {
+		return nil, fmt.Errorf("measure %s has no library", m.GetUrl().GetValue())
+	}
+	if opts.Library == nil {
+		return nil, fmt.Errorf("no library resolver for measure %s", m.GetUrl().GetValue())
+	}
+	canonical := m.GetLibrary()[0].GetValue()
+	lib, err := opts.Library(canonical)
+	if err != nil {
+		return nil, fmt.Errorf("loading library %s: %w", canonical, err)
+	}
+	reportType := req.ReportType
+	if reportType == "" {
+		reportType = PopulationReport
+		if req.Subject != "" {
+			reportType = SubjectReport
+		}
+	}
+	e := &evaluator{measure: m, req: req, reportType: reportType}
+	if e.groups, err = compileGroups(m); err != nil {
+		return nil, err
+	}
+	period := cql.Interval{
+		Low:       fhirpath.Temporal{Kind: fhirpath.DateTime, Time: req.PeriodStart, Precision: fhirpath.PrecisionMillisecond},
+		High:      fhirpath.Temporal{Kind: fhirpath.DateTime, Time: req.PeriodEnd, Precision: fhirpath.PrecisionMillisecond},
+		LowClosed: true, HighClosed: true,
+	}
+	engineOpts := opts.Engine
+	engineOpts.Parameters = map[string]interface{}{}
+	for k, v := range opts.Engine.Parameters {
+		engineOpts.Parameters[k] = v
+	}
+	if hasParameter(lib, MeasurementPeriod) {
+		engineOpts.Parameters[MeasurementPeriod] = period
+	}
+	if e.engine, err = cql.New(lib, engineOpts); err != nil {
+		return nil, err
+	}
+	e.now = opts.Engine.Now
+	if e.now.IsZero() {
+		e.now = time.Now()
+	}
+	e.period = period
+
+	subjects, err := e.subjects(ctx, opts.Engine.Retriever)
+	if err != nil {
+		return nil, err
+	}
+	results := make([][]*subjectResult, len(e.groups))
+	for _, subject := range subjects {
+		rs, err := e.evaluateSubject(ctx, subject)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating subject %s: %w", subject, err)
+		}
+		for i, r := range rs {
+			results[i] = append(results[i], r)
+		}
+	}
+	return e.report(results)
+}
+
+func hasParameter(lib *cql.Library, name string) bool {
+	for _, p := range lib.Parameters.Def {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluator holds the state of a single Evaluate call.
+type evaluator struct {
+	measure    *mpb.Measure
+	req        *Request
+	reportType ReportType
+	groups     []*group
+	engine     *cql.Engine
+	period     cql.Interval
+	now        time.Time
+	// lists counts the subject lists contained in the report.
+	lists int
+}
+
+func (e *evaluator) subjects(ctx context.Context, retriever cql.RetrieveProvider) ([]string, error) {
+	if e.reportType == SubjectReport {
+		if e.req.Subject == "" {
+			return nil, fmt.Errorf("an individual report requires a subject")
+		}
+		return []string{subjectID(e.req.Subject)}, nil
+	}
+	if len(e.req.Subjects) > 0 {
+		var ids []string
+		for _, s := range e.req.Subjects {
+			ids = append(ids, subjectID(s))
+		}
+		return ids, nil
+	}
+	if retriever == nil {
+		return nil, fmt.Errorf("a population report without subjects requires a retriever")
+	}
+	patients, err := retriever.Retrieve(ctx, &cql.RetrieveRequest{DataType: "Patient"})
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, p := range patients {
+		if id := elementpath.ID(p); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// subjectID returns the id of the patient referred to by ref, which is either
+// a relative reference or a bare id.
+func subjectID(ref string) string {
+	return strings.TrimPrefix(ref, "Patient/")
+}
+
+// member is an element of a population: the subject itself for Boolean
+// criteria or an item, usually a resource, for list-valued criteria.
+type member struct {
+	key   string
+	value interface{}
+}
+
+// members returns the population members described by the criterion value v
+// for subject.
+func members(subject string, v interface{}) []member {
+	var out []member
+	seen := map[string]bool{}
+	add := func(item interface{}) {
+		if item == nil || item == false {
+			return
+		}
+		var key string
+		switch x := item.(type) {
+		case bool:
+			key, item = "Patient/"+subject, nil
+		case proto.Message:
+			if id := elementpath.ID(x); id != "" {
+				key = elementpath.ResourceType(x) + "/" + id
+				break
+			}
+			key = fmt.Sprintf("%s:%v", subject, x)
+		default:
+			key = fmt.Sprintf("%s:%T:%v", subject, x, x)
+		}
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, member{key: key, value: item})
+		}
+	}
+	if list, ok := v.([]interface{}); ok {
+		for _, item := range list {
+			add(item)
+		}
+	} else {
+		add(v)
+	}
+	return out
+}
+
+// intersect returns the members of a that are also in b.
+func intersect(a, b []member) []member {
+	in := map[string]bool{}
+	for _, m := range b {
+		in[m.key] = true
+	}
+	var out []member
+	for _, m := range a {
+		if in[m.key] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// minus returns the members of a that are not in b.
+func minus(a, b []member) []member {
+	in := map[string]bool{}
+	for _, m := range b {
+		in[m.key] = true
+	}
+	var out []member
+	for _, m := range a {
+		if !in[m.key] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
@@ -0,0 +1,394 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measure
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/cql"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	lpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/list_go_proto"
+	mpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/measure_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/measure_report_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+const (
+	libraryURL = "http://example.com/Library/diabetes"
+	snomed     = "http://snomed.info/sct"
+)
+
+// elm is the translation of:
+//
+//	library Diabetes version '1'
+//	using FHIR version '4.0.1'
+//	include FHIRHelpers version '4.0.1'
+//	codesystem "LOINC": 'http://loinc.org'
+//	valueset "Diabetes": 'http://example.com/ValueSet/diabetes'
+//	code "Body weight": '29463-7' from "LOINC"
+//	parameter "Measurement Period" Interval<DateTime>
+//	context Patient
+//	define "Diabetes": [Condition: "Diabetes"]
+//	define "Initial Population": exists "Diabetes"
+//	define "Denominator": "Initial Population"
+//	define "Gender": FHIRHelpers.ToString(Patient.gender)
+//	define "Denominator Exclusion": "Gender" = 'other'
+//	define "Weights": [Observation: "Body weight"] O
+//	  where FHIRHelpers.ToDateTime(O.effective as dateTime) during "Measurement Period"
+//	define "Numerator": exists "Weights"
+//	define function "Weight"(O Observation): FHIRHelpers.ToQuantity(O.value as Quantity)
+const elm = `{"library":{
+	"identifier":{"id":"Diabetes","version":"1"},
+	"includes":{"def":[{"localIdentifier":"FHIRHelpers","path":"FHIRHelpers","version":"4.0.1"}]},
+	"parameters":{"def":[{"name":"Measurement Period"}]},
+	"codeSystems":{"def":[{"name":"LOINC","id":"http://loinc.org"}]},
+	"valueSets":{"def":[{"name":"Diabetes","id":"http://example.com/ValueSet/diabetes"}]},
+	"codes":{"def":[{"name":"Body weight","id":"29463-7","codeSystem":{"name":"LOINC"}}]},
+	"statements":{"def":[
+		{"name":"Patient","context":"Patient","expression":{"type":"SingletonFrom",
+			"operand":{"type":"Retrieve","dataType":"{http://hl7.org/fhir}Patient"}}},
+		{"name":"Diabetes","context":"Patient","expression":{"type":"Retrieve","dataType":"{http://hl7.org/fhir}Condition",
+			"codeProperty":"code","codes":{"type":"ValueSetRef","name":"Diabetes"}}},
+		{"name":"Initial Population","context":"Patient","expression":{"type":"Exists",
+			"operand":{"type":"ExpressionRef","name":"Diabetes"}}},
+		{"name":"Denominator","context":"Patient","expression":{"type":"ExpressionRef","name":"Initial Population"}},
+		{"name":"Gender","context":"Patient","expression":{"type":"FunctionRef","libraryName":"FHIRHelpers","name":"ToString",
+			"operand":[{"type":"Property","path":"gender","source":{"type":"ExpressionRef","name":"Patient"}}]}},
+		{"name":"Denominator Exclusion","context":"Patient","expression":{"type":"Equal","operand":[
+			{"type":"ExpressionRef","name":"Gender"},
+			{"type":"Literal","valueType":"{urn:hl7-org:elm-types:r1}String","value":"other"}]}},
+		{"name":"Weights","context":"Patient","expression":{"type":"Query",
+			"source":[{"alias":"O","expression":{"type":"Retrieve","dataType":"{http://hl7.org/fhir}Observation","codeProperty":"code",
+				"codes":{"type":"ToList","operand":{"type":"CodeRef","name":"Body weight"}}}}],
+			"where":{"type":"In","operand":[
+				{"type":"FunctionRef","libraryName":"FHIRHelpers","name":"ToDateTime","operand":[
+					{"type":"As","asType":"{http://hl7.org/fhir}dateTime","operand":{"type":"Property","path":"effective","scope":"O"}}]},
+				{"type":"ParameterRef","name":"Measurement Period"}]}}},
+		{"name":"Numerator","context":"Patient","expression":{"type":"Exists",
+			"operand":{"type":"ExpressionRef","name":"Weights"}}},
+		{"type":"FunctionDef","name":"Weight","context":"Patient","operand":[{"name":"O"}],
+			"expression":{"type":"FunctionRef","libraryName":"FHIRHelpers","name":"ToQuantity","operand":[
+				{"type":"As","asType":"{http://hl7.org/fhir}Quantity","operand":{"type":"Property","path":"value","source":{"type":"OperandRef","name":"O"}}}]}}
+	]}}}`
+
+func testResources() []proto.Message {
+	var out []proto.Message
+	patient := func(id string, gender c4pb.AdministrativeGenderCode_Value) {
+		out = append(out, &ppb.Patient{Id: &d4pb.Id{Value: id}, Gender: &ppb.Patient_GenderCode{Value: gender}})
+	}
+	subject := func(id string) *d4pb.Reference {
+		return &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: id}}}
+	}
+	diabetes := func(id, patient string) {
+		out = append(out, &cpb.Condition{
+			Id:      &d4pb.Id{Value: id},
+			Subject: subject(patient),
+			Code:    &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: snomed}, Code: &d4pb.Code{Value: "44054006"}}}},
+		})
+	}
+	weight := func(id, patient, kg string, year int) {
+		out = append(out, &obspb.Observation{
+			Id:      &d4pb.Id{Value: id},
+			Status:  &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+			Subject: subject(patient),
+			Code:    &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: "http://loinc.org"}, Code: &d4pb.Code{Value: "29463-7"}}}},
+			Effective: &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: &d4pb.DateTime{
+				ValueUs: time.Date(year, 6, 1, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND,
+			}}},
+			Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+				Value: &d4pb.Decimal{Value: kg}, Unit: &d4pb.String{Value: "kg"},
+			}}},
+		})
+	}
+	// p1 has diabetes and a weight, p2 diabetes and only an old weight, p3 a
+	// weight but no diabetes and p4 diabetes but is excluded.
+	patient("p1", c4pb.AdministrativeGenderCode_FEMALE)
+	patient("p2", c4pb.AdministrativeGenderCode_MALE)
+	patient("p3", c4pb.AdministrativeGenderCode_FEMALE)
+	patient("p4", c4pb.AdministrativeGenderCode_OTHER)
+	diabetes("c1", "p1")
+	diabetes("c2", "p2")
+	diabetes("c4", "p4")
+	weight("o1", "p1", "70", 2024)
+	weight("o2", "p2", "100", 2020)
+	weight("o3", "p3", "90", 2024)
+	return out
+}
+
+func testOptions(t *testing.T) Options {
+	t.Helper()
+	tp := cql.NewValueSetTerminology(&vspb.ValueSet{
+		Url: &d4pb.Uri{Value: "http://example.com/ValueSet/diabetes"},
+		Compose: &vspb.ValueSet_Compose{Include: []*vspb.ValueSet_Compose_ConceptSet{{
+			System: &d4pb.Uri{Value: snomed},
+		}}},
+	})
+	return Options{
+		Library: func(canonical string) (*cql.Library, error) {
+			if canonical != libraryURL {
+				return nil, fmt.Errorf("unknown library %s", canonical)
+			}
+			return cql.Parse([]byte(elm))
+		},
+		Engine: cql.Options{
+			Retriever:   cql.NewMemoryRetriever(tp, testResources()...),
+			Terminology: tp,
+			Now:         time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func concept(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: system}, Code: &d4pb.Code{Value: code}}}}
+}
+
+func populationCode(code string) *d4pb.CodeableConcept { return concept(populationSystem, code) }
+
+func cqlCriteria(name string) *d4pb.Expression {
+	return &d4pb.Expression{Language: &d4pb.Code{Value: "text/cql.identifier"}, Expression: &d4pb.String{Value: name}}
+}
+
+func testMeasure(scoring string, populations map[string]string, order ...string) *mpb.Measure {
+	g := &mpb.Measure_Group{}
+	for _, code := range order {
+		g.Population = append(g.Population, &mpb.Measure_Group_Population{
+			Code: populationCode(code), Criteria: cqlCriteria(populations[code]),
+		})
+	}
+	return &mpb.Measure{
+		Url:     &d4pb.Uri{Value: "http://example.com/Measure/diabetes"},
+		Version: &d4pb.String{Value: "1"},
+		Library: []*d4pb.Canonical{{Value: libraryURL}},
+		Scoring: concept("http://terminology.hl7.org/CodeSystem/measure-scoring", scoring),
+		Group:   []*mpb.Measure_Group{g},
+	}
+}
+
+func proportionMeasure() *mpb.Measure {
+	m := testMeasure(proportion, map[string]string{
+		initialPopulation:    "Initial Population",
+		denominator:          "Denominator",
+		denominatorExclusion: "Denominator Exclusion",
+		numerator:            "Numerator",
+	}, initialPopulation, denominator, denominatorExclusion, numerator)
+	m.Group[0].Stratifier = []*mpb.Measure_Group_Stratifier{{
+		Code:     &d4pb.CodeableConcept{Text: &d4pb.String{Value: "gender"}},
+		Criteria: cqlCriteria("Gender"),
+	}}
+	return m
+}
+
+type counts [4]int32
+
+func populations(c counts) []*mrpb.MeasureReport_Group_Population {
+	var out []*mrpb.MeasureReport_Group_Population
+	for i, code := range []string{initialPopulation, denominator, denominatorExclusion, numerator} {
+		out = append(out, &mrpb.MeasureReport_Group_Population{Code: populationCode(code), Count: &d4pb.Integer{Value: c[i]}})
+	}
+	return out
+}
+
+func stratumGroup(value string, c counts, score *d4pb.Quantity) *mrpb.MeasureReport_Group_Stratifier_StratifierGroup {
+	sg := &mrpb.MeasureReport_Group_Stratifier_StratifierGroup{
+		Value:        &d4pb.CodeableConcept{Text: &d4pb.String{Value: value}},
+		MeasureScore: score,
+	}
+	for _, p := range populations(c) {
+		sg.Population = append(sg.Population, &mrpb.MeasureReport_Group_Stratifier_StratifierGroup_StratifierGroupPopulation{
+			Code: p.Code, Count: p.Count,
+		})
+	}
+	return sg
+}
+
+func score(v string) *d4pb.Quantity { return &d4pb.Quantity{Value: &d4pb.Decimal{Value: v}} }
+
+func patientRef(id string) *d4pb.Reference {
+	return &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: id}}}
+}
+
+func wantReport(typ c4pb.MeasureReportTypeCode_Value, groups ...*mrpb.MeasureReport_Group) *mrpb.MeasureReport {
+	return &mrpb.MeasureReport{
+		Status:  &mrpb.MeasureReport_StatusCode{Value: c4pb.MeasureReportStatusCode_COMPLETE},
+		Type:    &mrpb.MeasureReport_TypeCode{Value: typ},
+		Measure: &d4pb.Canonical{Value: "http://example.com/Measure/diabetes|1"},
+		Date:    &d4pb.DateTime{ValueUs: time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND},
+		Period: &d4pb.Period{
+			Start: &d4pb.DateTime{ValueUs: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_MILLISECOND},
+			End:   &d4pb.DateTime{ValueUs: time.Date(2024, 12, 31, 23, 59, 59, 999e6, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_MILLISECOND},
+		},
+		Group: groups,
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	period := func(req *Request) *Request {
+		req.PeriodStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		req.PeriodEnd = time.Date(2024, 12, 31, 23, 59, 59, 999e6, time.UTC)
+		return req
+	}
+	genderStratifier := func(strata ...*mrpb.MeasureReport_Group_Stratifier_StratifierGroup) []*mrpb.MeasureReport_Group_Stratifier {
+		return []*mrpb.MeasureReport_Group_Stratifier{{
+			Code:    []*d4pb.CodeableConcept{{Text: &d4pb.String{Value: "gender"}}},
+			Stratum: strata,
+		}}
+	}
+	cv := testMeasure(continuousVariable, map[string]string{
+		initialPopulation:  "Weights",
+		measurePopulation:  "Weights",
+		measureObservation: "Weight",
+	}, initialPopulation, measurePopulation, measureObservation)
+	cv.Group[0].Population[2].Extension = []*d4pb.Extension{{
+		Url:   &d4pb.Uri{Value: aggregateMethodExtensionURL},
+		Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Code{Code: &d4pb.Code{Value: "maximum"}}},
+	}}
+	individual := wantReport(c4pb.MeasureReportTypeCode_INDIVIDUAL, &mrpb.MeasureReport_Group{
+		Population:   populations(counts{1, 1, 0, 1}),
+		MeasureScore: score("1"),
+		Stratifier:   genderStratifier(stratumGroup("female", counts{1, 1, 0, 1}, score("1"))),
+	})
+	individual.Subject = patientRef("p1")
+	tests := []struct {
+		name    string
+		measure *mpb.Measure
+		req     *Request
+		want    *mrpb.MeasureReport
+	}{
+		{
+			name:    "population",
+			measure: proportionMeasure(),
+			req:     period(&Request{}),
+			want: wantReport(c4pb.MeasureReportTypeCode_SUMMARY, &mrpb.MeasureReport_Group{
+				Population:   populations(counts{3, 3, 1, 1}),
+				MeasureScore: score("0.5"),
+				Stratifier: genderStratifier(
+					stratumGroup("female", counts{1, 1, 0, 1}, score("1")),
+					stratumGroup("male", counts{1, 1, 0, 0}, score("0")),
+					stratumGroup("other", counts{1, 1, 1, 0}, nil),
+				),
+			}),
+		},
+		{
+			name:    "individual",
+			measure: proportionMeasure(),
+			req:     period(&Request{Subject: "Patient/p1"}),
+			want:    individual,
+		},
+		{
+			name:    "continuous variable with episodes",
+			measure: cv,
+			req:     period(&Request{Subjects: []string{"p1", "p2", "p3"}}),
+			want: wantReport(c4pb.MeasureReportTypeCode_SUMMARY, &mrpb.MeasureReport_Group{
+				Population: []*mrpb.MeasureReport_Group_Population{
+					{Code: populationCode(initialPopulation), Count: &d4pb.Integer{Value: 2}},
+					{Code: populationCode(measurePopulation), Count: &d4pb.Integer{Value: 2}},
+					{Code: populationCode(measureObservation), Count: &d4pb.Integer{Value: 2}},
+				},
+				MeasureScore: &d4pb.Quantity{
+					Value: &d4pb.Decimal{Value: "90"}, Unit: &d4pb.String{Value: "kg"},
+					System: &d4pb.Uri{Value: "http://unitsofmeasure.org"}, Code: &d4pb.Code{Value: "kg"},
+				},
+			}),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Evaluate(context.Background(), test.measure, test.req, testOptions(t))
+			if err != nil {
+				t.Fatalf("Evaluate() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Evaluate() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEvaluate_SubjectList(t *testing.T) {
+	m := testMeasure(cohort, map[string]string{initialPopulation: "Initial Population"}, initialPopulation)
+	req := &Request{
+		ReportType:  SubjectListReport,
+		PeriodStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 12, 31, 23, 59, 59, 999e6, time.UTC),
+	}
+	got, err := Evaluate(context.Background(), m, req, testOptions(t))
+	if err != nil {
+		t.Fatalf("Evaluate() returned unexpected error: %v", err)
+	}
+	wantPopulation := &mrpb.MeasureReport_Group_Population{
+		Code:           populationCode(initialPopulation),
+		Count:          &d4pb.Integer{Value: 3},
+		SubjectResults: &d4pb.Reference{Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: "initial-population-1"}}},
+	}
+	if diff := cmp.Diff(wantPopulation, got.GetGroup()[0].GetPopulation()[0], protocmp.Transform()); diff != "" {
+		t.Errorf("Evaluate() population diff (-want +got):\n%s", diff)
+	}
+	wantList, err := anypb.New(&r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_List{List: &lpb.List{
+		Id:     &d4pb.Id{Value: "initial-population-1"},
+		Status: &lpb.List_StatusCode{Value: c4pb.ListStatusCode_CURRENT},
+		Mode:   &lpb.List_ModeCode{Value: c4pb.ListModeCode_SNAPSHOT},
+		Entry: []*lpb.List_Entry{
+			{Item: patientRef("p1")}, {Item: patientRef("p2")}, {Item: patientRef("p4")},
+		},
+	}}})
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]*anypb.Any{wantList}, got.GetContained(), protocmp.Transform()); diff != "" {
+		t.Errorf("Evaluate() contained diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvaluate_Errors(t *testing.T) {
+	noLibrary := proportionMeasure()
+	noLibrary.Library = nil
+	badScoring := proportionMeasure()
+	badScoring.Scoring = concept("http://terminology.hl7.org/CodeSystem/measure-scoring", "composite")
+	badLanguage := proportionMeasure()
+	badLanguage.Group[0].Population[0].Criteria.Language.Value = "text/fhirpath"
+	unknownExpression := proportionMeasure()
+	unknownExpression.Group[0].Population[0].Criteria.Expression.Value = "Missing"
+	tests := []struct {
+		name    string
+		measure *mpb.Measure
+		req     *Request
+	}{
+		{"no library", noLibrary, &Request{}},
+		{"unsupported scoring", badScoring, &Request{}},
+		{"unsupported criteria language", badLanguage, &Request{}},
+		{"unknown expression", unknownExpression, &Request{}},
+		{"individual report without subject", proportionMeasure(), &Request{ReportType: SubjectReport}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Evaluate(context.Background(), test.measure, test.req, testOptions(t)); err == nil {
+				t.Errorf("Evaluate() succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measure
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	lpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/list_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/measure_report_go_proto"
+)
+
+var reportTypes = map[ReportType]c4pb.MeasureReportTypeCode_Value{
+	SubjectReport:     c4pb.MeasureReportTypeCode_INDIVIDUAL,
+	SubjectListReport: c4pb.MeasureReportTypeCode_SUBJECT_LIST,
+	PopulationReport:  c4pb.MeasureReportTypeCode_SUMMARY,
+}
+
+func (e *evaluator) report(results [][]*subjectResult) (*mrpb.MeasureReport, error) {
+	typ, ok := reportTypes[e.reportType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported report type %q", e.reportType)
+	}
+	measure := e.measure.GetUrl().GetValue()
+	if v := e.measure.GetVersion().GetValue(); v != "" {
+		measure += "|" + v
+	}
+	start, _ := fhirpath.ProtoValue(e.period.Low)
+	end, _ := fhirpath.ProtoValue(e.period.High)
+	now, _ := fhirpath.ProtoValue(fhirpath.Temporal{Kind: fhirpath.DateTime, Time: e.now, Precision: fhirpath.PrecisionSecond})
+	report := &mrpb.MeasureReport{
+		Status:              &mrpb.MeasureReport_StatusCode{Value: c4pb.MeasureReportStatusCode_COMPLETE},
+		Type:                &mrpb.MeasureReport_TypeCode{Value: typ},
+		Measure:             &d4pb.Canonical{Value: measure},
+		Date:                now.(*d4pb.DateTime),
+		Period:              &d4pb.Period{Start: start.(*d4pb.DateTime), End: end.(*d4pb.DateTime)},
+		ImprovementNotation: e.measure.GetImprovementNotation(),
+	}
+	if e.reportType == SubjectReport {
+		ref, err := fhirtypes.ResourceReference("Patient/" + subjectID(e.req.Subject))
+		if err != nil {
+			return nil, err
+		}
+		report.Subject = ref
+	}
+	for i, g := range e.groups {
+		gr, err := e.groupReport(report, g, results[i])
+		if err != nil {
+			return nil, err
+		}
+		report.Group = append(report.Group, gr)
+	}
+	return report, nil
+}
+
+func (e *evaluator) groupReport(report *mrpb.MeasureReport, g *group, results []*subjectResult) (*mrpb.MeasureReport_Group, error) {
+	out := &mrpb.MeasureReport_Group{Code: g.def.GetCode()}
+	counts, score, err := e.tally(report, g, results)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		out.Population = append(out.Population, &mrpb.MeasureReport_Group_Population{
+			Code: c.code, Count: c.count, SubjectResults: c.subjects,
+		})
+	}
+	out.MeasureScore = score
+	for i, s := range g.stratifiers {
+		sr := &mrpb.MeasureReport_Group_Stratifier{}
+		if s.def.GetCode() != nil {
+			sr.Code = []*d4pb.CodeableConcept{s.def.GetCode()}
+		}
+		for _, c := range s.components {
+			if c.code != nil {
+				sr.Code = append(sr.Code, c.code)
+			}
+		}
+		var order []string
+		strata := map[string][]*subjectResult{}
+		for _, r := range results {
+			key := r.strata[i].key
+			if _, ok := strata[key]; !ok {
+				order = append(order, key)
+			}
+			strata[key] = append(strata[key], r)
+		}
+		for _, key := range order {
+			members := strata[key]
+			st := members[0].strata[i]
+			sg := &mrpb.MeasureReport_Group_Stratifier_StratifierGroup{Value: st.value}
+			for j, cc := range st.components {
+				sg.Component = append(sg.Component, &mrpb.MeasureReport_Group_Stratifier_StratifierGroup_Component{
+					Code: s.components[j].code, Value: cc,
+				})
+			}
+			counts, score, err := e.tally(report, g, members)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range counts {
+				sg.Population = append(sg.Population, &mrpb.MeasureReport_Group_Stratifier_StratifierGroup_StratifierGroupPopulation{
+					Code: c.code, Count: c.count, SubjectResults: c.subjects,
+				})
+			}
+			sg.MeasureScore = score
+			sr.Stratum = append(sr.Stratum, sg)
+		}
+		out.Stratifier = append(out.Stratifier, sr)
+	}
+	return out, nil
+}
+
+// count is the size of a population of a group or stratum.
+type count struct {
+	code     *d4pb.CodeableConcept
+	count    *d4pb.Integer
+	subjects *d4pb.Reference
+}
+
+// tally counts the populations of g over results and computes the measure
+// score. Subject lists are added to report when requested.
+func (e *evaluator) tally(report *mrpb.MeasureReport, g *group, results []*subjectResult) ([]count, *d4pb.Quantity, error) {
+	sizes := map[string]int{}
+	var observations []interface{}
+	for _, r := range results {
+		for code, ms := range r.populations {
+			sizes[code] += len(ms)
+		}
+		observations = append(observations, r.observations...)
+	}
+	sizes[measureObservation] = len(observations)
+	var out []count
+	for _, p := range g.populations {
+		c := count{code: p.def.GetCode(), count: &d4pb.Integer{Value: int32(sizes[p.code])}}
+		if e.reportType == SubjectListReport && p.code != measureObservation {
+			ref, err := e.subjectList(report, p.code, results)
+			if err != nil {
+				return nil, nil, err
+			}
+			c.subjects = ref
+		}
+		out = append(out, c)
+	}
+	var score *d4pb.Quantity
+	switch g.scoring {
+	case proportion:
+		num := sizes[numerator] - sizes[numeratorExclusion]
+		den := sizes[denominator] - sizes[denominatorExclusion] - sizes[denominatorException]
+		score = ratioScore(num, den)
+	case ratio:
+		score = ratioScore(sizes[numerator]-sizes[numeratorExclusion], sizes[denominator]-sizes[denominatorExclusion])
+	case continuousVariable:
+		var err error
+		if score, err = aggregate(g.aggregate, observations); err != nil {
+			return nil, nil, err
+		}
+	}
+	return out, score, nil
+}
+
+func ratioScore(num, den int) *d4pb.Quantity {
+	if den <= 0 {
+		return nil
+	}
+	return decimalQuantity(float64(num)/float64(den), "")
+}
+
+func decimalQuantity(v float64, unit string) *d4pb.Quantity {
+	q, _ := fhirpath.ProtoValue(fhirpath.Quantity{Value: v, Unit: unit})
+	return q.(*d4pb.Quantity)
+}
+
+// aggregate combines the measure observations of a continuous variable
+// measure with the cqfm-aggregateMethod method.
+func aggregate(method string, observations []interface{}) (*d4pb.Quantity, error) {
+	if method == "count" {
+		return decimalQuantity(float64(len(observations)), ""), nil
+	}
+	if len(observations) == 0 {
+		return nil, nil
+	}
+	var values []float64
+	unit := ""
+	for _, o := range observations {
+		switch x := o.(type) {
+		case int64:
+			values = append(values, float64(x))
+		case float64:
+			values = append(values, x)
+		case fhirpath.Quantity:
+			if unit != "" && x.Unit != unit {
+				return nil, fmt.Errorf("measure observations have different units %q and %q", unit, x.Unit)
+			}
+			values, unit = append(values, x.Value), x.Unit
+		default:
+			return nil, fmt.Errorf("measure observation %v is not a number or quantity", o)
+		}
+	}
+	var v float64
+	switch method {
+	case "sum", "average":
+		for _, x := range values {
+			v += x
+		}
+		if method == "average" {
+			v /= float64(len(values))
+		}
+	case "minimum", "maximum":
+		v = values[0]
+		for _, x := range values[1:] {
+			if method == "minimum" {
+				v = math.Min(v, x)
+			} else {
+				v = math.Max(v, x)
+			}
+		}
+	case "median":
+		sort.Float64s(values)
+		n := len(values)
+		v = values[n/2]
+		if n%2 == 0 {
+			v = (values[n/2-1] + values[n/2]) / 2
+		}
+	default:
+		return nil, fmt.Errorf("unsupported aggregate method %q", method)
+	}
+	return decimalQuantity(v, unit), nil
+}
+
+// subjectList adds a List of the members of the population code over results
+// to the resources contained in report and returns a reference to it.
+func (e *evaluator) subjectList(report *mrpb.MeasureReport, code string, results []*subjectResult) (*d4pb.Reference, error) {
+	e.lists++
+	id := code + "-" + strconv.Itoa(e.lists)
+	list := &lpb.List{
+		Id:     &d4pb.Id{Value: id},
+		Status: &lpb.List_StatusCode{Value: c4pb.ListStatusCode_CURRENT},
+		Mode:   &lpb.List_ModeCode{Value: c4pb.ListModeCode_SNAPSHOT},
+	}
+	for _, r := range results {
+		for _, m := range r.populations[code] {
+			ref, err := fhirtypes.ResourceReference(m.key)
+			if err != nil {
+				// Members that are not resources cannot be listed.
+				continue
+			}
+			list.Entry = append(list.Entry, &lpb.List_Entry{Item: ref})
+		}
+	}
+	any, err := anypb.New(&r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_List{List: list}})
+	if err != nil {
+		return nil, err
+	}
+	report.Contained = append(report.Contained, any)
+	return &d4pb.Reference{Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: id}}}, nil
+}
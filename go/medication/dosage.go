@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package medication
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/schedule"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// ErrAsNeeded is returned by DailyDose for Dosages taken as needed without a
+// maximum dose per period.
+var ErrAsNeeded = errors.New("dosage is taken as needed")
+
+// window is the period over which the administrations of a Timing are
+// averaged: four weeks, starting on a Monday in February of a year that is
+// not a leap year, so that weekly and monthly Timings both fit it exactly.
+var window = struct{ start, end time.Time }{
+	time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// DailyDose returns the average dose per day of d, in the normalized unit
+// of its dose: the dose of its first doseAndRate, or the high of a dose
+// Range, times the number of administrations of its timing per day,
+// averaged over four weeks. The bounds and count of the timing are ignored,
+// as the dose per day does not depend on the length of the course. For
+// Dosages taken as needed, it is the maximum dose per period of d, per day.
+func DailyDose(d *d4pb.Dosage) (*d4pb.Quantity, error) {
+	if an := d.GetAsNeeded(); an.GetBoolean().GetValue() || an.GetCodeableConcept() != nil {
+		return maxDailyDose(d.GetMaxDosePerPeriod())
+	}
+	var dose *d4pb.Quantity
+	for _, dr := range d.GetDoseAndRate() {
+		if q := dr.GetDose().GetQuantity(); q != nil {
+			dose = fhirtypes.QuantityOfSimple(q)
+		} else if r := dr.GetDose().GetRange(); r != nil {
+			if dose = fhirtypes.QuantityOfSimple(r.GetHigh()); r.GetHigh() == nil {
+				dose = fhirtypes.QuantityOfSimple(r.GetLow())
+			}
+		}
+		if dose != nil {
+			break
+		}
+	}
+	if dose == nil {
+		return nil, errors.New("dosage has no dose")
+	}
+	dose, err := NormalizeQuantity(dose)
+	if err != nil {
+		return nil, fmt.Errorf("dose: %w", err)
+	}
+	t := proto.Clone(d.GetTiming()).(*d4pb.Timing)
+	if r := t.GetRepeat(); r != nil {
+		r.Bounds, r.Count, r.CountMax = nil, nil, nil
+	}
+	times, err := schedule.Expand(t, window.start, window.end, schedule.Options{Location: time.UTC, Start: window.start})
+	if err != nil {
+		return nil, fmt.Errorf("timing: %w", err)
+	}
+	if len(times) == 0 {
+		return nil, errors.New("timing has no repeat")
+	}
+	days := int64(window.end.Sub(window.start) / (24 * time.Hour))
+	return scale(dose, big.NewRat(int64(len(times)), days))
+}
+
+// maxDailyDose returns the maximum dose per period r, per day.
+func maxDailyDose(r *d4pb.Ratio) (*d4pb.Quantity, error) {
+	if r == nil {
+		return nil, ErrAsNeeded
+	}
+	dose, err := NormalizeQuantity(r.GetNumerator())
+	if err != nil {
+		return nil, fmt.Errorf("maximum dose per period: %w", err)
+	}
+	period, err := fhirtypes.ConvertQuantity(r.GetDenominator(), "d")
+	if err != nil {
+		return nil, fmt.Errorf("maximum dose per period: %w", err)
+	}
+	days, err := decimal(period.GetValue())
+	if err != nil {
+		return nil, err
+	}
+	if days.Sign() == 0 {
+		return nil, errors.New("maximum dose per period: period is zero")
+	}
+	return scale(dose, days.Inv(days))
+}
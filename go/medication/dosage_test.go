@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package medication
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+func simple(q *d4pb.Quantity) *d4pb.SimpleQuantity {
+	return &d4pb.SimpleQuantity{Value: q.GetValue(), Unit: q.GetUnit(), System: q.GetSystem(), Code: q.GetCode()}
+}
+
+func dosage(dose *d4pb.Dosage_DoseAndRate_DoseX, t *d4pb.Timing) *d4pb.Dosage {
+	return &d4pb.Dosage{Timing: t, DoseAndRate: []*d4pb.Dosage_DoseAndRate{{Dose: dose}}}
+}
+
+func doseQuantity(q *d4pb.Quantity) *d4pb.Dosage_DoseAndRate_DoseX {
+	return &d4pb.Dosage_DoseAndRate_DoseX{Choice: &d4pb.Dosage_DoseAndRate_DoseX_Quantity{Quantity: simple(q)}}
+}
+
+func every(frequency uint32, period string, unit vspb.UnitsOfTimeValueSet_Value) *d4pb.Timing {
+	return &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{
+		Frequency:  fhirtypes.PositiveInt(frequency),
+		Period:     fhirtypes.Decimal(period),
+		PeriodUnit: &d4pb.Timing_Repeat_PeriodUnitCode{Value: unit},
+	}}
+}
+
+func TestDailyDose(t *testing.T) {
+	bounded := every(3, "1", vspb.UnitsOfTimeValueSet_D)
+	bounded.Repeat.Count = fhirtypes.PositiveInt(2)
+	weekly := every(1, "1", vspb.UnitsOfTimeValueSet_WK)
+	tests := []struct {
+		name string
+		d    *d4pb.Dosage
+		want *d4pb.Quantity
+	}{
+		{"three times a day", dosage(doseQuantity(fhirtypes.Quantity("0.5", "g")), every(3, "1", vspb.UnitsOfTimeValueSet_D)), fhirtypes.Quantity("1500", "mg")},
+		{"count ignored", dosage(doseQuantity(tablets("2")), bounded), tablets("6")},
+		{"every other day", dosage(doseQuantity(fhirtypes.Quantity("10", "mg")), every(1, "2", vspb.UnitsOfTimeValueSet_D)), fhirtypes.Quantity("5", "mg")},
+		{"weekly", dosage(doseQuantity(fhirtypes.Quantity("70", "mg")), weekly), fhirtypes.Quantity("10", "mg")},
+		{"code", dosage(doseQuantity(fhirtypes.Quantity("5", "mL")), &d4pb.Timing{
+			Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding("http://terminology.hl7.org/CodeSystem/v3-GTSAbbreviation", "BID")}},
+		}), fhirtypes.Quantity("10", "mL")},
+		{"range", dosage(&d4pb.Dosage_DoseAndRate_DoseX{Choice: &d4pb.Dosage_DoseAndRate_DoseX_Range{Range: &d4pb.Range{
+			Low: simple(tablets("1")), High: simple(tablets("2")),
+		}}}, every(2, "1", vspb.UnitsOfTimeValueSet_D)), tablets("4")},
+		{"as needed", &d4pb.Dosage{
+			AsNeeded:         &d4pb.Dosage_AsNeededX{Choice: &d4pb.Dosage_AsNeededX_Boolean{Boolean: fhirtypes.Boolean(true)}},
+			MaxDosePerPeriod: ratio(fhirtypes.Quantity("4", "g"), fhirtypes.Quantity("24", "h")),
+		}, fhirtypes.Quantity("4000", "mg")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DailyDose(tc.d)
+			if err != nil {
+				t.Fatalf("DailyDose() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("DailyDose() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDailyDoseErrors(t *testing.T) {
+	asNeeded := &d4pb.Dosage{AsNeeded: &d4pb.Dosage_AsNeededX{Choice: &d4pb.Dosage_AsNeededX_Boolean{Boolean: fhirtypes.Boolean(true)}}}
+	if _, err := DailyDose(asNeeded); !errors.Is(err, ErrAsNeeded) {
+		t.Errorf("DailyDose() returned error %v, want %v", err, ErrAsNeeded)
+	}
+	noDose := &d4pb.Dosage{Timing: every(1, "1", vspb.UnitsOfTimeValueSet_D)}
+	if _, err := DailyDose(noDose); err == nil {
+		t.Errorf("DailyDose() succeeded without a dose, want error")
+	}
+	noTiming := dosage(doseQuantity(fhirtypes.Quantity("5", "mg")), nil)
+	if _, err := DailyDose(noTiming); err == nil {
+		t.Errorf("DailyDose() succeeded without a timing, want error")
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package medication
+
+import (
+	"sort"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// RxNormSystem is the code system of RxNorm concepts.
+const RxNormSystem = "http://www.nlm.nih.gov/research/umls/rxnorm"
+
+// RxNorm relationships Compare follows.
+const (
+	// TradenameOf relates a branded drug to the clinical drug it is a brand
+	// of, i.e. an SBD to its SCD.
+	TradenameOf = "tradename_of"
+	// HasIngredient relates a clinical drug to its ingredients, as the
+	// ingredients of its components.
+	HasIngredient = "has_ingredient"
+)
+
+// Source is a source of RxNorm relationships, such as the RxNav API or a
+// local copy of the RxNorm release.
+type Source interface {
+	// Related returns the RxCUIs of the concepts that the concept rxcui is
+	// related to by the relationship rela, or none if it has no such
+	// relationship.
+	Related(rxcui, rela string) ([]string, error)
+}
+
+// Relationships is a Source of relationships held in memory, by RxCUI and
+// relationship.
+type Relationships map[string]map[string][]string
+
+// Related implements Source.
+func (r Relationships) Related(rxcui, rela string) ([]string, error) {
+	return r[rxcui][rela], nil
+}
+
+// Equivalence is how closely two medications correspond.
+type Equivalence int
+
+const (
+	// NotEquivalent medications have different ingredients, or are not
+	// coded in RxNorm.
+	NotEquivalent Equivalence = iota
+	// SameIngredients medications have the same active ingredients, but in
+	// other strengths or dose forms.
+	SameIngredients
+	// TherapeuticallyEquivalent medications are the same clinical drug,
+	// such as a branded drug and its generic.
+	TherapeuticallyEquivalent
+	// Identical medications have the same RxNorm code.
+	Identical
+)
+
+// String returns the name of e.
+func (e Equivalence) String() string {
+	switch e {
+	case SameIngredients:
+		return "same-ingredients"
+	case TherapeuticallyEquivalent:
+		return "therapeutically-equivalent"
+	case Identical:
+		return "identical"
+	}
+	return "not-equivalent"
+}
+
+// Compare returns the Equivalence of the medications coded a and b, by their
+// RxNorm codings and the relationships of src: branded drugs are the
+// clinical drugs they are a tradename of, and clinical drugs with the same
+// ingredients have the same ingredients.
+func Compare(a, b *d4pb.CodeableConcept, src Source) (Equivalence, error) {
+	ca, cb := rxcuis(a), rxcuis(b)
+	if len(ca) == 0 || len(cb) == 0 {
+		return NotEquivalent, nil
+	}
+	if intersects(ca, cb) {
+		return Identical, nil
+	}
+	var err error
+	if ca, err = clinical(ca, src); err != nil {
+		return NotEquivalent, err
+	}
+	if cb, err = clinical(cb, src); err != nil {
+		return NotEquivalent, err
+	}
+	if intersects(ca, cb) {
+		return TherapeuticallyEquivalent, nil
+	}
+	ia, err := related(ca, HasIngredient, src)
+	if err != nil {
+		return NotEquivalent, err
+	}
+	ib, err := related(cb, HasIngredient, src)
+	if err != nil {
+		return NotEquivalent, err
+	}
+	if len(ia) > 0 && equal(ia, ib) {
+		return SameIngredients, nil
+	}
+	return NotEquivalent, nil
+}
+
+// rxcuis returns the RxNorm codes of cc.
+func rxcuis(cc *d4pb.CodeableConcept) map[string]bool {
+	out := map[string]bool{}
+	for _, c := range cc.GetCoding() {
+		if fhirtypes.NormalizeSystem(c.GetSystem().GetValue()) == RxNormSystem && c.GetCode().GetValue() != "" {
+			out[c.GetCode().GetValue()] = true
+		}
+	}
+	return out
+}
+
+// clinical returns the clinical drugs of the concepts cs: the clinical drugs
+// that those that are branded drugs are a tradename of, and the others.
+func clinical(cs map[string]bool, src Source) (map[string]bool, error) {
+	out := map[string]bool{}
+	for _, c := range sorted(cs) {
+		scds, err := src.Related(c, TradenameOf)
+		if err != nil {
+			return nil, err
+		}
+		if len(scds) == 0 {
+			out[c] = true
+		}
+		for _, s := range scds {
+			out[s] = true
+		}
+	}
+	return out, nil
+}
+
+// related returns the concepts that the concepts cs are related to by rela.
+func related(cs map[string]bool, rela string, src Source) (map[string]bool, error) {
+	out := map[string]bool{}
+	for _, c := range sorted(cs) {
+		rs, err := src.Related(c, rela)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rs {
+			out[r] = true
+		}
+	}
+	return out, nil
+}
+
+func sorted(s map[string]bool) []string {
+	out := make([]string, 0, len(s))
+	for k := range s {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func intersects(a, b map[string]bool) bool {
+	for k := range a {
+		if b[k] {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if !b[k] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package medication
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func rxnorm(codes ...string) *d4pb.CodeableConcept {
+	cc := &d4pb.CodeableConcept{}
+	for _, c := range codes {
+		cc.Coding = append(cc.Coding, fhirtypes.Coding(RxNormSystem, c))
+	}
+	return cc
+}
+
+func TestCompare(t *testing.T) {
+	// Lisinopril 10 mg tablets as Zestril and Prinivil, the generic, and
+	// lisinopril 20 mg tablets; hydrochlorothiazide 25 mg tablets.
+	src := Relationships{
+		"206771": {TradenameOf: {"314076"}},
+		"104376": {TradenameOf: {"314076"}},
+		"314076": {HasIngredient: {"29046"}},
+		"314077": {HasIngredient: {"29046"}},
+		"310798": {HasIngredient: {"5487"}},
+	}
+	tests := []struct {
+		name string
+		a, b *d4pb.CodeableConcept
+		want Equivalence
+	}{
+		{"same code", rxnorm("314076"), rxnorm("999", "314076"), Identical},
+		{"brand and generic", rxnorm("206771"), rxnorm("314076"), TherapeuticallyEquivalent},
+		{"two brands", rxnorm("206771"), rxnorm("104376"), TherapeuticallyEquivalent},
+		{"other strength", rxnorm("206771"), rxnorm("314077"), SameIngredients},
+		{"other ingredient", rxnorm("314076"), rxnorm("310798"), NotEquivalent},
+		{"unknown", rxnorm("1"), rxnorm("2"), NotEquivalent},
+		{"not RxNorm", &d4pb.CodeableConcept{Text: fhirtypes.String("lisinopril")}, rxnorm("314076"), NotEquivalent},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Compare(tc.a, tc.b, src)
+			if err != nil {
+				t.Fatalf("Compare() returned unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Compare() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package medication normalizes the ingredient strengths of R4 Medications
+// and MedicationKnowledge to canonical units, computes daily doses from
+// Dosages, and compares medications for therapeutic equivalence by their
+// RxNorm relationships.
+//
+// Strengths are normalized to the first of CanonicalUnits that their units
+// convert to, per one unit of the denominator, so that 250 mg/5 mL and
+// 0.05 g/mL are both 50 mg/1 mL; units that are not UCUM units, such as
+// tablets, are kept.
+package medication
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	medpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mkpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_knowledge_go_proto"
+)
+
+// CanonicalUnits are the UCUM units quantities are normalized to, one for
+// each dimension: milligrams, milliliters, millimoles and international
+// units.
+var CanonicalUnits = []string{"mg", "mL", "mmol", "[iU]"}
+
+// NormalizeQuantity returns q in the first of CanonicalUnits its unit
+// converts to, or a copy of q if there is none.
+func NormalizeQuantity(q *d4pb.Quantity) (*d4pb.Quantity, error) {
+	if q.GetValue() == nil {
+		return nil, errors.New("quantity has no value")
+	}
+	for _, u := range CanonicalUnits {
+		c, err := fhirtypes.ConvertQuantity(q, u)
+		if errors.Is(err, fhirtypes.ErrIncompatibleUnits) {
+			continue
+		}
+		if err != nil {
+			// Units that are not UCUM units are kept.
+			break
+		}
+		return c, nil
+	}
+	return proto.Clone(q).(*d4pb.Quantity), nil
+}
+
+// NormalizeStrength returns the strength r with its numerator and
+// denominator normalized by NormalizeQuantity, per one unit of the
+// denominator.
+func NormalizeStrength(r *d4pb.Ratio) (*d4pb.Ratio, error) {
+	if r.GetNumerator() == nil || r.GetDenominator() == nil {
+		return nil, errors.New("strength has no numerator or denominator")
+	}
+	num, err := NormalizeQuantity(r.GetNumerator())
+	if err != nil {
+		return nil, fmt.Errorf("numerator: %w", err)
+	}
+	den, err := NormalizeQuantity(r.GetDenominator())
+	if err != nil {
+		return nil, fmt.Errorf("denominator: %w", err)
+	}
+	d, err := decimal(den.GetValue())
+	if err != nil {
+		return nil, fmt.Errorf("denominator: %w", err)
+	}
+	if d.Sign() == 0 {
+		return nil, errors.New("denominator is zero")
+	}
+	if num, err = scale(num, new(big.Rat).Inv(d)); err != nil {
+		return nil, fmt.Errorf("numerator: %w", err)
+	}
+	den.Value = fhirtypes.Decimal("1")
+	return &d4pb.Ratio{Numerator: num, Denominator: den}, nil
+}
+
+// Ingredient is an ingredient of a medication.
+type Ingredient struct {
+	// Item is the code of the ingredient, or Reference the Substance or
+	// Medication it is.
+	Item      *d4pb.CodeableConcept
+	Reference *d4pb.Reference
+	// Active is false only for ingredients whose isActive is false.
+	Active bool
+	// Strength is the normalized strength of the ingredient, or nil if it
+	// has none.
+	Strength *d4pb.Ratio
+}
+
+// Ingredients returns the ingredients of the Medication or
+// MedicationKnowledge m, with their strengths normalized.
+func Ingredients(m proto.Message) ([]Ingredient, error) {
+	var out []Ingredient
+	add := func(item *d4pb.CodeableConcept, ref *d4pb.Reference, active *d4pb.Boolean, strength *d4pb.Ratio) error {
+		in := Ingredient{Item: item, Reference: ref, Active: active == nil || active.GetValue()}
+		if strength != nil {
+			s, err := NormalizeStrength(strength)
+			if err != nil {
+				return fmt.Errorf("ingredient %d: strength %w", len(out), err)
+			}
+			in.Strength = s
+		}
+		out = append(out, in)
+		return nil
+	}
+	switch m := m.(type) {
+	case *medpb.Medication:
+		for _, in := range m.GetIngredient() {
+			if err := add(in.GetItem().GetCodeableConcept(), in.GetItem().GetReference(), in.GetIsActive(), in.GetStrength()); err != nil {
+				return nil, fmt.Errorf("Medication %s: %w", m.GetId().GetValue(), err)
+			}
+		}
+	case *mkpb.MedicationKnowledge:
+		for _, in := range m.GetIngredient() {
+			if err := add(in.GetItem().GetCodeableConcept(), in.GetItem().GetReference(), in.GetIsActive(), in.GetStrength()); err != nil {
+				return nil, fmt.Errorf("MedicationKnowledge %s: %w", m.GetId().GetValue(), err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%T is not a Medication or MedicationKnowledge", m)
+	}
+	return out, nil
+}
+
+// IngredientDose returns the amount of an ingredient of the given strength
+// in dose, such as 1000 mg in 2 tablets of 500 mg/1 tablet. The unit of
+// dose must convert to that of the denominator of strength.
+func IngredientDose(dose *d4pb.Quantity, strength *d4pb.Ratio) (*d4pb.Quantity, error) {
+	s, err := NormalizeStrength(strength)
+	if err != nil {
+		return nil, err
+	}
+	n, err := NormalizeQuantity(dose)
+	if err != nil {
+		return nil, err
+	}
+	den := s.GetDenominator()
+	if unit(n) != unit(den) || n.GetSystem().GetValue() != den.GetSystem().GetValue() {
+		if n, err = fhirtypes.ConvertQuantity(n, unit(den)); err != nil {
+			return nil, fmt.Errorf("dose of %q for strength per %q: %w", unit(dose), unit(den), err)
+		}
+	}
+	v, err := decimal(n.GetValue())
+	if err != nil {
+		return nil, err
+	}
+	return scale(s.GetNumerator(), v)
+}
+
+// unit returns the unit code of q, or its human readable unit if it has no
+// code.
+func unit(q *d4pb.Quantity) string {
+	if c := q.GetCode().GetValue(); c != "" {
+		return c
+	}
+	return q.GetUnit().GetValue()
+}
+
+func decimal(d *d4pb.Decimal) (*big.Rat, error) {
+	v, ok := new(big.Rat).SetString(d.GetValue())
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", d.GetValue())
+	}
+	return v, nil
+}
+
+// scale returns a copy of q with its value multiplied by f.
+func scale(q *d4pb.Quantity, f *big.Rat) (*d4pb.Quantity, error) {
+	v, err := decimal(q.GetValue())
+	if err != nil {
+		return nil, err
+	}
+	out := proto.Clone(q).(*d4pb.Quantity)
+	out.Value = fhirtypes.Decimal(format(v.Mul(v, f)))
+	return out, nil
+}
+
+// format formats r with as many decimal places as it needs, up to 6.
+func format(r *big.Rat) string {
+	s := r.FloatString(6)
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package medication
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	medpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mkpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_knowledge_go_proto"
+)
+
+const drugForm = "http://terminology.hl7.org/CodeSystem/v3-orderableDrugForm"
+
+func tablets(value string) *d4pb.Quantity {
+	return &d4pb.Quantity{Value: fhirtypes.Decimal(value), Unit: fhirtypes.String("tablet"), System: fhirtypes.URI(drugForm), Code: fhirtypes.Code("TAB")}
+}
+
+func ratio(num, den *d4pb.Quantity) *d4pb.Ratio {
+	return &d4pb.Ratio{Numerator: num, Denominator: den}
+}
+
+func TestNormalizeStrength(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *d4pb.Ratio
+		want *d4pb.Ratio
+	}{
+		{"per volume", ratio(fhirtypes.Quantity("250", "mg"), fhirtypes.Quantity("5", "mL")), ratio(fhirtypes.Quantity("50", "mg"), fhirtypes.Quantity("1", "mL"))},
+		{"grams per liter", ratio(fhirtypes.Quantity("0.9", "g"), fhirtypes.Quantity("100", "mL")), ratio(fhirtypes.Quantity("9", "mg"), fhirtypes.Quantity("1", "mL"))},
+		{"micrograms", ratio(fhirtypes.Quantity("125", "ug"), tablets("1")), ratio(fhirtypes.Quantity("0.125", "mg"), tablets("1"))},
+		{"units", ratio(fhirtypes.Quantity("100", "[iU]"), fhirtypes.Quantity("1", "mL")), ratio(fhirtypes.Quantity("100", "[iU]"), fhirtypes.Quantity("1", "mL"))},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizeStrength(tc.in)
+			if err != nil {
+				t.Fatalf("NormalizeStrength() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("NormalizeStrength() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := NormalizeStrength(ratio(fhirtypes.Quantity("5", "mg"), fhirtypes.Quantity("0", "mL"))); err == nil {
+		t.Errorf("NormalizeStrength() succeeded for a zero denominator, want error")
+	}
+}
+
+func TestIngredients(t *testing.T) {
+	apap := &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(RxNormSystem, "161")}}
+	med := &medpb.Medication{
+		Ingredient: []*medpb.Medication_Ingredient{{
+			Item:     &medpb.Medication_Ingredient_ItemX{Choice: &medpb.Medication_Ingredient_ItemX_CodeableConcept{CodeableConcept: apap}},
+			Strength: ratio(fhirtypes.Quantity("0.5", "g"), tablets("1")),
+		}, {
+			Item:     &medpb.Medication_Ingredient_ItemX{Choice: &medpb.Medication_Ingredient_ItemX_Reference{Reference: fhirtypes.Reference("Substance", "starch")}},
+			IsActive: fhirtypes.Boolean(false),
+		}},
+	}
+	want := []Ingredient{
+		{Item: apap, Active: true, Strength: ratio(fhirtypes.Quantity("500", "mg"), tablets("1"))},
+		{Reference: fhirtypes.Reference("Substance", "starch")},
+	}
+	got, err := Ingredients(med)
+	if err != nil {
+		t.Fatalf("Ingredients() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Ingredients() diff (-want +got):\n%s", diff)
+	}
+
+	mk := &mkpb.MedicationKnowledge{
+		Ingredient: []*mkpb.MedicationKnowledge_Ingredient{{
+			Item:     &mkpb.MedicationKnowledge_Ingredient_ItemX{Choice: &mkpb.MedicationKnowledge_Ingredient_ItemX_CodeableConcept{CodeableConcept: apap}},
+			Strength: ratio(fhirtypes.Quantity("160", "mg"), fhirtypes.Quantity("5", "mL")),
+		}},
+	}
+	got, err = Ingredients(mk)
+	if err != nil {
+		t.Fatalf("Ingredients() returned unexpected error: %v", err)
+	}
+	want = []Ingredient{{Item: apap, Active: true, Strength: ratio(fhirtypes.Quantity("32", "mg"), fhirtypes.Quantity("1", "mL"))}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Ingredients() diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := Ingredients(&d4pb.Quantity{}); err == nil {
+		t.Errorf("Ingredients() succeeded for a Quantity, want error")
+	}
+}
+
+func TestIngredientDose(t *testing.T) {
+	tests := []struct {
+		name     string
+		dose     *d4pb.Quantity
+		strength *d4pb.Ratio
+		want     *d4pb.Quantity
+	}{
+		{"tablets", tablets("2"), ratio(fhirtypes.Quantity("500", "mg"), tablets("1")), fhirtypes.Quantity("1000", "mg")},
+		{"volume", fhirtypes.Quantity("7.5", "mL"), ratio(fhirtypes.Quantity("160", "mg"), fhirtypes.Quantity("5", "mL")), fhirtypes.Quantity("240", "mg")},
+		{"other volume unit", fhirtypes.Quantity("0.01", "L"), ratio(fhirtypes.Quantity("1", "g"), fhirtypes.Quantity("100", "mL")), fhirtypes.Quantity("100", "mg")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := IngredientDose(tc.dose, tc.strength)
+			if err != nil {
+				t.Fatalf("IngredientDose() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("IngredientDose() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+	if _, err := IngredientDose(fhirtypes.Quantity("5", "mL"), ratio(fhirtypes.Quantity("500", "mg"), tablets("1"))); err == nil {
+		t.Errorf("IngredientDose() succeeded for mismatched units, want error")
+	}
+}
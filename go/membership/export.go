@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membership
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	grouppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/group_go_proto"
+)
+
+// PatientIDs returns the sorted ids of the Patients among members, as a
+// group-level $export needs: members whose reference is to a Patient, or
+// that resolved to one. Members that are neither, such as unresolved
+// logical references, are left out.
+func PatientIDs(members []Member) []string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, m := range members {
+		id := patientID(m)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func patientID(m Member) string {
+	if m.Resource != nil {
+		if elementpath.ResourceType(m.Resource) != "Patient" {
+			return ""
+		}
+		if key := resourceKey(m.Resource); key != "" {
+			return key[len("Patient/"):]
+		}
+	}
+	p, err := fhirtypes.ParseReference(m.Reference)
+	if err != nil || p.Type != "Patient" {
+		return ""
+	}
+	return p.ID
+}
+
+// Materializer keeps the patient ids of Groups for group-level $export, so
+// that exports of a Group do not resolve its members again until they may
+// have changed: an actual Group when it is updated, and a descriptive one
+// when it is updated or, if Options.Match is set, as Patients change. It is
+// safe for concurrent use.
+type Materializer struct {
+	opts  Options
+	match func(res proto.Message, params url.Values) (bool, error)
+
+	mu     sync.Mutex
+	groups map[string]*materialized
+}
+
+type materialized struct {
+	group  *grouppb.Group
+	params url.Values // of descriptive Groups
+	ids    map[string]bool
+}
+
+// NewMaterializer returns a Materializer resolving the members of Groups
+// with opts. match reports whether a Patient matches the search of a
+// descriptive Group, as fhirstore.Match does, and lets Patient changes
+// update descriptive Groups without searching again; if it is nil,
+// PatientChanged marks descriptive Groups for recomputation instead.
+func NewMaterializer(opts Options, match func(res proto.Message, params url.Values) (bool, error)) *Materializer {
+	return &Materializer{opts: opts, match: match, groups: map[string]*materialized{}}
+}
+
+// Patients returns the sorted ids of the Patients of g, which must have an
+// id, recomputing them if g differs from the version they were computed
+// for.
+func (m *Materializer) Patients(g *grouppb.Group) ([]string, error) {
+	key := resourceKey(g)
+	if key == "" {
+		return nil, fmt.Errorf("Group has no id")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.groups[key]; ok && proto.Equal(s.group, g) {
+		return sortedIDs(s.ids), nil
+	}
+	members, err := GroupMembers(g, m.opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &materialized{group: proto.Clone(g).(*grouppb.Group), ids: map[string]bool{}}
+	if !g.GetActual().GetValue() {
+		if _, s.params, err = Query(g, m.opts.Parameters); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range PatientIDs(members) {
+		s.ids[id] = true
+	}
+	m.groups[key] = s
+	return sortedIDs(s.ids), nil
+}
+
+// PatientChanged updates the descriptive Groups materialized so far for the
+// created or updated Patient p, and returns the keys of the Groups, such as
+// "Group/1", whose patients changed, in order. Without a match function,
+// every descriptive Group is forgotten, to be recomputed by the next call
+// to Patients, and none is returned.
+func (m *Materializer) PatientChanged(p proto.Message) ([]string, error) {
+	p = elementpath.Unwrap(p)
+	key := resourceKey(p)
+	if elementpath.ResourceType(p) != "Patient" || key == "" {
+		return nil, fmt.Errorf("not a Patient with an id")
+	}
+	id := key[len("Patient/"):]
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var changed []string
+	for gk, s := range m.groups {
+		if s.params == nil {
+			continue
+		}
+		if m.match == nil {
+			delete(m.groups, gk)
+			continue
+		}
+		in, err := m.match(p, s.params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", gk, err)
+		}
+		if in != s.ids[id] {
+			if in {
+				s.ids[id] = true
+			} else {
+				delete(s.ids, id)
+			}
+			changed = append(changed, gk)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// PatientDeleted removes the Patient with the id from the descriptive
+// Groups materialized so far, and returns the keys of the Groups it was a
+// member of, in order. Actual Groups keep referring to deleted Patients
+// until they are updated.
+func (m *Materializer) PatientDeleted(id string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var changed []string
+	for gk, s := range m.groups {
+		if s.params != nil && s.ids[id] {
+			delete(s.ids, id)
+			changed = append(changed, gk)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Forget drops the patients materialized for the Group key, such as
+// "Group/1", as when the Group is deleted.
+func (m *Materializer) Forget(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.groups, key)
+}
+
+func sortedIDs(set map[string]bool) []string {
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
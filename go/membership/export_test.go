@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membership
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	grouppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/group_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestPatientIDs(t *testing.T) {
+	members := []Member{
+		{Reference: "Patient/p2"},
+		{Reference: "https://example.com/fhir/Patient/p1"},
+		{Reference: "Patient/p2", Resource: patient("p2")},
+		{Reference: "Patient?identifier=http://example.org/mrn|1"},
+		{Reference: "Practitioner/d1"},
+		{Reference: "Patient?identifier=http://example.org/mrn|3", Resource: patient("p3")},
+	}
+	if diff := cmp.Diff([]string{"p1", "p2", "p3"}, PatientIDs(members)); diff != "" {
+		t.Errorf("PatientIDs() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMaterializer_Actual(t *testing.T) {
+	g := &grouppb.Group{
+		Id:     &d4pb.Id{Value: "g1"},
+		Type:   &grouppb.Group_TypeCode{Value: c4pb.GroupTypeCode_PERSON},
+		Actual: &d4pb.Boolean{Value: true},
+		Member: []*grouppb.Group_Member{{Entity: patientRef("p2")}, {Entity: patientRef("p1")}},
+	}
+	resolved := 0
+	counting := func(ref string) (proto.Message, error) {
+		resolved++
+		return resolver(ref)
+	}
+	m := NewMaterializer(Options{Resolver: counting}, nil)
+	for i := 0; i < 2; i++ {
+		got, err := m.Patients(g)
+		if err != nil {
+			t.Fatalf("Patients() returned unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]string{"p1", "p2"}, got); diff != "" {
+			t.Errorf("Patients() diff (-want +got):\n%s", diff)
+		}
+	}
+	if resolved != 2 {
+		t.Errorf("Patients() resolved %d references, want 2 for an unchanged Group", resolved)
+	}
+	g.Member = append(g.Member, &grouppb.Group_Member{Entity: patientRef("p3")})
+	got, err := m.Patients(g)
+	if err != nil {
+		t.Fatalf("Patients() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"p1", "p2", "p3"}, got); diff != "" {
+		t.Errorf("Patients() of the updated Group diff (-want +got):\n%s", diff)
+	}
+	if _, err := m.Patients(&grouppb.Group{Actual: &d4pb.Boolean{Value: true}}); err == nil {
+		t.Errorf("Patients() of a Group without id succeeded, want error")
+	}
+}
+
+func TestMaterializer_Descriptive(t *testing.T) {
+	g := &grouppb.Group{
+		Id:   &d4pb.Id{Value: "active"},
+		Type: &grouppb.Group_TypeCode{Value: c4pb.GroupTypeCode_PERSON},
+		Characteristic: []*grouppb.Group_Characteristic{
+			characteristic("active", false, &grouppb.Group_Characteristic_ValueX{Choice: &grouppb.Group_Characteristic_ValueX_Boolean{
+				Boolean: &d4pb.Boolean{Value: true},
+			}}),
+		},
+	}
+	searches := 0
+	search := func(string, string) ([]proto.Message, error) {
+		searches++
+		return []proto.Message{patient("p1"), patient("p2")}, nil
+	}
+	match := func(res proto.Message, params url.Values) (bool, error) {
+		return res.(*ppb.Patient).GetActive().GetValue() == (params.Get("active") == "true"), nil
+	}
+	active := func(id string, a bool) *ppb.Patient {
+		p := patient(id)
+		p.Active = &d4pb.Boolean{Value: a}
+		return p
+	}
+
+	m := NewMaterializer(Options{Search: search}, match)
+	if _, err := m.Patients(g); err != nil {
+		t.Fatalf("Patients() returned unexpected error: %v", err)
+	}
+	steps := []struct {
+		patient *ppb.Patient
+		changed []string
+	}{
+		{active("p3", true), []string{"Group/active"}},
+		{active("p3", true), nil},
+		{active("p1", false), []string{"Group/active"}},
+	}
+	for i, s := range steps {
+		changed, err := m.PatientChanged(s.patient)
+		if err != nil {
+			t.Fatalf("step %d: PatientChanged() returned unexpected error: %v", i, err)
+		}
+		if diff := cmp.Diff(s.changed, changed); diff != "" {
+			t.Errorf("step %d: PatientChanged() diff (-want +got):\n%s", i, diff)
+		}
+	}
+	if diff := cmp.Diff([]string{"Group/active"}, m.PatientDeleted("p2")); diff != "" {
+		t.Errorf("PatientDeleted() diff (-want +got):\n%s", diff)
+	}
+	got, err := m.Patients(g)
+	if err != nil {
+		t.Fatalf("Patients() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"p3"}, got); diff != "" {
+		t.Errorf("Patients() diff (-want +got):\n%s", diff)
+	}
+	if searches != 1 {
+		t.Errorf("Patients() searched %d times, want 1", searches)
+	}
+
+	m = NewMaterializer(Options{Search: search}, nil)
+	if _, err := m.Patients(g); err != nil {
+		t.Fatalf("Patients() returned unexpected error: %v", err)
+	}
+	if changed, err := m.PatientChanged(active("p3", true)); err != nil || changed != nil {
+		t.Errorf("PatientChanged() without match = %v, %v, want none", changed, err)
+	}
+	if _, err := m.Patients(g); err != nil {
+		t.Fatalf("Patients() returned unexpected error: %v", err)
+	}
+	if searches != 3 {
+		t.Errorf("Patients() searched %d times in all, want 3 after a change without match", searches)
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package membership materializes the members of R4 Groups and Lists into
+// the resources they stand for, and tracks how memberships change.
+//
+// The members of actual Groups and the entries of Lists are references,
+// which are resolved with a fhirpath.Resolver. Descriptive Groups, which
+// define their members by characteristics rather than enumerate them, are
+// translated into a search for the resources of the type of the Group: every
+// characteristic becomes a search parameter named for its code, whose value
+// is that of the characteristic, and excluded characteristics use the :not
+// modifier.
+//
+// For group-level $export, PatientIDs reduces members to the ids of their
+// Patients, and a Materializer keeps those of Groups between exports,
+// updating descriptive Groups as Patients change.
+package membership
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	grouppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/group_go_proto"
+	listpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/list_go_proto"
+)
+
+// Options configures the resolution of members.
+type Options struct {
+	// Resolver resolves the references of the members of actual Groups and
+	// the entries of Lists. A nil result leaves the member unresolved.
+	Resolver fhirpath.Resolver
+	// Search returns the resources of type resourceType matching params, the
+	// query string of a search, for descriptive Groups.
+	Search func(resourceType, params string) ([]proto.Message, error)
+	// Parameters maps the codes of characteristics, as "system|code" or
+	// "code", to the names of the search parameters they translate to.
+	// Codes without a mapping translate to the parameter named for the
+	// code itself.
+	Parameters map[string]string
+	// At is the time at which Group members must be active: members whose
+	// period does not contain it are left out. If it is zero, the periods
+	// of members are ignored.
+	At time.Time
+	// IncludeInactive includes the Group members marked inactive and the
+	// List entries marked deleted.
+	IncludeInactive bool
+}
+
+// Member is a member of a Group or List.
+type Member struct {
+	// Reference identifies the member: the literal reference of the member
+	// or entry, "Type?identifier=system|value" for logical references, or
+	// "Type/id" for the resources found by searching.
+	Reference string
+	// Resource is the resource of the member, or nil if the reference did
+	// not resolve.
+	Resource proto.Message
+}
+
+// groupTypes are the resource types of the members of the types of Groups.
+var groupTypes = map[c4pb.GroupTypeCode_Value]string{
+	c4pb.GroupTypeCode_PERSON:       "Patient",
+	c4pb.GroupTypeCode_ANIMAL:       "Patient",
+	c4pb.GroupTypeCode_PRACTITIONER: "Practitioner",
+	c4pb.GroupTypeCode_DEVICE:       "Device",
+	c4pb.GroupTypeCode_MEDICATION:   "Medication",
+	c4pb.GroupTypeCode_SUBSTANCE:    "Substance",
+}
+
+// GroupMembers returns the members of g. The members of an actual Group are
+// resolved in the order of g.member, and those of a descriptive Group are
+// the results of the search its characteristics translate to.
+func GroupMembers(g *grouppb.Group, opts Options) ([]Member, error) {
+	if !g.GetActual().GetValue() {
+		resourceType, params, err := Query(g, opts.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Search == nil {
+			return nil, fmt.Errorf("descriptive Group %s: no search function", g.GetId().GetValue())
+		}
+		found, err := opts.Search(resourceType, params.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("descriptive Group %s: %w", g.GetId().GetValue(), err)
+		}
+		var members []Member
+		for _, m := range found {
+			m = elementpath.Unwrap(m)
+			members = append(members, Member{Reference: resourceKey(m), Resource: m})
+		}
+		return members, nil
+	}
+	var refs []*d4pb.Reference
+	for _, m := range g.GetMember() {
+		if m.GetInactive().GetValue() && !opts.IncludeInactive {
+			continue
+		}
+		if !opts.At.IsZero() {
+			in, err := fhirtypes.PeriodContains(m.GetPeriod(), opts.At)
+			if err != nil {
+				return nil, fmt.Errorf("member %s: period %w", fhirtypes.ReferenceURI(m.GetEntity()), err)
+			}
+			if !in {
+				continue
+			}
+		}
+		refs = append(refs, m.GetEntity())
+	}
+	return resolve(refs, opts.Resolver)
+}
+
+// ListMembers returns the resources of the entries of l, in their order.
+func ListMembers(l *listpb.List, opts Options) ([]Member, error) {
+	var refs []*d4pb.Reference
+	for _, e := range l.GetEntry() {
+		if e.GetDeleted().GetValue() && !opts.IncludeInactive {
+			continue
+		}
+		refs = append(refs, e.GetItem())
+	}
+	return resolve(refs, opts.Resolver)
+}
+
+func resolve(refs []*d4pb.Reference, resolver fhirpath.Resolver) ([]Member, error) {
+	if len(refs) > 0 && resolver == nil {
+		return nil, fmt.Errorf("no resolver")
+	}
+	var members []Member
+	for _, ref := range refs {
+		uri := fhirtypes.ReferenceURI(ref)
+		if uri == "" {
+			// Logical references cannot be resolved, but still identify a
+			// member, by the search that would find it.
+			if id := ref.GetIdentifier(); id != nil {
+				q := ref.GetType().GetValue() + "?identifier=" + token(id.GetSystem().GetValue(), id.GetValue().GetValue())
+				members = append(members, Member{Reference: q})
+			}
+			continue
+		}
+		res, err := resolver(uri)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", uri, err)
+		}
+		if res != nil {
+			res = elementpath.Unwrap(res)
+		}
+		members = append(members, Member{Reference: uri, Resource: res})
+	}
+	return members, nil
+}
+
+// Query returns the search that finds the members of the descriptive Group
+// g: the type of its members and the search parameters its characteristics
+// translate to, named as by parameters (see Options.Parameters).
+func Query(g *grouppb.Group, parameters map[string]string) (string, url.Values, error) {
+	resourceType, ok := groupTypes[g.GetType().GetValue()]
+	if !ok {
+		return "", nil, fmt.Errorf("Group %s has unsupported type %v", g.GetId().GetValue(), g.GetType().GetValue())
+	}
+	params := url.Values{}
+	for i, c := range g.GetCharacteristic() {
+		name, err := parameterName(c.GetCode(), parameters)
+		if err != nil {
+			return "", nil, fmt.Errorf("characteristic %d: %w", i, err)
+		}
+		exclude := c.GetExclude().GetValue()
+		switch v := c.GetValue().GetChoice().(type) {
+		case *grouppb.Group_Characteristic_ValueX_CodeableConcept:
+			var tokens []string
+			for _, cd := range v.CodeableConcept.GetCoding() {
+				tokens = append(tokens, token(cd.GetSystem().GetValue(), cd.GetCode().GetValue()))
+			}
+			if len(tokens) == 0 {
+				return "", nil, fmt.Errorf("characteristic %d: value has no codings", i)
+			}
+			if exclude {
+				// :not of several codes excludes all of them, so each
+				// becomes a parameter of its own.
+				for _, t := range tokens {
+					params.Add(name+":not", t)
+				}
+			} else {
+				params.Add(name, strings.Join(tokens, ","))
+			}
+		case *grouppb.Group_Characteristic_ValueX_Boolean:
+			if exclude {
+				params.Add(name+":not", fmt.Sprint(v.Boolean.GetValue()))
+			} else {
+				params.Add(name, fmt.Sprint(v.Boolean.GetValue()))
+			}
+		case *grouppb.Group_Characteristic_ValueX_Reference:
+			uri := fhirtypes.ReferenceURI(v.Reference)
+			if uri == "" {
+				return "", nil, fmt.Errorf("characteristic %d: value is not a literal reference", i)
+			}
+			if exclude {
+				params.Add(name+":not", uri)
+			} else {
+				params.Add(name, uri)
+			}
+		case *grouppb.Group_Characteristic_ValueX_Quantity:
+			if exclude {
+				return "", nil, fmt.Errorf("characteristic %d: excluded quantities are not supported", i)
+			}
+			params.Add(name, quantity(comparators[v.Quantity.GetComparator().GetValue()], v.Quantity))
+		case *grouppb.Group_Characteristic_ValueX_Range:
+			if exclude {
+				return "", nil, fmt.Errorf("characteristic %d: excluded ranges are not supported", i)
+			}
+			if low := v.Range.GetLow(); low != nil {
+				params.Add(name, quantity("ge", fhirtypes.QuantityOfSimple(low)))
+			}
+			if high := v.Range.GetHigh(); high != nil {
+				params.Add(name, quantity("le", fhirtypes.QuantityOfSimple(high)))
+			}
+		default:
+			return "", nil, fmt.Errorf("characteristic %d: unsupported value %T", i, v)
+		}
+	}
+	return resourceType, params, nil
+}
+
+// parameterName returns the name of the search parameter for the code of a
+// characteristic.
+func parameterName(code *d4pb.CodeableConcept, parameters map[string]string) (string, error) {
+	for _, cd := range code.GetCoding() {
+		system, c := cd.GetSystem().GetValue(), cd.GetCode().GetValue()
+		if name, ok := parameters[system+"|"+c]; ok {
+			return name, nil
+		}
+		if name, ok := parameters[c]; ok {
+			return name, nil
+		}
+	}
+	for _, cd := range code.GetCoding() {
+		if c := cd.GetCode().GetValue(); c != "" {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("code has no codings")
+}
+
+func token(system, code string) string {
+	if system == "" {
+		return code
+	}
+	return system + "|" + code
+}
+
+// comparators are the search prefixes of the comparators of Quantities.
+var comparators = map[c4pb.QuantityComparatorCode_Value]string{
+	c4pb.QuantityComparatorCode_LESS_THAN:                "lt",
+	c4pb.QuantityComparatorCode_LESS_THAN_OR_EQUAL_TO:    "le",
+	c4pb.QuantityComparatorCode_GREATER_THAN_OR_EQUAL_TO: "ge",
+	c4pb.QuantityComparatorCode_GREATER_THAN:             "gt",
+}
+
+// quantity returns the value of a quantity search parameter for q, with the
+// search prefix.
+func quantity(prefix string, q *d4pb.Quantity) string {
+	s := prefix + q.GetValue().GetValue()
+	if system, code := q.GetSystem().GetValue(), q.GetCode().GetValue(); system != "" || code != "" {
+		s += "|" + system + "|" + code
+	}
+	return s
+}
+
+func resourceKey(res proto.Message) string {
+	id := elementpath.ID(res)
+	if id == "" {
+		return ""
+	}
+	return elementpath.ResourceType(res) + "/" + id
+}
+
+// Delta is the change between two memberships of a Group or List.
+type Delta struct {
+	// Added are the members only in the later membership and Removed those
+	// only in the earlier one, each sorted by reference.
+	Added, Removed []Member
+}
+
+// Empty reports whether d records no change.
+func (d Delta) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Diff returns the change from the membership before to after. Members are
+// the same if their references are.
+func Diff(before, after []Member) Delta {
+	in := func(ms []Member) map[string]Member {
+		out := map[string]Member{}
+		for _, m := range ms {
+			out[m.Reference] = m
+		}
+		return out
+	}
+	b, a := in(before), in(after)
+	var d Delta
+	for ref, m := range a {
+		if _, ok := b[ref]; !ok {
+			d.Added = append(d.Added, m)
+		}
+	}
+	for ref, m := range b {
+		if _, ok := a[ref]; !ok {
+			d.Removed = append(d.Removed, m)
+		}
+	}
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].Reference < d.Added[j].Reference })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].Reference < d.Removed[j].Reference })
+	return d
+}
+
+// Tracker keeps the last membership of Groups and Lists, so that changes to
+// them can be found as they are resolved again.
+type Tracker struct {
+	last map[string][]Member
+}
+
+// NewTracker returns a Tracker without memberships.
+func NewTracker() *Tracker {
+	return &Tracker{last: map[string][]Member{}}
+}
+
+// Update records members as the membership of the Group or List key, such
+// as "Group/1", and returns the change from its previous membership. The
+// first membership of key is all added.
+func (t *Tracker) Update(key string, members []Member) Delta {
+	d := Diff(t.last[key], members)
+	t.last[key] = members
+	return d
+}
+
+// Members returns the last membership recorded for key.
+func (t *Tracker) Members(key string) []Member {
+	return t.last[key]
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package membership
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	grouppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/group_go_proto"
+	listpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/list_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func patientRef(id string) *d4pb.Reference {
+	return &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: id}}}
+}
+
+func patient(id string) *ppb.Patient {
+	return &ppb.Patient{Id: &d4pb.Id{Value: id}}
+}
+
+// resolver resolves references to the Patients p1 to p3.
+func resolver(ref string) (proto.Message, error) {
+	switch ref {
+	case "Patient/p1", "Patient/p2", "Patient/p3":
+		return patient(ref[len("Patient/"):]), nil
+	case "Patient/broken":
+		return nil, fmt.Errorf("broken")
+	}
+	return nil, nil
+}
+
+func dateTime(year int, month time.Month, day int) *d4pb.DateTime {
+	return &d4pb.DateTime{ValueUs: time.Date(year, month, day, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_DAY}
+}
+
+func refs(members []Member) []string {
+	var out []string
+	for _, m := range members {
+		s := m.Reference
+		if m.Resource == nil {
+			s += " (unresolved)"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestGroupMembers_Actual(t *testing.T) {
+	g := &grouppb.Group{
+		Id:     &d4pb.Id{Value: "g1"},
+		Type:   &grouppb.Group_TypeCode{Value: c4pb.GroupTypeCode_PERSON},
+		Actual: &d4pb.Boolean{Value: true},
+		Member: []*grouppb.Group_Member{
+			{Entity: patientRef("p1")},
+			{Entity: patientRef("p2"), Inactive: &d4pb.Boolean{Value: true}},
+			{Entity: patientRef("p3"), Period: &d4pb.Period{End: dateTime(2024, 12, 31)}},
+			{Entity: patientRef("gone")},
+			{Entity: &d4pb.Reference{Type: &d4pb.Uri{Value: "Patient"}, Identifier: &d4pb.Identifier{
+				System: &d4pb.Uri{Value: "urn:mrn"}, Value: &d4pb.String{Value: "123"},
+			}}},
+		},
+	}
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "all periods",
+			opts: Options{Resolver: resolver},
+			want: []string{"Patient/p1", "Patient/p3", "Patient/gone (unresolved)", "Patient?identifier=urn:mrn|123 (unresolved)"},
+		},
+		{
+			name: "at time",
+			opts: Options{Resolver: resolver, At: time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC)},
+			want: []string{"Patient/p1", "Patient/p3", "Patient/gone (unresolved)", "Patient?identifier=urn:mrn|123 (unresolved)"},
+		},
+		{
+			name: "after period",
+			opts: Options{Resolver: resolver, At: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			want: []string{"Patient/p1", "Patient/gone (unresolved)", "Patient?identifier=urn:mrn|123 (unresolved)"},
+		},
+		{
+			name: "inactive",
+			opts: Options{Resolver: resolver, IncludeInactive: true},
+			want: []string{"Patient/p1", "Patient/p2", "Patient/p3", "Patient/gone (unresolved)", "Patient?identifier=urn:mrn|123 (unresolved)"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := GroupMembers(g, tc.opts)
+			if err != nil {
+				t.Fatalf("GroupMembers() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, refs(got)); diff != "" {
+				t.Errorf("GroupMembers() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGroupMembers_Errors(t *testing.T) {
+	actual := &grouppb.Group{
+		Actual: &d4pb.Boolean{Value: true},
+		Member: []*grouppb.Group_Member{{Entity: patientRef("broken")}},
+	}
+	if _, err := GroupMembers(actual, Options{Resolver: resolver}); err == nil {
+		t.Errorf("GroupMembers() with failing resolver succeeded, want error")
+	}
+	if _, err := GroupMembers(actual, Options{}); err == nil {
+		t.Errorf("GroupMembers() without resolver succeeded, want error")
+	}
+	descriptive := &grouppb.Group{Type: &grouppb.Group_TypeCode{Value: c4pb.GroupTypeCode_PERSON}}
+	if _, err := GroupMembers(descriptive, Options{}); err == nil {
+		t.Errorf("GroupMembers() without search succeeded, want error")
+	}
+}
+
+func characteristic(code string, exclude bool, value *grouppb.Group_Characteristic_ValueX) *grouppb.Group_Characteristic {
+	return &grouppb.Group_Characteristic{
+		Code:    &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: "http://example.com/traits"}, Code: &d4pb.Code{Value: code}}}},
+		Value:   value,
+		Exclude: &d4pb.Boolean{Value: exclude},
+	}
+}
+
+func TestQuery(t *testing.T) {
+	g := &grouppb.Group{
+		Type: &grouppb.Group_TypeCode{Value: c4pb.GroupTypeCode_PERSON},
+		Characteristic: []*grouppb.Group_Characteristic{
+			characteristic("gender", false, &grouppb.Group_Characteristic_ValueX{Choice: &grouppb.Group_Characteristic_ValueX_CodeableConcept{
+				CodeableConcept: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+					System: &d4pb.Uri{Value: "http://hl7.org/fhir/administrative-gender"}, Code: &d4pb.Code{Value: "female"},
+				}}},
+			}}),
+			characteristic("deceased", true, &grouppb.Group_Characteristic_ValueX{Choice: &grouppb.Group_Characteristic_ValueX_Boolean{
+				Boolean: &d4pb.Boolean{Value: true},
+			}}),
+			characteristic("organization", false, &grouppb.Group_Characteristic_ValueX{Choice: &grouppb.Group_Characteristic_ValueX_Reference{
+				Reference: &d4pb.Reference{Reference: &d4pb.Reference_OrganizationId{OrganizationId: &d4pb.ReferenceId{Value: "o1"}}},
+			}}),
+			characteristic("age", false, &grouppb.Group_Characteristic_ValueX{Choice: &grouppb.Group_Characteristic_ValueX_Range{
+				Range: &d4pb.Range{
+					Low:  &d4pb.SimpleQuantity{Value: &d4pb.Decimal{Value: "18"}, System: &d4pb.Uri{Value: "http://unitsofmeasure.org"}, Code: &d4pb.Code{Value: "a"}},
+					High: &d4pb.SimpleQuantity{Value: &d4pb.Decimal{Value: "65"}},
+				},
+			}}),
+		},
+	}
+	typ, params, err := Query(g, map[string]string{"http://example.com/traits|organization": "general-practitioner"})
+	if err != nil {
+		t.Fatalf("Query() returned unexpected error: %v", err)
+	}
+	if typ != "Patient" {
+		t.Errorf("Query() type = %q, want %q", typ, "Patient")
+	}
+	want := url.Values{
+		"gender":               {"http://hl7.org/fhir/administrative-gender|female"},
+		"deceased:not":         {"true"},
+		"general-practitioner": {"Organization/o1"},
+		"age":                  {"ge18|http://unitsofmeasure.org|a", "le65"},
+	}
+	if diff := cmp.Diff(want, params); diff != "" {
+		t.Errorf("Query() diff (-want +got):\n%s", diff)
+	}
+
+	g.Characteristic[3].Exclude = &d4pb.Boolean{Value: true}
+	if _, _, err := Query(g, nil); err == nil {
+		t.Errorf("Query() with excluded range succeeded, want error")
+	}
+}
+
+func TestGroupMembers_Descriptive(t *testing.T) {
+	g := &grouppb.Group{
+		Type: &grouppb.Group_TypeCode{Value: c4pb.GroupTypeCode_PERSON},
+		Characteristic: []*grouppb.Group_Characteristic{
+			characteristic("active", false, &grouppb.Group_Characteristic_ValueX{Choice: &grouppb.Group_Characteristic_ValueX_Boolean{
+				Boolean: &d4pb.Boolean{Value: true},
+			}}),
+		},
+	}
+	var gotType, gotParams string
+	search := func(resourceType, params string) ([]proto.Message, error) {
+		gotType, gotParams = resourceType, params
+		return []proto.Message{patient("p1"), patient("p2")}, nil
+	}
+	got, err := GroupMembers(g, Options{Search: search})
+	if err != nil {
+		t.Fatalf("GroupMembers() returned unexpected error: %v", err)
+	}
+	if gotType != "Patient" || gotParams != "active=true" {
+		t.Errorf("GroupMembers() searched %s?%s, want Patient?active=true", gotType, gotParams)
+	}
+	if diff := cmp.Diff([]string{"Patient/p1", "Patient/p2"}, refs(got)); diff != "" {
+		t.Errorf("GroupMembers() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestListMembers(t *testing.T) {
+	l := &listpb.List{Entry: []*listpb.List_Entry{
+		{Item: patientRef("p1")},
+		{Item: patientRef("p2"), Deleted: &d4pb.Boolean{Value: true}},
+		{Item: patientRef("p3")},
+	}}
+	got, err := ListMembers(l, Options{Resolver: resolver})
+	if err != nil {
+		t.Fatalf("ListMembers() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Patient/p1", "Patient/p3"}, refs(got)); diff != "" {
+		t.Errorf("ListMembers() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	members := func(ids ...string) []Member {
+		var out []Member
+		for _, id := range ids {
+			out = append(out, Member{Reference: "Patient/" + id, Resource: patient(id)})
+		}
+		return out
+	}
+	tr := NewTracker()
+	steps := []struct {
+		members        []Member
+		added, removed []string
+	}{
+		{members("p2", "p1"), []string{"Patient/p1", "Patient/p2"}, nil},
+		{members("p1", "p2"), nil, nil},
+		{members("p1", "p3"), []string{"Patient/p3"}, []string{"Patient/p2"}},
+		{nil, nil, []string{"Patient/p1", "Patient/p3"}},
+	}
+	for i, s := range steps {
+		d := tr.Update("Group/g1", s.members)
+		if diff := cmp.Diff(s.added, refs(d.Added)); diff != "" {
+			t.Errorf("step %d: Update() added diff (-want +got):\n%s", i, diff)
+		}
+		if diff := cmp.Diff(s.removed, refs(d.Removed)); diff != "" {
+			t.Errorf("step %d: Update() removed diff (-want +got):\n%s", i, diff)
+		}
+		if got, want := d.Empty(), len(s.added)+len(s.removed) == 0; got != want {
+			t.Errorf("step %d: Empty() = %v, want %v", i, got, want)
+		}
+	}
+}
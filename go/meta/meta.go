@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package meta reads and edits the meta of R4 resources: their profiles,
+// tags, security labels and versions. Additions skip values that are already present
+// and removals keep the order of the others, so that the stages of a
+// pipeline can apply them repeatedly without duplicating entries.
+//
+// Resources are passed as protos, either as the resource itself or wrapped
+// in a ContainedResource:
+//
+//	if err := meta.AddProfile(obs, "http://hl7.org/fhir/us/core/StructureDefinition/us-core-vital-signs"); err != nil {
+//		...
+//	}
+package meta
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// Of returns the meta of res, or nil if it has none.
+func Of(res proto.Message) *d4pb.Meta {
+	m := elementpath.Unwrap(res)
+	if m == nil {
+		return nil
+	}
+	rm := m.ProtoReflect()
+	fd := rm.Descriptor().Fields().ByName("meta")
+	if fd == nil || !rm.Has(fd) {
+		return nil
+	}
+	meta, _ := rm.Get(fd).Message().Interface().(*d4pb.Meta)
+	return meta
+}
+
+// mutable returns the meta of res, creating it if it has none.
+func mutable(res proto.Message) (*d4pb.Meta, error) {
+	m := elementpath.Unwrap(res)
+	if m == nil {
+		return nil, fmt.Errorf("empty ContainedResource")
+	}
+	rm := m.ProtoReflect()
+	fd := rm.Descriptor().Fields().ByName("meta")
+	if fd == nil || fd.Message() == nil {
+		return nil, fmt.Errorf("%s has no meta", rm.Descriptor().FullName())
+	}
+	meta, ok := rm.Mutable(fd).Message().Interface().(*d4pb.Meta)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an R4 resource", rm.Descriptor().FullName())
+	}
+	return meta, nil
+}
+
+// SetVersion sets the version id and last updated time of res, as stores do
+// on every write.
+func SetVersion(res proto.Message, versionID string, lastUpdated time.Time) error {
+	meta, err := mutable(res)
+	if err != nil {
+		return err
+	}
+	meta.VersionId = &d4pb.Id{Value: versionID}
+	meta.LastUpdated = &d4pb.Instant{
+		ValueUs:   lastUpdated.UnixMicro(),
+		Precision: d4pb.Instant_MICROSECOND,
+		Timezone:  "UTC",
+	}
+	return nil
+}
+
+// profileMatches reports whether the canonical p is url. A url without a
+// version matches every version of the profile, as "url|version".
+func profileMatches(p, url string) bool {
+	if p == url {
+		return true
+	}
+	return !strings.Contains(url, "|") && strings.HasPrefix(p, url+"|")
+}
+
+// HasProfile reports whether res claims conformance to the profile with the
+// canonical url, which matches every version of the profile if it has none.
+func HasProfile(res proto.Message, url string) bool {
+	for _, p := range Of(res).GetProfile() {
+		if profileMatches(p.GetValue(), url) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddProfile adds the profile with the canonical url to res, unless
+// HasProfile reports it is there already.
+func AddProfile(res proto.Message, url string) error {
+	meta, err := mutable(res)
+	if err != nil {
+		return err
+	}
+	if !HasProfile(res, url) {
+		meta.Profile = append(meta.Profile, &d4pb.Canonical{Value: url})
+	}
+	return nil
+}
+
+// RemoveProfile removes the profiles of res matching url, as for
+// HasProfile.
+func RemoveProfile(res proto.Message, url string) error {
+	meta := Of(res)
+	if meta == nil {
+		return nil
+	}
+	ps := meta.Profile[:0]
+	for _, p := range meta.Profile {
+		if !profileMatches(p.GetValue(), url) {
+			ps = append(ps, p)
+		}
+	}
+	meta.Profile = ps
+	return nil
+}
+
+// HasTag reports whether res has the tag code in system.
+func HasTag(res proto.Message, system, code string) bool {
+	return indexOf(Of(res).GetTag(), system, code) >= 0
+}
+
+// AddTag adds tag to res, unless it has a tag of the same system and code.
+func AddTag(res proto.Message, tag *d4pb.Coding) error {
+	meta, err := mutable(res)
+	if err != nil {
+		return err
+	}
+	meta.Tag = add(meta.Tag, tag)
+	return nil
+}
+
+// RemoveTag removes the tag code in system from res.
+func RemoveTag(res proto.Message, system, code string) error {
+	if meta := Of(res); meta != nil {
+		meta.Tag = remove(meta.Tag, system, code)
+	}
+	return nil
+}
+
+// HasSecurityLabel reports whether res has the security label code in
+// system.
+func HasSecurityLabel(res proto.Message, system, code string) bool {
+	return indexOf(Of(res).GetSecurity(), system, code) >= 0
+}
+
+// AddSecurityLabel adds label to res, unless it has a security label of the
+// same system and code.
+func AddSecurityLabel(res proto.Message, label *d4pb.Coding) error {
+	meta, err := mutable(res)
+	if err != nil {
+		return err
+	}
+	meta.Security = add(meta.Security, label)
+	return nil
+}
+
+// RemoveSecurityLabel removes the security label code in system from res.
+func RemoveSecurityLabel(res proto.Message, system, code string) error {
+	if meta := Of(res); meta != nil {
+		meta.Security = remove(meta.Security, system, code)
+	}
+	return nil
+}
+
+func indexOf(cs []*d4pb.Coding, system, code string) int {
+	for i, c := range cs {
+		if c.GetSystem().GetValue() == system && c.GetCode().GetValue() == code {
+			return i
+		}
+	}
+	return -1
+}
+
+func add(cs []*d4pb.Coding, c *d4pb.Coding) []*d4pb.Coding {
+	if indexOf(cs, c.GetSystem().GetValue(), c.GetCode().GetValue()) >= 0 {
+		return cs
+	}
+	return append(cs, c)
+}
+
+func remove(cs []*d4pb.Coding, system, code string) []*d4pb.Coding {
+	out := cs[:0]
+	for _, c := range cs {
+		if c.GetSystem().GetValue() != system || c.GetCode().GetValue() != code {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package meta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	bcrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func coding(system, code string) *d4pb.Coding {
+	return &d4pb.Coding{System: &d4pb.Uri{Value: system}, Code: &d4pb.Code{Value: code}}
+}
+
+func profiles(res *ppb.Patient) []string {
+	var out []string
+	for _, p := range res.GetMeta().GetProfile() {
+		out = append(out, p.GetValue())
+	}
+	return out
+}
+
+func TestProfile(t *testing.T) {
+	p := &ppb.Patient{}
+	cr := &bcrpb.ContainedResource{OneofResource: &bcrpb.ContainedResource_Patient{Patient: p}}
+	for _, url := range []string{"http://a", "http://b|1.0", "http://a", "http://b"} {
+		if err := AddProfile(cr, url); err != nil {
+			t.Fatalf("AddProfile(%q) returned unexpected error: %v", url, err)
+		}
+	}
+	if diff := cmp.Diff([]string{"http://a", "http://b|1.0"}, profiles(p)); diff != "" {
+		t.Errorf("AddProfile() diff (-want +got):\n%s", diff)
+	}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://a", true},
+		{"http://b", true},
+		{"http://b|1.0", true},
+		{"http://b|2.0", false},
+		{"http://a|1.0", false},
+		{"http://c", false},
+	}
+	for _, tc := range tests {
+		if got := HasProfile(p, tc.url); got != tc.want {
+			t.Errorf("HasProfile(%q) = %v, want %v", tc.url, got, tc.want)
+		}
+	}
+	if err := AddProfile(p, "http://c"); err != nil {
+		t.Fatalf("AddProfile() returned unexpected error: %v", err)
+	}
+	if err := RemoveProfile(p, "http://b"); err != nil {
+		t.Fatalf("RemoveProfile() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"http://a", "http://c"}, profiles(p)); diff != "" {
+		t.Errorf("RemoveProfile() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCodings(t *testing.T) {
+	tests := []struct {
+		name   string
+		add    func(*ppb.Patient, *d4pb.Coding) error
+		has    func(*ppb.Patient, string, string) bool
+		remove func(*ppb.Patient, string, string) error
+		get    func(*d4pb.Meta) []*d4pb.Coding
+	}{
+		{
+			"tags",
+			func(p *ppb.Patient, c *d4pb.Coding) error { return AddTag(p, c) },
+			func(p *ppb.Patient, s, c string) bool { return HasTag(p, s, c) },
+			func(p *ppb.Patient, s, c string) error { return RemoveTag(p, s, c) },
+			(*d4pb.Meta).GetTag,
+		},
+		{
+			"security labels",
+			func(p *ppb.Patient, c *d4pb.Coding) error { return AddSecurityLabel(p, c) },
+			func(p *ppb.Patient, s, c string) bool { return HasSecurityLabel(p, s, c) },
+			func(p *ppb.Patient, s, c string) error { return RemoveSecurityLabel(p, s, c) },
+			(*d4pb.Meta).GetSecurity,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ppb.Patient{}
+			if tc.has(p, "s", "a") {
+				t.Errorf("Has(s, a) = true on an empty resource")
+			}
+			for _, c := range []*d4pb.Coding{coding("s", "a"), coding("s", "b"), coding("t", "a"), coding("s", "a"), coding("s", "c")} {
+				if err := tc.add(p, c); err != nil {
+					t.Fatalf("Add() returned unexpected error: %v", err)
+				}
+			}
+			want := []*d4pb.Coding{coding("s", "a"), coding("s", "b"), coding("t", "a"), coding("s", "c")}
+			if diff := cmp.Diff(want, tc.get(p.GetMeta()), protocmp.Transform()); diff != "" {
+				t.Errorf("Add() diff (-want +got):\n%s", diff)
+			}
+			if !tc.has(p, "t", "a") || tc.has(p, "t", "b") {
+				t.Errorf("Has() did not match the added codings")
+			}
+			if err := tc.remove(p, "s", "b"); err != nil {
+				t.Fatalf("Remove() returned unexpected error: %v", err)
+			}
+			want = []*d4pb.Coding{coding("s", "a"), coding("t", "a"), coding("s", "c")}
+			if diff := cmp.Diff(want, tc.get(p.GetMeta()), protocmp.Transform()); diff != "" {
+				t.Errorf("Remove() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	p := &ppb.Patient{Meta: &d4pb.Meta{Profile: []*d4pb.Canonical{{Value: "http://a"}}}}
+	cr := &bcrpb.ContainedResource{OneofResource: &bcrpb.ContainedResource_Patient{Patient: p}}
+	if err := SetVersion(cr, "2", time.Unix(1, 0)); err != nil {
+		t.Fatalf("SetVersion() returned unexpected error: %v", err)
+	}
+	want := &d4pb.Meta{
+		VersionId:   &d4pb.Id{Value: "2"},
+		LastUpdated: &d4pb.Instant{ValueUs: 1000000, Precision: d4pb.Instant_MICROSECOND, Timezone: "UTC"},
+		Profile:     []*d4pb.Canonical{{Value: "http://a"}},
+	}
+	if diff := cmp.Diff(want, p.GetMeta(), protocmp.Transform()); diff != "" {
+		t.Errorf("SetVersion() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if err := AddProfile(&bcrpb.ContainedResource{}, "http://a"); err == nil {
+		t.Errorf("AddProfile(empty ContainedResource) succeeded, want error")
+	}
+	if err := AddTag(&d4pb.Coding{}, coding("s", "a")); err == nil {
+		t.Errorf("AddTag(Coding) succeeded, want error")
+	}
+	if err := RemoveTag(&ppb.Patient{}, "s", "a"); err != nil {
+		t.Errorf("RemoveTag() returned unexpected error: %v", err)
+	}
+	if Of(&ppb.Patient{}) != nil {
+		t.Errorf("Of() of a resource without meta is not nil")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namingsystem
+
+import (
+	"regexp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// identifierTypes is the code system of identifier types.
+const identifierTypes = "http://terminology.hl7.org/CodeSystem/v2-0203"
+
+func identifierType(code, display string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+		System:  &d4pb.Uri{Value: identifierTypes},
+		Code:    &d4pb.Code{Value: code},
+		Display: &d4pb.String{Value: display},
+	}}}
+}
+
+// Builtin returns the curated systems of Default: national person and
+// provider identifiers in common use, and the code systems most often
+// mistaken for identifier systems.
+func Builtin() []System {
+	identifier := func(url, oid, name string, typ *d4pb.CodeableConcept, pattern string) System {
+		s := System{URL: url, Name: name, Kind: c4pb.NamingSystemTypeCode_IDENTIFIER, Type: typ, Unique: true}
+		if oid != "" {
+			s.Aliases = []string{"urn:oid:" + oid}
+		}
+		if pattern != "" {
+			s.Pattern = regexp.MustCompile(pattern)
+		}
+		return s
+	}
+	codes := func(url, oid, name string) System {
+		return System{URL: url, Aliases: []string{"urn:oid:" + oid}, Name: name, Kind: c4pb.NamingSystemTypeCode_CODESYSTEM}
+	}
+	return []System{
+		identifier("http://hl7.org/fhir/sid/us-ssn", "2.16.840.1.113883.4.1", "US Social Security Number",
+			identifierType("SS", "Social Security number"), `^\d{3}-?\d{2}-?\d{4}$`),
+		identifier("http://hl7.org/fhir/sid/us-npi", "2.16.840.1.113883.4.6", "US National Provider Identifier",
+			identifierType("NPI", "National provider identifier"), `^\d{10}$`),
+		identifier("http://hl7.org/fhir/sid/us-mbi", "2.16.840.1.113883.4.927", "US Medicare Beneficiary Identifier",
+			identifierType("MC", "Patient's Medicare number"), `^[1-9][AC-HJKMNP-RT-Y][AC-HJKMNP-RT-Y0-9]\d[AC-HJKMNP-RT-Y][AC-HJKMNP-RT-Y0-9]\d[AC-HJKMNP-RT-Y]{2}\d{2}$`),
+		identifier("https://fhir.nhs.uk/Id/nhs-number", "2.16.840.1.113883.2.1.4.1", "NHS Number",
+			identifierType("NH", "National Health Plan Identifier"), `^\d{10}$`),
+		identifier("http://ns.electronichealth.net.au/id/hi/ihi/1.0", "1.2.36.1.2001.1003.0", "Australian Individual Healthcare Identifier",
+			identifierType("NI", "National unique individual identifier"), `^\d{16}$`),
+		identifier("urn:ietf:rfc:3986", "", "URI", nil, `^\S+:\S+$`),
+		codes("http://loinc.org", "2.16.840.1.113883.6.1", "LOINC"),
+		codes("http://snomed.info/sct", "2.16.840.1.113883.6.96", "SNOMED CT"),
+		codes("http://www.nlm.nih.gov/research/umls/rxnorm", "2.16.840.1.113883.6.88", "RxNorm"),
+		codes("http://hl7.org/fhir/sid/icd-10-cm", "2.16.840.1.113883.6.90", "ICD-10-CM"),
+		codes("http://hl7.org/fhir/sid/icd-10", "2.16.840.1.113883.6.3", "ICD-10"),
+		codes("http://hl7.org/fhir/sid/icd-9-cm", "2.16.840.1.113883.6.103", "ICD-9-CM"),
+		codes("http://hl7.org/fhir/sid/cvx", "2.16.840.1.113883.12.292", "CVX"),
+		codes("http://hl7.org/fhir/sid/ndc", "2.16.840.1.113883.6.69", "NDC"),
+		codes("http://www.ama-assn.org/go/cpt", "2.16.840.1.113883.6.12", "CPT"),
+		codes("http://unitsofmeasure.org", "2.16.840.1.113883.6.8", "UCUM"),
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package namingsystem is a registry of the systems of identifiers and
+// codes, built from R4 NamingSystem resources and a curated set of
+// well-known systems.
+//
+// The registry knows each system by its preferred URI and by its other
+// unique ids, such as its OID, and records what the identifiers of the
+// system are: their human name, their identifier type, whether a value
+// identifies a single entity, and the pattern of valid values. It serves
+// formatting identifiers for display, matching records by identifiers, and
+// warning about identifiers in unknown systems:
+//
+//	r := namingsystem.Default()
+//	fmt.Println(r.FormatIdentifier(id)) // US Social Security Number: 123-45-6789
+//	v, err := revalidate.NewValidator(r.Constraint())
+package namingsystem
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	nspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/naming_system_go_proto"
+)
+
+// ConstraintKey is the key of the constraint returned by
+// Registry.Constraint.
+const ConstraintKey = "identifier-system"
+
+// System is an identifier or code system.
+type System struct {
+	// URL is the preferred URI of the system, as used in Identifier.system
+	// and Coding.system.
+	URL string
+	// Aliases are the other URIs of the system, such as its OID as a
+	// urn:oid: URI.
+	Aliases []string
+	// Name is the human name of the system, i.e. "US Social Security
+	// Number".
+	Name string
+	// Kind tells whether the system is of identifiers or codes.
+	Kind c4pb.NamingSystemTypeCode_Value
+	// Type is the identifier type of the identifiers of the system, from
+	// http://terminology.hl7.org/CodeSystem/v2-0203, or nil if unknown.
+	Type *d4pb.CodeableConcept
+	// Unique is set for identifier systems whose values each identify a
+	// single entity, so that records with the same identifier are of the
+	// same entity.
+	Unique bool
+	// Pattern matches the valid values of the system, or is nil if any
+	// value is valid.
+	Pattern *regexp.Regexp
+}
+
+// Registry is a set of systems, safe for concurrent use.
+type Registry struct {
+	mu sync.RWMutex
+	// systems are the systems by their normalized URLs and aliases.
+	systems map[string]*System
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{systems: map[string]*System{}}
+}
+
+// Default returns a new Registry of the Builtin systems.
+func Default() *Registry {
+	r := NewRegistry()
+	for _, s := range Builtin() {
+		if err := r.Add(s); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Add adds s to r, replacing the system with the same URL. It fails if the
+// URL or an alias of s belongs to another system.
+func (r *Registry) Add(s System) error {
+	if s.URL == "" {
+		return fmt.Errorf("system %q has no URL", s.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url := fhirtypes.NormalizeSystem(s.URL)
+	keys := []string{url}
+	for _, a := range s.Aliases {
+		keys = append(keys, fhirtypes.NormalizeSystem(a))
+	}
+	for _, k := range keys {
+		if old, ok := r.systems[k]; ok && fhirtypes.NormalizeSystem(old.URL) != url {
+			return fmt.Errorf("system %s: %q already belongs to %s", s.URL, k, old.URL)
+		}
+	}
+	if old, ok := r.systems[url]; ok {
+		for k, v := range r.systems {
+			if v == old {
+				delete(r.systems, k)
+			}
+		}
+	}
+	for _, k := range keys {
+		r.systems[k] = &s
+	}
+	return nil
+}
+
+// AddNamingSystem adds the system ns defines to r, as by Add. Its preferred
+// URI unique id, or else its first URI, OID or UUID one, becomes the URL
+// of the system and the others its aliases; unique ids of type "other" are
+// ignored. The values of identifier systems are taken to be unique.
+func (r *Registry) AddNamingSystem(ns *nspb.NamingSystem) error {
+	s := System{
+		Name:   ns.GetName().GetValue(),
+		Kind:   ns.GetKind().GetValue(),
+		Type:   ns.GetType(),
+		Unique: ns.GetKind().GetValue() == c4pb.NamingSystemTypeCode_IDENTIFIER,
+	}
+	var uris []string
+	preferred := -1
+	for _, u := range ns.GetUniqueId() {
+		v := u.GetValue().GetValue()
+		if v == "" {
+			continue
+		}
+		switch u.GetType().GetValue() {
+		case c4pb.NamingSystemIdentifierTypeCode_URI:
+			if preferred < 0 && u.GetPreferred().GetValue() {
+				preferred = len(uris)
+			}
+		case c4pb.NamingSystemIdentifierTypeCode_OID:
+			v = "urn:oid:" + strings.TrimPrefix(v, "urn:oid:")
+		case c4pb.NamingSystemIdentifierTypeCode_UUID:
+			v = "urn:uuid:" + strings.TrimPrefix(v, "urn:uuid:")
+		default:
+			continue
+		}
+		uris = append(uris, v)
+	}
+	if len(uris) == 0 {
+		return fmt.Errorf("NamingSystem %s has no URI, OID or UUID unique id", s.Name)
+	}
+	if preferred < 0 {
+		preferred = 0
+		for i, u := range uris {
+			if !strings.HasPrefix(u, "urn:oid:") && !strings.HasPrefix(u, "urn:uuid:") {
+				preferred = i
+				break
+			}
+		}
+	}
+	s.URL = uris[preferred]
+	s.Aliases = append(append([]string(nil), uris[:preferred]...), uris[preferred+1:]...)
+	return r.Add(s)
+}
+
+// Lookup returns the system with the URL or alias system, compared as
+// normalized by fhirtypes.NormalizeSystem.
+func (r *Registry) Lookup(system string) (*System, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.systems[fhirtypes.NormalizeSystem(system)]
+	return s, ok
+}
+
+// Systems returns the systems of r, sorted by URL.
+func (r *Registry) Systems() []*System {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seen := map[*System]bool{}
+	var out []*System
+	for _, s := range r.systems {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].URL < out[j].URL })
+	return out
+}
+
+// Canonical returns the preferred URL of system, or system normalized by
+// fhirtypes.NormalizeSystem if r does not know it.
+func (r *Registry) Canonical(system string) string {
+	if s, ok := r.Lookup(system); ok {
+		return s.URL
+	}
+	return fhirtypes.NormalizeSystem(system)
+}
+
+// FormatIdentifier returns id for display, named for its system, i.e. "US
+// Social Security Number: 123-45-6789". Identifiers of unknown systems are
+// named by the text of their type, or else their system.
+func (r *Registry) FormatIdentifier(id *d4pb.Identifier) string {
+	value := strings.TrimSpace(id.GetValue().GetValue())
+	name := id.GetType().GetText().GetValue()
+	if s, ok := r.Lookup(id.GetSystem().GetValue()); ok && s.Name != "" {
+		name = s.Name
+	} else if name == "" {
+		name = id.GetSystem().GetValue()
+	}
+	if name == "" {
+		return value
+	}
+	return name + ": " + value
+}
+
+// SameIdentifier reports whether a and b are the same identifier, as by
+// fhirtypes.SameIdentifier, with the aliases of the systems of r standing
+// for their URLs.
+func (r *Registry) SameIdentifier(a, b *d4pb.Identifier) bool {
+	sa, sb := a.GetSystem().GetValue(), b.GetSystem().GetValue()
+	va, vb := strings.TrimSpace(a.GetValue().GetValue()), strings.TrimSpace(b.GetValue().GetValue())
+	return sa != "" && va != "" && r.Canonical(sa) == r.Canonical(sb) && va == vb
+}
+
+// Identifying reports whether id identifies a single entity, so that records
+// with it can be matched: its system is a unique identifier system of r and
+// its value is valid.
+func (r *Registry) Identifying(id *d4pb.Identifier) bool {
+	s, ok := r.Lookup(id.GetSystem().GetValue())
+	if !ok || s.Kind != c4pb.NamingSystemTypeCode_IDENTIFIER || !s.Unique {
+		return false
+	}
+	v := strings.TrimSpace(id.GetValue().GetValue())
+	return v != "" && (s.Pattern == nil || s.Pattern.MatchString(v))
+}
+
+// Check returns warnings about the identifiers of res, which may be wrapped
+// in a ContainedResource: identifiers whose system r does not know or is a
+// code system, and values that are not valid in their system. Identifiers
+// without a system are not checked.
+func (r *Registry) Check(res proto.Message) []revalidate.Issue {
+	m := elementpath.Unwrap(res)
+	if m == nil {
+		return nil
+	}
+	var issues []revalidate.Issue
+	r.walk(m.ProtoReflect(), elementpath.ResourceType(m), &issues)
+	return issues
+}
+
+// Constraint returns a revalidate Constraint, with key ConstraintKey, whose
+// issues are those of Check.
+func (r *Registry) Constraint() revalidate.Constraint {
+	return revalidate.Constraint{
+		Key: ConstraintKey,
+		Check: func(res proto.Message) ([]revalidate.Issue, error) {
+			return r.Check(res), nil
+		},
+	}
+}
+
+var identifierName = (&d4pb.Identifier{}).ProtoReflect().Descriptor().FullName()
+
+func (r *Registry) walk(m protoreflect.Message, path string, issues *[]revalidate.Issue) {
+	if m.Descriptor().FullName() == identifierName {
+		if issue, ok := r.checkIdentifier(m.Interface().(*d4pb.Identifier)); ok {
+			issue.Path = path
+			*issues = append(*issues, issue)
+		}
+	}
+	choice := elementpath.IsChoice(m.Descriptor())
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil || fd.IsMap() || elementpath.IsPrimitive(fd.Message()) {
+			return true
+		}
+		p := path + "." + fd.JSONName()
+		if choice {
+			p = path + strings.Title(fd.JSONName())
+		}
+		if !fd.IsList() {
+			r.walk(v.Message(), p, issues)
+			return true
+		}
+		l := v.List()
+		for i := 0; i < l.Len(); i++ {
+			r.walk(l.Get(i).Message(), fmt.Sprintf("%s[%d]", p, i), issues)
+		}
+		return true
+	})
+}
+
+func (r *Registry) checkIdentifier(id *d4pb.Identifier) (revalidate.Issue, bool) {
+	system := id.GetSystem().GetValue()
+	if system == "" {
+		return revalidate.Issue{}, false
+	}
+	warn := func(format string, args ...interface{}) (revalidate.Issue, bool) {
+		return revalidate.Issue{Severity: errorreporter.IssueSeverityWarning, Message: fmt.Sprintf(format, args...)}, true
+	}
+	s, ok := r.Lookup(system)
+	switch {
+	case !ok:
+		return warn("unknown identifier system %q", system)
+	case s.Kind == c4pb.NamingSystemTypeCode_CODESYSTEM:
+		return warn("%q is a code system, not an identifier system", system)
+	}
+	if v := strings.TrimSpace(id.GetValue().GetValue()); v != "" && s.Pattern != nil && !s.Pattern.MatchString(v) {
+		return warn("%q is not a valid %s", v, s.Name)
+	}
+	return revalidate.Issue{}, false
+}
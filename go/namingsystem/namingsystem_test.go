@@ -0,0 +1,194 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namingsystem
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+	"github.com/google/go-cmp/cmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	nspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/naming_system_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func identifier(system, value string) *d4pb.Identifier {
+	return &d4pb.Identifier{System: &d4pb.Uri{Value: system}, Value: &d4pb.String{Value: value}}
+}
+
+func uniqueID(typ c4pb.NamingSystemIdentifierTypeCode_Value, value string, preferred bool) *nspb.NamingSystem_UniqueId {
+	return &nspb.NamingSystem_UniqueId{
+		Type:      &nspb.NamingSystem_UniqueId_TypeCode{Value: typ},
+		Value:     &d4pb.String{Value: value},
+		Preferred: &d4pb.Boolean{Value: preferred},
+	}
+}
+
+// hospitalMRN is a NamingSystem of the medical record numbers of a
+// hospital.
+var hospitalMRN = &nspb.NamingSystem{
+	Name: &d4pb.String{Value: "General Hospital MRN"},
+	Kind: &nspb.NamingSystem_KindCode{Value: c4pb.NamingSystemTypeCode_IDENTIFIER},
+	UniqueId: []*nspb.NamingSystem_UniqueId{
+		uniqueID(c4pb.NamingSystemIdentifierTypeCode_OID, "1.2.3.4.5", false),
+		uniqueID(c4pb.NamingSystemIdentifierTypeCode_URI, "http://general.example.com/mrn", true),
+		uniqueID(c4pb.NamingSystemIdentifierTypeCode_OTHER, "GH-MRN", false),
+	},
+}
+
+func TestAddNamingSystem(t *testing.T) {
+	r := NewRegistry()
+	if err := r.AddNamingSystem(hospitalMRN); err != nil {
+		t.Fatalf("AddNamingSystem() returned unexpected error: %v", err)
+	}
+	for _, system := range []string{"http://general.example.com/mrn", "HTTP://General.example.com/mrn/", "urn:oid:1.2.3.4.5", "1.2.3.4.5"} {
+		s, ok := r.Lookup(system)
+		if !ok {
+			t.Errorf("Lookup(%q) found nothing", system)
+			continue
+		}
+		if s.URL != "http://general.example.com/mrn" || s.Name != "General Hospital MRN" || !s.Unique {
+			t.Errorf("Lookup(%q) = %+v, want the hospital MRN system", system, s)
+		}
+	}
+	if _, ok := r.Lookup("GH-MRN"); ok {
+		t.Errorf("Lookup(%q) found a system for a unique id of type other", "GH-MRN")
+	}
+	if diff := cmp.Diff([]string{"urn:oid:1.2.3.4.5"}, r.Systems()[0].Aliases); diff != "" {
+		t.Errorf("Systems() aliases diff (-want +got):\n%s", diff)
+	}
+
+	// Adding the system again replaces it.
+	renamed := &nspb.NamingSystem{
+		Name:     &d4pb.String{Value: "GH MRN"},
+		Kind:     hospitalMRN.Kind,
+		UniqueId: []*nspb.NamingSystem_UniqueId{uniqueID(c4pb.NamingSystemIdentifierTypeCode_URI, "http://general.example.com/mrn", false)},
+	}
+	if err := r.AddNamingSystem(renamed); err != nil {
+		t.Fatalf("AddNamingSystem() returned unexpected error: %v", err)
+	}
+	if s, _ := r.Lookup("http://general.example.com/mrn"); s.Name != "GH MRN" {
+		t.Errorf("Lookup() after replacing = %q, want %q", s.Name, "GH MRN")
+	}
+	if _, ok := r.Lookup("urn:oid:1.2.3.4.5"); ok {
+		t.Errorf("Lookup() found an alias of the replaced system")
+	}
+}
+
+func TestAdd_Errors(t *testing.T) {
+	r := Default()
+	tests := []struct {
+		name string
+		s    System
+	}{
+		{"no URL", System{Name: "nameless"}},
+		{"taken alias", System{URL: "http://example.com/ssn", Aliases: []string{"urn:oid:2.16.840.1.113883.4.1"}}},
+	}
+	for _, tc := range tests {
+		if err := r.Add(tc.s); err == nil {
+			t.Errorf("Add(%s) succeeded, want error", tc.name)
+		}
+	}
+	if err := r.AddNamingSystem(&nspb.NamingSystem{Name: &d4pb.String{Value: "none"}}); err == nil {
+		t.Errorf("AddNamingSystem() without unique ids succeeded, want error")
+	}
+}
+
+func TestFormatIdentifier(t *testing.T) {
+	r := Default()
+	tests := []struct {
+		id   *d4pb.Identifier
+		want string
+	}{
+		{identifier("http://hl7.org/fhir/sid/us-ssn", "123-45-6789"), "US Social Security Number: 123-45-6789"},
+		{identifier("urn:oid:2.16.840.1.113883.4.6", "1234567893"), "US National Provider Identifier: 1234567893"},
+		{&d4pb.Identifier{
+			System: &d4pb.Uri{Value: "http://example.com/mrn"},
+			Type:   &d4pb.CodeableConcept{Text: &d4pb.String{Value: "MRN"}},
+			Value:  &d4pb.String{Value: "42"},
+		}, "MRN: 42"},
+		{identifier("http://example.com/mrn", "42"), "http://example.com/mrn: 42"},
+		{&d4pb.Identifier{Value: &d4pb.String{Value: "42"}}, "42"},
+	}
+	for _, tc := range tests {
+		if got := r.FormatIdentifier(tc.id); got != tc.want {
+			t.Errorf("FormatIdentifier(%v) = %q, want %q", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestMatching(t *testing.T) {
+	r := Default()
+	if err := r.AddNamingSystem(hospitalMRN); err != nil {
+		t.Fatalf("AddNamingSystem() returned unexpected error: %v", err)
+	}
+	if !r.SameIdentifier(identifier("urn:oid:1.2.3.4.5", "42"), identifier("http://general.example.com/mrn", " 42")) {
+		t.Errorf("SameIdentifier() = false for an identifier under an alias of its system, want true")
+	}
+	if r.SameIdentifier(identifier("http://general.example.com/mrn", "42"), identifier("http://general.example.com/mrn", "43")) {
+		t.Errorf("SameIdentifier() = true for different values, want false")
+	}
+	tests := []struct {
+		id   *d4pb.Identifier
+		want bool
+	}{
+		{identifier("http://hl7.org/fhir/sid/us-ssn", "123456789"), true},
+		{identifier("http://hl7.org/fhir/sid/us-ssn", "12345"), false},
+		{identifier("urn:oid:1.2.3.4.5", "42"), true},
+		{identifier("http://loinc.org", "8867-4"), false},
+		{identifier("http://example.com/unknown", "42"), false},
+	}
+	for _, tc := range tests {
+		if got := r.Identifying(tc.id); got != tc.want {
+			t.Errorf("Identifying(%v) = %v, want %v", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := &ppb.Patient{Identifier: []*d4pb.Identifier{
+		identifier("http://hl7.org/fhir/sid/us-ssn", "123-45-6789"),
+		identifier("http://hl7.org/fhir/sid/us-ssn", "not-an-ssn"),
+		identifier("http://example.com/mrn", "42"),
+		identifier("http://loinc.org", "8867-4"),
+		{Value: &d4pb.String{Value: "no system"}},
+	}, GeneralPractitioner: []*d4pb.Reference{{Identifier: identifier("http://hl7.org/fhir/sid/us-npi", "123")}}}
+	v, err := revalidate.NewValidator(Default().Constraint())
+	if err != nil {
+		t.Fatalf("NewValidator() returned unexpected error: %v", err)
+	}
+	res, err := v.Validate(p)
+	if err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+	warning := func(path, msg string) revalidate.Issue {
+		return revalidate.Issue{Constraint: ConstraintKey, Path: path, Severity: errorreporter.IssueSeverityWarning, Message: msg}
+	}
+	want := []revalidate.Issue{
+		warning("Patient.identifier[1]", `"not-an-ssn" is not a valid US Social Security Number`),
+		warning("Patient.identifier[2]", `unknown identifier system "http://example.com/mrn"`),
+		warning("Patient.identifier[3]", `"http://loinc.org" is a code system, not an identifier system`),
+		warning("Patient.generalPractitioner[0].identifier", `"123" is not a valid US National Provider Identifier`),
+	}
+	if diff := cmp.Diff(want, res.Issues); diff != "" {
+		t.Errorf("Validate() issues diff (-want +got):\n%s", diff)
+	}
+	if !res.Valid() {
+		t.Errorf("Valid() = false, want true for warnings only")
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package omop
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	mapb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_administration_go_proto"
+	medpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_go_proto"
+	mrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/medication_request_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	patientpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+// OMOP concept ids used for fields that do not come from the vocabulary.
+const (
+	genderMale   = 8507
+	genderFemale = 8532
+
+	typeEHR               = 32817
+	typeEHRPrescription   = 32838
+	typeEHRAdministration = 32818
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04:05"
+)
+
+// ErrNoPerson is returned when a resource cannot be attributed to a Patient,
+// which every OMOP clinical event table requires.
+var ErrNoPerson = errors.New("resource has no Patient subject")
+
+// Exporter converts FHIR resources to OMOP rows. OMOP uses integer keys, so
+// the Exporter assigns person ids on first sight of a Patient id, whether
+// from the Patient resource or from a reference to it, and sequential ids to
+// the rows of each event table. An Exporter is safe for concurrent use.
+type Exporter struct {
+	vocab Vocabulary
+	w     RowWriter
+
+	mu          sync.Mutex
+	persons     map[string]int64
+	nextID      map[string]int64
+	medications map[string]*d4pb.CodeableConcept
+}
+
+// NewExporter returns an Exporter mapping codes with vocab and writing rows
+// to w.
+func NewExporter(vocab Vocabulary, w RowWriter) *Exporter {
+	return &Exporter{
+		vocab:       vocab,
+		w:           w,
+		persons:     map[string]int64{},
+		nextID:      map[string]int64{},
+		medications: map[string]*d4pb.CodeableConcept{},
+	}
+}
+
+// PersonID returns the person_id assigned to the Patient with the given
+// logical id, assigning one if needed.
+func (e *Exporter) PersonID(patientID string) int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	id, ok := e.persons[patientID]
+	if !ok {
+		id = int64(len(e.persons) + 1)
+		e.persons[patientID] = id
+	}
+	return id
+}
+
+func (e *Exporter) rowID(t *Table) int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nextID[t.Name]++
+	return e.nextID[t.Name]
+}
+
+// Export writes the rows for msg, which is either a ContainedResource or an
+// R4 resource. Resource types without an OMOP mapping are ignored, except for
+// Medications, whose codes are remembered so that MedicationRequests and
+// MedicationAdministrations exported afterwards can reference them.
+func (e *Exporter) Export(msg proto.Message) error {
+	switch res := elementpath.Unwrap(msg).(type) {
+	case *patientpb.Patient:
+		return e.exportPatient(res)
+	case *cpb.Condition:
+		return e.exportCondition(res)
+	case *obspb.Observation:
+		return e.exportObservation(res)
+	case *mrpb.MedicationRequest:
+		return e.exportMedicationRequest(res)
+	case *mapb.MedicationAdministration:
+		return e.exportMedicationAdministration(res)
+	case *medpb.Medication:
+		if id := res.GetId().GetValue(); id != "" && res.GetCode() != nil {
+			e.mu.Lock()
+			e.medications[id] = res.GetCode()
+			e.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) write(r *row) error {
+	return e.w.WriteRow(r.table, r.ordered())
+}
+
+func (e *Exporter) exportPatient(p *patientpb.Patient) error {
+	id := p.GetId().GetValue()
+	if id == "" {
+		return fmt.Errorf("Patient has no id")
+	}
+	r := newRow(Person)
+	r.set("person_id", formatInt(e.PersonID(id)))
+	r.set("person_source_value", id)
+	gender := int64(0)
+	switch p.GetGender().GetValue() {
+	case c4pb.AdministrativeGenderCode_MALE:
+		gender = genderMale
+	case c4pb.AdministrativeGenderCode_FEMALE:
+		gender = genderFemale
+	}
+	if p.GetGender() != nil {
+		r.set("gender_source_value", strings.ToLower(p.GetGender().GetValue().String()))
+	}
+	r.set("gender_concept_id", formatInt(gender))
+	r.set("race_concept_id", "0")
+	r.set("ethnicity_concept_id", "0")
+	if bd := p.GetBirthDate(); bd != nil {
+		t, err := toTime(bd.GetValueUs(), bd.GetTimezone())
+		if err != nil {
+			return err
+		}
+		r.set("year_of_birth", strconv.Itoa(t.Year()))
+		if bd.GetPrecision() != d4pb.Date_YEAR {
+			r.set("month_of_birth", strconv.Itoa(int(t.Month())))
+		}
+		if bd.GetPrecision() == d4pb.Date_DAY {
+			r.set("day_of_birth", strconv.Itoa(t.Day()))
+		}
+	}
+	return e.write(r)
+}
+
+func (e *Exporter) exportCondition(c *cpb.Condition) error {
+	person, err := e.subject(c.GetSubject())
+	if err != nil {
+		return err
+	}
+	r := newRow(ConditionOccurrence)
+	r.set("condition_occurrence_id", formatInt(e.rowID(ConditionOccurrence)))
+	r.set("person_id", person)
+	concept, source := e.concept(c.GetCode())
+	r.set("condition_concept_id", formatInt(concept.ID))
+	r.set("condition_source_value", source)
+	r.set("condition_type_concept_id", formatInt(typeEHR))
+
+	start := c.GetOnset().GetDateTime()
+	if start == nil {
+		start = c.GetOnset().GetPeriod().GetStart()
+	}
+	if start == nil {
+		start = c.GetRecordedDate()
+	}
+	if err := setDateTime(r, "condition_start_date", "condition_start_datetime", start); err != nil {
+		return err
+	}
+	end := c.GetAbatement().GetDateTime()
+	if end == nil {
+		end = c.GetAbatement().GetPeriod().GetEnd()
+	}
+	if err := setDateTime(r, "condition_end_date", "condition_end_datetime", end); err != nil {
+		return err
+	}
+	return e.write(r)
+}
+
+func (e *Exporter) exportObservation(o *obspb.Observation) error {
+	person, err := e.subject(o.GetSubject())
+	if err != nil {
+		return err
+	}
+	concept, source := e.concept(o.GetCode())
+	t := Observation
+	if isMeasurement(o, concept) {
+		t = Measurement
+	}
+	r := newRow(t)
+	r.set(t.Name+"_id", formatInt(e.rowID(t)))
+	r.set("person_id", person)
+	r.set(t.Name+"_concept_id", formatInt(concept.ID))
+	r.set(t.Name+"_source_value", source)
+	r.set(t.Name+"_type_concept_id", formatInt(typeEHR))
+
+	effective := o.GetEffective().GetDateTime()
+	if effective == nil {
+		effective = o.GetEffective().GetPeriod().GetStart()
+	}
+	if effective == nil && o.GetEffective().GetInstant() != nil {
+		i := o.GetEffective().GetInstant()
+		effective = &d4pb.DateTime{ValueUs: i.GetValueUs(), Timezone: i.GetTimezone(), Precision: d4pb.DateTime_SECOND}
+	}
+	if err := setDateTime(r, t.Name+"_date", t.Name+"_datetime", effective); err != nil {
+		return err
+	}
+
+	v := o.GetValue()
+	switch {
+	case v.GetQuantity() != nil:
+		q := v.GetQuantity()
+		r.set("value_as_number", q.GetValue().GetValue())
+		r.set("unit_concept_id", formatInt(e.lookup(q.GetSystem().GetValue(), q.GetCode().GetValue()).ID))
+		unit := q.GetCode().GetValue()
+		if unit == "" {
+			unit = q.GetUnit().GetValue()
+		}
+		r.set("unit_source_value", unit)
+		r.set("value_source_value", q.GetValue().GetValue())
+	case v.GetCodeableConcept() != nil:
+		vc, vs := e.concept(v.GetCodeableConcept())
+		r.set("value_as_concept_id", formatInt(vc.ID))
+		r.set("value_source_value", vs)
+	case v.GetInteger() != nil:
+		n := strconv.Itoa(int(v.GetInteger().GetValue()))
+		r.set("value_as_number", n)
+		r.set("value_source_value", n)
+	case v.GetStringValue() != nil:
+		if t == Observation {
+			r.set("value_as_string", v.GetStringValue().GetValue())
+		}
+		r.set("value_source_value", v.GetStringValue().GetValue())
+	case v.GetBoolean() != nil:
+		r.set("value_source_value", strconv.FormatBool(v.GetBoolean().GetValue()))
+	}
+	if t == Measurement && len(o.GetReferenceRange()) > 0 {
+		rr := o.GetReferenceRange()[0]
+		r.set("range_low", rr.GetLow().GetValue().GetValue())
+		r.set("range_high", rr.GetHigh().GetValue().GetValue())
+	}
+	return e.write(r)
+}
+
+// isMeasurement reports whether an Observation belongs in the measurement
+// table: by the domain of its concept if known, and otherwise if it has a
+// quantitative value or a laboratory or vital-signs category.
+func isMeasurement(o *obspb.Observation, c Concept) bool {
+	switch c.Domain {
+	case DomainMeasurement:
+		return true
+	case DomainObservation:
+		return false
+	}
+	if o.GetValue().GetQuantity() != nil {
+		return true
+	}
+	for _, cat := range o.GetCategory() {
+		for _, coding := range cat.GetCoding() {
+			switch coding.GetCode().GetValue() {
+			case "laboratory", "vital-signs":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e *Exporter) exportMedicationRequest(m *mrpb.MedicationRequest) error {
+	person, err := e.subject(m.GetSubject())
+	if err != nil {
+		return err
+	}
+	r := e.drugExposure(person, m.GetMedication().GetCodeableConcept(), m.GetMedication().GetReference())
+	r.set("drug_type_concept_id", formatInt(typeEHRPrescription))
+	validity := m.GetDispenseRequest().GetValidityPeriod()
+	start := m.GetAuthoredOn()
+	if start == nil {
+		start = validity.GetStart()
+	}
+	if err := setDateTime(r, "drug_exposure_start_date", "drug_exposure_start_datetime", start); err != nil {
+		return err
+	}
+	if err := setDateTime(r, "drug_exposure_end_date", "drug_exposure_end_datetime", validity.GetEnd()); err != nil {
+		return err
+	}
+	r.set("quantity", m.GetDispenseRequest().GetQuantity().GetValue().GetValue())
+	if len(m.GetDosageInstruction()) > 0 {
+		e.setRoute(r, m.GetDosageInstruction()[0].GetRoute())
+	}
+	return e.write(r)
+}
+
+func (e *Exporter) exportMedicationAdministration(m *mapb.MedicationAdministration) error {
+	person, err := e.subject(m.GetSubject())
+	if err != nil {
+		return err
+	}
+	r := e.drugExposure(person, m.GetMedication().GetCodeableConcept(), m.GetMedication().GetReference())
+	r.set("drug_type_concept_id", formatInt(typeEHRAdministration))
+	start, end := m.GetEffective().GetDateTime(), m.GetEffective().GetDateTime()
+	if p := m.GetEffective().GetPeriod(); p != nil {
+		start, end = p.GetStart(), p.GetEnd()
+	}
+	if err := setDateTime(r, "drug_exposure_start_date", "drug_exposure_start_datetime", start); err != nil {
+		return err
+	}
+	if err := setDateTime(r, "drug_exposure_end_date", "drug_exposure_end_datetime", end); err != nil {
+		return err
+	}
+	r.set("quantity", m.GetDosage().GetDose().GetValue().GetValue())
+	e.setRoute(r, m.GetDosage().GetRoute())
+	return e.write(r)
+}
+
+func (e *Exporter) drugExposure(person string, code *d4pb.CodeableConcept, ref *d4pb.Reference) *row {
+	if code == nil && ref != nil {
+		id := ref.GetMedicationId().GetValue()
+		if id == "" {
+			id = strings.TrimPrefix(ref.GetFragment().GetValue(), "#")
+		}
+		e.mu.Lock()
+		code = e.medications[id]
+		e.mu.Unlock()
+	}
+	r := newRow(DrugExposure)
+	r.set("drug_exposure_id", formatInt(e.rowID(DrugExposure)))
+	r.set("person_id", person)
+	concept, source := e.concept(code)
+	r.set("drug_concept_id", formatInt(concept.ID))
+	r.set("drug_source_value", source)
+	return r
+}
+
+func (e *Exporter) setRoute(r *row, route *d4pb.CodeableConcept) {
+	if route == nil {
+		return
+	}
+	concept, source := e.concept(route)
+	r.set("route_concept_id", formatInt(concept.ID))
+	r.set("route_source_value", source)
+}
+
+// subject returns the person_id for a reference to a Patient.
+func (e *Exporter) subject(ref *d4pb.Reference) (string, error) {
+	id := ref.GetPatientId().GetValue()
+	if id == "" {
+		return "", ErrNoPerson
+	}
+	return formatInt(e.PersonID(id)), nil
+}
+
+func (e *Exporter) lookup(system, code string) Concept {
+	if e.vocab == nil || code == "" {
+		return Concept{}
+	}
+	c, _ := e.vocab.Lookup(system, code)
+	return c
+}
+
+// concept returns the concept of the first coding of cc known to the
+// vocabulary, along with the source value to record: the code of that coding,
+// or of the first coding, or the text if there are no codings. Unmapped codes
+// get concept 0 as required by OMOP conventions.
+func (e *Exporter) concept(cc *d4pb.CodeableConcept) (Concept, string) {
+	for _, c := range cc.GetCoding() {
+		if concept := e.lookup(c.GetSystem().GetValue(), c.GetCode().GetValue()); concept.ID != 0 {
+			return concept, c.GetCode().GetValue()
+		}
+	}
+	if len(cc.GetCoding()) > 0 {
+		return Concept{}, cc.GetCoding()[0].GetCode().GetValue()
+	}
+	return Concept{}, cc.GetText().GetValue()
+}
+
+func setDateTime(r *row, dateCol, dateTimeCol string, dt *d4pb.DateTime) error {
+	if dt == nil {
+		return nil
+	}
+	t, err := toTime(dt.GetValueUs(), dt.GetTimezone())
+	if err != nil {
+		return err
+	}
+	r.set(dateCol, t.Format(dateLayout))
+	switch dt.GetPrecision() {
+	case d4pb.DateTime_YEAR, d4pb.DateTime_MONTH, d4pb.DateTime_DAY:
+	default:
+		r.set(dateTimeCol, t.Format(dateTimeLayout))
+	}
+	return nil
+}
+
+// toTime converts a FHIR primitive timestamp into its local time, so that
+// dates are reported as recorded.
+func toTime(us int64, tz string) (time.Time, error) {
+	t := time.UnixMicro(us)
+	if tz == "" || tz == "Z" || tz == "UTC" {
+		return t.UTC(), nil
+	}
+	if loc, err := time.LoadLocation(tz); err == nil {
+		return t.In(loc), nil
+	}
+	offset, err := time.Parse("-07:00", tz)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time zone %q", tz)
+	}
+	return t.In(offset.Location()), nil
+}
+
+func formatInt(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package omop
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	loinc  = "http://loinc.org"
+	snomed = "http://snomed.info/sct"
+	rxnorm = "http://www.nlm.nih.gov/research/umls/rxnorm"
+	ucum   = "http://unitsofmeasure.org"
+)
+
+// recorder is a RowWriter keeping rows by table name, as maps from column to
+// non-empty value.
+type recorder map[string][]map[string]string
+
+func (r recorder) WriteRow(t *Table, values []string) error {
+	m := map[string]string{}
+	for i, v := range values {
+		if v != "" {
+			m[t.Columns[i]] = v
+		}
+	}
+	r[t.Name] = append(r[t.Name], m)
+	return nil
+}
+
+func testVocabulary() *StaticVocabulary {
+	v := NewStaticVocabulary()
+	v.Add(snomed, "44054006", Concept{ID: 201826, Domain: "Condition"})
+	v.Add(loinc, "2345-7", Concept{ID: 3004501, Domain: DomainMeasurement})
+	v.Add(loinc, "72166-2", Concept{ID: 40766362, Domain: DomainObservation})
+	v.Add(snomed, "8517006", Concept{ID: 4310250, Domain: DomainObservation})
+	v.Add(ucum, "mg/dL", Concept{ID: 8840, Domain: "Unit"})
+	v.Add(rxnorm, "860975", Concept{ID: 40163924, Domain: "Drug"})
+	v.Add(snomed, "26643006", Concept{ID: 4132161, Domain: "Route"})
+	return v
+}
+
+func TestExport(t *testing.T) {
+	resources := []string{
+		`{"resourceType": "Patient", "id": "p1", "gender": "female", "birthDate": "1970-03-04"}`,
+		`{"resourceType": "Patient", "id": "p2", "birthDate": "1980"}`,
+		`{"resourceType": "Condition", "id": "c1", "subject": {"reference": "Patient/p1"},
+		  "code": {"coding": [{"system": "http://snomed.info/sct", "code": "44054006"}]},
+		  "onsetDateTime": "2019-05-06", "abatementDateTime": "2020-01-02T10:11:12Z"}`,
+		`{"resourceType": "Observation", "id": "o1", "status": "final", "subject": {"reference": "Patient/p1"},
+		  "code": {"coding": [{"system": "http://loinc.org", "code": "2345-7"}]},
+		  "effectiveDateTime": "2020-02-03T04:05:06Z",
+		  "valueQuantity": {"value": 95.5, "system": "http://unitsofmeasure.org", "code": "mg/dL"},
+		  "referenceRange": [{"low": {"value": 70}, "high": {"value": 100}}]}`,
+		`{"resourceType": "Observation", "id": "o2", "status": "final", "subject": {"reference": "Patient/p2"},
+		  "code": {"coding": [{"system": "http://loinc.org", "code": "72166-2"}]},
+		  "effectiveDateTime": "2020-02-03",
+		  "valueCodeableConcept": {"coding": [{"system": "http://snomed.info/sct", "code": "8517006"}]}}`,
+		`{"resourceType": "Observation", "id": "o3", "status": "final", "subject": {"reference": "Patient/p3"},
+		  "category": [{"coding": [{"code": "vital-signs"}]}],
+		  "code": {"text": "pulse"}, "valueInteger": 60}`,
+		`{"resourceType": "Medication", "id": "m1",
+		  "code": {"coding": [{"system": "http://www.nlm.nih.gov/research/umls/rxnorm", "code": "860975"}]}}`,
+		`{"resourceType": "MedicationRequest", "id": "mr1", "status": "active", "intent": "order",
+		  "subject": {"reference": "Patient/p1"}, "medicationReference": {"reference": "Medication/m1"},
+		  "authoredOn": "2021-01-01", "dosageInstruction": [{"route": {"coding": [{"system": "http://snomed.info/sct", "code": "26643006"}]}}],
+		  "dispenseRequest": {"validityPeriod": {"end": "2021-02-01"}, "quantity": {"value": 30}}}`,
+		`{"resourceType": "MedicationAdministration", "id": "ma1", "status": "completed",
+		  "subject": {"reference": "Patient/p2"},
+		  "medicationCodeableConcept": {"coding": [{"system": "urn:local", "code": "X"}]},
+		  "effectivePeriod": {"start": "2021-03-01T08:00:00Z", "end": "2021-03-01T09:00:00Z"},
+		  "dosage": {"dose": {"value": 2}}}`,
+		`{"resourceType": "Encounter", "id": "e1", "status": "finished", "class": {"code": "AMB"}}`,
+	}
+	um, err := jsonformat.NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	got := recorder{}
+	e := NewExporter(testVocabulary(), got)
+	for _, r := range resources {
+		res, err := um.Unmarshal([]byte(r))
+		if err != nil {
+			t.Fatalf("Unmarshal(%s) returned unexpected error: %v", r, err)
+		}
+		if err := e.Export(res); err != nil {
+			t.Fatalf("Export(%s) returned unexpected error: %v", r, err)
+		}
+	}
+
+	want := recorder{
+		"person": {
+			{
+				"person_id":            "1",
+				"gender_concept_id":    "8532",
+				"year_of_birth":        "1970",
+				"month_of_birth":       "3",
+				"day_of_birth":         "4",
+				"race_concept_id":      "0",
+				"ethnicity_concept_id": "0",
+				"person_source_value":  "p1",
+				"gender_source_value":  "female",
+			},
+			{
+				"person_id":            "2",
+				"gender_concept_id":    "0",
+				"year_of_birth":        "1980",
+				"race_concept_id":      "0",
+				"ethnicity_concept_id": "0",
+				"person_source_value":  "p2",
+			},
+		},
+		"condition_occurrence": {{
+			"condition_occurrence_id":   "1",
+			"person_id":                 "1",
+			"condition_concept_id":      "201826",
+			"condition_start_date":      "2019-05-06",
+			"condition_end_date":        "2020-01-02",
+			"condition_end_datetime":    "2020-01-02 10:11:12",
+			"condition_type_concept_id": "32817",
+			"condition_source_value":    "44054006",
+		}},
+		"measurement": {
+			{
+				"measurement_id":              "1",
+				"person_id":                   "1",
+				"measurement_concept_id":      "3004501",
+				"measurement_date":            "2020-02-03",
+				"measurement_datetime":        "2020-02-03 04:05:06",
+				"measurement_type_concept_id": "32817",
+				"value_as_number":             "95.5",
+				"unit_concept_id":             "8840",
+				"range_low":                   "70",
+				"range_high":                  "100",
+				"measurement_source_value":    "2345-7",
+				"unit_source_value":           "mg/dL",
+				"value_source_value":          "95.5",
+			},
+			{
+				"measurement_id":              "2",
+				"person_id":                   "3",
+				"measurement_concept_id":      "0",
+				"measurement_type_concept_id": "32817",
+				"value_as_number":             "60",
+				"measurement_source_value":    "pulse",
+				"value_source_value":          "60",
+			},
+		},
+		"observation": {{
+			"observation_id":              "1",
+			"person_id":                   "2",
+			"observation_concept_id":      "40766362",
+			"observation_date":            "2020-02-03",
+			"observation_type_concept_id": "32817",
+			"value_as_concept_id":         "4310250",
+			"observation_source_value":    "72166-2",
+			"value_source_value":          "8517006",
+		}},
+		"drug_exposure": {
+			{
+				"drug_exposure_id":         "1",
+				"person_id":                "1",
+				"drug_concept_id":          "40163924",
+				"drug_exposure_start_date": "2021-01-01",
+				"drug_exposure_end_date":   "2021-02-01",
+				"drug_type_concept_id":     "32838",
+				"quantity":                 "30",
+				"route_concept_id":         "4132161",
+				"drug_source_value":        "860975",
+				"route_source_value":       "26643006",
+			},
+			{
+				"drug_exposure_id":             "2",
+				"person_id":                    "2",
+				"drug_concept_id":              "0",
+				"drug_exposure_start_date":     "2021-03-01",
+				"drug_exposure_start_datetime": "2021-03-01 08:00:00",
+				"drug_exposure_end_date":       "2021-03-01",
+				"drug_exposure_end_datetime":   "2021-03-01 09:00:00",
+				"drug_type_concept_id":         "32818",
+				"quantity":                     "2",
+				"drug_source_value":            "X",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Export() wrote unexpected rows, diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestExport_NoPerson(t *testing.T) {
+	um, err := jsonformat.NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	res, err := um.Unmarshal([]byte(`{"resourceType": "Condition", "subject": {"reference": "Group/g1"}}`))
+	if err != nil {
+		t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+	}
+	if err := NewExporter(nil, recorder{}).Export(res); !errors.Is(err, ErrNoPerson) {
+		t.Errorf("Export() returned error %v, want %v", err, ErrNoPerson)
+	}
+}
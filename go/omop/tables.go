@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package omop exports R4 FHIR resources to OMOP Common Data Model v5.4
+// tables for research data warehousing.
+//
+// Patients become person rows, Conditions condition_occurrence rows,
+// Observations measurement or observation rows depending on the domain of
+// their concept, and MedicationRequests and MedicationAdministrations
+// drug_exposure rows. Only the subset of each table's columns that can be
+// derived from the source resource is populated; the remaining nullable
+// columns are omitted.
+package omop
+
+// Table describes the columns of an OMOP CDM table that the exporter
+// populates, in output order.
+type Table struct {
+	Name    string
+	Columns []string
+}
+
+var (
+	// Person is the OMOP person table.
+	Person = &Table{
+		Name: "person",
+		Columns: []string{
+			"person_id",
+			"gender_concept_id",
+			"year_of_birth",
+			"month_of_birth",
+			"day_of_birth",
+			"birth_datetime",
+			"race_concept_id",
+			"ethnicity_concept_id",
+			"person_source_value",
+			"gender_source_value",
+		},
+	}
+	// ConditionOccurrence is the OMOP condition_occurrence table.
+	ConditionOccurrence = &Table{
+		Name: "condition_occurrence",
+		Columns: []string{
+			"condition_occurrence_id",
+			"person_id",
+			"condition_concept_id",
+			"condition_start_date",
+			"condition_start_datetime",
+			"condition_end_date",
+			"condition_end_datetime",
+			"condition_type_concept_id",
+			"condition_source_value",
+		},
+	}
+	// Measurement is the OMOP measurement table.
+	Measurement = &Table{
+		Name: "measurement",
+		Columns: []string{
+			"measurement_id",
+			"person_id",
+			"measurement_concept_id",
+			"measurement_date",
+			"measurement_datetime",
+			"measurement_type_concept_id",
+			"value_as_number",
+			"value_as_concept_id",
+			"unit_concept_id",
+			"range_low",
+			"range_high",
+			"measurement_source_value",
+			"unit_source_value",
+			"value_source_value",
+		},
+	}
+	// Observation is the OMOP observation table.
+	Observation = &Table{
+		Name: "observation",
+		Columns: []string{
+			"observation_id",
+			"person_id",
+			"observation_concept_id",
+			"observation_date",
+			"observation_datetime",
+			"observation_type_concept_id",
+			"value_as_number",
+			"value_as_string",
+			"value_as_concept_id",
+			"unit_concept_id",
+			"observation_source_value",
+			"unit_source_value",
+			"value_source_value",
+		},
+	}
+	// DrugExposure is the OMOP drug_exposure table.
+	DrugExposure = &Table{
+		Name: "drug_exposure",
+		Columns: []string{
+			"drug_exposure_id",
+			"person_id",
+			"drug_concept_id",
+			"drug_exposure_start_date",
+			"drug_exposure_start_datetime",
+			"drug_exposure_end_date",
+			"drug_exposure_end_datetime",
+			"drug_type_concept_id",
+			"quantity",
+			"route_concept_id",
+			"drug_source_value",
+			"route_source_value",
+		},
+	}
+
+	// Tables lists all tables the exporter writes to.
+	Tables = []*Table{Person, ConditionOccurrence, Measurement, Observation, DrugExposure}
+)
+
+// row accumulates column values for a table by name.
+type row struct {
+	table  *Table
+	values map[string]string
+}
+
+func newRow(t *Table) *row {
+	return &row{table: t, values: map[string]string{}}
+}
+
+func (r *row) set(col, v string) {
+	r.values[col] = v
+}
+
+// ordered returns the values in the table's column order, with empty strings
+// for unset columns.
+func (r *row) ordered() []string {
+	out := make([]string, len(r.table.Columns))
+	for i, c := range r.table.Columns {
+		out[i] = r.values[c]
+	}
+	return out
+}
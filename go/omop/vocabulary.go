@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package omop
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Domains of OMOP standard concepts relevant to routing Observations.
+const (
+	DomainMeasurement = "Measurement"
+	DomainObservation = "Observation"
+)
+
+// Concept is an OMOP concept a FHIR code maps to.
+type Concept struct {
+	ID int64
+	// Domain is the OMOP domain_id of the concept, e.g. "Measurement". It may
+	// be empty if unknown.
+	Domain string
+}
+
+// Vocabulary maps FHIR codes to OMOP concepts.
+type Vocabulary interface {
+	// Lookup returns the concept for code in the given code system URI.
+	Lookup(system, code string) (Concept, bool)
+}
+
+// StaticVocabulary is a Vocabulary backed by an in-memory table, typically
+// derived from the OMOP CONCEPT and CONCEPT_RELATIONSHIP tables.
+type StaticVocabulary struct {
+	concepts map[string]Concept
+}
+
+// NewStaticVocabulary returns an empty StaticVocabulary.
+func NewStaticVocabulary() *StaticVocabulary {
+	return &StaticVocabulary{concepts: map[string]Concept{}}
+}
+
+// Add maps code in system to c.
+func (v *StaticVocabulary) Add(system, code string, c Concept) {
+	v.concepts[system+"|"+code] = c
+}
+
+// Lookup implements Vocabulary.
+func (v *StaticVocabulary) Lookup(system, code string) (Concept, bool) {
+	c, ok := v.concepts[system+"|"+code]
+	return c, ok
+}
+
+// LoadVocabularyCSV reads mappings from CSV with a header row containing the
+// columns system, code, concept_id and optionally domain_id, in any order.
+func LoadVocabularyCSV(r io.Reader) (*StaticVocabulary, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading vocabulary header: %w", err)
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.TrimSpace(strings.ToLower(h))] = i
+	}
+	for _, c := range []string{"system", "code", "concept_id"} {
+		if _, ok := cols[c]; !ok {
+			return nil, fmt.Errorf("vocabulary is missing column %q", c)
+		}
+	}
+	domainCol, hasDomain := cols["domain_id"]
+	v := NewStaticVocabulary()
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return v, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading vocabulary: %w", err)
+		}
+		field := func(i int) string {
+			if i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		id, err := strconv.ParseInt(field(cols["concept_id"]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary line %d: invalid concept_id: %w", line, err)
+		}
+		c := Concept{ID: id}
+		if hasDomain {
+			c.Domain = field(domainCol)
+		}
+		v.Add(field(cols["system"]), field(cols["code"]), c)
+	}
+}
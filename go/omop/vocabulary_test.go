@@ -0,0 +1,54 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package omop
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadVocabularyCSV(t *testing.T) {
+	v, err := LoadVocabularyCSV(strings.NewReader("code,system,concept_id,domain_id\n2345-7,http://loinc.org,3004501,Measurement\nGLU,urn:local,3004501\n"))
+	if err != nil {
+		t.Fatalf("LoadVocabularyCSV() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		system, code string
+		want         Concept
+		wantOK       bool
+	}{
+		{"http://loinc.org", "2345-7", Concept{ID: 3004501, Domain: DomainMeasurement}, true},
+		{"urn:local", "GLU", Concept{ID: 3004501}, true},
+		{"http://loinc.org", "GLU", Concept{}, false},
+	}
+	for _, test := range tests {
+		got, ok := v.Lookup(test.system, test.code)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("Lookup(%q, %q) = %v, %v, want %v, %v", test.system, test.code, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestLoadVocabularyCSV_Errors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"system,code\nurn:local,X\n",
+		"system,code,concept_id\nurn:local,X,notanumber\n",
+	} {
+		if _, err := LoadVocabularyCSV(strings.NewReader(in)); err == nil {
+			t.Errorf("LoadVocabularyCSV(%q) succeeded, want error", in)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package omop
+
+import (
+	"encoding/csv"
+	"io"
+	"sync"
+)
+
+// RowWriter receives exported rows. Values are in the order of t.Columns,
+// with empty strings for null columns.
+//
+// Columnar formats such as Parquet can be supported by implementing
+// RowWriter on top of an encoder for that format.
+type RowWriter interface {
+	WriteRow(t *Table, values []string) error
+}
+
+// CSVWriter is a RowWriter producing one CSV file per table, each starting
+// with a header row. It is safe for concurrent use.
+type CSVWriter struct {
+	open    func(table string) (io.Writer, error)
+	mu      sync.Mutex
+	writers map[string]*csv.Writer
+}
+
+// NewCSVWriter returns a CSVWriter which calls open the first time a row is
+// written to a table to obtain the destination for that table.
+func NewCSVWriter(open func(table string) (io.Writer, error)) *CSVWriter {
+	return &CSVWriter{open: open, writers: map[string]*csv.Writer{}}
+}
+
+// WriteRow implements RowWriter.
+func (w *CSVWriter) WriteRow(t *Table, values []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	cw, ok := w.writers[t.Name]
+	if !ok {
+		out, err := w.open(t.Name)
+		if err != nil {
+			return err
+		}
+		cw = csv.NewWriter(out)
+		if err := cw.Write(t.Columns); err != nil {
+			return err
+		}
+		w.writers[t.Name] = cw
+	}
+	return cw.Write(values)
+}
+
+// Flush flushes buffered rows of all tables.
+func (w *CSVWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, cw := range w.writers {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package omop
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSVWriter(t *testing.T) {
+	files := map[string]*strings.Builder{}
+	w := NewCSVWriter(func(table string) (io.Writer, error) {
+		files[table] = &strings.Builder{}
+		return files[table], nil
+	})
+	tbl := &Table{Name: "t", Columns: []string{"a", "b"}}
+	for _, row := range [][]string{{"1", ""}, {"2", "x,y"}} {
+		if err := w.WriteRow(tbl, row); err != nil {
+			t.Fatalf("WriteRow() returned unexpected error: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() returned unexpected error: %v", err)
+	}
+	got := map[string]string{}
+	for k, v := range files {
+		got[k] = v.String()
+	}
+	want := map[string]string{"t": "a,b\n1,\n2,\"x,y\"\n"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CSVWriter wrote unexpected output, diff (-want +got):\n%s", diff)
+	}
+}
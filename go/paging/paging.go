@@ -0,0 +1,229 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paging assembles the complete results of FHIR searches, and of
+// operations such as Patient/$everything, that servers return in pages:
+// searchset Bundles linking to the next page with a link of relation "next".
+//
+// An Iterator streams the entries of the pages, fetching each page when the
+// entries of the previous one are consumed:
+//
+//	it := paging.NewIterator(ctx, fetcher, first)
+//	for {
+//		e, err := it.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			...
+//		}
+//		...
+//	}
+//
+// Assemble collects the entries into a single searchset Bundle, up to a
+// maximum number of entries. Both skip the entries of resources already
+// returned by an earlier page, as servers may return when the results
+// change while they are paged through.
+package paging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// ErrTooLarge is returned by Assemble for results with more entries than its
+// maximum.
+var ErrTooLarge = errors.New("result set too large")
+
+// Fetcher fetches the pages of results.
+type Fetcher interface {
+	// Fetch returns the Bundle at url, the URL of a "next" link of the
+	// previous page, resolved against its "self" link if it is relative.
+	Fetch(ctx context.Context, url string) (*r4pb.Bundle, error)
+}
+
+// FetcherFunc is a Fetcher of a function.
+type FetcherFunc func(ctx context.Context, url string) (*r4pb.Bundle, error)
+
+// Fetch calls f.
+func (f FetcherFunc) Fetch(ctx context.Context, url string) (*r4pb.Bundle, error) {
+	return f(ctx, url)
+}
+
+// Iterator iterates over the entries of the pages of results.
+type Iterator struct {
+	ctx     context.Context
+	fetcher Fetcher
+	page    *r4pb.Bundle
+	next    int
+	// seen holds the keys of the entries returned, and fetched the URLs of
+	// the pages read, so that pages linked in a loop are an error.
+	seen    map[string]bool
+	fetched map[string]bool
+	pages   int
+	err     error
+}
+
+// NewIterator returns an Iterator over the entries of first and of the pages
+// following it, fetched with f.
+func NewIterator(ctx context.Context, f Fetcher, first *r4pb.Bundle) *Iterator {
+	it := &Iterator{ctx: ctx, fetcher: f, page: first, seen: map[string]bool{}, fetched: map[string]bool{}, pages: 1}
+	if self := link(first, "self"); self != "" {
+		it.fetched[self] = true
+	}
+	return it
+}
+
+// Next returns the next entry, or io.EOF after the last entry of the last
+// page. Entries whose full URL, or resource type and id for entries without
+// one, were returned before are skipped.
+func (it *Iterator) Next() (*r4pb.Bundle_Entry, error) {
+	for it.err == nil {
+		entries := it.page.GetEntry()
+		if it.next < len(entries) {
+			e := entries[it.next]
+			it.next++
+			if k := key(e); k != "" {
+				if it.seen[k] {
+					continue
+				}
+				it.seen[k] = true
+			}
+			return e, nil
+		}
+		it.err = it.fetch()
+	}
+	return nil, it.err
+}
+
+// Pages returns the number of pages read so far, including the first.
+func (it *Iterator) Pages() int {
+	return it.pages
+}
+
+// fetch replaces the page with the page it links to as next, and returns
+// io.EOF if it has none.
+func (it *Iterator) fetch() error {
+	next, err := nextURL(it.page)
+	if err != nil || next == "" {
+		return err
+	}
+	if it.fetched[next] {
+		return fmt.Errorf("page %d links back to %s", it.pages, next)
+	}
+	it.fetched[next] = true
+	page, err := it.fetcher.Fetch(it.ctx, next)
+	if err != nil {
+		return fmt.Errorf("fetching page %d: %w", it.pages+1, err)
+	}
+	it.page, it.next = page, 0
+	it.pages++
+	return nil
+}
+
+// nextURL returns the URL of the "next" link of page, resolved against its
+// "self" link, or io.EOF if it has none.
+func nextURL(page *r4pb.Bundle) (string, error) {
+	next := link(page, "next")
+	if next == "" {
+		return "", io.EOF
+	}
+	u, err := url.Parse(next)
+	if err != nil {
+		return "", fmt.Errorf("invalid next link %q: %w", next, err)
+	}
+	if u.IsAbs() {
+		return next, nil
+	}
+	base, err := url.Parse(link(page, "self"))
+	if err != nil || !base.IsAbs() {
+		return next, nil
+	}
+	return base.ResolveReference(u).String(), nil
+}
+
+func link(b *r4pb.Bundle, relation string) string {
+	for _, l := range b.GetLink() {
+		if l.GetRelation().GetValue() == relation {
+			return l.GetUrl().GetValue()
+		}
+	}
+	return ""
+}
+
+// key returns the key entries of the same resource share: their full URL,
+// or their resource type and id, or "" if they have neither.
+func key(e *r4pb.Bundle_Entry) string {
+	if u := e.GetFullUrl().GetValue(); u != "" {
+		return u
+	}
+	id := elementpath.ID(e.GetResource())
+	if id == "" {
+		return ""
+	}
+	return elementpath.ResourceType(e.GetResource()) + "/" + id
+}
+
+// Assemble returns a searchset Bundle of the entries of first and of the
+// pages following it, fetched with f, as returned by an Iterator. Its total
+// is that of first or, if first has none, the number of its entries that
+// match the search, rather than being included with them; its "self" link is
+// that of first. Assemble returns an error wrapping ErrTooLarge if there are
+// more than max entries, unless max is 0.
+func Assemble(ctx context.Context, f Fetcher, first *r4pb.Bundle, max int) (*r4pb.Bundle, error) {
+	out := &r4pb.Bundle{
+		Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+		Total: first.GetTotal(),
+	}
+	if self := link(first, "self"); self != "" {
+		out.Link = []*r4pb.Bundle_Link{{
+			Relation: &d4pb.String{Value: "self"},
+			Url:      &d4pb.Uri{Value: self},
+		}}
+	}
+	it := NewIterator(ctx, f, first)
+	matches := 0
+	for {
+		e, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if max > 0 && len(out.Entry) == max {
+			return nil, fmt.Errorf("%w: more than %d entries", ErrTooLarge, max)
+		}
+		if mode := e.GetSearch().GetMode().GetValue(); mode != c4pb.SearchEntryModeCode_INCLUDE && mode != c4pb.SearchEntryModeCode_OUTCOME {
+			matches++
+		}
+		out.Entry = append(out.Entry, e)
+	}
+	if out.Total == nil {
+		out.Total = &d4pb.UnsignedInt{Value: uint32(matches)}
+	} else {
+		out.Total = proto.Clone(out.Total).(*d4pb.UnsignedInt)
+	}
+	return out, nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+const base = "https://example.com/fhir/"
+
+func patient(id string) *r4pb.Bundle_Entry {
+	return &r4pb.Bundle_Entry{
+		FullUrl: &d4pb.Uri{Value: base + "Patient/" + id},
+		Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{
+			Patient: &ppb.Patient{Id: &d4pb.Id{Value: id}},
+		}},
+	}
+}
+
+func page(self, next string, entries ...*r4pb.Bundle_Entry) *r4pb.Bundle {
+	b := &r4pb.Bundle{
+		Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+		Link:  []*r4pb.Bundle_Link{{Relation: &d4pb.String{Value: "self"}, Url: &d4pb.Uri{Value: self}}},
+		Entry: entries,
+	}
+	if next != "" {
+		b.Link = append(b.Link, &r4pb.Bundle_Link{Relation: &d4pb.String{Value: "next"}, Url: &d4pb.Uri{Value: next}})
+	}
+	return b
+}
+
+// pages is a Fetcher of Bundles by URL.
+type pages map[string]*r4pb.Bundle
+
+func (p pages) Fetch(ctx context.Context, url string) (*r4pb.Bundle, error) {
+	b, ok := p[url]
+	if !ok {
+		return nil, fmt.Errorf("no page %s", url)
+	}
+	return b, nil
+}
+
+func ids(entries []*r4pb.Bundle_Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.GetResource().GetPatient().GetId().GetValue())
+	}
+	return out
+}
+
+func TestIterator(t *testing.T) {
+	first := page(base+"Patient?_count=2", base+"Patient?page=2", patient("1"), patient("2"))
+	f := pages{
+		base + "Patient?page=2": page(base+"Patient?page=2", "?page=3", patient("2"), patient("3")),
+		base + "Patient?page=3": page(base+"Patient?page=3", "", patient("4")),
+	}
+	it := NewIterator(context.Background(), f, first)
+	var got []*r4pb.Bundle_Entry
+	for {
+		e, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() returned unexpected error: %v", err)
+		}
+		got = append(got, e)
+	}
+	if diff := cmp.Diff([]string{"1", "2", "3", "4"}, ids(got)); diff != "" {
+		t.Errorf("Next() diff (-want +got):\n%s", diff)
+	}
+	if got := it.Pages(); got != 3 {
+		t.Errorf("Pages() = %d, want 3", got)
+	}
+	if _, err := it.Next(); err != io.EOF {
+		t.Errorf("Next() after the last entry returned %v, want io.EOF", err)
+	}
+}
+
+func TestIterator_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fetcher Fetcher
+	}{
+		{
+			name:    "fetch error",
+			fetcher: pages{},
+		},
+		{
+			name: "loop",
+			fetcher: pages{
+				base + "Patient?page=2": page(base+"Patient?page=2", base+"Patient?_count=1"),
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			first := page(base+"Patient?_count=1", base+"Patient?page=2", patient("1"))
+			it := NewIterator(context.Background(), tc.fetcher, first)
+			if _, err := it.Next(); err != nil {
+				t.Fatalf("Next() returned unexpected error: %v", err)
+			}
+			if _, err := it.Next(); err == nil || err == io.EOF {
+				t.Errorf("Next() returned %v, want error", err)
+			}
+		})
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	include := patient("9")
+	include.Search = &r4pb.Bundle_Entry_Search{Mode: &r4pb.Bundle_Entry_Search_ModeCode{Value: c4pb.SearchEntryModeCode_INCLUDE}}
+	first := page(base+"Patient?_count=2", base+"Patient?page=2", patient("1"), include)
+	f := FetcherFunc(func(ctx context.Context, url string) (*r4pb.Bundle, error) {
+		return page(url, "", patient("2"), patient("9")), nil
+	})
+	got, err := Assemble(context.Background(), f, first, 0)
+	if err != nil {
+		t.Fatalf("Assemble() returned unexpected error: %v", err)
+	}
+	want := page(base+"Patient?_count=2", "", patient("1"), include, patient("2"))
+	want.Total = &d4pb.UnsignedInt{Value: 2}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Assemble() diff (-want +got):\n%s", diff)
+	}
+
+	first.Total = &d4pb.UnsignedInt{Value: 10}
+	got, err = Assemble(context.Background(), f, first, 3)
+	if err != nil {
+		t.Fatalf("Assemble() returned unexpected error: %v", err)
+	}
+	if got.GetTotal().GetValue() != 10 {
+		t.Errorf("Assemble() total = %d, want 10", got.GetTotal().GetValue())
+	}
+	if _, err := Assemble(context.Background(), f, first, 2); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Assemble() with max 2 returned %v, want %v", err, ErrTooLarge)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package r4builder provides fluent builders for FHIR R4 resources and data
+// types, generated from their protos:
+//
+//	p, err := r4builder.Patient().
+//		Id("p1").
+//		Name(r4builder.HumanName().Family("Doe").Given("Jane")).
+//		Gender(codes_go_proto.AdministrativeGenderCode_FEMALE).
+//		BirthDate("1970-05-17").
+//		DeceasedBoolean(false).
+//		Build()
+//
+// There is a builder per resource, backbone element and complex data type,
+// named after the struct protoc-gen-go generates for it without
+// underscores, i.e. PatientContact for Patient.contact. Its setters are named
+// after the fields of the proto and take Go values for primitives: strings
+// for strings, codes, URIs and decimals, dates and times in their FHIR
+// string form, i.e. "2024-03-01T10:30:00Z", and the enum of bound codes.
+// Complex elements take their builder, and resources, contained or in
+// bundles, any resource proto. Choice elements have a setter per type, i.e.
+// ValueQuantity and ValueString, and a Reference takes its literal reference
+// with ReferenceBuilder.Reference, i.e. "Patient/123". Setters of repeated
+// elements append to them.
+//
+// Setters never panic: an invalid value, such as a malformed date, is
+// recorded and returned by Build, which also validates the built proto with
+// fhirvalidate. A builder, and the builders passed to it, must not be used
+// once built.
+package r4builder
+
+//go:generate go run ./internal/gen -out builders.go
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat/fhirvalidate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// builder holds the first error of the setters of a builder.
+type builder struct {
+	err error
+}
+
+// fail records err, the error setting field, unless an error was recorded
+// already.
+func (b *builder) fail(field string, err error) {
+	if err != nil && b.err == nil {
+		b.err = fmt.Errorf("%s: %w", field, err)
+	}
+}
+
+// build returns m, validated, or the error recorded by b.
+func (b *builder) build(m proto.Message) error {
+	if b.err != nil {
+		return b.err
+	}
+	return fhirvalidate.Validate(m)
+}
+
+var decimalPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func decimal(v string) (*d4pb.Decimal, error) {
+	if !decimalPattern.MatchString(v) {
+		return nil, fmt.Errorf("invalid decimal %q", v)
+	}
+	return &d4pb.Decimal{Value: v}, nil
+}
+
+// Layouts of the FHIR dates and times.
+const (
+	layoutYear  = "2006"
+	layoutMonth = "2006-01"
+	layoutDay   = "2006-01-02"
+	layoutTime  = "15:04:05.999999"
+)
+
+func parseDate(v string) (*d4pb.Date, error) {
+	for _, l := range []struct {
+		layout    string
+		precision d4pb.Date_Precision
+	}{{layoutDay, d4pb.Date_DAY}, {layoutMonth, d4pb.Date_MONTH}, {layoutYear, d4pb.Date_YEAR}} {
+		if len(v) != len(l.layout) {
+			continue
+		}
+		t, err := time.Parse(l.layout, v)
+		if err != nil {
+			break
+		}
+		return &d4pb.Date{ValueUs: t.UnixMicro(), Timezone: "UTC", Precision: l.precision}, nil
+	}
+	return nil, fmt.Errorf("invalid date %q", v)
+}
+
+// parseInstantOf parses the full date and time v, returning its time zone,
+// as recorded in the protos, and the number of digits of its fraction of
+// second.
+func parseInstantOf(v string) (time.Time, string, int, error) {
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, "", 0, err
+	}
+	tz := "Z"
+	if !strings.HasSuffix(v, "Z") {
+		tz = v[len(v)-len("+00:00"):]
+	}
+	digits := 0
+	if i := strings.IndexByte(v, '.'); i >= 0 {
+		digits = len(v) - len(tz) - i - 1
+	}
+	if digits > 6 {
+		return time.Time{}, "", 0, fmt.Errorf("more than microsecond precision")
+	}
+	return t, tz, digits, nil
+}
+
+func parseDateTime(v string) (*d4pb.DateTime, error) {
+	if !strings.Contains(v, "T") {
+		d, err := parseDate(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dateTime %q", v)
+		}
+		return &d4pb.DateTime{ValueUs: d.ValueUs, Timezone: d.Timezone, Precision: d4pb.DateTime_Precision(d.Precision)}, nil
+	}
+	t, tz, digits, err := parseInstantOf(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dateTime %q", v)
+	}
+	p := d4pb.DateTime_SECOND
+	switch {
+	case digits > 3:
+		p = d4pb.DateTime_MICROSECOND
+	case digits > 0:
+		p = d4pb.DateTime_MILLISECOND
+	}
+	return &d4pb.DateTime{ValueUs: t.UnixMicro(), Timezone: tz, Precision: p}, nil
+}
+
+func parseInstant(v string) (*d4pb.Instant, error) {
+	t, tz, digits, err := parseInstantOf(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instant %q", v)
+	}
+	p := d4pb.Instant_SECOND
+	switch {
+	case digits > 3:
+		p = d4pb.Instant_MICROSECOND
+	case digits > 0:
+		p = d4pb.Instant_MILLISECOND
+	}
+	return &d4pb.Instant{ValueUs: t.UnixMicro(), Timezone: tz, Precision: p}, nil
+}
+
+func parseTime(v string) (*d4pb.Time, error) {
+	t, err := time.Parse(layoutTime, v)
+	if err != nil || len(v) > len(layoutTime) {
+		return nil, fmt.Errorf("invalid time %q", v)
+	}
+	p := d4pb.Time_SECOND
+	if i := strings.IndexByte(v, '.'); i >= 0 {
+		p = d4pb.Time_MILLISECOND
+		if len(v)-i-1 > 3 {
+			p = d4pb.Time_MICROSECOND
+		}
+	}
+	us := int64(t.Hour())*3600e6 + int64(t.Minute())*60e6 + int64(t.Second())*1e6 + int64(t.Nanosecond()/1e3)
+	return &d4pb.Time{ValueUs: us, Precision: p}, nil
+}
+
+func containedResource(res proto.Message) (*r4pb.ContainedResource, error) {
+	if res == nil {
+		return nil, fmt.Errorf("nil resource")
+	}
+	cr, err := elementpath.Convert(res, (&r4pb.ContainedResource{}).ProtoReflect().Descriptor())
+	if err != nil {
+		return nil, err
+	}
+	return cr.(*r4pb.ContainedResource), nil
+}
+
+func containedAny(res proto.Message) (*anypb.Any, error) {
+	cr, err := containedResource(res)
+	if err != nil {
+		return nil, err
+	}
+	return anypb.New(cr)
+}
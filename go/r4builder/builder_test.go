@@ -0,0 +1,214 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package r4builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestPatient(t *testing.T) {
+	got, err := Patient().
+		Id("p1").
+		Name(HumanName().Family("Doe").Given("Jane", "Q"), nil).
+		Gender(c4pb.AdministrativeGenderCode_FEMALE).
+		BirthDate("1970-05").
+		DeceasedBoolean(false).
+		ManagingOrganization(Reference().Reference("Organization/o1").Display("Acme")).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() returned unexpected error: %v", err)
+	}
+	want := &ppb.Patient{
+		Id: &d4pb.Id{Value: "p1"},
+		Name: []*d4pb.HumanName{{
+			Family: &d4pb.String{Value: "Doe"},
+			Given:  []*d4pb.String{{Value: "Jane"}, {Value: "Q"}},
+		}},
+		Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+		BirthDate: &d4pb.Date{ValueUs: 10368000000000, Timezone: "UTC", Precision: d4pb.Date_MONTH},
+		Deceased: &ppb.Patient_DeceasedX{
+			Choice: &ppb.Patient_DeceasedX_Boolean{Boolean: &d4pb.Boolean{Value: false}},
+		},
+		ManagingOrganization: &d4pb.Reference{
+			Reference: &d4pb.Reference_OrganizationId{OrganizationId: &d4pb.ReferenceId{Value: "o1"}},
+			Display:   &d4pb.String{Value: "Acme"},
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Build() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestObservation(t *testing.T) {
+	got, err := Observation().
+		Status(c4pb.ObservationStatusCode_FINAL).
+		Code(CodeableConcept().Coding(Coding().System("http://loinc.org").Code("8867-4"))).
+		Subject(Reference().Reference("Patient/p1")).
+		EffectiveDateTime("2024-03-01T10:30:00.5+01:00").
+		ValueQuantity(Quantity().Value("72.0").Unit("/min")).
+		Contained(&ppb.Patient{Id: &d4pb.Id{Value: "c1"}}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() returned unexpected error: %v", err)
+	}
+	contained, err := containedAny(&ppb.Patient{Id: &d4pb.Id{Value: "c1"}})
+	if err != nil {
+		t.Fatalf("containedAny() returned unexpected error: %v", err)
+	}
+	want := &obspb.Observation{
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: "http://loinc.org"},
+			Code:   &d4pb.Code{Value: "8867-4"},
+		}}},
+		Subject: &d4pb.Reference{
+			Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}},
+		},
+		Effective: &obspb.Observation_EffectiveX{
+			Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: &d4pb.DateTime{
+				ValueUs: 1709285400500000, Timezone: "+01:00", Precision: d4pb.DateTime_MILLISECOND,
+			}},
+		},
+		Value: &obspb.Observation_ValueX{
+			Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+				Value: &d4pb.Decimal{Value: "72.0"},
+				Unit:  &d4pb.String{Value: "/min"},
+			}},
+		},
+		Contained: []*anypb.Any{contained},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Build() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestBundleEntryResource(t *testing.T) {
+	got, err := BundleEntry().
+		FullUrl("urn:uuid:1").
+		Resource(&ppb.Patient{Id: &d4pb.Id{Value: "p1"}}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() returned unexpected error: %v", err)
+	}
+	want := &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{Id: &d4pb.Id{Value: "p1"}}}}
+	if diff := cmp.Diff(want, got.GetResource(), protocmp.Transform()); diff != "" {
+		t.Errorf("Build() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() (proto.Message, error)
+		wantErr string
+	}{
+		{
+			name: "invalid date",
+			build: func() (proto.Message, error) {
+				return Patient().BirthDate("1970-13-01").Build()
+			},
+			wantErr: "birthDate",
+		},
+		{
+			name: "invalid decimal",
+			build: func() (proto.Message, error) {
+				return Observation().
+					Status(c4pb.ObservationStatusCode_FINAL).
+					Code(CodeableConcept().Text("pulse")).
+					ValueQuantity(Quantity().Value("72,5")).
+					Build()
+			},
+			wantErr: "valueQuantity: value",
+		},
+		{
+			name: "nested error",
+			build: func() (proto.Message, error) {
+				return Patient().Contact(PatientContact().Period(Period().Start("noon"))).Build()
+			},
+			wantErr: "contact: period: start",
+		},
+		{
+			name: "missing required field",
+			build: func() (proto.Message, error) {
+				return Observation().Status(c4pb.ObservationStatusCode_FINAL).Build()
+			},
+		},
+		{
+			name: "wrong reference type",
+			build: func() (proto.Message, error) {
+				return Patient().ManagingOrganization(Reference().Reference("Patient/p2")).Build()
+			},
+		},
+		{
+			name: "not a resource",
+			build: func() (proto.Message, error) {
+				return BundleEntry().Resource(&d4pb.String{Value: "x"}).Build()
+			},
+			wantErr: "resource",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.build()
+			if err == nil {
+				t.Fatalf("Build() succeeded, want error")
+			}
+			if !strings.HasPrefix(err.Error(), tc.wantErr) {
+				t.Errorf("Build() returned error %q, want prefix %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseTemporals(t *testing.T) {
+	tests := []struct {
+		value string
+		parse func(string) (proto.Message, error)
+		want  proto.Message
+	}{
+		{"2024", func(v string) (proto.Message, error) { return parseDate(v) }, &d4pb.Date{ValueUs: 1704067200000000, Timezone: "UTC", Precision: d4pb.Date_YEAR}},
+		{"2024-03-01", func(v string) (proto.Message, error) { return parseDateTime(v) }, &d4pb.DateTime{ValueUs: 1709251200000000, Timezone: "UTC", Precision: d4pb.DateTime_DAY}},
+		{"2024-03-01T00:00:00Z", func(v string) (proto.Message, error) { return parseDateTime(v) }, &d4pb.DateTime{ValueUs: 1709251200000000, Timezone: "Z", Precision: d4pb.DateTime_SECOND}},
+		{"2024-03-01T00:00:00.123456-05:00", func(v string) (proto.Message, error) { return parseInstant(v) }, &d4pb.Instant{ValueUs: 1709269200123456, Timezone: "-05:00", Precision: d4pb.Instant_MICROSECOND}},
+		{"10:30:00.25", func(v string) (proto.Message, error) { return parseTime(v) }, &d4pb.Time{ValueUs: 37800250000, Precision: d4pb.Time_MILLISECOND}},
+	}
+	for _, tc := range tests {
+		got, err := tc.parse(tc.value)
+		if err != nil {
+			t.Errorf("parse(%q) returned unexpected error: %v", tc.value, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+			t.Errorf("parse(%q) diff (-want +got):\n%s", tc.value, diff)
+		}
+	}
+	for _, v := range []string{"2024-3-01", "2024-03-01T10:00", "2024-03-01T10:00:00", "2024-03-01T10:00:00.1234567Z"} {
+		if _, err := parseDateTime(v); err == nil {
+			t.Errorf("parseDateTime(%q) succeeded, want error", v)
+		}
+	}
+}
@@ -0,0 +1,498 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command gen generates the builders of package r4builder, for every R4
+// resource and the complex types and backbone elements they hold.
+//
+// Usage:
+//
+//	go run ./internal/gen -out builders.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// protoImport is imported by the setters of resources, which take any
+// resource proto.
+const protoImport = "google.golang.org/protobuf/proto"
+
+// fhirtypesImport is imported by the Reference setter of the Reference
+// builder, which parses literal references.
+const fhirtypesImport = "github.com/google/fhir/go/fhirtypes"
+
+var out = flag.String("out", "", "output file; standard output if empty")
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var roots []protoreflect.MessageDescriptor
+	fds := (&r4pb.ContainedResource{}).ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fds.Len(); i++ {
+		roots = append(roots, fds.Get(i).Message())
+	}
+	src, err := generate(roots)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0644)
+}
+
+type generator struct {
+	imports map[string]string // import path -> alias
+	aliases map[string]bool
+	names   map[string]protoreflect.FullName
+
+	builders []protoreflect.MessageDescriptor
+	seen     map[protoreflect.FullName]bool
+}
+
+// generate returns the formatted source of the builders of roots and the
+// messages they hold.
+func generate(roots []protoreflect.MessageDescriptor) ([]byte, error) {
+	g := &generator{
+		imports: map[string]string{},
+		aliases: map[string]bool{},
+		names:   map[string]protoreflect.FullName{},
+		seen:    map[protoreflect.FullName]bool{},
+	}
+	for _, md := range roots {
+		if err := g.add(md); err != nil {
+			return nil, err
+		}
+	}
+	var body bytes.Buffer
+	// Builders are discovered breadth first while emitting, so every
+	// referenced message gets one once the queue is drained.
+	for i := 0; i < len(g.builders); i++ {
+		if err := g.emitBuilder(&body, g.builders[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprint(&src, "// Code generated by r4builder/internal/gen. DO NOT EDIT.\n\npackage r4builder\n\nimport (\n")
+	for _, path := range g.sortedImports() {
+		fmt.Fprintf(&src, "\t%s %q\n", g.imports[path], path)
+	}
+	fmt.Fprint(&src, ")\n")
+	src.Write(body.Bytes())
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// hasBuilder reports whether fields of type md take a builder.
+func hasBuilder(md protoreflect.MessageDescriptor) bool {
+	return strings.HasPrefix(string(md.FullName()), "google.fhir.") &&
+		!elementpath.IsPrimitive(md) && !elementpath.IsChoice(md) && !elementpath.IsContainedResource(md)
+}
+
+// add records md as needing a builder.
+func (g *generator) add(md protoreflect.MessageDescriptor) error {
+	if g.seen[md.FullName()] {
+		return nil
+	}
+	g.seen[md.FullName()] = true
+	name := structName(md)
+	if other, ok := g.names[name]; ok {
+		return fmt.Errorf("%s and %s both map to builder %s", other, md.FullName(), name)
+	}
+	g.names[name] = md.FullName()
+	g.builders = append(g.builders, md)
+	return nil
+}
+
+// conversion converts the argument of a setter into a proto element.
+type conversion struct {
+	// arg is the Go type of the argument.
+	arg string
+	// expr returns the expression converting v, of type arg. It returns the
+	// element and an error if fallible, and the builder of the element if
+	// builder.
+	expr     func(v string) string
+	fallible bool
+	builder  bool
+}
+
+// conversion returns the conversion of the arguments of a setter of
+// elements of type md.
+func (g *generator) conversion(md protoreflect.MessageDescriptor) (conversion, error) {
+	switch {
+	case md.FullName() == "google.protobuf.Any":
+		g.imports[protoImport] = "proto"
+		return conversion{arg: "proto.Message", expr: call("containedAny"), fallible: true}, nil
+	case elementpath.IsContainedResource(md):
+		g.imports[protoImport] = "proto"
+		return conversion{arg: "proto.Message", expr: call("containedResource"), fallible: true}, nil
+	case hasBuilder(md):
+		if err := g.add(md); err != nil {
+			return conversion{}, err
+		}
+		return conversion{arg: "*" + structName(md) + "Builder", expr: func(v string) string { return v }, builder: true}, nil
+	case !elementpath.IsPrimitive(md):
+		return conversion{}, fmt.Errorf("no conversion for %s", md.FullName())
+	}
+	pt := g.protoType(md)
+	switch md.FullName() {
+	case "google.fhir.r4.core.Decimal":
+		return conversion{arg: "string", expr: call("decimal"), fallible: true}, nil
+	case "google.fhir.r4.core.Date":
+		return conversion{arg: "string", expr: call("parseDate"), fallible: true}, nil
+	case "google.fhir.r4.core.DateTime":
+		return conversion{arg: "string", expr: call("parseDateTime"), fallible: true}, nil
+	case "google.fhir.r4.core.Instant":
+		return conversion{arg: "string", expr: call("parseInstant"), fallible: true}, nil
+	case "google.fhir.r4.core.Time":
+		return conversion{arg: "string", expr: call("parseTime"), fallible: true}, nil
+	}
+	v := md.Fields().ByName("value")
+	if v == nil || v.Message() != nil || v.IsList() {
+		return conversion{}, fmt.Errorf("no conversion for primitive %s", md.FullName())
+	}
+	return conversion{
+		arg:  g.scalarType(v),
+		expr: func(x string) string { return "&" + pt + "{Value: " + x + "}" },
+	}, nil
+}
+
+func call(fn string) func(v string) string {
+	return func(v string) string { return fn + "(" + v + ")" }
+}
+
+// setter is a method of a builder setting a field.
+type setter struct {
+	name string
+	// path names the element in errors, i.e. "valueQuantity".
+	path string
+	doc  string
+	fd   protoreflect.FieldDescriptor
+	conv conversion
+	// wrap returns the expression assigned to the field for the element v,
+	// for the options of choice types.
+	wrap func(v string) string
+}
+
+func (g *generator) emitBuilder(w *bytes.Buffer, md protoreflect.MessageDescriptor) error {
+	name, pt := structName(md), g.protoType(md)
+	var setters []setter
+	methods := map[string]bool{"Build": true}
+	add := func(s setter) error {
+		if methods[s.name] {
+			return fmt.Errorf("%s: duplicate method %s", md.FullName(), s.name)
+		}
+		methods[s.name] = true
+		setters = append(setters, s)
+		return nil
+	}
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil {
+			return fmt.Errorf("field %s is not a message", fd.FullName())
+		}
+		if isReferenceOneof(fd) {
+			if fd.ContainingOneof().Fields().Get(0) == fd {
+				g.imports[fhirtypesImport] = "fhirtypes"
+				if err := add(setter{name: "Reference", path: "reference"}); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if fd.ContainingOneof() != nil {
+			return fmt.Errorf("oneof field %s is not supported", fd.FullName())
+		}
+		if !elementpath.IsChoice(fd.Message()) {
+			c, err := g.conversion(fd.Message())
+			if err != nil {
+				return fmt.Errorf("%s: %w", fd.FullName(), err)
+			}
+			verb := "sets"
+			if fd.IsList() {
+				verb = "appends to"
+			}
+			s := setter{
+				name: goCamelCase(string(fd.Name())),
+				path: fd.JSONName(),
+				doc:  fmt.Sprintf("%s %s.", verb, fd.JSONName()),
+				fd:   fd,
+				conv: c,
+				wrap: func(v string) string { return v },
+			}
+			if err := add(s); err != nil {
+				return err
+			}
+			continue
+		}
+		cmd := fd.Message()
+		cpt := g.protoType(cmd)
+		options := cmd.Fields()
+		for j := 0; j < options.Len(); j++ {
+			ofd := options.Get(j)
+			if ofd.Message() == nil {
+				return fmt.Errorf("choice option %s is not a message", ofd.FullName())
+			}
+			c, err := g.conversion(ofd.Message())
+			if err != nil {
+				return fmt.Errorf("%s: %w", ofd.FullName(), err)
+			}
+			typeName := string(ofd.Message().Name())
+			wrapper, field := g.oneofWrapper(ofd), fieldName(ofd)
+			oneof := goCamelCase(string(ofd.ContainingOneof().Name()))
+			s := setter{
+				name: goCamelCase(string(fd.Name())) + typeName,
+				path: fd.JSONName() + typeName,
+				doc:  fmt.Sprintf("sets %s to a %s.", fd.JSONName(), typeName),
+				fd:   fd,
+				conv: c,
+				wrap: func(v string) string {
+					return fmt.Sprintf("&%s{%s: &%s{%s: %s}}", cpt, oneof, wrapper, field, v)
+				},
+			}
+			if err := add(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n// %sBuilder builds %s protos.\ntype %[1]sBuilder struct {\n\tbuilder\n\tpb *%[3]s\n}\n", name, md.FullName(), pt)
+	fmt.Fprintf(w, "\n// %s returns an empty %[1]sBuilder.\nfunc %[1]s() *%[1]sBuilder {\n\treturn &%[1]sBuilder{pb: &%[2]s{}}\n}\n", name, pt)
+	fmt.Fprintf(w, "\n// Build returns the %s, or the first error of the setters or of its\n// validation.\n", md.Name())
+	fmt.Fprintf(w, "func (b *%sBuilder) Build() (*%s, error) {\n\tif err := b.build(b.pb); err != nil {\n\t\treturn nil, err\n\t}\n\treturn b.pb, nil\n}\n", name, pt)
+	for _, s := range setters {
+		emitSetter(w, name+"Builder", s)
+	}
+	return nil
+}
+
+func emitSetter(w *bytes.Buffer, recv string, s setter) {
+	if s.fd == nil {
+		fmt.Fprintf(w, `
+// Reference sets the literal reference, i.e. "Patient/123". Relative
+// references are stored in the typed id fields of the Reference.
+func (b *%s) Reference(ref string) *%[1]s {
+	r, err := fhirtypes.ReferenceFromURI(ref)
+	if err != nil {
+		b.fail("reference", err)
+		return b
+	}
+	b.pb.Reference = r.Reference
+	return b
+}
+`, recv)
+		return
+	}
+	field := fieldName(s.fd)
+	c := s.conv
+	fmt.Fprintf(w, "\n// %s %s\n", s.name, s.doc)
+	if !s.fd.IsList() {
+		fmt.Fprintf(w, "func (b *%s) %s(v %s) *%[1]s {\n", recv, s.name, c.arg)
+		switch {
+		case c.builder:
+			fmt.Fprintf(w, "if v != nil {\nb.fail(%q, v.err)\nb.pb.%s = %s\n}\n", s.path, field, s.wrap("v.pb"))
+		case c.fallible:
+			fmt.Fprintf(w, "x, err := %s\nif err != nil {\nb.fail(%q, err)\nreturn b\n}\nb.pb.%s = %s\n", c.expr("v"), s.path, field, s.wrap("x"))
+		default:
+			fmt.Fprintf(w, "b.pb.%s = %s\n", field, s.wrap(c.expr("v")))
+		}
+		fmt.Fprint(w, "return b\n}\n")
+		return
+	}
+	fmt.Fprintf(w, "func (b *%s) %s(v ...%s) *%[1]s {\nfor _, x := range v {\n", recv, s.name, c.arg)
+	switch {
+	case c.builder:
+		fmt.Fprintf(w, "if x == nil {\ncontinue\n}\nb.fail(%q, x.err)\nb.pb.%s = append(b.pb.%[2]s, %s)\n", s.path, field, s.wrap("x.pb"))
+	case c.fallible:
+		fmt.Fprintf(w, "y, err := %s\nif err != nil {\nb.fail(%q, err)\ncontinue\n}\nb.pb.%s = append(b.pb.%[3]s, %s)\n", c.expr("x"), s.path, field, s.wrap("y"))
+	default:
+		fmt.Fprintf(w, "b.pb.%s = append(b.pb.%[1]s, %s)\n", field, s.wrap(c.expr("x")))
+	}
+	fmt.Fprint(w, "}\nreturn b\n}\n")
+}
+
+// isReferenceOneof reports whether fd is part of the oneof holding the
+// literal reference of a Reference, which is set with a single string.
+func isReferenceOneof(fd protoreflect.FieldDescriptor) bool {
+	od := fd.ContainingOneof()
+	return od != nil && od.Name() == "reference" && fd.ContainingMessage().Name() == "Reference"
+}
+
+// goIdent returns the name protoc-gen-go gives the message or enum d.
+func goIdent(d protoreflect.Descriptor) string {
+	name := string(d.Name())
+	for p := d.Parent(); p != nil; p = p.Parent() {
+		if _, ok := p.(protoreflect.FileDescriptor); ok {
+			break
+		}
+		name = string(p.Name()) + "_" + name
+	}
+	return name
+}
+
+// structName returns the name of the builder of md, without its Builder
+// suffix.
+func structName(md protoreflect.MessageDescriptor) string {
+	return strings.ReplaceAll(goIdent(md), "_", "")
+}
+
+// reservedNames are the method names protoc-gen-go avoids for fields.
+var reservedNames = map[string]bool{
+	"Reset":               true,
+	"String":              true,
+	"ProtoMessage":        true,
+	"Marshal":             true,
+	"Unmarshal":           true,
+	"ExtensionRangeArray": true,
+	"ExtensionMap":        true,
+	"Descriptor":          true,
+}
+
+// fieldName returns the Go name protoc-gen-go gives the field fd.
+func fieldName(fd protoreflect.FieldDescriptor) string {
+	n := goCamelCase(string(fd.Name()))
+	if reservedNames[n] {
+		n += "_"
+	}
+	return n
+}
+
+// oneofWrapper returns the qualified name of the oneof wrapper type
+// protoc-gen-go generates for fd.
+func (g *generator) oneofWrapper(fd protoreflect.FieldDescriptor) string {
+	md := fd.ContainingMessage()
+	suffix := goCamelCase(string(fd.Name()))
+	if md.Messages().ByName(protoreflect.Name(suffix)) != nil || md.Enums().ByName(protoreflect.Name(suffix)) != nil {
+		suffix += "_"
+	}
+	return g.qualify(md) + "." + goIdent(md) + "_" + suffix
+}
+
+// qualify returns the package alias of the Go package defining d, importing
+// it if necessary.
+func (g *generator) qualify(d protoreflect.Descriptor) string {
+	path := d.ParentFile().Options().(*descriptorpb.FileOptions).GetGoPackage()
+	if i := strings.Index(path, ";"); i >= 0 {
+		path = path[:i]
+	}
+	if alias, ok := g.imports[path]; ok {
+		return alias
+	}
+	base := path[strings.LastIndex(path, "/")+1:]
+	base = strings.TrimSuffix(base, "_go_proto")
+	base = strings.ReplaceAll(base, "_", "") + "pb"
+	alias := base
+	for n := 2; g.aliases[alias]; n++ {
+		alias = fmt.Sprintf("%s%d", base, n)
+	}
+	g.aliases[alias] = true
+	g.imports[path] = alias
+	return alias
+}
+
+// protoType returns the qualified Go type of the message md.
+func (g *generator) protoType(md protoreflect.MessageDescriptor) string {
+	return g.qualify(md) + "." + goIdent(md)
+}
+
+// scalarType returns the Go type of the value field v of a primitive.
+func (g *generator) scalarType(v protoreflect.FieldDescriptor) string {
+	switch v.Kind() {
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.BytesKind:
+		return "[]byte"
+	case protoreflect.EnumKind:
+		return g.qualify(v.Enum()) + "." + goIdent(v.Enum())
+	}
+	return "string"
+}
+
+// sortedImports returns the import paths in a stable order.
+func (g *generator) sortedImports() []string {
+	var paths []string
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// goCamelCase converts a proto field name to its Go name the way
+// protoc-gen-go does.
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool { return 'a' <= c && c <= 'z' }
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
@@ -0,0 +1,223 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package results assembles R4 laboratory and diagnostic results into a
+// tree, as results are rendered: the ServiceRequests that ordered them, the
+// DiagnosticReports that answer the requests, and the Observations the
+// reports contain, with panels holding their member Observations.
+//
+// Reports belong to the requests of their basedOn element and contain the
+// Observations of their result element. Observations belong to the panels
+// whose hasMember element refers to them; those in no report or panel
+// belong to the requests of their own basedOn element. Only literal
+// references to resources among those assembled are followed.
+package results
+
+import (
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	drpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/diagnostic_report_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	srpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/service_request_go_proto"
+)
+
+// Tree is the tree of results.
+type Tree struct {
+	// Orders are the ServiceRequests, in the order given.
+	Orders []*Order
+	// Unrequested are the DiagnosticReports based on none of the
+	// ServiceRequests.
+	Unrequested []*Report
+	// Unreported are the Observations in no DiagnosticReport or panel and
+	// based on none of the ServiceRequests.
+	Unreported []*Result
+}
+
+// Order is a ServiceRequest and its results.
+type Order struct {
+	Request *srpb.ServiceRequest
+	// Reports are the DiagnosticReports based on the request.
+	Reports []*Report
+	// Results are the Observations based on the request that are in no
+	// DiagnosticReport or panel.
+	Results []*Result
+}
+
+// Report is a DiagnosticReport and its results.
+type Report struct {
+	Report *drpb.DiagnosticReport
+	// Results are the Observations of the result element of the report,
+	// in its order.
+	Results []*Result
+	// Missing are the references of the result element that are not to
+	// any of the Observations.
+	Missing []string
+}
+
+// Result is an Observation and, for panels, its members.
+type Result struct {
+	Observation *obspb.Observation
+	// Members are the Observations of the hasMember element of a panel,
+	// in its order.
+	Members []*Result
+	// Missing are the references of the hasMember element that are not to
+	// any of the Observations.
+	Missing []string
+}
+
+// Panel reports whether r is a panel, grouping other results.
+func (r *Result) Panel() bool {
+	return len(r.Members) > 0 || len(r.Missing) > 0
+}
+
+// Walk calls fn for r and, depth first, the members of r.
+func (r *Result) Walk(fn func(*Result)) {
+	fn(r)
+	for _, m := range r.Members {
+		m.Walk(fn)
+	}
+}
+
+// Assemble returns the tree of the ServiceRequests, DiagnosticReports and
+// Observations among resources; other resources are ignored. An
+// Observation reached from several reports or panels appears under each.
+func Assemble(resources []*r4pb.ContainedResource) *Tree {
+	a := &assembly{
+		orders:       map[string]*Order{},
+		observations: map[string]*obspb.Observation{},
+		contained:    map[*obspb.Observation]bool{},
+	}
+	var reports []*drpb.DiagnosticReport
+	var observations []*obspb.Observation
+	t := &Tree{}
+	for _, cr := range resources {
+		switch {
+		case cr.GetServiceRequest() != nil:
+			o := &Order{Request: cr.GetServiceRequest()}
+			t.Orders = append(t.Orders, o)
+			if k := key(o.Request); k != "" {
+				a.orders[k] = o
+			}
+		case cr.GetDiagnosticReport() != nil:
+			reports = append(reports, cr.GetDiagnosticReport())
+		case cr.GetObservation() != nil:
+			obs := cr.GetObservation()
+			observations = append(observations, obs)
+			if k := key(obs); k != "" {
+				a.observations[k] = obs
+			}
+		}
+	}
+	for _, obs := range observations {
+		for _, ref := range obs.GetHasMember() {
+			if m, ok := a.observations[target(ref)]; ok {
+				a.contained[m] = true
+			}
+		}
+	}
+	for _, dr := range reports {
+		r := &Report{Report: dr}
+		for _, ref := range dr.GetResult() {
+			obs, ok := a.observations[target(ref)]
+			if !ok {
+				r.Missing = append(r.Missing, fhirtypes.ReferenceURI(ref))
+				continue
+			}
+			a.contained[obs] = true
+			r.Results = append(r.Results, a.result(obs, map[*obspb.Observation]bool{}))
+		}
+		if orders := a.basedOn(dr.GetBasedOn()); len(orders) > 0 {
+			for _, o := range orders {
+				o.Reports = append(o.Reports, r)
+			}
+		} else {
+			t.Unrequested = append(t.Unrequested, r)
+		}
+	}
+	for _, obs := range observations {
+		if a.contained[obs] {
+			continue
+		}
+		r := a.result(obs, map[*obspb.Observation]bool{})
+		if orders := a.basedOn(obs.GetBasedOn()); len(orders) > 0 {
+			for _, o := range orders {
+				o.Results = append(o.Results, r)
+			}
+		} else {
+			t.Unreported = append(t.Unreported, r)
+		}
+	}
+	return t
+}
+
+type assembly struct {
+	orders       map[string]*Order
+	observations map[string]*obspb.Observation
+	// contained are the Observations in a report or panel.
+	contained map[*obspb.Observation]bool
+}
+
+// result returns the Result of obs, with the members of panels. path holds
+// the panels being assembled, so that cyclic panels terminate.
+func (a *assembly) result(obs *obspb.Observation, path map[*obspb.Observation]bool) *Result {
+	r := &Result{Observation: obs}
+	path[obs] = true
+	defer delete(path, obs)
+	for _, ref := range obs.GetHasMember() {
+		m, ok := a.observations[target(ref)]
+		if !ok {
+			r.Missing = append(r.Missing, fhirtypes.ReferenceURI(ref))
+			continue
+		}
+		if path[m] {
+			continue
+		}
+		r.Members = append(r.Members, a.result(m, path))
+	}
+	return r
+}
+
+// basedOn returns the Orders of the ServiceRequests refs refer to.
+func (a *assembly) basedOn(refs []*d4pb.Reference) []*Order {
+	var out []*Order
+	for _, ref := range refs {
+		if o, ok := a.orders[target(ref)]; ok {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// key returns "Type/id" for res.
+func key(res proto.Message) string {
+	id := elementpath.ID(res)
+	if id == "" {
+		return ""
+	}
+	return elementpath.ResourceType(res) + "/" + id
+}
+
+// target returns "Type/id" for the resource ref refers to, or "" for
+// references that are not literal references to a resource by type and id.
+func target(ref *d4pb.Reference) string {
+	p, err := fhirtypes.ParseReference(fhirtypes.ReferenceURI(ref))
+	if err != nil || p.Type == "" {
+		return ""
+	}
+	return p.Type + "/" + p.ID
+}
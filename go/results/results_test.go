@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	drpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/diagnostic_report_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	srpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/service_request_go_proto"
+)
+
+func refs(targets ...string) []*d4pb.Reference {
+	var out []*d4pb.Reference
+	for _, t := range targets {
+		out = append(out, &d4pb.Reference{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: t}}})
+	}
+	return out
+}
+
+func request(id string) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_ServiceRequest{ServiceRequest: &srpb.ServiceRequest{Id: &d4pb.Id{Value: id}}}}
+}
+
+func report(id string, basedOn []string, results ...string) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_DiagnosticReport{DiagnosticReport: &drpb.DiagnosticReport{
+		Id:      &d4pb.Id{Value: id},
+		BasedOn: refs(basedOn...),
+		Result:  refs(results...),
+	}}}
+}
+
+func observation(id string, basedOn []string, members ...string) *r4pb.ContainedResource {
+	return &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: &obspb.Observation{
+		Id:        &d4pb.Id{Value: id},
+		BasedOn:   refs(basedOn...),
+		HasMember: refs(members...),
+	}}}
+}
+
+// format renders t as indented lines.
+func format(t *Tree) []string {
+	var out []string
+	var result func(r *Result, indent string)
+	result = func(r *Result, indent string) {
+		line := indent + "obs " + r.Observation.GetId().GetValue()
+		if len(r.Missing) > 0 {
+			line += fmt.Sprintf(" missing %v", r.Missing)
+		}
+		out = append(out, line)
+		for _, m := range r.Members {
+			result(m, indent+"  ")
+		}
+	}
+	rep := func(r *Report, indent string) {
+		line := indent + "report " + r.Report.GetId().GetValue()
+		if len(r.Missing) > 0 {
+			line += fmt.Sprintf(" missing %v", r.Missing)
+		}
+		out = append(out, line)
+		for _, res := range r.Results {
+			result(res, indent+"  ")
+		}
+	}
+	for _, o := range t.Orders {
+		out = append(out, "order "+o.Request.GetId().GetValue())
+		for _, r := range o.Reports {
+			rep(r, "  ")
+		}
+		for _, r := range o.Results {
+			result(r, "  ")
+		}
+	}
+	for _, r := range t.Unrequested {
+		rep(r, "unrequested ")
+	}
+	for _, r := range t.Unreported {
+		result(r, "unreported ")
+	}
+	return out
+}
+
+func TestAssemble(t *testing.T) {
+	resources := []*r4pb.ContainedResource{
+		{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{Id: &d4pb.Id{Value: "p1"}}}},
+		request("cbc"),
+		request("bmp"),
+		request("a1c"),
+		report("cbc-report", []string{"ServiceRequest/cbc"}, "Observation/cbc-panel", "Observation/gone"),
+		report("outside", nil, "Observation/glucose"),
+		observation("cbc-panel", nil, "Observation/hgb", "Observation/wbc", "Observation/diff"),
+		observation("hgb", []string{"ServiceRequest/cbc"}),
+		observation("wbc", nil),
+		observation("diff", nil, "Observation/neut", "Observation/cbc-panel"),
+		observation("neut", nil),
+		observation("a1c-result", []string{"ServiceRequest/a1c"}),
+		observation("glucose", nil),
+		observation("weight", nil),
+	}
+	want := []string{
+		"order cbc",
+		"  report cbc-report missing [Observation/gone]",
+		"    obs cbc-panel",
+		"      obs hgb",
+		"      obs wbc",
+		"      obs diff",
+		"        obs neut",
+		"order bmp",
+		"order a1c",
+		"  obs a1c-result",
+		"unrequested report outside",
+		"unrequested   obs glucose",
+		"unreported obs weight",
+	}
+	got := format(Assemble(resources))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Assemble() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestResult_Walk(t *testing.T) {
+	tree := Assemble([]*r4pb.ContainedResource{
+		observation("panel", nil, "Observation/a", "Observation/sub"),
+		observation("a", nil),
+		observation("sub", nil, "Observation/b"),
+		observation("b", nil),
+	})
+	if len(tree.Unreported) != 1 {
+		t.Fatalf("Assemble() returned %d unreported results, want 1", len(tree.Unreported))
+	}
+	var ids []string
+	tree.Unreported[0].Walk(func(r *Result) {
+		id := r.Observation.GetId().GetValue()
+		if r.Panel() {
+			id = strings.ToUpper(id)
+		}
+		ids = append(ids, id)
+	})
+	if diff := cmp.Diff([]string{"PANEL", "a", "SUB", "b"}, ids); diff != "" {
+		t.Errorf("Walk() diff (-want +got):\n%s", diff)
+	}
+}
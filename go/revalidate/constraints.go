@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revalidate
+
+import (
+	"fmt"
+
+	"github.com/google/fhir/go/dedupe"
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/jsonformat/fhirvalidate"
+	"google.golang.org/protobuf/proto"
+)
+
+// Invariant returns a constraint evaluating the FHIRPath expression expr
+// against resources, which are reported with the message human if it is not
+// true. paths are the elements expr reads, i.e. "Observation.value" and
+// "Observation.dataAbsentReason" for obs-6.
+func Invariant(key, expr string, severity errorreporter.IssueSeverityCode, human string, paths ...string) (Constraint, error) {
+	e, err := fhirpath.Compile(expr)
+	if err != nil {
+		return Constraint{}, fmt.Errorf("constraint %q: %w", key, err)
+	}
+	return Constraint{
+		Key:   key,
+		Paths: paths,
+		Check: func(res proto.Message) ([]Issue, error) {
+			ok, err := e.EvaluateBool(res)
+			if err != nil || ok {
+				return nil, err
+			}
+			return []Issue{{
+				Path:     elementpath.ResourceType(res),
+				Severity: severity,
+				Message:  human,
+			}}, nil
+		},
+	}, nil
+}
+
+// Structure returns a constraint checking the primitive values, required
+// elements and reference types of resources with fhirvalidate. It depends on
+// the whole resource.
+func Structure(key string) Constraint {
+	return Constraint{
+		Key: key,
+		Check: func(res proto.Message) ([]Issue, error) {
+			var r issueReporter
+			if err := fhirvalidate.ValidateWithErrorReporter(res, &r); err != nil {
+				return nil, err
+			}
+			return r.issues, nil
+		},
+	}
+}
+
+// Duplicates returns a constraint reporting the duplicate entries of the
+// repeated elements of resources, as found by dedupe.Find, with the given
+// severity. It depends on the whole resource.
+func Duplicates(key string, severity errorreporter.IssueSeverityCode) Constraint {
+	return Constraint{
+		Key: key,
+		Check: func(res proto.Message) ([]Issue, error) {
+			var issues []Issue
+			for _, d := range dedupe.Find(res) {
+				issues = append(issues, Issue{Path: d.Path, Severity: severity, Message: "duplicate of " + d.Of})
+			}
+			return issues, nil
+		},
+	}
+}
+
+// issueReporter is an ErrorReporter collecting issues.
+type issueReporter struct {
+	issues []Issue
+}
+
+func (r *issueReporter) ReportValidationError(elementPath string, err error) error {
+	r.issues = append(r.issues, Issue{Path: elementPath, Severity: errorreporter.IssueSeverityError, Message: err.Error()})
+	return nil
+}
+
+func (r *issueReporter) ReportValidationWarning(elementPath string, err error) error {
+	r.issues = append(r.issues, Issue{Path: elementPath, Severity: errorreporter.IssueSeverityWarning, Message: err.Error()})
+	return nil
+}
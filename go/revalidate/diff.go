@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revalidate
+
+import (
+	"fmt"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Diff returns the paths of the elements that differ between old and new,
+// two versions of a resource, i.e. "Patient.name[0].given[1]". Primitive
+// elements, contained resources and elements set in only one version are
+// reported as a whole; elements appended to or removed from the end of a
+// list are reported by index. Choice elements are reported by their base
+// name. If old and new are resources of different types, the only path is
+// the type of new.
+func Diff(old, new proto.Message) []string {
+	old, new = elementpath.Unwrap(old), elementpath.Unwrap(new)
+	if new == nil {
+		if old == nil {
+			return nil
+		}
+		return []string{string(old.ProtoReflect().Descriptor().Name())}
+	}
+	rt := string(new.ProtoReflect().Descriptor().Name())
+	if old == nil || old.ProtoReflect().Descriptor() != new.ProtoReflect().Descriptor() {
+		return []string{rt}
+	}
+	var paths []string
+	diffMessage(old.ProtoReflect(), new.ProtoReflect(), rt, &paths)
+	return paths
+}
+
+func diffMessage(a, b protoreflect.Message, path string, paths *[]string) {
+	fields := a.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !a.Has(fd) && !b.Has(fd) {
+			continue
+		}
+		fieldPath := path + "." + fd.JSONName()
+		if !fd.IsList() {
+			diffValue(a.Get(fd).Message(), b.Get(fd).Message(), fieldPath, paths)
+			continue
+		}
+		la, lb := a.Get(fd).List(), b.Get(fd).List()
+		n := la.Len()
+		if lb.Len() > n {
+			n = lb.Len()
+		}
+		for j := 0; j < n; j++ {
+			elemPath := fmt.Sprintf("%s[%d]", fieldPath, j)
+			if j >= la.Len() || j >= lb.Len() {
+				*paths = append(*paths, elemPath)
+				continue
+			}
+			diffValue(la.Get(j).Message(), lb.Get(j).Message(), elemPath, paths)
+		}
+	}
+}
+
+func diffValue(a, b protoreflect.Message, path string, paths *[]string) {
+	if !a.IsValid() || !b.IsValid() {
+		*paths = append(*paths, path)
+		return
+	}
+	d := a.Descriptor()
+	switch {
+	case isLeaf(d):
+		if !proto.Equal(a.Interface(), b.Interface()) {
+			*paths = append(*paths, path)
+		}
+	case elementpath.IsChoice(d):
+		od := d.Oneofs().Get(0)
+		fa, fb := a.WhichOneof(od), b.WhichOneof(od)
+		if fa != fb {
+			*paths = append(*paths, path)
+			return
+		}
+		if fa != nil {
+			diffValue(a.Get(fa).Message(), b.Get(fb).Message(), path, paths)
+		}
+	default:
+		diffMessage(a, b, path, paths)
+	}
+}
+
+// isLeaf tells whether elements of type d are compared as a whole: the
+// primitives, including the enum-valued codes, and the nested resources.
+func isLeaf(d protoreflect.MessageDescriptor) bool {
+	if elementpath.IsPrimitive(d) || elementpath.IsContainedResource(d) || d.FullName() == "google.protobuf.Any" {
+		return true
+	}
+	fields := d.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if fields.Get(i).Message() == nil {
+			return true
+		}
+	}
+	return false
+}
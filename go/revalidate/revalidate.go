@@ -0,0 +1,238 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package revalidate validates updated FHIR resources incrementally.
+//
+// A Validator checks resources against a list of constraints, each declaring
+// the element paths its outcome depends on. When a resource is updated,
+// Revalidate takes the Result of the previous version and the paths of the
+// elements that changed, as returned by Diff, and evaluates again only the
+// constraints that depend on them. The issues of the other constraints are
+// carried over, so expensive terminology and profile checks are not repeated
+// for elements that did not change.
+//
+// Paths follow the conventions of FHIRPath element paths: they start with the
+// resource type and name elements by their JSON names, i.e.
+// "Observation.code.coding". Choice elements may be named by their base name
+// ("Observation.value" or "Observation.value[x]") or by a typed name
+// ("Observation.valueQuantity"); indices are ignored when matching.
+package revalidate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/fhirerrors"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"google.golang.org/protobuf/proto"
+)
+
+// Issue is a problem found by a constraint.
+type Issue struct {
+	// Constraint is the key of the constraint that found the issue. It is set
+	// by the Validator.
+	Constraint string
+	// Path is the location of the problem, i.e. "Observation.code.coding[0]".
+	Path     string
+	Severity errorreporter.IssueSeverityCode
+	Message  string
+}
+
+// CheckFunc returns the issues of the resource res.
+type CheckFunc func(res proto.Message) ([]Issue, error)
+
+// Constraint is a check of resources along with the elements it depends on.
+type Constraint struct {
+	// Key identifies the constraint, i.e. "obs-6". Keys must be unique within
+	// a Validator.
+	Key string
+	// Paths are the paths of the elements the outcome of Check depends on.
+	// A path covers the elements nested in it. A constraint without paths
+	// depends on the whole resource and is evaluated on every update.
+	Paths []string
+	// Check evaluates the constraint.
+	Check CheckFunc
+}
+
+// Result is the outcome of a validation, which is the base of the
+// revalidation of the next version of the resource.
+type Result struct {
+	// Issues are the issues found, in the order of the constraints of the
+	// Validator.
+	Issues []Issue
+	// Evaluated are the keys of the constraints evaluated for this result;
+	// the issues of the others were carried over from the previous result.
+	Evaluated []string
+
+	byKey map[string][]Issue
+}
+
+// Valid tells whether r has no issues of error severity.
+func (r *Result) Valid() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == errorreporter.IssueSeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns an error listing the issues of error severity of r, which
+// matches fhirerrors.ErrConstraintViolation, or nil if r is valid.
+func (r *Result) Err() error {
+	var msgs []string
+	for _, issue := range r.Issues {
+		if issue.Severity == errorreporter.IssueSeverityError {
+			msgs = append(msgs, fmt.Sprintf("%s at %s: %s", issue.Constraint, issue.Path, issue.Message))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", fhirerrors.ErrConstraintViolation, strings.Join(msgs, "; "))
+}
+
+// Validator checks resources against constraints.
+type Validator struct {
+	constraints []Constraint
+	// paths are the split paths of the constraints.
+	paths [][][]string
+}
+
+// NewValidator returns a Validator of constraints.
+func NewValidator(constraints ...Constraint) (*Validator, error) {
+	v := &Validator{}
+	keys := map[string]bool{}
+	for _, c := range constraints {
+		if c.Key == "" {
+			return nil, errors.New("constraint without key")
+		}
+		if keys[c.Key] {
+			return nil, fmt.Errorf("duplicate constraint %q", c.Key)
+		}
+		keys[c.Key] = true
+		if c.Check == nil {
+			return nil, fmt.Errorf("constraint %q has no check", c.Key)
+		}
+		var paths [][]string
+		for _, p := range c.Paths {
+			segs, err := splitPath(p)
+			if err != nil {
+				return nil, fmt.Errorf("constraint %q: %w", c.Key, err)
+			}
+			paths = append(paths, segs)
+		}
+		v.constraints = append(v.constraints, c)
+		v.paths = append(v.paths, paths)
+	}
+	return v, nil
+}
+
+// Validate evaluates all constraints against res.
+func (v *Validator) Validate(res proto.Message) (*Result, error) {
+	return v.Revalidate(nil, nil, res)
+}
+
+// Revalidate evaluates against res, an updated version of the resource of
+// prev, the constraints that depend on the elements at the paths changed, and
+// carries over the issues of prev for the others. Constraints without paths,
+// and those prev has no outcome for, are always evaluated. If prev is nil all
+// constraints are evaluated.
+func (v *Validator) Revalidate(prev *Result, changed []string, res proto.Message) (*Result, error) {
+	var changedSegs [][]string
+	for _, p := range changed {
+		segs, err := splitPath(p)
+		if err != nil {
+			return nil, err
+		}
+		changedSegs = append(changedSegs, segs)
+	}
+	out := &Result{byKey: map[string][]Issue{}}
+	for i, c := range v.constraints {
+		issues, ok := []Issue(nil), false
+		if prev != nil && len(v.paths[i]) > 0 && !dependsOn(v.paths[i], changedSegs) {
+			issues, ok = prev.byKey[c.Key]
+		}
+		if !ok {
+			var err error
+			if issues, err = c.Check(res); err != nil {
+				return nil, fmt.Errorf("evaluating constraint %q: %w", c.Key, err)
+			}
+			for j := range issues {
+				issues[j].Constraint = c.Key
+			}
+			out.Evaluated = append(out.Evaluated, c.Key)
+		}
+		out.byKey[c.Key] = issues
+		out.Issues = append(out.Issues, issues...)
+	}
+	return out, nil
+}
+
+// splitPath returns the element names of path, without their indices.
+func splitPath(path string) ([]string, error) {
+	segs := strings.Split(path, ".")
+	for i, s := range segs {
+		if j := strings.IndexByte(s, '['); j >= 0 {
+			s = s[:j]
+		}
+		if s == "" {
+			return nil, fmt.Errorf("invalid element path %q", path)
+		}
+		segs[i] = s
+	}
+	return segs, nil
+}
+
+// dependsOn tells whether any of the paths of a constraint is changed, that
+// is, whether one of the changed paths is nested in it or contains it.
+func dependsOn(paths, changed [][]string) bool {
+	for _, p := range paths {
+		for _, c := range changed {
+			if overlap(p, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func overlap(a, b []string) bool {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n > 0 && a[0] != b[0] {
+		return false
+	}
+	for i := 1; i < n; i++ {
+		if !sameElement(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameElement tells whether the element names a and b may name the same
+// element, matching the base name of a choice element with its typed names.
+func sameElement(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return strings.HasPrefix(b, a) && b[len(a)] >= 'A' && b[len(a)] <= 'Z'
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revalidate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/fhir/go/fhirerrors"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+)
+
+func testObservation() *obspb.Observation {
+	return &obspb.Observation{
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: "http://loinc.org"},
+			Code:   &d4pb.Code{Value: "2345-7"},
+		}}},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+			Value: &d4pb.Decimal{Value: "98.50"},
+			Code:  &d4pb.Code{Value: "mg/dL"},
+		}}},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(*obspb.Observation)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			update: func(*obspb.Observation) {},
+		},
+		{
+			name:   "primitive",
+			update: func(o *obspb.Observation) { o.Code.Coding[0].Code.Value = "2339-0" },
+			want:   []string{"Observation.code.coding[0].code"},
+		},
+		{
+			name: "appended",
+			update: func(o *obspb.Observation) {
+				o.Code.Coding = append(o.Code.Coding, &d4pb.Coding{Code: &d4pb.Code{Value: "x"}})
+			},
+			want: []string{"Observation.code.coding[1]"},
+		},
+		{
+			name:   "added",
+			update: func(o *obspb.Observation) { o.Id = &d4pb.Id{Value: "o1"} },
+			want:   []string{"Observation.id"},
+		},
+		{
+			name:   "removed",
+			update: func(o *obspb.Observation) { o.Subject = nil },
+			want:   []string{"Observation.subject"},
+		},
+		{
+			name:   "code",
+			update: func(o *obspb.Observation) { o.Status.Value = c4pb.ObservationStatusCode_AMENDED },
+			want:   []string{"Observation.status"},
+		},
+		{
+			name: "reference",
+			update: func(o *obspb.Observation) {
+				o.Subject.Reference = &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p2"}}
+			},
+			want: []string{"Observation.subject.patientId"},
+		},
+		{
+			name:   "choice value",
+			update: func(o *obspb.Observation) { o.GetValue().GetQuantity().Value.Value = "99" },
+			want:   []string{"Observation.value.value"},
+		},
+		{
+			name: "choice type",
+			update: func(o *obspb.Observation) {
+				o.Value.Choice = &obspb.Observation_ValueX_StringValue{StringValue: &d4pb.String{Value: "high"}}
+			},
+			want: []string{"Observation.value"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			old := testObservation()
+			updated := proto.Clone(old).(*obspb.Observation)
+			test.update(updated)
+			if diff := cmp.Diff(test.want, Diff(old, updated), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Diff() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRevalidate(t *testing.T) {
+	evaluated := map[string]int{}
+	counted := func(c Constraint) Constraint {
+		check := c.Check
+		c.Check = func(res proto.Message) ([]Issue, error) {
+			evaluated[c.Key]++
+			return check(res)
+		}
+		return c
+	}
+	obs6, err := Invariant("obs-6", "dataAbsentReason.empty() or value.empty()", errorreporter.IssueSeverityError,
+		"dataAbsentReason SHALL only be present if Observation.value[x] is not present",
+		"Observation.value[x]", "Observation.dataAbsentReason")
+	if err != nil {
+		t.Fatalf("Invariant() returned unexpected error: %v", err)
+	}
+	loinc, err := Invariant("loinc", "code.coding.where(system = %loinc).exists()", errorreporter.IssueSeverityWarning,
+		"code should have a LOINC coding", "Observation.code.coding.system")
+	if err != nil {
+		t.Fatalf("Invariant() returned unexpected error: %v", err)
+	}
+	v, err := NewValidator(counted(obs6), counted(loinc), counted(Structure("structure")))
+	if err != nil {
+		t.Fatalf("NewValidator() returned unexpected error: %v", err)
+	}
+
+	obs := testObservation()
+	res, err := v.Validate(obs)
+	if err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+	if !res.Valid() || len(res.Issues) != 0 || res.Err() != nil {
+		t.Fatalf("Validate() returned issues %v, want none", res.Issues)
+	}
+
+	steps := []struct {
+		name          string
+		update        func(*obspb.Observation)
+		wantEvaluated []string
+		wantIssues    []string
+	}{
+		{
+			name:          "unrelated element",
+			update:        func(o *obspb.Observation) { o.Subject.GetPatientId().Value = "p2" },
+			wantEvaluated: []string{"structure"},
+		},
+		{
+			name: "choice element",
+			update: func(o *obspb.Observation) {
+				o.DataAbsentReason = &d4pb.CodeableConcept{Text: &d4pb.String{Value: "unknown"}}
+			},
+			wantEvaluated: []string{"obs-6", "structure"},
+			wantIssues:    []string{"obs-6"},
+		},
+		{
+			name:          "nested element",
+			update:        func(o *obspb.Observation) { o.Code.Coding[0].System.Value = "http://snomed.info/sct" },
+			wantEvaluated: []string{"loinc", "structure"},
+			wantIssues:    []string{"obs-6", "loinc"},
+		},
+		{
+			name:          "parent element",
+			update:        func(o *obspb.Observation) { o.Code = &d4pb.CodeableConcept{Text: &d4pb.String{Value: "glucose"}} },
+			wantEvaluated: []string{"loinc", "structure"},
+			wantIssues:    []string{"obs-6", "loinc"},
+		},
+		{
+			name:          "typed choice element",
+			update:        func(o *obspb.Observation) { o.Value = nil },
+			wantEvaluated: []string{"obs-6", "structure"},
+			wantIssues:    []string{"loinc"},
+		},
+	}
+	for _, step := range steps {
+		evaluated = map[string]int{}
+		updated := proto.Clone(obs).(*obspb.Observation)
+		step.update(updated)
+		next, err := v.Revalidate(res, Diff(obs, updated), updated)
+		if err != nil {
+			t.Fatalf("%s: Revalidate() returned unexpected error: %v", step.name, err)
+		}
+		if diff := cmp.Diff(step.wantEvaluated, next.Evaluated, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("%s: Revalidate() evaluated diff (-want +got):\n%s", step.name, diff)
+		}
+		for _, key := range next.Evaluated {
+			if evaluated[key] != 1 {
+				t.Errorf("%s: constraint %q evaluated %d times, want 1", step.name, key, evaluated[key])
+			}
+		}
+		var gotIssues []string
+		for _, issue := range next.Issues {
+			gotIssues = append(gotIssues, issue.Constraint)
+		}
+		if diff := cmp.Diff(step.wantIssues, gotIssues, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("%s: Revalidate() issues diff (-want +got):\n%s", step.name, diff)
+		}
+		if got := errors.Is(next.Err(), fhirerrors.ErrConstraintViolation); got == next.Valid() {
+			t.Errorf("%s: Err() = %v, want constraint violation: %v", step.name, next.Err(), !next.Valid())
+		}
+		full, err := v.Validate(updated)
+		if err != nil {
+			t.Fatalf("%s: Validate() returned unexpected error: %v", step.name, err)
+		}
+		if diff := cmp.Diff(full.Issues, next.Issues, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("%s: Revalidate() differs from Validate() (-full +incremental):\n%s", step.name, diff)
+		}
+		obs, res = updated, next
+	}
+}
+
+func TestNewValidator_Errors(t *testing.T) {
+	check := func(proto.Message) ([]Issue, error) { return nil, nil }
+	tests := []struct {
+		name        string
+		constraints []Constraint
+	}{
+		{"no key", []Constraint{{Check: check}}},
+		{"duplicate key", []Constraint{{Key: "a", Check: check}, {Key: "a", Check: check}}},
+		{"no check", []Constraint{{Key: "a"}}},
+		{"invalid path", []Constraint{{Key: "a", Paths: []string{"Observation..code"}, Check: check}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewValidator(test.constraints...); err == nil {
+				t.Errorf("NewValidator() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	obs := testObservation()
+	obs.Code.Coding = append(obs.Code.Coding, proto.Clone(obs.Code.Coding[0]).(*d4pb.Coding))
+	v, err := NewValidator(Duplicates("dup", errorreporter.IssueSeverityWarning))
+	if err != nil {
+		t.Fatalf("NewValidator() returned unexpected error: %v", err)
+	}
+	res, err := v.Validate(obs)
+	if err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+	want := []Issue{{
+		Constraint: "dup",
+		Path:       "Observation.code.coding[1]",
+		Severity:   errorreporter.IssueSeverityWarning,
+		Message:    "duplicate of Observation.code.coding[0]",
+	}}
+	if diff := cmp.Diff(want, res.Issues); diff != "" {
+		t.Errorf("Validate() issues diff (-want +got):\n%s", diff)
+	}
+}
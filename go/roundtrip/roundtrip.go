@@ -0,0 +1,326 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package roundtrip checks that a jsonformat configuration preserves the
+// data of FHIR JSON resources.
+//
+// Check parses a resource with an Unmarshaller, serializes it with a
+// Marshaller, and parses and serializes the result again. It reports the
+// elements whose values the first round trip changed or dropped, which the
+// configuration does not preserve, and those the second changed, which it
+// does not serialize idempotently, with their paths and the JSON before and
+// after. JSON is compared structurally, so the order of object keys and
+// whitespace do not matter, but numbers are compared as written, as FHIR
+// decimals keep their precision. Run checks every resource of a Source, such
+// as the examples of package speccorpus:
+//
+//	c, err := speccorpus.Open(fhirversion.R4)
+//	...
+//	it := c.Iterator()
+//	report, err := roundtrip.Run(cfg, roundtrip.SourceFunc(func() (roundtrip.Input, error) {
+//		ex, err := it.Next()
+//		if err != nil && ex == nil {
+//			return roundtrip.Input{}, err
+//		}
+//		return roundtrip.Input{Name: ex.Name, JSON: ex.JSON}, nil
+//	}))
+package roundtrip
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/jsonformat"
+)
+
+// Config is the jsonformat configuration to check.
+type Config struct {
+	Marshaller   *jsonformat.Marshaller
+	Unmarshaller *jsonformat.Unmarshaller
+}
+
+// Stage is the round trip that changed an element.
+type Stage string
+
+const (
+	// Preserve is the first round trip, from the input to its first
+	// serialization.
+	Preserve Stage = "preserve"
+	// Idempotent is the second round trip, from the first serialization to
+	// the second.
+	Idempotent Stage = "idempotent"
+)
+
+// Difference is an element changed by a round trip.
+type Difference struct {
+	Stage Stage
+	// Path is the path of the element in the JSON, i.e.
+	// "Patient.name[0].family".
+	Path string
+	// Before and After are the JSON of the element before and after the
+	// round trip, or nil where it is missing.
+	Before, After []byte
+}
+
+func (d Difference) String() string {
+	show := func(b []byte) string {
+		if b == nil {
+			return "(missing)"
+		}
+		return string(b)
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", d.Stage, d.Path, show(d.Before), show(d.After))
+}
+
+// Input is a JSON resource to check.
+type Input struct {
+	Name string
+	JSON []byte
+}
+
+// Result is the outcome of checking an Input.
+type Result struct {
+	Name string
+	// Err is set if the input or a serialization of it failed to parse or
+	// serialize, in which case Differences are those found before.
+	Err         error
+	Differences []Difference
+}
+
+// OK reports whether the configuration round trips the input exactly.
+func (r *Result) OK() bool {
+	return r.Err == nil && len(r.Differences) == 0
+}
+
+// Check round trips the JSON resource in with cfg.
+func Check(cfg Config, name string, in []byte) *Result {
+	r := &Result{Name: name}
+	first, err := roundTrip(cfg, in)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if r.Differences, err = diffJSON(Preserve, in, first); err != nil {
+		r.Err = err
+		return r
+	}
+	second, err := roundTrip(cfg, first)
+	if err != nil {
+		r.Err = fmt.Errorf("reparsing serialization: %w", err)
+		return r
+	}
+	if !bytes.Equal(first, second) {
+		diffs, err := diffJSON(Idempotent, first, second)
+		if err != nil {
+			r.Err = err
+			return r
+		}
+		r.Differences = append(r.Differences, diffs...)
+	}
+	return r
+}
+
+func roundTrip(cfg Config, in []byte) ([]byte, error) {
+	pb, err := cfg.Unmarshaller.Unmarshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("parsing: %w", err)
+	}
+	out, err := cfg.Marshaller.Marshal(pb)
+	if err != nil {
+		return nil, fmt.Errorf("serializing: %w", err)
+	}
+	return out, nil
+}
+
+// Source yields the inputs of Run. Next returns io.EOF after the last
+// input.
+type Source interface {
+	Next() (Input, error)
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func() (Input, error)
+
+// Next implements Source.
+func (f SourceFunc) Next() (Input, error) {
+	return f()
+}
+
+// Inputs returns a Source of in.
+func Inputs(in ...Input) Source {
+	return SourceFunc(func() (Input, error) {
+		if len(in) == 0 {
+			return Input{}, io.EOF
+		}
+		next := in[0]
+		in = in[1:]
+		return next, nil
+	})
+}
+
+// Report is the outcome of Run.
+type Report struct {
+	// Checked is the number of inputs checked.
+	Checked int
+	// Failures are the results of the inputs that did not round trip
+	// exactly, in the order of the source.
+	Failures []*Result
+}
+
+// OK reports whether every input round tripped exactly.
+func (r *Report) OK() bool {
+	return len(r.Failures) == 0
+}
+
+// String summarizes r, listing the differences and errors of its failures.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d checked, %d failed\n", r.Checked, len(r.Failures))
+	for _, f := range r.Failures {
+		if f.Err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", f.Name, f.Err)
+		}
+		for _, d := range f.Differences {
+			fmt.Fprintf(&b, "%s: %v\n", f.Name, d)
+		}
+	}
+	return b.String()
+}
+
+// Run checks every input of src with cfg. It returns an error only if src
+// does.
+func Run(cfg Config, src Source) (*Report, error) {
+	report := &Report{}
+	for {
+		in, err := src.Next()
+		if err == io.EOF {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Checked++
+		if r := Check(cfg, in.Name, in.JSON); !r.OK() {
+			report.Failures = append(report.Failures, r)
+		}
+	}
+}
+
+// diffJSON returns the differences between the JSON documents before and
+// after.
+func diffJSON(stage Stage, before, after []byte) ([]Difference, error) {
+	b, err := decode(before)
+	if err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	a, err := decode(after)
+	if err != nil {
+		return nil, fmt.Errorf("decoding serialization: %w", err)
+	}
+	root := "$"
+	if obj, ok := b.(map[string]interface{}); ok {
+		if rt, ok := obj["resourceType"].(string); ok {
+			root = rt
+		}
+	}
+	var diffs []Difference
+	walk(stage, root, b, a, &diffs)
+	return diffs, nil
+}
+
+func decode(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// walk appends the differences between the JSON values b and a at path to
+// diffs.
+func walk(stage Stage, path string, b, a interface{}, diffs *[]Difference) {
+	switch bv := b.(type) {
+	case map[string]interface{}:
+		av, ok := a.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range bv {
+			keys[k] = true
+		}
+		for k := range av {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			walk(stage, path+"."+k, bv[k], av[k], diffs)
+		}
+		return
+	case []interface{}:
+		av, ok := a.([]interface{})
+		if !ok {
+			break
+		}
+		n := len(bv)
+		if len(av) > n {
+			n = len(av)
+		}
+		for i := 0; i < n; i++ {
+			var be, ae interface{}
+			if i < len(bv) {
+				be = bv[i]
+			}
+			if i < len(av) {
+				ae = av[i]
+			}
+			walk(stage, fmt.Sprintf("%s[%d]", path, i), be, ae, diffs)
+		}
+		return
+	}
+	if equal(b, a) {
+		return
+	}
+	*diffs = append(*diffs, Difference{Stage: stage, Path: path, Before: encode(b), After: encode(a)})
+}
+
+func equal(b, a interface{}) bool {
+	if b == nil || a == nil {
+		return b == nil && a == nil
+	}
+	return bytes.Equal(encode(b), encode(a))
+}
+
+// encode returns the compact JSON of v, or nil if v is nil, the value of
+// elements missing from an object or array.
+func encode(v interface{}) []byte {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return []byte(fmt.Sprint(v))
+	}
+	return data
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roundtrip
+
+import (
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/go-cmp/cmp"
+)
+
+func config(t *testing.T) Config {
+	t.Helper()
+	m, err := jsonformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	um, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshallerWithoutValidation() returned unexpected error: %v", err)
+	}
+	return Config{Marshaller: m, Unmarshaller: um}
+}
+
+func TestRun(t *testing.T) {
+	src := Inputs(
+		Input{Name: "patient", JSON: []byte(`{
+  "resourceType": "Patient",
+  "name": [{"given": ["Jane"], "family": "Doe"}],
+  "id": "p1",
+  "birthDate": "1970-01-01"
+}`)},
+		Input{Name: "observation", JSON: []byte(`{"resourceType": "Observation", "id": "o1", "status": "final", "code": {"text": "x"}, "valueQuantity": {"value": 1.50}}`)},
+		Input{Name: "unknown element", JSON: []byte(`{"resourceType": "Patient", "colour": "blue"}`)},
+	)
+	report, err := Run(config(t), src)
+	if err != nil {
+		t.Fatalf("Run() returned unexpected error: %v", err)
+	}
+	if report.Checked != 3 {
+		t.Errorf("Run() checked %d inputs, want 3", report.Checked)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Name != "unknown element" || report.Failures[0].Err == nil {
+		t.Errorf("Run() returned failures:\n%v\nwant an error for unknown element", report)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	before := `{"resourceType": "Patient", "active": true, "name": [{"family": "Doe", "given": ["Jane", "J"]}], "multipleBirthInteger": 2}`
+	after := `{"name": [{"given": ["Jane"], "family": "Roe"}], "multipleBirthInteger": 2.0, "resourceType": "Patient"}`
+	got, err := diffJSON(Preserve, []byte(before), []byte(after))
+	if err != nil {
+		t.Fatalf("diffJSON() returned unexpected error: %v", err)
+	}
+	want := []Difference{
+		{Stage: Preserve, Path: "Patient.active", Before: []byte("true")},
+		{Stage: Preserve, Path: "Patient.multipleBirthInteger", Before: []byte("2"), After: []byte("2.0")},
+		{Stage: Preserve, Path: "Patient.name[0].family", Before: []byte(`"Doe"`), After: []byte(`"Roe"`)},
+		{Stage: Preserve, Path: "Patient.name[0].given[1]", Before: []byte(`"J"`)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diffJSON() diff (-want +got):\n%s", diff)
+	}
+	if got, want := want[0].String(), `preserve Patient.active: true -> (missing)`; got != want {
+		t.Errorf("Difference.String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rules loads deployment-specific business rules, invariants that an
+// organization enforces on top of the FHIR specification and profiles, from
+// YAML or JSON files, so that local policy changes without rebuilding the
+// services that validate resources:
+//
+//	rules:
+//	- key: org-obs-1
+//	  resourceType: Observation
+//	  expression: performer.exists()
+//	  human: Observations must name their performer
+//	  paths: [Observation.performer]
+//	- key: org-pat-1
+//	  resourceType: Patient
+//	  profile: http://example.org/StructureDefinition/registered-patient
+//	  expression: identifier.where(system = 'http://example.org/mrn').exists()
+//	  severity: warning
+//	  human: Registered patients should have an MRN
+//
+// Each rule becomes a revalidate Constraint evaluating its FHIRPath
+// expression, which must be true, against the resources it applies to: those
+// of its resourceType and, if it names a profile, that claim conformance to
+// it in meta.profile. Rules without a resourceType apply to every resource.
+package rules
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/meta"
+	"github.com/google/fhir/go/revalidate"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v2"
+)
+
+// File is a file of rules.
+type File struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule is a business rule.
+type Rule struct {
+	// Key identifies the rule in issues, i.e. "org-obs-1". Keys must be
+	// unique among the rules and constraints of a validator.
+	Key string `yaml:"key" json:"key"`
+	// Expression is the FHIRPath expression that must be true of the
+	// resources the rule applies to.
+	Expression string `yaml:"expression" json:"expression"`
+	// Severity is the severity of the issues of the rule, "error" or
+	// "warning"; the default is "error".
+	Severity string `yaml:"severity,omitempty" json:"severity,omitempty"`
+	// Human is the message of the issues of the rule.
+	Human string `yaml:"human" json:"human"`
+	// ResourceType restricts the rule to resources of the type.
+	ResourceType string `yaml:"resourceType,omitempty" json:"resourceType,omitempty"`
+	// Profile restricts the rule to resources claiming conformance to the
+	// profile with the canonical URL, as by meta.HasProfile.
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+	// Paths are the paths of the elements Expression reads, as for
+	// revalidate.Constraint. A rule without paths is evaluated again on every
+	// update of a resource.
+	Paths []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+}
+
+// Parse parses a file of rules in YAML or JSON and returns their
+// constraints.
+func Parse(data []byte) ([]revalidate.Constraint, error) {
+	var f File
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing rules: %w", err)
+	}
+	keys := map[string]bool{}
+	var cs []revalidate.Constraint
+	for _, r := range f.Rules {
+		if keys[r.Key] {
+			return nil, fmt.Errorf("duplicate rule %q", r.Key)
+		}
+		keys[r.Key] = true
+		c, err := r.Constraint()
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+// Load reads the file of rules at path and returns their constraints.
+func Load(path string) ([]revalidate.Constraint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cs, nil
+}
+
+// Constraint returns the constraint of r.
+func (r Rule) Constraint() (revalidate.Constraint, error) {
+	if r.Key == "" {
+		return revalidate.Constraint{}, fmt.Errorf("rule without key")
+	}
+	if r.Expression == "" {
+		return revalidate.Constraint{}, fmt.Errorf("rule %q has no expression", r.Key)
+	}
+	var severity errorreporter.IssueSeverityCode
+	switch r.Severity {
+	case "", "error":
+		severity = errorreporter.IssueSeverityError
+	case "warning":
+		severity = errorreporter.IssueSeverityWarning
+	default:
+		return revalidate.Constraint{}, fmt.Errorf("rule %q has unknown severity %q", r.Key, r.Severity)
+	}
+	for _, p := range r.Paths {
+		if r.ResourceType != "" && !strings.HasPrefix(p, r.ResourceType+".") {
+			return revalidate.Constraint{}, fmt.Errorf("rule %q has path %q outside %s", r.Key, p, r.ResourceType)
+		}
+	}
+	c, err := revalidate.Invariant(r.Key, r.Expression, severity, r.Human, r.paths()...)
+	if err != nil {
+		return revalidate.Constraint{}, err
+	}
+	check := c.Check
+	c.Check = func(res proto.Message) ([]revalidate.Issue, error) {
+		if !r.appliesTo(res) {
+			return nil, nil
+		}
+		return check(res)
+	}
+	return c, nil
+}
+
+// paths returns the paths the constraint of r depends on: its paths and, for
+// rules of a profile, the meta.profile of the resource types of the paths,
+// which decides whether the rule applies.
+func (r Rule) paths() []string {
+	if r.Profile == "" || len(r.Paths) == 0 {
+		return r.Paths
+	}
+	paths := append([]string(nil), r.Paths...)
+	seen := map[string]bool{}
+	for _, p := range r.Paths {
+		typ, _, _ := strings.Cut(p, ".")
+		if !seen[typ] {
+			seen[typ] = true
+			paths = append(paths, typ+".meta.profile")
+		}
+	}
+	return paths
+}
+
+func (r Rule) appliesTo(res proto.Message) bool {
+	if r.ResourceType != "" && elementpath.ResourceType(res) != r.ResourceType {
+		return false
+	}
+	return r.Profile == "" || meta.HasProfile(res, r.Profile)
+}
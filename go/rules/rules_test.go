@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+const testRules = `
+rules:
+- key: org-obs-1
+  resourceType: Observation
+  expression: performer.exists()
+  human: Observations must name their performer
+  paths: [Observation.performer]
+- key: org-pat-1
+  resourceType: Patient
+  profile: http://example.org/registered-patient
+  expression: identifier.exists()
+  severity: warning
+  human: Registered patients should have an identifier
+  paths: [Patient.identifier]
+`
+
+const registered = "http://example.org/registered-patient"
+
+func newValidator(t *testing.T, data string) *revalidate.Validator {
+	t.Helper()
+	cs, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	v, err := revalidate.NewValidator(cs...)
+	if err != nil {
+		t.Fatalf("NewValidator() returned unexpected error: %v", err)
+	}
+	return v
+}
+
+func keys(issues []revalidate.Issue) []string {
+	var out []string
+	for _, i := range issues {
+		out = append(out, i.Constraint+" "+string(i.Severity))
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	v := newValidator(t, testRules)
+	tests := []struct {
+		name string
+		res  proto.Message
+		want []string
+	}{
+		{"observation without performer", &obspb.Observation{}, []string{"org-obs-1 error"}},
+		{
+			"observation with performer",
+			&obspb.Observation{Performer: []*d4pb.Reference{{Display: &d4pb.String{Value: "Dr. Smith"}}}},
+			nil,
+		},
+		{"patient without profile", &ppb.Patient{}, nil},
+		{
+			"patient with profile",
+			&ppb.Patient{Meta: &d4pb.Meta{Profile: []*d4pb.Canonical{{Value: registered + "|1.0"}}}},
+			[]string{"org-pat-1 warning"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := v.Validate(tc.res)
+			if err != nil {
+				t.Fatalf("Validate() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, keys(res.Issues), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Validate() issues diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRevalidateProfile(t *testing.T) {
+	v := newValidator(t, testRules)
+	p := &ppb.Patient{}
+	res, err := v.Validate(p)
+	if err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+	updated := proto.Clone(p).(*ppb.Patient)
+	updated.Meta = &d4pb.Meta{Profile: []*d4pb.Canonical{{Value: registered}}}
+	next, err := v.Revalidate(res, revalidate.Diff(p, updated), updated)
+	if err != nil {
+		t.Fatalf("Revalidate() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"org-pat-1 warning"}, keys(next.Issues)); diff != "" {
+		t.Errorf("Revalidate() issues diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	data := `{"rules": [{"key": "org-1", "expression": "id.exists()", "human": "resources must have an id"}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	if len(cs) != 1 || cs[0].Key != "org-1" {
+		t.Fatalf("Load() = %v, want constraint org-1", cs)
+	}
+	issues, err := cs[0].Check(&ppb.Patient{})
+	if err != nil {
+		t.Fatalf("Check() returned unexpected error: %v", err)
+	}
+	want := []revalidate.Issue{{Path: "Patient", Severity: errorreporter.IssueSeverityError, Message: "resources must have an id"}}
+	if diff := cmp.Diff(want, issues); diff != "" {
+		t.Errorf("Check() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown field", "rules:\n- key: a\n  expression: id.exists()\n  message: x\n"},
+		{"no key", "rules:\n- expression: id.exists()\n"},
+		{"no expression", "rules:\n- key: a\n"},
+		{"duplicate key", "rules:\n- key: a\n  expression: id.exists()\n- key: a\n  expression: id.empty()\n"},
+		{"unknown severity", "rules:\n- key: a\n  expression: id.exists()\n  severity: fatal\n"},
+		{"invalid expression", "rules:\n- key: a\n  expression: id.exists(\n"},
+		{"path of another type", "rules:\n- key: a\n  resourceType: Patient\n  expression: id.exists()\n  paths: [Observation.id]\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tc.data)); err == nil {
+				t.Errorf("Parse() succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,468 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schedule expands R4 Timings and Dosages into the concrete times of
+// their occurrences within a window, as for medication administration
+// records and adherence analytics.
+//
+// A Timing repeats either on days, when it names times of day, event codes
+// such as MORN or days of the week, or in cycles of its period otherwise:
+// frequency 3 per 1 d without times of day is every 8 hours from the start
+// of the course. Where a Timing gives a range, such as frequency and
+// frequencyMax, the lower frequency and shorter period are used.
+package schedule
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+// GTSAbbreviationSystem is the code system of the Timing codes, such as BID,
+// that Expand understands for Timings without a repeat.
+const GTSAbbreviationSystem = "http://terminology.hl7.org/CodeSystem/v3-GTSAbbreviation"
+
+// DefaultEventTimes are the times of day of the event codes of Timing.repeat.when
+// used when Options.EventTimes has no entry for them. CM, CD and CV are
+// breakfast, lunch and dinner, which C, AC and PC refer to; HS and WAKE are
+// going to sleep and waking up.
+var DefaultEventTimes = map[vspb.EventTimingValueSet_Value]time.Duration{
+	vspb.EventTimingValueSet_MORN_EARLY: 6 * time.Hour,
+	vspb.EventTimingValueSet_MORN:       8 * time.Hour,
+	vspb.EventTimingValueSet_MORN_LATE:  10 * time.Hour,
+	vspb.EventTimingValueSet_NOON:       12 * time.Hour,
+	vspb.EventTimingValueSet_AFT_EARLY:  13 * time.Hour,
+	vspb.EventTimingValueSet_AFT:        14 * time.Hour,
+	vspb.EventTimingValueSet_AFT_LATE:   16 * time.Hour,
+	vspb.EventTimingValueSet_EVE_EARLY:  17 * time.Hour,
+	vspb.EventTimingValueSet_EVE:        18 * time.Hour,
+	vspb.EventTimingValueSet_EVE_LATE:   20 * time.Hour,
+	vspb.EventTimingValueSet_NIGHT:      22 * time.Hour,
+	vspb.EventTimingValueSet_WAKE:       7 * time.Hour,
+	vspb.EventTimingValueSet_HS:         22 * time.Hour,
+	vspb.EventTimingValueSet_CM:         8 * time.Hour,
+	vspb.EventTimingValueSet_CD:         12 * time.Hour,
+	vspb.EventTimingValueSet_CV:         18 * time.Hour,
+}
+
+// Options configures Expand and ExpandDosage.
+type Options struct {
+	// Location is the timezone of times of day, event codes and days of the
+	// week. It defaults to UTC.
+	Location *time.Location
+	// EventTimes overrides the times of day of DefaultEventTimes, as for a
+	// patient who has breakfast at 7:00.
+	EventTimes map[vspb.EventTimingValueSet_Value]time.Duration
+	// Start is the start of the course, from which cycles, counts and a
+	// bounding duration are measured. It defaults to the start of the bounding
+	// period of the Timing, or else to the start of the window.
+	Start time.Time
+}
+
+func (o Options) location() *time.Location {
+	if o.Location == nil {
+		return time.UTC
+	}
+	return o.Location
+}
+
+func (o Options) eventTime(e vspb.EventTimingValueSet_Value) time.Duration {
+	if d, ok := o.EventTimes[e]; ok {
+		return d
+	}
+	return DefaultEventTimes[e]
+}
+
+// Administration is a scheduled occurrence of a Dosage.
+type Administration struct {
+	Time time.Time
+	// Dose is the dose of the first doseAndRate of the Dosage, a
+	// SimpleQuantity or a Range, or nil if it has none.
+	Dose *d4pb.Dosage_DoseAndRate_DoseX
+}
+
+// ExpandDosage returns the administrations of d from from inclusive to to
+// exclusive, in order. Dosages taken as needed have no schedule and expand
+// to none.
+func ExpandDosage(d *d4pb.Dosage, from, to time.Time, opts Options) ([]Administration, error) {
+	if an := d.GetAsNeeded(); an.GetBoolean().GetValue() || an.GetCodeableConcept() != nil {
+		return nil, nil
+	}
+	times, err := Expand(d.GetTiming(), from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+	var dose *d4pb.Dosage_DoseAndRate_DoseX
+	for _, dr := range d.GetDoseAndRate() {
+		if dr.GetDose() != nil {
+			dose = dr.GetDose()
+			break
+		}
+	}
+	out := make([]Administration, len(times))
+	for i, t := range times {
+		out[i] = Administration{Time: t, Dose: dose}
+	}
+	return out, nil
+}
+
+// Expand returns the occurrences of t from from inclusive to to exclusive,
+// in order: its events, and the times of its repeat or, for Timings without
+// one, of its code, such as BID. Timings with neither have no occurrences
+// other than their events.
+func Expand(t *d4pb.Timing, from, to time.Time, opts Options) ([]time.Time, error) {
+	var out []time.Time
+	for i, e := range t.GetEvent() {
+		et, err := fhirtypes.DateTimeToTime(e)
+		if err != nil {
+			return nil, fmt.Errorf("event[%d]: %w", i, err)
+		}
+		if !et.Before(from) && et.Before(to) {
+			out = append(out, et)
+		}
+	}
+	r := t.GetRepeat()
+	if r == nil {
+		if c := fhirtypes.FirstCodingIn(t.GetCode(), GTSAbbreviationSystem); c != nil {
+			r = abbreviations[c.GetCode().GetValue()]
+		}
+	}
+	if r != nil {
+		times, err := expandRepeat(r, from, to, opts)
+		if err != nil {
+			return nil, fmt.Errorf("repeat: %w", err)
+		}
+		out = append(out, times...)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	uniq := out[:0]
+	for i, t := range out {
+		if i == 0 || !t.Equal(out[i-1]) {
+			uniq = append(uniq, t)
+		}
+	}
+	return uniq, nil
+}
+
+// abbreviations are the repeats of the common GTSAbbreviation codes.
+var abbreviations = map[string]*d4pb.Timing_Repeat{
+	"QD":   daily(1, 1),
+	"QOD":  daily(1, 2),
+	"BID":  daily(2, 1),
+	"TID":  daily(3, 1),
+	"QID":  daily(4, 1),
+	"AM":   {When: []*d4pb.Timing_Repeat_WhenCode{{Value: vspb.EventTimingValueSet_MORN}}},
+	"PM":   {When: []*d4pb.Timing_Repeat_WhenCode{{Value: vspb.EventTimingValueSet_EVE}}},
+	"Q4H":  hourly(4),
+	"Q6H":  hourly(6),
+	"Q8H":  hourly(8),
+	"Q12H": hourly(12),
+}
+
+func daily(frequency uint32, days int) *d4pb.Timing_Repeat {
+	return &d4pb.Timing_Repeat{
+		Frequency:  fhirtypes.PositiveInt(frequency),
+		Period:     fhirtypes.Decimal(strconv.Itoa(days)),
+		PeriodUnit: &d4pb.Timing_Repeat_PeriodUnitCode{Value: vspb.UnitsOfTimeValueSet_D},
+	}
+}
+
+func hourly(hours int) *d4pb.Timing_Repeat {
+	return &d4pb.Timing_Repeat{
+		Frequency:  fhirtypes.PositiveInt(1),
+		Period:     fhirtypes.Decimal(strconv.Itoa(hours)),
+		PeriodUnit: &d4pb.Timing_Repeat_PeriodUnitCode{Value: vspb.UnitsOfTimeValueSet_H},
+	}
+}
+
+// units are the lengths of the fixed units of time. Months and years are
+// calendar units, added with time.AddDate.
+var units = map[string]time.Duration{
+	"s":   time.Second,
+	"min": time.Minute,
+	"h":   time.Hour,
+	"d":   24 * time.Hour,
+	"wk":  7 * 24 * time.Hour,
+}
+
+// unitCodes are the UCUM codes of the units of time of periods.
+var unitCodes = map[vspb.UnitsOfTimeValueSet_Value]string{
+	vspb.UnitsOfTimeValueSet_S:   "s",
+	vspb.UnitsOfTimeValueSet_MIN: "min",
+	vspb.UnitsOfTimeValueSet_H:   "h",
+	vspb.UnitsOfTimeValueSet_D:   "d",
+	vspb.UnitsOfTimeValueSet_WK:  "wk",
+	vspb.UnitsOfTimeValueSet_MO:  "mo",
+	vspb.UnitsOfTimeValueSet_A:   "a",
+}
+
+// add returns t plus n times v of the unit of time, or false if the unit is
+// unknown.
+func add(t time.Time, v float64, n int, unit string) (time.Time, bool) {
+	if d, ok := units[unit]; ok {
+		return t.Add(time.Duration(v * float64(n) * float64(d))), true
+	}
+	months := 1
+	switch unit {
+	case "mo":
+	case "a":
+		months = 12
+	default:
+		return time.Time{}, false
+	}
+	if v == math.Trunc(v) {
+		return t.AddDate(0, int(v)*n*months, 0), true
+	}
+	// Fractions of months are taken as 30 days.
+	return t.Add(time.Duration(v * float64(n*months) * 30 * float64(units["d"]))), true
+}
+
+func decimal(d *d4pb.Decimal) (float64, error) {
+	s, ok := fhirtypes.DecimalValue(d)
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// expansion is the state of the expansion of a repeat.
+type expansion struct {
+	from, to   time.Time
+	start, end time.Time
+	limit      int
+	n          int
+	out        []time.Time
+}
+
+// emit records the occurrence t, and reports whether later occurrences may
+// still be emitted.
+func (e *expansion) emit(t time.Time) bool {
+	if t.Before(e.start) {
+		return true
+	}
+	if !t.Before(e.to) || (!e.end.IsZero() && !t.Before(e.end)) || (e.limit > 0 && e.n >= e.limit) {
+		return false
+	}
+	e.n++
+	if !t.Before(e.from) {
+		e.out = append(e.out, t)
+	}
+	return true
+}
+
+// stop returns the time after which no occurrence is emitted.
+func (e *expansion) stop() time.Time {
+	if !e.end.IsZero() && e.end.Before(e.to) {
+		return e.end
+	}
+	return e.to
+}
+
+func expandRepeat(r *d4pb.Timing_Repeat, from, to time.Time, opts Options) ([]time.Time, error) {
+	e := &expansion{from: from, to: to, start: opts.Start}
+	b := r.GetBounds()
+	if p := b.GetPeriod(); p != nil {
+		if p.GetStart() != nil {
+			s, _, err := fhirtypes.Span(p.GetStart())
+			if err != nil {
+				return nil, fmt.Errorf("bounds start: %w", err)
+			}
+			if e.start.IsZero() || e.start.Before(s) {
+				e.start = s
+			}
+		}
+		if p.GetEnd() != nil {
+			_, end, err := fhirtypes.Span(p.GetEnd())
+			if err != nil {
+				return nil, fmt.Errorf("bounds end: %w", err)
+			}
+			e.end = end
+		}
+	}
+	if e.start.IsZero() {
+		e.start = from
+	}
+	var length *d4pb.Decimal
+	var unit string
+	switch {
+	case b.GetDuration() != nil:
+		length, unit = b.GetDuration().GetValue(), b.GetDuration().GetCode().GetValue()
+	case b.GetRange().GetHigh() != nil:
+		length, unit = b.GetRange().GetHigh().GetValue(), b.GetRange().GetHigh().GetCode().GetValue()
+	}
+	if length != nil {
+		v, err := decimal(length)
+		if err != nil {
+			return nil, fmt.Errorf("bounds duration: %w", err)
+		}
+		end, ok := add(e.start, v, 1, unit)
+		if !ok {
+			return nil, fmt.Errorf("bounds duration has unknown unit %q", unit)
+		}
+		e.end = end
+	}
+	e.limit = int(r.GetCountMax().GetValue())
+	if e.limit == 0 {
+		e.limit = int(r.GetCount().GetValue())
+	}
+
+	period, err := decimal(r.GetPeriod())
+	if err != nil {
+		return nil, fmt.Errorf("period: %w", err)
+	}
+	unit = unitCodes[r.GetPeriodUnit().GetValue()]
+	if len(r.GetWhen()) > 0 || len(r.GetTimeOfDay()) > 0 || len(r.GetDayOfWeek()) > 0 {
+		e.days(r, period, unit, opts)
+		return e.out, nil
+	}
+	if period <= 0 || unit == "" {
+		return nil, fmt.Errorf("no period")
+	}
+	if d, ok := units[unit]; ok && time.Duration(period*float64(d)) <= 0 {
+		return nil, fmt.Errorf("period of %v%s is too short", period, unit)
+	}
+	frequency := int(r.GetFrequency().GetValue())
+	if frequency == 0 {
+		frequency = 1
+	}
+	e.cycles(frequency, period, unit)
+	return e.out, nil
+}
+
+// days emits the times of day of r on the days it repeats: every day, or
+// every period days for periods in days, restricted to its days of the week.
+func (e *expansion) days(r *d4pb.Timing_Repeat, period float64, unit string, opts Options) {
+	loc := opts.location()
+	var clock []time.Duration
+	for _, t := range r.GetTimeOfDay() {
+		clock = append(clock, time.Duration(t.GetValueUs())*time.Microsecond)
+	}
+	offset := time.Duration(r.GetOffset().GetValue()) * time.Minute
+	for _, w := range r.GetWhen() {
+		for _, ev := range events(w.GetValue()) {
+			t := opts.eventTime(ev.code)
+			if ev.before {
+				t -= offset
+			} else {
+				t += offset
+			}
+			clock = append(clock, t)
+		}
+	}
+	start := e.start.In(loc)
+	if len(clock) == 0 {
+		clock = []time.Duration{start.Sub(time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc))}
+	}
+	sort.Slice(clock, func(i, j int) bool { return clock[i] < clock[j] })
+	weekdays := map[time.Weekday]bool{}
+	for _, d := range r.GetDayOfWeek() {
+		weekdays[weekday(d.GetValue())] = true
+	}
+	step := 1
+	if unit == "d" && period >= 1 {
+		step = int(period)
+	}
+	stop := e.stop()
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(stop); day = time.Date(day.Year(), day.Month(), day.Day()+step, 0, 0, 0, 0, loc) {
+		if len(weekdays) > 0 && !weekdays[day.Weekday()] {
+			continue
+		}
+		for _, c := range clock {
+			if !e.emit(time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, int(c), loc)) {
+				return
+			}
+		}
+	}
+}
+
+// cycles emits frequency evenly spaced times in each period from the start
+// of the course.
+func (e *expansion) cycles(frequency int, period float64, unit string) {
+	k := 0
+	if d, ok := units[unit]; ok && e.limit == 0 && e.start.Before(e.from) {
+		// Skip the cycles before the window, which count for nothing.
+		k = int(e.from.Sub(e.start) / time.Duration(period*float64(d)))
+	}
+	stop := e.stop()
+	for ; ; k++ {
+		cs, _ := add(e.start, period, k, unit)
+		if !cs.Before(stop) {
+			return
+		}
+		ce, _ := add(e.start, period, k+1, unit)
+		for i := 0; i < frequency; i++ {
+			if !e.emit(cs.Add(ce.Sub(cs) * time.Duration(i) / time.Duration(frequency))) {
+				return
+			}
+		}
+	}
+}
+
+// event is an event of a when code, which happens at, or offset after or
+// before, the time of code.
+type event struct {
+	code   vspb.EventTimingValueSet_Value
+	before bool
+}
+
+// events returns the events of the when code w: the three meals of C, AC
+// and PC, and the meal or time of day of the others.
+func events(w vspb.EventTimingValueSet_Value) []event {
+	meals := func(before bool, codes ...vspb.EventTimingValueSet_Value) []event {
+		var out []event
+		for _, c := range codes {
+			out = append(out, event{c, before})
+		}
+		return out
+	}
+	const cm, cd, cv = vspb.EventTimingValueSet_CM, vspb.EventTimingValueSet_CD, vspb.EventTimingValueSet_CV
+	switch w {
+	case vspb.EventTimingValueSet_C, vspb.EventTimingValueSet_PC:
+		return meals(false, cm, cd, cv)
+	case vspb.EventTimingValueSet_AC:
+		return meals(true, cm, cd, cv)
+	case vspb.EventTimingValueSet_ACM:
+		return meals(true, cm)
+	case vspb.EventTimingValueSet_ACD:
+		return meals(true, cd)
+	case vspb.EventTimingValueSet_ACV:
+		return meals(true, cv)
+	case vspb.EventTimingValueSet_PCM:
+		return meals(false, cm)
+	case vspb.EventTimingValueSet_PCD:
+		return meals(false, cd)
+	case vspb.EventTimingValueSet_PCV:
+		return meals(false, cv)
+	case vspb.EventTimingValueSet_HS:
+		return []event{{w, true}}
+	case vspb.EventTimingValueSet_PHS:
+		return []event{{vspb.EventTimingValueSet_HS, false}}
+	}
+	return []event{{w, false}}
+}
+
+func weekday(d c4pb.DaysOfWeekCode_Value) time.Weekday {
+	if d == c4pb.DaysOfWeekCode_SUN {
+		return time.Sunday
+	}
+	return time.Weekday(d)
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+func at(day, hour, min int) time.Time {
+	return time.Date(2024, 3, day, hour, min, 0, 0, time.UTC)
+}
+
+func periodUnit(u vspb.UnitsOfTimeValueSet_Value) *d4pb.Timing_Repeat_PeriodUnitCode {
+	return &d4pb.Timing_Repeat_PeriodUnitCode{Value: u}
+}
+
+func when(codes ...vspb.EventTimingValueSet_Value) []*d4pb.Timing_Repeat_WhenCode {
+	var out []*d4pb.Timing_Repeat_WhenCode
+	for _, c := range codes {
+		out = append(out, &d4pb.Timing_Repeat_WhenCode{Value: c})
+	}
+	return out
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name     string
+		timing   *d4pb.Timing
+		from, to time.Time
+		opts     Options
+		want     []time.Time
+	}{
+		{
+			name: "frequency per day",
+			timing: &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{
+				Frequency: fhirtypes.PositiveInt(3), Period: fhirtypes.Decimal("1"), PeriodUnit: periodUnit(vspb.UnitsOfTimeValueSet_D),
+			}},
+			from: at(1, 6, 0), to: at(2, 6, 0),
+			want: []time.Time{at(1, 6, 0), at(1, 14, 0), at(1, 22, 0)},
+		},
+		{
+			name: "times of day with count",
+			timing: &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{
+				Count:     fhirtypes.PositiveInt(3),
+				TimeOfDay: []*d4pb.Time{{ValueUs: (9 * time.Hour).Microseconds()}, {ValueUs: (21 * time.Hour).Microseconds()}},
+			}},
+			from: at(1, 12, 0), to: at(10, 0, 0),
+			want: []time.Time{at(1, 21, 0), at(2, 9, 0), at(2, 21, 0)},
+		},
+		{
+			name: "when with offset",
+			timing: &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{
+				When: when(vspb.EventTimingValueSet_ACM, vspb.EventTimingValueSet_HS), Offset: fhirtypes.UnsignedInt(30),
+			}},
+			from: at(1, 0, 0), to: at(2, 0, 0),
+			want: []time.Time{at(1, 7, 30), at(1, 21, 30)},
+		},
+		{
+			name:   "meals with overridden breakfast",
+			timing: &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{When: when(vspb.EventTimingValueSet_C)}},
+			from:   at(1, 0, 0), to: at(2, 0, 0),
+			opts: Options{EventTimes: map[vspb.EventTimingValueSet_Value]time.Duration{vspb.EventTimingValueSet_CM: 7 * time.Hour}},
+			want: []time.Time{at(1, 7, 0), at(1, 12, 0), at(1, 18, 0)},
+		},
+		{
+			name: "days of week within bounds",
+			timing: &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{
+				Bounds: &d4pb.Timing_Repeat_BoundsX{Choice: &d4pb.Timing_Repeat_BoundsX_Period{Period: &d4pb.Period{
+					Start: fhirtypes.DateTimeFromTime(at(1, 0, 0), d4pb.DateTime_DAY),
+					End:   fhirtypes.DateTimeFromTime(at(8, 0, 0), d4pb.DateTime_DAY),
+				}}},
+				DayOfWeek: []*d4pb.Timing_Repeat_DayOfWeekCode{{Value: c4pb.DaysOfWeekCode_MON}, {Value: c4pb.DaysOfWeekCode_FRI}},
+				TimeOfDay: []*d4pb.Time{{ValueUs: (10 * time.Hour).Microseconds()}},
+			}},
+			from: at(1, 0, 0), to: at(31, 0, 0),
+			// March 1st 2024 is a Friday.
+			want: []time.Time{at(1, 10, 0), at(4, 10, 0), at(8, 10, 0)},
+		},
+		{
+			name: "bounds duration",
+			timing: &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{
+				Bounds: &d4pb.Timing_Repeat_BoundsX{Choice: &d4pb.Timing_Repeat_BoundsX_Duration{Duration: &d4pb.Duration{
+					Value: fhirtypes.Decimal("1"), Code: fhirtypes.Code("d"),
+				}}},
+				Frequency: fhirtypes.PositiveInt(1), Period: fhirtypes.Decimal("8"), PeriodUnit: periodUnit(vspb.UnitsOfTimeValueSet_H),
+			}},
+			from: at(1, 12, 0), to: at(5, 0, 0),
+			opts: Options{Start: at(1, 0, 0)},
+			want: []time.Time{at(1, 16, 0)},
+		},
+		{
+			name:   "code",
+			timing: &d4pb.Timing{Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(GTSAbbreviationSystem, "BID")}}},
+			from:   at(1, 8, 0), to: at(2, 8, 0),
+			want: []time.Time{at(1, 8, 0), at(1, 20, 0)},
+		},
+		{
+			name:   "events",
+			timing: &d4pb.Timing{Event: []*d4pb.DateTime{fhirtypes.DateTimeFromTime(at(2, 9, 0), d4pb.DateTime_SECOND), fhirtypes.DateTimeFromTime(at(9, 9, 0), d4pb.DateTime_SECOND)}},
+			from:   at(1, 0, 0), to: at(5, 0, 0),
+			want: []time.Time{at(2, 9, 0)},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Expand(tc.timing, tc.from, tc.to, tc.opts)
+			if err != nil {
+				t.Fatalf("Expand() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Expand() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExpand_Location(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no timezone database: %v", err)
+	}
+	timing := &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{When: when(vspb.EventTimingValueSet_MORN)}}
+	// Clocks spring forward on March 10th 2024 in New York.
+	got, err := Expand(timing, time.Date(2024, 3, 9, 0, 0, 0, 0, ny), time.Date(2024, 3, 11, 0, 0, 0, 0, ny), Options{Location: ny})
+	if err != nil {
+		t.Fatalf("Expand() returned unexpected error: %v", err)
+	}
+	want := []time.Time{time.Date(2024, 3, 9, 8, 0, 0, 0, ny), time.Date(2024, 3, 10, 8, 0, 0, 0, ny)}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Expand() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestExpandDosage(t *testing.T) {
+	dose := &d4pb.Dosage_DoseAndRate_DoseX{Choice: &d4pb.Dosage_DoseAndRate_DoseX_Quantity{Quantity: &d4pb.SimpleQuantity{
+		Value: fhirtypes.Decimal("500"), Code: fhirtypes.Code("mg"),
+	}}}
+	d := &d4pb.Dosage{
+		Timing:      &d4pb.Timing{Repeat: &d4pb.Timing_Repeat{When: when(vspb.EventTimingValueSet_MORN, vspb.EventTimingValueSet_EVE)}},
+		DoseAndRate: []*d4pb.Dosage_DoseAndRate{{Dose: dose}},
+	}
+	got, err := ExpandDosage(d, at(1, 0, 0), at(2, 0, 0), Options{})
+	if err != nil {
+		t.Fatalf("ExpandDosage() returned unexpected error: %v", err)
+	}
+	want := []Administration{{Time: at(1, 8, 0), Dose: dose}, {Time: at(1, 18, 0), Dose: dose}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ExpandDosage() diff (-want +got):\n%s", diff)
+	}
+
+	d.AsNeeded = &d4pb.Dosage_AsNeededX{Choice: &d4pb.Dosage_AsNeededX_Boolean{Boolean: fhirtypes.Boolean(true)}}
+	if got, err := ExpandDosage(d, at(1, 0, 0), at(2, 0, 0), Options{}); err != nil || len(got) != 0 {
+		t.Errorf("ExpandDosage() of as needed dosage = %v, %v, want none", got, err)
+	}
+}
+
+func TestExpand_Errors(t *testing.T) {
+	for _, r := range []*d4pb.Timing_Repeat{
+		{Frequency: fhirtypes.PositiveInt(1)},
+		{Period: fhirtypes.Decimal("x"), PeriodUnit: periodUnit(vspb.UnitsOfTimeValueSet_H)},
+		{Period: fhirtypes.Decimal("1e-12"), PeriodUnit: periodUnit(vspb.UnitsOfTimeValueSet_S)},
+	} {
+		if _, err := Expand(&d4pb.Timing{Repeat: r}, at(1, 0, 0), at(2, 0, 0), Options{}); err == nil {
+			t.Errorf("Expand(%v) succeeded, want error", r)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// bigQueryField is a field of a BigQuery JSON schema.
+type bigQueryField struct {
+	Name        string           `json:"name"`
+	Type        string           `json:"type"`
+	Mode        string           `json:"mode"`
+	Description string           `json:"description,omitempty"`
+	Fields      []*bigQueryField `json:"fields,omitempty"`
+}
+
+var bigQueryTypes = map[Type]string{
+	String:    "STRING",
+	Boolean:   "BOOLEAN",
+	Integer:   "INTEGER",
+	Decimal:   "FLOAT",
+	Timestamp: "TIMESTAMP",
+	Bytes:     "BYTES",
+	Record:    "RECORD",
+}
+
+// BigQuery returns the BigQuery JSON schema of s, as taken by bq mk and
+// load. Every field is NULLABLE or REPEATED.
+func (s *Schema) BigQuery() ([]byte, error) {
+	return json.MarshalIndent(bigQueryFields(s.Fields), "", "  ")
+}
+
+func bigQueryFields(fields []*Field) []*bigQueryField {
+	out := make([]*bigQueryField, 0, len(fields))
+	for _, f := range fields {
+		bf := &bigQueryField{Name: f.Name, Type: bigQueryTypes[f.Type], Mode: "NULLABLE", Description: f.Path}
+		if f.Repeated {
+			bf.Mode = "REPEATED"
+		}
+		if f.Type == Record {
+			bf.Fields = bigQueryFields(f.Fields)
+		}
+		out = append(out, bf)
+	}
+	return out
+}
+
+var avroTypes = map[Type]interface{}{
+	String:    "string",
+	Boolean:   "boolean",
+	Integer:   "int",
+	Decimal:   "double",
+	Timestamp: map[string]string{"type": "long", "logicalType": "timestamp-micros"},
+	Bytes:     "bytes",
+}
+
+// Avro returns the Avro schema of s, a record named after s in namespace.
+// Nested records are named after their path, i.e. Patient_name, since Avro
+// names are global to a schema. Every field is nullable and defaults to
+// null.
+func (s *Schema) Avro(namespace string) ([]byte, error) {
+	record := avroRecord(s.Name, s.Fields)
+	if namespace != "" {
+		record["namespace"] = namespace
+	}
+	return json.MarshalIndent(record, "", "  ")
+}
+
+func avroRecord(name string, fields []*Field) map[string]interface{} {
+	out := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		var t interface{}
+		if f.Type == Record {
+			t = avroRecord(name+"_"+f.Name, f.Fields)
+		} else {
+			t = avroTypes[f.Type]
+		}
+		if f.Repeated {
+			t = map[string]interface{}{"type": "array", "items": t}
+		}
+		field := map[string]interface{}{
+			"name":    f.Name,
+			"type":    []interface{}{"null", t},
+			"default": nil,
+		}
+		if f.Path != "" {
+			field["doc"] = f.Path
+		}
+		out = append(out, field)
+	}
+	return map[string]interface{}{"type": "record", "name": name, "fields": out}
+}
+
+var parquetTypes = map[Type]string{
+	String:    "binary %s (STRING)",
+	Boolean:   "boolean %s",
+	Integer:   "int32 %s",
+	Decimal:   "double %s",
+	Timestamp: "int64 %s (TIMESTAMP(MICROS,true))",
+	Bytes:     "binary %s",
+}
+
+// Parquet returns the Parquet message type of s in the text form of the
+// Parquet schema parsers. Lists use the three-level LIST structure of the
+// Parquet format.
+func (s *Schema) Parquet() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "message %s {\n", s.Name)
+	parquetFields(&b, s.Fields, "  ")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func parquetFields(b *strings.Builder, fields []*Field, indent string) {
+	for _, f := range fields {
+		if !f.Repeated {
+			parquetField(b, "optional", f.Name, f, indent)
+			continue
+		}
+		fmt.Fprintf(b, "%soptional group %s (LIST) {\n", indent, f.Name)
+		fmt.Fprintf(b, "%s  repeated group list {\n", indent)
+		parquetField(b, "optional", "element", f, indent+"    ")
+		fmt.Fprintf(b, "%s  }\n%s}\n", indent, indent)
+	}
+}
+
+func parquetField(b *strings.Builder, repetition, name string, f *Field, indent string) {
+	if f.Type != Record {
+		fmt.Fprintf(b, "%s%s %s;\n", indent, repetition, fmt.Sprintf(parquetTypes[f.Type], name))
+		return
+	}
+	fmt.Fprintf(b, "%s%s group %s {\n", indent, repetition, name)
+	parquetFields(b, f.Fields, indent+"  ")
+	fmt.Fprintf(b, "%s}\n", indent)
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schemagen generates warehouse schemas, for BigQuery, Avro and
+// Parquet, for FHIR resources from their proto descriptors.
+//
+// A schema describes the rows written by the analytics marshaller of
+// jsonformat, NewAnalyticsMarshaller, with the same maximum depth: primitives
+// are plain columns, the ids of elements are omitted, extensions are the list
+// of their URLs, choice elements are records with a field per type, i.e.
+// value.quantity, and contained resources are left out. A field whose name
+// already occurs MaxDepth times on its path is left out too, which cuts the
+// recursion of elements such as Identifier.assigner.identifier.
+//
+// Dates, times and dateTimes, which may be partial, are strings; instants are
+// timestamps, decimals floating point numbers and base64Binary bytes.
+//
+// A profile restricts the schema of its resource type: the elements it
+// prohibits are left out, and its choice elements keep the types it allows.
+// Flattened schemas replace the records of single elements by their fields,
+// named after their path, i.e. code_text; FlattenRow flattens the rows of
+// the analytics marshaller accordingly.
+package schemagen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+)
+
+// DefaultMaxDepth is the maximum depth of fields of the same name, as in
+// the analytics marshaller of jsonformat.
+const DefaultMaxDepth = 2
+
+// Type is the type of a Field.
+type Type int
+
+// Types of fields.
+const (
+	String Type = iota
+	Boolean
+	Integer
+	Decimal
+	Timestamp
+	Bytes
+	Record
+)
+
+// Field is a column of a schema.
+type Field struct {
+	Name     string
+	Type     Type
+	Repeated bool
+	// Fields are the fields of a Record.
+	Fields []*Field
+	// Path is the FHIR path of the element, i.e. "Observation.valueQuantity".
+	Path string
+}
+
+// Schema is the schema of the rows of a resource type or profile.
+type Schema struct {
+	// Name is the resource type, or the name of the profile.
+	Name   string
+	Fields []*Field
+}
+
+// Options configures a schema.
+type Options struct {
+	// MaxDepth is the number of times a field of the same name may occur on a
+	// path; DefaultMaxDepth if 0 or less.
+	MaxDepth int
+	// Profile, if set, is a profile of the resource type restricting the
+	// schema.
+	Profile *sdpb.StructureDefinition
+	// Flatten replaces the records of single elements by their fields.
+	Flatten bool
+}
+
+// Generate returns the schema of the R4 resource md.
+func Generate(md protoreflect.MessageDescriptor, opts Options) (*Schema, error) {
+	if !elementpath.IsResource(md) {
+		return nil, fmt.Errorf("%s is not a resource", md.FullName())
+	}
+	g := &generator{maxDepth: opts.MaxDepth, depths: map[string]int{}}
+	if g.maxDepth <= 0 {
+		g.maxDepth = DefaultMaxDepth
+	}
+	s := &Schema{Name: string(md.Name())}
+	if opts.Profile != nil {
+		if t := opts.Profile.GetType().GetValue(); t != s.Name {
+			return nil, fmt.Errorf("profile %s is of type %s, not %s", opts.Profile.GetUrl().GetValue(), t, s.Name)
+		}
+		g.restrict(opts.Profile)
+		if name := opts.Profile.GetName().GetValue(); name != "" {
+			s.Name = name
+		}
+	}
+	s.Fields = g.fields(md, string(md.Name()), true)
+	if opts.Flatten {
+		s.Fields = flatten(s.Fields, "")
+	}
+	return s, nil
+}
+
+type generator struct {
+	maxDepth int
+	// depths counts the non-primitive fields of each name on the current
+	// path.
+	depths map[string]int
+	// prohibited are the paths of the elements the profile prohibits.
+	prohibited map[string]bool
+	// types are the types the profile allows for choice elements, by path
+	// without the [x].
+	types map[string]map[string]bool
+}
+
+// restrict records the restrictions of the profile sd.
+func (g *generator) restrict(sd *sdpb.StructureDefinition) {
+	g.prohibited = map[string]bool{}
+	g.types = map[string]map[string]bool{}
+	elems := sd.GetSnapshot().GetElement()
+	if len(elems) == 0 {
+		elems = sd.GetDifferential().GetElement()
+	}
+	for _, e := range elems {
+		// Slices constrain some of the elements only.
+		if strings.Contains(e.GetId().GetValue(), ":") || e.GetSliceName() != nil {
+			continue
+		}
+		path := e.GetPath().GetValue()
+		if e.GetMax().GetValue() == "0" {
+			g.prohibited[strings.TrimSuffix(path, "[x]")] = true
+		}
+		if strings.HasSuffix(path, "[x]") && len(e.GetType()) > 0 {
+			allowed := map[string]bool{}
+			for _, t := range e.GetType() {
+				allowed[strings.ToLower(t.GetCode().GetValue())] = true
+			}
+			g.types[strings.TrimSuffix(path, "[x]")] = allowed
+		}
+	}
+}
+
+// fields returns the fields of the message md at path.
+func (g *generator) fields(md protoreflect.MessageDescriptor, path string, resource bool) []*Field {
+	var out []*Field
+	fds := md.Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		name := fd.JSONName()
+		p := path + "." + name
+		if fd.Message() == nil || g.prohibited[p] || (!resource && name == "id") {
+			continue
+		}
+		if f := g.field(fd, name, p); f != nil {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func (g *generator) field(fd protoreflect.FieldDescriptor, name, path string) *Field {
+	md := fd.Message()
+	f := &Field{Name: name, Repeated: fd.IsList(), Path: path}
+	switch {
+	case name == "extension" && fd.IsList():
+		f.Type = String
+		return f
+	case elementpath.IsContainedResource(md) || md.FullName() == "google.protobuf.Any":
+		return nil
+	case elementpath.IsPrimitive(md):
+		f.Type = primitiveType(md)
+		return f
+	}
+	g.depths[name]++
+	defer func() { g.depths[name]-- }()
+	if g.depths[name] > g.maxDepth {
+		return nil
+	}
+	f.Type = Record
+	if elementpath.IsChoice(md) {
+		allowed := g.types[path]
+		fds := md.Fields()
+		for i := 0; i < fds.Len(); i++ {
+			cfd := fds.Get(i)
+			typeName := string(cfd.Message().Name())
+			if allowed != nil && !allowed[strings.ToLower(typeName)] {
+				continue
+			}
+			if c := g.field(cfd, cfd.JSONName(), path+typeName); c != nil {
+				f.Fields = append(f.Fields, c)
+			}
+		}
+	} else {
+		f.Fields = g.fields(md, path, false)
+	}
+	// Records without fields are invalid in most warehouses.
+	if len(f.Fields) == 0 {
+		return nil
+	}
+	return f
+}
+
+// primitiveType returns the type of the primitive md.
+func primitiveType(md protoreflect.MessageDescriptor) Type {
+	switch md.Name() {
+	case "Boolean":
+		return Boolean
+	case "Integer", "PositiveInt", "UnsignedInt":
+		return Integer
+	case "Decimal":
+		return Decimal
+	case "Instant":
+		return Timestamp
+	case "Base64Binary":
+		return Bytes
+	}
+	return String
+}
+
+// flatten replaces the single records of fields by their fields, prefixing
+// their names with prefix.
+func flatten(fields []*Field, prefix string) []*Field {
+	var out []*Field
+	for _, f := range fields {
+		c := *f
+		c.Name = prefix + f.Name
+		switch {
+		case f.Type != Record:
+			out = append(out, &c)
+		case f.Repeated:
+			c.Fields = flatten(f.Fields, "")
+			out = append(out, &c)
+		default:
+			out = append(out, flatten(f.Fields, c.Name+"_")...)
+		}
+	}
+	return out
+}
+
+// FlattenRow flattens a row of the analytics marshaller of jsonformat, a
+// JSON object decoded with encoding/json, like a flattened Schema. Objects
+// in lists stay objects, with their fields flattened.
+func FlattenRow(row map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	flattenRow(out, row, "")
+	return out
+}
+
+func flattenRow(out, obj map[string]interface{}, prefix string) {
+	for k, v := range obj {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			flattenRow(out, x, prefix+k+"_")
+		case []interface{}:
+			list := make([]interface{}, len(x))
+			for i, item := range x {
+				if o, ok := item.(map[string]interface{}); ok {
+					item = FlattenRow(o)
+				}
+				list[i] = item
+			}
+			out[prefix+k] = list
+		default:
+			out[prefix+k] = v
+		}
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemagen
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/fhir/go/fhirgen"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/go-cmp/cmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	sdpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_definition_go_proto"
+)
+
+// conforms returns the keys of the JSON object row that do not fit fields.
+func conforms(fields []*Field, row map[string]interface{}, path string) []string {
+	var bad []string
+	byName := map[string]*Field{}
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	for k, v := range row {
+		f, ok := byName[k]
+		if !ok {
+			bad = append(bad, path+k)
+			continue
+		}
+		values := []interface{}{v}
+		if f.Repeated {
+			list, ok := v.([]interface{})
+			if !ok {
+				bad = append(bad, path+k)
+				continue
+			}
+			values = list
+		}
+		for _, v := range values {
+			switch x := v.(type) {
+			case map[string]interface{}:
+				if f.Type != Record {
+					bad = append(bad, path+k)
+					continue
+				}
+				bad = append(bad, conforms(f.Fields, x, path+k+".")...)
+			case []interface{}:
+				bad = append(bad, path+k)
+			default:
+				if f.Type == Record {
+					bad = append(bad, path+k)
+				}
+			}
+		}
+	}
+	return bad
+}
+
+func TestGenerate_AnalyticsRows(t *testing.T) {
+	m, err := jsonformat.NewAnalyticsMarshaller(0, fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewAnalyticsMarshaller() returned unexpected error: %v", err)
+	}
+	schemas := map[string][2]*Schema{}
+	for _, r := range fhirgen.New(3, fhirgen.Options{}).Patients(10) {
+		for _, cr := range r.Resources() {
+			res := elementpath.Unwrap(cr)
+			typ := elementpath.ResourceType(res)
+			if _, ok := schemas[typ]; !ok {
+				nested, err := Generate(res.ProtoReflect().Descriptor(), Options{})
+				if err != nil {
+					t.Fatalf("Generate(%s) returned unexpected error: %v", typ, err)
+				}
+				flat, err := Generate(res.ProtoReflect().Descriptor(), Options{Flatten: true})
+				if err != nil {
+					t.Fatalf("Generate(%s) returned unexpected error: %v", typ, err)
+				}
+				schemas[typ] = [2]*Schema{nested, flat}
+			}
+			data, err := m.Marshal(cr)
+			if err != nil {
+				t.Fatalf("Marshal() returned unexpected error: %v", err)
+			}
+			var row map[string]interface{}
+			if err := json.Unmarshal(data, &row); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+			if bad := conforms(schemas[typ][0].Fields, row, ""); len(bad) > 0 {
+				t.Errorf("%s row %s does not fit its schema at %v", typ, data, bad)
+			}
+			if bad := conforms(schemas[typ][1].Fields, FlattenRow(row), ""); len(bad) > 0 {
+				t.Errorf("flattened %s row %s does not fit its flattened schema at %v", typ, data, bad)
+			}
+		}
+	}
+}
+
+// find returns the field at the dotted path of names.
+func find(fields []*Field, names ...string) *Field {
+	for _, f := range fields {
+		if f.Name != names[0] {
+			continue
+		}
+		if len(names) == 1 {
+			return f
+		}
+		return find(f.Fields, names[1:]...)
+	}
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	s, err := Generate((&ppb.Patient{}).ProtoReflect().Descriptor(), Options{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("Generate() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		path []string
+		want *Field
+	}{
+		{[]string{"id"}, &Field{Name: "id", Type: String, Path: "Patient.id"}},
+		{[]string{"birthDate"}, &Field{Name: "birthDate", Type: String, Path: "Patient.birthDate"}},
+		{[]string{"active"}, &Field{Name: "active", Type: Boolean, Path: "Patient.active"}},
+		{[]string{"extension"}, &Field{Name: "extension", Type: String, Repeated: true, Path: "Patient.extension"}},
+		{[]string{"name", "given"}, &Field{Name: "given", Type: String, Repeated: true, Path: "Patient.name.given"}},
+		{[]string{"meta", "lastUpdated"}, &Field{Name: "lastUpdated", Type: Timestamp, Path: "Patient.meta.lastUpdated"}},
+		{[]string{"multipleBirth", "integer"}, &Field{Name: "integer", Type: Integer, Path: "Patient.multipleBirthInteger"}},
+		{[]string{"photo", "data"}, &Field{Name: "data", Type: Bytes, Path: "Patient.photo.data"}},
+		// Element ids, contained resources and fields beyond the depth are
+		// left out.
+		{[]string{"name", "id"}, nil},
+		{[]string{"contained"}, nil},
+		{[]string{"identifier", "assigner", "identifier"}, nil},
+	}
+	for _, test := range tests {
+		if diff := cmp.Diff(test.want, find(s.Fields, test.path...)); diff != "" {
+			t.Errorf("Generate() field %v diff (-want +got):\n%s", test.path, diff)
+		}
+	}
+}
+
+func TestGenerate_Profile(t *testing.T) {
+	elem := func(path, max string, types ...string) *d4pb.ElementDefinition {
+		e := &d4pb.ElementDefinition{Path: &d4pb.String{Value: path}}
+		if max != "" {
+			e.Max = &d4pb.String{Value: max}
+		}
+		for _, t := range types {
+			e.Type = append(e.Type, &d4pb.ElementDefinition_TypeRef{Code: &d4pb.Uri{Value: t}})
+		}
+		return e
+	}
+	profile := &sdpb.StructureDefinition{
+		Url:  &d4pb.Uri{Value: "http://example.org/StructureDefinition/vital"},
+		Name: &d4pb.String{Value: "Vital"},
+		Type: &d4pb.Uri{Value: "Observation"},
+		Differential: &sdpb.StructureDefinition_Differential{Element: []*d4pb.ElementDefinition{
+			elem("Observation", ""),
+			elem("Observation.value[x]", "", "Quantity"),
+			elem("Observation.bodySite", "0"),
+			elem("Observation.effective[x]", "0"),
+		}},
+	}
+	s, err := Generate((&obspb.Observation{}).ProtoReflect().Descriptor(), Options{Profile: profile})
+	if err != nil {
+		t.Fatalf("Generate() returned unexpected error: %v", err)
+	}
+	if s.Name != "Vital" {
+		t.Errorf("Generate() schema named %q, want Vital", s.Name)
+	}
+	var value []string
+	for _, f := range find(s.Fields, "value").Fields {
+		value = append(value, f.Name)
+	}
+	if diff := cmp.Diff([]string{"quantity"}, value); diff != "" {
+		t.Errorf("Generate() value fields diff (-want +got):\n%s", diff)
+	}
+	for _, name := range []string{"bodySite", "effective"} {
+		if f := find(s.Fields, name); f != nil {
+			t.Errorf("Generate() kept the prohibited field %s", name)
+		}
+	}
+	if _, err := Generate((&ppb.Patient{}).ProtoReflect().Descriptor(), Options{Profile: profile}); err == nil {
+		t.Errorf("Generate() of Patient with an Observation profile succeeded, want error")
+	}
+}
+
+var example = &Schema{Name: "Example", Fields: []*Field{
+	{Name: "id", Type: String, Path: "Example.id"},
+	{Name: "count", Type: Integer},
+	{Name: "tags", Type: String, Repeated: true},
+	{Name: "items", Type: Record, Repeated: true, Fields: []*Field{
+		{Name: "at", Type: Timestamp},
+	}},
+}}
+
+func TestSchema_BigQuery(t *testing.T) {
+	got, err := example.BigQuery()
+	if err != nil {
+		t.Fatalf("BigQuery() returned unexpected error: %v", err)
+	}
+	want := `[
+  {
+    "name": "id",
+    "type": "STRING",
+    "mode": "NULLABLE",
+    "description": "Example.id"
+  },
+  {
+    "name": "count",
+    "type": "INTEGER",
+    "mode": "NULLABLE"
+  },
+  {
+    "name": "tags",
+    "type": "STRING",
+    "mode": "REPEATED"
+  },
+  {
+    "name": "items",
+    "type": "RECORD",
+    "mode": "REPEATED",
+    "fields": [
+      {
+        "name": "at",
+        "type": "TIMESTAMP",
+        "mode": "NULLABLE"
+      }
+    ]
+  }
+]`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("BigQuery() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchema_Avro(t *testing.T) {
+	data, err := example.Avro("org.example")
+	if err != nil {
+		t.Fatalf("Avro() returned unexpected error: %v", err)
+	}
+	var got interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+	var want interface{}
+	if err := json.Unmarshal([]byte(`{
+  "type": "record", "name": "Example", "namespace": "org.example",
+  "fields": [
+    {"name": "id", "type": ["null", "string"], "default": null, "doc": "Example.id"},
+    {"name": "count", "type": ["null", "int"], "default": null},
+    {"name": "tags", "type": ["null", {"type": "array", "items": "string"}], "default": null},
+    {"name": "items", "type": ["null", {"type": "array", "items": {
+      "type": "record", "name": "Example_items",
+      "fields": [{"name": "at", "type": ["null", {"type": "long", "logicalType": "timestamp-micros"}], "default": null}]
+    }}], "default": null}
+  ]
+}`), &want); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Avro() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchema_Parquet(t *testing.T) {
+	want := `message Example {
+  optional binary id (STRING);
+  optional int32 count;
+  optional group tags (LIST) {
+    repeated group list {
+      optional binary element (STRING);
+    }
+  }
+  optional group items (LIST) {
+    repeated group list {
+      optional group element {
+        optional int64 at (TIMESTAMP(MICROS,true));
+      }
+    }
+  }
+}
+`
+	if diff := cmp.Diff(want, example.Parquet()); diff != "" {
+		t.Errorf("Parquet() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestFlattenRow(t *testing.T) {
+	row := map[string]interface{}{
+		"id":   "1",
+		"code": map[string]interface{}{"text": "x", "coding": []interface{}{map[string]interface{}{"code": "c", "x": map[string]interface{}{"y": 1.0}}}},
+	}
+	want := map[string]interface{}{
+		"id":          "1",
+		"code_text":   "x",
+		"code_coding": []interface{}{map[string]interface{}{"code": "c", "x_y": 1.0}},
+	}
+	if diff := cmp.Diff(want, FlattenRow(row)); diff != "" {
+		t.Errorf("FlattenRow() diff (-want +got):\n%s", diff)
+	}
+}
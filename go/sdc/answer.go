@@ -0,0 +1,212 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
+	qrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_response_go_proto"
+)
+
+// answerValue converts a FHIRPath result item to an answer value of the type
+// required by qi. It returns nil for primitives without a value.
+func answerValue(qi *qpb.Questionnaire_Item, v interface{}) (*qrpb.QuestionnaireResponse_Item_Answer_ValueX, error) {
+	typ := qi.GetType().GetValue()
+	sys, ok := fhirpath.SystemValue(v)
+	if !ok {
+		return nil, nil
+	}
+	mismatch := func() error {
+		return fmt.Errorf("cannot use %v as a %s answer", describe(v), codeString(typ))
+	}
+	switch typ {
+	case c4pb.QuestionnaireItemTypeCode_BOOLEAN:
+		if b, ok := sys.(bool); ok {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: b}}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_DECIMAL:
+		if d, ok := v.(*d4pb.Decimal); ok {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Decimal{Decimal: proto.Clone(d).(*d4pb.Decimal)}}, nil
+		}
+		if s, ok := decimalString(sys); ok {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Decimal{Decimal: &d4pb.Decimal{Value: s}}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_INTEGER:
+		if i, ok := sys.(int64); ok && i >= math.MinInt32 && i <= math.MaxInt32 {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Integer{Integer: &d4pb.Integer{Value: int32(i)}}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_DATE:
+		if t, ok := sys.(fhirpath.Temporal); ok && t.Kind != fhirpath.Time {
+			if t.Precision > fhirpath.PrecisionDay {
+				t.Precision = fhirpath.PrecisionDay
+			}
+			t.Kind = fhirpath.Date
+			d, _ := fhirpath.ProtoValue(t)
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Date{Date: d.(*d4pb.Date)}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_DATE_TIME:
+		if t, ok := sys.(fhirpath.Temporal); ok && t.Kind != fhirpath.Time {
+			t.Kind = fhirpath.DateTime
+			dt, _ := fhirpath.ProtoValue(t)
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_DateTime{DateTime: dt.(*d4pb.DateTime)}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_TIME:
+		if t, ok := sys.(fhirpath.Temporal); ok && t.Kind == fhirpath.Time {
+			tm, _ := fhirpath.ProtoValue(t)
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Time{Time: tm.(*d4pb.Time)}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_STRING, c4pb.QuestionnaireItemTypeCode_TEXT:
+		if s, ok := (fhirpath.Collection{sys}).StringValue(); ok {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_StringValue{StringValue: &d4pb.String{Value: s}}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_URL:
+		if s, ok := sys.(string); ok {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Uri{Uri: &d4pb.Uri{Value: s}}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_CHOICE, c4pb.QuestionnaireItemTypeCode_OPEN_CHOICE:
+		if val := choiceAnswer(qi, v, sys); val != nil {
+			return val, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_ATTACHMENT:
+		if a, ok := v.(*d4pb.Attachment); ok {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Attachment{Attachment: proto.Clone(a).(*d4pb.Attachment)}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_REFERENCE:
+		if r, ok := v.(*d4pb.Reference); ok {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Reference{Reference: proto.Clone(r).(*d4pb.Reference)}}, nil
+		}
+		if m, ok := v.(proto.Message); ok && elementpath.ResourceType(m) != "" {
+			r, err := referenceTo(m)
+			if err != nil {
+				return nil, err
+			}
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Reference{Reference: r}}, nil
+		}
+	case c4pb.QuestionnaireItemTypeCode_QUANTITY:
+		if q := quantityProto(v, sys); q != nil {
+			return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Quantity{Quantity: q}}, nil
+		}
+	default:
+		return nil, fmt.Errorf("items of type %s cannot have answers", codeString(typ))
+	}
+	return nil, mismatch()
+}
+
+// choiceAnswer converts v to a Coding answer. Codes and strings are matched
+// against the answer options of qi so that the answer carries the option's
+// system and display. Unmatched strings are kept as strings for open-choice
+// items.
+func choiceAnswer(qi *qpb.Questionnaire_Item, v, sys interface{}) *qrpb.QuestionnaireResponse_Item_Answer_ValueX {
+	switch x := v.(type) {
+	case *d4pb.Coding:
+		return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Coding{Coding: proto.Clone(x).(*d4pb.Coding)}}
+	case *d4pb.CodeableConcept:
+		if len(x.GetCoding()) > 0 {
+			return choiceAnswer(qi, x.GetCoding()[0], x.GetCoding()[0])
+		}
+		if t := x.GetText().GetValue(); t != "" {
+			return choiceAnswer(qi, t, t)
+		}
+		return nil
+	}
+	s, ok := sys.(string)
+	if !ok {
+		return nil
+	}
+	for _, opt := range qi.GetAnswerOption() {
+		switch ov := opt.GetValue().GetChoice().(type) {
+		case *qpb.Questionnaire_Item_AnswerOption_ValueX_Coding:
+			if ov.Coding.GetCode().GetValue() == s {
+				return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Coding{Coding: proto.Clone(ov.Coding).(*d4pb.Coding)}}
+			}
+		case *qpb.Questionnaire_Item_AnswerOption_ValueX_StringValue:
+			if ov.StringValue.GetValue() == s {
+				return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_StringValue{StringValue: &d4pb.String{Value: s}}}
+			}
+		}
+	}
+	if qi.GetType().GetValue() == c4pb.QuestionnaireItemTypeCode_OPEN_CHOICE {
+		return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_StringValue{StringValue: &d4pb.String{Value: s}}}
+	}
+	return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Coding{Coding: &d4pb.Coding{Code: &d4pb.Code{Value: s}}}}
+}
+
+func decimalString(v interface{}) (string, bool) {
+	switch x := v.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	}
+	return "", false
+}
+
+func quantityProto(v, sys interface{}) *d4pb.Quantity {
+	q, ok := sys.(fhirpath.Quantity)
+	if !ok {
+		return nil
+	}
+	if m, ok := v.(proto.Message); ok {
+		// Quantity profiles such as Age share the Quantity field layout.
+		if out, err := elementpath.Convert(m, (&d4pb.Quantity{}).ProtoReflect().Descriptor()); err == nil {
+			return out.(*d4pb.Quantity)
+		}
+	}
+	out, _ := fhirpath.ProtoValue(q)
+	return out.(*d4pb.Quantity)
+}
+
+// copyChoice sets the choice message dst to the option of the choice message
+// src with the same name. It returns false if src is empty or dst has no such
+// option.
+func copyChoice(dst, src protoreflect.Message) bool {
+	if !src.IsValid() {
+		return false
+	}
+	set := src.WhichOneof(src.Descriptor().Oneofs().Get(0))
+	if set == nil {
+		return false
+	}
+	dfd := dst.Descriptor().Fields().ByName(set.Name())
+	if dfd == nil || dfd.Message() == nil || dfd.Message().FullName() != set.Message().FullName() {
+		return false
+	}
+	dst.Set(dfd, protoreflect.ValueOfMessage(proto.Clone(src.Get(set).Message().Interface()).ProtoReflect()))
+	return true
+}
+
+func codeString(v c4pb.QuestionnaireItemTypeCode_Value) string {
+	return fhirpath.CodeString(v.Descriptor().Values().ByNumber(v.Number()))
+}
+
+func describe(v interface{}) string {
+	if m, ok := v.(proto.Message); ok {
+		return fhirpath.TypeName(m.ProtoReflect().Descriptor())
+	}
+	if s, ok := (fhirpath.Collection{v}).StringValue(); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", v)
+}
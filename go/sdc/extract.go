@@ -0,0 +1,488 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/uuid"
+	"github.com/google/fhir/go/structuremap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
+	qrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_response_go_proto"
+	smpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_map_go_proto"
+)
+
+// Extension URLs used by extraction.
+const (
+	ItemExtractionContextURL = "http://hl7.org/fhir/uv/sdc/StructureDefinition/sdc-questionnaire-itemExtractionContext"
+	ObservationExtractURL    = "http://hl7.org/fhir/uv/sdc/StructureDefinition/sdc-questionnaire-observationExtract"
+	TargetStructureMapURL    = "http://hl7.org/fhir/uv/sdc/StructureDefinition/sdc-questionnaire-targetStructureMap"
+	QuestionnaireUnitURL     = "http://hl7.org/fhir/StructureDefinition/questionnaire-unit"
+	corePackage              = "google.fhir.r4.core."
+)
+
+// ExtractOptions configures Extract.
+type ExtractOptions struct {
+	// StructureMaps returns StructureMaps by canonical URL. It is required for
+	// StructureMap-based extraction, and also resolves the imports of the map.
+	StructureMaps func(url string) (*smpb.StructureMap, error)
+	// Resolver is used by resolve() in expressions.
+	Resolver fhirpath.Resolver
+	// NewID returns the UUIDs used for the fullUrls of the extracted resources
+	// and by the uuid transform of StructureMaps. Random version 4 UUIDs are
+	// used if it is nil.
+	NewID func() string
+}
+
+// Extract converts qr, a response to q, to a transaction Bundle holding the
+// resources captured by the form.
+//
+// If q has a targetStructureMap extension, the referenced StructureMap is
+// executed with qr as its source and an empty Bundle as its target.
+// Otherwise extraction is definition-based and observation-based:
+//   - the itemExtractionContext extension of q or of a group names the type of
+//     the resource to create, or is an expression returning the resource to
+//     update, for the group's answers. Answers to items with a definition,
+//     i.e. "http://hl7.org/fhir/StructureDefinition/Patient#Patient.birthDate",
+//     are assigned to the element of the nearest enclosing context resource of
+//     the definition's type. A single resource per type is created for
+//     definitions outside a matching context. A group with a definition
+//     starts a new repetition of that element for its descendants.
+//   - each answer to an item with a code and the observationExtract extension,
+//     which is inherited by descendant items, becomes an Observation about the
+//     response's subject.
+func Extract(q *qpb.Questionnaire, qr *qrpb.QuestionnaireResponse, opts ExtractOptions) (*r4pb.Bundle, error) {
+	if opts.NewID == nil {
+		opts.NewID = uuid.New
+	}
+	if exts := extensions(q.GetExtension(), TargetStructureMapURL); len(exts) > 0 {
+		return extractWithMap(exts[0].GetValue().GetCanonical().GetValue(), qr, opts)
+	}
+	x := &extractor{opts: opts, q: q, qr: qr, implicit: map[string]*extractionContext{}}
+	root, err := x.context(q.GetExtension(), qr, nil, "Questionnaire")
+	if err != nil {
+		return nil, err
+	}
+	if err := x.items(q.GetItem(), qr.GetItem(), root, observationExtract(q.GetExtension(), false)); err != nil {
+		return nil, err
+	}
+	if root != nil {
+		x.emit(root.res)
+	}
+	for _, typ := range x.implicitOrder {
+		x.emit(x.implicit[typ].res)
+	}
+	bundle := &r4pb.Bundle{Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_TRANSACTION}}
+	for _, res := range x.resources {
+		entry, err := transactionEntry(res, opts.NewID())
+		if err != nil {
+			return nil, err
+		}
+		bundle.Entry = append(bundle.Entry, entry)
+	}
+	return bundle, nil
+}
+
+func extractWithMap(url string, qr *qrpb.QuestionnaireResponse, opts ExtractOptions) (*r4pb.Bundle, error) {
+	if opts.StructureMaps == nil {
+		return nil, fmt.Errorf("no StructureMap resolver for target map %q", url)
+	}
+	sm, err := opts.StructureMaps(url)
+	if err != nil {
+		return nil, fmt.Errorf("resolving target map %q: %w", url, err)
+	}
+	bundle := &r4pb.Bundle{}
+	err = structuremap.Transform(sm, qr, bundle, structuremap.Options{
+		Resolver:         opts.StructureMaps,
+		NewID:            opts.NewID,
+		FHIRPathResolver: opts.Resolver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("StructureMap %q: %w", url, err)
+	}
+	if bundle.GetType() == nil {
+		bundle.Type = &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_TRANSACTION}
+	}
+	return bundle, nil
+}
+
+// extractionContext is a resource under construction. Element paths bound
+// by groups with a definition map to the element repetition that their
+// descendants populate.
+type extractionContext struct {
+	typ    string
+	res    proto.Message
+	bound  map[string]protoreflect.Message
+	parent *extractionContext
+}
+
+type extractor struct {
+	opts          ExtractOptions
+	q             *qpb.Questionnaire
+	qr            *qrpb.QuestionnaireResponse
+	resources     []proto.Message
+	implicit      map[string]*extractionContext
+	implicitOrder []string
+}
+
+// context returns the extraction context declared by the
+// itemExtractionContext extension in exts, evaluated with focus as its input,
+// or nil if there is none.
+func (x *extractor) context(exts []*d4pb.Extension, focus proto.Message, parent *extractionContext, loc string) (*extractionContext, error) {
+	defs := extensions(exts, ItemExtractionContextURL)
+	if len(defs) == 0 {
+		return nil, nil
+	}
+	var res proto.Message
+	switch v := defs[0].GetValue().GetChoice().(type) {
+	case *d4pb.Extension_ValueX_Code:
+		var err error
+		if res, err = newResource(v.Code.GetValue()); err != nil {
+			return nil, fmt.Errorf("%s: %w", loc, err)
+		}
+	case *d4pb.Extension_ValueX_Expression:
+		val, err := x.evaluate(v.Expression, focus, loc)
+		if err != nil {
+			return nil, err
+		}
+		if len(val) == 0 {
+			return nil, nil
+		}
+		switch first := val[0].(type) {
+		case proto.Message:
+			if elementpath.ResourceType(first) == "" {
+				return nil, fmt.Errorf("%s: extraction context %s is not a resource", loc, describe(first))
+			}
+			res = proto.Clone(elementpath.Unwrap(first))
+		case string:
+			if res, err = newResource(first); err != nil {
+				return nil, fmt.Errorf("%s: %w", loc, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s: extraction context %s is not a resource", loc, describe(first))
+		}
+	default:
+		return nil, fmt.Errorf("%s: itemExtractionContext must be a code or an expression", loc)
+	}
+	return &extractionContext{typ: elementpath.ResourceType(res), res: res, parent: parent}, nil
+}
+
+func (x *extractor) evaluate(expr *d4pb.Expression, focus proto.Message, loc string) (fhirpath.Collection, error) {
+	if lang := expr.GetLanguage().GetValue(); lang != fhirPathLanguage {
+		return nil, fmt.Errorf("%s: unsupported expression language %q", loc, lang)
+	}
+	e, err := fhirpath.Compile(expr.GetExpression().GetValue())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", loc, err)
+	}
+	opts := []fhirpath.EvaluateOption{
+		fhirpath.WithResource(x.qr),
+		fhirpath.WithVariable("questionnaire", fhirpath.Collection{x.q}),
+	}
+	if x.opts.Resolver != nil {
+		opts = append(opts, fhirpath.WithResolver(x.opts.Resolver))
+	}
+	res, err := e.Evaluate(focus, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", loc, err)
+	}
+	return res, nil
+}
+
+// items extracts the response items ritems, which answer items of qitems.
+func (x *extractor) items(qitems []*qpb.Questionnaire_Item, ritems []*qrpb.QuestionnaireResponse_Item, ctx *extractionContext, obsExtract bool) error {
+	byLinkID := make(map[string]*qpb.Questionnaire_Item, len(qitems))
+	for _, qi := range qitems {
+		byLinkID[qi.GetLinkId().GetValue()] = qi
+	}
+	for _, ri := range ritems {
+		qi, ok := byLinkID[ri.GetLinkId().GetValue()]
+		if !ok {
+			return fmt.Errorf("item %q is not defined by the Questionnaire", ri.GetLinkId().GetValue())
+		}
+		if err := x.item(qi, ri, ctx, observationExtract(qi.GetExtension(), obsExtract)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) item(qi *qpb.Questionnaire_Item, ri *qrpb.QuestionnaireResponse_Item, ctx *extractionContext, obsExtract bool) error {
+	loc := fmt.Sprintf("item %q", qi.GetLinkId().GetValue())
+	own, err := x.context(qi.GetExtension(), ri, ctx, loc)
+	if err != nil {
+		return err
+	}
+	if own != nil {
+		ctx = own
+	}
+	if def := qi.GetDefinition().GetValue(); def != "" {
+		if qi.GetType().GetValue() == c4pb.QuestionnaireItemTypeCode_GROUP {
+			if ctx, err = x.bindGroup(def, ctx); err != nil {
+				return fmt.Errorf("%s: %w", loc, err)
+			}
+		} else {
+			for _, a := range ri.GetAnswer() {
+				if err := x.assign(def, answerProto(a), ctx); err != nil {
+					return fmt.Errorf("%s: %w", loc, err)
+				}
+			}
+		}
+	}
+	if obsExtract && len(qi.GetCode()) > 0 {
+		for _, a := range ri.GetAnswer() {
+			obs, err := x.observation(qi, a)
+			if err != nil {
+				return fmt.Errorf("%s: %w", loc, err)
+			}
+			x.resources = append(x.resources, obs)
+		}
+	}
+	if err := x.items(qi.GetItem(), ri.GetItem(), ctx, obsExtract); err != nil {
+		return err
+	}
+	for _, a := range ri.GetAnswer() {
+		if err := x.items(qi.GetItem(), a.GetItem(), ctx, obsExtract); err != nil {
+			return err
+		}
+	}
+	if own != nil {
+		x.emit(own.res)
+	}
+	return nil
+}
+
+// emit adds res to the extracted resources unless it is empty.
+func (x *extractor) emit(res proto.Message) {
+	if proto.Size(res) > 0 {
+		x.resources = append(x.resources, res)
+	}
+}
+
+// target returns the context receiving the answers of the definition of
+// type typ: the nearest enclosing context of that type, or the implicit one.
+func (x *extractor) target(typ string, ctx *extractionContext) (*extractionContext, error) {
+	for c := ctx; c != nil; c = c.parent {
+		if c.typ == typ {
+			return c, nil
+		}
+	}
+	if c, ok := x.implicit[typ]; ok {
+		return c, nil
+	}
+	res, err := newResource(typ)
+	if err != nil {
+		return nil, err
+	}
+	c := &extractionContext{typ: typ, res: res}
+	x.implicit[typ] = c
+	x.implicitOrder = append(x.implicitOrder, typ)
+	return c, nil
+}
+
+// element resolves the definition def to the context it applies to, the
+// message holding the element and the element's name. Intermediate elements
+// are created as needed.
+func (x *extractor) element(def string, ctx *extractionContext) (*extractionContext, protoreflect.Message, string, error) {
+	typ, elems, err := definitionPath(def)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if len(elems) == 0 {
+		return nil, nil, "", fmt.Errorf("definition %q does not name an element", def)
+	}
+	c, err := x.target(typ, ctx)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	msg := c.res.ProtoReflect()
+	start := 0
+	for i := len(elems) - 1; i > 0; i-- {
+		if m, ok := c.bound[typ+"."+strings.Join(elems[:i], ".")]; ok {
+			msg, start = m, i
+			break
+		}
+	}
+	for _, e := range elems[start : len(elems)-1] {
+		if msg, err = elementpath.Child(msg, e, false); err != nil {
+			return nil, nil, "", err
+		}
+	}
+	return c, msg, elems[len(elems)-1], nil
+}
+
+// bindGroup starts a new repetition of the element named by the definition of
+// a group and returns the context its descendants use.
+func (x *extractor) bindGroup(def string, ctx *extractionContext) (*extractionContext, error) {
+	c, msg, name, err := x.element(def, ctx)
+	if err != nil {
+		return nil, err
+	}
+	child, err := elementpath.Child(msg, name, true)
+	if err != nil {
+		return nil, err
+	}
+	typ, elems, _ := definitionPath(def)
+	bound := map[string]protoreflect.Message{typ + "." + strings.Join(elems, "."): child}
+	for k, v := range c.bound {
+		if _, ok := bound[k]; !ok {
+			bound[k] = v
+		}
+	}
+	return &extractionContext{typ: c.typ, res: c.res, bound: bound, parent: ctx}, nil
+}
+
+func (x *extractor) assign(def string, value proto.Message, ctx *extractionContext) error {
+	if value == nil {
+		return nil
+	}
+	_, msg, name, err := x.element(def, ctx)
+	if err != nil {
+		return err
+	}
+	return elementpath.Set(msg, name, value)
+}
+
+// observation returns the Observation recording answer a to qi.
+func (x *extractor) observation(qi *qpb.Questionnaire_Item, a *qrpb.QuestionnaireResponse_Item_Answer) (*obspb.Observation, error) {
+	obs := &obspb.Observation{
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code:   &d4pb.CodeableConcept{},
+	}
+	for _, c := range qi.GetCode() {
+		obs.Code.Coding = append(obs.Code.Coding, proto.Clone(c).(*d4pb.Coding))
+	}
+	if s := x.qr.GetSubject(); s != nil {
+		obs.Subject = proto.Clone(s).(*d4pb.Reference)
+	}
+	if e := x.qr.GetEncounter(); e != nil {
+		obs.Encounter = proto.Clone(e).(*d4pb.Reference)
+	}
+	if au := x.qr.GetAuthor(); au != nil {
+		obs.Performer = []*d4pb.Reference{proto.Clone(au).(*d4pb.Reference)}
+	}
+	if t := x.qr.GetAuthored(); t != nil {
+		obs.Effective = &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: proto.Clone(t).(*d4pb.DateTime)}}
+	}
+	if id := x.qr.GetId().GetValue(); id != "" {
+		ref, err := referenceTo(x.qr)
+		if err != nil {
+			return nil, err
+		}
+		obs.DerivedFrom = []*d4pb.Reference{ref}
+	}
+	value := answerProto(a)
+	if d, ok := value.(*d4pb.Decimal); ok {
+		// R4 Observations have no decimal value; decimals become quantities
+		// in the unit of the item, if any.
+		q := &d4pb.Quantity{Value: d}
+		if units := extensions(qi.GetExtension(), QuestionnaireUnitURL); len(units) > 0 {
+			u := units[0].GetValue().GetCoding()
+			q.Unit = u.GetDisplay()
+			if q.Unit == nil && u.GetCode() != nil {
+				q.Unit = &d4pb.String{Value: u.GetCode().GetValue()}
+			}
+			q.System, q.Code = u.GetSystem(), u.GetCode()
+		}
+		value = q
+	}
+	if value != nil {
+		if err := elementpath.Set(obs.ProtoReflect(), "value", value); err != nil {
+			return nil, err
+		}
+	}
+	return obs, nil
+}
+
+// observationExtract returns the value of the observationExtract extension in
+// exts, or inherited if there is none.
+func observationExtract(exts []*d4pb.Extension, inherited bool) bool {
+	if defs := extensions(exts, ObservationExtractURL); len(defs) > 0 {
+		return defs[0].GetValue().GetBoolean().GetValue()
+	}
+	return inherited
+}
+
+// answerProto returns the value of the answer a, or nil if it has none.
+func answerProto(a *qrpb.QuestionnaireResponse_Item_Answer) proto.Message {
+	v := a.GetValue()
+	if v == nil {
+		return nil
+	}
+	rm := v.ProtoReflect()
+	set := rm.WhichOneof(rm.Descriptor().Oneofs().Get(0))
+	if set == nil {
+		return nil
+	}
+	return rm.Get(set).Message().Interface()
+}
+
+// definitionPath returns the resource type and element names of an item
+// definition, i.e. "Patient" and ["name", "given"] for
+// "http://hl7.org/fhir/StructureDefinition/Patient#Patient.name.given". Slice
+// names are dropped.
+func definitionPath(def string) (string, []string, error) {
+	_, path, ok := strings.Cut(def, "#")
+	if !ok {
+		return "", nil, fmt.Errorf("definition %q has no element path", def)
+	}
+	typ, elems, err := elementpath.Split(path)
+	if err != nil {
+		return "", nil, err
+	}
+	for i, e := range elems {
+		elems[i], _, _ = strings.Cut(e, ":")
+	}
+	return typ, elems, nil
+}
+
+func newResource(typ string) (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(corePackage + typ))
+	if err != nil || !elementpath.IsResource(mt.Descriptor()) {
+		return nil, fmt.Errorf("unknown resource type %q", typ)
+	}
+	return mt.New().Interface(), nil
+}
+
+// transactionEntry returns a Bundle entry creating res, or updating it if it
+// has an id.
+func transactionEntry(res proto.Message, uuid string) (*r4pb.Bundle_Entry, error) {
+	entry := &r4pb.Bundle_Entry{
+		FullUrl: &d4pb.Uri{Value: "urn:uuid:" + uuid},
+		Request: &r4pb.Bundle_Entry_Request{
+			Method: &r4pb.Bundle_Entry_Request_MethodCode{Value: c4pb.HTTPVerbCode_POST},
+			Url:    &d4pb.Uri{Value: elementpath.ResourceType(res)},
+		},
+	}
+	if err := elementpath.Set(entry.ProtoReflect(), "resource", res); err != nil {
+		return nil, err
+	}
+	if id := elementpath.ID(res); id != "" {
+		entry.Request.Method.Value = c4pb.HTTPVerbCode_PUT
+		entry.Request.Url.Value += "/" + id
+	}
+	return entry, nil
+}
@@ -0,0 +1,353 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
+	qrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_response_go_proto"
+	smpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_map_go_proto"
+)
+
+const patientDefinition = "http://hl7.org/fhir/StructureDefinition/Patient#"
+
+func extension(url string, value *d4pb.Extension_ValueX) *d4pb.Extension {
+	return &d4pb.Extension{Url: &d4pb.Uri{Value: url}, Value: value}
+}
+
+func contextExt(typ string) *d4pb.Extension {
+	return extension(ItemExtractionContextURL, &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Code{Code: &d4pb.Code{Value: typ}}})
+}
+
+func observationExt() *d4pb.Extension {
+	return extension(ObservationExtractURL, &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: true}}})
+}
+
+func defined(qi *qpb.Questionnaire_Item, path string) *qpb.Questionnaire_Item {
+	qi.Definition = &d4pb.Uri{Value: patientDefinition + path}
+	return qi
+}
+
+func group(linkID string, items ...*qpb.Questionnaire_Item) *qpb.Questionnaire_Item {
+	g := question(linkID, c4pb.QuestionnaireItemTypeCode_GROUP)
+	g.Item = items
+	return g
+}
+
+func response(linkID string, answers []*qrpb.QuestionnaireResponse_Item_Answer, items ...*qrpb.QuestionnaireResponse_Item) *qrpb.QuestionnaireResponse_Item {
+	return &qrpb.QuestionnaireResponse_Item{LinkId: str(linkID), Answer: answers, Item: items}
+}
+
+func answers(values ...*qrpb.QuestionnaireResponse_Item_Answer_ValueX) []*qrpb.QuestionnaireResponse_Item_Answer {
+	var out []*qrpb.QuestionnaireResponse_Item_Answer
+	for _, v := range values {
+		out = append(out, &qrpb.QuestionnaireResponse_Item_Answer{Value: v})
+	}
+	return out
+}
+
+func stringAnswer(s string) *qrpb.QuestionnaireResponse_Item_Answer_ValueX {
+	return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_StringValue{StringValue: str(s)}}
+}
+
+func sequentialIDs() func() string {
+	n := 0
+	return func() string {
+		n++
+		return fmt.Sprintf("id-%d", n)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	weight := question("weight", c4pb.QuestionnaireItemTypeCode_DECIMAL,
+		extension(QuestionnaireUnitURL, &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Coding{Coding: &d4pb.Coding{
+			System: &d4pb.Uri{Value: "http://unitsofmeasure.org"},
+			Code:   &d4pb.Code{Value: "kg"},
+		}}}))
+	weightCode := &d4pb.Coding{System: &d4pb.Uri{Value: "http://loinc.org"}, Code: &d4pb.Code{Value: "29463-7"}}
+	weight.Code = []*d4pb.Coding{weightCode}
+	smoker := question("smoker", c4pb.QuestionnaireItemTypeCode_BOOLEAN)
+	smokerCode := &d4pb.Coding{System: &d4pb.Uri{Value: "http://loinc.org"}, Code: &d4pb.Code{Value: "72166-2"}}
+	smoker.Code = []*d4pb.Coding{smokerCode}
+	patient := group("patient",
+		defined(group("name",
+			defined(question("family", c4pb.QuestionnaireItemTypeCode_STRING), "Patient.name.family"),
+			defined(question("given", c4pb.QuestionnaireItemTypeCode_STRING), "Patient.name.given"),
+		), "Patient.name"),
+		defined(question("gender", c4pb.QuestionnaireItemTypeCode_CHOICE), "Patient.gender"),
+		defined(question("birthDate", c4pb.QuestionnaireItemTypeCode_DATE), "Patient.birthDate"),
+	)
+	patient.Extension = []*d4pb.Extension{contextExt("Patient")}
+	vitals := group("vitals", weight, smoker)
+	vitals.Extension = []*d4pb.Extension{observationExt()}
+	q := &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{
+		patient,
+		vitals,
+		question("comment", c4pb.QuestionnaireItemTypeCode_STRING),
+	}}
+
+	birthDate := &d4pb.Date{ValueUs: 321926400000000, Timezone: "Z", Precision: d4pb.Date_DAY}
+	authored := &d4pb.DateTime{ValueUs: 1777890600000000, Timezone: "Z", Precision: d4pb.DateTime_SECOND}
+	subject := &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}}
+	qr := &qrpb.QuestionnaireResponse{
+		Id:       &d4pb.Id{Value: "qr1"},
+		Subject:  subject,
+		Authored: authored,
+		Item: []*qrpb.QuestionnaireResponse_Item{
+			response("patient", nil,
+				response("name", nil,
+					response("family", answers(stringAnswer("Doe"))),
+					response("given", answers(stringAnswer("Jane"), stringAnswer("Q"))),
+				),
+				response("name", nil,
+					response("family", answers(stringAnswer("Roe"))),
+				),
+				response("gender", answers(&qrpb.QuestionnaireResponse_Item_Answer_ValueX{
+					Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Coding{Coding: &d4pb.Coding{Code: &d4pb.Code{Value: "female"}}},
+				})),
+				response("birthDate", answers(&qrpb.QuestionnaireResponse_Item_Answer_ValueX{
+					Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Date{Date: birthDate},
+				})),
+			),
+			response("vitals", nil,
+				response("weight", answers(&qrpb.QuestionnaireResponse_Item_Answer_ValueX{
+					Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Decimal{Decimal: &d4pb.Decimal{Value: "72.5"}},
+				})),
+				response("smoker", answers(&qrpb.QuestionnaireResponse_Item_Answer_ValueX{
+					Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: false}},
+				})),
+			),
+			response("comment", answers(stringAnswer("none"))),
+		},
+	}
+
+	got, err := Extract(q, qr, ExtractOptions{NewID: sequentialIDs()})
+	if err != nil {
+		t.Fatalf("Extract() returned unexpected error: %v", err)
+	}
+
+	observation := func(code *d4pb.Coding, value *obspb.Observation_ValueX) *obspb.Observation {
+		return &obspb.Observation{
+			Status:      &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+			Code:        &d4pb.CodeableConcept{Coding: []*d4pb.Coding{code}},
+			Subject:     subject,
+			Effective:   &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{DateTime: authored}},
+			DerivedFrom: []*d4pb.Reference{{Reference: &d4pb.Reference_QuestionnaireResponseId{QuestionnaireResponseId: &d4pb.ReferenceId{Value: "qr1"}}}},
+			Value:       value,
+		}
+	}
+	entry := func(id string, res *r4pb.ContainedResource, url string) *r4pb.Bundle_Entry {
+		return &r4pb.Bundle_Entry{
+			FullUrl:  &d4pb.Uri{Value: "urn:uuid:" + id},
+			Resource: res,
+			Request: &r4pb.Bundle_Entry_Request{
+				Method: &r4pb.Bundle_Entry_Request_MethodCode{Value: c4pb.HTTPVerbCode_POST},
+				Url:    &d4pb.Uri{Value: url},
+			},
+		}
+	}
+	want := &r4pb.Bundle{
+		Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_TRANSACTION},
+		Entry: []*r4pb.Bundle_Entry{
+			entry("id-1", &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{
+				Name: []*d4pb.HumanName{
+					{Family: str("Doe"), Given: []*d4pb.String{str("Jane"), str("Q")}},
+					{Family: str("Roe")},
+				},
+				Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+				BirthDate: birthDate,
+			}}}, "Patient"),
+			entry("id-2", &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: observation(weightCode,
+				&obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+					Value:  &d4pb.Decimal{Value: "72.5"},
+					Unit:   str("kg"),
+					System: &d4pb.Uri{Value: "http://unitsofmeasure.org"},
+					Code:   &d4pb.Code{Value: "kg"},
+				}}})}}, "Observation"),
+			entry("id-3", &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: observation(smokerCode,
+				&obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: false}}})}}, "Observation"),
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Extract() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestExtract_UpdateContext(t *testing.T) {
+	q := &qpb.Questionnaire{
+		Extension: []*d4pb.Extension{expressionExt(ItemExtractionContextURL, "", "%resource.subject.resolve()")},
+		Item: []*qpb.Questionnaire_Item{
+			defined(question("family", c4pb.QuestionnaireItemTypeCode_STRING), "Patient.name.family"),
+		},
+	}
+	qr := &qrpb.QuestionnaireResponse{
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Item:    []*qrpb.QuestionnaireResponse_Item{response("family", answers(stringAnswer("Smith")))},
+	}
+	resolver := func(ref string) (proto.Message, error) {
+		if ref != "Patient/p1" {
+			return nil, fmt.Errorf("unknown reference %q", ref)
+		}
+		return testPatient(), nil
+	}
+	got, err := Extract(q, qr, ExtractOptions{Resolver: resolver, NewID: sequentialIDs()})
+	if err != nil {
+		t.Fatalf("Extract() returned unexpected error: %v", err)
+	}
+	if len(got.GetEntry()) != 1 {
+		t.Fatalf("Extract() returned %d entries, want 1", len(got.GetEntry()))
+	}
+	e := got.GetEntry()[0]
+	if method, url := e.GetRequest().GetMethod().GetValue(), e.GetRequest().GetUrl().GetValue(); method != c4pb.HTTPVerbCode_PUT || url != "Patient/p1" {
+		t.Errorf("Extract() request = %v %s, want PUT Patient/p1", method, url)
+	}
+	if got := e.GetResource().GetPatient().GetName()[0].GetFamily().GetValue(); got != "Smith" {
+		t.Errorf("Extract() family = %q, want %q", got, "Smith")
+	}
+}
+
+func TestExtract_StructureMap(t *testing.T) {
+	const mapURL = "http://example.org/StructureMap/patient"
+	param := func(v *smpb.StructureMap_Group_Rule_Target_Parameter_ValueX) *smpb.StructureMap_Group_Rule_Target_Parameter {
+		return &smpb.StructureMap_Group_Rule_Target_Parameter{Value: v}
+	}
+	sm := &smpb.StructureMap{
+		Url: &d4pb.Uri{Value: mapURL},
+		Group: []*smpb.StructureMap_Group{{
+			Name: &d4pb.Id{Value: "patient"},
+			Input: []*smpb.StructureMap_Group_Input{
+				{Name: &d4pb.Id{Value: "src"}, Mode: &smpb.StructureMap_Group_Input_ModeCode{Value: c4pb.StructureMapInputModeCode_SOURCE}},
+				{Name: &d4pb.Id{Value: "bundle"}, Mode: &smpb.StructureMap_Group_Input_ModeCode{Value: c4pb.StructureMapInputModeCode_TARGET}},
+			},
+			Rule: []*smpb.StructureMap_Group_Rule{{
+				Name: &d4pb.Id{Value: "family"},
+				Source: []*smpb.StructureMap_Group_Rule_Source{{
+					Context:   &d4pb.Id{Value: "src"},
+					Element:   str("item"),
+					Variable:  &d4pb.Id{Value: "item"},
+					Condition: str("linkId = 'family'"),
+				}},
+				Target: []*smpb.StructureMap_Group_Rule_Target{
+					{Context: &d4pb.Id{Value: "bundle"}, Element: str("entry"), Variable: &d4pb.Id{Value: "entry"}},
+					{
+						Context:   &d4pb.Id{Value: "entry"},
+						Element:   str("resource"),
+						Variable:  &d4pb.Id{Value: "patient"},
+						Transform: &smpb.StructureMap_Group_Rule_Target_TransformCode{Value: c4pb.StructureMapTransformCode_CREATE},
+						Parameter: []*smpb.StructureMap_Group_Rule_Target_Parameter{param(&smpb.StructureMap_Group_Rule_Target_Parameter_ValueX{
+							Choice: &smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_StringValue{StringValue: str("Patient")},
+						})},
+					},
+					{Context: &d4pb.Id{Value: "patient"}, Element: str("name"), Variable: &d4pb.Id{Value: "name"}},
+					{
+						Context:   &d4pb.Id{Value: "name"},
+						Element:   str("family"),
+						Transform: &smpb.StructureMap_Group_Rule_Target_TransformCode{Value: c4pb.StructureMapTransformCode_EVALUATE},
+						Parameter: []*smpb.StructureMap_Group_Rule_Target_Parameter{
+							param(&smpb.StructureMap_Group_Rule_Target_Parameter_ValueX{
+								Choice: &smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_Id{Id: &d4pb.Id{Value: "item"}},
+							}),
+							param(&smpb.StructureMap_Group_Rule_Target_Parameter_ValueX{
+								Choice: &smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_StringValue{StringValue: str("answer.value")},
+							}),
+						},
+					},
+				},
+			}},
+		}},
+	}
+	q := &qpb.Questionnaire{
+		Extension: []*d4pb.Extension{extension(TargetStructureMapURL, &d4pb.Extension_ValueX{
+			Choice: &d4pb.Extension_ValueX_Canonical{Canonical: &d4pb.Canonical{Value: mapURL}},
+		})},
+		Item: []*qpb.Questionnaire_Item{question("family", c4pb.QuestionnaireItemTypeCode_STRING)},
+	}
+	qr := &qrpb.QuestionnaireResponse{
+		Item: []*qrpb.QuestionnaireResponse_Item{response("family", answers(stringAnswer("Doe")))},
+	}
+	maps := func(url string) (*smpb.StructureMap, error) {
+		if url != mapURL {
+			return nil, fmt.Errorf("unknown map %q", url)
+		}
+		return sm, nil
+	}
+	got, err := Extract(q, qr, ExtractOptions{StructureMaps: maps})
+	if err != nil {
+		t.Fatalf("Extract() returned unexpected error: %v", err)
+	}
+	want := &r4pb.Bundle{
+		Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_TRANSACTION},
+		Entry: []*r4pb.Bundle_Entry{{
+			Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{
+				Name: []*d4pb.HumanName{{Family: str("Doe")}},
+			}}},
+		}},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Extract() diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := Extract(q, qr, ExtractOptions{}); err == nil {
+		t.Errorf("Extract() without a StructureMap resolver succeeded, want error")
+	}
+}
+
+func TestExtract_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		item *qpb.Questionnaire_Item
+	}{
+		{
+			name: "definition without element path",
+			item: &qpb.Questionnaire_Item{LinkId: str("q"), Definition: &d4pb.Uri{Value: "http://hl7.org/fhir/StructureDefinition/Patient"}},
+		},
+		{
+			name: "unknown element",
+			item: defined(question("q", c4pb.QuestionnaireItemTypeCode_STRING), "Patient.nickname"),
+		},
+		{
+			name: "incompatible answer",
+			item: defined(question("q", c4pb.QuestionnaireItemTypeCode_STRING), "Patient.birthDate"),
+		},
+		{
+			name: "unknown context type",
+			item: question("q", c4pb.QuestionnaireItemTypeCode_GROUP, contextExt("Nonsense")),
+		},
+		{
+			name: "unknown link id",
+			item: question("other", c4pb.QuestionnaireItemTypeCode_STRING),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{test.item}}
+			qr := &qrpb.QuestionnaireResponse{Item: []*qrpb.QuestionnaireResponse_Item{response("q", answers(stringAnswer("x")))}}
+			if _, err := Extract(q, qr, ExtractOptions{}); err == nil {
+				t.Errorf("Extract() succeeded, want error")
+			}
+		})
+	}
+}
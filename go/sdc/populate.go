@@ -0,0 +1,353 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sdc implements operations from the HL7 Structured Data Capture
+// (SDC) implementation guide for R4 Questionnaires and
+// QuestionnaireResponses.
+//
+// Populate implements expression-based $populate: initial answers are
+// computed by evaluating the FHIRPath expressions attached to the
+// Questionnaire with the sdc-questionnaire-initialExpression and
+// sdc-questionnaire-itemPopulationContext extensions. Expressions can refer to
+// the launch context resources, i.e. %patient, and to the variables declared
+// with the variable extension.
+//
+// Extract implements $extract, turning a completed QuestionnaireResponse into
+// a transaction Bundle of the resources it captures, either by following item
+// definitions and the observationExtract extension or by executing the
+// StructureMap named by the targetStructureMap extension.
+//
+// Validate checks a QuestionnaireResponse against its Questionnaire and
+// reports the problems found in an OperationOutcome.
+package sdc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
+	qrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_response_go_proto"
+)
+
+// Extension URLs used by expression-based population.
+const (
+	LaunchContextURL         = "http://hl7.org/fhir/uv/sdc/StructureDefinition/sdc-questionnaire-launchContext"
+	VariableURL              = "http://hl7.org/fhir/StructureDefinition/variable"
+	InitialExpressionURL     = "http://hl7.org/fhir/uv/sdc/StructureDefinition/sdc-questionnaire-initialExpression"
+	ItemPopulationContextURL = "http://hl7.org/fhir/uv/sdc/StructureDefinition/sdc-questionnaire-itemPopulationContext"
+	fhirPathLanguage         = "text/fhirpath"
+	launchContextPatient     = "patient"
+)
+
+// PopulateOptions configures Populate.
+type PopulateOptions struct {
+	// Context holds the launch context resources by name, i.e. "patient" or
+	// "encounter". Each is available to expressions as %<name>.
+	Context map[string]proto.Message
+	// Resolver is used by resolve() in expressions.
+	Resolver fhirpath.Resolver
+	// Now is used as the authored time and by now() and today(). The current
+	// time is used if it is zero.
+	Now time.Time
+}
+
+// Populate returns a QuestionnaireResponse for q pre-filled from the launch
+// context. Items are included if they, or one of their descendants, received
+// an answer; display items are never included. Static initial values and
+// initially selected answer options are used for items without an
+// initialExpression.
+func Populate(q *qpb.Questionnaire, opts PopulateOptions) (*qrpb.QuestionnaireResponse, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	p := &populator{opts: opts, now: now}
+	vars := map[string]fhirpath.Collection{"questionnaire": {q}}
+	for name, res := range opts.Context {
+		vars[name] = fhirpath.Collection{res}
+	}
+	if err := checkLaunchContext(q, opts.Context); err != nil {
+		return nil, err
+	}
+	vars, err := p.variables(q.GetExtension(), vars, "Questionnaire")
+	if err != nil {
+		return nil, err
+	}
+	items, err := p.items(q.GetItem(), vars)
+	if err != nil {
+		return nil, err
+	}
+	authored, _ := fhirpath.ProtoValue(fhirpath.Temporal{Kind: fhirpath.DateTime, Time: now, Precision: fhirpath.PrecisionSecond})
+	qr := &qrpb.QuestionnaireResponse{
+		Status:   &qrpb.QuestionnaireResponse_StatusCode{Value: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS},
+		Authored: authored.(*d4pb.DateTime),
+		Item:     items,
+	}
+	if url := q.GetUrl().GetValue(); url != "" {
+		if v := q.GetVersion().GetValue(); v != "" {
+			url += "|" + v
+		}
+		qr.Questionnaire = &d4pb.Canonical{Value: url}
+	}
+	if patient, ok := opts.Context[launchContextPatient]; ok {
+		ref, err := referenceTo(patient)
+		if err != nil {
+			return nil, err
+		}
+		qr.Subject = ref
+	}
+	return qr, nil
+}
+
+// checkLaunchContext verifies that the supplied context resources have the
+// types declared by the launchContext extensions of q.
+func checkLaunchContext(q *qpb.Questionnaire, ctx map[string]proto.Message) error {
+	for _, ext := range extensions(q.GetExtension(), LaunchContextURL) {
+		var name, typ string
+		for _, sub := range ext.GetExtension() {
+			switch sub.GetUrl().GetValue() {
+			case "name":
+				name = sub.GetValue().GetCoding().GetCode().GetValue()
+			case "type":
+				typ = sub.GetValue().GetCode().GetValue()
+			}
+		}
+		res, ok := ctx[name]
+		if !ok || typ == "" {
+			continue
+		}
+		if got := elementpath.ResourceType(res); got != typ {
+			return fmt.Errorf("launch context %q must be a %s, got %s", name, typ, got)
+		}
+	}
+	return nil
+}
+
+type populator struct {
+	opts PopulateOptions
+	now  time.Time
+}
+
+func (p *populator) evaluate(expr *d4pb.Expression, vars map[string]fhirpath.Collection, loc string) (fhirpath.Collection, error) {
+	if lang := expr.GetLanguage().GetValue(); lang != fhirPathLanguage {
+		return nil, fmt.Errorf("%s: unsupported expression language %q", loc, lang)
+	}
+	e, err := fhirpath.Compile(expr.GetExpression().GetValue())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", loc, err)
+	}
+	opts := []fhirpath.EvaluateOption{fhirpath.WithNow(p.now)}
+	if p.opts.Resolver != nil {
+		opts = append(opts, fhirpath.WithResolver(p.opts.Resolver))
+	}
+	for name, val := range vars {
+		opts = append(opts, fhirpath.WithVariable(name, val))
+	}
+	res, err := e.Evaluate(nil, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", loc, err)
+	}
+	return res, nil
+}
+
+// variables evaluates the variable extensions in exts in order and returns
+// vars extended with their values. vars itself is not modified.
+func (p *populator) variables(exts []*d4pb.Extension, vars map[string]fhirpath.Collection, loc string) (map[string]fhirpath.Collection, error) {
+	defs := extensions(exts, VariableURL)
+	if len(defs) == 0 {
+		return vars, nil
+	}
+	out := make(map[string]fhirpath.Collection, len(vars)+len(defs))
+	for k, v := range vars {
+		out[k] = v
+	}
+	for _, ext := range defs {
+		expr := ext.GetValue().GetExpression()
+		name := expr.GetName().GetValue()
+		if name == "" {
+			return nil, fmt.Errorf("%s: variable without a name", loc)
+		}
+		val, err := p.evaluate(expr, out, loc+" variable "+name)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = val
+	}
+	return out, nil
+}
+
+func (p *populator) items(qitems []*qpb.Questionnaire_Item, vars map[string]fhirpath.Collection) ([]*qrpb.QuestionnaireResponse_Item, error) {
+	var out []*qrpb.QuestionnaireResponse_Item
+	for _, qi := range qitems {
+		items, err := p.item(qi, vars)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+	}
+	return out, nil
+}
+
+// item populates a single Questionnaire item. Groups with an
+// itemPopulationContext can produce several response items.
+func (p *populator) item(qi *qpb.Questionnaire_Item, vars map[string]fhirpath.Collection) ([]*qrpb.QuestionnaireResponse_Item, error) {
+	typ := qi.GetType().GetValue()
+	if typ == c4pb.QuestionnaireItemTypeCode_DISPLAY {
+		return nil, nil
+	}
+	loc := fmt.Sprintf("item %q", qi.GetLinkId().GetValue())
+	vars, err := p.variables(qi.GetExtension(), vars, loc)
+	if err != nil {
+		return nil, err
+	}
+	if typ == c4pb.QuestionnaireItemTypeCode_GROUP {
+		return p.group(qi, vars, loc)
+	}
+	answers, err := p.answers(qi, vars, loc)
+	if err != nil || len(answers) == 0 {
+		return nil, err
+	}
+	children, err := p.items(qi.GetItem(), vars)
+	if err != nil {
+		return nil, err
+	}
+	answers[0].Item = children
+	return []*qrpb.QuestionnaireResponse_Item{newResponseItem(qi, answers, nil)}, nil
+}
+
+func (p *populator) group(qi *qpb.Questionnaire_Item, vars map[string]fhirpath.Collection, loc string) ([]*qrpb.QuestionnaireResponse_Item, error) {
+	ctxExt := extensions(qi.GetExtension(), ItemPopulationContextURL)
+	if len(ctxExt) == 0 {
+		children, err := p.items(qi.GetItem(), vars)
+		if err != nil || len(children) == 0 {
+			return nil, err
+		}
+		return []*qrpb.QuestionnaireResponse_Item{newResponseItem(qi, nil, children)}, nil
+	}
+	expr := ctxExt[0].GetValue().GetExpression()
+	name := expr.GetName().GetValue()
+	if name == "" {
+		return nil, fmt.Errorf("%s: itemPopulationContext without a name", loc)
+	}
+	contexts, err := p.evaluate(expr, vars, loc+" itemPopulationContext")
+	if err != nil {
+		return nil, err
+	}
+	if !qi.GetRepeats().GetValue() && len(contexts) > 1 {
+		contexts = contexts[:1]
+	}
+	var out []*qrpb.QuestionnaireResponse_Item
+	for _, c := range contexts {
+		scoped := make(map[string]fhirpath.Collection, len(vars)+1)
+		for k, v := range vars {
+			scoped[k] = v
+		}
+		scoped[name] = fhirpath.Collection{c}
+		children, err := p.items(qi.GetItem(), scoped)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) > 0 {
+			out = append(out, newResponseItem(qi, nil, children))
+		}
+	}
+	return out, nil
+}
+
+// answers computes the initial answers of a question item.
+func (p *populator) answers(qi *qpb.Questionnaire_Item, vars map[string]fhirpath.Collection, loc string) ([]*qrpb.QuestionnaireResponse_Item_Answer, error) {
+	var out []*qrpb.QuestionnaireResponse_Item_Answer
+	if exts := extensions(qi.GetExtension(), InitialExpressionURL); len(exts) > 0 {
+		vals, err := p.evaluate(exts[0].GetValue().GetExpression(), vars, loc+" initialExpression")
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vals {
+			val, err := answerValue(qi, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", loc, err)
+			}
+			if val != nil {
+				out = append(out, &qrpb.QuestionnaireResponse_Item_Answer{Value: val})
+			}
+		}
+	} else {
+		for _, init := range qi.GetInitial() {
+			val := &qrpb.QuestionnaireResponse_Item_Answer_ValueX{}
+			if copyChoice(val.ProtoReflect(), init.GetValue().ProtoReflect()) {
+				out = append(out, &qrpb.QuestionnaireResponse_Item_Answer{Value: val})
+			}
+		}
+		for _, opt := range qi.GetAnswerOption() {
+			if !opt.GetInitialSelected().GetValue() {
+				continue
+			}
+			val := &qrpb.QuestionnaireResponse_Item_Answer_ValueX{}
+			if copyChoice(val.ProtoReflect(), opt.GetValue().ProtoReflect()) {
+				out = append(out, &qrpb.QuestionnaireResponse_Item_Answer{Value: val})
+			}
+		}
+	}
+	if !qi.GetRepeats().GetValue() && len(out) > 1 {
+		out = out[:1]
+	}
+	return out, nil
+}
+
+func newResponseItem(qi *qpb.Questionnaire_Item, answers []*qrpb.QuestionnaireResponse_Item_Answer, children []*qrpb.QuestionnaireResponse_Item) *qrpb.QuestionnaireResponse_Item {
+	item := &qrpb.QuestionnaireResponse_Item{
+		LinkId: proto.Clone(qi.GetLinkId()).(*d4pb.String),
+		Answer: answers,
+		Item:   children,
+	}
+	if qi.GetDefinition() != nil {
+		item.Definition = proto.Clone(qi.GetDefinition()).(*d4pb.Uri)
+	}
+	if qi.GetText() != nil {
+		item.Text = proto.Clone(qi.GetText()).(*d4pb.String)
+	}
+	return item
+}
+
+// extensions returns the extensions in exts with the given URL.
+func extensions(exts []*d4pb.Extension, url string) []*d4pb.Extension {
+	var out []*d4pb.Extension
+	for _, ext := range exts {
+		if ext.GetUrl().GetValue() == url {
+			out = append(out, ext)
+		}
+	}
+	return out
+}
+
+// referenceTo returns a normalized reference to the resource res.
+func referenceTo(res proto.Message) (*d4pb.Reference, error) {
+	res = elementpath.Unwrap(res)
+	typ := elementpath.ResourceType(res)
+	if typ == "" {
+		return nil, fmt.Errorf("%T is not a resource", res)
+	}
+	id := elementpath.ID(res)
+	if id == "" {
+		return nil, fmt.Errorf("%s resource has no id", typ)
+	}
+	return fhirtypes.Reference(typ, id), nil
+}
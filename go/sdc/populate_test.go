@@ -0,0 +1,299 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
+	qrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_response_go_proto"
+)
+
+var testNow = time.Date(2026, 5, 4, 10, 30, 0, 0, time.UTC)
+
+func str(s string) *d4pb.String { return &d4pb.String{Value: s} }
+
+func expressionExt(url, name, expr string) *d4pb.Extension {
+	e := &d4pb.Expression{
+		Language:   &d4pb.Code{Value: "text/fhirpath"},
+		Expression: str(expr),
+	}
+	if name != "" {
+		e.Name = &d4pb.Id{Value: name}
+	}
+	return &d4pb.Extension{
+		Url:   &d4pb.Uri{Value: url},
+		Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Expression{Expression: e}},
+	}
+}
+
+func launchContextExt(name, typ string) *d4pb.Extension {
+	return &d4pb.Extension{
+		Url: &d4pb.Uri{Value: LaunchContextURL},
+		Extension: []*d4pb.Extension{{
+			Url:   &d4pb.Uri{Value: "name"},
+			Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Coding{Coding: &d4pb.Coding{Code: &d4pb.Code{Value: name}}}},
+		}, {
+			Url:   &d4pb.Uri{Value: "type"},
+			Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_Code{Code: &d4pb.Code{Value: typ}}},
+		}},
+	}
+}
+
+func question(linkID string, typ c4pb.QuestionnaireItemTypeCode_Value, exts ...*d4pb.Extension) *qpb.Questionnaire_Item {
+	return &qpb.Questionnaire_Item{
+		LinkId:    str(linkID),
+		Type:      &qpb.Questionnaire_Item_TypeCode{Value: typ},
+		Extension: exts,
+	}
+}
+
+func testPatient() *ppb.Patient {
+	return &ppb.Patient{
+		Id: &d4pb.Id{Value: "p1"},
+		Name: []*d4pb.HumanName{{
+			Family: str("Doe"),
+			Given:  []*d4pb.String{str("Jane"), str("Q")},
+		}},
+		Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+		BirthDate: &d4pb.Date{ValueUs: 321926400000000, Timezone: "UTC", Precision: d4pb.Date_DAY},
+		Telecom: []*d4pb.ContactPoint{
+			{System: &d4pb.ContactPoint_SystemCode{Value: c4pb.ContactPointSystemCode_PHONE}, Value: str("555-1234")},
+			{System: &d4pb.ContactPoint_SystemCode{Value: c4pb.ContactPointSystemCode_EMAIL}, Value: str("jane@example.com")},
+		},
+	}
+}
+
+func genderOption(code string) *qpb.Questionnaire_Item_AnswerOption {
+	return &qpb.Questionnaire_Item_AnswerOption{Value: &qpb.Questionnaire_Item_AnswerOption_ValueX{
+		Choice: &qpb.Questionnaire_Item_AnswerOption_ValueX_Coding{Coding: &d4pb.Coding{
+			System: &d4pb.Uri{Value: "http://hl7.org/fhir/administrative-gender"},
+			Code:   &d4pb.Code{Value: code},
+		}},
+	}}
+}
+
+func TestPopulate(t *testing.T) {
+	gender := question("gender", c4pb.QuestionnaireItemTypeCode_CHOICE, expressionExt(InitialExpressionURL, "", "%patient.gender"))
+	gender.AnswerOption = []*qpb.Questionnaire_Item_AnswerOption{genderOption("male"), genderOption("female")}
+	consent := question("consent", c4pb.QuestionnaireItemTypeCode_BOOLEAN)
+	consent.Initial = []*qpb.Questionnaire_Item_Initial{{Value: &qpb.Questionnaire_Item_Initial_ValueX{
+		Choice: &qpb.Questionnaire_Item_Initial_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: true}},
+	}}}
+	contacts := question("contacts", c4pb.QuestionnaireItemTypeCode_GROUP, expressionExt(ItemPopulationContextURL, "contact", "%patient.telecom"))
+	contacts.Repeats = &d4pb.Boolean{Value: true}
+	contacts.Item = []*qpb.Questionnaire_Item{
+		question("contacts.system", c4pb.QuestionnaireItemTypeCode_STRING, expressionExt(InitialExpressionURL, "", "%contact.system")),
+		question("contacts.value", c4pb.QuestionnaireItemTypeCode_STRING, expressionExt(InitialExpressionURL, "", "%contact.value")),
+	}
+	q := &qpb.Questionnaire{
+		Url:     &d4pb.Uri{Value: "http://example.com/Questionnaire/intake"},
+		Version: str("1.0"),
+		Extension: []*d4pb.Extension{
+			launchContextExt("patient", "Patient"),
+			expressionExt(VariableURL, "given", "%patient.name.given"),
+		},
+		Item: []*qpb.Questionnaire_Item{
+			question("intro", c4pb.QuestionnaireItemTypeCode_DISPLAY),
+			{
+				LinkId: str("demographics"),
+				Text:   str("Demographics"),
+				Type:   &qpb.Questionnaire_Item_TypeCode{Value: c4pb.QuestionnaireItemTypeCode_GROUP},
+				Item: []*qpb.Questionnaire_Item{
+					question("first", c4pb.QuestionnaireItemTypeCode_STRING, expressionExt(InitialExpressionURL, "", "%given.first()")),
+					question("family", c4pb.QuestionnaireItemTypeCode_STRING, expressionExt(InitialExpressionURL, "", "%patient.name.family")),
+					question("birthDate", c4pb.QuestionnaireItemTypeCode_DATE, expressionExt(InitialExpressionURL, "", "%patient.birthDate")),
+					question("adult", c4pb.QuestionnaireItemTypeCode_BOOLEAN, expressionExt(InitialExpressionURL, "", "%patient.birthDate <= today() - 18 years")),
+					question("deceased", c4pb.QuestionnaireItemTypeCode_BOOLEAN, expressionExt(InitialExpressionURL, "", "%patient.deceased")),
+					gender,
+				},
+			},
+			contacts,
+			consent,
+		},
+	}
+
+	got, err := Populate(q, PopulateOptions{Context: map[string]proto.Message{"patient": testPatient()}, Now: testNow})
+	if err != nil {
+		t.Fatalf("Populate() returned unexpected error: %v", err)
+	}
+
+	answer := func(v *qrpb.QuestionnaireResponse_Item_Answer_ValueX) []*qrpb.QuestionnaireResponse_Item_Answer {
+		return []*qrpb.QuestionnaireResponse_Item_Answer{{Value: v}}
+	}
+	strAnswer := func(s string) []*qrpb.QuestionnaireResponse_Item_Answer {
+		return answer(&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_StringValue{StringValue: str(s)}})
+	}
+	boolAnswer := func(b bool) []*qrpb.QuestionnaireResponse_Item_Answer {
+		return answer(&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: b}}})
+	}
+	want := &qrpb.QuestionnaireResponse{
+		Questionnaire: &d4pb.Canonical{Value: "http://example.com/Questionnaire/intake|1.0"},
+		Status:        &qrpb.QuestionnaireResponse_StatusCode{Value: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS},
+		Subject:       &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Authored:      &d4pb.DateTime{ValueUs: testNow.UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND},
+		Item: []*qrpb.QuestionnaireResponse_Item{{
+			LinkId: str("demographics"),
+			Text:   str("Demographics"),
+			Item: []*qrpb.QuestionnaireResponse_Item{
+				{LinkId: str("first"), Answer: strAnswer("Jane")},
+				{LinkId: str("family"), Answer: strAnswer("Doe")},
+				{LinkId: str("birthDate"), Answer: answer(&qrpb.QuestionnaireResponse_Item_Answer_ValueX{
+					Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Date{Date: &d4pb.Date{ValueUs: 321926400000000, Timezone: "Z", Precision: d4pb.Date_DAY}},
+				})},
+				{LinkId: str("adult"), Answer: boolAnswer(true)},
+				{LinkId: str("gender"), Answer: answer(&qrpb.QuestionnaireResponse_Item_Answer_ValueX{
+					Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Coding{Coding: genderOption("female").GetValue().GetCoding()},
+				})},
+			},
+		}, {
+			LinkId: str("contacts"),
+			Item: []*qrpb.QuestionnaireResponse_Item{
+				{LinkId: str("contacts.system"), Answer: strAnswer("phone")},
+				{LinkId: str("contacts.value"), Answer: strAnswer("555-1234")},
+			},
+		}, {
+			LinkId: str("contacts"),
+			Item: []*qrpb.QuestionnaireResponse_Item{
+				{LinkId: str("contacts.system"), Answer: strAnswer("email")},
+				{LinkId: str("contacts.value"), Answer: strAnswer("jane@example.com")},
+			},
+		}, {
+			LinkId: str("consent"),
+			Answer: boolAnswer(true),
+		}},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Populate() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPopulate_AnswerTypes(t *testing.T) {
+	obs := &obspb.Observation{
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+			Value: &d4pb.Decimal{Value: "72.5"},
+			Unit:  str("kg"),
+		}}},
+	}
+	tests := []struct {
+		name string
+		typ  c4pb.QuestionnaireItemTypeCode_Value
+		expr string
+		want *qrpb.QuestionnaireResponse_Item_Answer_ValueX
+	}{
+		{
+			"quantity", c4pb.QuestionnaireItemTypeCode_QUANTITY, "%obs.value",
+			&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Quantity{Quantity: &d4pb.Quantity{Value: &d4pb.Decimal{Value: "72.5"}, Unit: str("kg")}}},
+		},
+		{
+			"decimal", c4pb.QuestionnaireItemTypeCode_DECIMAL, "%obs.value.value",
+			&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Decimal{Decimal: &d4pb.Decimal{Value: "72.5"}}},
+		},
+		{
+			"computed decimal", c4pb.QuestionnaireItemTypeCode_DECIMAL, "%obs.value.value * 2",
+			&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Decimal{Decimal: &d4pb.Decimal{Value: "145"}}},
+		},
+		{
+			"integer", c4pb.QuestionnaireItemTypeCode_INTEGER, "%patient.name.given.count()",
+			&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Integer{Integer: &d4pb.Integer{Value: 2}}},
+		},
+		{
+			"reference", c4pb.QuestionnaireItemTypeCode_REFERENCE, "%patient",
+			&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Reference{Reference: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}}}},
+		},
+		{
+			"dateTime", c4pb.QuestionnaireItemTypeCode_DATE_TIME, "@2020-01-02T03:04:05Z",
+			&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_DateTime{DateTime: &d4pb.DateTime{ValueUs: 1577934245000000, Timezone: "Z", Precision: d4pb.DateTime_SECOND}}},
+		},
+		{
+			"time", c4pb.QuestionnaireItemTypeCode_TIME, "@T10:15",
+			&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Time{Time: &d4pb.Time{ValueUs: 36900000000, Precision: d4pb.Time_SECOND}}},
+		},
+		{
+			"open choice", c4pb.QuestionnaireItemTypeCode_OPEN_CHOICE, "'other'",
+			&qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_StringValue{StringValue: str("other")}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{
+				question("q", test.typ, expressionExt(InitialExpressionURL, "", test.expr)),
+			}}
+			got, err := Populate(q, PopulateOptions{
+				Context: map[string]proto.Message{"patient": testPatient(), "obs": obs},
+				Now:     testNow,
+			})
+			if err != nil {
+				t.Fatalf("Populate() returned unexpected error: %v", err)
+			}
+			if len(got.GetItem()) != 1 || len(got.GetItem()[0].GetAnswer()) != 1 {
+				t.Fatalf("Populate() items = %v, want a single answer", got.GetItem())
+			}
+			if diff := cmp.Diff(test.want, got.GetItem()[0].GetAnswer()[0].GetValue(), protocmp.Transform()); diff != "" {
+				t.Errorf("Populate() answer diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPopulate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		q    *qpb.Questionnaire
+	}{
+		{
+			name: "wrong launch context type",
+			q:    &qpb.Questionnaire{Extension: []*d4pb.Extension{launchContextExt("patient", "Encounter")}},
+		},
+		{
+			name: "type mismatch",
+			q: &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{
+				question("q", c4pb.QuestionnaireItemTypeCode_INTEGER, expressionExt(InitialExpressionURL, "", "%patient.name.family")),
+			}},
+		},
+		{
+			name: "invalid expression",
+			q: &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{
+				question("q", c4pb.QuestionnaireItemTypeCode_STRING, expressionExt(InitialExpressionURL, "", "%patient.name.(")),
+			}},
+		},
+		{
+			name: "unnamed variable",
+			q:    &qpb.Questionnaire{Extension: []*d4pb.Extension{expressionExt(VariableURL, "", "1")}},
+		},
+		{
+			name: "unnamed population context",
+			q: &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{
+				question("g", c4pb.QuestionnaireItemTypeCode_GROUP, expressionExt(ItemPopulationContextURL, "", "%patient")),
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Populate(test.q, PopulateOptions{Context: map[string]proto.Message{"patient": testPatient()}}); err == nil {
+				t.Errorf("Populate() succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,594 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdc
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
+	qrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_response_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+// EnableWhenExpressionURL is the extension holding a FHIRPath expression that
+// determines whether an item is enabled.
+const EnableWhenExpressionURL = "http://hl7.org/fhir/uv/sdc/StructureDefinition/sdc-questionnaire-enableWhenExpression"
+
+// ValidateOptions configures Validate.
+type ValidateOptions struct {
+	// ValueSets returns the ValueSets named by answerValueSet by canonical URL.
+	// Value sets contained in the Questionnaire are found without it.
+	ValueSets func(url string) (*vspb.ValueSet, error)
+	// Resolver is used by resolve() in enableWhenExpressions.
+	Resolver fhirpath.Resolver
+}
+
+// Validate checks qr against q and returns the problems found as an
+// OperationOutcome. The returned error is only non-nil if validation itself
+// failed, i.e. an enableWhenExpression could not be evaluated.
+func Validate(q *qpb.Questionnaire, qr *qrpb.QuestionnaireResponse, opts ValidateOptions) (*oopb.OperationOutcome, error) {
+	er := errorreporter.NewOperationErrorReporter(fhirversion.R4)
+	if err := ValidateWithErrorReporter(q, qr, er, opts); err != nil {
+		return nil, err
+	}
+	return er.Outcome.R4Outcome, nil
+}
+
+// ValidateWithErrorReporter checks qr against q and reports problems to er:
+//   - items must be defined by the Questionnaire and appear no more often than
+//     allowed by repeats; questions may only have one answer unless they
+//     repeat,
+//   - answers must have the type required by the item, match its answer
+//     options, respect its maxLength and be members of its answerValueSet,
+//   - disabled items, per enableWhen or the enableWhenExpression extension,
+//     must not be answered,
+//   - required items that are enabled must be answered once the response is
+//     completed or amended.
+//
+// Codes that cannot be checked because the value set could not be resolved or
+// is defined by filters are reported as warnings.
+func ValidateWithErrorReporter(q *qpb.Questionnaire, qr *qrpb.QuestionnaireResponse, er errorreporter.ErrorReporter, opts ValidateOptions) error {
+	v := &validator{opts: opts, q: q, qr: qr, er: er, valueSets: map[string]*vspb.ValueSet{}}
+	status := qr.GetStatus().GetValue()
+	v.complete = status == c4pb.QuestionnaireResponseStatusCode_COMPLETED || status == c4pb.QuestionnaireResponseStatusCode_AMENDED
+	if want, got := q.GetUrl().GetValue(), qr.GetQuestionnaire().GetValue(); want != "" && got != "" {
+		if url, _, _ := strings.Cut(got, "|"); url != want {
+			if err := er.ReportValidationWarning("QuestionnaireResponse.questionnaire", fmt.Errorf("response is for Questionnaire %q, validating against %q", got, want)); err != nil {
+				return err
+			}
+		}
+	}
+	return v.items(q.GetItem(), qr.GetItem(), "QuestionnaireResponse", [][]*qrpb.QuestionnaireResponse_Item{qr.GetItem()})
+}
+
+type validator struct {
+	opts      ValidateOptions
+	q         *qpb.Questionnaire
+	qr        *qrpb.QuestionnaireResponse
+	er        errorreporter.ErrorReporter
+	complete  bool
+	valueSets map[string]*vspb.ValueSet
+}
+
+func (v *validator) error(path string, format string, args ...interface{}) error {
+	return v.er.ReportValidationError(path, fmt.Errorf(format, args...))
+}
+
+func (v *validator) warning(path string, format string, args ...interface{}) error {
+	return v.er.ReportValidationWarning(path, fmt.Errorf(format, args...))
+}
+
+// items validates the response items ritems, found at path, against qitems.
+// scopes holds the response item lists enclosing ritems, outermost first,
+// and is used to find the answers enableWhen conditions refer to.
+func (v *validator) items(qitems []*qpb.Questionnaire_Item, ritems []*qrpb.QuestionnaireResponse_Item, path string, scopes [][]*qrpb.QuestionnaireResponse_Item) error {
+	byLinkID := map[string][]int{}
+	for i, ri := range ritems {
+		id := ri.GetLinkId().GetValue()
+		byLinkID[id] = append(byLinkID[id], i)
+	}
+	known := map[string]bool{}
+	for _, qi := range qitems {
+		id := qi.GetLinkId().GetValue()
+		known[id] = true
+		indexes := byLinkID[id]
+		enabled, err := v.enabled(qi, scopes)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			for _, i := range indexes {
+				if hasContent(ritems[i]) {
+					if err := v.error(fmt.Sprintf("%s.item[%d]", path, i), "item %q is answered but not enabled", id); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+		if v.complete && qi.GetRequired().GetValue() && !anyContent(ritems, indexes) {
+			if err := v.error(path, "required item %q is not answered", id); err != nil {
+				return err
+			}
+		}
+		if len(indexes) > 1 && (qi.GetType().GetValue() != c4pb.QuestionnaireItemTypeCode_GROUP || !qi.GetRepeats().GetValue()) {
+			if err := v.error(fmt.Sprintf("%s.item[%d]", path, indexes[1]), "item %q appears %d times but does not repeat", id, len(indexes)); err != nil {
+				return err
+			}
+		}
+		for _, i := range indexes {
+			if err := v.item(qi, ritems[i], fmt.Sprintf("%s.item[%d]", path, i), scopes); err != nil {
+				return err
+			}
+		}
+	}
+	for i, ri := range ritems {
+		if id := ri.GetLinkId().GetValue(); !known[id] {
+			if err := v.error(fmt.Sprintf("%s.item[%d]", path, i), "item %q is not defined by the Questionnaire at this level", id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) item(qi *qpb.Questionnaire_Item, ri *qrpb.QuestionnaireResponse_Item, path string, scopes [][]*qrpb.QuestionnaireResponse_Item) error {
+	id := qi.GetLinkId().GetValue()
+	switch qi.GetType().GetValue() {
+	case c4pb.QuestionnaireItemTypeCode_DISPLAY:
+		if hasContent(ri) {
+			return v.error(path, "display item %q cannot have answers or items", id)
+		}
+		return nil
+	case c4pb.QuestionnaireItemTypeCode_GROUP:
+		if len(ri.GetAnswer()) > 0 {
+			if err := v.error(path, "group %q cannot have answers", id); err != nil {
+				return err
+			}
+		}
+		return v.items(qi.GetItem(), ri.GetItem(), path, append(scopes, ri.GetItem()))
+	}
+	if len(ri.GetItem()) > 0 {
+		if err := v.error(path, "items nested in question %q must be nested in its answers", id); err != nil {
+			return err
+		}
+	}
+	if len(ri.GetAnswer()) > 1 && !qi.GetRepeats().GetValue() {
+		if err := v.error(path, "item %q has %d answers but does not repeat", id, len(ri.GetAnswer())); err != nil {
+			return err
+		}
+	}
+	for j, a := range ri.GetAnswer() {
+		apath := fmt.Sprintf("%s.answer[%d]", path, j)
+		if err := v.answer(qi, a, apath); err != nil {
+			return err
+		}
+		if err := v.items(qi.GetItem(), a.GetItem(), apath, append(scopes, a.GetItem())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// answerTypes maps item types to the answer value types allowed for them,
+// named by the field of the answer value choice.
+var answerTypes = map[c4pb.QuestionnaireItemTypeCode_Value][]protoreflect.Name{
+	c4pb.QuestionnaireItemTypeCode_BOOLEAN:     {"boolean"},
+	c4pb.QuestionnaireItemTypeCode_DECIMAL:     {"decimal"},
+	c4pb.QuestionnaireItemTypeCode_INTEGER:     {"integer"},
+	c4pb.QuestionnaireItemTypeCode_DATE:        {"date"},
+	c4pb.QuestionnaireItemTypeCode_DATE_TIME:   {"date_time"},
+	c4pb.QuestionnaireItemTypeCode_TIME:        {"time"},
+	c4pb.QuestionnaireItemTypeCode_STRING:      {"string_value"},
+	c4pb.QuestionnaireItemTypeCode_TEXT:        {"string_value"},
+	c4pb.QuestionnaireItemTypeCode_URL:         {"uri"},
+	c4pb.QuestionnaireItemTypeCode_CHOICE:      {"coding"},
+	c4pb.QuestionnaireItemTypeCode_OPEN_CHOICE: {"coding", "string_value"},
+	c4pb.QuestionnaireItemTypeCode_ATTACHMENT:  {"attachment"},
+	c4pb.QuestionnaireItemTypeCode_REFERENCE:   {"reference"},
+	c4pb.QuestionnaireItemTypeCode_QUANTITY:    {"quantity"},
+}
+
+func (v *validator) answer(qi *qpb.Questionnaire_Item, a *qrpb.QuestionnaireResponse_Item_Answer, path string) error {
+	id := qi.GetLinkId().GetValue()
+	typ := qi.GetType().GetValue()
+	value := answerProto(a)
+	if value == nil {
+		return v.error(path, "answer to item %q has no value", id)
+	}
+	rm := a.GetValue().ProtoReflect()
+	field := rm.WhichOneof(rm.Descriptor().Oneofs().Get(0)).Name()
+	allowed := answerTypes[typ]
+	for _, opt := range qi.GetAnswerOption() {
+		// Choice items may also offer integer, date, time or string options.
+		orm := opt.GetValue().ProtoReflect()
+		if set := orm.WhichOneof(orm.Descriptor().Oneofs().Get(0)); set != nil {
+			allowed = append(allowed, set.Name())
+		}
+	}
+	if !containsName(allowed, field) {
+		return v.error(path, "answer to %s item %q cannot be a %s", codeString(typ), id, fhirpath.TypeName(value.ProtoReflect().Descriptor()))
+	}
+	if max := qi.GetMaxLength(); max != nil {
+		if s, ok := fhirpath.SystemValue(value); ok {
+			if str, isString := s.(string); isString && utf8.RuneCountInString(str) > int(max.GetValue()) {
+				if err := v.error(path, "answer to item %q is longer than %d characters", id, max.GetValue()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if len(qi.GetAnswerOption()) > 0 && !(typ == c4pb.QuestionnaireItemTypeCode_OPEN_CHOICE && field == "string_value") {
+		if !matchesOption(qi.GetAnswerOption(), value) {
+			if err := v.error(path, "answer to item %q is not one of its answer options", id); err != nil {
+				return err
+			}
+		}
+	}
+	if c, ok := value.(*d4pb.Coding); ok && qi.GetAnswerValueSet() != nil {
+		return v.checkValueSet(qi.GetAnswerValueSet().GetValue(), c, id, path)
+	}
+	return nil
+}
+
+func (v *validator) checkValueSet(url string, c *d4pb.Coding, id, path string) error {
+	vs, err := v.valueSet(url)
+	if err != nil {
+		return v.warning(path, "cannot check answer to item %q: %v", id, err)
+	}
+	in, known := valueSetContains(vs, c.GetSystem().GetValue(), c.GetCode().GetValue())
+	switch {
+	case !known:
+		return v.warning(path, "cannot check answer to item %q: membership of value set %q cannot be determined without a terminology service", id, url)
+	case !in:
+		return v.error(path, "answer %s|%s to item %q is not in value set %q", c.GetSystem().GetValue(), c.GetCode().GetValue(), id, url)
+	}
+	return nil
+}
+
+func (v *validator) valueSet(url string) (*vspb.ValueSet, error) {
+	if vs, ok := v.valueSets[url]; ok {
+		return vs, nil
+	}
+	var vs *vspb.ValueSet
+	if strings.HasPrefix(url, "#") {
+		for _, c := range v.q.GetContained() {
+			m, err := c.UnmarshalNew()
+			if err != nil {
+				return nil, err
+			}
+			if x, ok := elementpath.Unwrap(m).(*vspb.ValueSet); ok && x.GetId().GetValue() == url[1:] {
+				vs = x
+				break
+			}
+		}
+		if vs == nil {
+			return nil, fmt.Errorf("no contained value set %q", url)
+		}
+	} else {
+		if v.opts.ValueSets == nil {
+			return nil, fmt.Errorf("no resolver for value set %q", url)
+		}
+		var err error
+		if vs, err = v.opts.ValueSets(url); err != nil {
+			return nil, err
+		}
+	}
+	v.valueSets[url] = vs
+	return vs, nil
+}
+
+// valueSetContains reports whether the code is in vs, using its expansion if
+// present and otherwise its enumerated composition. known is false if the
+// composition uses filters or other value sets.
+func valueSetContains(vs *vspb.ValueSet, system, code string) (in, known bool) {
+	if exp := vs.GetExpansion(); exp != nil {
+		return expansionContains(exp.GetContains(), system, code), true
+	}
+	compose := vs.GetCompose()
+	match := func(sets []*vspb.ValueSet_Compose_ConceptSet) (bool, bool) {
+		for _, set := range sets {
+			if len(set.GetFilter()) > 0 || len(set.GetValueSet()) > 0 {
+				return false, false
+			}
+			if set.GetSystem().GetValue() != system {
+				continue
+			}
+			if len(set.GetConcept()) == 0 {
+				return true, true
+			}
+			for _, c := range set.GetConcept() {
+				if c.GetCode().GetValue() == code {
+					return true, true
+				}
+			}
+		}
+		return false, true
+	}
+	excluded, known := match(compose.GetExclude())
+	if !known {
+		return false, false
+	}
+	if excluded {
+		return false, true
+	}
+	return match(compose.GetInclude())
+}
+
+func expansionContains(contains []*vspb.ValueSet_Expansion_Contains, system, code string) bool {
+	for _, c := range contains {
+		if c.GetSystem().GetValue() == system && c.GetCode().GetValue() == code && !c.GetAbstract().GetValue() {
+			return true
+		}
+		if expansionContains(c.GetContains(), system, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// enabled evaluates the enableWhen conditions or enableWhenExpression of qi.
+func (v *validator) enabled(qi *qpb.Questionnaire_Item, scopes [][]*qrpb.QuestionnaireResponse_Item) (bool, error) {
+	if exts := extensions(qi.GetExtension(), EnableWhenExpressionURL); len(exts) > 0 {
+		return v.evaluateBool(exts[0].GetValue().GetExpression(), fmt.Sprintf("item %q enableWhenExpression", qi.GetLinkId().GetValue()))
+	}
+	conds := qi.GetEnableWhen()
+	if len(conds) == 0 {
+		return true, nil
+	}
+	any := qi.GetEnableBehavior().GetValue() == c4pb.EnableWhenBehaviorCode_ANY
+	for _, ew := range conds {
+		ok := conditionHolds(ew, answersTo(ew.GetQuestion().GetValue(), scopes))
+		if ok && any {
+			return true, nil
+		}
+		if !ok && !any {
+			return false, nil
+		}
+	}
+	return !any, nil
+}
+
+func (v *validator) evaluateBool(expr *d4pb.Expression, loc string) (bool, error) {
+	if lang := expr.GetLanguage().GetValue(); lang != fhirPathLanguage {
+		return false, fmt.Errorf("%s: unsupported expression language %q", loc, lang)
+	}
+	e, err := fhirpath.Compile(expr.GetExpression().GetValue())
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", loc, err)
+	}
+	opts := []fhirpath.EvaluateOption{
+		fhirpath.WithResource(v.qr),
+		fhirpath.WithVariable("questionnaire", fhirpath.Collection{v.q}),
+	}
+	if v.opts.Resolver != nil {
+		opts = append(opts, fhirpath.WithResolver(v.opts.Resolver))
+	}
+	ok, err := e.EvaluateBool(v.qr, opts...)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", loc, err)
+	}
+	return ok, nil
+}
+
+// answersTo returns the answers to the question linkID, looking in the
+// innermost scope that contains it.
+func answersTo(linkID string, scopes [][]*qrpb.QuestionnaireResponse_Item) []*qrpb.QuestionnaireResponse_Item_Answer {
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if answers, found := findAnswers(scopes[i], linkID); found {
+			return answers
+		}
+	}
+	return nil
+}
+
+func findAnswers(items []*qrpb.QuestionnaireResponse_Item, linkID string) ([]*qrpb.QuestionnaireResponse_Item_Answer, bool) {
+	for _, ri := range items {
+		if ri.GetLinkId().GetValue() == linkID {
+			return ri.GetAnswer(), true
+		}
+		if answers, found := findAnswers(ri.GetItem(), linkID); found {
+			return answers, true
+		}
+		for _, a := range ri.GetAnswer() {
+			if answers, found := findAnswers(a.GetItem(), linkID); found {
+				return answers, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func conditionHolds(ew *qpb.Questionnaire_Item_EnableWhen, answers []*qrpb.QuestionnaireResponse_Item_Answer) bool {
+	op := ew.GetOperator().GetValue()
+	want := choiceProto(ew.GetAnswer())
+	if op == c4pb.QuestionnaireItemOperatorCode_EXISTS {
+		b, _ := want.(*d4pb.Boolean)
+		return (len(answers) > 0) == b.GetValue()
+	}
+	if op == c4pb.QuestionnaireItemOperatorCode_NOT_EQUAL_TO {
+		for _, a := range answers {
+			if c, ok := compareAnswer(answerProto(a), want); ok && c == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	for _, a := range answers {
+		c, ok := compareAnswer(answerProto(a), want)
+		if !ok {
+			continue
+		}
+		switch op {
+		case c4pb.QuestionnaireItemOperatorCode_EQUALS:
+			ok = c == 0
+		case c4pb.QuestionnaireItemOperatorCode_GREATER_THAN:
+			ok = c > 0
+		case c4pb.QuestionnaireItemOperatorCode_LESS_THAN:
+			ok = c < 0
+		case c4pb.QuestionnaireItemOperatorCode_GREATER_THAN_OR_EQUAL_TO:
+			ok = c >= 0
+		case c4pb.QuestionnaireItemOperatorCode_LESS_THAN_OR_EQUAL_TO:
+			ok = c <= 0
+		default:
+			ok = false
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// compareAnswer compares an answer with an enableWhen value. ok is false if
+// they are not comparable. Codings are equal if their codes match and, when
+// the condition has one, their systems; they have no order.
+func compareAnswer(got, want proto.Message) (int, bool) {
+	if got == nil || want == nil {
+		return 0, false
+	}
+	if wc, ok := want.(*d4pb.Coding); ok {
+		gc, ok := got.(*d4pb.Coding)
+		if !ok || gc.GetCode().GetValue() != wc.GetCode().GetValue() {
+			return 1, ok
+		}
+		if sys := wc.GetSystem().GetValue(); sys != "" && sys != gc.GetSystem().GetValue() {
+			return 1, true
+		}
+		return 0, true
+	}
+	if _, ok := want.(*d4pb.Reference); ok {
+		if proto.Equal(got, want) {
+			return 0, true
+		}
+		return 1, true
+	}
+	g, gok := fhirpath.SystemValue(got)
+	w, wok := fhirpath.SystemValue(want)
+	if !gok || !wok {
+		return 0, false
+	}
+	switch wv := w.(type) {
+	case bool:
+		if gv, ok := g.(bool); ok && gv == wv {
+			return 0, true
+		}
+		return 1, true
+	case string:
+		gv, ok := g.(string)
+		return strings.Compare(gv, wv), ok
+	case int64, float64:
+		gf, gok := number(g)
+		wf, _ := number(wv)
+		return compareFloats(gf, wf), gok
+	case fhirpath.Temporal:
+		gv, ok := g.(fhirpath.Temporal)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case gv.Time.Before(wv.Time):
+			return -1, true
+		case gv.Time.After(wv.Time):
+			return 1, true
+		}
+		return 0, true
+	case fhirpath.Quantity:
+		gv, ok := g.(fhirpath.Quantity)
+		if !ok || gv.Unit != wv.Unit {
+			return 0, false
+		}
+		return compareFloats(gv.Value, wv.Value), true
+	}
+	return 0, false
+}
+
+func number(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func matchesOption(options []*qpb.Questionnaire_Item_AnswerOption, value proto.Message) bool {
+	for _, opt := range options {
+		ov := choiceProto(opt.GetValue())
+		if c, ok := compareAnswer(value, ov); ok && c == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// choiceProto returns the value set in the choice message m, or nil.
+func choiceProto(m proto.Message) proto.Message {
+	rm := m.ProtoReflect()
+	if !rm.IsValid() {
+		return nil
+	}
+	set := rm.WhichOneof(rm.Descriptor().Oneofs().Get(0))
+	if set == nil {
+		return nil
+	}
+	return rm.Get(set).Message().Interface()
+}
+
+func containsName(names []protoreflect.Name, name protoreflect.Name) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasContent returns true iff ri has an answer or a nested item.
+func hasContent(ri *qrpb.QuestionnaireResponse_Item) bool {
+	return len(ri.GetAnswer()) > 0 || len(ri.GetItem()) > 0
+}
+
+func anyContent(ritems []*qrpb.QuestionnaireResponse_Item, indexes []int) bool {
+	for _, i := range indexes {
+		if hasContent(ritems[i]) {
+			return true
+		}
+	}
+	return false
+}
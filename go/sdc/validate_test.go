@@ -0,0 +1,383 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	qpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_go_proto"
+	qrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_response_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+const countrySystem = "urn:iso:std:iso:3166"
+
+func coding(system, code string) *d4pb.Coding {
+	return &d4pb.Coding{System: &d4pb.Uri{Value: system}, Code: &d4pb.Code{Value: code}}
+}
+
+func codingAnswer(system, code string) *qrpb.QuestionnaireResponse_Item_Answer_ValueX {
+	return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Coding{Coding: coding(system, code)}}
+}
+
+func booleanAnswer(b bool) *qrpb.QuestionnaireResponse_Item_Answer_ValueX {
+	return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: b}}}
+}
+
+func integerAnswer(i int32) *qrpb.QuestionnaireResponse_Item_Answer_ValueX {
+	return &qrpb.QuestionnaireResponse_Item_Answer_ValueX{Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Integer{Integer: &d4pb.Integer{Value: i}}}
+}
+
+func enableWhen(question string, op c4pb.QuestionnaireItemOperatorCode_Value, answer *qpb.Questionnaire_Item_EnableWhen_AnswerX) *qpb.Questionnaire_Item_EnableWhen {
+	return &qpb.Questionnaire_Item_EnableWhen{
+		Question: str(question),
+		Operator: &qpb.Questionnaire_Item_EnableWhen_OperatorCode{Value: op},
+		Answer:   answer,
+	}
+}
+
+func validateQuestionnaire(t *testing.T) *qpb.Questionnaire {
+	t.Helper()
+	countries, err := anypb.New(&r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_ValueSet{ValueSet: &vspb.ValueSet{
+		Id: &d4pb.Id{Value: "countries"},
+		Compose: &vspb.ValueSet_Compose{
+			Include: []*vspb.ValueSet_Compose_ConceptSet{{
+				System: &d4pb.Uri{Value: countrySystem},
+				Concept: []*vspb.ValueSet_Compose_ConceptSet_ConceptReference{
+					{Code: &d4pb.Code{Value: "US"}},
+					{Code: &d4pb.Code{Value: "CA"}},
+				},
+			}},
+		},
+	}}})
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+
+	name := question("name", c4pb.QuestionnaireItemTypeCode_STRING)
+	name.Required = &d4pb.Boolean{Value: true}
+	name.MaxLength = &d4pb.Integer{Value: 5}
+	smoker := question("smoker", c4pb.QuestionnaireItemTypeCode_BOOLEAN)
+	packs := question("packs", c4pb.QuestionnaireItemTypeCode_INTEGER)
+	packs.Required = &d4pb.Boolean{Value: true}
+	packs.EnableWhen = []*qpb.Questionnaire_Item_EnableWhen{
+		enableWhen("smoker", c4pb.QuestionnaireItemOperatorCode_EQUALS, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+			Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_Boolean{Boolean: &d4pb.Boolean{Value: true}},
+		}),
+	}
+	heavy := question("heavy", c4pb.QuestionnaireItemTypeCode_DISPLAY)
+	heavy.EnableWhen = []*qpb.Questionnaire_Item_EnableWhen{
+		enableWhen("packs", c4pb.QuestionnaireItemOperatorCode_GREATER_THAN_OR_EQUAL_TO, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+			Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_Integer{Integer: &d4pb.Integer{Value: 2}},
+		}),
+	}
+	color := question("color", c4pb.QuestionnaireItemTypeCode_CHOICE)
+	color.Repeats = &d4pb.Boolean{Value: true}
+	for _, c := range []string{"red", "blue"} {
+		color.AnswerOption = append(color.AnswerOption, &qpb.Questionnaire_Item_AnswerOption{
+			Value: &qpb.Questionnaire_Item_AnswerOption_ValueX{
+				Choice: &qpb.Questionnaire_Item_AnswerOption_ValueX_Coding{Coding: coding("urn:colors", c)},
+			},
+		})
+	}
+	country := question("country", c4pb.QuestionnaireItemTypeCode_OPEN_CHOICE)
+	country.AnswerValueSet = &d4pb.Canonical{Value: "#countries"}
+	language := question("language", c4pb.QuestionnaireItemTypeCode_CHOICE)
+	language.AnswerValueSet = &d4pb.Canonical{Value: "http://example.com/ValueSet/languages"}
+	allergy := group("allergy", question("substance", c4pb.QuestionnaireItemTypeCode_STRING))
+	allergy.Repeats = &d4pb.Boolean{Value: true}
+	adult := question("adult", c4pb.QuestionnaireItemTypeCode_STRING,
+		expressionExt(EnableWhenExpressionURL, "", "%resource.item.where(linkId = 'name').exists()"))
+	return &qpb.Questionnaire{
+		Url:       &d4pb.Uri{Value: "http://example.com/Questionnaire/q"},
+		Contained: []*anypb.Any{countries},
+		Item:      []*qpb.Questionnaire_Item{name, smoker, packs, heavy, color, country, language, allergy, adult},
+	}
+}
+
+// issues summarizes an OperationOutcome as "severity expression" strings.
+func issues(outcome *oopb.OperationOutcome) []string {
+	var out []string
+	for _, issue := range outcome.GetIssue() {
+		s := issue.GetSeverity().GetValue().String()
+		for _, e := range issue.GetExpression() {
+			s += " " + e.GetValue()
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestValidate(t *testing.T) {
+	languages := func(url string) (*vspb.ValueSet, error) {
+		if url != "http://example.com/ValueSet/languages" {
+			return nil, fmt.Errorf("unknown value set %q", url)
+		}
+		return &vspb.ValueSet{Expansion: &vspb.ValueSet_Expansion{
+			Contains: []*vspb.ValueSet_Expansion_Contains{{
+				System: &d4pb.Uri{Value: "urn:ietf:bcp:47"},
+				Code:   &d4pb.Code{Value: "en"},
+			}},
+		}}, nil
+	}
+	tests := []struct {
+		name   string
+		status c4pb.QuestionnaireResponseStatusCode_Value
+		items  []*qrpb.QuestionnaireResponse_Item
+		want   []string
+	}{
+		{
+			name:   "valid",
+			status: c4pb.QuestionnaireResponseStatusCode_COMPLETED,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("name", answers(stringAnswer("Jo"))),
+				response("smoker", answers(booleanAnswer(true))),
+				response("packs", answers(integerAnswer(1))),
+				response("color", answers(codingAnswer("urn:colors", "red"), codingAnswer("urn:colors", "blue"))),
+				response("country", answers(codingAnswer(countrySystem, "CA"))),
+				response("language", answers(codingAnswer("urn:ietf:bcp:47", "en"))),
+				response("allergy", nil, response("substance", answers(stringAnswer("nuts")))),
+				response("allergy", nil, response("substance", answers(stringAnswer("eggs")))),
+				response("adult", answers(stringAnswer("yes"))),
+			},
+		},
+		{
+			name:   "open choice accepts strings",
+			status: c4pb.QuestionnaireResponseStatusCode_COMPLETED,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("name", answers(stringAnswer("Jo"))),
+				response("country", answers(stringAnswer("Atlantis"))),
+			},
+		},
+		{
+			name:   "missing required items",
+			status: c4pb.QuestionnaireResponseStatusCode_COMPLETED,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("smoker", answers(booleanAnswer(true))),
+			},
+			want: []string{"ERROR QuestionnaireResponse", "ERROR QuestionnaireResponse"},
+		},
+		{
+			name:   "required items not enforced while in progress",
+			status: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("smoker", answers(booleanAnswer(true))),
+			},
+		},
+		{
+			name:   "answers to disabled items",
+			status: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("smoker", answers(booleanAnswer(false))),
+				response("packs", answers(integerAnswer(1))),
+				response("adult", answers(stringAnswer("yes"))),
+			},
+			want: []string{"ERROR QuestionnaireResponse.item[1]", "ERROR QuestionnaireResponse.item[2]"},
+		},
+		{
+			name:   "answer to display item",
+			status: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("smoker", answers(booleanAnswer(true))),
+				response("packs", answers(integerAnswer(3))),
+				response("heavy", answers(stringAnswer("very"))),
+			},
+			want: []string{"ERROR QuestionnaireResponse.item[2]"},
+		},
+		{
+			name:   "wrong answer types",
+			status: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("name", answers(booleanAnswer(true))),
+				response("smoker", answers(stringAnswer("yes"))),
+			},
+			want: []string{"ERROR QuestionnaireResponse.item[0].answer[0]", "ERROR QuestionnaireResponse.item[1].answer[0]"},
+		},
+		{
+			name:   "too long",
+			status: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("name", answers(stringAnswer("Josephine"))),
+			},
+			want: []string{"ERROR QuestionnaireResponse.item[0].answer[0]"},
+		},
+		{
+			name:   "repeats",
+			status: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("name", answers(stringAnswer("Jo"), stringAnswer("Al"))),
+				response("smoker", answers(booleanAnswer(true))),
+				response("smoker", answers(booleanAnswer(true))),
+			},
+			want: []string{"ERROR QuestionnaireResponse.item[0]", "ERROR QuestionnaireResponse.item[2]"},
+		},
+		{
+			name:   "answer options and value sets",
+			status: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("color", answers(codingAnswer("urn:colors", "green"))),
+				response("country", answers(codingAnswer(countrySystem, "FR"))),
+				response("language", answers(codingAnswer("urn:ietf:bcp:47", "fr"))),
+			},
+			want: []string{
+				"ERROR QuestionnaireResponse.item[0].answer[0]",
+				"ERROR QuestionnaireResponse.item[1].answer[0]",
+				"ERROR QuestionnaireResponse.item[2].answer[0]",
+			},
+		},
+		{
+			name:   "unknown items",
+			status: c4pb.QuestionnaireResponseStatusCode_IN_PROGRESS,
+			items: []*qrpb.QuestionnaireResponse_Item{
+				response("allergy", nil, response("reaction", answers(stringAnswer("rash")))),
+				response("bogus", answers(stringAnswer("x"))),
+			},
+			want: []string{"ERROR QuestionnaireResponse.item[0].item[0]", "ERROR QuestionnaireResponse.item[1]"},
+		},
+	}
+	q := validateQuestionnaire(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			qr := &qrpb.QuestionnaireResponse{
+				Questionnaire: &d4pb.Canonical{Value: "http://example.com/Questionnaire/q|1.0"},
+				Status:        &qrpb.QuestionnaireResponse_StatusCode{Value: test.status},
+				Item:          test.items,
+			}
+			got, err := Validate(q, qr, ValidateOptions{ValueSets: languages})
+			if err != nil {
+				t.Fatalf("Validate() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, issues(got)); diff != "" {
+				t.Errorf("Validate() diff (-want +got):\n%s\noutcome: %v", diff, got)
+			}
+		})
+	}
+}
+
+func TestValidate_EnableWhen(t *testing.T) {
+	a := question("a", c4pb.QuestionnaireItemTypeCode_STRING)
+	b := question("b", c4pb.QuestionnaireItemTypeCode_CHOICE)
+	b.Repeats = &d4pb.Boolean{Value: true}
+	tests := []struct {
+		name     string
+		behavior c4pb.EnableWhenBehaviorCode_Value
+		conds    []*qpb.Questionnaire_Item_EnableWhen
+		answers  []*qrpb.QuestionnaireResponse_Item
+		want     bool
+	}{
+		{
+			name: "exists",
+			conds: []*qpb.Questionnaire_Item_EnableWhen{enableWhen("a", c4pb.QuestionnaireItemOperatorCode_EXISTS, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+				Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_Boolean{Boolean: &d4pb.Boolean{Value: true}},
+			})},
+			answers: []*qrpb.QuestionnaireResponse_Item{response("a", answers(stringAnswer("x")))},
+			want:    true,
+		},
+		{
+			name: "not exists",
+			conds: []*qpb.Questionnaire_Item_EnableWhen{enableWhen("a", c4pb.QuestionnaireItemOperatorCode_EXISTS, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+				Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_Boolean{Boolean: &d4pb.Boolean{Value: false}},
+			})},
+			answers: []*qrpb.QuestionnaireResponse_Item{response("a", answers(stringAnswer("x")))},
+			want:    false,
+		},
+		{
+			name: "coding equals",
+			conds: []*qpb.Questionnaire_Item_EnableWhen{enableWhen("b", c4pb.QuestionnaireItemOperatorCode_EQUALS, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+				Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_Coding{Coding: coding("urn:s", "y")},
+			})},
+			answers: []*qrpb.QuestionnaireResponse_Item{response("b", answers(codingAnswer("urn:s", "n"), codingAnswer("urn:s", "y")))},
+			want:    true,
+		},
+		{
+			name: "not equal to any answer",
+			conds: []*qpb.Questionnaire_Item_EnableWhen{enableWhen("b", c4pb.QuestionnaireItemOperatorCode_NOT_EQUAL_TO, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+				Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_Coding{Coding: coding("urn:s", "y")},
+			})},
+			answers: []*qrpb.QuestionnaireResponse_Item{response("b", answers(codingAnswer("urn:s", "n"), codingAnswer("urn:s", "y")))},
+			want:    false,
+		},
+		{
+			name: "string less than",
+			conds: []*qpb.Questionnaire_Item_EnableWhen{enableWhen("a", c4pb.QuestionnaireItemOperatorCode_LESS_THAN, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+				Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_StringValue{StringValue: str("m")},
+			})},
+			answers: []*qrpb.QuestionnaireResponse_Item{response("a", answers(stringAnswer("b")))},
+			want:    true,
+		},
+		{
+			name: "all",
+			conds: []*qpb.Questionnaire_Item_EnableWhen{
+				enableWhen("a", c4pb.QuestionnaireItemOperatorCode_EQUALS, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+					Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_StringValue{StringValue: str("x")},
+				}),
+				enableWhen("b", c4pb.QuestionnaireItemOperatorCode_EXISTS, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+					Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_Boolean{Boolean: &d4pb.Boolean{Value: true}},
+				}),
+			},
+			answers: []*qrpb.QuestionnaireResponse_Item{response("a", answers(stringAnswer("x")))},
+			want:    false,
+		},
+		{
+			name:     "any",
+			behavior: c4pb.EnableWhenBehaviorCode_ANY,
+			conds: []*qpb.Questionnaire_Item_EnableWhen{
+				enableWhen("a", c4pb.QuestionnaireItemOperatorCode_EQUALS, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+					Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_StringValue{StringValue: str("x")},
+				}),
+				enableWhen("b", c4pb.QuestionnaireItemOperatorCode_EXISTS, &qpb.Questionnaire_Item_EnableWhen_AnswerX{
+					Choice: &qpb.Questionnaire_Item_EnableWhen_AnswerX_Boolean{Boolean: &d4pb.Boolean{Value: true}},
+				}),
+			},
+			answers: []*qrpb.QuestionnaireResponse_Item{response("a", answers(stringAnswer("x")))},
+			want:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := question("c", c4pb.QuestionnaireItemTypeCode_STRING)
+			c.EnableWhen = test.conds
+			if test.behavior != c4pb.EnableWhenBehaviorCode_INVALID_UNINITIALIZED {
+				c.EnableBehavior = &qpb.Questionnaire_Item_EnableBehaviorCode{Value: test.behavior}
+			}
+			q := &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{a, b, c}}
+			qr := &qrpb.QuestionnaireResponse{Item: append(test.answers, response("c", answers(stringAnswer("z"))))}
+			got, err := Validate(q, qr, ValidateOptions{})
+			if err != nil {
+				t.Fatalf("Validate() returned unexpected error: %v", err)
+			}
+			if enabled := len(got.GetIssue()) == 0; enabled != test.want {
+				t.Errorf("Validate() reported %v, want item enabled = %v", issues(got), test.want)
+			}
+		})
+	}
+}
+
+func TestValidate_Errors(t *testing.T) {
+	q := &qpb.Questionnaire{Item: []*qpb.Questionnaire_Item{
+		question("a", c4pb.QuestionnaireItemTypeCode_STRING, expressionExt(EnableWhenExpressionURL, "", "item.where(")),
+	}}
+	if _, err := Validate(q, &qrpb.QuestionnaireResponse{}, ValidateOptions{}); err == nil {
+		t.Errorf("Validate() with an invalid enableWhenExpression succeeded, want error")
+	}
+}
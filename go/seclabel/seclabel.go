@@ -0,0 +1,293 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package seclabel computes and propagates the meta.security labels of R4
+// resources following the HL7 Healthcare Privacy and Security
+// Classification System (HCS).
+//
+// The confidentiality of a container, a resource with contained resources
+// or a Bundle, is the high-water mark of the confidentiality of its
+// content, and the other security labels of its content, such as
+// sensitivity and handling caveats, apply to it too.
+package seclabel
+
+import (
+	"fmt"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/composition_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+// ConfidentialitySystem is the system of confidentiality labels.
+const ConfidentialitySystem = "http://terminology.hl7.org/CodeSystem/v3-Confidentiality"
+
+// Confidentiality is a confidentiality level. Levels are ordered from the
+// least to the most restricted.
+type Confidentiality int
+
+// Confidentiality levels.
+const (
+	// Unlabeled is the level of resources without a confidentiality label.
+	Unlabeled Confidentiality = iota
+	Unrestricted
+	Low
+	Moderate
+	Normal
+	Restricted
+	VeryRestricted
+)
+
+var codes = []string{"", "U", "L", "M", "N", "R", "V"}
+
+// Code returns the v3-Confidentiality code of c, or "" if c is Unlabeled.
+func (c Confidentiality) Code() string {
+	if c < 0 || int(c) >= len(codes) {
+		return ""
+	}
+	return codes[c]
+}
+
+// ParseConfidentiality returns the level of a v3-Confidentiality code.
+func ParseConfidentiality(code string) (Confidentiality, error) {
+	for i, c := range codes {
+		if c != "" && c == code {
+			return Confidentiality(i), nil
+		}
+	}
+	return Unlabeled, fmt.Errorf("unknown confidentiality code %q", code)
+}
+
+// Of returns the confidentiality of res, the most restricted of its
+// confidentiality labels. Labels with unknown codes are ignored.
+func Of(res proto.Message) Confidentiality {
+	meta := metaOf(elementpath.Unwrap(res).ProtoReflect(), false)
+	c := Unlabeled
+	for _, l := range meta.GetSecurity() {
+		if l.GetSystem().GetValue() != ConfidentialitySystem {
+			continue
+		}
+		if lc, err := ParseConfidentiality(l.GetCode().GetValue()); err == nil && lc > c {
+			c = lc
+		}
+	}
+	return c
+}
+
+// HighWaterMark returns the most restricted confidentiality of resources.
+func HighWaterMark(resources ...proto.Message) Confidentiality {
+	c := Unlabeled
+	for _, res := range resources {
+		if rc := Of(res); rc > c {
+			c = rc
+		}
+	}
+	return c
+}
+
+// Set replaces the confidentiality labels of res with a label of c, or
+// removes them if c is Unlabeled.
+func Set(res proto.Message, c Confidentiality) error {
+	if c.Code() == "" && c != Unlabeled {
+		return fmt.Errorf("invalid confidentiality %d", c)
+	}
+	m := elementpath.Unwrap(res).ProtoReflect()
+	meta := metaOf(m, c != Unlabeled)
+	if meta == nil {
+		return nil
+	}
+	labels := meta.GetSecurity()[:0]
+	for _, l := range meta.GetSecurity() {
+		if l.GetSystem().GetValue() != ConfidentialitySystem {
+			labels = append(labels, l)
+		}
+	}
+	if c != Unlabeled {
+		labels = append(labels, &d4pb.Coding{
+			System: &d4pb.Uri{Value: ConfidentialitySystem},
+			Code:   &d4pb.Code{Value: c.Code()},
+		})
+	}
+	meta.Security = labels
+	return nil
+}
+
+// Propagate labels res with the labels of its content, recursively: a
+// resource with contained resources, and a Bundle with the resources of
+// its entries. Its confidentiality is raised to the high-water mark of its
+// own and that of its content, and the other labels of its content are
+// added to its own. The Composition of a document Bundle gets the
+// confidentiality of the document, in its labels and its confidentiality
+// element.
+func Propagate(res proto.Message) error {
+	res = elementpath.Unwrap(res)
+	if res == nil {
+		return nil
+	}
+	var content []proto.Message
+	if b, ok := res.(*r4pb.Bundle); ok {
+		for _, e := range b.GetEntry() {
+			if e.GetResource() == nil {
+				continue
+			}
+			if r := elementpath.Unwrap(e.GetResource()); r != nil {
+				content = append(content, r)
+			}
+		}
+	}
+	contained, err := containedResources(res.ProtoReflect())
+	if err != nil {
+		return err
+	}
+	content = append(content, contained...)
+	for _, r := range content {
+		if err := Propagate(r); err != nil {
+			return err
+		}
+	}
+	if err := repackContained(res.ProtoReflect(), contained); err != nil {
+		return err
+	}
+	if err := label(res, content); err != nil {
+		return err
+	}
+	if b, ok := res.(*r4pb.Bundle); ok && b.GetType().GetValue() == c4pb.BundleTypeCode_DOCUMENT {
+		return labelComposition(b)
+	}
+	return nil
+}
+
+// label adds the labels of content to res and raises its confidentiality
+// to their high-water mark.
+func label(res proto.Message, content []proto.Message) error {
+	if len(content) == 0 {
+		return nil
+	}
+	c := HighWaterMark(append(content, res)...)
+	seen := map[string]bool{}
+	var others []*d4pb.Coding
+	for _, r := range append([]proto.Message{res}, content...) {
+		for _, l := range metaOf(r.ProtoReflect(), false).GetSecurity() {
+			key := l.GetSystem().GetValue() + "|" + l.GetCode().GetValue()
+			if l.GetSystem().GetValue() == ConfidentialitySystem || seen[key] {
+				continue
+			}
+			seen[key] = true
+			others = append(others, l)
+		}
+	}
+	if c == Unlabeled && len(others) == 0 {
+		return nil
+	}
+	meta := metaOf(res.ProtoReflect(), true)
+	if meta == nil {
+		return fmt.Errorf("%s has no meta", elementpath.ResourceType(res))
+	}
+	meta.Security = others
+	return Set(res, c)
+}
+
+// labelComposition gives the Composition of the document b the
+// confidentiality of b.
+func labelComposition(b *r4pb.Bundle) error {
+	var comp *cpb.Composition
+	if len(b.GetEntry()) > 0 {
+		comp, _ = elementpath.Unwrap(b.GetEntry()[0].GetResource()).(*cpb.Composition)
+	}
+	if comp == nil {
+		return fmt.Errorf("document does not start with a Composition")
+	}
+	c := Of(b)
+	if c == Unlabeled {
+		return nil
+	}
+	if err := Set(comp, c); err != nil {
+		return err
+	}
+	comp.Confidentiality = &cpb.Composition_ConfidentialityCode{
+		Value: vspb.V3ConfidentialityClassificationValueSet_Value(c),
+	}
+	return nil
+}
+
+// metaOf returns the meta of m, a resource, creating it if create is set,
+// or nil if it has none.
+func metaOf(m protoreflect.Message, create bool) *d4pb.Meta {
+	fd := m.Descriptor().Fields().ByName("meta")
+	if fd == nil || (!create && !m.Has(fd)) {
+		return nil
+	}
+	if create {
+		meta, _ := m.Mutable(fd).Message().Interface().(*d4pb.Meta)
+		return meta
+	}
+	meta, _ := m.Get(fd).Message().Interface().(*d4pb.Meta)
+	return meta
+}
+
+// containedResources returns the resources contained in m, a resource,
+// unpacked.
+func containedResources(m protoreflect.Message) ([]proto.Message, error) {
+	fd := m.Descriptor().Fields().ByName("contained")
+	if fd == nil || !fd.IsList() {
+		return nil, nil
+	}
+	list := m.Get(fd).List()
+	var out []proto.Message
+	for i := 0; i < list.Len(); i++ {
+		a, ok := list.Get(i).Message().Interface().(*anypb.Any)
+		if !ok {
+			return nil, fmt.Errorf("contained resource %d is not packed", i)
+		}
+		cr, err := a.UnmarshalNew()
+		if err != nil {
+			return nil, fmt.Errorf("unpacking contained resource %d: %w", i, err)
+		}
+		out = append(out, elementpath.Unwrap(cr))
+	}
+	return out, nil
+}
+
+// repackContained packs contained, the resources contained in m after their
+// labels have been propagated, back into m, the way they were packed.
+func repackContained(m protoreflect.Message, contained []proto.Message) error {
+	if len(contained) == 0 {
+		return nil
+	}
+	list := m.Get(m.Descriptor().Fields().ByName("contained")).List()
+	for i, res := range contained {
+		a := list.Get(i).Message().Interface().(*anypb.Any)
+		if !a.MessageIs(&r4pb.ContainedResource{}) {
+			if err := a.MarshalFrom(res); err != nil {
+				return fmt.Errorf("packing contained resource %d: %w", i, err)
+			}
+			continue
+		}
+		cr, err := elementpath.Convert(res, (&r4pb.ContainedResource{}).ProtoReflect().Descriptor())
+		if err != nil {
+			return fmt.Errorf("packing contained resource %d: %w", i, err)
+		}
+		if err := a.MarshalFrom(cr); err != nil {
+			return fmt.Errorf("packing contained resource %d: %w", i, err)
+		}
+	}
+	return nil
+}
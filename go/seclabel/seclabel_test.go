@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seclabel
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/composition_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+const actCodeSystem = "http://terminology.hl7.org/CodeSystem/v3-ActCode"
+
+func labels(codes ...string) *d4pb.Meta {
+	meta := &d4pb.Meta{}
+	for _, c := range codes {
+		system := ConfidentialitySystem
+		if len(c) > 1 {
+			system = actCodeSystem
+		}
+		meta.Security = append(meta.Security, &d4pb.Coding{
+			System: &d4pb.Uri{Value: system},
+			Code:   &d4pb.Code{Value: c},
+		})
+	}
+	return meta
+}
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		meta *d4pb.Meta
+		want Confidentiality
+	}{
+		{"no meta", nil, Unlabeled},
+		{"no confidentiality", labels("HIV"), Unlabeled},
+		{"one", labels("N", "HIV"), Normal},
+		{"highest", labels("R", "L", "V", "N"), VeryRestricted},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Of(&ppb.Patient{Meta: test.meta}); got != test.want {
+				t.Errorf("Of() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	p := &ppb.Patient{Meta: labels("N", "HIV", "L")}
+	if err := Set(p, Restricted); err != nil {
+		t.Fatalf("Set() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(labels("HIV", "R"), p.GetMeta(), protocmp.Transform()); diff != "" {
+		t.Errorf("Set() diff (-want +got):\n%s", diff)
+	}
+	if err := Set(p, Unlabeled); err != nil {
+		t.Fatalf("Set() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(labels("HIV"), p.GetMeta(), protocmp.Transform()); diff != "" {
+		t.Errorf("Set() diff (-want +got):\n%s", diff)
+	}
+	if err := Set(p, Confidentiality(9)); err == nil {
+		t.Errorf("Set() succeeded, want error")
+	}
+}
+
+func TestPropagate_Contained(t *testing.T) {
+	contained, err := anypb.New(&r4pb.ContainedResource{
+		OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{Meta: labels("R", "PSY")}},
+	})
+	if err != nil {
+		t.Fatalf("anypb.New() returned unexpected error: %v", err)
+	}
+	obs := &obspb.Observation{Meta: labels("N"), Contained: []*anypb.Any{contained}}
+	if err := Propagate(obs); err != nil {
+		t.Fatalf("Propagate() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(labels("PSY", "R"), obs.GetMeta(), protocmp.Transform()); diff != "" {
+		t.Errorf("Propagate() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPropagate_Document(t *testing.T) {
+	b := &r4pb.Bundle{
+		Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_DOCUMENT},
+		Entry: []*r4pb.Bundle_Entry{
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Composition{
+				Composition: &cpb.Composition{Meta: labels("N")},
+			}}},
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{
+				Patient: &ppb.Patient{Meta: labels("L")},
+			}}},
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{
+				Observation: &obspb.Observation{Meta: labels("R", "HIV", "ETH")},
+			}}},
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{
+				Observation: &obspb.Observation{Meta: labels("HIV")},
+			}}},
+		},
+	}
+	if err := Propagate(b); err != nil {
+		t.Fatalf("Propagate() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(labels("HIV", "ETH", "R"), b.GetMeta(), protocmp.Transform()); diff != "" {
+		t.Errorf("Propagate() Bundle diff (-want +got):\n%s", diff)
+	}
+	wantComp := &cpb.Composition{
+		Meta:            labels("R"),
+		Confidentiality: &cpb.Composition_ConfidentialityCode{Value: vspb.V3ConfidentialityClassificationValueSet_R},
+	}
+	if diff := cmp.Diff(wantComp, b.GetEntry()[0].GetResource().GetComposition(), protocmp.Transform()); diff != "" {
+		t.Errorf("Propagate() Composition diff (-want +got):\n%s", diff)
+	}
+	// The labels of entries are their own.
+	if diff := cmp.Diff(labels("L"), b.GetEntry()[1].GetResource().GetPatient().GetMeta(), protocmp.Transform()); diff != "" {
+		t.Errorf("Propagate() Patient diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPropagate_Unlabeled(t *testing.T) {
+	b := &r4pb.Bundle{
+		Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+		Entry: []*r4pb.Bundle_Entry{
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{}}}},
+		},
+	}
+	want := proto.Clone(b)
+	if err := Propagate(b); err != nil {
+		t.Fatalf("Propagate() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, b, protocmp.Transform()); diff != "" {
+		t.Errorf("Propagate() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPropagate_DocumentWithoutComposition(t *testing.T) {
+	b := &r4pb.Bundle{
+		Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_DOCUMENT},
+		Entry: []*r4pb.Bundle_Entry{
+			{Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{
+				Patient: &ppb.Patient{Meta: labels("N")},
+			}}},
+		},
+	}
+	if err := Propagate(b); err == nil {
+		t.Errorf("Propagate() succeeded, want error")
+	}
+}
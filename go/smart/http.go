@@ -0,0 +1,346 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/fhirhttp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// ErrDenied is returned for requests and responses the scopes of a grant
+// do not permit.
+var ErrDenied = errors.New("access denied by SMART scopes")
+
+// ErrUnsupportedMediaType is returned for requests whose body scopes must
+// be checked against but that is not FHIR JSON.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// GrantFunc returns the grant of r, typically from its access token. An
+// error rejects the request.
+type GrantFunc func(r *http.Request) (Grant, error)
+
+// Middleware returns middleware enforcing the grants of requests on the
+// handler it wraps. basePath is the path of the FHIR base. Requests for
+// paths outside of it or for interactions no scope allows, and creates and updates of resources no
+// scope permits, are rejected with a 403 Forbidden before reaching the
+// handler, and those whose resources cannot be checked because they are not
+// FHIR JSON with a 415 Unsupported Media Type. Search results are then
+// filtered entry by entry, and a single resource that is not permitted, or
+// a response that is not FHIR JSON, is replaced by a 403 Forbidden.
+// Responses that cannot be parsed are replaced by a 500 Internal Server
+// Error.
+func Middleware(grant GrantFunc, basePath string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			g, err := grant(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			body, err := readBody(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			filter, err := g.check(r.Method, r.URL, basePath, r.Header.Get("Content-Type"), body)
+			if errors.Is(err, ErrDenied) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if errors.Is(err, ErrUnsupportedMediaType) {
+				http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			buf := fhirhttp.NewResponseBuffer()
+			next.ServeHTTP(buf, r)
+			for k, v := range buf.Header() {
+				w.Header()[k] = v
+			}
+			if filter == 0 || buf.Status < 200 || buf.Status > 299 || buf.Body.Len() == 0 {
+				w.WriteHeader(buf.Status)
+				w.Write(buf.Body.Bytes())
+				return
+			}
+			out, err := g.filterResponse(buf.Header().Get("Content-Type"), buf.Body.Bytes(), filter)
+			if err != nil {
+				w.Header().Del("Content-Length")
+				status := http.StatusInternalServerError
+				if errors.Is(err, ErrDenied) {
+					status = http.StatusForbidden
+				}
+				http.Error(w, err.Error(), status)
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(out)))
+			w.WriteHeader(buf.Status)
+			w.Write(out)
+		})
+	}
+}
+
+// Transport returns an http.RoundTripper enforcing g on the requests made
+// through base, or http.DefaultTransport if it is nil, to the FHIR server
+// whose base has the path basePath. Requests outside of it or that g does
+// not permit fail with ErrDenied without being sent, as do responses of a single resource g does
+// not permit and responses that are not FHIR JSON; search results are
+// filtered.
+func Transport(base http.RoundTripper, g Grant, basePath string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper(func(r *http.Request) (*http.Response, error) {
+		body, err := readBody(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		filter, err := g.check(r.Method, r.URL, basePath, r.Header.Get("Content-Type"), body)
+		if err != nil {
+			return nil, err
+		}
+		r = r.Clone(r.Context())
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		resp, err := base.RoundTrip(r)
+		if err != nil || filter == 0 || resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp, err
+		}
+		in, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(in) == 0 {
+			resp.Body = io.NopCloser(bytes.NewReader(in))
+			return resp, nil
+		}
+		out, err := g.filterResponse(resp.Header.Get("Content-Type"), in, filter)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(out))
+		resp.ContentLength = int64(len(out))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(out)))
+		return resp, nil
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// interaction is what a request does, as far as scopes are concerned.
+type interaction struct {
+	// typ is the resource type the request is on, "*" for the whole server,
+	// and perm the permission it requires, which is 0 if the request does
+	// not concern a resource type.
+	typ  string
+	perm Permissions
+	// filter is the permission the resources of the response are filtered
+	// for, 0 if they are not.
+	filter Permissions
+}
+
+// classify returns the interaction of a request with method on path,
+// relative to the FHIR base.
+func classify(method, path string) interaction {
+	path = strings.Trim(path, "/")
+	var segs []string
+	if path != "" {
+		segs = strings.Split(path, "/")
+	}
+	read := method == http.MethodGet || method == http.MethodHead
+	switch {
+	case len(segs) == 0 && read:
+		return interaction{typ: "*", perm: Search, filter: Search}
+	case len(segs) == 0 && method == http.MethodPost:
+		// A transaction or batch, whose entries are checked separately.
+		return interaction{filter: Read}
+	case len(segs) == 0:
+		return interaction{}
+	case strings.HasPrefix(segs[len(segs)-1], "$") && read:
+		return interaction{filter: Read}
+	case strings.HasPrefix(segs[len(segs)-1], "$"):
+		// Operations invoked otherwise than by a GET may change resources,
+		// of their type if they have one.
+		typ := "*"
+		if resourceTypePattern.MatchString(segs[0]) {
+			typ = segs[0]
+		}
+		return interaction{typ: typ, perm: Write, filter: Read}
+	case segs[0] == "_search":
+		return interaction{typ: "*", perm: Search, filter: Search}
+	case segs[0] == "_history":
+		return interaction{typ: "*", perm: Read, filter: Read}
+	case !resourceTypePattern.MatchString(segs[0]) || segs[0] == "*":
+		// metadata, .well-known and the like.
+		return interaction{}
+	}
+	typ := segs[0]
+	switch {
+	case len(segs) == 1 && read:
+		return interaction{typ: typ, perm: Search, filter: Search}
+	case len(segs) == 2 && segs[1] == "_search" && method == http.MethodPost:
+		return interaction{typ: typ, perm: Search, filter: Search}
+	case len(segs) == 1 && method == http.MethodPost:
+		return interaction{typ: typ, perm: Create}
+	case len(segs) <= 2 && (method == http.MethodPut || method == http.MethodPatch):
+		return interaction{typ: typ, perm: Update}
+	case len(segs) <= 2 && method == http.MethodDelete:
+		return interaction{typ: typ, perm: Delete}
+	case read && (segs[len(segs)-1] == "_history" || (len(segs) >= 3 && segs[len(segs)-2] == "_history") || len(segs) == 2):
+		return interaction{typ: typ, perm: Read, filter: Read}
+	case read && len(segs) == 3 && resourceTypePattern.MatchString(segs[2]):
+		// A compartment search, [type]/[id]/[type].
+		return interaction{typ: segs[2], perm: Search, filter: Search}
+	}
+	return interaction{typ: typ, perm: Read, filter: Read}
+}
+
+// relativePath returns the cleaned path p relative to the FHIR base
+// basePath, i.e. "/Patient/1", and whether p is the base or under it.
+func relativePath(p, basePath string) (string, bool) {
+	p = path.Clean("/" + p)
+	base := strings.TrimSuffix(path.Clean("/"+basePath), "/")
+	switch {
+	case p == base:
+		return "/", true
+	case strings.HasPrefix(p, base+"/"):
+		return p[len(base):], true
+	}
+	return "", false
+}
+
+// check checks a request of g with method on u, whose body is body, and
+// returns the permission its response is filtered for.
+func (g Grant) check(method string, u *url.URL, basePath, contentType string, body []byte) (Permissions, error) {
+	path, ok := relativePath(u.Path, basePath)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s is not under the FHIR base %s", ErrDenied, u.Path, basePath)
+	}
+	in := classify(method, path)
+	if in.perm != 0 && !g.Allows(in.typ, in.perm) {
+		return 0, fmt.Errorf("%w: %s %s requires a %s.%s scope", ErrDenied, method, path, in.typ, in.perm)
+	}
+	if len(body) == 0 {
+		return in.filter, nil
+	}
+	if in.perm != Create && in.perm != Update && strings.Trim(path, "/") != "" {
+		return in.filter, nil
+	}
+	// The resources of the body must be checked, which is only possible
+	// for FHIR JSON.
+	if !fhirhttp.IsFHIRJSON(contentType) {
+		return 0, fmt.Errorf("%w: %s %s with a %q body", ErrUnsupportedMediaType, method, path, contentType)
+	}
+	cr, err := fhirhttp.Unmarshaller.Unmarshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("parsing request body: %w", err)
+	}
+	res := elementpath.Unwrap(cr)
+	if b, ok := res.(*r4pb.Bundle); ok && in.perm == 0 {
+		return in.filter, g.checkEntries(b)
+	}
+	if in.perm != 0 && !g.Permits(res, in.perm) {
+		return 0, fmt.Errorf("%w: %s %s is not permitted", ErrDenied, method, path)
+	}
+	return in.filter, nil
+}
+
+// checkEntries checks the requests of the entries of b, a transaction or
+// batch.
+func (g Grant) checkEntries(b *r4pb.Bundle) error {
+	switch b.GetType().GetValue() {
+	case c4pb.BundleTypeCode_TRANSACTION, c4pb.BundleTypeCode_BATCH:
+	default:
+		return nil
+	}
+	for i, e := range b.GetEntry() {
+		req := e.GetRequest()
+		method := req.GetMethod().GetValue().String()
+		u, err := url.Parse(req.GetUrl().GetValue())
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		in := classify(method, u.Path)
+		if in.perm == 0 {
+			continue
+		}
+		if !g.Allows(in.typ, in.perm) {
+			return fmt.Errorf("%w: entry %d, %s %s, requires a %s.%s scope", ErrDenied, i, method, u.Path, in.typ, in.perm)
+		}
+		if (in.perm == Create || in.perm == Update) && e.GetResource() != nil && !g.Permits(e.GetResource(), in.perm) {
+			return fmt.Errorf("%w: entry %d, %s %s, is not permitted", ErrDenied, i, method, u.Path)
+		}
+	}
+	return nil
+}
+
+// filterResponse filters the body of a response, of contentType, for p. It
+// fails with ErrDenied if the body is not FHIR JSON, which cannot be
+// filtered, or is a single resource g does not permit to read.
+// OperationOutcomes are always permitted.
+func (g Grant) filterResponse(contentType string, body []byte, p Permissions) ([]byte, error) {
+	if !fhirhttp.IsFHIRJSON(contentType) {
+		return nil, fmt.Errorf("%w: cannot filter a %q response", ErrDenied, contentType)
+	}
+	cr, err := fhirhttp.Unmarshaller.Unmarshal(body)
+	if err != nil {
+		return nil, err
+	}
+	res := elementpath.Unwrap(cr)
+	if res == nil {
+		return nil, fmt.Errorf("response has no resource")
+	}
+	switch elementpath.ResourceType(res) {
+	case "OperationOutcome":
+		return body, nil
+	case "Bundle":
+		g.Filter(res.(*r4pb.Bundle), p)
+	default:
+		if !g.Permits(res, Read) {
+			return nil, fmt.Errorf("%w: %s", ErrDenied, elementpath.ResourceType(res))
+		}
+	}
+	return fhirhttp.Marshaller.MarshalResource(res)
+}
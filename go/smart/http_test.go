@@ -0,0 +1,329 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smart
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/fhirhttp"
+	"github.com/google/fhir/go/xmlformat"
+	"google.golang.org/protobuf/proto"
+	"path"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// fhirServer serves lab and social history Observations of p1, as FHIR XML
+// if _format=xml is requested. Like many servers, it cleans request paths.
+func fhirServer(t *testing.T) http.Handler {
+	t.Helper()
+	xm, err := xmlformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("xmlformat.NewMarshaller() returned unexpected error: %v", err)
+	}
+	write := func(w http.ResponseWriter, r *http.Request, res proto.Message) {
+		body, err := fhirhttp.Marshaller.MarshalResource(res)
+		contentType := "application/fhir+json"
+		if r.URL.Query().Get("_format") == "xml" {
+			body, err = xm.MarshalResource(res)
+			contentType = "application/fhir+xml"
+		}
+		if err != nil {
+			t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(path.Clean(r.URL.Path), "/fhir/") {
+		case "Observation/laboratory":
+			write(w, r, observation("p1", "laboratory"))
+		case "Observation/social-history":
+			write(w, r, observation("p1", "social-history"))
+		case "Observation":
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			write(w, r, &r4pb.Bundle{
+				Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+				Total: &d4pb.UnsignedInt{Value: 2},
+				Entry: []*r4pb.Bundle_Entry{
+					entry(observation("p1", "laboratory"), c4pb.SearchEntryModeCode_MATCH),
+					entry(observation("p1", "social-history"), c4pb.SearchEntryModeCode_MATCH),
+				},
+			})
+		case "metadata":
+			w.Header().Set("Content-Type", "application/fhir+json")
+			io.WriteString(w, `{"resourceType":"CapabilityStatement","status":"active","date":"2026","kind":"instance","fhirVersion":"4.0.1","format":["json"]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func labGrant(t *testing.T) Grant {
+	t.Helper()
+	return Grant{Scopes: mustParseScopes(t, "patient/Observation.crs?category=laboratory"), Patient: "p1"}
+}
+
+func TestMiddleware(t *testing.T) {
+	g := labGrant(t)
+	s := httptest.NewServer(Middleware(func(*http.Request) (Grant, error) { return g, nil }, "/fhir")(fhirServer(t)))
+	defer s.Close()
+	lab, err := fhirhttp.Marshaller.MarshalResource(observation("p1", "laboratory"))
+	if err != nil {
+		t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+	}
+	social, err := fhirhttp.Marshaller.MarshalResource(observation("p1", "social-history"))
+	if err != nil {
+		t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		body   []byte
+		want   int
+	}{
+		{http.MethodGet, "/fhir/Observation/laboratory", nil, http.StatusOK},
+		{http.MethodGet, "/fhir/Observation/social-history", nil, http.StatusForbidden},
+		{http.MethodGet, "/fhir/Patient/p1", nil, http.StatusForbidden},
+		{http.MethodDelete, "/fhir/Observation/laboratory", nil, http.StatusForbidden},
+		{http.MethodPost, "/fhir/Observation", lab, http.StatusCreated},
+		{http.MethodPost, "/fhir/Observation", social, http.StatusForbidden},
+		{http.MethodGet, "/fhir/metadata", nil, http.StatusOK},
+		{http.MethodGet, "//fhir/Observation/social-history", nil, http.StatusForbidden},
+		{http.MethodGet, "/fhirx/Observation/social-history", nil, http.StatusForbidden},
+		{http.MethodGet, "/Observation/social-history", nil, http.StatusForbidden},
+		{http.MethodPost, "/fhir/Observation/$lookup", nil, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			var body io.Reader
+			if test.body != nil {
+				body = strings.NewReader(string(test.body))
+			}
+			req, err := http.NewRequest(test.method, s.URL+test.path, body)
+			if err != nil {
+				t.Fatalf("NewRequest() returned unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/fhir+json")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s returned unexpected error: %v", test.method, test.path, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.want {
+				t.Errorf("%s %s returned status %d, want %d", test.method, test.path, resp.StatusCode, test.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware_FiltersSearch(t *testing.T) {
+	g := labGrant(t)
+	s := httptest.NewServer(Middleware(func(*http.Request) (Grant, error) { return g, nil }, "/fhir")(fhirServer(t)))
+	defer s.Close()
+	resp, err := http.Get(s.URL + "/fhir/Observation?patient=p1")
+	if err != nil {
+		t.Fatalf("GET returned unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response returned unexpected error: %v", err)
+	}
+	cr, err := fhirhttp.Unmarshaller.Unmarshal(body)
+	if err != nil {
+		t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+	}
+	b := elementpath.Unwrap(cr).(*r4pb.Bundle)
+	if len(b.GetEntry()) != 1 || b.GetEntry()[0].GetResource().GetObservation().GetId().GetValue() != "laboratory" || b.GetTotal().GetValue() != 1 {
+		t.Errorf("GET returned %d entries, total %d, want the laboratory Observation only", len(b.GetEntry()), b.GetTotal().GetValue())
+	}
+}
+
+func TestMiddleware_RejectsXML(t *testing.T) {
+	g := labGrant(t)
+	s := httptest.NewServer(Middleware(func(*http.Request) (Grant, error) { return g, nil }, "/fhir")(fhirServer(t)))
+	defer s.Close()
+	xm, err := xmlformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("xmlformat.NewMarshaller() returned unexpected error: %v", err)
+	}
+	social, err := xm.MarshalResource(observation("p1", "social-history"))
+	if err != nil {
+		t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+	}
+	e := entry(observation("p1", "social-history"), 0)
+	e.Search = nil
+	e.Request = &r4pb.Bundle_Entry_Request{
+		Method: &r4pb.Bundle_Entry_Request_MethodCode{Value: c4pb.HTTPVerbCode_POST},
+		Url:    &d4pb.Uri{Value: "Observation"},
+	}
+	tx, err := xm.MarshalResource(&r4pb.Bundle{
+		Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_TRANSACTION},
+		Entry: []*r4pb.Bundle_Entry{e},
+	})
+	if err != nil {
+		t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		body   []byte
+		want   int
+	}{
+		// Request bodies that are not FHIR JSON cannot be checked.
+		{http.MethodPost, "/fhir", tx, http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/fhir/Observation", social, http.StatusUnsupportedMediaType},
+		// Responses that are not FHIR JSON cannot be filtered.
+		{http.MethodGet, "/fhir/Observation?_format=xml", nil, http.StatusForbidden},
+		{http.MethodGet, "/fhir/Observation/laboratory?_format=xml", nil, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			var body io.Reader
+			if test.body != nil {
+				body = strings.NewReader(string(test.body))
+			}
+			req, err := http.NewRequest(test.method, s.URL+test.path, body)
+			if err != nil {
+				t.Fatalf("NewRequest() returned unexpected error: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/fhir+xml")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("%s %s returned unexpected error: %v", test.method, test.path, err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != test.want {
+				t.Errorf("%s %s returned status %d, want %d", test.method, test.path, resp.StatusCode, test.want)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var sent atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		fhirServer(t).ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	client := &http.Client{Transport: Transport(nil, labGrant(t), "/fhir")}
+
+	resp, err := client.Get(s.URL + "/fhir/Observation/laboratory")
+	if err != nil {
+		t.Fatalf("GET laboratory returned unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get(s.URL + "/fhir/Observation/social-history"); !errors.Is(err, ErrDenied) {
+		t.Errorf("GET social-history returned %v, want ErrDenied", err)
+	}
+	if _, err := client.Get(s.URL + "/fhir/Patient/p1"); !errors.Is(err, ErrDenied) {
+		t.Errorf("GET Patient returned %v, want ErrDenied", err)
+	}
+	// The Patient read is denied without being sent.
+	if got := sent.Load(); got != 2 {
+		t.Errorf("sent %d requests, want 2", got)
+	}
+	if _, err := client.Get(s.URL + "/fhir/Observation?_format=xml"); !errors.Is(err, ErrDenied) {
+		t.Errorf("GET Observation as XML returned %v, want ErrDenied", err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   interaction
+	}{
+		{http.MethodGet, "/Observation", interaction{typ: "Observation", perm: Search, filter: Search}},
+		{http.MethodPost, "/Observation/_search", interaction{typ: "Observation", perm: Search, filter: Search}},
+		{http.MethodGet, "/Observation/1", interaction{typ: "Observation", perm: Read, filter: Read}},
+		{http.MethodGet, "/Observation/1/_history/2", interaction{typ: "Observation", perm: Read, filter: Read}},
+		{http.MethodGet, "/Patient/1/Observation", interaction{typ: "Observation", perm: Search, filter: Search}},
+		{http.MethodPost, "/Observation", interaction{typ: "Observation", perm: Create}},
+		{http.MethodPut, "/Observation/1", interaction{typ: "Observation", perm: Update}},
+		{http.MethodPatch, "/Observation/1", interaction{typ: "Observation", perm: Update}},
+		{http.MethodDelete, "/Observation/1", interaction{typ: "Observation", perm: Delete}},
+		{http.MethodGet, "/", interaction{typ: "*", perm: Search, filter: Search}},
+		{http.MethodPost, "/", interaction{filter: Read}},
+		{http.MethodGet, "/Patient/1/$everything", interaction{filter: Read}},
+		{http.MethodPost, "/Patient/$merge", interaction{typ: "Patient", perm: Write, filter: Read}},
+		{http.MethodDelete, "/Observation/1/$meta-delete", interaction{typ: "Observation", perm: Write, filter: Read}},
+		{http.MethodPost, "/$process-message", interaction{typ: "*", perm: Write, filter: Read}},
+		{http.MethodGet, "/metadata", interaction{}},
+	}
+	for _, test := range tests {
+		if got := classify(test.method, test.path); got != test.want {
+			t.Errorf("classify(%s, %s) = %+v, want %+v", test.method, test.path, got, test.want)
+		}
+	}
+}
+
+func TestCheck_Transaction(t *testing.T) {
+	g := labGrant(t)
+	tx := func(method c4pb.HTTPVerbCode_Value, path string, res proto.Message) []byte {
+		e := &r4pb.Bundle_Entry{}
+		if res != nil {
+			e = entry(res, 0)
+			e.Search = nil
+		}
+		e.Request = &r4pb.Bundle_Entry_Request{
+			Method: &r4pb.Bundle_Entry_Request_MethodCode{Value: method},
+			Url:    &d4pb.Uri{Value: path},
+		}
+		body, err := fhirhttp.Marshaller.MarshalResource(&r4pb.Bundle{
+			Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_TRANSACTION},
+			Entry: []*r4pb.Bundle_Entry{e},
+		})
+		if err != nil {
+			t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+		}
+		return body
+	}
+	u, err := url.Parse("https://example.com/fhir")
+	if err != nil {
+		t.Fatalf("url.Parse() returned unexpected error: %v", err)
+	}
+	if _, err := g.check(http.MethodPost, u, "/fhir", "application/fhir+json", tx(c4pb.HTTPVerbCode_POST, "Observation", observation("p1", "laboratory"))); err != nil {
+		t.Errorf("check() returned unexpected error: %v", err)
+	}
+	if _, err := g.check(http.MethodPost, u, "/fhir", "application/fhir+json", tx(c4pb.HTTPVerbCode_POST, "Observation", observation("p1", "vital-signs"))); !errors.Is(err, ErrDenied) {
+		t.Errorf("check() returned %v, want ErrDenied", err)
+	}
+	if _, err := g.check(http.MethodPost, u, "/fhir", "application/fhir+json", tx(c4pb.HTTPVerbCode_DELETE, "Observation/laboratory", nil)); !errors.Is(err, ErrDenied) {
+		t.Errorf("check() returned %v, want ErrDenied", err)
+	}
+	if _, err := g.check(http.MethodPost, u, "/fhir", "application/fhir+xml", []byte(`<Bundle xmlns="http://hl7.org/fhir"/>`)); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("check() of an XML transaction returned %v, want ErrUnsupportedMediaType", err)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package smart enforces SMART App Launch v2 scopes, i.e.
+// "patient/Observation.rs?category=laboratory", on the FHIR RESTful
+// requests of an app and on the resources it gets back, through the
+// Middleware of a server or the Transport of a client.
+//
+// Scopes grant interactions on resource types: c(reate), r(ead), u(pdate),
+// d(elete) and s(earch). Patient scopes are restricted to the compartment of
+// the patient in context, which is approximated by the patient, subject or
+// beneficiary the resources refer to; resources without one are not
+// granted by patient scopes. The query of a scope restricts it to the
+// resources whose elements match its token parameters, i.e. an Observation
+// with a category coded "laboratory". SMART v1 scopes, such as
+// "patient/*.read", are accepted too.
+package smart
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// Permissions is a set of interactions.
+type Permissions uint8
+
+// Permissions.
+const (
+	Create Permissions = 1 << iota
+	Read
+	Update
+	Delete
+	Search
+)
+
+// Write is the permissions of SMART v1 write scopes, which operations not
+// invoked by a GET require.
+const Write = Create | Update | Delete
+
+const permissionLetters = "cruds"
+
+// String returns the SMART v2 form of p, i.e. "rs".
+func (p Permissions) String() string {
+	var sb strings.Builder
+	for i, c := range permissionLetters {
+		if p&(1<<i) != 0 {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// Contexts of scopes.
+const (
+	PatientContext = "patient"
+	UserContext    = "user"
+	SystemContext  = "system"
+)
+
+// Scope is a SMART resource scope.
+type Scope struct {
+	// Context is PatientContext, UserContext or SystemContext.
+	Context string
+	// Type is a resource type, or "*" for all.
+	Type        string
+	Permissions Permissions
+	// Query holds the token parameters restricting the scope.
+	Query url.Values
+}
+
+var (
+	resourceTypePattern = regexp.MustCompile(`^(\*|[A-Z][A-Za-z]+)$`)
+	parameterPattern    = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+)
+
+// ParseScope parses a SMART v2 or v1 resource scope.
+func ParseScope(s string) (Scope, error) {
+	rest, query, _ := strings.Cut(s, "?")
+	ctx, rest, ok := strings.Cut(rest, "/")
+	if !ok {
+		return Scope{}, fmt.Errorf("scope %q is not a resource scope", s)
+	}
+	switch ctx {
+	case PatientContext, UserContext, SystemContext:
+	default:
+		return Scope{}, fmt.Errorf("scope %q has unknown context %q", s, ctx)
+	}
+	typ, perms, ok := strings.Cut(rest, ".")
+	if !ok || !resourceTypePattern.MatchString(typ) {
+		return Scope{}, fmt.Errorf("scope %q has no valid resource type", s)
+	}
+	sc := Scope{Context: ctx, Type: typ}
+	switch perms {
+	case "read":
+		sc.Permissions = Read | Search
+	case "write":
+		sc.Permissions = Write
+	case "*":
+		sc.Permissions = Create | Read | Update | Delete | Search
+	default:
+		// v2 permissions are a subset of "cruds", in that order.
+		last := -1
+		for _, c := range perms {
+			i := strings.IndexRune(permissionLetters, c)
+			if i <= last {
+				return Scope{}, fmt.Errorf("scope %q has invalid permissions %q", s, perms)
+			}
+			last = i
+			sc.Permissions |= 1 << i
+		}
+		if sc.Permissions == 0 {
+			return Scope{}, fmt.Errorf("scope %q has no permissions", s)
+		}
+	}
+	if query != "" {
+		if perms == "read" || perms == "write" || perms == "*" {
+			return Scope{}, fmt.Errorf("scope %q: v1 scopes have no query", s)
+		}
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			return Scope{}, fmt.Errorf("scope %q: %w", s, err)
+		}
+		for name := range q {
+			if !parameterPattern.MatchString(name) {
+				return Scope{}, fmt.Errorf("scope %q has unsupported parameter %q", s, name)
+			}
+		}
+		sc.Query = q
+	}
+	return sc, nil
+}
+
+// String returns the SMART v2 form of s.
+func (s Scope) String() string {
+	out := s.Context + "/" + s.Type + "." + s.Permissions.String()
+	if len(s.Query) > 0 {
+		out += "?" + s.Query.Encode()
+	}
+	return out
+}
+
+// Scopes is a set of scopes.
+type Scopes []Scope
+
+// ParseScopes parses the resource scopes of a space-separated scope string,
+// such as the scope of an access token. Other scopes, i.e. "openid" or
+// "launch/patient", are ignored.
+func ParseScopes(s string) (Scopes, error) {
+	var out Scopes
+	for _, f := range strings.Fields(s) {
+		ctx, rest, ok := strings.Cut(f, "/")
+		if !ok || (ctx != PatientContext && ctx != UserContext && ctx != SystemContext) || !strings.Contains(rest, ".") {
+			continue
+		}
+		sc, err := ParseScope(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sc)
+	}
+	return out, nil
+}
+
+// Grant is what an app is granted: its scopes and launch context.
+type Grant struct {
+	Scopes Scopes
+	// Patient is the id of the patient in context, which patient scopes
+	// require.
+	Patient string
+}
+
+// Allows reports whether some scope of g grants p on resources of type typ,
+// regardless of the patient and query restrictions of the scopes.
+func (g Grant) Allows(typ string, p Permissions) bool {
+	for _, s := range g.Scopes {
+		if s.Permissions&p == p && (s.Type == "*" || s.Type == typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permits reports whether some scope of g grants p on res, which may be a
+// ContainedResource.
+func (g Grant) Permits(res proto.Message, p Permissions) bool {
+	res = elementpath.Unwrap(res)
+	if res == nil {
+		return false
+	}
+	typ := elementpath.ResourceType(res)
+	for _, s := range g.Scopes {
+		if s.Permissions&p != p || (s.Type != "*" && s.Type != typ) {
+			continue
+		}
+		if s.Context == PatientContext && (g.Patient == "" || elementpath.PatientOf(res.ProtoReflect()) != "Patient/"+g.Patient) {
+			continue
+		}
+		if s.matches(res, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter removes the entries of b whose resources g does not grant p on,
+// and decrements the total of b by the search matches removed.
+// OperationOutcomes are kept. The resources of transaction and batch
+// responses are removed from their entries instead, which keep their
+// response.
+func (g Grant) Filter(b *r4pb.Bundle, p Permissions) {
+	strip := false
+	switch b.GetType().GetValue() {
+	case c4pb.BundleTypeCode_TRANSACTION_RESPONSE, c4pb.BundleTypeCode_BATCH_RESPONSE:
+		strip = true
+	}
+	entries := b.GetEntry()[:0]
+	removed := 0
+	for _, e := range b.GetEntry() {
+		res := e.GetResource()
+		if res == nil || elementpath.ResourceType(res) == "" || elementpath.ResourceType(res) == "OperationOutcome" || g.Permits(res, p) {
+			entries = append(entries, e)
+			continue
+		}
+		if strip {
+			e.Resource = nil
+			entries = append(entries, e)
+			continue
+		}
+		if mode := e.GetSearch().GetMode().GetValue(); mode == c4pb.SearchEntryModeCode_INVALID_UNINITIALIZED || mode == c4pb.SearchEntryModeCode_MATCH {
+			removed++
+		}
+	}
+	for i := len(entries); i < len(b.Entry); i++ {
+		b.Entry[i] = nil
+	}
+	b.Entry = entries
+	if b.GetTotal() != nil && removed > 0 {
+		if int(b.Total.Value) < removed {
+			b.Total.Value = 0
+		} else {
+			b.Total.Value -= uint32(removed)
+		}
+	}
+}
+
+// matches reports whether res, of type typ, matches every parameter of the
+// query of s. A parameter matches the codes, Codings, CodeableConcepts or
+// strings of the element named after it, i.e. clinicalStatus for
+// clinical-status, against any of its comma-separated tokens.
+func (s Scope) matches(res proto.Message, typ string) bool {
+	for name, values := range s.Query {
+		expr, err := fhirpath.Compile(typ + "." + elementName(name))
+		if err != nil {
+			return false
+		}
+		got, err := expr.Evaluate(res)
+		if err != nil || len(got) == 0 {
+			return false
+		}
+		for _, v := range values {
+			if !matchesToken(got, strings.Split(v, ",")) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// elementName returns the element name of a search parameter name.
+func elementName(param string) string {
+	parts := strings.Split(param, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// matchesToken reports whether any of the values matches any of the tokens,
+// "code", "system|code" or "system|".
+func matchesToken(values fhirpath.Collection, tokens []string) bool {
+	var codings []*d4pb.Coding
+	var codes []string
+	for _, v := range values {
+		switch x := v.(type) {
+		case *d4pb.CodeableConcept:
+			codings = append(codings, x.GetCoding()...)
+		case *d4pb.Coding:
+			codings = append(codings, x)
+		case string:
+			codes = append(codes, x)
+		case proto.Message:
+			if sv, ok := fhirpath.SystemValue(x); ok {
+				if s, ok := sv.(string); ok {
+					codes = append(codes, s)
+				}
+			}
+		}
+	}
+	for _, t := range tokens {
+		system, code, hasSystem := strings.Cut(t, "|")
+		if !hasSystem {
+			code = t
+		}
+		for _, c := range codings {
+			if (!hasSystem || c.GetSystem().GetValue() == system) && (code == "" || c.GetCode().GetValue() == code) {
+				return true
+			}
+		}
+		if hasSystem {
+			continue
+		}
+		for _, c := range codes {
+			if c == code {
+				return true
+			}
+		}
+	}
+	return false
+}
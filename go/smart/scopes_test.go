@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smart
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+const categorySystem = "http://terminology.hl7.org/CodeSystem/observation-category"
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		scope string
+		want  Scope
+	}{
+		{"patient/Observation.rs?category=laboratory", Scope{
+			Context: PatientContext, Type: "Observation", Permissions: Read | Search,
+			Query: url.Values{"category": {"laboratory"}},
+		}},
+		{"user/*.cruds", Scope{Context: UserContext, Type: "*", Permissions: Create | Read | Update | Delete | Search}},
+		{"system/Patient.c", Scope{Context: SystemContext, Type: "Patient", Permissions: Create}},
+		{"patient/*.read", Scope{Context: PatientContext, Type: "*", Permissions: Read | Search}},
+		{"user/Observation.write", Scope{Context: UserContext, Type: "Observation", Permissions: Create | Update | Delete}},
+		{"user/Observation.*", Scope{Context: UserContext, Type: "Observation", Permissions: Create | Read | Update | Delete | Search}},
+	}
+	for _, test := range tests {
+		t.Run(test.scope, func(t *testing.T) {
+			got, err := ParseScope(test.scope)
+			if err != nil {
+				t.Fatalf("ParseScope(%q) returned unexpected error: %v", test.scope, err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ParseScope(%q) diff (-want +got):\n%s", test.scope, diff)
+			}
+		})
+	}
+}
+
+func TestParseScope_Errors(t *testing.T) {
+	for _, scope := range []string{
+		"openid",
+		"clinic/Patient.r",
+		"patient/observation.r",
+		"patient/Observation",
+		"patient/Observation.sr",
+		"patient/Observation.rr",
+		"patient/Observation.x",
+		"patient/Observation.",
+		"patient/Observation.read?category=laboratory",
+		"patient/Observation.rs?code:in=http://example.com/vs",
+	} {
+		if _, err := ParseScope(scope); err == nil {
+			t.Errorf("ParseScope(%q) succeeded, want error", scope)
+		}
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	got, err := ParseScopes("openid fhirUser launch/patient offline_access patient/Patient.r patient/Observation.rs?category=laboratory")
+	if err != nil {
+		t.Fatalf("ParseScopes() returned unexpected error: %v", err)
+	}
+	var strs []string
+	for _, s := range got {
+		strs = append(strs, s.String())
+	}
+	want := []string{"patient/Patient.r", "patient/Observation.rs?category=laboratory"}
+	if diff := cmp.Diff(want, strs); diff != "" {
+		t.Errorf("ParseScopes() diff (-want +got):\n%s", diff)
+	}
+}
+
+func observation(patient, category string) *obspb.Observation {
+	return &obspb.Observation{
+		Id:      &d4pb.Id{Value: category},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: patient}}},
+		Category: []*d4pb.CodeableConcept{{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: categorySystem},
+			Code:   &d4pb.Code{Value: category},
+		}}}},
+	}
+}
+
+func mustParseScopes(t *testing.T, s string) Scopes {
+	t.Helper()
+	scopes, err := ParseScopes(s)
+	if err != nil {
+		t.Fatalf("ParseScopes(%q) returned unexpected error: %v", s, err)
+	}
+	return scopes
+}
+
+func TestPermits(t *testing.T) {
+	lab := mustParseScopes(t, "patient/Observation.rs?category=laboratory")
+	condition := &cpb.Condition{
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		ClinicalStatus: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: "http://terminology.hl7.org/CodeSystem/condition-clinical"},
+			Code:   &d4pb.Code{Value: "active"},
+		}}},
+	}
+	tests := []struct {
+		name string
+		g    Grant
+		res  proto.Message
+		p    Permissions
+		want bool
+	}{
+		{"matching query", Grant{Scopes: lab, Patient: "p1"}, observation("p1", "laboratory"), Read, true},
+		{"query with system", Grant{Scopes: mustParseScopes(t, "patient/Observation.r?category="+categorySystem+"|laboratory"), Patient: "p1"}, observation("p1", "laboratory"), Read, true},
+		{"other category", Grant{Scopes: lab, Patient: "p1"}, observation("p1", "vital-signs"), Read, false},
+		{"any of the tokens", Grant{Scopes: mustParseScopes(t, "patient/Observation.r?category=vital-signs,laboratory"), Patient: "p1"}, observation("p1", "laboratory"), Read, true},
+		{"other patient", Grant{Scopes: lab, Patient: "p2"}, observation("p1", "laboratory"), Read, false},
+		{"no patient in context", Grant{Scopes: lab}, observation("p1", "laboratory"), Read, false},
+		{"missing permission", Grant{Scopes: lab, Patient: "p1"}, observation("p1", "laboratory"), Update, false},
+		{"user scope", Grant{Scopes: mustParseScopes(t, "user/Observation.r")}, observation("p1", "vital-signs"), Read, true},
+		{"wildcard", Grant{Scopes: mustParseScopes(t, "patient/*.r"), Patient: "p1"}, &ppb.Patient{Id: &d4pb.Id{Value: "p1"}}, Read, true},
+		{"other type", Grant{Scopes: lab, Patient: "p1"}, &ppb.Patient{Id: &d4pb.Id{Value: "p1"}}, Read, false},
+		{"hyphenated parameter", Grant{Scopes: mustParseScopes(t, "patient/Condition.rs?clinical-status=active"), Patient: "p1"}, condition, Read, true},
+		{"enum code", Grant{Scopes: mustParseScopes(t, "user/Patient.r?gender=female")}, &ppb.Patient{Gender: &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE}}, Read, true},
+		{"unknown element", Grant{Scopes: mustParseScopes(t, "user/Patient.r?unknown=x")}, &ppb.Patient{}, Read, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.g.Permits(test.res, test.p); got != test.want {
+				t.Errorf("Permits() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func entry(res proto.Message, mode c4pb.SearchEntryModeCode_Value) *r4pb.Bundle_Entry {
+	cr := &r4pb.ContainedResource{}
+	switch r := res.(type) {
+	case *obspb.Observation:
+		cr.OneofResource = &r4pb.ContainedResource_Observation{Observation: r}
+	case *ppb.Patient:
+		cr.OneofResource = &r4pb.ContainedResource_Patient{Patient: r}
+	case *oopb.OperationOutcome:
+		cr.OneofResource = &r4pb.ContainedResource_OperationOutcome{OperationOutcome: r}
+	}
+	return &r4pb.Bundle_Entry{
+		Resource: cr,
+		Search:   &r4pb.Bundle_Entry_Search{Mode: &r4pb.Bundle_Entry_Search_ModeCode{Value: mode}},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	g := Grant{Scopes: mustParseScopes(t, "patient/Observation.rs?category=laboratory"), Patient: "p1"}
+	b := &r4pb.Bundle{
+		Type:  &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_SEARCHSET},
+		Total: &d4pb.UnsignedInt{Value: 2},
+		Entry: []*r4pb.Bundle_Entry{
+			entry(observation("p1", "laboratory"), c4pb.SearchEntryModeCode_MATCH),
+			entry(observation("p1", "social-history"), c4pb.SearchEntryModeCode_MATCH),
+			entry(&ppb.Patient{Id: &d4pb.Id{Value: "p1"}}, c4pb.SearchEntryModeCode_INCLUDE),
+			entry(&oopb.OperationOutcome{}, c4pb.SearchEntryModeCode_OUTCOME),
+		},
+	}
+	g.Filter(b, Search)
+	var got []string
+	for _, e := range b.GetEntry() {
+		got = append(got, e.GetSearch().GetMode().GetValue().String())
+	}
+	if diff := cmp.Diff([]string{"MATCH", "OUTCOME"}, got); diff != "" {
+		t.Errorf("Filter() entries diff (-want +got):\n%s", diff)
+	}
+	if b.GetTotal().GetValue() != 1 {
+		t.Errorf("Filter() total = %d, want 1", b.GetTotal().GetValue())
+	}
+}
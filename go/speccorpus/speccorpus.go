@@ -0,0 +1,219 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package speccorpus gives tests the example resources published with the
+// FHIR specification, so that they can round-trip and validate the whole
+// corpus without vendoring the specification.
+//
+// The examples are read from the spec directory of this repository: the
+// hl7.fhir.r4.examples package for R4 and the hl7.fhir.core package, which
+// holds the examples along with the definitions, for STU3. Open finds the
+// directory in the Bazel runfiles, relative to this source file, or under
+// the directory named by the FHIR_SPEC_ROOT environment variable, and
+// returns ErrUnavailable where the examples were not checked out. Examples
+// are parsed as they are iterated:
+//
+//	func TestRoundTrip(t *testing.T) {
+//		speccorpus.ForEach(t, fhirversion.R4, func(t *testing.T, ex *speccorpus.Example) {
+//			...
+//		})
+//	}
+package speccorpus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/rules_go/go/tools/bazel"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrUnavailable is returned by Open when the examples of a version are not
+// on disk.
+var ErrUnavailable = errors.New("spec examples unavailable")
+
+// RootEnv is the environment variable naming the root of a checkout of this
+// repository, i.e. the directory holding spec/.
+const RootEnv = "FHIR_SPEC_ROOT"
+
+// versionDirs are the directories of the examples of each version, relative
+// to the repository root.
+var versionDirs = map[fhirversion.Version]string{
+	fhirversion.STU3: "spec/hl7.fhir.core/3.0.1/package",
+	fhirversion.R4:   "spec/hl7.fhir.r4.examples/4.0.1/package",
+}
+
+// Corpus is the example resources of a FHIR version.
+type Corpus struct {
+	dir   string
+	ver   fhirversion.Version
+	names []string
+	um    *jsonformat.Unmarshaller
+}
+
+// Example is an example resource of a Corpus.
+type Example struct {
+	// Name is the file name of the example without its extension, i.e.
+	// "Patient-example".
+	Name string
+	// JSON is the content of the example file.
+	JSON []byte
+	// Resource is the example parsed into the ContainedResource of its
+	// version.
+	Resource proto.Message
+}
+
+// root returns the candidate roots of the repository.
+func root() []string {
+	var roots []string
+	if r := os.Getenv(RootEnv); r != "" {
+		roots = append(roots, r)
+	}
+	if r, err := bazel.RunfilesPath(); err == nil {
+		roots = append(roots, r)
+	}
+	if _, file, _, ok := runtime.Caller(0); ok {
+		roots = append(roots, filepath.Join(filepath.Dir(file), "..", ".."))
+	}
+	return roots
+}
+
+// Open returns the corpus of the examples of ver.
+func Open(ver fhirversion.Version) (*Corpus, error) {
+	rel, ok := versionDirs[ver]
+	if !ok {
+		return nil, fmt.Errorf("%w: no examples of FHIR version %s", ErrUnavailable, ver)
+	}
+	for _, r := range root() {
+		c, err := OpenDir(filepath.Join(r, filepath.FromSlash(rel)), ver)
+		if err == nil {
+			return c, nil
+		}
+		if !errors.Is(err, ErrUnavailable) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s not found, set %s to a checkout of the repository", ErrUnavailable, rel, RootEnv)
+}
+
+// OpenDir returns the corpus of the JSON examples of ver in dir, such as an
+// extracted examples package.
+func OpenDir(dir string, ver fhirversion.Version) (*Corpus, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		// package.json and dot files such as .index.json describe the
+		// package, not examples.
+		if e.IsDir() || !strings.HasSuffix(name, ".json") || name == "package.json" || strings.HasPrefix(name, ".") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(name, ".json"))
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: no examples in %s", ErrUnavailable, dir)
+	}
+	sort.Strings(names)
+	um, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", ver)
+	if err != nil {
+		return nil, err
+	}
+	return &Corpus{dir: dir, ver: ver, names: names, um: um}, nil
+}
+
+// Version returns the FHIR version of c.
+func (c *Corpus) Version() fhirversion.Version {
+	return c.ver
+}
+
+// Names returns the names of the examples of c, sorted.
+func (c *Corpus) Names() []string {
+	return append([]string(nil), c.names...)
+}
+
+// Load reads and parses the example name.
+func (c *Corpus) Load(name string) (*Example, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir, name+".json"))
+	if err != nil {
+		return nil, err
+	}
+	ex := &Example{Name: name, JSON: data}
+	if ex.Resource, err = c.um.Unmarshal(data); err != nil {
+		return ex, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return ex, nil
+}
+
+// Iterator returns an iterator over the examples of c, in the order of
+// their names.
+func (c *Corpus) Iterator() *Iterator {
+	return &Iterator{c: c}
+}
+
+// Iterator reads the examples of a Corpus one at a time.
+type Iterator struct {
+	c *Corpus
+	i int
+}
+
+// Next returns the next example, or io.EOF after the last one. An example
+// that does not parse is returned, without its Resource, along with the
+// error, and iteration can continue past it.
+func (it *Iterator) Next() (*Example, error) {
+	if it.i >= len(it.c.names) {
+		return nil, io.EOF
+	}
+	name := it.c.names[it.i]
+	it.i++
+	return it.c.Load(name)
+}
+
+// ForEach runs fn in a subtest of t, named for the example, for every
+// example of ver. The subtests of examples that do not parse fail, and t is
+// skipped if the examples are unavailable.
+func ForEach(t *testing.T, ver fhirversion.Version, fn func(t *testing.T, ex *Example)) {
+	t.Helper()
+	c, err := Open(ver)
+	if errors.Is(err, ErrUnavailable) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("speccorpus.Open(%v) returned unexpected error: %v", ver, err)
+	}
+	for _, name := range c.names {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			ex, err := c.Load(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fn(t, ex)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package speccorpus
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/go-cmp/cmp"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+var testFiles = map[string]string{
+	"package.json":         `{"name": "hl7.fhir.r4.examples", "version": "4.0.1"}`,
+	".index.json":          `{"index-version": 1, "files": []}`,
+	"Patient-example.json": `{"resourceType": "Patient", "id": "example", "active": true}`,
+	"Observation-bad.json": `{"resourceType": "Observation", "unknown": 1}`,
+}
+
+// writeRoot writes testFiles as the R4 examples of a repository root and
+// returns it.
+func writeRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, filepath.FromSlash(versionDirs[fhirversion.R4]))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+	for name, content := range testFiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+	return root
+}
+
+func TestIterator(t *testing.T) {
+	root := writeRoot(t)
+	c, err := OpenDir(filepath.Join(root, filepath.FromSlash(versionDirs[fhirversion.R4])), fhirversion.R4)
+	if err != nil {
+		t.Fatalf("OpenDir() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"Observation-bad", "Patient-example"}, c.Names()); diff != "" {
+		t.Errorf("Names() diff (-want +got):\n%s", diff)
+	}
+	it := c.Iterator()
+	ex, err := it.Next()
+	if err == nil || ex.Name != "Observation-bad" || ex.Resource != nil {
+		t.Errorf("Next() = %v, %v, want Observation-bad without resource and an error", ex, err)
+	}
+	ex, err = it.Next()
+	if err != nil {
+		t.Fatalf("Next() returned unexpected error: %v", err)
+	}
+	if got := ex.Resource.(*r4pb.ContainedResource).GetPatient().GetId().GetValue(); ex.Name != "Patient-example" || got != "example" {
+		t.Errorf("Next() returned %s with Patient/%s, want Patient-example with Patient/example", ex.Name, got)
+	}
+	if _, err := it.Next(); err != io.EOF {
+		t.Errorf("Next() after the last example returned %v, want io.EOF", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Setenv(RootEnv, writeRoot(t))
+	c, err := Open(fhirversion.R4)
+	if err != nil {
+		t.Fatalf("Open() returned unexpected error: %v", err)
+	}
+	if got := len(c.Names()); got != 2 {
+		t.Errorf("Open() returned %d examples, want 2", got)
+	}
+	if _, err := OpenDir(t.TempDir(), fhirversion.R4); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("OpenDir() of an empty directory returned %v, want %v", err, ErrUnavailable)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	root := writeRoot(t)
+	dir := filepath.Join(root, filepath.FromSlash(versionDirs[fhirversion.R4]))
+	if err := os.Remove(filepath.Join(dir, "Observation-bad.json")); err != nil {
+		t.Fatalf("os.Remove() returned unexpected error: %v", err)
+	}
+	t.Setenv(RootEnv, root)
+	var got []string
+	ForEach(t, fhirversion.R4, func(t *testing.T, ex *Example) {
+		got = append(got, ex.Name)
+	})
+	if diff := cmp.Diff([]string{"Patient-example"}, got); diff != "" {
+		t.Errorf("ForEach() examples diff (-want +got):\n%s", diff)
+	}
+}
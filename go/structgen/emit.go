@@ -0,0 +1,289 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structgen
+
+import (
+	"bytes"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const jsonformatImport = "github.com/google/fhir/go/jsonformat"
+
+// emit writes the unformatted source of the generated file.
+func (g *generator) emit() []byte {
+	var body bytes.Buffer
+	if len(g.temporals) > 0 {
+		fmt.Fprint(&body, `
+// Temporal is a FHIR date, dateTime, instant or time. ValueUs counts
+// microseconds since the Unix epoch, or since midnight for a time. Precision
+// is the name of the precision enum value of the proto, i.e. "DAY".
+type Temporal struct {
+	ValueUs   int64
+	Timezone  string
+	Precision string
+}
+`)
+	}
+	for _, md := range g.structs {
+		g.emitStruct(&body, md)
+		g.emitToProto(&body, md)
+		g.emitFromProto(&body, md)
+	}
+	for _, md := range g.scalars {
+		g.emitScalarHelpers(&body, md)
+	}
+	for _, md := range g.temporals {
+		g.emitTemporalHelpers(&body, md)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprint(&out, "// Code generated by structgen. DO NOT EDIT.\n")
+	if g.cfg.Command != "" {
+		fmt.Fprintf(&out, "// %s\n", g.cfg.Command)
+	}
+	fmt.Fprintf(&out, "\npackage %s\n\nimport (\n", g.cfg.Package)
+	if g.usesJSONFormat {
+		fmt.Fprintf(&out, "\t%q\n\n", jsonformatImport)
+	}
+	for _, path := range g.sortedImports() {
+		fmt.Fprintf(&out, "\t%s %q\n", g.imports[path], path)
+	}
+	fmt.Fprint(&out, ")\n")
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+// emitStruct writes the struct type for md.
+func (g *generator) emitStruct(w *bytes.Buffer, md protoreflect.MessageDescriptor) {
+	fmt.Fprintf(w, "\n// %s is the plain Go form of %s.\ntype %[1]s struct {\n", structName(md), md.FullName())
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		switch {
+		case isReferenceOneof(fd):
+			if fd.ContainingOneof().Fields().Get(0) == fd {
+				fmt.Fprint(w, "\tReference *string\n")
+			}
+		case fd.Message() == nil:
+			t := scalarKindType(fd)
+			if fd.IsList() {
+				t = "[]" + t
+			}
+			fmt.Fprintf(w, "\t%s %s\n", fieldName(fd), t)
+		case fd.ContainingOneof() != nil:
+			fmt.Fprintf(w, "\t%s %s\n", fieldName(fd), g.goType(fd.Message(), false))
+		default:
+			fmt.Fprintf(w, "\t%s %s\n", fieldName(fd), g.goType(fd.Message(), fd.IsList()))
+		}
+	}
+	fmt.Fprint(w, "}\n")
+}
+
+// scalarKindType returns the Go type of a non-message proto field.
+func scalarKindType(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.BytesKind:
+		return "[]byte"
+	}
+	return "string"
+}
+
+// toProto returns the expression converting the Go element v, which must not
+// be nil, into a proto message of type md.
+func (g *generator) toProto(md protoreflect.MessageDescriptor, v string) string {
+	switch g.classify(md) {
+	case kindScalar, kindTemporal:
+		return helperName(md) + "ToProto(" + v + ")"
+	case kindStruct:
+		return v + ".ToProto()"
+	}
+	return v
+}
+
+// fromProto returns the expression converting the proto message v of type md
+// into its Go element.
+func (g *generator) fromProto(md protoreflect.MessageDescriptor, v string) string {
+	switch g.classify(md) {
+	case kindScalar, kindTemporal:
+		return helperName(md) + "FromProto(" + v + ")"
+	case kindStruct:
+		return structName(md) + "FromProto(" + v + ")"
+	}
+	return v
+}
+
+// isPointerScalar reports whether elements of type md are held by pointers
+// to scalars in the generated struct.
+func (g *generator) isPointerScalar(md protoreflect.MessageDescriptor) bool {
+	return g.classify(md) == kindScalar && g.scalarType(md) != "[]byte"
+}
+
+func (g *generator) emitToProto(w *bytes.Buffer, md protoreflect.MessageDescriptor) {
+	name, pt := structName(md), g.protoType(md)
+	fmt.Fprintf(w, "\n// ToProto converts x to its proto form. It returns nil if x is nil.\n")
+	fmt.Fprintf(w, "func (x *%s) ToProto() *%s {\n\tif x == nil {\n\t\treturn nil\n\t}\n\tp := &%s{}\n", name, pt, pt)
+	oneofs := md.Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		od := oneofs.Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+		ofield := goCamelCase(string(od.Name()))
+		if od.Name() == "reference" && md.Name() == "Reference" {
+			uri := od.Fields().ByName("uri")
+			fmt.Fprintf(w, "\tif x.Reference != nil {\n")
+			fmt.Fprintf(w, "\t\tp.%s = &%s{%s: &%s{Value: *x.Reference}}\n", ofield, g.oneofWrapper(uri), fieldName(uri), g.protoType(uri.Message()))
+			fmt.Fprintf(w, "\t\t// References that do not name a known resource type stay URIs.\n")
+			fmt.Fprintf(w, "\t\t_ = jsonformat.NormalizeReference(p)\n\t}\n")
+			continue
+		}
+		fmt.Fprint(w, "\tswitch {\n")
+		for j := 0; j < od.Fields().Len(); j++ {
+			fd := od.Fields().Get(j)
+			v := "x." + fieldName(fd)
+			if g.isPointerScalar(fd.Message()) {
+				v = "*" + v
+			}
+			fmt.Fprintf(w, "\tcase x.%s != nil:\n\t\tp.%s = &%s{%s: %s}\n", fieldName(fd), ofield, g.oneofWrapper(fd), fieldName(fd), g.toProto(fd.Message(), v))
+		}
+		fmt.Fprint(w, "\t}\n")
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.ContainingOneof() != nil && !fd.ContainingOneof().IsSynthetic() {
+			continue
+		}
+		f := fieldName(fd)
+		switch {
+		case fd.Message() == nil, g.classify(fd.Message()) == kindProto:
+			fmt.Fprintf(w, "\tp.%s = x.%[1]s\n", f)
+		case fd.IsList():
+			fmt.Fprintf(w, "\tfor _, v := range x.%s {\n\t\tp.%[1]s = append(p.%[1]s, %s)\n\t}\n", f, g.toProto(fd.Message(), "v"))
+		case g.classify(fd.Message()) == kindScalar:
+			v := "x." + f
+			if g.isPointerScalar(fd.Message()) {
+				v = "*" + v
+			}
+			fmt.Fprintf(w, "\tif x.%s != nil {\n\t\tp.%[1]s = %s\n\t}\n", f, g.toProto(fd.Message(), v))
+		default:
+			fmt.Fprintf(w, "\tp.%s = %s\n", f, g.toProto(fd.Message(), "x."+f))
+		}
+	}
+	fmt.Fprint(w, "\treturn p\n}\n")
+}
+
+func (g *generator) emitFromProto(w *bytes.Buffer, md protoreflect.MessageDescriptor) {
+	name, pt := structName(md), g.protoType(md)
+	fmt.Fprintf(w, "\n// %sFromProto converts p to its plain Go form. It returns nil if p is nil.\n", name)
+	fmt.Fprintf(w, "func %sFromProto(p *%s) *%[1]s {\n\tif p == nil {\n\t\treturn nil\n\t}\n\tx := &%[1]s{}\n", name, pt)
+	oneofs := md.Oneofs()
+	for i := 0; i < oneofs.Len(); i++ {
+		od := oneofs.Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+		ofield := goCamelCase(string(od.Name()))
+		if od.Name() == "reference" && md.Name() == "Reference" {
+			fmt.Fprintf(w, "\tif p.%s != nil {\n", ofield)
+			fmt.Fprint(w, "\t\tif r, err := jsonformat.NewDenormalizedReference(p); err == nil {\n")
+			fmt.Fprintf(w, "\t\t\tif u := r.(*%s).GetUri(); u != nil {\n", pt)
+			fmt.Fprint(w, "\t\t\t\ts := u.GetValue()\n\t\t\t\tx.Reference = &s\n\t\t\t}\n\t\t}\n\t}\n")
+			continue
+		}
+		fmt.Fprintf(w, "\tswitch v := p.%s.(type) {\n", ofield)
+		for j := 0; j < od.Fields().Len(); j++ {
+			fd := od.Fields().Get(j)
+			f := fieldName(fd)
+			fmt.Fprintf(w, "\tcase *%s:\n", g.oneofWrapper(fd))
+			if g.isPointerScalar(fd.Message()) {
+				fmt.Fprintf(w, "\t\ts := %s\n\t\tx.%s = &s\n", g.fromProto(fd.Message(), "v."+f), f)
+			} else {
+				fmt.Fprintf(w, "\t\tx.%s = %s\n", f, g.fromProto(fd.Message(), "v."+f))
+			}
+		}
+		fmt.Fprint(w, "\t}\n")
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.ContainingOneof() != nil && !fd.ContainingOneof().IsSynthetic() {
+			continue
+		}
+		f := fieldName(fd)
+		switch {
+		case fd.Message() == nil, g.classify(fd.Message()) == kindProto:
+			fmt.Fprintf(w, "\tx.%s = p.%[1]s\n", f)
+		case fd.IsList():
+			fmt.Fprintf(w, "\tfor _, v := range p.%s {\n\t\tx.%[1]s = append(x.%[1]s, %s)\n\t}\n", f, g.fromProto(fd.Message(), "v"))
+		case g.isPointerScalar(fd.Message()):
+			fmt.Fprintf(w, "\tif p.%s != nil {\n\t\ts := %s\n\t\tx.%[1]s = &s\n\t}\n", f, g.fromProto(fd.Message(), "p."+f))
+		case g.classify(fd.Message()) == kindScalar:
+			fmt.Fprintf(w, "\tif p.%s != nil {\n\t\tx.%[1]s = %s\n\t}\n", f, g.fromProto(fd.Message(), "p."+f))
+		default:
+			fmt.Fprintf(w, "\tx.%s = %s\n", f, g.fromProto(fd.Message(), "p."+f))
+		}
+	}
+	fmt.Fprint(w, "\treturn x\n}\n")
+}
+
+func (g *generator) emitScalarHelpers(w *bytes.Buffer, md protoreflect.MessageDescriptor) {
+	h, pt, t := helperName(md), g.protoType(md), g.scalarType(md)
+	fmt.Fprintf(w, "\nfunc %sToProto(v %s) *%s {\n\treturn &%[3]s{Value: v}\n}\n", h, t, pt)
+	fmt.Fprintf(w, "\nfunc %sFromProto(p *%s) %s {\n\treturn p.GetValue()\n}\n", h, pt, t)
+}
+
+func (g *generator) emitTemporalHelpers(w *bytes.Buffer, md protoreflect.MessageDescriptor) {
+	h, pt := helperName(md), g.protoType(md)
+	tz := md.Fields().ByName("timezone")
+	prec := md.Fields().ByName("precision")
+
+	fmt.Fprintf(w, "\nfunc %sToProto(t *Temporal) *%s {\n\tif t == nil {\n\t\treturn nil\n\t}\n", h, pt)
+	fmt.Fprintf(w, "\treturn &%s{\n\t\tValueUs: t.ValueUs,\n", pt)
+	if tz != nil {
+		fmt.Fprint(w, "\t\tTimezone: t.Timezone,\n")
+	}
+	if prec != nil {
+		et := g.qualify(prec.Enum()) + "." + goIdent(prec.Enum())
+		fmt.Fprintf(w, "\t\tPrecision: %s(%[1]s_value[t.Precision]),\n", et)
+	}
+	fmt.Fprint(w, "\t}\n}\n")
+
+	fmt.Fprintf(w, "\nfunc %sFromProto(p *%s) *Temporal {\n\tif p == nil {\n\t\treturn nil\n\t}\n", h, pt)
+	fmt.Fprint(w, "\treturn &Temporal{\n\t\tValueUs: p.GetValueUs(),\n")
+	if tz != nil {
+		fmt.Fprint(w, "\t\tTimezone: p.GetTimezone(),\n")
+	}
+	if prec != nil {
+		fmt.Fprint(w, "\t\tPrecision: p.GetPrecision().String(),\n")
+	}
+	fmt.Fprint(w, "\t}\n}\n")
+}
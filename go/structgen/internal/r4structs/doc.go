@@ -0,0 +1,20 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package r4structs holds the structs generated for Patient and Observation.
+// It is the golden output of the generator and exercises the generated
+// converters.
+package r4structs
+
+//go:generate go run github.com/google/fhir/go/cmd/structgen -package r4structs -resources Patient,Observation -out structs.go
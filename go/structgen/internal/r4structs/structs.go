@@ -0,0 +1,2734 @@
+// Code generated by structgen. DO NOT EDIT.
+// structgen -package r4structs -resources Patient,Observation -out structs.go
+
+package r4structs
+
+import (
+	"github.com/google/fhir/go/jsonformat"
+
+	anypb "github.com/golang/protobuf/ptypes/any"
+	codespb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	datatypespb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	observationpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	patientpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	valuesetspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+// Temporal is a FHIR date, dateTime, instant or time. ValueUs counts
+// microseconds since the Unix epoch, or since midnight for a time. Precision
+// is the name of the precision enum value of the proto, i.e. "DAY".
+type Temporal struct {
+	ValueUs   int64
+	Timezone  string
+	Precision string
+}
+
+// Patient is the plain Go form of google.fhir.r4.core.Patient.
+type Patient struct {
+	Id                   *string
+	Meta                 *Meta
+	ImplicitRules        *string
+	Language             *string
+	Text                 *Narrative
+	Contained            []*anypb.Any
+	Extension            []*datatypespb.Extension
+	ModifierExtension    []*datatypespb.Extension
+	Identifier           []*Identifier
+	Active               *bool
+	Name                 []*HumanName
+	Telecom              []*ContactPoint
+	Gender               *codespb.AdministrativeGenderCode_Value
+	BirthDate            *Temporal
+	Deceased             *PatientDeceasedX
+	Address              []*Address
+	MaritalStatus        *CodeableConcept
+	MultipleBirth        *PatientMultipleBirthX
+	Photo                []*Attachment
+	Contact              []*PatientContact
+	Communication        []*PatientCommunication
+	GeneralPractitioner  []*Reference
+	ManagingOrganization *Reference
+	Link                 []*PatientLink
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Patient) ToProto() *patientpb.Patient {
+	if x == nil {
+		return nil
+	}
+	p := &patientpb.Patient{}
+	if x.Id != nil {
+		p.Id = idToProto(*x.Id)
+	}
+	p.Meta = x.Meta.ToProto()
+	if x.ImplicitRules != nil {
+		p.ImplicitRules = uriToProto(*x.ImplicitRules)
+	}
+	if x.Language != nil {
+		p.Language = codeToProto(*x.Language)
+	}
+	p.Text = x.Text.ToProto()
+	p.Contained = x.Contained
+	p.Extension = x.Extension
+	p.ModifierExtension = x.ModifierExtension
+	for _, v := range x.Identifier {
+		p.Identifier = append(p.Identifier, v.ToProto())
+	}
+	if x.Active != nil {
+		p.Active = booleanToProto(*x.Active)
+	}
+	for _, v := range x.Name {
+		p.Name = append(p.Name, v.ToProto())
+	}
+	for _, v := range x.Telecom {
+		p.Telecom = append(p.Telecom, v.ToProto())
+	}
+	if x.Gender != nil {
+		p.Gender = patientGenderCodeToProto(*x.Gender)
+	}
+	p.BirthDate = dateToProto(x.BirthDate)
+	p.Deceased = x.Deceased.ToProto()
+	for _, v := range x.Address {
+		p.Address = append(p.Address, v.ToProto())
+	}
+	p.MaritalStatus = x.MaritalStatus.ToProto()
+	p.MultipleBirth = x.MultipleBirth.ToProto()
+	for _, v := range x.Photo {
+		p.Photo = append(p.Photo, v.ToProto())
+	}
+	for _, v := range x.Contact {
+		p.Contact = append(p.Contact, v.ToProto())
+	}
+	for _, v := range x.Communication {
+		p.Communication = append(p.Communication, v.ToProto())
+	}
+	for _, v := range x.GeneralPractitioner {
+		p.GeneralPractitioner = append(p.GeneralPractitioner, v.ToProto())
+	}
+	p.ManagingOrganization = x.ManagingOrganization.ToProto()
+	for _, v := range x.Link {
+		p.Link = append(p.Link, v.ToProto())
+	}
+	return p
+}
+
+// PatientFromProto converts p to its plain Go form. It returns nil if p is nil.
+func PatientFromProto(p *patientpb.Patient) *Patient {
+	if p == nil {
+		return nil
+	}
+	x := &Patient{}
+	if p.Id != nil {
+		s := idFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Meta = MetaFromProto(p.Meta)
+	if p.ImplicitRules != nil {
+		s := uriFromProto(p.ImplicitRules)
+		x.ImplicitRules = &s
+	}
+	if p.Language != nil {
+		s := codeFromProto(p.Language)
+		x.Language = &s
+	}
+	x.Text = NarrativeFromProto(p.Text)
+	x.Contained = p.Contained
+	x.Extension = p.Extension
+	x.ModifierExtension = p.ModifierExtension
+	for _, v := range p.Identifier {
+		x.Identifier = append(x.Identifier, IdentifierFromProto(v))
+	}
+	if p.Active != nil {
+		s := booleanFromProto(p.Active)
+		x.Active = &s
+	}
+	for _, v := range p.Name {
+		x.Name = append(x.Name, HumanNameFromProto(v))
+	}
+	for _, v := range p.Telecom {
+		x.Telecom = append(x.Telecom, ContactPointFromProto(v))
+	}
+	if p.Gender != nil {
+		s := patientGenderCodeFromProto(p.Gender)
+		x.Gender = &s
+	}
+	x.BirthDate = dateFromProto(p.BirthDate)
+	x.Deceased = PatientDeceasedXFromProto(p.Deceased)
+	for _, v := range p.Address {
+		x.Address = append(x.Address, AddressFromProto(v))
+	}
+	x.MaritalStatus = CodeableConceptFromProto(p.MaritalStatus)
+	x.MultipleBirth = PatientMultipleBirthXFromProto(p.MultipleBirth)
+	for _, v := range p.Photo {
+		x.Photo = append(x.Photo, AttachmentFromProto(v))
+	}
+	for _, v := range p.Contact {
+		x.Contact = append(x.Contact, PatientContactFromProto(v))
+	}
+	for _, v := range p.Communication {
+		x.Communication = append(x.Communication, PatientCommunicationFromProto(v))
+	}
+	for _, v := range p.GeneralPractitioner {
+		x.GeneralPractitioner = append(x.GeneralPractitioner, ReferenceFromProto(v))
+	}
+	x.ManagingOrganization = ReferenceFromProto(p.ManagingOrganization)
+	for _, v := range p.Link {
+		x.Link = append(x.Link, PatientLinkFromProto(v))
+	}
+	return x
+}
+
+// Observation is the plain Go form of google.fhir.r4.core.Observation.
+type Observation struct {
+	Id                *string
+	Meta              *Meta
+	ImplicitRules     *string
+	Language          *string
+	Text              *Narrative
+	Contained         []*anypb.Any
+	Extension         []*datatypespb.Extension
+	ModifierExtension []*datatypespb.Extension
+	Identifier        []*Identifier
+	BasedOn           []*Reference
+	PartOf            []*Reference
+	Status            *codespb.ObservationStatusCode_Value
+	Category          []*CodeableConcept
+	Code              *CodeableConcept
+	Subject           *Reference
+	Focus             []*Reference
+	Encounter         *Reference
+	Effective         *ObservationEffectiveX
+	Issued            *Temporal
+	Performer         []*Reference
+	Value             *ObservationValueX
+	DataAbsentReason  *CodeableConcept
+	Interpretation    []*CodeableConcept
+	Note              []*Annotation
+	BodySite          *CodeableConcept
+	Method            *CodeableConcept
+	Specimen          *Reference
+	Device            *Reference
+	ReferenceRange    []*ObservationReferenceRange
+	HasMember         []*Reference
+	DerivedFrom       []*Reference
+	Component         []*ObservationComponent
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Observation) ToProto() *observationpb.Observation {
+	if x == nil {
+		return nil
+	}
+	p := &observationpb.Observation{}
+	if x.Id != nil {
+		p.Id = idToProto(*x.Id)
+	}
+	p.Meta = x.Meta.ToProto()
+	if x.ImplicitRules != nil {
+		p.ImplicitRules = uriToProto(*x.ImplicitRules)
+	}
+	if x.Language != nil {
+		p.Language = codeToProto(*x.Language)
+	}
+	p.Text = x.Text.ToProto()
+	p.Contained = x.Contained
+	p.Extension = x.Extension
+	p.ModifierExtension = x.ModifierExtension
+	for _, v := range x.Identifier {
+		p.Identifier = append(p.Identifier, v.ToProto())
+	}
+	for _, v := range x.BasedOn {
+		p.BasedOn = append(p.BasedOn, v.ToProto())
+	}
+	for _, v := range x.PartOf {
+		p.PartOf = append(p.PartOf, v.ToProto())
+	}
+	if x.Status != nil {
+		p.Status = observationStatusCodeToProto(*x.Status)
+	}
+	for _, v := range x.Category {
+		p.Category = append(p.Category, v.ToProto())
+	}
+	p.Code = x.Code.ToProto()
+	p.Subject = x.Subject.ToProto()
+	for _, v := range x.Focus {
+		p.Focus = append(p.Focus, v.ToProto())
+	}
+	p.Encounter = x.Encounter.ToProto()
+	p.Effective = x.Effective.ToProto()
+	p.Issued = instantToProto(x.Issued)
+	for _, v := range x.Performer {
+		p.Performer = append(p.Performer, v.ToProto())
+	}
+	p.Value = x.Value.ToProto()
+	p.DataAbsentReason = x.DataAbsentReason.ToProto()
+	for _, v := range x.Interpretation {
+		p.Interpretation = append(p.Interpretation, v.ToProto())
+	}
+	for _, v := range x.Note {
+		p.Note = append(p.Note, v.ToProto())
+	}
+	p.BodySite = x.BodySite.ToProto()
+	p.Method = x.Method.ToProto()
+	p.Specimen = x.Specimen.ToProto()
+	p.Device = x.Device.ToProto()
+	for _, v := range x.ReferenceRange {
+		p.ReferenceRange = append(p.ReferenceRange, v.ToProto())
+	}
+	for _, v := range x.HasMember {
+		p.HasMember = append(p.HasMember, v.ToProto())
+	}
+	for _, v := range x.DerivedFrom {
+		p.DerivedFrom = append(p.DerivedFrom, v.ToProto())
+	}
+	for _, v := range x.Component {
+		p.Component = append(p.Component, v.ToProto())
+	}
+	return p
+}
+
+// ObservationFromProto converts p to its plain Go form. It returns nil if p is nil.
+func ObservationFromProto(p *observationpb.Observation) *Observation {
+	if p == nil {
+		return nil
+	}
+	x := &Observation{}
+	if p.Id != nil {
+		s := idFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Meta = MetaFromProto(p.Meta)
+	if p.ImplicitRules != nil {
+		s := uriFromProto(p.ImplicitRules)
+		x.ImplicitRules = &s
+	}
+	if p.Language != nil {
+		s := codeFromProto(p.Language)
+		x.Language = &s
+	}
+	x.Text = NarrativeFromProto(p.Text)
+	x.Contained = p.Contained
+	x.Extension = p.Extension
+	x.ModifierExtension = p.ModifierExtension
+	for _, v := range p.Identifier {
+		x.Identifier = append(x.Identifier, IdentifierFromProto(v))
+	}
+	for _, v := range p.BasedOn {
+		x.BasedOn = append(x.BasedOn, ReferenceFromProto(v))
+	}
+	for _, v := range p.PartOf {
+		x.PartOf = append(x.PartOf, ReferenceFromProto(v))
+	}
+	if p.Status != nil {
+		s := observationStatusCodeFromProto(p.Status)
+		x.Status = &s
+	}
+	for _, v := range p.Category {
+		x.Category = append(x.Category, CodeableConceptFromProto(v))
+	}
+	x.Code = CodeableConceptFromProto(p.Code)
+	x.Subject = ReferenceFromProto(p.Subject)
+	for _, v := range p.Focus {
+		x.Focus = append(x.Focus, ReferenceFromProto(v))
+	}
+	x.Encounter = ReferenceFromProto(p.Encounter)
+	x.Effective = ObservationEffectiveXFromProto(p.Effective)
+	x.Issued = instantFromProto(p.Issued)
+	for _, v := range p.Performer {
+		x.Performer = append(x.Performer, ReferenceFromProto(v))
+	}
+	x.Value = ObservationValueXFromProto(p.Value)
+	x.DataAbsentReason = CodeableConceptFromProto(p.DataAbsentReason)
+	for _, v := range p.Interpretation {
+		x.Interpretation = append(x.Interpretation, CodeableConceptFromProto(v))
+	}
+	for _, v := range p.Note {
+		x.Note = append(x.Note, AnnotationFromProto(v))
+	}
+	x.BodySite = CodeableConceptFromProto(p.BodySite)
+	x.Method = CodeableConceptFromProto(p.Method)
+	x.Specimen = ReferenceFromProto(p.Specimen)
+	x.Device = ReferenceFromProto(p.Device)
+	for _, v := range p.ReferenceRange {
+		x.ReferenceRange = append(x.ReferenceRange, ObservationReferenceRangeFromProto(v))
+	}
+	for _, v := range p.HasMember {
+		x.HasMember = append(x.HasMember, ReferenceFromProto(v))
+	}
+	for _, v := range p.DerivedFrom {
+		x.DerivedFrom = append(x.DerivedFrom, ReferenceFromProto(v))
+	}
+	for _, v := range p.Component {
+		x.Component = append(x.Component, ObservationComponentFromProto(v))
+	}
+	return x
+}
+
+// Meta is the plain Go form of google.fhir.r4.core.Meta.
+type Meta struct {
+	Id          *string
+	Extension   []*datatypespb.Extension
+	VersionId   *string
+	LastUpdated *Temporal
+	Source      *string
+	Profile     []string
+	Security    []*Coding
+	Tag         []*Coding
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Meta) ToProto() *datatypespb.Meta {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Meta{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.VersionId != nil {
+		p.VersionId = idToProto(*x.VersionId)
+	}
+	p.LastUpdated = instantToProto(x.LastUpdated)
+	if x.Source != nil {
+		p.Source = uriToProto(*x.Source)
+	}
+	for _, v := range x.Profile {
+		p.Profile = append(p.Profile, canonicalToProto(v))
+	}
+	for _, v := range x.Security {
+		p.Security = append(p.Security, v.ToProto())
+	}
+	for _, v := range x.Tag {
+		p.Tag = append(p.Tag, v.ToProto())
+	}
+	return p
+}
+
+// MetaFromProto converts p to its plain Go form. It returns nil if p is nil.
+func MetaFromProto(p *datatypespb.Meta) *Meta {
+	if p == nil {
+		return nil
+	}
+	x := &Meta{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.VersionId != nil {
+		s := idFromProto(p.VersionId)
+		x.VersionId = &s
+	}
+	x.LastUpdated = instantFromProto(p.LastUpdated)
+	if p.Source != nil {
+		s := uriFromProto(p.Source)
+		x.Source = &s
+	}
+	for _, v := range p.Profile {
+		x.Profile = append(x.Profile, canonicalFromProto(v))
+	}
+	for _, v := range p.Security {
+		x.Security = append(x.Security, CodingFromProto(v))
+	}
+	for _, v := range p.Tag {
+		x.Tag = append(x.Tag, CodingFromProto(v))
+	}
+	return x
+}
+
+// Narrative is the plain Go form of google.fhir.r4.core.Narrative.
+type Narrative struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	Status    *codespb.NarrativeStatusCode_Value
+	Div       *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Narrative) ToProto() *datatypespb.Narrative {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Narrative{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.Status != nil {
+		p.Status = narrativeStatusCodeToProto(*x.Status)
+	}
+	if x.Div != nil {
+		p.Div = xhtmlToProto(*x.Div)
+	}
+	return p
+}
+
+// NarrativeFromProto converts p to its plain Go form. It returns nil if p is nil.
+func NarrativeFromProto(p *datatypespb.Narrative) *Narrative {
+	if p == nil {
+		return nil
+	}
+	x := &Narrative{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.Status != nil {
+		s := narrativeStatusCodeFromProto(p.Status)
+		x.Status = &s
+	}
+	if p.Div != nil {
+		s := xhtmlFromProto(p.Div)
+		x.Div = &s
+	}
+	return x
+}
+
+// Identifier is the plain Go form of google.fhir.r4.core.Identifier.
+type Identifier struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	Use       *codespb.IdentifierUseCode_Value
+	Type      *CodeableConcept
+	System    *string
+	Value     *string
+	Period    *Period
+	Assigner  *Reference
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Identifier) ToProto() *datatypespb.Identifier {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Identifier{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.Use != nil {
+		p.Use = identifierUseCodeToProto(*x.Use)
+	}
+	p.Type = x.Type.ToProto()
+	if x.System != nil {
+		p.System = uriToProto(*x.System)
+	}
+	if x.Value != nil {
+		p.Value = stringToProto(*x.Value)
+	}
+	p.Period = x.Period.ToProto()
+	p.Assigner = x.Assigner.ToProto()
+	return p
+}
+
+// IdentifierFromProto converts p to its plain Go form. It returns nil if p is nil.
+func IdentifierFromProto(p *datatypespb.Identifier) *Identifier {
+	if p == nil {
+		return nil
+	}
+	x := &Identifier{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.Use != nil {
+		s := identifierUseCodeFromProto(p.Use)
+		x.Use = &s
+	}
+	x.Type = CodeableConceptFromProto(p.Type)
+	if p.System != nil {
+		s := uriFromProto(p.System)
+		x.System = &s
+	}
+	if p.Value != nil {
+		s := stringFromProto(p.Value)
+		x.Value = &s
+	}
+	x.Period = PeriodFromProto(p.Period)
+	x.Assigner = ReferenceFromProto(p.Assigner)
+	return x
+}
+
+// HumanName is the plain Go form of google.fhir.r4.core.HumanName.
+type HumanName struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	Use       *codespb.NameUseCode_Value
+	Text      *string
+	Family    *string
+	Given     []string
+	Prefix    []string
+	Suffix    []string
+	Period    *Period
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *HumanName) ToProto() *datatypespb.HumanName {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.HumanName{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.Use != nil {
+		p.Use = humanNameUseCodeToProto(*x.Use)
+	}
+	if x.Text != nil {
+		p.Text = stringToProto(*x.Text)
+	}
+	if x.Family != nil {
+		p.Family = stringToProto(*x.Family)
+	}
+	for _, v := range x.Given {
+		p.Given = append(p.Given, stringToProto(v))
+	}
+	for _, v := range x.Prefix {
+		p.Prefix = append(p.Prefix, stringToProto(v))
+	}
+	for _, v := range x.Suffix {
+		p.Suffix = append(p.Suffix, stringToProto(v))
+	}
+	p.Period = x.Period.ToProto()
+	return p
+}
+
+// HumanNameFromProto converts p to its plain Go form. It returns nil if p is nil.
+func HumanNameFromProto(p *datatypespb.HumanName) *HumanName {
+	if p == nil {
+		return nil
+	}
+	x := &HumanName{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.Use != nil {
+		s := humanNameUseCodeFromProto(p.Use)
+		x.Use = &s
+	}
+	if p.Text != nil {
+		s := stringFromProto(p.Text)
+		x.Text = &s
+	}
+	if p.Family != nil {
+		s := stringFromProto(p.Family)
+		x.Family = &s
+	}
+	for _, v := range p.Given {
+		x.Given = append(x.Given, stringFromProto(v))
+	}
+	for _, v := range p.Prefix {
+		x.Prefix = append(x.Prefix, stringFromProto(v))
+	}
+	for _, v := range p.Suffix {
+		x.Suffix = append(x.Suffix, stringFromProto(v))
+	}
+	x.Period = PeriodFromProto(p.Period)
+	return x
+}
+
+// ContactPoint is the plain Go form of google.fhir.r4.core.ContactPoint.
+type ContactPoint struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	System    *codespb.ContactPointSystemCode_Value
+	Value     *string
+	Use       *codespb.ContactPointUseCode_Value
+	Rank      *uint32
+	Period    *Period
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *ContactPoint) ToProto() *datatypespb.ContactPoint {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.ContactPoint{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.System != nil {
+		p.System = contactPointSystemCodeToProto(*x.System)
+	}
+	if x.Value != nil {
+		p.Value = stringToProto(*x.Value)
+	}
+	if x.Use != nil {
+		p.Use = contactPointUseCodeToProto(*x.Use)
+	}
+	if x.Rank != nil {
+		p.Rank = positiveIntToProto(*x.Rank)
+	}
+	p.Period = x.Period.ToProto()
+	return p
+}
+
+// ContactPointFromProto converts p to its plain Go form. It returns nil if p is nil.
+func ContactPointFromProto(p *datatypespb.ContactPoint) *ContactPoint {
+	if p == nil {
+		return nil
+	}
+	x := &ContactPoint{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.System != nil {
+		s := contactPointSystemCodeFromProto(p.System)
+		x.System = &s
+	}
+	if p.Value != nil {
+		s := stringFromProto(p.Value)
+		x.Value = &s
+	}
+	if p.Use != nil {
+		s := contactPointUseCodeFromProto(p.Use)
+		x.Use = &s
+	}
+	if p.Rank != nil {
+		s := positiveIntFromProto(p.Rank)
+		x.Rank = &s
+	}
+	x.Period = PeriodFromProto(p.Period)
+	return x
+}
+
+// PatientDeceasedX is the plain Go form of google.fhir.r4.core.Patient.DeceasedX.
+type PatientDeceasedX struct {
+	Boolean  *bool
+	DateTime *Temporal
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *PatientDeceasedX) ToProto() *patientpb.Patient_DeceasedX {
+	if x == nil {
+		return nil
+	}
+	p := &patientpb.Patient_DeceasedX{}
+	switch {
+	case x.Boolean != nil:
+		p.Choice = &patientpb.Patient_DeceasedX_Boolean{Boolean: booleanToProto(*x.Boolean)}
+	case x.DateTime != nil:
+		p.Choice = &patientpb.Patient_DeceasedX_DateTime{DateTime: dateTimeToProto(x.DateTime)}
+	}
+	return p
+}
+
+// PatientDeceasedXFromProto converts p to its plain Go form. It returns nil if p is nil.
+func PatientDeceasedXFromProto(p *patientpb.Patient_DeceasedX) *PatientDeceasedX {
+	if p == nil {
+		return nil
+	}
+	x := &PatientDeceasedX{}
+	switch v := p.Choice.(type) {
+	case *patientpb.Patient_DeceasedX_Boolean:
+		s := booleanFromProto(v.Boolean)
+		x.Boolean = &s
+	case *patientpb.Patient_DeceasedX_DateTime:
+		x.DateTime = dateTimeFromProto(v.DateTime)
+	}
+	return x
+}
+
+// Address is the plain Go form of google.fhir.r4.core.Address.
+type Address struct {
+	Id         *string
+	Extension  []*datatypespb.Extension
+	Use        *codespb.AddressUseCode_Value
+	Type       *codespb.AddressTypeCode_Value
+	Text       *string
+	Line       []string
+	City       *string
+	District   *string
+	State      *string
+	PostalCode *string
+	Country    *string
+	Period     *Period
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Address) ToProto() *datatypespb.Address {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Address{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.Use != nil {
+		p.Use = addressUseCodeToProto(*x.Use)
+	}
+	if x.Type != nil {
+		p.Type = addressTypeCodeToProto(*x.Type)
+	}
+	if x.Text != nil {
+		p.Text = stringToProto(*x.Text)
+	}
+	for _, v := range x.Line {
+		p.Line = append(p.Line, stringToProto(v))
+	}
+	if x.City != nil {
+		p.City = stringToProto(*x.City)
+	}
+	if x.District != nil {
+		p.District = stringToProto(*x.District)
+	}
+	if x.State != nil {
+		p.State = stringToProto(*x.State)
+	}
+	if x.PostalCode != nil {
+		p.PostalCode = stringToProto(*x.PostalCode)
+	}
+	if x.Country != nil {
+		p.Country = stringToProto(*x.Country)
+	}
+	p.Period = x.Period.ToProto()
+	return p
+}
+
+// AddressFromProto converts p to its plain Go form. It returns nil if p is nil.
+func AddressFromProto(p *datatypespb.Address) *Address {
+	if p == nil {
+		return nil
+	}
+	x := &Address{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.Use != nil {
+		s := addressUseCodeFromProto(p.Use)
+		x.Use = &s
+	}
+	if p.Type != nil {
+		s := addressTypeCodeFromProto(p.Type)
+		x.Type = &s
+	}
+	if p.Text != nil {
+		s := stringFromProto(p.Text)
+		x.Text = &s
+	}
+	for _, v := range p.Line {
+		x.Line = append(x.Line, stringFromProto(v))
+	}
+	if p.City != nil {
+		s := stringFromProto(p.City)
+		x.City = &s
+	}
+	if p.District != nil {
+		s := stringFromProto(p.District)
+		x.District = &s
+	}
+	if p.State != nil {
+		s := stringFromProto(p.State)
+		x.State = &s
+	}
+	if p.PostalCode != nil {
+		s := stringFromProto(p.PostalCode)
+		x.PostalCode = &s
+	}
+	if p.Country != nil {
+		s := stringFromProto(p.Country)
+		x.Country = &s
+	}
+	x.Period = PeriodFromProto(p.Period)
+	return x
+}
+
+// CodeableConcept is the plain Go form of google.fhir.r4.core.CodeableConcept.
+type CodeableConcept struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	Coding    []*Coding
+	Text      *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *CodeableConcept) ToProto() *datatypespb.CodeableConcept {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.CodeableConcept{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	for _, v := range x.Coding {
+		p.Coding = append(p.Coding, v.ToProto())
+	}
+	if x.Text != nil {
+		p.Text = stringToProto(*x.Text)
+	}
+	return p
+}
+
+// CodeableConceptFromProto converts p to its plain Go form. It returns nil if p is nil.
+func CodeableConceptFromProto(p *datatypespb.CodeableConcept) *CodeableConcept {
+	if p == nil {
+		return nil
+	}
+	x := &CodeableConcept{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	for _, v := range p.Coding {
+		x.Coding = append(x.Coding, CodingFromProto(v))
+	}
+	if p.Text != nil {
+		s := stringFromProto(p.Text)
+		x.Text = &s
+	}
+	return x
+}
+
+// PatientMultipleBirthX is the plain Go form of google.fhir.r4.core.Patient.MultipleBirthX.
+type PatientMultipleBirthX struct {
+	Boolean *bool
+	Integer *int32
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *PatientMultipleBirthX) ToProto() *patientpb.Patient_MultipleBirthX {
+	if x == nil {
+		return nil
+	}
+	p := &patientpb.Patient_MultipleBirthX{}
+	switch {
+	case x.Boolean != nil:
+		p.Choice = &patientpb.Patient_MultipleBirthX_Boolean{Boolean: booleanToProto(*x.Boolean)}
+	case x.Integer != nil:
+		p.Choice = &patientpb.Patient_MultipleBirthX_Integer{Integer: integerToProto(*x.Integer)}
+	}
+	return p
+}
+
+// PatientMultipleBirthXFromProto converts p to its plain Go form. It returns nil if p is nil.
+func PatientMultipleBirthXFromProto(p *patientpb.Patient_MultipleBirthX) *PatientMultipleBirthX {
+	if p == nil {
+		return nil
+	}
+	x := &PatientMultipleBirthX{}
+	switch v := p.Choice.(type) {
+	case *patientpb.Patient_MultipleBirthX_Boolean:
+		s := booleanFromProto(v.Boolean)
+		x.Boolean = &s
+	case *patientpb.Patient_MultipleBirthX_Integer:
+		s := integerFromProto(v.Integer)
+		x.Integer = &s
+	}
+	return x
+}
+
+// Attachment is the plain Go form of google.fhir.r4.core.Attachment.
+type Attachment struct {
+	Id          *string
+	Extension   []*datatypespb.Extension
+	ContentType *string
+	Language    *string
+	Data        []byte
+	Url         *string
+	Size        *uint32
+	Hash        []byte
+	Title       *string
+	Creation    *Temporal
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Attachment) ToProto() *datatypespb.Attachment {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Attachment{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.ContentType != nil {
+		p.ContentType = attachmentContentTypeCodeToProto(*x.ContentType)
+	}
+	if x.Language != nil {
+		p.Language = codeToProto(*x.Language)
+	}
+	if x.Data != nil {
+		p.Data = base64BinaryToProto(x.Data)
+	}
+	if x.Url != nil {
+		p.Url = urlToProto(*x.Url)
+	}
+	if x.Size != nil {
+		p.Size = unsignedIntToProto(*x.Size)
+	}
+	if x.Hash != nil {
+		p.Hash = base64BinaryToProto(x.Hash)
+	}
+	if x.Title != nil {
+		p.Title = stringToProto(*x.Title)
+	}
+	p.Creation = dateTimeToProto(x.Creation)
+	return p
+}
+
+// AttachmentFromProto converts p to its plain Go form. It returns nil if p is nil.
+func AttachmentFromProto(p *datatypespb.Attachment) *Attachment {
+	if p == nil {
+		return nil
+	}
+	x := &Attachment{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.ContentType != nil {
+		s := attachmentContentTypeCodeFromProto(p.ContentType)
+		x.ContentType = &s
+	}
+	if p.Language != nil {
+		s := codeFromProto(p.Language)
+		x.Language = &s
+	}
+	if p.Data != nil {
+		x.Data = base64BinaryFromProto(p.Data)
+	}
+	if p.Url != nil {
+		s := urlFromProto(p.Url)
+		x.Url = &s
+	}
+	if p.Size != nil {
+		s := unsignedIntFromProto(p.Size)
+		x.Size = &s
+	}
+	if p.Hash != nil {
+		x.Hash = base64BinaryFromProto(p.Hash)
+	}
+	if p.Title != nil {
+		s := stringFromProto(p.Title)
+		x.Title = &s
+	}
+	x.Creation = dateTimeFromProto(p.Creation)
+	return x
+}
+
+// PatientContact is the plain Go form of google.fhir.r4.core.Patient.Contact.
+type PatientContact struct {
+	Id                *string
+	Extension         []*datatypespb.Extension
+	ModifierExtension []*datatypespb.Extension
+	Relationship      []*CodeableConcept
+	Name              *HumanName
+	Telecom           []*ContactPoint
+	Address           *Address
+	Gender            *codespb.AdministrativeGenderCode_Value
+	Organization      *Reference
+	Period            *Period
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *PatientContact) ToProto() *patientpb.Patient_Contact {
+	if x == nil {
+		return nil
+	}
+	p := &patientpb.Patient_Contact{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.ModifierExtension = x.ModifierExtension
+	for _, v := range x.Relationship {
+		p.Relationship = append(p.Relationship, v.ToProto())
+	}
+	p.Name = x.Name.ToProto()
+	for _, v := range x.Telecom {
+		p.Telecom = append(p.Telecom, v.ToProto())
+	}
+	p.Address = x.Address.ToProto()
+	if x.Gender != nil {
+		p.Gender = patientContactGenderCodeToProto(*x.Gender)
+	}
+	p.Organization = x.Organization.ToProto()
+	p.Period = x.Period.ToProto()
+	return p
+}
+
+// PatientContactFromProto converts p to its plain Go form. It returns nil if p is nil.
+func PatientContactFromProto(p *patientpb.Patient_Contact) *PatientContact {
+	if p == nil {
+		return nil
+	}
+	x := &PatientContact{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.ModifierExtension = p.ModifierExtension
+	for _, v := range p.Relationship {
+		x.Relationship = append(x.Relationship, CodeableConceptFromProto(v))
+	}
+	x.Name = HumanNameFromProto(p.Name)
+	for _, v := range p.Telecom {
+		x.Telecom = append(x.Telecom, ContactPointFromProto(v))
+	}
+	x.Address = AddressFromProto(p.Address)
+	if p.Gender != nil {
+		s := patientContactGenderCodeFromProto(p.Gender)
+		x.Gender = &s
+	}
+	x.Organization = ReferenceFromProto(p.Organization)
+	x.Period = PeriodFromProto(p.Period)
+	return x
+}
+
+// PatientCommunication is the plain Go form of google.fhir.r4.core.Patient.Communication.
+type PatientCommunication struct {
+	Id                *string
+	Extension         []*datatypespb.Extension
+	ModifierExtension []*datatypespb.Extension
+	Language          *CodeableConcept
+	Preferred         *bool
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *PatientCommunication) ToProto() *patientpb.Patient_Communication {
+	if x == nil {
+		return nil
+	}
+	p := &patientpb.Patient_Communication{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.ModifierExtension = x.ModifierExtension
+	p.Language = x.Language.ToProto()
+	if x.Preferred != nil {
+		p.Preferred = booleanToProto(*x.Preferred)
+	}
+	return p
+}
+
+// PatientCommunicationFromProto converts p to its plain Go form. It returns nil if p is nil.
+func PatientCommunicationFromProto(p *patientpb.Patient_Communication) *PatientCommunication {
+	if p == nil {
+		return nil
+	}
+	x := &PatientCommunication{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.ModifierExtension = p.ModifierExtension
+	x.Language = CodeableConceptFromProto(p.Language)
+	if p.Preferred != nil {
+		s := booleanFromProto(p.Preferred)
+		x.Preferred = &s
+	}
+	return x
+}
+
+// Reference is the plain Go form of google.fhir.r4.core.Reference.
+type Reference struct {
+	Id         *string
+	Extension  []*datatypespb.Extension
+	Type       *string
+	Identifier *Identifier
+	Display    *string
+	Reference  *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Reference) ToProto() *datatypespb.Reference {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Reference{}
+	if x.Reference != nil {
+		p.Reference = &datatypespb.Reference_Uri{Uri: &datatypespb.String{Value: *x.Reference}}
+		// References that do not name a known resource type stay URIs.
+		_ = jsonformat.NormalizeReference(p)
+	}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.Type != nil {
+		p.Type = uriToProto(*x.Type)
+	}
+	p.Identifier = x.Identifier.ToProto()
+	if x.Display != nil {
+		p.Display = stringToProto(*x.Display)
+	}
+	return p
+}
+
+// ReferenceFromProto converts p to its plain Go form. It returns nil if p is nil.
+func ReferenceFromProto(p *datatypespb.Reference) *Reference {
+	if p == nil {
+		return nil
+	}
+	x := &Reference{}
+	if p.Reference != nil {
+		if r, err := jsonformat.NewDenormalizedReference(p); err == nil {
+			if u := r.(*datatypespb.Reference).GetUri(); u != nil {
+				s := u.GetValue()
+				x.Reference = &s
+			}
+		}
+	}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.Type != nil {
+		s := uriFromProto(p.Type)
+		x.Type = &s
+	}
+	x.Identifier = IdentifierFromProto(p.Identifier)
+	if p.Display != nil {
+		s := stringFromProto(p.Display)
+		x.Display = &s
+	}
+	return x
+}
+
+// PatientLink is the plain Go form of google.fhir.r4.core.Patient.Link.
+type PatientLink struct {
+	Id                *string
+	Extension         []*datatypespb.Extension
+	ModifierExtension []*datatypespb.Extension
+	Other             *Reference
+	Type              *codespb.LinkTypeCode_Value
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *PatientLink) ToProto() *patientpb.Patient_Link {
+	if x == nil {
+		return nil
+	}
+	p := &patientpb.Patient_Link{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.ModifierExtension = x.ModifierExtension
+	p.Other = x.Other.ToProto()
+	if x.Type != nil {
+		p.Type = patientLinkTypeCodeToProto(*x.Type)
+	}
+	return p
+}
+
+// PatientLinkFromProto converts p to its plain Go form. It returns nil if p is nil.
+func PatientLinkFromProto(p *patientpb.Patient_Link) *PatientLink {
+	if p == nil {
+		return nil
+	}
+	x := &PatientLink{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.ModifierExtension = p.ModifierExtension
+	x.Other = ReferenceFromProto(p.Other)
+	if p.Type != nil {
+		s := patientLinkTypeCodeFromProto(p.Type)
+		x.Type = &s
+	}
+	return x
+}
+
+// ObservationEffectiveX is the plain Go form of google.fhir.r4.core.Observation.EffectiveX.
+type ObservationEffectiveX struct {
+	DateTime *Temporal
+	Period   *Period
+	Timing   *Timing
+	Instant  *Temporal
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *ObservationEffectiveX) ToProto() *observationpb.Observation_EffectiveX {
+	if x == nil {
+		return nil
+	}
+	p := &observationpb.Observation_EffectiveX{}
+	switch {
+	case x.DateTime != nil:
+		p.Choice = &observationpb.Observation_EffectiveX_DateTime{DateTime: dateTimeToProto(x.DateTime)}
+	case x.Period != nil:
+		p.Choice = &observationpb.Observation_EffectiveX_Period{Period: x.Period.ToProto()}
+	case x.Timing != nil:
+		p.Choice = &observationpb.Observation_EffectiveX_Timing{Timing: x.Timing.ToProto()}
+	case x.Instant != nil:
+		p.Choice = &observationpb.Observation_EffectiveX_Instant{Instant: instantToProto(x.Instant)}
+	}
+	return p
+}
+
+// ObservationEffectiveXFromProto converts p to its plain Go form. It returns nil if p is nil.
+func ObservationEffectiveXFromProto(p *observationpb.Observation_EffectiveX) *ObservationEffectiveX {
+	if p == nil {
+		return nil
+	}
+	x := &ObservationEffectiveX{}
+	switch v := p.Choice.(type) {
+	case *observationpb.Observation_EffectiveX_DateTime:
+		x.DateTime = dateTimeFromProto(v.DateTime)
+	case *observationpb.Observation_EffectiveX_Period:
+		x.Period = PeriodFromProto(v.Period)
+	case *observationpb.Observation_EffectiveX_Timing:
+		x.Timing = TimingFromProto(v.Timing)
+	case *observationpb.Observation_EffectiveX_Instant:
+		x.Instant = instantFromProto(v.Instant)
+	}
+	return x
+}
+
+// ObservationValueX is the plain Go form of google.fhir.r4.core.Observation.ValueX.
+type ObservationValueX struct {
+	Quantity        *Quantity
+	CodeableConcept *CodeableConcept
+	StringValue     *string
+	Boolean         *bool
+	Integer         *int32
+	Range           *Range
+	Ratio           *Ratio
+	SampledData     *SampledData
+	Time            *Temporal
+	DateTime        *Temporal
+	Period          *Period
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *ObservationValueX) ToProto() *observationpb.Observation_ValueX {
+	if x == nil {
+		return nil
+	}
+	p := &observationpb.Observation_ValueX{}
+	switch {
+	case x.Quantity != nil:
+		p.Choice = &observationpb.Observation_ValueX_Quantity{Quantity: x.Quantity.ToProto()}
+	case x.CodeableConcept != nil:
+		p.Choice = &observationpb.Observation_ValueX_CodeableConcept{CodeableConcept: x.CodeableConcept.ToProto()}
+	case x.StringValue != nil:
+		p.Choice = &observationpb.Observation_ValueX_StringValue{StringValue: stringToProto(*x.StringValue)}
+	case x.Boolean != nil:
+		p.Choice = &observationpb.Observation_ValueX_Boolean{Boolean: booleanToProto(*x.Boolean)}
+	case x.Integer != nil:
+		p.Choice = &observationpb.Observation_ValueX_Integer{Integer: integerToProto(*x.Integer)}
+	case x.Range != nil:
+		p.Choice = &observationpb.Observation_ValueX_Range{Range: x.Range.ToProto()}
+	case x.Ratio != nil:
+		p.Choice = &observationpb.Observation_ValueX_Ratio{Ratio: x.Ratio.ToProto()}
+	case x.SampledData != nil:
+		p.Choice = &observationpb.Observation_ValueX_SampledData{SampledData: x.SampledData.ToProto()}
+	case x.Time != nil:
+		p.Choice = &observationpb.Observation_ValueX_Time{Time: timeToProto(x.Time)}
+	case x.DateTime != nil:
+		p.Choice = &observationpb.Observation_ValueX_DateTime{DateTime: dateTimeToProto(x.DateTime)}
+	case x.Period != nil:
+		p.Choice = &observationpb.Observation_ValueX_Period{Period: x.Period.ToProto()}
+	}
+	return p
+}
+
+// ObservationValueXFromProto converts p to its plain Go form. It returns nil if p is nil.
+func ObservationValueXFromProto(p *observationpb.Observation_ValueX) *ObservationValueX {
+	if p == nil {
+		return nil
+	}
+	x := &ObservationValueX{}
+	switch v := p.Choice.(type) {
+	case *observationpb.Observation_ValueX_Quantity:
+		x.Quantity = QuantityFromProto(v.Quantity)
+	case *observationpb.Observation_ValueX_CodeableConcept:
+		x.CodeableConcept = CodeableConceptFromProto(v.CodeableConcept)
+	case *observationpb.Observation_ValueX_StringValue:
+		s := stringFromProto(v.StringValue)
+		x.StringValue = &s
+	case *observationpb.Observation_ValueX_Boolean:
+		s := booleanFromProto(v.Boolean)
+		x.Boolean = &s
+	case *observationpb.Observation_ValueX_Integer:
+		s := integerFromProto(v.Integer)
+		x.Integer = &s
+	case *observationpb.Observation_ValueX_Range:
+		x.Range = RangeFromProto(v.Range)
+	case *observationpb.Observation_ValueX_Ratio:
+		x.Ratio = RatioFromProto(v.Ratio)
+	case *observationpb.Observation_ValueX_SampledData:
+		x.SampledData = SampledDataFromProto(v.SampledData)
+	case *observationpb.Observation_ValueX_Time:
+		x.Time = timeFromProto(v.Time)
+	case *observationpb.Observation_ValueX_DateTime:
+		x.DateTime = dateTimeFromProto(v.DateTime)
+	case *observationpb.Observation_ValueX_Period:
+		x.Period = PeriodFromProto(v.Period)
+	}
+	return x
+}
+
+// Annotation is the plain Go form of google.fhir.r4.core.Annotation.
+type Annotation struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	Author    *AnnotationAuthorX
+	Time      *Temporal
+	Text      *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Annotation) ToProto() *datatypespb.Annotation {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Annotation{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.Author = x.Author.ToProto()
+	p.Time = dateTimeToProto(x.Time)
+	if x.Text != nil {
+		p.Text = markdownToProto(*x.Text)
+	}
+	return p
+}
+
+// AnnotationFromProto converts p to its plain Go form. It returns nil if p is nil.
+func AnnotationFromProto(p *datatypespb.Annotation) *Annotation {
+	if p == nil {
+		return nil
+	}
+	x := &Annotation{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.Author = AnnotationAuthorXFromProto(p.Author)
+	x.Time = dateTimeFromProto(p.Time)
+	if p.Text != nil {
+		s := markdownFromProto(p.Text)
+		x.Text = &s
+	}
+	return x
+}
+
+// ObservationReferenceRange is the plain Go form of google.fhir.r4.core.Observation.ReferenceRange.
+type ObservationReferenceRange struct {
+	Id                *string
+	Extension         []*datatypespb.Extension
+	ModifierExtension []*datatypespb.Extension
+	Low               *SimpleQuantity
+	High              *SimpleQuantity
+	Type              *CodeableConcept
+	AppliesTo         []*CodeableConcept
+	Age               *Range
+	Text              *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *ObservationReferenceRange) ToProto() *observationpb.Observation_ReferenceRange {
+	if x == nil {
+		return nil
+	}
+	p := &observationpb.Observation_ReferenceRange{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.ModifierExtension = x.ModifierExtension
+	p.Low = x.Low.ToProto()
+	p.High = x.High.ToProto()
+	p.Type = x.Type.ToProto()
+	for _, v := range x.AppliesTo {
+		p.AppliesTo = append(p.AppliesTo, v.ToProto())
+	}
+	p.Age = x.Age.ToProto()
+	if x.Text != nil {
+		p.Text = stringToProto(*x.Text)
+	}
+	return p
+}
+
+// ObservationReferenceRangeFromProto converts p to its plain Go form. It returns nil if p is nil.
+func ObservationReferenceRangeFromProto(p *observationpb.Observation_ReferenceRange) *ObservationReferenceRange {
+	if p == nil {
+		return nil
+	}
+	x := &ObservationReferenceRange{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.ModifierExtension = p.ModifierExtension
+	x.Low = SimpleQuantityFromProto(p.Low)
+	x.High = SimpleQuantityFromProto(p.High)
+	x.Type = CodeableConceptFromProto(p.Type)
+	for _, v := range p.AppliesTo {
+		x.AppliesTo = append(x.AppliesTo, CodeableConceptFromProto(v))
+	}
+	x.Age = RangeFromProto(p.Age)
+	if p.Text != nil {
+		s := stringFromProto(p.Text)
+		x.Text = &s
+	}
+	return x
+}
+
+// ObservationComponent is the plain Go form of google.fhir.r4.core.Observation.Component.
+type ObservationComponent struct {
+	Id                *string
+	Extension         []*datatypespb.Extension
+	ModifierExtension []*datatypespb.Extension
+	Code              *CodeableConcept
+	Value             *ObservationComponentValueX
+	DataAbsentReason  *CodeableConcept
+	Interpretation    []*CodeableConcept
+	ReferenceRange    []*ObservationReferenceRange
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *ObservationComponent) ToProto() *observationpb.Observation_Component {
+	if x == nil {
+		return nil
+	}
+	p := &observationpb.Observation_Component{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.ModifierExtension = x.ModifierExtension
+	p.Code = x.Code.ToProto()
+	p.Value = x.Value.ToProto()
+	p.DataAbsentReason = x.DataAbsentReason.ToProto()
+	for _, v := range x.Interpretation {
+		p.Interpretation = append(p.Interpretation, v.ToProto())
+	}
+	for _, v := range x.ReferenceRange {
+		p.ReferenceRange = append(p.ReferenceRange, v.ToProto())
+	}
+	return p
+}
+
+// ObservationComponentFromProto converts p to its plain Go form. It returns nil if p is nil.
+func ObservationComponentFromProto(p *observationpb.Observation_Component) *ObservationComponent {
+	if p == nil {
+		return nil
+	}
+	x := &ObservationComponent{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.ModifierExtension = p.ModifierExtension
+	x.Code = CodeableConceptFromProto(p.Code)
+	x.Value = ObservationComponentValueXFromProto(p.Value)
+	x.DataAbsentReason = CodeableConceptFromProto(p.DataAbsentReason)
+	for _, v := range p.Interpretation {
+		x.Interpretation = append(x.Interpretation, CodeableConceptFromProto(v))
+	}
+	for _, v := range p.ReferenceRange {
+		x.ReferenceRange = append(x.ReferenceRange, ObservationReferenceRangeFromProto(v))
+	}
+	return x
+}
+
+// Coding is the plain Go form of google.fhir.r4.core.Coding.
+type Coding struct {
+	Id           *string
+	Extension    []*datatypespb.Extension
+	System       *string
+	Version      *string
+	Code         *string
+	Display      *string
+	UserSelected *bool
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Coding) ToProto() *datatypespb.Coding {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Coding{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.System != nil {
+		p.System = uriToProto(*x.System)
+	}
+	if x.Version != nil {
+		p.Version = stringToProto(*x.Version)
+	}
+	if x.Code != nil {
+		p.Code = codeToProto(*x.Code)
+	}
+	if x.Display != nil {
+		p.Display = stringToProto(*x.Display)
+	}
+	if x.UserSelected != nil {
+		p.UserSelected = booleanToProto(*x.UserSelected)
+	}
+	return p
+}
+
+// CodingFromProto converts p to its plain Go form. It returns nil if p is nil.
+func CodingFromProto(p *datatypespb.Coding) *Coding {
+	if p == nil {
+		return nil
+	}
+	x := &Coding{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.System != nil {
+		s := uriFromProto(p.System)
+		x.System = &s
+	}
+	if p.Version != nil {
+		s := stringFromProto(p.Version)
+		x.Version = &s
+	}
+	if p.Code != nil {
+		s := codeFromProto(p.Code)
+		x.Code = &s
+	}
+	if p.Display != nil {
+		s := stringFromProto(p.Display)
+		x.Display = &s
+	}
+	if p.UserSelected != nil {
+		s := booleanFromProto(p.UserSelected)
+		x.UserSelected = &s
+	}
+	return x
+}
+
+// Period is the plain Go form of google.fhir.r4.core.Period.
+type Period struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	Start     *Temporal
+	End       *Temporal
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Period) ToProto() *datatypespb.Period {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Period{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.Start = dateTimeToProto(x.Start)
+	p.End = dateTimeToProto(x.End)
+	return p
+}
+
+// PeriodFromProto converts p to its plain Go form. It returns nil if p is nil.
+func PeriodFromProto(p *datatypespb.Period) *Period {
+	if p == nil {
+		return nil
+	}
+	x := &Period{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.Start = dateTimeFromProto(p.Start)
+	x.End = dateTimeFromProto(p.End)
+	return x
+}
+
+// Timing is the plain Go form of google.fhir.r4.core.Timing.
+type Timing struct {
+	Id                *string
+	Extension         []*datatypespb.Extension
+	ModifierExtension []*datatypespb.Extension
+	Event             []*Temporal
+	Repeat            *TimingRepeat
+	Code              *CodeableConcept
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Timing) ToProto() *datatypespb.Timing {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Timing{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.ModifierExtension = x.ModifierExtension
+	for _, v := range x.Event {
+		p.Event = append(p.Event, dateTimeToProto(v))
+	}
+	p.Repeat = x.Repeat.ToProto()
+	p.Code = x.Code.ToProto()
+	return p
+}
+
+// TimingFromProto converts p to its plain Go form. It returns nil if p is nil.
+func TimingFromProto(p *datatypespb.Timing) *Timing {
+	if p == nil {
+		return nil
+	}
+	x := &Timing{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.ModifierExtension = p.ModifierExtension
+	for _, v := range p.Event {
+		x.Event = append(x.Event, dateTimeFromProto(v))
+	}
+	x.Repeat = TimingRepeatFromProto(p.Repeat)
+	x.Code = CodeableConceptFromProto(p.Code)
+	return x
+}
+
+// Quantity is the plain Go form of google.fhir.r4.core.Quantity.
+type Quantity struct {
+	Id         *string
+	Extension  []*datatypespb.Extension
+	Value      *string
+	Comparator *codespb.QuantityComparatorCode_Value
+	Unit       *string
+	System     *string
+	Code       *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Quantity) ToProto() *datatypespb.Quantity {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Quantity{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.Value != nil {
+		p.Value = decimalToProto(*x.Value)
+	}
+	if x.Comparator != nil {
+		p.Comparator = quantityComparatorCodeToProto(*x.Comparator)
+	}
+	if x.Unit != nil {
+		p.Unit = stringToProto(*x.Unit)
+	}
+	if x.System != nil {
+		p.System = uriToProto(*x.System)
+	}
+	if x.Code != nil {
+		p.Code = codeToProto(*x.Code)
+	}
+	return p
+}
+
+// QuantityFromProto converts p to its plain Go form. It returns nil if p is nil.
+func QuantityFromProto(p *datatypespb.Quantity) *Quantity {
+	if p == nil {
+		return nil
+	}
+	x := &Quantity{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.Value != nil {
+		s := decimalFromProto(p.Value)
+		x.Value = &s
+	}
+	if p.Comparator != nil {
+		s := quantityComparatorCodeFromProto(p.Comparator)
+		x.Comparator = &s
+	}
+	if p.Unit != nil {
+		s := stringFromProto(p.Unit)
+		x.Unit = &s
+	}
+	if p.System != nil {
+		s := uriFromProto(p.System)
+		x.System = &s
+	}
+	if p.Code != nil {
+		s := codeFromProto(p.Code)
+		x.Code = &s
+	}
+	return x
+}
+
+// Range is the plain Go form of google.fhir.r4.core.Range.
+type Range struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	Low       *SimpleQuantity
+	High      *SimpleQuantity
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Range) ToProto() *datatypespb.Range {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Range{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.Low = x.Low.ToProto()
+	p.High = x.High.ToProto()
+	return p
+}
+
+// RangeFromProto converts p to its plain Go form. It returns nil if p is nil.
+func RangeFromProto(p *datatypespb.Range) *Range {
+	if p == nil {
+		return nil
+	}
+	x := &Range{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.Low = SimpleQuantityFromProto(p.Low)
+	x.High = SimpleQuantityFromProto(p.High)
+	return x
+}
+
+// Ratio is the plain Go form of google.fhir.r4.core.Ratio.
+type Ratio struct {
+	Id          *string
+	Extension   []*datatypespb.Extension
+	Numerator   *Quantity
+	Denominator *Quantity
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Ratio) ToProto() *datatypespb.Ratio {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Ratio{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.Numerator = x.Numerator.ToProto()
+	p.Denominator = x.Denominator.ToProto()
+	return p
+}
+
+// RatioFromProto converts p to its plain Go form. It returns nil if p is nil.
+func RatioFromProto(p *datatypespb.Ratio) *Ratio {
+	if p == nil {
+		return nil
+	}
+	x := &Ratio{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.Numerator = QuantityFromProto(p.Numerator)
+	x.Denominator = QuantityFromProto(p.Denominator)
+	return x
+}
+
+// SampledData is the plain Go form of google.fhir.r4.core.SampledData.
+type SampledData struct {
+	Id         *string
+	Extension  []*datatypespb.Extension
+	Origin     *SimpleQuantity
+	Period     *string
+	Factor     *string
+	LowerLimit *string
+	UpperLimit *string
+	Dimensions *uint32
+	Data       *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *SampledData) ToProto() *datatypespb.SampledData {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.SampledData{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.Origin = x.Origin.ToProto()
+	if x.Period != nil {
+		p.Period = decimalToProto(*x.Period)
+	}
+	if x.Factor != nil {
+		p.Factor = decimalToProto(*x.Factor)
+	}
+	if x.LowerLimit != nil {
+		p.LowerLimit = decimalToProto(*x.LowerLimit)
+	}
+	if x.UpperLimit != nil {
+		p.UpperLimit = decimalToProto(*x.UpperLimit)
+	}
+	if x.Dimensions != nil {
+		p.Dimensions = positiveIntToProto(*x.Dimensions)
+	}
+	if x.Data != nil {
+		p.Data = stringToProto(*x.Data)
+	}
+	return p
+}
+
+// SampledDataFromProto converts p to its plain Go form. It returns nil if p is nil.
+func SampledDataFromProto(p *datatypespb.SampledData) *SampledData {
+	if p == nil {
+		return nil
+	}
+	x := &SampledData{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.Origin = SimpleQuantityFromProto(p.Origin)
+	if p.Period != nil {
+		s := decimalFromProto(p.Period)
+		x.Period = &s
+	}
+	if p.Factor != nil {
+		s := decimalFromProto(p.Factor)
+		x.Factor = &s
+	}
+	if p.LowerLimit != nil {
+		s := decimalFromProto(p.LowerLimit)
+		x.LowerLimit = &s
+	}
+	if p.UpperLimit != nil {
+		s := decimalFromProto(p.UpperLimit)
+		x.UpperLimit = &s
+	}
+	if p.Dimensions != nil {
+		s := positiveIntFromProto(p.Dimensions)
+		x.Dimensions = &s
+	}
+	if p.Data != nil {
+		s := stringFromProto(p.Data)
+		x.Data = &s
+	}
+	return x
+}
+
+// AnnotationAuthorX is the plain Go form of google.fhir.r4.core.Annotation.AuthorX.
+type AnnotationAuthorX struct {
+	Reference   *Reference
+	StringValue *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *AnnotationAuthorX) ToProto() *datatypespb.Annotation_AuthorX {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Annotation_AuthorX{}
+	switch {
+	case x.Reference != nil:
+		p.Choice = &datatypespb.Annotation_AuthorX_Reference{Reference: x.Reference.ToProto()}
+	case x.StringValue != nil:
+		p.Choice = &datatypespb.Annotation_AuthorX_StringValue{StringValue: stringToProto(*x.StringValue)}
+	}
+	return p
+}
+
+// AnnotationAuthorXFromProto converts p to its plain Go form. It returns nil if p is nil.
+func AnnotationAuthorXFromProto(p *datatypespb.Annotation_AuthorX) *AnnotationAuthorX {
+	if p == nil {
+		return nil
+	}
+	x := &AnnotationAuthorX{}
+	switch v := p.Choice.(type) {
+	case *datatypespb.Annotation_AuthorX_Reference:
+		x.Reference = ReferenceFromProto(v.Reference)
+	case *datatypespb.Annotation_AuthorX_StringValue:
+		s := stringFromProto(v.StringValue)
+		x.StringValue = &s
+	}
+	return x
+}
+
+// SimpleQuantity is the plain Go form of google.fhir.r4.core.SimpleQuantity.
+type SimpleQuantity struct {
+	Id        *string
+	Extension []*datatypespb.Extension
+	Value     *string
+	Unit      *string
+	System    *string
+	Code      *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *SimpleQuantity) ToProto() *datatypespb.SimpleQuantity {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.SimpleQuantity{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.Value != nil {
+		p.Value = decimalToProto(*x.Value)
+	}
+	if x.Unit != nil {
+		p.Unit = stringToProto(*x.Unit)
+	}
+	if x.System != nil {
+		p.System = uriToProto(*x.System)
+	}
+	if x.Code != nil {
+		p.Code = codeToProto(*x.Code)
+	}
+	return p
+}
+
+// SimpleQuantityFromProto converts p to its plain Go form. It returns nil if p is nil.
+func SimpleQuantityFromProto(p *datatypespb.SimpleQuantity) *SimpleQuantity {
+	if p == nil {
+		return nil
+	}
+	x := &SimpleQuantity{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.Value != nil {
+		s := decimalFromProto(p.Value)
+		x.Value = &s
+	}
+	if p.Unit != nil {
+		s := stringFromProto(p.Unit)
+		x.Unit = &s
+	}
+	if p.System != nil {
+		s := uriFromProto(p.System)
+		x.System = &s
+	}
+	if p.Code != nil {
+		s := codeFromProto(p.Code)
+		x.Code = &s
+	}
+	return x
+}
+
+// ObservationComponentValueX is the plain Go form of google.fhir.r4.core.Observation.Component.ValueX.
+type ObservationComponentValueX struct {
+	Quantity        *Quantity
+	CodeableConcept *CodeableConcept
+	StringValue     *string
+	Boolean         *bool
+	Integer         *int32
+	Range           *Range
+	Ratio           *Ratio
+	SampledData     *SampledData
+	Time            *Temporal
+	DateTime        *Temporal
+	Period          *Period
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *ObservationComponentValueX) ToProto() *observationpb.Observation_Component_ValueX {
+	if x == nil {
+		return nil
+	}
+	p := &observationpb.Observation_Component_ValueX{}
+	switch {
+	case x.Quantity != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_Quantity{Quantity: x.Quantity.ToProto()}
+	case x.CodeableConcept != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_CodeableConcept{CodeableConcept: x.CodeableConcept.ToProto()}
+	case x.StringValue != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_StringValue{StringValue: stringToProto(*x.StringValue)}
+	case x.Boolean != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_Boolean{Boolean: booleanToProto(*x.Boolean)}
+	case x.Integer != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_Integer{Integer: integerToProto(*x.Integer)}
+	case x.Range != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_Range{Range: x.Range.ToProto()}
+	case x.Ratio != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_Ratio{Ratio: x.Ratio.ToProto()}
+	case x.SampledData != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_SampledData{SampledData: x.SampledData.ToProto()}
+	case x.Time != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_Time{Time: timeToProto(x.Time)}
+	case x.DateTime != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_DateTime{DateTime: dateTimeToProto(x.DateTime)}
+	case x.Period != nil:
+		p.Choice = &observationpb.Observation_Component_ValueX_Period{Period: x.Period.ToProto()}
+	}
+	return p
+}
+
+// ObservationComponentValueXFromProto converts p to its plain Go form. It returns nil if p is nil.
+func ObservationComponentValueXFromProto(p *observationpb.Observation_Component_ValueX) *ObservationComponentValueX {
+	if p == nil {
+		return nil
+	}
+	x := &ObservationComponentValueX{}
+	switch v := p.Choice.(type) {
+	case *observationpb.Observation_Component_ValueX_Quantity:
+		x.Quantity = QuantityFromProto(v.Quantity)
+	case *observationpb.Observation_Component_ValueX_CodeableConcept:
+		x.CodeableConcept = CodeableConceptFromProto(v.CodeableConcept)
+	case *observationpb.Observation_Component_ValueX_StringValue:
+		s := stringFromProto(v.StringValue)
+		x.StringValue = &s
+	case *observationpb.Observation_Component_ValueX_Boolean:
+		s := booleanFromProto(v.Boolean)
+		x.Boolean = &s
+	case *observationpb.Observation_Component_ValueX_Integer:
+		s := integerFromProto(v.Integer)
+		x.Integer = &s
+	case *observationpb.Observation_Component_ValueX_Range:
+		x.Range = RangeFromProto(v.Range)
+	case *observationpb.Observation_Component_ValueX_Ratio:
+		x.Ratio = RatioFromProto(v.Ratio)
+	case *observationpb.Observation_Component_ValueX_SampledData:
+		x.SampledData = SampledDataFromProto(v.SampledData)
+	case *observationpb.Observation_Component_ValueX_Time:
+		x.Time = timeFromProto(v.Time)
+	case *observationpb.Observation_Component_ValueX_DateTime:
+		x.DateTime = dateTimeFromProto(v.DateTime)
+	case *observationpb.Observation_Component_ValueX_Period:
+		x.Period = PeriodFromProto(v.Period)
+	}
+	return x
+}
+
+// TimingRepeat is the plain Go form of google.fhir.r4.core.Timing.Repeat.
+type TimingRepeat struct {
+	Id           *string
+	Extension    []*datatypespb.Extension
+	Bounds       *TimingRepeatBoundsX
+	Count        *uint32
+	CountMax     *uint32
+	Duration     *string
+	DurationMax  *string
+	DurationUnit *valuesetspb.UnitsOfTimeValueSet_Value
+	Frequency    *uint32
+	FrequencyMax *uint32
+	Period       *string
+	PeriodMax    *string
+	PeriodUnit   *valuesetspb.UnitsOfTimeValueSet_Value
+	DayOfWeek    []codespb.DaysOfWeekCode_Value
+	TimeOfDay    []*Temporal
+	When         []valuesetspb.EventTimingValueSet_Value
+	Offset       *uint32
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *TimingRepeat) ToProto() *datatypespb.Timing_Repeat {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Timing_Repeat{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	p.Bounds = x.Bounds.ToProto()
+	if x.Count != nil {
+		p.Count = positiveIntToProto(*x.Count)
+	}
+	if x.CountMax != nil {
+		p.CountMax = positiveIntToProto(*x.CountMax)
+	}
+	if x.Duration != nil {
+		p.Duration = decimalToProto(*x.Duration)
+	}
+	if x.DurationMax != nil {
+		p.DurationMax = decimalToProto(*x.DurationMax)
+	}
+	if x.DurationUnit != nil {
+		p.DurationUnit = timingRepeatDurationUnitCodeToProto(*x.DurationUnit)
+	}
+	if x.Frequency != nil {
+		p.Frequency = positiveIntToProto(*x.Frequency)
+	}
+	if x.FrequencyMax != nil {
+		p.FrequencyMax = positiveIntToProto(*x.FrequencyMax)
+	}
+	if x.Period != nil {
+		p.Period = decimalToProto(*x.Period)
+	}
+	if x.PeriodMax != nil {
+		p.PeriodMax = decimalToProto(*x.PeriodMax)
+	}
+	if x.PeriodUnit != nil {
+		p.PeriodUnit = timingRepeatPeriodUnitCodeToProto(*x.PeriodUnit)
+	}
+	for _, v := range x.DayOfWeek {
+		p.DayOfWeek = append(p.DayOfWeek, timingRepeatDayOfWeekCodeToProto(v))
+	}
+	for _, v := range x.TimeOfDay {
+		p.TimeOfDay = append(p.TimeOfDay, timeToProto(v))
+	}
+	for _, v := range x.When {
+		p.When = append(p.When, timingRepeatWhenCodeToProto(v))
+	}
+	if x.Offset != nil {
+		p.Offset = unsignedIntToProto(*x.Offset)
+	}
+	return p
+}
+
+// TimingRepeatFromProto converts p to its plain Go form. It returns nil if p is nil.
+func TimingRepeatFromProto(p *datatypespb.Timing_Repeat) *TimingRepeat {
+	if p == nil {
+		return nil
+	}
+	x := &TimingRepeat{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	x.Bounds = TimingRepeatBoundsXFromProto(p.Bounds)
+	if p.Count != nil {
+		s := positiveIntFromProto(p.Count)
+		x.Count = &s
+	}
+	if p.CountMax != nil {
+		s := positiveIntFromProto(p.CountMax)
+		x.CountMax = &s
+	}
+	if p.Duration != nil {
+		s := decimalFromProto(p.Duration)
+		x.Duration = &s
+	}
+	if p.DurationMax != nil {
+		s := decimalFromProto(p.DurationMax)
+		x.DurationMax = &s
+	}
+	if p.DurationUnit != nil {
+		s := timingRepeatDurationUnitCodeFromProto(p.DurationUnit)
+		x.DurationUnit = &s
+	}
+	if p.Frequency != nil {
+		s := positiveIntFromProto(p.Frequency)
+		x.Frequency = &s
+	}
+	if p.FrequencyMax != nil {
+		s := positiveIntFromProto(p.FrequencyMax)
+		x.FrequencyMax = &s
+	}
+	if p.Period != nil {
+		s := decimalFromProto(p.Period)
+		x.Period = &s
+	}
+	if p.PeriodMax != nil {
+		s := decimalFromProto(p.PeriodMax)
+		x.PeriodMax = &s
+	}
+	if p.PeriodUnit != nil {
+		s := timingRepeatPeriodUnitCodeFromProto(p.PeriodUnit)
+		x.PeriodUnit = &s
+	}
+	for _, v := range p.DayOfWeek {
+		x.DayOfWeek = append(x.DayOfWeek, timingRepeatDayOfWeekCodeFromProto(v))
+	}
+	for _, v := range p.TimeOfDay {
+		x.TimeOfDay = append(x.TimeOfDay, timeFromProto(v))
+	}
+	for _, v := range p.When {
+		x.When = append(x.When, timingRepeatWhenCodeFromProto(v))
+	}
+	if p.Offset != nil {
+		s := unsignedIntFromProto(p.Offset)
+		x.Offset = &s
+	}
+	return x
+}
+
+// TimingRepeatBoundsX is the plain Go form of google.fhir.r4.core.Timing.Repeat.BoundsX.
+type TimingRepeatBoundsX struct {
+	Duration *Duration
+	Range    *Range
+	Period   *Period
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *TimingRepeatBoundsX) ToProto() *datatypespb.Timing_Repeat_BoundsX {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Timing_Repeat_BoundsX{}
+	switch {
+	case x.Duration != nil:
+		p.Choice = &datatypespb.Timing_Repeat_BoundsX_Duration{Duration: x.Duration.ToProto()}
+	case x.Range != nil:
+		p.Choice = &datatypespb.Timing_Repeat_BoundsX_Range{Range: x.Range.ToProto()}
+	case x.Period != nil:
+		p.Choice = &datatypespb.Timing_Repeat_BoundsX_Period{Period: x.Period.ToProto()}
+	}
+	return p
+}
+
+// TimingRepeatBoundsXFromProto converts p to its plain Go form. It returns nil if p is nil.
+func TimingRepeatBoundsXFromProto(p *datatypespb.Timing_Repeat_BoundsX) *TimingRepeatBoundsX {
+	if p == nil {
+		return nil
+	}
+	x := &TimingRepeatBoundsX{}
+	switch v := p.Choice.(type) {
+	case *datatypespb.Timing_Repeat_BoundsX_Duration:
+		x.Duration = DurationFromProto(v.Duration)
+	case *datatypespb.Timing_Repeat_BoundsX_Range:
+		x.Range = RangeFromProto(v.Range)
+	case *datatypespb.Timing_Repeat_BoundsX_Period:
+		x.Period = PeriodFromProto(v.Period)
+	}
+	return x
+}
+
+// Duration is the plain Go form of google.fhir.r4.core.Duration.
+type Duration struct {
+	Id         *string
+	Extension  []*datatypespb.Extension
+	Value      *string
+	Comparator *codespb.QuantityComparatorCode_Value
+	Unit       *string
+	System     *string
+	Code       *string
+}
+
+// ToProto converts x to its proto form. It returns nil if x is nil.
+func (x *Duration) ToProto() *datatypespb.Duration {
+	if x == nil {
+		return nil
+	}
+	p := &datatypespb.Duration{}
+	if x.Id != nil {
+		p.Id = stringToProto(*x.Id)
+	}
+	p.Extension = x.Extension
+	if x.Value != nil {
+		p.Value = decimalToProto(*x.Value)
+	}
+	if x.Comparator != nil {
+		p.Comparator = durationComparatorCodeToProto(*x.Comparator)
+	}
+	if x.Unit != nil {
+		p.Unit = stringToProto(*x.Unit)
+	}
+	if x.System != nil {
+		p.System = uriToProto(*x.System)
+	}
+	if x.Code != nil {
+		p.Code = codeToProto(*x.Code)
+	}
+	return p
+}
+
+// DurationFromProto converts p to its plain Go form. It returns nil if p is nil.
+func DurationFromProto(p *datatypespb.Duration) *Duration {
+	if p == nil {
+		return nil
+	}
+	x := &Duration{}
+	if p.Id != nil {
+		s := stringFromProto(p.Id)
+		x.Id = &s
+	}
+	x.Extension = p.Extension
+	if p.Value != nil {
+		s := decimalFromProto(p.Value)
+		x.Value = &s
+	}
+	if p.Comparator != nil {
+		s := durationComparatorCodeFromProto(p.Comparator)
+		x.Comparator = &s
+	}
+	if p.Unit != nil {
+		s := stringFromProto(p.Unit)
+		x.Unit = &s
+	}
+	if p.System != nil {
+		s := uriFromProto(p.System)
+		x.System = &s
+	}
+	if p.Code != nil {
+		s := codeFromProto(p.Code)
+		x.Code = &s
+	}
+	return x
+}
+
+func idToProto(v string) *datatypespb.Id {
+	return &datatypespb.Id{Value: v}
+}
+
+func idFromProto(p *datatypespb.Id) string {
+	return p.GetValue()
+}
+
+func uriToProto(v string) *datatypespb.Uri {
+	return &datatypespb.Uri{Value: v}
+}
+
+func uriFromProto(p *datatypespb.Uri) string {
+	return p.GetValue()
+}
+
+func codeToProto(v string) *datatypespb.Code {
+	return &datatypespb.Code{Value: v}
+}
+
+func codeFromProto(p *datatypespb.Code) string {
+	return p.GetValue()
+}
+
+func booleanToProto(v bool) *datatypespb.Boolean {
+	return &datatypespb.Boolean{Value: v}
+}
+
+func booleanFromProto(p *datatypespb.Boolean) bool {
+	return p.GetValue()
+}
+
+func patientGenderCodeToProto(v codespb.AdministrativeGenderCode_Value) *patientpb.Patient_GenderCode {
+	return &patientpb.Patient_GenderCode{Value: v}
+}
+
+func patientGenderCodeFromProto(p *patientpb.Patient_GenderCode) codespb.AdministrativeGenderCode_Value {
+	return p.GetValue()
+}
+
+func observationStatusCodeToProto(v codespb.ObservationStatusCode_Value) *observationpb.Observation_StatusCode {
+	return &observationpb.Observation_StatusCode{Value: v}
+}
+
+func observationStatusCodeFromProto(p *observationpb.Observation_StatusCode) codespb.ObservationStatusCode_Value {
+	return p.GetValue()
+}
+
+func stringToProto(v string) *datatypespb.String {
+	return &datatypespb.String{Value: v}
+}
+
+func stringFromProto(p *datatypespb.String) string {
+	return p.GetValue()
+}
+
+func canonicalToProto(v string) *datatypespb.Canonical {
+	return &datatypespb.Canonical{Value: v}
+}
+
+func canonicalFromProto(p *datatypespb.Canonical) string {
+	return p.GetValue()
+}
+
+func narrativeStatusCodeToProto(v codespb.NarrativeStatusCode_Value) *datatypespb.Narrative_StatusCode {
+	return &datatypespb.Narrative_StatusCode{Value: v}
+}
+
+func narrativeStatusCodeFromProto(p *datatypespb.Narrative_StatusCode) codespb.NarrativeStatusCode_Value {
+	return p.GetValue()
+}
+
+func xhtmlToProto(v string) *datatypespb.Xhtml {
+	return &datatypespb.Xhtml{Value: v}
+}
+
+func xhtmlFromProto(p *datatypespb.Xhtml) string {
+	return p.GetValue()
+}
+
+func identifierUseCodeToProto(v codespb.IdentifierUseCode_Value) *datatypespb.Identifier_UseCode {
+	return &datatypespb.Identifier_UseCode{Value: v}
+}
+
+func identifierUseCodeFromProto(p *datatypespb.Identifier_UseCode) codespb.IdentifierUseCode_Value {
+	return p.GetValue()
+}
+
+func humanNameUseCodeToProto(v codespb.NameUseCode_Value) *datatypespb.HumanName_UseCode {
+	return &datatypespb.HumanName_UseCode{Value: v}
+}
+
+func humanNameUseCodeFromProto(p *datatypespb.HumanName_UseCode) codespb.NameUseCode_Value {
+	return p.GetValue()
+}
+
+func contactPointSystemCodeToProto(v codespb.ContactPointSystemCode_Value) *datatypespb.ContactPoint_SystemCode {
+	return &datatypespb.ContactPoint_SystemCode{Value: v}
+}
+
+func contactPointSystemCodeFromProto(p *datatypespb.ContactPoint_SystemCode) codespb.ContactPointSystemCode_Value {
+	return p.GetValue()
+}
+
+func contactPointUseCodeToProto(v codespb.ContactPointUseCode_Value) *datatypespb.ContactPoint_UseCode {
+	return &datatypespb.ContactPoint_UseCode{Value: v}
+}
+
+func contactPointUseCodeFromProto(p *datatypespb.ContactPoint_UseCode) codespb.ContactPointUseCode_Value {
+	return p.GetValue()
+}
+
+func positiveIntToProto(v uint32) *datatypespb.PositiveInt {
+	return &datatypespb.PositiveInt{Value: v}
+}
+
+func positiveIntFromProto(p *datatypespb.PositiveInt) uint32 {
+	return p.GetValue()
+}
+
+func addressUseCodeToProto(v codespb.AddressUseCode_Value) *datatypespb.Address_UseCode {
+	return &datatypespb.Address_UseCode{Value: v}
+}
+
+func addressUseCodeFromProto(p *datatypespb.Address_UseCode) codespb.AddressUseCode_Value {
+	return p.GetValue()
+}
+
+func addressTypeCodeToProto(v codespb.AddressTypeCode_Value) *datatypespb.Address_TypeCode {
+	return &datatypespb.Address_TypeCode{Value: v}
+}
+
+func addressTypeCodeFromProto(p *datatypespb.Address_TypeCode) codespb.AddressTypeCode_Value {
+	return p.GetValue()
+}
+
+func integerToProto(v int32) *datatypespb.Integer {
+	return &datatypespb.Integer{Value: v}
+}
+
+func integerFromProto(p *datatypespb.Integer) int32 {
+	return p.GetValue()
+}
+
+func attachmentContentTypeCodeToProto(v string) *datatypespb.Attachment_ContentTypeCode {
+	return &datatypespb.Attachment_ContentTypeCode{Value: v}
+}
+
+func attachmentContentTypeCodeFromProto(p *datatypespb.Attachment_ContentTypeCode) string {
+	return p.GetValue()
+}
+
+func base64BinaryToProto(v []byte) *datatypespb.Base64Binary {
+	return &datatypespb.Base64Binary{Value: v}
+}
+
+func base64BinaryFromProto(p *datatypespb.Base64Binary) []byte {
+	return p.GetValue()
+}
+
+func urlToProto(v string) *datatypespb.Url {
+	return &datatypespb.Url{Value: v}
+}
+
+func urlFromProto(p *datatypespb.Url) string {
+	return p.GetValue()
+}
+
+func unsignedIntToProto(v uint32) *datatypespb.UnsignedInt {
+	return &datatypespb.UnsignedInt{Value: v}
+}
+
+func unsignedIntFromProto(p *datatypespb.UnsignedInt) uint32 {
+	return p.GetValue()
+}
+
+func patientContactGenderCodeToProto(v codespb.AdministrativeGenderCode_Value) *patientpb.Patient_Contact_GenderCode {
+	return &patientpb.Patient_Contact_GenderCode{Value: v}
+}
+
+func patientContactGenderCodeFromProto(p *patientpb.Patient_Contact_GenderCode) codespb.AdministrativeGenderCode_Value {
+	return p.GetValue()
+}
+
+func patientLinkTypeCodeToProto(v codespb.LinkTypeCode_Value) *patientpb.Patient_Link_TypeCode {
+	return &patientpb.Patient_Link_TypeCode{Value: v}
+}
+
+func patientLinkTypeCodeFromProto(p *patientpb.Patient_Link_TypeCode) codespb.LinkTypeCode_Value {
+	return p.GetValue()
+}
+
+func markdownToProto(v string) *datatypespb.Markdown {
+	return &datatypespb.Markdown{Value: v}
+}
+
+func markdownFromProto(p *datatypespb.Markdown) string {
+	return p.GetValue()
+}
+
+func decimalToProto(v string) *datatypespb.Decimal {
+	return &datatypespb.Decimal{Value: v}
+}
+
+func decimalFromProto(p *datatypespb.Decimal) string {
+	return p.GetValue()
+}
+
+func quantityComparatorCodeToProto(v codespb.QuantityComparatorCode_Value) *datatypespb.Quantity_ComparatorCode {
+	return &datatypespb.Quantity_ComparatorCode{Value: v}
+}
+
+func quantityComparatorCodeFromProto(p *datatypespb.Quantity_ComparatorCode) codespb.QuantityComparatorCode_Value {
+	return p.GetValue()
+}
+
+func timingRepeatDurationUnitCodeToProto(v valuesetspb.UnitsOfTimeValueSet_Value) *datatypespb.Timing_Repeat_DurationUnitCode {
+	return &datatypespb.Timing_Repeat_DurationUnitCode{Value: v}
+}
+
+func timingRepeatDurationUnitCodeFromProto(p *datatypespb.Timing_Repeat_DurationUnitCode) valuesetspb.UnitsOfTimeValueSet_Value {
+	return p.GetValue()
+}
+
+func timingRepeatPeriodUnitCodeToProto(v valuesetspb.UnitsOfTimeValueSet_Value) *datatypespb.Timing_Repeat_PeriodUnitCode {
+	return &datatypespb.Timing_Repeat_PeriodUnitCode{Value: v}
+}
+
+func timingRepeatPeriodUnitCodeFromProto(p *datatypespb.Timing_Repeat_PeriodUnitCode) valuesetspb.UnitsOfTimeValueSet_Value {
+	return p.GetValue()
+}
+
+func timingRepeatDayOfWeekCodeToProto(v codespb.DaysOfWeekCode_Value) *datatypespb.Timing_Repeat_DayOfWeekCode {
+	return &datatypespb.Timing_Repeat_DayOfWeekCode{Value: v}
+}
+
+func timingRepeatDayOfWeekCodeFromProto(p *datatypespb.Timing_Repeat_DayOfWeekCode) codespb.DaysOfWeekCode_Value {
+	return p.GetValue()
+}
+
+func timingRepeatWhenCodeToProto(v valuesetspb.EventTimingValueSet_Value) *datatypespb.Timing_Repeat_WhenCode {
+	return &datatypespb.Timing_Repeat_WhenCode{Value: v}
+}
+
+func timingRepeatWhenCodeFromProto(p *datatypespb.Timing_Repeat_WhenCode) valuesetspb.EventTimingValueSet_Value {
+	return p.GetValue()
+}
+
+func durationComparatorCodeToProto(v codespb.QuantityComparatorCode_Value) *datatypespb.Duration_ComparatorCode {
+	return &datatypespb.Duration_ComparatorCode{Value: v}
+}
+
+func durationComparatorCodeFromProto(p *datatypespb.Duration_ComparatorCode) codespb.QuantityComparatorCode_Value {
+	return p.GetValue()
+}
+
+func dateToProto(t *Temporal) *datatypespb.Date {
+	if t == nil {
+		return nil
+	}
+	return &datatypespb.Date{
+		ValueUs:   t.ValueUs,
+		Timezone:  t.Timezone,
+		Precision: datatypespb.Date_Precision(datatypespb.Date_Precision_value[t.Precision]),
+	}
+}
+
+func dateFromProto(p *datatypespb.Date) *Temporal {
+	if p == nil {
+		return nil
+	}
+	return &Temporal{
+		ValueUs:   p.GetValueUs(),
+		Timezone:  p.GetTimezone(),
+		Precision: p.GetPrecision().String(),
+	}
+}
+
+func instantToProto(t *Temporal) *datatypespb.Instant {
+	if t == nil {
+		return nil
+	}
+	return &datatypespb.Instant{
+		ValueUs:   t.ValueUs,
+		Timezone:  t.Timezone,
+		Precision: datatypespb.Instant_Precision(datatypespb.Instant_Precision_value[t.Precision]),
+	}
+}
+
+func instantFromProto(p *datatypespb.Instant) *Temporal {
+	if p == nil {
+		return nil
+	}
+	return &Temporal{
+		ValueUs:   p.GetValueUs(),
+		Timezone:  p.GetTimezone(),
+		Precision: p.GetPrecision().String(),
+	}
+}
+
+func dateTimeToProto(t *Temporal) *datatypespb.DateTime {
+	if t == nil {
+		return nil
+	}
+	return &datatypespb.DateTime{
+		ValueUs:   t.ValueUs,
+		Timezone:  t.Timezone,
+		Precision: datatypespb.DateTime_Precision(datatypespb.DateTime_Precision_value[t.Precision]),
+	}
+}
+
+func dateTimeFromProto(p *datatypespb.DateTime) *Temporal {
+	if p == nil {
+		return nil
+	}
+	return &Temporal{
+		ValueUs:   p.GetValueUs(),
+		Timezone:  p.GetTimezone(),
+		Precision: p.GetPrecision().String(),
+	}
+}
+
+func timeToProto(t *Temporal) *datatypespb.Time {
+	if t == nil {
+		return nil
+	}
+	return &datatypespb.Time{
+		ValueUs:   t.ValueUs,
+		Precision: datatypespb.Time_Precision(datatypespb.Time_Precision_value[t.Precision]),
+	}
+}
+
+func timeFromProto(p *datatypespb.Time) *Temporal {
+	if p == nil {
+		return nil
+	}
+	return &Temporal{
+		ValueUs:   p.GetValueUs(),
+		Precision: p.GetPrecision().String(),
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package r4structs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func testPatient() *ppb.Patient {
+	return &ppb.Patient{
+		Id: &d4pb.Id{Value: "p1"},
+		Extension: []*d4pb.Extension{{
+			Url:   &d4pb.Uri{Value: "http://example.com/ext"},
+			Value: &d4pb.Extension_ValueX{Choice: &d4pb.Extension_ValueX_StringValue{StringValue: &d4pb.String{Value: "x"}}},
+		}},
+		Active: &d4pb.Boolean{Value: true},
+		Name: []*d4pb.HumanName{{
+			Use:    &d4pb.HumanName_UseCode{Value: c4pb.NameUseCode_OFFICIAL},
+			Family: &d4pb.String{Value: "Doe"},
+			Given:  []*d4pb.String{{Value: "Jane"}, {Value: "Q"}},
+		}},
+		Gender:    &ppb.Patient_GenderCode{Value: c4pb.AdministrativeGenderCode_FEMALE},
+		BirthDate: &d4pb.Date{ValueUs: 321926400000000, Timezone: "UTC", Precision: d4pb.Date_DAY},
+		Deceased:  &ppb.Patient_DeceasedX{Choice: &ppb.Patient_DeceasedX_Boolean{Boolean: &d4pb.Boolean{Value: false}}},
+		Photo: []*d4pb.Attachment{{
+			ContentType: &d4pb.Attachment_ContentTypeCode{Value: "image/png"},
+			Data:        &d4pb.Base64Binary{Value: []byte{1, 2, 3}},
+		}},
+		GeneralPractitioner: []*d4pb.Reference{
+			{Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: "dr1"}}},
+			{Reference: &d4pb.Reference_Fragment{Fragment: &d4pb.String{Value: "org"}}},
+			{Reference: &d4pb.Reference_Uri{Uri: &d4pb.String{Value: "http://example.com/fhir/Practitioner/2"}}},
+		},
+	}
+}
+
+func testObservation() *obspb.Observation {
+	return &obspb.Observation{
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: "http://loinc.org"},
+			Code:   &d4pb.Code{Value: "2345-7"},
+		}}},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Effective: &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{
+			DateTime: &d4pb.DateTime{ValueUs: 1577836800000000, Timezone: "+01:00", Precision: d4pb.DateTime_SECOND},
+		}},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+			Value: &d4pb.Decimal{Value: "98.50"},
+			Unit:  &d4pb.String{Value: "mg/dL"},
+		}}},
+		Component: []*obspb.Observation_Component{{
+			Code:  &d4pb.CodeableConcept{Text: &d4pb.String{Value: "note"}},
+			Value: &obspb.Observation_Component_ValueX{Choice: &obspb.Observation_Component_ValueX_StringValue{StringValue: &d4pb.String{Value: "fasting"}}},
+		}},
+	}
+}
+
+func TestPatientRoundTrip(t *testing.T) {
+	want := testPatient()
+	got := PatientFromProto(want).ToProto()
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("PatientFromProto().ToProto() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestObservationRoundTrip(t *testing.T) {
+	want := testObservation()
+	got := ObservationFromProto(want).ToProto()
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ObservationFromProto().ToProto() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestPatientFromProto(t *testing.T) {
+	p := PatientFromProto(testPatient())
+	if got := *p.Name[0].Family; got != "Doe" {
+		t.Errorf("Name[0].Family = %q, want Doe", got)
+	}
+	if diff := cmp.Diff([]string{"Jane", "Q"}, p.Name[0].Given); diff != "" {
+		t.Errorf("Name[0].Given unexpected diff (-want +got):\n%s", diff)
+	}
+	if got := *p.Gender; got != c4pb.AdministrativeGenderCode_FEMALE {
+		t.Errorf("Gender = %v, want FEMALE", got)
+	}
+	if diff := cmp.Diff(&Temporal{ValueUs: 321926400000000, Timezone: "UTC", Precision: "DAY"}, p.BirthDate); diff != "" {
+		t.Errorf("BirthDate unexpected diff (-want +got):\n%s", diff)
+	}
+	var refs []string
+	for _, r := range p.GeneralPractitioner {
+		refs = append(refs, *r.Reference)
+	}
+	if diff := cmp.Diff([]string{"Practitioner/dr1", "#org", "http://example.com/fhir/Practitioner/2"}, refs); diff != "" {
+		t.Errorf("GeneralPractitioner references unexpected diff (-want +got):\n%s", diff)
+	}
+	if p.Deceased.Boolean == nil || *p.Deceased.Boolean {
+		t.Errorf("Deceased.Boolean = %v, want false", p.Deceased.Boolean)
+	}
+}
+
+func TestObservationToProto(t *testing.T) {
+	code := "2345-7"
+	value := "98.50"
+	ref := "Patient/p1"
+	status := c4pb.ObservationStatusCode_FINAL
+	o := &Observation{
+		Status:  &status,
+		Code:    &CodeableConcept{Coding: []*Coding{{Code: &code}}},
+		Subject: &Reference{Reference: &ref},
+		Value:   &ObservationValueX{Quantity: &Quantity{Value: &value}},
+	}
+	want := &obspb.Observation{
+		Status:  &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code:    &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{Code: &d4pb.Code{Value: code}}}},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+			Value: &d4pb.Decimal{Value: value},
+		}}},
+	}
+	if diff := cmp.Diff(want, o.ToProto(), protocmp.Transform()); diff != "" {
+		t.Errorf("ToProto() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestNil(t *testing.T) {
+	if got := PatientFromProto(nil); got != nil {
+		t.Errorf("PatientFromProto(nil) = %v, want nil", got)
+	}
+	var o *Observation
+	if got := o.ToProto(); got != nil {
+		t.Errorf("ToProto() on nil = %v, want nil", got)
+	}
+}
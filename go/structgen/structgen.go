@@ -0,0 +1,367 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package structgen generates plain Go structs for FHIR proto messages,
+// together with converters to and from the protos.
+//
+// The generated structs replace the primitive wrapper messages with Go
+// values: a String element becomes a *string, a repeated Code a []string, a
+// bound code its proto enum and a Boolean a *bool. Dates and times become a
+// *Temporal that keeps the microsecond value, time zone and precision of the
+// proto. Choice types become structs with one pointer per allowed type, of
+// which at most one is set, and a Reference carries its literal reference as
+// a string.
+//
+// The converters are lossless except for the id and extensions of primitive
+// elements, which have no place in a plain Go value and are dropped.
+// Extensions of complex elements and resources, contained resources and any
+// non-FHIR messages keep their proto types.
+package structgen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Config configures a generated file.
+type Config struct {
+	// Package is the name of the generated Go package.
+	Package string
+	// Messages are the root messages to generate structs for, usually
+	// resources. Structs for every FHIR message they reference are generated as
+	// well.
+	Messages []protoreflect.MessageDescriptor
+	// Command is recorded in the header of the generated file, i.e. the
+	// go:generate invocation that produced it.
+	Command string
+}
+
+// kind classifies how a message is represented in the generated code.
+type kind int
+
+const (
+	// kindProto messages keep their proto type.
+	kindProto kind = iota
+	// kindScalar messages are primitives holding a single value field.
+	kindScalar
+	// kindTemporal messages are dates and times.
+	kindTemporal
+	// kindStruct messages become generated structs.
+	kindStruct
+)
+
+// reservedNames are the method names protoc-gen-go avoids for fields.
+var reservedNames = map[string]bool{
+	"Reset":               true,
+	"String":              true,
+	"ProtoMessage":        true,
+	"Marshal":             true,
+	"Unmarshal":           true,
+	"ExtensionRangeArray": true,
+	"ExtensionMap":        true,
+	"Descriptor":          true,
+}
+
+type generator struct {
+	cfg     Config
+	imports map[string]string // import path -> alias
+	aliases map[string]bool
+	names   map[string]protoreflect.FullName
+
+	structs   []protoreflect.MessageDescriptor
+	scalars   []protoreflect.MessageDescriptor
+	temporals []protoreflect.MessageDescriptor
+	seen      map[protoreflect.FullName]bool
+
+	usesJSONFormat bool
+}
+
+// Generate returns the formatted source of a Go file with the structs and
+// converters for cfg.Messages.
+func Generate(cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		return nil, fmt.Errorf("no package name")
+	}
+	if len(cfg.Messages) == 0 {
+		return nil, fmt.Errorf("no messages to generate")
+	}
+	g := &generator{
+		cfg:     cfg,
+		imports: map[string]string{},
+		aliases: map[string]bool{},
+		names:   map[string]protoreflect.FullName{},
+		seen:    map[protoreflect.FullName]bool{},
+	}
+	for _, md := range cfg.Messages {
+		if g.classify(md) != kindStruct {
+			return nil, fmt.Errorf("%s cannot be generated as a struct", md.FullName())
+		}
+		if err := g.add(md); err != nil {
+			return nil, err
+		}
+	}
+	// Structs are discovered breadth first, so every referenced message is
+	// known once the queue is drained.
+	for i := 0; i < len(g.structs); i++ {
+		if err := g.collect(g.structs[i]); err != nil {
+			return nil, err
+		}
+	}
+	src := g.emit()
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return out, nil
+}
+
+// classify decides the representation of the message md.
+func (g *generator) classify(md protoreflect.MessageDescriptor) kind {
+	if !strings.HasPrefix(string(md.FullName()), "google.fhir.") ||
+		md.Name() == "Extension" || elementpath.IsContainedResource(md) {
+		return kindProto
+	}
+	if !elementpath.IsPrimitive(md) {
+		return kindStruct
+	}
+	if md.Fields().ByName("value_us") != nil {
+		return kindTemporal
+	}
+	if v := md.Fields().ByName("value"); v != nil && v.Message() == nil && !v.IsList() {
+		return kindScalar
+	}
+	return kindProto
+}
+
+// add records md as needing generated code.
+func (g *generator) add(md protoreflect.MessageDescriptor) error {
+	if g.seen[md.FullName()] {
+		return nil
+	}
+	g.seen[md.FullName()] = true
+	switch g.classify(md) {
+	case kindStruct:
+		name := structName(md)
+		if other, ok := g.names[name]; ok {
+			return fmt.Errorf("%s and %s both map to struct %s", other, md.FullName(), name)
+		}
+		g.names[name] = md.FullName()
+		g.structs = append(g.structs, md)
+	case kindScalar:
+		g.scalars = append(g.scalars, md)
+	case kindTemporal:
+		g.temporals = append(g.temporals, md)
+	}
+	return nil
+}
+
+// collect adds the messages referenced by the fields of md.
+func (g *generator) collect(md protoreflect.MessageDescriptor) error {
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil {
+			if fd.ContainingOneof() != nil {
+				return fmt.Errorf("oneof field %s is not a message", fd.FullName())
+			}
+			continue
+		}
+		if isReferenceOneof(fd) {
+			// Only the uri option is used; the typed ids are handled by
+			// jsonformat.
+			g.usesJSONFormat = true
+			continue
+		}
+		if err := g.add(fd.Message()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isReferenceOneof reports whether fd is part of the oneof holding the
+// literal reference of a Reference, which is generated as a single string.
+func isReferenceOneof(fd protoreflect.FieldDescriptor) bool {
+	od := fd.ContainingOneof()
+	return od != nil && od.Name() == "reference" && fd.ContainingMessage().Name() == "Reference"
+}
+
+// goIdent returns the name protoc-gen-go gives the message or enum d.
+func goIdent(d protoreflect.Descriptor) string {
+	name := string(d.Name())
+	for p := d.Parent(); p != nil; p = p.Parent() {
+		if _, ok := p.(protoreflect.FileDescriptor); ok {
+			break
+		}
+		name = string(p.Name()) + "_" + name
+	}
+	return name
+}
+
+// structName returns the name of the generated struct for md.
+func structName(md protoreflect.MessageDescriptor) string {
+	return strings.ReplaceAll(goIdent(md), "_", "")
+}
+
+// helperName returns the prefix of the conversion functions of a primitive.
+func helperName(md protoreflect.MessageDescriptor) string {
+	n := structName(md)
+	return strings.ToLower(n[:1]) + n[1:]
+}
+
+// fieldName returns the Go name protoc-gen-go gives the field fd.
+func fieldName(fd protoreflect.FieldDescriptor) string {
+	n := goCamelCase(string(fd.Name()))
+	if reservedNames[n] {
+		n += "_"
+	}
+	return n
+}
+
+// oneofWrapper returns the qualified name of the oneof wrapper type
+// protoc-gen-go generates for fd.
+func (g *generator) oneofWrapper(fd protoreflect.FieldDescriptor) string {
+	md := fd.ContainingMessage()
+	suffix := goCamelCase(string(fd.Name()))
+	if md.Messages().ByName(protoreflect.Name(suffix)) != nil || md.Enums().ByName(protoreflect.Name(suffix)) != nil {
+		suffix += "_"
+	}
+	return g.qualify(md) + "." + goIdent(md) + "_" + suffix
+}
+
+// qualify returns the package alias of the Go package defining d, importing
+// it if necessary.
+func (g *generator) qualify(d protoreflect.Descriptor) string {
+	path := d.ParentFile().Options().(*descriptorpb.FileOptions).GetGoPackage()
+	if i := strings.Index(path, ";"); i >= 0 {
+		path = path[:i]
+	}
+	if alias, ok := g.imports[path]; ok {
+		return alias
+	}
+	base := path[strings.LastIndex(path, "/")+1:]
+	base = strings.TrimSuffix(base, "_go_proto")
+	base = strings.ReplaceAll(base, "_", "") + "pb"
+	alias := base
+	for n := 2; g.aliases[alias]; n++ {
+		alias = fmt.Sprintf("%s%d", base, n)
+	}
+	g.aliases[alias] = true
+	g.imports[path] = alias
+	return alias
+}
+
+// protoType returns the qualified Go type of the message md.
+func (g *generator) protoType(md protoreflect.MessageDescriptor) string {
+	return g.qualify(md) + "." + goIdent(md)
+}
+
+// scalarType returns the Go type of the value of the primitive md.
+func (g *generator) scalarType(md protoreflect.MessageDescriptor) string {
+	v := md.Fields().ByName("value")
+	switch v.Kind() {
+	case protoreflect.BoolKind:
+		return "bool"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64"
+	case protoreflect.FloatKind:
+		return "float32"
+	case protoreflect.DoubleKind:
+		return "float64"
+	case protoreflect.BytesKind:
+		return "[]byte"
+	case protoreflect.EnumKind:
+		return g.qualify(v.Enum()) + "." + goIdent(v.Enum())
+	}
+	return "string"
+}
+
+// goType returns the Go type of a struct field holding md.
+func (g *generator) goType(md protoreflect.MessageDescriptor, list bool) string {
+	var t string
+	switch g.classify(md) {
+	case kindScalar:
+		t = g.scalarType(md)
+		if list {
+			return "[]" + t
+		}
+		if t == "[]byte" {
+			return t
+		}
+		return "*" + t
+	case kindTemporal:
+		t = "*Temporal"
+	case kindStruct:
+		t = "*" + structName(md)
+	default:
+		t = "*" + g.protoType(md)
+	}
+	if list {
+		return "[]" + t
+	}
+	return t
+}
+
+// sortedImports returns the import paths in a stable order.
+func (g *generator) sortedImports() []string {
+	var paths []string
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// goCamelCase converts a proto field name to its Go name the way
+// protoc-gen-go does.
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool { return 'a' <= c && c <= 'z' }
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structgen
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+)
+
+func TestGenerate_Golden(t *testing.T) {
+	want, err := os.ReadFile("internal/r4structs/structs.go")
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	got, err := Generate(Config{
+		Package: "r4structs",
+		Messages: []protoreflect.MessageDescriptor{
+			(&ppb.Patient{}).ProtoReflect().Descriptor(),
+			(&obspb.Observation{}).ProtoReflect().Descriptor(),
+		},
+		Command: "structgen -package r4structs -resources Patient,Observation -out structs.go",
+	})
+	if err != nil {
+		t.Fatalf("Generate() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("Generate() differs from internal/r4structs/structs.go, run go generate (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	patient := (&ppb.Patient{}).ProtoReflect().Descriptor()
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no package", Config{Messages: []protoreflect.MessageDescriptor{patient}}},
+		{"no messages", Config{Package: "p"}},
+		{"primitive root", Config{Package: "p", Messages: []protoreflect.MessageDescriptor{(&d4pb.String{}).ProtoReflect().Descriptor()}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Generate(test.cfg); err == nil {
+				t.Errorf("Generate() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestGoCamelCase(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"birth_date", "BirthDate"},
+		{"value_us", "ValueUs"},
+		{"class_value", "ClassValue"},
+		{"string_value", "StringValue"},
+		{"period_2", "Period_2"},
+		{"_x", "XX"},
+	}
+	for _, test := range tests {
+		if got := goCamelCase(test.in); got != test.want {
+			t.Errorf("goCamelCase(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
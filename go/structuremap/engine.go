@@ -0,0 +1,369 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structuremap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	smpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_map_go_proto"
+)
+
+type engine struct {
+	opts   Options
+	groups map[string]*smpb.StructureMap_Group
+	exprs  map[string]*fhirpath.Expression
+}
+
+// variables maps variable names to FHIR elements, held as proto.Message, or
+// FHIRPath system values.
+type variables map[string]interface{}
+
+func (v variables) with(name string, value interface{}) variables {
+	out := make(variables, len(v)+1)
+	for k, x := range v {
+		out[k] = x
+	}
+	out[name] = value
+	return out
+}
+
+func (e *engine) group(g *smpb.StructureMap_Group, args []interface{}) error {
+	name := g.GetName().GetValue()
+	if len(args) != len(g.GetInput()) {
+		return fmt.Errorf("group %s takes %d inputs, got %d", name, len(g.GetInput()), len(args))
+	}
+	if ext := g.GetExtends().GetValue(); ext != "" {
+		base, ok := e.groups[ext]
+		if !ok {
+			return fmt.Errorf("group %s extends unknown group %s", name, ext)
+		}
+		if err := e.group(base, args); err != nil {
+			return err
+		}
+	}
+	vars := variables{}
+	for i, in := range g.GetInput() {
+		vars[in.GetName().GetValue()] = args[i]
+	}
+	for _, r := range g.GetRule() {
+		if err := e.rule(r, vars); err != nil {
+			return fmt.Errorf("group %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (e *engine) rule(r *smpb.StructureMap_Group_Rule, vars variables) error {
+	err := e.sources(r.GetSource(), vars, func(vars variables) error {
+		for _, t := range r.GetTarget() {
+			var err error
+			if vars, err = e.target(t, vars); err != nil {
+				return err
+			}
+		}
+		for _, nested := range r.GetRule() {
+			if err := e.rule(nested, vars); err != nil {
+				return err
+			}
+		}
+		for _, dep := range r.GetDependent() {
+			g, ok := e.groups[dep.GetName().GetValue()]
+			if !ok {
+				return fmt.Errorf("unknown group %s", dep.GetName().GetValue())
+			}
+			var args []interface{}
+			for _, v := range dep.GetVariable() {
+				arg, ok := vars[v.GetValue()]
+				if !ok {
+					return fmt.Errorf("unknown variable %s", v.GetValue())
+				}
+				args = append(args, arg)
+			}
+			if err := e.group(g, args); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("rule %s: %w", r.GetName().GetValue(), err)
+	}
+	return nil
+}
+
+// sources calls fn once for every combination of the items matched by srcs,
+// with the source variables bound.
+func (e *engine) sources(srcs []*smpb.StructureMap_Group_Rule_Source, vars variables, fn func(variables) error) error {
+	if len(srcs) == 0 {
+		return fn(vars)
+	}
+	s := srcs[0]
+	items, err := e.sourceItems(s, vars)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if cond := s.GetCondition().GetValue(); cond != "" {
+			ok, err := e.evaluateBool(cond, item, vars)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		if check := s.GetCheck().GetValue(); check != "" {
+			ok, err := e.evaluateBool(check, item, vars)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("check %q failed", check)
+			}
+		}
+		next := vars
+		if v := s.GetVariable().GetValue(); v != "" {
+			next = vars.with(v, item)
+		}
+		if err := e.sources(srcs[1:], next, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *engine) sourceItems(s *smpb.StructureMap_Group_Rule_Source, vars variables) (fhirpath.Collection, error) {
+	ctx, ok := vars[s.GetContext().GetValue()]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %s", s.GetContext().GetValue())
+	}
+	items := fhirpath.Collection{ctx}
+	if elem := s.GetElement().GetValue(); elem != "" {
+		var err error
+		if items, err = e.evaluate("`"+elem+"`", ctx, vars); err != nil {
+			return nil, err
+		}
+	}
+	if typ := s.GetType().GetValue(); typ != "" {
+		var matched fhirpath.Collection
+		for _, item := range items {
+			if typeName(item) == typ {
+				matched = append(matched, item)
+			}
+		}
+		items = matched
+	}
+	if len(items) == 0 && s.GetDefaultValue() != nil {
+		if def := choiceValue(s.GetDefaultValue().ProtoReflect()); def != nil {
+			items = fhirpath.Collection{def}
+		}
+	}
+	if min := s.GetMin(); min != nil && len(items) < int(min.GetValue()) {
+		return nil, fmt.Errorf("source %s.%s has %d items, want at least %d", s.GetContext().GetValue(), s.GetElement().GetValue(), len(items), min.GetValue())
+	}
+	if max := s.GetMax().GetValue(); max != "" && max != "*" {
+		n, err := strconv.Atoi(max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source max %q", max)
+		}
+		if len(items) > n {
+			return nil, fmt.Errorf("source %s.%s has %d items, want at most %d", s.GetContext().GetValue(), s.GetElement().GetValue(), len(items), n)
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	switch s.GetListMode().GetValue() {
+	case c4pb.StructureMapSourceListModeCode_FIRST:
+		items = items[:1]
+	case c4pb.StructureMapSourceListModeCode_NOT_FIRST:
+		items = items[1:]
+	case c4pb.StructureMapSourceListModeCode_LAST:
+		items = items[len(items)-1:]
+	case c4pb.StructureMapSourceListModeCode_NOT_LAST:
+		items = items[:len(items)-1]
+	case c4pb.StructureMapSourceListModeCode_ONLY_ONE:
+		if len(items) > 1 {
+			return nil, fmt.Errorf("source %s.%s has %d items, want only one", s.GetContext().GetValue(), s.GetElement().GetValue(), len(items))
+		}
+	}
+	return items, nil
+}
+
+// target applies t and returns vars with its variable bound.
+func (e *engine) target(t *smpb.StructureMap_Group_Rule_Target, vars variables) (variables, error) {
+	var ctx protoreflect.Message
+	if name := t.GetContext().GetValue(); name != "" {
+		v, ok := vars[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %s", name)
+		}
+		m, ok := v.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("target context %s is not an element", name)
+		}
+		ctx = m.ProtoReflect()
+	}
+	elem := t.GetElement().GetValue()
+	if elem != "" && ctx == nil {
+		return nil, fmt.Errorf("target element %s has no context", elem)
+	}
+	transform := t.GetTransform().GetValue()
+	createChild := transform == c4pb.StructureMapTransformCode_INVALID_UNINITIALIZED ||
+		(transform == c4pb.StructureMapTransformCode_CREATE && len(t.GetParameter()) == 0)
+	var result interface{}
+	switch {
+	case createChild:
+		if elem == "" {
+			return nil, fmt.Errorf("target without element needs a transform")
+		}
+		child, err := elementpath.Child(ctx, elem, isList(ctx, elem))
+		if err != nil {
+			return nil, err
+		}
+		result = child.Interface()
+	default:
+		values, err := e.transform(t, vars)
+		if err != nil {
+			return nil, err
+		}
+		if elem == "" {
+			if len(values) > 0 {
+				result = values[len(values)-1]
+			}
+			break
+		}
+		for _, v := range values {
+			pv, ok := fhirpath.ProtoValue(v)
+			if !ok {
+				return nil, fmt.Errorf("cannot assign %v to %s", v, elem)
+			}
+			if err := elementpath.Set(ctx, elem, pv); err != nil {
+				return nil, err
+			}
+			result = lastValue(ctx, elem)
+		}
+	}
+	if v := t.GetVariable().GetValue(); v != "" && result != nil {
+		vars = vars.with(v, result)
+	}
+	return vars, nil
+}
+
+func (e *engine) evaluate(expr string, input interface{}, vars variables) (fhirpath.Collection, error) {
+	compiled, ok := e.exprs[expr]
+	if !ok {
+		var err error
+		if compiled, err = fhirpath.Compile(expr); err != nil {
+			return nil, err
+		}
+		e.exprs[expr] = compiled
+	}
+	opts := []fhirpath.EvaluateOption{fhirpath.WithResolver(e.opts.FHIRPathResolver)}
+	for name, v := range vars {
+		opts = append(opts, fhirpath.WithVariable(name, fhirpath.Collection{v}))
+	}
+	return compiled.Evaluate(input, opts...)
+}
+
+func (e *engine) evaluateBool(expr string, input interface{}, vars variables) (bool, error) {
+	res, err := e.evaluate(expr, input, vars)
+	if err != nil {
+		return false, err
+	}
+	switch len(res) {
+	case 0:
+		return false, nil
+	case 1:
+		b, ok := res[0].(bool)
+		if !ok {
+			sys, _ := fhirpath.SystemValue(res[0])
+			b, ok = sys.(bool)
+		}
+		if !ok {
+			return false, fmt.Errorf("%q did not evaluate to a boolean", expr)
+		}
+		return b, nil
+	}
+	return false, fmt.Errorf("%q evaluated to %d items, want a single boolean", expr, len(res))
+}
+
+// isList returns true iff the element name of msg is repeated.
+func isList(msg protoreflect.Message, name string) bool {
+	fd, _, err := elementpath.LookupField(msg.Descriptor(), strings.TrimSuffix(name, "[x]"))
+	return err == nil && fd.IsList()
+}
+
+// lastValue returns the value most recently assigned to the element name of
+// msg, unwrapping choice types and contained resources so that later rules
+// modify the element in place.
+func lastValue(msg protoreflect.Message, name string) proto.Message {
+	fd, _, err := elementpath.LookupField(msg.Descriptor(), strings.TrimSuffix(name, "[x]"))
+	if err != nil {
+		return nil
+	}
+	var m protoreflect.Message
+	if fd.IsList() {
+		l := msg.Mutable(fd).List()
+		m = l.Get(l.Len() - 1).Message()
+	} else {
+		m = msg.Mutable(fd).Message()
+	}
+	if d := m.Descriptor(); elementpath.IsChoice(d) || elementpath.IsContainedResource(d) {
+		if set := m.WhichOneof(d.Oneofs().Get(0)); set != nil {
+			m = m.Mutable(set).Message()
+		}
+	}
+	return m.Interface()
+}
+
+// choiceValue returns the value set in the choice message m, or nil.
+func choiceValue(m protoreflect.Message) proto.Message {
+	set := m.WhichOneof(m.Descriptor().Oneofs().Get(0))
+	if set == nil {
+		return nil
+	}
+	return m.Get(set).Message().Interface()
+}
+
+func typeName(v interface{}) string {
+	switch x := v.(type) {
+	case proto.Message:
+		if res := elementpath.Unwrap(x); res != nil {
+			return fhirpath.TypeName(res.ProtoReflect().Descriptor())
+		}
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int64:
+		return "integer"
+	case float64:
+		return "decimal"
+	case fhirpath.Quantity:
+		return "Quantity"
+	case fhirpath.Temporal:
+		return [...]string{"date", "dateTime", "time"}[x.Kind]
+	}
+	return ""
+}
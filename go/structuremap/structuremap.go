@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package structuremap executes R4 StructureMap resources against FHIR protos.
+//
+// The engine interprets the resource form of a map; it does not parse the
+// FHIR Mapping Language. Source elements, conditions and checks are evaluated
+// as FHIRPath, with the map variables available as %<name>. The create, copy,
+// evaluate, truncate, append, cc, c, qty, id, cp, uuid and reference
+// transforms are supported; maps using any other transform fail with an error.
+package structuremap
+
+import (
+	"fmt"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/internal/uuid"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	smpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_map_go_proto"
+)
+
+// Options configures Transform.
+type Options struct {
+	// Resolver returns the StructureMaps imported by the map by canonical URL.
+	// Imports are only followed if it is set.
+	Resolver func(url string) (*smpb.StructureMap, error)
+	// NewID returns the ids used by the uuid transform and assigned to
+	// resources without an id by the reference transform. Random version 4
+	// UUIDs are used if it is nil.
+	NewID func() string
+	// FHIRPathResolver is used by resolve() in FHIRPath expressions.
+	FHIRPathResolver fhirpath.Resolver
+}
+
+// Transform executes the first group of sm. The group's source inputs are
+// bound to source and its target inputs to target, which is modified in place.
+// Inputs without a mode are bound positionally, source first.
+func Transform(sm *smpb.StructureMap, source, target proto.Message, opts Options) error {
+	if len(sm.GetGroup()) == 0 {
+		return fmt.Errorf("StructureMap %q has no groups", sm.GetUrl().GetValue())
+	}
+	e := &engine{opts: opts, groups: map[string]*smpb.StructureMap_Group{}, exprs: map[string]*fhirpath.Expression{}}
+	if e.opts.NewID == nil {
+		e.opts.NewID = uuid.New
+	}
+	if err := e.load(sm, map[string]bool{}); err != nil {
+		return err
+	}
+	entry := sm.GetGroup()[0]
+	var args []interface{}
+	for i, in := range entry.GetInput() {
+		switch in.GetMode().GetValue() {
+		case c4pb.StructureMapInputModeCode_SOURCE:
+			args = append(args, source)
+		case c4pb.StructureMapInputModeCode_TARGET:
+			args = append(args, target)
+		default:
+			args = append(args, []proto.Message{source, target}[i%2])
+		}
+	}
+	return e.group(entry, args)
+}
+
+// load registers the groups of sm and, recursively, of its imports.
+func (e *engine) load(sm *smpb.StructureMap, seen map[string]bool) error {
+	url := sm.GetUrl().GetValue()
+	if seen[url] {
+		return nil
+	}
+	seen[url] = true
+	for _, g := range sm.GetGroup() {
+		if _, ok := e.groups[g.GetName().GetValue()]; !ok {
+			e.groups[g.GetName().GetValue()] = g
+		}
+	}
+	if e.opts.Resolver == nil {
+		return nil
+	}
+	for _, imp := range sm.GetImport() {
+		m, err := e.opts.Resolver(imp.GetValue())
+		if err != nil {
+			return fmt.Errorf("resolving import %q: %w", imp.GetValue(), err)
+		}
+		if err := e.load(m, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
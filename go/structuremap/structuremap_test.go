@@ -0,0 +1,287 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structuremap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	obspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/observation_go_proto"
+	qrpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/questionnaire_response_go_proto"
+	smpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_map_go_proto"
+)
+
+type param = *smpb.StructureMap_Group_Rule_Target_Parameter
+
+func id(name string) param {
+	return &smpb.StructureMap_Group_Rule_Target_Parameter{Value: &smpb.StructureMap_Group_Rule_Target_Parameter_ValueX{
+		Choice: &smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_Id{Id: &d4pb.Id{Value: name}},
+	}}
+}
+
+func lit(s string) param {
+	return &smpb.StructureMap_Group_Rule_Target_Parameter{Value: &smpb.StructureMap_Group_Rule_Target_Parameter_ValueX{
+		Choice: &smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_StringValue{StringValue: &d4pb.String{Value: s}},
+	}}
+}
+
+func source(context, element, variable, condition string) *smpb.StructureMap_Group_Rule_Source {
+	s := &smpb.StructureMap_Group_Rule_Source{Context: &d4pb.Id{Value: context}}
+	if element != "" {
+		s.Element = &d4pb.String{Value: element}
+	}
+	if variable != "" {
+		s.Variable = &d4pb.Id{Value: variable}
+	}
+	if condition != "" {
+		s.Condition = &d4pb.String{Value: condition}
+	}
+	return s
+}
+
+func target(context, element, variable string, transform c4pb.StructureMapTransformCode_Value, params ...param) *smpb.StructureMap_Group_Rule_Target {
+	t := &smpb.StructureMap_Group_Rule_Target{Parameter: params}
+	if context != "" {
+		t.Context = &d4pb.Id{Value: context}
+	}
+	if element != "" {
+		t.Element = &d4pb.String{Value: element}
+	}
+	if variable != "" {
+		t.Variable = &d4pb.Id{Value: variable}
+	}
+	if transform != c4pb.StructureMapTransformCode_INVALID_UNINITIALIZED {
+		t.Transform = &smpb.StructureMap_Group_Rule_Target_TransformCode{Value: transform}
+	}
+	return t
+}
+
+func rule(name string, srcs []*smpb.StructureMap_Group_Rule_Source, tgts []*smpb.StructureMap_Group_Rule_Target, nested ...*smpb.StructureMap_Group_Rule) *smpb.StructureMap_Group_Rule {
+	return &smpb.StructureMap_Group_Rule{Name: &d4pb.Id{Value: name}, Source: srcs, Target: tgts, Rule: nested}
+}
+
+func input(name string, mode c4pb.StructureMapInputModeCode_Value) *smpb.StructureMap_Group_Input {
+	return &smpb.StructureMap_Group_Input{Name: &d4pb.Id{Value: name}, Mode: &smpb.StructureMap_Group_Input_ModeCode{Value: mode}}
+}
+
+func group(name string, inputs []*smpb.StructureMap_Group_Input, rules ...*smpb.StructureMap_Group_Rule) *smpb.StructureMap_Group {
+	return &smpb.StructureMap_Group{Name: &d4pb.Id{Value: name}, Input: inputs, Rule: rules}
+}
+
+const (
+	create   = c4pb.StructureMapTransformCode_CREATE
+	copyT    = c4pb.StructureMapTransformCode_COPY
+	evaluate = c4pb.StructureMapTransformCode_EVALUATE
+)
+
+func weightMap() *smpb.StructureMap {
+	entryRule := rule("entry",
+		[]*smpb.StructureMap_Group_Rule_Source{source("src", "item", "item", "linkId = 'weight'")},
+		[]*smpb.StructureMap_Group_Rule_Target{
+			target("bundle", "entry", "entry", 0),
+			target("entry", "resource", "obs", create, lit("Observation")),
+			target("", "", "uuid", c4pb.StructureMapTransformCode_UUID),
+			target("entry", "fullUrl", "", c4pb.StructureMapTransformCode_APPEND, lit("urn:uuid:"), id("uuid")),
+			target("obs", "status", "", copyT, lit("final")),
+			target("obs", "code", "", c4pb.StructureMapTransformCode_CC, lit("http://loinc.org"), lit("29463-7"), lit("Body weight")),
+			target("obs", "derivedFrom", "", c4pb.StructureMapTransformCode_REFERENCE, id("src")),
+		},
+		rule("value",
+			[]*smpb.StructureMap_Group_Rule_Source{source("item", "answer", "answer", ""), source("answer", "value", "v", "")},
+			[]*smpb.StructureMap_Group_Rule_Target{
+				target("obs", "value", "", c4pb.StructureMapTransformCode_QTY, id("v"), lit("kg"), lit("http://unitsofmeasure.org"), lit("kg")),
+			}),
+		rule("subject",
+			[]*smpb.StructureMap_Group_Rule_Source{source("src", "subject", "s", "")},
+			[]*smpb.StructureMap_Group_Rule_Target{target("obs", "subject", "", copyT, id("s"))}),
+		rule("issued",
+			[]*smpb.StructureMap_Group_Rule_Source{source("src", "", "", "")},
+			[]*smpb.StructureMap_Group_Rule_Target{target("obs", "effective", "", evaluate, id("src"), lit("authored"))}),
+	)
+	entryRule.Dependent = []*smpb.StructureMap_Group_Rule_Dependent{{
+		Name:     &d4pb.Id{Value: "request"},
+		Variable: []*d4pb.String{{Value: "entry"}},
+	}}
+	return &smpb.StructureMap{
+		Url: &d4pb.Uri{Value: "http://example.org/StructureMap/weight"},
+		Group: []*smpb.StructureMap_Group{
+			group("weight",
+				[]*smpb.StructureMap_Group_Input{input("src", c4pb.StructureMapInputModeCode_SOURCE), input("bundle", c4pb.StructureMapInputModeCode_TARGET)},
+				rule("type",
+					[]*smpb.StructureMap_Group_Rule_Source{source("src", "", "", "")},
+					[]*smpb.StructureMap_Group_Rule_Target{target("bundle", "type", "", copyT, lit("transaction"))}),
+				entryRule,
+			),
+			group("request",
+				[]*smpb.StructureMap_Group_Input{input("entry", c4pb.StructureMapInputModeCode_TARGET)},
+				rule("request",
+					[]*smpb.StructureMap_Group_Rule_Source{source("entry", "", "", "")},
+					[]*smpb.StructureMap_Group_Rule_Target{
+						target("entry", "request", "req", 0),
+						target("req", "method", "", copyT, lit("POST")),
+						target("req", "url", "", copyT, lit("Observation")),
+					}),
+			),
+		},
+	}
+}
+
+func testResponse() *qrpb.QuestionnaireResponse {
+	decimal := func(s string) *qrpb.QuestionnaireResponse_Item_Answer {
+		return &qrpb.QuestionnaireResponse_Item_Answer{Value: &qrpb.QuestionnaireResponse_Item_Answer_ValueX{
+			Choice: &qrpb.QuestionnaireResponse_Item_Answer_ValueX_Decimal{Decimal: &d4pb.Decimal{Value: s}},
+		}}
+	}
+	return &qrpb.QuestionnaireResponse{
+		Id:       &d4pb.Id{Value: "qr1"},
+		Subject:  &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Authored: &d4pb.DateTime{ValueUs: 1700000000000000, Timezone: "Z", Precision: d4pb.DateTime_SECOND},
+		Item: []*qrpb.QuestionnaireResponse_Item{
+			{LinkId: &d4pb.String{Value: "height"}, Answer: []*qrpb.QuestionnaireResponse_Item_Answer{decimal("180")}},
+			{LinkId: &d4pb.String{Value: "weight"}, Answer: []*qrpb.QuestionnaireResponse_Item_Answer{decimal("72.5")}},
+		},
+	}
+}
+
+func TestTransform(t *testing.T) {
+	got := &r4pb.Bundle{}
+	n := 0
+	opts := Options{NewID: func() string { n++; return fmt.Sprintf("id-%d", n) }}
+	if err := Transform(weightMap(), testResponse(), got, opts); err != nil {
+		t.Fatalf("Transform() returned unexpected error: %v", err)
+	}
+	obs := &obspb.Observation{
+		Status: &obspb.Observation_StatusCode{Value: c4pb.ObservationStatusCode_FINAL},
+		Code: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System:  &d4pb.Uri{Value: "http://loinc.org"},
+			Code:    &d4pb.Code{Value: "29463-7"},
+			Display: &d4pb.String{Value: "Body weight"},
+		}}},
+		DerivedFrom: []*d4pb.Reference{{Reference: &d4pb.Reference_QuestionnaireResponseId{QuestionnaireResponseId: &d4pb.ReferenceId{Value: "qr1"}}}},
+		Value: &obspb.Observation_ValueX{Choice: &obspb.Observation_ValueX_Quantity{Quantity: &d4pb.Quantity{
+			Value:  &d4pb.Decimal{Value: "72.5"},
+			Unit:   &d4pb.String{Value: "kg"},
+			System: &d4pb.Uri{Value: "http://unitsofmeasure.org"},
+			Code:   &d4pb.Code{Value: "kg"},
+		}}},
+		Subject: &d4pb.Reference{Reference: &d4pb.Reference_PatientId{PatientId: &d4pb.ReferenceId{Value: "p1"}}},
+		Effective: &obspb.Observation_EffectiveX{Choice: &obspb.Observation_EffectiveX_DateTime{
+			DateTime: &d4pb.DateTime{ValueUs: 1700000000000000, Timezone: "Z", Precision: d4pb.DateTime_SECOND},
+		}},
+	}
+	want := &r4pb.Bundle{
+		Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_TRANSACTION},
+		Entry: []*r4pb.Bundle_Entry{{
+			FullUrl:  &d4pb.Uri{Value: "urn:uuid:id-1"},
+			Resource: &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Observation{Observation: obs}},
+			Request: &r4pb.Bundle_Entry_Request{
+				Method: &r4pb.Bundle_Entry_Request_MethodCode{Value: c4pb.HTTPVerbCode_POST},
+				Url:    &d4pb.Uri{Value: "Observation"},
+			},
+		}},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Transform() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestTransform_Imports(t *testing.T) {
+	sm := weightMap()
+	imported := &smpb.StructureMap{
+		Url:   &d4pb.Uri{Value: "http://example.org/StructureMap/request"},
+		Group: sm.Group[1:],
+	}
+	sm.Group = sm.Group[:1]
+	sm.Import = []*d4pb.Canonical{{Value: imported.GetUrl().GetValue()}}
+	resolver := func(url string) (*smpb.StructureMap, error) {
+		if url != imported.GetUrl().GetValue() {
+			return nil, fmt.Errorf("unknown map %q", url)
+		}
+		return imported, nil
+	}
+	got := &r4pb.Bundle{}
+	if err := Transform(sm, testResponse(), got, Options{Resolver: resolver}); err != nil {
+		t.Fatalf("Transform() returned unexpected error: %v", err)
+	}
+	if got := got.GetEntry()[0].GetRequest().GetUrl().GetValue(); got != "Observation" {
+		t.Errorf("Transform() entry request url = %q, want %q", got, "Observation")
+	}
+}
+
+func TestTransform_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		sm   func() *smpb.StructureMap
+	}{
+		{
+			name: "no groups",
+			sm:   func() *smpb.StructureMap { return &smpb.StructureMap{} },
+		},
+		{
+			name: "unresolved import",
+			sm: func() *smpb.StructureMap {
+				sm := weightMap()
+				sm.Group = sm.Group[:1]
+				return sm
+			},
+		},
+		{
+			name: "unsupported transform",
+			sm: func() *smpb.StructureMap {
+				sm := weightMap()
+				sm.Group[0].Rule[0].Target[0] = target("bundle", "type", "", c4pb.StructureMapTransformCode_TRANSLATE, lit("x"))
+				return sm
+			},
+		},
+		{
+			name: "unknown variable",
+			sm: func() *smpb.StructureMap {
+				sm := weightMap()
+				sm.Group[0].Rule[0].Target[0] = target("bundle", "type", "", copyT, id("nope"))
+				return sm
+			},
+		},
+		{
+			name: "incompatible value",
+			sm: func() *smpb.StructureMap {
+				sm := weightMap()
+				sm.Group[0].Rule[0].Target[0] = target("bundle", "type", "", copyT, lit("not-a-bundle-type"))
+				return sm
+			},
+		},
+		{
+			name: "failed check",
+			sm: func() *smpb.StructureMap {
+				sm := weightMap()
+				s := sm.Group[0].Rule[0].Source[0]
+				s.Check = &d4pb.String{Value: "item.exists().not()"}
+				return sm
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Transform(test.sm(), testResponse(), &r4pb.Bundle{}, Options{}); err == nil {
+				t.Errorf("Transform() succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package structuremap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	smpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/structure_map_go_proto"
+)
+
+// corePackage is the proto package of the R4 data types and resources.
+const corePackage = "google.fhir.r4.core."
+
+const identifierTypeSystem = "http://terminology.hl7.org/CodeSystem/v2-0203"
+
+// transform computes the values produced by the transform of t.
+func (e *engine) transform(t *smpb.StructureMap_Group_Rule_Target, vars variables) (fhirpath.Collection, error) {
+	code := t.GetTransform().GetValue()
+	name := fhirpath.CodeString(code.Descriptor().Values().ByNumber(code.Number()))
+	params := make([]interface{}, len(t.GetParameter()))
+	for i, p := range t.GetParameter() {
+		v, err := parameter(p, vars)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		params[i] = v
+	}
+	arity := func(min, max int) error {
+		if len(params) < min || len(params) > max {
+			return fmt.Errorf("%s takes %d to %d parameters, got %d", name, min, max, len(params))
+		}
+		return nil
+	}
+	switch code {
+	case c4pb.StructureMapTransformCode_CREATE:
+		if err := arity(1, 1); err != nil {
+			return nil, err
+		}
+		typ, err := stringParam(name, params[0])
+		if err != nil {
+			return nil, err
+		}
+		mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(corePackage + typ))
+		if err != nil {
+			return nil, fmt.Errorf("create: unknown type %q", typ)
+		}
+		return fhirpath.Collection{mt.New().Interface()}, nil
+	case c4pb.StructureMapTransformCode_COPY:
+		if err := arity(1, 1); err != nil {
+			return nil, err
+		}
+		return fhirpath.Collection{params[0]}, nil
+	case c4pb.StructureMapTransformCode_EVALUATE:
+		if err := arity(2, 2); err != nil {
+			return nil, err
+		}
+		expr, err := stringParam(name, params[1])
+		if err != nil {
+			return nil, err
+		}
+		return e.evaluate(expr, params[0], vars)
+	case c4pb.StructureMapTransformCode_TRUNCATE:
+		if err := arity(2, 2); err != nil {
+			return nil, err
+		}
+		s, err := stringParam(name, params[0])
+		if err != nil {
+			return nil, err
+		}
+		n, ok := params[1].(int64)
+		if !ok || n < 0 {
+			return nil, fmt.Errorf("truncate: invalid length %v", params[1])
+		}
+		if r := []rune(s); int64(len(r)) > n {
+			s = string(r[:n])
+		}
+		return fhirpath.Collection{s}, nil
+	case c4pb.StructureMapTransformCode_APPEND:
+		var b strings.Builder
+		for _, p := range params {
+			s, err := stringParam(name, p)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(s)
+		}
+		return fhirpath.Collection{b.String()}, nil
+	case c4pb.StructureMapTransformCode_C:
+		if err := arity(2, 3); err != nil {
+			return nil, err
+		}
+		c, err := coding(name, params)
+		if err != nil {
+			return nil, err
+		}
+		return fhirpath.Collection{c}, nil
+	case c4pb.StructureMapTransformCode_CC:
+		if err := arity(1, 3); err != nil {
+			return nil, err
+		}
+		if len(params) == 1 {
+			text, err := stringParam(name, params[0])
+			if err != nil {
+				return nil, err
+			}
+			return fhirpath.Collection{&d4pb.CodeableConcept{Text: &d4pb.String{Value: text}}}, nil
+		}
+		c, err := coding(name, params)
+		if err != nil {
+			return nil, err
+		}
+		return fhirpath.Collection{&d4pb.CodeableConcept{Coding: []*d4pb.Coding{c}}}, nil
+	case c4pb.StructureMapTransformCode_QTY:
+		return quantity(name, params)
+	case c4pb.StructureMapTransformCode_ID:
+		if err := arity(2, 3); err != nil {
+			return nil, err
+		}
+		strs, err := stringParams(name, params)
+		if err != nil {
+			return nil, err
+		}
+		id := &d4pb.Identifier{System: &d4pb.Uri{Value: strs[0]}, Value: &d4pb.String{Value: strs[1]}}
+		if len(strs) == 3 {
+			id.Type = &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+				System: &d4pb.Uri{Value: identifierTypeSystem},
+				Code:   &d4pb.Code{Value: strs[2]},
+			}}}
+		}
+		return fhirpath.Collection{id}, nil
+	case c4pb.StructureMapTransformCode_CP:
+		if err := arity(1, 2); err != nil {
+			return nil, err
+		}
+		strs, err := stringParams(name, params)
+		if err != nil {
+			return nil, err
+		}
+		cp := &d4pb.ContactPoint{Value: &d4pb.String{Value: strs[len(strs)-1]}}
+		if len(strs) == 2 {
+			if err := elementpath.Set(cp.ProtoReflect(), "system", &d4pb.Code{Value: strs[0]}); err != nil {
+				return nil, fmt.Errorf("cp: %w", err)
+			}
+		}
+		return fhirpath.Collection{cp}, nil
+	case c4pb.StructureMapTransformCode_UUID:
+		if err := arity(0, 0); err != nil {
+			return nil, err
+		}
+		return fhirpath.Collection{e.opts.NewID()}, nil
+	case c4pb.StructureMapTransformCode_REFERENCE:
+		if err := arity(1, 1); err != nil {
+			return nil, err
+		}
+		ref, err := e.reference(params[0])
+		if err != nil {
+			return nil, err
+		}
+		return fhirpath.Collection{ref}, nil
+	}
+	return nil, fmt.Errorf("unsupported transform %s", name)
+}
+
+// parameter returns the value of a transform parameter. Ids name variables;
+// all other parameter types are literals.
+func parameter(p *smpb.StructureMap_Group_Rule_Target_Parameter, vars variables) (interface{}, error) {
+	switch x := p.GetValue().GetChoice().(type) {
+	case *smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_Id:
+		v, ok := vars[x.Id.GetValue()]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %s", x.Id.GetValue())
+		}
+		return v, nil
+	case *smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_StringValue:
+		return x.StringValue.GetValue(), nil
+	case *smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_Boolean:
+		return x.Boolean.GetValue(), nil
+	case *smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_Integer:
+		return int64(x.Integer.GetValue()), nil
+	case *smpb.StructureMap_Group_Rule_Target_Parameter_ValueX_Decimal:
+		f, err := strconv.ParseFloat(x.Decimal.GetValue(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decimal parameter %q", x.Decimal.GetValue())
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("parameter has no value")
+}
+
+func stringParam(transform string, v interface{}) (string, error) {
+	sys, ok := fhirpath.SystemValue(v)
+	if ok {
+		if s, ok := (fhirpath.Collection{sys}).StringValue(); ok {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("%s: parameter %v is not a string", transform, v)
+}
+
+func stringParams(transform string, params []interface{}) ([]string, error) {
+	out := make([]string, len(params))
+	for i, p := range params {
+		s, err := stringParam(transform, p)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+func coding(transform string, params []interface{}) (*d4pb.Coding, error) {
+	strs, err := stringParams(transform, params)
+	if err != nil {
+		return nil, err
+	}
+	c := &d4pb.Coding{System: &d4pb.Uri{Value: strs[0]}, Code: &d4pb.Code{Value: strs[1]}}
+	if len(strs) == 3 {
+		c.Display = &d4pb.String{Value: strs[2]}
+	}
+	return c, nil
+}
+
+// quantity implements qty(text), where text is a value followed by a UCUM
+// unit, and qty(value, unit[, system, code]).
+func quantity(transform string, params []interface{}) (fhirpath.Collection, error) {
+	strs, err := stringParams(transform, params)
+	if err != nil {
+		return nil, err
+	}
+	var value, unit, system, code string
+	switch len(strs) {
+	case 1:
+		value, unit, _ = strings.Cut(strings.TrimSpace(strs[0]), " ")
+		unit = strings.TrimSpace(unit)
+		system, code = "http://unitsofmeasure.org", unit
+	case 2:
+		value, unit = strs[0], strs[1]
+	case 4:
+		value, unit, system, code = strs[0], strs[1], strs[2], strs[3]
+	default:
+		return nil, fmt.Errorf("%s takes 1, 2 or 4 parameters, got %d", transform, len(strs))
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return nil, fmt.Errorf("%s: invalid value %q", transform, value)
+	}
+	q := &d4pb.Quantity{Value: &d4pb.Decimal{Value: value}}
+	if unit != "" {
+		q.Unit = &d4pb.String{Value: unit}
+	}
+	if system != "" {
+		q.System = &d4pb.Uri{Value: system}
+	}
+	if code != "" {
+		q.Code = &d4pb.Code{Value: code}
+	}
+	return fhirpath.Collection{q}, nil
+}
+
+// reference returns a Reference to the resource v, assigning it an id if it
+// has none.
+func (e *engine) reference(v interface{}) (*d4pb.Reference, error) {
+	m, ok := v.(proto.Message)
+	if !ok || elementpath.ResourceType(m) == "" {
+		return nil, fmt.Errorf("reference: %v is not a resource", v)
+	}
+	res := elementpath.Unwrap(m).ProtoReflect()
+	fd := res.Descriptor().Fields().ByName("id")
+	id := res.Get(fd).Message().Interface().(*d4pb.Id)
+	if id.GetValue() == "" {
+		id = &d4pb.Id{Value: e.opts.NewID()}
+		res.Set(fd, protoreflect.ValueOfMessage(id.ProtoReflect()))
+	}
+	return fhirtypes.Reference(string(res.Descriptor().Name()), id.GetValue()), nil
+}
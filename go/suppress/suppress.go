@@ -0,0 +1,198 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package suppress loads suppressions, the known and accepted issues of a
+// dataset, from YAML or JSON files, so that data quality gates fail only on
+// new issues:
+//
+//	suppressions:
+//	- code: us-core-6
+//	  path: Patient.telecom
+//	  expires: 2026-12-31
+//	  justification: Legacy feed sends phone numbers without a system, see TICKET-123
+//	- path: Observation.performer
+//	  justification: Device observations have no performer
+//
+// A suppression matches the issues of the constraint with key code, i.e. a
+// profile invariant or business rule, at paths matching path; see Matches.
+// Every suppression must say why the issues are accepted, and may expire so
+// that it is revisited. Suppressed issues are not dropped: reports list them,
+// with their justification, apart from the issues that count.
+package suppress
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/revalidate"
+	"gopkg.in/yaml.v2"
+)
+
+// dateLayout is the layout of expiry dates.
+const dateLayout = "2006-01-02"
+
+// File is a file of suppressions.
+type File struct {
+	Suppressions []Suppression `yaml:"suppressions" json:"suppressions"`
+}
+
+// Suppression accepts the issues it matches.
+type Suppression struct {
+	// Code is the key of the constraint of the issues, i.e. "us-core-6", or
+	// "" for issues of any constraint.
+	Code string `yaml:"code,omitempty" json:"code,omitempty"`
+	// Path is the pattern of the paths of the issues, or "" for any path.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Expires is the last day the suppression applies, as YYYY-MM-DD in UTC,
+	// or "" if it does not expire.
+	Expires string `yaml:"expires,omitempty" json:"expires,omitempty"`
+	// Justification says why the issues are accepted.
+	Justification string `yaml:"justification" json:"justification"`
+}
+
+// String returns a short description of s for reports, i.e.
+// "us-core-6 at Patient.telecom".
+func (s Suppression) String() string {
+	switch {
+	case s.Path == "":
+		return s.Code
+	case s.Code == "":
+		return "issues at " + s.Path
+	}
+	return s.Code + " at " + s.Path
+}
+
+// Parse parses a file of suppressions in YAML or JSON.
+func Parse(data []byte) ([]Suppression, error) {
+	var f File
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing suppressions: %w", err)
+	}
+	for _, s := range f.Suppressions {
+		if err := s.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return f.Suppressions, nil
+}
+
+// Load reads the file of suppressions at path.
+func Load(path string) ([]Suppression, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ss, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ss, nil
+}
+
+func (s Suppression) validate() error {
+	if s.Code == "" && s.Path == "" {
+		return fmt.Errorf("suppression without code or path")
+	}
+	if s.Justification == "" {
+		return fmt.Errorf("suppression of %s has no justification", s)
+	}
+	if s.Path != "" {
+		for _, seg := range strings.Split(s.Path, ".") {
+			if seg == "" {
+				return fmt.Errorf("suppression of %s has invalid path", s)
+			}
+		}
+	}
+	if _, err := s.expiry(); err != nil {
+		return fmt.Errorf("suppression of %s has invalid expiry date %q", s, s.Expires)
+	}
+	return nil
+}
+
+// expiry returns the time s expires, the end of its Expires day, or the zero
+// time if it does not expire.
+func (s Suppression) expiry() (time.Time, error) {
+	if s.Expires == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(dateLayout, s.Expires)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.AddDate(0, 0, 1), nil
+}
+
+// Expired reports whether s no longer applies at now. Suppressions with
+// invalid expiry dates are expired.
+func (s Suppression) Expired(now time.Time) bool {
+	t, err := s.expiry()
+	return err != nil || !t.IsZero() && !now.Before(t)
+}
+
+// Matches reports whether s matches the issue i: the constraint of i is
+// Code, if set, and its path matches Path, if set. Path is a dot-separated
+// element path, where "*" matches any element name and a name without an
+// index, as in "Patient.name", matches every item of a repeated element,
+// i.e. "Patient.name[1]". The pattern also matches the paths of the elements
+// nested in those it matches.
+func (s Suppression) Matches(i revalidate.Issue) bool {
+	if s.Code != "" && s.Code != i.Constraint {
+		return false
+	}
+	if s.Path == "" {
+		return true
+	}
+	pattern, path := strings.Split(s.Path, "."), strings.Split(i.Path, ".")
+	if i.Path == "" || len(path) < len(pattern) {
+		return false
+	}
+	for j, p := range pattern {
+		name := path[j]
+		if !strings.Contains(p, "[") {
+			if k := strings.IndexByte(name, '['); k >= 0 {
+				name = name[:k]
+			}
+		}
+		if p != "*" && p != name {
+			return false
+		}
+	}
+	return true
+}
+
+// Active splits ss into the suppressions that apply at now and those that
+// have expired, which should be reported so that they are renewed or
+// removed.
+func Active(ss []Suppression, now time.Time) (active, expired []Suppression) {
+	for _, s := range ss {
+		if s.Expired(now) {
+			expired = append(expired, s)
+		} else {
+			active = append(active, s)
+		}
+	}
+	return active, expired
+}
+
+// Find returns the first of ss that matches the issue i.
+func Find(ss []Suppression, i revalidate.Issue) (Suppression, bool) {
+	for _, s := range ss {
+		if s.Matches(i) {
+			return s, true
+		}
+	}
+	return Suppression{}, false
+}
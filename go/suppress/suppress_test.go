@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suppress
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/jsonformat/errorreporter"
+	"github.com/google/fhir/go/revalidate"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testSuppressions = `
+suppressions:
+- code: us-core-6
+  path: Patient.telecom
+  expires: 2026-12-31
+  justification: Legacy feed sends phone numbers without a system
+- path: Observation.*.performer
+  justification: Device observations have no performer
+`
+
+func TestParse(t *testing.T) {
+	got, err := Parse([]byte(testSuppressions))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	want := []Suppression{
+		{Code: "us-core-6", Path: "Patient.telecom", Expires: "2026-12-31", Justification: "Legacy feed sends phone numbers without a system"},
+		{Path: "Observation.*.performer", Justification: "Device observations have no performer"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no code or path", "suppressions:\n- justification: x\n"},
+		{"no justification", "suppressions:\n- code: a\n"},
+		{"invalid path", "suppressions:\n- path: Patient..name\n  justification: x\n"},
+		{"invalid expiry", "suppressions:\n- code: a\n  expires: 31/12/2026\n  justification: x\n"},
+		{"unknown field", "suppressions:\n- code: a\n  reason: x\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tc.data)); err == nil {
+				t.Errorf("Parse() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestLoad_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppressions.json")
+	data := `{"suppressions": [{"code": "sp-1", "justification": "Known issue"}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned unexpected error: %v", err)
+	}
+	want := []Suppression{{Code: "sp-1", Justification: "Known issue"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Load() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	issue := func(key, path string) revalidate.Issue {
+		return revalidate.Issue{Constraint: key, Path: path, Severity: errorreporter.IssueSeverityError}
+	}
+	tests := []struct {
+		s    Suppression
+		i    revalidate.Issue
+		want bool
+	}{
+		{Suppression{Code: "us-core-6"}, issue("us-core-6", "Patient.telecom[0]"), true},
+		{Suppression{Code: "us-core-6"}, issue("us-core-7", "Patient.telecom[0]"), false},
+		{Suppression{Code: "us-core-6", Path: "Patient.telecom"}, issue("us-core-6", "Patient.telecom[2]"), true},
+		{Suppression{Code: "us-core-6", Path: "Patient.telecom"}, issue("us-core-6", "Patient.telecom[2].system"), true},
+		{Suppression{Path: "Patient.telecom[1]"}, issue("us-core-6", "Patient.telecom[2]"), false},
+		{Suppression{Path: "Patient.telecom[1]"}, issue("us-core-6", "Patient.telecom[1]"), true},
+		{Suppression{Path: "Patient.telecom"}, issue("us-core-6", "Patient.telecomSystem"), false},
+		{Suppression{Path: "Patient.telecom"}, issue("us-core-6", "Patient"), false},
+		{Suppression{Path: "Patient.telecom"}, issue("us-core-6", ""), false},
+		{Suppression{Path: "*.meta"}, issue("core", "Observation.meta.profile[0]"), true},
+	}
+	for _, tc := range tests {
+		if got := tc.s.Matches(tc.i); got != tc.want {
+			t.Errorf("%v.Matches(%v at %s) = %v, want %v", tc.s, tc.i.Constraint, tc.i.Path, got, tc.want)
+		}
+	}
+}
+
+func TestActive(t *testing.T) {
+	ss := []Suppression{
+		{Code: "a", Expires: "2026-06-30", Justification: "x"},
+		{Code: "b", Expires: "2026-07-01", Justification: "x"},
+		{Code: "c", Justification: "x"},
+	}
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	active, expired := Active(ss, now)
+	if diff := cmp.Diff(ss[1:], active); diff != "" {
+		t.Errorf("Active() active diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(ss[:1], expired); diff != "" {
+		t.Errorf("Active() expired diff (-want +got):\n%s", diff)
+	}
+	if s, ok := Find(active, revalidate.Issue{Constraint: "c"}); !ok || s.Code != "c" {
+		t.Errorf("Find() = %v, %v, want suppression of c", s, ok)
+	}
+	if _, ok := Find(active, revalidate.Issue{Constraint: "a"}); ok {
+		t.Errorf("Find() of an expired suppression succeeded")
+	}
+}
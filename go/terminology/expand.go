@@ -0,0 +1,232 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminology
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	valuesetpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+// ErrUnknownValueSet is returned for value sets Lookup does not know.
+var ErrUnknownValueSet = errors.New("unknown value set")
+
+// Designation is a designation of a code in an expansion.
+type Designation = valuesetpb.ValueSet_Compose_ConceptSet_ConceptReference_Designation
+
+// DisplayFunc returns the display of the code of system and its other
+// designations, which the R4 protos do not carry.
+type DisplayFunc func(system, code string) (string, []*Designation)
+
+// ExpandOptions are the parameters of an expansion.
+type ExpandOptions struct {
+	// Filter keeps the codes whose code or display contain it, ignoring
+	// case.
+	Filter string
+	// Offset is the number of codes to skip, and Count the number of codes
+	// to return after them; all are returned if Count is negative, and only
+	// the total if it is 0.
+	Offset, Count int
+	// IncludeDesignations includes the designations Display returns.
+	IncludeDesignations bool
+	// Display supplies the displays and designations of codes, if not nil.
+	Display DisplayFunc
+	// Now is the time of the expansion; the current time is used if it is
+	// zero.
+	Now time.Time
+}
+
+// Expand returns the core value set with the canonical url expanded as by
+// the ValueSet/$expand operation: its codes sorted by system and code,
+// filtered and paged by opts, with the total number of codes matching the
+// filter.
+func Expand(url string, opts ExpandOptions) (*valuesetpb.ValueSet, error) {
+	vs, ok := Lookup(url)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownValueSet, url)
+	}
+	if opts.Offset < 0 {
+		return nil, fmt.Errorf("negative offset %d", opts.Offset)
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	filter := strings.ToLower(opts.Filter)
+	var matched []*valuesetpb.ValueSet_Expansion_Contains
+	for _, c := range vs.Codings() {
+		system, code := c.GetSystem().GetValue(), c.GetCode().GetValue()
+		var display string
+		var designations []*Designation
+		if opts.Display != nil {
+			display, designations = opts.Display(system, code)
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(code), filter) && !strings.Contains(strings.ToLower(display), filter) {
+			continue
+		}
+		contains := &valuesetpb.ValueSet_Expansion_Contains{System: c.GetSystem(), Code: c.GetCode()}
+		if display != "" {
+			contains.Display = fhirtypes.String(display)
+		}
+		if opts.IncludeDesignations {
+			contains.Designation = designations
+		}
+		matched = append(matched, contains)
+	}
+
+	exp := &valuesetpb.ValueSet_Expansion{
+		Timestamp: fhirtypes.DateTimeFromTime(now, d4pb.DateTime_SECOND),
+		Total:     &d4pb.Integer{Value: int32(len(matched))},
+		Offset:    &d4pb.Integer{Value: int32(opts.Offset)},
+	}
+	addParam := func(name string, v *valuesetpb.ValueSet_Expansion_Parameter_ValueX) {
+		exp.Parameter = append(exp.Parameter, &valuesetpb.ValueSet_Expansion_Parameter{Name: fhirtypes.String(name), Value: v})
+	}
+	if opts.Filter != "" {
+		addParam("filter", &valuesetpb.ValueSet_Expansion_Parameter_ValueX{
+			Choice: &valuesetpb.ValueSet_Expansion_Parameter_ValueX_StringValue{StringValue: fhirtypes.String(opts.Filter)},
+		})
+	}
+	if opts.Count >= 0 {
+		addParam("count", &valuesetpb.ValueSet_Expansion_Parameter_ValueX{
+			Choice: &valuesetpb.ValueSet_Expansion_Parameter_ValueX_Integer{Integer: &d4pb.Integer{Value: int32(opts.Count)}},
+		})
+	}
+	if opts.IncludeDesignations {
+		addParam("includeDesignations", &valuesetpb.ValueSet_Expansion_Parameter_ValueX{
+			Choice: &valuesetpb.ValueSet_Expansion_Parameter_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: true}},
+		})
+	}
+	if opts.Offset < len(matched) {
+		page := matched[opts.Offset:]
+		if opts.Count >= 0 && opts.Count < len(page) {
+			page = page[:opts.Count]
+		}
+		exp.Contains = page
+	}
+	return &valuesetpb.ValueSet{
+		Url:       &d4pb.Uri{Value: vs.URL},
+		Status:    &valuesetpb.ValueSet_StatusCode{Value: c4pb.PublicationStatusCode_ACTIVE},
+		Expansion: exp,
+	}, nil
+}
+
+// ExpandHandler is an http.Handler of the ValueSet/$expand operation over
+// the core value sets, for a terminology endpoint: GET requests take the
+// parameters url, filter, offset, count and includeDesignations from their
+// query, and POST requests from a Parameters resource. Requests to
+// ValueSet/[id]/$expand expand the value set whose url ends in /id. The
+// expansion is written as FHIR JSON, and errors as OperationOutcomes.
+type ExpandHandler struct {
+	// Display supplies the displays and designations of codes, if not nil.
+	Display DisplayFunc
+	// MaxCount bounds the number of codes of a response, and is the count
+	// of requests without one. Responses are not bounded if it is 0.
+	MaxCount int
+}
+
+// ServeHTTP implements http.Handler.
+func (h *ExpandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := readRequest(w, r, "ValueSet", "$expand")
+	if req == nil {
+		return
+	}
+	params, id := req.params, req.id
+	vsURL := params.Get("url")
+	if id != "" {
+		if vsURL = valueSetURL(id); vsURL == "" {
+			writeOutcome(w, http.StatusNotFound, c4pb.IssueTypeCode_NOT_FOUND, fmt.Sprintf("unknown ValueSet/%s", id))
+			return
+		}
+	}
+	if vsURL == "" {
+		writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_REQUIRED, "no url parameter")
+		return
+	}
+	opts := ExpandOptions{Filter: params.Get("filter"), Count: -1, Display: h.Display}
+	if h.MaxCount > 0 {
+		opts.Count = h.MaxCount
+	}
+	for _, p := range []struct {
+		name string
+		v    *int
+	}{{"offset", &opts.Offset}, {"count", &opts.Count}} {
+		s := params.Get(p.name)
+		if s == "" {
+			continue
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_INVALID, fmt.Sprintf("invalid %s %q", p.name, s))
+			return
+		}
+		*p.v = v
+	}
+	if h.MaxCount > 0 && opts.Count > h.MaxCount {
+		opts.Count = h.MaxCount
+	}
+	if s := params.Get("includeDesignations"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_INVALID, fmt.Sprintf("invalid includeDesignations %q", s))
+			return
+		}
+		opts.IncludeDesignations = b
+	}
+
+	vs, err := Expand(vsURL, opts)
+	if errors.Is(err, ErrUnknownValueSet) {
+		writeOutcome(w, http.StatusNotFound, c4pb.IssueTypeCode_NOT_FOUND, err.Error())
+		return
+	}
+	if err != nil {
+		writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_INVALID, err.Error())
+		return
+	}
+	if id != "" {
+		vs.Id = fhirtypes.ID(id)
+	}
+	writeResource(w, http.StatusOK, vs)
+}
+
+// valueSetURL and codeSystemURL return the url of the core value set or
+// code system whose last path segment is id, or "" if there is none.
+func valueSetURL(id string) string {
+	once.Do(index)
+	return urlOfID(valueSets, id)
+}
+
+func codeSystemURL(id string) string {
+	once.Do(index)
+	return urlOfID(codeSystems, id)
+}
+
+func urlOfID(sets map[string]*ValueSet, id string) string {
+	for u := range sets {
+		if strings.HasSuffix(u, "/"+id) {
+			return u
+		}
+	}
+	return ""
+}
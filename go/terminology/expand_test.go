@@ -0,0 +1,214 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminology
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	valuesetpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/value_set_go_proto"
+)
+
+const (
+	statusVS     = "http://hl7.org/fhir/ValueSet/observation-status"
+	statusSystem = "http://hl7.org/fhir/observation-status"
+)
+
+func statusDisplay(system, code string) (string, []*Designation) {
+	if code != "final" {
+		return "", nil
+	}
+	return "Final", []*Designation{{Language: &d4pb.Code{Value: "de"}, Value: &d4pb.String{Value: "Endgültig"}}}
+}
+
+func codesOf(vs *valuesetpb.ValueSet) []string {
+	var out []string
+	for _, c := range vs.GetExpansion().GetContains() {
+		out = append(out, c.GetCode().GetValue())
+	}
+	return out
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      ExpandOptions
+		wantCodes []string
+		wantTotal int32
+	}{
+		{"all", ExpandOptions{Count: -1}, []string{"amended", "cancelled", "corrected", "entered-in-error", "final", "preliminary", "registered", "unknown"}, 8},
+		{"paged", ExpandOptions{Offset: 2, Count: 3}, []string{"corrected", "entered-in-error", "final"}, 8},
+		{"past end", ExpandOptions{Offset: 8, Count: 3}, nil, 8},
+		{"total only", ExpandOptions{Count: 0}, nil, 8},
+		{"filter code", ExpandOptions{Filter: "ER", Count: -1}, []string{"entered-in-error", "registered"}, 2},
+		{"filter display", ExpandOptions{Filter: "fin", Count: -1, Display: statusDisplay}, []string{"final"}, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vs, err := Expand(statusVS, tc.opts)
+			if err != nil {
+				t.Fatalf("Expand() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantCodes, codesOf(vs)); diff != "" {
+				t.Errorf("Expand() codes diff (-want +got):\n%s", diff)
+			}
+			if got := vs.GetExpansion().GetTotal().GetValue(); got != tc.wantTotal {
+				t.Errorf("Expand() total = %d, want %d", got, tc.wantTotal)
+			}
+		})
+	}
+}
+
+func TestExpand_Designations(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	vs, err := Expand(statusVS, ExpandOptions{Filter: "final", Count: 10, IncludeDesignations: true, Display: statusDisplay, Now: now})
+	if err != nil {
+		t.Fatalf("Expand() returned unexpected error: %v", err)
+	}
+	_, designations := statusDisplay(statusSystem, "final")
+	want := &valuesetpb.ValueSet_Expansion{
+		Timestamp: &d4pb.DateTime{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND},
+		Total:     &d4pb.Integer{Value: 1},
+		Offset:    &d4pb.Integer{},
+		Parameter: []*valuesetpb.ValueSet_Expansion_Parameter{
+			{Name: &d4pb.String{Value: "filter"}, Value: &valuesetpb.ValueSet_Expansion_Parameter_ValueX{Choice: &valuesetpb.ValueSet_Expansion_Parameter_ValueX_StringValue{StringValue: &d4pb.String{Value: "final"}}}},
+			{Name: &d4pb.String{Value: "count"}, Value: &valuesetpb.ValueSet_Expansion_Parameter_ValueX{Choice: &valuesetpb.ValueSet_Expansion_Parameter_ValueX_Integer{Integer: &d4pb.Integer{Value: 10}}}},
+			{Name: &d4pb.String{Value: "includeDesignations"}, Value: &valuesetpb.ValueSet_Expansion_Parameter_ValueX{Choice: &valuesetpb.ValueSet_Expansion_Parameter_ValueX_Boolean{Boolean: &d4pb.Boolean{Value: true}}}},
+		},
+		Contains: []*valuesetpb.ValueSet_Expansion_Contains{{
+			System:      &d4pb.Uri{Value: statusSystem},
+			Code:        &d4pb.Code{Value: "final"},
+			Display:     &d4pb.String{Value: "Final"},
+			Designation: designations,
+		}},
+	}
+	if diff := cmp.Diff(want, vs.GetExpansion(), protocmp.Transform()); diff != "" {
+		t.Errorf("Expand() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestExpand_Errors(t *testing.T) {
+	if _, err := Expand("http://example.com/ValueSet/unknown", ExpandOptions{}); !errors.Is(err, ErrUnknownValueSet) {
+		t.Errorf("Expand() returned error %v, want %v", err, ErrUnknownValueSet)
+	}
+	if _, err := Expand(statusVS, ExpandOptions{Offset: -1}); err == nil {
+		t.Errorf("Expand() with negative offset succeeded, want error")
+	}
+}
+
+func TestExpandHandler(t *testing.T) {
+	h := &ExpandHandler{Display: statusDisplay, MaxCount: 5}
+	tests := []struct {
+		name, method, target, body string
+		wantStatus                 int
+		wantContains               []string
+	}{
+		{
+			name:         "get by url",
+			method:       http.MethodGet,
+			target:       "/fhir/ValueSet/$expand?url=" + statusVS + "&filter=fin&includeDesignations=true",
+			wantStatus:   http.StatusOK,
+			wantContains: []string{`"code":"final"`, `"display":"Final"`, `"designation"`},
+		},
+		{
+			name:         "get by id",
+			method:       http.MethodGet,
+			target:       "/ValueSet/observation-status/$expand?offset=1&count=1",
+			wantStatus:   http.StatusOK,
+			wantContains: []string{`"id":"observation-status"`, `"code":"cancelled"`, `"total":8`},
+		},
+		{
+			name:         "count bounded",
+			method:       http.MethodGet,
+			target:       "/ValueSet/observation-status/$expand?count=100",
+			wantStatus:   http.StatusOK,
+			wantContains: []string{`"code":"final"`, `"valueInteger":5`},
+		},
+		{
+			name:   "post parameters",
+			method: http.MethodPost,
+			target: "/ValueSet/$expand",
+			body: `{"resourceType":"Parameters","parameter":[` +
+				`{"name":"url","valueUri":"` + statusVS + `"},` +
+				`{"name":"filter","valueString":"amend"}]}`,
+			wantStatus:   http.StatusOK,
+			wantContains: []string{`"code":"amended"`, `"total":1`},
+		},
+		{
+			name:         "unknown value set",
+			method:       http.MethodGet,
+			target:       "/ValueSet/$expand?url=http://example.com/ValueSet/unknown",
+			wantStatus:   http.StatusNotFound,
+			wantContains: []string{`"resourceType":"OperationOutcome"`, `"not-found"`},
+		},
+		{
+			name:         "unknown id",
+			method:       http.MethodGet,
+			target:       "/ValueSet/unknown/$expand",
+			wantStatus:   http.StatusNotFound,
+			wantContains: []string{`"not-found"`},
+		},
+		{
+			name:         "no url",
+			method:       http.MethodGet,
+			target:       "/ValueSet/$expand",
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{`"required"`},
+		},
+		{
+			name:         "invalid count",
+			method:       http.MethodGet,
+			target:       "/ValueSet/$expand?url=" + statusVS + "&count=-1",
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{`"invalid"`},
+		},
+		{
+			name:         "not parameters",
+			method:       http.MethodPost,
+			target:       "/ValueSet/$expand",
+			body:         `{"resourceType":"Patient"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: []string{`"invalid"`},
+		},
+		{
+			name:       "method",
+			method:     http.MethodDelete,
+			target:     "/ValueSet/$expand",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body)))
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("ServeHTTP() status = %d, want %d; body %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			for _, s := range tc.wantContains {
+				if !strings.Contains(rec.Body.String(), s) {
+					t.Errorf("ServeHTTP() body %s does not contain %s", rec.Body, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminology
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	oopb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/operation_outcome_go_proto"
+	parameterspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+)
+
+var (
+	marshaller   *jsonformat.Marshaller
+	unmarshaller *jsonformat.Unmarshaller
+)
+
+func init() {
+	var err error
+	if marshaller, err = jsonformat.NewMarshaller(false, "", "", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("terminology: creating marshaller: %v", err))
+	}
+	if unmarshaller, err = jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4); err != nil {
+		panic(fmt.Sprintf("terminology: creating unmarshaller: %v", err))
+	}
+}
+
+// maxRequest bounds the size of the Parameters of POST requests.
+const maxRequest = 1 << 20
+
+// request is an operation request.
+type request struct {
+	// id is the id of the resource of an instance level request, as in
+	// ValueSet/[id]/$expand, and "" for a type level one.
+	id string
+	// params are the primitive parameters, as strings.
+	params url.Values
+	// codings are the codings of the coding and codeableConcept parameters.
+	codings []*d4pb.Coding
+}
+
+// readRequest returns the request r of the operation op on the resources of
+// typ, taking its parameters from the query of GET requests and the
+// Parameters resource of POST requests. It writes an OperationOutcome and
+// returns nil if r is not such a request.
+func readRequest(w http.ResponseWriter, r *http.Request, typ, op string) *request {
+	segs := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	n := len(segs)
+	req := &request{}
+	switch {
+	case n >= 2 && segs[n-2] == typ && segs[n-1] == op:
+	case n >= 3 && segs[n-3] == typ && segs[n-1] == op:
+		req.id = segs[n-2]
+	default:
+		writeOutcome(w, http.StatusNotFound, c4pb.IssueTypeCode_NOT_FOUND, fmt.Sprintf("not a %s/%s request", typ, op))
+		return nil
+	}
+	switch r.Method {
+	case http.MethodGet:
+		req.params = r.URL.Query()
+	case http.MethodPost:
+		if err := req.readParameters(r.Body); err != nil {
+			writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_INVALID, err.Error())
+			return nil
+		}
+	default:
+		writeOutcome(w, http.StatusMethodNotAllowed, c4pb.IssueTypeCode_NOT_SUPPORTED, op+" requires GET or POST")
+		return nil
+	}
+	return req
+}
+
+// readParameters reads the parameters of req from the Parameters resource
+// read from body.
+func (req *request) readParameters(body io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(body, maxRequest))
+	if err != nil {
+		return err
+	}
+	res, err := unmarshaller.Unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("invalid Parameters: %w", err)
+	}
+	p, ok := elementpath.Unwrap(res).(*parameterspb.Parameters)
+	if !ok {
+		return fmt.Errorf("got a %s, want Parameters", elementpath.ResourceType(res))
+	}
+	req.params = url.Values{}
+	for _, pp := range p.GetParameter() {
+		name := pp.GetName().GetValue()
+		switch v := pp.GetValue().GetChoice().(type) {
+		case *parameterspb.Parameters_Parameter_ValueX_Uri:
+			req.params.Add(name, v.Uri.GetValue())
+		case *parameterspb.Parameters_Parameter_ValueX_Canonical:
+			req.params.Add(name, v.Canonical.GetValue())
+		case *parameterspb.Parameters_Parameter_ValueX_StringValue:
+			req.params.Add(name, v.StringValue.GetValue())
+		case *parameterspb.Parameters_Parameter_ValueX_Code:
+			req.params.Add(name, v.Code.GetValue())
+		case *parameterspb.Parameters_Parameter_ValueX_Integer:
+			req.params.Add(name, strconv.Itoa(int(v.Integer.GetValue())))
+		case *parameterspb.Parameters_Parameter_ValueX_Boolean:
+			req.params.Add(name, strconv.FormatBool(v.Boolean.GetValue()))
+		case *parameterspb.Parameters_Parameter_ValueX_Coding:
+			req.codings = append(req.codings, v.Coding)
+		case *parameterspb.Parameters_Parameter_ValueX_CodeableConcept:
+			req.codings = append(req.codings, v.CodeableConcept.GetCoding()...)
+		default:
+			if pp.GetResource() != nil {
+				return fmt.Errorf("resource parameter %q is not supported", name)
+			}
+		}
+	}
+	return nil
+}
+
+// coding returns the coding of the code, system and display parameters of
+// req, or its first coding parameter if it has no code parameter.
+func (req *request) coding() *d4pb.Coding {
+	if code := req.params.Get("code"); code != "" {
+		c := &d4pb.Coding{Code: fhirtypes.Code(code)}
+		if s := req.params.Get("system"); s != "" {
+			c.System = fhirtypes.URI(s)
+		}
+		if d := req.params.Get("display"); d != "" {
+			c.Display = fhirtypes.String(d)
+		}
+		return c
+	}
+	if len(req.codings) > 0 {
+		return req.codings[0]
+	}
+	return nil
+}
+
+// parameter returns the parameter name with value v, one of the choices of
+// Parameters.parameter.value.
+func parameter(name string, v proto.Message) *parameterspb.Parameters_Parameter {
+	p := &parameterspb.Parameters_Parameter{Name: fhirtypes.String(name), Value: &parameterspb.Parameters_Parameter_ValueX{}}
+	switch v := v.(type) {
+	case *d4pb.Boolean:
+		p.Value.Choice = &parameterspb.Parameters_Parameter_ValueX_Boolean{Boolean: v}
+	case *d4pb.String:
+		p.Value.Choice = &parameterspb.Parameters_Parameter_ValueX_StringValue{StringValue: v}
+	case *d4pb.Code:
+		p.Value.Choice = &parameterspb.Parameters_Parameter_ValueX_Code{Code: v}
+	case *d4pb.Uri:
+		p.Value.Choice = &parameterspb.Parameters_Parameter_ValueX_Uri{Uri: v}
+	case *d4pb.Coding:
+		p.Value.Choice = &parameterspb.Parameters_Parameter_ValueX_Coding{Coding: v}
+	default:
+		panic(fmt.Sprintf("unsupported parameter type %T", v))
+	}
+	return p
+}
+
+// writeResource writes res as FHIR JSON.
+func writeResource(w http.ResponseWriter, status int, res proto.Message) {
+	out, err := marshaller.MarshalResource(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/fhir+json")
+	w.WriteHeader(status)
+	w.Write(out)
+}
+
+// writeOutcome writes an OperationOutcome with an error of type code.
+func writeOutcome(w http.ResponseWriter, status int, code c4pb.IssueTypeCode_Value, msg string) {
+	writeResource(w, status, &oopb.OperationOutcome{Issue: []*oopb.OperationOutcome_Issue{{
+		Severity:    &oopb.OperationOutcome_Issue_SeverityCode{Value: c4pb.IssueSeverityCode_ERROR},
+		Code:        &oopb.OperationOutcome_Issue_CodeType{Value: code},
+		Diagnostics: fhirtypes.String(msg),
+	}}})
+}
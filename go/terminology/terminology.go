@@ -0,0 +1,227 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package terminology checks codes against the value sets and code systems
+// of the FHIR R4 core specification without a terminology server or
+// implementation guide packages.
+//
+// The codes come from the R4 protos themselves: the enums generated for
+// coded elements with required bindings, and for the value sets they draw
+// from, are annotated with the canonical URLs of their value set and code
+// system. The descriptors compiled into every binary that links the
+// resource protos are therefore a complete, compact copy of those value sets,
+// indexed here on first use:
+//
+//	vs, ok := terminology.Lookup("http://hl7.org/fhir/ValueSet/observation-status")
+//	if ok && !vs.Contains("http://hl7.org/fhir/observation-status", "final") {
+//		...
+//	}
+//
+// Value sets bound with weaker strengths, or defined by filters over large
+// external code systems such as SNOMED CT and LOINC, are not available.
+//
+// Expand expands a value set as by the ValueSet/$expand operation. For a
+// lightweight terminology endpoint, ExpandHandler serves that operation over
+// HTTP, ValidateCodeHandler serves $validate-code over the same value sets
+// and code systems, and TranslateHandler serves ConceptMap/$translate over a
+// conceptmap.Translator.
+package terminology
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/fhir/go/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	apb "github.com/google/fhir/go/proto/google/fhir/proto/annotations_go_proto"
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	_ "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	vspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/valuesets_go_proto"
+)
+
+// ValueSet is the expansion of a value set: the codes it contains, by code
+// system.
+type ValueSet struct {
+	// URL is the canonical URL of the value set, without a version.
+	URL string
+	// codes are the codes of the value set by the URL of their system.
+	codes map[string]map[string]bool
+}
+
+// Contains reports whether vs contains code in system. An empty system
+// matches every system, as for code elements, whose system is implied by
+// their binding.
+func (vs *ValueSet) Contains(system, code string) bool {
+	if system != "" {
+		return vs.codes[system][code]
+	}
+	for _, cs := range vs.codes {
+		if cs[code] {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsCoding reports whether vs contains the code of c in its system.
+func (vs *ValueSet) ContainsCoding(c *d4pb.Coding) bool {
+	system := c.GetSystem().GetValue()
+	return system != "" && vs.Contains(system, c.GetCode().GetValue())
+}
+
+// ContainsConcept reports whether vs contains a coding of cc.
+func (vs *ValueSet) ContainsConcept(cc *d4pb.CodeableConcept) bool {
+	for _, c := range cc.GetCoding() {
+		if vs.ContainsCoding(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Codings returns the codes of vs, sorted by system and code.
+func (vs *ValueSet) Codings() []*d4pb.Coding {
+	var out []*d4pb.Coding
+	for system, cs := range vs.codes {
+		for code := range cs {
+			out = append(out, &d4pb.Coding{System: &d4pb.Uri{Value: system}, Code: &d4pb.Code{Value: code}})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.GetSystem().GetValue() != b.GetSystem().GetValue() {
+			return a.GetSystem().GetValue() < b.GetSystem().GetValue()
+		}
+		return a.GetCode().GetValue() < b.GetCode().GetValue()
+	})
+	return out
+}
+
+var (
+	once        sync.Once
+	valueSets   map[string]*ValueSet
+	codeSystems map[string]*ValueSet
+)
+
+// Lookup returns the core value set with the canonical url, which may carry
+// a "|version" suffix that is ignored.
+func Lookup(url string) (*ValueSet, bool) {
+	once.Do(index)
+	vs, ok := valueSets[stripVersion(url)]
+	return vs, ok
+}
+
+// CodeSystem returns all codes of the core code system with the canonical
+// url, as a value set of that url.
+func CodeSystem(url string) (*ValueSet, bool) {
+	once.Do(index)
+	cs, ok := codeSystems[stripVersion(url)]
+	return cs, ok
+}
+
+// ValueSetURLs returns the canonical URLs of the value sets Lookup knows,
+// sorted.
+func ValueSetURLs() []string {
+	once.Do(index)
+	var urls []string
+	for url := range valueSets {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func stripVersion(url string) string {
+	if i := strings.LastIndexByte(url, '|'); i >= 0 {
+		return url[:i]
+	}
+	return url
+}
+
+// index builds the value sets and code systems from the descriptors of the
+// R4 protos: the enums of the codes and value set protos, and the messages of
+// bound code elements, whose fhir_valueset_url names the value set of the
+// enum of their value field.
+func index() {
+	valueSets, codeSystems = map[string]*ValueSet{}, map[string]*ValueSet{}
+	for _, fd := range []protoreflect.FileDescriptor{
+		c4pb.File_proto_google_fhir_proto_r4_core_codes_proto,
+		vspb.File_proto_google_fhir_proto_r4_core_valuesets_proto,
+	} {
+		msgs := fd.Messages()
+		for i := 0; i < msgs.Len(); i++ {
+			ed := msgs.Get(i).Enums().ByName("Value")
+			if ed == nil {
+				continue
+			}
+			if url := stringOption(ed.Options(), apb.E_FhirCodeSystemUrl); url != "" {
+				add(codeSystems, url, ed)
+			}
+			if url := stringOption(ed.Options(), apb.E_EnumValuesetUrl); url != "" {
+				add(valueSets, url, ed)
+			}
+		}
+	}
+	var visit func(protoreflect.MessageDescriptors)
+	visit = func(msgs protoreflect.MessageDescriptors) {
+		for i := 0; i < msgs.Len(); i++ {
+			md := msgs.Get(i)
+			if url := stringOption(md.Options(), apb.E_FhirValuesetUrl); url != "" {
+				if f := md.Fields().ByName("value"); f != nil && f.Enum() != nil {
+					add(valueSets, url, f.Enum())
+				}
+			}
+			visit(md.Messages())
+		}
+	}
+	protoregistry.GlobalFiles.RangeFilesByPackage("google.fhir.r4.core", func(fd protoreflect.FileDescriptor) bool {
+		visit(fd.Messages())
+		return true
+	})
+}
+
+// add adds the codes of the enum ed to the value set url of sets.
+func add(sets map[string]*ValueSet, url string, ed protoreflect.EnumDescriptor) {
+	vs, ok := sets[url]
+	if !ok {
+		vs = &ValueSet{URL: url, codes: map[string]map[string]bool{}}
+		sets[url] = vs
+	}
+	defaultSystem := stringOption(ed.Options(), apb.E_FhirCodeSystemUrl)
+	values := ed.Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		if v.Number() == 0 {
+			continue
+		}
+		system := stringOption(v.Options(), apb.E_SourceCodeSystem)
+		if system == "" {
+			system = defaultSystem
+		}
+		if vs.codes[system] == nil {
+			vs.codes[system] = map[string]bool{}
+		}
+		vs.codes[system][codes.ValueCode(v)] = true
+	}
+}
+
+func stringOption(opts proto.Message, xt protoreflect.ExtensionType) string {
+	s, _ := proto.GetExtension(opts, xt).(string)
+	return s
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminology
+
+import (
+	"testing"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+func coding(system, code string) *d4pb.Coding {
+	return &d4pb.Coding{System: &d4pb.Uri{Value: system}, Code: &d4pb.Code{Value: code}}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		url, system, code string
+		want              bool
+	}{
+		{"http://hl7.org/fhir/ValueSet/observation-status", "http://hl7.org/fhir/observation-status", "final", true},
+		{"http://hl7.org/fhir/ValueSet/observation-status|4.0.1", "http://hl7.org/fhir/observation-status", "amended", true},
+		{"http://hl7.org/fhir/ValueSet/observation-status", "", "final", true},
+		{"http://hl7.org/fhir/ValueSet/observation-status", "http://hl7.org/fhir/observation-status", "FINAL", false},
+		{"http://hl7.org/fhir/ValueSet/observation-status", "http://hl7.org/fhir/administrative-gender", "final", false},
+		{"http://hl7.org/fhir/ValueSet/units-of-time", "http://unitsofmeasure.org", "wk", true},
+		{"http://hl7.org/fhir/ValueSet/units-of-time", "http://unitsofmeasure.org", "week", false},
+	}
+	for _, tc := range tests {
+		vs, ok := Lookup(tc.url)
+		if !ok {
+			t.Errorf("Lookup(%q) found no value set", tc.url)
+			continue
+		}
+		if got := vs.Contains(tc.system, tc.code); got != tc.want {
+			t.Errorf("Lookup(%q).Contains(%q, %q) = %v, want %v", tc.url, tc.system, tc.code, got, tc.want)
+		}
+	}
+	if _, ok := Lookup("http://example.org/ValueSet/unknown"); ok {
+		t.Errorf("Lookup(%q) found a value set, want none", "http://example.org/ValueSet/unknown")
+	}
+}
+
+func TestContainsConcept(t *testing.T) {
+	vs, ok := Lookup("http://hl7.org/fhir/ValueSet/administrative-gender")
+	if !ok {
+		t.Fatalf("Lookup(%q) found no value set", "http://hl7.org/fhir/ValueSet/administrative-gender")
+	}
+	if vs.ContainsCoding(&d4pb.Coding{Code: &d4pb.Code{Value: "female"}}) {
+		t.Errorf("ContainsCoding() of a coding without a system = true, want false")
+	}
+	tests := []struct {
+		name string
+		cc   *d4pb.CodeableConcept
+		want bool
+	}{
+		{
+			name: "second coding",
+			cc: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{
+				coding("http://terminology.hl7.org/CodeSystem/v2-0001", "F"),
+				coding("http://hl7.org/fhir/administrative-gender", "female"),
+			}},
+			want: true,
+		},
+		{
+			name: "no coding in value set",
+			cc: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{
+				coding("http://terminology.hl7.org/CodeSystem/v2-0001", "F"),
+			}},
+		},
+		{
+			name: "text only",
+			cc:   &d4pb.CodeableConcept{Text: &d4pb.String{Value: "female"}},
+		},
+	}
+	for _, tc := range tests {
+		if got := vs.ContainsConcept(tc.cc); got != tc.want {
+			t.Errorf("ContainsConcept() for %s = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCodeSystem(t *testing.T) {
+	cs, ok := CodeSystem("http://hl7.org/fhir/administrative-gender")
+	if !ok {
+		t.Fatalf("CodeSystem(%q) found no code system", "http://hl7.org/fhir/administrative-gender")
+	}
+	var got []string
+	for _, c := range cs.Codings() {
+		got = append(got, c.GetCode().GetValue())
+	}
+	want := []string{"female", "male", "other", "unknown"}
+	if len(got) != len(want) {
+		t.Fatalf("CodeSystem(%q).Codings() = %v, want %v", cs.URL, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CodeSystem(%q).Codings() = %v, want %v", cs.URL, got, want)
+			break
+		}
+	}
+}
+
+func TestValueSetURLs(t *testing.T) {
+	urls := ValueSetURLs()
+	found := false
+	for _, u := range urls {
+		if u == "http://hl7.org/fhir/ValueSet/observation-status" {
+			found = true
+		}
+		if vs, ok := Lookup(u); !ok || vs.URL != u {
+			t.Errorf("Lookup(%q) of a URL from ValueSetURLs() failed", u)
+		}
+	}
+	if !found {
+		t.Errorf("ValueSetURLs() does not include %q", "http://hl7.org/fhir/ValueSet/observation-status")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminology
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/fhir/go/codes"
+	"github.com/google/fhir/go/conceptmap"
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	parameterspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+)
+
+// TranslateHandler is an http.Handler of the ConceptMap/$translate
+// operation over the ConceptMaps of a conceptmap.Translator. The ConceptMap
+// is given by the url parameter, and the code by the code and system
+// parameters, or by a coding or codeableConcept parameter in POST requests,
+// all of whose codings are translated. Matches can be restricted to the
+// target system. The result is written as a Parameters resource with the
+// result and message parameters and a match parameter, with equivalence,
+// concept and source parts, for each translation.
+type TranslateHandler struct {
+	Translator *conceptmap.Translator
+}
+
+// ServeHTTP implements http.Handler.
+func (h *TranslateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := readRequest(w, r, "ConceptMap", "$translate")
+	if req == nil {
+		return
+	}
+	u := req.params.Get("url")
+	switch {
+	case req.id != "":
+		writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_NOT_SUPPORTED, "ConceptMaps are identified by the url parameter")
+		return
+	case req.params.Get("reverse") == "true":
+		writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_NOT_SUPPORTED, "reverse translation is not supported")
+		return
+	case u == "":
+		writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_REQUIRED, "no url parameter")
+		return
+	case !h.Translator.Has(u):
+		writeOutcome(w, http.StatusNotFound, c4pb.IssueTypeCode_NOT_FOUND, fmt.Sprintf("unknown ConceptMap %q", u))
+		return
+	}
+	codings := req.codings
+	if req.params.Get("code") != "" {
+		codings = []*d4pb.Coding{req.coding()}
+	}
+	if len(codings) == 0 {
+		writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_REQUIRED, "no code, coding or codeableConcept parameter")
+		return
+	}
+	target := req.params.Get("target")
+	var matches []*parameterspb.Parameters_Parameter
+	for _, c := range codings {
+		found, err := h.Translator.Translate(u, c.GetSystem().GetValue(), c.GetCode().GetValue())
+		if err != nil {
+			writeOutcome(w, http.StatusInternalServerError, c4pb.IssueTypeCode_EXCEPTION, err.Error())
+			return
+		}
+		for _, m := range found {
+			if target != "" && m.System != target {
+				continue
+			}
+			matches = append(matches, matchParameter(m))
+		}
+	}
+	out := []*parameterspb.Parameters_Parameter{parameter("result", &d4pb.Boolean{Value: len(matches) > 0})}
+	if len(matches) == 0 {
+		out = append(out, parameter("message", fhirtypes.String(fmt.Sprintf("no translation in ConceptMap %s", u))))
+	}
+	writeResource(w, http.StatusOK, &parameterspb.Parameters{Parameter: append(out, matches...)})
+}
+
+// matchParameter returns the match parameter of m.
+func matchParameter(m conceptmap.Match) *parameterspb.Parameters_Parameter {
+	concept := &d4pb.Coding{Code: fhirtypes.Code(m.Code)}
+	if m.System != "" {
+		concept.System = fhirtypes.URI(m.System)
+	}
+	if m.Version != "" {
+		concept.Version = fhirtypes.String(m.Version)
+	}
+	if m.Display != "" {
+		concept.Display = fhirtypes.String(m.Display)
+	}
+	return &parameterspb.Parameters_Parameter{
+		Name: fhirtypes.String("match"),
+		Part: []*parameterspb.Parameters_Parameter{
+			parameter("equivalence", fhirtypes.Code(codes.Code(m.Equivalence))),
+			parameter("concept", concept),
+			parameter("source", fhirtypes.URI(m.ConceptMap)),
+		},
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminology
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/fhir/go/conceptmap"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cmpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/concept_map_go_proto"
+	parameterspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+)
+
+const (
+	labMap      = "http://example.com/ConceptMap/lab"
+	labSystem   = "http://example.com/lab-codes"
+	loincSystem = "http://loinc.org"
+)
+
+func labTranslator(t *testing.T) *conceptmap.Translator {
+	t.Helper()
+	target := func(code, display string, eq c4pb.ConceptMapEquivalenceCode_Value) *cmpb.ConceptMap_Group_SourceElement_TargetElement {
+		return &cmpb.ConceptMap_Group_SourceElement_TargetElement{
+			Code:        &d4pb.Code{Value: code},
+			Display:     &d4pb.String{Value: display},
+			Equivalence: &cmpb.ConceptMap_Group_SourceElement_TargetElement_EquivalenceCode{Value: eq},
+		}
+	}
+	tr, err := conceptmap.NewTranslator(&cmpb.ConceptMap{
+		Url: &d4pb.Uri{Value: labMap},
+		Group: []*cmpb.ConceptMap_Group{{
+			Source: &d4pb.Uri{Value: labSystem},
+			Target: &d4pb.Uri{Value: loincSystem},
+			Element: []*cmpb.ConceptMap_Group_SourceElement{
+				{Code: &d4pb.Code{Value: "GLU"}, Target: []*cmpb.ConceptMap_Group_SourceElement_TargetElement{
+					target("2345-7", "Glucose", c4pb.ConceptMapEquivalenceCode_EQUIVALENT),
+				}},
+				{Code: &d4pb.Code{Value: "NA"}, Target: []*cmpb.ConceptMap_Group_SourceElement_TargetElement{
+					target("2951-2", "Sodium", c4pb.ConceptMapEquivalenceCode_EQUIVALENT),
+					target("2947-0", "Sodium, blood", c4pb.ConceptMapEquivalenceCode_WIDER),
+				}},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewTranslator() returned unexpected error: %v", err)
+	}
+	return tr
+}
+
+func match(eq, code, display string) *parameterspb.Parameters_Parameter {
+	return &parameterspb.Parameters_Parameter{
+		Name: &d4pb.String{Value: "match"},
+		Part: []*parameterspb.Parameters_Parameter{
+			parameter("equivalence", &d4pb.Code{Value: eq}),
+			parameter("concept", &d4pb.Coding{
+				System:  &d4pb.Uri{Value: loincSystem},
+				Code:    &d4pb.Code{Value: code},
+				Display: &d4pb.String{Value: display},
+			}),
+			parameter("source", &d4pb.Uri{Value: labMap}),
+		},
+	}
+}
+
+func TestTranslateHandler(t *testing.T) {
+	h := &TranslateHandler{Translator: labTranslator(t)}
+	tests := []struct {
+		name, method, target, body string
+		want                       *parameterspb.Parameters
+	}{
+		{
+			name:   "matches",
+			method: http.MethodGet,
+			target: "/ConceptMap/$translate?url=" + labMap + "&system=" + labSystem + "&code=NA",
+			want: &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{
+				boolParam("result", true),
+				match("equivalent", "2951-2", "Sodium"),
+				match("wider", "2947-0", "Sodium, blood"),
+			}},
+		},
+		{
+			name:   "unmapped",
+			method: http.MethodGet,
+			target: "/ConceptMap/$translate?url=" + labMap + "&code=K",
+			want: &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{
+				boolParam("result", false),
+				stringParam("message", "no translation in ConceptMap "+labMap),
+			}},
+		},
+		{
+			name:   "other target",
+			method: http.MethodGet,
+			target: "/ConceptMap/$translate?url=" + labMap + "&code=GLU&target=http://snomed.info/sct",
+			want: &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{
+				boolParam("result", false),
+				stringParam("message", "no translation in ConceptMap "+labMap),
+			}},
+		},
+		{
+			name:   "coding",
+			method: http.MethodPost,
+			target: "/fhir/ConceptMap/$translate",
+			body: `{"resourceType":"Parameters","parameter":[` +
+				`{"name":"url","valueUri":"` + labMap + `"},` +
+				`{"name":"coding","valueCoding":{"system":"` + labSystem + `","code":"GLU"}}]}`,
+			want: &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{
+				boolParam("result", true),
+				match("equivalent", "2345-7", "Glucose"),
+			}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, got := serve(t, h, tc.method, tc.target, tc.body)
+			if status != http.StatusOK {
+				t.Fatalf("ServeHTTP() status = %d, want %d; got %v", status, http.StatusOK, got)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ServeHTTP() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTranslateHandler_Errors(t *testing.T) {
+	h := &TranslateHandler{Translator: labTranslator(t)}
+	tests := []struct {
+		name, target string
+		want         int
+	}{
+		{"unknown map", "/ConceptMap/$translate?url=http://example.com/ConceptMap/other&code=GLU", http.StatusNotFound},
+		{"no url", "/ConceptMap/$translate?code=GLU", http.StatusBadRequest},
+		{"no code", "/ConceptMap/$translate?url=" + labMap, http.StatusBadRequest},
+		{"instance", "/ConceptMap/lab/$translate?code=GLU", http.StatusBadRequest},
+		{"reverse", "/ConceptMap/$translate?url=" + labMap + "&code=2345-7&reverse=true", http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if status, _ := serve(t, h, http.MethodGet, tc.target, ""); status != tc.want {
+				t.Errorf("ServeHTTP() status = %d, want %d", status, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminology
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	parameterspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+)
+
+// ValidateCodeHandler is an http.Handler of the ValueSet/$validate-code and
+// CodeSystem/$validate-code operations over the core value sets and code
+// systems. The value set or code system is given by the url parameter or
+// the id of an instance level request, and the code by the code, system and
+// display parameters, or by a coding or codeableConcept parameter in POST
+// requests, of which one coding must be valid. The result is written as a
+// Parameters resource with the result, message and display parameters.
+type ValidateCodeHandler struct {
+	// Display supplies the displays of codes, if not nil. Displays given in
+	// requests are then checked against them.
+	Display DisplayFunc
+}
+
+// ServeHTTP implements http.Handler.
+func (h *ValidateCodeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	typ, lookup, urlOf := "ValueSet", Lookup, valueSetURL
+	if strings.Contains(r.URL.Path, "CodeSystem/") {
+		typ, lookup, urlOf = "CodeSystem", CodeSystem, codeSystemURL
+	}
+	req := readRequest(w, r, typ, "$validate-code")
+	if req == nil {
+		return
+	}
+	u := req.params.Get("url")
+	if req.id != "" {
+		if u = urlOf(req.id); u == "" {
+			writeOutcome(w, http.StatusNotFound, c4pb.IssueTypeCode_NOT_FOUND, fmt.Sprintf("unknown %s/%s", typ, req.id))
+			return
+		}
+	}
+	if u == "" {
+		writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_REQUIRED, "no url parameter")
+		return
+	}
+	vs, ok := lookup(u)
+	if !ok {
+		writeOutcome(w, http.StatusNotFound, c4pb.IssueTypeCode_NOT_FOUND, fmt.Sprintf("unknown %s %q", typ, u))
+		return
+	}
+	codings := req.codings
+	if req.params.Get("code") != "" {
+		codings = []*d4pb.Coding{req.coding()}
+	}
+	if len(codings) == 0 {
+		writeOutcome(w, http.StatusBadRequest, c4pb.IssueTypeCode_REQUIRED, "no code, coding or codeableConcept parameter")
+		return
+	}
+	var out []*parameterspb.Parameters_Parameter
+	var messages []string
+	for _, c := range codings {
+		system := c.GetSystem().GetValue()
+		if system == "" && typ == "CodeSystem" {
+			system = vs.URL
+		}
+		code := c.GetCode().GetValue()
+		if !vs.Contains(system, code) {
+			messages = append(messages, fmt.Sprintf("code %q of system %q is not in %s %s", code, system, typ, vs.URL))
+			continue
+		}
+		var display string
+		if h.Display != nil {
+			display, _ = h.Display(system, code)
+		}
+		if got := c.GetDisplay().GetValue(); display != "" && got != "" && got != display {
+			messages = append(messages, fmt.Sprintf("display %q of code %q is not %q", got, code, display))
+			continue
+		}
+		out = append(out, parameter("result", &d4pb.Boolean{Value: true}))
+		if display != "" {
+			out = append(out, parameter("display", fhirtypes.String(display)))
+		}
+		break
+	}
+	if out == nil {
+		out = []*parameterspb.Parameters_Parameter{
+			parameter("result", &d4pb.Boolean{Value: false}),
+			parameter("message", fhirtypes.String(strings.Join(messages, "; "))),
+		}
+	}
+	writeResource(w, http.StatusOK, &parameterspb.Parameters{Parameter: out})
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminology
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	parameterspb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/parameters_go_proto"
+)
+
+// serve returns the status and the resource of the response of h to a
+// request.
+func serve(t *testing.T, h http.Handler, method, target, body string) (int, proto.Message) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	res, err := unmarshaller.Unmarshal(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("ServeHTTP() wrote %s, not a resource: %v", rec.Body, err)
+	}
+	return rec.Code, elementpath.Unwrap(res)
+}
+
+func boolParam(name string, v bool) *parameterspb.Parameters_Parameter {
+	return parameter(name, &d4pb.Boolean{Value: v})
+}
+
+func stringParam(name, v string) *parameterspb.Parameters_Parameter {
+	return parameter(name, &d4pb.String{Value: v})
+}
+
+func TestValidateCodeHandler(t *testing.T) {
+	h := &ValidateCodeHandler{Display: statusDisplay}
+	tests := []struct {
+		name, method, target, body string
+		want                       *parameterspb.Parameters
+	}{
+		{
+			name:   "valid",
+			method: http.MethodGet,
+			target: "/ValueSet/$validate-code?url=" + statusVS + "&system=" + statusSystem + "&code=final",
+			want:   &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{boolParam("result", true), stringParam("display", "Final")}},
+		},
+		{
+			name:   "by id without display",
+			method: http.MethodGet,
+			target: "/ValueSet/observation-status/$validate-code?code=amended",
+			want:   &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{boolParam("result", true)}},
+		},
+		{
+			name:   "code system",
+			method: http.MethodGet,
+			target: "/CodeSystem/$validate-code?url=" + statusSystem + "&code=registered",
+			want:   &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{boolParam("result", true)}},
+		},
+		{
+			name:   "invalid code",
+			method: http.MethodGet,
+			target: "/ValueSet/$validate-code?url=" + statusVS + "&system=" + statusSystem + "&code=done",
+			want: &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{
+				boolParam("result", false),
+				stringParam("message", `code "done" of system "`+statusSystem+`" is not in ValueSet `+statusVS),
+			}},
+		},
+		{
+			name:   "wrong display",
+			method: http.MethodGet,
+			target: "/ValueSet/$validate-code?url=" + statusVS + "&code=final&display=Done",
+			want: &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{
+				boolParam("result", false),
+				stringParam("message", `display "Done" of code "final" is not "Final"`),
+			}},
+		},
+		{
+			name:   "codeable concept",
+			method: http.MethodPost,
+			target: "/ValueSet/$validate-code",
+			body: `{"resourceType":"Parameters","parameter":[` +
+				`{"name":"url","valueUri":"` + statusVS + `"},` +
+				`{"name":"codeableConcept","valueCodeableConcept":{"coding":[` +
+				`{"system":"http://example.com","code":"final"},` +
+				`{"system":"` + statusSystem + `","code":"final"}]}}]}`,
+			want: &parameterspb.Parameters{Parameter: []*parameterspb.Parameters_Parameter{boolParam("result", true), stringParam("display", "Final")}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, got := serve(t, h, tc.method, tc.target, tc.body)
+			if status != http.StatusOK {
+				t.Fatalf("ServeHTTP() status = %d, want %d; got %v", status, http.StatusOK, got)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("ServeHTTP() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateCodeHandler_Errors(t *testing.T) {
+	h := &ValidateCodeHandler{}
+	tests := []struct {
+		name, target string
+		want         int
+	}{
+		{"unknown value set", "/ValueSet/$validate-code?url=http://example.com/vs&code=a", http.StatusNotFound},
+		{"unknown code system id", "/CodeSystem/unknown/$validate-code?code=a", http.StatusNotFound},
+		{"no url", "/ValueSet/$validate-code?code=a", http.StatusBadRequest},
+		{"no code", "/ValueSet/$validate-code?url=" + statusVS, http.StatusBadRequest},
+		{"other operation", "/ValueSet/$lookup?url=" + statusVS, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if status, _ := serve(t, h, http.MethodGet, tc.target, ""); status != tc.want {
+				t.Errorf("ServeHTTP() status = %d, want %d", status, tc.want)
+			}
+		})
+	}
+}
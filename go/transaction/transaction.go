@@ -0,0 +1,295 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transaction uploads sets of FHIR R4 resources to a server as
+// transaction Bundles.
+//
+// Plan splits the resources into Batches of bounded size, ordered so that
+// resources come after the resources they reference: a Batch only references
+// resources of the same Batch, of earlier Batches or outside the set. Within a
+// Batch, resources are created with POST and referenced by urn:uuid full
+// URLs, which the server replaces with the ids it assigns. Resources that
+// reference each other in a cycle are kept in the same Batch.
+//
+// An Uploader posts the Batches in order, rewriting the references to the
+// resources of earlier Batches with the ids the server assigned them, and
+// records the mapping from the local references of the resources, i.e.
+// "Patient/local-1", to their server references.
+package transaction
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/internal/elementpath"
+	"github.com/google/fhir/go/internal/uuid"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// DefaultBundleSize is the default number of entries of a Batch.
+const DefaultBundleSize = 100
+
+// Options configures Plan.
+type Options struct {
+	// BundleSize is the largest number of entries of a Batch, which is only
+	// exceeded by cycles of references; DefaultBundleSize if 0.
+	BundleSize int
+	// KeepIDs has resources updated with PUT at their own ids rather than
+	// created with POST at ids the server assigns, so references are not
+	// rewritten.
+	KeepIDs bool
+}
+
+// Batch is a transaction Bundle of a Plan.
+type Batch struct {
+	Bundle *r4pb.Bundle
+	// Keys are the local references of the resources of the entries of
+	// Bundle, i.e. "Patient/local-1", or empty for resources without id.
+	Keys []string
+}
+
+// node is a resource of a Plan.
+type node struct {
+	cr  *r4pb.ContainedResource
+	key string
+	// refs are the indices of the resources of the set it references.
+	refs []int
+	// Tarjan's algorithm state.
+	index, low int
+	onStack    bool
+}
+
+// Plan returns the Batches uploading resources, which are resources or
+// ContainedResources. The resources are cloned, not modified.
+func Plan(resources []proto.Message, opts Options) ([]*Batch, error) {
+	size := opts.BundleSize
+	if size <= 0 {
+		size = DefaultBundleSize
+	}
+	nodes := make([]*node, len(resources))
+	byKey := map[string]int{}
+	for i, res := range resources {
+		cr, err := containedResource(res)
+		if err != nil {
+			return nil, fmt.Errorf("resource %d: %w", i, err)
+		}
+		n := &node{cr: cr, index: -1}
+		if id := elementpath.ID(cr); id != "" {
+			n.key = elementpath.ResourceType(cr) + "/" + id
+			if _, ok := byKey[n.key]; ok {
+				return nil, fmt.Errorf("duplicate resource %s", n.key)
+			}
+			byKey[n.key] = i
+		}
+		nodes[i] = n
+	}
+	for _, n := range nodes {
+		forEachReference(n.cr, func(ref string) (string, bool) {
+			if j, ok := byKey[referenceKey(ref)]; ok {
+				n.refs = append(n.refs, j)
+			}
+			return "", false
+		})
+	}
+
+	// The components come after those they reference, so packing them in
+	// order keeps references within a Batch or to earlier ones.
+	var groups [][]int
+	for _, scc := range components(nodes) {
+		last := len(groups) - 1
+		if last < 0 || len(groups[last]) > 0 && len(groups[last])+len(scc) > size {
+			groups = append(groups, nil)
+			last++
+		}
+		groups[last] = append(groups[last], scc...)
+	}
+	batches := make([]*Batch, len(groups))
+	for i, g := range groups {
+		batches[i] = newBatch(nodes, g, opts.KeepIDs)
+	}
+	return batches, nil
+}
+
+// newBatch returns the Batch of the resources of nodes at the indices g.
+func newBatch(nodes []*node, g []int, keepIDs bool) *Batch {
+	b := &Batch{Bundle: &r4pb.Bundle{Type: &r4pb.Bundle_TypeCode{Value: c4pb.BundleTypeCode_TRANSACTION}}}
+	// fullURLs maps the keys of the resources of b to their urn:uuid.
+	fullURLs := map[string]string{}
+	for _, i := range g {
+		if key := nodes[i].key; key != "" && !keepIDs {
+			fullURLs[key] = "urn:uuid:" + uuid.New()
+		}
+	}
+	for _, i := range g {
+		n := nodes[i]
+		cr := proto.Clone(n.cr).(*r4pb.ContainedResource)
+		e := &r4pb.Bundle_Entry{Resource: cr, Request: &r4pb.Bundle_Entry_Request{}}
+		if keepIDs && n.key != "" {
+			e.Request.Method = &r4pb.Bundle_Entry_Request_MethodCode{Value: c4pb.HTTPVerbCode_PUT}
+			e.Request.Url = &d4pb.Uri{Value: n.key}
+		} else {
+			forEachReference(cr, func(ref string) (string, bool) {
+				u, ok := fullURLs[referenceKey(ref)]
+				return u, ok
+			})
+			u, ok := fullURLs[n.key]
+			if !ok {
+				u = "urn:uuid:" + uuid.New()
+			}
+			e.FullUrl = &d4pb.Uri{Value: u}
+			e.Request.Method = &r4pb.Bundle_Entry_Request_MethodCode{Value: c4pb.HTTPVerbCode_POST}
+			e.Request.Url = &d4pb.Uri{Value: elementpath.ResourceType(cr)}
+		}
+		b.Bundle.Entry = append(b.Bundle.Entry, e)
+		b.Keys = append(b.Keys, n.key)
+	}
+	return b
+}
+
+// components returns the strongly connected components of the graph of
+// references of nodes, as lists of indices, with the components a
+// component references before it.
+func components(nodes []*node) [][]int {
+	var sccs [][]int
+	var stack []int
+	index := 0
+	var visit func(v int)
+	visit = func(v int) {
+		n := nodes[v]
+		n.index, n.low = index, index
+		index++
+		stack = append(stack, v)
+		n.onStack = true
+		for _, w := range n.refs {
+			m := nodes[w]
+			if m.index < 0 {
+				visit(w)
+				if m.low < n.low {
+					n.low = m.low
+				}
+			} else if m.onStack && m.index < n.low {
+				n.low = m.index
+			}
+		}
+		if n.low != n.index {
+			return
+		}
+		var scc []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			nodes[w].onStack = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		sccs = append(sccs, scc)
+	}
+	for v := range nodes {
+		if nodes[v].index < 0 {
+			visit(v)
+		}
+	}
+	return sccs
+}
+
+func containedResource(res proto.Message) (*r4pb.ContainedResource, error) {
+	if cr, ok := res.(*r4pb.ContainedResource); ok {
+		return cr, nil
+	}
+	v, err := elementpath.Convert(res, (&r4pb.ContainedResource{}).ProtoReflect().Descriptor())
+	if err != nil {
+		return nil, err
+	}
+	return v.(*r4pb.ContainedResource), nil
+}
+
+// referenceKey returns the local reference of the resource ref refers to,
+// without its version.
+func referenceKey(ref string) string {
+	if i := strings.Index(ref, "/_history/"); i >= 0 {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// forEachReference calls fn with the reference string of each Reference of
+// the resource of cr, including those of its contained resources, and
+// replaces it with the returned string if fn returns true.
+func forEachReference(cr *r4pb.ContainedResource, fn func(ref string) (string, bool)) {
+	walkReferences(cr.ProtoReflect(), fn)
+}
+
+func walkReferences(m protoreflect.Message, fn func(string) (string, bool)) {
+	if ref, ok := m.Interface().(*d4pb.Reference); ok {
+		rewriteReference(ref, fn)
+		return
+	}
+	if a, ok := m.Interface().(*anypb.Any); ok {
+		inner, err := a.UnmarshalNew()
+		if err != nil {
+			return
+		}
+		changed := false
+		walkReferences(inner.ProtoReflect(), func(ref string) (string, bool) {
+			s, ok := fn(ref)
+			changed = changed || ok
+			return s, ok
+		})
+		if changed {
+			a.MarshalFrom(inner)
+		}
+		return
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		if fd.IsList() {
+			l := v.List()
+			for i := 0; i < l.Len(); i++ {
+				walkReferences(l.Get(i).Message(), fn)
+			}
+		} else {
+			walkReferences(v.Message(), fn)
+		}
+		return true
+	})
+}
+
+func rewriteReference(ref *d4pb.Reference, fn func(string) (string, bool)) {
+	if ref.GetReference() == nil || ref.GetFragment() != nil {
+		return
+	}
+	uri, err := jsonformat.NewDenormalizedReference(ref)
+	if err != nil {
+		return
+	}
+	s, ok := fn(uri.(*d4pb.Reference).GetUri().GetValue())
+	if !ok {
+		return
+	}
+	ref.Reference = &d4pb.Reference_Uri{Uri: &d4pb.String{Value: s}}
+	if !strings.HasPrefix(s, "urn:") {
+		jsonformat.NormalizeReference(ref)
+	}
+}
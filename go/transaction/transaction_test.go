@@ -0,0 +1,289 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+)
+
+func resources(t *testing.T, jsons ...string) []proto.Message {
+	t.Helper()
+	um, err := jsonformat.NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	var out []proto.Message
+	for _, j := range jsons {
+		cr, err := um.UnmarshalR4([]byte(j))
+		if err != nil {
+			t.Fatalf("UnmarshalR4(%s) returned unexpected error: %v", j, err)
+		}
+		out = append(out, cr)
+	}
+	return out
+}
+
+// entry is the JSON of a Bundle entry.
+type entry struct {
+	FullURL  string `json:"fullUrl"`
+	Resource struct {
+		ResourceType string `json:"resourceType"`
+		ID           string `json:"id"`
+		Subject      *struct {
+			Reference string `json:"reference"`
+		} `json:"subject"`
+		Link []struct {
+			Other struct {
+				Reference string `json:"reference"`
+			} `json:"other"`
+		} `json:"link"`
+	} `json:"resource"`
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+	} `json:"request"`
+}
+
+type bundle struct {
+	Type  string  `json:"type"`
+	Entry []entry `json:"entry"`
+}
+
+func bundleJSON(t *testing.T, b *Batch) bundle {
+	t.Helper()
+	m, err := jsonformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	data, err := m.MarshalResource(b.Bundle)
+	if err != nil {
+		t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+	}
+	var out bundle
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+	return out
+}
+
+var testResources = []string{
+	`{"resourceType":"Observation","id":"o1","status":"final","code":{"text":"x"},"subject":{"reference":"Patient/p1"}}`,
+	`{"resourceType":"Observation","id":"o2","status":"final","code":{"text":"x"},"subject":{"reference":"Patient/p2"}}`,
+	`{"resourceType":"Patient","id":"p1"}`,
+	`{"resourceType":"Patient","id":"p2","link":[{"type":"seealso","other":{"reference":"Patient/p3"}}]}`,
+	`{"resourceType":"Patient","id":"p3","link":[{"type":"seealso","other":{"reference":"Patient/p2"}}]}`,
+	`{"resourceType":"Observation","id":"o3","status":"final","code":{"text":"x"},"subject":{"reference":"Patient/elsewhere"}}`,
+}
+
+func TestPlan(t *testing.T) {
+	batches, err := Plan(resources(t, testResources...), Options{BundleSize: 2})
+	if err != nil {
+		t.Fatalf("Plan() returned unexpected error: %v", err)
+	}
+	var keys [][]string
+	for _, b := range batches {
+		keys = append(keys, b.Keys)
+	}
+	// The cycle p2 <-> p3 is not split although it fills a whole batch.
+	want := [][]string{{"Patient/p1", "Observation/o1"}, {"Patient/p3", "Patient/p2"}, {"Observation/o2", "Observation/o3"}}
+	if diff := cmp.Diff(want, keys); diff != "" {
+		t.Fatalf("Plan() keys diff (-want +got):\n%s", diff)
+	}
+
+	first := bundleJSON(t, batches[0])
+	if first.Type != "transaction" {
+		t.Errorf("Plan() bundle type %q, want transaction", first.Type)
+	}
+	p1, o1 := first.Entry[0], first.Entry[1]
+	if !strings.HasPrefix(p1.FullURL, "urn:uuid:") || p1.Request.Method != "POST" || p1.Request.URL != "Patient" {
+		t.Errorf("Plan() entry of Patient/p1 = %+v, want a POST with a urn:uuid", p1)
+	}
+	if got := o1.Resource.Subject.Reference; got != p1.FullURL {
+		t.Errorf("Plan() reference to Patient/p1 within the batch = %q, want %q", got, p1.FullURL)
+	}
+	last := bundleJSON(t, batches[2])
+	for i, want := range []string{"Patient/p2", "Patient/elsewhere"} {
+		if got := last.Entry[i].Resource.Subject.Reference; got != want {
+			t.Errorf("Plan() reference of entry %d of the last batch = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestPlan_KeepIDs(t *testing.T) {
+	batches, err := Plan(resources(t, testResources[:3]...), Options{KeepIDs: true})
+	if err != nil {
+		t.Fatalf("Plan() returned unexpected error: %v", err)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("Plan() returned %d batches, want 1", len(batches))
+	}
+	b := bundleJSON(t, batches[0])
+	for _, e := range b.Entry {
+		key := e.Resource.ResourceType + "/" + e.Resource.ID
+		if e.Request.Method != "PUT" || e.Request.URL != key || e.FullURL != "" {
+			t.Errorf("Plan() entry of %s = %+v, want a PUT to its id", key, e)
+		}
+		if e.Resource.Subject != nil && !strings.HasPrefix(e.Resource.Subject.Reference, "Patient/") {
+			t.Errorf("Plan() rewrote reference %q", e.Resource.Subject.Reference)
+		}
+	}
+}
+
+func TestPlan_Duplicate(t *testing.T) {
+	if _, err := Plan(resources(t, testResources[2], testResources[2]), Options{}); err == nil {
+		t.Errorf("Plan() of a duplicate resource succeeded, want error")
+	}
+}
+
+// testServer answers transactions with server ids, failing the first
+// request with a 503.
+type testServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []bundle
+	next     int
+	failed   bool
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.failed {
+		s.failed = true
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+		return
+	}
+	var b bundle
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil || r.Header.Get("Content-Type") != "application/fhir+json" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	s.requests = append(s.requests, b)
+	var entries []string
+	for _, e := range b.Entry {
+		s.next++
+		entries = append(entries, fmt.Sprintf(`{"response":{"status":"201 Created","location":"%s/%s/s%d/_history/1"}}`, s.URL, e.Resource.ResourceType, s.next))
+	}
+	fmt.Fprintf(w, `{"resourceType":"Bundle","type":"transaction-response","entry":[%s]}`, strings.Join(entries, ","))
+}
+
+func TestUpload(t *testing.T) {
+	s := newTestServer(t)
+	batches, err := Plan(resources(t, testResources...), Options{BundleSize: 2})
+	if err != nil {
+		t.Fatalf("Plan() returned unexpected error: %v", err)
+	}
+	u := &Uploader{BaseURL: s.URL, Backoff: time.Millisecond}
+	ids := IDMap{}
+	ctx := context.Background()
+	if err := u.Upload(ctx, batches[:2], ids); err != nil {
+		t.Fatalf("Upload() returned unexpected error: %v", err)
+	}
+	// Resuming skips the uploaded batches.
+	if err := u.Upload(ctx, batches, ids); err != nil {
+		t.Fatalf("Upload() returned unexpected error: %v", err)
+	}
+	want := IDMap{
+		"Patient/p1": "Patient/s1", "Observation/o1": "Observation/s2",
+		"Patient/p3": "Patient/s3", "Patient/p2": "Patient/s4",
+		"Observation/o2": "Observation/s5", "Observation/o3": "Observation/s6",
+	}
+	if diff := cmp.Diff(want, ids); diff != "" {
+		t.Errorf("Upload() ids diff (-want +got):\n%s", diff)
+	}
+	if len(s.requests) != 3 {
+		t.Fatalf("server received %d transactions, want 3", len(s.requests))
+	}
+	last := s.requests[2]
+	for i, want := range []string{"Patient/s4", "Patient/elsewhere"} {
+		if got := last.Entry[i].Resource.Subject.Reference; got != want {
+			t.Errorf("Upload() reference of entry %d of the last batch = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestUpload_Errors(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/bad" {
+			http.Error(w, `{"resourceType":"OperationOutcome"}`, http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	batches, err := Plan(resources(t, testResources[2]), Options{})
+	if err != nil {
+		t.Fatalf("Plan() returned unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	u := &Uploader{BaseURL: srv.URL + "/bad", Backoff: time.Millisecond}
+	if err := u.Upload(ctx, batches, IDMap{}); err == nil || !strings.Contains(err.Error(), "OperationOutcome") {
+		t.Errorf("Upload() returned error %v, want the OperationOutcome", err)
+	}
+	if calls != 1 {
+		t.Errorf("Upload() sent %d requests for a 400, want 1", calls)
+	}
+	calls = 0
+	u = &Uploader{BaseURL: srv.URL, MaxAttempts: 3, Backoff: time.Millisecond}
+	if err := u.Upload(ctx, batches, IDMap{}); err == nil {
+		t.Errorf("Upload() succeeded, want error")
+	}
+	if calls != 3 {
+		t.Errorf("Upload() sent %d requests for a 500, want 3", calls)
+	}
+}
+
+func TestLocationReference(t *testing.T) {
+	tests := []struct {
+		loc, want string
+		ok        bool
+	}{
+		{"Patient/1", "Patient/1", true},
+		{"Patient/1/_history/2", "Patient/1", true},
+		{"https://example.com/fhir/Patient/1/_history/2", "Patient/1", true},
+		{"Patient", "", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		got, ok := locationReference(test.loc)
+		if got != test.want || ok != test.ok {
+			t.Errorf("locationReference(%q) = %q, %v, want %q, %v", test.loc, got, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/bulkdata"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+)
+
+// IDMap maps the local references of uploaded resources, i.e.
+// "Patient/local-1", to their references on the server, i.e. "Patient/123".
+type IDMap map[string]string
+
+// Defaults of the Uploader.
+const (
+	DefaultMaxAttempts = 5
+	DefaultBackoff     = time.Second
+	// maxBackoff caps the delay between attempts, unless the server asks for
+	// a longer one.
+	maxBackoff = time.Minute
+)
+
+// Uploader posts transaction Bundles to a FHIR server.
+//
+// Failed requests are retried when the server is throttling or unavailable,
+// with a 429 or 5xx status, and on network errors. The server rolls back
+// failed transactions, but a transaction whose response was lost to a network
+// error may have been committed, in which case retrying it creates its POSTed
+// resources again.
+type Uploader struct {
+	// BaseURL is the FHIR base URL of the server, i.e.
+	// "https://example.com/fhir".
+	BaseURL string
+	// HTTPClient is used for the requests; http.DefaultClient is used if it
+	// is nil.
+	HTTPClient *http.Client
+	// Auth provides the access tokens of the requests, which are sent without
+	// one if it is nil.
+	Auth bulkdata.TokenSource
+	// MaxAttempts is the number of attempts of a request;
+	// DefaultMaxAttempts if 0.
+	MaxAttempts int
+	// Backoff is the delay before the first retry of a request, doubled on
+	// each further retry; DefaultBackoff if 0. A Retry-After of the server
+	// takes precedence.
+	Backoff time.Duration
+}
+
+// Upload uploads the batches in order with UploadBatch, recording the
+// references the server assigns in ids.
+func (u *Uploader) Upload(ctx context.Context, batches []*Batch, ids IDMap) error {
+	for i, b := range batches {
+		if err := u.UploadBatch(ctx, b, ids); err != nil {
+			return fmt.Errorf("batch %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// UploadBatch posts b, with the references to the resources of ids replaced
+// with their server references, and adds the server references of the
+// resources of b to ids. A batch whose resources all are in ids already is
+// skipped, so that an interrupted upload can be resumed with the ids it
+// recorded.
+func (u *Uploader) UploadBatch(ctx context.Context, b *Batch, ids IDMap) error {
+	if uploaded(b, ids) {
+		return nil
+	}
+	bundle := proto.Clone(b.Bundle).(*r4pb.Bundle)
+	for _, e := range bundle.GetEntry() {
+		forEachReference(e.GetResource(), func(ref string) (string, bool) {
+			s, ok := ids[referenceKey(ref)]
+			return s, ok
+		})
+	}
+	m, err := jsonformat.NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		return err
+	}
+	body, err := m.MarshalResource(bundle)
+	if err != nil {
+		return err
+	}
+	resp, err := u.post(ctx, body)
+	if err != nil {
+		return err
+	}
+	refs, err := responseReferences(resp, len(bundle.GetEntry()))
+	if err != nil {
+		return err
+	}
+	for i, key := range b.Keys {
+		if key != "" {
+			ids[key] = refs[i]
+		}
+	}
+	return nil
+}
+
+func uploaded(b *Batch, ids IDMap) bool {
+	for _, key := range b.Keys {
+		if _, ok := ids[key]; key == "" || !ok {
+			return false
+		}
+	}
+	return len(b.Keys) > 0
+}
+
+// post posts the transaction body to the server, with retries, and returns
+// the body of the response.
+func (u *Uploader) post(ctx context.Context, body []byte) ([]byte, error) {
+	attempts := u.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+	backoff := u.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		resp, wait, err := u.postOnce(ctx, body)
+		if err == nil || attempt == attempts || wait < 0 || ctx.Err() != nil {
+			return resp, err
+		}
+		if wait == 0 {
+			wait = backoff
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// postOnce posts body once. On failure, it returns the delay the server asks
+// for before retrying, 0 if it does not say, or -1 if the request must not be
+// retried.
+func (u *Uploader) postOnce(ctx context.Context, body []byte) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(u.BaseURL, "/"), bytes.NewReader(body))
+	if err != nil {
+		return nil, -1, err
+	}
+	req.Header.Set("Content-Type", "application/fhir+json")
+	req.Header.Set("Accept", "application/fhir+json")
+	if u.Auth != nil {
+		token, err := u.Auth.Token(ctx)
+		if err != nil {
+			return nil, -1, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := u.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return data, 0, nil
+	}
+	err = fmt.Errorf("posting transaction: %s: %s", resp.Status, strings.TrimSpace(string(truncate(data, 4096))))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5 {
+		return nil, bulkdata.RetryAfter(resp.Header), err
+	}
+	return nil, -1, err
+}
+
+func truncate(b []byte, n int) []byte {
+	if len(b) > n {
+		return b[:n]
+	}
+	return b
+}
+
+// responseReferences returns the references of the resources of the entries
+// of the transaction-response Bundle data, which has n entries.
+func responseReferences(data []byte, n int) ([]string, error) {
+	um, err := jsonformat.NewUnmarshallerWithoutValidation("UTC", fhirversion.R4)
+	if err != nil {
+		return nil, err
+	}
+	cr, err := um.UnmarshalR4(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading transaction response: %w", err)
+	}
+	bundle := cr.GetBundle()
+	if bundle == nil {
+		return nil, errors.New("reading transaction response: not a Bundle")
+	}
+	if len(bundle.GetEntry()) != n {
+		return nil, fmt.Errorf("reading transaction response: %d entries, want %d", len(bundle.GetEntry()), n)
+	}
+	refs := make([]string, n)
+	for i, e := range bundle.GetEntry() {
+		loc := e.GetResponse().GetLocation().GetValue()
+		ref, ok := locationReference(loc)
+		if !ok {
+			return nil, fmt.Errorf("reading transaction response: entry %d: unexpected location %q", i, loc)
+		}
+		refs[i] = ref
+	}
+	return refs, nil
+}
+
+// locationReference returns the "Type/id" reference of the resource at the
+// relative or absolute location loc, without its version.
+func locationReference(loc string) (string, bool) {
+	loc = referenceKey(loc)
+	parts := strings.Split(strings.TrimSuffix(loc, "/"), "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", false
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1], true
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+	"google.golang.org/protobuf/proto"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+	provpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/provenance_go_proto"
+)
+
+// Code systems of the clinical and verification statuses of Conditions, and
+// of their categories.
+const (
+	ConditionClinicalSystem     = "http://terminology.hl7.org/CodeSystem/condition-clinical"
+	ConditionVerificationSystem = "http://terminology.hl7.org/CodeSystem/condition-ver-status"
+	ConditionCategorySystem     = "http://terminology.hl7.org/CodeSystem/condition-category"
+)
+
+// Clinical statuses of Conditions.
+const (
+	ClinicalActive     = "active"
+	ClinicalRecurrence = "recurrence"
+	ClinicalRelapse    = "relapse"
+	ClinicalInactive   = "inactive"
+	ClinicalRemission  = "remission"
+	ClinicalResolved   = "resolved"
+)
+
+// Verification statuses of Conditions.
+const (
+	VerificationUnconfirmed    = "unconfirmed"
+	VerificationProvisional    = "provisional"
+	VerificationDifferential   = "differential"
+	VerificationConfirmed      = "confirmed"
+	VerificationRefuted        = "refuted"
+	VerificationEnteredInError = "entered-in-error"
+)
+
+// clinicalTransitions lists the clinical statuses a Condition may move to
+// from each clinical status. A Condition that is active again after it
+// resolved or became inactive recurs, and one that is active again after a
+// remission relapses.
+var clinicalTransitions = map[string][]string{
+	ClinicalActive:     {ClinicalInactive, ClinicalRemission, ClinicalResolved},
+	ClinicalRecurrence: {ClinicalInactive, ClinicalRemission, ClinicalResolved},
+	ClinicalRelapse:    {ClinicalInactive, ClinicalRemission, ClinicalResolved},
+	ClinicalInactive:   {ClinicalRecurrence, ClinicalRemission, ClinicalResolved},
+	ClinicalRemission:  {ClinicalRelapse, ClinicalInactive, ClinicalResolved},
+	ClinicalResolved:   {ClinicalRecurrence},
+}
+
+// verificationTransitions lists the verification statuses a Condition may
+// move to from each verification status. Every status but entered-in-error
+// may additionally move to entered-in-error.
+var verificationTransitions = map[string][]string{
+	VerificationUnconfirmed:  {VerificationProvisional, VerificationDifferential, VerificationConfirmed, VerificationRefuted},
+	VerificationProvisional:  {VerificationDifferential, VerificationConfirmed, VerificationRefuted},
+	VerificationDifferential: {VerificationProvisional, VerificationConfirmed, VerificationRefuted},
+	VerificationConfirmed:    {VerificationRefuted},
+	VerificationRefuted:      {VerificationConfirmed},
+}
+
+// CanTransitionClinical reports whether a Condition may move from clinical
+// status from to clinical status to. A Condition without a clinical status
+// may take any.
+func CanTransitionClinical(from, to string) bool {
+	if _, ok := clinicalTransitions[to]; !ok {
+		return false
+	}
+	return from == "" || from == to || contains(clinicalTransitions[from], to)
+}
+
+// CanTransitionVerification reports whether a Condition may move from
+// verification status from to verification status to. A Condition without
+// a verification status may take any.
+func CanTransitionVerification(from, to string) bool {
+	if _, ok := verificationTransitions[to]; !ok && to != VerificationEnteredInError {
+		return false
+	}
+	switch {
+	case from == "" || from == to:
+		return true
+	case from == VerificationEnteredInError:
+		return false
+	case to == VerificationEnteredInError:
+		return true
+	}
+	return contains(verificationTransitions[from], to)
+}
+
+// ClinicalStatus returns the clinical status code of c, or "" if it has
+// none.
+func ClinicalStatus(c *cpb.Condition) string {
+	return statusIn(c.GetClinicalStatus(), ConditionClinicalSystem)
+}
+
+// VerificationStatus returns the verification status code of c, or "" if
+// it has none.
+func VerificationStatus(c *cpb.Condition) string {
+	return statusIn(c.GetVerificationStatus(), ConditionVerificationSystem)
+}
+
+// abated reports whether the clinical status s is one of those con-4 allows
+// for an abated Condition.
+func abated(s string) bool {
+	return s == ClinicalInactive || s == ClinicalRemission || s == ClinicalResolved
+}
+
+// CheckCondition returns an error if the statuses of c violate the
+// invariants of Condition: con-3, that a problem list item that was not
+// entered in error has a clinical status; con-4, that an abated Condition
+// is inactive, in remission or resolved; and con-5, that a Condition
+// entered in error has no clinical status.
+func CheckCondition(c *cpb.Condition) error {
+	clinical, verification := ClinicalStatus(c), VerificationStatus(c)
+	if verification == VerificationEnteredInError {
+		if c.GetClinicalStatus() != nil {
+			return fmt.Errorf("con-5: condition %s entered in error has a clinical status", c.GetId().GetValue())
+		}
+		return nil
+	}
+	if c.GetClinicalStatus() == nil {
+		for _, cat := range c.GetCategory() {
+			if statusIn(cat, ConditionCategorySystem) == "problem-list-item" {
+				return fmt.Errorf("con-3: problem list item %s has no clinical status", c.GetId().GetValue())
+			}
+		}
+	}
+	if c.GetAbatement() != nil && c.GetClinicalStatus() != nil && !abated(clinical) {
+		return fmt.Errorf("con-4: abated condition %s is %s", c.GetId().GetValue(), clinical)
+	}
+	return nil
+}
+
+// ConditionChange describes a change of the statuses of a Condition.
+type ConditionChange struct {
+	// Clinical and Verification are the new clinical and verification
+	// statuses; "" keeps the current status.
+	Clinical, Verification string
+	// Agent is the relative reference of who makes the change, i.e.
+	// "Practitioner/123". It defaults to the asserter of the Condition, or
+	// else to its recorder.
+	Agent string
+	// Reason optionally explains the change, in the Provenance.
+	Reason *d4pb.CodeableConcept
+	// Time is when the change happens; it defaults to the current time.
+	Time time.Time
+	// ProvenanceID is the id of the Provenance recording the change.
+	ProvenanceID string
+}
+
+// ApplyCondition applies ch to c and returns the updated Condition together
+// with a Provenance recording the change. c itself is not modified.
+//
+// Besides the statuses, ApplyCondition maintains meta.lastUpdated and the
+// abatement: a Condition that becomes inactive, remits or resolves abates
+// at the time of the change unless it already had an abatement, and one
+// that becomes active again loses it. A Condition entered in error loses
+// its clinical status. The result must satisfy CheckCondition.
+func ApplyCondition(c *cpb.Condition, ch ConditionChange) (*cpb.Condition, *provpb.Provenance, error) {
+	clinical, verification := ClinicalStatus(c), VerificationStatus(c)
+	if ch.Verification != "" && !CanTransitionVerification(verification, ch.Verification) {
+		return nil, nil, fmt.Errorf("illegal condition verification status transition from %q to %q", verification, ch.Verification)
+	}
+	entered := ch.Verification == VerificationEnteredInError || ch.Verification == "" && verification == VerificationEnteredInError
+	if ch.Clinical != "" {
+		if entered {
+			return nil, nil, fmt.Errorf("condition entered in error cannot have clinical status %q", ch.Clinical)
+		}
+		if !CanTransitionClinical(clinical, ch.Clinical) {
+			return nil, nil, fmt.Errorf("illegal condition clinical status transition from %q to %q", clinical, ch.Clinical)
+		}
+	}
+	now := ch.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	agent := c.GetAsserter()
+	if agent == nil {
+		agent = c.GetRecorder()
+	}
+	if ch.Agent != "" {
+		var err error
+		if agent, err = fhirtypes.ResourceReference(ch.Agent); err != nil {
+			return nil, nil, err
+		}
+	}
+	if agent == nil {
+		return nil, nil, fmt.Errorf("change of condition %s has no agent and the condition no asserter or recorder", c.GetId().GetValue())
+	}
+
+	out := proto.Clone(c).(*cpb.Condition)
+	if ch.Verification != "" {
+		out.VerificationStatus = status(ConditionVerificationSystem, ch.Verification)
+	}
+	switch {
+	case entered:
+		out.ClinicalStatus = nil
+	case ch.Clinical != "" && ch.Clinical != clinical:
+		out.ClinicalStatus = status(ConditionClinicalSystem, ch.Clinical)
+		if !abated(ch.Clinical) {
+			out.Abatement = nil
+		} else if out.GetAbatement() == nil {
+			out.Abatement = &cpb.Condition_AbatementX{Choice: &cpb.Condition_AbatementX_DateTime{DateTime: dateTime(now)}}
+		}
+	}
+	if out.Meta == nil {
+		out.Meta = &d4pb.Meta{}
+	}
+	out.Meta.LastUpdated = &d4pb.Instant{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_MICROSECOND}
+	if err := CheckCondition(out); err != nil {
+		return nil, nil, err
+	}
+	prov, err := provenance("Condition", c.GetId().GetValue(), c.GetMeta(), ch.Reason, ch.ProvenanceID, agent, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, prov, nil
+}
+
+// Resolve applies ch to c with the clinical status resolved.
+func Resolve(c *cpb.Condition, ch ConditionChange) (*cpb.Condition, *provpb.Provenance, error) {
+	ch.Clinical = ClinicalResolved
+	return ApplyCondition(c, ch)
+}
+
+// Recur applies ch to c with the clinical status relapse, for a Condition
+// in remission, or recurrence otherwise.
+func Recur(c *cpb.Condition, ch ConditionChange) (*cpb.Condition, *provpb.Provenance, error) {
+	ch.Clinical = ClinicalRecurrence
+	if ClinicalStatus(c) == ClinicalRemission {
+		ch.Clinical = ClinicalRelapse
+	}
+	return ApplyCondition(c, ch)
+}
+
+// EnterInError applies ch to c with the verification status
+// entered-in-error.
+func EnterInError(c *cpb.Condition, ch ConditionChange) (*cpb.Condition, *provpb.Provenance, error) {
+	ch.Clinical, ch.Verification = "", VerificationEnteredInError
+	return ApplyCondition(c, ch)
+}
+
+// statusIn returns the code of the first coding of cc in system, or "".
+func statusIn(cc *d4pb.CodeableConcept, system string) string {
+	for _, c := range cc.GetCoding() {
+		if c.GetSystem().GetValue() == system {
+			return c.GetCode().GetValue()
+		}
+	}
+	return ""
+}
+
+func status(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+		System: &d4pb.Uri{Value: system},
+		Code:   &d4pb.Code{Value: code},
+	}}}
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
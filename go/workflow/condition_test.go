@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	cpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/condition_go_proto"
+)
+
+func condition(clinical, verification string) *cpb.Condition {
+	c := &cpb.Condition{
+		Id:       &d4pb.Id{Value: "c1"},
+		Meta:     &d4pb.Meta{VersionId: &d4pb.Id{Value: "2"}},
+		Category: []*d4pb.CodeableConcept{status(ConditionCategorySystem, "problem-list-item")},
+		Asserter: practitioner("asserter"),
+	}
+	if clinical != "" {
+		c.ClinicalStatus = status(ConditionClinicalSystem, clinical)
+	}
+	if verification != "" {
+		c.VerificationStatus = status(ConditionVerificationSystem, verification)
+	}
+	return c
+}
+
+func TestCanTransitionClinical(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{ClinicalActive, ClinicalResolved, true},
+		{ClinicalResolved, ClinicalRecurrence, true},
+		{ClinicalRemission, ClinicalRelapse, true},
+		{"", ClinicalActive, true},
+		{ClinicalResolved, ClinicalActive, false},
+		{ClinicalResolved, ClinicalRelapse, false},
+		{ClinicalActive, "cured", false},
+	}
+	for _, test := range tests {
+		if got := CanTransitionClinical(test.from, test.to); got != test.want {
+			t.Errorf("CanTransitionClinical(%q, %q) = %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestCanTransitionVerification(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{VerificationProvisional, VerificationConfirmed, true},
+		{VerificationConfirmed, VerificationRefuted, true},
+		{VerificationConfirmed, VerificationEnteredInError, true},
+		{VerificationConfirmed, VerificationProvisional, false},
+		{VerificationEnteredInError, VerificationConfirmed, false},
+	}
+	for _, test := range tests {
+		if got := CanTransitionVerification(test.from, test.to); got != test.want {
+			t.Errorf("CanTransitionVerification(%q, %q) = %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestCheckCondition(t *testing.T) {
+	abated := condition(ClinicalActive, VerificationConfirmed)
+	abated.Abatement = &cpb.Condition_AbatementX{Choice: &cpb.Condition_AbatementX_DateTime{DateTime: dt(finished)}}
+	tests := []struct {
+		name    string
+		c       *cpb.Condition
+		wantErr bool
+	}{
+		{"valid", condition(ClinicalActive, VerificationConfirmed), false},
+		{"entered in error", condition("", VerificationEnteredInError), false},
+		{"con-3", condition("", VerificationConfirmed), true},
+		{"con-4", abated, true},
+		{"con-5", condition(ClinicalActive, VerificationEnteredInError), true},
+	}
+	for _, test := range tests {
+		if err := CheckCondition(test.c); (err != nil) != test.wantErr {
+			t.Errorf("CheckCondition(%s) returned error %v, want error %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestResolveAndRecur(t *testing.T) {
+	c := condition(ClinicalActive, VerificationConfirmed)
+	resolved, prov, err := Resolve(c, ConditionChange{Time: finished, ProvenanceID: "p1"})
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	want := condition(ClinicalResolved, VerificationConfirmed)
+	want.Abatement = &cpb.Condition_AbatementX{Choice: &cpb.Condition_AbatementX_DateTime{DateTime: dt(finished)}}
+	want.Meta.LastUpdated = &d4pb.Instant{ValueUs: finished.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_MICROSECOND}
+	if diff := cmp.Diff(want, resolved, protocmp.Transform()); diff != "" {
+		t.Errorf("Resolve() diff (-want +got):\n%s", diff)
+	}
+	if got := ClinicalStatus(c); got != ClinicalActive {
+		t.Errorf("Resolve() modified its input to status %q", got)
+	}
+	if got := prov.GetId().GetValue(); got != "p1" {
+		t.Errorf("Resolve() provenance id = %q, want %q", got, "p1")
+	}
+	if diff := cmp.Diff(practitioner("asserter"), prov.GetAgent()[0].GetWho(), protocmp.Transform()); diff != "" {
+		t.Errorf("Resolve() provenance agent diff (-want +got):\n%s", diff)
+	}
+	if got := prov.GetEntity()[0].GetWhat().GetConditionId().GetHistory().GetValue(); got != "2" {
+		t.Errorf("Resolve() provenance entity version = %q, want %q", got, "2")
+	}
+
+	recurred, _, err := Recur(resolved, ConditionChange{Agent: "Practitioner/p2", Time: finished.Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Recur() returned unexpected error: %v", err)
+	}
+	if got := ClinicalStatus(recurred); got != ClinicalRecurrence {
+		t.Errorf("Recur() clinical status = %q, want %q", got, ClinicalRecurrence)
+	}
+	if recurred.GetAbatement() != nil {
+		t.Errorf("Recur() kept abatement %v", recurred.GetAbatement())
+	}
+
+	relapsed, _, err := Recur(condition(ClinicalRemission, VerificationConfirmed), ConditionChange{Time: finished})
+	if err != nil {
+		t.Fatalf("Recur() returned unexpected error: %v", err)
+	}
+	if got := ClinicalStatus(relapsed); got != ClinicalRelapse {
+		t.Errorf("Recur() clinical status = %q, want %q", got, ClinicalRelapse)
+	}
+}
+
+func TestEnterInError(t *testing.T) {
+	got, _, err := EnterInError(condition(ClinicalActive, VerificationConfirmed), ConditionChange{Time: finished})
+	if err != nil {
+		t.Fatalf("EnterInError() returned unexpected error: %v", err)
+	}
+	if got.GetClinicalStatus() != nil || VerificationStatus(got) != VerificationEnteredInError {
+		t.Errorf("EnterInError() = clinical %v, verification %q, want none and %q", got.GetClinicalStatus(), VerificationStatus(got), VerificationEnteredInError)
+	}
+	if _, _, err := ApplyCondition(got, ConditionChange{Clinical: ClinicalActive, Time: finished}); err == nil {
+		t.Errorf("ApplyCondition() succeeded on a condition entered in error, want error")
+	}
+}
+
+func TestApplyConditionErrors(t *testing.T) {
+	noAgent := condition(ClinicalActive, VerificationConfirmed)
+	noAgent.Asserter = nil
+	tests := []struct {
+		name string
+		c    *cpb.Condition
+		ch   ConditionChange
+	}{
+		{"illegal clinical", condition(ClinicalResolved, VerificationConfirmed), ConditionChange{Clinical: ClinicalActive}},
+		{"illegal verification", condition(ClinicalActive, VerificationConfirmed), ConditionChange{Verification: VerificationUnconfirmed}},
+		{"no agent", noAgent, ConditionChange{Clinical: ClinicalResolved}},
+	}
+	for _, test := range tests {
+		if _, _, err := ApplyCondition(test.c, test.ch); err == nil {
+			t.Errorf("ApplyCondition(%s) succeeded, want error", test.name)
+		}
+	}
+}
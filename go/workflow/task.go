@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workflow implements the lifecycles of R4 Tasks and Conditions for
+// services that maintain them: legal status transitions, the bookkeeping
+// that goes with them and the Provenance that records who made each change.
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirpath"
+	"github.com/google/fhir/go/fhirtypes"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	provpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/provenance_go_proto"
+	tpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/task_go_proto"
+)
+
+// transitions lists the statuses a Task may move to from each status,
+// following the Task state machine of the FHIR specification. Every status
+// may additionally move to entered-in-error.
+var transitions = map[c4pb.TaskStatusCode_Value][]c4pb.TaskStatusCode_Value{
+	c4pb.TaskStatusCode_DRAFT:       {c4pb.TaskStatusCode_REQUESTED, c4pb.TaskStatusCode_CANCELLED},
+	c4pb.TaskStatusCode_REQUESTED:   {c4pb.TaskStatusCode_RECEIVED, c4pb.TaskStatusCode_ACCEPTED, c4pb.TaskStatusCode_REJECTED, c4pb.TaskStatusCode_CANCELLED},
+	c4pb.TaskStatusCode_RECEIVED:    {c4pb.TaskStatusCode_ACCEPTED, c4pb.TaskStatusCode_REJECTED, c4pb.TaskStatusCode_CANCELLED},
+	c4pb.TaskStatusCode_ACCEPTED:    {c4pb.TaskStatusCode_READY, c4pb.TaskStatusCode_IN_PROGRESS, c4pb.TaskStatusCode_ON_HOLD, c4pb.TaskStatusCode_CANCELLED, c4pb.TaskStatusCode_FAILED},
+	c4pb.TaskStatusCode_READY:       {c4pb.TaskStatusCode_IN_PROGRESS, c4pb.TaskStatusCode_ON_HOLD, c4pb.TaskStatusCode_CANCELLED, c4pb.TaskStatusCode_FAILED},
+	c4pb.TaskStatusCode_IN_PROGRESS: {c4pb.TaskStatusCode_COMPLETED, c4pb.TaskStatusCode_FAILED, c4pb.TaskStatusCode_ON_HOLD, c4pb.TaskStatusCode_CANCELLED},
+	c4pb.TaskStatusCode_ON_HOLD:     {c4pb.TaskStatusCode_IN_PROGRESS, c4pb.TaskStatusCode_CANCELLED, c4pb.TaskStatusCode_FAILED},
+}
+
+// Next returns the statuses a Task in status from may move to.
+func Next(from c4pb.TaskStatusCode_Value) []c4pb.TaskStatusCode_Value {
+	var out []c4pb.TaskStatusCode_Value
+	out = append(out, transitions[from]...)
+	if from != c4pb.TaskStatusCode_ENTERED_IN_ERROR {
+		out = append(out, c4pb.TaskStatusCode_ENTERED_IN_ERROR)
+	}
+	return out
+}
+
+// CanTransition reports whether a Task may move from status from to status
+// to.
+func CanTransition(from, to c4pb.TaskStatusCode_Value) bool {
+	for _, s := range Next(from) {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal reports whether status s ends the lifecycle of a Task, i.e. no
+// further work is expected.
+func Terminal(s c4pb.TaskStatusCode_Value) bool {
+	switch s {
+	case c4pb.TaskStatusCode_REJECTED, c4pb.TaskStatusCode_CANCELLED, c4pb.TaskStatusCode_FAILED,
+		c4pb.TaskStatusCode_COMPLETED, c4pb.TaskStatusCode_ENTERED_IN_ERROR:
+		return true
+	}
+	return false
+}
+
+// Transition describes a change of the status of a Task.
+type Transition struct {
+	// To is the new status.
+	To c4pb.TaskStatusCode_Value
+	// Agent is the relative reference of who makes the change, i.e.
+	// "Practitioner/123". It defaults to the owner of the Task.
+	Agent string
+	// Reason optionally explains the change and becomes Task.statusReason.
+	Reason *d4pb.CodeableConcept
+	// Outputs are appended to Task.output.
+	Outputs []*tpb.Task_Output
+	// Time is when the change happens; it defaults to the current time.
+	Time time.Time
+	// ProvenanceID is the id of the Provenance recording the change. If set,
+	// the Task refers to the Provenance from relevantHistory.
+	ProvenanceID string
+}
+
+// Apply applies t to task and returns the updated Task together with a
+// Provenance recording the change. task itself is not modified.
+//
+// Besides the status, Apply maintains lastModified and executionPeriod: the
+// period starts when the Task first moves to in-progress and ends when it
+// reaches a terminal status. A Task whose restriction has a period may only
+// be started within that period.
+func Apply(task *tpb.Task, t Transition) (*tpb.Task, *provpb.Provenance, error) {
+	from := task.GetStatus().GetValue()
+	if !CanTransition(from, t.To) {
+		return nil, nil, fmt.Errorf("illegal task transition from %s to %s", statusCode(from), statusCode(t.To))
+	}
+	now := t.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if t.To == c4pb.TaskStatusCode_IN_PROGRESS {
+		if err := checkRestriction(task.GetRestriction(), now); err != nil {
+			return nil, nil, err
+		}
+	}
+	agent := task.GetOwner()
+	if t.Agent != "" {
+		var err error
+		if agent, err = fhirtypes.ResourceReference(t.Agent); err != nil {
+			return nil, nil, err
+		}
+	}
+	if agent == nil {
+		return nil, nil, fmt.Errorf("transition of task %s has no agent and the task no owner", task.GetId().GetValue())
+	}
+
+	out := proto.Clone(task).(*tpb.Task)
+	out.Status = &tpb.Task_StatusCode{Value: t.To}
+	out.StatusReason = t.Reason
+	out.Output = append(out.Output, t.Outputs...)
+	dt := dateTime(now)
+	out.LastModified = dt
+	if t.To == c4pb.TaskStatusCode_IN_PROGRESS && out.GetExecutionPeriod().GetStart() == nil {
+		if out.ExecutionPeriod == nil {
+			out.ExecutionPeriod = &d4pb.Period{}
+		}
+		out.ExecutionPeriod.Start = dt
+	}
+	if Terminal(t.To) && out.GetExecutionPeriod().GetStart() != nil {
+		out.ExecutionPeriod.End = dt
+	}
+
+	prov, err := provenance("Task", task.GetId().GetValue(), task.GetMeta(), t.Reason, t.ProvenanceID, agent, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.ProvenanceID != "" {
+		ref, err := fhirtypes.ResourceReference("Provenance/" + t.ProvenanceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		out.RelevantHistory = append(out.RelevantHistory, ref)
+	}
+	return out, prov, nil
+}
+
+// provenance records a change by agent at now of the resource of
+// resourceType with the id and meta it had before the change.
+func provenance(resourceType, id string, meta *d4pb.Meta, reason *d4pb.CodeableConcept, provenanceID string, agent *d4pb.Reference, now time.Time) (*provpb.Provenance, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%s has no id", strings.ToLower(resourceType))
+	}
+	target, err := fhirtypes.ResourceReference(resourceType + "/" + id)
+	if err != nil {
+		return nil, err
+	}
+	prov := &provpb.Provenance{
+		Target:   []*d4pb.Reference{target},
+		Recorded: &d4pb.Instant{ValueUs: now.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_MICROSECOND},
+		Activity: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+			System: &d4pb.Uri{Value: "http://terminology.hl7.org/CodeSystem/v3-DataOperation"},
+			Code:   &d4pb.Code{Value: "UPDATE"},
+		}}},
+		Agent: []*provpb.Provenance_Agent{{Who: agent}},
+	}
+	if provenanceID != "" {
+		prov.Id = &d4pb.Id{Value: provenanceID}
+	}
+	if reason != nil {
+		prov.Reason = []*d4pb.CodeableConcept{reason}
+	}
+	if v := meta.GetVersionId().GetValue(); v != "" {
+		// The previous version of the resource is the entity that was
+		// revised.
+		what, err := fhirtypes.ResourceReference(resourceType + "/" + id + "/_history/" + v)
+		if err != nil {
+			return nil, err
+		}
+		prov.Entity = []*provpb.Provenance_Entity{{
+			Role: &provpb.Provenance_Entity_RoleCode{Value: c4pb.ProvenanceEntityRoleCode_REVISION},
+			What: what,
+		}}
+	}
+	return prov, nil
+}
+
+// checkRestriction returns an error if now is outside the period of r.
+func checkRestriction(r *tpb.Task_Restriction, now time.Time) error {
+	start, end := r.GetPeriod().GetStart(), r.GetPeriod().GetEnd()
+	if start != nil && now.Before(time.UnixMicro(start.GetValueUs())) {
+		return fmt.Errorf("task cannot start before its restriction period")
+	}
+	if end != nil && !now.Before(periodEnd(end)) {
+		return fmt.Errorf("task cannot start after its restriction period")
+	}
+	return nil
+}
+
+// periodEnd returns the instant after the end of a period given at the
+// precision of end, i.e. the next day for a date.
+func periodEnd(end *d4pb.DateTime) time.Time {
+	t := time.UnixMicro(end.GetValueUs())
+	switch end.GetPrecision() {
+	case d4pb.DateTime_YEAR:
+		return t.AddDate(1, 0, 0)
+	case d4pb.DateTime_MONTH:
+		return t.AddDate(0, 1, 0)
+	case d4pb.DateTime_DAY:
+		return t.AddDate(0, 0, 1)
+	case d4pb.DateTime_SECOND:
+		return t.Add(time.Second)
+	case d4pb.DateTime_MILLISECOND:
+		return t.Add(time.Millisecond)
+	}
+	return t.Add(time.Microsecond)
+}
+
+// Restrict sets the restriction of task to the given number of repetitions,
+// period and recipients, given as relative references. Zero values leave the
+// corresponding element unset.
+func Restrict(task *tpb.Task, repetitions int, period *d4pb.Period, recipients ...string) error {
+	r := &tpb.Task_Restriction{Period: period}
+	if repetitions < 0 {
+		return fmt.Errorf("negative restriction repetitions %d", repetitions)
+	}
+	if repetitions > 0 {
+		r.Repetitions = &d4pb.PositiveInt{Value: uint32(repetitions)}
+	}
+	for _, rec := range recipients {
+		ref, err := fhirtypes.ResourceReference(rec)
+		if err != nil {
+			return err
+		}
+		r.Recipient = append(r.Recipient, ref)
+	}
+	task.Restriction = r
+	return nil
+}
+
+// Output returns a Task.output of type typ holding value, which is converted
+// to one of the types allowed for Task.output.value[x]. Bare FHIRPath system
+// values such as strings are accepted too.
+func Output(typ *d4pb.CodeableConcept, value interface{}) (*tpb.Task_Output, error) {
+	m, ok := value.(proto.Message)
+	if !ok {
+		if m, ok = fhirpath.ProtoValue(value); !ok {
+			return nil, fmt.Errorf("output value %v has no FHIR representation", value)
+		}
+	}
+	out := &tpb.Task_Output{Type: typ}
+	if err := elementpath.Set(out.ProtoReflect(), "value", m); err != nil {
+		return nil, fmt.Errorf("output value: %w", err)
+	}
+	return out, nil
+}
+
+// Outputs returns the values of the outputs of task whose type has a coding
+// with the given system and code.
+func Outputs(task *tpb.Task, system, code string) []proto.Message {
+	var out []proto.Message
+	for _, o := range task.GetOutput() {
+		for _, c := range o.GetType().GetCoding() {
+			if c.GetSystem().GetValue() == system && c.GetCode().GetValue() == code {
+				rm := o.GetValue().ProtoReflect()
+				if set := rm.WhichOneof(rm.Descriptor().Oneofs().Get(0)); set != nil {
+					out = append(out, rm.Get(set).Message().Interface())
+				}
+				break
+			}
+		}
+	}
+	return out
+}
+
+func statusCode(s c4pb.TaskStatusCode_Value) string {
+	return fhirpath.CodeString(s.Descriptor().Values().ByNumber(s.Number()))
+}
+
+func dateTime(t time.Time) *d4pb.DateTime {
+	dt, _ := fhirpath.ProtoValue(fhirpath.Temporal{Kind: fhirpath.DateTime, Time: t, Precision: fhirpath.PrecisionSecond})
+	return dt.(*d4pb.DateTime)
+}
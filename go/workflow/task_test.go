@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	provpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/provenance_go_proto"
+	tpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/task_go_proto"
+)
+
+var (
+	started  = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	finished = time.Date(2025, 3, 1, 17, 30, 0, 0, time.UTC)
+)
+
+func dt(t time.Time) *d4pb.DateTime {
+	return &d4pb.DateTime{ValueUs: t.UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_SECOND}
+}
+
+func practitioner(id string) *d4pb.Reference {
+	return &d4pb.Reference{Reference: &d4pb.Reference_PractitionerId{PractitionerId: &d4pb.ReferenceId{Value: id}}}
+}
+
+func task(status c4pb.TaskStatusCode_Value) *tpb.Task {
+	return &tpb.Task{
+		Id:     &d4pb.Id{Value: "t1"},
+		Meta:   &d4pb.Meta{VersionId: &d4pb.Id{Value: "3"}},
+		Status: &tpb.Task_StatusCode{Value: status},
+		Owner:  practitioner("owner"),
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to c4pb.TaskStatusCode_Value
+		want     bool
+	}{
+		{c4pb.TaskStatusCode_REQUESTED, c4pb.TaskStatusCode_ACCEPTED, true},
+		{c4pb.TaskStatusCode_ACCEPTED, c4pb.TaskStatusCode_IN_PROGRESS, true},
+		{c4pb.TaskStatusCode_IN_PROGRESS, c4pb.TaskStatusCode_COMPLETED, true},
+		{c4pb.TaskStatusCode_IN_PROGRESS, c4pb.TaskStatusCode_FAILED, true},
+		{c4pb.TaskStatusCode_COMPLETED, c4pb.TaskStatusCode_ENTERED_IN_ERROR, true},
+		{c4pb.TaskStatusCode_REQUESTED, c4pb.TaskStatusCode_COMPLETED, false},
+		{c4pb.TaskStatusCode_COMPLETED, c4pb.TaskStatusCode_IN_PROGRESS, false},
+		{c4pb.TaskStatusCode_ENTERED_IN_ERROR, c4pb.TaskStatusCode_ENTERED_IN_ERROR, false},
+		{c4pb.TaskStatusCode_INVALID_UNINITIALIZED, c4pb.TaskStatusCode_REQUESTED, false},
+	}
+	for _, test := range tests {
+		if got := CanTransition(test.from, test.to); got != test.want {
+			t.Errorf("CanTransition(%v, %v) = %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	resultType := &d4pb.CodeableConcept{Text: &d4pb.String{Value: "result"}}
+	output, err := Output(resultType, "done")
+	if err != nil {
+		t.Fatalf("Output() returned unexpected error: %v", err)
+	}
+	inProgress := func() *tpb.Task {
+		tk := task(c4pb.TaskStatusCode_IN_PROGRESS)
+		tk.ExecutionPeriod = &d4pb.Period{Start: dt(started)}
+		return tk
+	}
+	tests := []struct {
+		name     string
+		task     *tpb.Task
+		t        Transition
+		want     *tpb.Task
+		wantProv *provpb.Provenance
+	}{
+		{
+			name: "start",
+			task: task(c4pb.TaskStatusCode_ACCEPTED),
+			t:    Transition{To: c4pb.TaskStatusCode_IN_PROGRESS, Time: started},
+			want: func() *tpb.Task {
+				tk := inProgress()
+				tk.LastModified = dt(started)
+				return tk
+			}(),
+			wantProv: &provpb.Provenance{
+				Target:   []*d4pb.Reference{{Reference: &d4pb.Reference_TaskId{TaskId: &d4pb.ReferenceId{Value: "t1"}}}},
+				Recorded: &d4pb.Instant{ValueUs: started.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_MICROSECOND},
+				Activity: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+					System: &d4pb.Uri{Value: "http://terminology.hl7.org/CodeSystem/v3-DataOperation"},
+					Code:   &d4pb.Code{Value: "UPDATE"},
+				}}},
+				Agent: []*provpb.Provenance_Agent{{Who: practitioner("owner")}},
+				Entity: []*provpb.Provenance_Entity{{
+					Role: &provpb.Provenance_Entity_RoleCode{Value: c4pb.ProvenanceEntityRoleCode_REVISION},
+					What: &d4pb.Reference{Reference: &d4pb.Reference_TaskId{TaskId: &d4pb.ReferenceId{Value: "t1", History: &d4pb.Id{Value: "3"}}}},
+				}},
+			},
+		},
+		{
+			name: "complete",
+			task: inProgress(),
+			t: Transition{
+				To: c4pb.TaskStatusCode_COMPLETED, Agent: "Practitioner/p1", Time: finished,
+				Outputs: []*tpb.Task_Output{output}, ProvenanceID: "prov1",
+			},
+			want: func() *tpb.Task {
+				tk := inProgress()
+				tk.Status.Value = c4pb.TaskStatusCode_COMPLETED
+				tk.ExecutionPeriod.End = dt(finished)
+				tk.LastModified = dt(finished)
+				tk.Output = []*tpb.Task_Output{{
+					Type:  resultType,
+					Value: &tpb.Task_Output_ValueX{Choice: &tpb.Task_Output_ValueX_StringValue{StringValue: &d4pb.String{Value: "done"}}},
+				}}
+				tk.RelevantHistory = []*d4pb.Reference{{Reference: &d4pb.Reference_ProvenanceId{ProvenanceId: &d4pb.ReferenceId{Value: "prov1"}}}}
+				return tk
+			}(),
+			wantProv: &provpb.Provenance{
+				Id:       &d4pb.Id{Value: "prov1"},
+				Target:   []*d4pb.Reference{{Reference: &d4pb.Reference_TaskId{TaskId: &d4pb.ReferenceId{Value: "t1"}}}},
+				Recorded: &d4pb.Instant{ValueUs: finished.UnixMicro(), Timezone: "Z", Precision: d4pb.Instant_MICROSECOND},
+				Activity: &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{
+					System: &d4pb.Uri{Value: "http://terminology.hl7.org/CodeSystem/v3-DataOperation"},
+					Code:   &d4pb.Code{Value: "UPDATE"},
+				}}},
+				Agent: []*provpb.Provenance_Agent{{Who: practitioner("p1")}},
+				Entity: []*provpb.Provenance_Entity{{
+					Role: &provpb.Provenance_Entity_RoleCode{Value: c4pb.ProvenanceEntityRoleCode_REVISION},
+					What: &d4pb.Reference{Reference: &d4pb.Reference_TaskId{TaskId: &d4pb.ReferenceId{Value: "t1", History: &d4pb.Id{Value: "3"}}}},
+				}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := proto.Clone(test.task)
+			got, prov, err := Apply(test.task, test.t)
+			if err != nil {
+				t.Fatalf("Apply() returned unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Apply() task diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantProv, prov, protocmp.Transform()); diff != "" {
+				t.Errorf("Apply() provenance diff (-want +got):\n%s", diff)
+			}
+			if !proto.Equal(before, test.task) {
+				t.Errorf("Apply() modified its input task")
+			}
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	restricted := task(c4pb.TaskStatusCode_ACCEPTED)
+	if err := Restrict(restricted, 1, &d4pb.Period{End: &d4pb.DateTime{
+		ValueUs: time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC).UnixMicro(), Timezone: "Z", Precision: d4pb.DateTime_DAY,
+	}}, "Practitioner/p1"); err != nil {
+		t.Fatalf("Restrict() returned unexpected error: %v", err)
+	}
+	unowned := task(c4pb.TaskStatusCode_REQUESTED)
+	unowned.Owner = nil
+	tests := []struct {
+		name string
+		task *tpb.Task
+		t    Transition
+	}{
+		{"illegal transition", task(c4pb.TaskStatusCode_REQUESTED), Transition{To: c4pb.TaskStatusCode_COMPLETED}},
+		{"terminal status", task(c4pb.TaskStatusCode_CANCELLED), Transition{To: c4pb.TaskStatusCode_IN_PROGRESS}},
+		{"no agent", unowned, Transition{To: c4pb.TaskStatusCode_ACCEPTED}},
+		{"bad agent", task(c4pb.TaskStatusCode_REQUESTED), Transition{To: c4pb.TaskStatusCode_ACCEPTED, Agent: "not a reference"}},
+		{"after restriction period", restricted, Transition{To: c4pb.TaskStatusCode_IN_PROGRESS, Time: started}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := Apply(test.task, test.t); err == nil {
+				t.Errorf("Apply() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	typ := &d4pb.CodeableConcept{Coding: []*d4pb.Coding{{System: &d4pb.Uri{Value: "http://example.com"}, Code: &d4pb.Code{Value: "report"}}}}
+	report := &d4pb.Reference{Reference: &d4pb.Reference_DiagnosticReportId{DiagnosticReportId: &d4pb.ReferenceId{Value: "r1"}}}
+	o, err := Output(typ, report)
+	if err != nil {
+		t.Fatalf("Output() returned unexpected error: %v", err)
+	}
+	tk := &tpb.Task{Output: []*tpb.Task_Output{o}}
+	got := Outputs(tk, "http://example.com", "report")
+	if diff := cmp.Diff([]proto.Message{report}, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Outputs() diff (-want +got):\n%s", diff)
+	}
+	if got := Outputs(tk, "http://example.com", "other"); len(got) != 0 {
+		t.Errorf("Outputs() of another type = %v, want none", got)
+	}
+	if _, err := Output(typ, []interface{}{"a"}); err == nil {
+		t.Errorf("Output() of a list succeeded, want error")
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x12
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	clpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/claim_go_proto"
+)
+
+const (
+	icd10PCSSystem     = "http://www.cms.gov/Medicare/Coding/ICD10"
+	careTeamRoleSystem = "http://terminology.hl7.org/CodeSystem/claimcareteamrole"
+)
+
+// claimType returns the claim-type code for the implementation guide of an
+// 837 transaction.
+func claimType(version string) (string, error) {
+	switch {
+	case strings.Contains(version, "X222"):
+		return "professional", nil
+	case strings.Contains(version, "X223"):
+		return "institutional", nil
+	case strings.Contains(version, "X224"):
+		return "oral", nil
+	}
+	return "", fmt.Errorf("unsupported 837 implementation guide %q", version)
+}
+
+// ToClaims converts an 837 professional, institutional or dental transaction
+// into one Claim per CLM segment. The patient, provider, insurer and coverage
+// are referenced by identifier.
+func ToClaims(tx *Transaction, opts Options) ([]*clpb.Claim, error) {
+	if tx.Type != "837" {
+		return nil, fmt.Errorf("transaction %s is a %s, not an 837", tx.ControlNumber, tx.Type)
+	}
+	typ, err := claimType(tx.Version)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newConverter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		claims                              []*clpb.Claim
+		cur                                 *clpb.Claim
+		item                                *clpb.Claim_Item
+		created                             *d4pb.DateTime
+		billing, subscriber, patient, payer party
+	)
+	for n, s := range tx.Segments {
+		var err error
+		switch s.ID {
+		case "BHT":
+			created, err = c.dateTime(s.Element(4))
+		case "HL":
+			cur, item = nil, nil
+			switch s.Element(3) {
+			case "20":
+				billing, subscriber, patient, payer = party{}, party{}, party{}, party{}
+			case "22":
+				subscriber, patient, payer = party{}, party{}, party{}
+			case "23":
+				patient = party{}
+			}
+		case "NM1":
+			p := nm1Party(s)
+			switch s.Element(1) {
+			case "85":
+				billing = p
+			case "IL":
+				subscriber = p
+			case "QC":
+				patient = p
+			case "PR":
+				payer = p
+			case "82":
+				if cur != nil {
+					addCareTeam(cur, item, p)
+				}
+			}
+		case "CLM":
+			cur, item = c.newClaim(s, typ, created, billing, subscriber, patient, payer), nil
+			claims = append(claims, cur)
+		case "HI":
+			if cur != nil {
+				addDiagnoses(cur, s)
+			}
+		case "LX":
+			if cur != nil {
+				item = &clpb.Claim_Item{Sequence: &d4pb.PositiveInt{Value: uint32(len(cur.Item) + 1)}}
+				cur.Item = append(cur.Item, item)
+			}
+		case "SV1", "SV2", "SV3":
+			if item != nil {
+				c.setService(item, s)
+			}
+		case "DTP":
+			err = c.setClaimDate(cur, item, s)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s, segment %d (%s): %w", tx.ControlNumber, n+1, s.ID, err)
+		}
+	}
+	return claims, nil
+}
+
+func (c *converter) newClaim(s Segment, typ string, created *d4pb.DateTime, billing, subscriber, patient, payer party) *clpb.Claim {
+	if patient.name == "" && patient.id == "" {
+		patient = subscriber
+	}
+	patientID := patient.id
+	if patient.idQualifier != "" && patient.idQualifier != "MI" {
+		patientID = ""
+	}
+	payerSystem := ""
+	if payer.idQualifier == "PI" {
+		payerSystem = c.opts.PayerIDSystem
+	}
+	cl := &clpb.Claim{
+		Identifier: []*d4pb.Identifier{{Value: &d4pb.String{Value: s.Element(1)}}},
+		Status:     &clpb.Claim_StatusCode{Value: c4pb.FinancialResourceStatusCode_ACTIVE},
+		Type:       concept(ClaimTypeSystem, typ),
+		Use:        &clpb.Claim_UseCode{Value: c4pb.UseCode_CLAIM},
+		Patient:    identifierReference(c.opts.MemberIDSystem, patientID, patient.name),
+		Created:    created,
+		Insurer:    identifierReference(payerSystem, payer.id, payer.name),
+		Provider:   providerReference(billing),
+		Priority:   concept(ProcessPrioritySystem, "normal"),
+		Insurance: []*clpb.Claim_Insurance{{
+			Sequence: &d4pb.PositiveInt{Value: 1},
+			Focal:    &d4pb.Boolean{Value: true},
+			Coverage: identifierReference(c.opts.MemberIDSystem, subscriber.id, payer.name),
+		}},
+		Total: c.money(s.Element(2)),
+	}
+	return cl
+}
+
+func addCareTeam(cl *clpb.Claim, item *clpb.Claim_Item, p party) {
+	seq := &d4pb.PositiveInt{Value: uint32(len(cl.CareTeam) + 1)}
+	cl.CareTeam = append(cl.CareTeam, &clpb.Claim_CareTeam{
+		Sequence: seq,
+		Provider: providerReference(p),
+		Role:     concept(careTeamRoleSystem, "performing"),
+	})
+	if item != nil {
+		item.CareTeamSequence = append(item.CareTeamSequence, seq)
+	}
+}
+
+// addDiagnoses adds the diagnosis and procedure codes of an HI segment.
+func addDiagnoses(cl *clpb.Claim, s Segment) {
+	for i := 1; i <= len(s.Elements); i++ {
+		qual, code := s.Component(i, 1), s.Component(i, 2)
+		if code == "" {
+			continue
+		}
+		var system, dxType string
+		switch qual {
+		case "ABK", "BK":
+			dxType = "principal"
+		case "ABJ", "BJ":
+			dxType = "admitting"
+		case "ABF", "BF", "ABN", "BN":
+		case "BBR", "BBQ":
+			cl.Procedure = append(cl.Procedure, &clpb.Claim_Procedure{
+				Sequence: &d4pb.PositiveInt{Value: uint32(len(cl.Procedure) + 1)},
+				Procedure: &clpb.Claim_Procedure_ProcedureX{
+					Choice: &clpb.Claim_Procedure_ProcedureX_CodeableConcept{CodeableConcept: concept(icd10PCSSystem, code)},
+				},
+			})
+			continue
+		default:
+			continue
+		}
+		if strings.HasPrefix(qual, "A") {
+			system, code = ICD10CMSystem, icd10(code)
+		} else {
+			system, code = ICD9CMSystem, icd9(code)
+		}
+		dx := &clpb.Claim_Diagnosis{
+			Sequence: &d4pb.PositiveInt{Value: uint32(len(cl.Diagnosis) + 1)},
+			Diagnosis: &clpb.Claim_Diagnosis_DiagnosisX{
+				Choice: &clpb.Claim_Diagnosis_DiagnosisX_CodeableConcept{CodeableConcept: concept(system, code)},
+			},
+		}
+		if dxType != "" {
+			dx.Type = []*d4pb.CodeableConcept{concept(DiagnosisTypeSystem, dxType)}
+		}
+		cl.Diagnosis = append(cl.Diagnosis, dx)
+	}
+}
+
+// setService fills a claim item from an SV1 (professional), SV2
+// (institutional) or SV3 (dental) segment.
+func (c *converter) setService(item *clpb.Claim_Item, s Segment) {
+	var amount, quantity string
+	switch s.ID {
+	case "SV1":
+		item.ProductOrService, item.Modifier = procedureConcept(s.Components(1))
+		amount, quantity = s.Element(2), s.Element(4)
+		for _, p := range s.Components(7) {
+			if n, err := strconv.Atoi(p); err == nil && n > 0 {
+				item.DiagnosisSequence = append(item.DiagnosisSequence, &d4pb.PositiveInt{Value: uint32(n)})
+			}
+		}
+	case "SV2":
+		if rev := s.Element(1); rev != "" {
+			item.Revenue = concept(RevenueCodeSystem, rev)
+		}
+		item.ProductOrService, item.Modifier = procedureConcept(s.Components(2))
+		amount, quantity = s.Element(3), s.Element(5)
+	case "SV3":
+		item.ProductOrService, item.Modifier = procedureConcept(s.Components(1))
+		amount, quantity = s.Element(2), s.Element(6)
+	}
+	if item.ProductOrService == nil {
+		item.ProductOrService = notApplicable()
+	}
+	item.Net = c.money(amount)
+	if quantity != "" {
+		item.Quantity = &d4pb.SimpleQuantity{Value: &d4pb.Decimal{Value: quantity}}
+	}
+}
+
+// setClaimDate handles the DTP segments for service dates (472) and the
+// statement period (434).
+func (c *converter) setClaimDate(cl *clpb.Claim, item *clpb.Claim_Item, s Segment) error {
+	if cl == nil {
+		return nil
+	}
+	qual, format, value := s.Element(1), s.Element(2), s.Element(3)
+	switch {
+	case qual == "472" && item != nil:
+		if format == "D8" {
+			d, err := c.date(value)
+			if err != nil {
+				return err
+			}
+			item.Serviced = &clpb.Claim_Item_ServicedX{Choice: &clpb.Claim_Item_ServicedX_Date{Date: d}}
+			return nil
+		}
+		p, err := c.period(value)
+		if err != nil {
+			return err
+		}
+		item.Serviced = &clpb.Claim_Item_ServicedX{Choice: &clpb.Claim_Item_ServicedX_Period{Period: p}}
+	case qual == "434" || qual == "472":
+		p, err := c.period(value)
+		if err != nil {
+			return err
+		}
+		cl.BillablePeriod = p
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x12
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	clpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/claim_go_proto"
+)
+
+const memberSystem = "urn:example:members"
+
+var professional837 = []string{
+	"BHT*0019*00*244579*20200102*1023*CH",
+	"NM1*41*2*SUBMITTER*****46*TGJ23",
+	"HL*1**20*1",
+	"NM1*85*2*BEN KILDARE SERVICE*****XX*9876543210",
+	"HL*2*1*22*0",
+	"SBR*P*18*******CI",
+	"NM1*IL*1*SMITH*JANE****MI*JS00111223333",
+	"NM1*PR*2*KEY INSURANCE COMPANY*****PI*999996666",
+	"CLM*26463774*100***11:B:1*Y*A*Y*I",
+	"HI*ABK:J020*ABF:R509",
+	"NM1*82*1*KILDARE*BEN****XX*1234567804",
+	"LX*1",
+	"SV1*HC:99213:25*40*UN*1***1:2",
+	"DTP*472*D8*20200101",
+	"LX*2",
+	"SV1*HC:87070*60*UN*1***1",
+	"DTP*472*RD8*20200101-20200102",
+}
+
+func dt(us int64) *d4pb.DateTime {
+	return &d4pb.DateTime{ValueUs: us, Timezone: "UTC", Precision: d4pb.DateTime_DAY}
+}
+
+func usd(v string) *d4pb.Money {
+	return &d4pb.Money{Value: &d4pb.Decimal{Value: v}, Currency: &d4pb.Money_CurrencyCode{Value: "USD"}}
+}
+
+func pos(vs ...uint32) []*d4pb.PositiveInt {
+	var out []*d4pb.PositiveInt
+	for _, v := range vs {
+		out = append(out, &d4pb.PositiveInt{Value: v})
+	}
+	return out
+}
+
+const (
+	jan1 = 1577836800000000
+	jan2 = jan1 + 86400000000
+)
+
+func TestToClaims(t *testing.T) {
+	txs, err := Parse(interchange("837", "005010X222A1", professional837...))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	got, err := ToClaims(txs[0], Options{MemberIDSystem: memberSystem})
+	if err != nil {
+		t.Fatalf("ToClaims() returned unexpected error: %v", err)
+	}
+	dx := func(seq uint32, code, typ string) *clpb.Claim_Diagnosis {
+		d := &clpb.Claim_Diagnosis{
+			Sequence: &d4pb.PositiveInt{Value: seq},
+			Diagnosis: &clpb.Claim_Diagnosis_DiagnosisX{
+				Choice: &clpb.Claim_Diagnosis_DiagnosisX_CodeableConcept{CodeableConcept: concept(ICD10CMSystem, code)},
+			},
+		}
+		if typ != "" {
+			d.Type = []*d4pb.CodeableConcept{concept(DiagnosisTypeSystem, typ)}
+		}
+		return d
+	}
+	want := []*clpb.Claim{{
+		Identifier: []*d4pb.Identifier{{Value: &d4pb.String{Value: "26463774"}}},
+		Status:     &clpb.Claim_StatusCode{Value: c4pb.FinancialResourceStatusCode_ACTIVE},
+		Type:       concept(ClaimTypeSystem, "professional"),
+		Use:        &clpb.Claim_UseCode{Value: c4pb.UseCode_CLAIM},
+		Patient:    identifierReference(memberSystem, "JS00111223333", "JANE SMITH"),
+		Created:    dt(jan2),
+		Insurer:    identifierReference("", "999996666", "KEY INSURANCE COMPANY"),
+		Provider:   identifierReference(NPISystem, "9876543210", "BEN KILDARE SERVICE"),
+		Priority:   concept(ProcessPrioritySystem, "normal"),
+		CareTeam: []*clpb.Claim_CareTeam{{
+			Sequence: &d4pb.PositiveInt{Value: 1},
+			Provider: identifierReference(NPISystem, "1234567804", "BEN KILDARE"),
+			Role:     concept(careTeamRoleSystem, "performing"),
+		}},
+		Diagnosis: []*clpb.Claim_Diagnosis{dx(1, "J02.0", "principal"), dx(2, "R50.9", "")},
+		Insurance: []*clpb.Claim_Insurance{{
+			Sequence: &d4pb.PositiveInt{Value: 1},
+			Focal:    &d4pb.Boolean{Value: true},
+			Coverage: identifierReference(memberSystem, "JS00111223333", "KEY INSURANCE COMPANY"),
+		}},
+		Item: []*clpb.Claim_Item{
+			{
+				Sequence:          &d4pb.PositiveInt{Value: 1},
+				DiagnosisSequence: pos(1, 2),
+				ProductOrService:  concept(CPTSystem, "99213"),
+				Modifier:          []*d4pb.CodeableConcept{concept(CPTSystem, "25")},
+				Serviced: &clpb.Claim_Item_ServicedX{Choice: &clpb.Claim_Item_ServicedX_Date{
+					Date: &d4pb.Date{ValueUs: jan1, Timezone: "UTC", Precision: d4pb.Date_DAY},
+				}},
+				Quantity: &d4pb.SimpleQuantity{Value: &d4pb.Decimal{Value: "1"}},
+				Net:      usd("40"),
+			},
+			{
+				Sequence:          &d4pb.PositiveInt{Value: 2},
+				DiagnosisSequence: pos(1),
+				ProductOrService:  concept(CPTSystem, "87070"),
+				Serviced: &clpb.Claim_Item_ServicedX{Choice: &clpb.Claim_Item_ServicedX_Period{
+					Period: &d4pb.Period{Start: dt(jan1), End: dt(jan2)},
+				}},
+				Quantity: &d4pb.SimpleQuantity{Value: &d4pb.Decimal{Value: "1"}},
+				Net:      usd("60"),
+			},
+		},
+		Total: usd("100"),
+	}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ToClaims() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestToClaims_Institutional(t *testing.T) {
+	txs, err := Parse(interchange("837", "005010X223A2",
+		"BHT*0019*00*1*20200102*1023*CH",
+		"HL*1**20*1",
+		"NM1*85*2*GENERAL HOSPITAL*****XX*9876543210",
+		"HL*2*1*22*1",
+		"NM1*IL*1*SMITH*JOHN****MI*11122333301",
+		"NM1*PR*2*PAYER*****PI*00435",
+		"HL*3*2*23*0",
+		"NM1*QC*1*SMITH*TED",
+		"CLM*756048Q*89.93***14:A:1**A*Y*Y",
+		"DTP*434*RD8*20200101-20200102",
+		"HI*BK:E8490*BF:4019",
+		"HI*BBR:0DTJ4ZZ",
+		"LX*1",
+		"SV2*0305**89.93*UN*1",
+	))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	got, err := ToClaims(txs[0], Options{})
+	if err != nil {
+		t.Fatalf("ToClaims() returned unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("ToClaims() returned %d claims, want 1", len(got))
+	}
+	cl := got[0]
+	checks := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"type", cl.GetType(), concept(ClaimTypeSystem, "institutional")},
+		{"patient", cl.GetPatient(), identifierReference("", "", "TED SMITH")},
+		{"coverage", cl.GetInsurance()[0].GetCoverage(), identifierReference("", "11122333301", "PAYER")},
+		{"billable period", cl.GetBillablePeriod(), &d4pb.Period{Start: dt(jan1), End: dt(jan2)}},
+		{"diagnosis 1", cl.GetDiagnosis()[0].GetDiagnosis().GetCodeableConcept(), concept(ICD9CMSystem, "E849.0")},
+		{"diagnosis 2", cl.GetDiagnosis()[1].GetDiagnosis().GetCodeableConcept(), concept(ICD9CMSystem, "401.9")},
+		{"procedure", cl.GetProcedure()[0].GetProcedure().GetCodeableConcept(), concept(icd10PCSSystem, "0DTJ4ZZ")},
+		{"revenue", cl.GetItem()[0].GetRevenue(), concept(RevenueCodeSystem, "0305")},
+		{"product", cl.GetItem()[0].GetProductOrService(), notApplicable()},
+	}
+	for _, c := range checks {
+		if diff := cmp.Diff(c.want, c.got, protocmp.Transform()); diff != "" {
+			t.Errorf("ToClaims() %s unexpected diff (-want +got):\n%s", c.name, diff)
+		}
+	}
+}
+
+func TestToClaims_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   *Transaction
+		opts Options
+	}{
+		{"not an 837", &Transaction{Type: "835", Version: "005010X221A1"}, Options{}},
+		{"unknown guide", &Transaction{Type: "837", Version: "004010X098A1"}, Options{}},
+		{"bad time zone", &Transaction{Type: "837", Version: "005010X222A1"}, Options{TimeZone: "Nowhere/Special"}},
+		{"bad date", &Transaction{Type: "837", Version: "005010X222A1", Segments: []Segment{{ID: "BHT", Elements: []string{"0019", "00", "1", "2020"}}}}, Options{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ToClaims(test.tx, test.opts); err == nil {
+				t.Errorf("ToClaims() succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x12
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/fhir/go/fhirtypes"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+)
+
+// Code systems used in the generated resources.
+const (
+	NPISystem                = "http://hl7.org/fhir/sid/us-npi"
+	ICD10CMSystem            = "http://hl7.org/fhir/sid/icd-10-cm"
+	ICD9CMSystem             = "http://hl7.org/fhir/sid/icd-9-cm"
+	CPTSystem                = "http://www.ama-assn.org/go/cpt"
+	HCPCSSystem              = "urn:oid:2.16.840.1.113883.6.285"
+	RevenueCodeSystem        = "https://www.nubc.org/CodeSystem/RevenueCodes"
+	ClaimTypeSystem          = "http://terminology.hl7.org/CodeSystem/claim-type"
+	ProcessPrioritySystem    = "http://terminology.hl7.org/CodeSystem/processpriority"
+	DiagnosisTypeSystem      = "http://terminology.hl7.org/CodeSystem/ex-diagnosistype"
+	AdjudicationSystem       = "http://terminology.hl7.org/CodeSystem/adjudication"
+	PaymentTypeSystem        = "http://terminology.hl7.org/CodeSystem/ex-paymenttype"
+	DataAbsentReasonSystem   = "http://terminology.hl7.org/CodeSystem/data-absent-reason"
+	CARCSystem               = "https://x12.org/codes/claim-adjustment-reason-codes"
+	C4BBAdjudicationSystem   = "http://hl7.org/fhir/us/carin-bb/CodeSystem/C4BBAdjudication"
+	C4BBIdentifierTypeSystem = "http://hl7.org/fhir/us/carin-bb/CodeSystem/C4BBIdentifierType"
+)
+
+// Options configure the conversion of X12 transactions.
+type Options struct {
+	// MemberIDSystem is the identifier system of subscriber and patient member
+	// ids. The payer specific system cannot be derived from the transaction.
+	MemberIDSystem string
+	// PayerIDSystem is the identifier system of payer ids.
+	PayerIDSystem string
+	// Currency is the currency code of all amounts. Defaults to USD.
+	Currency string
+	// TimeZone is the location dates are interpreted in. Defaults to UTC.
+	TimeZone string
+}
+
+type converter struct {
+	opts Options
+	loc  *time.Location
+	tz   string
+}
+
+func newConverter(opts Options) (*converter, error) {
+	c := &converter{opts: opts, loc: time.UTC, tz: "UTC"}
+	if c.opts.Currency == "" {
+		c.opts.Currency = "USD"
+	}
+	if opts.TimeZone != "" {
+		loc, err := time.LoadLocation(opts.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", opts.TimeZone, err)
+		}
+		c.loc, c.tz = loc, opts.TimeZone
+	}
+	return c, nil
+}
+
+func (c *converter) money(amount string) *d4pb.Money {
+	if amount == "" {
+		return nil
+	}
+	return &d4pb.Money{
+		Value:    &d4pb.Decimal{Value: amount},
+		Currency: &d4pb.Money_CurrencyCode{Value: c.opts.Currency},
+	}
+}
+
+// date parses a CCYYMMDD date.
+func (c *converter) date(s string) (*d4pb.Date, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation("20060102", s, c.loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", s)
+	}
+	return &d4pb.Date{ValueUs: t.UnixMicro(), Timezone: c.tz, Precision: d4pb.Date_DAY}, nil
+}
+
+func (c *converter) dateTime(s string) (*d4pb.DateTime, error) {
+	d, err := c.date(s)
+	if d == nil || err != nil {
+		return nil, err
+	}
+	return &d4pb.DateTime{ValueUs: d.GetValueUs(), Timezone: d.GetTimezone(), Precision: d4pb.DateTime_DAY}, nil
+}
+
+// period parses a CCYYMMDD-CCYYMMDD date range, or a single date as a period
+// of one day.
+func (c *converter) period(s string) (*d4pb.Period, error) {
+	start, end := s, s
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		start, end = s[:i], s[i+1:]
+	}
+	p := &d4pb.Period{}
+	var err error
+	if p.Start, err = c.dateTime(start); err != nil {
+		return nil, err
+	}
+	if p.End, err = c.dateTime(end); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func concept(system, code string) *d4pb.CodeableConcept {
+	return &d4pb.CodeableConcept{Coding: []*d4pb.Coding{fhirtypes.Coding(system, code)}}
+}
+
+// identifierReference returns a logical reference by identifier, as the
+// referenced resources are generally not known when converting.
+func identifierReference(system, value, display string) *d4pb.Reference {
+	if value == "" && display == "" {
+		return nil
+	}
+	ref := &d4pb.Reference{}
+	if value != "" {
+		ref.Identifier = &d4pb.Identifier{Value: &d4pb.String{Value: value}}
+		if system != "" {
+			ref.Identifier.System = &d4pb.Uri{Value: system}
+		}
+	}
+	if display != "" {
+		ref.Display = &d4pb.String{Value: display}
+	}
+	return ref
+}
+
+// party is an entity named in an NM1 or N1 segment.
+type party struct {
+	name, idQualifier, id string
+}
+
+// nm1Party reads an NM1 segment, formatting person names as "First Last".
+func nm1Party(s Segment) party {
+	name := s.Element(3)
+	if s.Element(2) == "1" {
+		name = strings.TrimSpace(strings.Join([]string{s.Element(4), s.Element(5), s.Element(3)}, " "))
+		name = strings.Join(strings.Fields(name), " ")
+	}
+	return party{name: name, idQualifier: s.Element(8), id: s.Element(9)}
+}
+
+func n1Party(s Segment) party {
+	return party{name: s.Element(2), idQualifier: s.Element(3), id: s.Element(4)}
+}
+
+// providerReference references a provider by NPI if the party is identified
+// by one.
+func providerReference(p party) *d4pb.Reference {
+	if p.idQualifier == "XX" {
+		return identifierReference(NPISystem, p.id, p.name)
+	}
+	return identifierReference("", "", p.name)
+}
+
+// procedureConcept converts a composite medical procedure identifier such as
+// "HC:99213:25" into a product or service code and modifiers.
+func procedureConcept(comps []string) (*d4pb.CodeableConcept, []*d4pb.CodeableConcept) {
+	if len(comps) < 2 || comps[1] == "" {
+		return nil, nil
+	}
+	system := CPTSystem
+	switch comps[0] {
+	case "HC":
+		if c := comps[1][0]; c < '0' || c > '9' {
+			system = HCPCSSystem
+		}
+	case "NU":
+		system = RevenueCodeSystem
+	}
+	code := concept(system, comps[1])
+	var mods []*d4pb.CodeableConcept
+	for _, m := range comps[2:] {
+		if m != "" && len(mods) < 4 {
+			mods = append(mods, concept(system, m))
+		}
+	}
+	return code, mods
+}
+
+// notApplicable is used for required codes that the transaction does not
+// provide, following CARIN BB.
+func notApplicable() *d4pb.CodeableConcept {
+	return concept(DataAbsentReasonSystem, "not-applicable")
+}
+
+// icd10 formats an ICD-10-CM code as transmitted in X12, without the decimal
+// point, in the dotted form used by FHIR.
+func icd10(code string) string {
+	if len(code) > 3 && !strings.Contains(code, ".") {
+		return code[:3] + "." + code[3:]
+	}
+	return code
+}
+
+// icd9 formats an ICD-9-CM diagnosis code in its dotted form. E codes have
+// four digits before the decimal point.
+func icd9(code string) string {
+	n := 3
+	if strings.HasPrefix(code, "E") {
+		n = 4
+	}
+	if len(code) > n && !strings.Contains(code, ".") {
+		return code[:n] + "." + code[n:]
+	}
+	return code
+}
@@ -0,0 +1,451 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x12
+
+import (
+	"fmt"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	crpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/claim_response_go_proto"
+	eobpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/explanation_of_benefit_go_proto"
+)
+
+// claimStatus describes the CLP02 claim status codes.
+var claimStatus = map[string]string{
+	"1":  "Processed as Primary",
+	"2":  "Processed as Secondary",
+	"3":  "Processed as Tertiary",
+	"4":  "Denied",
+	"19": "Processed as Primary, Forwarded to Additional Payer(s)",
+	"20": "Processed as Secondary, Forwarded to Additional Payer(s)",
+	"21": "Processed as Tertiary, Forwarded to Additional Payer(s)",
+	"22": "Reversal of Previous Payment",
+	"23": "Not Our Claim, Forwarded to Additional Payer(s)",
+	"25": "Predetermination Pricing Only - No Payment",
+}
+
+// adjustment is a single reason and amount of a CAS segment.
+type adjustment struct {
+	group, reason, amount string
+}
+
+// adjudication is an amount with its category, independent of the resource
+// type it ends up in.
+type adjudication struct {
+	category *d4pb.CodeableConcept
+	reason   *d4pb.CodeableConcept
+	amount   string
+}
+
+type remitLine struct {
+	productOrService *d4pb.CodeableConcept
+	modifiers        []*d4pb.CodeableConcept
+	revenue          *d4pb.CodeableConcept
+	charge, paid     string
+	allowed          string
+	quantity         string
+	serviced         *d4pb.Date
+	adjustments      []adjustment
+}
+
+type remitClaim struct {
+	id, status, charge, paid, patientResp, payerClaimID string
+	institutional                                       bool
+	patient, insured                                    party
+	statementPeriod                                     *d4pb.Period
+	adjustments                                         []adjustment
+	lines                                               []*remitLine
+}
+
+type remittance struct {
+	payer, payee party
+	paymentDate  *d4pb.Date
+	traceNumber  string
+	claims       []*remitClaim
+}
+
+// parseRemittance collects the parts of an 835 transaction used in the
+// generated resources.
+func (c *converter) parseRemittance(tx *Transaction) (*remittance, error) {
+	if tx.Type != "835" {
+		return nil, fmt.Errorf("transaction %s is a %s, not an 835", tx.ControlNumber, tx.Type)
+	}
+	r := &remittance{}
+	var (
+		claim *remitClaim
+		line  *remitLine
+	)
+	for n, s := range tx.Segments {
+		var err error
+		switch s.ID {
+		case "BPR":
+			r.paymentDate, err = c.date(s.Element(16))
+		case "TRN":
+			r.traceNumber = s.Element(2)
+		case "N1":
+			switch s.Element(1) {
+			case "PR":
+				r.payer = n1Party(s)
+			case "PE":
+				r.payee = n1Party(s)
+			}
+		case "CLP":
+			claim = &remitClaim{
+				id:            s.Element(1),
+				status:        s.Element(2),
+				charge:        s.Element(3),
+				paid:          s.Element(4),
+				patientResp:   s.Element(5),
+				payerClaimID:  s.Element(7),
+				institutional: s.Element(8) != "",
+			}
+			line = nil
+			r.claims = append(r.claims, claim)
+		case "NM1":
+			if claim == nil {
+				break
+			}
+			switch s.Element(1) {
+			case "QC":
+				claim.patient = nm1Party(s)
+			case "IL":
+				claim.insured = nm1Party(s)
+			}
+		case "CAS":
+			adj := casAdjustments(s)
+			switch {
+			case line != nil:
+				line.adjustments = append(line.adjustments, adj...)
+			case claim != nil:
+				claim.adjustments = append(claim.adjustments, adj...)
+			}
+		case "DTM":
+			err = c.setRemitDate(claim, line, s)
+		case "SVC":
+			if claim == nil {
+				break
+			}
+			line = &remitLine{charge: s.Element(2), paid: s.Element(3), quantity: s.Element(5)}
+			if s.Component(1, 1) == "NU" {
+				line.revenue = concept(RevenueCodeSystem, s.Component(1, 2))
+				line.productOrService = notApplicable()
+			} else {
+				line.productOrService, line.modifiers = procedureConcept(s.Components(1))
+				if rev := s.Element(4); rev != "" {
+					line.revenue = concept(RevenueCodeSystem, rev)
+				}
+			}
+			if line.productOrService == nil {
+				line.productOrService = notApplicable()
+			}
+			claim.lines = append(claim.lines, line)
+		case "AMT":
+			if line != nil && s.Element(1) == "B6" {
+				line.allowed = s.Element(2)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s, segment %d (%s): %w", tx.ControlNumber, n+1, s.ID, err)
+		}
+	}
+	return r, nil
+}
+
+func (c *converter) setRemitDate(claim *remitClaim, line *remitLine, s Segment) error {
+	if claim == nil {
+		return nil
+	}
+	var err error
+	switch s.Element(1) {
+	case "472":
+		if line != nil {
+			line.serviced, err = c.date(s.Element(2))
+		}
+	case "232", "233":
+		dt, e := c.dateTime(s.Element(2))
+		if e != nil {
+			return e
+		}
+		if claim.statementPeriod == nil {
+			claim.statementPeriod = &d4pb.Period{}
+		}
+		if s.Element(1) == "232" {
+			claim.statementPeriod.Start = dt
+		} else {
+			claim.statementPeriod.End = dt
+		}
+	}
+	return err
+}
+
+// casAdjustments reads the reason, amount and quantity triples of a CAS
+// segment.
+func casAdjustments(s Segment) []adjustment {
+	var out []adjustment
+	for i := 2; i+1 <= len(s.Elements); i += 3 {
+		if s.Element(i) == "" {
+			continue
+		}
+		out = append(out, adjustment{group: s.Element(1), reason: s.Element(i), amount: s.Element(i + 1)})
+	}
+	return out
+}
+
+// category returns the adjudication category for a claim adjustment. Patient
+// responsibility for deductibles, coinsurance and copays maps to the
+// corresponding categories; other adjustments are amounts not covered.
+func (a adjustment) category() *d4pb.CodeableConcept {
+	if a.group == "PR" {
+		switch a.reason {
+		case "1":
+			return concept(AdjudicationSystem, "deductible")
+		case "2":
+			return concept(C4BBAdjudicationSystem, "coinsurance")
+		case "3":
+			return concept(AdjudicationSystem, "copay")
+		}
+		return concept(C4BBAdjudicationSystem, "memberliability")
+	}
+	return concept(C4BBAdjudicationSystem, "noncovered")
+}
+
+func adjudications(charge, allowed, paid string, adjs []adjustment) []adjudication {
+	var out []adjudication
+	if charge != "" {
+		out = append(out, adjudication{category: concept(AdjudicationSystem, "submitted"), amount: charge})
+	}
+	if allowed != "" {
+		out = append(out, adjudication{category: concept(AdjudicationSystem, "eligible"), amount: allowed})
+	}
+	if paid != "" {
+		out = append(out, adjudication{category: concept(AdjudicationSystem, "benefit"), amount: paid})
+	}
+	for _, a := range adjs {
+		out = append(out, adjudication{
+			category: a.category(),
+			reason:   concept(CARCSystem, a.reason),
+			amount:   a.amount,
+		})
+	}
+	return out
+}
+
+func (rc *remitClaim) claimType() *d4pb.CodeableConcept {
+	if rc.institutional {
+		return concept(ClaimTypeSystem, "institutional")
+	}
+	return concept(ClaimTypeSystem, "professional")
+}
+
+func (c *converter) remitPatient(rc *remitClaim) *d4pb.Reference {
+	p := rc.patient
+	if p.id == "" && rc.insured.id != "" {
+		// The patient is identified by the subscriber's member id.
+		p.id = rc.insured.id
+	}
+	return identifierReference(c.opts.MemberIDSystem, p.id, p.name)
+}
+
+func (c *converter) remitCoverage(rc *remitClaim, r *remittance) *d4pb.Reference {
+	id := rc.insured.id
+	if id == "" {
+		id = rc.patient.id
+	}
+	return identifierReference(c.opts.MemberIDSystem, id, r.payer.name)
+}
+
+func (c *converter) remitInsurer(r *remittance) *d4pb.Reference {
+	system := ""
+	if r.payer.idQualifier == "XV" || r.payer.idQualifier == "PI" {
+		system = c.opts.PayerIDSystem
+	}
+	return identifierReference(system, r.payer.id, r.payer.name)
+}
+
+func created(r *remittance) *d4pb.DateTime {
+	if r.paymentDate == nil {
+		return nil
+	}
+	return &d4pb.DateTime{ValueUs: r.paymentDate.GetValueUs(), Timezone: r.paymentDate.GetTimezone(), Precision: d4pb.DateTime_DAY}
+}
+
+func disposition(rc *remitClaim) *d4pb.String {
+	if d, ok := claimStatus[rc.status]; ok {
+		return &d4pb.String{Value: d}
+	}
+	return nil
+}
+
+// ToClaimResponses converts an 835 transaction into one ClaimResponse per
+// CLP segment. Each response references the original claim by its patient
+// control number.
+func ToClaimResponses(tx *Transaction, opts Options) ([]*crpb.ClaimResponse, error) {
+	c, err := newConverter(opts)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.parseRemittance(tx)
+	if err != nil {
+		return nil, err
+	}
+	var out []*crpb.ClaimResponse
+	for _, rc := range r.claims {
+		resp := &crpb.ClaimResponse{
+			Status:      &crpb.ClaimResponse_StatusCode{Value: c4pb.FinancialResourceStatusCode_ACTIVE},
+			Type:        rc.claimType(),
+			Use:         &crpb.ClaimResponse_UseCode{Value: c4pb.UseCode_CLAIM},
+			Patient:     c.remitPatient(rc),
+			Created:     created(r),
+			Insurer:     c.remitInsurer(r),
+			Requestor:   providerReference(r.payee),
+			Request:     identifierReference("", rc.id, ""),
+			Outcome:     &crpb.ClaimResponse_OutcomeCode{Value: c4pb.ClaimProcessingCode_COMPLETE},
+			Disposition: disposition(rc),
+			Insurance: []*crpb.ClaimResponse_Insurance{{
+				Sequence: &d4pb.PositiveInt{Value: 1},
+				Focal:    &d4pb.Boolean{Value: true},
+				Coverage: c.remitCoverage(rc, r),
+			}},
+			Payment: &crpb.ClaimResponse_Payment{
+				Type:   concept(PaymentTypeSystem, "complete"),
+				Date:   r.paymentDate,
+				Amount: c.money(rc.paid),
+			},
+		}
+		if rc.payerClaimID != "" {
+			resp.Identifier = []*d4pb.Identifier{{Value: &d4pb.String{Value: rc.payerClaimID}}}
+		}
+		if r.traceNumber != "" {
+			resp.Payment.Identifier = &d4pb.Identifier{Value: &d4pb.String{Value: r.traceNumber}}
+		}
+		for i, l := range rc.lines {
+			item := &crpb.ClaimResponse_Item{ItemSequence: &d4pb.PositiveInt{Value: uint32(i + 1)}}
+			for _, a := range adjudications(l.charge, l.allowed, l.paid, l.adjustments) {
+				item.Adjudication = append(item.Adjudication, c.claimResponseAdjudication(a))
+			}
+			resp.Item = append(resp.Item, item)
+		}
+		for _, a := range adjudications("", "", "", rc.adjustments) {
+			resp.Adjudication = append(resp.Adjudication, c.claimResponseAdjudication(a))
+		}
+		for _, t := range totals(rc) {
+			resp.Total = append(resp.Total, &crpb.ClaimResponse_Total{Category: t.category, Amount: c.money(t.amount)})
+		}
+		out = append(out, resp)
+	}
+	return out, nil
+}
+
+func (c *converter) claimResponseAdjudication(a adjudication) *crpb.ClaimResponse_Item_Adjudication {
+	return &crpb.ClaimResponse_Item_Adjudication{Category: a.category, Reason: a.reason, Amount: c.money(a.amount)}
+}
+
+func totals(rc *remitClaim) []adjudication {
+	var out []adjudication
+	if rc.charge != "" {
+		out = append(out, adjudication{category: concept(AdjudicationSystem, "submitted"), amount: rc.charge})
+	}
+	if rc.paid != "" {
+		out = append(out, adjudication{category: concept(AdjudicationSystem, "benefit"), amount: rc.paid})
+	}
+	if rc.patientResp != "" {
+		out = append(out, adjudication{category: concept(C4BBAdjudicationSystem, "memberliability"), amount: rc.patientResp})
+	}
+	return out
+}
+
+// ToExplanationOfBenefits converts an 835 transaction into one
+// ExplanationOfBenefit per CLP segment, shaped after the CARIN BB profiles:
+// the payer claim control number is the unique claim identifier, and amounts
+// use the CARIN BB adjudication categories where the base categories do not
+// apply.
+func ToExplanationOfBenefits(tx *Transaction, opts Options) ([]*eobpb.ExplanationOfBenefit, error) {
+	c, err := newConverter(opts)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.parseRemittance(tx)
+	if err != nil {
+		return nil, err
+	}
+	var out []*eobpb.ExplanationOfBenefit
+	for _, rc := range r.claims {
+		eob := &eobpb.ExplanationOfBenefit{
+			Status:         &eobpb.ExplanationOfBenefit_StatusCode{Value: c4pb.ExplanationOfBenefitStatusCode_ACTIVE},
+			Type:           rc.claimType(),
+			Use:            &eobpb.ExplanationOfBenefit_UseCode{Value: c4pb.UseCode_CLAIM},
+			Patient:        c.remitPatient(rc),
+			BillablePeriod: rc.statementPeriod,
+			Created:        created(r),
+			Insurer:        c.remitInsurer(r),
+			Provider:       providerReference(r.payee),
+			Claim:          identifierReference("", rc.id, ""),
+			Outcome:        &eobpb.ExplanationOfBenefit_OutcomeCode{Value: c4pb.ClaimProcessingCode_COMPLETE},
+			Disposition:    disposition(rc),
+			Insurance: []*eobpb.ExplanationOfBenefit_Insurance{{
+				Focal:    &d4pb.Boolean{Value: true},
+				Coverage: c.remitCoverage(rc, r),
+			}},
+			Payment: &eobpb.ExplanationOfBenefit_Payment{
+				Type:   concept(PaymentTypeSystem, "complete"),
+				Date:   r.paymentDate,
+				Amount: c.money(rc.paid),
+			},
+		}
+		if rc.payerClaimID != "" {
+			eob.Identifier = []*d4pb.Identifier{{
+				Type:  concept(C4BBIdentifierTypeSystem, "uc"),
+				Value: &d4pb.String{Value: rc.payerClaimID},
+			}}
+		}
+		if r.traceNumber != "" {
+			eob.Payment.Identifier = &d4pb.Identifier{Value: &d4pb.String{Value: r.traceNumber}}
+		}
+		for i, l := range rc.lines {
+			item := &eobpb.ExplanationOfBenefit_Item{
+				Sequence:         &d4pb.PositiveInt{Value: uint32(i + 1)},
+				Revenue:          l.revenue,
+				ProductOrService: l.productOrService,
+				Modifier:         l.modifiers,
+				Net:              c.money(l.charge),
+			}
+			if l.serviced != nil {
+				item.Serviced = &eobpb.ExplanationOfBenefit_Item_ServicedX{
+					Choice: &eobpb.ExplanationOfBenefit_Item_ServicedX_Date{Date: l.serviced},
+				}
+			}
+			if l.quantity != "" {
+				item.Quantity = &d4pb.SimpleQuantity{Value: &d4pb.Decimal{Value: l.quantity}}
+			}
+			for _, a := range adjudications(l.charge, l.allowed, l.paid, l.adjustments) {
+				item.Adjudication = append(item.Adjudication, c.eobAdjudication(a))
+			}
+			eob.Item = append(eob.Item, item)
+		}
+		for _, a := range adjudications("", "", "", rc.adjustments) {
+			eob.Adjudication = append(eob.Adjudication, c.eobAdjudication(a))
+		}
+		for _, t := range totals(rc) {
+			eob.Total = append(eob.Total, &eobpb.ExplanationOfBenefit_Total{Category: t.category, Amount: c.money(t.amount)})
+		}
+		out = append(out, eob)
+	}
+	return out, nil
+}
+
+func (c *converter) eobAdjudication(a adjudication) *eobpb.ExplanationOfBenefit_Item_Adjudication {
+	return &eobpb.ExplanationOfBenefit_Item_Adjudication{Category: a.category, Reason: a.reason, Amount: c.money(a.amount)}
+}
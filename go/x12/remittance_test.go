@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x12
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	c4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/codes_go_proto"
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	crpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/claim_response_go_proto"
+	eobpb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/explanation_of_benefit_go_proto"
+)
+
+var remittance835 = []string{
+	"BPR*I*80*C*ACH*CCP*01*999999992*DA*123456*1512345678**01*999988880*DA*98765*20200115",
+	"TRN*1*12345*1512345678",
+	"N1*PR*KEY INSURANCE COMPANY*XV*999996666",
+	"N1*PE*BEN KILDARE SERVICE*XX*9876543210",
+	"LX*1",
+	"CLP*26463774*1*100*80*20*12*PCN123",
+	"CAS*PR*2*20",
+	"NM1*QC*1*SMITH*JANE****MI*JS00111223333",
+	"DTM*232*20200101",
+	"DTM*233*20200102",
+	"SVC*HC:99213:25*40*40**1",
+	"DTM*472*20200101",
+	"AMT*B6*40",
+	"SVC*HC:87070*60*40**1",
+	"DTM*472*20200102",
+	"CAS*PR*2*10",
+	"CAS*CO*45*10",
+}
+
+func parse835(t *testing.T) *Transaction {
+	t.Helper()
+	txs, err := Parse(interchange("835", "005010X221A1", remittance835...))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	return txs[0]
+}
+
+func adj(system, code, amount string) (*d4pb.CodeableConcept, *d4pb.Money) {
+	return concept(system, code), usd(amount)
+}
+
+func TestToClaimResponses(t *testing.T) {
+	got, err := ToClaimResponses(parse835(t), Options{MemberIDSystem: memberSystem})
+	if err != nil {
+		t.Fatalf("ToClaimResponses() returned unexpected error: %v", err)
+	}
+	a := func(system, code, amount, reason string) *crpb.ClaimResponse_Item_Adjudication {
+		cat, m := adj(system, code, amount)
+		out := &crpb.ClaimResponse_Item_Adjudication{Category: cat, Amount: m}
+		if reason != "" {
+			out.Reason = concept(CARCSystem, reason)
+		}
+		return out
+	}
+	want := []*crpb.ClaimResponse{{
+		Identifier:  []*d4pb.Identifier{{Value: &d4pb.String{Value: "PCN123"}}},
+		Status:      &crpb.ClaimResponse_StatusCode{Value: c4pb.FinancialResourceStatusCode_ACTIVE},
+		Type:        concept(ClaimTypeSystem, "professional"),
+		Use:         &crpb.ClaimResponse_UseCode{Value: c4pb.UseCode_CLAIM},
+		Patient:     identifierReference(memberSystem, "JS00111223333", "JANE SMITH"),
+		Created:     dt(jan1 + 14*86400000000),
+		Insurer:     identifierReference("", "999996666", "KEY INSURANCE COMPANY"),
+		Requestor:   identifierReference(NPISystem, "9876543210", "BEN KILDARE SERVICE"),
+		Request:     identifierReference("", "26463774", ""),
+		Outcome:     &crpb.ClaimResponse_OutcomeCode{Value: c4pb.ClaimProcessingCode_COMPLETE},
+		Disposition: &d4pb.String{Value: "Processed as Primary"},
+		Item: []*crpb.ClaimResponse_Item{
+			{
+				ItemSequence: &d4pb.PositiveInt{Value: 1},
+				Adjudication: []*crpb.ClaimResponse_Item_Adjudication{
+					a(AdjudicationSystem, "submitted", "40", ""),
+					a(AdjudicationSystem, "eligible", "40", ""),
+					a(AdjudicationSystem, "benefit", "40", ""),
+				},
+			},
+			{
+				ItemSequence: &d4pb.PositiveInt{Value: 2},
+				Adjudication: []*crpb.ClaimResponse_Item_Adjudication{
+					a(AdjudicationSystem, "submitted", "60", ""),
+					a(AdjudicationSystem, "benefit", "40", ""),
+					a(C4BBAdjudicationSystem, "coinsurance", "10", "2"),
+					a(C4BBAdjudicationSystem, "noncovered", "10", "45"),
+				},
+			},
+		},
+		Adjudication: []*crpb.ClaimResponse_Item_Adjudication{
+			a(C4BBAdjudicationSystem, "coinsurance", "20", "2"),
+		},
+		Total: []*crpb.ClaimResponse_Total{
+			{Category: concept(AdjudicationSystem, "submitted"), Amount: usd("100")},
+			{Category: concept(AdjudicationSystem, "benefit"), Amount: usd("80")},
+			{Category: concept(C4BBAdjudicationSystem, "memberliability"), Amount: usd("20")},
+		},
+		Payment: &crpb.ClaimResponse_Payment{
+			Type:       concept(PaymentTypeSystem, "complete"),
+			Date:       &d4pb.Date{ValueUs: jan1 + 14*86400000000, Timezone: "UTC", Precision: d4pb.Date_DAY},
+			Amount:     usd("80"),
+			Identifier: &d4pb.Identifier{Value: &d4pb.String{Value: "12345"}},
+		},
+		Insurance: []*crpb.ClaimResponse_Insurance{{
+			Sequence: &d4pb.PositiveInt{Value: 1},
+			Focal:    &d4pb.Boolean{Value: true},
+			Coverage: identifierReference(memberSystem, "JS00111223333", "KEY INSURANCE COMPANY"),
+		}},
+	}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ToClaimResponses() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestToExplanationOfBenefits(t *testing.T) {
+	got, err := ToExplanationOfBenefits(parse835(t), Options{MemberIDSystem: memberSystem})
+	if err != nil {
+		t.Fatalf("ToExplanationOfBenefits() returned unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("ToExplanationOfBenefits() returned %d resources, want 1", len(got))
+	}
+	eob := got[0]
+	checks := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"identifier", eob.GetIdentifier(), []*d4pb.Identifier{{
+			Type:  concept(C4BBIdentifierTypeSystem, "uc"),
+			Value: &d4pb.String{Value: "PCN123"},
+		}}},
+		{"status", eob.GetStatus(), &eobpb.ExplanationOfBenefit_StatusCode{Value: c4pb.ExplanationOfBenefitStatusCode_ACTIVE}},
+		{"billable period", eob.GetBillablePeriod(), &d4pb.Period{Start: dt(jan1), End: dt(jan2)}},
+		{"provider", eob.GetProvider(), identifierReference(NPISystem, "9876543210", "BEN KILDARE SERVICE")},
+		{"claim", eob.GetClaim(), identifierReference("", "26463774", "")},
+		{"serviced", eob.GetItem()[1].GetServiced().GetDate(), &d4pb.Date{ValueUs: jan2, Timezone: "UTC", Precision: d4pb.Date_DAY}},
+		{"modifier", eob.GetItem()[0].GetModifier(), []*d4pb.CodeableConcept{concept(CPTSystem, "25")}},
+		{"net", eob.GetItem()[1].GetNet(), usd("60")},
+		{"adjudications", len(eob.GetItem()[1].GetAdjudication()), 4},
+		{"totals", len(eob.GetTotal()), 3},
+		{"payment", eob.GetPayment().GetAmount(), usd("80")},
+	}
+	for _, c := range checks {
+		if diff := cmp.Diff(c.want, c.got, protocmp.Transform()); diff != "" {
+			t.Errorf("ToExplanationOfBenefits() %s unexpected diff (-want +got):\n%s", c.name, diff)
+		}
+	}
+}
+
+func TestToExplanationOfBenefits_Institutional(t *testing.T) {
+	txs, err := Parse(interchange("835", "005010X221A1",
+		"N1*PR*PAYER",
+		"CLP*A1*4*500*0*0*MC*PCN9*11*1",
+		"SVC*NU:0450*500*0**1",
+	))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	got, err := ToExplanationOfBenefits(txs[0], Options{})
+	if err != nil {
+		t.Fatalf("ToExplanationOfBenefits() returned unexpected error: %v", err)
+	}
+	eob := got[0]
+	if diff := cmp.Diff(concept(ClaimTypeSystem, "institutional"), eob.GetType(), protocmp.Transform()); diff != "" {
+		t.Errorf("type unexpected diff (-want +got):\n%s", diff)
+	}
+	if got, want := eob.GetDisposition().GetValue(), "Denied"; got != want {
+		t.Errorf("disposition = %q, want %q", got, want)
+	}
+	if diff := cmp.Diff(concept(RevenueCodeSystem, "0450"), eob.GetItem()[0].GetRevenue(), protocmp.Transform()); diff != "" {
+		t.Errorf("revenue unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestToClaimResponses_NotRemittance(t *testing.T) {
+	if _, err := ToClaimResponses(&Transaction{Type: "837"}, Options{}); err == nil {
+		t.Errorf("ToClaimResponses() succeeded, want error")
+	}
+}
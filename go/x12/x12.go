@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package x12 converts ASC X12 5010 health care claim (837) and claim
+// payment/advice (835) transactions to R4 Claim, ClaimResponse and
+// ExplanationOfBenefit resources. The ExplanationOfBenefit resources follow
+// the shape of the CARIN Consumer Directed Payer Data Exchange (CARIN BB)
+// implementation guide.
+//
+// The converters cover the segments needed to populate those resources and
+// skip everything else, so they are not validators for the X12
+// implementation guides.
+package x12
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// isaLength is the fixed length of the ISA segment including its terminator.
+const isaLength = 106
+
+// Segment is a single X12 segment.
+type Segment struct {
+	ID string
+	// Elements holds the elements following the segment ID, so that the
+	// element called XXX01 in implementation guides is Elements[0].
+	Elements []string
+
+	componentSep string
+}
+
+// Element returns the element at the 1-based position used by implementation
+// guides, or "" if absent.
+func (s Segment) Element(i int) string {
+	if i < 1 || i > len(s.Elements) {
+		return ""
+	}
+	return s.Elements[i-1]
+}
+
+// Component returns the j-th component of the composite element at position
+// i, both 1-based, or "" if absent.
+func (s Segment) Component(i, j int) string {
+	parts := strings.Split(s.Element(i), s.componentSep)
+	if j < 1 || j > len(parts) {
+		return ""
+	}
+	return parts[j-1]
+}
+
+// Components returns all components of the composite element at position i.
+func (s Segment) Components(i int) []string {
+	e := s.Element(i)
+	if e == "" {
+		return nil
+	}
+	return strings.Split(e, s.componentSep)
+}
+
+// Transaction is a transaction set, from its ST segment to its SE segment.
+type Transaction struct {
+	// Type is the transaction set identifier code, e.g. "837".
+	Type string
+	// ControlNumber is the transaction set control number from ST02.
+	ControlNumber string
+	// Version is the implementation convention reference, e.g.
+	// "005010X222A1", from ST03 or, if absent, GS08.
+	Version string
+	// Segments holds the segments between ST and SE, exclusive.
+	Segments []Segment
+}
+
+// Parse splits an interchange into its transaction sets. The delimiters are
+// taken from the ISA segment.
+func Parse(in []byte) ([]*Transaction, error) {
+	in = bytes.TrimLeft(in, " \t\r\n")
+	if len(in) < isaLength || string(in[:3]) != "ISA" {
+		return nil, fmt.Errorf("input does not start with an ISA segment")
+	}
+	elementSep := string(in[3])
+	componentSep := string(in[isaLength-2])
+	segmentTerm := string(in[isaLength-1])
+
+	var (
+		txs     []*Transaction
+		cur     *Transaction
+		version string
+	)
+	for n, raw := range strings.Split(string(in), segmentTerm) {
+		raw = strings.Trim(raw, " \t\r\n")
+		if raw == "" {
+			continue
+		}
+		parts := strings.Split(raw, elementSep)
+		seg := Segment{ID: parts[0], Elements: parts[1:], componentSep: componentSep}
+		switch seg.ID {
+		case "GS":
+			version = seg.Element(8)
+		case "ST":
+			if cur != nil {
+				return nil, fmt.Errorf("segment %d: ST before SE of transaction %s", n+1, cur.ControlNumber)
+			}
+			cur = &Transaction{Type: seg.Element(1), ControlNumber: seg.Element(2), Version: seg.Element(3)}
+			if cur.Version == "" {
+				cur.Version = version
+			}
+		case "SE":
+			if cur == nil {
+				return nil, fmt.Errorf("segment %d: SE without ST", n+1)
+			}
+			// SE01 counts the segments including ST and SE.
+			if count, err := strconv.Atoi(seg.Element(1)); err != nil || count != len(cur.Segments)+2 {
+				return nil, fmt.Errorf("transaction %s: SE01 segment count %q, want %d", cur.ControlNumber, seg.Element(1), len(cur.Segments)+2)
+			}
+			txs = append(txs, cur)
+			cur = nil
+		default:
+			if cur != nil {
+				cur.Segments = append(cur.Segments, seg)
+			}
+		}
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("transaction %s has no SE segment", cur.ControlNumber)
+	}
+	return txs, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x12
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const isa = "ISA*00*          *00*          *ZZ*SENDER         *ZZ*RECEIVER       *200101*1253*^*00501*000000001*0*P*:~"
+
+// interchange wraps the body segments of a single transaction in an
+// interchange and functional group envelope.
+func interchange(txType, version string, body ...string) []byte {
+	segs := []string{
+		isa,
+		"GS*HC*SENDER*RECEIVER*20200101*1253*1*X*" + version + "~",
+		"ST*" + txType + "*0001*" + version + "~",
+	}
+	for _, b := range body {
+		segs = append(segs, b+"~")
+	}
+	segs = append(segs,
+		fmt.Sprintf("SE*%d*0001~", len(body)+2),
+		"GE*1*1~",
+		"IEA*1*000000001~",
+	)
+	return []byte(strings.Join(segs, "\n"))
+}
+
+func TestParse(t *testing.T) {
+	txs, err := Parse(interchange("837", "005010X222A1", "BHT*0019*00*1*20200102*1200*CH", "HI*ABK:J020*ABF:R509"))
+	if err != nil {
+		t.Fatalf("Parse() returned unexpected error: %v", err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("Parse() returned %d transactions, want 1", len(txs))
+	}
+	tx := txs[0]
+	if tx.Type != "837" || tx.ControlNumber != "0001" || tx.Version != "005010X222A1" {
+		t.Errorf("Parse() returned transaction %+v, want 837 0001 005010X222A1", tx)
+	}
+	if len(tx.Segments) != 2 {
+		t.Fatalf("Parse() returned %d segments, want 2", len(tx.Segments))
+	}
+	hi := tx.Segments[1]
+	if got := hi.Component(2, 2); got != "R509" {
+		t.Errorf("Component(2, 2) = %q, want R509", got)
+	}
+	if got := hi.Element(3); got != "" {
+		t.Errorf("Element(3) = %q, want empty", got)
+	}
+	if got := tx.Segments[0].Element(4); got != "20200102" {
+		t.Errorf("Element(4) = %q, want 20200102", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"no ISA", "GS*HC~"},
+		{"short ISA", "ISA*00~"},
+		{"bad count", isa + "ST*837*0001~BHT*0019~SE*5*0001~"},
+		{"missing SE", isa + "ST*837*0001~BHT*0019~"},
+		{"SE without ST", isa + "SE*2*0001~"},
+		{"nested ST", isa + "ST*837*0001~ST*837*0002~"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse([]byte(test.in)); err == nil {
+				t.Errorf("Parse() succeeded, want error")
+			}
+		})
+	}
+}
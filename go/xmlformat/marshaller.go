@@ -0,0 +1,316 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmlformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Marshaller converts resource protos to FHIR XML.
+type Marshaller struct {
+	json              *jsonformat.Marshaller
+	containedResource protoreflect.MessageDescriptor
+	enableIndent      bool
+	prefix, indent    string
+}
+
+// NewMarshaller returns a Marshaller of resources of version ver, which
+// indents elements with indent, after prefix, if enableIndent is set.
+func NewMarshaller(enableIndent bool, prefix, indent string, ver fhirversion.Version) (*Marshaller, error) {
+	cr, err := containedResource(ver)
+	if err != nil {
+		return nil, err
+	}
+	jm, err := jsonformat.NewMarshaller(false, "", "", ver)
+	if err != nil {
+		return nil, err
+	}
+	return &Marshaller{json: jm, containedResource: cr, enableIndent: enableIndent, prefix: prefix, indent: indent}, nil
+}
+
+// NewPrettyMarshaller returns a Marshaller that indents elements by two
+// spaces.
+func NewPrettyMarshaller(ver fhirversion.Version) (*Marshaller, error) {
+	return NewMarshaller(true, "", "  ", ver)
+}
+
+// Marshal returns the XML of the resource held by the ContainedResource pb.
+func (m *Marshaller) Marshal(pb proto.Message) ([]byte, error) {
+	return m.MarshalResource(pb)
+}
+
+// MarshalResource returns the XML of the resource r. r may also be a
+// ContainedResource.
+func (m *Marshaller) MarshalResource(r proto.Message) ([]byte, error) {
+	res, err := unwrap(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := m.json.MarshalResource(res)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	w := &writer{m: m}
+	if err := w.resource(res.ProtoReflect().Descriptor(), obj, true); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// writer writes the XML of a resource from its JSON.
+type writer struct {
+	m     *Marshaller
+	buf   bytes.Buffer
+	depth int
+}
+
+// newline starts a line at the current depth when indenting.
+func (w *writer) newline() {
+	if !w.m.enableIndent {
+		return
+	}
+	if w.buf.Len() > 0 {
+		w.buf.WriteByte('\n')
+	}
+	w.buf.WriteString(w.m.prefix)
+	w.buf.WriteString(strings.Repeat(w.m.indent, w.depth))
+}
+
+func (w *writer) attr(name, value string) {
+	fmt.Fprintf(&w.buf, ` %s="`, name)
+	xml.EscapeText(&w.buf, []byte(value))
+	w.buf.WriteByte('"')
+}
+
+// resource writes the element of the resource of type d with the JSON
+// properties obj. The root element declares the FHIR namespace.
+func (w *writer) resource(d protoreflect.MessageDescriptor, obj map[string]json.RawMessage, root bool) error {
+	delete(obj, "resourceType")
+	w.newline()
+	fmt.Fprintf(&w.buf, "<%s", d.Name())
+	if root {
+		w.attr("xmlns", fhirNamespace)
+	}
+	return w.content(string(d.Name()), d, obj)
+}
+
+// complex writes the element name of type d with the JSON properties obj.
+func (w *writer) complex(name string, d protoreflect.MessageDescriptor, obj map[string]json.RawMessage) error {
+	w.newline()
+	fmt.Fprintf(&w.buf, "<%s", name)
+	return w.content(name, d, obj)
+}
+
+// content writes the attributes and children of an element whose start tag
+// has been opened, and closes it.
+func (w *writer) content(name string, d protoreflect.MessageDescriptor, obj map[string]json.RawMessage) error {
+	t := elementsOf(d)
+	for k := range obj {
+		if _, ok := t.byName[strings.TrimPrefix(k, "_")]; !ok {
+			return fmt.Errorf("unexpected property %q", k)
+		}
+	}
+	var children []*element
+	for _, e := range t.ordered {
+		v, ok := obj[e.name]
+		if !ok && obj["_"+e.name] == nil {
+			continue
+		}
+		if !e.attr {
+			children = append(children, e)
+			continue
+		}
+		s, err := text(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+		w.attr(e.name, s)
+	}
+	if len(children) == 0 {
+		w.buf.WriteString("/>")
+		return nil
+	}
+	w.buf.WriteByte('>')
+	w.depth++
+	for _, e := range children {
+		if err := w.element(e, obj[e.name], obj["_"+e.name]); err != nil {
+			return err
+		}
+	}
+	w.depth--
+	w.newline()
+	fmt.Fprintf(&w.buf, "</%s>", name)
+	return nil
+}
+
+// element writes the elements of e with the JSON value v and, for
+// primitives, the id and extensions ext.
+func (w *writer) element(e *element, v, ext json.RawMessage) error {
+	values, exts := []json.RawMessage{v}, []json.RawMessage{ext}
+	if e.repeated {
+		values, exts = nil, nil
+		if err := unmarshalArray(v, &values); err != nil {
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+		if err := unmarshalArray(ext, &exts); err != nil {
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+	}
+	n := len(values)
+	if len(exts) > n {
+		n = len(exts)
+	}
+	for i := 0; i < n; i++ {
+		var v, ext json.RawMessage
+		if i < len(values) {
+			v = values[i]
+		}
+		if i < len(exts) {
+			ext = exts[i]
+		}
+		if err := w.value(e, v, ext); err != nil {
+			if e.repeated {
+				return fmt.Errorf("%s[%d]: %w", e.name, i, err)
+			}
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+	}
+	return nil
+}
+
+func (w *writer) value(e *element, v, ext json.RawMessage) error {
+	switch e.kind {
+	case primitiveKind:
+		return w.primitive(e.name, v, ext)
+	case xhtmlKind:
+		s, err := text(v)
+		if err != nil {
+			return err
+		}
+		w.newline()
+		w.buf.WriteString(s)
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(v, &obj); err != nil {
+		return err
+	}
+	if e.kind == complexKind {
+		return w.complex(e.name, e.desc, obj)
+	}
+	var typ string
+	if err := json.Unmarshal(obj["resourceType"], &typ); err != nil {
+		return fmt.Errorf("invalid resourceType: %w", err)
+	}
+	d, ok := resourceType(w.m.containedResource, typ)
+	if !ok {
+		return fmt.Errorf("unknown resource type %q", typ)
+	}
+	w.newline()
+	fmt.Fprintf(&w.buf, "<%s>", e.name)
+	w.depth++
+	if err := w.resource(d, obj, false); err != nil {
+		return err
+	}
+	w.depth--
+	w.newline()
+	fmt.Fprintf(&w.buf, "</%s>", e.name)
+	return nil
+}
+
+// primitive writes the primitive element name with the JSON value v and the
+// JSON object ext of its id and extensions.
+func (w *writer) primitive(name string, v, ext json.RawMessage) error {
+	var props struct {
+		ID        *string                      `json:"id"`
+		Extension []map[string]json.RawMessage `json:"extension"`
+	}
+	if isNull(v) && isNull(ext) {
+		return nil
+	}
+	if !isNull(ext) {
+		if err := json.Unmarshal(ext, &props); err != nil {
+			return err
+		}
+	}
+	w.newline()
+	fmt.Fprintf(&w.buf, "<%s", name)
+	if props.ID != nil {
+		w.attr("id", *props.ID)
+	}
+	if !isNull(v) {
+		s, err := text(v)
+		if err != nil {
+			return err
+		}
+		w.attr("value", s)
+	}
+	if len(props.Extension) == 0 {
+		w.buf.WriteString("/>")
+		return nil
+	}
+	w.buf.WriteByte('>')
+	w.depth++
+	for _, obj := range props.Extension {
+		if err := w.complex("extension", extensionType(w.m.containedResource), obj); err != nil {
+			return err
+		}
+	}
+	w.depth--
+	w.newline()
+	fmt.Fprintf(&w.buf, "</%s>", name)
+	return nil
+}
+
+// extensionType returns the Extension descriptor of the version of cr, a
+// ContainedResource descriptor.
+func extensionType(cr protoreflect.MessageDescriptor) protoreflect.MessageDescriptor {
+	d, _ := resourceType(cr, "Basic")
+	return d.Fields().ByName("extension").Message()
+}
+
+// text returns the XML text of the JSON primitive v.
+func text(v json.RawMessage) (string, error) {
+	if len(v) > 0 && v[0] == '"' {
+		var s string
+		err := json.Unmarshal(v, &s)
+		return s, err
+	}
+	return string(v), nil
+}
+
+func isNull(v json.RawMessage) bool {
+	return len(v) == 0 || string(v) == "null"
+}
+
+func unmarshalArray(v json.RawMessage, out *[]json.RawMessage) error {
+	if isNull(v) {
+		return nil
+	}
+	return json.Unmarshal(v, out)
+}
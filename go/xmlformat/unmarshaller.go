@@ -0,0 +1,322 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmlformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/jsonformat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// jsonNumberRE matches the numbers of JSON.
+var jsonNumberRE = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// Unmarshaller converts FHIR XML to resource protos.
+type Unmarshaller struct {
+	json              *jsonformat.Unmarshaller
+	containedResource protoreflect.MessageDescriptor
+}
+
+// NewUnmarshaller returns an Unmarshaller of resources of version ver that
+// validates them as jsonformat.NewUnmarshaller does. Times without a time zone
+// are in the time zone tz.
+func NewUnmarshaller(tz string, ver fhirversion.Version) (*Unmarshaller, error) {
+	ju, err := jsonformat.NewUnmarshaller(tz, ver)
+	if err != nil {
+		return nil, err
+	}
+	return newUnmarshaller(ju, ver)
+}
+
+// NewUnmarshallerWithoutValidation returns an Unmarshaller that doesn't
+// perform resource validation.
+func NewUnmarshallerWithoutValidation(tz string, ver fhirversion.Version) (*Unmarshaller, error) {
+	ju, err := jsonformat.NewUnmarshallerWithoutValidation(tz, ver)
+	if err != nil {
+		return nil, err
+	}
+	return newUnmarshaller(ju, ver)
+}
+
+func newUnmarshaller(ju *jsonformat.Unmarshaller, ver fhirversion.Version) (*Unmarshaller, error) {
+	cr, err := containedResource(ver)
+	if err != nil {
+		return nil, err
+	}
+	return &Unmarshaller{json: ju, containedResource: cr}, nil
+}
+
+// Unmarshal returns the resource of the XML in, in a ContainedResource.
+// Errors in the values of the resource are those of jsonformat, with paths
+// to its elements.
+func (u *Unmarshaller) Unmarshal(in []byte) (proto.Message, error) {
+	root, err := parse(in)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := u.resource(root)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return u.json.Unmarshal(data)
+}
+
+// UnmarshalFromReader is Unmarshal of the XML read from in.
+func (u *Unmarshaller) UnmarshalFromReader(in io.Reader) (proto.Message, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	return u.Unmarshal(data)
+}
+
+// node is an element of an XML document.
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	// xhtml is the XML of a narrative div.
+	xhtml []byte
+}
+
+// parse returns the root element of the XML document in.
+func parse(in []byte) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(in))
+	var root *node
+	var stack []*node
+	for {
+		start := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: tok.Name, attrs: tok.Attr}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, errors.New("more than one root element")
+				}
+				root = n
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+			if tok.Name.Space == xhtmlNamespace {
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				n.xhtml = in[start:d.InputOffset()]
+				continue
+			}
+			if tok.Name.Space != fhirNamespace {
+				return nil, fmt.Errorf("element %s is not in the FHIR namespace", tok.Name.Local)
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(bytes.TrimSpace(tok)) > 0 {
+				return nil, fmt.Errorf("unexpected text %.50q", tok)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("no root element")
+	}
+	return root, nil
+}
+
+// resource returns the JSON object of the resource element n.
+func (u *Unmarshaller) resource(n *node) (map[string]interface{}, error) {
+	d, ok := resourceType(u.containedResource, n.name.Local)
+	if !ok {
+		return nil, fmt.Errorf("unknown resource type %q", n.name.Local)
+	}
+	obj, err := u.complex(n.name.Local, n, d)
+	if err != nil {
+		return nil, err
+	}
+	obj["resourceType"] = n.name.Local
+	return obj, nil
+}
+
+// complex returns the JSON object of the element n of type d at path.
+func (u *Unmarshaller) complex(path string, n *node, d protoreflect.MessageDescriptor) (map[string]interface{}, error) {
+	t := elementsOf(d)
+	obj := map[string]interface{}{}
+	for _, a := range n.attrs {
+		if isNamespaceDecl(a) {
+			continue
+		}
+		e, ok := t.byName[a.Name.Local]
+		if !ok || !e.attr || a.Name.Space != "" {
+			return nil, fmt.Errorf("%s: unexpected attribute %q", path, a.Name.Local)
+		}
+		obj[e.name] = a.Value
+	}
+	// Values and ids and extensions of primitives, by element.
+	values, exts := map[*element][]interface{}{}, map[*element][]interface{}{}
+	for _, c := range n.children {
+		e, ok := t.byName[c.name.Local]
+		if !ok || e.attr {
+			return nil, fmt.Errorf("%s: unknown element %q", path, c.name.Local)
+		}
+		if !e.repeated && len(values[e]) > 0 {
+			return nil, fmt.Errorf("%s: repeated element %q", path, e.name)
+		}
+		p := path + "." + e.name
+		if e.repeated {
+			p = fmt.Sprintf("%s[%d]", p, len(values[e]))
+		}
+		v, ext, err := u.value(p, c, e)
+		if err != nil {
+			return nil, err
+		}
+		values[e] = append(values[e], v)
+		exts[e] = append(exts[e], ext)
+	}
+	for e, vs := range values {
+		if p := property(vs, e.repeated); p != nil {
+			obj[e.name] = p
+		}
+		if p := property(exts[e], e.repeated); p != nil {
+			obj["_"+e.name] = p
+		}
+	}
+	return obj, nil
+}
+
+// property returns the JSON property of the values of an element: the
+// single value or nil of an element that is not repeated, and the values of
+// one that is or nil if they are all nil.
+func property(vs []interface{}, repeated bool) interface{} {
+	if !repeated {
+		return vs[0]
+	}
+	for _, v := range vs {
+		if v != nil {
+			return vs
+		}
+	}
+	return nil
+}
+
+// value returns the JSON value of the element n of e at path, and for
+// primitives the JSON object of its id and extensions. Absent values are
+// nil.
+func (u *Unmarshaller) value(path string, n *node, e *element) (interface{}, interface{}, error) {
+	switch e.kind {
+	case xhtmlKind:
+		if n.xhtml == nil {
+			return nil, nil, fmt.Errorf("%s: div is not XHTML", path)
+		}
+		return string(n.xhtml), nil, nil
+	case primitiveKind:
+		return u.primitive(path, n, e)
+	case resourceKind:
+		if hasAttrs(n) || len(n.children) != 1 {
+			return nil, nil, fmt.Errorf("%s: want a single resource element", path)
+		}
+		obj, err := u.resource(n.children[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return obj, nil, nil
+	}
+	if n.xhtml != nil {
+		return nil, nil, fmt.Errorf("%s: unexpected XHTML", path)
+	}
+	obj, err := u.complex(path, n, e.desc)
+	return obj, nil, err
+}
+
+// primitive returns the JSON value of the primitive element n of e, and the
+// JSON object of its id and extensions.
+func (u *Unmarshaller) primitive(path string, n *node, e *element) (interface{}, interface{}, error) {
+	var value interface{}
+	ext := map[string]interface{}{}
+	for _, a := range n.attrs {
+		switch {
+		case isNamespaceDecl(a):
+		case a.Name.Space == "" && a.Name.Local == "value":
+			value = jsonValue(a.Value, e.number)
+		case a.Name.Space == "" && a.Name.Local == "id":
+			ext["id"] = a.Value
+		default:
+			return nil, nil, fmt.Errorf("%s: unexpected attribute %q", path, a.Name.Local)
+		}
+	}
+	var extensions []interface{}
+	for i, c := range n.children {
+		if c.name.Local != "extension" || c.xhtml != nil {
+			return nil, nil, fmt.Errorf("%s: unknown element %q", path, c.name.Local)
+		}
+		obj, err := u.complex(fmt.Sprintf("%s.extension[%d]", path, i), c, extensionType(u.containedResource))
+		if err != nil {
+			return nil, nil, err
+		}
+		extensions = append(extensions, obj)
+	}
+	if extensions != nil {
+		ext["extension"] = extensions
+	}
+	if len(ext) == 0 {
+		return value, nil, nil
+	}
+	return value, ext, nil
+}
+
+// jsonValue returns the JSON value of the value attribute s, which is a
+// number or boolean if number is set and s is one; other values are strings
+// for jsonformat to reject.
+func jsonValue(s string, number bool) interface{} {
+	if number && (s == "true" || s == "false" || jsonNumberRE.MatchString(s)) {
+		return json.RawMessage(s)
+	}
+	return s
+}
+
+// isNamespaceDecl reports whether a declares a namespace.
+func isNamespaceDecl(a xml.Attr) bool {
+	return a.Name.Space == "xmlns" || a.Name.Space == "" && a.Name.Local == "xmlns"
+}
+
+// hasAttrs reports whether n has attributes other than namespace
+// declarations.
+func hasAttrs(n *node) bool {
+	for _, a := range n.attrs {
+		if !isNamespaceDecl(a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xmlformat converts FHIR resources between their XML representation
+// and protos, as jsonformat does for JSON, for the STU3 and R4 versions:
+//
+//	u, err := xmlformat.NewUnmarshaller("UTC", fhirversion.R4)
+//	...
+//	cr, err := u.Unmarshal(data)
+//
+// Conversions go through jsonformat: the XML of a resource is rewritten as
+// its JSON, and the reverse, so both formats parse, validate and print
+// values the same way. The structure the rewriting needs, such as the order
+// of elements, which are repeated and which are primitives, comes from the
+// descriptors of the protos. Primitives are elements with value and id
+// attributes and extension children, the id of elements other than resources
+// and the url of extensions are attributes, narrative div elements are kept
+// as XHTML, and contained resources are wrapped in an element named for
+// their type.
+package xmlformat
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/fhir/go/fhirerrors"
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/fhir/go/internal/elementpath"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	r3pb "github.com/google/fhir/go/proto/google/fhir/proto/stu3/resources_go_proto"
+)
+
+const (
+	// fhirNamespace is the namespace of FHIR elements.
+	fhirNamespace = "http://hl7.org/fhir"
+	// xhtmlNamespace is the namespace of narrative div elements.
+	xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+)
+
+// containedResource returns the ContainedResource descriptor of ver.
+func containedResource(ver fhirversion.Version) (protoreflect.MessageDescriptor, error) {
+	switch ver {
+	case fhirversion.STU3:
+		return (&r3pb.ContainedResource{}).ProtoReflect().Descriptor(), nil
+	case fhirversion.R4:
+		return (&r4pb.ContainedResource{}).ProtoReflect().Descriptor(), nil
+	default:
+		return nil, fmt.Errorf("%w %s", fhirerrors.ErrUnsupportedVersion, ver)
+	}
+}
+
+// resourceType returns the descriptor of the resource named typ in cr, a
+// ContainedResource descriptor.
+func resourceType(cr protoreflect.MessageDescriptor, typ string) (protoreflect.MessageDescriptor, bool) {
+	fields := cr.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if d := fields.Get(i).Message(); d != nil && string(d.Name()) == typ {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// kind tells how an element is represented.
+type kind int
+
+const (
+	// complexKind is an element with child elements.
+	complexKind kind = iota
+	// primitiveKind is an element with a value attribute, which is a
+	// separate property from its id and extensions in JSON.
+	primitiveKind
+	// xhtmlKind is a narrative div, a string of XHTML in JSON.
+	xhtmlKind
+	// resourceKind is an element holding a resource, whose element is named
+	// for its type in XML and which has a resourceType property in JSON.
+	resourceKind
+)
+
+// element describes an element of a message type.
+type element struct {
+	// name is the name of the element in both formats, i.e. valueQuantity.
+	name string
+	// desc is the type of its value. It is nil for the literal reference of
+	// a Reference, a string in both formats.
+	desc     protoreflect.MessageDescriptor
+	kind     kind
+	repeated bool
+	// attr is set for the elements that are attributes in XML.
+	attr bool
+	// number is set for the primitives that are numbers or booleans, rather
+	// than strings, in JSON.
+	number bool
+}
+
+// elements are the elements of a message type in the order of XML.
+type elements struct {
+	ordered []*element
+	byName  map[string]*element
+}
+
+var tables sync.Map // protoreflect.MessageDescriptor -> *elements
+
+// numberTypes are the primitives that are not strings in JSON.
+var numberTypes = map[protoreflect.Name]bool{
+	"Boolean":     true,
+	"Decimal":     true,
+	"Integer":     true,
+	"PositiveInt": true,
+	"UnsignedInt": true,
+}
+
+// elementsOf returns the elements of d, built on first use. Fields follow
+// the order of the elements of FHIR, as the protos are generated from their
+// definitions.
+func elementsOf(d protoreflect.MessageDescriptor) *elements {
+	if t, ok := tables.Load(d); ok {
+		return t.(*elements)
+	}
+	t := &elements{byName: map[string]*element{}}
+	add := func(e *element) {
+		t.ordered = append(t.ordered, e)
+		t.byName[e.name] = e
+	}
+	isResource := elementpath.IsResource(d)
+	fields := d.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.Get(i)
+		if od := f.ContainingOneof(); od != nil && od.Name() == "reference" {
+			// The typed ids of a Reference are all its reference element.
+			if od.Fields().Get(0) == f {
+				add(&element{name: "reference", kind: primitiveKind})
+			}
+			continue
+		}
+		if f.Message() == nil {
+			continue
+		}
+		if elementpath.IsChoice(f.Message()) {
+			choices := f.Message().Fields()
+			for j := 0; j < choices.Len(); j++ {
+				c := choices.Get(j)
+				name := f.JSONName() + strings.ToUpper(c.JSONName()[:1]) + c.JSONName()[1:]
+				add(newElement(name, c.Message(), f.IsList(), false))
+			}
+			continue
+		}
+		attr := f.JSONName() == "id" && !isResource || f.JSONName() == "url" && d.Name() == "Extension"
+		add(newElement(f.JSONName(), f.Message(), f.IsList(), attr))
+	}
+	actual, _ := tables.LoadOrStore(d, t)
+	return actual.(*elements)
+}
+
+func newElement(name string, d protoreflect.MessageDescriptor, repeated, attr bool) *element {
+	e := &element{name: name, desc: d, repeated: repeated, attr: attr}
+	switch {
+	case d.Name() == "Xhtml":
+		e.kind = xhtmlKind
+	case elementpath.IsPrimitive(d):
+		e.kind = primitiveKind
+		e.number = numberTypes[d.Name()]
+	case elementpath.IsContainedResource(d) || d.FullName() == anyName:
+		e.kind = resourceKind
+	}
+	return e
+}
+
+// anyName is the name of Any, the type of contained resources in R4.
+var anyName = (&anypb.Any{}).ProtoReflect().Descriptor().FullName()
+
+// unwrap returns the resource of pb, a resource or a ContainedResource.
+func unwrap(pb proto.Message) (proto.Message, error) {
+	res := elementpath.Unwrap(pb)
+	if res == nil || !elementpath.IsResource(res.ProtoReflect().Descriptor()) {
+		return nil, fmt.Errorf("%v is not a resource", pb.ProtoReflect().Descriptor().FullName())
+	}
+	return res, nil
+}
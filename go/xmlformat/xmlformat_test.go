@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xmlformat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/fhir/go/fhirversion"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	d4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/datatypes_go_proto"
+	r4pb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/bundle_and_contained_resource_go_proto"
+	ppb "github.com/google/fhir/go/proto/google/fhir/proto/r4/core/resources/patient_go_proto"
+	r3pb "github.com/google/fhir/go/proto/google/fhir/proto/stu3/resources_go_proto"
+)
+
+const patientXML = `<Patient xmlns="http://hl7.org/fhir">
+  <id value="p1"/>
+  <text>
+    <status value="generated"/>
+    <div xmlns="http://www.w3.org/1999/xhtml"><p>Jane <b>Doe</b></p></div>
+  </text>
+  <contained>
+    <Practitioner>
+      <id value="gp"/>
+      <name>
+        <family value="House"/>
+      </name>
+    </Practitioner>
+  </contained>
+  <extension url="http://example.com/nickname">
+    <valueString value="JD"/>
+  </extension>
+  <active value="true"/>
+  <name id="n1">
+    <family value="Doe"/>
+    <given value="Jane"/>
+    <given>
+      <extension url="http://hl7.org/fhir/StructureDefinition/data-absent-reason">
+        <valueCode value="unknown"/>
+      </extension>
+    </given>
+    <given value="Q"/>
+  </name>
+  <gender value="female"/>
+  <birthDate id="bd" value="1970-03-01">
+    <extension url="http://hl7.org/fhir/StructureDefinition/patient-birthTime">
+      <valueDateTime value="1970-03-01T08:15:00Z"/>
+    </extension>
+  </birthDate>
+  <deceasedBoolean value="false"/>
+  <multipleBirthInteger value="2"/>
+  <generalPractitioner>
+    <reference value="#gp"/>
+  </generalPractitioner>
+</Patient>`
+
+func TestRoundTrip_R4(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	m, err := NewPrettyMarshaller(fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewPrettyMarshaller() returned unexpected error: %v", err)
+	}
+	cr, err := u.Unmarshal([]byte(patientXML))
+	if err != nil {
+		t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+	}
+	p := cr.(*r4pb.ContainedResource).GetPatient()
+	if got, want := p.GetText().GetDiv().GetValue(), `<div xmlns="http://www.w3.org/1999/xhtml"><p>Jane <b>Doe</b></p></div>`; got != want {
+		t.Errorf("Unmarshal() div = %q, want %q", got, want)
+	}
+	if got := len(p.GetName()[0].GetGiven()); got != 3 {
+		t.Errorf("Unmarshal() got %d given names, want 3", got)
+	}
+	if got := p.GetMultipleBirth().GetInteger().GetValue(); got != 2 {
+		t.Errorf("Unmarshal() multipleBirthInteger = %d, want 2", got)
+	}
+	if got := p.GetGeneralPractitioner()[0].GetFragment().GetValue(); got != "gp" {
+		t.Errorf("Unmarshal() generalPractitioner fragment = %q, want %q", got, "gp")
+	}
+	out, err := m.Marshal(cr)
+	if err != nil {
+		t.Fatalf("Marshal() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(patientXML, string(out)); diff != "" {
+		t.Errorf("Marshal() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestMarshalResource(t *testing.T) {
+	m, err := NewMarshaller(false, "", "", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	p := &ppb.Patient{
+		Id:     &d4pb.Id{Value: "p<1>"},
+		Active: &d4pb.Boolean{Value: true},
+		Name: []*d4pb.HumanName{{
+			Family: &d4pb.String{Value: `O'Brien & "Sons"`},
+		}},
+	}
+	out, err := m.MarshalResource(p)
+	if err != nil {
+		t.Fatalf("MarshalResource() returned unexpected error: %v", err)
+	}
+	want := `<Patient xmlns="http://hl7.org/fhir"><id value="p&lt;1&gt;"/><active value="true"/>` +
+		`<name><family value="O&#39;Brien &amp; &#34;Sons&#34;"/></name></Patient>`
+	if got := string(out); got != want {
+		t.Errorf("MarshalResource() = %s, want %s", got, want)
+	}
+}
+
+func TestRoundTrip_STU3(t *testing.T) {
+	in := `<Observation xmlns="http://hl7.org/fhir"><id value="o1"/><status value="final"/>` +
+		`<code><coding><system value="http://loinc.org"/><code value="8867-4"/></coding></code>` +
+		`<valueQuantity><value value="72.0"/><unit value="/min"/></valueQuantity></Observation>`
+	u, err := NewUnmarshallerWithoutValidation("UTC", fhirversion.STU3)
+	if err != nil {
+		t.Fatalf("NewUnmarshallerWithoutValidation() returned unexpected error: %v", err)
+	}
+	m, err := NewMarshaller(false, "", "", fhirversion.STU3)
+	if err != nil {
+		t.Fatalf("NewMarshaller() returned unexpected error: %v", err)
+	}
+	cr, err := u.UnmarshalFromReader(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("UnmarshalFromReader() returned unexpected error: %v", err)
+	}
+	if got := cr.(*r3pb.ContainedResource).GetObservation().GetValue().GetQuantity().GetValue().GetValue(); got != "72.0" {
+		t.Errorf("UnmarshalFromReader() valueQuantity.value = %q, want %q", got, "72.0")
+	}
+	out, err := m.Marshal(cr)
+	if err != nil {
+		t.Fatalf("Marshal() returned unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(in, string(out)); diff != "" {
+		t.Errorf("Marshal() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	in := `<?xml version="1.0" encoding="UTF-8"?>
+<!-- a comment -->
+<f:Patient xmlns:f="http://hl7.org/fhir"><f:id value="p1"/><f:active value="false"/></f:Patient>`
+	got, err := u.Unmarshal([]byte(in))
+	if err != nil {
+		t.Fatalf("Unmarshal() returned unexpected error: %v", err)
+	}
+	want := &r4pb.ContainedResource{OneofResource: &r4pb.ContainedResource_Patient{Patient: &ppb.Patient{
+		Id:     &d4pb.Id{Value: "p1"},
+		Active: &d4pb.Boolean{Value: false},
+	}}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Unmarshal() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	u, err := NewUnmarshaller("UTC", fhirversion.R4)
+	if err != nil {
+		t.Fatalf("NewUnmarshaller() returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		name, in string
+	}{
+		{"not xml", `{"resourceType":"Patient"}`},
+		{"no namespace", `<Patient><id value="p1"/></Patient>`},
+		{"unknown resource", `<Unicorn xmlns="http://hl7.org/fhir"/>`},
+		{"unknown element", `<Patient xmlns="http://hl7.org/fhir"><horn value="1"/></Patient>`},
+		{"repeated element", `<Patient xmlns="http://hl7.org/fhir"><active value="true"/><active value="false"/></Patient>`},
+		{"invalid value", `<Patient xmlns="http://hl7.org/fhir"><active value="yes"/></Patient>`},
+		{"unexpected attribute", `<Patient xmlns="http://hl7.org/fhir"><active value="true" lang="en"/></Patient>`},
+		{"text content", `<Patient xmlns="http://hl7.org/fhir"><active value="true">yes</active></Patient>`},
+		{"resource id attribute", `<Patient xmlns="http://hl7.org/fhir" id="p1"/>`},
+		{"empty contained", `<Patient xmlns="http://hl7.org/fhir"><contained/></Patient>`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := u.Unmarshal([]byte(tc.in)); err == nil {
+				t.Errorf("Unmarshal(%s) succeeded, want error", tc.in)
+			}
+		})
+	}
+}
+
+func TestNewMarshaller_UnsupportedVersion(t *testing.T) {
+	if _, err := NewMarshaller(false, "", "", fhirversion.Version("DSTU1")); err == nil {
+		t.Errorf("NewMarshaller() succeeded, want error")
+	}
+}